@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+)
+
+const adminUsage = "usage: planter-api admin grant <email> [admin|expert|editor|reviewer]"
+
+// grantableRoles are the roles the admin subcommand grants
+var grantableRoles = map[models.Role]bool{
+	models.RoleAdmin:    true,
+	models.RoleExpert:   true,
+	models.RoleEditor:   true,
+	models.RoleReviewer: true,
+}
+
+// runAdmin executes the admin subcommand: grant <email> [role] gives a registered user a role, the
+// admin role unless another is given
+func runAdmin(userService *services.UserService, args []string) error {
+	if len(args) < 2 || len(args) > 3 || args[0] != "grant" {
+		return errors.New(adminUsage)
+	}
+	role := models.RoleAdmin
+	if len(args) == 3 {
+		role = models.Role(args[2])
+		if !grantableRoles[role] {
+			return errors.New(adminUsage)
+		}
+	}
+
+	if err := userService.GrantRole(context.Background(), args[1], role); err != nil {
+		return err
+	}
+	fmt.Printf("Granted the %s role to %s\n", role, args[1])
+	return nil
+}
@@ -1,17 +1,31 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/anpanovv/planter/internal/api"
+	"github.com/anpanovv/planter/internal/audit"
+	"github.com/anpanovv/planter/internal/cache"
 	"github.com/anpanovv/planter/internal/config"
 	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/db/migrations"
+	"github.com/anpanovv/planter/internal/events"
 	"github.com/anpanovv/planter/internal/jobs"
 	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/redis"
+	"github.com/anpanovv/planter/internal/repository"
 	"github.com/anpanovv/planter/internal/repository/impl"
+	"github.com/anpanovv/planter/internal/saga"
 	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/storage"
+	"github.com/anpanovv/planter/internal/ws"
+	"github.com/google/uuid"
 )
 
 func main() {
@@ -25,22 +39,104 @@ func main() {
 	}
 	defer database.Close()
 
-	// Apply database schema
-	schema, err := os.ReadFile("scripts/schema.sql")
-	if err != nil {
-		log.Fatalf("Failed to read schema file: %v", err)
+	// Resolve asset keys to URLs under the CDN base URL when responses are written
+	assets := storage.New(cfg.Storage.BaseURL, cfg.Storage.LegacyBaseURLs)
+	storage.SetDefault(assets)
+
+	// Handle the migrate subcommand
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(database, assets, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
 	}
-	if _, err := database.Exec(string(schema)); err != nil {
-		log.Fatalf("Failed to apply database schema: %v", err)
+
+	// Apply pending database migrations
+	if cfg.Database.MigrateOnStart {
+		migrator, err := migrations.New(database)
+		if err != nil {
+			log.Fatalf("Failed to load database migrations: %v", err)
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to apply database migrations: %v", err)
+		}
+		log.Printf("Database migrations applied: %d", applied)
+
+		// Rows written by instances that still stored absolute URLs are rewritten on every start
+		rewritten, err := database.RewriteStorageURLs(context.Background(), assets.BaseURLs())
+		if err != nil {
+			log.Fatalf("Failed to rewrite asset URLs to storage keys: %v", err)
+		}
+		log.Printf("Asset URLs rewritten to storage keys: %d", rewritten)
+	}
+
+	// Handle the admin subcommand
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		if err := runAdmin(services.NewUserService(impl.NewUserRepository(database)), os.Args[2:]); err != nil {
+			log.Fatalf("Admin command failed: %v", err)
+		}
+		return
+	}
+
+	// Share rate limits, caches and locks between instances through Redis when it is configured
+	var redisClient *redis.Client
+	if cfg.Redis.URL != "" {
+		redisClient, err = redis.NewFromURL(cfg.Redis.URL, cfg.Redis.PoolSize)
+		if err != nil {
+			log.Fatalf("Failed to configure Redis: %v", err)
+		}
+		defer redisClient.Close()
+		if err := redisClient.Ping(context.Background()); err != nil {
+			log.Printf("Redis is unreachable, limits and caches will catch up once it is back: %v", err)
+		}
 	}
-	log.Println("Database schema applied successfully")
 
 	// Create repositories
 	userRepo := impl.NewUserRepository(database)
-	plantRepo := impl.NewPlantRepository(database)
+	var plantRepo repository.PlantRepository = impl.NewPlantRepository(database)
+	var plantSpeciesRepo repository.PlantSpeciesRepository = impl.NewPlantSpeciesRepository(database)
+	var plantEnrichmentRepo repository.PlantEnrichmentRepository = impl.NewPlantEnrichmentRepository(database)
+	var plantReviewRepo repository.PlantReviewRepository = impl.NewPlantReviewRepository(database)
+	var plantTaxonomyRepo repository.PlantTaxonomyRepository = impl.NewPlantTaxonomyRepository(database)
+	var plantCache cache.Cache
+	if cfg.PlantCache.TTLSeconds > 0 {
+		plantCache = cache.New(redisClient, "planter:plants:", time.Duration(cfg.PlantCache.TTLSeconds)*time.Second, cfg.PlantCache.Size)
+		plantRepo = impl.NewCachedPlantRepository(plantRepo, plantCache)
+		plantSpeciesRepo = impl.NewCachedPlantSpeciesRepository(plantSpeciesRepo, plantCache)
+		plantEnrichmentRepo = impl.NewCachedPlantEnrichmentRepository(plantEnrichmentRepo, plantCache)
+		plantReviewRepo = impl.NewCachedPlantReviewRepository(plantReviewRepo, plantCache)
+		plantTaxonomyRepo = impl.NewCachedPlantTaxonomyRepository(plantTaxonomyRepo, plantCache)
+	}
+	userPlantPhotoRepo := impl.NewUserPlantPhotoRepository(database)
+	accountMergeRepo := impl.NewAccountMergeRepository(database)
+	plantStatsRepo := impl.NewPlantStatsRepository(database)
+	changelogRepo := impl.NewChangelogRepository(database)
 	shopRepo := impl.NewShopRepository(database)
 	recommendationRepo := impl.NewRecommendationRepository(database)
 	notificationRepo := impl.NewNotificationRepository(database)
+	notificationActionRepo := impl.NewNotificationActionRepository(database)
+	apiKeyRepo := impl.NewAPIKeyRepository(database)
+	funFactRepo := impl.NewFunFactRepository(database)
+	careTaskRepo := impl.NewCareTaskRepository(database)
+	notificationTemplateRepo := impl.NewNotificationTemplateRepository(database)
+	personalTokenRepo := impl.NewPersonalTokenRepository(database)
+	eventRepo := impl.NewEventRepository(database)
+	reconciliationRepo := impl.NewReconciliationRepository(database)
+	careFeedbackRepo := impl.NewCareFeedbackRepository(database)
+	diagnosisRepo := impl.NewDiagnosisRepository(database)
+	journalRepo := impl.NewJournalRepository(database)
+	plantEventRepo := impl.NewPlantEventRepository(database)
+	supportTicketRepo := impl.NewSupportTicketRepository(database)
+	anonymizationRepo := impl.NewAnonymizationRepository(database)
+	capturedRequestRepo := impl.NewCapturedRequestRepository(database)
+	carePlanRepo := impl.NewCarePlanRepository(database)
+	llmUsageRepo := impl.NewLLMUsageRepository(database)
+	userPlantTaskRepo := impl.NewUserPlantTaskRepository(database)
+	plantReminderRepo := impl.NewPlantReminderRepository(database)
+	auditTrail := audit.NewTrail(impl.NewAuditRepository(database))
+	sagaOrchestrator := saga.NewOrchestrator(impl.NewSagaRepository(database))
+	plantAvailabilityRepo := impl.NewPlantAvailabilityRepository(database)
 
 	// Create auth middleware
 	auth := middleware.NewAuth(cfg.Auth.JWTSecret)
@@ -48,7 +144,29 @@ func main() {
 	// Create services
 	authService := services.NewAuthService(userRepo, auth)
 	userService := services.NewUserService(userRepo)
+	userService.SetAccountMergeRepository(accountMergeRepo)
+	userService.SetAuditTrail(auditTrail)
+	userService.BootstrapAdmins(context.Background(), cfg.Auth.AdminEmails)
 	plantService := services.NewPlantService(plantRepo)
+	plantService.SetSpeciesRepository(plantSpeciesRepo)
+	plantService.SetPhotoRepository(userPlantPhotoRepo)
+	plantService.SetTranslationRepository(impl.NewPlantTranslationRepository(database))
+	plantService.SetReviewRepository(plantReviewRepo)
+	plantService.SetTaxonomyRepository(plantTaxonomyRepo)
+	enrichmentCfg := cfg.Enrichment
+	enrichmentProviders, err := services.NewPlantEnrichmentProviders(enrichmentCfg.Sources, enrichmentCfg.UserAgent)
+	if err != nil {
+		log.Fatalf("Failed to configure plant enrichment: %v", err)
+	}
+	plantEnrichmentService := services.NewPlantEnrichmentService(plantEnrichmentRepo, enrichmentProviders...)
+	if cfg.Storage.UploadDir != "" {
+		plantService.SetObjectStore(storage.NewDirectoryStore(cfg.Storage.UploadDir))
+	}
+	imageModerator, err := services.NewImageModerator(cfg.Moderation.Provider, cfg.Vision.APIKey, cfg.Vision.FolderID, cfg.Moderation.PlantModel)
+	if err != nil {
+		log.Fatalf("Failed to configure photo moderation: %v", err)
+	}
+	plantService.SetImageModerator(imageModerator)
 	shopService := services.NewShopService(shopRepo)
 	recommendationService := services.NewRecommendationService(
 		recommendationRepo,
@@ -56,14 +174,259 @@ func main() {
 		cfg.YandexGPT.APIKey,
 		cfg.YandexGPT.Model,
 	)
-	notificationService := services.NewNotificationService(notificationRepo, plantRepo)
+
+	// Track the Yandex GPT spend and quotas and stop calling the API while it keeps failing
+	llmBudgetService := services.NewLLMBudgetService(
+		llmUsageRepo,
+		cfg.LLMBudget.MonthlyBudget,
+		cfg.LLMBudget.PricePer1KTokens,
+		cfg.LLMBudget.UserMonthlyQuota,
+		cfg.LLMBudget.BreakerThreshold,
+		time.Duration(cfg.LLMBudget.BreakerCooldown)*time.Second,
+	)
+	recommendationService.SetLLMBudget(llmBudgetService)
+	recommendationService.SetChatContextCache(cfg.Chat.ContextCacheSize, time.Duration(cfg.Chat.ContextIdleHours)*time.Hour)
+
+	// Scrub personal data and blocked words from chat messages before they are sent to Yandex GPT
+	var chatScrubber *services.ChatScrubber
+	if cfg.Chat.ScrubEnabled {
+		var patterns []string
+		if cfg.Chat.ScrubPatternsFile != "" {
+			patterns, err = services.LoadChatScrubPatterns(cfg.Chat.ScrubPatternsFile)
+			if err != nil {
+				log.Fatalf("Failed to configure chat scrubbing: %v", err)
+			}
+		}
+		chatScrubber, err = services.NewChatScrubber(patterns, cfg.Chat.BlockedWords)
+		if err != nil {
+			log.Fatalf("Failed to configure chat scrubbing: %v", err)
+		}
+		recommendationService.SetChatScrubber(chatScrubber)
+	}
+
+	// Score recommendations with the configured engine and criteria weights
+	recommendationService.SetRecommendationWeights(services.RecommendationWeights{
+		Sunlight:    cfg.Recommendations.SunlightWeight,
+		CareLevel:   cfg.Recommendations.CareLevelWeight,
+		PetFriendly: cfg.Recommendations.PetFriendlyWeight,
+		Location:    cfg.Recommendations.LocationWeight,
+		Tags:        cfg.Recommendations.TagsWeight,
+	})
+	recommendationEngine, err := services.NewRecommendationEngine(cfg.Recommendations.Engine, recommendationService, cfg.Recommendations.HybridLLMShare)
+	if err != nil {
+		log.Fatalf("Failed to configure the recommendation engine: %v", err)
+	}
+	recommendationService.SetRecommendationEngine(recommendationEngine)
+
+	var publicRateLimiter middleware.Limiter = middleware.NewRateLimiter(cfg.PublicAPI.RateLimit, time.Minute)
+	if redisClient != nil {
+		recommendationService.SetRedis(redisClient)
+		publicRateLimiter = middleware.NewRedisRateLimiter(redisClient, "planter:ratelimit:public:", cfg.PublicAPI.RateLimit, time.Minute)
+	}
+
+	notificationTemplateService := services.NewNotificationTemplateService(notificationTemplateRepo)
+	notificationService := services.NewNotificationService(notificationRepo, plantRepo, userPlantTaskRepo, notificationTemplateService)
+	notificationService.SetReminderRepository(plantReminderRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	personalTokenService := services.NewPersonalTokenService(personalTokenRepo)
+	analyticsService := services.NewAnalyticsService(eventRepo, 10*time.Second)
+	reconciliationService := services.NewReconciliationService(reconciliationRepo)
+	careFeedbackService := services.NewCareFeedbackService(careFeedbackRepo, plantRepo, notificationService)
+	plantStatsService := services.NewPlantStatsService(plantStatsRepo)
+	changelogService := services.NewChangelogService(changelogRepo)
+	homeService := services.NewHomeService(impl.NewHomeRepository(database))
+	plantExportService := services.NewPlantExportService(impl.NewPlantExportRepository(database))
+	seasonalGuideService := services.NewSeasonalGuideService(impl.NewSeasonalGuideRepository(database), plantRepo)
+	seasonalGuideService.SetCache(cache.New(redisClient, "planter:seasonal-guide:", services.SeasonalGuideCacheTTL, 64))
+	supportService := services.NewSupportService(supportTicketRepo, plantRepo, userRepo, notificationService)
+	chatEscalationService := services.NewChatEscalationService(recommendationRepo, userRepo, notificationService)
+	plantAvailabilityService := services.NewPlantAvailabilityService(plantAvailabilityRepo, plantRepo, shopRepo, notificationService)
+
+	// Owners of dormant accounts are warned by email, so accounts are anonymized only when SMTP is configured;
+	// watering reminders go by email only then too
+	// Watering notifications carry a token marking the plant watered without signing in
+	notificationService.SetActionTokens(notificationActionRepo, cfg.Auth.JWTSecret,
+		time.Duration(cfg.Reminders.ActionTokenTTL)*time.Hour, cfg.Reminders.ActionURL)
+	var mailer services.Mailer
+	if cfg.SMTP.Host != "" {
+		smtpMailer, err := services.NewSMTPMailer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
+		if err != nil {
+			log.Fatalf("Failed to configure SMTP: %v", err)
+		}
+		mailer = smtpMailer
+		notificationService.SetEmailSender(services.NewEmailSender(mailer), cfg.Reminders.EmailHour)
+	}
+	anonymizationService := services.NewAnonymizationService(anonymizationRepo, mailer, cfg.Retention.InactiveDays, cfg.Retention.WarningDays)
+	auth.SetActivityRecorder(anonymizationService)
+
+	// Requests answered with a server error are captured; they can be replayed only when a staging target is configured
+	requestCaptureService := services.NewRequestCaptureService(capturedRequestRepo, cfg.Capture.Enabled, cfg.Capture.MaxBodyBytes, cfg.Capture.RetentionDays)
+	if cfg.Capture.ReplayTargetURL != "" {
+		if cfg.Capture.ReplayJWTSecret == "" {
+			log.Fatalf("REPLAY_TARGET_JWT_SECRET is required when REPLAY_TARGET_URL is set")
+		}
+		if err := requestCaptureService.SetReplayTarget(cfg.Capture.ReplayTargetURL, middleware.NewAuth(cfg.Capture.ReplayJWTSecret)); err != nil {
+			log.Fatalf("Failed to configure request replay: %v", err)
+		}
+	}
+
+	// Photo diagnosis is available only when a vision provider is configured
+	var diagnosisProvider services.DiagnosisProvider
+	if cfg.Vision.APIKey != "" {
+		diagnosisProvider = services.NewYandexVisionProvider(cfg.Vision.APIKey, cfg.Vision.FolderID, cfg.Vision.Model)
+	}
+	diagnosisService := services.NewDiagnosisService(diagnosisRepo, plantRepo, diagnosisProvider)
+
+	// Photos can be attached to chat messages when uploads are stored; the vision provider describes them
+	if cfg.Storage.UploadDir != "" {
+		recommendationService.SetChatAttachments(storage.NewDirectoryStore(cfg.Storage.UploadDir), diagnosisProvider)
+	}
+
+	// Shops can be imported only when a geocoder is configured
+	if cfg.Geocoder.APIKey != "" {
+		shopService.SetGeocoder(services.NewYandexGeocoder(cfg.Geocoder.APIKey))
+	}
+	triageService := services.NewTriageService(journalRepo, plantRepo, recommendationService)
+	carePlanService := services.NewCarePlanService(carePlanRepo, plantRepo, notificationService, recommendationService)
+	demoService := services.NewDemoService(userRepo, plantRepo, cfg.Demo.AccountEmail)
+	funFactService := services.NewFunFactService(funFactRepo, plantRepo, recommendationService)
+	careTaskService := services.NewCareTaskService(plantRepo, careTaskRepo, carePlanRepo, userPlantTaskRepo)
+	plantService.SetCareScheduler(careTaskService)
+
+	// New plants can be identified from photos only when Pl@ntNet is configured
+	var plantIdentifier services.PlantIdentificationProvider
+	if cfg.Identification.APIKey != "" {
+		plantIdentifier = services.NewPlantNetProvider(cfg.Identification.APIKey, cfg.Identification.Project)
+	}
+	plantOnboardingService := services.NewPlantOnboardingService(plantRepo, plantService, careTaskService, plantIdentifier)
+	lowEffortService := services.NewLowEffortService(plantRepo, userRepo)
+
+	// Watering reminders of plants in outdoor locations follow the weather when OpenWeather is configured
+	weatherService := services.NewWeatherService(impl.NewOutdoorLocationRepository(database), cfg.Weather.RainSkipMM, cfg.Weather.HeatAdvance)
+	if cfg.Weather.APIKey != "" {
+		weatherService.SetProvider(services.NewOpenWeatherProvider(cfg.Weather.APIKey))
+	}
+	notificationService.SetWeatherService(weatherService)
+
+	// New notifications are pushed to the clients connected to /ws/notifications
+	notificationHub := ws.NewHub()
+	notificationService.SetNotificationStream(notificationHub)
+
+	// New users get a sample plant and the welcome campaign when configured; campaigns already
+	// started are finished either way
+	campaignService := services.NewCampaignService(impl.NewCampaignRepository(database), notificationService)
+	var samplePlantID *uuid.UUID
+	if cfg.Welcome.SamplePlantID != "" {
+		id, err := uuid.Parse(cfg.Welcome.SamplePlantID)
+		if err != nil {
+			log.Fatalf("Invalid WELCOME_SAMPLE_PLANT_ID: %v", err)
+		}
+		samplePlantID = &id
+	}
+	var welcomeCampaigns *services.CampaignService
+	if cfg.Welcome.CampaignEnabled {
+		welcomeCampaigns = campaignService
+	}
+	if samplePlantID != nil || welcomeCampaigns != nil {
+		authService.SetWelcomer(services.NewWelcomeService(plantService, welcomeCampaigns, samplePlantID))
+	}
+
+	// Waterings, fertilizings, repottings, moves and photos go into each plant's event stream
+	plantEventService := services.NewPlantEventService(plantEventRepo, plantRepo)
+	plantService.SetPlantEventRecorder(plantEventService)
+	careTaskService.SetPlantEventRecorder(plantEventService)
+	diagnosisService.SetPlantEventRecorder(plantEventService)
+
+	// Moving house reassigns rooms and postpones demanding care while plants settle in
+	plantMoveService := services.NewPlantMoveService(plantRepo, userPlantTaskRepo)
+	plantMoveService.SetPlantEventRecorder(plantEventService)
+	clientConfigService := services.NewClientConfigService(
+		cfg.Client.MinAppVersion,
+		cfg.Client.LatestAppVersion,
+		cfg.Client.FeatureFlags,
+	)
+
+	// Publish domain events on the in-process bus; consumers subscribe to it and
+	// external brokers can be attached with bus.SubscribeAll(events.Forward(...))
+	eventBus := events.NewInProcessBus()
+	defer eventBus.Close()
+	authService.SetEventPublisher(eventBus)
+	plantService.SetEventPublisher(eventBus)
+	notificationService.SetEventPublisher(eventBus)
+	recommendationService.SetEventPublisher(eventBus)
+	plantEventService.SetEventPublisher(eventBus)
+	shopService.SetEventPublisher(eventBus)
+	eventBus.Subscribe(events.InventoryChangedEvent, plantAvailabilityService.HandleInventoryChanged)
+
+	// Check the Yandex GPT configuration in the background so problems show up in the logs at startup
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		status := recommendationService.SelfTestYandexGPT(ctx)
+		switch status.Status {
+		case models.LLMStatusOK:
+			log.Printf("Yandex GPT self-test passed in %d ms", status.LatencyMs)
+		case models.LLMStatusDisabled:
+			log.Printf("Yandex GPT is disabled: %s", status.Hint)
+		default:
+			log.Printf("Yandex GPT self-test failed (%s): %s; %s", status.Status, status.Error, status.Hint)
+		}
+	}()
 
 	// Create and start background jobs
-	log.Println("Initializing watering notifications job...")
-	wateringJob := jobs.NewWateringNotificationsJob(notificationService, 1*time.Minute)
-	wateringJob.Start()
-	defer wateringJob.Stop()
-	log.Println("Watering notifications job started successfully")
+	log.Println("Initializing care notifications job...")
+	careNotificationsJob := jobs.NewCareNotificationsJob(notificationService, 1*time.Minute)
+	careNotificationsJob.SetDryRun(cfg.Reminders.DryRun)
+	careNotificationsJob.Start()
+	log.Println("Care notifications job started successfully")
+
+	// Start writing buffered analytics events
+	analyticsService.Start()
+	defer analyticsService.Stop()
+
+	// Repair inconsistent derived data every night at 03:00
+	reconciliationJob := jobs.NewReconciliationJob(reconciliationService, 3)
+	reconciliationJob.Start()
+	defer reconciliationJob.Stop()
+
+	// Aggregate the ownership statistics shown on plant pages every night at 02:00
+	plantStatsJob := jobs.NewPlantStatsJob(plantStatsService, 2)
+	plantStatsJob.Start()
+	defer plantStatsJob.Stop()
+
+	// Warn the owners of dormant accounts and anonymize the accounts that stay inactive every night at 04:00
+	if cfg.Retention.InactiveDays > 0 {
+		anonymizationJob := jobs.NewAnonymizationJob(anonymizationService, 4)
+		anonymizationJob.Start()
+		defer anonymizationJob.Stop()
+	}
+
+	// Ask owners about plant difficulty and recalibrate the community difficulty every 6 hours
+	careCalibrationJob := jobs.NewCareCalibrationJob(careFeedbackService, 6*time.Hour)
+	careCalibrationJob.Start()
+	defer careCalibrationJob.Stop()
+
+	// Remind owners when a repotting, fertilizing or dormancy window of a care plan begins
+	carePlanReminderJob := jobs.NewCarePlanReminderJob(carePlanService, 1*time.Hour)
+	carePlanReminderJob.Start()
+	defer carePlanReminderJob.Stop()
+
+	// Send the steps of campaigns, such as the welcome notifications of new users, once they are due
+	campaignJob := jobs.NewCampaignJob(campaignService, 1*time.Hour)
+	campaignJob.Start()
+	defer campaignJob.Stop()
+
+	// Broadcast the announcements of admins to their segments in batches
+	announcementService := services.NewAnnouncementService(impl.NewAnnouncementRepository(database), notificationService)
+	announcementJob := jobs.NewAnnouncementJob(announcementService, 1*time.Minute)
+	announcementJob.Start()
+	defer announcementJob.Stop()
+
+	// Daily care quiz, leaderboards among followed users and badges
+	badgeService := services.NewBadgeService(impl.NewBadgeRepository(database), notificationService)
+	quizService := services.NewQuizService(impl.NewQuizRepository(database), plantRepo, badgeService)
+	followService := services.NewFollowService(impl.NewFollowRepository(database), userRepo)
 
 	// Create API
 	api := api.New(
@@ -73,13 +436,77 @@ func main() {
 		shopService,
 		recommendationService,
 		notificationService,
+		apiKeyService,
+		clientConfigService,
+		funFactService,
+		careTaskService,
+		notificationTemplateService,
+		personalTokenService,
+		analyticsService,
+		demoService,
+		reconciliationService,
+		careFeedbackService,
+		diagnosisService,
+		triageService,
+		carePlanService,
+		llmBudgetService,
+		plantEventService,
+		supportService,
+		anonymizationService,
+		requestCaptureService,
+		chatEscalationService,
+		plantAvailabilityService,
 		auth,
+		publicRateLimiter,
 	)
+	if redisClient != nil {
+		api.SetRedis(redisClient)
+	}
+	if plantCache != nil {
+		api.SetPlantCache(plantCache)
+	}
+	api.SetQueryMetrics(database.QueryMetrics())
+	if chatScrubber != nil {
+		api.SetChatScrubber(chatScrubber)
+	}
+	api.SetPlantEnrichmentService(plantEnrichmentService)
+	api.SetPlantOnboardingService(plantOnboardingService)
+	api.SetLowEffortService(lowEffortService)
+	api.SetWeatherService(weatherService)
+	api.SetNotificationHub(notificationHub)
+	api.SetAnnouncementService(announcementService)
+	api.SetQuizService(quizService)
+	api.SetFollowService(followService)
+	api.SetBadgeService(badgeService)
+	api.SetPlantMoveService(plantMoveService)
+	api.SetPlantStatsService(plantStatsService)
+	api.SetChangelogService(changelogService)
+	api.SetHomeService(homeService)
+	api.SetPlantExportService(plantExportService)
+	api.SetSeasonalGuideService(seasonalGuideService)
+	api.SetPlantReminderService(services.NewPlantReminderService(plantReminderRepo, plantRepo))
+	api.SetAuditTrail(auditTrail)
+	api.SetSagaOrchestrator(sagaOrchestrator)
+	if cfg.Storage.UploadDir != "" && cfg.Storage.ServeUploads {
+		api.SetAssets(storage.NewFileServer(cfg.Storage.UploadDir))
+	}
+
+	// Serve until SIGINT or SIGTERM, then drain in-flight requests
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Start the API server
 	log.Printf("Starting server on port %s", cfg.Server.Port)
-	err = api.Start(cfg)
-	if err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	serveErr := api.Start(ctx, cfg)
+
+	// Let a running care notifications check finish before the database is closed
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	defer cancel()
+	if err := careNotificationsJob.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Care notifications job did not stop in time: %v", err)
+	}
+
+	if serveErr != nil {
+		log.Fatalf("Server failed: %v", serveErr)
 	}
+	log.Println("Server stopped")
 }
\ No newline at end of file
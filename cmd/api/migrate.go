@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/db/migrations"
+	"github.com/anpanovv/planter/internal/storage"
+)
+
+const migrateUsage = "usage: planter-api migrate up | down [steps] | status | backfill-renames | storage-keys"
+
+// runMigrate executes the migrate subcommand: up, down [steps], status, backfill-renames or storage-keys
+func runMigrate(database *db.DB, assets *storage.Storage, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	migrator, err := migrations.New(database)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+
+	case "down":
+		// Roll back a single migration unless told otherwise
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rolled back %d migration(s)\n", rolledBack)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d  %-40s  %s\n", status.Version, status.Name, appliedAt)
+		}
+
+	case "backfill-renames":
+		// Copy values written by instances that did not dual-write yet
+		updated, err := database.BackfillRenames(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backfilled %d row(s)\n", updated)
+
+	case "storage-keys":
+		// Replace the stored URLs of assets with their keys
+		updated, err := database.RewriteStorageURLs(ctx, assets.BaseURLs())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rewrote %d row(s)\n", updated)
+
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
@@ -1,17 +1,30 @@
 package main
 
 import (
+	"context"
 	"log"
-	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/anpanovv/planter/internal/api"
+	"github.com/anpanovv/planter/internal/audit"
+	"github.com/anpanovv/planter/internal/cache"
+	"github.com/anpanovv/planter/internal/config"
 	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/events"
 	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/redis"
+	"github.com/anpanovv/planter/internal/repository"
 	"github.com/anpanovv/planter/internal/repository/impl"
+	"github.com/anpanovv/planter/internal/saga"
 	"github.com/anpanovv/planter/internal/jobs"
 	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/storage"
+	"github.com/anpanovv/planter/internal/ws"
+	"github.com/google/uuid"
 )
 
 func init() {
@@ -29,36 +42,351 @@ func main() {
 	}
 	defer database.Close()
 
+	// Resolve asset keys to URLs under the CDN base URL when responses are written
+	storageCfg := config.Load().Storage
+	storage.SetDefault(storage.New(storageCfg.BaseURL, storageCfg.LegacyBaseURLs))
+
+	// Share rate limits, caches and locks between instances through Redis when it is configured
+	var redisClient *redis.Client
+	redisCfg := config.Load().Redis
+	if redisCfg.URL != "" {
+		redisClient, err = redis.NewFromURL(redisCfg.URL, redisCfg.PoolSize)
+		if err != nil {
+			log.Fatalf("Failed to configure Redis: %v", err)
+		}
+		defer redisClient.Close()
+		if err := redisClient.Ping(context.Background()); err != nil {
+			log.Printf("Redis is unreachable, limits and caches will catch up once it is back: %v", err)
+		}
+	}
+
 	// Create repositories
 	userRepo := impl.NewUserRepository(database)
-	plantRepo := impl.NewPlantRepository(database)
+	var plantRepo repository.PlantRepository = impl.NewPlantRepository(database)
+	var plantSpeciesRepo repository.PlantSpeciesRepository = impl.NewPlantSpeciesRepository(database)
+	var plantEnrichmentRepo repository.PlantEnrichmentRepository = impl.NewPlantEnrichmentRepository(database)
+	var plantReviewRepo repository.PlantReviewRepository = impl.NewPlantReviewRepository(database)
+	var plantTaxonomyRepo repository.PlantTaxonomyRepository = impl.NewPlantTaxonomyRepository(database)
+	var plantCache cache.Cache
+	if plantCacheCfg := config.Load().PlantCache; plantCacheCfg.TTLSeconds > 0 {
+		plantCache = cache.New(redisClient, "planter:plants:", time.Duration(plantCacheCfg.TTLSeconds)*time.Second, plantCacheCfg.Size)
+		plantRepo = impl.NewCachedPlantRepository(plantRepo, plantCache)
+		plantSpeciesRepo = impl.NewCachedPlantSpeciesRepository(plantSpeciesRepo, plantCache)
+		plantEnrichmentRepo = impl.NewCachedPlantEnrichmentRepository(plantEnrichmentRepo, plantCache)
+		plantReviewRepo = impl.NewCachedPlantReviewRepository(plantReviewRepo, plantCache)
+		plantTaxonomyRepo = impl.NewCachedPlantTaxonomyRepository(plantTaxonomyRepo, plantCache)
+	}
+	userPlantPhotoRepo := impl.NewUserPlantPhotoRepository(database)
+	accountMergeRepo := impl.NewAccountMergeRepository(database)
+	plantStatsRepo := impl.NewPlantStatsRepository(database)
+	changelogRepo := impl.NewChangelogRepository(database)
 	shopRepo := impl.NewShopRepository(database)
 	notificationRepo := impl.NewNotificationRepository(database)
+	notificationActionRepo := impl.NewNotificationActionRepository(database)
+	apiKeyRepo := impl.NewAPIKeyRepository(database)
+	funFactRepo := impl.NewFunFactRepository(database)
+	careTaskRepo := impl.NewCareTaskRepository(database)
+	notificationTemplateRepo := impl.NewNotificationTemplateRepository(database)
+	personalTokenRepo := impl.NewPersonalTokenRepository(database)
+	eventRepo := impl.NewEventRepository(database)
+	reconciliationRepo := impl.NewReconciliationRepository(database)
+	careFeedbackRepo := impl.NewCareFeedbackRepository(database)
+	diagnosisRepo := impl.NewDiagnosisRepository(database)
+	journalRepo := impl.NewJournalRepository(database)
+	plantEventRepo := impl.NewPlantEventRepository(database)
+	supportTicketRepo := impl.NewSupportTicketRepository(database)
+	capturedRequestRepo := impl.NewCapturedRequestRepository(database)
+	anonymizationRepo := impl.NewAnonymizationRepository(database)
+	carePlanRepo := impl.NewCarePlanRepository(database)
+	llmUsageRepo := impl.NewLLMUsageRepository(database)
+	userPlantTaskRepo := impl.NewUserPlantTaskRepository(database)
+	plantReminderRepo := impl.NewPlantReminderRepository(database)
+	auditTrail := audit.NewTrail(impl.NewAuditRepository(database))
+	sagaOrchestrator := saga.NewOrchestrator(impl.NewSagaRepository(database))
+	plantAvailabilityRepo := impl.NewPlantAvailabilityRepository(database)
 
 	// Create services
 	userService := services.NewUserService(userRepo)
+	userService.SetAccountMergeRepository(accountMergeRepo)
+	userService.SetAuditTrail(auditTrail)
+	userService.BootstrapAdmins(context.Background(), config.Load().Auth.AdminEmails)
 	plantService := services.NewPlantService(plantRepo)
+	plantService.SetSpeciesRepository(plantSpeciesRepo)
+	plantService.SetPhotoRepository(userPlantPhotoRepo)
+	plantService.SetTranslationRepository(impl.NewPlantTranslationRepository(database))
+	plantService.SetReviewRepository(plantReviewRepo)
+	plantService.SetTaxonomyRepository(plantTaxonomyRepo)
+	enrichmentCfg := config.Load().Enrichment
+	enrichmentProviders, err := services.NewPlantEnrichmentProviders(enrichmentCfg.Sources, enrichmentCfg.UserAgent)
+	if err != nil {
+		log.Fatalf("Failed to configure plant enrichment: %v", err)
+	}
+	plantEnrichmentService := services.NewPlantEnrichmentService(plantEnrichmentRepo, enrichmentProviders...)
+	if storageCfg.UploadDir != "" {
+		plantService.SetObjectStore(storage.NewDirectoryStore(storageCfg.UploadDir))
+	}
+	moderationCfg, visionCfg := config.Load().Moderation, config.Load().Vision
+	imageModerator, err := services.NewImageModerator(moderationCfg.Provider, visionCfg.APIKey, visionCfg.FolderID, moderationCfg.PlantModel)
+	if err != nil {
+		log.Fatalf("Failed to configure photo moderation: %v", err)
+	}
+	plantService.SetImageModerator(imageModerator)
 	shopService := services.NewShopService(shopRepo)
-	notificationService := services.NewNotificationService(notificationRepo, plantRepo)
+	notificationTemplateService := services.NewNotificationTemplateService(notificationTemplateRepo)
+	notificationService := services.NewNotificationService(notificationRepo, plantRepo, userPlantTaskRepo, notificationTemplateService)
+	notificationService.SetReminderRepository(plantReminderRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo)
+	personalTokenService := services.NewPersonalTokenService(personalTokenRepo)
+	analyticsService := services.NewAnalyticsService(eventRepo, 10*time.Second)
+	reconciliationService := services.NewReconciliationService(reconciliationRepo)
+	careFeedbackService := services.NewCareFeedbackService(careFeedbackRepo, plantRepo, notificationService)
+	plantStatsService := services.NewPlantStatsService(plantStatsRepo)
+	changelogService := services.NewChangelogService(changelogRepo)
+	homeService := services.NewHomeService(impl.NewHomeRepository(database))
+	plantExportService := services.NewPlantExportService(impl.NewPlantExportRepository(database))
+	seasonalGuideService := services.NewSeasonalGuideService(impl.NewSeasonalGuideRepository(database), plantRepo)
+	seasonalGuideService.SetCache(cache.New(redisClient, "planter:seasonal-guide:", services.SeasonalGuideCacheTTL, 64))
+	supportService := services.NewSupportService(supportTicketRepo, plantRepo, userRepo, notificationService)
+
+	// Photo diagnosis is available only when a vision provider is configured
+	var diagnosisProvider services.DiagnosisProvider
+	if visionCfg := config.Load().Vision; visionCfg.APIKey != "" {
+		diagnosisProvider = services.NewYandexVisionProvider(visionCfg.APIKey, visionCfg.FolderID, visionCfg.Model)
+	}
+	diagnosisService := services.NewDiagnosisService(diagnosisRepo, plantRepo, diagnosisProvider)
+
+	// Shops can be imported only when a geocoder is configured
+	if geocoderCfg := config.Load().Geocoder; geocoderCfg.APIKey != "" {
+		shopService.SetGeocoder(services.NewYandexGeocoder(geocoderCfg.APIKey))
+	}
+	demoService := services.NewDemoService(userRepo, plantRepo, config.Load().Demo.AccountEmail)
+	clientCfg := config.Load().Client
+	clientConfigService := services.NewClientConfigService(
+		clientCfg.MinAppVersion,
+		clientCfg.LatestAppVersion,
+		clientCfg.FeatureFlags,
+	)
+
+	// Publish domain events on the in-process bus; consumers subscribe to it and
+	// external brokers can be attached with bus.SubscribeAll(events.Forward(...))
+	eventBus := events.NewInProcessBus()
+	defer eventBus.Close()
+	plantService.SetEventPublisher(eventBus)
+	notificationService.SetEventPublisher(eventBus)
+	shopService.SetEventPublisher(eventBus)
+
+	// Users waiting for a plant in their city are notified when a shop there stocks it
+	plantAvailabilityService := services.NewPlantAvailabilityService(plantAvailabilityRepo, plantRepo, shopRepo, notificationService)
+	eventBus.Subscribe(events.InventoryChangedEvent, plantAvailabilityService.HandleInventoryChanged)
 
 	// Create and start background jobs
-	log.Println("Initializing watering notifications job...")
-	wateringJob := jobs.NewWateringNotificationsJob(notificationService, 1*time.Minute)
-	wateringJob.Start()
-	log.Println("Watering notifications job started successfully")
-	defer wateringJob.Stop()
+	log.Println("Initializing care notifications job...")
+	careNotificationsJob := jobs.NewCareNotificationsJob(notificationService, 1*time.Minute)
+	careNotificationsJob.SetDryRun(config.Load().Reminders.DryRun)
+	careNotificationsJob.Start()
+	log.Println("Care notifications job started successfully")
+
+	// Start writing buffered analytics events
+	analyticsService.Start()
+	defer analyticsService.Stop()
+
+	// Repair inconsistent derived data every night at 03:00
+	reconciliationJob := jobs.NewReconciliationJob(reconciliationService, 3)
+	reconciliationJob.Start()
+	defer reconciliationJob.Stop()
+
+	// Aggregate the ownership statistics shown on plant pages every night at 02:00
+	plantStatsJob := jobs.NewPlantStatsJob(plantStatsService, 2)
+	plantStatsJob.Start()
+	defer plantStatsJob.Stop()
+
+	// Ask owners about plant difficulty and recalibrate the community difficulty every 6 hours
+	careCalibrationJob := jobs.NewCareCalibrationJob(careFeedbackService, 6*time.Hour)
+	careCalibrationJob.Start()
+	defer careCalibrationJob.Stop()
 
 	// Create auth middleware first
 	authMiddleware := middleware.NewAuth("development-secret-key") // TODO: Replace with config value
 	
 	// Create additional services
 	authService := services.NewAuthService(userRepo, authMiddleware)
+
+	// Owners of dormant accounts are warned by email, so accounts are anonymized only when SMTP is configured;
+	// watering reminders go by email only then too
+	// Watering notifications carry a token marking the plant watered without signing in
+	remindersCfg := config.Load().Reminders
+	notificationService.SetActionTokens(notificationActionRepo, config.Load().Auth.JWTSecret,
+		time.Duration(remindersCfg.ActionTokenTTL)*time.Hour, remindersCfg.ActionURL)
+	var mailer services.Mailer
+	if smtpCfg := config.Load().SMTP; smtpCfg.Host != "" {
+		smtpMailer, err := services.NewSMTPMailer(smtpCfg.Host, smtpCfg.Port, smtpCfg.Username, smtpCfg.Password, smtpCfg.From)
+		if err != nil {
+			log.Fatalf("Failed to configure SMTP: %v", err)
+		}
+		mailer = smtpMailer
+		notificationService.SetEmailSender(services.NewEmailSender(mailer), config.Load().Reminders.EmailHour)
+	}
+	retentionCfg := config.Load().Retention
+	anonymizationService := services.NewAnonymizationService(anonymizationRepo, mailer, retentionCfg.InactiveDays, retentionCfg.WarningDays)
+	authMiddleware.SetActivityRecorder(anonymizationService)
+
+	// Requests answered with a server error are captured; they can be replayed only when a staging target is configured
+	captureCfg := config.Load().Capture
+	requestCaptureService := services.NewRequestCaptureService(capturedRequestRepo, captureCfg.Enabled, captureCfg.MaxBodyBytes, captureCfg.RetentionDays)
+	if captureCfg.ReplayTargetURL != "" {
+		if captureCfg.ReplayJWTSecret == "" {
+			log.Fatalf("REPLAY_TARGET_JWT_SECRET is required when REPLAY_TARGET_URL is set")
+		}
+		if err := requestCaptureService.SetReplayTarget(captureCfg.ReplayTargetURL, middleware.NewAuth(captureCfg.ReplayJWTSecret)); err != nil {
+			log.Fatalf("Failed to configure request replay: %v", err)
+		}
+	}
+
+	// Warn the owners of dormant accounts and anonymize the accounts that stay inactive every night at 04:00
+	if retentionCfg.InactiveDays > 0 {
+		anonymizationJob := jobs.NewAnonymizationJob(anonymizationService, 4)
+		anonymizationJob.Start()
+		defer anonymizationJob.Stop()
+	}
+	recommendationRepo := impl.NewRecommendationRepository(database)
 	recommendationService := services.NewRecommendationService(
-		impl.NewRecommendationRepository(database),
+		recommendationRepo,
 		plantRepo,
 		"", // yandexGPT API key
 		"", // yandexGPT model
 	)
+	llmBudgetCfg := config.Load().LLMBudget
+	llmBudgetService := services.NewLLMBudgetService(
+		llmUsageRepo,
+		llmBudgetCfg.MonthlyBudget,
+		llmBudgetCfg.PricePer1KTokens,
+		llmBudgetCfg.UserMonthlyQuota,
+		llmBudgetCfg.BreakerThreshold,
+		time.Duration(llmBudgetCfg.BreakerCooldown)*time.Second,
+	)
+	recommendationService.SetLLMBudget(llmBudgetService)
+	chatEscalationService := services.NewChatEscalationService(recommendationRepo, userRepo, notificationService)
+	chatCfg := config.Load().Chat
+	recommendationService.SetChatContextCache(chatCfg.ContextCacheSize, time.Duration(chatCfg.ContextIdleHours)*time.Hour)
+	var chatScrubber *services.ChatScrubber
+	if chatCfg.ScrubEnabled {
+		var patterns []string
+		if chatCfg.ScrubPatternsFile != "" {
+			patterns, err = services.LoadChatScrubPatterns(chatCfg.ScrubPatternsFile)
+			if err != nil {
+				log.Fatalf("Failed to configure chat scrubbing: %v", err)
+			}
+		}
+		chatScrubber, err = services.NewChatScrubber(patterns, chatCfg.BlockedWords)
+		if err != nil {
+			log.Fatalf("Failed to configure chat scrubbing: %v", err)
+		}
+		recommendationService.SetChatScrubber(chatScrubber)
+	}
+	recommendationsCfg := config.Load().Recommendations
+	recommendationService.SetRecommendationWeights(services.RecommendationWeights{
+		Sunlight:    recommendationsCfg.SunlightWeight,
+		CareLevel:   recommendationsCfg.CareLevelWeight,
+		PetFriendly: recommendationsCfg.PetFriendlyWeight,
+		Location:    recommendationsCfg.LocationWeight,
+		Tags:        recommendationsCfg.TagsWeight,
+	})
+	recommendationEngine, err := services.NewRecommendationEngine(recommendationsCfg.Engine, recommendationService, recommendationsCfg.HybridLLMShare)
+	if err != nil {
+		log.Fatalf("Failed to configure the recommendation engine: %v", err)
+	}
+	recommendationService.SetRecommendationEngine(recommendationEngine)
+
+	// Photos can be attached to chat messages when uploads are stored; the vision provider describes them
+	if storageCfg.UploadDir != "" {
+		recommendationService.SetChatAttachments(storage.NewDirectoryStore(storageCfg.UploadDir), diagnosisProvider)
+	}
+
+	var publicRateLimiter middleware.Limiter = middleware.NewRateLimiter(60, time.Minute)
+	if redisClient != nil {
+		recommendationService.SetRedis(redisClient)
+		publicRateLimiter = middleware.NewRedisRateLimiter(redisClient, "planter:ratelimit:public:", 60, time.Minute)
+	}
+
+	funFactService := services.NewFunFactService(funFactRepo, plantRepo, recommendationService)
+	triageService := services.NewTriageService(journalRepo, plantRepo, recommendationService)
+	carePlanService := services.NewCarePlanService(carePlanRepo, plantRepo, notificationService, recommendationService)
+	careTaskService := services.NewCareTaskService(plantRepo, careTaskRepo, carePlanRepo, userPlantTaskRepo)
+	plantService.SetCareScheduler(careTaskService)
+
+	// New plants can be identified from photos only when Pl@ntNet is configured
+	var plantIdentifier services.PlantIdentificationProvider
+	if identificationCfg := config.Load().Identification; identificationCfg.APIKey != "" {
+		plantIdentifier = services.NewPlantNetProvider(identificationCfg.APIKey, identificationCfg.Project)
+	}
+	plantOnboardingService := services.NewPlantOnboardingService(plantRepo, plantService, careTaskService, plantIdentifier)
+	lowEffortService := services.NewLowEffortService(plantRepo, userRepo)
+
+	// Watering reminders of plants in outdoor locations follow the weather when OpenWeather is configured
+	weatherCfg := config.Load().Weather
+	weatherService := services.NewWeatherService(impl.NewOutdoorLocationRepository(database), weatherCfg.RainSkipMM, weatherCfg.HeatAdvance)
+	if weatherCfg.APIKey != "" {
+		weatherService.SetProvider(services.NewOpenWeatherProvider(weatherCfg.APIKey))
+	}
+	notificationService.SetWeatherService(weatherService)
+
+	// New notifications are pushed to the clients connected to /ws/notifications
+	notificationHub := ws.NewHub()
+	notificationService.SetNotificationStream(notificationHub)
+
+	// New users get a sample plant and the welcome campaign when configured; campaigns already
+	// started are finished either way
+	campaignService := services.NewCampaignService(impl.NewCampaignRepository(database), notificationService)
+	welcomeCfg := config.Load().Welcome
+	var samplePlantID *uuid.UUID
+	if welcomeCfg.SamplePlantID != "" {
+		id, err := uuid.Parse(welcomeCfg.SamplePlantID)
+		if err != nil {
+			log.Fatalf("Invalid WELCOME_SAMPLE_PLANT_ID: %v", err)
+		}
+		samplePlantID = &id
+	}
+	var welcomeCampaigns *services.CampaignService
+	if welcomeCfg.CampaignEnabled {
+		welcomeCampaigns = campaignService
+	}
+	if samplePlantID != nil || welcomeCampaigns != nil {
+		authService.SetWelcomer(services.NewWelcomeService(plantService, welcomeCampaigns, samplePlantID))
+	}
+
+	// Waterings, fertilizings, repottings, moves and photos go into each plant's event stream
+	plantEventService := services.NewPlantEventService(plantEventRepo, plantRepo)
+	plantService.SetPlantEventRecorder(plantEventService)
+	careTaskService.SetPlantEventRecorder(plantEventService)
+	diagnosisService.SetPlantEventRecorder(plantEventService)
+
+	// Moving house reassigns rooms and postpones demanding care while plants settle in
+	plantMoveService := services.NewPlantMoveService(plantRepo, userPlantTaskRepo)
+	plantMoveService.SetPlantEventRecorder(plantEventService)
+	authService.SetEventPublisher(eventBus)
+	recommendationService.SetEventPublisher(eventBus)
+	plantEventService.SetEventPublisher(eventBus)
+
+	// Remind owners when a repotting, fertilizing or dormancy window of a care plan begins
+	carePlanReminderJob := jobs.NewCarePlanReminderJob(carePlanService, 1*time.Hour)
+	carePlanReminderJob.Start()
+	defer carePlanReminderJob.Stop()
+
+	// Send the steps of campaigns, such as the welcome notifications of new users, once they are due
+	campaignJob := jobs.NewCampaignJob(campaignService, 1*time.Hour)
+	campaignJob.Start()
+	defer campaignJob.Stop()
+
+	// Broadcast the announcements of admins to their segments in batches
+	announcementService := services.NewAnnouncementService(impl.NewAnnouncementRepository(database), notificationService)
+	announcementJob := jobs.NewAnnouncementJob(announcementService, 1*time.Minute)
+	announcementJob.Start()
+	defer announcementJob.Stop()
+
+	// Daily care quiz, leaderboards among followed users and badges
+	badgeService := services.NewBadgeService(impl.NewBadgeRepository(database), notificationService)
+	quizService := services.NewQuizService(impl.NewQuizRepository(database), plantRepo, badgeService)
+	followService := services.NewFollowService(impl.NewFollowRepository(database), userRepo)
 
 	// Create and start API server
 	apiHandler := api.New(
@@ -68,16 +396,78 @@ func main() {
 		shopService,
 		recommendationService,
 		notificationService,
+		apiKeyService,
+		clientConfigService,
+		funFactService,
+		careTaskService,
+		notificationTemplateService,
+		personalTokenService,
+		analyticsService,
+		demoService,
+		reconciliationService,
+		careFeedbackService,
+		diagnosisService,
+		triageService,
+		carePlanService,
+		llmBudgetService,
+		plantEventService,
+		supportService,
+		anonymizationService,
+		requestCaptureService,
+		chatEscalationService,
+		plantAvailabilityService,
 		authMiddleware,
+		publicRateLimiter,
 	)
+	if redisClient != nil {
+		apiHandler.SetRedis(redisClient)
+	}
+	if plantCache != nil {
+		apiHandler.SetPlantCache(plantCache)
+	}
+	apiHandler.SetQueryMetrics(database.QueryMetrics())
+	if chatScrubber != nil {
+		apiHandler.SetChatScrubber(chatScrubber)
+	}
+	apiHandler.SetPlantEnrichmentService(plantEnrichmentService)
+	apiHandler.SetPlantOnboardingService(plantOnboardingService)
+	apiHandler.SetLowEffortService(lowEffortService)
+	apiHandler.SetWeatherService(weatherService)
+	apiHandler.SetNotificationHub(notificationHub)
+	apiHandler.SetAnnouncementService(announcementService)
+	apiHandler.SetQuizService(quizService)
+	apiHandler.SetFollowService(followService)
+	apiHandler.SetBadgeService(badgeService)
+	apiHandler.SetPlantMoveService(plantMoveService)
+	apiHandler.SetPlantStatsService(plantStatsService)
+	apiHandler.SetChangelogService(changelogService)
+	apiHandler.SetHomeService(homeService)
+	apiHandler.SetPlantExportService(plantExportService)
+	apiHandler.SetSeasonalGuideService(seasonalGuideService)
+	apiHandler.SetPlantReminderService(services.NewPlantReminderService(plantReminderRepo, plantRepo))
+	apiHandler.SetAuditTrail(auditTrail)
+	apiHandler.SetSagaOrchestrator(sagaOrchestrator)
+	if storageCfg.UploadDir != "" && storageCfg.ServeUploads {
+		apiHandler.SetAssets(storage.NewFileServer(storageCfg.UploadDir))
+	}
 
-	server := &http.Server{
-		Addr:    ":8080",
-		Handler: apiHandler.Handler(),
+	// Serve until SIGINT or SIGTERM, then drain in-flight requests
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	log.Printf("Starting server on :%s", cfg.Server.Port)
+	serveErr := apiHandler.Start(ctx, cfg)
+
+	// Let a running care notifications check finish before the database is closed
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	defer cancel()
+	if err := careNotificationsJob.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Care notifications job did not stop in time: %v", err)
 	}
 
-	log.Println("Starting server on :8080")
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatal(err)
+	if serveErr != nil {
+		log.Fatal(serveErr)
 	}
+	log.Println("Server stopped")
 } 
\ No newline at end of file
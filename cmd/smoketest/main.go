@@ -0,0 +1,70 @@
+// Command smoketest runs the critical user journey against a deployed instance and exits with
+// a non-zero status when a step fails, so it can gate a deployment:
+//
+//	go run ./cmd/smoketest -url https://api.example.com -email smoke@example.com -password secret
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	baseURL := flag.String("url", "http://localhost:8080", "base URL of the instance")
+	email := flag.String("email", "", "email of the test account; a new account is registered when empty")
+	password := flag.String("password", "smoke-test-password", "password of the test account")
+	chat := flag.Bool("chat", true, "send a chat message; disable where Yandex GPT is stubbed out")
+	timeout := flag.Duration("timeout", 2*time.Minute, "timeout of the whole run")
+	jsonReport := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *email == "" {
+		*email = fmt.Sprintf("smoke+%d@example.com", time.Now().Unix())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	test := newSmokeTest(Options{
+		BaseURL:  *baseURL,
+		Email:    *email,
+		Password: *password,
+		Chat:     *chat,
+	}, &http.Client{Timeout: 30 * time.Second})
+	report := test.Run(ctx)
+
+	if *jsonReport {
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			log.Fatalf("Failed to write the report: %v", err)
+		}
+	} else {
+		printReport(report)
+	}
+
+	if !report.Passed {
+		os.Exit(1)
+	}
+}
+
+// printReport prints one line per step and the overall outcome
+func printReport(report *Report) {
+	fmt.Printf("Smoke test of %s\n", report.BaseURL)
+	for _, result := range report.Steps {
+		line := fmt.Sprintf("%-4s %-14s %6dms", result.Status, result.Name, result.Duration.Milliseconds())
+		if result.Detail != "" {
+			line += "  " + result.Detail
+		}
+		fmt.Println(line)
+	}
+	if report.Passed {
+		fmt.Println("PASSED")
+	} else {
+		fmt.Println("FAILED")
+	}
+}
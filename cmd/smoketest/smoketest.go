@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// errSkipped is returned by steps that cannot run because an earlier step failed or was disabled
+var errSkipped = errors.New("skipped")
+
+// StepStatus is the outcome of a smoke test step
+type StepStatus string
+
+const (
+	StepPassed  StepStatus = "PASS"
+	StepFailed  StepStatus = "FAIL"
+	StepSkipped StepStatus = "SKIP"
+)
+
+// StepResult describes the outcome of a smoke test step
+type StepResult struct {
+	Name     string        `json:"name"`
+	Status   StepStatus    `json:"status"`
+	Duration time.Duration `json:"durationNs"`
+	Detail   string        `json:"detail,omitempty"`
+}
+
+// Report describes the outcome of a smoke test run
+type Report struct {
+	BaseURL string        `json:"baseUrl"`
+	Passed  bool          `json:"passed"`
+	Steps   []*StepResult `json:"steps"`
+}
+
+// Options configures a smoke test run
+type Options struct {
+	BaseURL  string
+	Email    string
+	Password string
+	// Chat sends a message to the assistant; disable it where Yandex GPT is stubbed out or unbudgeted
+	Chat bool
+}
+
+// step is a check run against the deployed instance
+type step struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// smokeTest runs the critical user journey against a deployed instance. Steps share the state
+// they create, such as the token and the plant added to the collection.
+type smokeTest struct {
+	options Options
+	client  *http.Client
+
+	token   string
+	plantID uuid.UUID
+	added   bool
+}
+
+// newSmokeTest creates a new smoke test
+func newSmokeTest(options Options, client *http.Client) *smokeTest {
+	return &smokeTest{
+		options: options,
+		client:  client,
+	}
+}
+
+// steps lists the steps in the order they run
+func (s *smokeTest) steps() []step {
+	return []step{
+		{"readiness", s.checkReadiness},
+		{"register", s.register},
+		{"find plant", s.findPlant},
+		{"add plant", s.addPlant},
+		{"water plant", s.waterPlant},
+		{"questionnaire", s.fillQuestionnaire},
+		{"chat", s.chat},
+		{"notifications", s.checkNotifications},
+		{"remove plant", s.removePlant},
+	}
+}
+
+// Run runs every step and reports the outcome; a step that fails does not stop the ones that
+// do not depend on it
+func (s *smokeTest) Run(ctx context.Context) *Report {
+	report := &Report{BaseURL: s.options.BaseURL, Passed: true}
+	for _, st := range s.steps() {
+		start := time.Now()
+		err := st.run(ctx)
+		result := &StepResult{Name: st.name, Status: StepPassed, Duration: time.Since(start)}
+
+		switch {
+		case errors.Is(err, errSkipped):
+			result.Status = StepSkipped
+			result.Detail = strings.TrimPrefix(strings.TrimPrefix(err.Error(), errSkipped.Error()), ": ")
+		case err != nil:
+			result.Status = StepFailed
+			result.Detail = err.Error()
+			report.Passed = false
+		}
+		report.Steps = append(report.Steps, result)
+	}
+	return report
+}
+
+// checkReadiness checks that the instance is up and reports a degraded Yandex GPT
+func (s *smokeTest) checkReadiness(ctx context.Context) error {
+	var readiness models.ReadinessResponse
+	if err := s.do(ctx, http.MethodGet, "/readyz", nil, http.StatusOK, &readiness); err != nil {
+		return err
+	}
+	if readiness.Status != "ready" && readiness.Status != "degraded" {
+		return fmt.Errorf("unexpected readiness status %q", readiness.Status)
+	}
+	return nil
+}
+
+// register registers the test account, logging in when it already exists
+func (s *smokeTest) register(ctx context.Context) error {
+	var auth models.AuthResponse
+	err := s.do(ctx, http.MethodPost, "/auth/register", models.RegisterRequest{
+		Name:     "Smoke Test",
+		Email:    s.options.Email,
+		Password: s.options.Password,
+	}, http.StatusCreated, &auth)
+	if err != nil {
+		// The account is reused between runs
+		loginErr := s.do(ctx, http.MethodPost, "/auth/login", models.LoginRequest{
+			Email:    s.options.Email,
+			Password: s.options.Password,
+		}, http.StatusOK, &auth)
+		if loginErr != nil {
+			return fmt.Errorf("failed to register (%v) or log in: %w", err, loginErr)
+		}
+	}
+	if auth.Token == "" {
+		return errors.New("no token in the response")
+	}
+	s.token = auth.Token
+	return nil
+}
+
+// findPlant picks the first plant of the catalog
+func (s *smokeTest) findPlant(ctx context.Context) error {
+	var plants []*models.Plant
+	if err := s.do(ctx, http.MethodGet, "/plants?pageSize=1", nil, http.StatusOK, &plants); err != nil {
+		return err
+	}
+	if len(plants) == 0 {
+		return errors.New("the catalog is empty")
+	}
+	s.plantID = plants[0].ID
+	return nil
+}
+
+// addPlant adds the plant to the test account's collection
+func (s *smokeTest) addPlant(ctx context.Context) error {
+	if err := s.requirePlant(); err != nil {
+		return err
+	}
+	body := map[string]string{"location": "Smoke test"}
+	if err := s.do(ctx, http.MethodPost, "/plants/user/"+s.plantID.String(), body, http.StatusOK, nil); err != nil {
+		return err
+	}
+	s.added = true
+	return nil
+}
+
+// waterPlant marks the plant as watered and checks that the next watering is scheduled
+func (s *smokeTest) waterPlant(ctx context.Context) error {
+	if !s.added {
+		return fmt.Errorf("%w: no plant in the collection", errSkipped)
+	}
+	var plant models.Plant
+	if err := s.do(ctx, http.MethodPost, "/plants/"+s.plantID.String()+"/water", nil, http.StatusOK, &plant); err != nil {
+		return err
+	}
+	if plant.LastWatered == nil {
+		return errors.New("lastWatered was not set")
+	}
+	return nil
+}
+
+// fillQuestionnaire fills the questionnaire and checks that a plant is recommended
+func (s *smokeTest) fillQuestionnaire(ctx context.Context) error {
+	if s.token == "" {
+		return fmt.Errorf("%w: not signed in", errSkipped)
+	}
+	var plant models.Plant
+	err := s.do(ctx, http.MethodPost, "/recommendations/questionnaire", models.QuestionnaireRequest{
+		SunlightPreference: models.SunlightLevelMedium,
+		CareLevel:          2,
+	}, http.StatusCreated, &plant)
+	if err != nil {
+		return err
+	}
+	if plant.ID == uuid.Nil {
+		return errors.New("no plant recommended")
+	}
+	return nil
+}
+
+// chat opens a chat session and checks that the assistant answers a message
+func (s *smokeTest) chat(ctx context.Context) error {
+	if !s.options.Chat {
+		return fmt.Errorf("%w: chat is disabled", errSkipped)
+	}
+	if s.token == "" {
+		return fmt.Errorf("%w: not signed in", errSkipped)
+	}
+
+	var session models.ChatSession
+	if err := s.do(ctx, http.MethodPost, "/chat/sessions", nil, http.StatusCreated, &session); err != nil {
+		return err
+	}
+	var response models.ChatResponse
+	err := s.do(ctx, http.MethodPost, "/chat/sessions/"+session.ID.String()+"/messages", models.ChatRequest{
+		Message: "How often should I water a monstera?",
+	}, http.StatusOK, &response)
+	if err != nil {
+		return err
+	}
+	if response.Message.Role != "assistant" || response.Message.Content == "" {
+		return errors.New("the assistant did not answer")
+	}
+	return nil
+}
+
+// checkNotifications checks that the notifications of the test account can be listed
+func (s *smokeTest) checkNotifications(ctx context.Context) error {
+	if s.token == "" {
+		return fmt.Errorf("%w: not signed in", errSkipped)
+	}
+	var response models.NotificationResponse
+	if err := s.do(ctx, http.MethodGet, "/notifications", nil, http.StatusOK, &response); err != nil {
+		return err
+	}
+	if response.Total < len(response.Notifications) {
+		return fmt.Errorf("total %d is less than the %d notifications returned", response.Total, len(response.Notifications))
+	}
+	return nil
+}
+
+// removePlant removes the plant from the collection so the account can be reused
+func (s *smokeTest) removePlant(ctx context.Context) error {
+	if !s.added {
+		return fmt.Errorf("%w: no plant in the collection", errSkipped)
+	}
+	return s.do(ctx, http.MethodDelete, "/plants/user/"+s.plantID.String(), nil, http.StatusOK, nil)
+}
+
+// requirePlant skips steps that need the signed-in account and a catalog plant
+func (s *smokeTest) requirePlant() error {
+	if s.token == "" {
+		return fmt.Errorf("%w: not signed in", errSkipped)
+	}
+	if s.plantID == uuid.Nil {
+		return fmt.Errorf("%w: no catalog plant", errSkipped)
+	}
+	return nil
+}
+
+// do sends a request with the token, checks the status and decodes the response into out unless it is nil
+func (s *smokeTest) do(ctx context.Context, method, path string, body interface{}, wantStatus int, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode the request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(s.options.BaseURL, "/")+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create the request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s %s: failed to read the response: %w", method, path, err)
+	}
+	if resp.StatusCode != wantStatus {
+		return fmt.Errorf("%s %s: got status %d, want %d: %s", method, path, resp.StatusCode, wantStatus, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("%s %s: failed to decode the response: %w", method, path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeInstance serves the endpoints the smoke test calls, answering chat messages with the status
+func newFakeInstance(t *testing.T, chatStatus int) *httptest.Server {
+	plantID, sessionID := uuid.New(), uuid.New()
+	respond := func(w http.ResponseWriter, code int, payload interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(payload)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, models.ReadinessResponse{Status: "degraded"})
+	})
+	mux.HandleFunc("POST /auth/register", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusBadRequest, map[string]string{"error": "user with this email already exists"})
+	})
+	mux.HandleFunc("POST /auth/login", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, models.AuthResponse{Token: "token"})
+	})
+	mux.HandleFunc("GET /plants", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, []*models.Plant{{ID: plantID}})
+	})
+	mux.HandleFunc("POST /plants/user/{plantId}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			respond(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+			return
+		}
+		respond(w, http.StatusOK, map[string]string{"message": "Plant added to collection"})
+	})
+	mux.HandleFunc("DELETE /plants/user/{plantId}", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, map[string]string{"message": "Plant removed from collection"})
+	})
+	mux.HandleFunc("POST /plants/"+plantID.String()+"/water", func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		respond(w, http.StatusOK, models.Plant{ID: plantID, LastWatered: &now})
+	})
+	mux.HandleFunc("POST /recommendations/questionnaire", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusCreated, models.Plant{ID: plantID})
+	})
+	mux.HandleFunc("POST /chat/sessions", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusCreated, models.ChatSession{ID: sessionID})
+	})
+	mux.HandleFunc("POST /chat/sessions/{sessionId}/messages", func(w http.ResponseWriter, r *http.Request) {
+		if chatStatus != http.StatusOK {
+			respond(w, chatStatus, map[string]string{"error": "Chat is temporarily unavailable"})
+			return
+		}
+		respond(w, http.StatusOK, models.ChatResponse{Message: models.ChatMessage{Role: "assistant", Content: "Once a week"}})
+	})
+	mux.HandleFunc("GET /notifications", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, http.StatusOK, models.NotificationResponse{Notifications: []*models.Notification{}})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// stepStatuses returns the status of each step by name
+func stepStatuses(report *Report) map[string]StepStatus {
+	statuses := make(map[string]StepStatus, len(report.Steps))
+	for _, result := range report.Steps {
+		statuses[result.Name] = result.Status
+	}
+	return statuses
+}
+
+// TestSmokeTest_Run tests a passing run with an existing account and the chat disabled
+func TestSmokeTest_Run(t *testing.T) {
+	server := newFakeInstance(t, http.StatusOK)
+
+	test := newSmokeTest(Options{BaseURL: server.URL, Email: "smoke@example.com", Password: "secret"}, server.Client())
+	report := test.Run(context.Background())
+
+	assert.True(t, report.Passed)
+	statuses := stepStatuses(report)
+	assert.Equal(t, StepPassed, statuses["register"])
+	assert.Equal(t, StepPassed, statuses["water plant"])
+	assert.Equal(t, StepSkipped, statuses["chat"])
+	assert.Len(t, report.Steps, 9)
+}
+
+// TestSmokeTest_Run_Failure tests that a failing step fails the run without stopping the others
+func TestSmokeTest_Run_Failure(t *testing.T) {
+	server := newFakeInstance(t, http.StatusServiceUnavailable)
+
+	test := newSmokeTest(Options{BaseURL: server.URL, Email: "smoke@example.com", Password: "secret", Chat: true}, server.Client())
+	report := test.Run(context.Background())
+
+	assert.False(t, report.Passed)
+	statuses := stepStatuses(report)
+	assert.Equal(t, StepFailed, statuses["chat"])
+	assert.Equal(t, StepPassed, statuses["notifications"])
+	assert.Equal(t, StepPassed, statuses["remove plant"])
+}
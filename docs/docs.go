@@ -0,0 +1,10 @@
+// Package docs holds the OpenAPI definition of the API, embedded into the binary so it is served
+// exactly as documented.
+package docs
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3 definition of the API in YAML
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
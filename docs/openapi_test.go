@@ -0,0 +1,324 @@
+package docs_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/anpanovv/planter/docs"
+	"github.com/anpanovv/planter/internal/api"
+	"github.com/anpanovv/planter/internal/dto"
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/ws"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaTypes are the Go types the request and response schemas of the definition describe
+var schemaTypes = map[string]interface{}{
+	"LoginRequest":                      models.LoginRequest{},
+	"RegisterRequest":                   models.RegisterRequest{},
+	"AuthResponse":                      models.AuthResponse{},
+	"User":                              models.User{},
+	"CareInstructions":                  models.CareInstructions{},
+	"Plant":                             models.Plant{},
+	"Shop":                              models.Shop{},
+	"ShopPlant":                         models.ShopPlant{},
+	"PlantOffer":                        models.PlantOffer{},
+	"UpdateShopPlantRequest":            models.UpdateShopPlantRequest{},
+	"AddShopPlantRequest":               models.AddShopPlantRequest{},
+	"ShopRequest":                       models.ShopRequest{},
+	"SpecialOffer":                      models.SpecialOffer{},
+	"SpecialOfferRequest":               models.SpecialOfferRequest{},
+	"ShopImportResult":                  models.ShopImportResult{},
+	"PlantImportResult":                 models.PlantImportResult{},
+	"QuestionnaireRequest":              models.QuestionnaireRequest{},
+	"PlantQuestionnaire":                models.PlantQuestionnaire{},
+	"DetailedQuestionnaireRequest":      models.DetailedQuestionnaireRequest{},
+	"ChatSession":                       models.ChatSession{},
+	"ChatMessage":                       models.ChatMessage{},
+	"ChatRequest":                       models.ChatRequest{},
+	"ChatResponse":                      models.ChatResponse{},
+	"RenameChatSessionRequest":          models.RenameChatSessionRequest{},
+	"EscalateChatRequest":               models.EscalateChatRequest{},
+	"ChatEscalation":                    models.ChatEscalation{},
+	"SubscribeToAvailabilityRequest":    models.SubscribeToAvailabilityRequest{},
+	"PlantAvailabilitySubscription":     models.PlantAvailabilitySubscription{},
+	"NotificationStats":                 services.NotificationStats{},
+	"ChatUsage":                         models.ChatUsage{},
+	"ValidationError":                   models.ValidationError{},
+	"Warning":                           models.Warning{},
+	"Notification":                      models.Notification{},
+	"NotificationResponse":              models.NotificationResponse{},
+	"NotificationDisplay":               models.NotificationDisplay{},
+	"NotificationActionResult":          models.NotificationActionResult{},
+	"NotificationTypeDefinition":        models.NotificationTypeDefinition{},
+	"NotificationTemplate":              models.NotificationTemplate{},
+	"APIKey":                            models.APIKey{},
+	"CreateAPIKeyResponse":              models.CreateAPIKeyResponse{},
+	"APIKeyUsage":                       models.APIKeyUsage{},
+	"ClientConfig":                      models.ClientConfig{},
+	"LLMStatus":                         models.LLMStatus{},
+	"ReadinessResponse":                 models.ReadinessResponse{},
+	"RedisStatus":                       models.RedisStatus{},
+	"PlantFunFact":                      models.PlantFunFact{},
+	"LowEffortWatering":                 models.LowEffortWatering{},
+	"SetLowEffortModeRequest":           models.SetLowEffortModeRequest{},
+	"OutdoorLocation":                   models.OutdoorLocation{},
+	"Home":                              models.Home{},
+	"AssignHomePlantsRequest":           models.AssignHomePlantsRequest{},
+	"CurrentHomeRequest":                models.CurrentHomeRequest{},
+	"NotificationBell":                  models.NotificationBell{},
+	"PlantTranslation":                  models.PlantTranslation{},
+	"PlantTranslationRequest":           models.PlantTranslationRequest{},
+	"Toxicity":                          models.Toxicity{},
+	"FavoriteChanges":                   models.FavoriteChanges{},
+	"PlantStatusRequest":                models.PlantStatusRequest{},
+	"Category":                          models.Category{},
+	"CategoryRequest":                   models.CategoryRequest{},
+	"TagCount":                          models.TagCount{},
+	"PlantTaxonomy":                     models.PlantTaxonomy{},
+	"SeasonalGuideOverride":             models.SeasonalGuideOverride{},
+	"SeasonalGuideOverrideRequest":      models.SeasonalGuideOverrideRequest{},
+	"SeasonalGuideItem":                 models.SeasonalGuideItem{},
+	"SeasonalGuide":                     models.SeasonalGuide{},
+	"NotificationStreamMessage":         ws.Message{},
+	"PlantCompatibilityRequest":         models.PlantCompatibilityRequest{},
+	"PlantCompatibility":                models.PlantCompatibility{},
+	"CompatibilityCheck":                models.CompatibilityCheck{},
+	"SharedCare":                        models.SharedCare{},
+	"CreateAnnouncementRequest":         models.CreateAnnouncementRequest{},
+	"Announcement":                      models.Announcement{},
+	"DailyQuiz":                         models.DailyQuiz{},
+	"QuizQuestion":                      models.QuizQuestion{},
+	"QuizAnswer":                        models.QuizAnswer{},
+	"QuizResult":                        models.QuizResult{},
+	"SubmitQuizAnswersRequest":          models.SubmitQuizAnswersRequest{},
+	"QuizLeaderboard":                   models.QuizLeaderboard{},
+	"QuizLeaderboardEntry":              models.QuizLeaderboardEntry{},
+	"FollowedUser":                      models.FollowedUser{},
+	"Badge":                             models.Badge{},
+	"RoomMove":                          models.RoomMove{},
+	"MovePlantsRequest":                 models.MovePlantsRequest{},
+	"PostponedCareTask":                 models.PostponedCareTask{},
+	"MovedPlant":                        models.MovedPlant{},
+	"PlantMove":                         models.PlantMove{},
+	"PlantStats":                        models.PlantStats{},
+	"SaveChangelogEntryRequest":         models.SaveChangelogEntryRequest{},
+	"ChangelogEntry":                    models.ChangelogEntry{},
+	"ChangelogItem":                     models.ChangelogItem{},
+	"Changelog":                         models.Changelog{},
+	"MarkChangelogSeenRequest":          models.MarkChangelogSeenRequest{},
+	"PlantIdentificationCandidate":      models.PlantIdentificationCandidate{},
+	"PlantOnboardingResult":             models.PlantOnboardingResult{},
+	"AddUserPlantsResponse":             models.AddUserPlantsResponse{},
+	"AddUserPlantsResult":               models.AddUserPlantsResult{},
+	"PlantImage":                        models.PlantImage{},
+	"PlantEnrichment":                   models.PlantEnrichment{},
+	"PlantEnrichmentProposal":           models.PlantEnrichmentProposal{},
+	"PlantEnrichmentRun":                models.PlantEnrichmentRun{},
+	"NicknameSuggestions":               models.NicknameSuggestions{},
+	"CareTask":                          models.CareTask{},
+	"CareTaskStats":                     models.CareTaskStats{},
+	"CareTaskChecklist":                 models.CareTaskChecklist{},
+	"UserPlantTask":                     models.UserPlantTask{},
+	"CareScheduleEntry":                 models.CareScheduleEntry{},
+	"UpdateCareScheduleRequest":         models.UpdateCareScheduleRequest{},
+	"PlantReminder":                     models.PlantReminder{},
+	"PlantReminderRequest":              models.PlantReminderRequest{},
+	"AuditEntry":                        models.AuditEntry{},
+	"AuditLogPage":                      models.AuditLogPage{},
+	"Saga":                              models.Saga{},
+	"CareSchedule":                      models.CareSchedule{},
+	"WateringRoute":                     models.WateringRoute{},
+	"PersonalAccessToken":               models.PersonalAccessToken{},
+	"CreatePersonalAccessTokenRequest":  models.CreatePersonalAccessTokenRequest{},
+	"CreatePersonalAccessTokenResponse": models.CreatePersonalAccessTokenResponse{},
+	"AnalyticsEvent":                    models.AnalyticsEvent{},
+	"TrackEventsRequest":                models.TrackEventsRequest{},
+	"TrackEventsResponse":               models.TrackEventsResponse{},
+	"AnalyticsEventCount":               models.AnalyticsEventCount{},
+	"ReconciliationCorrection":          models.ReconciliationCorrection{},
+	"ReconciliationRun":                 models.ReconciliationRun{},
+	"AnonymizationRun":                  models.AnonymizationRun{},
+	"AccountMergeRequest":               models.AccountMergeRequest{},
+	"AccountMerge":                      models.AccountMerge{},
+	"AccountMergeSummary":               models.AccountMergeSummary{},
+	"CapturedRequest":                   models.CapturedRequest{},
+	"ReplayResult":                      models.ReplayResult{},
+	"CareFeedback":                      models.CareFeedback{},
+	"SubmitCareFeedbackRequest":         models.SubmitCareFeedbackRequest{},
+	"PlantDifficulty":                   models.PlantDifficulty{},
+	"UpdatePlantDifficultyRequest":      models.UpdatePlantDifficultyRequest{},
+	"PlantDiagnosis":                    models.PlantDiagnosis{},
+	"UserPlantPhoto":                    models.UserPlantPhoto{},
+	"TriageRequest":                     models.TriageRequest{},
+	"TriageResult":                      models.TriageResult{},
+	"PlantJournalEntry":                 models.PlantJournalEntry{},
+	"SupportTicket":                     models.SupportTicket{},
+	"SupportTicketContext":              models.SupportTicketContext{},
+	"CreateSupportTicketRequest":        models.CreateSupportTicketRequest{},
+	"UpdateSupportTicketRequest":        models.UpdateSupportTicketRequest{},
+	"SupportTicketListResponse":         models.SupportTicketListResponse{},
+	"PlantEvent":                        models.PlantEvent{},
+	"PlantEventPage":                    models.PlantEventPage{},
+	"CarePlanRequest":                   models.CarePlanRequest{},
+	"CarePlanMonth":                     models.CarePlanMonth{},
+	"CarePlan":                          models.CarePlan{},
+	"CircuitBreakerStatus":              models.CircuitBreakerStatus{},
+	"LLMUserUsage":                      models.LLMUserUsage{},
+	"LLMBudgetReport":                   models.LLMBudgetReport{},
+	"LitePlant":                         dto.LitePlant{},
+	"CareHint":                          models.CareHint{},
+	"RecommendationExplanation":         models.RecommendationExplanation{},
+	"RecommendationCriterionScore":      models.RecommendationCriterionScore{},
+	"PlantSpecies":                      models.PlantSpecies{},
+	"PlantSpeciesRequest":               models.PlantSpeciesRequest{},
+	"CareOverrides":                     models.CareOverrides{},
+}
+
+// schema is the part of an OpenAPI schema the tests compare
+type schema struct {
+	Ref        string             `yaml:"$ref"`
+	Properties map[string]*schema `yaml:"properties"`
+	AllOf      []*schema          `yaml:"allOf"`
+}
+
+// definition is the part of the OpenAPI definition the tests compare
+type definition struct {
+	Paths      map[string]map[string]yaml.Node `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+func loadDefinition(t *testing.T) *definition {
+	t.Helper()
+	var def definition
+	if err := yaml.Unmarshal(docs.OpenAPI, &def); err != nil {
+		t.Fatalf("failed to parse openapi.yaml: %v", err)
+	}
+	return &def
+}
+
+// newTestAPI creates an API without services; only routes that call none can be served
+func newTestAPI() *api.API {
+	return api.New(
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		services.NewDemoService(nil, nil, ""),
+		nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		services.NewRequestCaptureService(nil, false, 0, 0),
+		nil, nil,
+		middleware.NewAuth("test-secret"),
+		nil,
+	)
+}
+
+// TestOpenAPI_Served tests that the definition is served in JSON as it is written in YAML
+func TestOpenAPI_Served(t *testing.T) {
+	handler := newTestAPI().Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var served map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	var written map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal(docs.OpenAPI, &written))
+	assert.Equal(t, written["openapi"], served["openapi"])
+	assert.Len(t, served["paths"], len(written["paths"].(map[string]interface{})))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `url: "openapi.json"`)
+}
+
+// TestOpenAPI_Paths tests that the definition describes exactly the routes the API serves
+func TestOpenAPI_Paths(t *testing.T) {
+	def := loadDefinition(t)
+
+	var documented []string
+	for path, operations := range def.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+	served := newTestAPI().Routes()
+
+	sort.Strings(documented)
+	sort.Strings(served)
+	assert.Equal(t, served, documented, "docs/openapi.yaml must describe every route of internal/api and nothing else")
+}
+
+// TestOpenAPI_Schemas tests that the schemas list the JSON fields of the types they describe
+func TestOpenAPI_Schemas(t *testing.T) {
+	def := loadDefinition(t)
+
+	for name, value := range schemaTypes {
+		t.Run(name, func(t *testing.T) {
+			s, ok := def.Components.Schemas[name]
+			if !ok {
+				t.Fatalf("schema %s is not defined", name)
+			}
+
+			documented := schemaProperties(def, s)
+			fields := jsonFields(reflect.TypeOf(value))
+			sort.Strings(documented)
+			sort.Strings(fields)
+			assert.Equal(t, fields, documented, "properties of schema %s must match the JSON fields of %T", name, value)
+		})
+	}
+}
+
+// schemaProperties gets the names of the properties of a schema, following allOf and references
+func schemaProperties(def *definition, s *schema) []string {
+	if s.Ref != "" {
+		return schemaProperties(def, def.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")])
+	}
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	for _, part := range s.AllOf {
+		names = append(names, schemaProperties(def, part)...)
+	}
+	return names
+}
+
+// jsonFields gets the names of the fields of a struct type in its JSON encoding, including the
+// fields of embedded structs
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			names = append(names, jsonFields(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// handleAdminMergeAccounts handles the admin merge accounts request
+func (a *API) handleAdminMergeAccounts(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated admin ID from the context
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Only report what would be moved when a dry run is requested
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	// Parse the request body
+	var req models.AccountMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Merge the accounts
+	merge, err := a.userService.MergeAccounts(r.Context(), adminID, &req, dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAccountMerge):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrAccountNotMergeable):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to merge accounts")
+		}
+		return
+	}
+
+	// Respond with the merge
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	utils.RespondWithJSON(w, status, merge)
+}
+
+// handleAdminGetAccountMerges handles the admin get account merges request
+func (a *API) handleAdminGetAccountMerges(w http.ResponseWriter, r *http.Request) {
+	// Get the number of merges
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	// Get the merges
+	merges, err := a.userService.GetAccountMerges(r.Context(), limit)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get account merges")
+		return
+	}
+
+	// Respond with the merges
+	utils.RespondWithJSON(w, http.StatusOK, merges)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleAdminCreateAnnouncement handles the admin create announcement request
+func (a *API) handleAdminCreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	if a.announcementService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Announcements are not available")
+		return
+	}
+
+	// Get the authenticated admin ID from the context
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.CreateAnnouncementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Create the announcement
+	announcement, err := a.announcementService.CreateAnnouncement(r.Context(), adminID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAnnouncement) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create announcement")
+		return
+	}
+
+	// Respond with the announcement, which the job starts sending on its next run
+	utils.RespondWithJSON(w, http.StatusCreated, announcement)
+}
+
+// handleAdminListAnnouncements handles the admin list announcements request
+func (a *API) handleAdminListAnnouncements(w http.ResponseWriter, r *http.Request) {
+	if a.announcementService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Announcements are not available")
+		return
+	}
+
+	// Get the announcements
+	announcements, err := a.announcementService.ListAnnouncements(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get announcements")
+		return
+	}
+
+	// Respond with the announcements
+	utils.RespondWithJSON(w, http.StatusOK, announcements)
+}
+
+// handleAdminGetAnnouncement handles the admin get announcement request
+func (a *API) handleAdminGetAnnouncement(w http.ResponseWriter, r *http.Request) {
+	if a.announcementService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Announcements are not available")
+		return
+	}
+
+	// Get the announcement ID from the URL
+	announcementID, err := uuid.Parse(mux.Vars(r)["announcementId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid announcement ID")
+		return
+	}
+
+	// Get the announcement
+	announcement, err := a.announcementService.GetAnnouncement(r.Context(), announcementID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Announcement not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get announcement")
+		return
+	}
+
+	// Respond with the announcement and its delivery stats
+	utils.RespondWithJSON(w, http.StatusOK, announcement)
+}
+
+// handleAdminCancelAnnouncement handles the admin cancel announcement request
+func (a *API) handleAdminCancelAnnouncement(w http.ResponseWriter, r *http.Request) {
+	if a.announcementService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Announcements are not available")
+		return
+	}
+
+	// Get the announcement ID from the URL
+	announcementID, err := uuid.Parse(mux.Vars(r)["announcementId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid announcement ID")
+		return
+	}
+
+	// Cancel the announcement
+	announcement, err := a.announcementService.CancelAnnouncement(r.Context(), announcementID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Announcement not found")
+		case errors.Is(err, services.ErrAnnouncementFinished):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to cancel announcement")
+		}
+		return
+	}
+
+	// Respond with the canceled announcement
+	utils.RespondWithJSON(w, http.StatusOK, announcement)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// handleAdminRunAnonymization handles the admin run anonymization request
+func (a *API) handleAdminRunAnonymization(w http.ResponseWriter, r *http.Request) {
+	// Only list the accounts when a dry run is requested
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	// Run the anonymization
+	run, err := a.anonymizationService.Run(r.Context(), dryRun)
+	if run == nil {
+		if errors.Is(err, services.ErrAnonymizationDisabled) {
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to run anonymization")
+		return
+	}
+
+	// Respond with the report, which includes the accounts that failed
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusInternalServerError
+	}
+	utils.RespondWithJSON(w, status, run)
+}
+
+// handleAdminGetAnonymizationRuns handles the admin get anonymization runs request
+func (a *API) handleAdminGetAnonymizationRuns(w http.ResponseWriter, r *http.Request) {
+	// Get the number of runs
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	// Get the runs
+	runs, err := a.anonymizationService.GetRuns(r.Context(), limit)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get anonymization runs")
+		return
+	}
+
+	// Respond with the runs
+	utils.RespondWithJSON(w, http.StatusOK, runs)
+}
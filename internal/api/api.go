@@ -1,11 +1,22 @@
 package api
 
 import (
+	"context"
 	"net/http"
+	"time"
 
+	"github.com/anpanovv/planter/internal/audit"
+	"github.com/anpanovv/planter/internal/cache"
 	"github.com/anpanovv/planter/internal/config"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/dto"
 	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/redis"
+	"github.com/anpanovv/planter/internal/saga"
+	"github.com/anpanovv/planter/internal/server"
 	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/ws"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 )
@@ -19,9 +30,60 @@ type API struct {
 	shopService     *services.ShopService
 	recommendationService *services.RecommendationService
 	notificationService *services.NotificationService
+	apiKeyService   *services.APIKeyService
+	clientConfigService *services.ClientConfigService
+	funFactService  *services.FunFactService
+	careTaskService *services.CareTaskService
+	notificationTemplateService *services.NotificationTemplateService
+	personalTokenService *services.PersonalTokenService
+	analyticsService *services.AnalyticsService
+	demoService     *services.DemoService
+	reconciliationService *services.ReconciliationService
+	careFeedbackService *services.CareFeedbackService
+	diagnosisService *services.DiagnosisService
+	triageService *services.TriageService
+	carePlanService *services.CarePlanService
+	llmBudgetService *services.LLMBudgetService
+	plantEventService *services.PlantEventService
+	supportService  *services.SupportService
+	anonymizationService *services.AnonymizationService
+	requestCaptureService *services.RequestCaptureService
+	chatEscalationService *services.ChatEscalationService
+	plantAvailabilityService *services.PlantAvailabilityService
 	auth            *middleware.Auth
+	apiKeyAuth      *middleware.APIKeyAuth
+	tokenAuth       *middleware.TokenAuth
+	demoMode        *middleware.DemoMode
+	roleAuth        *middleware.RoleAuth
+	publicRateLimiter middleware.Limiter
+	redis           *redis.Client // nil when Redis is not configured
+	plantCache      cache.Cache   // nil when plant catalog reads are not cached
+	queryMetrics    *db.QueryMetrics // nil when database queries are not instrumented
+	chatScrubber    *services.ChatScrubber // nil when chat messages are not scrubbed
+	plantEnrichmentService *services.PlantEnrichmentService // nil when enrichment is not configured
+	plantOnboardingService *services.PlantOnboardingService // nil until set
+	lowEffortService *services.LowEffortService // nil until set
+	weatherService   *services.WeatherService   // nil until set
+	notificationHub  *ws.Hub                    // nil when notifications are not streamed
+	announcementService *services.AnnouncementService // nil until set
+	quizService      *services.QuizService      // nil until set
+	followService    *services.FollowService    // nil until set
+	badgeService     *services.BadgeService     // nil until set
+	plantMoveService *services.PlantMoveService // nil until set
+	plantStatsService *services.PlantStatsService // nil until set
+	changelogService *services.ChangelogService  // nil until set
+	homeService      *services.HomeService       // nil until set
+	plantExportService *services.PlantExportService // nil until set
+	seasonalGuideService *services.SeasonalGuideService // nil until set
+	plantReminderService *services.PlantReminderService // nil until set
+	auditTrail       *audit.Trail                // nil until set; admin actions are not recorded meanwhile
+	sagaOrchestrator *saga.Orchestrator          // nil until set
+	assets           http.Handler                // nil when uploaded assets are served by the CDN alone
 }
 
+// assetsPathPrefix is the path uploaded assets are served under when the API is the origin of the CDN
+const assetsPathPrefix = "/assets"
+
 // New creates a new API server
 func New(
 	authService *services.AuthService,
@@ -30,7 +92,28 @@ func New(
 	shopService *services.ShopService,
 	recommendationService *services.RecommendationService,
 	notificationService *services.NotificationService,
+	apiKeyService *services.APIKeyService,
+	clientConfigService *services.ClientConfigService,
+	funFactService *services.FunFactService,
+	careTaskService *services.CareTaskService,
+	notificationTemplateService *services.NotificationTemplateService,
+	personalTokenService *services.PersonalTokenService,
+	analyticsService *services.AnalyticsService,
+	demoService *services.DemoService,
+	reconciliationService *services.ReconciliationService,
+	careFeedbackService *services.CareFeedbackService,
+	diagnosisService *services.DiagnosisService,
+	triageService *services.TriageService,
+	carePlanService *services.CarePlanService,
+	llmBudgetService *services.LLMBudgetService,
+	plantEventService *services.PlantEventService,
+	supportService *services.SupportService,
+	anonymizationService *services.AnonymizationService,
+	requestCaptureService *services.RequestCaptureService,
+	chatEscalationService *services.ChatEscalationService,
+	plantAvailabilityService *services.PlantAvailabilityService,
 	auth *middleware.Auth,
+	publicRateLimiter middleware.Limiter,
 ) *API {
 	api := &API{
 		router:          mux.NewRouter(),
@@ -40,15 +123,201 @@ func New(
 		shopService:     shopService,
 		recommendationService: recommendationService,
 		notificationService: notificationService,
+		apiKeyService:   apiKeyService,
+		clientConfigService: clientConfigService,
+		funFactService:  funFactService,
+		careTaskService: careTaskService,
+		notificationTemplateService: notificationTemplateService,
+		personalTokenService: personalTokenService,
+		analyticsService: analyticsService,
+		demoService:     demoService,
+		reconciliationService: reconciliationService,
+		careFeedbackService: careFeedbackService,
+		diagnosisService: diagnosisService,
+		triageService: triageService,
+		carePlanService: carePlanService,
+		llmBudgetService: llmBudgetService,
+		plantEventService: plantEventService,
+		supportService:  supportService,
+		anonymizationService: anonymizationService,
+		requestCaptureService: requestCaptureService,
+		chatEscalationService: chatEscalationService,
+		plantAvailabilityService: plantAvailabilityService,
 		auth:            auth,
+		apiKeyAuth:      middleware.NewAPIKeyAuth(apiKeyService, publicRateLimiter),
+		tokenAuth:       middleware.NewTokenAuth(auth, personalTokenService, services.PersonalTokenPrefix),
+		demoMode:        middleware.NewDemoMode(auth, demoService),
+		roleAuth:        middleware.NewRoleAuth(auth, userService),
+		publicRateLimiter: publicRateLimiter,
 	}
 
 	api.setupRoutes()
 	return api
 }
 
+// SetRedis sets the Redis client whose health is reported by the readiness probe and the metrics
+func (a *API) SetRedis(client *redis.Client) {
+	a.redis = client
+}
+
+// SetPlantCache sets the cache of plant catalog reads whose hits and misses are reported by the metrics
+func (a *API) SetPlantCache(plantCache cache.Cache) {
+	a.plantCache = plantCache
+}
+
+// SetQueryMetrics sets the metrics of the database queries of the repositories reported by the metrics
+func (a *API) SetQueryMetrics(queryMetrics *db.QueryMetrics) {
+	a.queryMetrics = queryMetrics
+}
+
+// SetChatScrubber sets the scrubber of chat messages whose scrubs are reported by the metrics
+func (a *API) SetChatScrubber(chatScrubber *services.ChatScrubber) {
+	a.chatScrubber = chatScrubber
+}
+
+// SetPlantOnboardingService sets the service adding new plants to collections from a photo
+func (a *API) SetPlantOnboardingService(plantOnboardingService *services.PlantOnboardingService) {
+	a.plantOnboardingService = plantOnboardingService
+}
+
+// SetLowEffortService sets the service turning low effort mode on and off
+func (a *API) SetLowEffortService(lowEffortService *services.LowEffortService) {
+	a.lowEffortService = lowEffortService
+}
+
+// SetWeatherService sets the service keeping the outdoor locations of users
+func (a *API) SetWeatherService(weatherService *services.WeatherService) {
+	a.weatherService = weatherService
+}
+
+// SetNotificationHub sets the hub streaming new notifications to connected clients
+func (a *API) SetNotificationHub(notificationHub *ws.Hub) {
+	a.notificationHub = notificationHub
+}
+
+// SetAnnouncementService sets the service broadcasting announcements of admins to the users
+func (a *API) SetAnnouncementService(announcementService *services.AnnouncementService) {
+	a.announcementService = announcementService
+}
+
+// SetQuizService sets the service serving the daily care knowledge quiz
+func (a *API) SetQuizService(quizService *services.QuizService) {
+	a.quizService = quizService
+}
+
+// SetFollowService sets the service handling the users a user follows
+func (a *API) SetFollowService(followService *services.FollowService) {
+	a.followService = followService
+}
+
+// SetBadgeService sets the service awarding badges to the users
+func (a *API) SetBadgeService(badgeService *services.BadgeService) {
+	a.badgeService = badgeService
+}
+
+// SetPlantMoveService sets the service moving the plants of collections to other rooms
+func (a *API) SetPlantMoveService(plantMoveService *services.PlantMoveService) {
+	a.plantMoveService = plantMoveService
+}
+
+// SetPlantStatsService sets the service aggregating the ownership statistics of catalog plants
+func (a *API) SetPlantStatsService(plantStatsService *services.PlantStatsService) {
+	a.plantStatsService = plantStatsService
+}
+
+// SetChangelogService sets the service managing the release notes of the what's-new sheet
+func (a *API) SetChangelogService(changelogService *services.ChangelogService) {
+	a.changelogService = changelogService
+}
+
+// SetHomeService sets the service managing the homes of users
+func (a *API) SetHomeService(homeService *services.HomeService) {
+	a.homeService = homeService
+}
+
+// SetPlantExportService sets the service exporting the plant catalog
+func (a *API) SetPlantExportService(plantExportService *services.PlantExportService) {
+	a.plantExportService = plantExportService
+}
+
+// SetSeasonalGuideService sets the service assembling the seasonal guide of the catalog
+func (a *API) SetSeasonalGuideService(seasonalGuideService *services.SeasonalGuideService) {
+	a.seasonalGuideService = seasonalGuideService
+}
+
+// SetPlantReminderService sets the service managing the reminders users define for their plants
+func (a *API) SetPlantReminderService(plantReminderService *services.PlantReminderService) {
+	a.plantReminderService = plantReminderService
+}
+
+// SetAuditTrail sets the audit trail admin actions are recorded in and read from
+func (a *API) SetAuditTrail(auditTrail *audit.Trail) {
+	a.auditTrail = auditTrail
+}
+
+// SetSagaOrchestrator sets the orchestrator of multi-step operations whose stuck sagas admins list
+func (a *API) SetSagaOrchestrator(sagaOrchestrator *saga.Orchestrator) {
+	a.sagaOrchestrator = sagaOrchestrator
+}
+
+// SetAssets sets the handler serving uploaded assets under /assets/ by their key
+func (a *API) SetAssets(assets http.Handler) {
+	a.assets = assets
+}
+
+// SetPlantEnrichmentService sets the service looking up missing plant fields in external sources
+func (a *API) SetPlantEnrichmentService(plantEnrichmentService *services.PlantEnrichmentService) {
+	a.plantEnrichmentService = plantEnrichmentService
+}
+
+// Routes lists the routes the API serves as "METHOD /path/{param}", in registration order
+func (a *API) Routes() []string {
+	var routes []string
+	a.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Subrouter prefixes have no methods of their own
+			return nil
+		}
+		for _, method := range methods {
+			routes = append(routes, method+" "+path)
+		}
+		return nil
+	})
+	return routes
+}
+
 // setupRoutes sets up the API routes
 func (a *API) setupRoutes() {
+	// Demo account mutations are answered without saving anything
+	if a.demoService.Enabled() {
+		a.router.Use(a.demoMode.Middleware, a.demoSandbox)
+	}
+
+	// Readiness probe
+	a.router.HandleFunc("/readyz", a.handleReadyz).Methods(http.MethodGet)
+
+	// Metrics scrape endpoint in the OpenMetrics text format
+	a.router.HandleFunc("/metrics", a.handleMetrics).Methods(http.MethodGet)
+
+	// API definition and its documentation page
+	a.router.HandleFunc("/openapi.json", a.handleOpenAPIJSON).Methods(http.MethodGet)
+	a.router.HandleFunc("/openapi.yaml", a.handleOpenAPIYAML).Methods(http.MethodGet)
+	a.router.HandleFunc("/docs", a.handleSwaggerUI).Methods(http.MethodGet)
+
+	// Uploaded assets, for a CDN pulling them from the API; served as files, so not part of the definition
+	a.router.PathPrefix(assetsPathPrefix + "/").HandlerFunc(a.handleAsset)
+
+	// Client bootstrap route (authentication is optional and only used for the user's language)
+	a.router.Handle("/client-config", a.auth.OptionalAuth(http.HandlerFunc(a.handleGetClientConfig))).Methods(http.MethodGet)
+
+	// Analytics event ingestion (authentication is optional and only used to attribute events)
+	a.router.Handle("/events", a.auth.OptionalAuth(http.HandlerFunc(a.handleTrackEvents))).Methods(http.MethodPost)
+
 	// Auth routes
 	a.router.HandleFunc("/auth/login", a.handleLogin).Methods(http.MethodPost)
 	a.router.HandleFunc("/auth/register", a.handleRegister).Methods(http.MethodPost)
@@ -59,37 +328,183 @@ func (a *API) setupRoutes() {
 	userRouter.HandleFunc("/{userId}", a.handleGetUser).Methods(http.MethodGet)
 	userRouter.HandleFunc("/{userId}", a.handleUpdateUser).Methods(http.MethodPut)
 
+	// API key self-service routes
+	userRouter.HandleFunc("/me/api-keys", a.handleGetAPIKeys).Methods(http.MethodGet)
+	userRouter.HandleFunc("/me/api-keys", a.handleCreateAPIKey).Methods(http.MethodPost)
+	userRouter.HandleFunc("/me/api-keys/{keyId}", a.handleRevokeAPIKey).Methods(http.MethodDelete)
+	userRouter.HandleFunc("/me/api-keys/{keyId}/usage", a.handleGetAPIKeyUsage).Methods(http.MethodGet)
+
+	// Personal access token self-service routes
+	userRouter.HandleFunc("/me/tokens", a.handleGetPersonalTokens).Methods(http.MethodGet)
+	userRouter.HandleFunc("/me/tokens", a.handleCreatePersonalToken).Methods(http.MethodPost)
+	userRouter.HandleFunc("/me/tokens/{tokenId}", a.handleRevokePersonalToken).Methods(http.MethodDelete)
+
+	// Followed users and badges
+	userRouter.HandleFunc("/me/following", a.handleGetFollowing).Methods(http.MethodGet)
+	userRouter.HandleFunc("/me/following/{userId}", a.handleFollowUser).Methods(http.MethodPut)
+	userRouter.HandleFunc("/me/following/{userId}", a.handleUnfollowUser).Methods(http.MethodDelete)
+	userRouter.HandleFunc("/me/badges", a.handleGetBadges).Methods(http.MethodGet)
+
 	// Plant routes
 	a.router.HandleFunc("/plants", a.handleGetAllPlants).Methods(http.MethodGet)
 	a.router.HandleFunc("/plants/search", a.handleSearchPlants).Methods(http.MethodGet)
+	a.router.HandleFunc("/plants/seasonal-guide", a.handleGetSeasonalGuide).Methods(http.MethodGet)
+	a.router.HandleFunc("/plants/compatibility", a.handleCheckPlantCompatibility).Methods(http.MethodPost)
 	a.router.HandleFunc("/plants/{plantId}", a.handleGetPlant).Methods(http.MethodGet)
+	a.router.HandleFunc("/plants/{plantId}/fun-facts", a.handleGetPlantFunFacts).Methods(http.MethodGet)
+	a.router.HandleFunc("/plants/{plantId}/difficulty", a.handleGetPlantDifficulty).Methods(http.MethodGet)
+	a.router.HandleFunc("/plants/{plantId}/offers", a.handleGetPlantOffers).Methods(http.MethodGet)
+	a.router.HandleFunc("/plants/{plantId}/stats", a.handleGetPlantStats).Methods(http.MethodGet)
+	a.router.HandleFunc("/categories", a.handleGetCategories).Methods(http.MethodGet)
+	a.router.HandleFunc("/tags", a.handleGetTags).Methods(http.MethodGet)
+
+	// Plant routes that also accept personal access tokens with the matching scope
+	a.router.Handle("/plants/{plantId}/water", a.tokenAuth.RequireScope(string(models.TokenScopePlantsWater))(http.HandlerFunc(a.handleMarkAsWatered))).Methods(http.MethodPost)
+	a.router.Handle("/plants/user", a.tokenAuth.RequireScope(string(models.TokenScopePlantsRead))(http.HandlerFunc(a.handleGetUserPlants))).Methods(http.MethodGet)
 
 	// Plant routes that require authentication
 	plantRouter := a.router.PathPrefix("/plants").Subrouter()
 	plantRouter.Use(a.auth.RequireAuth)
 	userRouter.HandleFunc("/me/favorites", a.handleGetFavoritePlants).Methods(http.MethodGet)
+	userRouter.HandleFunc("/me/favorites/changes", a.handleGetFavoriteChanges).Methods(http.MethodGet)
+	userRouter.HandleFunc("/me/watering-route", a.handleGetWateringRoute).Methods(http.MethodGet)
+	userRouter.HandleFunc("/me/low-effort-mode", a.handleSetLowEffortMode).Methods(http.MethodPut)
+	userRouter.HandleFunc("/me/move", a.handleMovePlants).Methods(http.MethodPost)
+	userRouter.HandleFunc("/me/outdoor-locations", a.handleGetOutdoorLocations).Methods(http.MethodGet)
+	userRouter.HandleFunc("/me/outdoor-locations", a.handleSaveOutdoorLocation).Methods(http.MethodPut)
+	userRouter.HandleFunc("/me/outdoor-locations", a.handleDeleteOutdoorLocation).Methods(http.MethodDelete)
+	userRouter.HandleFunc("/me/homes", a.handleGetHomes).Methods(http.MethodGet)
+	userRouter.HandleFunc("/me/homes", a.handleCreateHome).Methods(http.MethodPost)
+	userRouter.HandleFunc("/me/homes/{homeId}", a.handleUpdateHome).Methods(http.MethodPut)
+	userRouter.HandleFunc("/me/homes/{homeId}", a.handleDeleteHome).Methods(http.MethodDelete)
+	userRouter.HandleFunc("/me/homes/{homeId}/plants", a.handleAssignHomePlants).Methods(http.MethodPost)
+	userRouter.HandleFunc("/me/homes/{homeId}/plants/{plantId}", a.handleUnassignHomePlant).Methods(http.MethodDelete)
+	userRouter.HandleFunc("/me/current-home", a.handleSetCurrentHome).Methods(http.MethodPut)
 	plantRouter.HandleFunc("/{plantId}/favorite", a.handleAddToFavorites).Methods(http.MethodPost)
 	plantRouter.HandleFunc("/{plantId}/favorite", a.handleRemoveFromFavorites).Methods(http.MethodDelete)
-	plantRouter.HandleFunc("/{plantId}/water", a.handleMarkAsWatered).Methods(http.MethodPost)
-	plantRouter.HandleFunc("/user", a.handleGetUserPlants).Methods(http.MethodGet)
+	userRouter.HandleFunc("/me/availability-subscriptions", a.handleGetAvailabilitySubscriptions).Methods(http.MethodGet)
+	plantRouter.HandleFunc("/{plantId}/availability-subscription", a.handleSubscribeToAvailability).Methods(http.MethodPut)
+	plantRouter.HandleFunc("/{plantId}/availability-subscription", a.handleUnsubscribeFromAvailability).Methods(http.MethodDelete)
+	plantRouter.HandleFunc("/user/batch", a.handleAddUserPlants).Methods(http.MethodPost) // before /user/{plantId}, which would match it
 	plantRouter.HandleFunc("/user/{plantId}", a.handleAddUserPlant).Methods(http.MethodPost)
 	plantRouter.HandleFunc("/user/{plantId}", a.handleUpdateUserPlant).Methods(http.MethodPut)
 	plantRouter.HandleFunc("/user/{plantId}", a.handleRemoveUserPlant).Methods(http.MethodDelete)
+	plantRouter.HandleFunc("/user/{plantId}/low-effort-mode", a.handleSetPlantLowEffortMode).Methods(http.MethodPut)
+	plantRouter.HandleFunc("/user/{plantId}/nickname-suggestions", a.handleGetNicknameSuggestions).Methods(http.MethodGet)
+	plantRouter.HandleFunc("/user/{plantId}/photos", a.handleAddUserPlantPhoto).Methods(http.MethodPost)
+	plantRouter.HandleFunc("/user/{plantId}/photos", a.handleGetUserPlantPhotos).Methods(http.MethodGet)
+	plantRouter.HandleFunc("/user/{plantId}/photos/{photoId}", a.handleDeleteUserPlantPhoto).Methods(http.MethodDelete)
+	plantRouter.HandleFunc("/user/{plantId}/tasks", a.handleGetCareTasks).Methods(http.MethodGet)
+	plantRouter.HandleFunc("/user/{plantId}/tasks/adherence", a.handleGetCareTaskAdherence).Methods(http.MethodGet)
+	plantRouter.HandleFunc("/user/{plantId}/tasks/{taskId}/complete", a.handleCompleteCareTask).Methods(http.MethodPost)
+	plantRouter.HandleFunc("/user/{plantId}/schedule", a.handleGetCareSchedule).Methods(http.MethodGet)
+	plantRouter.HandleFunc("/user/{plantId}/schedule", a.handleUpdateCareSchedule).Methods(http.MethodPut)
+	plantRouter.HandleFunc("/user/{plantId}/reminders", a.handleGetPlantReminders).Methods(http.MethodGet)
+	plantRouter.HandleFunc("/user/{plantId}/reminders", a.handleCreatePlantReminder).Methods(http.MethodPost)
+	plantRouter.HandleFunc("/user/{plantId}/reminders/{reminderId}", a.handleUpdatePlantReminder).Methods(http.MethodPut)
+	plantRouter.HandleFunc("/user/{plantId}/reminders/{reminderId}", a.handleDeletePlantReminder).Methods(http.MethodDelete)
+	plantRouter.HandleFunc("/user/{plantId}/care-feedback", a.handleSubmitCareFeedback).Methods(http.MethodPost)
+	plantRouter.HandleFunc("/user/{plantId}/diagnoses", a.handleGetPlantDiagnoses).Methods(http.MethodGet)
+	plantRouter.HandleFunc("/diagnose", a.handleDiagnosePlant).Methods(http.MethodPost)
+	plantRouter.HandleFunc("/onboard", a.handleOnboardPlant).Methods(http.MethodPost)
+	plantRouter.HandleFunc("/user/{plantId}/journal", a.handleGetPlantJournal).Methods(http.MethodGet)
+	plantRouter.HandleFunc("/user/{plantId}/events", a.handleGetPlantEvents).Methods(http.MethodGet)
+	plantRouter.HandleFunc("/triage", a.handleTriagePlant).Methods(http.MethodPost)
+	plantRouter.HandleFunc("/user/{plantId}/care-plan", a.handleGenerateCarePlan).Methods(http.MethodPost)
+	plantRouter.HandleFunc("/user/{plantId}/care-plan", a.handleGetCarePlan).Methods(http.MethodGet)
 
 	// Shop routes
 	a.router.HandleFunc("/shops", a.handleGetAllShops).Methods(http.MethodGet)
 	a.router.HandleFunc("/shops/{shopId}", a.handleGetShop).Methods(http.MethodGet)
 	a.router.HandleFunc("/shops/{shopId}/plants", a.handleGetShopPlants).Methods(http.MethodGet)
+	a.router.HandleFunc("/special-offers", a.handleGetSpecialOffers).Methods(http.MethodGet)
 
 	// Recommendation routes
 	recommendationRouter := a.router.PathPrefix("/recommendations").Subrouter()
 	recommendationRouter.HandleFunc("/questionnaire", a.handleSaveQuestionnaire).Methods(http.MethodPost)
 	recommendationRouter.HandleFunc("/questionnaire/detailed", a.handleSaveDetailedQuestionnaire).Methods(http.MethodPost)
 	recommendationRouter.HandleFunc("/questionnaire/{questionnaireId}", a.handleGetRecommendations).Methods(http.MethodGet)
+	recommendationRouter.Handle("/quick", a.auth.OptionalAuth(http.HandlerFunc(a.handleGetQuickRecommendations))).Methods(http.MethodGet)
 	
-	// Admin routes
+	// Admin routes (require the admin role); the changes admins make are recorded in the audit log
 	adminRouter := a.router.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(a.roleAuth.RequireRole(string(models.RoleAdmin)), a.auditAdminActions)
+	adminRouter.HandleFunc("/plants", a.handleAdminListPlants).Methods(http.MethodGet)
 	adminRouter.HandleFunc("/plants", a.handleAdminCreatePlant).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/plants/import", a.handleAdminImportPlants).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/plants/export", a.handleAdminExportPlants).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/plants/{plantId}", a.handleAdminUpdatePlant).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/plants/{plantId}", a.handleAdminDeletePlant).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/species", a.handleAdminListSpecies).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/species", a.handleAdminCreateSpecies).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/species/{speciesId}", a.handleAdminGetSpecies).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/species/{speciesId}", a.handleAdminUpdateSpecies).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/care-instructions/stale", a.handleAdminGetStaleCareInstructions).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/llm/self-test", a.handleAdminYandexGPTSelfTest).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/llm/usage", a.handleAdminGetLLMUsage).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/plants/{plantId}/fun-facts", a.handleAdminGenerateFunFacts).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/plants/{plantId}/image", a.handleAdminSetPlantImage).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/plants/{plantId}/difficulty", a.handleAdminUpdatePlantDifficulty).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/plants/{plantId}/translations", a.handleAdminGetPlantTranslations).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/plants/{plantId}/translations/{language}", a.handleAdminSetPlantTranslation).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/plants/{plantId}/translations/{language}", a.handleAdminDeletePlantTranslation).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/plants/{plantId}/taxonomy", a.handleAdminGetPlantTaxonomy).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/plants/{plantId}/taxonomy", a.handleAdminSetPlantTaxonomy).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/categories", a.handleGetCategories).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/categories", a.handleAdminCreateCategory).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/categories/{categoryId}", a.handleAdminUpdateCategory).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/categories/{categoryId}", a.handleAdminDeleteCategory).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/seasonal-guide/overrides", a.handleAdminGetSeasonalGuideOverrides).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/seasonal-guide/overrides", a.handleAdminSetSeasonalGuideOverride).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/seasonal-guide/overrides/{overrideId}", a.handleAdminDeleteSeasonalGuideOverride).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/shops", a.handleAdminCreateShop).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/shops/import", a.handleAdminImportShops).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/shops/{shopId}", a.handleAdminUpdateShop).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/shops/{shopId}", a.handleAdminDeleteShop).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/shops/{shopId}/plants", a.handleAdminAddShopPlant).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/shops/{shopId}/plants/{plantId}", a.handleAdminUpdateShopPlant).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/shops/{shopId}/plants/{plantId}", a.handleAdminRemoveShopPlant).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/special-offers", a.handleAdminGetSpecialOffers).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/special-offers", a.handleAdminCreateSpecialOffer).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/special-offers/{offerId}", a.handleAdminUpdateSpecialOffer).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/special-offers/{offerId}", a.handleAdminDeleteSpecialOffer).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/fun-facts/pending", a.handleAdminGetPendingFunFacts).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/fun-facts/{factId}", a.handleAdminReviewFunFact).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/plant-enrichment/runs", a.handleAdminRunPlantEnrichment).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/plant-enrichment/proposals", a.handleAdminGetPlantEnrichmentProposals).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/plant-enrichment/proposals/{proposalId}", a.handleAdminReviewPlantEnrichmentProposal).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/notification-templates", a.handleAdminGetNotificationTemplates).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/notification-templates/{type}/{language}", a.handleAdminUpdateNotificationTemplate).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/notifications", a.handleAdminSendNotification).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/notifications/care-check", a.handleAdminRunCareNotifications).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/announcements", a.handleAdminListAnnouncements).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/announcements", a.handleAdminCreateAnnouncement).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/announcements/{announcementId}", a.handleAdminGetAnnouncement).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/announcements/{announcementId}/cancel", a.handleAdminCancelAnnouncement).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/changelog", a.handleAdminListChangelog).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/changelog", a.handleAdminCreateChangelogEntry).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/changelog/{entryId}", a.handleAdminGetChangelogEntry).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/changelog/{entryId}", a.handleAdminUpdateChangelogEntry).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/changelog/{entryId}", a.handleAdminDeleteChangelogEntry).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/photos/quarantined", a.handleAdminGetQuarantinedPhotos).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/photos/{photoId}/approve", a.handleAdminApprovePhoto).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/photos/{photoId}/reject", a.handleAdminRejectPhoto).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/events/stats", a.handleAdminGetEventStats).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/reconciliation/runs", a.handleAdminGetReconciliationRuns).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/reconciliation/runs", a.handleAdminRunReconciliation).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/support/tickets", a.handleAdminListSupportTickets).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/support/tickets/{ticketId}", a.handleAdminGetSupportTicket).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/support/tickets/{ticketId}", a.handleAdminUpdateSupportTicket).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/anonymization/runs", a.handleAdminGetAnonymizationRuns).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/anonymization/runs", a.handleAdminRunAnonymization).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/account-merges", a.handleAdminGetAccountMerges).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/account-merges", a.handleAdminMergeAccounts).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/captured-requests", a.handleAdminListCapturedRequests).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/captured-requests/{requestId}", a.handleAdminGetCapturedRequest).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/captured-requests/{requestId}/replay", a.handleAdminReplayCapturedRequest).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/audit-log", a.handleAdminGetAuditLog).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/users/{userId}", a.handleAdminDeleteUser).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/sagas/stuck", a.handleAdminGetStuckSagas).Methods(http.MethodGet)
 	
 	// Chat routes (require authentication)
 	chatRouter := a.router.PathPrefix("/chat").Subrouter()
@@ -97,12 +512,69 @@ func (a *API) setupRoutes() {
 	chatRouter.HandleFunc("/sessions", a.handleCreateChatSession).Methods(http.MethodPost)
 	chatRouter.HandleFunc("/sessions", a.handleGetChatSessions).Methods(http.MethodGet)
 	chatRouter.HandleFunc("/sessions/{sessionId}", a.handleGetChatSession).Methods(http.MethodGet)
+	chatRouter.HandleFunc("/sessions/{sessionId}", a.handleRenameChatSession).Methods(http.MethodPatch)
+	chatRouter.HandleFunc("/sessions/{sessionId}", a.handleDeleteChatSession).Methods(http.MethodDelete)
 	chatRouter.HandleFunc("/sessions/{sessionId}/messages", a.handleGetChatMessages).Methods(http.MethodGet)
 	chatRouter.HandleFunc("/sessions/{sessionId}/messages", a.handleSendChatMessage).Methods(http.MethodPost)
+	chatRouter.HandleFunc("/sessions/{sessionId}/escalate", a.handleEscalateChatSession).Methods(http.MethodPost)
+
+	// Quiz routes (require authentication)
+	quizRouter := a.router.PathPrefix("/quiz").Subrouter()
+	quizRouter.Use(a.auth.RequireAuth)
+	quizRouter.HandleFunc("/daily", a.handleGetDailyQuiz).Methods(http.MethodGet)
+	quizRouter.HandleFunc("/answers", a.handleSubmitQuizAnswers).Methods(http.MethodPost)
+	quizRouter.HandleFunc("/leaderboard", a.handleGetQuizLeaderboard).Methods(http.MethodGet)
+
+	// Catalog review routes (require the editor, reviewer or admin role); the review queue is only for
+	// reviewers and admins, and who may move a plant between review states depends on the move
+	catalogRouter := a.router.PathPrefix("/catalog").Subrouter()
+	catalogRouter.Use(a.roleAuth.RequireAnyRole(string(models.RoleEditor), string(models.RoleReviewer), string(models.RoleAdmin)))
+	catalogRouter.HandleFunc("/drafts", a.handleCatalogListDrafts).Methods(http.MethodGet)
+	catalogRouter.Handle("/review-queue", a.roleAuth.RequireAnyRole(string(models.RoleReviewer), string(models.RoleAdmin))(http.HandlerFunc(a.handleCatalogGetReviewQueue))).Methods(http.MethodGet)
+	catalogRouter.HandleFunc("/plants", a.handleCatalogCreatePlant).Methods(http.MethodPost)
+	catalogRouter.HandleFunc("/plants/{plantId}", a.handleCatalogGetPlant).Methods(http.MethodGet)
+	catalogRouter.HandleFunc("/plants/{plantId}", a.handleCatalogUpdatePlant).Methods(http.MethodPut)
+	catalogRouter.HandleFunc("/plants/{plantId}/status", a.handleCatalogSetPlantStatus).Methods(http.MethodPut)
+
+	// Expert routes (require the expert or admin role)
+	expertRouter := a.router.PathPrefix("/expert").Subrouter()
+	expertRouter.Use(a.roleAuth.RequireAnyRole(string(models.RoleExpert), string(models.RoleAdmin)))
+	expertRouter.HandleFunc("/escalations", a.handleExpertListEscalations).Methods(http.MethodGet)
+	expertRouter.HandleFunc("/escalations/{sessionId}", a.handleExpertGetEscalation).Methods(http.MethodGet)
+	expertRouter.HandleFunc("/escalations/{sessionId}/claim", a.handleExpertClaimEscalation).Methods(http.MethodPost)
+	expertRouter.HandleFunc("/escalations/{sessionId}/messages", a.handleExpertReplyToEscalation).Methods(http.MethodPost)
+	expertRouter.HandleFunc("/escalations/{sessionId}/resolve", a.handleExpertResolveEscalation).Methods(http.MethodPost)
+
+	// Public API routes (require an API key)
+	a.router.HandleFunc("/public/v1/docs", a.handlePublicDocs).Methods(http.MethodGet)
+	publicRouter := a.router.PathPrefix("/public/v1").Subrouter()
+	publicRouter.Use(a.apiKeyAuth.RequireAPIKey)
+	publicRouter.HandleFunc("/plants", a.handleGetAllPlants).Methods(http.MethodGet)
+	publicRouter.HandleFunc("/plants/search", a.handleSearchPlants).Methods(http.MethodGet)
+	publicRouter.HandleFunc("/plants/{plantId}", a.handleGetPlant).Methods(http.MethodGet)
+	publicRouter.HandleFunc("/plants/{plantId}/care-instructions", a.handlePublicGetPlantCareInstructions).Methods(http.MethodGet)
 
 	// Notification routes
 	a.router.Handle("/notifications", a.auth.RequireAuth(http.HandlerFunc(a.handleGetUserNotifications))).Methods(http.MethodGet)
+	a.router.Handle("/notifications/types", a.auth.RequireAuth(http.HandlerFunc(a.handleGetNotificationTypes))).Methods(http.MethodGet)
+	a.router.Handle("/notifications/unread-count", a.auth.RequireAuth(http.HandlerFunc(a.handleGetUnreadNotificationCount))).Methods(http.MethodGet)
+	a.router.Handle("/notifications/bell", a.auth.RequireAuth(http.HandlerFunc(a.handleGetNotificationBell))).Methods(http.MethodGet)
+	a.router.Handle("/notifications/read-all", a.auth.RequireAuth(http.HandlerFunc(a.handleMarkAllNotificationsAsRead))).Methods(http.MethodPost)
+	a.router.Handle("/notifications/{notificationId}", a.auth.RequireAuth(http.HandlerFunc(a.handleDeleteNotification))).Methods(http.MethodDelete)
 	a.router.Handle("/notifications/{notificationId}/read", a.auth.RequireAuth(http.HandlerFunc(a.handleMarkNotificationAsRead))).Methods(http.MethodPost)
+
+	// Changelog routes (require authentication)
+	a.router.Handle("/changelog", a.auth.RequireAuth(http.HandlerFunc(a.handleGetChangelog))).Methods(http.MethodGet)
+	a.router.Handle("/changelog/seen", a.auth.RequireAuth(http.HandlerFunc(a.handleMarkChangelogSeen))).Methods(http.MethodPost)
+
+	// Notification action route (the signed single-use token authorizes the action)
+	a.router.HandleFunc("/actions/{token}", a.handleNotificationAction).Methods(http.MethodPost)
+
+	// Real-time notification stream; browsers cannot set headers on the handshake, so the token may be in the query
+	a.router.Handle("/ws/notifications", a.auth.RequireAuthOrQueryToken(http.HandlerFunc(a.handleNotificationStream))).Methods(http.MethodGet)
+
+	// Support routes
+	a.router.Handle("/support/tickets", a.auth.RequireAuth(http.HandlerFunc(a.handleCreateSupportTicket))).Methods(http.MethodPost)
 }
 
 // Handler returns the HTTP handler for the API
@@ -110,17 +582,32 @@ func (a *API) Handler() http.Handler {
 	// Set up CORS
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", middleware.APIKeyHeader, AppVersionHeader, dto.ClientProfileHeader},
+		ExposedHeaders:   []string{middleware.DemoModeHeader},
 		AllowCredentials: true,
 	})
 
+	// Capture the requests answered with a server error so they can be replayed on staging
+	var handler http.Handler = a.router
+	if a.requestCaptureService.Enabled() {
+		handler = middleware.NewRequestCapture(a.auth, a.requestCaptureService).Middleware(handler)
+	}
+
 	// Wrap router with logging middleware and CORS
-	return c.Handler(middleware.LoggingMiddleware(a.router))
+	return c.Handler(middleware.LoggingMiddleware(handler))
 }
 
-// Start starts the API server
-func (a *API) Start(cfg *config.Config) error {
-	addr := ":" + cfg.Server.Port
-	return http.ListenAndServe(addr, a.Handler())
+// Start starts the API server and serves until the context is cancelled, then drains in-flight requests
+func (a *API) Start(ctx context.Context, cfg *config.Config) error {
+	srv := server.New(":"+cfg.Server.Port, a.Handler(), server.Timeouts{
+		Read:     time.Duration(cfg.Server.ReadTimeout) * time.Second,
+		Write:    time.Duration(cfg.Server.WriteTimeout) * time.Second,
+		Idle:     time.Duration(cfg.Server.IdleTimeout) * time.Second,
+		Shutdown: time.Duration(cfg.Server.ShutdownTimeout) * time.Second,
+	})
+	if a.notificationHub != nil {
+		srv.RegisterOnShutdown(a.notificationHub.Close)
+	}
+	return srv.Run(ctx)
 }
\ No newline at end of file
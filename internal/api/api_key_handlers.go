@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleCreateAPIKey handles the create API key request
+func (a *API) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Create the API key
+	resp, err := a.apiKeyService.CreateAPIKey(r.Context(), userID, req.Name)
+	if err != nil {
+		log.Printf("Failed to create API key for user %s: %v", userID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	// Respond with the API key, including the raw key which is not shown again
+	utils.RespondWithJSON(w, http.StatusCreated, resp)
+}
+
+// handleGetAPIKeys handles the get API keys request
+func (a *API) handleGetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the API keys
+	apiKeys, err := a.apiKeyService.GetAPIKeys(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get API keys")
+		return
+	}
+
+	// Respond with the API keys
+	utils.RespondWithJSON(w, http.StatusOK, apiKeys)
+}
+
+// handleRevokeAPIKey handles the revoke API key request
+func (a *API) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the API key ID from the URL
+	vars := mux.Vars(r)
+	keyID, err := uuid.Parse(vars["keyId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	// Revoke the API key
+	err = a.apiKeyService.RevokeAPIKey(r.Context(), userID, keyID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "API key not found")
+		return
+	}
+
+	// Respond with success
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "API key revoked"})
+}
+
+// handleGetAPIKeyUsage handles the get API key usage request
+func (a *API) handleGetAPIKeyUsage(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the API key ID from the URL
+	vars := mux.Vars(r)
+	keyID, err := uuid.Parse(vars["keyId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	// Get the reporting period
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+
+	// Get the usage
+	usage, err := a.apiKeyService.GetAPIKeyUsage(r.Context(), userID, keyID, days)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "API key not found")
+		return
+	}
+
+	// Respond with the usage
+	utils.RespondWithJSON(w, http.StatusOK, usage)
+}
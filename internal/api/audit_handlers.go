@@ -0,0 +1,124 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// auditAdminActions records the successful changes made through the admin routes in the audit log,
+// once the audit trail is set
+func (a *API) auditAdminActions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.auditTrail == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		a.auditTrail.Middleware(next).ServeHTTP(w, r)
+	})
+}
+
+// handleAdminGetAuditLog handles the admin get audit log request
+func (a *API) handleAdminGetAuditLog(w http.ResponseWriter, r *http.Request) {
+	if a.auditTrail == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Audit log is not available")
+		return
+	}
+	query := r.URL.Query()
+
+	// Parse the query parameters
+	filter := models.AuditLogFilter{
+		EntityType: query.Get("entityType"),
+		EntityID:   query.Get("entityId"),
+		Action:     query.Get("action"),
+	}
+	if value := query.Get("actorId"); value != "" {
+		actorID, err := uuid.Parse(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid actorId parameter")
+			return
+		}
+		filter.ActorID = &actorID
+	}
+	for name, bound := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				utils.RespondWithError(w, http.StatusBadRequest, "Invalid "+name+" parameter")
+				return
+			}
+			*bound = &parsed
+		}
+	}
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid page parameter")
+			return
+		}
+		filter.Page = page
+	}
+	if value := query.Get("pageSize"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid pageSize parameter")
+			return
+		}
+		filter.PageSize = pageSize
+	}
+
+	// Get the page of entries
+	page, err := a.auditTrail.List(r.Context(), &filter)
+	if err != nil {
+		log.Printf("Failed to get audit log: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get audit log")
+		return
+	}
+
+	// Respond with the entries
+	utils.RespondWithJSON(w, http.StatusOK, page)
+}
+
+// handleAdminDeleteUser handles the admin delete user request
+func (a *API) handleAdminDeleteUser(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the URL
+	userID, err := uuid.Parse(mux.Vars(r)["userId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Admins cannot lock themselves out
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if adminID == userID {
+		utils.RespondWithError(w, http.StatusConflict, "Admins cannot delete their own account")
+		return
+	}
+
+	// Delete the user
+	if err := a.userService.DeleteUser(r.Context(), userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Printf("Failed to delete user %s: %v", userID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete user")
+		return
+	}
+
+	// Respond with no content
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -19,7 +19,7 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 	// Validate the request
 	if err := utils.Validate.Struct(req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, utils.ValidationErrorMessage(err))
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
 		return
 	}
 
@@ -45,7 +45,7 @@ func (a *API) handleRegister(w http.ResponseWriter, r *http.Request) {
 
 	// Validate the request
 	if err := utils.Validate.Struct(req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, utils.ValidationErrorMessage(err))
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
 		return
 	}
 
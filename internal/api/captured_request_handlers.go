@@ -0,0 +1,99 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleAdminListCapturedRequests handles the admin list captured requests request
+func (a *API) handleAdminListCapturedRequests(w http.ResponseWriter, r *http.Request) {
+	// Get the number of requests
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	// Get the captured requests
+	requests, err := a.requestCaptureService.ListCaptured(r.Context(), limit)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get captured requests")
+		return
+	}
+
+	// Respond with the captured requests
+	utils.RespondWithJSON(w, http.StatusOK, requests)
+}
+
+// handleAdminGetCapturedRequest handles the admin get captured request request
+func (a *API) handleAdminGetCapturedRequest(w http.ResponseWriter, r *http.Request) {
+	// Get the captured request ID from the URL
+	requestID, err := uuid.Parse(mux.Vars(r)["requestId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid captured request ID")
+		return
+	}
+
+	// Get the captured request
+	request, err := a.requestCaptureService.GetCaptured(r.Context(), requestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Captured request not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get captured request")
+		return
+	}
+
+	// Respond with the captured request
+	utils.RespondWithJSON(w, http.StatusOK, request)
+}
+
+// handleAdminReplayCapturedRequest handles the admin replay captured request request
+func (a *API) handleAdminReplayCapturedRequest(w http.ResponseWriter, r *http.Request) {
+	// Get the captured request ID from the URL
+	requestID, err := uuid.Parse(mux.Vars(r)["requestId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid captured request ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.ReplayCapturedRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Replay the request as the chosen user
+	result, err := a.requestCaptureService.Replay(r.Context(), requestID, req.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReplayUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrCapturedBodyIncomplete):
+			utils.RespondWithError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Captured request not found")
+		default:
+			log.Printf("Failed to replay captured request %s: %v", requestID, err)
+			utils.RespondWithError(w, http.StatusBadGateway, "Failed to replay captured request")
+		}
+		return
+	}
+
+	// Respond with the response of the replay target
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetPlantDifficulty handles the get plant difficulty request
+func (a *API) handleGetPlantDifficulty(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the difficulty
+	difficulty, err := a.careFeedbackService.GetDifficulty(r.Context(), plantID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		return
+	}
+
+	// Respond with the difficulty
+	utils.RespondWithJSON(w, http.StatusOK, difficulty)
+}
+
+// handleSubmitCareFeedback handles the submit care feedback request
+func (a *API) handleSubmitCareFeedback(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.SubmitCareFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Save the feedback
+	feedback, err := a.careFeedbackService.SubmitFeedback(r.Context(), userID, plantID, req.Rating)
+	if errors.Is(err, services.ErrCareFeedbackNotEligible) {
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+		return
+	}
+
+	// Respond with the feedback
+	utils.RespondWithJSON(w, http.StatusOK, feedback)
+}
+
+// handleAdminUpdatePlantDifficulty handles the admin update plant difficulty request
+func (a *API) handleAdminUpdatePlantDifficulty(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.UpdatePlantDifficultyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Set the editorial difficulty
+	if err := a.careFeedbackService.SetEditorialDifficulty(r.Context(), plantID, req.Difficulty); err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		return
+	}
+
+	// Respond with the updated difficulty
+	difficulty, err := a.careFeedbackService.GetDifficulty(r.Context(), plantID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plant difficulty")
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, difficulty)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGenerateCarePlan handles the generate care plan request
+func (a *API) handleGenerateCarePlan(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body, which is optional
+	var req models.CarePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Generate the care plan
+	plan, err := a.carePlanService.GeneratePlan(r.Context(), userID, plantID, &req, a.resolveClientLanguage(r))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to generate care plan")
+		return
+	}
+
+	// Respond with the care plan
+	utils.RespondWithJSON(w, http.StatusCreated, plan)
+}
+
+// handleGetCarePlan handles the get care plan request
+func (a *API) handleGetCarePlan(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the care plan
+	plan, err := a.carePlanService.GetPlan(r.Context(), userID, plantID)
+	if err != nil {
+		if errors.Is(err, services.ErrCarePlanNotFound) {
+			utils.RespondWithError(w, http.StatusNotFound, "Care plan not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get care plan")
+		return
+	}
+
+	// Respond with the care plan
+	utils.RespondWithJSON(w, http.StatusOK, plan)
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetCareTasks handles the get weekly care tasks request
+func (a *API) handleGetCareTasks(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the weekly checklist
+	checklist, err := a.careTaskService.GetWeeklyTasks(r.Context(), userID, plantID, r.URL.Query().Get("week"))
+	if errors.Is(err, services.ErrInvalidCareTask) {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+		return
+	}
+
+	// Respond with the checklist
+	utils.RespondWithJSON(w, http.StatusOK, checklist)
+}
+
+// handleCompleteCareTask handles the complete care task request
+func (a *API) handleCompleteCareTask(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Complete the task
+	task, err := a.careTaskService.CompleteTask(r.Context(), userID, plantID, vars["taskId"])
+	if errors.Is(err, services.ErrInvalidCareTask) {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+		return
+	}
+
+	// Respond with the completed task
+	utils.RespondWithJSON(w, http.StatusOK, task)
+}
+
+// handleGetCareTaskAdherence handles the get care task adherence request
+func (a *API) handleGetCareTaskAdherence(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the number of weeks to report on
+	weeks := 4
+	if value := r.URL.Query().Get("weeks"); value != "" {
+		weeks, err = strconv.Atoi(value)
+		if err != nil || weeks <= 0 || weeks > 52 {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid weeks parameter")
+			return
+		}
+	}
+
+	// Get the adherence stats
+	stats, err := a.careTaskService.GetAdherence(r.Context(), userID, plantID, weeks)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+		return
+	}
+
+	// Respond with the stats
+	utils.RespondWithJSON(w, http.StatusOK, stats)
+}
+
+// handleGetCareSchedule handles the get upcoming care tasks request
+func (a *API) handleGetCareSchedule(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the number of days to schedule
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, err = strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid days parameter")
+			return
+		}
+	}
+
+	// Get the schedule
+	schedule, err := a.careTaskService.GetSchedule(r.Context(), userID, plantID, days)
+	if errors.Is(err, services.ErrInvalidCareTask) {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+		return
+	}
+
+	// Respond with the schedule
+	utils.RespondWithJSON(w, http.StatusOK, schedule)
+}
+
+// handleUpdateCareSchedule handles the replace recurring care tasks request
+func (a *API) handleUpdateCareSchedule(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.UpdateCareScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Replace the recurring tasks
+	tasks, err := a.careTaskService.UpdateSchedule(r.Context(), userID, plantID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCareTask):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update care schedule")
+		}
+		return
+	}
+
+	// Respond with the recurring tasks
+	utils.RespondWithJSON(w, http.StatusOK, tasks)
+}
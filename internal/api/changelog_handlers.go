@@ -0,0 +1,234 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetChangelog handles the get changelog request: the release notes published for the user in
+// their language, only the unseen ones with ?unseen=true
+func (a *API) handleGetChangelog(w http.ResponseWriter, r *http.Request) {
+	if a.changelogService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Changelog is not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the changelog
+	unseenOnly := r.URL.Query().Get("unseen") == "true"
+	changelog, err := a.changelogService.GetChangelog(r.Context(), userID, a.resolveClientLanguage(r), unseenOnly)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get changelog")
+		return
+	}
+
+	// Respond with the changelog
+	utils.RespondWithJSON(w, http.StatusOK, changelog)
+}
+
+// handleMarkChangelogSeen handles the mark changelog seen request, sent once the app showed the
+// entries to the user
+func (a *API) handleMarkChangelogSeen(w http.ResponseWriter, r *http.Request) {
+	if a.changelogService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Changelog is not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse and validate the request body
+	var req models.MarkChangelogSeenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Mark the entries as seen
+	if _, err := a.changelogService.MarkSeen(r.Context(), userID, req.EntryIDs); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to mark changelog as seen")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminListChangelog handles the admin list changelog request
+func (a *API) handleAdminListChangelog(w http.ResponseWriter, r *http.Request) {
+	if a.changelogService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Changelog is not available")
+		return
+	}
+
+	// Get the entries
+	entries, err := a.changelogService.ListEntries(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get changelog entries")
+		return
+	}
+
+	// Respond with the entries
+	utils.RespondWithJSON(w, http.StatusOK, entries)
+}
+
+// handleAdminGetChangelogEntry handles the admin get changelog entry request
+func (a *API) handleAdminGetChangelogEntry(w http.ResponseWriter, r *http.Request) {
+	if a.changelogService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Changelog is not available")
+		return
+	}
+
+	// Get the entry ID from the URL
+	entryID, err := uuid.Parse(mux.Vars(r)["entryId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid changelog entry ID")
+		return
+	}
+
+	// Get the entry
+	entry, err := a.changelogService.GetEntry(r.Context(), entryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Changelog entry not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get changelog entry")
+		return
+	}
+
+	// Respond with the entry
+	utils.RespondWithJSON(w, http.StatusOK, entry)
+}
+
+// handleAdminCreateChangelogEntry handles the admin create changelog entry request
+func (a *API) handleAdminCreateChangelogEntry(w http.ResponseWriter, r *http.Request) {
+	if a.changelogService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Changelog is not available")
+		return
+	}
+
+	// Get the authenticated admin ID from the context
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse and validate the request body
+	var req models.SaveChangelogEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Create the entry
+	entry, err := a.changelogService.CreateEntry(r.Context(), adminID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidChangelogEntry) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create changelog entry")
+		return
+	}
+
+	// Respond with the created entry
+	utils.RespondWithJSON(w, http.StatusCreated, entry)
+}
+
+// handleAdminUpdateChangelogEntry handles the admin update changelog entry request
+func (a *API) handleAdminUpdateChangelogEntry(w http.ResponseWriter, r *http.Request) {
+	if a.changelogService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Changelog is not available")
+		return
+	}
+
+	// Get the entry ID from the URL
+	entryID, err := uuid.Parse(mux.Vars(r)["entryId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid changelog entry ID")
+		return
+	}
+
+	// Parse and validate the request body
+	var req models.SaveChangelogEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Update the entry
+	entry, err := a.changelogService.UpdateEntry(r.Context(), entryID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidChangelogEntry):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Changelog entry not found")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update changelog entry")
+		}
+		return
+	}
+
+	// Respond with the updated entry
+	utils.RespondWithJSON(w, http.StatusOK, entry)
+}
+
+// handleAdminDeleteChangelogEntry handles the admin delete changelog entry request
+func (a *API) handleAdminDeleteChangelogEntry(w http.ResponseWriter, r *http.Request) {
+	if a.changelogService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Changelog is not available")
+		return
+	}
+
+	// Get the entry ID from the URL
+	entryID, err := uuid.Parse(mux.Vars(r)["entryId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid changelog entry ID")
+		return
+	}
+
+	// Delete the entry
+	if err := a.changelogService.DeleteEntry(r.Context(), entryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Changelog entry not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete changelog entry")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
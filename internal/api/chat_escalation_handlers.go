@@ -0,0 +1,216 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleEscalateChatSession handles the ask for a human expert request
+func (a *API) handleEscalateChatSession(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the chat session ID from the URL
+	sessionID, err := uuid.Parse(mux.Vars(r)["sessionId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	// Parse the request body; the reason is optional, so an empty body is accepted
+	var req models.EscalateChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Escalate the session
+	session, err := a.chatEscalationService.Escalate(r.Context(), sessionID, userID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Chat session not found")
+		case errors.Is(err, services.ErrChatSessionForbidden):
+			utils.RespondWithError(w, http.StatusForbidden, "Forbidden")
+		case errors.Is(err, services.ErrChatEscalationOpen):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		default:
+			log.Printf("Failed to escalate chat session %s: %v", sessionID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to escalate chat session")
+		}
+		return
+	}
+
+	// Respond with the escalated session
+	utils.RespondWithJSON(w, http.StatusOK, session)
+}
+
+// handleExpertListEscalations handles the expert list escalated chat sessions request
+func (a *API) handleExpertListEscalations(w http.ResponseWriter, r *http.Request) {
+	// Get the escalations with the status
+	status := models.ChatEscalationStatus(r.URL.Query().Get("status"))
+	sessions, err := a.chatEscalationService.ListEscalations(r.Context(), status)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidChatEscalationStatus) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get chat escalations")
+		return
+	}
+
+	// Respond with the escalations
+	utils.RespondWithJSON(w, http.StatusOK, sessions)
+}
+
+// handleExpertGetEscalation handles the expert get escalated chat session request
+func (a *API) handleExpertGetEscalation(w http.ResponseWriter, r *http.Request) {
+	// Get the chat session ID from the URL
+	sessionID, err := uuid.Parse(mux.Vars(r)["sessionId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	// Get the escalation with its conversation
+	escalation, err := a.chatEscalationService.GetEscalation(r.Context(), sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Chat escalation not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get chat escalation")
+		return
+	}
+
+	// Respond with the escalation
+	utils.RespondWithJSON(w, http.StatusOK, escalation)
+}
+
+// handleExpertClaimEscalation handles the expert claim escalated chat session request
+func (a *API) handleExpertClaimEscalation(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated expert ID
+	expertID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the chat session ID from the URL
+	sessionID, err := uuid.Parse(mux.Vars(r)["sessionId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	// Claim the escalation
+	session, err := a.chatEscalationService.Claim(r.Context(), sessionID, expertID)
+	if err != nil {
+		a.respondWithEscalationError(w, err, "Failed to claim chat escalation")
+		return
+	}
+
+	// Respond with the claimed session
+	utils.RespondWithJSON(w, http.StatusOK, session)
+}
+
+// handleExpertReplyToEscalation handles the expert answer in an escalated chat session request
+func (a *API) handleExpertReplyToEscalation(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated expert ID
+	expertID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the chat session ID from the URL
+	sessionID, err := uuid.Parse(mux.Vars(r)["sessionId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Add the answer to the session
+	message, err := a.chatEscalationService.Reply(r.Context(), sessionID, expertID, req.Message)
+	if err != nil {
+		a.respondWithEscalationError(w, err, "Failed to send expert reply")
+		return
+	}
+
+	// Respond with the message
+	utils.RespondWithJSON(w, http.StatusCreated, models.ChatResponse{Message: *message})
+}
+
+// handleExpertResolveEscalation handles the expert resolve escalated chat session request
+func (a *API) handleExpertResolveEscalation(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated expert ID
+	expertID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the chat session ID from the URL
+	sessionID, err := uuid.Parse(mux.Vars(r)["sessionId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	// Resolve the escalation
+	session, err := a.chatEscalationService.Resolve(r.Context(), sessionID, expertID)
+	if err != nil {
+		a.respondWithEscalationError(w, err, "Failed to resolve chat escalation")
+		return
+	}
+
+	// Respond with the resolved session
+	utils.RespondWithJSON(w, http.StatusOK, session)
+}
+
+// respondWithEscalationError responds with the status of an error of an expert's action on an escalation
+func (a *API) respondWithEscalationError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		utils.RespondWithError(w, http.StatusNotFound, "Chat escalation not found")
+	case errors.Is(err, services.ErrChatEscalationTaken), errors.Is(err, services.ErrChatEscalationNotClaimed):
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("%s: %v", message, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, message)
+	}
+}
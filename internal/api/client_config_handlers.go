@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// AppVersionHeader is the header carrying the client app version
+const AppVersionHeader = "X-App-Version"
+
+// handleGetClientConfig handles the get client config request
+func (a *API) handleGetClientConfig(w http.ResponseWriter, r *http.Request) {
+	// Get the app version from the header or the query
+	appVersion := r.Header.Get(AppVersionHeader)
+	if appVersion == "" {
+		appVersion = r.URL.Query().Get("appVersion")
+	}
+
+	// Build the client config
+	config := a.clientConfigService.GetClientConfig(appVersion, a.resolveClientLanguage(r))
+
+	// Respond with the client config
+	utils.RespondWithJSON(w, http.StatusOK, config)
+}
+
+// resolveClientLanguage picks the language for client-facing copy: the lang query parameter,
+// then the authenticated user's preference, then the Accept-Language header
+func (a *API) resolveClientLanguage(r *http.Request) models.Language {
+	switch strings.ToLower(r.URL.Query().Get("lang")) {
+	case "ru", "russian":
+		return models.LanguageRussian
+	case "en", "english":
+		return models.LanguageEnglish
+	}
+
+	if userID, err := middleware.GetUserID(r.Context()); err == nil {
+		if user, err := a.userService.GetUser(r.Context(), userID); err == nil {
+			return user.Language
+		}
+	}
+
+	if language, ok := services.ParseAcceptLanguage(r.Header.Get("Accept-Language")); ok {
+		return language
+	}
+
+	return models.LanguageRussian
+}
+
+// resolveClientLocation picks the time zone humanized times are counted in: the tz query parameter,
+// then the authenticated user's timezone, then UTC
+func (a *API) resolveClientLocation(r *http.Request) *time.Location {
+	if location, err := services.LoadTimezone(r.URL.Query().Get("tz")); err == nil {
+		return location
+	}
+
+	if userID, err := middleware.GetUserID(r.Context()); err == nil {
+		if user, err := a.userService.GetUser(r.Context(), userID); err == nil && user.Timezone != nil {
+			if location, err := services.LoadTimezone(*user.Timezone); err == nil {
+				return location
+			}
+		}
+	}
+
+	return time.UTC
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// demoPassthroughPrefixes lists route templates the demo account may really use; chat only
+// touches the demo account's own sessions and is the main thing reviewers want to try, and
+// compatibility checks change nothing
+var demoPassthroughPrefixes = []string{"/chat/", "/plants/compatibility"}
+
+// demoMessages holds the responses of simple mutations, keyed by method and route template
+var demoMessages = map[string]string{
+	http.MethodPost + " /plants/{plantId}/favorite":                "Added to favorites",
+	http.MethodDelete + " /plants/{plantId}/favorite":              "Removed from favorites",
+	http.MethodPost + " /plants/user/{plantId}":                    "Plant added to collection",
+	http.MethodPut + " /plants/user/{plantId}":                     "Plant updated",
+	http.MethodDelete + " /plants/user/{plantId}":                  "Plant removed from collection",
+	http.MethodDelete + " /plants/user/{plantId}/photos/{photoId}": "Photo deleted",
+	http.MethodPost + " /notifications/{notificationId}/read":      "Notification marked as read",
+	http.MethodPost + " /support/tickets":                          "Support ticket received",
+}
+
+// demoSandbox is a middleware that accepts mutations of the demo account without saving them
+func (a *API) demoSandbox(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !middleware.IsDemoRequest(r.Context()) || r.Method == http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		routeTemplate := ""
+		if route := mux.CurrentRoute(r); route != nil {
+			routeTemplate, _ = route.GetPathTemplate()
+		}
+		for _, prefix := range demoPassthroughPrefixes {
+			if strings.HasPrefix(routeTemplate, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		a.handleDemoMutation(w, r, r.Method+" "+routeTemplate)
+	})
+}
+
+// handleDemoMutation responds to a demo account mutation as the real handler would, without saving it
+func (a *API) handleDemoMutation(w http.ResponseWriter, r *http.Request, route string) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if message, ok := demoMessages[route]; ok {
+		utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": message})
+		return
+	}
+
+	vars := mux.Vars(r)
+	switch route {
+	case http.MethodPost + " /plants/{plantId}/water":
+		// Get the plant ID from the URL
+		plantID, err := uuid.Parse(vars["plantId"])
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+			return
+		}
+
+		plant, err := a.demoService.SimulateMarkAsWatered(r.Context(), userID, plantID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to mark as watered")
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, plant)
+
+	case http.MethodPut + " /users/{userId}":
+		// Check if the user is updating their own data
+		if vars["userId"] != userID.String() {
+			utils.RespondWithError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		// Apply the submitted fields to the current profile
+		user, err := a.userService.GetUser(r.Context(), userID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update user")
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(user); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		user.ID = userID
+		utils.RespondWithJSON(w, http.StatusOK, user)
+
+	case http.MethodPost + " /notifications/read-all":
+		// Report the unread notifications as marked
+		count, err := a.notificationService.GetUnreadCount(r.Context(), userID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to mark notifications as read")
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, models.NotificationCountResponse{Count: count})
+
+	case http.MethodDelete + " /notifications/{notificationId}":
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		utils.RespondWithJSON(w, http.StatusAccepted, map[string]string{"message": "Demo mode: changes are not saved"})
+	}
+}
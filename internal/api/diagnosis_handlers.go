@@ -0,0 +1,106 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxDiagnosisPhotoBytes limits the size of an uploaded diagnosis photo
+const maxDiagnosisPhotoBytes = 10 << 20
+
+// handleDiagnosePlant handles the diagnose plant request
+func (a *API) handleDiagnosePlant(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the multipart form
+	r.Body = http.MaxBytesReader(w, r.Body, maxDiagnosisPhotoBytes+1<<20)
+	if err := r.ParseMultipartForm(maxDiagnosisPhotoBytes); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid form or photo too large")
+		return
+	}
+
+	// Get the plant ID from the form
+	plantID, err := uuid.Parse(r.FormValue("plantId"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Read the photo
+	file, _, err := r.FormFile("photo")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Photo is required")
+		return
+	}
+	defer file.Close()
+
+	photo, err := io.ReadAll(io.LimitReader(file, maxDiagnosisPhotoBytes+1))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to read photo")
+		return
+	}
+	if len(photo) > maxDiagnosisPhotoBytes {
+		utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "Photo too large")
+		return
+	}
+
+	// Diagnose the plant
+	diagnosis, err := a.diagnosisService.Diagnose(r.Context(), userID, plantID, photo)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDiagnosisUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrUnsupportedDiagnosisImage):
+			utils.RespondWithError(w, http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to diagnose plant")
+		}
+		return
+	}
+
+	// Respond with the diagnosis
+	utils.RespondWithJSON(w, http.StatusCreated, diagnosis)
+}
+
+// handleGetPlantDiagnoses handles the get plant diagnoses request
+func (a *API) handleGetPlantDiagnoses(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the diagnoses
+	diagnoses, err := a.diagnosisService.GetDiagnoses(r.Context(), userID, plantID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get diagnoses")
+		return
+	}
+
+	// Respond with the diagnoses
+	utils.RespondWithJSON(w, http.StatusOK, diagnoses)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+)
+
+// maxEventsBodyBytes limits the size of an analytics event batch
+const maxEventsBodyBytes = 1 << 20
+
+// handleTrackEvents handles the analytics event ingestion request
+func (a *API) handleTrackEvents(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context, if any
+	var userID *uuid.UUID
+	if id, err := middleware.GetUserID(r.Context()); err == nil {
+		userID = &id
+	}
+
+	// Parse the request body
+	var req models.TrackEventsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEventsBodyBytes)).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Buffer the events
+	accepted, err := a.analyticsService.TrackEvents(r.Context(), userID, req.Events)
+	if errors.Is(err, services.ErrInvalidEvent) {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to track analytics events: %v", err)
+		w.Header().Set("Retry-After", "60")
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Failed to track events")
+		return
+	}
+
+	// Respond with the number of accepted events
+	utils.RespondWithJSON(w, http.StatusAccepted, models.TrackEventsResponse{Accepted: accepted})
+}
+
+// handleAdminGetEventStats handles the admin analytics event stats request
+func (a *API) handleAdminGetEventStats(w http.ResponseWriter, r *http.Request) {
+	// Get the reporting period
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+
+	// Get the event counts
+	counts, err := a.analyticsService.GetDailyCounts(r.Context(), days)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get event stats")
+		return
+	}
+
+	// Respond with the event counts
+	utils.RespondWithJSON(w, http.StatusOK, counts)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetFollowing handles the get followed users request
+func (a *API) handleGetFollowing(w http.ResponseWriter, r *http.Request) {
+	if a.followService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Following users is not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the users the user follows
+	users, err := a.followService.GetFollowing(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get followed users")
+		return
+	}
+
+	// Respond with the users
+	utils.RespondWithJSON(w, http.StatusOK, users)
+}
+
+// handleFollowUser handles the follow user request
+func (a *API) handleFollowUser(w http.ResponseWriter, r *http.Request) {
+	if a.followService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Following users is not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the ID of the user to follow from the URL
+	followeeID, err := uuid.Parse(mux.Vars(r)["userId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Follow the user
+	if err := a.followService.Follow(r.Context(), userID, followeeID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrCannotFollowSelf):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "User not found")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to follow user")
+		}
+		return
+	}
+
+	// Respond with no content
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUnfollowUser handles the unfollow user request
+func (a *API) handleUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	if a.followService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Following users is not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the ID of the followed user from the URL
+	followeeID, err := uuid.Parse(mux.Vars(r)["userId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Stop following the user
+	if err := a.followService.Unfollow(r.Context(), userID, followeeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "User not followed")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to unfollow user")
+		return
+	}
+
+	// Respond with no content
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetPlantFunFacts handles the get plant fun facts request
+func (a *API) handleGetPlantFunFacts(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the approved fun facts
+	facts, err := a.funFactService.GetFunFacts(r.Context(), plantID, a.resolveClientLanguage(r))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get fun facts")
+		return
+	}
+
+	// Respond with the fun facts
+	utils.RespondWithJSON(w, http.StatusOK, facts)
+}
+
+// handleAdminGenerateFunFacts handles the admin generate fun facts request
+func (a *API) handleAdminGenerateFunFacts(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Generate the fun facts for review
+	facts, err := a.funFactService.GenerateFunFacts(r.Context(), plantID, a.resolveClientLanguage(r))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Respond with the generated fun facts
+	utils.RespondWithJSON(w, http.StatusCreated, facts)
+}
+
+// handleAdminGetPendingFunFacts handles the admin get pending fun facts request
+func (a *API) handleAdminGetPendingFunFacts(w http.ResponseWriter, r *http.Request) {
+	// Get the fun facts awaiting review
+	facts, err := a.funFactService.GetPendingFunFacts(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get pending fun facts")
+		return
+	}
+
+	// Respond with the fun facts
+	utils.RespondWithJSON(w, http.StatusOK, facts)
+}
+
+// handleAdminReviewFunFact handles the admin review fun fact request
+func (a *API) handleAdminReviewFunFact(w http.ResponseWriter, r *http.Request) {
+	// Get the fun fact ID from the URL
+	vars := mux.Vars(r)
+	factID, err := uuid.Parse(vars["factId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid fun fact ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.ReviewFunFactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Review the fun fact
+	fact, err := a.funFactService.ReviewFunFact(r.Context(), factID, req.Status)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Fun fact not found")
+		return
+	}
+
+	// Respond with the reviewed fun fact
+	utils.RespondWithJSON(w, http.StatusOK, fact)
+}
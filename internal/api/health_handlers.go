@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/anpanovv/planter/internal/cache"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/redis"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// redisHealthTimeout bounds the Redis ping of the readiness probe
+const redisHealthTimeout = time.Second
+
+// handleReadyz handles the readiness probe request. A failing Yandex GPT check marks
+// the service as degraded but does not fail the probe, since recommendations fall
+// back to the local matcher. An unreachable Redis is reported the same way, since
+// rate limits, caches and locks let requests through while it is down.
+func (a *API) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	// Get the last Yandex GPT status
+	llmStatus := a.recommendationService.GetYandexGPTStatus()
+
+	response := &models.ReadinessResponse{
+		Status:    "ready",
+		YandexGPT: llmStatus,
+	}
+	if llmStatus.Status == models.LLMStatusMisconfigured || llmStatus.Status == models.LLMStatusUnreachable {
+		response.Status = "degraded"
+	}
+
+	// Check Redis
+	if a.redis != nil {
+		response.Redis = checkRedis(r.Context(), a.redis)
+		if response.Redis.Status != "OK" {
+			response.Status = "degraded"
+		}
+	}
+
+	// Respond with the readiness status
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// checkRedis pings Redis and reports its status with the connection pool
+func checkRedis(ctx context.Context, client *redis.Client) *models.RedisStatus {
+	ctx, cancel := context.WithTimeout(ctx, redisHealthTimeout)
+	defer cancel()
+
+	status := &models.RedisStatus{Status: "OK"}
+	if err := client.Ping(ctx); err != nil {
+		status.Status = "UNREACHABLE"
+		status.Error = err.Error()
+	}
+	stats := client.Stats()
+	status.OpenConns = stats.OpenConns
+	status.IdleConns = stats.IdleConns
+	return status
+}
+
+// redisMetrics renders the Redis pool and command statistics in the OpenMetrics text format
+func redisMetrics(stats redis.Stats) []byte {
+	var buf bytes.Buffer
+	metric := func(name, kind, help, sample string) {
+		fmt.Fprintf(&buf, "# TYPE %s %s\n# HELP %s %s\n%s\n", name, kind, name, help, sample)
+	}
+
+	metric("planter_redis_connections", "gauge", "Open Redis connections.",
+		fmt.Sprintf("planter_redis_connections{state=\"open\"} %d\nplanter_redis_connections{state=\"idle\"} %d", stats.OpenConns, stats.IdleConns))
+	metric("planter_redis_dials", "counter", "Redis connections established.",
+		fmt.Sprintf("planter_redis_dials_total %d", stats.Dials))
+	metric("planter_redis_commands", "counter", "Redis commands sent.",
+		fmt.Sprintf("planter_redis_commands_total %d", stats.Commands))
+	metric("planter_redis_errors", "counter", "Redis commands that failed.",
+		fmt.Sprintf("planter_redis_errors_total %d", stats.Errors))
+	return buf.Bytes()
+}
+
+// plantCacheMetrics renders the hits, misses and invalidations of the plant catalog cache in the
+// OpenMetrics text format
+func plantCacheMetrics(stats cache.Stats) []byte {
+	var buf bytes.Buffer
+	metric := func(name, kind, help, sample string) {
+		fmt.Fprintf(&buf, "# TYPE %s %s\n# HELP %s %s\n%s\n", name, kind, name, help, sample)
+	}
+
+	metric("planter_plant_cache_hits", "counter", "Plant catalog reads served from the cache.",
+		fmt.Sprintf("planter_plant_cache_hits_total %d", stats.Hits))
+	metric("planter_plant_cache_misses", "counter", "Plant catalog reads loaded from the database.",
+		fmt.Sprintf("planter_plant_cache_misses_total %d", stats.Misses))
+	metric("planter_plant_cache_invalidations", "counter", "Plant catalog cache invalidations.",
+		fmt.Sprintf("planter_plant_cache_invalidations_total %d", stats.Invalidations))
+	return buf.Bytes()
+}
+
+// dbQueryMetrics renders the latency histogram, slow queries and timed out queries of each repository
+// in the OpenMetrics text format
+func dbQueryMetrics(stats map[string]db.QueryLatency) []byte {
+	repositories := make([]string, 0, len(stats))
+	for repository := range stats {
+		repositories = append(repositories, repository)
+	}
+	sort.Strings(repositories)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# TYPE planter_db_query_duration_seconds histogram\n# UNIT planter_db_query_duration_seconds seconds\n# HELP planter_db_query_duration_seconds Latency of the database queries of each repository.\n")
+	for _, repository := range repositories {
+		latency := stats[repository]
+		var cumulative uint64
+		for i, count := range latency.Buckets {
+			cumulative += count
+			le := "+Inf"
+			if i < len(db.QueryLatencyBuckets) {
+				le = strconv.FormatFloat(db.QueryLatencyBuckets[i], 'g', -1, 64)
+			}
+			fmt.Fprintf(&buf, "planter_db_query_duration_seconds_bucket{repository=\"%s\",le=\"%s\"} %d\n", repository, le, cumulative)
+		}
+		fmt.Fprintf(&buf, "planter_db_query_duration_seconds_sum{repository=\"%s\"} %s\n", repository, strconv.FormatFloat(latency.Sum, 'g', -1, 64))
+		fmt.Fprintf(&buf, "planter_db_query_duration_seconds_count{repository=\"%s\"} %d\n", repository, latency.Count)
+	}
+	fmt.Fprintf(&buf, "# TYPE planter_db_slow_queries counter\n# HELP planter_db_slow_queries Database queries slower than the slow query threshold.\n")
+	for _, repository := range repositories {
+		fmt.Fprintf(&buf, "planter_db_slow_queries_total{repository=\"%s\"} %d\n", repository, stats[repository].Slow)
+	}
+	fmt.Fprintf(&buf, "# TYPE planter_db_query_timeouts counter\n# HELP planter_db_query_timeouts Database queries canceled by the query timeout.\n")
+	for _, repository := range repositories {
+		fmt.Fprintf(&buf, "planter_db_query_timeouts_total{repository=\"%s\"} %d\n", repository, stats[repository].TimedOut)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetHomes handles the get homes request
+func (a *API) handleGetHomes(w http.ResponseWriter, r *http.Request) {
+	if a.homeService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Homes are not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the homes
+	homes, err := a.homeService.ListHomes(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get homes")
+		return
+	}
+
+	// Respond with the homes
+	utils.RespondWithJSON(w, http.StatusOK, homes)
+}
+
+// handleCreateHome handles the create home request
+func (a *API) handleCreateHome(w http.ResponseWriter, r *http.Request) {
+	if a.homeService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Homes are not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse and validate the request body
+	home, ok := a.decodeHome(w, r)
+	if !ok {
+		return
+	}
+
+	// Create the home
+	home, err = a.homeService.CreateHome(r.Context(), userID, home)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidHome) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if utils.RespondWithDatabaseError(w, err) {
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create home")
+		return
+	}
+
+	// Respond with the home
+	utils.RespondWithJSON(w, http.StatusCreated, home)
+}
+
+// handleUpdateHome handles the update home request
+func (a *API) handleUpdateHome(w http.ResponseWriter, r *http.Request) {
+	if a.homeService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Homes are not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the home ID from the URL
+	homeID, err := uuid.Parse(mux.Vars(r)["homeId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid home ID")
+		return
+	}
+
+	// Parse and validate the request body
+	home, ok := a.decodeHome(w, r)
+	if !ok {
+		return
+	}
+
+	// Update the home
+	home, err = a.homeService.UpdateHome(r.Context(), userID, homeID, home)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidHome):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Home not found")
+		case utils.RespondWithDatabaseError(w, err):
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update home")
+		}
+		return
+	}
+
+	// Respond with the home
+	utils.RespondWithJSON(w, http.StatusOK, home)
+}
+
+// handleDeleteHome handles the delete home request; the plants of the home stay in the collection
+func (a *API) handleDeleteHome(w http.ResponseWriter, r *http.Request) {
+	if a.homeService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Homes are not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the home ID from the URL
+	homeID, err := uuid.Parse(mux.Vars(r)["homeId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid home ID")
+		return
+	}
+
+	// Delete the home
+	if err := a.homeService.DeleteHome(r.Context(), userID, homeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Home not found")
+			return
+		}
+		if utils.RespondWithDatabaseError(w, err) {
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete home")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAssignHomePlants handles the assign home plants request: the given plants of the collection
+// move to the home
+func (a *API) handleAssignHomePlants(w http.ResponseWriter, r *http.Request) {
+	if a.homeService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Homes are not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the home ID from the URL
+	homeID, err := uuid.Parse(mux.Vars(r)["homeId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid home ID")
+		return
+	}
+
+	// Parse and validate the request body
+	var req models.AssignHomePlantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Move the plants to the home
+	home, err := a.homeService.AssignPlants(r.Context(), userID, homeID, req.PlantIDs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Home not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to assign plants to home")
+		return
+	}
+
+	// Respond with the home and its new plant count
+	utils.RespondWithJSON(w, http.StatusOK, home)
+}
+
+// handleUnassignHomePlant handles the unassign home plant request: the plant stays in the collection
+// without a home
+func (a *API) handleUnassignHomePlant(w http.ResponseWriter, r *http.Request) {
+	if a.homeService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Homes are not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the home and plant IDs from the URL
+	vars := mux.Vars(r)
+	homeID, err := uuid.Parse(vars["homeId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid home ID")
+		return
+	}
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Take the plant out of the home
+	if err := a.homeService.UnassignPlant(r.Context(), userID, homeID, plantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found in home")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to take plant out of home")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSetCurrentHome handles the set current home request, sent when the user arrives at one of
+// their homes: watering reminders switch to the plants there. A null home reminds of every plant.
+func (a *API) handleSetCurrentHome(w http.ResponseWriter, r *http.Request) {
+	if a.homeService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Homes are not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.CurrentHomeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Mark the home
+	home, err := a.homeService.SetCurrentHome(r.Context(), userID, req.HomeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Home not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to set current home")
+		return
+	}
+	if home == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Respond with the home
+	utils.RespondWithJSON(w, http.StatusOK, home)
+}
+
+// decodeHome parses and validates the home of a create or update request, responding with the error
+// when it is invalid
+func (a *API) decodeHome(w http.ResponseWriter, r *http.Request) (*models.Home, bool) {
+	var home models.Home
+	if err := json.NewDecoder(r.Body).Decode(&home); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return nil, false
+	}
+	if err := utils.Validate.Struct(home); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return nil, false
+	}
+	return &home, true
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// openMetricsContentType is the content type of the OpenMetrics text format
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// handleMetrics handles the metrics scrape request. Month totals are exported as gauges because
+// they reset when a new month begins.
+func (a *API) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	// Get the Yandex GPT budget report
+	report, err := a.llmBudgetService.GetReport(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get metrics")
+		return
+	}
+
+	// Add the recommendation answer counters, the Redis pool and command statistics, the plant cache
+	// counters, the chat scrub counters and the database query latencies
+	metrics := llmBudgetMetrics(report)
+	metrics = append(metrics, recommendationParseMetrics(a.recommendationService.RecommendationParseStats())...)
+	if a.redis != nil {
+		metrics = append(metrics, redisMetrics(a.redis.Stats())...)
+	}
+	if a.plantCache != nil {
+		metrics = append(metrics, plantCacheMetrics(a.plantCache.Stats())...)
+	}
+	if a.chatScrubber != nil {
+		metrics = append(metrics, chatScrubMetrics(a.chatScrubber.Stats())...)
+	}
+	if a.queryMetrics != nil {
+		metrics = append(metrics, dbQueryMetrics(a.queryMetrics.Stats())...)
+	}
+	metrics = append(metrics, "# EOF\n"...)
+
+	// Respond with the metrics
+	w.Header().Set("Content-Type", openMetricsContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(metrics)
+}
+
+// handleAdminGetLLMUsage handles the admin get Yandex GPT usage request
+func (a *API) handleAdminGetLLMUsage(w http.ResponseWriter, r *http.Request) {
+	// Get the Yandex GPT budget report
+	report, err := a.llmBudgetService.GetReport(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get LLM usage")
+		return
+	}
+
+	// Respond with the report
+	utils.RespondWithJSON(w, http.StatusOK, report)
+}
+
+// llmBudgetMetrics renders a budget report in the OpenMetrics text format, without the closing EOF
+func llmBudgetMetrics(report *models.LLMBudgetReport) []byte {
+	var buf bytes.Buffer
+	gauge := func(name, unit, help string, samples ...string) {
+		fmt.Fprintf(&buf, "# TYPE %s gauge\n", name)
+		if unit != "" {
+			fmt.Fprintf(&buf, "# UNIT %s %s\n", name, unit)
+		}
+		fmt.Fprintf(&buf, "# HELP %s %s\n", name, help)
+		for _, sample := range samples {
+			fmt.Fprintf(&buf, "%s%s\n", name, sample)
+		}
+	}
+	value := func(v float64) string {
+		return " " + strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	gauge("planter_llm_month_requests", "", "Yandex GPT requests made this month.",
+		value(float64(report.Requests)))
+	gauge("planter_llm_month_tokens", "", "Yandex GPT tokens consumed this month.",
+		`{kind="input"}`+value(float64(report.InputTokens)),
+		`{kind="completion"}`+value(float64(report.CompletionTokens)))
+	gauge("planter_llm_month_spend_rubles", "rubles", "Yandex GPT spend this month.",
+		value(report.Spend))
+	gauge("planter_llm_projected_month_spend_rubles", "rubles", "Yandex GPT spend expected by the end of the month at the current rate.",
+		value(report.ProjectedSpend))
+	if report.MonthlyBudget != nil {
+		gauge("planter_llm_monthly_budget_rubles", "rubles", "Monthly Yandex GPT budget.",
+			value(*report.MonthlyBudget))
+		gauge("planter_llm_budget_used_ratio", "ratio", "Share of the monthly Yandex GPT budget spent.",
+			value(*report.BudgetUsedRatio))
+	}
+	if report.UserQuotaTokens != nil {
+		maxUsedRatio := 0.0
+		for _, user := range report.TopUsers {
+			if user.QuotaUsedRatio != nil && *user.QuotaUsedRatio > maxUsedRatio {
+				maxUsedRatio = *user.QuotaUsedRatio
+			}
+		}
+		gauge("planter_llm_user_quota_tokens", "", "Monthly Yandex GPT token quota of each user.",
+			value(float64(*report.UserQuotaTokens)))
+		gauge("planter_llm_users_over_quota", "", "Users who used up their monthly Yandex GPT quota.",
+			value(float64(report.UsersOverQuota)))
+		gauge("planter_llm_user_quota_max_used_ratio", "ratio", "Largest share of the monthly quota used by a single user.",
+			value(maxUsedRatio))
+	}
+
+	states := []models.CircuitBreakerState{models.CircuitBreakerClosed, models.CircuitBreakerOpen, models.CircuitBreakerHalfOpen}
+	stateSamples := make([]string, 0, len(states))
+	for _, state := range states {
+		active := 0.0
+		if report.CircuitBreaker.State == state {
+			active = 1
+		}
+		stateSamples = append(stateSamples, fmt.Sprintf(`{state="%s"}`, strings.ToLower(string(state)))+value(active))
+	}
+	gauge("planter_llm_circuit_breaker_state", "", "Current state of the Yandex GPT circuit breaker.", stateSamples...)
+	gauge("planter_llm_circuit_breaker_consecutive_failures", "", "Consecutive failed Yandex GPT calls.",
+		value(float64(report.CircuitBreaker.ConsecutiveFailures)))
+
+	return buf.Bytes()
+}
+
+// chatScrubMetrics renders the chat scrub counters in the OpenMetrics text format, without the closing EOF
+func chatScrubMetrics(stats services.ChatScrubStats) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# TYPE planter_chat_scrubbed_messages counter\n# HELP planter_chat_scrubbed_messages Chat messages scrubbed before they were sent to Yandex GPT.\n")
+	fmt.Fprintf(&buf, "planter_chat_scrubbed_messages_total %d\n", stats.Messages)
+	fmt.Fprintf(&buf, "# TYPE planter_chat_scrubs counter\n# HELP planter_chat_scrubs Personal data and blocked words scrubbed from chat messages.\n")
+	for _, kind := range services.ChatScrubKinds {
+		fmt.Fprintf(&buf, "planter_chat_scrubs_total{kind=\"%s\"} %d\n", kind, stats.Scrubs[kind])
+	}
+	return buf.Bytes()
+}
+
+// recommendationParseMetrics renders the counters of the recommendation answers of Yandex GPT in the
+// OpenMetrics text format, without the closing EOF
+func recommendationParseMetrics(stats services.RecommendationParseStats) []byte {
+	var buf bytes.Buffer
+	counter := func(name, help string, value uint64) {
+		fmt.Fprintf(&buf, "# TYPE %s counter\n# HELP %s %s\n%s_total %d\n", name, name, help, name, value)
+	}
+	counter("planter_llm_recommendation_answers", "Recommendation answers received from Yandex GPT.", stats.Answers)
+	fmt.Fprintf(&buf, "# TYPE planter_llm_recommendation_parse_failures counter\n# HELP planter_llm_recommendation_parse_failures Recommendation answers of Yandex GPT that failed validation.\n")
+	for _, reason := range services.RecommendationParseReasons {
+		fmt.Fprintf(&buf, "planter_llm_recommendation_parse_failures_total{reason=\"%s\"} %d\n", reason, stats.Failures[reason])
+	}
+	counter("planter_llm_recommendation_reasks", "Recommendation answers asked for again after one failed validation.", stats.Reasks)
+	counter("planter_llm_recommendation_rejected", "Recommendations left to the local engine because no answer of Yandex GPT validated.", stats.Rejected)
+	return buf.Bytes()
+}
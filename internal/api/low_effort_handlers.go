@@ -0,0 +1,103 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleSetLowEffortMode handles the set low effort mode request: the whole collection of the user is
+// watered as rarely as each plant tolerates, or as usual again
+func (a *API) handleSetLowEffortMode(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if a.lowEffortService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Low effort mode is not available")
+		return
+	}
+
+	// Parse the request body; the mode of the user cannot be unset
+	var req models.SetLowEffortModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Enabled == nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	// Set the mode and reschedule the collection
+	plants, err := a.lowEffortService.SetUserMode(r.Context(), userID, *req.Enabled)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to set low effort mode")
+		return
+	}
+
+	// Respond with the collection and what the mode costs each plant
+	services.AddLowEffortTradeOffs(plants, a.resolveClientLanguage(r))
+	utils.RespondWithJSON(w, http.StatusOK, plants)
+}
+
+// handleSetPlantLowEffortMode handles the set plant low effort mode request: one plant of the
+// collection gets a mode of its own, or follows the user's mode again when it is null
+func (a *API) handleSetPlantLowEffortMode(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if a.lowEffortService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Low effort mode is not available")
+		return
+	}
+
+	// Parse the request body
+	var req models.SetLowEffortModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Set the mode and reschedule the plant
+	plant, err := a.lowEffortService.SetPlantMode(r.Context(), userID, plantID, req.Enabled)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not in collection")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to set low effort mode")
+		return
+	}
+
+	// Respond with the plant and what the mode costs it
+	services.AddLowEffortTradeOffs([]*models.Plant{plant}, a.resolveClientLanguage(r))
+	utils.RespondWithJSON(w, http.StatusOK, plant)
+}
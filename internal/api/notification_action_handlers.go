@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// handleNotificationAction handles the perform notification action request. The token from the
+// notification authorizes the action, so the request needs no authentication.
+func (a *API) handleNotificationAction(w http.ResponseWriter, r *http.Request) {
+	// Get the token from the URL
+	token := mux.Vars(r)["token"]
+
+	// Verify the token and mark the plant watered
+	var plant *models.Plant
+	action, err := a.notificationService.UseActionToken(r.Context(), token, func(ctx context.Context, action *models.NotificationActionToken) error {
+		var err error
+		plant, err = a.plantService.MarkAsWatered(ctx, action.UserID, action.PlantID)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotificationActionsUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrInvalidActionToken):
+			utils.RespondWithError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrActionTokenExpired):
+			utils.RespondWithError(w, http.StatusGone, err.Error())
+		case errors.Is(err, services.ErrActionTokenUsed):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to perform notification action")
+		}
+		return
+	}
+
+	// Respond with the watered plant
+	utils.RespondWithJSON(w, http.StatusOK, &models.NotificationActionResult{Action: action.Action, Plant: plant})
+}
@@ -1,10 +1,17 @@
 package api
 
 import (
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "log"
     "net/http"
     "strconv"
+    "time"
 
     "github.com/anpanovv/planter/internal/middleware"
+    "github.com/anpanovv/planter/internal/models"
+    "github.com/anpanovv/planter/internal/services"
     "github.com/anpanovv/planter/internal/utils"
     "github.com/google/uuid"
     "github.com/gorilla/mux"
@@ -30,6 +37,9 @@ func (a *API) handleGetUserNotifications(w http.ResponseWriter, r *http.Request)
         return
     }
 
+    // Humanize the times of the notifications in the language of the client
+    services.AddNotificationTimeDisplays(response.Notifications, a.resolveClientLanguage(r), a.resolveClientLocation(r), time.Now())
+
     utils.RespondWithJSON(w, http.StatusOK, response)
 }
 
@@ -58,4 +68,168 @@ func (a *API) handleMarkNotificationAsRead(w http.ResponseWriter, r *http.Reques
     }
 
     utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Notification marked as read"})
-} 
\ No newline at end of file
+}
+
+// handleGetUnreadNotificationCount handles the get unread notification count request
+func (a *API) handleGetUnreadNotificationCount(w http.ResponseWriter, r *http.Request) {
+    // Get the authenticated user ID from the context
+    userID, err := middleware.GetUserID(r.Context())
+    if err != nil {
+        utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    // Count the unread notifications
+    count, err := a.notificationService.GetUnreadCount(r.Context(), userID)
+    if err != nil {
+        utils.RespondWithError(w, http.StatusInternalServerError, "Failed to count unread notifications")
+        return
+    }
+
+    utils.RespondWithJSON(w, http.StatusOK, models.NotificationCountResponse{Count: count})
+}
+
+// handleGetNotificationBell handles the get notification bell request, made every time the app
+// opens: the unread state is read from a projection rather than counted
+func (a *API) handleGetNotificationBell(w http.ResponseWriter, r *http.Request) {
+    // Get the authenticated user ID from the context
+    userID, err := middleware.GetUserID(r.Context())
+    if err != nil {
+        utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    // Get the bell
+    bell, err := a.notificationService.GetBell(r.Context(), userID)
+    if err != nil {
+        utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get notification bell")
+        return
+    }
+
+    utils.RespondWithJSON(w, http.StatusOK, bell)
+}
+
+// handleMarkAllNotificationsAsRead handles the mark all notifications as read request
+func (a *API) handleMarkAllNotificationsAsRead(w http.ResponseWriter, r *http.Request) {
+    // Get the authenticated user ID from the context
+    userID, err := middleware.GetUserID(r.Context())
+    if err != nil {
+        utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    // Mark all as read
+    count, err := a.notificationService.MarkAllAsRead(r.Context(), userID)
+    if err != nil {
+        utils.RespondWithError(w, http.StatusInternalServerError, "Failed to mark notifications as read")
+        return
+    }
+
+    // Respond with the number of notifications marked
+    utils.RespondWithJSON(w, http.StatusOK, models.NotificationCountResponse{Count: count})
+}
+
+// handleDeleteNotification handles the delete notification request
+func (a *API) handleDeleteNotification(w http.ResponseWriter, r *http.Request) {
+    // Get the authenticated user ID from the context
+    userID, err := middleware.GetUserID(r.Context())
+    if err != nil {
+        utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    // Get the notification ID from the URL
+    vars := mux.Vars(r)
+    notificationID, err := uuid.Parse(vars["notificationId"])
+    if err != nil {
+        utils.RespondWithError(w, http.StatusBadRequest, "Invalid notification ID")
+        return
+    }
+
+    // Delete the notification
+    err = a.notificationService.DeleteNotification(r.Context(), notificationID, userID)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            utils.RespondWithError(w, http.StatusNotFound, "Notification not found")
+            return
+        }
+        utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete notification")
+        return
+    }
+
+    // Respond with no content
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetNotificationTypes handles the get notification types request
+func (a *API) handleGetNotificationTypes(w http.ResponseWriter, r *http.Request) {
+    // Respond with the registered notification types
+    utils.RespondWithJSON(w, http.StatusOK, services.NotificationTypes())
+}
+
+// handleAdminSendNotification handles the admin send notification request
+func (a *API) handleAdminSendNotification(w http.ResponseWriter, r *http.Request) {
+    // Parse the request body
+    var req models.SendNotificationRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    // Validate the request
+    if err := utils.Validate.Struct(req); err != nil {
+        utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+        return
+    }
+
+    // Get the recipient to render the message in their language
+    user, err := a.userService.GetUser(r.Context(), req.UserID)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            utils.RespondWithError(w, http.StatusNotFound, "User not found")
+            return
+        }
+        utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user")
+        return
+    }
+
+    // Send the notification
+    notification, err := a.notificationService.SendNotification(r.Context(), user.ID, user.Language, req.Type, req.Payload)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrInvalidNotification):
+            utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+        case errors.Is(err, sql.ErrNoRows):
+            utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+        default:
+            utils.RespondWithError(w, http.StatusInternalServerError, "Failed to send notification")
+        }
+        return
+    }
+
+    // Respond with the notification
+    utils.RespondWithJSON(w, http.StatusCreated, notification)
+}
+
+// handleAdminRunCareNotifications handles the admin run care notifications check request
+func (a *API) handleAdminRunCareNotifications(w http.ResponseWriter, r *http.Request) {
+    // Only report what the check would do when a dry run is requested
+    dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+    // Run the check
+    var stats *services.NotificationStats
+    var err error
+    if dryRun {
+        stats, err = a.notificationService.DryRunCareNotifications(r.Context())
+    } else {
+        stats, err = a.notificationService.CheckAndCreateCareNotifications(r.Context())
+    }
+    if err != nil {
+        log.Printf("Failed to run care notifications check (dry run: %t): %v", dryRun, err)
+        utils.RespondWithError(w, http.StatusInternalServerError, "Failed to run care notifications check")
+        return
+    }
+
+    // Respond with the statistics
+    utils.RespondWithJSON(w, http.StatusOK, stats)
+}
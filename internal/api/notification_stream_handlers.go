@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/anpanovv/planter/internal/ws"
+)
+
+// handleNotificationStream handles the notification stream request: the connection is upgraded to a
+// WebSocket that gets every notification created for the user from then on
+func (a *API) handleNotificationStream(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if a.notificationHub == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Notification stream is not available")
+		return
+	}
+
+	// Stream until the client disconnects
+	if err := a.notificationHub.ServeNotifications(w, r, userID); err != nil {
+		if errors.Is(err, ws.ErrTooManyConnections) {
+			utils.RespondWithError(w, http.StatusTooManyRequests, "Too many notification streams")
+			return
+		}
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Notification stream is not available")
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/gorilla/mux"
+)
+
+// handleAdminGetNotificationTemplates handles the admin get notification templates request
+func (a *API) handleAdminGetNotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	// Get the effective templates
+	templates, err := a.notificationTemplateService.GetTemplates(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get notification templates")
+		return
+	}
+
+	// Respond with the templates
+	utils.RespondWithJSON(w, http.StatusOK, templates)
+}
+
+// handleAdminUpdateNotificationTemplate handles the admin update notification template request
+func (a *API) handleAdminUpdateNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	// Get the notification type and language from the URL
+	vars := mux.Vars(r)
+	notificationType := models.NotificationType(strings.ToUpper(vars["type"]))
+	language := models.Language(strings.ToUpper(vars["language"]))
+
+	// Parse the request body
+	var req models.UpdateNotificationTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Save the template
+	template, err := a.notificationTemplateService.UpdateTemplate(r.Context(), notificationType, language, req.Body)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Respond with the saved template
+	utils.RespondWithJSON(w, http.StatusOK, template)
+}
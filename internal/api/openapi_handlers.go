@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/anpanovv/planter/docs"
+	"github.com/anpanovv/planter/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// swaggerUIVersion is the version of Swagger UI the documentation page loads
+const swaggerUIVersion = "5.17.14"
+
+// swaggerUIPage is the documentation page; it renders the definition served at /openapi.json
+var swaggerUIPage = []byte(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Planter API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`)
+
+// openAPIJSON converts the embedded definition to JSON once
+var openAPIJSON = sync.OnceValues(func() ([]byte, error) {
+	var definition interface{}
+	if err := yaml.Unmarshal(docs.OpenAPI, &definition); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI definition: %w", err)
+	}
+	return json.Marshal(jsonCompatible(definition))
+})
+
+// handleOpenAPIJSON handles the get OpenAPI definition in JSON request
+func (a *API) handleOpenAPIJSON(w http.ResponseWriter, r *http.Request) {
+	definition, err := openAPIJSON()
+	if err != nil {
+		log.Printf("Failed to serve OpenAPI definition: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get OpenAPI definition")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(definition)
+}
+
+// handleOpenAPIYAML handles the get OpenAPI definition in YAML request
+func (a *API) handleOpenAPIYAML(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docs.OpenAPI)
+}
+
+// handleSwaggerUI handles the get API documentation page request
+func (a *API) handleSwaggerUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(swaggerUIPage)
+}
+
+// jsonCompatible converts the mappings of a decoded YAML value with non-string keys, such as
+// unquoted status codes, to JSON objects
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = jsonCompatible(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return object
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonCompatible(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// handleGetOutdoorLocations handles the get outdoor locations request
+func (a *API) handleGetOutdoorLocations(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if a.weatherService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Outdoor locations are not available")
+		return
+	}
+
+	locations, err := a.weatherService.ListOutdoorLocations(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get outdoor locations")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, locations)
+}
+
+// handleSaveOutdoorLocation handles the save outdoor location request: watering reminders of the
+// plants in the location follow the weather at its coordinates
+func (a *API) handleSaveOutdoorLocation(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if a.weatherService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Outdoor locations are not available")
+		return
+	}
+
+	// Parse the request body
+	var location models.OutdoorLocation
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(location); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	if err := a.weatherService.SaveOutdoorLocation(r.Context(), userID, &location); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to save outdoor location")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, location)
+}
+
+// handleDeleteOutdoorLocation handles the delete outdoor location request: the location given in the
+// query is treated as indoors again
+func (a *API) handleDeleteOutdoorLocation(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if a.weatherService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Outdoor locations are not available")
+		return
+	}
+
+	location := strings.TrimSpace(r.URL.Query().Get("location"))
+	if location == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "location is required")
+		return
+	}
+
+	if err := a.weatherService.DeleteOutdoorLocation(r.Context(), userID, location); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Outdoor location not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete outdoor location")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleCreatePersonalToken handles the create personal access token request
+func (a *API) handleCreatePersonalToken(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.CreatePersonalAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Create the token
+	resp, err := a.personalTokenService.CreateToken(r.Context(), userID, &req)
+	if err != nil {
+		log.Printf("Failed to create personal access token for user %s: %v", userID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create token")
+		return
+	}
+
+	// Respond with the token, including the raw value which is not shown again
+	utils.RespondWithJSON(w, http.StatusCreated, resp)
+}
+
+// handleGetPersonalTokens handles the get personal access tokens request
+func (a *API) handleGetPersonalTokens(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the tokens
+	tokens, err := a.personalTokenService.GetTokens(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get tokens")
+		return
+	}
+
+	// Respond with the tokens
+	utils.RespondWithJSON(w, http.StatusOK, tokens)
+}
+
+// handleRevokePersonalToken handles the revoke personal access token request
+func (a *API) handleRevokePersonalToken(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the token ID from the URL
+	vars := mux.Vars(r)
+	tokenID, err := uuid.Parse(vars["tokenId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	// Revoke the token
+	err = a.personalTokenService.RevokeToken(r.Context(), userID, tokenID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Token not found")
+		return
+	}
+
+	// Respond with success
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Token revoked"})
+}
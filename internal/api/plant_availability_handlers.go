@@ -0,0 +1,116 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetAvailabilitySubscriptions handles the get plant availability subscriptions request
+func (a *API) handleGetAvailabilitySubscriptions(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the subscriptions
+	subscriptions, err := a.plantAvailabilityService.GetSubscriptions(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to get plant availability subscriptions for user %s: %v", userID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get availability subscriptions")
+		return
+	}
+
+	// Respond with the subscriptions
+	utils.RespondWithJSON(w, http.StatusOK, subscriptions)
+}
+
+// handleSubscribeToAvailability handles the notify me when a plant is available in my city request
+func (a *API) handleSubscribeToAvailability(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.SubscribeToAvailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Subscribe to the plant
+	subscription, err := a.plantAvailabilityService.Subscribe(r.Context(), userID, plantID, req.City)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		case errors.Is(err, services.ErrPlantDeleted):
+			utils.RespondWithError(w, http.StatusGone, err.Error())
+		default:
+			log.Printf("Failed to subscribe user %s to the availability of plant %s: %v", userID, plantID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to subscribe to availability")
+		}
+		return
+	}
+
+	// Respond with the subscription
+	utils.RespondWithJSON(w, http.StatusOK, subscription)
+}
+
+// handleUnsubscribeFromAvailability handles the stop waiting for a plant to be available request
+func (a *API) handleUnsubscribeFromAvailability(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Remove the subscription
+	if err := a.plantAvailabilityService.Unsubscribe(r.Context(), userID, plantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Availability subscription not found")
+			return
+		}
+		log.Printf("Failed to unsubscribe user %s from the availability of plant %s: %v", userID, plantID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to unsubscribe from availability")
+		return
+	}
+
+	// Respond with success
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Unsubscribed from availability"})
+}
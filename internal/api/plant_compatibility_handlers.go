@@ -0,0 +1,41 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// handleCheckPlantCompatibility handles the plant compatibility request: whether the plants can share a
+// pot or a terrarium, with an explanation for each need compared
+func (a *API) handleCheckPlantCompatibility(w http.ResponseWriter, r *http.Request) {
+	// Parse the request body
+	var req models.PlantCompatibilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Compare the care needs of the plants
+	compatibility, err := a.plantService.CheckPlantCompatibility(r.Context(), req.PlantIDs, a.resolveClientLanguage(r))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to check plant compatibility")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, compatibility)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleAdminRunPlantEnrichment handles the admin run plant enrichment request
+func (a *API) handleAdminRunPlantEnrichment(w http.ResponseWriter, r *http.Request) {
+	if a.plantEnrichmentService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, services.ErrPlantEnrichmentUnavailable.Error())
+		return
+	}
+
+	// Get the number of plants to check
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	// Look up the missing fields and queue them for review
+	run, err := a.plantEnrichmentService.Run(r.Context(), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrPlantEnrichmentUnavailable) {
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to run plant enrichment")
+		return
+	}
+
+	// Respond with the run
+	utils.RespondWithJSON(w, http.StatusOK, run)
+}
+
+// handleAdminGetPlantEnrichmentProposals handles the admin get pending plant enrichment proposals request
+func (a *API) handleAdminGetPlantEnrichmentProposals(w http.ResponseWriter, r *http.Request) {
+	if a.plantEnrichmentService == nil {
+		utils.RespondWithJSON(w, http.StatusOK, []*models.PlantEnrichmentProposal{})
+		return
+	}
+
+	// Get the proposals awaiting review
+	proposals, err := a.plantEnrichmentService.GetPendingProposals(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plant enrichment proposals")
+		return
+	}
+
+	// Respond with the proposals
+	utils.RespondWithJSON(w, http.StatusOK, proposals)
+}
+
+// handleAdminReviewPlantEnrichmentProposal handles the admin review plant enrichment proposal request
+func (a *API) handleAdminReviewPlantEnrichmentProposal(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated admin ID from the context
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the proposal ID from the URL
+	vars := mux.Vars(r)
+	proposalID, err := uuid.Parse(vars["proposalId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid proposal ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.ReviewPlantEnrichmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	if a.plantEnrichmentService == nil {
+		utils.RespondWithError(w, http.StatusNotFound, services.ErrPlantEnrichmentProposalNotFound.Error())
+		return
+	}
+
+	// Review the proposal
+	proposal, err := a.plantEnrichmentService.ReviewProposal(r.Context(), proposalID, req.Status, adminID)
+	if err != nil {
+		if errors.Is(err, services.ErrPlantEnrichmentProposalNotFound) {
+			utils.RespondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to review plant enrichment proposal")
+		return
+	}
+
+	// Respond with the reviewed proposal
+	utils.RespondWithJSON(w, http.StatusOK, proposal)
+}
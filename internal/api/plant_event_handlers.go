@@ -0,0 +1,69 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetPlantEvents handles the get plant lifecycle events request
+func (a *API) handleGetPlantEvents(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the cursor, page size and event types from the query
+	query := r.URL.Query()
+	filter := &models.PlantEventFilter{Cursor: query.Get("cursor")}
+	if value := query.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit <= 0 {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+	}
+	for _, value := range query["type"] {
+		for _, eventType := range strings.Split(value, ",") {
+			if eventType = strings.TrimSpace(eventType); eventType != "" {
+				filter.Types = append(filter.Types, models.PlantEventType(strings.ToUpper(eventType)))
+			}
+		}
+	}
+
+	// Get the page of events
+	page, err := a.plantEventService.List(r.Context(), userID, plantID, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPlantEventCursor), errors.Is(err, services.ErrInvalidPlantEventType):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plant events")
+		}
+		return
+	}
+
+	// Respond with the page
+	utils.RespondWithJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// plantExportWriteTimeout is how long a client has to download a catalog export, instead of the
+// write timeout of the server
+const plantExportWriteTimeout = 10 * time.Minute
+
+// handleAdminExportPlants handles the admin export plants request: the catalog is streamed as CSV or
+// JSON, with only the fields given in ?fields= when set
+func (a *API) handleAdminExportPlants(w http.ResponseWriter, r *http.Request) {
+	if a.plantExportService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Plant export is not available")
+		return
+	}
+
+	// Check the format and fields before the file is started
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = services.PlantExportFormatCSV
+	}
+	var fields []string
+	if value := r.URL.Query().Get("fields"); value != "" {
+		fields = strings.Split(value, ",")
+	}
+	export, err := services.NewPlantExport(format, fields)
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedPlantExportFormat) || errors.Is(err, services.ErrInvalidPlantExport) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to export plants")
+		return
+	}
+
+	// Give the download more time than other responses get
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(plantExportWriteTimeout)); err != nil {
+		log.Printf("Failed to extend the write deadline of the plant export: %v", err)
+	}
+
+	// Stream the file; once it started, an error can only cut it short
+	w.Header().Set("Content-Type", export.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+export.Filename(time.Now())+`"`)
+	w.WriteHeader(http.StatusOK)
+	if err := a.plantExportService.Export(r.Context(), w, export); err != nil {
+		log.Printf("Plant export stopped: %v", err)
+	}
+}
@@ -1,12 +1,19 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/anpanovv/planter/internal/dto"
 	"github.com/anpanovv/planter/internal/middleware"
 	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
 	"github.com/anpanovv/planter/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -14,15 +21,94 @@ import (
 
 // handleGetAllPlants handles the get all plants request
 func (a *API) handleGetAllPlants(w http.ResponseWriter, r *http.Request) {
-	// Get all plants
-	plants, err := a.plantService.GetAllPlants(r.Context())
+	query := r.URL.Query()
+
+	// Parse the query parameters
+	var filter models.PlantFilter
+	if value := query.Get("sunlight"); value != "" {
+		sunlight := models.SunlightLevel(strings.ToUpper(value))
+		filter.Sunlight = &sunlight
+	}
+	if value := query.Get("humidity"); value != "" {
+		humidity := models.HumidityLevel(strings.ToUpper(value))
+		filter.Humidity = &humidity
+	}
+	if value := query.Get("petFriendly"); value != "" {
+		petFriendly, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid petFriendly parameter")
+			return
+		}
+		filter.PetFriendly = &petFriendly
+	}
+	if value := query.Get("minPrice"); value != "" {
+		minPrice, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid minPrice parameter")
+			return
+		}
+		filter.MinPrice = &minPrice
+	}
+	if value := query.Get("maxPrice"); value != "" {
+		maxPrice, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid maxPrice parameter")
+			return
+		}
+		filter.MaxPrice = &maxPrice
+	}
+	if value := query.Get("shopId"); value != "" {
+		shopID, err := uuid.Parse(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid shopId parameter")
+			return
+		}
+		filter.ShopID = &shopID
+	}
+	if value := query.Get("category"); value != "" {
+		filter.Category = &value
+	}
+	filter.Tags = query["tag"]
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid page parameter")
+			return
+		}
+		filter.Page = page
+	}
+	if value := query.Get("pageSize"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid pageSize parameter")
+			return
+		}
+		filter.PageSize = pageSize
+	}
+
+	// Validate the filter
+	if err := utils.Validate.Struct(filter); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Get the page of plants
+	plants, total, err := a.plantService.ListPlants(r.Context(), &filter)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidPlantFilter) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plants")
 		return
 	}
 
-	// Respond with the plants
-	utils.RespondWithJSON(w, http.StatusOK, plants)
+	// Show the plants in the language of the client
+	a.localizePlants(w, r, plants...)
+
+	// Respond with the plants; the body stays a plain list and the total number of matches goes in a header
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	utils.RespondWithJSON(w, http.StatusOK, dto.Plants(plants, dto.ClientProfileFromRequest(r)))
 }
 
 // handleGetPlant handles the get plant request
@@ -42,6 +128,9 @@ func (a *API) handleGetPlant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Show the plant in the language of the client
+	a.localizePlants(w, r, plant)
+
 	// Respond with the plant
 	utils.RespondWithJSON(w, http.StatusOK, plant)
 }
@@ -62,8 +151,11 @@ func (a *API) handleSearchPlants(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Respond with the plants
-	utils.RespondWithJSON(w, http.StatusOK, plants)
+	// Show the plants in the language of the client
+	a.localizePlants(w, r, plants...)
+
+	// Respond with the plants in the shape the client asked for
+	utils.RespondWithJSON(w, http.StatusOK, dto.Plants(plants, dto.ClientProfileFromRequest(r)))
 }
 
 // handleGetFavoritePlants handles the get favorite plants request
@@ -84,8 +176,70 @@ func (a *API) handleGetFavoritePlants(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Respond with the plants
-	utils.RespondWithJSON(w, http.StatusOK, plants)
+	// Show the plants in the language of the client
+	a.localizePlants(w, r, plants...)
+
+	// Respond with the plants in the shape the client asked for, or with 304 when the client has them
+	utils.RespondWithJSONETag(w, r, http.StatusOK, dto.Plants(plants, dto.ClientProfileFromRequest(r)))
+}
+
+// handleGetFavoriteChanges handles the get favorite changes request: the plants added to or removed
+// from the user's favorites since the last sync
+func (a *API) handleGetFavoriteChanges(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the time of the last sync; without it every favorite is listed
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		if since, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid since parameter")
+			return
+		}
+	}
+
+	// Get the changes
+	changes, err := a.plantService.GetFavoriteChanges(r.Context(), userID, since)
+	if err != nil {
+		log.Printf("Failed to get favorite changes for user %s: %v", userID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get favorite changes")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, changes)
+}
+
+// handleGetWateringRoute handles the get watering route request
+func (a *API) handleGetWateringRoute(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the user's rooms, which define the walking order
+	rooms, err := a.userService.GetLocations(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to get locations for user %s: %v", userID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get watering route")
+		return
+	}
+
+	// Build the route
+	route, err := a.plantService.GetWateringRoute(r.Context(), userID, rooms, time.Now())
+	if err != nil {
+		log.Printf("Failed to get watering route for user %s: %v", userID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get watering route")
+		return
+	}
+
+	// Respond with the route
+	utils.RespondWithJSON(w, http.StatusOK, route)
 }
 
 // handleAddToFavorites handles the add to favorites request
@@ -109,6 +263,13 @@ func (a *API) handleAddToFavorites(w http.ResponseWriter, r *http.Request) {
 	// Add to favorites
 	err = a.plantService.AddToFavorites(r.Context(), userID, plantID)
 	if err != nil {
+		if errors.Is(err, services.ErrPlantDeleted) {
+			utils.RespondWithError(w, http.StatusGone, err.Error())
+			return
+		}
+		if utils.RespondWithDatabaseError(w, err) {
+			return
+		}
 		log.Printf("Failed to add plant %s to favorites for user %s: %v", plantID, userID, err)
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to add to favorites")
 		return
@@ -172,6 +333,10 @@ func (a *API) handleMarkAsWatered(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Spell out what low effort mode costs the plant and when it needs water next
+	services.AddLowEffortTradeOffs([]*models.Plant{plant}, a.resolveClientLanguage(r))
+	services.AddPlantTimeDisplays([]*models.Plant{plant}, a.resolveClientLanguage(r), a.resolveClientLocation(r), time.Now())
+
 	// Respond with the updated plant
 	utils.RespondWithJSON(w, http.StatusOK, plant)
 }
@@ -185,15 +350,36 @@ func (a *API) handleGetUserPlants(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get the user plants
-	plants, err := a.plantService.GetUserPlants(r.Context(), userID)
+	// Get the user plants, only the ones in a home when one is given; none lists the plants in no home
+	var plants []*models.Plant
+	switch homeID := r.URL.Query().Get("homeId"); homeID {
+	case "":
+		plants, err = a.plantService.GetUserPlants(r.Context(), userID)
+	case "none":
+		plants, err = a.plantService.GetUserPlantsInHome(r.Context(), userID, uuid.Nil)
+	default:
+		id, parseErr := uuid.Parse(homeID)
+		if parseErr != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid home ID")
+			return
+		}
+		plants, err = a.plantService.GetUserPlantsInHome(r.Context(), userID, id)
+	}
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get user plants")
 		return
 	}
 
-	// Respond with the plants
-	utils.RespondWithJSON(w, http.StatusOK, plants)
+	// Show the plants in the language of the client
+	a.localizePlants(w, r, plants...)
+
+	// Tell the client how to present the watering urgency of each plant
+	services.AddCareHints(plants, a.resolveClientLanguage(r), time.Now())
+	services.AddPlantTimeDisplays(plants, a.resolveClientLanguage(r), a.resolveClientLocation(r), time.Now())
+	services.AddLowEffortTradeOffs(plants, a.resolveClientLanguage(r))
+
+	// Respond with the plants in the shape the client asked for
+	utils.RespondWithJSON(w, http.StatusOK, dto.Plants(plants, dto.ClientProfileFromRequest(r)))
 }
 
 // handleAddUserPlant handles the add user plant request
@@ -215,22 +401,70 @@ func (a *API) handleAddUserPlant(w http.ResponseWriter, r *http.Request) {
 
 	// Parse the request body
 	var req struct {
-		Location string `json:"location"`
+		Location string                `json:"location"`
+		Light    *models.SunlightLevel `json:"light,omitempty" validate:"omitempty,oneof=LOW MEDIUM HIGH"` // light at the spot
+		models.UserPlantDetails
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
 	// Add the plant to the user's collection
-	err = a.plantService.AddUserPlant(r.Context(), userID, plantID, req.Location)
+	warnings, err := a.plantService.AddUserPlant(r.Context(), userID, plantID, req.Location, req.Light, req.UserPlantDetails)
 	if err != nil {
+		if errors.Is(err, services.ErrPlantDeleted) {
+			utils.RespondWithError(w, http.StatusGone, err.Error())
+			return
+		}
+		if utils.RespondWithDatabaseError(w, err) {
+			return
+		}
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to add user plant")
 		return
 	}
 
 	// Respond with success
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Plant added to collection"})
+	utils.RespondWithWarnings(w, http.StatusOK, map[string]string{"message": "Plant added to collection"}, warnings)
+}
+
+// handleAddUserPlants handles the add user plants in a batch request
+func (a *API) handleAddUserPlants(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.AddUserPlantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Add the plants to the user's collection
+	response, err := a.plantService.AddUserPlants(r.Context(), userID, req.Plants)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to add user plants")
+		return
+	}
+
+	// Respond with the result of each plant
+	utils.RespondWithJSON(w, http.StatusOK, response)
 }
 
 // handleUpdateUserPlant handles the update user plant request
@@ -252,16 +486,30 @@ func (a *API) handleUpdateUserPlant(w http.ResponseWriter, r *http.Request) {
 
 	// Parse the request body
 	var req struct {
-		Location string `json:"location"`
+		Location *string `json:"location,omitempty"`
+		models.UserPlantDetails
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
 	// Update the user plant
-	err = a.plantService.UpdateUserPlant(r.Context(), userID, plantID, req.Location)
+	err = a.plantService.UpdateUserPlant(r.Context(), userID, plantID, req.Location, req.UserPlantDetails)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+			return
+		}
+		if utils.RespondWithDatabaseError(w, err) {
+			return
+		}
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update user plant")
 		return
 	}
@@ -298,7 +546,35 @@ func (a *API) handleRemoveUserPlant(w http.ResponseWriter, r *http.Request) {
 	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Plant removed from collection"})
 }
 
-// AdminPlantRequest represents the request body for creating a plant
+// handleGetNicknameSuggestions handles the get nickname suggestions request
+func (a *API) handleGetNicknameSuggestions(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the nickname suggestions
+	suggestions, err := a.plantService.GetNicknameSuggestions(r.Context(), userID, plantID, a.resolveClientLanguage(r))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+		return
+	}
+
+	// Respond with the suggestions
+	utils.RespondWithJSON(w, http.StatusOK, suggestions)
+}
+
+// AdminPlantRequest represents the request body for creating or updating a plant
 type AdminPlantRequest struct {
 	Name           string                  `json:"name"`
 	ScientificName string                  `json:"scientificName"`
@@ -306,7 +582,79 @@ type AdminPlantRequest struct {
 	ImageURL       string                  `json:"imageUrl"`
 	Price          *float64                `json:"price,omitempty"`
 	ShopID         *string                 `json:"shopId,omitempty"`
-	CareInstructions models.CareInstructions `json:"careInstructions"`
+	PetFriendly    *bool                   `json:"petFriendly,omitempty"`
+	Toxicity       *models.Toxicity        `json:"toxicity,omitempty"`      // Sets petFriendly when it is left out
+	CareInstructions models.CareInstructions `json:"careInstructions"`           // Ignored for cultivars
+	SpeciesID      *uuid.UUID              `json:"speciesId,omitempty"`     // Makes the plant a cultivar of the species
+	CareOverrides  *models.CareOverrides   `json:"careOverrides,omitempty"` // Care instruction fields the cultivar changes
+}
+
+// plant returns the plant model of the request
+func (req *AdminPlantRequest) plant() *models.Plant {
+	return &models.Plant{
+		Name:           req.Name,
+		ScientificName: req.ScientificName,
+		Description:    req.Description,
+		ImageURL:       models.AssetKeyFromURL(req.ImageURL),
+		Price:          req.Price,
+		ShopID:         req.ShopID,
+		PetFriendly:    req.PetFriendly,
+		Toxicity:       req.Toxicity,
+		SpeciesID:      req.SpeciesID,
+		CareOverrides:  req.CareOverrides,
+	}
+}
+
+// handleAdminListPlants handles the admin list plants request
+func (a *API) handleAdminListPlants(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// Parse the query parameters
+	var filter models.PlantFilter
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid page parameter")
+			return
+		}
+		filter.Page = page
+	}
+	if value := query.Get("pageSize"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid pageSize parameter")
+			return
+		}
+		filter.PageSize = pageSize
+	}
+	if value := query.Get("includeDeleted"); value != "" {
+		includeDeleted, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid includeDeleted parameter")
+			return
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+	if value := query.Get("status"); value != "" {
+		status := models.PlantStatus(value)
+		if status != models.PlantStatusDraft && status != models.PlantStatusInReview && status != models.PlantStatusPublished {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid status parameter")
+			return
+		}
+		filter.Status = &status
+	}
+
+	// Get the page of plants
+	plants, total, err := a.plantService.ListPlants(r.Context(), &filter)
+	if err != nil {
+		log.Printf("Failed to list plants: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plants")
+		return
+	}
+
+	// Respond with the plants and the total number in a header, as the public list does
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	utils.RespondWithJSON(w, http.StatusOK, dto.Plants(plants, dto.ClientProfileFromRequest(r)))
 }
 
 // handleAdminCreatePlant handles the admin create plant request
@@ -318,23 +666,103 @@ func (a *API) handleAdminCreatePlant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create a new plant model
-	plant := &models.Plant{
-		Name:           req.Name,
-		ScientificName: req.ScientificName,
-		Description:    req.Description,
-		ImageURL:       req.ImageURL,
-		Price:          req.Price,
-		ShopID:         req.ShopID,
-	}
-
 	// Create the plant
-	createdPlant, err := a.plantService.CreatePlant(r.Context(), plant, &req.CareInstructions)
+	createdPlant, warnings, err := a.plantService.CreatePlant(r.Context(), req.plant(), &req.CareInstructions)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidPlant) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create plant: "+err.Error())
 		return
 	}
 
-	// Respond with the created plant
-	utils.RespondWithJSON(w, http.StatusCreated, createdPlant)
+	// Respond with the created plant and any likely duplicates
+	utils.RespondWithWarnings(w, http.StatusCreated, createdPlant, warnings)
+}
+
+// handleAdminUpdatePlant handles the admin update plant request
+func (a *API) handleAdminUpdatePlant(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Parse the request body
+	var req AdminPlantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Update the plant and its care instructions
+	plant, err := a.plantService.UpdatePlant(r.Context(), plantID, req.plant(), &req.CareInstructions)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPlant):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		default:
+			log.Printf("Failed to update plant %s: %v", plantID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update plant")
+		}
+		return
+	}
+
+	// Respond with the updated plant
+	utils.RespondWithJSON(w, http.StatusOK, plant)
+}
+
+// handleAdminDeletePlant handles the admin delete plant request
+func (a *API) handleAdminDeletePlant(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Remove the plant from the catalog
+	if err := a.plantService.DeletePlant(r.Context(), plantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+			return
+		}
+		log.Printf("Failed to delete plant %s: %v", plantID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete plant")
+		return
+	}
+
+	// Respond with no content
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminGetStaleCareInstructions handles the admin report of care instructions that need review
+func (a *API) handleAdminGetStaleCareInstructions(w http.ResponseWriter, r *http.Request) {
+	// Get the maximum review age, defaulting to one year
+	maxAgeDays := 365
+	if days := r.URL.Query().Get("days"); days != "" {
+		parsed, err := strconv.Atoi(days)
+		if err != nil || parsed <= 0 {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid days parameter")
+			return
+		}
+		maxAgeDays = parsed
+	}
+
+	// Get the plants with stale care instructions
+	plants, err := a.plantService.GetStaleCareInstructions(r.Context(), maxAgeDays)
+	if err != nil {
+		log.Printf("Failed to get stale care instructions: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get stale care instructions")
+		return
+	}
+
+	// Respond with the plants
+	utils.RespondWithJSON(w, http.StatusOK, plants)
 }
\ No newline at end of file
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleAdminSetPlantImage handles the admin upload of the image of a catalog plant
+func (a *API) handleAdminSetPlantImage(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Parse the multipart form
+	r.Body = http.MaxBytesReader(w, r.Body, maxUserPlantPhotoBytes+1<<20)
+	if err := r.ParseMultipartForm(maxUserPlantPhotoBytes); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid form or image too large")
+		return
+	}
+
+	// Read the image
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Image is required")
+		return
+	}
+	defer file.Close()
+
+	image, err := io.ReadAll(io.LimitReader(file, maxUserPlantPhotoBytes+1))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to read image")
+		return
+	}
+	if len(image) > maxUserPlantPhotoBytes {
+		utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "Image too large")
+		return
+	}
+
+	// Store the image and point the plant at it
+	plant, err := a.plantService.SetPlantImage(r.Context(), plantID, image)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPhotoUploadUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrUnsupportedPhoto):
+			utils.RespondWithError(w, http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		default:
+			log.Printf("Failed to set image of plant %s: %v", plantID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to set plant image")
+		}
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, plant)
+}
+
+// handleAsset serves an uploaded asset from the upload directory, for a CDN pulling assets from the API
+func (a *API) handleAsset(w http.ResponseWriter, r *http.Request) {
+	if a.assets == nil {
+		http.NotFound(w, r)
+		return
+	}
+	http.StripPrefix(assetsPathPrefix, a.assets).ServeHTTP(w, r)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// maxPlantImportBytes limits the size of an uploaded plant catalog import
+const maxPlantImportBytes = 50 << 20
+
+// plantImportFormats maps the content types of plant imports to their format
+var plantImportFormats = map[string]string{
+	"text/csv":         services.PlantImportFormatCSV,
+	"application/csv":  services.PlantImportFormatCSV,
+	"application/json": services.PlantImportFormatJSON,
+}
+
+// handleAdminImportPlants handles the admin import plants request
+func (a *API) handleAdminImportPlants(w http.ResponseWriter, r *http.Request) {
+	// Get the format from the content type
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	format, ok := plantImportFormats[mediaType]
+	if !ok {
+		utils.RespondWithError(w, http.StatusUnsupportedMediaType, services.ErrUnsupportedPlantImportFormat.Error())
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	// Import the plants, reading the body as they are created
+	r.Body = http.MaxBytesReader(w, r.Body, maxPlantImportBytes)
+	result, err := a.plantService.ImportPlants(r.Context(), r.Body, format, dryRun)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "Import file too large")
+		case errors.Is(err, services.ErrInvalidPlantImport):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to import plants")
+		}
+		return
+	}
+
+	// Respond with the result of each row
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// handleMovePlants handles the move plants request: the plants of each room are moved to their new
+// room, plants short of or overexposed to light there are flagged, and demanding care is postponed
+// until they have settled in
+func (a *API) handleMovePlants(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if a.plantMoveService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Moving plants is not available")
+		return
+	}
+
+	// Parse and validate the request body
+	var req models.MovePlantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Move the plants
+	move, err := a.plantMoveService.MovePlants(r.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPlantMove) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to move plants")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, move)
+}
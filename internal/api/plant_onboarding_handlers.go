@@ -0,0 +1,102 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+)
+
+// maxOnboardingPhotoBytes limits the size of the photo a new plant is onboarded from
+const maxOnboardingPhotoBytes = 10 << 20
+
+// handleOnboardPlant handles the onboard plant request: the plant on the photo is identified, given a
+// room, added to the collection with its care schedule and the photo in one round trip
+func (a *API) handleOnboardPlant(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if a.plantOnboardingService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, services.ErrPlantIdentificationUnavailable.Error())
+		return
+	}
+
+	// Parse the multipart form
+	r.Body = http.MaxBytesReader(w, r.Body, maxOnboardingPhotoBytes+1<<20)
+	if err := r.ParseMultipartForm(maxOnboardingPhotoBytes); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid form or photo too large")
+		return
+	}
+
+	// Get the optional fields from the form
+	var req models.PlantOnboardingRequest
+	if value := r.FormValue("plantId"); value != "" {
+		plantID, err := uuid.Parse(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+			return
+		}
+		req.PlantID = &plantID
+	}
+	if _, ok := r.MultipartForm.Value["location"]; ok {
+		location := r.FormValue("location")
+		req.Location = &location
+	}
+	if nickname := r.FormValue("nickname"); nickname != "" {
+		req.Nickname = &nickname
+	}
+
+	// Read the photo
+	file, _, err := r.FormFile("photo")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Photo is required")
+		return
+	}
+	defer file.Close()
+
+	req.Photo, err = io.ReadAll(io.LimitReader(file, maxOnboardingPhotoBytes+1))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to read photo")
+		return
+	}
+	if len(req.Photo) > maxOnboardingPhotoBytes {
+		utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "Photo too large")
+		return
+	}
+
+	// Onboard the plant
+	result, warnings, err := a.plantOnboardingService.Onboard(r.Context(), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPlantNotIdentified):
+			// The candidates let the user pick the plant and try again with its ID
+			utils.RespondWithJSON(w, http.StatusUnprocessableEntity, result)
+		case errors.Is(err, services.ErrPlantIdentificationUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrUnsupportedPhoto):
+			utils.RespondWithError(w, http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, services.ErrPlantAlreadyInCollection):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrPlantDeleted):
+			utils.RespondWithError(w, http.StatusGone, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to onboard plant")
+		}
+		return
+	}
+
+	// Respond with the plant as added
+	utils.RespondWithWarnings(w, http.StatusCreated, result, warnings)
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetPlantReminders handles the get reminders of a plant in the collection request
+func (a *API) handleGetPlantReminders(w http.ResponseWriter, r *http.Request) {
+	if a.plantReminderService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Plant reminders are not available")
+		return
+	}
+
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the reminders
+	reminders, err := a.plantReminderService.GetReminders(r.Context(), userID, plantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+			return
+		}
+		log.Printf("Failed to get reminders of plant %s: %v", plantID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plant reminders")
+		return
+	}
+
+	// Respond with the reminders
+	utils.RespondWithJSON(w, http.StatusOK, reminders)
+}
+
+// handleCreatePlantReminder handles the add reminder to a plant in the collection request
+func (a *API) handleCreatePlantReminder(w http.ResponseWriter, r *http.Request) {
+	if a.plantReminderService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Plant reminders are not available")
+		return
+	}
+
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse and validate the request body
+	var req models.PlantReminderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Create the reminder
+	reminder, err := a.plantReminderService.CreateReminder(r.Context(), userID, plantID, &req)
+	if err != nil {
+		a.respondWithPlantReminderError(w, err, "Failed to create plant reminder")
+		return
+	}
+
+	// Respond with the reminder
+	utils.RespondWithJSON(w, http.StatusCreated, reminder)
+}
+
+// handleUpdatePlantReminder handles the update reminder of a plant in the collection request
+func (a *API) handleUpdatePlantReminder(w http.ResponseWriter, r *http.Request) {
+	if a.plantReminderService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Plant reminders are not available")
+		return
+	}
+
+	// Get the plant and reminder IDs from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+	reminderID, err := uuid.Parse(vars["reminderId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid reminder ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse and validate the request body
+	var req models.PlantReminderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Update the reminder
+	reminder, err := a.plantReminderService.UpdateReminder(r.Context(), userID, plantID, reminderID, &req)
+	if err != nil {
+		a.respondWithPlantReminderError(w, err, "Failed to update plant reminder")
+		return
+	}
+
+	// Respond with the reminder
+	utils.RespondWithJSON(w, http.StatusOK, reminder)
+}
+
+// handleDeletePlantReminder handles the delete reminder of a plant in the collection request
+func (a *API) handleDeletePlantReminder(w http.ResponseWriter, r *http.Request) {
+	if a.plantReminderService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Plant reminders are not available")
+		return
+	}
+
+	// Get the plant and reminder IDs from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+	reminderID, err := uuid.Parse(vars["reminderId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid reminder ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Delete the reminder
+	if err := a.plantReminderService.DeleteReminder(r.Context(), userID, plantID, reminderID); err != nil {
+		a.respondWithPlantReminderError(w, err, "Failed to delete plant reminder")
+		return
+	}
+
+	// Respond with no content
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondWithPlantReminderError maps an error of the plant reminder service to a response
+func (a *API) respondWithPlantReminderError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPlantReminder):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrTooManyPlantReminders):
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, sql.ErrNoRows):
+		utils.RespondWithError(w, http.StatusNotFound, "Plant reminder not found")
+	case utils.RespondWithDatabaseError(w, err):
+	default:
+		log.Printf("%s: %v", message, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleCatalogListDrafts handles the list draft plants request
+func (a *API) handleCatalogListDrafts(w http.ResponseWriter, r *http.Request) {
+	a.respondWithPlantsByStatus(w, r, models.PlantStatusDraft)
+}
+
+// handleCatalogGetReviewQueue handles the reviewer queue request: the plants submitted for review,
+// the ones waiting longest first
+func (a *API) handleCatalogGetReviewQueue(w http.ResponseWriter, r *http.Request) {
+	a.respondWithPlantsByStatus(w, r, models.PlantStatusInReview)
+}
+
+// respondWithPlantsByStatus responds with the catalog plants in a review state
+func (a *API) respondWithPlantsByStatus(w http.ResponseWriter, r *http.Request, status models.PlantStatus) {
+	plants, err := a.plantService.ListPlantsByStatus(r.Context(), status)
+	if err != nil {
+		if errors.Is(err, services.ErrPlantReviewUnavailable) {
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		log.Printf("Failed to list %s plants: %v", status, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plants")
+		return
+	}
+
+	// Respond with the plants
+	utils.RespondWithJSON(w, http.StatusOK, plants)
+}
+
+// handleCatalogCreatePlant handles the create draft plant request
+func (a *API) handleCatalogCreatePlant(w http.ResponseWriter, r *http.Request) {
+	// Parse the request body
+	var req AdminPlantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Create the plant as a draft
+	createdPlant, warnings, err := a.plantService.CreateDraftPlant(r.Context(), req.plant(), &req.CareInstructions)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPlant) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Failed to create draft plant: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create plant")
+		return
+	}
+
+	// Respond with the created draft and any likely duplicates
+	utils.RespondWithWarnings(w, http.StatusCreated, createdPlant, warnings)
+}
+
+// handleCatalogGetPlant handles the get catalog plant request, which finds plants in any review state
+func (a *API) handleCatalogGetPlant(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the plant
+	plant, err := a.plantService.GetCatalogPlant(r.Context(), plantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+			return
+		}
+		log.Printf("Failed to get catalog plant %s: %v", plantID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plant")
+		return
+	}
+
+	// Respond with the plant
+	utils.RespondWithJSON(w, http.StatusOK, plant)
+}
+
+// handleCatalogUpdatePlant handles the update draft plant request
+func (a *API) handleCatalogUpdatePlant(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Parse the request body
+	var req AdminPlantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Update the draft and its care instructions
+	plant, err := a.plantService.UpdateDraftPlant(r.Context(), plantID, req.plant(), &req.CareInstructions)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPlant):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrPlantNotDraft):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		default:
+			log.Printf("Failed to update draft plant %s: %v", plantID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update plant")
+		}
+		return
+	}
+
+	// Respond with the updated draft
+	utils.RespondWithJSON(w, http.StatusOK, plant)
+}
+
+// handleCatalogSetPlantStatus handles the move plant to another review state request
+func (a *API) handleCatalogSetPlantStatus(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Parse and validate the request body
+	var req models.PlantStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Get the roles the move is checked against
+	roles, err := a.userService.GetRoles(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to get the roles of user %s: %v", userID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to check permissions")
+		return
+	}
+
+	// Move the plant
+	plant, err := a.plantService.ChangePlantStatus(r.Context(), plantID, req.Status, roles)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPlantReviewUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrInvalidPlantStatusChange):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrPlantStatusChangeForbidden):
+			utils.RespondWithError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		default:
+			log.Printf("Failed to change the status of plant %s: %v", plantID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to change plant status")
+		}
+		return
+	}
+
+	// Respond with the plant in its new state
+	utils.RespondWithJSON(w, http.StatusOK, plant)
+}
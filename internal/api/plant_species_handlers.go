@@ -0,0 +1,131 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleAdminListSpecies handles the admin list species request
+func (a *API) handleAdminListSpecies(w http.ResponseWriter, r *http.Request) {
+	// Get the species
+	species, err := a.plantService.ListSpecies(r.Context())
+	if err != nil {
+		log.Printf("Failed to list species: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get species")
+		return
+	}
+
+	// Respond with the species
+	utils.RespondWithJSON(w, http.StatusOK, species)
+}
+
+// handleAdminGetSpecies handles the admin get species request
+func (a *API) handleAdminGetSpecies(w http.ResponseWriter, r *http.Request) {
+	// Get the species ID from the URL
+	speciesID, err := uuid.Parse(mux.Vars(r)["speciesId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid species ID")
+		return
+	}
+
+	// Get the species
+	species, err := a.plantService.GetSpecies(r.Context(), speciesID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Species not found")
+			return
+		}
+		log.Printf("Failed to get species %s: %v", speciesID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get species")
+		return
+	}
+
+	// Respond with the species
+	utils.RespondWithJSON(w, http.StatusOK, species)
+}
+
+// handleAdminCreateSpecies handles the admin create species request
+func (a *API) handleAdminCreateSpecies(w http.ResponseWriter, r *http.Request) {
+	// Parse the request body
+	var req models.PlantSpeciesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Create the species
+	species, err := a.plantService.CreateSpecies(r.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSpecies):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrSpeciesExists):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		default:
+			log.Printf("Failed to create species %q: %v", req.ScientificName, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create species")
+		}
+		return
+	}
+
+	// Respond with the created species
+	utils.RespondWithJSON(w, http.StatusCreated, species)
+}
+
+// handleAdminUpdateSpecies handles the admin update species request
+func (a *API) handleAdminUpdateSpecies(w http.ResponseWriter, r *http.Request) {
+	// Get the species ID from the URL
+	speciesID, err := uuid.Parse(mux.Vars(r)["speciesId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid species ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.PlantSpeciesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Update the species and the care instructions of its cultivars
+	species, err := a.plantService.UpdateSpecies(r.Context(), speciesID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSpecies):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrSpeciesExists):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Species not found")
+		default:
+			log.Printf("Failed to update species %s: %v", speciesID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update species")
+		}
+		return
+	}
+
+	// Respond with the updated species
+	utils.RespondWithJSON(w, http.StatusOK, species)
+}
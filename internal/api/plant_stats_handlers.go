@@ -0,0 +1,41 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetPlantStats handles the get plant stats request: anonymized ownership statistics of a
+// catalog plant, aggregated every night
+func (a *API) handleGetPlantStats(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	if a.plantStatsService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Plant stats are not available")
+		return
+	}
+
+	// Get the stats
+	stats, err := a.plantStatsService.GetPlantStats(r.Context(), plantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plant stats")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, stats)
+}
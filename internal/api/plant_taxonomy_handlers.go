@@ -0,0 +1,231 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetCategories handles the list categories request
+func (a *API) handleGetCategories(w http.ResponseWriter, r *http.Request) {
+	// Get the categories
+	categories, err := a.plantService.ListCategories(r.Context())
+	if err != nil {
+		if errors.Is(err, services.ErrTaxonomyUnavailable) {
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		log.Printf("Failed to list categories: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get categories")
+		return
+	}
+
+	// Respond with the categories
+	utils.RespondWithJSON(w, http.StatusOK, categories)
+}
+
+// handleGetTags handles the list tags request
+func (a *API) handleGetTags(w http.ResponseWriter, r *http.Request) {
+	// Get the tags
+	tags, err := a.plantService.ListTags(r.Context())
+	if err != nil {
+		if errors.Is(err, services.ErrTaxonomyUnavailable) {
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		log.Printf("Failed to list tags: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get tags")
+		return
+	}
+
+	// Respond with the tags
+	utils.RespondWithJSON(w, http.StatusOK, tags)
+}
+
+// handleAdminCreateCategory handles the admin create category request
+func (a *API) handleAdminCreateCategory(w http.ResponseWriter, r *http.Request) {
+	// Parse the request body
+	var req models.CategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Create the category
+	category, err := a.plantService.CreateCategory(r.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTaxonomyUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrInvalidCategory):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrCategoryExists):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		default:
+			log.Printf("Failed to create category %q: %v", req.Slug, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create category")
+		}
+		return
+	}
+
+	// Respond with the created category
+	utils.RespondWithJSON(w, http.StatusCreated, category)
+}
+
+// handleAdminUpdateCategory handles the admin update category request
+func (a *API) handleAdminUpdateCategory(w http.ResponseWriter, r *http.Request) {
+	// Get the category ID from the URL
+	categoryID, err := uuid.Parse(mux.Vars(r)["categoryId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.CategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Update the category
+	category, err := a.plantService.UpdateCategory(r.Context(), categoryID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTaxonomyUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrInvalidCategory):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrCategoryExists):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Category not found")
+		default:
+			log.Printf("Failed to update category %s: %v", categoryID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update category")
+		}
+		return
+	}
+
+	// Respond with the updated category
+	utils.RespondWithJSON(w, http.StatusOK, category)
+}
+
+// handleAdminDeleteCategory handles the admin delete category request
+func (a *API) handleAdminDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	// Get the category ID from the URL
+	categoryID, err := uuid.Parse(mux.Vars(r)["categoryId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	// Delete the category
+	if err := a.plantService.DeleteCategory(r.Context(), categoryID); err != nil {
+		switch {
+		case errors.Is(err, services.ErrTaxonomyUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Category not found")
+		case utils.RespondWithDatabaseError(w, err):
+		default:
+			log.Printf("Failed to delete category %s: %v", categoryID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete category")
+		}
+		return
+	}
+
+	// Respond with no content
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminGetPlantTaxonomy handles the admin get plant categories and tags request
+func (a *API) handleAdminGetPlantTaxonomy(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the categories and tags
+	taxonomy, err := a.plantService.GetPlantTaxonomy(r.Context(), plantID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTaxonomyUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		default:
+			log.Printf("Failed to get the taxonomy of plant %s: %v", plantID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plant categories and tags")
+		}
+		return
+	}
+
+	// Respond with the categories and tags
+	utils.RespondWithJSON(w, http.StatusOK, taxonomy)
+}
+
+// handleAdminSetPlantTaxonomy handles the admin replace plant categories and tags request
+func (a *API) handleAdminSetPlantTaxonomy(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.PlantTaxonomy
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Replace the categories and tags
+	taxonomy, err := a.plantService.SetPlantTaxonomy(r.Context(), plantID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTaxonomyUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrUnknownCategory), errors.Is(err, services.ErrInvalidTag):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		default:
+			log.Printf("Failed to set the taxonomy of plant %s: %v", plantID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to set plant categories and tags")
+		}
+		return
+	}
+
+	// Respond with the saved categories and tags
+	utils.RespondWithJSON(w, http.StatusOK, taxonomy)
+}
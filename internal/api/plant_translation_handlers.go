@@ -0,0 +1,120 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// localizePlants shows plants in the language of the client, see resolveClientLanguage; responses of
+// catalog content vary by Accept-Language for the caches in between
+func (a *API) localizePlants(w http.ResponseWriter, r *http.Request, plants ...*models.Plant) {
+	a.plantService.LocalizePlants(r.Context(), a.resolveClientLanguage(r), plants...)
+	w.Header().Add("Vary", "Accept-Language")
+}
+
+// handleAdminGetPlantTranslations handles the admin get plant translations request
+func (a *API) handleAdminGetPlantTranslations(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	plantID, err := uuid.Parse(mux.Vars(r)["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the translations
+	translations, err := a.plantService.GetPlantTranslations(r.Context(), plantID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPlantTranslationsUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plant translations")
+		}
+		return
+	}
+
+	// Respond with the translations
+	utils.RespondWithJSON(w, http.StatusOK, translations)
+}
+
+// handleAdminSetPlantTranslation handles the admin set plant translation request, creating or
+// replacing the translation of a plant into a language
+func (a *API) handleAdminSetPlantTranslation(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID and language from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+	language := models.Language(vars["language"])
+
+	// Parse and validate the request body
+	var req models.PlantTranslationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Save the translation
+	translation, err := a.plantService.SetPlantTranslation(r.Context(), plantID, language, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPlantTranslationsUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrInvalidPlantTranslation):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to save plant translation")
+		}
+		return
+	}
+
+	// Respond with the translation
+	utils.RespondWithJSON(w, http.StatusOK, translation)
+}
+
+// handleAdminDeletePlantTranslation handles the admin delete plant translation request
+func (a *API) handleAdminDeletePlantTranslation(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID and language from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+	language := models.Language(vars["language"])
+
+	// Delete the translation
+	if err := a.plantService.DeletePlantTranslation(r.Context(), plantID, language); err != nil {
+		switch {
+		case errors.Is(err, services.ErrPlantTranslationsUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrInvalidPlantTranslation):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant translation not found")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete plant translation")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// PublicAPIEndpoint describes an endpoint of the public API
+type PublicAPIEndpoint struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// PublicAPIDocs describes the public API for integrators
+type PublicAPIDocs struct {
+	Version          string              `json:"version"`
+	AuthHeader       string              `json:"authHeader"`
+	RateLimit        int                 `json:"rateLimit"`
+	RateLimitWindow  string              `json:"rateLimitWindow"`
+	KeyManagementURL string              `json:"keyManagementUrl"`
+	Endpoints        []PublicAPIEndpoint `json:"endpoints"`
+}
+
+// publicAPIEndpoints lists the endpoints available to API key holders
+var publicAPIEndpoints = []PublicAPIEndpoint{
+	{Method: http.MethodGet, Path: "/public/v1/plants", Description: "List plants in the catalog, filtered by sunlight, humidity, petFriendly, minPrice, maxPrice and shopId and paged with page and pageSize"},
+	{Method: http.MethodGet, Path: "/public/v1/plants/search?query={query}", Description: "Search plants by name, scientific name or description, with light, humidity, pet, family and water filters such as light:low pet:true water:<7"},
+	{Method: http.MethodGet, Path: "/public/v1/plants/{plantId}", Description: "Get a plant by ID"},
+	{Method: http.MethodGet, Path: "/public/v1/plants/{plantId}/care-instructions", Description: "Get the care instructions of a plant"},
+}
+
+// handlePublicDocs handles the public API documentation request
+func (a *API) handlePublicDocs(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJSON(w, http.StatusOK, PublicAPIDocs{
+		Version:          "v1",
+		AuthHeader:       middleware.APIKeyHeader,
+		RateLimit:        a.publicRateLimiter.Limit(),
+		RateLimitWindow:  a.publicRateLimiter.Window().String(),
+		KeyManagementURL: "/users/me/api-keys",
+		Endpoints:        publicAPIEndpoints,
+	})
+}
+
+// handlePublicGetPlantCareInstructions handles the public get plant care instructions request
+func (a *API) handlePublicGetPlantCareInstructions(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the plant
+	plant, err := a.plantService.GetPlant(r.Context(), plantID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+		return
+	}
+
+	// Show the care notes in the language of the client
+	a.localizePlants(w, r, plant)
+
+	// Respond with the care instructions
+	utils.RespondWithJSON(w, http.StatusOK, plant.CareInstructions)
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// handleGetDailyQuiz handles the get daily quiz request
+func (a *API) handleGetDailyQuiz(w http.ResponseWriter, r *http.Request) {
+	if a.quizService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Quiz is not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the quiz of today in the user's language
+	quiz, err := a.quizService.GetDailyQuiz(r.Context(), userID, a.resolveClientLanguage(r))
+	if err != nil {
+		if errors.Is(err, services.ErrQuizUnavailable) {
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get daily quiz")
+		return
+	}
+
+	// Respond with the quiz
+	utils.RespondWithJSON(w, http.StatusOK, quiz)
+}
+
+// handleSubmitQuizAnswers handles the submit quiz answers request
+func (a *API) handleSubmitQuizAnswers(w http.ResponseWriter, r *http.Request) {
+	if a.quizService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Quiz is not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.SubmitQuizAnswersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	language := a.resolveClientLanguage(r)
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, language)
+		return
+	}
+
+	// Score the answers
+	result, err := a.quizService.SubmitAnswers(r.Context(), userID, language, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidQuizAnswers):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrQuizClosed), errors.Is(err, services.ErrQuizAlreadyAnswered):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, services.ErrQuizUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to submit quiz answers")
+		}
+		return
+	}
+
+	// Respond with the result and the badges it earned
+	utils.RespondWithJSON(w, http.StatusCreated, result)
+}
+
+// handleGetQuizLeaderboard handles the get quiz leaderboard request
+func (a *API) handleGetQuizLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if a.quizService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Quiz is not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the number of days counted
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+
+	// Get the leaderboard of the user and the users they follow
+	leaderboard, err := a.quizService.GetLeaderboard(r.Context(), userID, days)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get quiz leaderboard")
+		return
+	}
+
+	// Respond with the leaderboard
+	utils.RespondWithJSON(w, http.StatusOK, leaderboard)
+}
+
+// handleGetBadges handles the get badges request
+func (a *API) handleGetBadges(w http.ResponseWriter, r *http.Request) {
+	if a.badgeService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Badges are not available")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the user's badges
+	badges, err := a.badgeService.GetBadges(r.Context(), userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get badges")
+		return
+	}
+
+	// Respond with the badges
+	utils.RespondWithJSON(w, http.StatusOK, badges)
+}
@@ -1,11 +1,19 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/anpanovv/planter/internal/dto"
 	"github.com/anpanovv/planter/internal/middleware"
 	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
 	"github.com/anpanovv/planter/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -22,7 +30,7 @@ func (a *API) handleSaveQuestionnaire(w http.ResponseWriter, r *http.Request) {
 
 	// Validate the request
 	if err := utils.Validate.Struct(req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, utils.ValidationErrorMessage(err))
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
 		return
 	}
 
@@ -41,7 +49,7 @@ func (a *API) handleSaveQuestionnaire(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get recommendations
-	plants, err := a.recommendationService.GetRecommendations(r.Context(), questionnaire.ID)
+	plants, warnings, err := a.recommendationService.GetRecommendations(r.Context(), questionnaire.ID)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get recommendations")
 		return
@@ -53,7 +61,7 @@ func (a *API) handleSaveQuestionnaire(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Respond with the best matching plant (first in the list)
-	utils.RespondWithJSON(w, http.StatusCreated, plants[0])
+	utils.RespondWithWarnings(w, http.StatusCreated, plants[0], warnings)
 }
 
 // handleGetRecommendations handles the get recommendations request
@@ -66,15 +74,88 @@ func (a *API) handleGetRecommendations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Get the recommendations; the list has no room for warnings
+	plants, _, err := a.recommendationService.GetRecommendations(r.Context(), questionnaireID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get recommendations")
+		return
+	}
+
+	// Respond with the recommended plants in the shape the client asked for
+	utils.RespondWithJSON(w, http.StatusOK, dto.Plants(plants, dto.ClientProfileFromRequest(r)))
+}
+
+// handleGetQuickRecommendations handles the quick recommendations request
+func (a *API) handleGetQuickRecommendations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// Parse the query parameters
+	req := models.QuickRecommendationsRequest{
+		Light: models.SunlightLevel(strings.ToUpper(query.Get("light"))),
+		Tags:  query["tag"],
+	}
+	var err error
+	if value := query.Get("pet"); value != "" {
+		if req.PetFriendly, err = strconv.ParseBool(value); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid pet parameter")
+			return
+		}
+	}
+	if value := query.Get("children"); value != "" {
+		if req.HasChildren, err = strconv.ParseBool(value); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid children parameter")
+			return
+		}
+	}
+	if value := query.Get("effort"); value != "" {
+		if req.Effort, err = strconv.Atoi(value); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid effort parameter")
+			return
+		}
+	}
+	if value := query.Get("count"); value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid count parameter")
+			return
+		}
+		req.Count = &count
+	}
+	if value := query.Get("maxPerFamily"); value != "" {
+		maxPerFamily, err := strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid maxPerFamily parameter")
+			return
+		}
+		req.MaxPerFamily = &maxPerFamily
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Save the request only when the authenticated user opts in
+	var userID *uuid.UUID
+	if save, _ := strconv.ParseBool(query.Get("save")); save {
+		authUserID, err := middleware.GetUserID(r.Context())
+		if err != nil {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required to save recommendations")
+			return
+		}
+		userID = &authUserID
+	}
+
 	// Get the recommendations
-	plants, err := a.recommendationService.GetRecommendations(r.Context(), questionnaireID)
+	response, warnings, err := a.recommendationService.QuickRecommendations(r.Context(), userID, &req)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get recommendations")
 		return
 	}
 
 	// Respond with the recommended plants
-	utils.RespondWithJSON(w, http.StatusOK, plants)
+	utils.RespondWithWarnings(w, http.StatusOK, response, warnings)
 }
 
 // handleSaveDetailedQuestionnaire handles the save detailed questionnaire request
@@ -88,7 +169,7 @@ func (a *API) handleSaveDetailedQuestionnaire(w http.ResponseWriter, r *http.Req
 
 	// Validate the request
 	if err := utils.Validate.Struct(req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, utils.ValidationErrorMessage(err))
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
 		return
 	}
 
@@ -107,7 +188,7 @@ func (a *API) handleSaveDetailedQuestionnaire(w http.ResponseWriter, r *http.Req
 	}
 
 	// Get recommendations
-	plants, err := a.recommendationService.GetRecommendations(r.Context(), questionnaire.ID)
+	plants, warnings, err := a.recommendationService.GetRecommendations(r.Context(), questionnaire.ID)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get recommendations")
 		return
@@ -119,7 +200,7 @@ func (a *API) handleSaveDetailedQuestionnaire(w http.ResponseWriter, r *http.Req
 	}
 
 	// Respond with the best matching plant (first in the list)
-	utils.RespondWithJSON(w, http.StatusCreated, plants[0])
+	utils.RespondWithWarnings(w, http.StatusCreated, plants[0], warnings)
 }
 
 // handleCreateChatSession handles the create chat session request
@@ -196,8 +277,8 @@ func (a *API) handleGetChatSession(w http.ResponseWriter, r *http.Request) {
 	utils.RespondWithJSON(w, http.StatusOK, session)
 }
 
-// handleSendChatMessage handles the send chat message request
-func (a *API) handleSendChatMessage(w http.ResponseWriter, r *http.Request) {
+// handleRenameChatSession handles the rename chat session request
+func (a *API) handleRenameChatSession(w http.ResponseWriter, r *http.Request) {
 	// Get the authenticated user ID
 	userID, err := middleware.GetUserID(r.Context())
 	if err != nil {
@@ -206,15 +287,14 @@ func (a *API) handleSendChatMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get the chat session ID from the URL
-	vars := mux.Vars(r)
-	sessionID, err := uuid.Parse(vars["sessionId"])
+	sessionID, err := uuid.Parse(mux.Vars(r)["sessionId"])
 	if err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "Invalid session ID")
 		return
 	}
 
 	// Parse the request body
-	var req models.ChatRequest
+	var req models.RenameChatSessionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
@@ -222,14 +302,155 @@ func (a *API) handleSendChatMessage(w http.ResponseWriter, r *http.Request) {
 
 	// Validate the request
 	if err := utils.Validate.Struct(req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, utils.ValidationErrorMessage(err))
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Rename the session
+	session, err := a.recommendationService.RenameChatSession(r.Context(), sessionID, userID, req.Title)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidChatSessionTitle):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Chat session not found")
+		case errors.Is(err, services.ErrChatSessionForbidden):
+			utils.RespondWithError(w, http.StatusForbidden, "Forbidden")
+		default:
+			log.Printf("Failed to rename chat session %s: %v", sessionID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to rename chat session")
+		}
+		return
+	}
+
+	// Respond with the renamed session
+	utils.RespondWithJSON(w, http.StatusOK, session)
+}
+
+// handleDeleteChatSession handles the delete chat session request
+func (a *API) handleDeleteChatSession(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
+	// Get the chat session ID from the URL
+	sessionID, err := uuid.Parse(mux.Vars(r)["sessionId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	// Delete the session with its messages
+	if err := a.recommendationService.DeleteChatSession(r.Context(), sessionID, userID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Chat session not found")
+		case errors.Is(err, services.ErrChatSessionForbidden):
+			utils.RespondWithError(w, http.StatusForbidden, "Forbidden")
+		default:
+			log.Printf("Failed to delete chat session %s: %v", sessionID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete chat session")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// statusClientClosedRequest is the nginx status of requests the client abandoned before the answer.
+// The client never reads it, but it keeps these requests apart from server errors in the logs.
+const statusClientClosedRequest = 499
+
+// maxChatImageBytes limits the size of a photo attached to a chat message
+const maxChatImageBytes = 10 << 20
+
+// handleSendChatMessage handles the send chat message request. The message is sent as JSON, or as
+// a multipart form with the message and an optional image when a photo is attached. A client that
+// disconnects cancels the Yandex GPT request along with r.Context().
+func (a *API) handleSendChatMessage(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the chat session ID from the URL
+	vars := mux.Vars(r)
+	sessionID, err := uuid.Parse(vars["sessionId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.ChatRequest
+	var image []byte
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.Body = http.MaxBytesReader(w, r.Body, maxChatImageBytes+1<<20)
+		if err := r.ParseMultipartForm(maxChatImageBytes); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid form or photo too large")
+			return
+		}
+		req.Message = r.FormValue("message")
+
+		// Read the attached photo, if any
+		file, _, err := r.FormFile("image")
+		if err != nil && !errors.Is(err, http.ErrMissingFile) {
+			utils.RespondWithError(w, http.StatusBadRequest, "Failed to read photo")
+			return
+		}
+		if file != nil {
+			defer file.Close()
+			image, err = io.ReadAll(io.LimitReader(file, maxChatImageBytes+1))
+			if err != nil {
+				utils.RespondWithError(w, http.StatusBadRequest, "Failed to read photo")
+				return
+			}
+			if len(image) > maxChatImageBytes {
+				utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "Photo too large")
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request; a message may be left out when a photo is attached
+	if image == nil {
+		if err := utils.Validate.Struct(req); err != nil {
+			utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+			return
+		}
+	}
+
+	// Use the user's preferred language when the message language cannot be detected
+	var preferredLanguage models.Language
+	if user, err := a.userService.GetUser(r.Context(), userID); err == nil {
+		preferredLanguage = user.Language
+	}
+
 	// Send the chat message
-	message, err := a.recommendationService.SendChatMessage(r.Context(), sessionID, userID, req.Message)
+	message, err := a.recommendationService.SendChatMessageWithImage(r.Context(), sessionID, userID, req.Message, image, preferredLanguage)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to send chat message")
+		switch {
+		case errors.Is(err, services.ErrChatAttachmentsUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrUnsupportedChatImage):
+			utils.RespondWithError(w, http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, services.ErrLLMQuotaExceeded):
+			utils.RespondWithError(w, http.StatusTooManyRequests, "Monthly chat quota exceeded")
+		case errors.Is(err, services.ErrYandexGPTCircuitOpen):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, "Chat is temporarily unavailable")
+		case errors.Is(err, services.ErrChatRequestCanceled):
+			utils.RespondWithError(w, statusClientClosedRequest, "Chat request canceled")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to send chat message")
+		}
 		return
 	}
 
@@ -263,4 +484,12 @@ func (a *API) handleGetChatMessages(w http.ResponseWriter, r *http.Request) {
 
 	// Respond with the chat messages
 	utils.RespondWithJSON(w, http.StatusOK, messages)
-}
\ No newline at end of file
+}
+// handleAdminYandexGPTSelfTest handles the admin Yandex GPT self-test request
+func (a *API) handleAdminYandexGPTSelfTest(w http.ResponseWriter, r *http.Request) {
+	// Run the self-test
+	status := a.recommendationService.SelfTestYandexGPT(r.Context())
+
+	// Respond with the result
+	utils.RespondWithJSON(w, http.StatusOK, status)
+}
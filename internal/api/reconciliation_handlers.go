@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// handleAdminRunReconciliation handles the admin run reconciliation request
+func (a *API) handleAdminRunReconciliation(w http.ResponseWriter, r *http.Request) {
+	// Only count the inconsistencies when a dry run is requested
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	// Run the reconciliation
+	run, err := a.reconciliationService.Reconcile(r.Context(), dryRun)
+	if run == nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to run reconciliation")
+		return
+	}
+
+	// Respond with the report, which includes the errors of failed checks
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusInternalServerError
+	}
+	utils.RespondWithJSON(w, status, run)
+}
+
+// handleAdminGetReconciliationRuns handles the admin get reconciliation runs request
+func (a *API) handleAdminGetReconciliationRuns(w http.ResponseWriter, r *http.Request) {
+	// Get the number of runs
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	// Get the runs
+	runs, err := a.reconciliationService.GetRuns(r.Context(), limit)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get reconciliation runs")
+		return
+	}
+
+	// Respond with the runs
+	utils.RespondWithJSON(w, http.StatusOK, runs)
+}
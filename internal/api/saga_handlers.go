@@ -0,0 +1,40 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anpanovv/planter/internal/utils"
+)
+
+// handleAdminGetStuckSagas handles the admin get stuck sagas request
+func (a *API) handleAdminGetStuckSagas(w http.ResponseWriter, r *http.Request) {
+	if a.sagaOrchestrator == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Sagas are not available")
+		return
+	}
+
+	// Parse how long a saga may go without advancing; the default when unset
+	var stuckAfter time.Duration
+	if value := r.URL.Query().Get("stuckAfterMinutes"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes < 1 {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid stuckAfterMinutes parameter")
+			return
+		}
+		stuckAfter = time.Duration(minutes) * time.Minute
+	}
+
+	// Get the stuck sagas
+	sagas, err := a.sagaOrchestrator.ListStuck(r.Context(), stuckAfter)
+	if err != nil {
+		log.Printf("Failed to get stuck sagas: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get stuck sagas")
+		return
+	}
+
+	// Respond with the sagas
+	utils.RespondWithJSON(w, http.StatusOK, sagas)
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleGetSeasonalGuide handles the get seasonal guide request: the plants best bought, propagated
+// and repotted in a month, the current one by default
+func (a *API) handleGetSeasonalGuide(w http.ResponseWriter, r *http.Request) {
+	if a.seasonalGuideService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Seasonal guide is not available")
+		return
+	}
+
+	// Parse the month and hemisphere
+	query := r.URL.Query()
+	month := 0
+	if value := query.Get("month"); value != "" {
+		var err error
+		if month, err = strconv.Atoi(value); err != nil || month < 1 || month > 12 {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid month parameter")
+			return
+		}
+	}
+	hemisphere := models.Hemisphere(query.Get("hemisphere"))
+
+	// Get the guide
+	guide, err := a.seasonalGuideService.GetGuide(r.Context(), month, hemisphere)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSeasonalGuide) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Failed to get seasonal guide of month %d: %v", month, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get seasonal guide")
+		return
+	}
+
+	// Show the plants in the client's language
+	var plants []*models.Plant
+	for _, items := range [][]*models.SeasonalGuideItem{guide.Buy, guide.Propagate, guide.Repot} {
+		for _, item := range items {
+			plants = append(plants, item.Plant)
+		}
+	}
+	a.localizePlants(w, r, plants...)
+
+	utils.RespondWithJSON(w, http.StatusOK, guide)
+}
+
+// handleAdminGetSeasonalGuideOverrides handles the admin list seasonal guide overrides request, of
+// one month or of all of them
+func (a *API) handleAdminGetSeasonalGuideOverrides(w http.ResponseWriter, r *http.Request) {
+	if a.seasonalGuideService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Seasonal guide is not available")
+		return
+	}
+
+	// Parse the month
+	month := 0
+	if value := r.URL.Query().Get("month"); value != "" {
+		var err error
+		if month, err = strconv.Atoi(value); err != nil || month < 1 || month > 12 {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid month parameter")
+			return
+		}
+	}
+
+	// Get the overrides
+	overrides, err := a.seasonalGuideService.ListOverrides(r.Context(), month)
+	if err != nil {
+		log.Printf("Failed to list seasonal guide overrides: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get seasonal guide overrides")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, overrides)
+}
+
+// handleAdminSetSeasonalGuideOverride handles the admin set seasonal guide override request: a plant
+// pinned to a list of a month or hidden from it
+func (a *API) handleAdminSetSeasonalGuideOverride(w http.ResponseWriter, r *http.Request) {
+	if a.seasonalGuideService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Seasonal guide is not available")
+		return
+	}
+
+	// Parse the request body
+	var req models.SeasonalGuideOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Set the override
+	override, err := a.seasonalGuideService.SetOverride(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found")
+			return
+		}
+		log.Printf("Failed to set seasonal guide override of plant %s: %v", req.PlantID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to set seasonal guide override")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, override)
+}
+
+// handleAdminDeleteSeasonalGuideOverride handles the admin delete seasonal guide override request
+func (a *API) handleAdminDeleteSeasonalGuideOverride(w http.ResponseWriter, r *http.Request) {
+	if a.seasonalGuideService == nil {
+		utils.RespondWithError(w, http.StatusServiceUnavailable, "Seasonal guide is not available")
+		return
+	}
+
+	// Get the override ID from the URL
+	overrideID, err := uuid.Parse(mux.Vars(r)["overrideId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid override ID")
+		return
+	}
+
+	// Delete the override
+	if err := a.seasonalGuideService.DeleteOverride(r.Context(), overrideID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Seasonal guide override not found")
+			return
+		}
+		log.Printf("Failed to delete seasonal guide override %s: %v", overrideID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete seasonal guide override")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
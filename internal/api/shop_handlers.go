@@ -1,13 +1,23 @@
 package api
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 
+	"github.com/anpanovv/planter/internal/dto"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
 	"github.com/anpanovv/planter/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+// maxShopImportBytes limits the size of an uploaded shop import
+const maxShopImportBytes = 1 << 20
+
 // handleGetAllShops handles the get all shops request
 func (a *API) handleGetAllShops(w http.ResponseWriter, r *http.Request) {
 	// Get all shops
@@ -59,6 +69,395 @@ func (a *API) handleGetShopPlants(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Respond with the plants
-	utils.RespondWithJSON(w, http.StatusOK, plants)
-}
\ No newline at end of file
+	// Respond with the plants in the shape the client asked for
+	utils.RespondWithJSON(w, http.StatusOK, dto.Plants(plants, dto.ClientProfileFromRequest(r)))
+}
+
+// handleGetPlantOffers handles the get plant offers request
+func (a *API) handleGetPlantOffers(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the offers
+	offers, err := a.shopService.GetPlantOffers(r.Context(), plantID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get plant offers")
+		return
+	}
+
+	// Respond with the offers
+	utils.RespondWithJSON(w, http.StatusOK, offers)
+}
+
+// handleAdminUpdateShopPlant handles the admin update shop plant request
+func (a *API) handleAdminUpdateShopPlant(w http.ResponseWriter, r *http.Request) {
+	// Get the shop and plant IDs from the URL
+	vars := mux.Vars(r)
+	shopID, err := uuid.Parse(vars["shopId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid shop ID")
+		return
+	}
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.UpdateShopPlantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Update the shop plant
+	shopPlant, err := a.shopService.UpdateShopPlant(r.Context(), shopID, plantID, &req)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Plant not sold by this shop")
+		return
+	}
+
+	// Respond with the updated shop plant
+	utils.RespondWithJSON(w, http.StatusOK, shopPlant)
+}
+
+// handleGetSpecialOffers handles the get special offers request: the offers that have not ended yet
+func (a *API) handleGetSpecialOffers(w http.ResponseWriter, r *http.Request) {
+	// Get the special offers
+	offers, err := a.shopService.GetSpecialOffers(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get special offers")
+		return
+	}
+
+	// Respond with the special offers
+	if offers == nil {
+		offers = []*models.SpecialOffer{}
+	}
+	utils.RespondWithJSON(w, http.StatusOK, offers)
+}
+
+// handleAdminCreateShop handles the admin create shop request
+func (a *API) handleAdminCreateShop(w http.ResponseWriter, r *http.Request) {
+	// Parse the request body
+	var req models.ShopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Create the shop
+	shop, err := a.shopService.CreateShop(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidShop) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if utils.RespondWithDatabaseError(w, err) {
+			return
+		}
+		log.Printf("Failed to create shop %q: %v", req.Name, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create shop")
+		return
+	}
+
+	// Respond with the created shop
+	utils.RespondWithJSON(w, http.StatusCreated, shop)
+}
+
+// handleAdminUpdateShop handles the admin update shop request
+func (a *API) handleAdminUpdateShop(w http.ResponseWriter, r *http.Request) {
+	// Get the shop ID from the URL
+	shopID, err := uuid.Parse(mux.Vars(r)["shopId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid shop ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.ShopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Update the shop
+	shop, err := a.shopService.UpdateShop(r.Context(), shopID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidShop):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Shop not found")
+		case utils.RespondWithDatabaseError(w, err):
+		default:
+			log.Printf("Failed to update shop %s: %v", shopID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update shop")
+		}
+		return
+	}
+
+	// Respond with the updated shop
+	utils.RespondWithJSON(w, http.StatusOK, shop)
+}
+
+// handleAdminDeleteShop handles the admin delete shop request
+func (a *API) handleAdminDeleteShop(w http.ResponseWriter, r *http.Request) {
+	// Get the shop ID from the URL
+	shopID, err := uuid.Parse(mux.Vars(r)["shopId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid shop ID")
+		return
+	}
+
+	// Delete the shop
+	if err := a.shopService.DeleteShop(r.Context(), shopID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Shop not found")
+			return
+		}
+		if utils.RespondWithDatabaseError(w, err) {
+			return
+		}
+		log.Printf("Failed to delete shop %s: %v", shopID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete shop")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminAddShopPlant handles the admin add shop plant request: a catalog plant the shop sells
+func (a *API) handleAdminAddShopPlant(w http.ResponseWriter, r *http.Request) {
+	// Get the shop ID from the URL
+	shopID, err := uuid.Parse(mux.Vars(r)["shopId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid shop ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.AddShopPlantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Add the plant to the shop
+	shopPlant, err := a.shopService.AddShopPlant(r.Context(), shopID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShopPlantExists):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Shop or plant not found")
+		case utils.RespondWithDatabaseError(w, err):
+		default:
+			log.Printf("Failed to add plant %s to shop %s: %v", req.PlantID, shopID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to add shop plant")
+		}
+		return
+	}
+
+	// Respond with the shop plant
+	utils.RespondWithJSON(w, http.StatusCreated, shopPlant)
+}
+
+// handleAdminRemoveShopPlant handles the admin remove shop plant request
+func (a *API) handleAdminRemoveShopPlant(w http.ResponseWriter, r *http.Request) {
+	// Get the shop and plant IDs from the URL
+	vars := mux.Vars(r)
+	shopID, err := uuid.Parse(vars["shopId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid shop ID")
+		return
+	}
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Remove the plant from the shop
+	if err := a.shopService.RemoveShopPlant(r.Context(), shopID, plantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not sold by this shop")
+			return
+		}
+		log.Printf("Failed to remove plant %s from shop %s: %v", plantID, shopID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to remove shop plant")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminGetSpecialOffers handles the admin get special offers request, expired offers included
+func (a *API) handleAdminGetSpecialOffers(w http.ResponseWriter, r *http.Request) {
+	// Get the special offers
+	offers, err := a.shopService.ListSpecialOffers(r.Context())
+	if err != nil {
+		log.Printf("Failed to list special offers: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get special offers")
+		return
+	}
+
+	// Respond with the special offers
+	utils.RespondWithJSON(w, http.StatusOK, offers)
+}
+
+// handleAdminCreateSpecialOffer handles the admin create special offer request
+func (a *API) handleAdminCreateSpecialOffer(w http.ResponseWriter, r *http.Request) {
+	// Parse the request body
+	var req models.SpecialOfferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Create the special offer
+	offer, err := a.shopService.CreateSpecialOffer(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSpecialOffer) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if utils.RespondWithDatabaseError(w, err) {
+			return
+		}
+		log.Printf("Failed to create special offer %q: %v", req.Title, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create special offer")
+		return
+	}
+
+	// Respond with the created special offer
+	utils.RespondWithJSON(w, http.StatusCreated, offer)
+}
+
+// handleAdminUpdateSpecialOffer handles the admin update special offer request
+func (a *API) handleAdminUpdateSpecialOffer(w http.ResponseWriter, r *http.Request) {
+	// Get the offer ID from the URL
+	offerID, err := uuid.Parse(mux.Vars(r)["offerId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid offer ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.SpecialOfferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Update the special offer
+	offer, err := a.shopService.UpdateSpecialOffer(r.Context(), offerID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSpecialOffer):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Special offer not found")
+		case utils.RespondWithDatabaseError(w, err):
+		default:
+			log.Printf("Failed to update special offer %s: %v", offerID, err)
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update special offer")
+		}
+		return
+	}
+
+	// Respond with the updated special offer
+	utils.RespondWithJSON(w, http.StatusOK, offer)
+}
+
+// handleAdminDeleteSpecialOffer handles the admin delete special offer request
+func (a *API) handleAdminDeleteSpecialOffer(w http.ResponseWriter, r *http.Request) {
+	// Get the offer ID from the URL
+	offerID, err := uuid.Parse(mux.Vars(r)["offerId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid offer ID")
+		return
+	}
+
+	// Delete the special offer
+	if err := a.shopService.DeleteSpecialOffer(r.Context(), offerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Special offer not found")
+			return
+		}
+		if utils.RespondWithDatabaseError(w, err) {
+			return
+		}
+		log.Printf("Failed to delete special offer %s: %v", offerID, err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete special offer")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminImportShops handles the admin import shops request
+func (a *API) handleAdminImportShops(w http.ResponseWriter, r *http.Request) {
+	// Import the shops from the CSV body
+	r.Body = http.MaxBytesReader(w, r.Body, maxShopImportBytes)
+	result, err := a.shopService.ImportShops(r.Context(), r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "Import file too large")
+		case errors.Is(err, services.ErrGeocodingUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrInvalidShopImport):
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to import shops")
+		}
+		return
+	}
+
+	// Respond with the result of each row
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleCreateSupportTicket handles the contact support request
+func (a *API) handleCreateSupportTicket(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.CreateSupportTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	language := a.resolveClientLanguage(r)
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, language)
+		return
+	}
+
+	// Take the snapshot of the app the message was sent from
+	snapshot := models.SupportTicketContext{
+		AppVersion:   r.Header.Get(AppVersionHeader),
+		Platform:     req.Platform,
+		UserAgent:    r.UserAgent(),
+		Language:     language,
+		RecentErrors: req.RecentErrors,
+	}
+
+	// Submit the ticket
+	ticket, err := a.supportService.SubmitTicket(r.Context(), userID, req.Message, req.PlantID, snapshot)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+			return
+		}
+		log.Printf("Failed to submit support ticket: %v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to submit support ticket")
+		return
+	}
+
+	// Respond with the ticket
+	utils.RespondWithJSON(w, http.StatusCreated, ticket)
+}
+
+// handleAdminListSupportTickets handles the admin list support tickets request
+func (a *API) handleAdminListSupportTickets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// Parse the query parameters
+	filter := models.SupportTicketFilter{Status: models.SupportTicketStatus(query.Get("status"))}
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid page parameter")
+			return
+		}
+		filter.Page = page
+	}
+	if value := query.Get("pageSize"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid pageSize parameter")
+			return
+		}
+		filter.PageSize = pageSize
+	}
+
+	// Get the page of tickets
+	response, err := a.supportService.ListTickets(r.Context(), &filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSupportTicketStatus) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get support tickets")
+		return
+	}
+
+	// Respond with the tickets
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// handleAdminGetSupportTicket handles the admin get support ticket request
+func (a *API) handleAdminGetSupportTicket(w http.ResponseWriter, r *http.Request) {
+	// Get the ticket ID from the URL
+	vars := mux.Vars(r)
+	ticketID, err := uuid.Parse(vars["ticketId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	// Get the ticket
+	ticket, err := a.supportService.GetTicket(r.Context(), ticketID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Support ticket not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get support ticket")
+		return
+	}
+
+	// Respond with the ticket
+	utils.RespondWithJSON(w, http.StatusOK, ticket)
+}
+
+// handleAdminUpdateSupportTicket handles the admin support ticket triage request
+func (a *API) handleAdminUpdateSupportTicket(w http.ResponseWriter, r *http.Request) {
+	// Get the ticket ID from the URL
+	vars := mux.Vars(r)
+	ticketID, err := uuid.Parse(vars["ticketId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	// Parse the request body
+	var req models.UpdateSupportTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Update the ticket
+	ticket, err := a.supportService.UpdateTicket(r.Context(), ticketID, &req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Support ticket not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update support ticket")
+		return
+	}
+
+	// Respond with the updated ticket
+	utils.RespondWithJSON(w, http.StatusOK, ticket)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// handleTriagePlant handles the plant triage request
+func (a *API) handleTriagePlant(w http.ResponseWriter, r *http.Request) {
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the request body
+	var req models.TriageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate the request
+	if err := utils.Validate.Struct(req); err != nil {
+		utils.RespondWithValidationError(w, err, a.resolveClientLanguage(r))
+		return
+	}
+
+	// Triage the plant
+	result, err := a.triageService.Triage(r.Context(), userID, &req, a.resolveClientLanguage(r))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to triage plant")
+		return
+	}
+
+	// Respond with the triage result
+	utils.RespondWithJSON(w, http.StatusOK, result)
+}
+
+// handleGetPlantJournal handles the get plant journal request
+func (a *API) handleGetPlantJournal(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the journal
+	entries, err := a.triageService.GetJournal(r.Context(), userID, plantID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get journal")
+		return
+	}
+
+	// Respond with the journal entries
+	utils.RespondWithJSON(w, http.StatusOK, entries)
+}
@@ -2,10 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/anpanovv/planter/internal/middleware"
 	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/services"
 	"github.com/anpanovv/planter/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -41,6 +44,12 @@ func (a *API) handleGetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Add the chat usage; the profile is still useful without it
+	user.ChatUsage, err = a.recommendationService.GetChatUsage(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error getting chat usage of user %s: %v", userID, err)
+	}
+
 	// Respond with the user
 	utils.RespondWithJSON(w, http.StatusOK, user)
 }
@@ -81,6 +90,11 @@ func (a *API) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 	// Update the user
 	updatedUser, err := a.userService.UpdateUser(r.Context(), &user)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidReminderChannel) || errors.Is(err, services.ErrInvalidCity) ||
+			errors.Is(err, services.ErrInvalidTimezone) || errors.Is(err, services.ErrInvalidNotificationHour) {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update user")
 		return
 	}
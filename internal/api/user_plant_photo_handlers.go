@@ -0,0 +1,207 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/services"
+	"github.com/anpanovv/planter/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxUserPlantPhotoBytes limits the size of an uploaded photo of a plant in a collection
+const maxUserPlantPhotoBytes = 10 << 20
+
+// handleAddUserPlantPhoto handles the add user plant photo request
+func (a *API) handleAddUserPlantPhoto(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Parse the multipart form
+	r.Body = http.MaxBytesReader(w, r.Body, maxUserPlantPhotoBytes+1<<20)
+	if err := r.ParseMultipartForm(maxUserPlantPhotoBytes); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid form or photo too large")
+		return
+	}
+
+	// Read the photo
+	file, _, err := r.FormFile("photo")
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Photo is required")
+		return
+	}
+	defer file.Close()
+
+	image, err := io.ReadAll(io.LimitReader(file, maxUserPlantPhotoBytes+1))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Failed to read photo")
+		return
+	}
+	if len(image) > maxUserPlantPhotoBytes {
+		utils.RespondWithError(w, http.StatusRequestEntityTooLarge, "Photo too large")
+		return
+	}
+
+	// Store the photo
+	photo, err := a.plantService.AddUserPlantPhoto(r.Context(), userID, plantID, image)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPhotoUploadUnavailable):
+			utils.RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		case errors.Is(err, services.ErrUnsupportedPhoto):
+			utils.RespondWithError(w, http.StatusUnsupportedMediaType, err.Error())
+		case errors.Is(err, services.ErrTooManyPhotos):
+			utils.RespondWithError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			utils.RespondWithError(w, http.StatusNotFound, "Plant not found in collection")
+		default:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to add photo")
+		}
+		return
+	}
+
+	// Respond with the photo
+	utils.RespondWithJSON(w, http.StatusCreated, photo)
+}
+
+// handleGetUserPlantPhotos handles the get user plant photos request
+func (a *API) handleGetUserPlantPhotos(w http.ResponseWriter, r *http.Request) {
+	// Get the plant ID from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the photos
+	photos, err := a.plantService.GetUserPlantPhotos(r.Context(), userID, plantID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get photos")
+		return
+	}
+
+	// Respond with the photos
+	utils.RespondWithJSON(w, http.StatusOK, photos)
+}
+
+// handleDeleteUserPlantPhoto handles the delete user plant photo request
+func (a *API) handleDeleteUserPlantPhoto(w http.ResponseWriter, r *http.Request) {
+	// Get the plant and photo IDs from the URL
+	vars := mux.Vars(r)
+	plantID, err := uuid.Parse(vars["plantId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid plant ID")
+		return
+	}
+	photoID, err := uuid.Parse(vars["photoId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid photo ID")
+		return
+	}
+
+	// Get the authenticated user ID from the context
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Delete the photo
+	if err := a.plantService.DeleteUserPlantPhoto(r.Context(), userID, plantID, photoID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Photo not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete photo")
+		return
+	}
+
+	// Respond with success
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Photo deleted"})
+}
+
+// handleAdminGetQuarantinedPhotos handles the admin get quarantined photos request
+func (a *API) handleAdminGetQuarantinedPhotos(w http.ResponseWriter, r *http.Request) {
+	// Get the photos awaiting review
+	photos, err := a.plantService.GetQuarantinedPhotos(r.Context())
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to get quarantined photos")
+		return
+	}
+
+	// Respond with the photos
+	utils.RespondWithJSON(w, http.StatusOK, photos)
+}
+
+// handleAdminApprovePhoto handles the admin approve photo request
+func (a *API) handleAdminApprovePhoto(w http.ResponseWriter, r *http.Request) {
+	// Get the photo ID from the URL
+	vars := mux.Vars(r)
+	photoID, err := uuid.Parse(vars["photoId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid photo ID")
+		return
+	}
+
+	// Approve the photo
+	photo, err := a.plantService.ApprovePhoto(r.Context(), photoID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Quarantined photo not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to approve photo")
+		return
+	}
+
+	// Respond with the approved photo
+	utils.RespondWithJSON(w, http.StatusOK, photo)
+}
+
+// handleAdminRejectPhoto handles the admin reject photo request
+func (a *API) handleAdminRejectPhoto(w http.ResponseWriter, r *http.Request) {
+	// Get the photo ID from the URL
+	vars := mux.Vars(r)
+	photoID, err := uuid.Parse(vars["photoId"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid photo ID")
+		return
+	}
+
+	// Reject the photo, deleting it
+	if err := a.plantService.RejectPhoto(r.Context(), photoID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Quarantined photo not found")
+			return
+		}
+		utils.RespondWithError(w, http.StatusInternalServerError, "Failed to reject photo")
+		return
+	}
+
+	// Respond with success
+	w.WriteHeader(http.StatusNoContent)
+}
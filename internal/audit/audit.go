@@ -0,0 +1,94 @@
+// Package audit records who changed what: the admin actions and the profile edits of users go into the
+// audit log, which admins can query to find out when and by whom an entity was changed. Recording is
+// best effort; an entry that cannot be stored is logged and the change it describes still happens.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+)
+
+const (
+	// defaultPageSize is the number of entries returned per page unless asked otherwise
+	defaultPageSize = 50
+
+	// maxPageSize is the largest page of entries that can be requested
+	maxPageSize = 200
+)
+
+// Trail records changes into the audit log and reads them back. A nil trail records nothing, so
+// callers need not check whether auditing is set up.
+type Trail struct {
+	repo repository.AuditRepository
+}
+
+// NewTrail creates a new audit trail
+func NewTrail(repo repository.AuditRepository) *Trail {
+	return &Trail{
+		repo: repo,
+	}
+}
+
+// Record adds an entry to the audit log. The actor is the authenticated user of the context unless
+// the entry names one; entries without either are recorded as changes made by the system.
+func (t *Trail) Record(ctx context.Context, entry *models.AuditEntry) {
+	if t == nil {
+		return
+	}
+	if entry.ActorID == nil {
+		if userID, err := middleware.GetUserID(ctx); err == nil {
+			entry.ActorID = &userID
+		}
+	}
+	if entry.Changes == nil {
+		entry.Changes = models.AuditChanges{}
+	}
+
+	// The change already happened, so it is not undone when it cannot be recorded
+	if err := t.repo.Record(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("Failed to record audit entry %s of %s %s: %v", entry.Action, entry.EntityType, entry.EntityID, err)
+	}
+}
+
+// List gets a page of the audit log entries matching a filter, newest first
+func (t *Trail) List(ctx context.Context, filter *models.AuditLogFilter) (*models.AuditLogPage, error) {
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	entries, total, err := t.repo.List(ctx, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	return &models.AuditLogPage{Entries: entries, Total: total}, nil
+}
+
+// Diff records the change of a field in changes as {"from", "to"} when its old and new values differ.
+// Pointers are compared by the values they point to and slices element by element; a nil slice equals
+// an empty one.
+func Diff(changes models.AuditChanges, field string, from, to interface{}) {
+	if reflect.DeepEqual(from, to) || (isEmptySlice(from) && isEmptySlice(to)) {
+		return
+	}
+	changes[field] = map[string]interface{}{"from": from, "to": to}
+}
+
+// isEmptySlice reports whether a value is a slice without elements
+func isEmptySlice(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Slice && v.Len() == 0
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditRepository is a mock implementation of the AuditRepository interface
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Record(ctx context.Context, entry *models.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter *models.AuditLogFilter, limit int, offset int) ([]*models.AuditEntry, int, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	return args.Get(0).([]*models.AuditEntry), args.Int(1), args.Error(2)
+}
+
+// TestTrail_Record tests that entries are recorded as changed by the authenticated user
+func TestTrail_Record(t *testing.T) {
+	repo := new(MockAuditRepository)
+	trail := NewTrail(repo)
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, userID.String())
+
+	repo.On("Record", mock.Anything, mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.ActorID != nil && *entry.ActorID == userID && entry.Changes != nil
+	})).Return(nil).Once()
+	trail.Record(ctx, &models.AuditEntry{Action: models.AuditActionUpdateProfile, EntityType: "users", EntityID: userID.String()})
+	repo.AssertExpectations(t)
+
+	// A nil trail records nothing
+	var none *Trail
+	none.Record(ctx, &models.AuditEntry{Action: models.AuditActionUpdateProfile})
+}
+
+// TestTrail_List tests that pages of the audit log are limited
+func TestTrail_List(t *testing.T) {
+	repo := new(MockAuditRepository)
+	trail := NewTrail(repo)
+	ctx := context.Background()
+
+	filter := &models.AuditLogFilter{EntityType: "shops", Page: 3, PageSize: 1000}
+	repo.On("List", ctx, filter, maxPageSize, 2*maxPageSize).Return([]*models.AuditEntry{}, 0, nil)
+
+	page, err := trail.List(ctx, filter)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Entries)
+}
+
+// TestDiff tests that only changed fields are recorded
+func TestDiff(t *testing.T) {
+	city, sameCity, newCity := "Moscow", "Moscow", "Kazan"
+	changes := models.AuditChanges{}
+	Diff(changes, "name", "Anna", "Anna")
+	Diff(changes, "city", &city, &sameCity)
+	Diff(changes, "locations", []string(nil), []string{})
+	Diff(changes, "notificationsEnabled", true, false)
+	Diff(changes, "newCity", &city, &newCity)
+
+	assert.Equal(t, models.AuditChanges{
+		"notificationsEnabled": map[string]interface{}{"from": true, "to": false},
+		"newCity":              map[string]interface{}{"from": &city, "to": &newCity},
+	}, changes)
+}
+
+// TestTrail_Middleware tests that successful changes are recorded with their route and body, and that
+// reads and failed requests are not
+func TestTrail_Middleware(t *testing.T) {
+	repo := new(MockAuditRepository)
+	trail := NewTrail(repo)
+	adminID, shopID, createdID := uuid.New(), uuid.New(), uuid.New()
+
+	router := mux.NewRouter()
+	adminRouter := router.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), middleware.UserIDKey, adminID.String())))
+		})
+	})
+	adminRouter.Use(trail.Middleware)
+	adminRouter.HandleFunc("/shops", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"` + createdID.String() + `","name":"Флора"}`))
+	}).Methods(http.MethodPost)
+	adminRouter.HandleFunc("/shops/{shopId}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodDelete)
+	adminRouter.HandleFunc("/shops/{shopId}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	}).Methods(http.MethodPut)
+	adminRouter.HandleFunc("/shops/{shopId}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}).Methods(http.MethodGet)
+
+	repo.On("Record", mock.Anything, mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == "POST /admin/shops" && entry.EntityType == "shops" && entry.EntityID == createdID.String() &&
+			*entry.ActorID == adminID && assert.ObjectsAreEqual(map[string]interface{}{"name": "Флора"}, entry.Changes["body"])
+	})).Return(nil).Once()
+	repo.On("Record", mock.Anything, mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == "DELETE /admin/shops/{shopId}" && entry.EntityType == "shops" && entry.EntityID == shopID.String() &&
+			assert.ObjectsAreEqual(map[string]string{"shopId": shopID.String()}, entry.Changes["params"])
+	})).Return(nil).Once()
+
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/admin/shops", strings.NewReader(`{"name":"Флора"}`)),
+		httptest.NewRequest(http.MethodDelete, "/admin/shops/"+shopID.String(), nil),
+		httptest.NewRequest(http.MethodPut, "/admin/shops/"+shopID.String(), strings.NewReader(`{`)),
+		httptest.NewRequest(http.MethodGet, "/admin/shops/"+shopID.String(), nil),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+	repo.AssertExpectations(t)
+	repo.AssertNumberOfCalls(t, "Record", 2)
+}
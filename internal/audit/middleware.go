@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// maxBodyBytes is the number of bytes of a request or response body kept for an entry; longer request
+// bodies are recorded as truncated
+const maxBodyBytes = 16 << 10
+
+// Middleware records the successful changes made through the routes it wraps, e.g. the admin routes.
+// The action of an entry is the method and route of the request, e.g. "DELETE /admin/shops/{shopId}";
+// the entity is named by the first segment of the route after its prefix and identified by the first
+// route variable, or by the ID of the created entity for requests to a collection. The changes hold
+// the route variables and the JSON request body.
+func (t *Trail) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if t == nil || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Keep the start of the body and hand the handler the whole body as it was received
+		var body []byte
+		if r.Body != nil && r.Body != http.NoBody {
+			read, _ := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+			r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(read), r.Body), Closer: r.Body}
+			body = read
+		}
+
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		if rw.status < http.StatusOK || rw.status >= http.StatusMultipleChoices {
+			return
+		}
+
+		template := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if path, err := route.GetPathTemplate(); err == nil {
+				template = path
+			}
+		}
+		vars := mux.Vars(r)
+		entityType, entityID := entityOf(template, vars)
+		if entityID == "" {
+			entityID = createdID(rw.body.Bytes())
+		}
+
+		changes := models.AuditChanges{}
+		if len(vars) > 0 {
+			changes["params"] = vars
+		}
+		recordBody(changes, r.Header.Get("Content-Type"), body)
+
+		t.Record(r.Context(), &models.AuditEntry{
+			Action:     r.Method + " " + template,
+			EntityType: entityType,
+			EntityID:   entityID,
+			Changes:    changes,
+		})
+	})
+}
+
+// entityOf gets the type and ID of the entity a route changes: the first segment after the prefix,
+// e.g. "shops" of "/admin/shops/{shopId}/plants", and the value of the first route variable
+func entityOf(template string, vars map[string]string) (string, string) {
+	segments := strings.Split(strings.Trim(template, "/"), "/")
+	if len(segments) > 1 {
+		segments = segments[1:]
+	}
+
+	entityID := ""
+	for _, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			name, _, _ = strings.Cut(strings.TrimSuffix(name, "}"), ":")
+			entityID = vars[name]
+			break
+		}
+	}
+	return segments[0], entityID
+}
+
+// createdID gets the ID of the entity a request created from the JSON object it was answered with
+func createdID(response []byte) string {
+	var created struct {
+		ID interface{} `json:"id"`
+	}
+	if json.Unmarshal(response, &created) != nil || created.ID == nil {
+		return ""
+	}
+	if id, ok := created.ID.(string); ok {
+		return id
+	}
+	encoded, _ := json.Marshal(created.ID)
+	return string(encoded)
+}
+
+// recordBody adds a JSON request body to the changes; other bodies, such as uploaded images, are
+// recorded by their content type only
+func recordBody(changes models.AuditChanges, contentType string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	if len(body) > maxBodyBytes {
+		changes["bodyTruncated"] = true
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	var decoded interface{}
+	if (mediaType == "" || mediaType == "application/json") && json.Unmarshal(body, &decoded) == nil {
+		changes["body"] = decoded
+		return
+	}
+	changes["contentType"] = mediaType
+}
+
+// responseWriter captures the status and the start of the body of a response
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader captures the status code
+func (rw *responseWriter) WriteHeader(status int) {
+	rw.status = status
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+// Write keeps the start of the body
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if remaining := maxBodyBytes - rw.body.Len(); remaining > 0 {
+		rw.body.Write(p[:min(len(p), remaining)])
+	}
+	return rw.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController reaches its deadlines and flushing
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// readCloser reads from a reader and closes a closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,57 @@
+// Package cache caches encoded values in memory or in Redis. Entries expire after a TTL and a cache
+// can be invalidated as a whole, for data such as the plant catalog that changes rarely and all at once.
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/anpanovv/planter/internal/redis"
+)
+
+// Cache holds encoded values by key
+type Cache interface {
+	// Load returns the cached value of a key, or calls load and caches its result. A value loaded
+	// while the cache is invalidated is not cached, so it cannot outlive the invalidation.
+	// Errors of load are returned and not cached.
+	Load(ctx context.Context, key string, load func() ([]byte, error)) ([]byte, error)
+
+	// Invalidate drops every cached value
+	Invalidate(ctx context.Context)
+
+	// Stats returns the number of hits, misses and invalidations since the cache was created
+	Stats() Stats
+}
+
+// Stats holds the counters of a cache
+type Stats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64
+}
+
+// counters counts the hits, misses and invalidations of a cache
+type counters struct {
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// Stats returns the counters
+func (c *counters) Stats() Stats {
+	return Stats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+	}
+}
+
+// New creates a cache keeping values for ttl in Redis under keys starting with prefix, shared by all
+// instances, or in memory up to capacity values when client is nil
+func New(client *redis.Client, prefix string, ttl time.Duration, capacity int) Cache {
+	if client != nil {
+		return NewRedis(client, prefix, ttl)
+	}
+	return NewMemory(ttl, capacity)
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/redis"
+	"github.com/anpanovv/planter/internal/redis/redistest"
+	"github.com/stretchr/testify/assert"
+)
+
+// loader returns a load function counting its calls
+func loader(value string, calls *int) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		*calls++
+		return []byte(value), nil
+	}
+}
+
+// TestMemory_Load tests that values are cached until they expire or are evicted
+func TestMemory_Load(t *testing.T) {
+	cache := NewMemory(time.Minute, 2)
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	calls := 0
+	for i := 0; i < 2; i++ {
+		value, err := cache.Load(ctx, "monstera", loader("Monstera", &calls))
+		assert.NoError(t, err)
+		assert.Equal(t, "Monstera", string(value))
+	}
+	assert.Equal(t, 1, calls)
+
+	// Errors are not cached
+	_, err := cache.Load(ctx, "ficus", func() ([]byte, error) { return nil, errors.New("connection refused") })
+	assert.Error(t, err)
+	assert.Equal(t, 1, cache.Len())
+
+	// The least recently used value is evicted
+	_, _ = cache.Load(ctx, "ficus", loader("Ficus", &calls))
+	_, _ = cache.Load(ctx, "monstera", loader("Monstera", &calls))
+	_, _ = cache.Load(ctx, "aloe", loader("Aloe", &calls))
+	assert.Equal(t, 3, calls)
+	_, _ = cache.Load(ctx, "ficus", loader("Ficus", &calls))
+	assert.Equal(t, 4, calls)
+
+	// Expired values are loaded again
+	now = now.Add(2 * time.Minute)
+	_, _ = cache.Load(ctx, "ficus", loader("Ficus", &calls))
+	assert.Equal(t, 5, calls)
+
+	assert.Equal(t, Stats{Hits: 2, Misses: 6}, cache.Stats())
+}
+
+// TestMemory_Invalidate tests that invalidation drops the values, including one loaded meanwhile
+func TestMemory_Invalidate(t *testing.T) {
+	cache := NewMemory(time.Minute, 0)
+	ctx := context.Background()
+
+	calls := 0
+	_, _ = cache.Load(ctx, "monstera", loader("Monstera", &calls))
+	cache.Invalidate(ctx)
+	assert.Equal(t, 0, cache.Len())
+
+	// A value loaded while the cache is invalidated is not cached
+	value, err := cache.Load(ctx, "monstera", func() ([]byte, error) {
+		cache.Invalidate(ctx)
+		return []byte("stale"), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "stale", string(value))
+	assert.Equal(t, 0, cache.Len())
+	assert.Equal(t, uint64(2), cache.Stats().Invalidations)
+}
+
+// TestRedis_Load tests that values are shared through Redis and dropped by invalidation
+func TestRedis_Load(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := redis.New(redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	ctx := context.Background()
+
+	// Two instances share the cached values
+	first := NewRedis(client, "planter:test:", time.Minute)
+	second := NewRedis(client, "planter:test:", time.Minute)
+	calls := 0
+	_, err := first.Load(ctx, "monstera", loader("Monstera", &calls))
+	assert.NoError(t, err)
+	value, err := second.Load(ctx, "monstera", loader("Monstera", &calls))
+	assert.NoError(t, err)
+	assert.Equal(t, "Monstera", string(value))
+	assert.Equal(t, 1, calls)
+
+	// Invalidating on one instance drops the values of both
+	second.Invalidate(ctx)
+	_, _ = first.Load(ctx, "monstera", loader("Monstera", &calls))
+	assert.Equal(t, 2, calls)
+
+	// A value loaded while the cache is invalidated is not served afterwards
+	_, _ = first.Load(ctx, "ficus", func() ([]byte, error) {
+		second.Invalidate(ctx)
+		return []byte("stale"), nil
+	})
+	value, _ = first.Load(ctx, "ficus", loader("Ficus", &calls))
+	assert.Equal(t, "Ficus", string(value))
+
+	// Values expire with their TTL
+	server.Advance(2 * time.Minute)
+	_, _ = first.Load(ctx, "ficus", loader("Ficus", &calls))
+	assert.Equal(t, 4, calls)
+
+	assert.Equal(t, Stats{Hits: 0, Misses: 5}, first.Stats())
+	assert.Equal(t, Stats{Hits: 1, Invalidations: 2}, second.Stats())
+
+	// Redis errors count as misses
+	server.Close()
+	value, err = first.Load(ctx, "aloe", loader("Aloe", &calls))
+	assert.NoError(t, err)
+	assert.Equal(t, "Aloe", string(value))
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryCapacity is the number of values a memory cache holds when not configured
+const DefaultMemoryCapacity = 1000
+
+// memoryEntry is a value held by a memory cache
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// Memory keeps values in the memory of the instance. It holds at most capacity values, evicting the
+// least recently used one. Invalidating it only affects this instance.
+type Memory struct {
+	counters
+
+	mu         sync.Mutex
+	ttl        time.Duration
+	capacity   int
+	generation uint64     // incremented by every invalidation
+	order      *list.List // Front is the most recently used
+	entries    map[string]*list.Element
+	now        func() time.Time
+}
+
+// NewMemory creates a new memory cache keeping values for ttl
+func NewMemory(ttl time.Duration, capacity int) *Memory {
+	if capacity <= 0 {
+		capacity = DefaultMemoryCapacity
+	}
+	return &Memory{
+		ttl:      ttl,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Load returns the cached value of a key, or calls load and caches its result
+func (c *Memory) Load(ctx context.Context, key string, load func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		if c.now().Before(entry.expiresAt) {
+			c.order.MoveToFront(element)
+			c.mu.Unlock()
+			c.hits.Add(1)
+			return entry.value, nil
+		}
+		c.remove(element)
+	}
+	generation := c.generation
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation {
+		return value, nil
+	}
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: c.now().Add(c.ttl)})
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+	return value, nil
+}
+
+// Invalidate drops every cached value
+func (c *Memory) Invalidate(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+	c.invalidations.Add(1)
+}
+
+// Len returns the number of cached values, expired ones included
+func (c *Memory) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove drops a cached value
+func (c *Memory) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*memoryEntry).key)
+}
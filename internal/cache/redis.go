@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/redis"
+)
+
+// redisTimeout bounds the Redis round trips of a cache operation
+const redisTimeout = time.Second
+
+// Redis keeps values in Redis, shared by all instances of the API. Values are stored under the
+// current generation of the cache, and invalidating it moves every instance to the next generation;
+// the values of older generations expire with their TTL. Redis errors count as misses.
+type Redis struct {
+	counters
+
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedis creates a new Redis cache storing values for ttl under keys starting with prefix
+func NewRedis(client *redis.Client, prefix string, ttl time.Duration) *Redis {
+	return &Redis{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+// Load returns the cached value of a key, or calls load and caches its result
+func (c *Redis) Load(ctx context.Context, key string, load func() ([]byte, error)) ([]byte, error) {
+	redisCtx, cancel := context.WithTimeout(ctx, redisTimeout)
+	generation, err := c.client.Get(redisCtx, c.prefix+"generation")
+	if errors.Is(err, redis.ErrNil) {
+		generation, err = "0", nil
+	}
+	var value string
+	if err == nil {
+		value, err = c.client.Get(redisCtx, c.prefix+generation+":"+key)
+	}
+	cancel()
+	if err == nil {
+		c.hits.Add(1)
+		return []byte(value), nil
+	}
+	if !errors.Is(err, redis.ErrNil) {
+		log.Printf("Error getting cached %s%s: %v", c.prefix, key, err)
+	}
+	c.misses.Add(1)
+
+	loaded, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Values are stored under the generation read before loading them, which is dropped by an
+	// invalidation in between
+	if generation != "" {
+		redisCtx, cancel := context.WithTimeout(ctx, redisTimeout)
+		defer cancel()
+		if err := c.client.Set(redisCtx, c.prefix+generation+":"+key, string(loaded), c.ttl); err != nil {
+			log.Printf("Error caching %s%s: %v", c.prefix, key, err)
+		}
+	}
+	return loaded, nil
+}
+
+// Invalidate drops every cached value by moving to the next generation
+func (c *Redis) Invalidate(ctx context.Context) {
+	redisCtx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+
+	if _, err := c.client.Do(redisCtx, "INCR", c.prefix+"generation"); err != nil {
+		log.Printf("Error invalidating cache %s: %v", c.prefix, err)
+		return
+	}
+	c.invalidations.Add(1)
+}
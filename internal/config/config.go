@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,11 +15,35 @@ type Config struct {
 	Database DatabaseConfig
 	Auth     AuthConfig
 	YandexGPT YandexGPTConfig
+	LLMBudget LLMBudgetConfig
+	Recommendations RecommendationsConfig
+	Chat      ChatConfig
+	Redis     RedisConfig
+	PlantCache PlantCacheConfig
+	Vision    VisionConfig
+	Moderation ModerationConfig
+	Identification IdentificationConfig
+	Weather   WeatherConfig
+	Geocoder  GeocoderConfig
+	Enrichment EnrichmentConfig
+	PublicAPI PublicAPIConfig
+	Client    ClientConfig
+	Demo      DemoConfig
+	Welcome   WelcomeConfig
+	SMTP      SMTPConfig
+	Reminders RemindersConfig
+	Retention RetentionConfig
+	Storage   StorageConfig
+	Capture   CaptureConfig
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port string
+	Port            string
+	ReadTimeout     int // in seconds
+	WriteTimeout    int // in seconds
+	IdleTimeout     int // in seconds
+	ShutdownTimeout int // in seconds to drain in-flight requests and jobs
 }
 
 // DatabaseConfig holds database configuration
@@ -29,12 +54,15 @@ type DatabaseConfig struct {
 	Password string
 	Name     string
 	SSLMode  string
+
+	MigrateOnStart bool // apply pending migrations when the API starts
 }
 
 // AuthConfig holds authentication configuration
 type AuthConfig struct {
 	JWTSecret     string
-	TokenDuration int // in hours
+	TokenDuration int      // in hours
+	AdminEmails   []string // users granted the admin role on startup
 }
 
 // YandexGPTConfig holds Yandex GPT configuration
@@ -43,6 +71,151 @@ type YandexGPTConfig struct {
 	Model  string
 }
 
+// LLMBudgetConfig holds configuration of the Yandex GPT budget, user quotas and circuit breaker
+type LLMBudgetConfig struct {
+	MonthlyBudget    float64 // in rubles; 0 disables the budget
+	PricePer1KTokens float64 // in rubles
+	UserMonthlyQuota int64   // tokens per user per month; 0 disables the quota
+	BreakerThreshold int     // consecutive failures that open the circuit breaker
+	BreakerCooldown  int     // in seconds
+}
+
+// RecommendationsConfig holds configuration of the engine plant recommendations are scored with
+type RecommendationsConfig struct {
+	Engine            string  // auto, weighted, llm or hybrid; auto uses Yandex GPT when it has an API key
+	HybridLLMShare    float64 // share of the hybrid score Yandex GPT is worth
+	SunlightWeight    float64 // weights of the questionnaire criteria, only their ratios matter
+	CareLevelWeight   float64
+	PetFriendlyWeight float64
+	LocationWeight    float64
+	TagsWeight        float64 // share of the score preferred tags are worth when a questionnaire has some
+}
+
+// ChatConfig holds configuration of the in-memory cache of chat contexts and of the scrubbing of
+// chat messages sent to Yandex GPT
+type ChatConfig struct {
+	ContextCacheSize  int      // chat sessions whose context is kept in memory
+	ContextIdleHours  int      // in hours a context stays in memory unused
+	ScrubEnabled      bool     // scrub personal data and blocked words from chat messages
+	ScrubPatternsFile string   // file of further regular expressions to scrub, one per line
+	BlockedWords      []string // words scrubbed from chat messages
+}
+
+// RedisConfig holds configuration of the Redis server sharing rate limits, caches and locks
+// between instances
+type RedisConfig struct {
+	URL      string // redis://[:password@]host[:port][/db]; empty keeps the state in memory
+	PoolSize int    // maximum number of open connections
+}
+
+// PlantCacheConfig holds configuration of the cache of plant catalog reads, kept in Redis when it
+// is configured and in memory otherwise
+type PlantCacheConfig struct {
+	TTLSeconds int // in seconds a cached read is served; 0 disables the cache
+	Size       int // plant catalog reads kept in memory
+}
+
+// VisionConfig holds configuration of the Yandex Vision classifier used for photo diagnosis
+type VisionConfig struct {
+	APIKey   string // diagnosis is disabled when empty
+	FolderID string
+	Model    string // classification model trained on plant conditions
+}
+
+// ModerationConfig holds configuration of the moderation of photos users upload
+type ModerationConfig struct {
+	Provider   string // stub, yandex-vision or none; Yandex Vision uses the API key and folder of VisionConfig
+	PlantModel string // Yandex Vision classification model with a plant class; photos are not told apart from plants when empty
+}
+
+// IdentificationConfig holds configuration of the Pl@ntNet API new plants are identified from photos with
+type IdentificationConfig struct {
+	APIKey  string // onboarding from a photo alone is disabled when empty
+	Project string // flora species are looked up in, "all" for every one
+}
+
+// WeatherConfig holds configuration of the OpenWeather API watering reminders of outdoor plants follow
+type WeatherConfig struct {
+	APIKey      string  // reminders ignore the weather when empty
+	RainSkipMM  float64 // rainfall over the last two days that skips a reminder
+	HeatAdvance float64 // highest temperature in °C over the last two days that sends reminders a day early
+}
+
+// EnrichmentConfig holds configuration of the external sources missing plant fields are looked up in
+type EnrichmentConfig struct {
+	Sources   []string // gbif, wikidata; enrichment is disabled when empty
+	UserAgent string   // identifies the requests to the sources, which ask automated clients for a contact
+}
+
+// GeocoderConfig holds configuration of the Yandex Geocoder used to place imported shops on the map
+type GeocoderConfig struct {
+	APIKey string // shop import is disabled when empty
+}
+
+// PublicAPIConfig holds public API configuration
+type PublicAPIConfig struct {
+	RateLimit int // requests per minute per API key
+}
+
+// ClientConfig holds configuration delivered to mobile clients
+type ClientConfig struct {
+	MinAppVersion    string
+	LatestAppVersion string
+	FeatureFlags     map[string]bool
+}
+
+// DemoConfig holds configuration of the shared demo account
+type DemoConfig struct {
+	AccountEmail string // demo mode is disabled when empty
+}
+
+// WelcomeConfig holds configuration of how the accounts of new users are set up
+type WelcomeConfig struct {
+	SamplePlantID   string // catalog plant added to new collections; none when empty
+	CampaignEnabled bool   // send new users the welcome notifications over their first week
+}
+
+// SMTPConfig holds configuration of the SMTP server emails are sent through
+type SMTPConfig struct {
+	Host     string // emails are disabled when empty
+	Port     int
+	Username string // the server is used without authentication when empty
+	Password string
+	From     string
+}
+
+// RemindersConfig holds configuration of the care reminders
+type RemindersConfig struct {
+	EmailHour int  // UTC hour from which the daily watering reminder emails are sent
+	DryRun    bool // the care notifications job only logs what it would create and send
+
+	ActionTokenTTL int    // hours watering notification action tokens can be used
+	ActionURL      string // page marking a plant watered with {token} in it, linked from emails; empty leaves the links out
+}
+
+// RetentionConfig holds configuration of the anonymization of inactive accounts
+type RetentionConfig struct {
+	InactiveDays int // days without activity after which owners are warned; 0 disables anonymization
+	WarningDays  int // days between the warning email and the anonymization
+}
+
+// StorageConfig holds configuration of the storage images and other assets are served from
+type StorageConfig struct {
+	BaseURL        string   // CDN base URL asset keys are resolved under; empty serves keys as they are
+	LegacyBaseURLs []string // base URLs assets were previously served from, rewritten to keys
+	UploadDir      string   // directory uploaded assets are written to, served under BaseURL; empty disables uploads
+	ServeUploads   bool     // serve UploadDir under /assets/, for a CDN pulling assets from the API
+}
+
+// CaptureConfig holds configuration of the capture and replay of requests answered with a server error
+type CaptureConfig struct {
+	Enabled         bool   // capture the requests answered with a server error
+	MaxBodyBytes    int    // bytes of a request body kept
+	RetentionDays   int    // days captures are kept; 0 keeps them
+	ReplayTargetURL string // base URL of the staging instance captures are replayed against; empty disables replay
+	ReplayJWTSecret string // JWT secret of the replay target, used to act as the chosen user there
+}
+
 // Load loads configuration from environment variables
 func Load() *Config {
 	// Load .env file if it exists
@@ -53,7 +226,11 @@ func Load() *Config {
 
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
+			Port:            getEnv("PORT", "8080"),
+			ReadTimeout:     getEnvAsInt("SERVER_READ_TIMEOUT", 30),
+			WriteTimeout:    getEnvAsInt("SERVER_WRITE_TIMEOUT", 60),
+			IdleTimeout:     getEnvAsInt("SERVER_IDLE_TIMEOUT", 120),
+			ShutdownTimeout: getEnvAsInt("SERVER_SHUTDOWN_TIMEOUT", 30),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -62,15 +239,120 @@ func Load() *Config {
 			Password: getEnv("DB_PASSWORD", "postgres"),
 			Name:     getEnv("DB_NAME", "planter"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			MigrateOnStart: getEnvAsBool("DB_MIGRATE_ON_START", true),
 		},
 		Auth: AuthConfig{
 			JWTSecret:     getEnv("JWT_SECRET", "your-secret-key"),
 			TokenDuration: getEnvAsInt("TOKEN_DURATION", 24),
+			AdminEmails:   getEnvAsList("ADMIN_EMAILS", ""),
 		},
 		YandexGPT: YandexGPTConfig{
 			APIKey: getEnv("YANDEX_GPT_API_KEY", ""),
 			Model:  getEnv("YANDEX_GPT_MODEL", "yandexgpt"),
 		},
+		LLMBudget: LLMBudgetConfig{
+			MonthlyBudget:    getEnvAsFloat("LLM_MONTHLY_BUDGET", 0),
+			PricePer1KTokens: getEnvAsFloat("LLM_PRICE_PER_1K_TOKENS", 0.2),
+			UserMonthlyQuota: int64(getEnvAsInt("LLM_USER_MONTHLY_TOKEN_QUOTA", 0)),
+			BreakerThreshold: getEnvAsInt("LLM_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:  getEnvAsInt("LLM_BREAKER_COOLDOWN", 60),
+		},
+		Recommendations: RecommendationsConfig{
+			Engine:            getEnv("RECOMMENDATION_ENGINE", "auto"),
+			HybridLLMShare:    getEnvAsFloat("RECOMMENDATION_HYBRID_LLM_SHARE", 0.5),
+			SunlightWeight:    getEnvAsFloat("RECOMMENDATION_WEIGHT_SUNLIGHT", 0.4),
+			CareLevelWeight:   getEnvAsFloat("RECOMMENDATION_WEIGHT_CARE_LEVEL", 0.3),
+			PetFriendlyWeight: getEnvAsFloat("RECOMMENDATION_WEIGHT_PET_FRIENDLY", 0.1),
+			LocationWeight:    getEnvAsFloat("RECOMMENDATION_WEIGHT_LOCATION", 0.2),
+			TagsWeight:        getEnvAsFloat("RECOMMENDATION_WEIGHT_TAGS", 0.3),
+		},
+		Chat: ChatConfig{
+			ContextCacheSize:  getEnvAsInt("CHAT_CONTEXT_CACHE_SIZE", 1000),
+			ContextIdleHours:  getEnvAsInt("CHAT_CONTEXT_IDLE_HOURS", 24),
+			ScrubEnabled:      getEnvAsBool("CHAT_SCRUB_ENABLED", true),
+			ScrubPatternsFile: getEnv("CHAT_SCRUB_PATTERNS_FILE", ""),
+			BlockedWords:      getEnvAsList("CHAT_BLOCKED_WORDS", ""),
+		},
+		Redis: RedisConfig{
+			URL:      getEnv("REDIS_URL", ""),
+			PoolSize: getEnvAsInt("REDIS_POOL_SIZE", 10),
+		},
+		PlantCache: PlantCacheConfig{
+			TTLSeconds: getEnvAsInt("PLANT_CACHE_TTL", 300),
+			Size:       getEnvAsInt("PLANT_CACHE_SIZE", 1000),
+		},
+		Vision: VisionConfig{
+			APIKey:   getEnv("YANDEX_VISION_API_KEY", ""),
+			FolderID: getEnv("YANDEX_VISION_FOLDER_ID", ""),
+			Model:    getEnv("YANDEX_VISION_MODEL", ""),
+		},
+		Moderation: ModerationConfig{
+			Provider:   getEnv("MODERATION_PROVIDER", "stub"),
+			PlantModel: getEnv("MODERATION_PLANT_MODEL", ""),
+		},
+		Identification: IdentificationConfig{
+			APIKey:  getEnv("PLANTNET_API_KEY", ""),
+			Project: getEnv("PLANTNET_PROJECT", "all"),
+		},
+		Weather: WeatherConfig{
+			APIKey:      getEnv("OPENWEATHER_API_KEY", ""),
+			RainSkipMM:  getEnvAsFloat("WEATHER_RAIN_SKIP_MM", 5),
+			HeatAdvance: getEnvAsFloat("WEATHER_HEAT_ADVANCE_C", 30),
+		},
+		Enrichment: EnrichmentConfig{
+			Sources:   getEnvAsList("ENRICHMENT_SOURCES", "gbif,wikidata"),
+			UserAgent: getEnv("ENRICHMENT_USER_AGENT", "planter/1.0 (https://github.com/anpanovv/planter)"),
+		},
+		Geocoder: GeocoderConfig{
+			APIKey: getEnv("YANDEX_GEOCODER_API_KEY", ""),
+		},
+		PublicAPI: PublicAPIConfig{
+			RateLimit: getEnvAsInt("PUBLIC_API_RATE_LIMIT", 60),
+		},
+		Client: ClientConfig{
+			MinAppVersion:    getEnv("CLIENT_MIN_APP_VERSION", "1.0.0"),
+			LatestAppVersion: getEnv("CLIENT_LATEST_APP_VERSION", "1.0.0"),
+			FeatureFlags:     getEnvAsFlags("CLIENT_FEATURE_FLAGS", "chat=true,recommendations=true,shops=true"),
+		},
+		Demo: DemoConfig{
+			AccountEmail: getEnv("DEMO_ACCOUNT_EMAIL", ""),
+		},
+		Welcome: WelcomeConfig{
+			SamplePlantID:   getEnv("WELCOME_SAMPLE_PLANT_ID", ""),
+			CampaignEnabled: getEnvAsBool("WELCOME_CAMPAIGN_ENABLED", false),
+		},
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnvAsInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", "Planter <no-reply@planter.app>"),
+		},
+		Reminders: RemindersConfig{
+			EmailHour: getEnvAsInt("WATERING_EMAIL_HOUR", 8),
+			DryRun:    getEnvAsBool("CARE_NOTIFICATIONS_DRY_RUN", false),
+
+			ActionTokenTTL: getEnvAsInt("REMINDER_ACTION_TOKEN_TTL", 72),
+			ActionURL:      getEnv("REMINDER_ACTION_URL", ""),
+		},
+		Retention: RetentionConfig{
+			InactiveDays: getEnvAsInt("ACCOUNT_INACTIVE_DAYS", 730),
+			WarningDays:  getEnvAsInt("ACCOUNT_ANONYMIZATION_WARNING_DAYS", 30),
+		},
+		Storage: StorageConfig{
+			BaseURL:        getEnv("STORAGE_BASE_URL", ""),
+			LegacyBaseURLs: getEnvAsList("STORAGE_LEGACY_BASE_URLS", ""),
+			UploadDir:      getEnv("STORAGE_UPLOAD_DIR", ""),
+			ServeUploads:   getEnvAsBool("STORAGE_SERVE_UPLOADS", false),
+		},
+		Capture: CaptureConfig{
+			Enabled:         getEnvAsBool("REQUEST_CAPTURE_ENABLED", true),
+			MaxBodyBytes:    getEnvAsInt("REQUEST_CAPTURE_MAX_BODY_BYTES", 65536),
+			RetentionDays:   getEnvAsInt("REQUEST_CAPTURE_RETENTION_DAYS", 14),
+			ReplayTargetURL: getEnv("REPLAY_TARGET_URL", ""),
+			ReplayJWTSecret: getEnv("REPLAY_TARGET_JWT_SECRET", ""),
+		},
 	}
 }
 
@@ -97,4 +379,71 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 
 	return value
+}
+
+// getEnvAsFloat gets an environment variable as a floating-point number or returns a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		log.Printf("Warning: %s is not a valid number, using default value %g\n", key, defaultValue)
+		return defaultValue
+	}
+
+	return value
+}
+
+// getEnvAsBool gets an environment variable as a boolean or returns a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		log.Printf("Warning: %s is not a valid boolean, using default value %t\n", key, defaultValue)
+		return defaultValue
+	}
+
+	return value
+}
+
+// getEnvAsList gets an environment variable as a comma-separated list or returns the default
+func getEnvAsList(key string, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvAsFlags gets an environment variable as a comma-separated list of name=bool flags or returns the default
+func getEnvAsFlags(key string, defaultValue string) map[string]bool {
+	flags := make(map[string]bool)
+	for _, pair := range strings.Split(getEnv(key, defaultValue), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(pair, "=")
+		if !found {
+			flags[name] = true
+			continue
+		}
+
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Warning: invalid value for flag %s in %s, disabling it\n", name, key)
+		}
+		flags[name] = enabled
+	}
+	return flags
 }
\ No newline at end of file
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -12,6 +13,10 @@ import (
 // DB is a database connection pool
 type DB struct {
 	*sqlx.DB
+
+	renamePhases map[string]RenamePhase // phases of columns being renamed by table.column
+	queryMetrics *QueryMetrics          // nil when queries are not instrumented
+	repository   string                 // name of the repository of a view, empty for the database itself
 }
 
 // New creates a new database connection
@@ -36,7 +41,15 @@ func New() (*DB, error) {
 	db.SetMaxIdleConns(25)
 
 	log.Println("Connected to database")
-	return &DB{db}, nil
+	return &DB{
+		DB:           db,
+		renamePhases: ParseRenamePhases(getEnv("DB_COLUMN_RENAMES", "")),
+		queryMetrics: NewQueryMetrics(
+			getDurationEnv("DB_QUERY_TIMEOUT", 5*time.Second),
+			ParseQueryTimeouts(getEnv("DB_REPOSITORY_QUERY_TIMEOUTS", ""), DefaultRepositoryQueryTimeouts),
+			getDurationEnv("DB_SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+		),
+	}, nil
 }
 
 // getEnv gets an environment variable or returns a default value
@@ -46,4 +59,19 @@ func getEnv(key, defaultValue string) string {
 		return defaultValue
 	}
 	return value
-}
\ No newline at end of file
+}
+
+// getDurationEnv gets a duration from an environment variable or returns a default value when it
+// is unset or invalid
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		log.Printf("Warning: invalid duration %q for %s, using %s\n", value, key, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
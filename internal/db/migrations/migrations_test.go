@@ -0,0 +1,130 @@
+package migrations
+
+import (
+	"context"
+	"io/fs"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_OrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_journal.up.sql":   {Data: []byte("CREATE TABLE journal ();")},
+		"0002_add_journal.down.sql": {Data: []byte("DROP TABLE journal;")},
+		"0001_init.up.sql":          {Data: []byte("CREATE TABLE users ();")},
+		"0010_add_index.up.sql":     {Data: []byte("CREATE INDEX idx ON users(id);")},
+	}
+
+	migrations, err := load(fsys)
+
+	assert.NoError(t, err)
+	if !assert.Len(t, migrations, 3) {
+		return
+	}
+	assert.Equal(t, []int{1, 2, 10}, []int{migrations[0].Version, migrations[1].Version, migrations[2].Version})
+	assert.Equal(t, "add_journal", migrations[1].Name)
+	assert.Equal(t, "DROP TABLE journal;", migrations[1].Down)
+	assert.Empty(t, migrations[2].Down)
+}
+
+func TestLoad_RejectsInvalidFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"bad name":          {"init.sql": {Data: []byte("SELECT 1;")}},
+		"zero version":      {"0000_init.up.sql": {Data: []byte("SELECT 1;")}},
+		"duplicate version": {"0001_init.up.sql": {Data: []byte("SELECT 1;")}, "0001_other.up.sql": {Data: []byte("SELECT 1;")}},
+		"down without up":   {"0001_init.down.sql": {Data: []byte("SELECT 1;")}},
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := load(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	sub, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		t.Fatalf("Failed to open embedded migrations: %v", err)
+	}
+
+	migrations, err := load(sub)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version, "migration versions must be sequential")
+		assert.NotEmpty(t, migration.Down, "migration %04d_%s has no down migration", migration.Version, migration.Name)
+	}
+}
+
+func TestMigrator_Up_SkipsAppliedMigrations(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+
+	migrator := &Migrator{
+		db: sqlx.NewDb(mockDB, "sqlmock"),
+		migrations: []Migration{
+			{Version: 1, Name: "init", Up: "CREATE TABLE users ();"},
+			{Version: 2, Name: "add_journal", Up: "CREATE TABLE journal ();"},
+		},
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WithArgs(advisoryLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE journal ();")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(2, "add_journal").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WithArgs(advisoryLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := migrator.Up(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down_RollsBackLatest(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+
+	migrator := &Migrator{
+		db: sqlx.NewDb(mockDB, "sqlmock"),
+		migrations: []Migration{
+			{Version: 1, Name: "init", Up: "CREATE TABLE users ();", Down: "DROP TABLE users;"},
+			{Version: 2, Name: "add_journal", Up: "CREATE TABLE journal ();", Down: "DROP TABLE journal;"},
+		},
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WithArgs(advisoryLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()).AddRow(2, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DROP TABLE journal;")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WithArgs(advisoryLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rolledBack, err := migrator.Down(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rolledBack)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,32 @@
+-- Drop tables in reverse dependency order
+DROP TABLE IF EXISTS plant_journal_entries;
+DROP TABLE IF EXISTS plant_diagnosis_findings;
+DROP TABLE IF EXISTS plant_diagnoses;
+DROP TABLE IF EXISTS plant_community_difficulty;
+DROP TABLE IF EXISTS care_feedback;
+DROP TABLE IF EXISTS reconciliation_corrections;
+DROP TABLE IF EXISTS reconciliation_runs;
+DROP TABLE IF EXISTS analytics_events;
+DROP TABLE IF EXISTS personal_access_tokens;
+DROP TABLE IF EXISTS notification_templates;
+DROP TABLE IF EXISTS care_task_completions;
+DROP TABLE IF EXISTS plant_fun_facts;
+DROP TABLE IF EXISTS api_key_usage;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS plant_recommendations;
+DROP TABLE IF EXISTS plant_questionnaires;
+DROP TABLE IF EXISTS special_offers;
+DROP TABLE IF EXISTS shop_plants;
+DROP TABLE IF EXISTS shops;
+DROP TABLE IF EXISTS user_favorite_plants;
+DROP TABLE IF EXISTS user_plants;
+DROP TABLE IF EXISTS plants;
+DROP TABLE IF EXISTS care_instructions;
+DROP TABLE IF EXISTS user_locations;
+DROP TABLE IF EXISTS users;
+
+-- Drop enum types
+DROP TYPE IF EXISTS language;
+DROP TYPE IF EXISTS humidity_level;
+DROP TYPE IF EXISTS sunlight_level;
@@ -0,0 +1,344 @@
+-- Create extension for UUID generation
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- Create enum types
+DO $$
+BEGIN
+    -- Only create sunlight_level if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'sunlight_level') THEN
+        CREATE TYPE sunlight_level AS ENUM ('LOW', 'MEDIUM', 'HIGH');
+    END IF;
+
+    -- Only create humidity_level if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'humidity_level') THEN
+        CREATE TYPE humidity_level AS ENUM ('LOW', 'MEDIUM', 'HIGH');
+    END IF;
+
+    -- Only create language if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'language') THEN
+        CREATE TYPE language AS ENUM ('RUSSIAN', 'ENGLISH');
+    END IF;
+END
+$$;
+
+-- Create users table
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    profile_image_url TEXT,
+    language language NOT NULL DEFAULT 'RUSSIAN',
+    notifications_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create user_locations table
+CREATE TABLE IF NOT EXISTS user_locations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    location VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create care_instructions table
+CREATE TABLE IF NOT EXISTS care_instructions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    watering_frequency INTEGER NOT NULL, -- in days
+    sunlight sunlight_level NOT NULL,
+    min_temperature INTEGER NOT NULL,
+    max_temperature INTEGER NOT NULL,
+    humidity humidity_level NOT NULL,
+    soil_type VARCHAR(255) NOT NULL,
+    fertilizer_frequency INTEGER NOT NULL, -- in days
+    additional_notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add provenance fields to care_instructions
+ALTER TABLE care_instructions ADD COLUMN IF NOT EXISTS source_url TEXT;
+ALTER TABLE care_instructions ADD COLUMN IF NOT EXISTS source_author VARCHAR(255);
+ALTER TABLE care_instructions ADD COLUMN IF NOT EXISTS last_reviewed_at TIMESTAMP WITH TIME ZONE;
+
+-- Add the editorial difficulty (1-5 scale) to care_instructions
+ALTER TABLE care_instructions ADD COLUMN IF NOT EXISTS difficulty INTEGER CHECK (difficulty BETWEEN 1 AND 5);
+
+-- Create plants table
+CREATE TABLE IF NOT EXISTS plants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    scientific_name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    image_url TEXT NOT NULL,
+    care_instructions_id UUID NOT NULL REFERENCES care_instructions(id) ON DELETE CASCADE,
+    price DECIMAL(10, 2),
+    shop_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Botanical family, used to keep recommendations diverse
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS family VARCHAR(100);
+
+-- Create user_plants table (for owned plants)
+CREATE TABLE IF NOT EXISTS user_plants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    location VARCHAR(255),
+    last_watered TIMESTAMP WITH TIME ZONE,
+    next_watering TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, plant_id)
+);
+
+-- Create user_favorite_plants table
+CREATE TABLE IF NOT EXISTS user_favorite_plants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, plant_id)
+);
+
+-- Create shops table
+CREATE TABLE IF NOT EXISTS shops (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    address TEXT NOT NULL,
+    rating DECIMAL(2, 1) NOT NULL,
+    image_url TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create shop_plants table
+CREATE TABLE IF NOT EXISTS shop_plants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    shop_id UUID NOT NULL REFERENCES shops(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    price DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(shop_id, plant_id)
+);
+
+-- Condition, size and photos of the batch a shop currently has in stock
+ALTER TABLE shop_plants ADD COLUMN IF NOT EXISTS condition VARCHAR(20) CHECK (condition IN ('EXCELLENT', 'GOOD', 'FAIR'));
+ALTER TABLE shop_plants ADD COLUMN IF NOT EXISTS size_cm INTEGER CHECK (size_cm > 0);
+ALTER TABLE shop_plants ADD COLUMN IF NOT EXISTS pot_diameter_cm INTEGER CHECK (pot_diameter_cm > 0);
+ALTER TABLE shop_plants ADD COLUMN IF NOT EXISTS batch_photo_urls TEXT[] NOT NULL DEFAULT '{}';
+
+-- Create special_offers table
+CREATE TABLE IF NOT EXISTS special_offers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    image_url TEXT NOT NULL,
+    discount_percentage INTEGER NOT NULL,
+    valid_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create plant_questionnaire table
+CREATE TABLE IF NOT EXISTS plant_questionnaires (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    sunlight_preference sunlight_level NOT NULL,
+    pet_friendly BOOLEAN NOT NULL,
+    care_level INTEGER NOT NULL, -- 1-5 scale
+    preferred_location VARCHAR(255),
+    additional_preferences TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Number of recommended plants and the maximum number of them from one family
+ALTER TABLE plant_questionnaires ADD COLUMN IF NOT EXISTS result_count INTEGER NOT NULL DEFAULT 5 CHECK (result_count BETWEEN 1 AND 20);
+ALTER TABLE plant_questionnaires ADD COLUMN IF NOT EXISTS max_per_family INTEGER NOT NULL DEFAULT 2 CHECK (max_per_family BETWEEN 1 AND 20);
+
+-- Create plant_recommendations table
+CREATE TABLE IF NOT EXISTS plant_recommendations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    questionnaire_id UUID NOT NULL REFERENCES plant_questionnaires(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    score DECIMAL(3, 2) NOT NULL, -- 0-1 scale
+    reasoning TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(questionnaire_id, plant_id)
+);
+
+-- Create notifications table
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    message TEXT NOT NULL,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create api_keys table
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    key_hash VARCHAR(64) UNIQUE NOT NULL,
+    key_prefix VARCHAR(20) NOT NULL,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create api_key_usage table (daily request counters per key)
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    api_key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    request_count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, day)
+);
+
+-- Create plant_fun_facts table (LLM-generated facts, shown after admin approval)
+CREATE TABLE IF NOT EXISTS plant_fun_facts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    language VARCHAR(20) NOT NULL,
+    fact TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create care_task_completions table (completed weekly checklist tasks)
+CREATE TABLE IF NOT EXISTS care_task_completions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    task_type VARCHAR(20) NOT NULL,
+    due_date DATE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, plant_id, task_type, due_date)
+);
+
+-- Create notification_templates table (overrides of the built-in notification templates)
+CREATE TABLE IF NOT EXISTS notification_templates (
+    type VARCHAR(50) NOT NULL,
+    language VARCHAR(20) NOT NULL,
+    body TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (type, language)
+);
+
+-- Create personal_access_tokens table (scoped tokens for automation such as Home Assistant)
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    token_prefix VARCHAR(20) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create analytics_events table (client events, written in batches)
+CREATE TABLE IF NOT EXISTS analytics_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    session_id VARCHAR(100) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    screen VARCHAR(100),
+    plant_id UUID,
+    questionnaire_id UUID,
+    variant VARCHAR(50),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create reconciliation_runs table (reports of the nightly reconciliation job)
+CREATE TABLE IF NOT EXISTS reconciliation_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    total_corrections INTEGER NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+-- Create reconciliation_corrections table (corrections per check of a reconciliation run)
+CREATE TABLE IF NOT EXISTS reconciliation_corrections (
+    run_id UUID NOT NULL REFERENCES reconciliation_runs(id) ON DELETE CASCADE,
+    check_name VARCHAR(50) NOT NULL,
+    corrections INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (run_id, check_name)
+);
+
+-- Create care_feedback table (owners' answers on how hard a plant was to care for)
+CREATE TABLE IF NOT EXISTS care_feedback (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    rating VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, plant_id)
+);
+
+-- Create plant_community_difficulty table (difficulty recalibrated from care feedback)
+CREATE TABLE IF NOT EXISTS plant_community_difficulty (
+    plant_id UUID PRIMARY KEY REFERENCES plants(id) ON DELETE CASCADE,
+    score NUMERIC(3, 2) NOT NULL,
+    responses INTEGER NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create plant_diagnoses table
+CREATE TABLE IF NOT EXISTS plant_diagnoses (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create plant_diagnosis_findings table
+CREATE TABLE IF NOT EXISTS plant_diagnosis_findings (
+    diagnosis_id UUID NOT NULL REFERENCES plant_diagnoses(id) ON DELETE CASCADE,
+    condition VARCHAR(100) NOT NULL,
+    confidence NUMERIC(4, 3) NOT NULL CHECK (confidence BETWEEN 0 AND 1),
+    PRIMARY KEY (diagnosis_id, condition)
+);
+
+-- Create plant_journal_entries table
+CREATE TABLE IF NOT EXISTS plant_journal_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create index for faster notification queries
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_plants_name ON plants(name);
+CREATE INDEX IF NOT EXISTS idx_plants_scientific_name ON plants(scientific_name);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_user_plants_user_id ON user_plants(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_favorite_plants_user_id ON user_favorite_plants(user_id);
+CREATE INDEX IF NOT EXISTS idx_shop_plants_shop_id ON shop_plants(shop_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+CREATE INDEX IF NOT EXISTS idx_plant_fun_facts_plant_id ON plant_fun_facts(plant_id, language);
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_analytics_events_occurred_at ON analytics_events(occurred_at, type);
+CREATE INDEX IF NOT EXISTS idx_plant_diagnoses_user_plant ON plant_diagnoses(user_id, plant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_plant_journal_entries_user_plant ON plant_journal_entries(user_id, plant_id, created_at DESC);
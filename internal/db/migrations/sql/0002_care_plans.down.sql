@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS care_plan_reminders;
+DROP TABLE IF EXISTS care_plan_months;
+DROP TABLE IF EXISTS care_plans;
@@ -0,0 +1,34 @@
+-- Create care_plans table
+CREATE TABLE IF NOT EXISTS care_plans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    hemisphere VARCHAR(20) NOT NULL,
+    starts_on DATE NOT NULL,
+    summary TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, plant_id)
+);
+
+-- Create care_plan_months table
+CREATE TABLE IF NOT EXISTS care_plan_months (
+    plan_id UUID NOT NULL REFERENCES care_plans(id) ON DELETE CASCADE,
+    month DATE NOT NULL,
+    fertilize BOOLEAN NOT NULL,
+    repot BOOLEAN NOT NULL,
+    dormant BOOLEAN NOT NULL,
+    watering_frequency INTEGER NOT NULL,
+    notes TEXT NOT NULL,
+    PRIMARY KEY (plan_id, month)
+);
+
+-- Create care_plan_reminders table
+CREATE TABLE IF NOT EXISTS care_plan_reminders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    plan_id UUID NOT NULL REFERENCES care_plans(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    due_on DATE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_care_plan_reminders_due ON care_plan_reminders(due_on) WHERE sent_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_plants_name;
+
+ALTER TABLE plants DROP COLUMN IF EXISTS pet_friendly;
@@ -0,0 +1,4 @@
+-- Whether a plant is safe for cats and dogs; NULL while unknown
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS pet_friendly BOOLEAN;
+
+CREATE INDEX IF NOT EXISTS idx_plants_name ON plants(name, id);
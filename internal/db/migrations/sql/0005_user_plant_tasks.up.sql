@@ -0,0 +1,39 @@
+-- Create user_plant_tasks table (recurring care tasks of plants in users' collections besides watering)
+CREATE TABLE IF NOT EXISTS user_plant_tasks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    plant_id UUID NOT NULL,
+    task_type VARCHAR(20) NOT NULL,
+    frequency_days INTEGER NOT NULL CHECK (frequency_days > 0),
+    next_due DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, plant_id, task_type),
+    FOREIGN KEY (user_id, plant_id) REFERENCES user_plants(user_id, plant_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_plant_tasks_next_due ON user_plant_tasks(next_due);
+
+-- Schedule the default tasks of plants already in collections; new plants get them from defaultCareSchedules.
+-- Fertilizing keeps the interval counted from the day the plant was added.
+INSERT INTO user_plant_tasks (user_id, plant_id, task_type, frequency_days, next_due)
+SELECT up.user_id, up.plant_id, 'FERTILIZE', ci.fertilizer_frequency,
+    up.created_at::date + (CEIL(GREATEST(CURRENT_DATE - up.created_at::date, 1)::numeric / ci.fertilizer_frequency) * ci.fertilizer_frequency)::integer
+FROM user_plants up
+JOIN plants p ON up.plant_id = p.id
+JOIN care_instructions ci ON p.care_instructions_id = ci.id
+WHERE ci.fertilizer_frequency > 0
+ON CONFLICT (user_id, plant_id, task_type) DO NOTHING;
+
+INSERT INTO user_plant_tasks (user_id, plant_id, task_type, frequency_days, next_due)
+SELECT up.user_id, up.plant_id, 'MIST', f.days, CURRENT_DATE + f.days
+FROM user_plants up
+JOIN plants p ON up.plant_id = p.id
+JOIN care_instructions ci ON p.care_instructions_id = ci.id
+JOIN (VALUES ('HIGH', 2), ('MEDIUM', 7)) AS f(humidity, days) ON ci.humidity::text = f.humidity
+ON CONFLICT (user_id, plant_id, task_type) DO NOTHING;
+
+INSERT INTO user_plant_tasks (user_id, plant_id, task_type, frequency_days, next_due)
+SELECT up.user_id, up.plant_id, 'PRUNE', 90, CURRENT_DATE + 90
+FROM user_plants up
+ON CONFLICT (user_id, plant_id, task_type) DO NOTHING;
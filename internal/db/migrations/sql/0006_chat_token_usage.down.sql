@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_chat_messages_user_id;
+ALTER TABLE IF EXISTS chat_messages DROP COLUMN IF EXISTS completion_tokens;
+ALTER TABLE IF EXISTS chat_messages DROP COLUMN IF EXISTS prompt_tokens;
@@ -0,0 +1,11 @@
+-- Yandex GPT tokens spent on each assistant chat message. The chat tables are created by
+-- scripts/chat_tables.sql, so databases without them are left alone.
+ALTER TABLE IF EXISTS chat_messages ADD COLUMN IF NOT EXISTS prompt_tokens BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE IF EXISTS chat_messages ADD COLUMN IF NOT EXISTS completion_tokens BIGINT NOT NULL DEFAULT 0;
+
+DO $$
+BEGIN
+    IF to_regclass('chat_messages') IS NOT NULL THEN
+        CREATE INDEX IF NOT EXISTS idx_chat_messages_user_id ON chat_messages(user_id);
+    END IF;
+END $$;
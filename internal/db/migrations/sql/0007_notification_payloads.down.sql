@@ -0,0 +1,3 @@
+DELETE FROM notifications WHERE plant_id IS NULL;
+ALTER TABLE notifications ALTER COLUMN plant_id SET NOT NULL;
+ALTER TABLE notifications DROP COLUMN IF EXISTS payload;
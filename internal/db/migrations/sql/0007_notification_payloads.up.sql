@@ -0,0 +1,9 @@
+-- Type-specific notification data; the fields of each type are described by the notification type registry
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS payload JSONB NOT NULL DEFAULT '{}';
+
+-- Not every notification is about a plant, e.g. shop offers
+ALTER TABLE notifications ALTER COLUMN plant_id DROP NOT NULL;
+
+UPDATE notifications
+SET payload = jsonb_build_object('plantId', plant_id)
+WHERE payload = '{}' AND plant_id IS NOT NULL;
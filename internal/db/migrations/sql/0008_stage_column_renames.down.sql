@@ -0,0 +1,15 @@
+UPDATE care_instructions
+SET watering_frequency = COALESCE(watering_frequency_days, watering_frequency),
+    fertilizer_frequency = COALESCE(fertilizer_frequency_days, fertilizer_frequency);
+
+ALTER TABLE care_instructions ALTER COLUMN watering_frequency SET NOT NULL;
+ALTER TABLE care_instructions ALTER COLUMN fertilizer_frequency SET NOT NULL;
+
+UPDATE user_plants
+SET last_watered = COALESCE(last_watered_at, last_watered),
+    next_watering = COALESCE(next_watering_at, next_watering);
+
+ALTER TABLE care_instructions DROP COLUMN IF EXISTS fertilizer_frequency_days;
+ALTER TABLE care_instructions DROP COLUMN IF EXISTS watering_frequency_days;
+ALTER TABLE user_plants DROP COLUMN IF EXISTS next_watering_at;
+ALTER TABLE user_plants DROP COLUMN IF EXISTS last_watered_at;
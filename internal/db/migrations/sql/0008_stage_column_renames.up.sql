@@ -0,0 +1,16 @@
+-- New names of columns renamed with a staged rollout (see DB_COLUMN_RENAMES). The old columns
+-- are kept until every deployment reads and writes the new ones.
+ALTER TABLE user_plants ADD COLUMN IF NOT EXISTS last_watered_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE user_plants ADD COLUMN IF NOT EXISTS next_watering_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE care_instructions ADD COLUMN IF NOT EXISTS watering_frequency_days INTEGER;
+ALTER TABLE care_instructions ADD COLUMN IF NOT EXISTS fertilizer_frequency_days INTEGER;
+
+-- Instances that only write the new columns must be able to leave the old ones empty
+ALTER TABLE care_instructions ALTER COLUMN watering_frequency DROP NOT NULL;
+ALTER TABLE care_instructions ALTER COLUMN fertilizer_frequency DROP NOT NULL;
+
+UPDATE user_plants
+SET last_watered_at = last_watered, next_watering_at = next_watering;
+
+UPDATE care_instructions
+SET watering_frequency_days = watering_frequency, fertilizer_frequency_days = fertilizer_frequency;
@@ -0,0 +1,2 @@
+-- Roles granting access to restricted routes, e.g. admin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+ALTER TABLE shops DROP COLUMN IF EXISTS longitude;
+ALTER TABLE shops DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,3 @@
+-- Coordinates of shops on the map, filled by geocoding their address
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_plants_not_deleted;
+ALTER TABLE plants DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+-- Plants removed from the catalog are soft-deleted so collections and favorites keep them
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_plants_not_deleted ON plants(name) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+-- Unread notifications are counted for the bell badge on every app start
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications(user_id) WHERE is_read = false;
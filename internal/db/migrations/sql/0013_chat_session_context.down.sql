@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS chat_sessions DROP COLUMN IF EXISTS summarized_through;
+ALTER TABLE IF EXISTS chat_sessions DROP COLUMN IF EXISTS summary;
+ALTER TABLE IF EXISTS chat_sessions DROP COLUMN IF EXISTS system_prompt;
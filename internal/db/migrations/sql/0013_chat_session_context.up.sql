@@ -0,0 +1,6 @@
+-- Conversation context of each chat session: the system prompt and a rolling summary of the
+-- messages older than the ones sent to Yandex GPT verbatim. The chat tables are created by
+-- scripts/chat_tables.sql, so databases without them are left alone.
+ALTER TABLE IF EXISTS chat_sessions ADD COLUMN IF NOT EXISTS system_prompt TEXT NOT NULL DEFAULT '';
+ALTER TABLE IF EXISTS chat_sessions ADD COLUMN IF NOT EXISTS summary TEXT NOT NULL DEFAULT '';
+ALTER TABLE IF EXISTS chat_sessions ADD COLUMN IF NOT EXISTS summarized_through TIMESTAMP WITH TIME ZONE;
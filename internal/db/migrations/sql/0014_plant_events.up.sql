@@ -0,0 +1,28 @@
+-- Create plant_events table (lifecycle events of plants in users' collections, read with a cursor over seq)
+CREATE TABLE IF NOT EXISTS plant_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    seq BIGSERIAL NOT NULL UNIQUE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_plant_events_user_plant_seq ON plant_events(user_id, plant_id, seq);
+
+-- Backfill the events already recorded as completed care tasks and photo diagnoses, oldest first
+INSERT INTO plant_events (user_id, plant_id, type, payload, occurred_at)
+SELECT user_id, plant_id, type, payload, occurred_at
+FROM (
+    SELECT ctc.user_id, ctc.plant_id,
+        CASE ctc.task_type WHEN 'WATER' THEN 'WATERED' WHEN 'FERTILIZE' THEN 'FERTILIZED' ELSE 'REPOTTED' END AS type,
+        jsonb_build_object('dueDate', to_char(ctc.due_date, 'YYYY-MM-DD')) AS payload,
+        ctc.completed_at AS occurred_at
+    FROM care_task_completions ctc
+    WHERE ctc.task_type IN ('WATER', 'FERTILIZE', 'REPOT')
+    UNION ALL
+    SELECT pd.user_id, pd.plant_id, 'PHOTO_ADDED', jsonb_build_object('diagnosisId', pd.id), pd.created_at
+    FROM plant_diagnoses pd
+) backfill
+ORDER BY occurred_at;
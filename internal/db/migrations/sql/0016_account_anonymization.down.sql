@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS anonymization_accounts;
+DROP TABLE IF EXISTS anonymization_runs;
+DROP INDEX IF EXISTS idx_users_last_active_at;
+ALTER TABLE users DROP COLUMN IF EXISTS anonymized_at;
+ALTER TABLE users DROP COLUMN IF EXISTS inactivity_warned_at;
+ALTER TABLE users DROP COLUMN IF EXISTS last_active_at;
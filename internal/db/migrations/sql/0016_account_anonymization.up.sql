@@ -0,0 +1,31 @@
+-- Activity of users, the warning sent before a dormant account is anonymized and when it was
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_active_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS inactivity_warned_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE users SET last_active_at = updated_at WHERE last_active_at IS NULL;
+ALTER TABLE users ALTER COLUMN last_active_at SET DEFAULT NOW();
+ALTER TABLE users ALTER COLUMN last_active_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_last_active_at ON users(last_active_at) WHERE anonymized_at IS NULL;
+
+-- Create anonymization_runs table (reports of the anonymization job)
+CREATE TABLE IF NOT EXISTS anonymization_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    warned INTEGER NOT NULL DEFAULT 0,
+    anonymized INTEGER NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+-- Create anonymization_accounts table (accounts warned or anonymized by a run)
+CREATE TABLE IF NOT EXISTS anonymization_accounts (
+    run_id UUID NOT NULL REFERENCES anonymization_runs(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    PRIMARY KEY (run_id, user_id, action)
+);
+
+CREATE INDEX IF NOT EXISTS idx_anonymization_runs_started_at ON anonymization_runs(started_at DESC);
@@ -0,0 +1,16 @@
+-- Create captured_requests table (requests answered with a server error, kept to replay them on staging)
+CREATE TABLE IF NOT EXISTS captured_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    headers JSONB NOT NULL DEFAULT '{}',
+    body TEXT NOT NULL DEFAULT '',
+    body_status VARCHAR(20) NOT NULL DEFAULT 'COMPLETE',
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    status INTEGER NOT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    captured_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_captured_requests_captured_at ON captured_requests(captured_at DESC);
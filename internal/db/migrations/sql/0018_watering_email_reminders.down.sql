@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS watering_email_sent_on;
+ALTER TABLE users DROP COLUMN IF EXISTS watering_reminder_channel;
@@ -0,0 +1,5 @@
+-- Let users get watering reminders as a daily email instead of in-app notifications
+ALTER TABLE users ADD COLUMN IF NOT EXISTS watering_reminder_channel VARCHAR(10) NOT NULL DEFAULT 'PUSH';
+
+-- Day the last watering reminder email was sent on, so one email is sent a day at most
+ALTER TABLE users ADD COLUMN IF NOT EXISTS watering_email_sent_on DATE;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_chat_sessions_escalation;
+ALTER TABLE IF EXISTS chat_messages DROP COLUMN IF EXISTS expert_id;
+ALTER TABLE IF EXISTS chat_sessions DROP COLUMN IF EXISTS expert_id;
+ALTER TABLE IF EXISTS chat_sessions DROP COLUMN IF EXISTS escalated_at;
+ALTER TABLE IF EXISTS chat_sessions DROP COLUMN IF EXISTS escalation_reason;
+ALTER TABLE IF EXISTS chat_sessions DROP COLUMN IF EXISTS escalation_status;
@@ -0,0 +1,15 @@
+-- Requests for a human expert in a chat session and the expert replies. The chat tables are
+-- created by scripts/chat_tables.sql, so databases without them are left alone.
+ALTER TABLE IF EXISTS chat_sessions ADD COLUMN IF NOT EXISTS escalation_status VARCHAR(20);
+ALTER TABLE IF EXISTS chat_sessions ADD COLUMN IF NOT EXISTS escalation_reason TEXT;
+ALTER TABLE IF EXISTS chat_sessions ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE IF EXISTS chat_sessions ADD COLUMN IF NOT EXISTS expert_id UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE IF EXISTS chat_messages ADD COLUMN IF NOT EXISTS expert_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+DO $$
+BEGIN
+    IF to_regclass('chat_sessions') IS NOT NULL THEN
+        CREATE INDEX IF NOT EXISTS idx_chat_sessions_escalation ON chat_sessions(escalation_status, escalated_at)
+            WHERE escalation_status IS NOT NULL;
+    END IF;
+END $$;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS plant_availability_subscriptions;
+
+DROP INDEX IF EXISTS idx_shops_city;
+ALTER TABLE shops DROP COLUMN IF EXISTS city;
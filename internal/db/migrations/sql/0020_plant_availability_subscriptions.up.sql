@@ -0,0 +1,21 @@
+-- City of a shop, filled from the geocoder when shops are imported; availability subscriptions match on it
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS city VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_shops_city ON shops(LOWER(city));
+
+-- Create plant_availability_subscriptions table (users waiting for a plant to be sold in their city)
+CREATE TABLE IF NOT EXISTS plant_availability_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    city VARCHAR(255) NOT NULL,
+    notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, plant_id)
+);
+
+-- Subscriptions still waiting, looked up when a plant is stocked in a city
+CREATE INDEX IF NOT EXISTS idx_plant_availability_subscriptions_waiting
+    ON plant_availability_subscriptions(plant_id, LOWER(city))
+    WHERE notified_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE plant_recommendations DROP COLUMN IF EXISTS explanation;
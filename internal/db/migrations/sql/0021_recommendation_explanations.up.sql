@@ -0,0 +1,2 @@
+-- Keep why each plant was recommended so the explanation can be shown with saved recommendations
+ALTER TABLE plant_recommendations ADD COLUMN IF NOT EXISTS explanation JSONB;
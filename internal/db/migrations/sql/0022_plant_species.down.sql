@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_plants_species_id;
+ALTER TABLE plants DROP COLUMN IF EXISTS care_overrides;
+ALTER TABLE plants DROP COLUMN IF EXISTS species_id;
+
+DROP TABLE IF EXISTS plant_species;
@@ -0,0 +1,16 @@
+-- Species of the catalog; their care instructions are the defaults of the plants that are cultivars of them
+CREATE TABLE IF NOT EXISTS plant_species (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    scientific_name VARCHAR(255) NOT NULL UNIQUE,
+    family VARCHAR(255),
+    care_instructions_id UUID NOT NULL REFERENCES care_instructions(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Cultivars keep the fields they override; their own care instructions hold the species defaults with
+-- the overrides applied and are rewritten whenever the species changes
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS species_id UUID REFERENCES plant_species(id);
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS care_overrides JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_plants_species_id ON plants(species_id) WHERE species_id IS NOT NULL;
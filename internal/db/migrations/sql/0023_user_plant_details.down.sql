@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_plant_photos;
+
+ALTER TABLE user_plants DROP COLUMN IF EXISTS notes;
+ALTER TABLE user_plants DROP COLUMN IF EXISTS nickname;
@@ -0,0 +1,16 @@
+-- Details users keep about the plants in their collection
+ALTER TABLE user_plants ADD COLUMN IF NOT EXISTS nickname VARCHAR(50);
+ALTER TABLE user_plants ADD COLUMN IF NOT EXISTS notes TEXT;
+
+-- Photos users took of the plants in their collection; the images are in object storage under image_key
+CREATE TABLE IF NOT EXISTS user_plant_photos (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    plant_id UUID NOT NULL,
+    image_key TEXT NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id, plant_id) REFERENCES user_plants(user_id, plant_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_plant_photos_user_plant ON user_plant_photos(user_id, plant_id, created_at DESC);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS account_merges;
+
+ALTER TABLE users DROP COLUMN IF EXISTS merged_at;
+ALTER TABLE users DROP COLUMN IF EXISTS merged_into;
@@ -0,0 +1,16 @@
+-- Accounts merged into another one keep their row so the merge can be traced, without credentials
+ALTER TABLE users ADD COLUMN IF NOT EXISTS merged_into UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS merged_at TIMESTAMP WITH TIME ZONE;
+
+-- Merges of a source account into a target account, with the rows moved and dropped per table
+CREATE TABLE IF NOT EXISTS account_merges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    target_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    merged_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    method VARCHAR(20) NOT NULL,
+    summary JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_merges_created_at ON account_merges(created_at DESC);
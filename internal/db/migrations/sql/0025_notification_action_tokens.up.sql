@@ -0,0 +1,10 @@
+-- Notification action tokens that were used, kept until they expire so each can be used once
+CREATE TABLE IF NOT EXISTS notification_action_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_action_tokens_expires_at ON notification_action_tokens(expires_at);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS plant_enrichment_proposals;
+
+ALTER TABLE plants DROP COLUMN IF EXISTS enrichment_checked_at;
+ALTER TABLE plants DROP COLUMN IF EXISTS image_attribution;
+ALTER TABLE plants DROP COLUMN IF EXISTS image_license;
+ALTER TABLE plants DROP COLUMN IF EXISTS synonyms;
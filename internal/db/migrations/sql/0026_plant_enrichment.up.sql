@@ -0,0 +1,22 @@
+-- Fields filled from external sources once an admin approves them
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS synonyms TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS image_license TEXT;
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS image_attribution TEXT;
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS enrichment_checked_at TIMESTAMP WITH TIME ZONE;
+
+-- Changes of plant fields proposed by enrichment sources, awaiting admin review
+CREATE TABLE IF NOT EXISTS plant_enrichment_proposals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    field VARCHAR(20) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    value JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- A source proposes at most one pending change per plant field
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plant_enrichment_proposals_pending
+    ON plant_enrichment_proposals(plant_id, field, source) WHERE status = 'PENDING';
@@ -0,0 +1,4 @@
+ALTER TABLE user_plants DROP COLUMN IF EXISTS low_effort_mode;
+ALTER TABLE users DROP COLUMN IF EXISTS low_effort_mode;
+ALTER TABLE care_instructions DROP COLUMN IF EXISTS watering_frequency_max;
+ALTER TABLE care_instructions DROP COLUMN IF EXISTS watering_frequency_min;
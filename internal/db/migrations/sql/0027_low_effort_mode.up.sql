@@ -0,0 +1,7 @@
+-- Range of days between waterings a plant tolerates, so low effort mode can stretch watering
+ALTER TABLE care_instructions ADD COLUMN IF NOT EXISTS watering_frequency_min INTEGER CHECK (watering_frequency_min > 0);
+ALTER TABLE care_instructions ADD COLUMN IF NOT EXISTS watering_frequency_max INTEGER CHECK (watering_frequency_max > 0);
+
+-- Low effort mode of a user, and of a plant in their collection overriding it when set
+ALTER TABLE users ADD COLUMN IF NOT EXISTS low_effort_mode BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE user_plants ADD COLUMN IF NOT EXISTS low_effort_mode BOOLEAN;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS user_outdoor_locations;
@@ -0,0 +1,10 @@
+-- Locations of users that are outdoors, with the coordinates their weather is looked up at
+CREATE TABLE IF NOT EXISTS user_outdoor_locations (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    location VARCHAR(100) NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, location)
+);
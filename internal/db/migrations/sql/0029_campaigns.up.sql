@@ -0,0 +1,12 @@
+-- Users receiving the notifications of a campaign, such as the welcome sequence of new users
+CREATE TABLE IF NOT EXISTS user_campaigns (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    campaign VARCHAR(50) NOT NULL,
+    plant_id UUID REFERENCES plants(id) ON DELETE SET NULL,
+    next_step INTEGER NOT NULL DEFAULT 0,
+    enrolled_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (user_id, campaign)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_campaigns_active ON user_campaigns(enrolled_at) WHERE completed_at IS NULL;
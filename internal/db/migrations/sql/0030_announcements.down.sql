@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notifications_announcement;
+DROP TABLE IF EXISTS announcements;
+DROP INDEX IF EXISTS idx_users_city;
+ALTER TABLE users DROP COLUMN IF EXISTS city;
@@ -0,0 +1,26 @@
+-- City of a user, which announcements can be targeted at
+ALTER TABLE users ADD COLUMN IF NOT EXISTS city VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_users_city ON users(LOWER(city)) WHERE city IS NOT NULL;
+
+-- Announcements admins broadcast to a segment of the users as notifications. The job sending them
+-- works through the recipients in batches by user ID; last_user_id is the last user of the batches
+-- claimed so far.
+CREATE TABLE IF NOT EXISTS announcements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    segment VARCHAR(20) NOT NULL,
+    city VARCHAR(255),
+    messages JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    recipients INTEGER NOT NULL DEFAULT 0,
+    sent INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    last_user_id UUID,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_in_progress ON announcements(created_at) WHERE status IN ('PENDING', 'SENDING');
+CREATE INDEX IF NOT EXISTS idx_notifications_announcement ON notifications((payload->>'announcementId')) WHERE type = 'ANNOUNCEMENT';
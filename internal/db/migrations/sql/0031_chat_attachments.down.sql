@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS chat_messages DROP COLUMN IF EXISTS image_description;
+ALTER TABLE IF EXISTS chat_messages DROP COLUMN IF EXISTS image_url;
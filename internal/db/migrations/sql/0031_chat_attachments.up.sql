@@ -0,0 +1,4 @@
+-- Photos attached to chat messages and what the vision provider saw on them. The chat tables are
+-- created by scripts/chat_tables.sql, so databases without them are left alone.
+ALTER TABLE IF EXISTS chat_messages ADD COLUMN IF NOT EXISTS image_url TEXT;
+ALTER TABLE IF EXISTS chat_messages ADD COLUMN IF NOT EXISTS image_description TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_badges;
+DROP TABLE IF EXISTS user_follows;
+DROP TABLE IF EXISTS quiz_results;
+DROP TABLE IF EXISTS quiz_days;
@@ -0,0 +1,41 @@
+-- Daily care knowledge quizzes. The questions of a day are generated from the catalog once and
+-- stored, so every user gets the same quiz even when the catalog changes during the day.
+CREATE TABLE IF NOT EXISTS quiz_days (
+    quiz_date DATE PRIMARY KEY,
+    questions JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Scored answers of users to the daily quizzes; a quiz is answered once
+CREATE TABLE IF NOT EXISTS quiz_results (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quiz_date DATE NOT NULL,
+    correct INTEGER NOT NULL,
+    total INTEGER NOT NULL,
+    points INTEGER NOT NULL,
+    streak INTEGER NOT NULL,
+    answers JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, quiz_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_results_date ON quiz_results(quiz_date);
+
+-- Users a user follows, e.g. to compare quiz scores with
+CREATE TABLE IF NOT EXISTS user_follows (
+    follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_follows_followee ON user_follows(followee_id);
+
+-- Badges users earned; each badge is earned once
+CREATE TABLE IF NOT EXISTS user_badges (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    badge VARCHAR(50) NOT NULL,
+    awarded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, badge)
+);
@@ -0,0 +1,8 @@
+-- Anonymized ownership statistics of each catalog plant, aggregated every night
+CREATE TABLE IF NOT EXISTS plant_stats (
+    plant_id UUID PRIMARY KEY REFERENCES plants(id) ON DELETE CASCADE,
+    owners INTEGER NOT NULL DEFAULT 0,
+    watering_interval_days NUMERIC(6, 1),
+    survival_rate NUMERIC(4, 3),
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS changelog_seen;
+DROP TABLE IF EXISTS changelog_entries;
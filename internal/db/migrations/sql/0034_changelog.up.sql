@@ -0,0 +1,22 @@
+-- Release notes admins publish for the what's-new sheet of the app; entries published in the future
+-- are shown from then on
+CREATE TABLE IF NOT EXISTS changelog_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    version VARCHAR(20) NOT NULL,
+    titles JSONB NOT NULL,
+    bodies JSONB NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_changelog_entries_published_at ON changelog_entries(published_at DESC);
+
+-- Changelog entries each user has seen, so the app shows them once
+CREATE TABLE IF NOT EXISTS changelog_seen (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    entry_id UUID NOT NULL REFERENCES changelog_entries(id) ON DELETE CASCADE,
+    seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, entry_id)
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_user_plant_photos_quarantined;
+
+ALTER TABLE user_plant_photos DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE user_plant_photos DROP COLUMN IF EXISTS moderation_labels;
+ALTER TABLE user_plant_photos DROP COLUMN IF EXISTS moderation_status;
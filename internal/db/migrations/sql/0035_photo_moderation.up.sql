@@ -0,0 +1,8 @@
+-- Moderation of the photos users upload of the plants in their collection. Photos with unsafe content
+-- are quarantined until an admin approves them; labels name what the moderation provider found,
+-- not_plant marking photos that obviously show no plant
+ALTER TABLE user_plant_photos ADD COLUMN IF NOT EXISTS moderation_status VARCHAR(20) NOT NULL DEFAULT 'APPROVED';
+ALTER TABLE user_plant_photos ADD COLUMN IF NOT EXISTS moderation_labels TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE user_plant_photos ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_user_plant_photos_quarantined ON user_plant_photos(created_at) WHERE moderation_status = 'QUARANTINED';
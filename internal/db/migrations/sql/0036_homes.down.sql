@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS current_home_id;
+DROP INDEX IF EXISTS idx_user_plants_home_id;
+ALTER TABLE user_plants DROP COLUMN IF EXISTS home_id;
+DROP TABLE IF EXISTS homes;
@@ -0,0 +1,26 @@
+-- Homes group the rooms of a user who keeps plants in several places, e.g. a flat and a dacha. The
+-- weather of the outdoor rooms of a home is looked up at its coordinates, and the watering reminders
+-- of its plants wait for daytime in its timezone.
+CREATE TABLE IF NOT EXISTS homes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    outdoor_rooms TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK ((latitude IS NULL) = (longitude IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_homes_user_id ON homes(user_id);
+
+-- The home a plant of a collection is kept in; plants of deleted homes keep their rooms
+ALTER TABLE user_plants ADD COLUMN IF NOT EXISTS home_id UUID REFERENCES homes(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_plants_home_id ON user_plants(home_id);
+
+-- The home the user is staying at; reminders of the plants in their other homes wait until they
+-- come back. NULL reminds of every plant.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS current_home_id UUID REFERENCES homes(id) ON DELETE SET NULL;
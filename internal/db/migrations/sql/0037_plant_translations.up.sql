@@ -0,0 +1,12 @@
+-- Translations of the catalog, which is written in Russian. A plant is shown in the language of the
+-- client with the fields of its translation into it; fields left NULL are shown as in the catalog.
+CREATE TABLE IF NOT EXISTS plant_translations (
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    language VARCHAR(20) NOT NULL,
+    name VARCHAR(255),
+    description TEXT,
+    additional_notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (plant_id, language)
+);
@@ -0,0 +1,15 @@
+-- The unread state of the notifications of a user, shown on the bell every time the app opens. It is
+-- updated in the transactions that create, read and delete notifications, so reading it never counts
+-- the notifications table.
+CREATE TABLE IF NOT EXISTS notification_bells (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    unread_count INTEGER NOT NULL DEFAULT 0 CHECK (unread_count >= 0),
+    latest_notification_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO notification_bells (user_id, unread_count, latest_notification_at)
+SELECT user_id, COUNT(*) FILTER (WHERE NOT is_read), MAX(created_at)
+FROM notifications
+GROUP BY user_id
+ON CONFLICT (user_id) DO NOTHING;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_plants_unpublished;
+ALTER TABLE plants DROP COLUMN IF EXISTS status_changed_at;
+ALTER TABLE plants DROP COLUMN IF EXISTS status;
@@ -0,0 +1,6 @@
+-- Plants go through review before users see them: editors write drafts and submit them, reviewers
+-- publish them. Plants already in the catalog are published.
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'PUBLISHED'
+    CHECK (status IN ('DRAFT', 'IN_REVIEW', 'PUBLISHED'));
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+CREATE INDEX IF NOT EXISTS idx_plants_unpublished ON plants(status, status_changed_at) WHERE status <> 'PUBLISHED';
@@ -0,0 +1,5 @@
+ALTER TABLE plant_questionnaires DROP COLUMN IF EXISTS preferred_tags;
+DROP TABLE IF EXISTS plant_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS plant_categories;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,32 @@
+-- Categories of the catalog, managed by admins, and free-form tags, created when they are first
+-- given to a plant. Plants have any number of both.
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS plant_categories (
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (plant_id, category_id)
+);
+CREATE INDEX IF NOT EXISTS idx_plant_categories_category_id ON plant_categories(category_id);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS plant_tags (
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (plant_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_plant_tags_tag_id ON plant_tags(tag_id);
+
+-- Tags questionnaires ask recommended plants to have
+ALTER TABLE plant_questionnaires ADD COLUMN IF NOT EXISTS preferred_tags TEXT[] NOT NULL DEFAULT '{}';
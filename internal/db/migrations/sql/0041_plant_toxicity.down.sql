@@ -0,0 +1,3 @@
+ALTER TABLE plant_questionnaires DROP COLUMN IF EXISTS has_children;
+ALTER TABLE plants DROP COLUMN IF EXISTS toxicity_children;
+ALTER TABLE plants DROP COLUMN IF EXISTS toxicity_pets;
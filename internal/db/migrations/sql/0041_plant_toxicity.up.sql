@@ -0,0 +1,10 @@
+-- How harmful plants are to pets and to children when eaten; unknown while NULL. Plants known to be
+-- safe for pets are not toxic to them.
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS toxicity_pets VARCHAR(10)
+    CHECK (toxicity_pets IN ('NONE', 'MILD', 'MODERATE', 'SEVERE'));
+ALTER TABLE plants ADD COLUMN IF NOT EXISTS toxicity_children VARCHAR(10)
+    CHECK (toxicity_children IN ('NONE', 'MILD', 'MODERATE', 'SEVERE'));
+UPDATE plants SET toxicity_pets = 'NONE' WHERE pet_friendly AND toxicity_pets IS NULL;
+
+-- Questionnaires of users with children leave out plants toxic to them
+ALTER TABLE plant_questionnaires ADD COLUMN IF NOT EXISTS has_children BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS seasonal_guide_overrides;
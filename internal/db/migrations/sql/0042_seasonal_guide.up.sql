@@ -0,0 +1,13 @@
+-- Admin choices for the seasonal guide: a plant pinned to a list of a month, or hidden from it
+CREATE TABLE IF NOT EXISTS seasonal_guide_overrides (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    month SMALLINT NOT NULL CHECK (month BETWEEN 1 AND 12),
+    list VARCHAR(20) NOT NULL CHECK (list IN ('BUY', 'PROPAGATE', 'REPOT')),
+    plant_id UUID NOT NULL REFERENCES plants(id) ON DELETE CASCADE,
+    hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    note VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (month, list, plant_id)
+);
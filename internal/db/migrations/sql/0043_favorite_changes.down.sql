@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_favorite_plants_changes;
+DELETE FROM user_favorite_plants WHERE deleted_at IS NOT NULL;
+ALTER TABLE user_favorite_plants DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+-- Favorites removed by their user are kept as tombstones, so clients can sync the changes since their last sync
+ALTER TABLE user_favorite_plants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_user_favorite_plants_changes ON user_favorite_plants(user_id, GREATEST(created_at, deleted_at));
@@ -0,0 +1,15 @@
+-- Recurring reminders users define for plants in their collections, e.g. checking for spider mites monthly
+CREATE TABLE IF NOT EXISTS plant_reminders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    plant_id UUID NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    frequency_days INTEGER NOT NULL CHECK (frequency_days > 0),
+    next_due DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id, plant_id) REFERENCES user_plants(user_id, plant_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_plant_reminders_user_plant ON plant_reminders(user_id, plant_id);
+CREATE INDEX IF NOT EXISTS idx_plant_reminders_next_due ON plant_reminders(next_due);
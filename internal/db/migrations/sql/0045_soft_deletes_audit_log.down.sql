@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS audit_log;
+
+-- Soft-deleted shops and users are removed for good
+DELETE FROM shops WHERE deleted_at IS NOT NULL;
+ALTER TABLE shops DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
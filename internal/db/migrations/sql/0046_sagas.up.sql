@@ -0,0 +1,14 @@
+-- State of multi-step operations, so ones stuck between steps or failing to undo them can be found
+CREATE TABLE IF NOT EXISTS sagas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'RUNNING',
+    current_step VARCHAR(100) NOT NULL DEFAULT '',
+    completed_steps INTEGER NOT NULL DEFAULT 0,
+    data JSONB NOT NULL DEFAULT '{}',
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sagas_unfinished ON sagas(updated_at) WHERE status IN ('RUNNING', 'COMPENSATING', 'FAILED');
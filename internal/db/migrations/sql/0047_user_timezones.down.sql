@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS watering_reminded_on;
+ALTER TABLE users DROP COLUMN IF EXISTS notification_hour;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
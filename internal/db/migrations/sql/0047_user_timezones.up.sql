@@ -0,0 +1,9 @@
+-- Time zone watering reminders of a user follow; NULL follows the home of each plant, else UTC
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
+
+-- Local hour from which a user gets the watering reminders of the day
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notification_hour SMALLINT NOT NULL DEFAULT 9
+    CHECK (notification_hour BETWEEN 0 AND 21);
+
+-- Local day the watering reminders of a user were last sent on, so they are sent once a day at most
+ALTER TABLE users ADD COLUMN IF NOT EXISTS watering_reminded_on DATE;
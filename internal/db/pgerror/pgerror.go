@@ -0,0 +1,122 @@
+// Package pgerror translates the SQLSTATE of PostgreSQL errors into errors services and handlers can
+// branch on: a conflict is answered with 409, a missing referenced row with 404, and a serialization
+// failure can be retried. Repositories keep wrapping errors with fmt.Errorf and %w; the repository
+// views of the database map the errors of their statements, and Map maps any other error, such as one
+// of a transaction, so errors.Is finds the kind anywhere in the chain.
+package pgerror
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrConflict is returned when a row conflicts with an existing one, e.g. on a unique key
+	ErrConflict = errors.New("conflicts with an existing row")
+
+	// ErrReferenceNotFound is returned when a row references a row that does not exist
+	ErrReferenceNotFound = errors.New("referenced row not found")
+
+	// ErrStillReferenced is returned when a row cannot be deleted or changed because other rows reference it
+	ErrStillReferenced = errors.New("row is still referenced")
+
+	// ErrInvalidValue is returned for values a column or check constraint does not accept
+	ErrInvalidValue = errors.New("invalid value")
+
+	// ErrRetryable is returned when a transaction failed because of concurrent ones and can be retried
+	ErrRetryable = errors.New("concurrent update, retry")
+)
+
+// SQLSTATE codes of the errors mapped besides whole classes
+const (
+	codeUniqueViolation      = "23505"
+	codeExclusionViolation   = "23P01"
+	codeForeignKeyViolation  = "23503"
+	codeNotNullViolation     = "23502"
+	codeCheckViolation       = "23514"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+	codeLockNotAvailable     = "55P03"
+
+	// classDataException holds errors of invalid values, e.g. too long strings or malformed input
+	classDataException = "22"
+)
+
+// Error is a PostgreSQL error with the kind its SQLSTATE maps to. It unwraps to both, so errors.Is
+// finds the kind and errors.As the *pq.Error.
+type Error struct {
+	Kind       error  // one of the errors of the package
+	Code       string // SQLSTATE of the error
+	Table      string // table of the violated constraint, if any
+	Constraint string // name of the violated constraint, if any
+
+	err error
+}
+
+// Error returns the message of the original error
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the kind and the original error
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.err}
+}
+
+// Map returns err with the kind of the PostgreSQL error in its chain. Errors without one, errors
+// whose SQLSTATE maps to no kind and errors already mapped are returned unchanged.
+func Map(err error) error {
+	var mapped *Error
+	if err == nil || errors.As(err, &mapped) {
+		return err
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	kind := kindOf(pqErr)
+	if kind == nil {
+		return err
+	}
+	return &Error{
+		Kind:       kind,
+		Code:       string(pqErr.Code),
+		Table:      pqErr.Table,
+		Constraint: pqErr.Constraint,
+		err:        err,
+	}
+}
+
+// kindOf returns the kind of a PostgreSQL error, or nil when its SQLSTATE maps to none
+func kindOf(err *pq.Error) error {
+	switch string(err.Code) {
+	case codeUniqueViolation, codeExclusionViolation:
+		return ErrConflict
+	case codeForeignKeyViolation:
+		// The same code reports inserting a reference to a missing row and deleting a referenced one
+		if strings.Contains(err.Detail, "is still referenced") {
+			return ErrStillReferenced
+		}
+		return ErrReferenceNotFound
+	case codeNotNullViolation, codeCheckViolation:
+		return ErrInvalidValue
+	case codeSerializationFailure, codeDeadlockDetected, codeLockNotAvailable:
+		return ErrRetryable
+	}
+	if strings.HasPrefix(string(err.Code), classDataException) {
+		return ErrInvalidValue
+	}
+	return nil
+}
+
+// ConstraintOf returns the name of the constraint a mapped error violated, or "" when there is none
+func ConstraintOf(err error) string {
+	var mapped *Error
+	if errors.As(Map(err), &mapped) {
+		return mapped.Constraint
+	}
+	return ""
+}
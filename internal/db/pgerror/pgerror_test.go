@@ -0,0 +1,48 @@
+package pgerror
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *pq.Error
+		kind error
+	}{
+		{"unique violation", &pq.Error{Code: "23505", Constraint: "categories_slug_key"}, ErrConflict},
+		{"missing reference", &pq.Error{Code: "23503", Detail: `Key (plant_id)=(1) is not present in table "plants".`}, ErrReferenceNotFound},
+		{"still referenced", &pq.Error{Code: "23503", Detail: `Key (id)=(1) is still referenced from table "shop_plants".`}, ErrStillReferenced},
+		{"check violation", &pq.Error{Code: "23514"}, ErrInvalidValue},
+		{"value too long", &pq.Error{Code: "22001"}, ErrInvalidValue},
+		{"serialization failure", &pq.Error{Code: "40001"}, ErrRetryable},
+		{"deadlock", &pq.Error{Code: "40P01"}, ErrRetryable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Map(fmt.Errorf("failed to create category: %w", tt.err))
+			assert.ErrorIs(t, err, tt.kind)
+			assert.Equal(t, "failed to create category: pq: ", err.Error())
+
+			// The original error stays in the chain and mapping again changes nothing
+			var pqErr *pq.Error
+			assert.True(t, errors.As(err, &pqErr))
+			assert.Same(t, err, Map(err))
+			assert.Equal(t, tt.err.Constraint, ConstraintOf(err))
+		})
+	}
+
+	// Other errors are left alone
+	assert.Nil(t, Map(nil))
+	assert.Equal(t, sql.ErrNoRows, Map(sql.ErrNoRows))
+	undefinedTable := fmt.Errorf("failed to list: %w", &pq.Error{Code: "42P01"})
+	assert.Equal(t, undefinedTable, Map(undefinedTable))
+	assert.Empty(t, ConstraintOf(undefinedTable))
+}
@@ -0,0 +1,254 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db/pgerror"
+	"github.com/jmoiron/sqlx"
+)
+
+// QueryLatencyBuckets are the upper bounds, in seconds, of the buckets query latencies are counted in
+var QueryLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// maxLoggedQueryLength is the most characters of a slow query that are logged
+const maxLoggedQueryLength = 200
+
+// DefaultRepositoryQueryTimeouts are the query timeouts of the repositories whose nightly batch
+// queries take longer than the queries of requests. Catalog exports read their rows as fast as the
+// client downloads them.
+var DefaultRepositoryQueryTimeouts = map[string]time.Duration{
+	"plant_stats":    2 * time.Minute,
+	"reconciliation": 2 * time.Minute,
+	"anonymization":  2 * time.Minute,
+	"plant_export":   10 * time.Minute,
+}
+
+// QueryLatency is a latency histogram of the queries of a repository, with its slow and timed out queries
+type QueryLatency struct {
+	Buckets  []uint64 // queries per bucket of QueryLatencyBuckets, not cumulative; the last one counts the slower queries
+	Sum      float64  // seconds
+	Count    uint64
+	Slow     uint64 // queries slower than the slow query threshold
+	TimedOut uint64 // queries canceled by the query timeout
+}
+
+// QueryMetrics applies the query timeout to the queries of the repositories and records their
+// latency, logging the slow ones. The repository views of a DB share it.
+type QueryMetrics struct {
+	timeout       time.Duration            // no timeout when zero
+	timeouts      map[string]time.Duration // timeouts of repositories overriding timeout, by name
+	slowThreshold time.Duration            // no slow query log when zero
+
+	mu           sync.Mutex
+	repositories map[string]*QueryLatency
+}
+
+// NewQueryMetrics creates query metrics applying the given timeout to each query, unless timeouts
+// has one for its repository, and logging the queries slower than slowThreshold; zero disables either
+func NewQueryMetrics(timeout time.Duration, timeouts map[string]time.Duration, slowThreshold time.Duration) *QueryMetrics {
+	return &QueryMetrics{
+		timeout:       timeout,
+		timeouts:      timeouts,
+		slowThreshold: slowThreshold,
+		repositories:  make(map[string]*QueryLatency),
+	}
+}
+
+// ParseQueryTimeouts parses a comma-separated list of repository=duration pairs over the defaults.
+// Invalid durations are logged and ignored.
+func ParseQueryTimeouts(value string, defaults map[string]time.Duration) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(defaults))
+	for name, timeout := range defaults {
+		timeouts[name] = timeout
+	}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(pair, "=")
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || timeout < 0 {
+			log.Printf("Warning: invalid query timeout %q for the %s repository, ignoring it\n", value, name)
+			continue
+		}
+		timeouts[strings.TrimSpace(name)] = timeout
+	}
+	return timeouts
+}
+
+// timeoutOf returns the query timeout of a repository
+func (m *QueryMetrics) timeoutOf(repository string) time.Duration {
+	if timeout, ok := m.timeouts[repository]; ok {
+		return timeout
+	}
+	return m.timeout
+}
+
+// Stats returns the latency histogram of each repository by name
+func (m *QueryMetrics) Stats() map[string]QueryLatency {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]QueryLatency, len(m.repositories))
+	for name, latency := range m.repositories {
+		snapshot := *latency
+		snapshot.Buckets = append([]uint64(nil), latency.Buckets...)
+		stats[name] = snapshot
+	}
+	return stats
+}
+
+// observe records a query of a repository that took elapsed, telling whether its timeout canceled it
+func (m *QueryMetrics) observe(repository, query string, elapsed time.Duration, timedOut bool) {
+	slow := m.slowThreshold > 0 && elapsed >= m.slowThreshold
+
+	m.mu.Lock()
+	latency, ok := m.repositories[repository]
+	if !ok {
+		latency = &QueryLatency{Buckets: make([]uint64, len(QueryLatencyBuckets)+1)}
+		m.repositories[repository] = latency
+	}
+	seconds := elapsed.Seconds()
+	latency.Buckets[sort.SearchFloat64s(QueryLatencyBuckets, seconds)]++
+	latency.Sum += seconds
+	latency.Count++
+	if slow {
+		latency.Slow++
+	}
+	if timedOut {
+		latency.TimedOut++
+	}
+	m.mu.Unlock()
+
+	// Log the statement only; its arguments can hold personal data
+	switch {
+	case timedOut:
+		log.Printf("Query of the %s repository timed out after %s: %s", repository, elapsed.Round(time.Millisecond), compactQuery(query))
+	case slow:
+		log.Printf("Slow query of the %s repository took %s: %s", repository, elapsed.Round(time.Millisecond), compactQuery(query))
+	}
+}
+
+// compactQuery returns a query on one line, cut to maxLoggedQueryLength characters
+func compactQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if runes := []rune(query); len(runes) > maxLoggedQueryLength {
+		query = string(runes[:maxLoggedQueryLength]) + "…"
+	}
+	return query
+}
+
+// SetQueryMetrics sets the metrics the repository views of the database record their queries in
+func (d *DB) SetQueryMetrics(metrics *QueryMetrics) {
+	d.queryMetrics = metrics
+}
+
+// QueryMetrics returns the metrics the repository views of the database record their queries in,
+// or nil when queries are not instrumented
+func (d *DB) QueryMetrics() *QueryMetrics {
+	return d.queryMetrics
+}
+
+// Repository returns a view of the database for a repository. Its queries run with the query
+// timeout and are recorded under the repository's name; the database itself, used by migrations,
+// stays without them. Statements of transactions run under the context the transaction began with.
+// The errors of its statements are mapped with pgerror.Map; those of transactions and of rows read
+// after a query returned are not.
+func (d *DB) Repository(name string) *DB {
+	view := *d
+	view.repository = name
+	return &view
+}
+
+// startQuery applies the query timeout to ctx and returns the function recording the query once
+// it ended. The rows of a query are read after it returns, so for them release is false and the
+// context is released when its deadline passes rather than when the query returns.
+func (d *DB) startQuery(ctx context.Context, release bool) (context.Context, func(query string, err error)) {
+	if d.queryMetrics == nil || d.repository == "" {
+		return ctx, func(string, error) {}
+	}
+
+	start := time.Now()
+	cancel := context.CancelFunc(func() {})
+	if timeout := d.queryMetrics.timeoutOf(d.repository); timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		if release {
+			cancel = cancelTimeout
+		} else {
+			time.AfterFunc(timeout, cancelTimeout)
+		}
+	}
+
+	// The driver reports a canceled query with an error of its own, so a timeout is told by the context
+	return ctx, func(query string, err error) {
+		timedOut := err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+		cancel()
+		d.queryMetrics.observe(d.repository, query, time.Since(start), timedOut)
+	}
+}
+
+// GetContext runs a query and scans its single row into dest
+func (d *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, done := d.startQuery(ctx, true)
+	err := d.DB.GetContext(ctx, dest, query, args...)
+	done(query, err)
+	return pgerror.Map(err)
+}
+
+// SelectContext runs a query and scans its rows into dest
+func (d *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, done := d.startQuery(ctx, true)
+	err := d.DB.SelectContext(ctx, dest, query, args...)
+	done(query, err)
+	return pgerror.Map(err)
+}
+
+// ExecContext runs a statement without returning rows
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, done := d.startQuery(ctx, true)
+	result, err := d.DB.ExecContext(ctx, query, args...)
+	done(query, err)
+	return result, pgerror.Map(err)
+}
+
+// NamedExecContext runs a statement with named parameters without returning rows
+func (d *DB) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	ctx, done := d.startQuery(ctx, true)
+	result, err := d.DB.NamedExecContext(ctx, query, arg)
+	done(query, err)
+	return result, pgerror.Map(err)
+}
+
+// QueryxContext runs a query returning rows; its latency is the time to its first row
+func (d *DB) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	ctx, done := d.startQuery(ctx, false)
+	rows, err := d.DB.QueryxContext(ctx, query, args...)
+	done(query, err)
+	return rows, pgerror.Map(err)
+}
+
+// QueryRowxContext runs a query returning at most one row
+func (d *DB) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	ctx, done := d.startQuery(ctx, false)
+	row := d.DB.QueryRowxContext(ctx, query, args...)
+	done(query, row.Err())
+	return row
+}
+
+// QueryRowContext runs a query returning at most one row
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, done := d.startQuery(ctx, false)
+	row := d.DB.QueryRowContext(ctx, query, args...)
+	done(query, row.Err())
+	return row
+}
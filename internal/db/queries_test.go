@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db/pgerror"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseQueryTimeouts tests that repository timeouts override the defaults and invalid ones are ignored
+func TestParseQueryTimeouts(t *testing.T) {
+	timeouts := ParseQueryTimeouts("plant=2s, reconciliation=5m,user=soon", map[string]time.Duration{
+		"reconciliation": time.Minute,
+		"anonymization":  time.Minute,
+	})
+
+	assert.Equal(t, map[string]time.Duration{
+		"plant":          2 * time.Second,
+		"reconciliation": 5 * time.Minute,
+		"anonymization":  time.Minute,
+	}, timeouts)
+}
+
+// TestRepositoryQueries tests that the queries of a repository view are timed out and recorded
+// under its name, while the queries of the database itself are not
+func TestRepositoryQueries(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+
+	d := &DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	d.SetQueryMetrics(NewQueryMetrics(time.Second, map[string]time.Duration{"plant": 20 * time.Millisecond}, 10*time.Millisecond))
+	plants, users := d.Repository("plant"), d.Repository("user")
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT name FROM plants").WillDelayFor(100 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Ficus"))
+	mock.ExpectExec("DELETE FROM plants").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SELECT pg_sleep").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = users.ExecContext(context.Background(), "UPDATE users SET city = NULL")
+	require.NoError(t, err)
+
+	var name string
+	err = plants.GetContext(context.Background(), &name, "SELECT name FROM plants WHERE id = $1", 1)
+	assert.Error(t, err)
+
+	_, err = plants.ExecContext(context.Background(), "DELETE FROM plants")
+	require.NoError(t, err)
+
+	// Migrations run on the database itself, without a timeout and unrecorded
+	_, err = d.ExecContext(context.Background(), "SELECT pg_sleep(1)")
+	require.NoError(t, err)
+
+	stats := d.QueryMetrics().Stats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, uint64(2), stats["plant"].Count)
+	assert.Equal(t, uint64(1), stats["plant"].TimedOut)
+	assert.Equal(t, uint64(1), stats["plant"].Slow)
+	assert.Equal(t, uint64(1), stats["plant"].Buckets[0]+stats["plant"].Buckets[1], "the delete ends within 10ms")
+	assert.Equal(t, uint64(1), stats["user"].Count)
+	assert.Len(t, stats["user"].Buckets, len(QueryLatencyBuckets)+1)
+}
+
+// TestCompactQuery tests that logged queries are put on one line and cut
+func TestCompactQuery(t *testing.T) {
+	assert.Equal(t, "SELECT id FROM plants WHERE name = $1", compactQuery("\n\t\tSELECT id\n\t\tFROM plants\n\t\tWHERE name = $1\n\t"))
+	assert.Len(t, []rune(compactQuery("SELECT "+string(make([]byte, 300)))), maxLoggedQueryLength+1)
+}
+
+// TestRepositoryQueries_MapErrors tests that the errors of the statements of a repository view are
+// mapped to the kind of their SQLSTATE
+func TestRepositoryQueries_MapErrors(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+
+	categories := (&DB{DB: sqlx.NewDb(mockDB, "sqlmock")}).Repository("plant_taxonomy")
+	mock.ExpectExec("INSERT INTO categories").WillReturnError(&pq.Error{Code: "23505", Constraint: "categories_slug_key"})
+	mock.ExpectQuery("SELECT slug FROM categories").WillReturnError(&pq.Error{Code: "40001"})
+
+	_, err = categories.ExecContext(context.Background(), "INSERT INTO categories (slug) VALUES ($1)", "ferns")
+	assert.ErrorIs(t, err, pgerror.ErrConflict)
+	assert.Equal(t, "categories_slug_key", pgerror.ConstraintOf(err))
+
+	var slugs []string
+	err = categories.SelectContext(context.Background(), &slugs, "SELECT slug FROM categories")
+	assert.ErrorIs(t, err, pgerror.ErrRetryable)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// RenamePhase is the step a deployment has reached in the zero-downtime rename of a column.
+// Every instance moves through the phases in order; the next phase is deployed only after
+// all instances run the previous one.
+type RenamePhase string
+
+const (
+	// RenamePhaseOld reads and writes the old column only
+	RenamePhaseOld RenamePhase = "OLD"
+
+	// RenamePhaseDualWrite writes both columns and reads the old one. Run BackfillRenames once
+	// every instance is in this phase to copy values written by older instances.
+	RenamePhaseDualWrite RenamePhase = "DUAL_WRITE"
+
+	// RenamePhaseDualRead writes both columns and reads the new one, falling back to the old one
+	RenamePhaseDualRead RenamePhase = "DUAL_READ"
+
+	// RenamePhaseNew reads and writes the new column only; the old column can then be dropped
+	RenamePhaseNew RenamePhase = "NEW"
+)
+
+// ColumnRename describes a column being renamed
+type ColumnRename struct {
+	Table string
+	Old   string
+	New   string
+}
+
+// key returns the name a rename is configured by
+func (c ColumnRename) key() string {
+	return c.Table + "." + c.Old
+}
+
+// ColumnRenames lists the columns being renamed with a staged rollout. Their new columns are
+// added by a migration; the old ones are dropped by a later migration once every deployment
+// has reached RenamePhaseNew.
+var ColumnRenames = []ColumnRename{
+	{Table: "user_plants", Old: "last_watered", New: "last_watered_at"},
+	{Table: "user_plants", Old: "next_watering", New: "next_watering_at"},
+	{Table: "care_instructions", Old: "watering_frequency", New: "watering_frequency_days"},
+	{Table: "care_instructions", Old: "fertilizer_frequency", New: "fertilizer_frequency_days"},
+}
+
+// renameByKey returns the rename of a column, if it is being renamed
+func renameByKey(table, column string) (ColumnRename, bool) {
+	for _, rename := range ColumnRenames {
+		if rename.Table == table && rename.Old == column {
+			return rename, true
+		}
+	}
+	return ColumnRename{}, false
+}
+
+// ParseRenamePhases parses a comma-separated list of table.column=PHASE pairs, where column is
+// the old name. Unknown columns and phases are logged and ignored, leaving the column in RenamePhaseOld.
+func ParseRenamePhases(value string) map[string]RenamePhase {
+	phases := make(map[string]RenamePhase)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, phase, _ := strings.Cut(pair, "=")
+		table, column, _ := strings.Cut(name, ".")
+		if _, ok := renameByKey(table, column); !ok {
+			log.Printf("Warning: %s is not a column being renamed, ignoring it\n", name)
+			continue
+		}
+
+		switch p := RenamePhase(strings.ToUpper(phase)); p {
+		case RenamePhaseOld, RenamePhaseDualWrite, RenamePhaseDualRead, RenamePhaseNew:
+			phases[name] = p
+		default:
+			log.Printf("Warning: invalid rename phase %q for %s, keeping the old column\n", phase, name)
+		}
+	}
+	return phases
+}
+
+// SetRenamePhases sets the phase of each column being renamed
+func (d *DB) SetRenamePhases(phases map[string]RenamePhase) {
+	d.renamePhases = phases
+}
+
+// renamePhase returns the phase of a column and its new name; columns not being renamed are in RenamePhaseOld
+func (d *DB) renamePhase(table, column string) (RenamePhase, string) {
+	rename, ok := renameByKey(table, column)
+	if !ok {
+		return RenamePhaseOld, column
+	}
+	phase, ok := d.renamePhases[rename.key()]
+	if !ok {
+		return RenamePhaseOld, rename.New
+	}
+	return phase, rename.New
+}
+
+// Read returns the expression reading a column by its old name, qualified with the table
+// alias unless it is empty
+func (d *DB) Read(alias, table, column string) string {
+	qualify := func(name string) string {
+		if alias == "" {
+			return name
+		}
+		return alias + "." + name
+	}
+
+	switch phase, newColumn := d.renamePhase(table, column); phase {
+	case RenamePhaseDualRead:
+		return fmt.Sprintf("COALESCE(%s, %s)", qualify(newColumn), qualify(column))
+	case RenamePhaseNew:
+		return qualify(newColumn)
+	default:
+		return qualify(column)
+	}
+}
+
+// Columns returns the columns a value of a column is written to
+func (d *DB) Columns(table, column string) []string {
+	switch phase, newColumn := d.renamePhase(table, column); phase {
+	case RenamePhaseDualWrite, RenamePhaseDualRead:
+		return []string{column, newColumn}
+	case RenamePhaseNew:
+		return []string{newColumn}
+	default:
+		return []string{column}
+	}
+}
+
+// Assign returns the SET clause writing a value to a column
+func (d *DB) Assign(table, column, value string) string {
+	columns := d.Columns(table, column)
+	assignments := make([]string, 0, len(columns))
+	for _, name := range columns {
+		assignments = append(assignments, name+" = "+value)
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// Insert returns the column and value lists of an INSERT writing the values to the columns
+func (d *DB) Insert(table string, columns []string, values []string) (string, string) {
+	var names, placeholders []string
+	for i, column := range columns {
+		for _, name := range d.Columns(table, column) {
+			names = append(names, name)
+			placeholders = append(placeholders, values[i])
+		}
+	}
+	return strings.Join(names, ", "), strings.Join(placeholders, ", ")
+}
+
+// BackfillRenames copies the old column to the new one wherever they differ for every column
+// being dual-written, and returns the number of rows updated
+func (d *DB) BackfillRenames(ctx context.Context) (int64, error) {
+	var total int64
+	for _, rename := range ColumnRenames {
+		if phase := d.renamePhases[rename.key()]; phase != RenamePhaseDualWrite {
+			continue
+		}
+
+		result, err := d.ExecContext(ctx, fmt.Sprintf(
+			"UPDATE %s SET %s = %s WHERE %s IS DISTINCT FROM %s",
+			rename.Table, rename.New, rename.Old, rename.New, rename.Old,
+		))
+		if err != nil {
+			return total, fmt.Errorf("failed to backfill %s: %w", rename.key(), err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		total += rows
+	}
+	return total, nil
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseRenamePhases tests that unknown columns and phases are ignored
+func TestParseRenamePhases(t *testing.T) {
+	phases := ParseRenamePhases("user_plants.last_watered=dual_write, care_instructions.watering_frequency=NEW,plants.name=NEW,user_plants.next_watering=SOON")
+
+	assert.Equal(t, map[string]RenamePhase{
+		"user_plants.last_watered":             RenamePhaseDualWrite,
+		"care_instructions.watering_frequency": RenamePhaseNew,
+	}, phases)
+}
+
+// TestRenamePhases tests the columns read and written in each phase
+func TestRenamePhases(t *testing.T) {
+	d := &DB{}
+	assert.Equal(t, "up.last_watered", d.Read("up", "user_plants", "last_watered"))
+	assert.Equal(t, "last_watered = $1", d.Assign("user_plants", "last_watered", "$1"))
+
+	d.SetRenamePhases(map[string]RenamePhase{"user_plants.last_watered": RenamePhaseDualWrite})
+	assert.Equal(t, "up.last_watered", d.Read("up", "user_plants", "last_watered"))
+	assert.Equal(t, "last_watered = $1, last_watered_at = $1", d.Assign("user_plants", "last_watered", "$1"))
+
+	columns, values := d.Insert("user_plants", []string{"user_id", "last_watered", "next_watering"}, []string{"$1", "$2", "$3"})
+	assert.Equal(t, "user_id, last_watered, last_watered_at, next_watering", columns)
+	assert.Equal(t, "$1, $2, $2, $3", values)
+
+	d.SetRenamePhases(map[string]RenamePhase{"user_plants.last_watered": RenamePhaseDualRead})
+	assert.Equal(t, "COALESCE(up.last_watered_at, up.last_watered)", d.Read("up", "user_plants", "last_watered"))
+	assert.Equal(t, "last_watered = $1, last_watered_at = $1", d.Assign("user_plants", "last_watered", "$1"))
+
+	d.SetRenamePhases(map[string]RenamePhase{"user_plants.last_watered": RenamePhaseNew})
+	assert.Equal(t, "last_watered_at", d.Read("", "user_plants", "last_watered"))
+	assert.Equal(t, "last_watered_at = $1", d.Assign("user_plants", "last_watered", "$1"))
+
+	// Columns that are not being renamed are left alone
+	assert.Equal(t, "up.location", d.Read("up", "user_plants", "location"))
+}
+
+// TestBackfillRenames tests that only dual-written columns are backfilled
+func TestBackfillRenames(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+
+	d := &DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+	d.SetRenamePhases(map[string]RenamePhase{
+		"user_plants.next_watering":            RenamePhaseDualWrite,
+		"care_instructions.watering_frequency": RenamePhaseNew,
+	})
+
+	mock.ExpectExec(`UPDATE user_plants SET next_watering_at = next_watering WHERE next_watering_at IS DISTINCT FROM next_watering`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	updated, err := d.BackfillRenames(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
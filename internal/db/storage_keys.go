@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// StorageColumn is a column referencing stored assets by key
+type StorageColumn struct {
+	Table  string
+	Column string
+	Array  bool // the column holds a text array of keys
+}
+
+// StorageColumns lists the columns referencing stored assets. They used to hold absolute URLs;
+// RewriteStorageURLs turns the URLs of stored assets into keys.
+var StorageColumns = []StorageColumn{
+	{Table: "plants", Column: "image_url"},
+	{Table: "shops", Column: "image_url"},
+	{Table: "special_offers", Column: "image_url"},
+	{Table: "users", Column: "profile_image_url"},
+	{Table: "shop_plants", Column: "batch_photo_urls", Array: true},
+}
+
+// RewriteStorageURLs replaces the URLs starting with one of the base URLs by the key that follows
+// the base URL in every storage column, and returns the number of rows updated. URLs of assets
+// hosted elsewhere are left alone, so the rewrite can run any number of times.
+func (d *DB) RewriteStorageURLs(ctx context.Context, baseURLs []string) (int64, error) {
+	var total int64
+	for _, baseURL := range baseURLs {
+		if baseURL == "" {
+			continue
+		}
+		pattern := escapeLike(baseURL) + "%"
+
+		for _, column := range StorageColumns {
+			query := fmt.Sprintf(
+				"UPDATE %s SET %s = substr(%s, length($1) + 1) WHERE %s LIKE $2",
+				column.Table, column.Column, column.Column, column.Column,
+			)
+			if column.Array {
+				query = fmt.Sprintf(`
+					UPDATE %s SET %s = ARRAY(
+						SELECT CASE WHEN u LIKE $2 THEN substr(u, length($1) + 1) ELSE u END
+						FROM unnest(%s) WITH ORDINALITY AS a(u, n)
+						ORDER BY n
+					)
+					WHERE EXISTS (SELECT 1 FROM unnest(%s) AS u WHERE u LIKE $2)
+				`, column.Table, column.Column, column.Column, column.Column)
+			}
+
+			result, err := d.ExecContext(ctx, query, baseURL, pattern)
+			if err != nil {
+				return total, fmt.Errorf("failed to rewrite %s.%s: %w", column.Table, column.Column, err)
+			}
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return total, fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			total += rows
+		}
+	}
+	return total, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
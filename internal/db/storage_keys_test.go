@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRewriteStorageURLs tests that every storage column is rewritten for the base URL with its wildcards escaped
+func TestRewriteStorageURLs(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	d := &DB{DB: sqlx.NewDb(mockDB, "sqlmock")}
+
+	baseURL := "https://old_bucket.example.com/"
+	for _, column := range StorageColumns {
+		mock.ExpectExec("UPDATE "+column.Table+" SET "+column.Column).
+			WithArgs(baseURL, `https://old\_bucket.example.com/%`).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+	}
+
+	updated, err := d.RewriteStorageURLs(context.Background(), []string{baseURL, ""})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2*len(StorageColumns)), updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// ClientProfileHeader is the header a client sends to pick the shape of plant lists
+const ClientProfileHeader = "X-Client-Profile"
+
+// ClientProfile is the shape of the plant objects a client receives in lists
+type ClientProfile string
+
+const (
+	// ClientProfileFull returns complete plant objects
+	ClientProfileFull ClientProfile = "full"
+
+	// ClientProfileLite returns pared-down plant objects for old devices and slow connections
+	ClientProfileLite ClientProfile = "lite"
+)
+
+// LiteImageWidth is the width in pixels of the image variant sent to lite clients. The image
+// host resizes images by the width query parameter.
+const LiteImageWidth = 320
+
+// ClientProfileFromRequest returns the profile asked for by the X-Client-Profile header or the
+// profile query parameter, defaulting to the full profile
+func ClientProfileFromRequest(r *http.Request) ClientProfile {
+	value := r.Header.Get(ClientProfileHeader)
+	if value == "" {
+		value = r.URL.Query().Get("profile")
+	}
+	if ClientProfile(strings.ToLower(strings.TrimSpace(value))) == ClientProfileLite {
+		return ClientProfileLite
+	}
+	return ClientProfileFull
+}
+
+// LiteCareInstructions holds the care essentials shown on plant cards
+type LiteCareInstructions struct {
+	WateringFrequency int                  `json:"wateringFrequency"`
+	Sunlight          models.SunlightLevel `json:"sunlight"`
+	Humidity          models.HumidityLevel `json:"humidity"`
+}
+
+// LitePlant is a plant without its description, care notes and sources, with a small image
+type LitePlant struct {
+	ID                  uuid.UUID                 `json:"id"`
+	Name                string                    `json:"name"`
+	ScientificName      string                    `json:"scientificName"`
+	PetFriendly         *bool                     `json:"petFriendly,omitempty"`
+	ImageURL            string                    `json:"imageUrl"`
+	CareInstructions    LiteCareInstructions      `json:"careInstructions"`
+	Price               *float64                  `json:"price,omitempty"`
+	ShopID              *string                   `json:"shopId,omitempty"`
+	IsFavorite          bool                      `json:"isFavorite"`
+	NextWatering        *time.Time                `json:"nextWatering,omitempty"`
+	NextWateringDisplay string                    `json:"nextWateringDisplay,omitempty"`
+	CareHint            *models.CareHint          `json:"careHint,omitempty"`
+	LowEffort           *models.LowEffortWatering `json:"lowEffort,omitempty"`
+	DeletedAt           *time.Time                `json:"deletedAt,omitempty"`
+}
+
+// NewLitePlant converts a plant to its lite shape
+func NewLitePlant(plant *models.Plant) *LitePlant {
+	return &LitePlant{
+		ID:             plant.ID,
+		Name:           plant.Name,
+		ScientificName: plant.ScientificName,
+		PetFriendly:    plant.PetFriendly,
+		ImageURL:       ImageVariant(plant.ImageURL.URL(), LiteImageWidth),
+		CareInstructions: LiteCareInstructions{
+			WateringFrequency: plant.CareInstructions.WateringFrequency,
+			Sunlight:          plant.CareInstructions.Sunlight,
+			Humidity:          plant.CareInstructions.Humidity,
+		},
+		Price:               plant.Price,
+		ShopID:              plant.ShopID,
+		IsFavorite:          plant.IsFavorite,
+		NextWatering:        plant.NextWatering,
+		NextWateringDisplay: plant.NextWateringDisplay,
+		CareHint:            plant.CareHint,
+		LowEffort:           plant.LowEffort,
+		DeletedAt:           plant.DeletedAt,
+	}
+}
+
+// Plants shapes a list of plants for a client profile: lite clients get LitePlant objects and
+// the others get the plants unchanged
+func Plants(plants []*models.Plant, profile ClientProfile) interface{} {
+	if profile != ClientProfileLite {
+		return plants
+	}
+
+	lite := make([]*LitePlant, len(plants))
+	for i, plant := range plants {
+		lite[i] = NewLitePlant(plant)
+	}
+	return lite
+}
+
+// ImageVariant returns the URL of an image resized to the width. URLs that are empty, relative
+// or cannot be parsed are returned unchanged.
+func ImageVariant(imageURL string, width int) string {
+	u, err := url.Parse(imageURL)
+	if err != nil || !u.IsAbs() {
+		return imageURL
+	}
+
+	query := u.Query()
+	query.Set("width", strconv.Itoa(width))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestClientProfileFromRequest tests that the header wins over the query parameter
+func TestClientProfileFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/plants", nil)
+	assert.Equal(t, ClientProfileFull, ClientProfileFromRequest(r))
+
+	r.Header.Set(ClientProfileHeader, " Lite ")
+	assert.Equal(t, ClientProfileLite, ClientProfileFromRequest(r))
+
+	r = httptest.NewRequest("GET", "/plants?profile=lite", nil)
+	assert.Equal(t, ClientProfileLite, ClientProfileFromRequest(r))
+
+	r.Header.Set(ClientProfileHeader, "full")
+	assert.Equal(t, ClientProfileFull, ClientProfileFromRequest(r))
+
+	// Unknown profiles get full plants
+	r = httptest.NewRequest("GET", "/plants?profile=tiny", nil)
+	assert.Equal(t, ClientProfileFull, ClientProfileFromRequest(r))
+}
+
+// TestImageVariant tests that the width is added to absolute URLs only
+func TestImageVariant(t *testing.T) {
+	assert.Equal(t, "https://cdn.example.com/monstera.jpg?width=320", ImageVariant("https://cdn.example.com/monstera.jpg", 320))
+	assert.Equal(t, "https://cdn.example.com/monstera.jpg?v=2&width=320", ImageVariant("https://cdn.example.com/monstera.jpg?v=2&width=1200", 320))
+	assert.Equal(t, "", ImageVariant("", 320))
+	assert.Equal(t, "/static/monstera.jpg", ImageVariant("/static/monstera.jpg", 320))
+}
+
+// TestPlants tests that lite clients get plants without care notes
+func TestPlants(t *testing.T) {
+	plants := []*models.Plant{{
+		ID:          uuid.New(),
+		Name:        "Monstera",
+		Description: "A large tropical plant",
+		ImageURL:    "https://cdn.example.com/monstera.jpg",
+		CareInstructions: models.CareInstructions{
+			WateringFrequency: 7,
+			Sunlight:          models.SunlightLevelMedium,
+			AdditionalNotes:   "Wipe the leaves monthly",
+		},
+		IsFavorite:          true,
+		NextWateringDisplay: "today",
+		CareHint:            &models.CareHint{Status: models.CareStatusDueSoon, Badge: "Water today"},
+	}}
+
+	assert.Equal(t, plants, Plants(plants, ClientProfileFull))
+
+	lite, ok := Plants(plants, ClientProfileLite).([]*LitePlant)
+	assert.True(t, ok)
+	assert.Len(t, lite, 1)
+	assert.Equal(t, plants[0].ID, lite[0].ID)
+	assert.Equal(t, "https://cdn.example.com/monstera.jpg?width=320", lite[0].ImageURL)
+	assert.Equal(t, 7, lite[0].CareInstructions.WateringFrequency)
+	assert.True(t, lite[0].IsFavorite)
+	assert.Equal(t, plants[0].CareHint, lite[0].CareHint)
+	assert.Equal(t, "today", lite[0].NextWateringDisplay)
+}
+
+// TestPlants_StorageKeys tests that plants stored with image keys get URLs under the CDN base URL
+func TestPlants_StorageKeys(t *testing.T) {
+	storage.SetDefault(storage.New("https://cdn.example.com/", []string{"https://old-bucket.example.com/"}))
+	defer storage.SetDefault(storage.New("", nil))
+
+	plants := []*models.Plant{{ID: uuid.New(), ImageURL: "plants/monstera.jpg"}}
+	lite := Plants(plants, ClientProfileLite).([]*LitePlant)
+	assert.Equal(t, "https://cdn.example.com/plants/monstera.jpg?width=320", lite[0].ImageURL)
+
+	data, err := json.Marshal(plants[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"imageUrl":"https://cdn.example.com/plants/monstera.jpg"`)
+
+	// URLs sent by clients are stored as keys
+	var plant models.Plant
+	assert.NoError(t, json.Unmarshal([]byte(`{"imageUrl":"https://old-bucket.example.com/plants/ficus.jpg"}`), &plant))
+	assert.Equal(t, models.AssetKey("plants/ficus.jpg"), plant.ImageURL)
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Envelope is the wire format of events sent to external brokers
+type Envelope struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	Key        string    `json:"key"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Payload    Event     `json:"payload"`
+}
+
+// encodeEnvelope wraps an event in an envelope and encodes it as JSON
+func encodeEnvelope(event Event) ([]byte, error) {
+	data, err := json.Marshal(Envelope{
+		ID:         uuid.New(),
+		Name:       event.EventName(),
+		Key:        event.EventKey(),
+		OccurredAt: time.Now().UTC(),
+		Payload:    event,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event %s: %w", event.EventName(), err)
+	}
+	return data, nil
+}
+
+// NATSConn is the part of a NATS connection used to publish events; *nats.Conn satisfies it
+type NATSConn interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSPublisher publishes events to NATS on the subject "<prefix>.<event name>"
+type NATSPublisher struct {
+	conn          NATSConn
+	subjectPrefix string
+}
+
+// NewNATSPublisher creates a new NATS publisher
+func NewNATSPublisher(conn NATSConn, subjectPrefix string) *NATSPublisher {
+	return &NATSPublisher{
+		conn:          conn,
+		subjectPrefix: subjectPrefix,
+	}
+}
+
+// Publish publishes an event to NATS
+func (p *NATSPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := encodeEnvelope(event)
+	if err != nil {
+		return err
+	}
+
+	subject := event.EventName()
+	if p.subjectPrefix != "" {
+		subject = p.subjectPrefix + "." + subject
+	}
+
+	if err := p.conn.Publish(subject, data); err != nil {
+		return fmt.Errorf("failed to publish event %s to NATS: %w", event.EventName(), err)
+	}
+	return nil
+}
+
+// KafkaProducer is the part of a Kafka client used to publish events; wrap e.g. a kafka-go Writer to satisfy it
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// KafkaPublisher publishes all events to one Kafka topic, keyed so events of a user stay in order
+type KafkaPublisher struct {
+	producer KafkaProducer
+	topic    string
+}
+
+// NewKafkaPublisher creates a new Kafka publisher
+func NewKafkaPublisher(producer KafkaProducer, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		producer: producer,
+		topic:    topic,
+	}
+}
+
+// Publish publishes an event to Kafka
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := encodeEnvelope(event)
+	if err != nil {
+		return err
+	}
+
+	if err := p.producer.Produce(ctx, p.topic, []byte(event.EventKey()), data); err != nil {
+		return fmt.Errorf("failed to publish event %s to Kafka: %w", event.EventName(), err)
+	}
+	return nil
+}
+
+// Forward returns a handler that republishes events to another publisher, e.g. to mirror the
+// in-process bus to NATS or Kafka with bus.SubscribeAll(events.Forward(natsPublisher))
+func Forward(publisher Publisher) Handler {
+	return func(ctx context.Context, event Event) error {
+		return publisher.Publish(ctx, event)
+	}
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// InProcessBus delivers events to handlers registered in the same process. Handlers run in the
+// background so a slow or failing consumer never delays or fails the request that published the event.
+type InProcessBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	all      []Handler
+	wg       sync.WaitGroup
+}
+
+// NewInProcessBus creates a new in-process event bus
+func NewInProcessBus() *InProcessBus {
+	return &InProcessBus{
+		handlers: make(map[string][]Handler),
+	}
+}
+
+// Subscribe registers a handler for events with the given name
+func (b *InProcessBus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// SubscribeAll registers a handler for every event, e.g. to forward them to an external broker
+func (b *InProcessBus) SubscribeAll(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.all = append(b.all, handler)
+}
+
+// Publish delivers an event to its handlers in the background
+func (b *InProcessBus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers[event.EventName()])+len(b.all))
+	handlers = append(handlers, b.handlers[event.EventName()]...)
+	handlers = append(handlers, b.all...)
+	b.mu.RUnlock()
+
+	// Handlers must outlive the request that published the event
+	ctx = context.WithoutCancel(ctx)
+	for _, handler := range handlers {
+		b.wg.Add(1)
+		go func(handler Handler) {
+			defer b.wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Event handler for %s panicked: %v", event.EventName(), r)
+				}
+			}()
+
+			if err := handler(ctx, event); err != nil {
+				log.Printf("Event handler for %s failed: %v", event.EventName(), err)
+			}
+		}(handler)
+	}
+
+	return nil
+}
+
+// Close waits for the handlers of all published events to finish
+func (b *InProcessBus) Close() {
+	b.wg.Wait()
+}
@@ -0,0 +1,108 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInProcessBus_Publish tests that events reach their own and catch-all handlers
+func TestInProcessBus_Publish(t *testing.T) {
+	bus := NewInProcessBus()
+
+	var mu sync.Mutex
+	received := map[string][]string{}
+	record := func(handler string) Handler {
+		return func(ctx context.Context, event Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			received[handler] = append(received[handler], event.EventName())
+			return nil
+		}
+	}
+	bus.Subscribe(PlantWateredEvent, record("watered"))
+	bus.SubscribeAll(record("all"))
+
+	// A failing or panicking handler must not affect the others
+	bus.Subscribe(PlantWateredEvent, func(ctx context.Context, event Event) error {
+		return errors.New("consumer unavailable")
+	})
+	bus.Subscribe(PlantWateredEvent, func(ctx context.Context, event Event) error {
+		panic("broken consumer")
+	})
+
+	// A cancelled request context must not cancel the handlers
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NoError(t, bus.Publish(ctx, PlantWatered{UserID: uuid.New(), PlantID: uuid.New(), WateredAt: time.Now()}))
+	assert.NoError(t, bus.Publish(ctx, UserRegistered{UserID: uuid.New(), OccurredAt: time.Now()}))
+	bus.Close()
+
+	assert.Equal(t, []string{PlantWateredEvent}, received["watered"])
+	assert.ElementsMatch(t, []string{PlantWateredEvent, UserRegisteredEvent}, received["all"])
+}
+
+type fakeNATSConn struct {
+	subject string
+	data    []byte
+}
+
+func (c *fakeNATSConn) Publish(subject string, data []byte) error {
+	c.subject = subject
+	c.data = data
+	return nil
+}
+
+type fakeKafkaProducer struct {
+	topic string
+	key   []byte
+	value []byte
+}
+
+func (p *fakeKafkaProducer) Produce(ctx context.Context, topic string, key, value []byte) error {
+	p.topic = topic
+	p.key = key
+	p.value = value
+	return nil
+}
+
+// TestBrokerPublishers tests the subjects, keys and envelopes sent to NATS and Kafka
+func TestBrokerPublishers(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	plantID := uuid.New()
+	event := NotificationCreated{UserID: userID, PlantID: &plantID, Type: "WATERING", Message: "Water me"}
+
+	conn := &fakeNATSConn{}
+	assert.NoError(t, NewNATSPublisher(conn, "planter").Publish(ctx, event))
+	assert.Equal(t, "planter.notification.created", conn.subject)
+
+	var envelope struct {
+		Name    string              `json:"name"`
+		Key     string              `json:"key"`
+		Payload NotificationCreated `json:"payload"`
+	}
+	assert.NoError(t, json.Unmarshal(conn.data, &envelope))
+	assert.Equal(t, NotificationCreatedEvent, envelope.Name)
+	assert.Equal(t, event, envelope.Payload)
+
+	producer := &fakeKafkaProducer{}
+	assert.NoError(t, NewKafkaPublisher(producer, "planter-events").Publish(ctx, event))
+	assert.Equal(t, "planter-events", producer.topic)
+	assert.Equal(t, userID.String(), string(producer.key))
+
+	// Forwarding mirrors the in-process bus to a broker
+	bus := NewInProcessBus()
+	forwarded := &fakeNATSConn{}
+	bus.SubscribeAll(Forward(NewNATSPublisher(forwarded, "")))
+	assert.NoError(t, bus.Publish(ctx, event))
+	bus.Close()
+	assert.Equal(t, NotificationCreatedEvent, forwarded.subject)
+}
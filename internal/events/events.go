@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a domain event published on the event bus
+type Event interface {
+	// EventName returns the name consumers subscribe to
+	EventName() string
+
+	// EventKey returns the key events are ordered by, e.g. the user they belong to
+	EventKey() string
+}
+
+// Publisher publishes domain events
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Handler consumes a domain event
+type Handler func(ctx context.Context, event Event) error
+
+// NopPublisher discards all events; it is the default until a bus is configured
+type NopPublisher struct{}
+
+// Publish discards the event
+func (NopPublisher) Publish(ctx context.Context, event Event) error {
+	return nil
+}
+
+// Event names
+const (
+	UserRegisteredEvent          = "user.registered"
+	PlantWateredEvent            = "plant.watered"
+	PlantLifecycleEvent          = "plant.lifecycle"
+	NotificationCreatedEvent     = "notification.created"
+	RecommendationGeneratedEvent = "recommendation.generated"
+	InventoryChangedEvent        = "shop.inventory_changed"
+)
+
+// UserRegistered is published after a new user account is created
+type UserRegistered struct {
+	UserID     uuid.UUID `json:"userId"`
+	Email      string    `json:"email"`
+	Language   string    `json:"language"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// EventName returns the name of the event
+func (e UserRegistered) EventName() string { return UserRegisteredEvent }
+
+// EventKey returns the user ID
+func (e UserRegistered) EventKey() string { return e.UserID.String() }
+
+// PlantWatered is published after a user marks a plant as watered
+type PlantWatered struct {
+	UserID       uuid.UUID  `json:"userId"`
+	PlantID      uuid.UUID  `json:"plantId"`
+	WateredAt    time.Time  `json:"wateredAt"`
+	NextWatering *time.Time `json:"nextWatering,omitempty"`
+}
+
+// EventName returns the name of the event
+func (e PlantWatered) EventName() string { return PlantWateredEvent }
+
+// EventKey returns the user ID
+func (e PlantWatered) EventKey() string { return e.UserID.String() }
+
+// PlantLifecycle is published after a lifecycle event is appended to a plant's event stream. It
+// carries the stored event as is, so payloads sent to external automation match what the events
+// endpoint returns.
+type PlantLifecycle struct {
+	ID         uuid.UUID              `json:"id"`
+	UserID     uuid.UUID              `json:"userId"`
+	PlantID    uuid.UUID              `json:"plantId"`
+	Type       string                 `json:"type"`
+	Payload    map[string]interface{} `json:"payload"`
+	OccurredAt time.Time              `json:"occurredAt"`
+}
+
+// EventName returns the name of the event
+func (e PlantLifecycle) EventName() string { return PlantLifecycleEvent }
+
+// EventKey returns the user ID
+func (e PlantLifecycle) EventKey() string { return e.UserID.String() }
+
+// NotificationCreated is published after a notification is stored for a user
+type NotificationCreated struct {
+	UserID     uuid.UUID              `json:"userId"`
+	PlantID    *uuid.UUID             `json:"plantId,omitempty"`
+	Type       string                 `json:"type"`
+	Message    string                 `json:"message"`
+	Payload    map[string]interface{} `json:"payload"`
+	OccurredAt time.Time              `json:"occurredAt"`
+}
+
+// EventName returns the name of the event
+func (e NotificationCreated) EventName() string { return NotificationCreatedEvent }
+
+// EventKey returns the user ID
+func (e NotificationCreated) EventKey() string { return e.UserID.String() }
+
+// RecommendationGenerated is published after recommendations are generated for a questionnaire
+type RecommendationGenerated struct {
+	QuestionnaireID uuid.UUID   `json:"questionnaireId"`
+	UserID          *uuid.UUID  `json:"userId,omitempty"`
+	PlantIDs        []uuid.UUID `json:"plantIds"`
+	OccurredAt      time.Time   `json:"occurredAt"`
+}
+
+// EventName returns the name of the event
+func (e RecommendationGenerated) EventName() string { return RecommendationGeneratedEvent }
+
+// EventKey returns the questionnaire ID
+func (e RecommendationGenerated) EventKey() string { return e.QuestionnaireID.String() }
+
+// InventoryChanged is published after the stock of a plant at a shop changes, whether an admin updated
+// the shop's plant or inventory sync reported it
+type InventoryChanged struct {
+	ShopID     uuid.UUID `json:"shopId"`
+	PlantID    uuid.UUID `json:"plantId"`
+	InStock    bool      `json:"inStock"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// EventName returns the name of the event
+func (e InventoryChanged) EventName() string { return InventoryChangedEvent }
+
+// EventKey returns the shop ID
+func (e InventoryChanged) EventKey() string { return e.ShopID.String() }
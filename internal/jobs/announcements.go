@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/services"
+)
+
+// AnnouncementJob sends the announcements of admins to their recipients in batches
+type AnnouncementJob struct {
+	announcementService *services.AnnouncementService
+	interval            time.Duration
+	stopChan            chan struct{}
+}
+
+// NewAnnouncementJob creates a new announcement job
+func NewAnnouncementJob(announcementService *services.AnnouncementService, interval time.Duration) *AnnouncementJob {
+	return &AnnouncementJob{
+		announcementService: announcementService,
+		interval:            interval,
+		stopChan:            make(chan struct{}),
+	}
+}
+
+// Start starts the announcement job
+func (j *AnnouncementJob) Start() {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				j.sendAnnouncements()
+			case <-j.stopChan:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the announcement job
+func (j *AnnouncementJob) Stop() {
+	close(j.stopChan)
+}
+
+// sendAnnouncements sends the announcements in progress
+func (j *AnnouncementJob) sendAnnouncements() {
+	sent, err := j.announcementService.SendPending(context.Background())
+	if err != nil {
+		log.Printf("Error sending announcements: %v", err)
+	}
+	if sent > 0 {
+		log.Printf("Announcement notifications sent: %d", sent)
+	}
+}
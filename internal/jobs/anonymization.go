@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/services"
+)
+
+// AnonymizationJob warns the owners of dormant accounts and anonymizes the accounts that stay inactive once a day
+type AnonymizationJob struct {
+	anonymizationService *services.AnonymizationService
+	hour                 int // local hour of day the job runs at
+	stopChan             chan struct{}
+}
+
+// NewAnonymizationJob creates a new anonymization job running daily at the given local hour
+func NewAnonymizationJob(anonymizationService *services.AnonymizationService, hour int) *AnonymizationJob {
+	return &AnonymizationJob{
+		anonymizationService: anonymizationService,
+		hour:                 hour,
+		stopChan:             make(chan struct{}),
+	}
+}
+
+// Start starts the anonymization job
+func (j *AnonymizationJob) Start() {
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextDailyRun(time.Now(), j.hour)))
+			select {
+			case <-timer.C:
+				j.anonymize()
+			case <-j.stopChan:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the anonymization job
+func (j *AnonymizationJob) Stop() {
+	close(j.stopChan)
+}
+
+// anonymize runs the anonymization and logs its report
+func (j *AnonymizationJob) anonymize() {
+	log.Println("Starting anonymization of inactive accounts...")
+
+	run, err := j.anonymizationService.Run(context.Background(), false)
+	if err != nil {
+		log.Printf("Error during anonymization: %v", err)
+	}
+	if run != nil {
+		log.Printf("Anonymization completed: warned: %d, anonymized: %d", run.Warned, run.Anonymized)
+	}
+}
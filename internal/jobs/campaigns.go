@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/services"
+)
+
+// CampaignJob sends the campaign steps that are due, such as the welcome sequence of new users
+type CampaignJob struct {
+	campaignService *services.CampaignService
+	interval        time.Duration
+	stopChan        chan struct{}
+}
+
+// NewCampaignJob creates a new campaign job
+func NewCampaignJob(campaignService *services.CampaignService, interval time.Duration) *CampaignJob {
+	return &CampaignJob{
+		campaignService: campaignService,
+		interval:        interval,
+		stopChan:        make(chan struct{}),
+	}
+}
+
+// Start starts the campaign job
+func (j *CampaignJob) Start() {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				j.sendSteps()
+			case <-j.stopChan:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the campaign job
+func (j *CampaignJob) Stop() {
+	close(j.stopChan)
+}
+
+// sendSteps sends the campaign steps that are due
+func (j *CampaignJob) sendSteps() {
+	sent, err := j.campaignService.SendDueSteps(context.Background())
+	if err != nil {
+		log.Printf("Error sending campaign steps: %v", err)
+	}
+	if sent > 0 {
+		log.Printf("Campaign steps sent: %d", sent)
+	}
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/services"
+)
+
+// CareCalibrationJob asks owners about plant difficulty and recalibrates the community difficulty
+type CareCalibrationJob struct {
+	careFeedbackService *services.CareFeedbackService
+	interval            time.Duration
+	stopChan            chan struct{}
+}
+
+// NewCareCalibrationJob creates a new care calibration job
+func NewCareCalibrationJob(careFeedbackService *services.CareFeedbackService, interval time.Duration) *CareCalibrationJob {
+	return &CareCalibrationJob{
+		careFeedbackService: careFeedbackService,
+		interval:            interval,
+		stopChan:            make(chan struct{}),
+	}
+}
+
+// Start starts the care calibration job
+func (j *CareCalibrationJob) Start() {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				j.calibrate()
+			case <-j.stopChan:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the care calibration job
+func (j *CareCalibrationJob) Stop() {
+	close(j.stopChan)
+}
+
+// calibrate requests feedback from new candidates and recalibrates the community difficulty
+func (j *CareCalibrationJob) calibrate() {
+	ctx := context.Background()
+
+	requested, err := j.careFeedbackService.RequestFeedback(ctx)
+	if err != nil {
+		log.Printf("Error requesting care feedback: %v", err)
+	}
+
+	recalibrated, err := j.careFeedbackService.Recalibrate(ctx)
+	if err != nil {
+		log.Printf("Error recalibrating plant difficulty: %v", err)
+		return
+	}
+
+	log.Printf(
+		"Care calibration completed: feedback requests sent: %d, plants recalibrated: %d",
+		requested,
+		recalibrated,
+	)
+}
@@ -0,0 +1,136 @@
+package jobs
+
+import (
+    "context"
+    "log"
+    "sync"
+    "time"
+
+    "github.com/anpanovv/planter/internal/services"
+)
+
+// CareNotificationsJob handles checking and creating watering and other care task notifications
+type CareNotificationsJob struct {
+    notificationService *services.NotificationService
+    interval           time.Duration
+    dryRun             bool // checks write nothing and only log what they would have done
+    stopChan           chan struct{}
+    stopOnce           sync.Once
+    done               chan struct{}
+    // ctx is passed to checks and cancelled when a shutdown runs out of time
+    ctx                context.Context
+    cancel             context.CancelFunc
+}
+
+// NewCareNotificationsJob creates a new care notifications job
+func NewCareNotificationsJob(notificationService *services.NotificationService, interval time.Duration) *CareNotificationsJob {
+    ctx, cancel := context.WithCancel(context.Background())
+    return &CareNotificationsJob{
+        notificationService: notificationService,
+        interval:           interval,
+        stopChan:           make(chan struct{}),
+        done:               make(chan struct{}),
+        ctx:                ctx,
+        cancel:             cancel,
+    }
+}
+
+// SetDryRun makes the checks of the job write nothing and log what they would have created and sent
+func (j *CareNotificationsJob) SetDryRun(dryRun bool) {
+    j.dryRun = dryRun
+}
+
+// Start starts the care notifications job
+func (j *CareNotificationsJob) Start() {
+    ticker := time.NewTicker(j.interval)
+    go func() {
+        defer close(j.done)
+        for {
+            select {
+            case <-ticker.C:
+                if err := j.checkAndCreateNotifications(); err != nil {
+                    log.Printf("Error checking care notifications: %v", err)
+                }
+            case <-j.stopChan:
+                ticker.Stop()
+                return
+            }
+        }
+    }()
+}
+
+// Stop stops the care notifications job without waiting for a running check
+func (j *CareNotificationsJob) Stop() {
+    j.stopOnce.Do(func() {
+        close(j.stopChan)
+    })
+}
+
+// Shutdown stops the care notifications job and waits for a running check to finish. If the
+// context ends first, the check is cancelled and the context error is returned.
+func (j *CareNotificationsJob) Shutdown(ctx context.Context) error {
+    j.Stop()
+    select {
+    case <-j.done:
+        return nil
+    case <-ctx.Done():
+        j.cancel()
+        return ctx.Err()
+    }
+}
+
+// checkAndCreateNotifications checks for plants that need watering or other care and creates notifications
+func (j *CareNotificationsJob) checkAndCreateNotifications() error {
+	if j.dryRun {
+		return j.dryRunNotifications()
+	}
+
+	log.Println("Starting care notifications check...")
+	
+	stats, err := j.notificationService.CheckAndCreateCareNotifications(j.ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf(
+		"Care notifications check completed: "+
+			"users processed: %d, "+
+			"plants needing water: %d, "+
+			"care tasks due: %d, "+
+			"notifications created: %d, "+
+			"emails sent: %d",
+		stats.UsersProcessed,
+		stats.PlantsNeedingWater,
+		stats.CareTasksDue,
+		stats.NotificationsCreated,
+		stats.EmailsSent,
+	)
+
+	return nil
+}
+// dryRunNotifications runs the check without writing and logs the notifications it would have created
+func (j *CareNotificationsJob) dryRunNotifications() error {
+	stats, err := j.notificationService.DryRunCareNotifications(j.ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf(
+		"Care notifications dry run completed: "+
+			"users processed: %d, "+
+			"plants needing water: %d, "+
+			"care tasks due: %d, "+
+			"notifications that would be created: %d, "+
+			"emails that would be sent: %d",
+		stats.UsersProcessed,
+		stats.PlantsNeedingWater,
+		stats.CareTasksDue,
+		stats.NotificationsCreated,
+		stats.EmailsSent,
+	)
+	for _, notification := range stats.Sample {
+		log.Printf("Dry run would notify user %s (%s): %s", notification.UserID, notification.Type, notification.Message)
+	}
+
+	return nil
+}
@@ -15,18 +15,18 @@ type MockNotificationService struct {
     mock.Mock
 }
 
-func (m *MockNotificationService) CheckAndCreateWateringNotifications(ctx context.Context) error {
+func (m *MockNotificationService) CheckAndCreateCareNotifications(ctx context.Context) error {
     args := m.Called(ctx)
     return args.Error(0)
 }
 
-func TestWateringNotificationsJob_Start(t *testing.T) {
+func TestCareNotificationsJob_Start(t *testing.T) {
     // Create mock service
     mockService := new(MockNotificationService)
-    mockService.On("CheckAndCreateWateringNotifications", mock.Anything).Return(nil)
+    mockService.On("CheckAndCreateCareNotifications", mock.Anything).Return(nil)
 
     // Create job with short interval for testing
-    job := NewWateringNotificationsJob(mockService, 100*time.Millisecond)
+    job := NewCareNotificationsJob(mockService, 100*time.Millisecond)
 
     // Start job
     job.Start()
@@ -38,31 +38,31 @@ func TestWateringNotificationsJob_Start(t *testing.T) {
     job.Stop()
 
     // Assert that the service was called at least once
-    mockService.AssertNumberOfCalls(t, "CheckAndCreateWateringNotifications", 1)
+    mockService.AssertNumberOfCalls(t, "CheckAndCreateCareNotifications", 1)
 }
 
-func TestWateringNotificationsJob_Stop(t *testing.T) {
+func TestCareNotificationsJob_Stop(t *testing.T) {
     // Create mock service
     mockService := new(MockNotificationService)
 
     // Create job
-    job := NewWateringNotificationsJob(mockService, time.Hour)
+    job := NewCareNotificationsJob(mockService, time.Hour)
 
     // Start and immediately stop
     job.Start()
     job.Stop()
 
     // Assert that the service was not called
-    mockService.AssertNotCalled(t, "CheckAndCreateWateringNotifications")
+    mockService.AssertNotCalled(t, "CheckAndCreateCareNotifications")
 }
 
-func TestWateringNotificationsJob_CheckAndCreateNotifications(t *testing.T) {
+func TestCareNotificationsJob_CheckAndCreateNotifications(t *testing.T) {
     // Create mock service
     mockService := new(MockNotificationService)
-    mockService.On("CheckAndCreateWateringNotifications", mock.Anything).Return(nil)
+    mockService.On("CheckAndCreateCareNotifications", mock.Anything).Return(nil)
 
     // Create job
-    job := NewWateringNotificationsJob(mockService, time.Hour)
+    job := NewCareNotificationsJob(mockService, time.Hour)
 
     // Call check directly
     err := job.checkAndCreateNotifications()
@@ -72,14 +72,14 @@ func TestWateringNotificationsJob_CheckAndCreateNotifications(t *testing.T) {
     mockService.AssertExpectations(t)
 }
 
-func TestWateringNotificationsJob_CheckAndCreateNotifications_Error(t *testing.T) {
+func TestCareNotificationsJob_CheckAndCreateNotifications_Error(t *testing.T) {
     // Create mock service with error
     mockService := new(MockNotificationService)
     expectedError := assert.AnError
-    mockService.On("CheckAndCreateWateringNotifications", mock.Anything).Return(expectedError)
+    mockService.On("CheckAndCreateCareNotifications", mock.Anything).Return(expectedError)
 
     // Create job
-    job := NewWateringNotificationsJob(mockService, time.Hour)
+    job := NewCareNotificationsJob(mockService, time.Hour)
 
     // Call check directly
     err := job.checkAndCreateNotifications()
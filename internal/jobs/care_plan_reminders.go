@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/services"
+)
+
+// CarePlanReminderJob notifies owners when a repotting, fertilizing or dormancy window of a care plan begins
+type CarePlanReminderJob struct {
+	carePlanService *services.CarePlanService
+	interval        time.Duration
+	stopChan        chan struct{}
+}
+
+// NewCarePlanReminderJob creates a new care plan reminder job
+func NewCarePlanReminderJob(carePlanService *services.CarePlanService, interval time.Duration) *CarePlanReminderJob {
+	return &CarePlanReminderJob{
+		carePlanService: carePlanService,
+		interval:        interval,
+		stopChan:        make(chan struct{}),
+	}
+}
+
+// Start starts the care plan reminder job
+func (j *CarePlanReminderJob) Start() {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				j.sendReminders()
+			case <-j.stopChan:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the care plan reminder job
+func (j *CarePlanReminderJob) Stop() {
+	close(j.stopChan)
+}
+
+// sendReminders sends the reminders of care plan windows that have begun
+func (j *CarePlanReminderJob) sendReminders() {
+	sent, err := j.carePlanService.SendReminders(context.Background())
+	if err != nil {
+		log.Printf("Error sending care plan reminders: %v", err)
+	}
+	if sent > 0 {
+		log.Printf("Care plan reminders sent: %d", sent)
+	}
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/services"
+)
+
+// PlantStatsJob aggregates the ownership statistics of catalog plants once a day
+type PlantStatsJob struct {
+	plantStatsService *services.PlantStatsService
+	hour              int // local hour of day the job runs at
+	stopChan          chan struct{}
+}
+
+// NewPlantStatsJob creates a new plant stats job running daily at the given local hour
+func NewPlantStatsJob(plantStatsService *services.PlantStatsService, hour int) *PlantStatsJob {
+	return &PlantStatsJob{
+		plantStatsService: plantStatsService,
+		hour:              hour,
+		stopChan:          make(chan struct{}),
+	}
+}
+
+// Start starts the plant stats job
+func (j *PlantStatsJob) Start() {
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextDailyRun(time.Now(), j.hour)))
+			select {
+			case <-timer.C:
+				j.aggregate()
+			case <-j.stopChan:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the plant stats job
+func (j *PlantStatsJob) Stop() {
+	close(j.stopChan)
+}
+
+// aggregate refreshes the plant stats and logs how many plants were aggregated
+func (j *PlantStatsJob) aggregate() {
+	aggregated, err := j.plantStatsService.Refresh(context.Background())
+	if err != nil {
+		log.Printf("Error aggregating plant stats: %v", err)
+		return
+	}
+	log.Printf("Plant stats aggregation completed: plants aggregated: %d", aggregated)
+}
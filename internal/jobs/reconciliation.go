@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/services"
+)
+
+// ReconciliationJob repairs inconsistent derived data once a day
+type ReconciliationJob struct {
+	reconciliationService *services.ReconciliationService
+	hour                  int // local hour of day the job runs at
+	stopChan              chan struct{}
+}
+
+// NewReconciliationJob creates a new reconciliation job running daily at the given local hour
+func NewReconciliationJob(reconciliationService *services.ReconciliationService, hour int) *ReconciliationJob {
+	return &ReconciliationJob{
+		reconciliationService: reconciliationService,
+		hour:                  hour,
+		stopChan:              make(chan struct{}),
+	}
+}
+
+// Start starts the reconciliation job
+func (j *ReconciliationJob) Start() {
+	go func() {
+		for {
+			timer := time.NewTimer(time.Until(nextDailyRun(time.Now(), j.hour)))
+			select {
+			case <-timer.C:
+				j.reconcile()
+			case <-j.stopChan:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the reconciliation job
+func (j *ReconciliationJob) Stop() {
+	close(j.stopChan)
+}
+
+// reconcile runs the reconciliation and logs its report
+func (j *ReconciliationJob) reconcile() {
+	log.Println("Starting reconciliation...")
+
+	run, err := j.reconciliationService.Reconcile(context.Background(), false)
+	if err != nil {
+		log.Printf("Error during reconciliation: %v", err)
+	}
+	if run != nil {
+		log.Printf("Reconciliation completed: corrections made: %d", run.TotalCorrections)
+	}
+}
+
+// nextDailyRun returns the next time after now at the given local hour
+func nextDailyRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
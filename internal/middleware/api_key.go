@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyIDKey is the key for the API key ID in the request context
+const APIKeyIDKey contextKey = "apiKeyID"
+
+// APIKeyHeader is the header carrying the public API key
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyValidator validates public API keys and records their usage
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, rawKey string) (uuid.UUID, error)
+	RecordAPIKeyUsage(ctx context.Context, keyID uuid.UUID) error
+}
+
+// APIKeyAuth is the public API key authentication middleware
+type APIKeyAuth struct {
+	validator APIKeyValidator
+	limiter   Limiter
+}
+
+// NewAPIKeyAuth creates a new APIKeyAuth middleware
+func NewAPIKeyAuth(validator APIKeyValidator, limiter Limiter) *APIKeyAuth {
+	return &APIKeyAuth{
+		validator: validator,
+		limiter:   limiter,
+	}
+}
+
+// RequireAPIKey is a middleware that requires a valid API key and applies the per-key rate limit
+func (a *APIKeyAuth) RequireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Get the API key header
+		rawKey := r.Header.Get(APIKeyHeader)
+		if rawKey == "" {
+			http.Error(w, APIKeyHeader+" header is required", http.StatusUnauthorized)
+			return
+		}
+
+		// Validate the key
+		keyID, err := a.validator.ValidateAPIKey(r.Context(), rawKey)
+		if err != nil {
+			http.Error(w, "Invalid or revoked API key", http.StatusUnauthorized)
+			return
+		}
+
+		// Apply the rate limit
+		if allowed, retryAfter := a.limiter.Allow(keyID.String()); !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		// Record the usage; failures must not block the request
+		if err := a.validator.RecordAPIKeyUsage(r.Context(), keyID); err != nil {
+			log.Printf("Failed to record usage for API key %s: %v", keyID, err)
+		}
+
+		// Add the API key ID to the request context
+		ctx := context.WithValue(r.Context(), APIKeyIDKey, keyID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetAPIKeyID gets the API key ID from the request context
+func GetAPIKeyID(ctx context.Context) (uuid.UUID, error) {
+	keyID, ok := ctx.Value(APIKeyIDKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, errors.New("API key ID not found in context")
+	}
+	return keyID, nil
+}
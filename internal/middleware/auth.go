@@ -23,9 +23,15 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
+// ActivityRecorder records that an authenticated user is active
+type ActivityRecorder interface {
+	RecordActivity(ctx context.Context, userID uuid.UUID)
+}
+
 // Auth is the authentication middleware
 type Auth struct {
-	jwtSecret string
+	jwtSecret        string
+	activityRecorder ActivityRecorder
 }
 
 // NewAuth creates a new Auth middleware
@@ -35,6 +41,11 @@ func NewAuth(jwtSecret string) *Auth {
 	}
 }
 
+// SetActivityRecorder sets the recorder authenticated requests are reported to
+func (a *Auth) SetActivityRecorder(recorder ActivityRecorder) {
+	a.activityRecorder = recorder
+}
+
 // Middleware authenticates the request
 func (a *Auth) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -60,6 +71,13 @@ func (a *Auth) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Report the activity so dormant accounts are told apart from active ones
+		if a.activityRecorder != nil {
+			if userID, err := uuid.Parse(claims.UserID); err == nil {
+				a.activityRecorder.RecordActivity(r.Context(), userID)
+			}
+		}
+
 		// Add the user ID to the request context
 		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -126,6 +144,26 @@ func (a *Auth) RequireAuth(next http.Handler) http.Handler {
 	return a.Middleware(next)
 }
 
+// AccessTokenParam is the query parameter WebSocket clients send their JWT in, since browsers cannot
+// set headers on the handshake
+const AccessTokenParam = "access_token"
+
+// RequireAuthOrQueryToken is a middleware that requires authentication like RequireAuth, also
+// accepting the token in the access_token query parameter when the Authorization header is missing
+func (a *Auth) RequireAuthOrQueryToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if token := query.Get(AccessTokenParam); token != "" && r.Header.Get("Authorization") == "" {
+			// Move the token to the header so it does not travel further in the URL
+			r = r.Clone(r.Context())
+			r.Header.Set("Authorization", "Bearer "+token)
+			query.Del(AccessTokenParam)
+			r.URL.RawQuery = query.Encode()
+		}
+		a.Middleware(next).ServeHTTP(w, r)
+	})
+}
+
 // OptionalAuth is a middleware that makes authentication optional
 func (a *Auth) OptionalAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// activityRecorderFunc adapts a function to the ActivityRecorder interface
+type activityRecorderFunc func(ctx context.Context, userID uuid.UUID)
+
+func (f activityRecorderFunc) RecordActivity(ctx context.Context, userID uuid.UUID) {
+	f(ctx, userID)
+}
+
+// TestAuth_Middleware_RecordsActivity tests that only authenticated requests are reported as activity
+func TestAuth_Middleware_RecordsActivity(t *testing.T) {
+	auth := NewAuth("test-secret")
+	var active []uuid.UUID
+	auth.SetActivityRecorder(activityRecorderFunc(func(ctx context.Context, userID uuid.UUID) {
+		active = append(active, userID)
+	}))
+	handler := auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	userID := uuid.New()
+	token, err := auth.GenerateToken(userID, time.Hour)
+	assert.NoError(t, err)
+
+	for _, header := range []string{"Bearer " + token, "Bearer invalid", ""} {
+		req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	assert.Equal(t, []uuid.UUID{userID}, active)
+}
+
+// TestAuth_RequireAuthOrQueryToken tests authenticating with the token in the query, which is then
+// removed from the URL, and that requests without a token are rejected
+func TestAuth_RequireAuthOrQueryToken(t *testing.T) {
+	auth := NewAuth("test-secret")
+	var gotUserID uuid.UUID
+	var gotQuery string
+	handler := auth.RequireAuthOrQueryToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserID, _ = GetUserID(r.Context())
+		gotQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	userID := uuid.New()
+	token, err := auth.GenerateToken(userID, time.Hour)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/ws/notifications?access_token="+token+"&v=2", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, userID, gotUserID)
+	assert.Equal(t, "v=2", gotQuery)
+
+	req = httptest.NewRequest(http.MethodGet, "/ws/notifications", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
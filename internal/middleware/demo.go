@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// DemoModeKey is the key marking requests made by the shared demo account in the request context
+const DemoModeKey contextKey = "demoMode"
+
+// DemoModeHeader is set on responses to requests made by the demo account
+const DemoModeHeader = "X-Demo-Mode"
+
+// DemoAccountChecker identifies the shared demo account
+type DemoAccountChecker interface {
+	IsDemoUser(ctx context.Context, userID uuid.UUID) bool
+}
+
+// DemoMode marks requests authenticated as the demo account
+type DemoMode struct {
+	auth    *Auth
+	checker DemoAccountChecker
+}
+
+// NewDemoMode creates a new DemoMode middleware
+func NewDemoMode(auth *Auth, checker DemoAccountChecker) *DemoMode {
+	return &DemoMode{
+		auth:    auth,
+		checker: checker,
+	}
+}
+
+// Middleware marks requests made with a demo account JWT; all other requests pass through unchanged
+func (d *DemoMode) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Get the bearer token
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Personal access tokens and invalid tokens are left to the route's own authentication
+		claims, err := d.auth.parseToken(token)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		userID, err := uuid.Parse(claims.UserID)
+		if err != nil || !d.checker.IsDemoUser(r.Context(), userID) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Mark the request and the response
+		w.Header().Set(DemoModeHeader, "true")
+		ctx := context.WithValue(r.Context(), DemoModeKey, true)
+		ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// IsDemoRequest checks if the request was made by the demo account
+func IsDemoRequest(ctx context.Context) bool {
+	demo, _ := ctx.Value(DemoModeKey).(bool)
+	return demo
+}
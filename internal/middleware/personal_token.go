@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PersonalTokenValidator validates personal access tokens
+type PersonalTokenValidator interface {
+	ValidatePersonalToken(ctx context.Context, rawToken string) (uuid.UUID, []string, error)
+}
+
+// TokenAuth authenticates requests made either with a user's JWT or with a scoped personal access token
+type TokenAuth struct {
+	auth        *Auth
+	validator   PersonalTokenValidator
+	tokenPrefix string
+}
+
+// NewTokenAuth creates a new TokenAuth middleware; bearer tokens starting with tokenPrefix are
+// treated as personal access tokens and everything else as a JWT
+func NewTokenAuth(auth *Auth, validator PersonalTokenValidator, tokenPrefix string) *TokenAuth {
+	return &TokenAuth{
+		auth:        auth,
+		validator:   validator,
+		tokenPrefix: tokenPrefix,
+	}
+}
+
+// RequireScope is a middleware that requires a JWT or a personal access token granted the given scope
+func (a *TokenAuth) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		jwtHandler := a.auth.Middleware(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// JWT sessions have full access to the user's account
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !strings.HasPrefix(token, a.tokenPrefix) {
+				jwtHandler.ServeHTTP(w, r)
+				return
+			}
+
+			// Validate the personal access token
+			userID, scopes, err := a.validator.ValidatePersonalToken(r.Context(), token)
+			if err != nil {
+				http.Error(w, "Invalid, expired or revoked token", http.StatusUnauthorized)
+				return
+			}
+
+			// Check the scope
+			if !hasScope(scopes, scope) {
+				http.Error(w, "Token is missing the "+scope+" scope", http.StatusForbidden)
+				return
+			}
+
+			// Add the user ID to the request context
+			ctx := context.WithValue(r.Context(), UserIDKey, userID.String())
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// hasScope checks if a scope is in the list of granted scopes
+func hasScope(scopes []string, scope string) bool {
+	for _, granted := range scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
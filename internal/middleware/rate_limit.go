@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/redis"
+)
+
+// Limiter limits the number of requests per key in a window
+type Limiter interface {
+	// Allow records a request for the key and reports whether it is allowed.
+	// When the request is rejected, the time until the window resets is returned.
+	Allow(key string) (bool, time.Duration)
+
+	// Limit returns the number of requests allowed per window
+	Limit() int
+
+	// Window returns the length of the rate limit window
+	Window() time.Duration
+}
+
+// RateLimiter is a fixed-window in-memory rate limiter keyed by an arbitrary string
+type RateLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	counters map[string]*rateWindow
+}
+
+// rateWindow holds the request count of a key in the current window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// NewRateLimiter creates a new rate limiter allowing limit requests per window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:    limit,
+		window:   window,
+		counters: make(map[string]*rateWindow),
+	}
+}
+
+// Allow records a request for the key and reports whether it is allowed.
+// When the request is rejected, the time until the window resets is returned.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	w, ok := l.counters[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.counters[key] = w
+	}
+
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+
+	w.count++
+	return true, 0
+}
+
+// Limit returns the number of requests allowed per window
+func (l *RateLimiter) Limit() int {
+	return l.limit
+}
+
+// Window returns the length of the rate limit window
+func (l *RateLimiter) Window() time.Duration {
+	return l.window
+}
+
+// redisRateLimitTimeout bounds the Redis round trips of a rate limit check
+const redisRateLimitTimeout = time.Second
+
+// RedisRateLimiter is a fixed-window rate limiter keyed by an arbitrary string whose counters are
+// kept in Redis, so every instance of the API enforces the same limit
+type RedisRateLimiter struct {
+	client *redis.Client
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+// NewRedisRateLimiter creates a new rate limiter allowing limit requests per window. Counters
+// are stored under keys starting with prefix.
+func NewRedisRateLimiter(client *redis.Client, prefix string, limit int, window time.Duration) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client: client,
+		prefix: prefix,
+		limit:  limit,
+		window: window,
+	}
+}
+
+// Allow records a request for the key and reports whether it is allowed. When Redis is
+// unavailable the request is allowed, so an outage does not take the API down with it.
+func (l *RedisRateLimiter) Allow(key string) (bool, time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisRateLimitTimeout)
+	defer cancel()
+
+	count, ttl, err := l.client.IncrWindow(ctx, l.prefix+key, l.window)
+	if err != nil {
+		log.Printf("Failed to check rate limit of %s: %v", key, err)
+		return true, 0
+	}
+
+	if count > int64(l.limit) {
+		return false, ttl
+	}
+	return true, 0
+}
+
+// Limit returns the number of requests allowed per window
+func (l *RedisRateLimiter) Limit() int {
+	return l.limit
+}
+
+// Window returns the length of the rate limit window
+func (l *RedisRateLimiter) Window() time.Duration {
+	return l.window
+}
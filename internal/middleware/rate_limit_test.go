@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/redis"
+	"github.com/anpanovv/planter/internal/redis/redistest"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRedisRateLimiter_Allow tests that instances sharing Redis share the limit of a key
+func TestRedisRateLimiter_Allow(t *testing.T) {
+	server := redistest.NewServer(t)
+	first := NewRedisRateLimiter(redis.New(redis.Options{Addr: server.Addr()}), "ratelimit:", 3, time.Minute)
+	second := NewRedisRateLimiter(redis.New(redis.Options{Addr: server.Addr()}), "ratelimit:", 3, time.Minute)
+
+	for _, limiter := range []*RedisRateLimiter{first, second, first} {
+		allowed, _ := limiter.Allow("key")
+		assert.True(t, allowed)
+	}
+	allowed, retryAfter := second.Allow("key")
+	assert.False(t, allowed)
+	assert.InDelta(t, time.Minute, retryAfter, float64(time.Second))
+
+	// Other keys and the next window are not affected
+	allowed, _ = first.Allow("other")
+	assert.True(t, allowed)
+	server.Advance(time.Minute)
+	allowed, _ = first.Allow("key")
+	assert.True(t, allowed)
+}
+
+// TestRedisRateLimiter_Allow_Unavailable tests that requests are allowed while Redis is down
+func TestRedisRateLimiter_Allow_Unavailable(t *testing.T) {
+	server := redistest.NewServer(t)
+	server.Close()
+	limiter := NewRedisRateLimiter(redis.New(redis.Options{Addr: server.Addr(), DialTimeout: 100 * time.Millisecond}), "ratelimit:", 1, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := limiter.Allow("key")
+		assert.True(t, allowed)
+	}
+}
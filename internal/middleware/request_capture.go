@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FailedRequest is a request answered with a server error, as it was received
+type FailedRequest struct {
+	Method        string
+	Path          string
+	Query         string
+	Header        http.Header
+	Body          []byte
+	BodyTruncated bool       // the body was longer than the capture limit
+	UserID        *uuid.UUID // the user of a JWT session
+	Status        int
+	Duration      time.Duration
+}
+
+// FailedRequestRecorder records requests answered with a server error
+type FailedRequestRecorder interface {
+	// MaxCapturedBodyBytes is the number of bytes of a request body kept for the recording
+	MaxCapturedBodyBytes() int
+
+	// RecordFailedRequest records a failed request; it is called after the response was sent
+	RecordFailedRequest(request FailedRequest)
+}
+
+// RequestCapture is the middleware recording the requests answered with a server error so they can be
+// replayed to reproduce the failure
+type RequestCapture struct {
+	auth     *Auth
+	recorder FailedRequestRecorder
+}
+
+// NewRequestCapture creates a new request capture middleware
+func NewRequestCapture(auth *Auth, recorder FailedRequestRecorder) *RequestCapture {
+	return &RequestCapture{
+		auth:     auth,
+		recorder: recorder,
+	}
+}
+
+// Middleware records the request when it is answered with a server error or the handler panics
+func (c *RequestCapture) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Keep the start of the body and hand the handler the whole body as it was received
+		var body []byte
+		truncated := false
+		if r.Body != nil && r.Body != http.NoBody {
+			limit := c.recorder.MaxCapturedBodyBytes()
+			read, _ := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+			r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(read), r.Body), Closer: r.Body}
+			body = read
+			if len(body) > limit {
+				body = body[:limit]
+				truncated = true
+			}
+		}
+
+		// Clone what the handler may change before it runs
+		failed := FailedRequest{
+			Method:        r.Method,
+			Path:          r.URL.Path,
+			Query:         r.URL.RawQuery,
+			Header:        r.Header.Clone(),
+			Body:          body,
+			BodyTruncated: truncated,
+			UserID:        c.userID(r),
+		}
+
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			// A panic is answered with a server error, so record it and let the server handle it
+			if p := recover(); p != nil {
+				failed.Status = http.StatusInternalServerError
+				failed.Duration = time.Since(start)
+				go c.recorder.RecordFailedRequest(failed)
+				panic(p)
+			}
+		}()
+
+		next.ServeHTTP(rw, r)
+
+		if rw.status >= http.StatusInternalServerError {
+			failed.Status = rw.status
+			failed.Duration = time.Since(start)
+			go c.recorder.RecordFailedRequest(failed)
+		}
+	})
+}
+
+// userID gets the user of the JWT session the request was sent with, if any
+func (c *RequestCapture) userID(r *http.Request) *uuid.UUID {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil
+	}
+	claims, err := c.auth.parseToken(token)
+	if err != nil {
+		return nil
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil
+	}
+	return &userID
+}
+
+// readCloser reads from a reader and closes a closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
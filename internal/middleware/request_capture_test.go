@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// channelRecorder sends the failed requests recorded through it to a channel
+type channelRecorder struct {
+	maxBodyBytes int
+	recorded     chan FailedRequest
+}
+
+func (c *channelRecorder) MaxCapturedBodyBytes() int {
+	return c.maxBodyBytes
+}
+
+func (c *channelRecorder) RecordFailedRequest(request FailedRequest) {
+	c.recorded <- request
+}
+
+// TestRequestCapture_Middleware tests that requests answered with a server error are recorded with the
+// start of their body and their user, while the handler still reads the whole body
+func TestRequestCapture_Middleware(t *testing.T) {
+	auth := NewAuth("test-secret")
+	recorder := &channelRecorder{maxBodyBytes: 8, recorded: make(chan FailedRequest, 1)}
+	var handlerBody string
+	handler := NewRequestCapture(auth, recorder).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		handlerBody = string(body)
+		if r.URL.Path == "/fail" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	userID := uuid.New()
+	token, err := auth.GenerateToken(userID, time.Hour)
+	assert.NoError(t, err)
+
+	// A client error is not recorded
+	req := httptest.NewRequest(http.MethodPost, "/bad", strings.NewReader("short"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "short", handlerBody)
+
+	// A server error is
+	req = httptest.NewRequest(http.MethodPost, "/fail?lang=en", strings.NewReader("a long request body"))
+	req.Header.Set("Authorization", "Bearer "+token)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "a long request body", handlerBody)
+
+	select {
+	case failed := <-recorder.recorded:
+		assert.Equal(t, http.MethodPost, failed.Method)
+		assert.Equal(t, "/fail", failed.Path)
+		assert.Equal(t, "lang=en", failed.Query)
+		assert.Equal(t, "a long r", string(failed.Body))
+		assert.True(t, failed.BodyTruncated)
+		assert.Equal(t, &userID, failed.UserID)
+		assert.Equal(t, http.StatusInternalServerError, failed.Status)
+	case <-time.After(time.Second):
+		t.Fatal("failed request was not recorded")
+	}
+	assert.Empty(t, recorder.recorded)
+}
+
+// TestRequestCapture_Middleware_Panic tests that a panicking handler is recorded as a server error and
+// the panic is passed on
+func TestRequestCapture_Middleware_Panic(t *testing.T) {
+	recorder := &channelRecorder{maxBodyBytes: 1024, recorded: make(chan FailedRequest, 1)}
+	handler := NewRequestCapture(NewAuth("test-secret"), recorder).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("nil map")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/plants", nil)
+	assert.PanicsWithValue(t, "nil map", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	})
+
+	select {
+	case failed := <-recorder.recorded:
+		assert.Equal(t, http.StatusInternalServerError, failed.Status)
+		assert.Nil(t, failed.UserID)
+	case <-time.After(time.Second):
+		t.Fatal("panicking request was not recorded")
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RoleChecker looks up the roles granted to users
+type RoleChecker interface {
+	HasRole(ctx context.Context, userID uuid.UUID, role string) (bool, error)
+}
+
+// RoleAuth authorizes requests by the roles of the authenticated user. Roles are looked up on every
+// request, so revoking a role takes effect without waiting for tokens to expire.
+type RoleAuth struct {
+	auth    *Auth
+	checker RoleChecker
+}
+
+// NewRoleAuth creates a new RoleAuth middleware
+func NewRoleAuth(auth *Auth, checker RoleChecker) *RoleAuth {
+	return &RoleAuth{
+		auth:    auth,
+		checker: checker,
+	}
+}
+
+// RequireRole is a middleware that requires a JWT of a user who has been granted the role
+func (a *RoleAuth) RequireRole(role string) func(http.Handler) http.Handler {
+	return a.RequireAnyRole(role)
+}
+
+// RequireAnyRole is a middleware that requires a JWT of a user who has been granted one of the roles
+func (a *RoleAuth) RequireAnyRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return a.auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := GetUserID(r.Context())
+			if err != nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			// Check the roles
+			for _, role := range roles {
+				granted, err := a.checker.HasRole(r.Context(), userID, role)
+				if err != nil {
+					log.Printf("Error checking the %s role of user %s: %v", role, userID, err)
+					http.Error(w, "Failed to check permissions", http.StatusInternalServerError)
+					return
+				}
+				if granted {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "The "+strings.Join(roles, " or ")+" role is required", http.StatusForbidden)
+		}))
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// roleCheckerFunc adapts a function to the RoleChecker interface
+type roleCheckerFunc func(ctx context.Context, userID uuid.UUID, role string) (bool, error)
+
+func (f roleCheckerFunc) HasRole(ctx context.Context, userID uuid.UUID, role string) (bool, error) {
+	return f(ctx, userID, role)
+}
+
+// TestRoleAuth_RequireRole tests that only users granted the role reach the handler
+func TestRoleAuth_RequireRole(t *testing.T) {
+	auth := NewAuth("test-secret")
+	adminID, userID := uuid.New(), uuid.New()
+	roleAuth := NewRoleAuth(auth, roleCheckerFunc(func(ctx context.Context, id uuid.UUID, role string) (bool, error) {
+		return id == adminID && role == "admin", nil
+	}))
+	handler := roleAuth.RequireRole("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(userID *uuid.UUID) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/plants", nil)
+		if userID != nil {
+			token, err := auth.GenerateToken(*userID, time.Hour)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request(nil))
+	assert.Equal(t, http.StatusForbidden, request(&userID))
+	assert.Equal(t, http.StatusOK, request(&adminID))
+}
+
+// TestRoleAuth_RequireAnyRole tests that users granted any of the roles reach the handler
+func TestRoleAuth_RequireAnyRole(t *testing.T) {
+	auth := NewAuth("test-secret")
+	adminID, expertID, userID := uuid.New(), uuid.New(), uuid.New()
+	roleAuth := NewRoleAuth(auth, roleCheckerFunc(func(ctx context.Context, id uuid.UUID, role string) (bool, error) {
+		return (id == adminID && role == "admin") || (id == expertID && role == "expert"), nil
+	}))
+	handler := roleAuth.RequireAnyRole("expert", "admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(userID uuid.UUID) *httptest.ResponseRecorder {
+		token, err := auth.GenerateToken(userID, time.Hour)
+		assert.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/expert/escalations", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, request(expertID).Code)
+	assert.Equal(t, http.StatusOK, request(adminID).Code)
+	rec := request(userID)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "The expert or admin role is required")
+}
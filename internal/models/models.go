@@ -1,9 +1,15 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/anpanovv/planter/internal/storage"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // SunlightLevel represents the amount of sunlight a plant needs
@@ -32,22 +38,109 @@ const (
 	LanguageEnglish Language = "ENGLISH"
 )
 
+// AssetKey is the storage key of an image or other asset, or the absolute URL of an asset hosted
+// elsewhere. It is written to JSON as the URL it resolves to and read from JSON as a key.
+type AssetKey string
+
+// URL returns the URL the asset is served from
+func (k AssetKey) URL() string {
+	return storage.Default().URL(string(k))
+}
+
+// MarshalJSON writes the URL the asset is served from
+func (k AssetKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.URL())
+}
+
+// UnmarshalJSON reads the key of an asset from its URL
+func (k *AssetKey) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*k = AssetKeyFromURL(value)
+	return nil
+}
+
+// AssetKeyFromURL returns the key of an asset referenced by its URL; URLs of assets hosted
+// elsewhere are kept as they are
+func AssetKeyFromURL(rawURL string) AssetKey {
+	return AssetKey(storage.Default().Key(rawURL))
+}
+
+// AssetKeys represents a list of asset keys stored as a text array
+type AssetKeys []AssetKey
+
+// Value implements the driver.Valuer interface
+func (k AssetKeys) Value() (driver.Value, error) {
+	if k == nil {
+		return pq.StringArray(nil).Value()
+	}
+	values := make(pq.StringArray, len(k))
+	for i, key := range k {
+		values[i] = string(key)
+	}
+	return values.Value()
+}
+
+// Scan implements the sql.Scanner interface
+func (k *AssetKeys) Scan(value interface{}) error {
+	var values pq.StringArray
+	if err := values.Scan(value); err != nil {
+		return err
+	}
+	if values == nil {
+		*k = nil
+		return nil
+	}
+	*k = make(AssetKeys, len(values))
+	for i, v := range values {
+		(*k)[i] = AssetKey(v)
+	}
+	return nil
+}
+
 // User represents a user in the system
 type User struct {
 	ID                  uuid.UUID `json:"id" db:"id"`
 	Name                string    `json:"name" db:"name"`
 	Email               string    `json:"email" db:"email"`
 	PasswordHash        string    `json:"-" db:"password_hash"`
-	ProfileImageURL     *string   `json:"profileImageUrl,omitempty" db:"profile_image_url"`
+	ProfileImageURL     *AssetKey `json:"profileImageUrl,omitempty" db:"profile_image_url"`
 	Language            Language  `json:"language" db:"language"`
 	NotificationsEnabled bool      `json:"notificationsEnabled" db:"notifications_enabled"`
+	WateringReminderChannel ReminderChannel `json:"wateringReminderChannel" db:"watering_reminder_channel"`
+	LowEffortMode       bool      `json:"lowEffortMode" db:"low_effort_mode"` // Stretches watering of the collection toward what the plants tolerate
+	City                *string   `json:"city,omitempty" db:"city" validate:"omitempty,max=255"` // Announcements can be targeted at the users of a city
+	Timezone            *string   `json:"timezone,omitempty" db:"timezone"` // IANA zone watering reminders follow; nil follows the home of each plant, else UTC
+	NotificationHour    *int      `json:"notificationHour,omitempty" db:"notification_hour"` // Local hour from which watering reminders of the day are sent
 	Locations           []string  `json:"locations,omitempty" db:"-"`
 	FavoritePlantIDs    []string  `json:"favoritePlantIds,omitempty" db:"-"`
 	OwnedPlantIDs       []string  `json:"ownedPlantIds,omitempty" db:"-"`
+	ChatUsage           *ChatUsage `json:"chatUsage,omitempty" db:"-"` // filled when users get their own profile
+	Roles               pq.StringArray `json:"roles" db:"roles"`
 	CreatedAt           time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt           time.Time `json:"updatedAt" db:"updated_at"`
 }
 
+// ReminderChannel is the channel a user gets watering reminders through
+type ReminderChannel string
+
+const (
+	ReminderChannelPush  ReminderChannel = "PUSH"  // an in-app notification per plant
+	ReminderChannelEmail ReminderChannel = "EMAIL" // one email a day listing every plant to water
+)
+
+// Role represents a role granting a user access to restricted routes
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleExpert   Role = "expert"   // answers chat sessions escalated to a human
+	RoleEditor   Role = "editor"   // writes catalog plants as drafts and submits them for review
+	RoleReviewer Role = "reviewer" // publishes catalog plants submitted for review or sends them back
+)
+
 // UserLocation represents a location associated with a user
 type UserLocation struct {
 	ID        uuid.UUID `json:"id" db:"id"`
@@ -66,32 +159,211 @@ type TemperatureRange struct {
 type CareInstructions struct {
 	ID                 uuid.UUID     `json:"id" db:"id"`
 	WateringFrequency  int           `json:"wateringFrequency" db:"watering_frequency"`
+	WateringFrequencyMin *int        `json:"wateringFrequencyMin,omitempty" db:"watering_frequency_min"` // Fewest days between waterings the plant tolerates
+	WateringFrequencyMax *int        `json:"wateringFrequencyMax,omitempty" db:"watering_frequency_max"` // Most days between waterings the plant tolerates
 	Sunlight           SunlightLevel `json:"sunlight" db:"sunlight"`
 	Temperature        TemperatureRange `json:"temperature" db:"-"`
 	Humidity           HumidityLevel `json:"humidity" db:"humidity"`
 	SoilType           string        `json:"soilType" db:"soil_type"`
 	FertilizerFrequency int           `json:"fertilizerFrequency" db:"fertilizer_frequency"`
 	AdditionalNotes    string        `json:"additionalNotes" db:"additional_notes"`
+	SourceURL          *string       `json:"sourceUrl,omitempty" db:"source_url"`
+	SourceAuthor       *string       `json:"sourceAuthor,omitempty" db:"source_author"`
+	LastReviewedAt     *time.Time    `json:"lastReviewedAt,omitempty" db:"last_reviewed_at"`
 	CreatedAt          time.Time     `json:"createdAt" db:"created_at"`
 	UpdatedAt          time.Time     `json:"updatedAt" db:"updated_at"`
 }
 
+// LowEffortWateringFrequency returns the days between waterings in low effort mode: the most the
+// plant tolerates when its care instructions document it, the usual frequency otherwise
+func (c CareInstructions) LowEffortWateringFrequency() int {
+	if c.WateringFrequencyMax != nil && *c.WateringFrequencyMax > c.WateringFrequency {
+		return *c.WateringFrequencyMax
+	}
+	return c.WateringFrequency
+}
+
+// PlantTranslation is the translation of a catalog plant into a language other than the catalog's.
+// Fields left unset are shown as in the catalog.
+type PlantTranslation struct {
+	PlantID         uuid.UUID `json:"plantId" db:"plant_id"`
+	Language        Language  `json:"language" db:"language"`
+	Name            *string   `json:"name,omitempty" db:"name"`
+	Description     *string   `json:"description,omitempty" db:"description"`
+	AdditionalNotes *string   `json:"additionalNotes,omitempty" db:"additional_notes"` // the care notes
+	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Apply shows a plant with the translated fields
+func (t *PlantTranslation) Apply(plant *Plant) {
+	if t.Name != nil {
+		plant.Name = *t.Name
+	}
+	if t.Description != nil {
+		plant.Description = *t.Description
+	}
+	if t.AdditionalNotes != nil {
+		plant.CareInstructions.AdditionalNotes = *t.AdditionalNotes
+	}
+}
+
+// PlantTranslationRequest represents the request body for setting the translation of a plant
+type PlantTranslationRequest struct {
+	Name            *string `json:"name,omitempty" validate:"omitempty,max=255"`
+	Description     *string `json:"description,omitempty" validate:"omitempty,max=10000"`
+	AdditionalNotes *string `json:"additionalNotes,omitempty" validate:"omitempty,max=10000"`
+}
+
+// PlantSpecies is a species of the catalog. Its care instructions are the defaults of the plants
+// that are cultivars of it.
+type PlantSpecies struct {
+	ID               uuid.UUID        `json:"id" db:"id"`
+	ScientificName   string           `json:"scientificName" db:"scientific_name"`
+	Family           *string          `json:"family,omitempty" db:"family"`
+	CareInstructions CareInstructions `json:"careInstructions" db:"-"`
+	CultivarCount    int              `json:"cultivarCount" db:"cultivar_count"` // Catalog plants inheriting the care instructions
+	CreatedAt        time.Time        `json:"createdAt" db:"created_at"`
+	UpdatedAt        time.Time        `json:"updatedAt" db:"updated_at"`
+}
+
+// PlantSpeciesRequest represents the request body for creating or updating a species
+type PlantSpeciesRequest struct {
+	ScientificName   string           `json:"scientificName" validate:"required,max=255"`
+	Family           *string          `json:"family,omitempty" validate:"omitempty,max=255"`
+	CareInstructions CareInstructions `json:"careInstructions"`
+}
+
+// CareOverrides are the care instruction fields a cultivar changes from the defaults of its
+// species; unset fields are inherited
+type CareOverrides struct {
+	WateringFrequency   *int              `json:"wateringFrequency,omitempty"`
+	Sunlight            *SunlightLevel    `json:"sunlight,omitempty"`
+	Temperature         *TemperatureRange `json:"temperature,omitempty"`
+	Humidity            *HumidityLevel    `json:"humidity,omitempty"`
+	SoilType            *string           `json:"soilType,omitempty"`
+	FertilizerFrequency *int              `json:"fertilizerFrequency,omitempty"`
+	AdditionalNotes     *string           `json:"additionalNotes,omitempty"`
+}
+
+// Apply returns the care instructions of a cultivar: the defaults of its species with the
+// overridden fields replaced. The ID and timestamps are left for the cultivar's own record.
+func (o CareOverrides) Apply(defaults CareInstructions) CareInstructions {
+	resolved := defaults
+	resolved.ID = uuid.Nil
+	resolved.CreatedAt, resolved.UpdatedAt = time.Time{}, time.Time{}
+	if o.WateringFrequency != nil {
+		resolved.WateringFrequency = *o.WateringFrequency
+		// The tolerance of the species does not hold for a cultivar watered outside it
+		if (resolved.WateringFrequencyMin != nil && *resolved.WateringFrequencyMin > resolved.WateringFrequency) ||
+			(resolved.WateringFrequencyMax != nil && *resolved.WateringFrequencyMax < resolved.WateringFrequency) {
+			resolved.WateringFrequencyMin, resolved.WateringFrequencyMax = nil, nil
+		}
+	}
+	if o.Sunlight != nil {
+		resolved.Sunlight = *o.Sunlight
+	}
+	if o.Temperature != nil {
+		resolved.Temperature = *o.Temperature
+	}
+	if o.Humidity != nil {
+		resolved.Humidity = *o.Humidity
+	}
+	if o.SoilType != nil {
+		resolved.SoilType = *o.SoilType
+	}
+	if o.FertilizerFrequency != nil {
+		resolved.FertilizerFrequency = *o.FertilizerFrequency
+	}
+	if o.AdditionalNotes != nil {
+		resolved.AdditionalNotes = *o.AdditionalNotes
+	}
+	return resolved
+}
+
+// Value implements driver.Valuer
+func (o CareOverrides) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
+
+// Scan implements sql.Scanner
+func (o *CareOverrides) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*o = CareOverrides{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into CareOverrides", src)
+	}
+	return json.Unmarshal(data, o)
+}
+
 // Plant represents a plant in the system
 type Plant struct {
 	ID               uuid.UUID       `json:"id" db:"id"`
 	Name             string          `json:"name" db:"name"`
 	ScientificName   string          `json:"scientificName" db:"scientific_name"`
+	Family           *string         `json:"family,omitempty" db:"family"` // Botanical family, e.g. Araceae
+	PetFriendly      *bool           `json:"petFriendly,omitempty" db:"pet_friendly"` // Safe for cats and dogs; unknown when nil
+	Toxicity         *Toxicity       `json:"toxicity,omitempty" db:"-"` // How harmful the plant is when eaten; unknown when nil
 	Description      string          `json:"description" db:"description"`
-	ImageURL         string          `json:"imageUrl" db:"image_url"`
+	ImageURL         AssetKey        `json:"imageUrl" db:"image_url"`
 	CareInstructions CareInstructions `json:"careInstructions" db:"-"`
 	Price            *float64        `json:"price,omitempty" db:"price"`
 	ShopID           *string         `json:"shopId,omitempty" db:"shop_id"`
 	IsFavorite       bool            `json:"isFavorite" db:"-"`
 	Location         *string         `json:"location,omitempty" db:"-"`
+	HomeID           *uuid.UUID      `json:"homeId,omitempty" db:"-"` // Home the plant is kept in in the collection
 	LastWatered      *time.Time      `json:"lastWatered,omitempty" db:"-"`
 	NextWatering     *time.Time      `json:"nextWatering,omitempty" db:"-"`
+	NextWateringDisplay string       `json:"nextWateringDisplay,omitempty" db:"-"` // Next watering humanized in the requested language, e.g. "in 3 days"
+	Nickname         *string         `json:"nickname,omitempty" db:"-"` // Name the owner gave the plant in their collection
+	Notes            *string         `json:"notes,omitempty" db:"-"`    // Owner's free-form notes on the plant in their collection
+	Photos           []*UserPlantPhoto `json:"photos,omitempty" db:"-"` // Owner's photos of the plant in their collection, newest first
+	CareHint         *CareHint       `json:"careHint,omitempty" db:"-"` // Watering urgency of a plant in the collection
+	LowEffort        *LowEffortWatering `json:"lowEffort,omitempty" db:"-"` // Set for plants in the collection watered in low effort mode
+	Recommendation   *RecommendationExplanation `json:"recommendation,omitempty" db:"-"` // Why the plant was recommended
+	SpeciesID        *uuid.UUID      `json:"speciesId,omitempty" db:"species_id"` // Set for cultivars inheriting the care instructions of a species
+	CareOverrides    *CareOverrides  `json:"careOverrides,omitempty" db:"care_overrides"` // Care instruction fields a cultivar changes from its species
+	Synonyms         pq.StringArray  `json:"synonyms,omitempty" db:"synonyms"` // Other scientific names of the plant; set on plant details
+	ImageLicense     *string         `json:"imageLicense,omitempty" db:"image_license"` // License of an image from an external source; set on plant details
+	ImageAttribution *string         `json:"imageAttribution,omitempty" db:"image_attribution"` // Author the license of the image requires crediting; set on plant details
 	CreatedAt        time.Time       `json:"createdAt" db:"created_at"`
 	UpdatedAt        time.Time       `json:"updatedAt" db:"updated_at"`
+	// Set when the plant was removed from the catalog; it stays in collections and favorites
+	DeletedAt        *time.Time      `json:"deletedAt,omitempty" db:"deleted_at"`
+	Status           PlantStatus     `json:"status,omitempty" db:"status"` // Review state; users only see published plants
+	StatusChangedAt  *time.Time      `json:"statusChangedAt,omitempty" db:"status_changed_at"` // Set on plants listed for review
+	Categories       pq.StringArray  `json:"categories,omitempty" db:"-"` // Slugs of the categories of the plant, e.g. succulent
+	Tags             pq.StringArray  `json:"tags,omitempty" db:"-"`       // Free-form tags, e.g. air-purifying
+}
+
+// FavoriteChanges lists the plants a user added to or removed from their favorites since a sync
+type FavoriteChanges struct {
+	Added   []uuid.UUID `json:"added"`
+	Removed []uuid.UUID `json:"removed"`
+	Until   time.Time   `json:"until"` // changes are listed up to this time; sent as since on the next sync
+}
+
+// CareStatus is the watering urgency of a plant in a user's collection
+type CareStatus string
+
+const (
+	CareStatusOverdue CareStatus = "OVERDUE"  // the plant needed water before today
+	CareStatusDueSoon CareStatus = "DUE_SOON" // the plant needs water today or within the due-soon days of the language
+	CareStatusOK      CareStatus = "OK"
+)
+
+// CareHint tells clients how to present the watering urgency of a plant, so every client renders it the same way
+type CareHint struct {
+	Status       CareStatus `json:"status"`
+	Badge        string     `json:"badge"`        // short text for the plant card in the requested language
+	SortPriority int        `json:"sortPriority"` // days until the next watering, negative when overdue; ascending is most urgent first
 }
 
 // UserPlant represents a plant owned by a user
@@ -100,12 +372,229 @@ type UserPlant struct {
 	UserID       uuid.UUID  `json:"userId" db:"user_id"`
 	PlantID      uuid.UUID  `json:"plantId" db:"plant_id"`
 	Location     *string    `json:"location,omitempty" db:"location"`
+	// Home the plant is kept in; nil when the user has no homes or did not assign it
+	HomeID       *uuid.UUID `json:"homeId,omitempty" db:"home_id"`
 	LastWatered  *time.Time `json:"lastWatered,omitempty" db:"last_watered"`
 	NextWatering *time.Time `json:"nextWatering,omitempty" db:"next_watering"`
+	Nickname     *string    `json:"nickname,omitempty" db:"nickname"`
+	Notes        *string    `json:"notes,omitempty" db:"notes"`
+	// Low effort mode of the plant; nil follows the owner's mode
+	LowEffortMode *bool     `json:"lowEffortMode,omitempty" db:"low_effort_mode"`
 	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+	// Owner's low effort mode, filled when the user plant is read
+	UserLowEffortMode bool  `json:"-" db:"user_low_effort_mode"`
 	// Additional fields for response
 	Plant        *Plant     `json:"plant,omitempty" db:"-"`
+	// Owner's preferred language, filled by the watering check
+	UserLanguage Language   `json:"-" db:"-"`
+	// Owner's watering reminder channel, filled by the watering check
+	UserReminderChannel ReminderChannel `json:"-" db:"-"`
+	// Outdoor location the plant is in, filled by the watering check; nil for plants indoors
+	OutdoorLocation *OutdoorLocation `json:"-" db:"-"`
+	// Home the plant is kept in, filled by the watering check; nil for plants in no home
+	Home *Home `json:"-" db:"-"`
+	// Home the owner is staying at, filled by the watering check; nil when the owner set none
+	UserCurrentHomeID *uuid.UUID `json:"-" db:"-"`
+	// Owner's time zone, filled by the watering check; nil when the owner set none
+	UserTimezone *string `json:"-" db:"-"`
+	// Local hour from which the owner gets watering reminders, filled by the watering check
+	UserNotificationHour int `json:"-" db:"-"`
+	// Owner's local day the watering reminders were last sent on, filled by the watering check
+	UserWateringRemindedOn *time.Time `json:"-" db:"-"`
+	// How the weather changed the watering reminder, set by the watering check and recorded on the notification
+	WeatherDecision *WateringWeatherDecision `json:"-" db:"-"`
+}
+
+// LowEffort reports whether a plant is watered in low effort mode and whether the plant or its owner
+// decided it
+func (up *UserPlant) LowEffort() (bool, LowEffortSource) {
+	if up.LowEffortMode != nil {
+		return *up.LowEffortMode, LowEffortSourcePlant
+	}
+	return up.UserLowEffortMode, LowEffortSourceUser
+}
+
+// LowEffortSource is where the low effort mode of a plant in a collection comes from
+type LowEffortSource string
+
+const (
+	LowEffortSourceUser  LowEffortSource = "USER"  // the owner's mode for the whole collection
+	LowEffortSourcePlant LowEffortSource = "PLANT" // the mode set for the plant itself
+)
+
+// LowEffortWatering describes how low effort mode changes the watering of a plant and what it costs
+type LowEffortWatering struct {
+	Source                  LowEffortSource `json:"source"`
+	WateringFrequency       int             `json:"wateringFrequency"`       // days between waterings in low effort mode
+	NormalWateringFrequency int             `json:"normalWateringFrequency"` // days between waterings the plant does best with
+	Stretched               bool            `json:"stretched"`               // false when the plant has no documented tolerance to stretch toward
+	TradeOffs               []string        `json:"tradeOffs"`
+}
+
+// NewLowEffortWatering describes the watering of a plant in low effort mode turned on by source; the
+// trade-offs are left for the language of the response
+func NewLowEffortWatering(care CareInstructions, source LowEffortSource) *LowEffortWatering {
+	frequency := care.LowEffortWateringFrequency()
+	return &LowEffortWatering{
+		Source:                  source,
+		WateringFrequency:       frequency,
+		NormalWateringFrequency: care.WateringFrequency,
+		Stretched:               frequency > care.WateringFrequency,
+		TradeOffs:               []string{},
+	}
+}
+
+// SetLowEffortModeRequest represents a request to turn low effort mode on or off. For a plant, a
+// null mode makes it follow the owner's mode again.
+type SetLowEffortModeRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+
+// PlantCompatibilityRequest represents the request body for checking whether plants can be planted together
+type PlantCompatibilityRequest struct {
+	PlantIDs []uuid.UUID `json:"plantIds" validate:"required,min=2,max=10,unique"`
+}
+
+// CompatibilityCriterion is a need of plants compared when they share a pot or a terrarium
+type CompatibilityCriterion string
+
+const (
+	CompatibilityCriterionLight       CompatibilityCriterion = "LIGHT"
+	CompatibilityCriterionWater       CompatibilityCriterion = "WATER"
+	CompatibilityCriterionHumidity    CompatibilityCriterion = "HUMIDITY"
+	CompatibilityCriterionTemperature CompatibilityCriterion = "TEMPERATURE"
+)
+
+// CompatibilityStatus is how well the needs of plants planted together agree, from best to worst
+type CompatibilityStatus string
+
+const (
+	CompatibilityStatusCompatible   CompatibilityStatus = "COMPATIBLE"   // the ranges overlap
+	CompatibilityStatusCaution      CompatibilityStatus = "CAUTION"      // close enough for a compromise
+	CompatibilityStatusIncompatible CompatibilityStatus = "INCOMPATIBLE" // one of the plants would suffer
+)
+
+// CompatibilityCheck is the outcome of comparing one need of plants planted together
+type CompatibilityCheck struct {
+	Criterion   CompatibilityCriterion `json:"criterion"`
+	Status      CompatibilityStatus    `json:"status"`
+	Explanation string                 `json:"explanation"`
+}
+
+// SharedCare is the care plants planted together get
+type SharedCare struct {
+	WateringFrequency int              `json:"wateringFrequency"`
+	Sunlight          SunlightLevel    `json:"sunlight"`
+	Humidity          HumidityLevel    `json:"humidity"`
+	Temperature       TemperatureRange `json:"temperature"`
+}
+
+// PlantCompatibility tells whether plants can share a pot or a terrarium
+type PlantCompatibility struct {
+	Compatible bool                 `json:"compatible"` // no check is INCOMPATIBLE
+	Status     CompatibilityStatus  `json:"status"`     // worst status of the checks
+	Checks     []CompatibilityCheck `json:"checks"`
+	SharedCare *SharedCare          `json:"sharedCare,omitempty"` // only for compatible plants
+}
+
+// UserPlantDetails are the details a user keeps about a plant in their collection. Nil fields are
+// left unchanged and empty ones are cleared.
+type UserPlantDetails struct {
+	Nickname *string `json:"nickname,omitempty" validate:"omitempty,max=50"`
+	Notes    *string `json:"notes,omitempty" validate:"omitempty,max=4000"`
+}
+
+// AddUserPlantsRequest represents a request to add several plants to the user's collection at once
+type AddUserPlantsRequest struct {
+	Plants []*AddUserPlantsItem `json:"plants" validate:"required,min=1,max=100,dive,required"`
+}
+
+// AddUserPlantsItem represents a plant of a batch added to the user's collection
+type AddUserPlantsItem struct {
+	PlantID     uuid.UUID  `json:"plantId" validate:"required"`
+	Location    string     `json:"location"`
+	LastWatered *time.Time `json:"lastWatered,omitempty"` // when the owner last watered the plant; the next watering is scheduled from it
+}
+
+// AddUserPlantsResult represents the outcome of adding one plant of a batch
+type AddUserPlantsResult struct {
+	PlantID  uuid.UUID `json:"plantId"`
+	Added    bool      `json:"added"`
+	Error    string    `json:"error,omitempty"` // why the plant was not added
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// AddUserPlantsResponse represents the outcome of adding a batch of plants to the user's collection
+type AddUserPlantsResponse struct {
+	Added   int                    `json:"added"`
+	Failed  int                    `json:"failed"`
+	Results []*AddUserPlantsResult `json:"results"` // in the order of the request
+}
+
+// PlantIdentificationCandidate represents a species a plant photo was identified as
+type PlantIdentificationCandidate struct {
+	ScientificName string   `json:"scientificName"`
+	CommonNames    []string `json:"commonNames,omitempty"`
+	Family         string   `json:"family,omitempty"`
+	Score          float64  `json:"score"`           // confidence of the identification, 0-1
+	Plant          *Plant   `json:"plant,omitempty"` // the catalog plant of the species, if any
+}
+
+// PlantOnboardingLocationSource tells where the room of an onboarded plant came from
+type PlantOnboardingLocationSource string
+
+const (
+	// PlantOnboardingLocationRequested is the room the user asked for
+	PlantOnboardingLocationRequested PlantOnboardingLocationSource = "REQUESTED"
+	// PlantOnboardingLocationLightFit is the room whose plants need the same light as the new one
+	PlantOnboardingLocationLightFit PlantOnboardingLocationSource = "LIGHT_FIT"
+	// PlantOnboardingLocationNone means no room of the collection fits, so the plant has none
+	PlantOnboardingLocationNone PlantOnboardingLocationSource = "NONE"
+)
+
+// PlantOnboardingRequest represents a new plant to identify from a photo and add to the user's collection
+type PlantOnboardingRequest struct {
+	Photo    []byte
+	PlantID  *uuid.UUID // skips identification, e.g. once the user picked one of the candidates
+	Location *string    // skips the room proposal
+	Nickname *string
+}
+
+// PlantOnboardingResult represents a plant identified from a photo and added to the user's collection
+type PlantOnboardingResult struct {
+	Plant          *Plant                          `json:"plant"`      // nil when the photo matched no catalog plant
+	Candidates     []*PlantIdentificationCandidate `json:"candidates"` // most likely first; empty when the plant was given
+	Location       string                          `json:"location"`
+	LocationSource PlantOnboardingLocationSource   `json:"locationSource,omitempty"`
+	Photo          *UserPlantPhoto                 `json:"photo,omitempty"`    // nil when photo uploads are not configured
+	Schedule       *CareSchedule                   `json:"schedule,omitempty"` // care due in the next two weeks
+}
+
+// PhotoModerationStatus represents the moderation status of a photo uploaded by a user
+type PhotoModerationStatus string
+
+const (
+	PhotoModerationApproved    PhotoModerationStatus = "APPROVED"
+	PhotoModerationQuarantined PhotoModerationStatus = "QUARANTINED" // awaits an admin's review before it goes live
+)
+
+// PhotoModerationLabelNotPlant labels photos that obviously show no plant; they are kept from public share pages
+const PhotoModerationLabelNotPlant = "not_plant"
+
+// UserPlantPhoto represents a photo a user took of a plant in their collection
+type UserPlantPhoto struct {
+	ID               uuid.UUID             `json:"id" db:"id"`
+	UserID           uuid.UUID             `json:"userId" db:"user_id"`
+	PlantID          uuid.UUID             `json:"plantId" db:"plant_id"`
+	ImageURL         AssetKey              `json:"imageUrl" db:"image_key"`
+	ContentType      string                `json:"contentType" db:"content_type"`
+	ModerationStatus PhotoModerationStatus `json:"moderationStatus" db:"moderation_status"`
+	ModerationLabels pq.StringArray        `json:"moderationLabels" db:"moderation_labels"` // what the moderation provider found, e.g. adult or not_plant
+	Shareable        bool                  `json:"shareable" db:"shareable"`                // approved and showing a plant, so public share pages may show it
+	ReviewedAt       *time.Time            `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	CreatedAt        time.Time             `json:"createdAt" db:"created_at"`
 }
 
 // UserFavoritePlant represents a plant favorited by a user
@@ -121,20 +610,160 @@ type Shop struct {
 	ID        uuid.UUID `json:"id" db:"id"`
 	Name      string    `json:"name" db:"name"`
 	Address   string    `json:"address" db:"address"`
+	City      *string   `json:"city,omitempty" db:"city"` // locality of the address, set when the shop is imported
 	Rating    float64   `json:"rating" db:"rating"`
-	ImageURL  *string   `json:"imageUrl,omitempty" db:"image_url"`
+	ImageURL  *AssetKey `json:"imageUrl,omitempty" db:"image_url"`
+	Latitude  *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude *float64  `json:"longitude,omitempty" db:"longitude"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
 }
 
+// ShopRequest represents a request to create or update a shop
+type ShopRequest struct {
+	Name      string   `json:"name" validate:"required,max=255"`
+	Address   string   `json:"address" validate:"required,max=500"`
+	City      *string  `json:"city,omitempty" validate:"omitempty,max=255"`
+	Rating    float64  `json:"rating" validate:"min=0,max=5"`
+	ImageURL  *string  `json:"imageUrl,omitempty" validate:"omitempty,url"`
+	Latitude  *float64 `json:"latitude,omitempty" validate:"omitempty,min=-90,max=90"` // set with longitude
+	Longitude *float64 `json:"longitude,omitempty" validate:"omitempty,min=-180,max=180"`
+}
+
+// GeoPoint represents the coordinates of an address
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	City      string  `json:"city,omitempty"` // locality the address is in, when the geocoder knows it
+}
+
+// ShopImportStatus represents the outcome of a row of a shop import
+type ShopImportStatus string
+
+const (
+	ShopImportStatusCreated   ShopImportStatus = "CREATED"
+	ShopImportStatusDuplicate ShopImportStatus = "DUPLICATE" // a shop with the same address exists or appears earlier in the file
+	ShopImportStatusInvalid   ShopImportStatus = "INVALID"
+	ShopImportStatusNotFound  ShopImportStatus = "NOT_FOUND" // the address could not be geocoded
+	ShopImportStatusFailed    ShopImportStatus = "FAILED"
+)
+
+// ShopImportRow represents the outcome of a row of a shop import
+type ShopImportRow struct {
+	Line    int              `json:"line"`
+	Name    string           `json:"name"`
+	Address string           `json:"address"`
+	Status  ShopImportStatus `json:"status"`
+	ShopID  *uuid.UUID       `json:"shopId,omitempty"` // the created shop or the shop it duplicates
+	Error   string           `json:"error,omitempty"`
+}
+
+// ShopImportResult represents the outcome of a shop import
+type ShopImportResult struct {
+	Created    int              `json:"created"`
+	Duplicates int              `json:"duplicates"`
+	Failed     int              `json:"failed"`
+	Rows       []*ShopImportRow `json:"rows"`
+}
+
+// PlantImportStatus represents the outcome of a row of a plant catalog import
+type PlantImportStatus string
+
+const (
+	PlantImportStatusCreated   PlantImportStatus = "CREATED"
+	PlantImportStatusValid     PlantImportStatus = "VALID"     // a dry run would create the plant
+	PlantImportStatusDuplicate PlantImportStatus = "DUPLICATE" // a plant with the same scientific name exists or appears earlier in the file
+	PlantImportStatusInvalid   PlantImportStatus = "INVALID"
+	PlantImportStatusFailed    PlantImportStatus = "FAILED"
+)
+
+// PlantImportRow represents the outcome of a row of a plant catalog import
+type PlantImportRow struct {
+	Row            int               `json:"row"` // line of a CSV file or position in a JSON array, from 1
+	Name           string            `json:"name"`
+	ScientificName string            `json:"scientificName"`
+	Status         PlantImportStatus `json:"status"`
+	PlantID        *uuid.UUID        `json:"plantId,omitempty"` // the created plant or the catalog plant it duplicates
+	Error          string            `json:"error,omitempty"`
+}
+
+// PlantImportResult represents the outcome of a plant catalog import
+type PlantImportResult struct {
+	DryRun     bool              `json:"dryRun"`
+	Created    int               `json:"created"` // plants created, or that a dry run would create
+	Duplicates int               `json:"duplicates"`
+	Invalid    int               `json:"invalid"`
+	Failed     int               `json:"failed"`
+	Rows       []*PlantImportRow `json:"rows"`
+	Error      string            `json:"error,omitempty"` // why the file stopped being read; the rows after it were not imported
+}
+
+// ShopPlantCondition represents the condition grade of a shop's plant stock
+type ShopPlantCondition string
+
+const (
+	ShopPlantConditionExcellent ShopPlantCondition = "EXCELLENT"
+	ShopPlantConditionGood      ShopPlantCondition = "GOOD"
+	ShopPlantConditionFair      ShopPlantCondition = "FAIR"
+)
+
 // ShopPlant represents a plant sold by a shop
 type ShopPlant struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	ShopID    uuid.UUID `json:"shopId" db:"shop_id"`
-	PlantID   uuid.UUID `json:"plantId" db:"plant_id"`
-	Price     float64   `json:"price" db:"price"`
-	CreatedAt time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	ID            uuid.UUID           `json:"id" db:"id"`
+	ShopID        uuid.UUID           `json:"shopId" db:"shop_id"`
+	PlantID       uuid.UUID           `json:"plantId" db:"plant_id"`
+	Price         float64             `json:"price" db:"price"`
+	// Attributes of the batch currently in stock
+	Condition     *ShopPlantCondition `json:"condition,omitempty" db:"condition"`
+	SizeCm        *int                `json:"sizeCm,omitempty" db:"size_cm"`
+	PotDiameterCm *int                `json:"potDiameterCm,omitempty" db:"pot_diameter_cm"`
+	BatchPhotos   AssetKeys           `json:"batchPhotos" db:"batch_photo_urls"`
+	CreatedAt     time.Time           `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time           `json:"updatedAt" db:"updated_at"`
+}
+
+// PlantOffer represents a shop's offer for a plant
+type PlantOffer struct {
+	ShopPlant
+	Shop Shop `json:"shop" db:"shop"`
+}
+
+// UpdateShopPlantRequest represents a request to update the stock attributes of a shop's plant
+type UpdateShopPlantRequest struct {
+	Price         float64             `json:"price" validate:"required,gt=0"`
+	Condition     *ShopPlantCondition `json:"condition,omitempty" validate:"omitempty,oneof=EXCELLENT GOOD FAIR"`
+	SizeCm        *int                `json:"sizeCm,omitempty" validate:"omitempty,min=1,max=1000"`
+	PotDiameterCm *int                `json:"potDiameterCm,omitempty" validate:"omitempty,min=1,max=200"`
+	BatchPhotos   []string            `json:"batchPhotos" validate:"max=10,dive,url"`
+}
+
+// AddShopPlantRequest represents a request to have a shop sell a plant
+type AddShopPlantRequest struct {
+	PlantID uuid.UUID `json:"plantId" validate:"required"`
+	UpdateShopPlantRequest
+}
+
+// PlantAvailabilitySubscription represents a user waiting for a plant to be sold in a city
+type PlantAvailabilitySubscription struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"userId" db:"user_id"`
+	PlantID    uuid.UUID  `json:"plantId" db:"plant_id"`
+	PlantName  string     `json:"plantName" db:"plant_name"`
+	City       string     `json:"city" db:"city"`
+	NotifiedAt *time.Time `json:"notifiedAt,omitempty" db:"notified_at"` // set once the plant was stocked in the city
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// PlantAvailabilityRecipient represents a subscriber to notify that a plant is available
+type PlantAvailabilityRecipient struct {
+	UserID   uuid.UUID `db:"user_id"`
+	Language Language  `db:"language"`
+}
+
+// SubscribeToAvailabilityRequest represents a request to be notified when a plant is available in a city
+type SubscribeToAvailabilityRequest struct {
+	City string `json:"city" validate:"required,max=255"`
 }
 
 // SpecialOffer represents a special offer in the system
@@ -142,13 +771,22 @@ type SpecialOffer struct {
 	ID                uuid.UUID `json:"id" db:"id"`
 	Title             string    `json:"title" db:"title"`
 	Description       string    `json:"description" db:"description"`
-	ImageURL          string    `json:"imageUrl" db:"image_url"`
+	ImageURL          AssetKey  `json:"imageUrl" db:"image_url"`
 	DiscountPercentage int       `json:"discountPercentage" db:"discount_percentage"`
 	ValidUntil        time.Time `json:"validUntil" db:"valid_until"`
 	CreatedAt         time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt         time.Time `json:"updatedAt" db:"updated_at"`
 }
 
+// SpecialOfferRequest represents a request to create or update a special offer
+type SpecialOfferRequest struct {
+	Title              string    `json:"title" validate:"required,max=255"`
+	Description        string    `json:"description" validate:"required,max=2000"`
+	ImageURL           string    `json:"imageUrl" validate:"required,url"`
+	DiscountPercentage int       `json:"discountPercentage" validate:"required,min=1,max=100"`
+	ValidUntil         time.Time `json:"validUntil" validate:"required"` // must be in the future
+}
+
 // PlantQuestionnaire represents a questionnaire for plant recommendations
 type PlantQuestionnaire struct {
 	ID                   uuid.UUID     `json:"id" db:"id"`
@@ -158,6 +796,10 @@ type PlantQuestionnaire struct {
 	CareLevel            int           `json:"careLevel" db:"care_level"`
 	PreferredLocation    *string       `json:"preferredLocation,omitempty" db:"preferred_location"`
 	AdditionalPreferences *string       `json:"additionalPreferences,omitempty" db:"additional_preferences"`
+	PreferredTags        pq.StringArray `json:"preferredTags,omitempty" db:"preferred_tags"` // Tags or categories the plants should have
+	HasChildren          bool          `json:"hasChildren" db:"has_children"` // Plants toxic to children are left out
+	ResultCount          int           `json:"resultCount" db:"result_count"`      // Number of plants to recommend
+	MaxPerFamily         int           `json:"maxPerFamily" db:"max_per_family"` // Maximum number of recommended plants from one family
 	CreatedAt            time.Time     `json:"createdAt" db:"created_at"`
 }
 
@@ -168,9 +810,69 @@ type PlantRecommendation struct {
 	PlantID         uuid.UUID `json:"plantId" db:"plant_id"`
 	Score           float64   `json:"score" db:"score"`
 	Reasoning       string    `json:"reasoning" db:"reasoning"`
+	Explanation     *RecommendationExplanation `json:"explanation,omitempty" db:"explanation"` // Why the engine recommended the plant
 	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
 }
 
+// RecommendationEngineName identifies the engine that scored a recommendation
+type RecommendationEngineName string
+
+const (
+	RecommendationEngineWeighted RecommendationEngineName = "WEIGHTED" // weighted questionnaire criteria
+	RecommendationEngineLLM      RecommendationEngineName = "LLM"      // Yandex GPT
+	RecommendationEngineHybrid   RecommendationEngineName = "HYBRID"   // Yandex GPT blended with the weighted criteria
+)
+
+// RecommendationCriterion identifies a criterion a plant is scored on
+type RecommendationCriterion string
+
+const (
+	RecommendationCriterionSunlight    RecommendationCriterion = "SUNLIGHT"
+	RecommendationCriterionCareLevel   RecommendationCriterion = "CARE_LEVEL"
+	RecommendationCriterionPetFriendly RecommendationCriterion = "PET_FRIENDLY"
+	RecommendationCriterionLocation    RecommendationCriterion = "LOCATION"
+	RecommendationCriterionTags        RecommendationCriterion = "TAGS" // the preferred tags the plant has
+	RecommendationCriterionLLM         RecommendationCriterion = "LLM" // the score Yandex GPT gave the plant
+)
+
+// RecommendationCriterionScore is how well a plant meets one criterion and how much that added to its score
+type RecommendationCriterionScore struct {
+	Criterion    RecommendationCriterion `json:"criterion"`
+	Weight       float64                 `json:"weight"`       // share of the score the criterion is worth, the weights sum to 1
+	Match        float64                 `json:"match"`        // 0 when the plant does not meet the criterion, 1 when it fully does
+	Contribution float64                 `json:"contribution"` // weight multiplied by match
+	Reason       string                  `json:"reason,omitempty"`
+}
+
+// RecommendationExplanation tells why a plant was recommended
+type RecommendationExplanation struct {
+	Engine   RecommendationEngineName       `json:"engine"`
+	Score    float64                        `json:"score"`
+	Criteria []RecommendationCriterionScore `json:"criteria"`
+}
+
+// Value implements driver.Valuer
+func (e RecommendationExplanation) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+// Scan implements sql.Scanner
+func (e *RecommendationExplanation) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*e = RecommendationExplanation{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into RecommendationExplanation", src)
+	}
+	return json.Unmarshal(data, e)
+}
+
 // LoginRequest represents a login request
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -197,6 +899,10 @@ type QuestionnaireRequest struct {
 	CareLevel            int           `json:"careLevel" validate:"required,min=1,max=5"`
 	PreferredLocation    *string       `json:"preferredLocation,omitempty"`
 	AdditionalPreferences *string       `json:"additionalPreferences,omitempty"`
+	HasChildren          bool          `json:"hasChildren"` // plants toxic to children are left out
+	Tags                 []string      `json:"tags,omitempty" validate:"omitempty,max=10"` // tags or categories the plants should have, e.g. air-purifying
+	Count                *int          `json:"count,omitempty" validate:"omitempty,min=1,max=20"`
+	MaxPerFamily         *int          `json:"maxPerFamily,omitempty" validate:"omitempty,min=1,max=20"`
 }
 
 // ChatMessage represents a message in a chat session
@@ -204,11 +910,24 @@ type ChatMessage struct {
 	ID        uuid.UUID `json:"id" db:"id"`
 	SessionID uuid.UUID `json:"sessionId" db:"session_id"`
 	UserID    uuid.UUID `json:"userId" db:"user_id"`
-	Role      string    `json:"role" db:"role"` // "user" or "assistant"
+	Role      string    `json:"role" db:"role"` // "user", "assistant" or "expert"
 	Content   string    `json:"content" db:"content"`
+	Language  Language  `json:"language" db:"language"`
+	// Yandex GPT tokens spent on an assistant message; zero for user messages
+	PromptTokens     int64 `json:"promptTokens" db:"prompt_tokens"`
+	CompletionTokens int64 `json:"completionTokens" db:"completion_tokens"`
+	// The human expert who wrote an expert message
+	ExpertID  *uuid.UUID `json:"expertId,omitempty" db:"expert_id"`
+	// The photo attached to a user message and what the vision provider saw on it, which is passed
+	// to Yandex GPT along with the message
+	ImageURL         *AssetKey `json:"imageUrl,omitempty" db:"image_url"`
+	ImageDescription string    `json:"-" db:"image_description"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
+// ChatMessageRoleExpert is the role of the messages written by a human expert in an escalated session
+const ChatMessageRoleExpert = "expert"
+
 // ChatSession represents a chat session with Yandex GPT
 type ChatSession struct {
 	ID        uuid.UUID  `json:"id" db:"id"`
@@ -217,6 +936,59 @@ type ChatSession struct {
 	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
 	LastUsed  time.Time  `json:"lastUsed" db:"last_used"`
+	// Yandex GPT tokens spent on the session's messages
+	PromptTokens     int64 `json:"promptTokens" db:"prompt_tokens"`
+	CompletionTokens int64 `json:"completionTokens" db:"completion_tokens"`
+	// Set once the user asked for a human expert
+	EscalationStatus *ChatEscalationStatus `json:"escalationStatus,omitempty" db:"escalation_status"`
+	EscalationReason *string               `json:"escalationReason,omitempty" db:"escalation_reason"`
+	EscalatedAt      *time.Time            `json:"escalatedAt,omitempty" db:"escalated_at"`
+	ExpertID         *uuid.UUID            `json:"expertId,omitempty" db:"expert_id"` // the expert who claimed the escalation
+}
+
+// ChatEscalationStatus represents the state of a chat session's request for a human expert
+type ChatEscalationStatus string
+
+const (
+	ChatEscalationStatusPending  ChatEscalationStatus = "PENDING" // waiting in the queue for an expert
+	ChatEscalationStatusClaimed  ChatEscalationStatus = "CLAIMED" // an expert is answering
+	ChatEscalationStatusResolved ChatEscalationStatus = "RESOLVED"
+)
+
+// EscalateChatRequest represents a request to hand a chat session to a human expert
+type EscalateChatRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=1000"`
+}
+
+// RenameChatSessionRequest represents a request to rename a chat session
+type RenameChatSessionRequest struct {
+	Title string `json:"title" validate:"required,max=100"`
+}
+
+// ChatEscalation represents an escalated chat session with its conversation, as shown to experts
+type ChatEscalation struct {
+	Session  *ChatSession   `json:"session"`
+	Messages []*ChatMessage `json:"messages"`
+}
+
+// ChatContext represents the conversation context of a chat session sent to Yandex GPT with
+// each message: the system prompt and a rolling summary of the messages up to SummarizedThrough
+type ChatContext struct {
+	SessionID         uuid.UUID  `json:"sessionId" db:"id"`
+	SystemPrompt      string     `json:"systemPrompt" db:"system_prompt"`
+	Summary           string     `json:"summary" db:"summary"`
+	SummarizedThrough *time.Time `json:"summarizedThrough,omitempty" db:"summarized_through"`
+}
+
+// ChatUsage represents the cumulative chat usage of a user
+type ChatUsage struct {
+	Sessions         int   `json:"sessions" db:"sessions"`
+	Messages         int   `json:"messages" db:"messages"`
+	PromptTokens     int64 `json:"promptTokens" db:"prompt_tokens"`
+	CompletionTokens int64 `json:"completionTokens" db:"completion_tokens"`
+	// Yandex GPT tokens spent this month across all features and the monthly quota, when users are limited
+	MonthTokens        *int64 `json:"monthTokens,omitempty" db:"-"`
+	MonthlyQuotaTokens *int64 `json:"monthlyQuotaTokens,omitempty" db:"-"`
 }
 
 // ChatRequest represents a request to send a message to the chat
@@ -242,6 +1014,8 @@ type DetailedQuestionnaireRequest struct {
 	WateringFrequency     string        `json:"wateringFrequency" validate:"required,oneof=RARE REGULAR FREQUENT"`
 	ExperienceLevel       string        `json:"experienceLevel" validate:"required,oneof=BEGINNER INTERMEDIATE ADVANCED"`
 	AdditionalPreferences *string       `json:"additionalPreferences,omitempty"`
+	Count                 *int          `json:"count,omitempty" validate:"omitempty,min=1,max=20"`
+	MaxPerFamily          *int          `json:"maxPerFamily,omitempty" validate:"omitempty,min=1,max=20"`
 }
 
 // NotificationType represents the type of notification
@@ -249,24 +1023,2057 @@ type NotificationType string
 
 const (
 	NotificationTypeWatering NotificationType = "WATERING"
+	NotificationTypeCareFeedback NotificationType = "CARE_FEEDBACK"
+	NotificationTypeRepotting NotificationType = "REPOTTING"
+	NotificationTypeFertilizingSeason NotificationType = "FERTILIZING_SEASON"
+	NotificationTypeDormancy NotificationType = "DORMANCY"
+	NotificationTypeFertilizing NotificationType = "FERTILIZING"
+	NotificationTypeMisting NotificationType = "MISTING"
+	NotificationTypePruning NotificationType = "PRUNING"
+	NotificationTypeOffer NotificationType = "OFFER"
+	NotificationTypeSupportTicket NotificationType = "SUPPORT_TICKET"
+	NotificationTypeChatExpertReply NotificationType = "CHAT_EXPERT_REPLY"
+	NotificationTypePlantAvailable NotificationType = "PLANT_AVAILABLE"
+	NotificationTypeWateringSkipped NotificationType = "WATERING_SKIPPED"
+	NotificationTypeWelcome NotificationType = "WELCOME"
+	NotificationTypeAnnouncement NotificationType = "ANNOUNCEMENT"
+	NotificationTypeBadgeEarned NotificationType = "BADGE_EARNED"
+	NotificationTypeReminder NotificationType = "REMINDER"
 )
 
 // Notification represents a notification in the system
 type Notification struct {
 	ID        uuid.UUID        `json:"id" db:"id"`
 	UserID    uuid.UUID        `json:"userId" db:"user_id"`
-	PlantID   uuid.UUID        `json:"plantId" db:"plant_id"`
+	PlantID   *uuid.UUID       `json:"plantId,omitempty" db:"plant_id"` // nil for notifications not about a plant
 	Type      NotificationType `json:"type" db:"type"`
 	Message   string          `json:"message" db:"message"`
+	Payload   NotificationPayload `json:"payload" db:"payload"`
 	IsRead    bool            `json:"isRead" db:"is_read"`
 	CreatedAt time.Time       `json:"createdAt" db:"created_at"`
 	UpdatedAt time.Time       `json:"updatedAt" db:"updated_at"`
 	// Additional fields for response
 	Plant     *Plant          `json:"plant,omitempty" db:"-"`
+	Display   *NotificationDisplay `json:"display,omitempty" db:"-"`
+	CreatedAtDisplay string   `json:"createdAtDisplay,omitempty" db:"-"` // Creation time humanized in the requested language, e.g. "5 minutes ago"
+	DueDateDisplay   string   `json:"dueDateDisplay,omitempty" db:"-"`   // Due date of the payload humanized in the requested language, e.g. "tomorrow"
+}
+
+// NotificationPayload holds the data of a notification described by the fields of its type.
+// It is stored as a JSON object so new notification types do not need new columns.
+type NotificationPayload map[string]interface{}
+
+// Value implements driver.Valuer
+func (p NotificationPayload) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan implements sql.Scanner
+func (p *NotificationPayload) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*p = NotificationPayload{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into NotificationPayload", src)
+	}
+	payload := NotificationPayload{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+	*p = payload
+	return nil
+}
+
+// NotificationCategory groups notification types for clients
+type NotificationCategory string
+
+const (
+	NotificationCategoryCare       NotificationCategory = "CARE"
+	NotificationCategorySeason     NotificationCategory = "SEASON"
+	NotificationCategoryFeedback   NotificationCategory = "FEEDBACK"
+	NotificationCategoryOffer      NotificationCategory = "OFFER"
+	NotificationCategorySupport    NotificationCategory = "SUPPORT"
+	NotificationCategoryOnboarding NotificationCategory = "ONBOARDING"
+	NotificationCategoryNews       NotificationCategory = "NEWS"
+	NotificationCategoryAchievement NotificationCategory = "ACHIEVEMENT"
+)
+
+// NotificationFieldType represents the type of a notification payload field
+type NotificationFieldType string
+
+const (
+	NotificationFieldTypeUUID   NotificationFieldType = "UUID"
+	NotificationFieldTypeDate   NotificationFieldType = "DATE" // YYYY-MM-DD
+	NotificationFieldTypeNumber NotificationFieldType = "NUMBER"
+	NotificationFieldTypeString NotificationFieldType = "STRING"
+)
+
+// NotificationField describes a field of the payload of a notification type
+type NotificationField struct {
+	Name     string                `json:"name"`
+	Type     NotificationFieldType `json:"type"`
+	Required bool                  `json:"required"`
+}
+
+// NotificationTypeDefinition describes a registered notification type: the payload it carries
+// and how clients display it
+type NotificationTypeDefinition struct {
+	Type     NotificationType     `json:"type"`
+	Category NotificationCategory `json:"category"`
+	Icon     string               `json:"icon"`
+	Action   string               `json:"action"` // deep link; {field} is replaced with the payload field
+	Fields   []NotificationField  `json:"fields"`
+}
+
+// NotificationDisplay holds what a client needs to show a notification
+type NotificationDisplay struct {
+	Category NotificationCategory `json:"category"`
+	Icon     string               `json:"icon"`
+	Action   string               `json:"action,omitempty"`
+}
+
+// SendNotificationRequest represents an admin request to send a notification to a user
+type SendNotificationRequest struct {
+	UserID  uuid.UUID           `json:"userId" validate:"required"`
+	Type    NotificationType    `json:"type" validate:"required"`
+	Payload NotificationPayload `json:"payload" validate:"required"`
 }
 
 // NotificationResponse represents the response for notifications list
 type NotificationResponse struct {
 	Notifications []*Notification `json:"notifications"`
 	Total         int            `json:"total"`
-}
\ No newline at end of file
+}
+
+// NotificationCountResponse represents the number of notifications unread or marked as read
+type NotificationCountResponse struct {
+	Count int `json:"count"`
+}
+
+// NotificationBell is the unread state of a user's notifications, kept as notifications are created
+// and read so clients can show it without the notifications being counted
+type NotificationBell struct {
+	UnreadCount          int        `json:"unreadCount" db:"unread_count"`
+	LatestNotificationAt *time.Time `json:"latestNotificationAt,omitempty" db:"latest_notification_at"` // unset until the first notification
+}
+
+// APIKey represents a key issued to a user for the public API
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"userId" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	KeyPrefix  string     `json:"keyPrefix" db:"key_prefix"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// APIKeyUsage represents the number of public API requests made with a key on a day
+type APIKeyUsage struct {
+	APIKeyID     uuid.UUID `json:"apiKeyId" db:"api_key_id"`
+	Day          time.Time `json:"day" db:"day"`
+	RequestCount int       `json:"requestCount" db:"request_count"`
+}
+
+// CreateAPIKeyRequest represents a request to create an API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// CreateAPIKeyResponse represents a newly created API key; the raw key is only returned once
+type CreateAPIKeyResponse struct {
+	APIKey APIKey `json:"apiKey"`
+	Key    string `json:"key"`
+}
+
+// DeprecationNotice describes an API endpoint scheduled for removal
+type DeprecationNotice struct {
+	Endpoint    string    `json:"endpoint"`
+	Message     string    `json:"message"`
+	SunsetDate  time.Time `json:"sunsetDate"`
+	Replacement *string   `json:"replacement,omitempty"`
+}
+
+// OnboardingStep represents a localized onboarding screen shown by the client
+type OnboardingStep struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// AppVersionInfo describes the supported client app versions
+type AppVersionInfo struct {
+	Minimum         string `json:"minimum"`
+	Latest          string `json:"latest"`
+	ForceUpgrade    bool   `json:"forceUpgrade"`
+	UpdateAvailable bool   `json:"updateAvailable"`
+}
+
+// ClientConfig represents the server-driven configuration of the mobile client
+type ClientConfig struct {
+	Features     map[string]bool     `json:"features"`
+	AppVersion   AppVersionInfo      `json:"appVersion"`
+	Deprecations []DeprecationNotice `json:"deprecations"`
+	Language     Language            `json:"language"`
+	Onboarding   []OnboardingStep    `json:"onboarding"`
+}
+
+// LLMStatusCode represents the outcome of the language model self-test
+type LLMStatusCode string
+
+const (
+	LLMStatusUnknown       LLMStatusCode = "UNKNOWN"
+	LLMStatusOK            LLMStatusCode = "OK"
+	LLMStatusDisabled      LLMStatusCode = "DISABLED"
+	LLMStatusMisconfigured LLMStatusCode = "MISCONFIGURED"
+	LLMStatusUnreachable   LLMStatusCode = "UNREACHABLE"
+)
+
+// LLMStatus represents the result of the Yandex GPT configuration check
+type LLMStatus struct {
+	Status    LLMStatusCode `json:"status"`
+	Model     string        `json:"model"`
+	CheckedAt *time.Time    `json:"checkedAt,omitempty"`
+	LatencyMs int64         `json:"latencyMs,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Hint      string        `json:"hint,omitempty"`
+}
+
+// ReadinessResponse represents the response of the readiness probe
+type ReadinessResponse struct {
+	Status    string       `json:"status"`
+	YandexGPT *LLMStatus   `json:"yandexGpt"`
+	Redis     *RedisStatus `json:"redis,omitempty"` // nil when Redis is not configured
+}
+
+// RedisStatus represents the result of the Redis health check
+type RedisStatus struct {
+	Status    string `json:"status"` // OK or UNREACHABLE
+	Error     string `json:"error,omitempty"`
+	OpenConns int    `json:"openConns"`
+	IdleConns int    `json:"idleConns"`
+}
+
+// FunFactStatus represents the moderation status of a plant fun fact
+type FunFactStatus string
+
+const (
+	FunFactStatusPending  FunFactStatus = "PENDING"
+	FunFactStatusApproved FunFactStatus = "APPROVED"
+	FunFactStatusRejected FunFactStatus = "REJECTED"
+)
+
+// PlantFunFact represents a generated fun fact about a plant
+type PlantFunFact struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	PlantID    uuid.UUID     `json:"plantId" db:"plant_id"`
+	Language   Language      `json:"language" db:"language"`
+	Fact       string        `json:"fact" db:"fact"`
+	Status     FunFactStatus `json:"status" db:"status"`
+	ReviewedAt *time.Time    `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	CreatedAt  time.Time     `json:"createdAt" db:"created_at"`
+}
+
+// ReviewFunFactRequest represents a request to approve or reject a fun fact
+type ReviewFunFactRequest struct {
+	Status FunFactStatus `json:"status" validate:"required,oneof=APPROVED REJECTED"`
+}
+
+// NicknameSuggestions represents nickname suggestions for a user plant
+type NicknameSuggestions struct {
+	PlantID   uuid.UUID `json:"plantId"`
+	Nicknames []string  `json:"nicknames"`
+}
+
+// CareTaskType represents the kind of a weekly care task
+type CareTaskType string
+
+const (
+	CareTaskTypeWater     CareTaskType = "WATER"
+	CareTaskTypeMist      CareTaskType = "MIST"
+	CareTaskTypeFertilize CareTaskType = "FERTILIZE"
+	CareTaskTypeRotate    CareTaskType = "ROTATE"
+	CareTaskTypeRepot     CareTaskType = "REPOT"
+	CareTaskTypePrune     CareTaskType = "PRUNE"
+)
+
+// CareTask represents a care task due on a given day
+type CareTask struct {
+	ID          string       `json:"id"`
+	Type        CareTaskType `json:"type"`
+	DueDate     time.Time    `json:"dueDate"`
+	Completed   bool         `json:"completed"`
+	CompletedAt *time.Time   `json:"completedAt,omitempty"`
+}
+
+// CareTaskStats represents care task adherence over a period
+type CareTaskStats struct {
+	Total     int     `json:"total"`
+	Completed int     `json:"completed"`
+	Adherence float64 `json:"adherence"` // completed / total, 1 when there are no tasks
+}
+
+// CareTaskChecklist represents the care tasks of a user plant for one week
+type CareTaskChecklist struct {
+	PlantID   uuid.UUID     `json:"plantId"`
+	WeekStart time.Time     `json:"weekStart"`
+	WeekEnd   time.Time     `json:"weekEnd"`
+	Tasks     []*CareTask   `json:"tasks"`
+	Stats     CareTaskStats `json:"stats"`
+}
+
+// CareTaskCompletion represents a recorded completion of a care task
+type CareTaskCompletion struct {
+	ID          uuid.UUID    `json:"id" db:"id"`
+	UserID      uuid.UUID    `json:"userId" db:"user_id"`
+	PlantID     uuid.UUID    `json:"plantId" db:"plant_id"`
+	TaskType    CareTaskType `json:"taskType" db:"task_type"`
+	DueDate     time.Time    `json:"dueDate" db:"due_date"`
+	CompletedAt time.Time    `json:"completedAt" db:"completed_at"`
+}
+
+// UserPlantTask represents a recurring care task of a plant in a user's collection
+type UserPlantTask struct {
+	ID            uuid.UUID    `json:"id" db:"id"`
+	UserID        uuid.UUID    `json:"userId" db:"user_id"`
+	PlantID       uuid.UUID    `json:"plantId" db:"plant_id"`
+	Type          CareTaskType `json:"type" db:"task_type"`
+	FrequencyDays int          `json:"frequencyDays" db:"frequency_days"`
+	NextDue       time.Time    `json:"nextDue" db:"next_due"`
+	CreatedAt     time.Time    `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time    `json:"updatedAt" db:"updated_at"`
+	// Plant the task is about, filled when tasks are due
+	UserPlant *UserPlant `json:"-" db:"-"`
+}
+
+// CareScheduleEntry represents a recurring care task in a schedule update
+type CareScheduleEntry struct {
+	Type          CareTaskType `json:"type" validate:"required,oneof=MIST FERTILIZE REPOT PRUNE"`
+	FrequencyDays int          `json:"frequencyDays" validate:"required,min=1,max=730"`
+	NextDue       *time.Time   `json:"nextDue,omitempty"` // keeps the current date of the task, or a full interval from today for new tasks
+}
+
+// UpdateCareScheduleRequest represents a request to replace the recurring care tasks of a user plant
+type UpdateCareScheduleRequest struct {
+	Tasks []CareScheduleEntry `json:"tasks" validate:"required,dive"`
+}
+
+// PlantReminder represents a recurring reminder a user defined for a plant in their collection, e.g.
+// checking for spider mites monthly
+type PlantReminder struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	UserID        uuid.UUID `json:"userId" db:"user_id"`
+	PlantID       uuid.UUID `json:"plantId" db:"plant_id"`
+	Title         string    `json:"title" db:"title"`
+	FrequencyDays int       `json:"frequencyDays" db:"frequency_days"`
+	NextDue       time.Time `json:"nextDue" db:"next_due"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time `json:"updatedAt" db:"updated_at"`
+	// Plant the reminder is about, filled when reminders are due
+	UserPlant *UserPlant `json:"-" db:"-"`
+}
+
+// PlantReminderRequest represents a request to create or update a reminder of a user plant
+type PlantReminderRequest struct {
+	Title         string     `json:"title" validate:"required,max=100"`
+	FrequencyDays int        `json:"frequencyDays" validate:"required,min=1,max=730"`
+	NextDue       *time.Time `json:"nextDue,omitempty"` // keeps the current date of the reminder, or a full interval from today for new reminders
+}
+
+// CareSchedule represents the upcoming care tasks of a user plant
+type CareSchedule struct {
+	PlantID   uuid.UUID        `json:"plantId"`
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Schedules []*UserPlantTask `json:"schedules"`
+	Tasks     []*CareTask      `json:"tasks"`
+}
+
+// WateringRouteStop represents one room of the watering route with the plants due there
+type WateringRouteStop struct {
+	Step   int      `json:"step"`
+	Room   *string  `json:"room"` // nil for plants without a room
+	Plants []*Plant `json:"plants"`
+}
+
+// WateringRoute represents the plants due for watering today, grouped and ordered by room
+type WateringRoute struct {
+	Date        time.Time            `json:"date"`
+	TotalPlants int                  `json:"totalPlants"`
+	Stops       []*WateringRouteStop `json:"stops"`
+}
+
+// NotificationTemplate represents the message template of a notification type in a language
+type NotificationTemplate struct {
+	Type      NotificationType `json:"type" db:"type"`
+	Language  Language         `json:"language" db:"language"`
+	Body      string           `json:"body" db:"body"`
+	IsDefault bool             `json:"isDefault" db:"-"` // true when the built-in template is used
+	UpdatedAt *time.Time       `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// NotificationTemplateData holds the variables available to notification templates
+type NotificationTemplateData struct {
+	PlantName string
+	Location  string
+	DueDate   string
+	Payload   NotificationPayload
+}
+
+// WateringDigest represents the plants a user who gets watering reminders by email needs to water today
+type WateringDigest struct {
+	UserID   uuid.UUID
+	Name     string
+	Email    string
+	Language Language
+	Plants   []*WateringDigestPlant
+}
+
+// WateringDigestPlant represents a plant listed in a watering reminder email
+type WateringDigestPlant struct {
+	PlantID      uuid.UUID
+	Name         string
+	Location     *string
+	NextWatering time.Time
+}
+
+// OutdoorLocation is a location of a user that is outdoors, such as a balcony or a garden. Watering
+// reminders of the plants there follow the weather at its coordinates.
+type OutdoorLocation struct {
+	UserID    uuid.UUID `json:"-" db:"user_id"`
+	Location  string    `json:"location" db:"location" validate:"required,max=100"`
+	Latitude  float64   `json:"latitude" db:"latitude" validate:"min=-90,max=90"`
+	Longitude float64   `json:"longitude" db:"longitude" validate:"min=-180,max=180"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Home groups the rooms of a user who keeps plants in several places, e.g. a flat and a dacha. Its
+// outdoor rooms follow the weather at its coordinates and the watering reminders of its plants wait
+// for daytime in its timezone.
+type Home struct {
+	ID           uuid.UUID      `json:"id" db:"id"`
+	UserID       uuid.UUID      `json:"-" db:"user_id"`
+	Name         string         `json:"name" db:"name" validate:"required,max=100"`
+	Timezone     string         `json:"timezone" db:"timezone" validate:"required,max=64"` // IANA name, e.g. Europe/Moscow
+	Latitude     *float64       `json:"latitude,omitempty" db:"latitude" validate:"omitempty,min=-90,max=90"`
+	Longitude    *float64       `json:"longitude,omitempty" db:"longitude" validate:"omitempty,min=-180,max=180"`
+	OutdoorRooms pq.StringArray `json:"outdoorRooms" db:"outdoor_rooms" validate:"max=50,dive,required,max=100"` // rooms following the weather at the coordinates
+	PlantCount   int            `json:"plantCount" db:"plant_count"`
+	Current      bool           `json:"current" db:"current"` // the user is staying at the home
+	CreatedAt    time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time      `json:"updatedAt" db:"updated_at"`
+}
+
+// HasOutdoorRoom reports whether a room of the home is outdoors, regardless of case and surrounding
+// spaces
+func (h *Home) HasOutdoorRoom(room string) bool {
+	room = strings.ToLower(strings.TrimSpace(room))
+	for _, outdoor := range h.OutdoorRooms {
+		if strings.ToLower(strings.TrimSpace(outdoor)) == room {
+			return true
+		}
+	}
+	return false
+}
+
+// AssignHomePlantsRequest represents a request to move plants of the collection to a home
+type AssignHomePlantsRequest struct {
+	PlantIDs []uuid.UUID `json:"plantIds" validate:"required,min=1,max=500"`
+}
+
+// CurrentHomeRequest represents a request to mark the home the user is staying at; a nil home
+// reminds of the plants in every home
+type CurrentHomeRequest struct {
+	HomeID *uuid.UUID `json:"homeId"`
+}
+
+// WeatherReport is the weather at a place over the last days
+type WeatherReport struct {
+	RainfallMM     float64 // total precipitation
+	MaxTemperature float64 // highest temperature in °C
+}
+
+// WateringWeatherAction is what the weather did to the watering reminder of an outdoor plant
+type WateringWeatherAction string
+
+const (
+	WateringWeatherKept     WateringWeatherAction = "KEPT"     // the reminder was sent when due
+	WateringWeatherSkipped  WateringWeatherAction = "SKIPPED"  // rain watered the plant, the reminder moved to the next day
+	WateringWeatherAdvanced WateringWeatherAction = "ADVANCED" // heat dries the soil, the reminder was sent up to a day early
+)
+
+// WateringWeatherDecision records why the watering reminder of an outdoor plant was kept, skipped or
+// advanced
+type WateringWeatherDecision struct {
+	Action         WateringWeatherAction
+	RainfallMM     float64 // over the days the weather was looked at
+	MaxTemperature float64
+}
+
+// NotificationAction represents an action a notification lets its recipient take without signing in
+type NotificationAction string
+
+const (
+	NotificationActionWater NotificationAction = "WATER"
+)
+
+// NotificationActionToken represents the claims of a signed single-use token of a notification action
+type NotificationActionToken struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"userId"`
+	PlantID   uuid.UUID          `json:"plantId"`
+	Action    NotificationAction `json:"action"`
+	ExpiresAt int64              `json:"exp"` // Unix time
+}
+
+// NotificationActionResult represents the result of a performed notification action
+type NotificationActionResult struct {
+	Action NotificationAction `json:"action"`
+	Plant  *Plant             `json:"plant"`
+}
+
+// UpdateNotificationTemplateRequest represents a request to override a notification template
+type UpdateNotificationTemplateRequest struct {
+	Body string `json:"body" validate:"required,max=1000"`
+}
+
+// TokenScope represents a permission granted to a personal access token
+type TokenScope string
+
+const (
+	TokenScopePlantsRead  TokenScope = "plants:read"
+	TokenScopePlantsWater TokenScope = "plants:water"
+)
+
+// PersonalAccessToken represents a scoped token issued by a user for automation (e.g. Home Assistant)
+type PersonalAccessToken struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	UserID      uuid.UUID      `json:"userId" db:"user_id"`
+	Name        string         `json:"name" db:"name"`
+	TokenHash   string         `json:"-" db:"token_hash"`
+	TokenPrefix string         `json:"tokenPrefix" db:"token_prefix"`
+	Scopes      pq.StringArray `json:"scopes" db:"scopes"`
+	ExpiresAt   *time.Time     `json:"expiresAt,omitempty" db:"expires_at"`
+	LastUsedAt  *time.Time     `json:"lastUsedAt,omitempty" db:"last_used_at"`
+	RevokedAt   *time.Time     `json:"revokedAt,omitempty" db:"revoked_at"`
+	CreatedAt   time.Time      `json:"createdAt" db:"created_at"`
+}
+
+// CreatePersonalAccessTokenRequest represents a request to create a personal access token
+type CreatePersonalAccessTokenRequest struct {
+	Name          string       `json:"name" validate:"required,max=100"`
+	Scopes        []TokenScope `json:"scopes" validate:"required,min=1,dive,oneof=plants:read plants:water"`
+	ExpiresInDays *int         `json:"expiresInDays,omitempty" validate:"omitempty,min=1,max=365"`
+}
+
+// CreatePersonalAccessTokenResponse represents a newly created token; the raw token is only returned once
+type CreatePersonalAccessTokenResponse struct {
+	Token PersonalAccessToken `json:"token"`
+	Value string              `json:"value"`
+}
+
+// AnalyticsEventType represents the type of a client analytics event
+type AnalyticsEventType string
+
+const (
+	AnalyticsEventTypeScreenView          AnalyticsEventType = "SCREEN_VIEW"
+	AnalyticsEventTypeRecommendationClick AnalyticsEventType = "RECOMMENDATION_CLICK"
+)
+
+// AnalyticsEvent represents an analytics event reported by a client
+type AnalyticsEvent struct {
+	ID              uuid.UUID          `json:"id" db:"id"`
+	UserID          *uuid.UUID         `json:"userId,omitempty" db:"user_id"` // set from the authenticated user, if any
+	SessionID       string             `json:"sessionId" db:"session_id" validate:"required,max=100"`
+	Type            AnalyticsEventType `json:"type" db:"type" validate:"required,oneof=SCREEN_VIEW RECOMMENDATION_CLICK"`
+	Screen          *string            `json:"screen,omitempty" db:"screen" validate:"omitempty,max=100"`
+	PlantID         *uuid.UUID         `json:"plantId,omitempty" db:"plant_id"`
+	QuestionnaireID *uuid.UUID         `json:"questionnaireId,omitempty" db:"questionnaire_id"`
+	Variant         *string            `json:"variant,omitempty" db:"variant" validate:"omitempty,max=50"` // A/B test variant the client is in
+	OccurredAt      time.Time          `json:"occurredAt" db:"occurred_at" validate:"required"`
+	ReceivedAt      time.Time          `json:"receivedAt" db:"received_at"`
+}
+
+// TrackEventsRequest represents a batch of analytics events sent by a client
+type TrackEventsRequest struct {
+	Events []*AnalyticsEvent `json:"events" validate:"required,min=1,max=100,dive,required"`
+}
+
+// TrackEventsResponse represents the result of accepting a batch of analytics events
+type TrackEventsResponse struct {
+	Accepted int `json:"accepted"`
+}
+
+// AnalyticsEventCount represents the number of events of a type and A/B variant on a day
+type AnalyticsEventCount struct {
+	Day     time.Time          `json:"day" db:"day"`
+	Type    AnalyticsEventType `json:"type" db:"type"`
+	Variant *string            `json:"variant,omitempty" db:"variant"`
+	Count   int                `json:"count" db:"count"`
+}
+
+// ReconciliationCheck represents a consistency check of derived data
+type ReconciliationCheck string
+
+const (
+	// ReconciliationCheckNextWatering finds next watering dates that do not match the last watering and the plant's frequency
+	ReconciliationCheckNextWatering ReconciliationCheck = "NEXT_WATERING"
+	// ReconciliationCheckOrphanedCareTasks finds completed care tasks of plants no longer in the user's collection
+	ReconciliationCheckOrphanedCareTasks ReconciliationCheck = "ORPHANED_CARE_TASKS"
+	// ReconciliationCheckOrphanedNotifications finds unread watering notifications of plants no longer in the user's collection
+	ReconciliationCheckOrphanedNotifications ReconciliationCheck = "ORPHANED_NOTIFICATIONS"
+	// ReconciliationCheckNotificationBells finds notification bells whose unread count or latest notification do not match the notifications
+	ReconciliationCheckNotificationBells ReconciliationCheck = "NOTIFICATION_BELLS"
+)
+
+// ReconciliationCorrection represents the number of inconsistencies a check found and repaired
+type ReconciliationCorrection struct {
+	Check       ReconciliationCheck `json:"check" db:"check_name"`
+	Corrections int                 `json:"corrections" db:"corrections"`
+}
+
+// ReconciliationRun represents a run of the reconciliation job and the corrections it made
+type ReconciliationRun struct {
+	ID               uuid.UUID                   `json:"id" db:"id"`
+	DryRun           bool                        `json:"dryRun" db:"dry_run"` // inconsistencies were only counted
+	StartedAt        time.Time                   `json:"startedAt" db:"started_at"`
+	FinishedAt       time.Time                   `json:"finishedAt" db:"finished_at"`
+	TotalCorrections int                         `json:"totalCorrections" db:"total_corrections"`
+	Error            *string                     `json:"error,omitempty" db:"error"`
+	Corrections      []*ReconciliationCorrection `json:"corrections" db:"-"`
+}
+
+// CareFeedbackRating represents how hard a plant was to care for compared to what the owner expected
+type CareFeedbackRating string
+
+const (
+	CareFeedbackRatingEasier     CareFeedbackRating = "EASIER"
+	CareFeedbackRatingAsExpected CareFeedbackRating = "AS_EXPECTED"
+	CareFeedbackRatingHarder     CareFeedbackRating = "HARDER"
+)
+
+// CareFeedback represents an owner's answer about how hard a plant was to care for
+type CareFeedback struct {
+	ID        uuid.UUID          `json:"id" db:"id"`
+	UserID    uuid.UUID          `json:"userId" db:"user_id"`
+	PlantID   uuid.UUID          `json:"plantId" db:"plant_id"`
+	Rating    CareFeedbackRating `json:"rating" db:"rating"`
+	CreatedAt time.Time          `json:"createdAt" db:"created_at"`
+}
+
+// SubmitCareFeedbackRequest represents a request to answer the care difficulty question
+type SubmitCareFeedbackRequest struct {
+	Rating CareFeedbackRating `json:"rating" validate:"required,oneof=EASIER AS_EXPECTED HARDER"`
+}
+
+// CareFeedbackTotals represents the answers collected for a plant
+type CareFeedbackTotals struct {
+	PlantID    uuid.UUID `db:"plant_id"`
+	Editorial  *int      `db:"difficulty"`
+	Easier     int       `db:"easier"`
+	AsExpected int       `db:"as_expected"`
+	Harder     int       `db:"harder"`
+}
+
+// CommunityDifficulty represents the difficulty of a plant recalibrated from owner feedback
+type CommunityDifficulty struct {
+	PlantID   uuid.UUID `json:"-" db:"plant_id"`
+	Score     float64   `json:"score" db:"score"` // 1-5 scale
+	Responses int       `json:"responses" db:"responses"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// PlantDifficulty represents the editorial and community difficulty of a plant
+type PlantDifficulty struct {
+	PlantID   uuid.UUID            `json:"plantId"`
+	Editorial *int                 `json:"editorial,omitempty"` // 1-5 scale, nil when not rated
+	Community *CommunityDifficulty `json:"community,omitempty"` // nil until enough owners answered
+}
+
+// UpdatePlantDifficultyRequest represents a request to set the editorial difficulty of a plant
+type UpdatePlantDifficultyRequest struct {
+	Difficulty int `json:"difficulty" validate:"required,min=1,max=5"`
+}
+
+// DiagnosisFinding represents a condition detected on a plant photo
+type DiagnosisFinding struct {
+	Condition  string  `json:"condition" db:"condition"`
+	Confidence float64 `json:"confidence" db:"confidence"` // 0-1
+}
+
+// PlantDiagnosis represents a photo diagnosis of a plant in a user's collection
+type PlantDiagnosis struct {
+	ID        uuid.UUID           `json:"id" db:"id"`
+	UserID    uuid.UUID           `json:"userId" db:"user_id"`
+	PlantID   uuid.UUID           `json:"plantId" db:"plant_id"`
+	Provider  string              `json:"provider" db:"provider"` // vision provider that made the diagnosis
+	CreatedAt time.Time           `json:"createdAt" db:"created_at"`
+	Findings  []*DiagnosisFinding `json:"findings" db:"-"` // most likely first
+}
+
+// QuickRecommendationsRequest represents the query parameters of a quick recommendation request
+type QuickRecommendationsRequest struct {
+	Light        SunlightLevel `validate:"required,oneof=LOW MEDIUM HIGH"`
+	PetFriendly  bool
+	HasChildren  bool
+	Effort       int  `validate:"required,min=1,max=5"` // care level, 1-5 scale
+	Tags         []string `validate:"omitempty,max=10"` // tags or categories the plants should have
+	Count        *int `validate:"omitempty,min=1,max=20"`
+	MaxPerFamily *int `validate:"omitempty,min=1,max=20"`
+}
+
+// QuickRecommendationsResponse represents the response of a quick recommendation request
+type QuickRecommendationsResponse struct {
+	QuestionnaireID *uuid.UUID `json:"questionnaireId,omitempty"` // set when the request was saved
+	Plants          []*Plant   `json:"plants"`
+}
+
+// TriageSymptom represents a symptom of a struggling plant
+type TriageSymptom string
+
+const (
+	TriageSymptomYellowLeaves TriageSymptom = "YELLOW_LEAVES"
+	TriageSymptomDrooping     TriageSymptom = "DROOPING"
+	TriageSymptomBrownSpots   TriageSymptom = "BROWN_SPOTS"
+	TriageSymptomBrownTips    TriageSymptom = "BROWN_TIPS"
+	TriageSymptomLeafDrop     TriageSymptom = "LEAF_DROP"
+	TriageSymptomMushyStem    TriageSymptom = "MUSHY_STEM"
+	TriageSymptomLeggyGrowth  TriageSymptom = "LEGGY_GROWTH"
+	TriageSymptomPaleLeaves   TriageSymptom = "PALE_LEAVES"
+)
+
+// TriageCause represents a likely cause of a plant's symptoms
+type TriageCause string
+
+const (
+	TriageCauseOverwatering  TriageCause = "OVERWATERING"
+	TriageCauseUnderwatering TriageCause = "UNDERWATERING"
+	TriageCauseLowLight      TriageCause = "LOW_LIGHT"
+	TriageCauseSunburn       TriageCause = "SUNBURN"
+	TriageCauseLowHumidity   TriageCause = "LOW_HUMIDITY"
+	TriageCauseFungalDisease TriageCause = "FUNGAL_DISEASE"
+)
+
+// TriageRequest represents a request to triage a struggling plant
+type TriageRequest struct {
+	Symptoms []TriageSymptom `json:"symptoms" validate:"required,min=1,max=8,dive,oneof=YELLOW_LEAVES DROOPING BROWN_SPOTS BROWN_TIPS LEAF_DROP MUSHY_STEM LEGGY_GROWTH PALE_LEAVES"`
+	PlantID  *uuid.UUID      `json:"plantId,omitempty"` // plant in the user's collection, if known
+}
+
+// TriageCauseResult represents a ranked likely cause with the actions to take right away
+type TriageCauseResult struct {
+	Cause   TriageCause `json:"cause"`
+	Score   float64     `json:"score"` // share of the evidence, 0-1
+	Actions []string    `json:"actions"`
+}
+
+// TriageResult represents the result of a plant triage
+type TriageResult struct {
+	PlantID  *uuid.UUID           `json:"plantId,omitempty"`
+	Causes   []*TriageCauseResult `json:"causes"`             // most likely first
+	FollowUp *string              `json:"followUp,omitempty"` // advice from Yandex GPT, when available
+}
+
+// JournalEntryType represents the type of a plant journal entry
+type JournalEntryType string
+
+const (
+	JournalEntryTypeTriage JournalEntryType = "TRIAGE"
+)
+
+// PlantJournalEntry represents an entry in the journal of a plant in a user's collection
+type PlantJournalEntry struct {
+	ID        uuid.UUID        `json:"id" db:"id"`
+	UserID    uuid.UUID        `json:"userId" db:"user_id"`
+	PlantID   uuid.UUID        `json:"plantId" db:"plant_id"`
+	Type      JournalEntryType `json:"type" db:"type"`
+	Text      string           `json:"text" db:"text"`
+	CreatedAt time.Time        `json:"createdAt" db:"created_at"`
+}
+
+// PlantEventType represents the type of a plant lifecycle event
+type PlantEventType string
+
+const (
+	PlantEventTypeWatered    PlantEventType = "WATERED"
+	PlantEventTypeFertilized PlantEventType = "FERTILIZED"
+	PlantEventTypeRepotted   PlantEventType = "REPOTTED"
+	PlantEventTypeMoved      PlantEventType = "MOVED"
+	PlantEventTypePhotoAdded PlantEventType = "PHOTO_ADDED"
+)
+
+// PlantEvent represents a lifecycle event of a plant in a user's collection. It is the canonical
+// record the journal timeline and the payloads sent to external automation are built from.
+type PlantEvent struct {
+	ID         uuid.UUID         `json:"id" db:"id"`
+	Seq        int64             `json:"-" db:"seq"` // position in the event stream, encoded in cursors
+	UserID     uuid.UUID         `json:"userId" db:"user_id"`
+	PlantID    uuid.UUID         `json:"plantId" db:"plant_id"`
+	Type       PlantEventType    `json:"type" db:"type"`
+	Payload    PlantEventPayload `json:"payload" db:"payload"`
+	OccurredAt time.Time         `json:"occurredAt" db:"occurred_at"`
+}
+
+// PlantEventPayload holds the details of a plant event, e.g. the previous and new location of a
+// MOVED event. It is stored as a JSON object.
+type PlantEventPayload map[string]interface{}
+
+// Value implements driver.Valuer
+func (p PlantEventPayload) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan implements sql.Scanner
+func (p *PlantEventPayload) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*p = PlantEventPayload{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into PlantEventPayload", src)
+	}
+	payload := PlantEventPayload{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+	*p = payload
+	return nil
+}
+
+// PlantEventFilter represents a page request of a plant's event stream
+type PlantEventFilter struct {
+	Cursor string           // opaque cursor of the last event seen, empty to start from the oldest event
+	Limit  int              // maximum number of events
+	Types  []PlantEventType // only events of these types, all types when empty
+}
+
+// PlantEventPage represents a page of a plant's event stream, oldest event first
+type PlantEventPage struct {
+	Events     []*PlantEvent `json:"events"`
+	NextCursor string        `json:"nextCursor"` // pass back to get the events after this page; stays valid when there are none yet
+	HasMore    bool          `json:"hasMore"`
+}
+
+// SupportTicketStatus represents the triage status of a support ticket
+type SupportTicketStatus string
+
+const (
+	SupportTicketStatusOpen       SupportTicketStatus = "OPEN"
+	SupportTicketStatusInProgress SupportTicketStatus = "IN_PROGRESS"
+	SupportTicketStatusResolved   SupportTicketStatus = "RESOLVED"
+	SupportTicketStatusClosed     SupportTicketStatus = "CLOSED"
+)
+
+// SupportTicket represents a message to support from a user
+type SupportTicket struct {
+	ID         uuid.UUID            `json:"id" db:"id"`
+	UserID     uuid.UUID            `json:"userId" db:"user_id"`
+	PlantID    *uuid.UUID           `json:"plantId,omitempty" db:"plant_id"` // the plant the message is about
+	Message    string               `json:"message" db:"message"`
+	Context    SupportTicketContext `json:"context" db:"context"`
+	Status     SupportTicketStatus  `json:"status" db:"status"`
+	AdminNote  *string              `json:"adminNote,omitempty" db:"admin_note"`
+	CreatedAt  time.Time            `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time            `json:"updatedAt" db:"updated_at"`
+	ResolvedAt *time.Time           `json:"resolvedAt,omitempty" db:"resolved_at"`
+}
+
+// SupportTicketContext is the snapshot of the sender's app and plant taken when a ticket is
+// submitted. It is stored as a JSON object.
+type SupportTicketContext struct {
+	AppVersion   string              `json:"appVersion,omitempty"`
+	Platform     string              `json:"platform,omitempty"`
+	UserAgent    string              `json:"userAgent,omitempty"`
+	Language     Language            `json:"language,omitempty"`
+	RecentErrors []string            `json:"recentErrors,omitempty"` // errors the app showed lately, most recent last
+	Plant        *SupportTicketPlant `json:"plant,omitempty"`
+}
+
+// SupportTicketPlant is the state of the plant in question when a ticket is submitted
+type SupportTicketPlant struct {
+	Name         string     `json:"name"`
+	Location     *string    `json:"location,omitempty"`
+	LastWatered  *time.Time `json:"lastWatered,omitempty"`
+	NextWatering *time.Time `json:"nextWatering,omitempty"`
+	AddedAt      time.Time  `json:"addedAt"`
+}
+
+// Value implements driver.Valuer
+func (c SupportTicketContext) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner
+func (c *SupportTicketContext) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*c = SupportTicketContext{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into SupportTicketContext", src)
+	}
+	var snapshot SupportTicketContext
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+	*c = snapshot
+	return nil
+}
+
+// CreateSupportTicketRequest represents a request to contact support. The app version, user agent
+// and language are taken from the request itself.
+type CreateSupportTicketRequest struct {
+	Message      string     `json:"message" validate:"required,min=1,max=5000"`
+	PlantID      *uuid.UUID `json:"plantId,omitempty"`
+	Platform     string     `json:"platform,omitempty" validate:"max=50"`
+	RecentErrors []string   `json:"recentErrors,omitempty" validate:"max=20,dive,max=500"`
+}
+
+// UpdateSupportTicketRequest represents an admin's triage of a support ticket
+type UpdateSupportTicketRequest struct {
+	Status    SupportTicketStatus `json:"status" validate:"required,oneof=OPEN IN_PROGRESS RESOLVED CLOSED"`
+	AdminNote *string             `json:"adminNote,omitempty" validate:"omitempty,max=2000"`
+}
+
+// SupportTicketFilter represents a page request of support tickets
+type SupportTicketFilter struct {
+	Status   SupportTicketStatus // all statuses when empty
+	Page     int
+	PageSize int
+}
+
+// SupportTicketListResponse represents a page of support tickets, oldest first
+type SupportTicketListResponse struct {
+	Tickets []*SupportTicket `json:"tickets"`
+	Total   int              `json:"total"`
+}
+
+// AnonymizationAction represents what a run of the anonymization job did to a dormant account
+type AnonymizationAction string
+
+const (
+	// AnonymizationActionWarned means the owner was emailed that the account will be anonymized
+	AnonymizationActionWarned AnonymizationAction = "WARNED"
+	// AnonymizationActionAnonymized means the personal data of the account was removed
+	AnonymizationActionAnonymized AnonymizationAction = "ANONYMIZED"
+)
+
+// InactiveAccount represents an account without activity for longer than the inactivity period
+type InactiveAccount struct {
+	UserID       uuid.UUID `db:"id"`
+	Name         string    `db:"name"`
+	Email        string    `db:"email"`
+	Language     Language  `db:"language"`
+	LastActiveAt time.Time `db:"last_active_at"`
+}
+
+// AnonymizationAccount represents an account processed by a run of the anonymization job
+type AnonymizationAccount struct {
+	UserID uuid.UUID           `json:"userId" db:"user_id"`
+	Action AnonymizationAction `json:"action" db:"action"`
+}
+
+// AnonymizationRun represents a run of the anonymization job and the accounts it processed
+type AnonymizationRun struct {
+	ID         uuid.UUID               `json:"id" db:"id"`
+	DryRun     bool                    `json:"dryRun" db:"dry_run"` // accounts were only listed
+	StartedAt  time.Time               `json:"startedAt" db:"started_at"`
+	FinishedAt time.Time               `json:"finishedAt" db:"finished_at"`
+	Warned     int                     `json:"warned" db:"warned"`
+	Anonymized int                     `json:"anonymized" db:"anonymized"`
+	Error      *string                 `json:"error,omitempty" db:"error"`
+	Accounts   []*AnonymizationAccount `json:"accounts" db:"-"`
+}
+
+// AccountMergeMethod is how the merge of two accounts was authorized
+type AccountMergeMethod string
+
+const (
+	// AccountMergeMethodAdmin is a merge an admin performed on the owner's request
+	AccountMergeMethodAdmin AccountMergeMethod = "ADMIN"
+)
+
+// AccountMergeRequest represents the request body for merging a source account into a target account
+type AccountMergeRequest struct {
+	SourceUserID uuid.UUID `json:"sourceUserId" validate:"required"`
+	TargetUserID uuid.UUID `json:"targetUserId" validate:"required"`
+}
+
+// AccountMerge represents the merge of a source account into a target account
+type AccountMerge struct {
+	ID           uuid.UUID           `json:"id" db:"id"`
+	SourceUserID uuid.UUID           `json:"sourceUserId" db:"source_user_id"`
+	TargetUserID uuid.UUID           `json:"targetUserId" db:"target_user_id"`
+	MergedBy     *uuid.UUID          `json:"mergedBy,omitempty" db:"merged_by"`
+	Method       AccountMergeMethod  `json:"method" db:"method"`
+	DryRun       bool                `json:"dryRun" db:"-"` // the merge was rolled back after counting the rows
+	Summary      AccountMergeSummary `json:"summary" db:"summary"`
+	CreatedAt    time.Time           `json:"createdAt" db:"created_at"`
+}
+
+// AccountMergeSummary counts per table the rows moved to the target account and the rows of the
+// source account dropped or folded into one of the target account's because both had one
+type AccountMergeSummary struct {
+	Moved     map[string]int64 `json:"moved"`
+	Conflicts map[string]int64 `json:"conflicts"`
+}
+
+// Value implements driver.Valuer
+func (s AccountMergeSummary) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan implements sql.Scanner
+func (s *AccountMergeSummary) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*s = AccountMergeSummary{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into AccountMergeSummary", src)
+	}
+	var summary AccountMergeSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return err
+	}
+	*s = summary
+	return nil
+}
+
+// Hemisphere represents the hemisphere a plant is kept in, which shifts its seasons
+type Hemisphere string
+
+const (
+	HemisphereNorthern Hemisphere = "NORTHERN"
+	HemisphereSouthern Hemisphere = "SOUTHERN"
+)
+
+// CarePlanRequest represents a request to generate a care plan for a plant in the user's collection
+type CarePlanRequest struct {
+	Hemisphere *Hemisphere `json:"hemisphere,omitempty" validate:"omitempty,oneof=NORTHERN SOUTHERN"` // NORTHERN by default
+}
+
+// CarePlanMonth represents one month of a care plan
+type CarePlanMonth struct {
+	Month             time.Time `json:"month" db:"month"` // first day of the month
+	Fertilize         bool      `json:"fertilize" db:"fertilize"`
+	Repot             bool      `json:"repot" db:"repot"`
+	Dormant           bool      `json:"dormant" db:"dormant"`
+	WateringFrequency int       `json:"wateringFrequency" db:"watering_frequency"` // in days, adjusted for dormancy
+	Notes             string    `json:"notes" db:"notes"`
+}
+
+// CarePlan represents a 12-month care plan of a plant in a user's collection
+type CarePlan struct {
+	ID         uuid.UUID        `json:"id" db:"id"`
+	UserID     uuid.UUID        `json:"userId" db:"user_id"`
+	PlantID    uuid.UUID        `json:"plantId" db:"plant_id"`
+	Hemisphere Hemisphere       `json:"hemisphere" db:"hemisphere"`
+	StartsOn   time.Time        `json:"startsOn" db:"starts_on"`
+	Summary    *string          `json:"summary,omitempty" db:"summary"` // advice from Yandex GPT, when available
+	Months     []*CarePlanMonth `json:"months" db:"-"`
+	CreatedAt  time.Time        `json:"createdAt" db:"created_at"`
+}
+
+// CarePlanReminder represents a notification scheduled by a care plan for the start of a repotting,
+// fertilizing or dormancy window
+type CarePlanReminder struct {
+	ID     uuid.UUID        `json:"id" db:"id"`
+	PlanID uuid.UUID        `json:"planId" db:"plan_id"`
+	Type   NotificationType `json:"type" db:"type"`
+	DueOn  time.Time        `json:"dueOn" db:"due_on"`
+	SentAt *time.Time       `json:"sentAt,omitempty" db:"sent_at"`
+	// Plant the reminder is about, filled when reminders are due
+	UserPlant *UserPlant `json:"-" db:"-"`
+}
+
+// SeasonalGuideList names a list of the seasonal guide
+type SeasonalGuideList string
+
+const (
+	SeasonalGuideListBuy       SeasonalGuideList = "BUY"       // plants best bought this month
+	SeasonalGuideListPropagate SeasonalGuideList = "PROPAGATE" // plants best propagated this month
+	SeasonalGuideListRepot     SeasonalGuideList = "REPOT"     // plants best repotted this month
+)
+
+// SeasonalGuideReason explains why a plant is in a list of the seasonal guide
+type SeasonalGuideReason string
+
+const (
+	SeasonalGuideReasonPinned       SeasonalGuideReason = "PINNED"        // an admin pinned the plant to the list
+	SeasonalGuideReasonInShop       SeasonalGuideReason = "IN_SHOP"       // sold by a shop and settles in easily this time of the year
+	SeasonalGuideReasonColdHardy    SeasonalGuideReason = "COLD_HARDY"    // sold by a shop and tolerates the cold on the way home
+	SeasonalGuideReasonGrowthPeak   SeasonalGuideReason = "GROWTH_PEAK"   // cuttings root best while the plant grows fastest
+	SeasonalGuideReasonRepotWindow  SeasonalGuideReason = "REPOT_WINDOW"  // early spring, when plants are repotted
+	SeasonalGuideReasonFastGrower   SeasonalGuideReason = "FAST_GROWER"   // heavy feeder outgrowing its pot during the growing season
+)
+
+// SeasonalGuideOverride represents a plant an admin pinned to a list of the seasonal guide of a month,
+// or hid from it
+type SeasonalGuideOverride struct {
+	ID        uuid.UUID         `json:"id" db:"id"`
+	Month     int               `json:"month" db:"month"`
+	List      SeasonalGuideList `json:"list" db:"list"`
+	PlantID   uuid.UUID         `json:"plantId" db:"plant_id"`
+	Hidden    bool              `json:"hidden" db:"hidden"`
+	Position  int               `json:"position" db:"position"` // pinned plants are shown by position, before the others
+	Note      *string           `json:"note,omitempty" db:"note"` // shown with a pinned plant
+	CreatedAt time.Time         `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time         `json:"updatedAt" db:"updated_at"`
+}
+
+// SeasonalGuideOverrideRequest represents a request to pin a plant to a list of the seasonal guide of
+// a month or to hide it from it, replacing the previous choice for the plant
+type SeasonalGuideOverrideRequest struct {
+	Month    int               `json:"month" validate:"required,min=1,max=12"`
+	List     SeasonalGuideList `json:"list" validate:"required,oneof=BUY PROPAGATE REPOT"`
+	PlantID  uuid.UUID         `json:"plantId" validate:"required"`
+	Hidden   bool              `json:"hidden"`
+	Position int               `json:"position" validate:"min=0"`
+	Note     *string           `json:"note,omitempty" validate:"omitempty,max=200"`
+}
+
+// SeasonalGuideItem represents a plant in a list of the seasonal guide
+type SeasonalGuideItem struct {
+	Plant  *Plant              `json:"plant"`
+	Reason SeasonalGuideReason `json:"reason"`
+	Note   *string             `json:"note,omitempty"` // the admin's note of a pinned plant
+}
+
+// SeasonalGuide represents the plants best bought, propagated and repotted in a month, for the
+// seasonal carousel of the app
+type SeasonalGuide struct {
+	Month       int                  `json:"month"`
+	Hemisphere  Hemisphere           `json:"hemisphere"`
+	Buy         []*SeasonalGuideItem `json:"buy"`
+	Propagate   []*SeasonalGuideItem `json:"propagate"`
+	Repot       []*SeasonalGuideItem `json:"repot"`
+	GeneratedAt time.Time            `json:"generatedAt"`
+}
+
+// CampaignEnrollment represents a user receiving the notifications of a campaign, one step at a time
+type CampaignEnrollment struct {
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	Campaign    string     `json:"campaign" db:"campaign"`
+	PlantID     *uuid.UUID `json:"plantId,omitempty" db:"plant_id"` // plant the steps about a plant link to
+	NextStep    int        `json:"nextStep" db:"next_step"`
+	EnrolledAt  time.Time  `json:"enrolledAt" db:"enrolled_at"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" db:"completed_at"`
+	// Language of the user, filled when due steps are looked up
+	UserLanguage Language `json:"-" db:"language"`
+}
+
+// AnnouncementSegment represents the users an announcement is broadcast to
+type AnnouncementSegment string
+
+const (
+	AnnouncementSegmentAll         AnnouncementSegment = "ALL"
+	AnnouncementSegmentPlantOwners AnnouncementSegment = "PLANT_OWNERS" // users with at least one plant in their collection
+	AnnouncementSegmentCity        AnnouncementSegment = "CITY"         // users whose profile names the city
+)
+
+// AnnouncementStatus represents the progress of the broadcast of an announcement
+type AnnouncementStatus string
+
+const (
+	AnnouncementStatusPending   AnnouncementStatus = "PENDING" // waiting for the job to send the first batch
+	AnnouncementStatusSending   AnnouncementStatus = "SENDING"
+	AnnouncementStatusCompleted AnnouncementStatus = "COMPLETED"
+	AnnouncementStatusCanceled  AnnouncementStatus = "CANCELED" // stopped by an admin; batches already sent stay
+)
+
+// AnnouncementMessages holds the message of an announcement by language
+type AnnouncementMessages map[Language]string
+
+// Value implements driver.Valuer
+func (m AnnouncementMessages) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner
+func (m *AnnouncementMessages) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into AnnouncementMessages", src)
+	}
+	messages := AnnouncementMessages{}
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return err
+	}
+	*m = messages
+	return nil
+}
+
+// Announcement represents a message admins broadcast to a segment of the users as notifications
+type Announcement struct {
+	ID         uuid.UUID            `json:"id" db:"id"`
+	Segment    AnnouncementSegment  `json:"segment" db:"segment"`
+	City       *string              `json:"city,omitempty" db:"city"` // set for the CITY segment
+	Messages   AnnouncementMessages `json:"messages" db:"messages"`
+	Status     AnnouncementStatus   `json:"status" db:"status"`
+	Recipients int                  `json:"recipients" db:"recipients"` // users in the segment when the announcement was created
+	Sent       int                  `json:"sent" db:"sent"`
+	Failed     int                  `json:"failed" db:"failed"`
+	Read       int                  `json:"read" db:"read"` // notifications of the announcement their recipients read
+	LastUserID *uuid.UUID           `json:"-" db:"last_user_id"`
+	CreatedBy  *uuid.UUID           `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt  time.Time            `json:"createdAt" db:"created_at"`
+	StartedAt  *time.Time           `json:"startedAt,omitempty" db:"started_at"`
+	FinishedAt *time.Time           `json:"finishedAt,omitempty" db:"finished_at"` // set once the announcement is completed or canceled
+}
+
+// AnnouncementRecipient represents a user an announcement is sent to
+type AnnouncementRecipient struct {
+	UserID   uuid.UUID `db:"id"`
+	Language Language  `db:"language"`
+}
+
+// CreateAnnouncementRequest represents an admin request to broadcast an announcement. The message in
+// Russian, the default language, is required; users of other languages without a message get it.
+type CreateAnnouncementRequest struct {
+	Segment  AnnouncementSegment  `json:"segment" validate:"required,oneof=ALL PLANT_OWNERS CITY"`
+	City     *string              `json:"city,omitempty" validate:"required_if=Segment CITY,omitempty,min=1,max=255"`
+	Messages AnnouncementMessages `json:"messages" validate:"required,dive,keys,oneof=RUSSIAN ENGLISH,endkeys,required,max=1000"`
+}
+
+// PlantFilter represents the filters and page of a plant list request
+type PlantFilter struct {
+	Sunlight    *SunlightLevel `validate:"omitempty,oneof=LOW MEDIUM HIGH"`
+	Humidity    *HumidityLevel `validate:"omitempty,oneof=LOW MEDIUM HIGH"`
+	PetFriendly *bool
+	MinPrice    *float64   `validate:"omitempty,min=0"`
+	MaxPrice    *float64   `validate:"omitempty,min=0"`
+	ShopID      *uuid.UUID // plants the shop sells, directly or through its offers
+	Page        int        // 1-based; the first page when not positive
+	PageSize    int        // the default page size when not positive, capped at the maximum
+
+	IncludeDeleted bool         // include plants removed from the catalog
+	Status         *PlantStatus // plants in the review state; only published plants unless set or IncludeDeleted
+	Category       *string      // plants in the category with the slug
+	Tags           []string     // plants with all the tags
+}
+
+// PlantSearchQuery represents a catalog search: Text is matched against the names and description
+// of plants, the other fields filter them when set
+type PlantSearchQuery struct {
+	Text        string
+	Sunlight    *SunlightLevel
+	Humidity    *HumidityLevel
+	PetFriendly *bool
+	Family      *string
+	MinWatering *int // days between waterings
+	MaxWatering *int
+}
+
+// CircuitBreakerState represents the state of the Yandex GPT circuit breaker
+type CircuitBreakerState string
+
+const (
+	CircuitBreakerClosed   CircuitBreakerState = "CLOSED"
+	CircuitBreakerOpen     CircuitBreakerState = "OPEN"
+	CircuitBreakerHalfOpen CircuitBreakerState = "HALF_OPEN"
+)
+
+// CircuitBreakerStatus represents the current state of the Yandex GPT circuit breaker
+type CircuitBreakerStatus struct {
+	State               CircuitBreakerState `json:"state"`
+	ConsecutiveFailures int                 `json:"consecutiveFailures"`
+	OpenedAt            *time.Time          `json:"openedAt,omitempty"`
+}
+
+// LLMUsage represents the tokens consumed by a single Yandex GPT request
+type LLMUsage struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	UserID           *uuid.UUID `json:"userId,omitempty" db:"user_id"` // nil for requests not made on behalf of a user
+	InputTokens      int64      `json:"inputTokens" db:"input_tokens"`
+	CompletionTokens int64      `json:"completionTokens" db:"completion_tokens"`
+	CreatedAt        time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// LLMUsageTotals represents the Yandex GPT usage summed over a period
+type LLMUsageTotals struct {
+	Requests         int   `json:"requests" db:"requests"`
+	InputTokens      int64 `json:"inputTokens" db:"input_tokens"`
+	CompletionTokens int64 `json:"completionTokens" db:"completion_tokens"`
+}
+
+// LLMUserUsage represents the Yandex GPT tokens a user consumed over a period
+type LLMUserUsage struct {
+	UserID         uuid.UUID `json:"userId" db:"user_id"`
+	Requests       int       `json:"requests" db:"requests"`
+	Tokens         int64     `json:"tokens" db:"tokens"`
+	QuotaUsedRatio *float64  `json:"quotaUsedRatio,omitempty" db:"-"` // set when a user quota is configured
+}
+
+// LLMBudgetReport represents the Yandex GPT spend and quota consumption of the current month
+type LLMBudgetReport struct {
+	PeriodStart      time.Time            `json:"periodStart"`
+	GeneratedAt      time.Time            `json:"generatedAt"`
+	Requests         int                  `json:"requests"`
+	InputTokens      int64                `json:"inputTokens"`
+	CompletionTokens int64                `json:"completionTokens"`
+	Spend            float64              `json:"spend"`                     // in rubles
+	ProjectedSpend   float64              `json:"projectedSpend"`            // month-end spend at the current rate
+	MonthlyBudget    *float64             `json:"monthlyBudget,omitempty"`   // nil when no budget is configured
+	BudgetUsedRatio  *float64             `json:"budgetUsedRatio,omitempty"` // nil when no budget is configured
+	UserQuotaTokens  *int64               `json:"userQuotaTokens,omitempty"` // nil when users are not limited
+	UsersOverQuota   int                  `json:"usersOverQuota"`
+	TopUsers         []*LLMUserUsage      `json:"topUsers"`
+	CircuitBreaker   CircuitBreakerStatus `json:"circuitBreaker"`
+}
+
+// WarningCode identifies a kind of non-fatal warning attached to a successful response
+type WarningCode string
+
+const (
+	WarningCodeLightMismatch  WarningCode = "LIGHT_MISMATCH"
+	WarningCodeDuplicatePlant WarningCode = "DUPLICATE_PLANT"
+	WarningCodeLLMFallback    WarningCode = "LLM_FALLBACK"
+)
+
+// Warning represents a non-fatal problem with a request that still succeeded
+type Warning struct {
+	Code    WarningCode `json:"code"`
+	Message string      `json:"message"`
+}
+
+// ValidationErrorCode identifies the rule a request field failed
+type ValidationErrorCode string
+
+const (
+	ValidationErrorRequired     ValidationErrorCode = "REQUIRED"
+	ValidationErrorInvalidEmail ValidationErrorCode = "INVALID_EMAIL"
+	ValidationErrorInvalidURL   ValidationErrorCode = "INVALID_URL"
+	ValidationErrorTooShort     ValidationErrorCode = "TOO_SHORT" // too few characters or items
+	ValidationErrorTooLong      ValidationErrorCode = "TOO_LONG"  // too many characters or items
+	ValidationErrorTooSmall     ValidationErrorCode = "TOO_SMALL"
+	ValidationErrorTooLarge     ValidationErrorCode = "TOO_LARGE"
+	ValidationErrorNotAllowed   ValidationErrorCode = "NOT_ALLOWED" // not one of the allowed values
+	ValidationErrorInvalid      ValidationErrorCode = "INVALID"
+)
+
+// ValidationError represents a request field that failed validation
+type ValidationError struct {
+	Field   string              `json:"field"` // JSON name, with the path of nested fields, e.g. careInstructions.sunlight
+	Code    ValidationErrorCode `json:"code"`
+	Param   string              `json:"param,omitempty"` // the limit or allowed values of the rule
+	Message string              `json:"message"`         // localized
+}
+
+// CapturedBodyStatus tells how much of the body of a captured request was kept
+type CapturedBodyStatus string
+
+const (
+	CapturedBodyComplete  CapturedBodyStatus = "COMPLETE"
+	CapturedBodyTruncated CapturedBodyStatus = "TRUNCATED" // longer than the capture limit
+	CapturedBodyOmitted   CapturedBodyStatus = "OMITTED"   // not text, e.g. an uploaded photo
+)
+
+// CapturedRequest represents a request answered with a server error, kept with its credentials and
+// secrets redacted so it can be replayed against a staging instance
+type CapturedRequest struct {
+	ID         uuid.UUID          `json:"id" db:"id"`
+	Method     string             `json:"method" db:"method"`
+	Path       string             `json:"path" db:"path"`
+	Query      string             `json:"query,omitempty" db:"query"`
+	Headers    CapturedHeaders    `json:"headers" db:"headers"`
+	Body       string             `json:"body" db:"body"`
+	BodyStatus CapturedBodyStatus `json:"bodyStatus" db:"body_status"`
+	UserID     *uuid.UUID         `json:"userId,omitempty" db:"user_id"` // the user of a JWT session
+	Status     int                `json:"status" db:"status"`
+	DurationMs int64              `json:"durationMs" db:"duration_ms"`
+	CapturedAt time.Time          `json:"capturedAt" db:"captured_at"`
+}
+
+// CapturedHeaders are the headers of a captured request. They are stored as a JSON object.
+type CapturedHeaders map[string][]string
+
+// Value implements driver.Valuer
+func (h CapturedHeaders) Value() (driver.Value, error) {
+	if h == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(h)
+}
+
+// Scan implements sql.Scanner
+func (h *CapturedHeaders) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*h = CapturedHeaders{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into CapturedHeaders", src)
+	}
+	headers := CapturedHeaders{}
+	if err := json.Unmarshal(data, &headers); err != nil {
+		return err
+	}
+	*h = headers
+	return nil
+}
+
+// ReplayCapturedRequestRequest represents a request to replay a captured request on staging as a user
+type ReplayCapturedRequestRequest struct {
+	UserID uuid.UUID `json:"userId" validate:"required"`
+}
+
+// ReplayResult represents the response staging gave to a replayed request
+type ReplayResult struct {
+	RequestID     uuid.UUID           `json:"requestId"`
+	Target        string              `json:"target"` // the URL the request was replayed against
+	UserID        uuid.UUID           `json:"userId"`
+	Status        int                 `json:"status"`
+	Headers       map[string][]string `json:"headers"`
+	Body          string              `json:"body"`
+	BodyTruncated bool                `json:"bodyTruncated"`
+	DurationMs    int64               `json:"durationMs"`
+}
+
+// PlantEnrichmentField is a plant field external sources can fill
+type PlantEnrichmentField string
+
+const (
+	PlantEnrichmentFieldSynonyms PlantEnrichmentField = "SYNONYMS" // other scientific names
+	PlantEnrichmentFieldImage    PlantEnrichmentField = "IMAGE"    // image with its license
+	PlantEnrichmentFieldToxicity PlantEnrichmentField = "TOXICITY" // whether the plant is safe for cats and dogs
+)
+
+// PlantEnrichmentStatus represents the review status of a proposed plant change
+type PlantEnrichmentStatus string
+
+const (
+	PlantEnrichmentStatusPending  PlantEnrichmentStatus = "PENDING"
+	PlantEnrichmentStatusApproved PlantEnrichmentStatus = "APPROVED"
+	PlantEnrichmentStatusRejected PlantEnrichmentStatus = "REJECTED"
+)
+
+// PlantImage represents an image of a plant with the license it can be used under
+type PlantImage struct {
+	URL         string `json:"url"`
+	License     string `json:"license"`
+	Attribution string `json:"attribution,omitempty"` // author the license requires crediting
+}
+
+// PlantEnrichment represents plant fields found by an external source; fields it knows nothing about are empty
+type PlantEnrichment struct {
+	Synonyms    []string    `json:"synonyms,omitempty"`
+	Image       *PlantImage `json:"image,omitempty"`
+	PetFriendly *bool       `json:"petFriendly,omitempty"`
+}
+
+// Value implements driver.Valuer
+func (e PlantEnrichment) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+// Scan implements sql.Scanner
+func (e *PlantEnrichment) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*e = PlantEnrichment{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into PlantEnrichment", src)
+	}
+	var enrichment PlantEnrichment
+	if err := json.Unmarshal(data, &enrichment); err != nil {
+		return err
+	}
+	*e = enrichment
+	return nil
+}
+
+// PlantEnrichmentProposal represents a change of one plant field found by an external source,
+// applied to the plant once an admin approves it
+type PlantEnrichmentProposal struct {
+	ID         uuid.UUID             `json:"id" db:"id"`
+	PlantID    uuid.UUID             `json:"plantId" db:"plant_id"`
+	PlantName  string                `json:"plantName" db:"plant_name"`
+	Field      PlantEnrichmentField  `json:"field" db:"field"`
+	Source     string                `json:"source" db:"source"`
+	Value      PlantEnrichment       `json:"value" db:"value"` // only the proposed field is set
+	Status     PlantEnrichmentStatus `json:"status" db:"status"`
+	ReviewedBy *uuid.UUID            `json:"reviewedBy,omitempty" db:"reviewed_by"`
+	ReviewedAt *time.Time            `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	CreatedAt  time.Time             `json:"createdAt" db:"created_at"`
+}
+
+// PlantEnrichmentRun represents the result of looking up the missing fields of catalog plants
+type PlantEnrichmentRun struct {
+	PlantsChecked int                        `json:"plantsChecked"`
+	Proposals     []*PlantEnrichmentProposal `json:"proposals"` // proposals queued for review by the run
+	Errors        []string                   `json:"errors,omitempty"` // lookups that failed; their plants are checked again by the next run
+}
+
+// ReviewPlantEnrichmentRequest represents a request to approve or reject a proposed plant change
+type ReviewPlantEnrichmentRequest struct {
+	Status PlantEnrichmentStatus `json:"status" validate:"required,oneof=APPROVED REJECTED"`
+}
+
+// PlantStatus represents the review state of a catalog plant
+type PlantStatus string
+
+const (
+	PlantStatusDraft     PlantStatus = "DRAFT"     // being written by an editor
+	PlantStatusInReview  PlantStatus = "IN_REVIEW" // submitted, waiting for a reviewer
+	PlantStatusPublished PlantStatus = "PUBLISHED" // shown to users
+)
+
+// Published reports whether users may see the plant. Plants read without their status, like the
+// plants of collections, were published when they were added.
+func (p *Plant) Published() bool {
+	return p.Status == "" || p.Status == PlantStatusPublished
+}
+
+// ToxicitySeverity is how harmful a plant is when eaten
+type ToxicitySeverity string
+
+const (
+	ToxicitySeverityNone     ToxicitySeverity = "NONE"
+	ToxicitySeverityMild     ToxicitySeverity = "MILD"     // irritation, upset stomach
+	ToxicitySeverityModerate ToxicitySeverity = "MODERATE" // vomiting, swelling; a vet or doctor should be called
+	ToxicitySeveritySevere   ToxicitySeverity = "SEVERE"   // organ damage or worse
+)
+
+// Toxicity is how harmful a plant is to pets and to children; a severity is unknown when nil
+type Toxicity struct {
+	Pets     *ToxicitySeverity `json:"pets,omitempty"`
+	Children *ToxicitySeverity `json:"children,omitempty"`
+}
+
+// Severities returns the severities of a toxicity that may be unknown
+func (t *Toxicity) Severities() (pets *ToxicitySeverity, children *ToxicitySeverity) {
+	if t == nil {
+		return nil, nil
+	}
+	return t.Pets, t.Children
+}
+
+// ToxicToPets reports whether the plant is known to harm pets, from its toxicity or, while that is
+// unknown, from it not being pet friendly
+func (p *Plant) ToxicToPets() bool {
+	if p.Toxicity != nil && p.Toxicity.Pets != nil {
+		return *p.Toxicity.Pets != ToxicitySeverityNone
+	}
+	return p.PetFriendly != nil && !*p.PetFriendly
+}
+
+// ToxicToChildren reports whether the plant is known to harm children
+func (p *Plant) ToxicToChildren() bool {
+	return p.Toxicity != nil && p.Toxicity.Children != nil && *p.Toxicity.Children != ToxicitySeverityNone
+}
+
+// PlantStatusRequest represents a request to move a catalog plant to another review state
+type PlantStatusRequest struct {
+	Status PlantStatus `json:"status" validate:"required,oneof=DRAFT IN_REVIEW PUBLISHED"`
+}
+
+// Category represents a category of the catalog, such as succulents or ferns
+type Category struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	Slug       string    `json:"slug" db:"slug"` // Filters the plant list by the category, e.g. succulent
+	Name       string    `json:"name" db:"name"`
+	PlantCount int       `json:"plantCount" db:"plant_count"` // Published plants in the category
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// CategoryRequest represents a request to create or update a category
+type CategoryRequest struct {
+	Slug string `json:"slug" validate:"required,max=50"`
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// TagCount represents a tag with the number of published plants that have it
+type TagCount struct {
+	Tag        string `json:"tag" db:"tag"`
+	PlantCount int    `json:"plantCount" db:"plant_count"`
+}
+
+// PlantTaxonomy represents the categories and tags of a plant; as a request it replaces both
+type PlantTaxonomy struct {
+	Categories []string `json:"categories" validate:"max=10"` // category slugs
+	Tags       []string `json:"tags" validate:"max=20"`
+}
+
+// BadgeType identifies a badge users earn
+type BadgeType string
+
+const (
+	BadgeTypeQuizFirst    BadgeType = "QUIZ_FIRST"     // answered the daily quiz for the first time
+	BadgeTypeQuizPerfect  BadgeType = "QUIZ_PERFECT"   // answered every question of a daily quiz right
+	BadgeTypeQuizStreak7  BadgeType = "QUIZ_STREAK_7"  // answered the daily quiz 7 days in a row
+	BadgeTypeQuizStreak30 BadgeType = "QUIZ_STREAK_30" // answered the daily quiz 30 days in a row
+)
+
+// Badge represents a badge a user earned
+type Badge struct {
+	Type      BadgeType `json:"type" db:"badge"`
+	AwardedAt time.Time `json:"awardedAt" db:"awarded_at"`
+}
+
+// FollowedUser represents a user another user follows, e.g. to compare quiz scores with
+type FollowedUser struct {
+	UserID     uuid.UUID `json:"userId" db:"user_id"`
+	Name       string    `json:"name" db:"name"`
+	FollowedAt time.Time `json:"followedAt" db:"followed_at"`
+}
+
+// QuizQuestionKind is the catalog fact a quiz question asks about
+type QuizQuestionKind string
+
+const (
+	QuizQuestionKindWatering    QuizQuestionKind = "WATERING"     // days between waterings
+	QuizQuestionKindSunlight    QuizQuestionKind = "SUNLIGHT"     // sunlight level
+	QuizQuestionKindHumidity    QuizQuestionKind = "HUMIDITY"     // humidity level
+	QuizQuestionKindPetFriendly QuizQuestionKind = "PET_FRIENDLY" // whether the plant is safe for pets
+)
+
+// QuizQuestionFacts represents a question of a daily quiz as stored: the plant and fact it asks
+// about, the option values and the index of the right one. Options are put into words in the
+// language of the user when the quiz is shown.
+type QuizQuestionFacts struct {
+	PlantID   uuid.UUID        `json:"plantId"`
+	PlantName string           `json:"plantName"`
+	Kind      QuizQuestionKind `json:"kind"`
+	Options   []string         `json:"options"` // e.g. days between waterings, LOW or true
+	Answer    int              `json:"answer"`
+}
+
+// QuizQuestionSet holds the questions of a daily quiz
+type QuizQuestionSet []*QuizQuestionFacts
+
+// Value implements driver.Valuer
+func (q QuizQuestionSet) Value() (driver.Value, error) {
+	if q == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(q)
+}
+
+// Scan implements sql.Scanner
+func (q *QuizQuestionSet) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into QuizQuestionSet", src)
+	}
+	questions := QuizQuestionSet{}
+	if err := json.Unmarshal(data, &questions); err != nil {
+		return err
+	}
+	*q = questions
+	return nil
+}
+
+// QuizQuestion represents a question of the daily quiz as shown to a user
+type QuizQuestion struct {
+	PlantID uuid.UUID        `json:"plantId"`
+	Kind    QuizQuestionKind `json:"kind"`
+	Text    string           `json:"text"`
+	Options []string         `json:"options"`
+}
+
+// DailyQuiz represents the quiz of a day, the same for every user
+type DailyQuiz struct {
+	Date      string          `json:"date"` // YYYY-MM-DD in UTC
+	Questions []*QuizQuestion `json:"questions"`
+	Result    *QuizResult     `json:"result,omitempty"` // set once the user answered the quiz
+}
+
+// QuizAnswer represents the answer of a user to a question of a daily quiz
+type QuizAnswer struct {
+	Option        int  `json:"option"`
+	CorrectOption int  `json:"correctOption"`
+	Correct       bool `json:"correct"`
+}
+
+// QuizAnswers holds the answers of a user to a daily quiz
+type QuizAnswers []*QuizAnswer
+
+// Value implements driver.Valuer
+func (a QuizAnswers) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan implements sql.Scanner
+func (a *QuizAnswers) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into QuizAnswers", src)
+	}
+	answers := QuizAnswers{}
+	if err := json.Unmarshal(data, &answers); err != nil {
+		return err
+	}
+	*a = answers
+	return nil
+}
+
+// QuizResult represents the scored answers of a user to a daily quiz
+type QuizResult struct {
+	Date      string      `json:"date" db:"quiz_date"`
+	Correct   int         `json:"correct" db:"correct"`
+	Total     int         `json:"total" db:"total"`
+	Points    int         `json:"points" db:"points"`
+	Streak    int         `json:"streak" db:"streak"` // days in a row the user answered the quiz, this one included
+	Answers   QuizAnswers `json:"answers" db:"answers"`
+	Badges    []BadgeType `json:"badges,omitempty" db:"-"` // badges earned with these answers
+	CreatedAt time.Time   `json:"createdAt" db:"created_at"`
+}
+
+// SubmitQuizAnswersRequest represents the answers of a user to the daily quiz: the index of the
+// chosen option of each question, in question order
+type SubmitQuizAnswersRequest struct {
+	Date    string `json:"date" validate:"required,datetime=2006-01-02"`
+	Answers []int  `json:"answers" validate:"required,min=1,dive,min=0"`
+}
+
+// QuizLeaderboardEntry represents the quiz score of a user on a leaderboard
+type QuizLeaderboardEntry struct {
+	Rank    int       `json:"rank" db:"-"` // users with the same points share a rank
+	UserID  uuid.UUID `json:"userId" db:"user_id"`
+	Name    string    `json:"name" db:"name"`
+	Points  int       `json:"points" db:"points"`
+	Quizzes int       `json:"quizzes" db:"quizzes"` // quizzes answered in the period
+	Streak  int       `json:"streak" db:"streak"`   // current streak; 0 when the last quiz answered was before yesterday
+	IsMe    bool      `json:"isMe" db:"-"`
+}
+
+// QuizLeaderboard represents the quiz scores of a user and the users they follow over a period
+type QuizLeaderboard struct {
+	Days    int                     `json:"days"` // days counted, today included
+	Entries []*QuizLeaderboardEntry `json:"entries"`
+}
+
+// RoomMove maps a room of a collection to the room its plants move to, with the light there
+type RoomMove struct {
+	From  string        `json:"from" validate:"required,max=100"`
+	To    string        `json:"to" validate:"required,max=100"`
+	Light SunlightLevel `json:"light" validate:"required,oneof=LOW MEDIUM HIGH"`
+}
+
+// MovePlantsRequest represents a request to move the plants of a collection to other rooms, e.g.
+// when moving house
+type MovePlantsRequest struct {
+	Rooms []*RoomMove `json:"rooms" validate:"required,min=1,max=50,dive"`
+	// Days moved plants get to settle in before they are fertilized, repotted or pruned; 14 when unset
+	AcclimationDays *int `json:"acclimationDays,omitempty" validate:"omitempty,min=0,max=60"`
+}
+
+// PlantLightFit tells how the light of the room a plant moved to fits the light it needs
+type PlantLightFit string
+
+const (
+	PlantLightFitGood      PlantLightFit = "GOOD"
+	PlantLightFitTooDark   PlantLightFit = "TOO_DARK"
+	PlantLightFitTooBright PlantLightFit = "TOO_BRIGHT"
+)
+
+// PostponedCareTask represents a care task of a moved plant postponed until it settles in
+type PostponedCareTask struct {
+	Type CareTaskType `json:"type"`
+	From time.Time    `json:"from"` // when the task was due
+	To   time.Time    `json:"to"`   // when the task is due now
+}
+
+// MovedPlant represents a plant of a collection moved to another room
+type MovedPlant struct {
+	PlantID        uuid.UUID            `json:"plantId"`
+	Name           string               `json:"name"`
+	Nickname       *string              `json:"nickname,omitempty"`
+	From           string               `json:"from"`
+	To             string               `json:"to"`
+	Light          SunlightLevel        `json:"light"`      // light of the new room
+	NeedsLight     SunlightLevel        `json:"needsLight"` // light the plant needs
+	LightFit       PlantLightFit        `json:"lightFit"`
+	PostponedTasks []*PostponedCareTask `json:"postponedTasks,omitempty"`
+}
+
+// PlantMove represents the plants of a collection moved to other rooms
+type PlantMove struct {
+	AcclimationDays int           `json:"acclimationDays"`
+	AcclimationEnds string        `json:"acclimationEnds"` // YYYY-MM-DD; care tasks postponed by the move are due from this day
+	Plants          []*MovedPlant `json:"plants"`
+	Unsuitable      int           `json:"unsuitable"`               // moved plants whose new room is too dark or too bright for them
+	UnmatchedRooms  []string      `json:"unmatchedRooms,omitempty"` // rooms of the request no plant of the collection is in
+}
+
+// PlantStats represents anonymized ownership statistics of a catalog plant, aggregated every night.
+// Averages are left out while a plant has too few owners to keep them anonymous.
+type PlantStats struct {
+	PlantID uuid.UUID `json:"plantId" db:"plant_id"`
+	Owners  int       `json:"owners" db:"owners"` // users with the plant in their collection
+	// Average days between waterings logged by owners over the last half year
+	WateringIntervalDays *float64 `json:"wateringIntervalDays,omitempty" db:"watering_interval_days"`
+	// Share of plants owned for a month or more that were watered in the last month, a proxy for the
+	// plants still alive
+	SurvivalRate *float64   `json:"survivalRate,omitempty" db:"survival_rate"`
+	ComputedAt   *time.Time `json:"computedAt,omitempty" db:"computed_at"` // unset until the first aggregation
+}
+
+// ChangelogTexts holds a text of a changelog entry by language
+type ChangelogTexts map[Language]string
+
+// Value implements driver.Valuer
+func (t ChangelogTexts) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements sql.Scanner
+func (t *ChangelogTexts) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ChangelogTexts", src)
+	}
+	texts := ChangelogTexts{}
+	if err := json.Unmarshal(data, &texts); err != nil {
+		return err
+	}
+	*t = texts
+	return nil
+}
+
+// ChangelogEntry represents the release notes of an app version, as admins manage them
+type ChangelogEntry struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	Version     string         `json:"version" db:"version"`
+	Titles      ChangelogTexts `json:"titles" db:"titles"`
+	Bodies      ChangelogTexts `json:"bodies" db:"bodies"`
+	PublishedAt time.Time      `json:"publishedAt" db:"published_at"` // users see the entry from then on
+	CreatedBy   *uuid.UUID     `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt   time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updatedAt" db:"updated_at"`
+	Seen        bool           `json:"-" db:"seen"` // set when listing the entries published for a user
+}
+
+// SaveChangelogEntryRequest represents an admin request to create or update a changelog entry. The
+// title and body in Russian, the default language, are required; users of other languages without
+// them get them.
+type SaveChangelogEntryRequest struct {
+	Version     string         `json:"version" validate:"required,max=20"`
+	Titles      ChangelogTexts `json:"titles" validate:"required,dive,keys,oneof=RUSSIAN ENGLISH,endkeys,required,max=200"`
+	Bodies      ChangelogTexts `json:"bodies" validate:"required,dive,keys,oneof=RUSSIAN ENGLISH,endkeys,required,max=4000"`
+	PublishedAt *time.Time     `json:"publishedAt,omitempty"` // now when unset
+}
+
+// ChangelogItem represents a changelog entry in the language of a user
+type ChangelogItem struct {
+	ID          uuid.UUID `json:"id"`
+	Version     string    `json:"version"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Seen        bool      `json:"seen"`
+}
+
+// Changelog represents the changelog entries published for a user, newest first
+type Changelog struct {
+	Entries []*ChangelogItem `json:"entries"`
+	Unseen  int              `json:"unseen"` // entries the user has not seen
+}
+
+// MarkChangelogSeenRequest represents a request to mark changelog entries shown to a user as seen
+type MarkChangelogSeenRequest struct {
+	EntryIDs []uuid.UUID `json:"entryIds" validate:"required,min=1,max=100"`
+}
+
+// Audit actions of changes that are not admin requests; admin requests are recorded as their
+// method and route, e.g. "DELETE /admin/shops/{shopId}"
+const (
+	// AuditActionUpdateProfile is a user editing their profile
+	AuditActionUpdateProfile = "UPDATE_PROFILE"
+)
+
+// AuditChanges describes what an audited action changed, e.g. the fields of an edit with their old and
+// new values, or the request body of an admin action
+type AuditChanges map[string]interface{}
+
+// Value converts the changes to a database value
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan converts a database value to the changes
+func (c *AuditChanges) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*c = AuditChanges{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into AuditChanges", src)
+	}
+	changes := AuditChanges{}
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return err
+	}
+	*c = changes
+	return nil
+}
+
+// AuditEntry represents who changed what: an admin action or a user's profile edit
+type AuditEntry struct {
+	ID         uuid.UUID    `json:"id" db:"id"`
+	ActorID    *uuid.UUID   `json:"actorId,omitempty" db:"actor_id"` // unset for changes made by the system
+	Action     string       `json:"action" db:"action"`
+	EntityType string       `json:"entityType" db:"entity_type"` // e.g. "shops" or "users"
+	EntityID   string       `json:"entityId" db:"entity_id"`     // empty when the action has no single entity
+	Changes    AuditChanges `json:"changes" db:"changes"`
+	CreatedAt  time.Time    `json:"createdAt" db:"created_at"`
+}
+
+// AuditLogFilter represents a page request of the audit log; unset fields match every entry
+type AuditLogFilter struct {
+	ActorID    *uuid.UUID
+	EntityType string
+	EntityID   string
+	Action     string
+	Since      *time.Time
+	Until      *time.Time
+	Page       int // 1-based; the first page when not positive
+	PageSize   int // the default page size when not positive, capped at the maximum
+}
+
+// AuditLogPage represents a page of the audit log, newest entry first
+type AuditLogPage struct {
+	Entries []*AuditEntry `json:"entries"`
+	Total   int           `json:"total"`
+}
+
+// SagaStatus represents where a saga is: running its steps, undoing them after one failed, or done
+type SagaStatus string
+
+const (
+	// SagaStatusRunning means the saga is running its steps
+	SagaStatusRunning SagaStatus = "RUNNING"
+	// SagaStatusCompensating means a step failed and the completed steps are being undone
+	SagaStatusCompensating SagaStatus = "COMPENSATING"
+	// SagaStatusCompleted means every step succeeded
+	SagaStatusCompleted SagaStatus = "COMPLETED"
+	// SagaStatusCompensated means a step failed and every completed step was undone
+	SagaStatusCompensated SagaStatus = "COMPENSATED"
+	// SagaStatusFailed means a step could not be undone, so the saga needs an admin
+	SagaStatusFailed SagaStatus = "FAILED"
+)
+
+// SagaData holds what the steps of a saga share, e.g. the ID of a reservation a later step refers to
+type SagaData map[string]interface{}
+
+// Value converts the data to a database value
+func (d SagaData) Value() (driver.Value, error) {
+	if d == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(d)
+}
+
+// Scan converts a database value to the data
+func (d *SagaData) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*d = SagaData{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into SagaData", src)
+	}
+	sagaData := SagaData{}
+	if err := json.Unmarshal(data, &sagaData); err != nil {
+		return err
+	}
+	*d = sagaData
+	return nil
+}
+
+// Saga represents the persisted state of a multi-step operation whose completed steps are undone when
+// a later one fails
+type Saga struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	Status      SagaStatus `json:"status" db:"status"`
+	CurrentStep string     `json:"currentStep" db:"current_step"` // the step running or being undone, or the last one
+	Completed   int        `json:"completedSteps" db:"completed_steps"`
+	Data        SagaData   `json:"data" db:"data"`
+	Error       *string    `json:"error,omitempty" db:"error"` // why a step failed
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
+}
@@ -0,0 +1,399 @@
+// Package redis is a small Redis client for the state shared by all instances of the API: rate
+// limit counters, caches and locks. It speaks RESP over a pool of connections and records the
+// numbers reported on the metrics endpoint.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNil is returned when a key does not exist
+	ErrNil = errors.New("redis: nil")
+
+	// ErrClosed is returned by a closed client
+	ErrClosed = errors.New("redis: client is closed")
+
+	// ErrLockHeld is returned when a lock is held by someone else
+	ErrLockHeld = errors.New("redis: lock is held")
+)
+
+// unlockScript deletes a lock only while it still holds the caller's token
+const unlockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+
+// Options configures a client
+type Options struct {
+	Addr        string
+	Password    string
+	DB          int
+	PoolSize    int           // maximum number of open connections
+	DialTimeout time.Duration // timeout of establishing a connection
+	IOTimeout   time.Duration // timeout of a command when the context has no earlier deadline
+}
+
+// ParseURL parses a redis://[:password@]host[:port][/db] URL into options
+func ParseURL(rawURL string) (Options, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Options{}, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if u.Scheme != "redis" {
+		return Options{}, fmt.Errorf("invalid redis URL scheme %q", u.Scheme)
+	}
+
+	options := Options{Addr: u.Host}
+	if u.Port() == "" {
+		options.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			options.Password = password
+		} else {
+			options.Password = u.User.Username()
+		}
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		options.DB, err = strconv.Atoi(db)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+	return options, nil
+}
+
+// Stats describes the pool and the commands sent by a client
+type Stats struct {
+	OpenConns int   // connections currently open
+	IdleConns int   // open connections waiting in the pool
+	Dials     int64 // connections established
+	Commands  int64 // commands sent
+	Errors    int64 // commands that failed, including error replies
+}
+
+// conn is a pooled connection
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+}
+
+// Client is a Redis client backed by a pool of connections. It is safe for concurrent use.
+type Client struct {
+	options Options
+	slots   chan struct{} // one slot per open connection
+	idle    chan *conn
+	closed  atomic.Bool
+
+	dials    atomic.Int64
+	commands atomic.Int64
+	errors   atomic.Int64
+}
+
+// New creates a new client. Connections are established when they are first needed.
+func New(options Options) *Client {
+	if options.PoolSize <= 0 {
+		options.PoolSize = 10
+	}
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = 5 * time.Second
+	}
+	if options.IOTimeout <= 0 {
+		options.IOTimeout = 3 * time.Second
+	}
+	return &Client{
+		options: options,
+		slots:   make(chan struct{}, options.PoolSize),
+		idle:    make(chan *conn, options.PoolSize),
+	}
+}
+
+// NewFromURL creates a new client for a redis:// URL with at most poolSize open connections
+func NewFromURL(rawURL string, poolSize int) (*Client, error) {
+	options, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	options.PoolSize = poolSize
+	return New(options), nil
+}
+
+// Do sends a command and returns its reply. Error replies are returned as Error.
+func (c *Client) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	c.commands.Add(1)
+	reply, err := c.do(ctx, args)
+	if err != nil {
+		c.errors.Add(1)
+	}
+	return reply, err
+}
+
+// do sends a command on a pooled connection
+func (c *Client) do(ctx context.Context, args []interface{}) (interface{}, error) {
+	cn, err := c.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.roundTrip(ctx, c.options.IOTimeout, args)
+	if err != nil {
+		// The connection is in an unknown state after a network error
+		c.putConn(cn, true)
+		return nil, err
+	}
+	c.putConn(cn, false)
+
+	if replyErr, ok := reply.(Error); ok {
+		return nil, replyErr
+	}
+	return reply, nil
+}
+
+// Ping checks that the server answers
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Get gets the value of a key, returning ErrNil when it does not exist
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	reply, err := c.Do(ctx, "GET", key)
+	if err != nil {
+		return "", err
+	}
+	if reply == nil {
+		return "", ErrNil
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return "", fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return value, nil
+}
+
+// Set sets the value of a key that expires after ttl, or never when ttl is zero
+func (c *Client) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	args := []interface{}{"SET", key, value}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+// SetNX sets the value of a key that expires after ttl unless the key exists, and reports whether it was set
+func (c *Client) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	reply, err := c.Do(ctx, "SET", key, value, "PX", ttl.Milliseconds(), "NX")
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
+}
+
+// Del deletes keys
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	args := []interface{}{"DEL"}
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+// Expire sets a key to expire after ttl and reports whether the key exists
+func (c *Client) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	reply, err := c.Do(ctx, "PEXPIRE", key, ttl.Milliseconds())
+	if err != nil {
+		return false, err
+	}
+	return reply == int64(1), nil
+}
+
+// IncrWindow increments a counter that expires window after its first increment and returns
+// the new count with the time left until it expires
+func (c *Client) IncrWindow(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	reply, err := c.Do(ctx, "INCR", key)
+	if err != nil {
+		return 0, 0, err
+	}
+	count, ok := reply.(int64)
+	if !ok {
+		return 0, 0, fmt.Errorf("redis: unexpected INCR reply %T", reply)
+	}
+
+	reply, err = c.Do(ctx, "PTTL", key)
+	if err != nil {
+		return 0, 0, err
+	}
+	ttl, _ := reply.(int64)
+
+	// The first increment starts the window; a counter left without an expiry by a failed
+	// call is given one as well
+	if ttl < 0 {
+		if _, err := c.Do(ctx, "PEXPIRE", key, window.Milliseconds()); err != nil {
+			return 0, 0, err
+		}
+		ttl = window.Milliseconds()
+	}
+	return count, time.Duration(ttl) * time.Millisecond, nil
+}
+
+// Lock is a lock held on a key until it is released or expires
+type Lock struct {
+	client *Client
+	key    string
+	token  string
+}
+
+// Lock acquires a lock on a key that expires after ttl, returning ErrLockHeld when someone else holds it
+func (c *Client) Lock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	ok, err := c.SetNX(ctx, key, token, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLockHeld
+	}
+	return &Lock{client: c, key: key, token: token}, nil
+}
+
+// Release releases the lock unless it has expired and been taken by someone else
+func (l *Lock) Release(ctx context.Context) error {
+	_, err := l.client.Do(ctx, "EVAL", unlockScript, 1, l.key, l.token)
+	return err
+}
+
+// Stats returns the pool and command statistics
+func (c *Client) Stats() Stats {
+	return Stats{
+		OpenConns: len(c.slots),
+		IdleConns: len(c.idle),
+		Dials:     c.dials.Load(),
+		Commands:  c.commands.Load(),
+		Errors:    c.errors.Load(),
+	}
+}
+
+// Close closes the idle connections and makes the client refuse further commands. Connections
+// in use are closed when they are returned.
+func (c *Client) Close() error {
+	c.closed.Store(true)
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.netConn.Close()
+			<-c.slots
+		default:
+			return nil
+		}
+	}
+}
+
+// getConn takes an idle connection or opens a new one when the pool has room, waiting otherwise
+func (c *Client) getConn(ctx context.Context) (*conn, error) {
+	if c.closed.Load() {
+		return nil, ErrClosed
+	}
+
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	case c.slots <- struct{}{}:
+		cn, err := c.dial(ctx)
+		if err != nil {
+			<-c.slots
+			return nil, err
+		}
+		return cn, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// putConn returns a connection to the pool, closing it when it is broken or the client is closed
+func (c *Client) putConn(cn *conn, broken bool) {
+	if !broken && !c.closed.Load() {
+		select {
+		case c.idle <- cn:
+			return
+		default:
+		}
+	}
+	cn.netConn.Close()
+	<-c.slots
+}
+
+// dial opens a connection, authenticating and selecting the database
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := net.Dialer{Timeout: c.options.DialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.options.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	c.dials.Add(1)
+
+	cn := &conn{
+		netConn: netConn,
+		reader:  bufio.NewReader(netConn),
+		writer:  bufio.NewWriter(netConn),
+	}
+
+	var setup [][]interface{}
+	if c.options.Password != "" {
+		setup = append(setup, []interface{}{"AUTH", c.options.Password})
+	}
+	if c.options.DB != 0 {
+		setup = append(setup, []interface{}{"SELECT", c.options.DB})
+	}
+	for _, args := range setup {
+		reply, err := cn.roundTrip(ctx, c.options.IOTimeout, args)
+		if err == nil {
+			if replyErr, ok := reply.(Error); ok {
+				err = replyErr
+			}
+		}
+		if err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("failed to set up redis connection: %w", err)
+		}
+	}
+	return cn, nil
+}
+
+// roundTrip writes a command and reads its reply before the context deadline or the timeout
+func (cn *conn) roundTrip(ctx context.Context, timeout time.Duration, args []interface{}) (interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := cn.netConn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := writeCommand(cn.writer, args); err != nil {
+		return nil, err
+	}
+	return readReply(cn.reader)
+}
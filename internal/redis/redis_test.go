@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/redis/redistest"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseURL tests the address, password and database taken from a URL
+func TestParseURL(t *testing.T) {
+	options, err := ParseURL("redis://:secret@cache.internal/2")
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Addr: "cache.internal:6379", Password: "secret", DB: 2}, options)
+
+	options, err = ParseURL("redis://localhost:6380")
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:6380", options.Addr)
+	assert.Zero(t, options.DB)
+
+	_, err = ParseURL("http://localhost:6379")
+	assert.Error(t, err)
+	_, err = ParseURL("redis://localhost/db")
+	assert.Error(t, err)
+}
+
+// TestReadReply tests the decoding of every reply type
+func TestReadReply(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("+OK\r\n-ERR boom\r\n:42\r\n$5\r\nhello\r\n$-1\r\n*2\r\n$1\r\na\r\n:1\r\n"))
+	for _, want := range []interface{}{"OK", Error("ERR boom"), int64(42), "hello", nil, []interface{}{"a", int64(1)}} {
+		reply, err := readReply(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, want, reply)
+	}
+
+	_, err := readReply(bufio.NewReader(strings.NewReader("?\r\n")))
+	assert.Error(t, err)
+}
+
+// TestClient_Commands tests the command helpers against a server
+func TestClient_Commands(t *testing.T) {
+	server := redistest.NewServerWithPassword(t, "secret")
+	client := New(Options{Addr: server.Addr(), Password: "secret", DB: 1})
+	defer client.Close()
+	ctx := context.Background()
+
+	assert.NoError(t, client.Ping(ctx))
+
+	_, err := client.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNil)
+
+	assert.NoError(t, client.Set(ctx, "plant", "monstera", time.Minute))
+	value, err := client.Get(ctx, "plant")
+	assert.NoError(t, err)
+	assert.Equal(t, "monstera", value)
+
+	ok, err := client.SetNX(ctx, "plant", "ficus", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, client.Del(ctx, "plant"))
+	_, err = client.Get(ctx, "plant")
+	assert.ErrorIs(t, err, ErrNil)
+
+	// Error replies do not break the connection
+	_, err = client.Do(ctx, "NOPE")
+	var replyErr Error
+	assert.True(t, errors.As(err, &replyErr))
+	assert.NoError(t, client.Ping(ctx))
+
+	stats := client.Stats()
+	assert.Equal(t, int64(1), stats.Dials)
+	assert.Equal(t, int64(9), stats.Commands)
+	assert.Equal(t, int64(1), stats.Errors)
+	assert.Equal(t, 1, stats.IdleConns)
+}
+
+// TestClient_WrongPassword tests that a connection failing authentication is not pooled
+func TestClient_WrongPassword(t *testing.T) {
+	server := redistest.NewServerWithPassword(t, "secret")
+	client := New(Options{Addr: server.Addr(), Password: "wrong"})
+	defer client.Close()
+
+	assert.Error(t, client.Ping(context.Background()))
+	assert.Zero(t, client.Stats().OpenConns)
+}
+
+// TestClient_IncrWindow tests that a counter is reset once its window expires
+func TestClient_IncrWindow(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := New(Options{Addr: server.Addr()})
+	defer client.Close()
+	ctx := context.Background()
+
+	for want := int64(1); want <= 3; want++ {
+		count, ttl, err := client.IncrWindow(ctx, "limit", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, want, count)
+		assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+	}
+
+	server.Advance(time.Minute)
+	count, _, err := client.IncrWindow(ctx, "limit", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+// TestClient_Lock tests that a lock is exclusive until it is released or expires
+func TestClient_Lock(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := New(Options{Addr: server.Addr()})
+	defer client.Close()
+	ctx := context.Background()
+
+	lock, err := client.Lock(ctx, "lock", time.Minute)
+	assert.NoError(t, err)
+	_, err = client.Lock(ctx, "lock", time.Minute)
+	assert.ErrorIs(t, err, ErrLockHeld)
+
+	assert.NoError(t, lock.Release(ctx))
+	expired, err := client.Lock(ctx, "lock", time.Second)
+	assert.NoError(t, err)
+
+	// A lock taken over after it expired is not released by its previous holder
+	server.Advance(time.Second)
+	_, err = client.Lock(ctx, "lock", time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, expired.Release(ctx))
+	_, err = client.Lock(ctx, "lock", time.Minute)
+	assert.ErrorIs(t, err, ErrLockHeld)
+}
+
+// TestClient_Pool tests that concurrent commands never open more connections than the pool size
+func TestClient_Pool(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := New(Options{Addr: server.Addr(), PoolSize: 2})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, client.Ping(ctx))
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, client.Stats().Dials, int64(2))
+	assert.NoError(t, client.Close())
+	assert.Zero(t, client.Stats().OpenConns)
+	assert.ErrorIs(t, client.Ping(ctx), ErrClosed)
+}
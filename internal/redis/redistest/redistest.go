@@ -0,0 +1,319 @@
+// Package redistest provides an in-process Redis server for tests. It understands the commands
+// the redis package sends: PING, AUTH, SELECT, GET, SET with NX/PX/EX, DEL, INCR, PEXPIRE, PTTL
+// and EVAL of the lock release script.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// entry is a stored value with its expiry
+type entry struct {
+	value     string
+	expiresAt time.Time // zero when the key does not expire
+}
+
+// Server is an in-process Redis server
+type Server struct {
+	listener net.Listener
+	password string
+
+	mu   sync.Mutex
+	data map[string]*entry
+	now  func() time.Time
+}
+
+// NewServer starts a server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	return NewServerWithPassword(t, "")
+}
+
+// NewServerWithPassword starts a server requiring AUTH with the password
+func NewServerWithPassword(t testing.TB, password string) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	s := &Server{
+		listener: listener,
+		password: password,
+		data:     make(map[string]*entry),
+		now:      time.Now,
+	}
+	go s.serve()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.listener.Close()
+}
+
+// Advance moves the server clock forward, expiring keys
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.now = func() time.Time { return now.Add(d) }
+}
+
+// Keys returns the number of keys that have not expired
+func (s *Server) Keys() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for key := range s.data {
+		if s.lookup(key) != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// serve accepts connections until the server is closed
+func (s *Server) serve() {
+	for {
+		netConn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(netConn)
+	}
+}
+
+// handle answers the commands of a connection
+func (s *Server) handle(netConn net.Conn) {
+	defer netConn.Close()
+	reader := bufio.NewReader(netConn)
+	writer := bufio.NewWriter(netConn)
+	authenticated := s.password == ""
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		name := strings.ToUpper(args[0])
+		switch {
+		case name == "AUTH":
+			if len(args) == 2 && args[1] == s.password {
+				authenticated = true
+				writer.WriteString("+OK\r\n")
+			} else {
+				writer.WriteString("-WRONGPASS invalid password\r\n")
+			}
+		case !authenticated:
+			writer.WriteString("-NOAUTH Authentication required.\r\n")
+		default:
+			writer.WriteString(s.execute(name, args[1:]))
+		}
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// execute runs a command and returns the encoded reply
+func (s *Server) execute(name string, args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch name {
+	case "PING":
+		return "+PONG\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		if len(args) != 1 {
+			return wrongArgs(name)
+		}
+		if e := s.lookup(args[0]); e != nil {
+			return bulk(e.value)
+		}
+		return "$-1\r\n"
+	case "SET":
+		return s.set(args)
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if s.lookup(key) != nil {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		return integer(int64(deleted))
+	case "INCR":
+		if len(args) != 1 {
+			return wrongArgs(name)
+		}
+		e := s.lookup(args[0])
+		if e == nil {
+			e = &entry{value: "0"}
+			s.data[args[0]] = e
+		}
+		n, err := strconv.ParseInt(e.value, 10, 64)
+		if err != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+		e.value = strconv.FormatInt(n+1, 10)
+		return integer(n + 1)
+	case "PEXPIRE":
+		if len(args) != 2 {
+			return wrongArgs(name)
+		}
+		ms, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+		e := s.lookup(args[0])
+		if e == nil {
+			return integer(0)
+		}
+		e.expiresAt = s.now().Add(time.Duration(ms) * time.Millisecond)
+		return integer(1)
+	case "PTTL":
+		if len(args) != 1 {
+			return wrongArgs(name)
+		}
+		e := s.lookup(args[0])
+		switch {
+		case e == nil:
+			return integer(-2)
+		case e.expiresAt.IsZero():
+			return integer(-1)
+		default:
+			return integer(e.expiresAt.Sub(s.now()).Milliseconds())
+		}
+	case "EVAL":
+		// Only the lock release script: delete KEYS[1] while it holds ARGV[1]
+		if len(args) != 4 || args[1] != "1" {
+			return wrongArgs(name)
+		}
+		if e := s.lookup(args[2]); e != nil && e.value == args[3] {
+			delete(s.data, args[2])
+			return integer(1)
+		}
+		return integer(0)
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", name)
+	}
+}
+
+// set runs SET with the NX, PX and EX options
+func (s *Server) set(args []string) string {
+	if len(args) < 2 {
+		return wrongArgs("SET")
+	}
+	key, value := args[0], args[1]
+	var ttl time.Duration
+	nx := false
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "PX", "EX":
+			if i+1 >= len(args) {
+				return "-ERR syntax error\r\n"
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				return "-ERR invalid expire time in 'set' command\r\n"
+			}
+			ttl = time.Duration(n) * time.Millisecond
+			if strings.ToUpper(args[i]) == "EX" {
+				ttl = time.Duration(n) * time.Second
+			}
+			i++
+		default:
+			return "-ERR syntax error\r\n"
+		}
+	}
+
+	if nx && s.lookup(key) != nil {
+		return "$-1\r\n"
+	}
+	e := &entry{value: value}
+	if ttl > 0 {
+		e.expiresAt = s.now().Add(ttl)
+	}
+	s.data[key] = e
+	return "+OK\r\n"
+}
+
+// lookup returns the entry of a key, dropping it when it has expired
+func (s *Server) lookup(key string) *entry {
+	e, ok := s.data[key]
+	if !ok {
+		return nil
+	}
+	if !e.expiresAt.IsZero() && !s.now().Before(e.expiresAt) {
+		delete(s.data, key)
+		return nil
+	}
+	return e
+}
+
+// readCommand reads a command sent as a RESP array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid command length %q", line)
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimRight(header, "\r\n")[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk length %q", header)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+// bulk encodes a bulk string reply
+func bulk(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+// integer encodes an integer reply
+func integer(n int64) string {
+	return fmt.Sprintf(":%d\r\n", n)
+}
+
+// wrongArgs encodes the reply to a command with the wrong number of arguments
+func wrongArgs(name string) string {
+	return fmt.Sprintf("-ERR wrong number of arguments for '%s' command\r\n", strings.ToLower(name))
+}
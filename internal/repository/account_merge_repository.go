@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// AccountMergeRepository defines the interface for account merge operations
+type AccountMergeRepository interface {
+	// Merge moves the plants, favorites, chats, notifications and other data of the source account
+	// to the target account in one transaction, fills the summary and closes the source account.
+	// Dry runs are rolled back after counting the rows and not recorded. It reports false when
+	// either account does not exist or was merged or anonymized.
+	Merge(ctx context.Context, merge *models.AccountMerge) (bool, error)
+
+	// List gets the most recent account merges
+	List(ctx context.Context, limit int) ([]*models.AccountMerge, error)
+}
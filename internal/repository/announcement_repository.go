@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// AnnouncementRepository defines the interface for announcements broadcast to segments of the users
+type AnnouncementRepository interface {
+	// Create creates an announcement, counting the users of its segment who get notifications as
+	// its recipients
+	Create(ctx context.Context, announcement *models.Announcement) error
+
+	// GetByID gets an announcement with its delivery stats
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Announcement, error)
+
+	// List gets the announcements with their delivery stats, newest first
+	List(ctx context.Context) ([]*models.Announcement, error)
+
+	// GetInProgress gets the announcements waiting to be sent or being sent, oldest first
+	GetInProgress(ctx context.Context) ([]*models.Announcement, error)
+
+	// GetRecipients gets up to limit users of the segment of an announcement who get notifications,
+	// ordered by ID and starting after the given user when it is set
+	GetRecipients(ctx context.Context, announcement *models.Announcement, after *uuid.UUID, limit int) ([]*models.AnnouncementRecipient, error)
+
+	// ClaimBatch claims the recipients after from up to and including to for sending, reporting
+	// false when the batch was claimed already or the announcement is no longer in progress
+	ClaimBatch(ctx context.Context, id uuid.UUID, from *uuid.UUID, to uuid.UUID) (bool, error)
+
+	// RecordBatch adds the notifications of a claimed batch to the delivery stats
+	RecordBatch(ctx context.Context, id uuid.UUID, sent, failed int) error
+
+	// Complete completes an announcement in progress once every recipient was sent to
+	Complete(ctx context.Context, id uuid.UUID) error
+
+	// Cancel cancels an announcement in progress, reporting false when it is no longer in progress
+	Cancel(ctx context.Context, id uuid.UUID) (bool, error)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// AnonymizationRepository defines the interface for tracking account activity and anonymizing dormant accounts
+type AnonymizationRepository interface {
+	// TouchActivity records that a user was active now, which cancels a pending anonymization
+	TouchActivity(ctx context.Context, userID uuid.UUID) error
+
+	// GetUnwarnedInactive gets accounts inactive since the given time whose owners were not warned yet
+	GetUnwarnedInactive(ctx context.Context, inactiveSince time.Time, limit int) ([]*models.InactiveAccount, error)
+
+	// MarkWarned records that the owner of an account was warned about its anonymization
+	MarkWarned(ctx context.Context, userID uuid.UUID) error
+
+	// GetWarnedInactive gets accounts warned before the given time and inactive since the warning
+	GetWarnedInactive(ctx context.Context, warnedBefore time.Time, limit int) ([]*models.InactiveAccount, error)
+
+	// Anonymize replaces the email of a warned, still inactive account with anonymizedEmail and removes
+	// its personal data and credentials; it reports false when the account became active meanwhile
+	Anonymize(ctx context.Context, userID uuid.UUID, anonymizedEmail string) (bool, error)
+
+	// SaveRun saves an anonymization run with the accounts it processed
+	SaveRun(ctx context.Context, run *models.AnonymizationRun) error
+
+	// GetRuns gets the most recent anonymization runs
+	GetRuns(ctx context.Context, limit int) ([]*models.AnonymizationRun, error)
+}
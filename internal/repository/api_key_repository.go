@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// APIKeyRepository defines the interface for public API key operations
+type APIKeyRepository interface {
+	// Create creates a new API key
+	Create(ctx context.Context, apiKey *models.APIKey) error
+
+	// GetByHash gets an active API key by the hash of its raw value
+	GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+
+	// GetByUser gets all API keys of a user
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error)
+
+	// Revoke revokes an API key owned by a user
+	Revoke(ctx context.Context, keyID uuid.UUID, userID uuid.UUID) error
+
+	// RecordUsage increments the request counter of an API key for the current day
+	RecordUsage(ctx context.Context, keyID uuid.UUID) error
+
+	// GetUsage gets the daily usage of an API key since the given time
+	GetUsage(ctx context.Context, keyID uuid.UUID, since time.Time) ([]*models.APIKeyUsage, error)
+}
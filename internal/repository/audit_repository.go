@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// AuditRepository defines the interface for audit log operations
+type AuditRepository interface {
+	// Record adds an entry to the audit log
+	Record(ctx context.Context, entry *models.AuditEntry) error
+
+	// List gets the entries matching a filter, newest first, with the number of all matching entries
+	List(ctx context.Context, filter *models.AuditLogFilter, limit int, offset int) ([]*models.AuditEntry, int, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// BadgeRepository defines the interface for the badges users earn
+type BadgeRepository interface {
+	// Award awards a badge to a user, reporting false when the user has it already
+	Award(ctx context.Context, userID uuid.UUID, badge models.BadgeType) (bool, error)
+
+	// ListByUser gets the badges of a user in the order they were earned
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Badge, error)
+}
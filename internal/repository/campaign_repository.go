@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// CampaignRepository defines the interface for the enrollments of users in notification campaigns
+type CampaignRepository interface {
+	// Enroll enrolls a user in a campaign, reporting false when the user already was
+	Enroll(ctx context.Context, enrollment *models.CampaignEnrollment) (bool, error)
+
+	// GetActive gets the enrollments that have steps left, of users who get notifications
+	GetActive(ctx context.Context) ([]*models.CampaignEnrollment, error)
+
+	// SetNextStep records the step of a campaign a user gets next, completing the enrollment when
+	// no step is left
+	SetNextStep(ctx context.Context, userID uuid.UUID, campaign string, nextStep int, completed bool) error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// CapturedRequestRepository defines the interface for captured request data access
+type CapturedRequestRepository interface {
+	// Create stores a captured request
+	Create(ctx context.Context, request *models.CapturedRequest) error
+
+	// GetByID gets a captured request by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.CapturedRequest, error)
+
+	// List gets the most recently captured requests
+	List(ctx context.Context, limit int) ([]*models.CapturedRequest, error)
+
+	// DeleteBefore deletes the requests captured before the given time and returns how many were deleted
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// CareFeedbackRepository defines the interface for care difficulty feedback operations
+type CareFeedbackRepository interface {
+	// Upsert saves a user's answer for a plant, replacing an earlier one
+	Upsert(ctx context.Context, feedback *models.CareFeedback) error
+
+	// GetFeedbackCandidates gets user plants owned since before the given time whose owners were not asked yet
+	GetFeedbackCandidates(ctx context.Context, ownedBefore time.Time) ([]*models.UserPlant, error)
+
+	// GetTotals gets the answers collected for every plant with feedback
+	GetTotals(ctx context.Context) ([]*models.CareFeedbackTotals, error)
+
+	// SaveCommunityDifficulties saves recalibrated community difficulties
+	SaveCommunityDifficulties(ctx context.Context, difficulties []*models.CommunityDifficulty) error
+
+	// GetDifficulty gets the editorial and community difficulty of a plant
+	GetDifficulty(ctx context.Context, plantID uuid.UUID) (*models.PlantDifficulty, error)
+
+	// SetEditorialDifficulty sets the editorial difficulty of a plant
+	SetEditorialDifficulty(ctx context.Context, plantID uuid.UUID, difficulty int) error
+}
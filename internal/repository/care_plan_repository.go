@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// CarePlanRepository defines the interface for care plan operations
+type CarePlanRepository interface {
+	// Save stores a care plan with its months and reminders, replacing the plant's previous plan
+	Save(ctx context.Context, plan *models.CarePlan, reminders []*models.CarePlanReminder) error
+
+	// GetByUserPlant gets the care plan of a plant in a user's collection, or nil if there is none
+	GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) (*models.CarePlan, error)
+
+	// GetDueReminders gets unsent reminders due in [from, to) of plants still in their owners' collections
+	GetDueReminders(ctx context.Context, from time.Time, to time.Time) ([]*models.CarePlanReminder, error)
+
+	// MarkReminderSent marks a reminder as sent
+	MarkReminderSent(ctx context.Context, reminderID uuid.UUID) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// CareTaskRepository defines the interface for care task completion operations
+type CareTaskRepository interface {
+	// CompleteTask records the completion of a care task; completing a task twice keeps the first completion
+	CompleteTask(ctx context.Context, completion *models.CareTaskCompletion) error
+
+	// GetCompletions gets the completions of a user plant's tasks due in [from, to)
+	GetCompletions(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, from time.Time, to time.Time) ([]*models.CareTaskCompletion, error)
+}
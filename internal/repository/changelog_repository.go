@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// ChangelogRepository defines the interface for changelog entries and the entries users have seen
+type ChangelogRepository interface {
+	// Create creates a changelog entry
+	Create(ctx context.Context, entry *models.ChangelogEntry) error
+
+	// Update updates the version, texts and publication time of a changelog entry
+	Update(ctx context.Context, entry *models.ChangelogEntry) error
+
+	// Delete deletes a changelog entry
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// GetByID gets a changelog entry
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ChangelogEntry, error)
+
+	// List gets every changelog entry, scheduled ones included, the latest published first
+	List(ctx context.Context) ([]*models.ChangelogEntry, error)
+
+	// ListPublished gets the changelog entries published until the given time, newest first, telling
+	// whether the user has seen each
+	ListPublished(ctx context.Context, userID uuid.UUID, until time.Time) ([]*models.ChangelogEntry, error)
+
+	// MarkSeen marks the given entries published until the given time as seen by the user and returns
+	// the number of entries the user had not seen yet
+	MarkSeen(ctx context.Context, userID uuid.UUID, entryIDs []uuid.UUID, until time.Time) (int, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// DiagnosisRepository defines the interface for plant diagnosis operations
+type DiagnosisRepository interface {
+	// Create saves a diagnosis with its findings
+	Create(ctx context.Context, diagnosis *models.PlantDiagnosis) error
+
+	// GetByUserPlant gets the most recent diagnoses of a plant in a user's collection
+	GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, limit int) ([]*models.PlantDiagnosis, error)
+}
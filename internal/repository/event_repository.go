@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// EventRepository defines the interface for analytics event storage
+type EventRepository interface {
+	// CreateBatch stores a batch of analytics events
+	CreateBatch(ctx context.Context, events []*models.AnalyticsEvent) error
+
+	// GetDailyCounts gets the number of events per day, type and A/B variant since the given time
+	GetDailyCounts(ctx context.Context, since time.Time) ([]*models.AnalyticsEventCount, error)
+}
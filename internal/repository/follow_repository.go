@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// FollowRepository defines the interface for the users a user follows
+type FollowRepository interface {
+	// Follow makes a user follow another; following a user again is not an error
+	Follow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error
+
+	// Unfollow makes a user stop following another, reporting false when they did not follow them
+	Unfollow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error)
+
+	// ListFollowing gets the users a user follows, by name
+	ListFollowing(ctx context.Context, userID uuid.UUID) ([]*models.FollowedUser, error)
+}
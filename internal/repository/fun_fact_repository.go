@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// FunFactRepository defines the interface for plant fun fact operations
+type FunFactRepository interface {
+	// CreateBatch saves generated fun facts for a plant
+	CreateBatch(ctx context.Context, facts []*models.PlantFunFact) error
+
+	// GetByPlant gets the fun facts of a plant in a language with the given status
+	GetByPlant(ctx context.Context, plantID uuid.UUID, language models.Language, status models.FunFactStatus) ([]*models.PlantFunFact, error)
+
+	// CountByPlant counts the fun facts of a plant in a language regardless of status
+	CountByPlant(ctx context.Context, plantID uuid.UUID, language models.Language) (int, error)
+
+	// GetPending gets all fun facts awaiting review
+	GetPending(ctx context.Context) ([]*models.PlantFunFact, error)
+
+	// UpdateStatus sets the review status of a fun fact
+	UpdateStatus(ctx context.Context, id uuid.UUID, status models.FunFactStatus) (*models.PlantFunFact, error)
+}
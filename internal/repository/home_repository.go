@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// HomeRepository defines the interface for the homes of users and the plants kept in them
+type HomeRepository interface {
+	// List gets the homes of a user by name with the number of plants in each
+	List(ctx context.Context, userID uuid.UUID) ([]*models.Home, error)
+
+	// GetByID gets a home of a user
+	GetByID(ctx context.Context, userID uuid.UUID, homeID uuid.UUID) (*models.Home, error)
+
+	// Create creates a home
+	Create(ctx context.Context, home *models.Home) error
+
+	// Update updates the name, timezone, coordinates and outdoor rooms of a home of a user
+	Update(ctx context.Context, home *models.Home) error
+
+	// Delete deletes a home of a user; its plants stay in the collection without a home
+	Delete(ctx context.Context, userID uuid.UUID, homeID uuid.UUID) error
+
+	// AssignPlants moves plants of the user's collection to a home of the user and returns the number
+	// of plants moved; plants not in the collection are ignored
+	AssignPlants(ctx context.Context, userID uuid.UUID, homeID uuid.UUID, plantIDs []uuid.UUID) (int64, error)
+
+	// UnassignPlant takes a plant of the user's collection out of a home
+	UnassignPlant(ctx context.Context, userID uuid.UUID, homeID uuid.UUID, plantID uuid.UUID) error
+
+	// SetCurrent marks the home of the user the user is staying at; nil clears it
+	SetCurrent(ctx context.Context, userID uuid.UUID, homeID *uuid.UUID) error
+}
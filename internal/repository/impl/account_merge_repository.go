@@ -0,0 +1,261 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// accountMergeStep moves the rows of a table from the source account $1 to the target account $2
+type accountMergeStep struct {
+	table string
+	key   []string // columns besides user_id an account has one row per; the target account's row wins
+}
+
+// accountMergeSteps lists the tables moved after the collection, which the plant tasks and photos
+// reference, in the order they are moved
+var accountMergeSteps = []accountMergeStep{
+	{table: "homes"},
+	{table: "user_plant_photos"},
+	{table: "user_plant_tasks", key: []string{"plant_id", "task_type"}},
+	{table: "plant_reminders"},
+	{table: "user_favorite_plants", key: []string{"plant_id"}},
+	{table: "user_locations", key: []string{"location"}},
+	{table: "care_task_completions", key: []string{"plant_id", "task_type", "due_date"}},
+	{table: "care_feedback", key: []string{"plant_id"}},
+	{table: "care_plans", key: []string{"plant_id"}},
+	{table: "plant_availability_subscriptions", key: []string{"plant_id"}},
+	{table: "plant_diagnoses"},
+	{table: "plant_journal_entries"},
+	{table: "plant_events"},
+	{table: "plant_questionnaires"},
+	{table: "notifications"},
+	{table: "support_tickets"},
+	{table: "api_keys"},
+	{table: "personal_access_tokens"},
+	{table: "analytics_events"},
+	{table: "llm_usage"},
+	{table: "captured_requests"},
+	{table: "quiz_results", key: []string{"quiz_date"}},
+	{table: "user_badges", key: []string{"badge"}},
+	{table: "changelog_seen", key: []string{"entry_id"}},
+}
+
+// chatMergeSteps move the chat history. The chat tables are created by scripts/chat_tables.sql,
+// so they only run when the tables exist.
+var chatMergeSteps = []accountMergeStep{
+	{table: "chat_sessions"},
+	{table: "chat_messages"},
+}
+
+// AccountMergeRepository is the implementation of the account merge repository
+type AccountMergeRepository struct {
+	db *db.DB
+}
+
+// NewAccountMergeRepository creates a new account merge repository
+func NewAccountMergeRepository(db *db.DB) *AccountMergeRepository {
+	return &AccountMergeRepository{
+		db: db.Repository("account_merge"),
+	}
+}
+
+// Merge moves the data of the source account to the target account in one transaction and closes the source account
+func (r *AccountMergeRepository) Merge(ctx context.Context, merge *models.AccountMerge) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock both accounts so they are not merged, anonymized or changed meanwhile
+	var open []uuid.UUID
+	err = tx.SelectContext(ctx, &open, `
+		SELECT id FROM users
+		WHERE id IN ($1, $2) AND merged_at IS NULL AND anonymized_at IS NULL AND deleted_at IS NULL
+		FOR UPDATE
+	`, merge.SourceUserID, merge.TargetUserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock accounts: %w", err)
+	}
+	if len(open) != 2 {
+		return false, nil
+	}
+
+	merge.Summary = models.AccountMergeSummary{Moved: map[string]int64{}, Conflicts: map[string]int64{}}
+	if err := r.mergeUserPlants(ctx, tx, merge); err != nil {
+		return false, err
+	}
+
+	steps := accountMergeSteps
+	var hasChat bool
+	if err := tx.GetContext(ctx, &hasChat, `SELECT to_regclass('chat_messages') IS NOT NULL`); err != nil {
+		return false, fmt.Errorf("failed to check chat tables: %w", err)
+	}
+	if hasChat {
+		steps = append(steps[:len(steps):len(steps)], chatMergeSteps...)
+	}
+	for _, step := range steps {
+		if len(step.key) > 0 {
+			if err := countRows(ctx, tx, merge.Summary.Conflicts, step.table, dropDuplicatesStatement(step), merge); err != nil {
+				return false, err
+			}
+		}
+		statement := `UPDATE ` + step.table + ` SET user_id = $2 WHERE user_id = $1`
+		if err := countRows(ctx, tx, merge.Summary.Moved, step.table, statement, merge); err != nil {
+			return false, err
+		}
+	}
+
+	if err := mergeFollows(ctx, tx, merge); err != nil {
+		return false, err
+	}
+
+	// The notifications were moved, so both bells are recounted
+	if _, err := tx.ExecContext(ctx, refreshNotificationBells, merge.SourceUserID, merge.TargetUserID); err != nil {
+		return false, fmt.Errorf("failed to refresh notification bells: %w", err)
+	}
+
+	// The plant tasks and photos were moved, so the source collection can go
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_plants WHERE user_id = $1`, merge.SourceUserID); err != nil {
+		return false, fmt.Errorf("failed to remove merged user plants: %w", err)
+	}
+
+	// Close the source account; its credentials are cleared and its tokens were moved
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET password_hash = '', notifications_enabled = FALSE, merged_into = $2, merged_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, merge.SourceUserID, merge.TargetUserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to close merged account: %w", err)
+	}
+
+	if merge.DryRun {
+		merge.CreatedAt = time.Now()
+		return true, nil
+	}
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO account_merges (source_user_id, target_user_id, merged_by, method, summary)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, merge.SourceUserID, merge.TargetUserID, merge.MergedBy, merge.Method, merge.Summary).Scan(&merge.ID, &merge.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to save account merge: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// mergeUserPlants copies the collection of the source account to the target account. A plant in
+// both collections keeps the target's location, nickname and notes unless they are empty, and the
+// watering dates of the copy watered last.
+func (r *AccountMergeRepository) mergeUserPlants(ctx context.Context, tx *sqlx.Tx, merge *models.AccountMerge) error {
+	lastWatered := func(alias string) string { return r.db.Read(alias, "user_plants", "last_watered") }
+	nextWatering := func(alias string) string { return r.db.Read(alias, "user_plants", "next_watering") }
+	sourceWateredLast := lastWatered("t") + " IS NULL OR " + lastWatered("s") + " > " + lastWatered("t")
+
+	fold := `
+		UPDATE user_plants t
+		SET location = COALESCE(t.location, s.location), nickname = COALESCE(t.nickname, s.nickname),
+			notes = COALESCE(t.notes, s.notes), home_id = COALESCE(t.home_id, s.home_id),
+			` + r.db.Assign("user_plants", "last_watered", "CASE WHEN "+sourceWateredLast+" THEN "+lastWatered("s")+" ELSE "+lastWatered("t")+" END") + `,
+			` + r.db.Assign("user_plants", "next_watering", "CASE WHEN "+sourceWateredLast+" THEN "+nextWatering("s")+" ELSE "+nextWatering("t")+" END") + `,
+			updated_at = NOW()
+		FROM user_plants s
+		WHERE t.user_id = $2 AND s.user_id = $1 AND s.plant_id = t.plant_id
+	`
+	if err := countRows(ctx, tx, merge.Summary.Conflicts, "user_plants", fold, merge); err != nil {
+		return err
+	}
+
+	columns, values := r.db.Insert("user_plants",
+		[]string{"user_id", "plant_id", "location", "home_id", "last_watered", "next_watering", "nickname", "notes", "created_at", "updated_at"},
+		[]string{"$2", "s.plant_id", "s.location", "s.home_id", lastWatered("s"), nextWatering("s"), "s.nickname", "s.notes", "s.created_at", "NOW()"})
+	copyPlants := `
+		INSERT INTO user_plants (` + columns + `)
+		SELECT ` + values + `
+		FROM user_plants s
+		WHERE s.user_id = $1 AND NOT EXISTS (
+			SELECT 1 FROM user_plants t WHERE t.user_id = $2 AND t.plant_id = s.plant_id
+		)
+	`
+	return countRows(ctx, tx, merge.Summary.Moved, "user_plants", copyPlants, merge)
+}
+
+// mergeFollows moves the follows of the source account both ways: the users it follows and the
+// users following it. Follows the target account has already and follows between the two
+// accounts are dropped.
+func mergeFollows(ctx context.Context, tx *sqlx.Tx, merge *models.AccountMerge) error {
+	dropDuplicates := `
+		DELETE FROM user_follows s
+		WHERE (s.follower_id = $1 AND (s.followee_id = $2 OR EXISTS (
+			SELECT 1 FROM user_follows t WHERE t.follower_id = $2 AND t.followee_id = s.followee_id
+		))) OR (s.followee_id = $1 AND (s.follower_id = $2 OR EXISTS (
+			SELECT 1 FROM user_follows t WHERE t.followee_id = $2 AND t.follower_id = s.follower_id
+		)))
+	`
+	if err := countRows(ctx, tx, merge.Summary.Conflicts, "user_follows", dropDuplicates, merge); err != nil {
+		return err
+	}
+	if err := countRows(ctx, tx, merge.Summary.Moved, "user_follows", `UPDATE user_follows SET follower_id = $2 WHERE follower_id = $1`, merge); err != nil {
+		return err
+	}
+	return countRows(ctx, tx, merge.Summary.Moved, "user_follows", `UPDATE user_follows SET followee_id = $2 WHERE followee_id = $1`, merge)
+}
+
+// dropDuplicatesStatement returns the statement dropping the source rows of a step the target
+// account has a row with the same key of
+func dropDuplicatesStatement(step accountMergeStep) string {
+	conditions := make([]string, len(step.key))
+	for i, column := range step.key {
+		conditions[i] = "t." + column + " = s." + column
+	}
+	return `
+		DELETE FROM ` + step.table + ` s
+		WHERE s.user_id = $1 AND EXISTS (
+			SELECT 1 FROM ` + step.table + ` t WHERE t.user_id = $2 AND ` + strings.Join(conditions, " AND ") + `
+		)
+	`
+}
+
+// countRows runs a statement on the source $1 and target $2 accounts and adds the rows it affected to counts
+func countRows(ctx context.Context, tx *sqlx.Tx, counts map[string]int64, table, statement string, merge *models.AccountMerge) error {
+	result, err := tx.ExecContext(ctx, statement, merge.SourceUserID, merge.TargetUserID)
+	if err != nil {
+		return fmt.Errorf("failed to merge %s: %w", table, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows > 0 {
+		counts[table] += rows
+	}
+	return nil
+}
+
+// List gets the most recent account merges
+func (r *AccountMergeRepository) List(ctx context.Context, limit int) ([]*models.AccountMerge, error) {
+	merges := []*models.AccountMerge{}
+	err := r.db.SelectContext(ctx, &merges, `
+		SELECT id, source_user_id, target_user_id, merged_by, method, summary, created_at
+		FROM account_merges
+		ORDER BY created_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account merges: %w", err)
+	}
+	return merges, nil
+}
@@ -0,0 +1,79 @@
+package impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountMergeRepository_Merge_DryRun(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewAccountMergeRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	sourceID, targetID := uuid.New(), uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users").
+		WithArgs(sourceID, targetID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(sourceID).AddRow(targetID))
+
+	// One plant is in both collections and two only in the source one
+	mock.ExpectExec("UPDATE user_plants t").WithArgs(sourceID, targetID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO user_plants").WithArgs(sourceID, targetID).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("SELECT to_regclass").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	for _, step := range accountMergeSteps {
+		moved, conflicts := int64(0), int64(0)
+		if step.table == "user_favorite_plants" {
+			moved, conflicts = 3, 1
+		}
+		if len(step.key) > 0 {
+			mock.ExpectExec("DELETE FROM "+step.table).WithArgs(sourceID, targetID).WillReturnResult(sqlmock.NewResult(0, conflicts))
+		}
+		mock.ExpectExec("UPDATE "+step.table+" SET user_id").WithArgs(sourceID, targetID).WillReturnResult(sqlmock.NewResult(0, moved))
+	}
+	mock.ExpectExec("DELETE FROM user_follows s").WithArgs(sourceID, targetID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE user_follows SET follower_id").WithArgs(sourceID, targetID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE user_follows SET followee_id").WithArgs(sourceID, targetID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO notification_bells").WithArgs(sourceID, targetID).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM user_plants").WithArgs(sourceID).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("UPDATE users").WithArgs(sourceID, targetID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	merge := &models.AccountMerge{SourceUserID: sourceID, TargetUserID: targetID, Method: models.AccountMergeMethodAdmin, DryRun: true}
+	merged, err := repo.Merge(context.Background(), merge)
+	assert.NoError(t, err)
+	assert.True(t, merged)
+	assert.Equal(t, map[string]int64{"user_plants": 2, "user_favorite_plants": 3}, merge.Summary.Moved)
+	assert.Equal(t, map[string]int64{"user_plants": 1, "user_favorite_plants": 1}, merge.Summary.Conflicts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountMergeRepository_Merge_AlreadyMerged(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewAccountMergeRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	sourceID, targetID := uuid.New(), uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users").
+		WithArgs(sourceID, targetID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(targetID))
+	mock.ExpectRollback()
+
+	merged, err := repo.Merge(context.Background(), &models.AccountMerge{SourceUserID: sourceID, TargetUserID: targetID})
+	assert.NoError(t, err)
+	assert.False(t, merged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
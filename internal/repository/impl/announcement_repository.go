@@ -0,0 +1,201 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// announcementColumns are the columns of an announcement with its read count, selected from
+// announcements aliased a
+const announcementColumns = `
+	a.id, a.segment, a.city, a.messages, a.status, a.recipients, a.sent, a.failed, a.last_user_id,
+	a.created_by, a.created_at, a.started_at, a.finished_at,
+	(SELECT COUNT(*) FROM notifications n
+	 WHERE n.type = 'ANNOUNCEMENT' AND n.payload->>'announcementId' = a.id::text AND n.is_read) AS read`
+
+// AnnouncementRepository is the implementation of the announcement repository
+type AnnouncementRepository struct {
+	db *db.DB
+}
+
+// NewAnnouncementRepository creates a new announcement repository
+func NewAnnouncementRepository(db *db.DB) *AnnouncementRepository {
+	return &AnnouncementRepository{
+		db: db.Repository("announcement"),
+	}
+}
+
+// announcementSegmentCondition returns the condition selecting the users, aliased u, of the segment
+// of an announcement, with args extended by the parameters it refers to
+func announcementSegmentCondition(announcement *models.Announcement, args []interface{}) (string, []interface{}) {
+	switch announcement.Segment {
+	case models.AnnouncementSegmentPlantOwners:
+		return "EXISTS (SELECT 1 FROM user_plants up WHERE up.user_id = u.id)", args
+	case models.AnnouncementSegmentCity:
+		city := ""
+		if announcement.City != nil {
+			city = *announcement.City
+		}
+		args = append(args, city)
+		return fmt.Sprintf("LOWER(u.city) = LOWER($%d)", len(args)), args
+	default:
+		return "TRUE", args
+	}
+}
+
+// Create creates an announcement, counting the users of its segment who get notifications as
+// its recipients
+func (r *AnnouncementRepository) Create(ctx context.Context, announcement *models.Announcement) error {
+	args := []interface{}{announcement.Segment, announcement.City, announcement.Messages, announcement.CreatedBy}
+	condition, args := announcementSegmentCondition(announcement, args)
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO announcements (segment, city, messages, created_by, recipients)
+		VALUES ($1, $2, $3, $4, (
+			SELECT COUNT(*) FROM users u WHERE u.notifications_enabled AND u.deleted_at IS NULL AND `+condition+`
+		))
+		RETURNING id, status, recipients, created_at
+	`, args...).
+		Scan(&announcement.ID, &announcement.Status, &announcement.Recipients, &announcement.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create announcement: %w", err)
+	}
+	return nil
+}
+
+// GetByID gets an announcement with its delivery stats
+func (r *AnnouncementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Announcement, error) {
+	var announcement models.Announcement
+	err := r.db.GetContext(ctx, &announcement, `
+		SELECT `+announcementColumns+`
+		FROM announcements a
+		WHERE a.id = $1
+	`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("announcement not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get announcement: %w", err)
+	}
+	return &announcement, nil
+}
+
+// List gets the announcements with their delivery stats, newest first
+func (r *AnnouncementRepository) List(ctx context.Context) ([]*models.Announcement, error) {
+	announcements := []*models.Announcement{}
+	err := r.db.SelectContext(ctx, &announcements, `
+		SELECT `+announcementColumns+`
+		FROM announcements a
+		ORDER BY a.created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list announcements: %w", err)
+	}
+	return announcements, nil
+}
+
+// GetInProgress gets the announcements waiting to be sent or being sent, oldest first
+func (r *AnnouncementRepository) GetInProgress(ctx context.Context) ([]*models.Announcement, error) {
+	announcements := []*models.Announcement{}
+	err := r.db.SelectContext(ctx, &announcements, `
+		SELECT `+announcementColumns+`
+		FROM announcements a
+		WHERE a.status IN ('PENDING', 'SENDING')
+		ORDER BY a.created_at ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcements in progress: %w", err)
+	}
+	return announcements, nil
+}
+
+// GetRecipients gets up to limit users of the segment of an announcement who get notifications,
+// ordered by ID and starting after the given user when it is set
+func (r *AnnouncementRepository) GetRecipients(
+	ctx context.Context,
+	announcement *models.Announcement,
+	after *uuid.UUID,
+	limit int,
+) ([]*models.AnnouncementRecipient, error) {
+	condition, args := announcementSegmentCondition(announcement, []interface{}{after, limit})
+	recipients := []*models.AnnouncementRecipient{}
+	err := r.db.SelectContext(ctx, &recipients, `
+		SELECT u.id, u.language
+		FROM users u
+		WHERE u.notifications_enabled AND u.deleted_at IS NULL AND ($1::uuid IS NULL OR u.id > $1) AND `+condition+`
+		ORDER BY u.id ASC
+		LIMIT $2
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcement recipients: %w", err)
+	}
+	return recipients, nil
+}
+
+// ClaimBatch claims the recipients after from up to and including to for sending, reporting
+// false when the batch was claimed already or the announcement is no longer in progress
+func (r *AnnouncementRepository) ClaimBatch(ctx context.Context, id uuid.UUID, from *uuid.UUID, to uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE announcements
+		SET last_user_id = $3, status = 'SENDING', started_at = COALESCE(started_at, NOW())
+		WHERE id = $1 AND status IN ('PENDING', 'SENDING') AND last_user_id IS NOT DISTINCT FROM $2
+	`, id, from, to)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim announcement batch: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// RecordBatch adds the notifications of a claimed batch to the delivery stats
+func (r *AnnouncementRepository) RecordBatch(ctx context.Context, id uuid.UUID, sent, failed int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE announcements
+		SET sent = sent + $2, failed = failed + $3
+		WHERE id = $1
+	`, id, sent, failed)
+	if err != nil {
+		return fmt.Errorf("failed to record announcement batch: %w", err)
+	}
+	return nil
+}
+
+// Complete completes an announcement in progress once every recipient was sent to
+func (r *AnnouncementRepository) Complete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE announcements
+		SET status = 'COMPLETED', started_at = COALESCE(started_at, NOW()), finished_at = NOW()
+		WHERE id = $1 AND status IN ('PENDING', 'SENDING')
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete announcement: %w", err)
+	}
+	return nil
+}
+
+// Cancel cancels an announcement in progress, reporting false when it is no longer in progress
+func (r *AnnouncementRepository) Cancel(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE announcements
+		SET status = 'CANCELED', finished_at = NOW()
+		WHERE id = $1 AND status IN ('PENDING', 'SENDING')
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel announcement: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
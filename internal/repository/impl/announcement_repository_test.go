@@ -0,0 +1,83 @@
+package impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnouncementRepository_GetRecipients(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewAnnouncementRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	city := "Казань"
+	after, userID := uuid.New(), uuid.New()
+	announcement := &models.Announcement{ID: uuid.New(), Segment: models.AnnouncementSegmentCity, City: &city}
+	mock.ExpectQuery(`SELECT u.id, u.language FROM users u WHERE u.notifications_enabled AND u.deleted_at IS NULL AND \(\$1::uuid IS NULL OR u.id > \$1\) AND LOWER\(u.city\) = LOWER\(\$3\)`).
+		WithArgs(&after, 100, city).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "language"}).AddRow(userID, "ENGLISH"))
+
+	recipients, err := repo.GetRecipients(context.Background(), announcement, &after, 100)
+	assert.NoError(t, err)
+	if assert.Len(t, recipients, 1) {
+		assert.Equal(t, userID, recipients[0].UserID)
+		assert.Equal(t, models.LanguageEnglish, recipients[0].Language)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAnnouncementRepository_GetRecipients_PlantOwners(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewAnnouncementRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	announcement := &models.Announcement{ID: uuid.New(), Segment: models.AnnouncementSegmentPlantOwners}
+	mock.ExpectQuery(`AND EXISTS \(SELECT 1 FROM user_plants up WHERE up.user_id = u.id\)`).
+		WithArgs(nil, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "language"}))
+
+	recipients, err := repo.GetRecipients(context.Background(), announcement, nil, 100)
+	assert.NoError(t, err)
+	assert.Empty(t, recipients)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAnnouncementRepository_ClaimBatch(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewAnnouncementRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	id, from, to := uuid.New(), uuid.New(), uuid.New()
+	mock.ExpectExec(`UPDATE announcements SET last_user_id = \$3, status = 'SENDING', (.+) WHERE id = \$1 AND status IN \('PENDING', 'SENDING'\) AND last_user_id IS NOT DISTINCT FROM \$2`).
+		WithArgs(id, &from, to).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE announcements").
+		WithArgs(id, &from, to).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err := repo.ClaimBatch(context.Background(), id, &from, to)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// The batch was claimed by another instance or the announcement was canceled
+	claimed, err = repo.ClaimBatch(context.Background(), id, &from, to)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,259 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// inactiveSinceCondition returns the condition matching accounts of users u that were not anonymized,
+// are not admins and were not active since the given SQL expression, counting the use of their
+// personal access tokens and API keys as activity
+func inactiveSinceCondition(since string) string {
+	return `
+		u.anonymized_at IS NULL
+		AND NOT ('admin' = ANY(u.roles))
+		AND u.last_active_at < ` + since + `
+		AND NOT EXISTS (
+			SELECT 1 FROM personal_access_tokens t
+			WHERE t.user_id = u.id AND t.revoked_at IS NULL AND t.last_used_at >= ` + since + `
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM api_keys k
+			WHERE k.user_id = u.id AND k.revoked_at IS NULL AND k.last_used_at >= ` + since + `
+		)
+	`
+}
+
+// anonymizationStatements removes the personal data of an anonymized user $1 while keeping the
+// plants, care history and usage counters the aggregate statistics are built from
+var anonymizationStatements = []string{
+	`DELETE FROM personal_access_tokens WHERE user_id = $1`,
+	`UPDATE api_keys SET name = '', revoked_at = COALESCE(revoked_at, NOW()) WHERE user_id = $1`,
+	`DELETE FROM user_locations WHERE user_id = $1`,
+	`DELETE FROM user_outdoor_locations WHERE user_id = $1`,
+	`DELETE FROM homes WHERE user_id = $1`,
+	`DELETE FROM user_campaigns WHERE user_id = $1`,
+	`DELETE FROM notifications WHERE user_id = $1`,
+	`DELETE FROM notification_bells WHERE user_id = $1`,
+	`DELETE FROM plant_journal_entries WHERE user_id = $1`,
+	`UPDATE plant_questionnaires SET user_id = NULL, additional_preferences = NULL WHERE user_id = $1`,
+	`UPDATE support_tickets SET message = '', context = '{}', updated_at = NOW() WHERE user_id = $1`,
+	`DELETE FROM captured_requests WHERE user_id = $1`,
+	`DELETE FROM plant_availability_subscriptions WHERE user_id = $1`,
+	`UPDATE user_plants SET nickname = NULL, notes = NULL WHERE user_id = $1`,
+	`DELETE FROM user_plant_photos WHERE user_id = $1`,
+	`DELETE FROM plant_reminders WHERE user_id = $1`,
+	`UPDATE audit_log SET changes = '{}' WHERE entity_type = 'users' AND entity_id = $1::text`,
+	`DELETE FROM user_follows WHERE follower_id = $1 OR followee_id = $1`,
+}
+
+// chatAnonymizationStatements clear the chat history of an anonymized user $1 but keep its token counts.
+// The chat tables are created by scripts/chat_tables.sql, so they only run when the tables exist.
+var chatAnonymizationStatements = []string{
+	`UPDATE chat_messages SET content = '', image_url = NULL, image_description = '' WHERE user_id = $1`,
+	`UPDATE chat_sessions SET title = '', system_prompt = '', summary = '' WHERE user_id = $1`,
+}
+
+// AnonymizationRepository is the implementation of the anonymization repository
+type AnonymizationRepository struct {
+	db *db.DB
+}
+
+// NewAnonymizationRepository creates a new anonymization repository
+func NewAnonymizationRepository(db *db.DB) *AnonymizationRepository {
+	return &AnonymizationRepository{
+		db: db.Repository("anonymization"),
+	}
+}
+
+// TouchActivity records that a user was active now, which cancels a pending anonymization
+func (r *AnonymizationRepository) TouchActivity(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET last_active_at = NOW(), inactivity_warned_at = NULL
+		WHERE id = $1 AND anonymized_at IS NULL
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to record user activity: %w", err)
+	}
+	return nil
+}
+
+// GetUnwarnedInactive gets accounts inactive since the given time whose owners were not warned yet
+func (r *AnonymizationRepository) GetUnwarnedInactive(ctx context.Context, inactiveSince time.Time, limit int) ([]*models.InactiveAccount, error) {
+	accounts := []*models.InactiveAccount{}
+	err := r.db.SelectContext(ctx, &accounts, `
+		SELECT u.id, u.name, u.email, u.language, u.last_active_at
+		FROM users u
+		WHERE u.inactivity_warned_at IS NULL AND `+inactiveSinceCondition("$1")+`
+		ORDER BY u.last_active_at
+		LIMIT $2
+	`, inactiveSince, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inactive accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// MarkWarned records that the owner of an account was warned about its anonymization
+func (r *AnonymizationRepository) MarkWarned(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users SET inactivity_warned_at = NOW() WHERE id = $1
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark account as warned: %w", err)
+	}
+	return nil
+}
+
+// GetWarnedInactive gets accounts warned before the given time and inactive since the warning
+func (r *AnonymizationRepository) GetWarnedInactive(ctx context.Context, warnedBefore time.Time, limit int) ([]*models.InactiveAccount, error) {
+	accounts := []*models.InactiveAccount{}
+	err := r.db.SelectContext(ctx, &accounts, `
+		SELECT u.id, u.name, u.email, u.language, u.last_active_at
+		FROM users u
+		WHERE u.inactivity_warned_at < $1 AND `+inactiveSinceCondition("u.inactivity_warned_at")+`
+		ORDER BY u.inactivity_warned_at
+		LIMIT $2
+	`, warnedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warned accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// Anonymize replaces the email of a warned, still inactive account with anonymizedEmail and removes
+// its personal data and credentials; it reports false when the account became active meanwhile
+func (r *AnonymizationRepository) Anonymize(ctx context.Context, userID uuid.UUID, anonymizedEmail string) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Check the activity again in the transaction so a sign-in since the candidates were listed wins
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users u
+		SET name = '', email = $2, password_hash = '', profile_image_url = NULL, city = NULL,
+			notifications_enabled = FALSE, anonymized_at = NOW(), updated_at = NOW()
+		WHERE u.id = $1 AND u.inactivity_warned_at IS NOT NULL AND `+inactiveSinceCondition("u.inactivity_warned_at")+`
+	`, userID, anonymizedEmail)
+	if err != nil {
+		return false, fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	statements := anonymizationStatements
+	var hasChat bool
+	if err := tx.GetContext(ctx, &hasChat, `SELECT to_regclass('chat_messages') IS NOT NULL`); err != nil {
+		return false, fmt.Errorf("failed to check chat tables: %w", err)
+	}
+	if hasChat {
+		statements = append(statements[:len(statements):len(statements)], chatAnonymizationStatements...)
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, userID); err != nil {
+			return false, fmt.Errorf("failed to remove personal data: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// SaveRun saves an anonymization run with the accounts it processed
+func (r *AnonymizationRepository) SaveRun(ctx context.Context, run *models.AnonymizationRun) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO anonymization_runs (dry_run, started_at, finished_at, warned, anonymized, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, run.DryRun, run.StartedAt, run.FinishedAt, run.Warned, run.Anonymized, run.Error).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save anonymization run: %w", err)
+	}
+
+	for _, account := range run.Accounts {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO anonymization_accounts (run_id, user_id, action)
+			VALUES ($1, $2, $3)
+		`, run.ID, account.UserID, account.Action)
+		if err != nil {
+			return fmt.Errorf("failed to save anonymization account: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetRuns gets the most recent anonymization runs
+func (r *AnonymizationRepository) GetRuns(ctx context.Context, limit int) ([]*models.AnonymizationRun, error) {
+	runs := []*models.AnonymizationRun{}
+	err := r.db.SelectContext(ctx, &runs, `
+		SELECT id, dry_run, started_at, finished_at, warned, anonymized, error
+		FROM anonymization_runs
+		ORDER BY started_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anonymization runs: %w", err)
+	}
+	if len(runs) == 0 {
+		return runs, nil
+	}
+
+	// Get the accounts processed by the runs
+	runIDs := make([]uuid.UUID, 0, len(runs))
+	runsByID := make(map[uuid.UUID]*models.AnonymizationRun, len(runs))
+	for _, run := range runs {
+		run.Accounts = []*models.AnonymizationAccount{}
+		runIDs = append(runIDs, run.ID)
+		runsByID[run.ID] = run
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT run_id, user_id, action
+		FROM anonymization_accounts
+		WHERE run_id IN (?)
+		ORDER BY action, user_id
+	`, runIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build anonymization accounts query: %w", err)
+	}
+
+	var rows []struct {
+		RunID uuid.UUID `db:"run_id"`
+		models.AnonymizationAccount
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get anonymization accounts: %w", err)
+	}
+	for _, row := range rows {
+		account := row.AnonymizationAccount
+		runsByID[row.RunID].Accounts = append(runsByID[row.RunID].Accounts, &account)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,60 @@
+package impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizationRepository_Anonymize(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewAnonymizationRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+	userID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users u").
+		WithArgs(userID, "hash@anonymized.invalid").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT to_regclass").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	for range anonymizationStatements {
+		mock.ExpectExec("(DELETE FROM|UPDATE) ").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	anonymized, err := repo.Anonymize(context.Background(), userID, "hash@anonymized.invalid")
+	assert.NoError(t, err)
+	assert.True(t, anonymized)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAnonymizationRepository_Anonymize_BecameActive(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewAnonymizationRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+	userID := uuid.New()
+
+	// Nothing else is touched when the account was used since the candidates were listed
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users u").
+		WithArgs(userID, "hash@anonymized.invalid").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	anonymized, err := repo.Anonymize(context.Background(), userID, "hash@anonymized.invalid")
+	assert.NoError(t, err)
+	assert.False(t, anonymized)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,139 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// APIKeyRepository is the implementation of the API key repository
+type APIKeyRepository struct {
+	db *db.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *db.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db.Repository("api_key"),
+	}
+}
+
+// Create creates a new API key
+func (r *APIKeyRepository) Create(ctx context.Context, apiKey *models.APIKey) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO api_keys (user_id, name, key_hash, key_prefix)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, apiKey.UserID, apiKey.Name, apiKey.KeyHash, apiKey.KeyPrefix).
+		Scan(&apiKey.ID, &apiKey.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+// GetByHash gets an active API key by the hash of its raw value
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := r.db.GetContext(ctx, &apiKey, `
+		SELECT id, user_id, name, key_hash, key_prefix, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`, keyHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("API key not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &apiKey, nil
+}
+
+// GetByUser gets all API keys of a user
+func (r *APIKeyRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	var apiKeys []*models.APIKey
+	err := r.db.SelectContext(ctx, &apiKeys, `
+		SELECT id, user_id, name, key_hash, key_prefix, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	return apiKeys, nil
+}
+
+// Revoke revokes an API key owned by a user
+func (r *APIKeyRepository) Revoke(ctx context.Context, keyID uuid.UUID, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, keyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("API key not found or not owned by user")
+	}
+
+	return nil
+}
+
+// RecordUsage increments the request counter of an API key for the current day
+func (r *APIKeyRepository) RecordUsage(ctx context.Context, keyID uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO api_key_usage (api_key_id, day, request_count)
+		VALUES ($1, CURRENT_DATE, 1)
+		ON CONFLICT (api_key_id, day) DO UPDATE
+		SET request_count = api_key_usage.request_count + 1
+	`, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to record API key usage: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE id = $1
+	`, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to update API key last used: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetUsage gets the daily usage of an API key since the given time
+func (r *APIKeyRepository) GetUsage(ctx context.Context, keyID uuid.UUID, since time.Time) ([]*models.APIKeyUsage, error) {
+	var usage []*models.APIKeyUsage
+	err := r.db.SelectContext(ctx, &usage, `
+		SELECT api_key_id, day, request_count
+		FROM api_key_usage
+		WHERE api_key_id = $1 AND day >= $2::date
+		ORDER BY day DESC
+	`, keyID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,73 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// auditLogCondition matches the audit log entries of the actor $1, entity type $2, entity ID $3 and
+// action $4, created from $5 until before $6; unset parameters match every entry
+const auditLogCondition = `
+	($1::uuid IS NULL OR actor_id = $1)
+	AND ($2::text = '' OR entity_type = $2)
+	AND ($3::text = '' OR entity_id = $3)
+	AND ($4::text = '' OR action = $4)
+	AND ($5::timestamptz IS NULL OR created_at >= $5)
+	AND ($6::timestamptz IS NULL OR created_at < $6)
+`
+
+// AuditRepository is the implementation of the audit repository
+type AuditRepository struct {
+	db *db.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *db.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db.Repository("audit"),
+	}
+}
+
+// Record adds an entry to the audit log
+func (r *AuditRepository) Record(ctx context.Context, entry *models.AuditEntry) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO audit_log (actor_id, action, entity_type, entity_id, changes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, entry.ActorID, entry.Action, entry.EntityType, entry.EntityID, entry.Changes).
+		Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// List gets the entries matching a filter, newest first, with the number of all matching entries
+func (r *AuditRepository) List(ctx context.Context, filter *models.AuditLogFilter, limit int, offset int) ([]*models.AuditEntry, int, error) {
+	args := []interface{}{filter.ActorID, filter.EntityType, filter.EntityID, filter.Action, filter.Since, filter.Until}
+
+	var total int
+	err := r.db.GetContext(ctx, &total, `
+		SELECT COUNT(*)
+		FROM audit_log
+		WHERE `+auditLogCondition, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	entries := []*models.AuditEntry{}
+	err = r.db.SelectContext(ctx, &entries, `
+		SELECT id, actor_id, action, entity_type, entity_id, changes, created_at
+		FROM audit_log
+		WHERE `+auditLogCondition+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7 OFFSET $8
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,71 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRepository_Record(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewAuditRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	actorID, entryID := uuid.New(), uuid.New()
+	entry := &models.AuditEntry{
+		ActorID:    &actorID,
+		Action:     models.AuditActionUpdateProfile,
+		EntityType: "users",
+		EntityID:   actorID.String(),
+		Changes:    models.AuditChanges{"city": map[string]interface{}{"from": nil, "to": "Moscow"}},
+	}
+	mock.ExpectQuery("INSERT INTO audit_log \\(actor_id, action, entity_type, entity_id, changes\\)").
+		WithArgs(&actorID, models.AuditActionUpdateProfile, "users", actorID.String(), []byte(`{"city":{"from":null,"to":"Moscow"}}`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(entryID, now))
+
+	assert.NoError(t, repo.Record(context.Background(), entry))
+	assert.Equal(t, entryID, entry.ID)
+	assert.Equal(t, now, entry.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepository_List(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewAuditRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	shopID := uuid.New()
+	filter := &models.AuditLogFilter{EntityType: "shops", EntityID: shopID.String(), Since: &now}
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM audit_log WHERE").
+		WithArgs(nil, "shops", shopID.String(), "", &now, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT id, actor_id, action, entity_type, entity_id, changes, created_at FROM audit_log WHERE .* ORDER BY created_at DESC, id DESC LIMIT \\$7 OFFSET \\$8").
+		WithArgs(nil, "shops", shopID.String(), "", &now, nil, 50, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_id", "action", "entity_type", "entity_id", "changes", "created_at"}).
+			AddRow(uuid.New(), nil, "DELETE /admin/shops/{shopId}", "shops", shopID.String(), []byte(`{"vars":{"shopId":"`+shopID.String()+`"}}`), now))
+
+	entries, total, err := repo.List(context.Background(), filter, 50, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	if assert.Len(t, entries, 1) {
+		assert.Nil(t, entries[0].ActorID)
+		assert.Equal(t, "DELETE /admin/shops/{shopId}", entries[0].Action)
+		assert.Equal(t, map[string]interface{}{"shopId": shopID.String()}, entries[0].Changes["vars"])
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,55 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// BadgeRepository is the implementation of the badge repository
+type BadgeRepository struct {
+	db *db.DB
+}
+
+// NewBadgeRepository creates a new badge repository
+func NewBadgeRepository(db *db.DB) *BadgeRepository {
+	return &BadgeRepository{
+		db: db.Repository("badge"),
+	}
+}
+
+// Award awards a badge to a user, reporting false when the user has it already
+func (r *BadgeRepository) Award(ctx context.Context, userID uuid.UUID, badge models.BadgeType) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_badges (user_id, badge)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, badge) DO NOTHING
+	`, userID, badge)
+	if err != nil {
+		return false, fmt.Errorf("failed to award badge: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ListByUser gets the badges of a user in the order they were earned
+func (r *BadgeRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Badge, error) {
+	badges := []*models.Badge{}
+	err := r.db.SelectContext(ctx, &badges, `
+		SELECT badge, awarded_at
+		FROM user_badges
+		WHERE user_id = $1
+		ORDER BY awarded_at ASC, badge ASC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get badges: %w", err)
+	}
+	return badges, nil
+}
@@ -0,0 +1,246 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/cache"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// CachedPlantRepository caches the catalog reads of a plant repository: GetAll, GetByID and Search.
+// Creating, updating and deleting plants invalidates the cache. Collections and favorites are read
+// from the repository.
+type CachedPlantRepository struct {
+	repository.PlantRepository
+	cache cache.Cache
+}
+
+// NewCachedPlantRepository creates a new plant repository caching the catalog reads of plantRepo
+func NewCachedPlantRepository(plantRepo repository.PlantRepository, catalogCache cache.Cache) *CachedPlantRepository {
+	return &CachedPlantRepository{
+		PlantRepository: plantRepo,
+		cache:           catalogCache,
+	}
+}
+
+// GetAll gets all plants
+func (r *CachedPlantRepository) GetAll(ctx context.Context) ([]*models.Plant, error) {
+	var plants []*models.Plant
+	err := loadCached(ctx, r.cache, "all", &plants, func() (interface{}, error) {
+		return r.PlantRepository.GetAll(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plants, nil
+}
+
+// GetByID gets a plant by ID
+func (r *CachedPlantRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Plant, error) {
+	var plant *models.Plant
+	err := loadCached(ctx, r.cache, "plant:"+id.String(), &plant, func() (interface{}, error) {
+		return r.PlantRepository.GetByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plant, nil
+}
+
+// Search gets the catalog plants whose name, scientific name or description contain the text of
+// the query and that match its filters, ordered by name
+func (r *CachedPlantRepository) Search(ctx context.Context, query *models.PlantSearchQuery) ([]*models.Plant, error) {
+	key, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search query: %w", err)
+	}
+
+	var plants []*models.Plant
+	err = loadCached(ctx, r.cache, "search:"+string(key), &plants, func() (interface{}, error) {
+		return r.PlantRepository.Search(ctx, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plants, nil
+}
+
+// CreatePlant creates a new plant and invalidates the cache
+func (r *CachedPlantRepository) CreatePlant(ctx context.Context, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, error) {
+	created, err := r.PlantRepository.CreatePlant(ctx, plant, careInstructions)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Invalidate(ctx)
+	return created, nil
+}
+
+// UpdatePlant updates a plant in the catalog and its care instructions together and invalidates the cache
+func (r *CachedPlantRepository) UpdatePlant(ctx context.Context, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, error) {
+	updated, err := r.PlantRepository.UpdatePlant(ctx, plant, careInstructions)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Invalidate(ctx)
+	return updated, nil
+}
+
+// UpdatePlantImage replaces the image of a plant in the catalog and invalidates the cache
+func (r *CachedPlantRepository) UpdatePlantImage(ctx context.Context, plantID uuid.UUID, imageURL models.AssetKey) error {
+	if err := r.PlantRepository.UpdatePlantImage(ctx, plantID, imageURL); err != nil {
+		return err
+	}
+	r.cache.Invalidate(ctx)
+	return nil
+}
+
+// DeletePlant removes a plant from the catalog and invalidates the cache
+func (r *CachedPlantRepository) DeletePlant(ctx context.Context, id uuid.UUID) error {
+	if err := r.PlantRepository.DeletePlant(ctx, id); err != nil {
+		return err
+	}
+	r.cache.Invalidate(ctx)
+	return nil
+}
+
+// CachedPlantSpeciesRepository invalidates the catalog cache when a species changes, since updating
+// a species resolves the care instructions of its cultivars again
+type CachedPlantSpeciesRepository struct {
+	repository.PlantSpeciesRepository
+	cache cache.Cache
+}
+
+// NewCachedPlantSpeciesRepository creates a new species repository invalidating the catalog cache on updates
+func NewCachedPlantSpeciesRepository(speciesRepo repository.PlantSpeciesRepository, catalogCache cache.Cache) *CachedPlantSpeciesRepository {
+	return &CachedPlantSpeciesRepository{
+		PlantSpeciesRepository: speciesRepo,
+		cache:                  catalogCache,
+	}
+}
+
+// Update updates a species, its care instructions and those of its cultivars and invalidates the cache
+func (r *CachedPlantSpeciesRepository) Update(ctx context.Context, species *models.PlantSpecies) error {
+	if err := r.PlantSpeciesRepository.Update(ctx, species); err != nil {
+		return err
+	}
+	r.cache.Invalidate(ctx)
+	return nil
+}
+
+// CachedPlantEnrichmentRepository invalidates the catalog cache when an approved enrichment changes a plant
+type CachedPlantEnrichmentRepository struct {
+	repository.PlantEnrichmentRepository
+	cache cache.Cache
+}
+
+// NewCachedPlantEnrichmentRepository creates a new plant enrichment repository invalidating the catalog
+// cache on approvals
+func NewCachedPlantEnrichmentRepository(enrichmentRepo repository.PlantEnrichmentRepository, catalogCache cache.Cache) *CachedPlantEnrichmentRepository {
+	return &CachedPlantEnrichmentRepository{
+		PlantEnrichmentRepository: enrichmentRepo,
+		cache:                     catalogCache,
+	}
+}
+
+// Review reviews a proposal and invalidates the cache when it was approved
+func (r *CachedPlantEnrichmentRepository) Review(ctx context.Context, id uuid.UUID, status models.PlantEnrichmentStatus, reviewerID uuid.UUID) (*models.PlantEnrichmentProposal, error) {
+	proposal, err := r.PlantEnrichmentRepository.Review(ctx, id, status, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	if status == models.PlantEnrichmentStatusApproved {
+		r.cache.Invalidate(ctx)
+	}
+	return proposal, nil
+}
+
+// CachedPlantReviewRepository invalidates the catalog cache when a plant changes its review state, since
+// cached plant details carry the state
+type CachedPlantReviewRepository struct {
+	repository.PlantReviewRepository
+	cache cache.Cache
+}
+
+// NewCachedPlantReviewRepository creates a new plant review repository invalidating the catalog cache
+// on status changes
+func NewCachedPlantReviewRepository(reviewRepo repository.PlantReviewRepository, catalogCache cache.Cache) *CachedPlantReviewRepository {
+	return &CachedPlantReviewRepository{
+		PlantReviewRepository: reviewRepo,
+		cache:                 catalogCache,
+	}
+}
+
+// SetStatus moves a plant to another review state and invalidates the cache
+func (r *CachedPlantReviewRepository) SetStatus(ctx context.Context, plantID uuid.UUID, from models.PlantStatus, to models.PlantStatus) error {
+	if err := r.PlantReviewRepository.SetStatus(ctx, plantID, from, to); err != nil {
+		return err
+	}
+	r.cache.Invalidate(ctx)
+	return nil
+}
+
+// CachedPlantTaxonomyRepository invalidates the catalog cache when the categories or tags of plants
+// change, since cached plants carry them
+type CachedPlantTaxonomyRepository struct {
+	repository.PlantTaxonomyRepository
+	cache cache.Cache
+}
+
+// NewCachedPlantTaxonomyRepository creates a new plant taxonomy repository invalidating the catalog
+// cache on changes
+func NewCachedPlantTaxonomyRepository(taxonomyRepo repository.PlantTaxonomyRepository, catalogCache cache.Cache) *CachedPlantTaxonomyRepository {
+	return &CachedPlantTaxonomyRepository{
+		PlantTaxonomyRepository: taxonomyRepo,
+		cache:                   catalogCache,
+	}
+}
+
+// UpdateCategory updates a category and invalidates the cache
+func (r *CachedPlantTaxonomyRepository) UpdateCategory(ctx context.Context, category *models.Category) error {
+	if err := r.PlantTaxonomyRepository.UpdateCategory(ctx, category); err != nil {
+		return err
+	}
+	r.cache.Invalidate(ctx)
+	return nil
+}
+
+// DeleteCategory deletes a category and invalidates the cache
+func (r *CachedPlantTaxonomyRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	if err := r.PlantTaxonomyRepository.DeleteCategory(ctx, id); err != nil {
+		return err
+	}
+	r.cache.Invalidate(ctx)
+	return nil
+}
+
+// SetPlantTaxonomy replaces the categories and tags of a plant and invalidates the cache
+func (r *CachedPlantTaxonomyRepository) SetPlantTaxonomy(ctx context.Context, plantID uuid.UUID, taxonomy *models.PlantTaxonomy) error {
+	if err := r.PlantTaxonomyRepository.SetPlantTaxonomy(ctx, plantID, taxonomy); err != nil {
+		return err
+	}
+	r.cache.Invalidate(ctx)
+	return nil
+}
+
+// loadCached decodes the cached JSON of a key into target, loading and encoding the value on a miss.
+// Every call decodes a copy, so callers may change what they get.
+func loadCached(ctx context.Context, c cache.Cache, key string, target interface{}, load func() (interface{}, error)) error {
+	data, err := c.Load(ctx, key, func() ([]byte, error) {
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	})
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode cached %s: %w", key, err)
+	}
+	return nil
+}
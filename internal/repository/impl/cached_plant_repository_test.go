@@ -0,0 +1,82 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/cache"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingPlantRepository serves one plant and counts the catalog reads
+type countingPlantRepository struct {
+	repository.PlantRepository
+	plant *models.Plant
+	reads int
+}
+
+func (r *countingPlantRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Plant, error) {
+	r.reads++
+	if id != r.plant.ID {
+		return nil, fmt.Errorf("plant not found: %w", sql.ErrNoRows)
+	}
+	plant := *r.plant
+	return &plant, nil
+}
+
+func (r *countingPlantRepository) DeletePlant(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func TestCachedPlantRepository_GetByID(t *testing.T) {
+	family := "Araceae"
+	petFriendly := false
+	reviewedAt := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	sunlight := models.SunlightLevelHigh
+	plant := &models.Plant{
+		ID:             uuid.New(),
+		Name:           "Monstera",
+		ScientificName: "Monstera deliciosa",
+		Family:         &family,
+		PetFriendly:    &petFriendly,
+		ImageURL:       "plants/monstera.jpg",
+		CareInstructions: models.CareInstructions{
+			ID:                uuid.New(),
+			WateringFrequency: 7,
+			Sunlight:          models.SunlightLevelMedium,
+			Temperature:       models.TemperatureRange{Min: 18, Max: 27},
+			LastReviewedAt:    &reviewedAt,
+		},
+		CareOverrides: &models.CareOverrides{Sunlight: &sunlight},
+		CreatedAt:     reviewedAt,
+		UpdatedAt:     reviewedAt,
+	}
+	plantRepo := &countingPlantRepository{plant: plant}
+	repo := NewCachedPlantRepository(plantRepo, cache.NewMemory(time.Minute, 0))
+	ctx := context.Background()
+
+	// The second read is served from the cache with every field intact, as a copy
+	first, err := repo.GetByID(ctx, plant.ID)
+	assert.NoError(t, err)
+	first.IsFavorite = true
+	second, err := repo.GetByID(ctx, plant.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, plant, second)
+	assert.Equal(t, 1, plantRepo.reads)
+
+	// Missing plants are not cached
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Deleting a plant invalidates the cache
+	assert.NoError(t, repo.DeletePlant(ctx, plant.ID))
+	_, err = repo.GetByID(ctx, plant.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, plantRepo.reads)
+}
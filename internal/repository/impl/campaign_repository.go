@@ -0,0 +1,80 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// CampaignRepository is the implementation of the campaign repository
+type CampaignRepository struct {
+	db *db.DB
+}
+
+// NewCampaignRepository creates a new campaign repository
+func NewCampaignRepository(db *db.DB) *CampaignRepository {
+	return &CampaignRepository{
+		db: db.Repository("campaign"),
+	}
+}
+
+// Enroll enrolls a user in a campaign, reporting false when the user already was
+func (r *CampaignRepository) Enroll(ctx context.Context, enrollment *models.CampaignEnrollment) (bool, error) {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO user_campaigns (user_id, campaign, plant_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, campaign) DO NOTHING
+		RETURNING next_step, enrolled_at
+	`, enrollment.UserID, enrollment.Campaign, enrollment.PlantID).
+		Scan(&enrollment.NextStep, &enrollment.EnrolledAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to enroll user in campaign: %w", err)
+	}
+	return true, nil
+}
+
+// GetActive gets the enrollments that have steps left, of users who get notifications
+func (r *CampaignRepository) GetActive(ctx context.Context) ([]*models.CampaignEnrollment, error) {
+	enrollments := []*models.CampaignEnrollment{}
+	err := r.db.SelectContext(ctx, &enrollments, `
+		SELECT uc.user_id, uc.campaign, uc.plant_id, uc.next_step, uc.enrolled_at, uc.completed_at, u.language
+		FROM user_campaigns uc
+		JOIN users u ON uc.user_id = u.id
+		WHERE uc.completed_at IS NULL AND u.notifications_enabled AND u.deleted_at IS NULL
+		ORDER BY uc.enrolled_at ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active campaign enrollments: %w", err)
+	}
+	return enrollments, nil
+}
+
+// SetNextStep records the step of a campaign a user gets next, completing the enrollment when
+// no step is left
+func (r *CampaignRepository) SetNextStep(ctx context.Context, userID uuid.UUID, campaign string, nextStep int, completed bool) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_campaigns
+		SET next_step = $3, completed_at = CASE WHEN $4 THEN NOW() END
+		WHERE user_id = $1 AND campaign = $2
+	`, userID, campaign, nextStep, completed)
+	if err != nil {
+		return fmt.Errorf("failed to set next campaign step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCampaignRepository_Enroll(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewCampaignRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID, plantID := uuid.New(), uuid.New()
+	enrolledAt := time.Date(2024, time.May, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("INSERT INTO user_campaigns (.+) ON CONFLICT (.+) DO NOTHING").
+		WithArgs(userID, "WELCOME", &plantID).
+		WillReturnRows(sqlmock.NewRows([]string{"next_step", "enrolled_at"}).AddRow(0, enrolledAt))
+
+	enrollment := &models.CampaignEnrollment{UserID: userID, Campaign: "WELCOME", PlantID: &plantID}
+	enrolled, err := repo.Enroll(context.Background(), enrollment)
+	assert.NoError(t, err)
+	assert.True(t, enrolled)
+	assert.Equal(t, enrolledAt, enrollment.EnrolledAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCampaignRepository_Enroll_AlreadyEnrolled(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewCampaignRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID := uuid.New()
+	mock.ExpectQuery("INSERT INTO user_campaigns").
+		WithArgs(userID, "WELCOME", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"next_step", "enrolled_at"}))
+
+	enrolled, err := repo.Enroll(context.Background(), &models.CampaignEnrollment{UserID: userID, Campaign: "WELCOME"})
+	assert.NoError(t, err)
+	assert.False(t, enrolled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,90 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// capturedRequestColumns are the columns selected for a captured request
+const capturedRequestColumns = `id, method, path, query, headers, body, body_status, user_id, status, duration_ms, captured_at`
+
+// CapturedRequestRepository is the implementation of the captured request repository
+type CapturedRequestRepository struct {
+	db *db.DB
+}
+
+// NewCapturedRequestRepository creates a new captured request repository
+func NewCapturedRequestRepository(db *db.DB) *CapturedRequestRepository {
+	return &CapturedRequestRepository{
+		db: db.Repository("captured_request"),
+	}
+}
+
+// Create stores a captured request
+func (r *CapturedRequestRepository) Create(ctx context.Context, request *models.CapturedRequest) error {
+	// The user may have been deleted since the request, which must not lose the capture
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO captured_requests (method, path, query, headers, body, body_status, user_id, status, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, (SELECT id FROM users WHERE id = $7), $8, $9)
+		RETURNING id, user_id, captured_at
+	`, request.Method, request.Path, request.Query, request.Headers, request.Body, request.BodyStatus,
+		request.UserID, request.Status, request.DurationMs).Scan(&request.ID, &request.UserID, &request.CapturedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create captured request: %w", err)
+	}
+	return nil
+}
+
+// GetByID gets a captured request by ID
+func (r *CapturedRequestRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.CapturedRequest, error) {
+	var request models.CapturedRequest
+	err := r.db.GetContext(ctx, &request, `
+		SELECT `+capturedRequestColumns+`
+		FROM captured_requests
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("captured request not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get captured request: %w", err)
+	}
+	return &request, nil
+}
+
+// List gets the most recently captured requests
+func (r *CapturedRequestRepository) List(ctx context.Context, limit int) ([]*models.CapturedRequest, error) {
+	requests := []*models.CapturedRequest{}
+	err := r.db.SelectContext(ctx, &requests, `
+		SELECT `+capturedRequestColumns+`
+		FROM captured_requests
+		ORDER BY captured_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list captured requests: %w", err)
+	}
+	return requests, nil
+}
+
+// DeleteBefore deletes the requests captured before the given time and returns how many were deleted
+func (r *CapturedRequestRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM captured_requests WHERE captured_at < $1
+	`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete captured requests: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,190 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// CareFeedbackRepository is the implementation of the care feedback repository
+type CareFeedbackRepository struct {
+	db *db.DB
+}
+
+// NewCareFeedbackRepository creates a new care feedback repository
+func NewCareFeedbackRepository(db *db.DB) *CareFeedbackRepository {
+	return &CareFeedbackRepository{
+		db: db.Repository("care_feedback"),
+	}
+}
+
+// Upsert saves a user's answer for a plant, replacing an earlier one
+func (r *CareFeedbackRepository) Upsert(ctx context.Context, feedback *models.CareFeedback) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO care_feedback (user_id, plant_id, rating)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, plant_id) DO UPDATE
+		SET rating = EXCLUDED.rating, created_at = NOW()
+		RETURNING id, created_at
+	`, feedback.UserID, feedback.PlantID, feedback.Rating).
+		Scan(&feedback.ID, &feedback.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save care feedback: %w", err)
+	}
+	return nil
+}
+
+// GetFeedbackCandidates gets user plants owned since before the given time whose owners were not asked yet
+func (r *CareFeedbackRepository) GetFeedbackCandidates(ctx context.Context, ownedBefore time.Time) ([]*models.UserPlant, error) {
+	rows, err := r.db.QueryxContext(ctx, `
+		SELECT up.id, up.user_id, up.plant_id, up.location, up.created_at, p.name, u.language
+		FROM user_plants up
+		JOIN plants p ON up.plant_id = p.id
+		JOIN users u ON up.user_id = u.id
+		WHERE up.created_at <= $1 AND u.anonymized_at IS NULL AND u.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM care_feedback f
+				WHERE f.user_id = up.user_id AND f.plant_id = up.plant_id
+			)
+			AND NOT EXISTS (
+				SELECT 1 FROM notifications n
+				WHERE n.user_id = up.user_id AND n.plant_id = up.plant_id AND n.type = $2
+			)
+		ORDER BY up.created_at ASC
+	`, ownedBefore, models.NotificationTypeCareFeedback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care feedback candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var userPlants []*models.UserPlant
+	for rows.Next() {
+		var userPlant models.UserPlant
+		var plantName string
+		err := rows.Scan(
+			&userPlant.ID, &userPlant.UserID, &userPlant.PlantID, &userPlant.Location,
+			&userPlant.CreatedAt, &plantName, &userPlant.UserLanguage,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan care feedback candidate: %w", err)
+		}
+
+		userPlant.Plant = &models.Plant{
+			ID:   userPlant.PlantID,
+			Name: plantName,
+		}
+		userPlants = append(userPlants, &userPlant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating care feedback candidates: %w", err)
+	}
+	return userPlants, nil
+}
+
+// GetTotals gets the answers collected for every plant with feedback
+func (r *CareFeedbackRepository) GetTotals(ctx context.Context) ([]*models.CareFeedbackTotals, error) {
+	totals := []*models.CareFeedbackTotals{}
+	err := r.db.SelectContext(ctx, &totals, `
+		SELECT f.plant_id, c.difficulty,
+			   COUNT(*) FILTER (WHERE f.rating = 'EASIER') AS easier,
+			   COUNT(*) FILTER (WHERE f.rating = 'AS_EXPECTED') AS as_expected,
+			   COUNT(*) FILTER (WHERE f.rating = 'HARDER') AS harder
+		FROM care_feedback f
+		JOIN plants p ON f.plant_id = p.id
+		JOIN care_instructions c ON p.care_instructions_id = c.id
+		GROUP BY f.plant_id, c.difficulty
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care feedback totals: %w", err)
+	}
+	return totals, nil
+}
+
+// SaveCommunityDifficulties saves recalibrated community difficulties
+func (r *CareFeedbackRepository) SaveCommunityDifficulties(ctx context.Context, difficulties []*models.CommunityDifficulty) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, difficulty := range difficulties {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO plant_community_difficulty (plant_id, score, responses, updated_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (plant_id) DO UPDATE
+			SET score = EXCLUDED.score, responses = EXCLUDED.responses, updated_at = EXCLUDED.updated_at
+		`, difficulty.PlantID, difficulty.Score, difficulty.Responses, difficulty.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save community difficulty: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetDifficulty gets the editorial and community difficulty of a plant
+func (r *CareFeedbackRepository) GetDifficulty(ctx context.Context, plantID uuid.UUID) (*models.PlantDifficulty, error) {
+	difficulty := &models.PlantDifficulty{PlantID: plantID}
+	err := r.db.GetContext(ctx, &difficulty.Editorial, `
+		SELECT c.difficulty
+		FROM plants p
+		JOIN care_instructions c ON p.care_instructions_id = c.id
+		WHERE p.id = $1
+	`, plantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("plant not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get editorial difficulty: %w", err)
+	}
+
+	var community models.CommunityDifficulty
+	err = r.db.GetContext(ctx, &community, `
+		SELECT plant_id, score, responses, updated_at
+		FROM plant_community_difficulty
+		WHERE plant_id = $1
+	`, plantID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get community difficulty: %w", err)
+	}
+	if err == nil {
+		difficulty.Community = &community
+	}
+
+	return difficulty, nil
+}
+
+// SetEditorialDifficulty sets the editorial difficulty of a plant
+func (r *CareFeedbackRepository) SetEditorialDifficulty(ctx context.Context, plantID uuid.UUID, difficulty int) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE care_instructions c
+		SET difficulty = $2, updated_at = NOW()
+		FROM plants p
+		WHERE p.care_instructions_id = c.id AND p.id = $1
+	`, plantID, difficulty)
+	if err != nil {
+		return fmt.Errorf("failed to set editorial difficulty: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("plant not found")
+	}
+
+	return nil
+}
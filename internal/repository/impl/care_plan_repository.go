@@ -0,0 +1,164 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// CarePlanRepository is the implementation of the care plan repository
+type CarePlanRepository struct {
+	db *db.DB
+}
+
+// NewCarePlanRepository creates a new care plan repository
+func NewCarePlanRepository(db *db.DB) *CarePlanRepository {
+	return &CarePlanRepository{
+		db: db.Repository("care_plan"),
+	}
+}
+
+// Save stores a care plan with its months and reminders, replacing the plant's previous plan
+func (r *CarePlanRepository) Save(ctx context.Context, plan *models.CarePlan, reminders []*models.CarePlanReminder) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The months and reminders of the previous plan are removed with it
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM care_plans WHERE user_id = $1 AND plant_id = $2
+	`, plan.UserID, plan.PlantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete previous care plan: %w", err)
+	}
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO care_plans (user_id, plant_id, hemisphere, starts_on, summary)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, plan.UserID, plan.PlantID, plan.Hemisphere, plan.StartsOn, plan.Summary).Scan(&plan.ID, &plan.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save care plan: %w", err)
+	}
+
+	for _, month := range plan.Months {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO care_plan_months (plan_id, month, fertilize, repot, dormant, watering_frequency, notes)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, plan.ID, month.Month, month.Fertilize, month.Repot, month.Dormant, month.WateringFrequency, month.Notes)
+		if err != nil {
+			return fmt.Errorf("failed to save care plan month: %w", err)
+		}
+	}
+
+	for _, reminder := range reminders {
+		reminder.PlanID = plan.ID
+		err = tx.QueryRowxContext(ctx, `
+			INSERT INTO care_plan_reminders (plan_id, type, due_on)
+			VALUES ($1, $2, $3)
+			RETURNING id
+		`, reminder.PlanID, reminder.Type, reminder.DueOn).Scan(&reminder.ID)
+		if err != nil {
+			return fmt.Errorf("failed to save care plan reminder: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetByUserPlant gets the care plan of a plant in a user's collection, or nil if there is none
+func (r *CarePlanRepository) GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) (*models.CarePlan, error) {
+	var plan models.CarePlan
+	err := r.db.GetContext(ctx, &plan, `
+		SELECT id, user_id, plant_id, hemisphere, starts_on, summary, created_at
+		FROM care_plans
+		WHERE user_id = $1 AND plant_id = $2
+	`, userID, plantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get care plan: %w", err)
+	}
+
+	plan.Months = []*models.CarePlanMonth{}
+	err = r.db.SelectContext(ctx, &plan.Months, `
+		SELECT month, fertilize, repot, dormant, watering_frequency, notes
+		FROM care_plan_months
+		WHERE plan_id = $1
+		ORDER BY month ASC
+	`, plan.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care plan months: %w", err)
+	}
+
+	return &plan, nil
+}
+
+// GetDueReminders gets unsent reminders due in [from, to) of plants still in their owners' collections
+func (r *CarePlanRepository) GetDueReminders(ctx context.Context, from time.Time, to time.Time) ([]*models.CarePlanReminder, error) {
+	rows, err := r.db.QueryxContext(ctx, `
+		SELECT r.id, r.plan_id, r.type, r.due_on,
+			up.id, up.user_id, up.plant_id, up.location, up.created_at, p.name, u.language
+		FROM care_plan_reminders r
+		JOIN care_plans cp ON r.plan_id = cp.id
+		JOIN user_plants up ON up.user_id = cp.user_id AND up.plant_id = cp.plant_id
+		JOIN plants p ON up.plant_id = p.id
+		JOIN users u ON up.user_id = u.id
+		WHERE r.sent_at IS NULL AND r.due_on >= $1 AND r.due_on < $2 AND u.anonymized_at IS NULL AND u.deleted_at IS NULL
+		ORDER BY r.due_on ASC
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due care plan reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []*models.CarePlanReminder
+	for rows.Next() {
+		var reminder models.CarePlanReminder
+		var userPlant models.UserPlant
+		var plantName string
+		err := rows.Scan(
+			&reminder.ID, &reminder.PlanID, &reminder.Type, &reminder.DueOn,
+			&userPlant.ID, &userPlant.UserID, &userPlant.PlantID, &userPlant.Location,
+			&userPlant.CreatedAt, &plantName, &userPlant.UserLanguage,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan care plan reminder: %w", err)
+		}
+
+		userPlant.Plant = &models.Plant{
+			ID:   userPlant.PlantID,
+			Name: plantName,
+		}
+		reminder.UserPlant = &userPlant
+		reminders = append(reminders, &reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating care plan reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// MarkReminderSent marks a reminder as sent
+func (r *CarePlanRepository) MarkReminderSent(ctx context.Context, reminderID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE care_plan_reminders SET sent_at = NOW() WHERE id = $1
+	`, reminderID)
+	if err != nil {
+		return fmt.Errorf("failed to mark care plan reminder as sent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// CareTaskRepository is the implementation of the care task repository
+type CareTaskRepository struct {
+	db *db.DB
+}
+
+// NewCareTaskRepository creates a new care task repository
+func NewCareTaskRepository(db *db.DB) *CareTaskRepository {
+	return &CareTaskRepository{
+		db: db.Repository("care_task"),
+	}
+}
+
+// CompleteTask records the completion of a care task; completing a task twice keeps the first completion
+func (r *CareTaskRepository) CompleteTask(ctx context.Context, completion *models.CareTaskCompletion) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO care_task_completions (user_id, plant_id, task_type, due_date)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, plant_id, task_type, due_date)
+		DO UPDATE SET completed_at = care_task_completions.completed_at
+		RETURNING id, completed_at
+	`, completion.UserID, completion.PlantID, completion.TaskType, completion.DueDate).
+		Scan(&completion.ID, &completion.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to complete care task: %w", err)
+	}
+	return nil
+}
+
+// GetCompletions gets the completions of a user plant's tasks due in [from, to)
+func (r *CareTaskRepository) GetCompletions(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, from time.Time, to time.Time) ([]*models.CareTaskCompletion, error) {
+	completions := []*models.CareTaskCompletion{}
+	err := r.db.SelectContext(ctx, &completions, `
+		SELECT id, user_id, plant_id, task_type, due_date, completed_at
+		FROM care_task_completions
+		WHERE user_id = $1 AND plant_id = $2 AND due_date >= $3 AND due_date < $4
+		ORDER BY due_date ASC
+	`, userID, plantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care task completions: %w", err)
+	}
+	return completions, nil
+}
@@ -0,0 +1,148 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// changelogColumns are the columns of a changelog entry
+const changelogColumns = `id, version, titles, bodies, published_at, created_by, created_at, updated_at`
+
+// ChangelogRepository is the implementation of the changelog repository
+type ChangelogRepository struct {
+	db *db.DB
+}
+
+// NewChangelogRepository creates a new changelog repository
+func NewChangelogRepository(db *db.DB) *ChangelogRepository {
+	return &ChangelogRepository{
+		db: db.Repository("changelog"),
+	}
+}
+
+// Create creates a changelog entry
+func (r *ChangelogRepository) Create(ctx context.Context, entry *models.ChangelogEntry) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO changelog_entries (version, titles, bodies, published_at, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, entry.Version, entry.Titles, entry.Bodies, entry.PublishedAt, entry.CreatedBy).
+		Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create changelog entry: %w", err)
+	}
+	return nil
+}
+
+// Update updates the version, texts and publication time of a changelog entry
+func (r *ChangelogRepository) Update(ctx context.Context, entry *models.ChangelogEntry) error {
+	err := r.db.QueryRowxContext(ctx, `
+		UPDATE changelog_entries
+		SET version = $2, titles = $3, bodies = $4, published_at = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_by, created_at, updated_at
+	`, entry.ID, entry.Version, entry.Titles, entry.Bodies, entry.PublishedAt).
+		Scan(&entry.CreatedBy, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("changelog entry not found: %w", err)
+		}
+		return fmt.Errorf("failed to update changelog entry: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a changelog entry
+func (r *ChangelogRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM changelog_entries WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete changelog entry: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("changelog entry not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// GetByID gets a changelog entry
+func (r *ChangelogRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ChangelogEntry, error) {
+	var entry models.ChangelogEntry
+	err := r.db.GetContext(ctx, &entry, `SELECT `+changelogColumns+` FROM changelog_entries WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("changelog entry not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get changelog entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// List gets every changelog entry, scheduled ones included, the latest published first
+func (r *ChangelogRepository) List(ctx context.Context) ([]*models.ChangelogEntry, error) {
+	entries := []*models.ChangelogEntry{}
+	err := r.db.SelectContext(ctx, &entries, `
+		SELECT `+changelogColumns+`
+		FROM changelog_entries
+		ORDER BY published_at DESC, created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changelog entries: %w", err)
+	}
+	return entries, nil
+}
+
+// ListPublished gets the changelog entries published until the given time, newest first, telling
+// whether the user has seen each
+func (r *ChangelogRepository) ListPublished(ctx context.Context, userID uuid.UUID, until time.Time) ([]*models.ChangelogEntry, error) {
+	entries := []*models.ChangelogEntry{}
+	err := r.db.SelectContext(ctx, &entries, `
+		SELECT e.id, e.version, e.titles, e.bodies, e.published_at, e.created_by, e.created_at, e.updated_at,
+			   EXISTS (SELECT 1 FROM changelog_seen s WHERE s.user_id = $1 AND s.entry_id = e.id) AS seen
+		FROM changelog_entries e
+		WHERE e.published_at <= $2
+		ORDER BY e.published_at DESC, e.created_at DESC
+	`, userID, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list published changelog entries: %w", err)
+	}
+	return entries, nil
+}
+
+// MarkSeen marks the given entries published until the given time as seen by the user and returns
+// the number of entries the user had not seen yet
+func (r *ChangelogRepository) MarkSeen(ctx context.Context, userID uuid.UUID, entryIDs []uuid.UUID, until time.Time) (int, error) {
+	ids := make([]string, 0, len(entryIDs))
+	for _, id := range entryIDs {
+		ids = append(ids, id.String())
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO changelog_seen (user_id, entry_id, seen_at)
+		SELECT $1, e.id, $3
+		FROM changelog_entries e
+		WHERE e.id = ANY($2::uuid[]) AND e.published_at <= $3
+		ON CONFLICT (user_id, entry_id) DO NOTHING
+	`, userID, pq.StringArray(ids), until)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark changelog entries as seen: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rows), nil
+}
@@ -0,0 +1,77 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangelogRepository_ListPublished(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewChangelogRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID, entryID := uuid.New(), uuid.New()
+	now := time.Date(2024, time.May, 13, 9, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "version", "titles", "bodies", "published_at", "created_by", "created_at", "updated_at", "seen"}).
+		AddRow(entryID, "2.4.0", []byte(`{"RUSSIAN":"Квиз","ENGLISH":"Quiz"}`), []byte(`{"RUSSIAN":"Ежедневный квиз"}`), now, nil, now, now, true)
+	mock.ExpectQuery("SELECT (.+) FROM changelog_entries e WHERE e.published_at <= \\$2").
+		WithArgs(userID, now).
+		WillReturnRows(rows)
+
+	entries, err := repo.ListPublished(context.Background(), userID, now)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "Quiz", entries[0].Titles[models.LanguageEnglish])
+		assert.True(t, entries[0].Seen)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangelogRepository_MarkSeen(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewChangelogRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID, firstID, secondID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Date(2024, time.May, 13, 9, 0, 0, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO changelog_seen (.+) ON CONFLICT \\(user_id, entry_id\\) DO NOTHING").
+		WithArgs(userID, pq.StringArray{firstID.String(), secondID.String()}, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	marked, err := repo.MarkSeen(context.Background(), userID, []uuid.UUID{firstID, secondID}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, marked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangelogRepository_Delete_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewChangelogRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	entryID := uuid.New()
+	mock.ExpectExec("DELETE FROM changelog_entries").WithArgs(entryID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.Delete(context.Background(), entryID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,107 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// DiagnosisRepository is the implementation of the diagnosis repository
+type DiagnosisRepository struct {
+	db *db.DB
+}
+
+// NewDiagnosisRepository creates a new diagnosis repository
+func NewDiagnosisRepository(db *db.DB) *DiagnosisRepository {
+	return &DiagnosisRepository{
+		db: db.Repository("diagnosis"),
+	}
+}
+
+// Create saves a diagnosis with its findings
+func (r *DiagnosisRepository) Create(ctx context.Context, diagnosis *models.PlantDiagnosis) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO plant_diagnoses (user_id, plant_id, provider)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, diagnosis.UserID, diagnosis.PlantID, diagnosis.Provider).Scan(&diagnosis.ID, &diagnosis.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save diagnosis: %w", err)
+	}
+
+	for _, finding := range diagnosis.Findings {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO plant_diagnosis_findings (diagnosis_id, condition, confidence)
+			VALUES ($1, $2, $3)
+		`, diagnosis.ID, finding.Condition, finding.Confidence)
+		if err != nil {
+			return fmt.Errorf("failed to save diagnosis finding: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetByUserPlant gets the most recent diagnoses of a plant in a user's collection
+func (r *DiagnosisRepository) GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, limit int) ([]*models.PlantDiagnosis, error) {
+	diagnoses := []*models.PlantDiagnosis{}
+	err := r.db.SelectContext(ctx, &diagnoses, `
+		SELECT id, user_id, plant_id, provider, created_at
+		FROM plant_diagnoses
+		WHERE user_id = $1 AND plant_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, userID, plantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diagnoses: %w", err)
+	}
+	if len(diagnoses) == 0 {
+		return diagnoses, nil
+	}
+
+	// Get the findings of the diagnoses
+	diagnosisIDs := make([]uuid.UUID, 0, len(diagnoses))
+	diagnosesByID := make(map[uuid.UUID]*models.PlantDiagnosis, len(diagnoses))
+	for _, diagnosis := range diagnoses {
+		diagnosis.Findings = []*models.DiagnosisFinding{}
+		diagnosisIDs = append(diagnosisIDs, diagnosis.ID)
+		diagnosesByID[diagnosis.ID] = diagnosis
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT diagnosis_id, condition, confidence
+		FROM plant_diagnosis_findings
+		WHERE diagnosis_id IN (?)
+		ORDER BY confidence DESC
+	`, diagnosisIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build diagnosis findings query: %w", err)
+	}
+
+	var rows []struct {
+		DiagnosisID uuid.UUID `db:"diagnosis_id"`
+		models.DiagnosisFinding
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get diagnosis findings: %w", err)
+	}
+	for _, row := range rows {
+		finding := row.DiagnosisFinding
+		diagnosesByID[row.DiagnosisID].Findings = append(diagnosesByID[row.DiagnosisID].Findings, &finding)
+	}
+
+	return diagnoses, nil
+}
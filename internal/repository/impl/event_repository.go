@@ -0,0 +1,54 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// EventRepository is the implementation of the analytics event repository
+type EventRepository struct {
+	db *db.DB
+}
+
+// NewEventRepository creates a new analytics event repository
+func NewEventRepository(db *db.DB) *EventRepository {
+	return &EventRepository{
+		db: db.Repository("event"),
+	}
+}
+
+// CreateBatch stores a batch of analytics events
+func (r *EventRepository) CreateBatch(ctx context.Context, events []*models.AnalyticsEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	_, err := r.db.NamedExecContext(ctx, `
+		INSERT INTO analytics_events (user_id, session_id, type, screen, plant_id, questionnaire_id, variant, occurred_at, received_at)
+		VALUES (:user_id, :session_id, :type, :screen, :plant_id, :questionnaire_id, :variant, :occurred_at, :received_at)
+	`, events)
+	if err != nil {
+		return fmt.Errorf("failed to create analytics events: %w", err)
+	}
+	return nil
+}
+
+// GetDailyCounts gets the number of events per day, type and A/B variant since the given time
+func (r *EventRepository) GetDailyCounts(ctx context.Context, since time.Time) ([]*models.AnalyticsEventCount, error) {
+	counts := []*models.AnalyticsEventCount{}
+	err := r.db.SelectContext(ctx, &counts, `
+		SELECT occurred_at::date AS day, type, variant, COUNT(*) AS count
+		FROM analytics_events
+		WHERE occurred_at >= $1
+		GROUP BY day, type, variant
+		ORDER BY day DESC, type, variant
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analytics event counts: %w", err)
+	}
+	return counts, nil
+}
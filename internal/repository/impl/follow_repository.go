@@ -0,0 +1,67 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// FollowRepository is the implementation of the follow repository
+type FollowRepository struct {
+	db *db.DB
+}
+
+// NewFollowRepository creates a new follow repository
+func NewFollowRepository(db *db.DB) *FollowRepository {
+	return &FollowRepository{
+		db: db.Repository("follow"),
+	}
+}
+
+// Follow makes a user follow another; following a user again is not an error
+func (r *FollowRepository) Follow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_follows (follower_id, followee_id)
+		VALUES ($1, $2)
+		ON CONFLICT (follower_id, followee_id) DO NOTHING
+	`, followerID, followeeID)
+	if err != nil {
+		return fmt.Errorf("failed to follow user: %w", err)
+	}
+	return nil
+}
+
+// Unfollow makes a user stop following another, reporting false when they did not follow them
+func (r *FollowRepository) Unfollow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2
+	`, followerID, followeeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to unfollow user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ListFollowing gets the users a user follows, by name
+func (r *FollowRepository) ListFollowing(ctx context.Context, userID uuid.UUID) ([]*models.FollowedUser, error) {
+	users := []*models.FollowedUser{}
+	err := r.db.SelectContext(ctx, &users, `
+		SELECT u.id AS user_id, u.name, f.created_at AS followed_at
+		FROM user_follows f
+		JOIN users u ON u.id = f.followee_id
+		WHERE f.follower_id = $1 AND u.deleted_at IS NULL
+		ORDER BY u.name ASC, u.id ASC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followed users: %w", err)
+	}
+	return users, nil
+}
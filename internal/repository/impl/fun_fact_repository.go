@@ -0,0 +1,112 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// FunFactRepository is the implementation of the fun fact repository
+type FunFactRepository struct {
+	db *db.DB
+}
+
+// NewFunFactRepository creates a new fun fact repository
+func NewFunFactRepository(db *db.DB) *FunFactRepository {
+	return &FunFactRepository{
+		db: db.Repository("fun_fact"),
+	}
+}
+
+// CreateBatch saves generated fun facts for a plant
+func (r *FunFactRepository) CreateBatch(ctx context.Context, facts []*models.PlantFunFact) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, fact := range facts {
+		err = tx.QueryRowxContext(ctx, `
+			INSERT INTO plant_fun_facts (plant_id, language, fact, status)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		`, fact.PlantID, fact.Language, fact.Fact, fact.Status).
+			Scan(&fact.ID, &fact.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create fun fact: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetByPlant gets the fun facts of a plant in a language with the given status
+func (r *FunFactRepository) GetByPlant(ctx context.Context, plantID uuid.UUID, language models.Language, status models.FunFactStatus) ([]*models.PlantFunFact, error) {
+	facts := []*models.PlantFunFact{}
+	err := r.db.SelectContext(ctx, &facts, `
+		SELECT id, plant_id, language, fact, status, reviewed_at, created_at
+		FROM plant_fun_facts
+		WHERE plant_id = $1 AND language = $2 AND status = $3
+		ORDER BY created_at ASC
+	`, plantID, language, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fun facts: %w", err)
+	}
+	return facts, nil
+}
+
+// CountByPlant counts the fun facts of a plant in a language regardless of status
+func (r *FunFactRepository) CountByPlant(ctx context.Context, plantID uuid.UUID, language models.Language) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `
+		SELECT COUNT(*)
+		FROM plant_fun_facts
+		WHERE plant_id = $1 AND language = $2
+	`, plantID, language)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count fun facts: %w", err)
+	}
+	return count, nil
+}
+
+// GetPending gets all fun facts awaiting review
+func (r *FunFactRepository) GetPending(ctx context.Context) ([]*models.PlantFunFact, error) {
+	facts := []*models.PlantFunFact{}
+	err := r.db.SelectContext(ctx, &facts, `
+		SELECT id, plant_id, language, fact, status, reviewed_at, created_at
+		FROM plant_fun_facts
+		WHERE status = $1
+		ORDER BY created_at ASC
+	`, models.FunFactStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending fun facts: %w", err)
+	}
+	return facts, nil
+}
+
+// UpdateStatus sets the review status of a fun fact
+func (r *FunFactRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.FunFactStatus) (*models.PlantFunFact, error) {
+	var fact models.PlantFunFact
+	err := r.db.GetContext(ctx, &fact, `
+		UPDATE plant_fun_facts
+		SET status = $2, reviewed_at = NOW()
+		WHERE id = $1
+		RETURNING id, plant_id, language, fact, status, reviewed_at, created_at
+	`, id, status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("fun fact not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to update fun fact: %w", err)
+	}
+	return &fact, nil
+}
@@ -0,0 +1,180 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// homeColumns are the columns of a home of user $1, with its plant count and whether the user is
+// staying at it
+const homeColumns = `h.id, h.user_id, h.name, h.timezone, h.latitude, h.longitude, h.outdoor_rooms,
+	h.created_at, h.updated_at,
+	(SELECT COUNT(*) FROM user_plants up WHERE up.home_id = h.id) AS plant_count,
+	EXISTS (SELECT 1 FROM users u WHERE u.id = $1 AND u.current_home_id = h.id) AS current`
+
+// HomeRepository is the implementation of the home repository
+type HomeRepository struct {
+	db *db.DB
+}
+
+// NewHomeRepository creates a new home repository
+func NewHomeRepository(db *db.DB) *HomeRepository {
+	return &HomeRepository{
+		db: db.Repository("home"),
+	}
+}
+
+// List gets the homes of a user by name with the number of plants in each
+func (r *HomeRepository) List(ctx context.Context, userID uuid.UUID) ([]*models.Home, error) {
+	homes := []*models.Home{}
+	err := r.db.SelectContext(ctx, &homes, `
+		SELECT `+homeColumns+`
+		FROM homes h
+		WHERE h.user_id = $1
+		ORDER BY h.name, h.created_at
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list homes: %w", err)
+	}
+	return homes, nil
+}
+
+// GetByID gets a home of a user
+func (r *HomeRepository) GetByID(ctx context.Context, userID uuid.UUID, homeID uuid.UUID) (*models.Home, error) {
+	var home models.Home
+	err := r.db.GetContext(ctx, &home, `
+		SELECT `+homeColumns+`
+		FROM homes h
+		WHERE h.user_id = $1 AND h.id = $2
+	`, userID, homeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("home not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get home: %w", err)
+	}
+	return &home, nil
+}
+
+// Create creates a home
+func (r *HomeRepository) Create(ctx context.Context, home *models.Home) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO homes (user_id, name, timezone, latitude, longitude, outdoor_rooms)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, home.UserID, home.Name, home.Timezone, home.Latitude, home.Longitude, home.OutdoorRooms).
+		Scan(&home.ID, &home.CreatedAt, &home.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create home: %w", err)
+	}
+	return nil
+}
+
+// Update updates the name, timezone, coordinates and outdoor rooms of a home of a user
+func (r *HomeRepository) Update(ctx context.Context, home *models.Home) error {
+	err := r.db.QueryRowxContext(ctx, `
+		UPDATE homes
+		SET name = $3, timezone = $4, latitude = $5, longitude = $6, outdoor_rooms = $7, updated_at = NOW()
+		WHERE user_id = $1 AND id = $2
+		RETURNING created_at, updated_at
+	`, home.UserID, home.ID, home.Name, home.Timezone, home.Latitude, home.Longitude, home.OutdoorRooms).
+		Scan(&home.CreatedAt, &home.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("home not found: %w", err)
+		}
+		return fmt.Errorf("failed to update home: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a home of a user; its plants stay in the collection without a home
+func (r *HomeRepository) Delete(ctx context.Context, userID uuid.UUID, homeID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM homes WHERE user_id = $1 AND id = $2`, userID, homeID)
+	if err != nil {
+		return fmt.Errorf("failed to delete home: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("home not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// AssignPlants moves plants of the user's collection to a home of the user and returns the number of
+// plants moved; plants not in the collection are ignored
+func (r *HomeRepository) AssignPlants(ctx context.Context, userID uuid.UUID, homeID uuid.UUID, plantIDs []uuid.UUID) (int64, error) {
+	ids := make([]string, 0, len(plantIDs))
+	for _, id := range plantIDs {
+		ids = append(ids, id.String())
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_plants
+		SET home_id = $2, updated_at = NOW()
+		WHERE user_id = $1 AND plant_id = ANY($3::uuid[])
+		  AND EXISTS (SELECT 1 FROM homes WHERE user_id = $1 AND id = $2)
+	`, userID, homeID, pq.StringArray(ids))
+	if err != nil {
+		return 0, fmt.Errorf("failed to assign plants to home: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows, nil
+}
+
+// UnassignPlant takes a plant of the user's collection out of a home
+func (r *HomeRepository) UnassignPlant(ctx context.Context, userID uuid.UUID, homeID uuid.UUID, plantID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_plants
+		SET home_id = NULL, updated_at = NOW()
+		WHERE user_id = $1 AND home_id = $2 AND plant_id = $3
+	`, userID, homeID, plantID)
+	if err != nil {
+		return fmt.Errorf("failed to take plant out of home: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("plant not found in home: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// SetCurrent marks the home of the user the user is staying at; nil clears it
+func (r *HomeRepository) SetCurrent(ctx context.Context, userID uuid.UUID, homeID *uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET current_home_id = $2, updated_at = NOW()
+		WHERE id = $1 AND ($2::uuid IS NULL OR EXISTS (SELECT 1 FROM homes WHERE user_id = $1 AND id = $2))
+	`, userID, homeID)
+	if err != nil {
+		return fmt.Errorf("failed to set current home: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("home not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHomeRepository_AssignPlants(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewHomeRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID, homeID, plantID := uuid.New(), uuid.New(), uuid.New()
+	mock.ExpectExec("UPDATE user_plants SET home_id = \\$2").
+		WithArgs(userID, homeID, pq.StringArray{plantID.String()}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	moved, err := repo.AssignPlants(context.Background(), userID, homeID, []uuid.UUID{plantID})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), moved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHomeRepository_SetCurrent_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewHomeRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	// A home of another user is not found
+	userID, homeID := uuid.New(), uuid.New()
+	mock.ExpectExec("UPDATE users SET current_home_id").
+		WithArgs(userID, &homeID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.SetCurrent(context.Background(), userID, &homeID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// JournalRepository is the implementation of the journal repository
+type JournalRepository struct {
+	db *db.DB
+}
+
+// NewJournalRepository creates a new journal repository
+func NewJournalRepository(db *db.DB) *JournalRepository {
+	return &JournalRepository{
+		db: db.Repository("journal"),
+	}
+}
+
+// Create adds an entry to a plant's journal
+func (r *JournalRepository) Create(ctx context.Context, entry *models.PlantJournalEntry) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO plant_journal_entries (user_id, plant_id, type, text)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, entry.UserID, entry.PlantID, entry.Type, entry.Text).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create journal entry: %w", err)
+	}
+	return nil
+}
+
+// GetByUserPlant gets the most recent journal entries of a plant in a user's collection
+func (r *JournalRepository) GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, limit int) ([]*models.PlantJournalEntry, error) {
+	entries := []*models.PlantJournalEntry{}
+	err := r.db.SelectContext(ctx, &entries, `
+		SELECT id, user_id, plant_id, type, text, created_at
+		FROM plant_journal_entries
+		WHERE user_id = $1 AND plant_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, userID, plantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal entries: %w", err)
+	}
+	return entries, nil
+}
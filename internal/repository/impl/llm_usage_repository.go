@@ -0,0 +1,107 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// LLMUsageRepository is the implementation of the Yandex GPT usage repository
+type LLMUsageRepository struct {
+	db *db.DB
+}
+
+// NewLLMUsageRepository creates a new Yandex GPT usage repository
+func NewLLMUsageRepository(db *db.DB) *LLMUsageRepository {
+	return &LLMUsageRepository{
+		db: db.Repository("llm_usage"),
+	}
+}
+
+// Record stores the tokens consumed by a Yandex GPT request
+func (r *LLMUsageRepository) Record(ctx context.Context, usage *models.LLMUsage) error {
+	if usage.ID == uuid.Nil {
+		usage.ID = uuid.New()
+	}
+	if usage.CreatedAt.IsZero() {
+		usage.CreatedAt = time.Now()
+	}
+
+	_, err := r.db.NamedExecContext(ctx, `
+		INSERT INTO llm_usage (id, user_id, input_tokens, completion_tokens, created_at)
+		VALUES (:id, :user_id, :input_tokens, :completion_tokens, :created_at)
+	`, usage)
+	if err != nil {
+		return fmt.Errorf("failed to record LLM usage: %w", err)
+	}
+	return nil
+}
+
+// GetTotals gets the number of requests and tokens since the given time
+func (r *LLMUsageRepository) GetTotals(ctx context.Context, since time.Time) (*models.LLMUsageTotals, error) {
+	var totals models.LLMUsageTotals
+	err := r.db.GetContext(ctx, &totals, `
+		SELECT COUNT(*) AS requests,
+		       COALESCE(SUM(input_tokens), 0) AS input_tokens,
+		       COALESCE(SUM(completion_tokens), 0) AS completion_tokens
+		FROM llm_usage
+		WHERE created_at >= $1
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM usage totals: %w", err)
+	}
+	return &totals, nil
+}
+
+// GetTopUsers gets the users who consumed the most tokens since the given time
+func (r *LLMUsageRepository) GetTopUsers(ctx context.Context, since time.Time, limit int) ([]*models.LLMUserUsage, error) {
+	users := []*models.LLMUserUsage{}
+	err := r.db.SelectContext(ctx, &users, `
+		SELECT user_id, COUNT(*) AS requests, SUM(input_tokens + completion_tokens) AS tokens
+		FROM llm_usage
+		WHERE created_at >= $1 AND user_id IS NOT NULL
+		GROUP BY user_id
+		ORDER BY tokens DESC, user_id
+		LIMIT $2
+	`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top LLM users: %w", err)
+	}
+	return users, nil
+}
+
+// CountUsersOverQuota counts the users who consumed at least the given number of tokens since the given time
+func (r *LLMUsageRepository) CountUsersOverQuota(ctx context.Context, since time.Time, tokens int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `
+		SELECT COUNT(*) FROM (
+			SELECT user_id
+			FROM llm_usage
+			WHERE created_at >= $1 AND user_id IS NOT NULL
+			GROUP BY user_id
+			HAVING SUM(input_tokens + completion_tokens) >= $2
+		) AS over_quota
+	`, since, tokens)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count users over LLM quota: %w", err)
+	}
+	return count, nil
+}
+
+// GetUserTokens gets the number of tokens a user consumed since the given time
+func (r *LLMUsageRepository) GetUserTokens(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error) {
+	var tokens int64
+	err := r.db.GetContext(ctx, &tokens, `
+		SELECT COALESCE(SUM(input_tokens + completion_tokens), 0)
+		FROM llm_usage
+		WHERE user_id = $1 AND created_at >= $2
+	`, userID, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user LLM usage: %w", err)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,64 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// NotificationActionRepository is the implementation of the notification action repository
+type NotificationActionRepository struct {
+	db *db.DB
+}
+
+// NewNotificationActionRepository creates a new notification action repository
+func NewNotificationActionRepository(db *db.DB) *NotificationActionRepository {
+	return &NotificationActionRepository{
+		db: db.Repository("notification_action"),
+	}
+}
+
+// Claim records that a notification action token is used; it reports false when it was used before
+func (r *NotificationActionRepository) Claim(ctx context.Context, token *models.NotificationActionToken) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO notification_action_tokens (id, user_id, action, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO NOTHING
+	`, token.ID, token.UserID, token.Action, time.Unix(token.ExpiresAt, 0).UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to claim notification action token: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// Release undoes the claim of a token whose action could not be performed
+func (r *NotificationActionRepository) Release(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM notification_action_tokens WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to release notification action token: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes the used tokens that expired before the given time and returns how many were deleted
+func (r *NotificationActionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM notification_action_tokens WHERE expires_at < $1
+	`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired notification action tokens: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,48 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationActionRepository_Claim(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewNotificationActionRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	expiresAt := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	token := &models.NotificationActionToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		PlantID:   uuid.New(),
+		Action:    models.NotificationActionWater,
+		ExpiresAt: expiresAt.Unix(),
+	}
+	mock.ExpectExec("INSERT INTO notification_action_tokens (.+) ON CONFLICT \\(id\\) DO NOTHING").
+		WithArgs(token.ID, token.UserID, token.Action, expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO notification_action_tokens").
+		WithArgs(token.ID, token.UserID, token.Action, expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err := repo.Claim(context.Background(), token)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// A replayed token is not claimed again
+	claimed, err = repo.Claim(context.Background(), token)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
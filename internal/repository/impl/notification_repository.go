@@ -3,13 +3,29 @@ package impl
 import (
     "context"
     "database/sql"
+    "errors"
     "fmt"
+    "time"
 
     "github.com/anpanovv/planter/internal/db"
     "github.com/anpanovv/planter/internal/models"
     "github.com/google/uuid"
+    "github.com/jmoiron/sqlx"
 )
 
+// refreshNotificationBells recounts the bells of users $1 and $2 from their notifications, for
+// statements that move or remove the notifications of whole accounts
+const refreshNotificationBells = `
+    INSERT INTO notification_bells (user_id, unread_count, latest_notification_at)
+    SELECT u.id, COUNT(n.id) FILTER (WHERE NOT n.is_read), MAX(n.created_at)
+    FROM users u
+    LEFT JOIN notifications n ON n.user_id = u.id
+    WHERE u.id IN ($1, $2)
+    GROUP BY u.id
+    ON CONFLICT (user_id) DO UPDATE
+    SET unread_count = EXCLUDED.unread_count, latest_notification_at = EXCLUDED.latest_notification_at, updated_at = NOW()
+`
+
 // NotificationRepository is the implementation of the notification repository
 type NotificationRepository struct {
     db *db.DB
@@ -18,19 +34,47 @@ type NotificationRepository struct {
 // NewNotificationRepository creates a new notification repository
 func NewNotificationRepository(db *db.DB) *NotificationRepository {
     return &NotificationRepository{
-        db: db,
+        db: db.Repository("notification"),
     }
 }
 
-// Create creates a new notification
+// Create creates a new notification and rings the bell of its user
 func (r *NotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
-    _, err := r.db.ExecContext(ctx, `
-        INSERT INTO notifications (user_id, plant_id, type, message, is_read)
-        VALUES ($1, $2, $3, $4, $5)
-    `, notification.UserID, notification.PlantID, notification.Type, notification.Message, notification.IsRead)
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    err = tx.QueryRowxContext(ctx, `
+        INSERT INTO notifications (user_id, plant_id, type, message, payload, is_read)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at, updated_at
+    `, notification.UserID, notification.PlantID, notification.Type, notification.Message, notification.Payload, notification.IsRead).
+        Scan(&notification.ID, &notification.CreatedAt, &notification.UpdatedAt)
     if err != nil {
         return fmt.Errorf("failed to create notification: %w", err)
     }
+
+    unread := 1
+    if notification.IsRead {
+        unread = 0
+    }
+    _, err = tx.ExecContext(ctx, `
+        INSERT INTO notification_bells (user_id, unread_count, latest_notification_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id) DO UPDATE
+        SET unread_count = notification_bells.unread_count + EXCLUDED.unread_count,
+            latest_notification_at = GREATEST(notification_bells.latest_notification_at, EXCLUDED.latest_notification_at),
+            updated_at = NOW()
+    `, notification.UserID, unread, notification.CreatedAt)
+    if err != nil {
+        return fmt.Errorf("failed to update notification bell: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit transaction: %w", err)
+    }
     return nil
 }
 
@@ -49,7 +93,7 @@ func (r *NotificationRepository) GetUserNotifications(ctx context.Context, userI
 
     // Get notifications with plants
     rows, err := r.db.QueryxContext(ctx, `
-        SELECT n.id, n.user_id, n.plant_id, n.type, n.message, n.is_read, n.created_at, n.updated_at,
+        SELECT n.id, n.user_id, n.plant_id, n.type, n.message, n.payload, n.is_read, n.created_at, n.updated_at,
                p.id as "plant.id", p.name as "plant.name", 
                p.scientific_name as "plant.scientific_name",
                p.image_url as "plant.image_url"
@@ -70,7 +114,7 @@ func (r *NotificationRepository) GetUserNotifications(ctx context.Context, userI
         var plantID, plantName, scientificName, imageURL sql.NullString
         err := rows.Scan(
             &notification.ID, &notification.UserID, &notification.PlantID,
-            &notification.Type, &notification.Message, &notification.IsRead,
+            &notification.Type, &notification.Message, &notification.Payload, &notification.IsRead,
             &notification.CreatedAt, &notification.UpdatedAt,
             &plantID, &plantName, &scientificName, &imageURL,
         )
@@ -84,7 +128,7 @@ func (r *NotificationRepository) GetUserNotifications(ctx context.Context, userI
                 ID:            uuid.MustParse(plantID.String),
                 Name:          plantName.String,
                 ScientificName: scientificName.String,
-                ImageURL:      imageURL.String,
+                ImageURL:      models.AssetKey(imageURL.String),
             }
         }
 
@@ -100,31 +144,161 @@ func (r *NotificationRepository) GetUserNotifications(ctx context.Context, userI
 
 // MarkAsRead marks a notification as read
 func (r *NotificationRepository) MarkAsRead(ctx context.Context, notificationID uuid.UUID, userID uuid.UUID) error {
-    result, err := r.db.ExecContext(ctx, `
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    // Lock the notification so a concurrent read does not take it off the bell twice
+    var isRead bool
+    err = tx.GetContext(ctx, &isRead, `
+        SELECT is_read FROM notifications WHERE id = $1 AND user_id = $2 FOR UPDATE
+    `, notificationID, userID)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return fmt.Errorf("notification not found or not owned by user: %w", err)
+        }
+        return fmt.Errorf("failed to mark notification as read: %w", err)
+    }
+    if isRead {
+        return nil
+    }
+
+    _, err = tx.ExecContext(ctx, `
         UPDATE notifications
         SET is_read = true, updated_at = NOW()
-        WHERE id = $1 AND user_id = $2
-    `, notificationID, userID)
+        WHERE id = $1
+    `, notificationID)
     if err != nil {
         return fmt.Errorf("failed to mark notification as read: %w", err)
     }
+    if err := readNotificationBell(ctx, tx, userID, 1); err != nil {
+        return err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return nil
+}
+
+// CountUnread counts the unread notifications of a user, as their bell shows them
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+    bell, err := r.GetBell(ctx, userID)
+    if err != nil {
+        return 0, err
+    }
+    return bell.UnreadCount, nil
+}
+
+// GetBell gets the unread state of the notifications of a user; a user without notifications has
+// an empty bell
+func (r *NotificationRepository) GetBell(ctx context.Context, userID uuid.UUID) (*models.NotificationBell, error) {
+    var bell models.NotificationBell
+    err := r.db.GetContext(ctx, &bell, `
+        SELECT unread_count, latest_notification_at FROM notification_bells WHERE user_id = $1
+    `, userID)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return &models.NotificationBell{}, nil
+        }
+        return nil, fmt.Errorf("failed to get notification bell: %w", err)
+    }
+    return &bell, nil
+}
+
+// MarkAllAsRead marks all notifications of a user as read and returns the number marked
+func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) (int, error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    result, err := tx.ExecContext(ctx, `
+        UPDATE notifications
+        SET is_read = true, updated_at = NOW()
+        WHERE user_id = $1 AND is_read = false
+    `, userID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to mark notifications as read: %w", err)
+    }
 
     rows, err := result.RowsAffected()
     if err != nil {
-        return fmt.Errorf("failed to get rows affected: %w", err)
+        return 0, fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    if rows > 0 {
+        if err := readNotificationBell(ctx, tx, userID, int(rows)); err != nil {
+            return 0, err
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return int(rows), nil
+}
+
+// Delete deletes a notification of a user
+func (r *NotificationRepository) Delete(ctx context.Context, notificationID uuid.UUID, userID uuid.UUID) error {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
     }
+    defer tx.Rollback()
 
-    if rows == 0 {
-        return fmt.Errorf("notification not found or not owned by user")
+    var isRead bool
+    err = tx.GetContext(ctx, &isRead, `
+        DELETE FROM notifications WHERE id = $1 AND user_id = $2 RETURNING is_read
+    `, notificationID, userID)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return fmt.Errorf("notification not found: %w", err)
+        }
+        return fmt.Errorf("failed to delete notification: %w", err)
+    }
+
+    // The deleted notification may have been the latest one
+    unread := 1
+    if isRead {
+        unread = 0
+    }
+    _, err = tx.ExecContext(ctx, `
+        UPDATE notification_bells
+        SET unread_count = GREATEST(unread_count - $2, 0),
+            latest_notification_at = (SELECT MAX(created_at) FROM notifications WHERE user_id = $1),
+            updated_at = NOW()
+        WHERE user_id = $1
+    `, userID, unread)
+    if err != nil {
+        return fmt.Errorf("failed to update notification bell: %w", err)
     }
 
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return nil
+}
+
+// readNotificationBell takes notifications of a user marked as read off their bell
+func readNotificationBell(ctx context.Context, tx *sqlx.Tx, userID uuid.UUID, read int) error {
+    _, err := tx.ExecContext(ctx, `
+        UPDATE notification_bells
+        SET unread_count = GREATEST(unread_count - $2, 0), updated_at = NOW()
+        WHERE user_id = $1
+    `, userID, read)
+    if err != nil {
+        return fmt.Errorf("failed to update notification bell: %w", err)
+    }
     return nil
 }
 
 // GetUnreadWateringNotifications gets all unread watering notifications that need to be sent
 func (r *NotificationRepository) GetUnreadWateringNotifications(ctx context.Context) ([]*models.Notification, error) {
     rows, err := r.db.QueryxContext(ctx, `
-        SELECT n.id, n.user_id, n.plant_id, n.type, n.message, n.is_read, n.created_at, n.updated_at,
+        SELECT n.id, n.user_id, n.plant_id, n.type, n.message, n.payload, n.is_read, n.created_at, n.updated_at,
                p.id as "plant.id", p.name as "plant.name", 
                p.scientific_name as "plant.scientific_name",
                p.image_url as "plant.image_url"
@@ -144,7 +318,7 @@ func (r *NotificationRepository) GetUnreadWateringNotifications(ctx context.Cont
         var plantID, plantName, scientificName, imageURL sql.NullString
         err := rows.Scan(
             &notification.ID, &notification.UserID, &notification.PlantID,
-            &notification.Type, &notification.Message, &notification.IsRead,
+            &notification.Type, &notification.Message, &notification.Payload, &notification.IsRead,
             &notification.CreatedAt, &notification.UpdatedAt,
             &plantID, &plantName, &scientificName, &imageURL,
         )
@@ -158,7 +332,7 @@ func (r *NotificationRepository) GetUnreadWateringNotifications(ctx context.Cont
                 ID:            uuid.MustParse(plantID.String),
                 Name:          plantName.String,
                 ScientificName: scientificName.String,
-                ImageURL:      imageURL.String,
+                ImageURL:      models.AssetKey(imageURL.String),
             }
         }
 
@@ -170,4 +344,107 @@ func (r *NotificationRepository) GetUnreadWateringNotifications(ctx context.Cont
     }
 
     return notifications, nil
-} 
\ No newline at end of file
+} 
+
+// GetWateringDigests gets the users who get watering reminders by email and were not emailed on the
+// given day, with their plants due for watering before dueBefore. Plants in a home other than the
+// one the user is staying at are left out.
+func (r *NotificationRepository) GetWateringDigests(ctx context.Context, day time.Time, dueBefore time.Time) ([]*models.WateringDigest, error) {
+    nextWatering := r.db.Read("up", "user_plants", "next_watering")
+    var rows []struct {
+        UserID       uuid.UUID       `db:"user_id"`
+        Name         string          `db:"name"`
+        Email        string          `db:"email"`
+        Language     models.Language `db:"language"`
+        PlantID      uuid.UUID       `db:"plant_id"`
+        PlantName    string          `db:"plant_name"`
+        Location     *string         `db:"location"`
+        NextWatering time.Time       `db:"next_watering"`
+    }
+    err := r.db.SelectContext(ctx, &rows, `
+        SELECT u.id AS user_id, u.name, u.email, u.language, up.plant_id, p.name AS plant_name, up.location,
+               `+nextWatering+` AS next_watering
+        FROM user_plants up
+        JOIN plants p ON up.plant_id = p.id
+        JOIN users u ON up.user_id = u.id
+        WHERE u.watering_reminder_channel = $1
+          AND u.notifications_enabled
+          AND u.anonymized_at IS NULL AND u.deleted_at IS NULL
+          AND (u.watering_email_sent_on IS NULL OR u.watering_email_sent_on < $2::date)
+          AND `+nextWatering+` < $3
+          AND (u.current_home_id IS NULL OR up.home_id IS NULL OR up.home_id = u.current_home_id)
+        ORDER BY u.id, `+nextWatering+`, p.name
+    `, models.ReminderChannelEmail, day.Format(time.DateOnly), dueBefore)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get watering digests: %w", err)
+    }
+
+    // Group the plants by user, keeping the order of the rows
+    digests := []*models.WateringDigest{}
+    for _, row := range rows {
+        if len(digests) == 0 || digests[len(digests)-1].UserID != row.UserID {
+            digests = append(digests, &models.WateringDigest{
+                UserID:   row.UserID,
+                Name:     row.Name,
+                Email:    row.Email,
+                Language: row.Language,
+            })
+        }
+        digest := digests[len(digests)-1]
+        digest.Plants = append(digest.Plants, &models.WateringDigestPlant{
+            PlantID:      row.PlantID,
+            Name:         row.PlantName,
+            Location:     row.Location,
+            NextWatering: row.NextWatering,
+        })
+    }
+    return digests, nil
+}
+
+// ClaimWateringDigest records that the watering reminder email of a user is sent on the given day;
+// it reports false when the email was already sent that day, e.g. by another instance
+func (r *NotificationRepository) ClaimWateringDigest(ctx context.Context, userID uuid.UUID, day time.Time) (bool, error) {
+    result, err := r.db.ExecContext(ctx, `
+        UPDATE users
+        SET watering_email_sent_on = $2::date
+        WHERE id = $1 AND (watering_email_sent_on IS NULL OR watering_email_sent_on < $2::date)
+    `, userID, day.Format(time.DateOnly))
+    if err != nil {
+        return false, fmt.Errorf("failed to claim watering digest: %w", err)
+    }
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return false, fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    return rows > 0, nil
+}
+
+// ReleaseWateringDigest undoes the claim of a watering reminder email that could not be sent
+func (r *NotificationRepository) ReleaseWateringDigest(ctx context.Context, userID uuid.UUID, day time.Time) error {
+    _, err := r.db.ExecContext(ctx, `
+        UPDATE users SET watering_email_sent_on = NULL WHERE id = $1 AND watering_email_sent_on = $2::date
+    `, userID, day.Format(time.DateOnly))
+    if err != nil {
+        return fmt.Errorf("failed to release watering digest: %w", err)
+    }
+    return nil
+}
+
+// ClaimWateringReminders records that the in-app watering reminders of a user are sent for the given
+// local day of the user; it reports false when they were already sent for that day or a later one,
+// e.g. by another instance
+func (r *NotificationRepository) ClaimWateringReminders(ctx context.Context, userID uuid.UUID, day time.Time) (bool, error) {
+    result, err := r.db.ExecContext(ctx, `
+        UPDATE users
+        SET watering_reminded_on = $2::date
+        WHERE id = $1 AND (watering_reminded_on IS NULL OR watering_reminded_on < $2::date)
+    `, userID, day.Format(time.DateOnly))
+    if err != nil {
+        return false, fmt.Errorf("failed to claim watering reminders: %w", err)
+    }
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return false, fmt.Errorf("failed to get rows affected: %w", err)
+    }
+    return rows > 0, nil
+}
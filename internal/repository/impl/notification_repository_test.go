@@ -2,6 +2,7 @@ package impl
 
 import (
     "context"
+    "database/sql"
     "testing"
     "time"
 
@@ -32,20 +33,30 @@ func TestNotificationRepository_Create(t *testing.T) {
     repo, mock, cleanup := setupNotificationTest(t)
     defer cleanup()
 
+    plantID := uuid.New()
     notification := &models.Notification{
         UserID:  uuid.New(),
-        PlantID: uuid.New(),
+        PlantID: &plantID,
         Type:    models.NotificationTypeWatering,
         Message: "Test notification",
+        Payload: models.NotificationPayload{"plantId": plantID.String()},
         IsRead:  false,
     }
 
-    mock.ExpectExec("INSERT INTO notifications").
-        WithArgs(notification.UserID, notification.PlantID, notification.Type, notification.Message, notification.IsRead).
-        WillReturnResult(sqlmock.NewResult(1, 1))
+    notificationID := uuid.New()
+    createdAt := time.Now()
+    mock.ExpectBegin()
+    mock.ExpectQuery("INSERT INTO notifications").
+        WithArgs(notification.UserID, notification.PlantID, notification.Type, notification.Message, notification.Payload, notification.IsRead).
+        WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(notificationID, createdAt, createdAt))
+    mock.ExpectExec("INSERT INTO notification_bells").
+        WithArgs(notification.UserID, 1, createdAt).
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectCommit()
 
     err := repo.Create(context.Background(), notification)
     assert.NoError(t, err)
+    assert.Equal(t, notificationID, notification.ID)
     assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -55,12 +66,14 @@ func TestNotificationRepository_GetUserNotifications(t *testing.T) {
 
     userID := uuid.New()
     expectedTotal := 1
+    plantID := uuid.New()
     expectedNotification := &models.Notification{
         ID:      uuid.New(),
         UserID:  userID,
-        PlantID: uuid.New(),
+        PlantID: &plantID,
         Type:    models.NotificationTypeWatering,
         Message: "Test notification",
+        Payload: models.NotificationPayload{"plantId": plantID.String()},
         IsRead:  false,
         Plant: &models.Plant{
             ID:   uuid.New(),
@@ -75,11 +88,11 @@ func TestNotificationRepository_GetUserNotifications(t *testing.T) {
 
     // Expect notifications query
     rows := sqlmock.NewRows([]string{
-        "id", "user_id", "plant_id", "type", "message", "is_read", "created_at", "updated_at",
+        "id", "user_id", "plant_id", "type", "message", "payload", "is_read", "created_at", "updated_at",
         "plant.id", "plant.name", "plant.scientific_name", "plant.image_url",
     }).AddRow(
-        expectedNotification.ID, expectedNotification.UserID, expectedNotification.PlantID,
-        expectedNotification.Type, expectedNotification.Message, expectedNotification.IsRead,
+        expectedNotification.ID, expectedNotification.UserID, plantID,
+        expectedNotification.Type, expectedNotification.Message, []byte(`{"plantId":"`+plantID.String()+`"}`), expectedNotification.IsRead,
         time.Now(), time.Now(),
         expectedNotification.Plant.ID, expectedNotification.Plant.Name,
         "Scientific Name", "image.jpg",
@@ -95,6 +108,8 @@ func TestNotificationRepository_GetUserNotifications(t *testing.T) {
     assert.Len(t, notifications, 1)
     assert.Equal(t, expectedNotification.ID, notifications[0].ID)
     assert.Equal(t, expectedNotification.Plant.ID, notifications[0].Plant.ID)
+    assert.Equal(t, plantID, *notifications[0].PlantID)
+    assert.Equal(t, expectedNotification.Payload, notifications[0].Payload)
     assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -104,12 +119,14 @@ func TestNotificationRepository_GetUserNotifications_NullPlant(t *testing.T) {
 
     userID := uuid.New()
     expectedTotal := 1
+    plantID := uuid.New()
     expectedNotification := &models.Notification{
         ID:      uuid.New(),
         UserID:  userID,
-        PlantID: uuid.New(),
+        PlantID: &plantID,
         Type:    models.NotificationTypeWatering,
         Message: "Test notification",
+        Payload: models.NotificationPayload{"plantId": plantID.String()},
         IsRead:  false,
     }
 
@@ -120,11 +137,11 @@ func TestNotificationRepository_GetUserNotifications_NullPlant(t *testing.T) {
 
     // Expect notifications query with NULL plant fields
     rows := sqlmock.NewRows([]string{
-        "id", "user_id", "plant_id", "type", "message", "is_read", "created_at", "updated_at",
+        "id", "user_id", "plant_id", "type", "message", "payload", "is_read", "created_at", "updated_at",
         "plant.id", "plant.name", "plant.scientific_name", "plant.image_url",
     }).AddRow(
-        expectedNotification.ID, expectedNotification.UserID, expectedNotification.PlantID,
-        expectedNotification.Type, expectedNotification.Message, expectedNotification.IsRead,
+        expectedNotification.ID, expectedNotification.UserID, plantID,
+        expectedNotification.Type, expectedNotification.Message, []byte(`{"plantId":"`+plantID.String()+`"}`), expectedNotification.IsRead,
         time.Now(), time.Now(),
         nil, nil, nil, nil,
     )
@@ -150,25 +167,137 @@ func TestNotificationRepository_MarkAsRead(t *testing.T) {
     notificationID := uuid.New()
     userID := uuid.New()
 
-    mock.ExpectExec("UPDATE notifications").
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT is_read FROM notifications WHERE id = \\$1 AND user_id = \\$2 FOR UPDATE").
         WithArgs(notificationID, userID).
+        WillReturnRows(sqlmock.NewRows([]string{"is_read"}).AddRow(false))
+    mock.ExpectExec("UPDATE notifications").
+        WithArgs(notificationID).
         WillReturnResult(sqlmock.NewResult(1, 1))
+    mock.ExpectExec("UPDATE notification_bells SET unread_count = GREATEST\\(unread_count - \\$2, 0\\)").
+        WithArgs(userID, 1).
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectCommit()
 
     err := repo.MarkAsRead(context.Background(), notificationID, userID)
     assert.NoError(t, err)
     assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestNotificationRepository_MarkAsRead_AlreadyRead(t *testing.T) {
+    repo, mock, cleanup := setupNotificationTest(t)
+    defer cleanup()
+
+    notificationID := uuid.New()
+    userID := uuid.New()
+
+    // A notification read before is not taken off the bell again
+    mock.ExpectBegin()
+    mock.ExpectQuery("SELECT is_read FROM notifications").
+        WithArgs(notificationID, userID).
+        WillReturnRows(sqlmock.NewRows([]string{"is_read"}).AddRow(true))
+    mock.ExpectRollback()
+
+    err := repo.MarkAsRead(context.Background(), notificationID, userID)
+    assert.NoError(t, err)
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_CountUnread(t *testing.T) {
+    repo, mock, cleanup := setupNotificationTest(t)
+    defer cleanup()
+
+    userID := uuid.New()
+
+    mock.ExpectQuery("SELECT unread_count, latest_notification_at FROM notification_bells WHERE user_id = \\$1").
+        WithArgs(userID).
+        WillReturnRows(sqlmock.NewRows([]string{"unread_count", "latest_notification_at"}).AddRow(4, time.Now()))
+
+    count, err := repo.CountUnread(context.Background(), userID)
+    assert.NoError(t, err)
+    assert.Equal(t, 4, count)
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_GetBell_NoNotifications(t *testing.T) {
+    repo, mock, cleanup := setupNotificationTest(t)
+    defer cleanup()
+
+    userID := uuid.New()
+
+    mock.ExpectQuery("SELECT unread_count, latest_notification_at FROM notification_bells").
+        WithArgs(userID).
+        WillReturnRows(sqlmock.NewRows([]string{"unread_count", "latest_notification_at"}))
+
+    bell, err := repo.GetBell(context.Background(), userID)
+    assert.NoError(t, err)
+    assert.Equal(t, 0, bell.UnreadCount)
+    assert.Nil(t, bell.LatestNotificationAt)
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_MarkAllAsRead(t *testing.T) {
+    repo, mock, cleanup := setupNotificationTest(t)
+    defer cleanup()
+
+    userID := uuid.New()
+
+    mock.ExpectBegin()
+    mock.ExpectExec("UPDATE notifications").
+        WithArgs(userID).
+        WillReturnResult(sqlmock.NewResult(0, 2))
+    mock.ExpectExec("UPDATE notification_bells").
+        WithArgs(userID, 2).
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectCommit()
+
+    count, err := repo.MarkAllAsRead(context.Background(), userID)
+    assert.NoError(t, err)
+    assert.Equal(t, 2, count)
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_Delete(t *testing.T) {
+    repo, mock, cleanup := setupNotificationTest(t)
+    defer cleanup()
+
+    notificationID := uuid.New()
+    userID := uuid.New()
+
+    mock.ExpectBegin()
+    mock.ExpectQuery("DELETE FROM notifications WHERE id = \\$1 AND user_id = \\$2 RETURNING is_read").
+        WithArgs(notificationID, userID).
+        WillReturnRows(sqlmock.NewRows([]string{"is_read"}).AddRow(false))
+    mock.ExpectExec("UPDATE notification_bells SET unread_count = GREATEST\\(unread_count - \\$2, 0\\), latest_notification_at = \\(SELECT MAX").
+        WithArgs(userID, 1).
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectCommit()
+    mock.ExpectBegin()
+    mock.ExpectQuery("DELETE FROM notifications").
+        WithArgs(notificationID, userID).
+        WillReturnRows(sqlmock.NewRows([]string{"is_read"}))
+    mock.ExpectRollback()
+
+    assert.NoError(t, repo.Delete(context.Background(), notificationID, userID))
+
+    // Deleting it again, or deleting another user's notification, finds nothing
+    err := repo.Delete(context.Background(), notificationID, userID)
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestNotificationRepository_GetUnreadWateringNotifications(t *testing.T) {
     repo, mock, cleanup := setupNotificationTest(t)
     defer cleanup()
 
+    plantID := uuid.New()
     expectedNotification := &models.Notification{
         ID:      uuid.New(),
         UserID:  uuid.New(),
-        PlantID: uuid.New(),
+        PlantID: &plantID,
         Type:    models.NotificationTypeWatering,
         Message: "Test notification",
+        Payload: models.NotificationPayload{"plantId": plantID.String()},
         IsRead:  false,
         Plant: &models.Plant{
             ID:   uuid.New(),
@@ -177,11 +306,11 @@ func TestNotificationRepository_GetUnreadWateringNotifications(t *testing.T) {
     }
 
     rows := sqlmock.NewRows([]string{
-        "id", "user_id", "plant_id", "type", "message", "is_read", "created_at", "updated_at",
+        "id", "user_id", "plant_id", "type", "message", "payload", "is_read", "created_at", "updated_at",
         "plant.id", "plant.name", "plant.scientific_name", "plant.image_url",
     }).AddRow(
-        expectedNotification.ID, expectedNotification.UserID, expectedNotification.PlantID,
-        expectedNotification.Type, expectedNotification.Message, expectedNotification.IsRead,
+        expectedNotification.ID, expectedNotification.UserID, plantID,
+        expectedNotification.Type, expectedNotification.Message, []byte(`{"plantId":"`+plantID.String()+`"}`), expectedNotification.IsRead,
         time.Now(), time.Now(),
         expectedNotification.Plant.ID, expectedNotification.Plant.Name,
         "Scientific Name", "image.jpg",
@@ -203,21 +332,23 @@ func TestNotificationRepository_GetUnreadWateringNotifications_NullPlant(t *test
     repo, mock, cleanup := setupNotificationTest(t)
     defer cleanup()
 
+    plantID := uuid.New()
     expectedNotification := &models.Notification{
         ID:      uuid.New(),
         UserID:  uuid.New(),
-        PlantID: uuid.New(),
+        PlantID: &plantID,
         Type:    models.NotificationTypeWatering,
         Message: "Test notification",
+        Payload: models.NotificationPayload{"plantId": plantID.String()},
         IsRead:  false,
     }
 
     rows := sqlmock.NewRows([]string{
-        "id", "user_id", "plant_id", "type", "message", "is_read", "created_at", "updated_at",
+        "id", "user_id", "plant_id", "type", "message", "payload", "is_read", "created_at", "updated_at",
         "plant.id", "plant.name", "plant.scientific_name", "plant.image_url",
     }).AddRow(
-        expectedNotification.ID, expectedNotification.UserID, expectedNotification.PlantID,
-        expectedNotification.Type, expectedNotification.Message, expectedNotification.IsRead,
+        expectedNotification.ID, expectedNotification.UserID, plantID,
+        expectedNotification.Type, expectedNotification.Message, []byte(`{"plantId":"`+plantID.String()+`"}`), expectedNotification.IsRead,
         time.Now(), time.Now(),
         nil, nil, nil, nil,
     )
@@ -232,4 +363,91 @@ func TestNotificationRepository_GetUnreadWateringNotifications_NullPlant(t *test
     assert.Equal(t, expectedNotification.ID, notifications[0].ID)
     assert.Nil(t, notifications[0].Plant)
     assert.NoError(t, mock.ExpectationsWereMet())
-} 
\ No newline at end of file
+} 
+func TestNotificationRepository_GetWateringDigests(t *testing.T) {
+    repo, mock, cleanup := setupNotificationTest(t)
+    defer cleanup()
+
+    day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+    dueBefore := day.AddDate(0, 0, 1)
+    firstUser, secondUser := uuid.New(), uuid.New()
+    kitchen := "Kitchen"
+
+    monstera := uuid.New()
+    rows := sqlmock.NewRows([]string{"user_id", "name", "email", "language", "plant_id", "plant_name", "location", "next_watering"}).
+        AddRow(firstUser, "Anna", "anna@example.com", models.LanguageEnglish, monstera, "Monstera", kitchen, day.AddDate(0, 0, -2)).
+        AddRow(firstUser, "Anna", "anna@example.com", models.LanguageEnglish, uuid.New(), "Ficus", nil, day).
+        AddRow(secondUser, "Boris", "boris@example.com", models.LanguageRussian, uuid.New(), "Aloe", nil, day)
+    mock.ExpectQuery("SELECT u.id AS user_id, (.+) FROM user_plants up").
+        WithArgs(models.ReminderChannelEmail, "2024-05-10", dueBefore).
+        WillReturnRows(rows)
+
+    digests, err := repo.GetWateringDigests(context.Background(), day, dueBefore)
+    assert.NoError(t, err)
+    if assert.Len(t, digests, 2) {
+        assert.Equal(t, firstUser, digests[0].UserID)
+        assert.Equal(t, models.LanguageEnglish, digests[0].Language)
+        if assert.Len(t, digests[0].Plants, 2) {
+            assert.Equal(t, monstera, digests[0].Plants[0].PlantID)
+            assert.Equal(t, "Monstera", digests[0].Plants[0].Name)
+            assert.Equal(t, &kitchen, digests[0].Plants[0].Location)
+            assert.Nil(t, digests[0].Plants[1].Location)
+        }
+        assert.Equal(t, secondUser, digests[1].UserID)
+        assert.Len(t, digests[1].Plants, 1)
+    }
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_ClaimWateringDigest(t *testing.T) {
+    repo, mock, cleanup := setupNotificationTest(t)
+    defer cleanup()
+
+    userID := uuid.New()
+    day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+    mock.ExpectExec("UPDATE users SET watering_email_sent_on = \\$2::date WHERE id = \\$1").
+        WithArgs(userID, "2024-05-10").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("UPDATE users SET watering_email_sent_on = \\$2::date WHERE id = \\$1").
+        WithArgs(userID, "2024-05-10").
+        WillReturnResult(sqlmock.NewResult(0, 0))
+
+    claimed, err := repo.ClaimWateringDigest(context.Background(), userID, day)
+    assert.NoError(t, err)
+    assert.True(t, claimed)
+
+    // The second claim of the day finds the email already sent
+    claimed, err = repo.ClaimWateringDigest(context.Background(), userID, day)
+    assert.NoError(t, err)
+    assert.False(t, claimed)
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_ClaimWateringReminders(t *testing.T) {
+    repo, mock, cleanup := setupNotificationTest(t)
+    defer cleanup()
+
+    // The local day of a user in Vladivostok, which has begun before the UTC one
+    userID := uuid.New()
+    vladivostok, err := time.LoadLocation("Asia/Vladivostok")
+    assert.NoError(t, err)
+    day := time.Date(2024, 5, 11, 0, 0, 0, 0, vladivostok)
+
+    mock.ExpectExec("UPDATE users SET watering_reminded_on = \\$2::date WHERE id = \\$1").
+        WithArgs(userID, "2024-05-11").
+        WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("UPDATE users SET watering_reminded_on = \\$2::date WHERE id = \\$1").
+        WithArgs(userID, "2024-05-11").
+        WillReturnResult(sqlmock.NewResult(0, 0))
+
+    claimed, err := repo.ClaimWateringReminders(context.Background(), userID, day)
+    assert.NoError(t, err)
+    assert.True(t, claimed)
+
+    // The second claim of the day finds the reminders already sent
+    claimed, err = repo.ClaimWateringReminders(context.Background(), userID, day)
+    assert.NoError(t, err)
+    assert.False(t, claimed)
+    assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,70 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// NotificationTemplateRepository is the implementation of the notification template repository
+type NotificationTemplateRepository struct {
+	db *db.DB
+}
+
+// NewNotificationTemplateRepository creates a new notification template repository
+func NewNotificationTemplateRepository(db *db.DB) *NotificationTemplateRepository {
+	return &NotificationTemplateRepository{
+		db: db.Repository("notification_template"),
+	}
+}
+
+// Get gets the stored template of a notification type in a language, or nil if none is stored
+func (r *NotificationTemplateRepository) Get(ctx context.Context, notificationType models.NotificationType, language models.Language) (*models.NotificationTemplate, error) {
+	var template models.NotificationTemplate
+	err := r.db.GetContext(ctx, &template, `
+		SELECT type, language, body, updated_at
+		FROM notification_templates
+		WHERE type = $1 AND language = $2
+	`, notificationType, language)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get notification template: %w", err)
+	}
+	return &template, nil
+}
+
+// GetAll gets all stored templates
+func (r *NotificationTemplateRepository) GetAll(ctx context.Context) ([]*models.NotificationTemplate, error) {
+	templates := []*models.NotificationTemplate{}
+	err := r.db.SelectContext(ctx, &templates, `
+		SELECT type, language, body, updated_at
+		FROM notification_templates
+		ORDER BY type, language
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification templates: %w", err)
+	}
+	return templates, nil
+}
+
+// Upsert creates or replaces the stored template of a notification type in a language
+func (r *NotificationTemplateRepository) Upsert(ctx context.Context, template *models.NotificationTemplate) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO notification_templates (type, language, body)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (type, language)
+		DO UPDATE SET body = EXCLUDED.body, updated_at = NOW()
+		RETURNING updated_at
+	`, template.Type, template.Language, template.Body).
+		Scan(&template.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save notification template: %w", err)
+	}
+	return nil
+}
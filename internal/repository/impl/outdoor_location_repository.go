@@ -0,0 +1,73 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// OutdoorLocationRepository is the implementation of the outdoor location repository
+type OutdoorLocationRepository struct {
+	db *db.DB
+}
+
+// NewOutdoorLocationRepository creates a new outdoor location repository
+func NewOutdoorLocationRepository(db *db.DB) *OutdoorLocationRepository {
+	return &OutdoorLocationRepository{
+		db: db.Repository("outdoor_location"),
+	}
+}
+
+// List gets the outdoor locations of a user
+func (r *OutdoorLocationRepository) List(ctx context.Context, userID uuid.UUID) ([]*models.OutdoorLocation, error) {
+	locations := []*models.OutdoorLocation{}
+	err := r.db.SelectContext(ctx, &locations, `
+		SELECT user_id, location, latitude, longitude, created_at, updated_at
+		FROM user_outdoor_locations
+		WHERE user_id = $1
+		ORDER BY location ASC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outdoor locations: %w", err)
+	}
+	return locations, nil
+}
+
+// Upsert marks a location of a user as outdoors or moves it to other coordinates
+func (r *OutdoorLocationRepository) Upsert(ctx context.Context, location *models.OutdoorLocation) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO user_outdoor_locations (user_id, location, latitude, longitude)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, location) DO UPDATE
+		SET latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`, location.UserID, location.Location, location.Latitude, location.Longitude).
+		Scan(&location.CreatedAt, &location.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save outdoor location: %w", err)
+	}
+	return nil
+}
+
+// Delete stops treating a location of a user as outdoors
+func (r *OutdoorLocationRepository) Delete(ctx context.Context, userID uuid.UUID, location string) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM user_outdoor_locations
+		WHERE user_id = $1 AND location = $2
+	`, userID, location)
+	if err != nil {
+		return fmt.Errorf("failed to delete outdoor location: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
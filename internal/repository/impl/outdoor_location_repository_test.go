@@ -0,0 +1,55 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutdoorLocationRepository_Upsert(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewOutdoorLocationRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID := uuid.New()
+	createdAt := time.Date(2024, time.May, 1, 9, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, time.June, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("INSERT INTO user_outdoor_locations (.+) ON CONFLICT").
+		WithArgs(userID, "Balcony", 59.9357, 30.326).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(createdAt, updatedAt))
+
+	location := &models.OutdoorLocation{UserID: userID, Location: "Balcony", Latitude: 59.9357, Longitude: 30.326}
+	assert.NoError(t, repo.Upsert(context.Background(), location))
+	assert.Equal(t, createdAt, location.CreatedAt)
+	assert.Equal(t, updatedAt, location.UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOutdoorLocationRepository_Delete_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewOutdoorLocationRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID := uuid.New()
+	mock.ExpectExec("DELETE FROM user_outdoor_locations").
+		WithArgs(userID, "Garden").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.Delete(context.Background(), userID, "Garden")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
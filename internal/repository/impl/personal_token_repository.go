@@ -0,0 +1,106 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PersonalTokenRepository is the implementation of the personal access token repository
+type PersonalTokenRepository struct {
+	db *db.DB
+}
+
+// NewPersonalTokenRepository creates a new personal access token repository
+func NewPersonalTokenRepository(db *db.DB) *PersonalTokenRepository {
+	return &PersonalTokenRepository{
+		db: db.Repository("personal_token"),
+	}
+}
+
+// Create creates a new personal access token
+func (r *PersonalTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO personal_access_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, token.UserID, token.Name, token.TokenHash, token.TokenPrefix, token.Scopes, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create personal access token: %w", err)
+	}
+	return nil
+}
+
+// GetByHash gets an active, unexpired token by the hash of its raw value
+func (r *PersonalTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	err := r.db.GetContext(ctx, &token, `
+		SELECT id, user_id, name, token_hash, token_prefix, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("personal access token not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get personal access token: %w", err)
+	}
+	return &token, nil
+}
+
+// GetByUser gets all personal access tokens of a user
+func (r *PersonalTokenRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.PersonalAccessToken, error) {
+	var tokens []*models.PersonalAccessToken
+	err := r.db.SelectContext(ctx, &tokens, `
+		SELECT id, user_id, name, token_hash, token_prefix, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get personal access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke revokes a personal access token owned by a user
+func (r *PersonalTokenRepository) Revoke(ctx context.Context, tokenID uuid.UUID, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE personal_access_tokens
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke personal access token: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("personal access token not found or not owned by user")
+	}
+
+	return nil
+}
+
+// UpdateLastUsed sets the last used time of a token to now
+func (r *PersonalTokenRepository) UpdateLastUsed(ctx context.Context, tokenID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE personal_access_tokens
+		SET last_used_at = NOW()
+		WHERE id = $1
+	`, tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to update personal access token last used: %w", err)
+	}
+	return nil
+}
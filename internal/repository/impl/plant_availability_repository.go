@@ -0,0 +1,93 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantAvailabilityRepository is the implementation of the plant availability subscription repository
+type PlantAvailabilityRepository struct {
+	db *db.DB
+}
+
+// NewPlantAvailabilityRepository creates a new plant availability subscription repository
+func NewPlantAvailabilityRepository(db *db.DB) *PlantAvailabilityRepository {
+	return &PlantAvailabilityRepository{
+		db: db.Repository("plant_availability"),
+	}
+}
+
+// Upsert subscribes a user to a plant in a city, replacing the city of an existing subscription and
+// making it wait again if it was notified
+func (r *PlantAvailabilityRepository) Upsert(ctx context.Context, subscription *models.PlantAvailabilitySubscription) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO plant_availability_subscriptions (user_id, plant_id, city)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, plant_id) DO UPDATE
+		SET city = EXCLUDED.city, notified_at = NULL, updated_at = NOW()
+		RETURNING id, notified_at, created_at, updated_at
+	`, subscription.UserID, subscription.PlantID, subscription.City).
+		Scan(&subscription.ID, &subscription.NotifiedAt, &subscription.CreatedAt, &subscription.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save plant availability subscription: %w", err)
+	}
+	return nil
+}
+
+// GetByUser gets the subscriptions of a user, newest first
+func (r *PlantAvailabilityRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.PlantAvailabilitySubscription, error) {
+	subscriptions := []*models.PlantAvailabilitySubscription{}
+	err := r.db.SelectContext(ctx, &subscriptions, `
+		SELECT s.id, s.user_id, s.plant_id, p.name AS plant_name, s.city, s.notified_at, s.created_at, s.updated_at
+		FROM plant_availability_subscriptions s
+		JOIN plants p ON p.id = s.plant_id
+		WHERE s.user_id = $1
+		ORDER BY s.created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant availability subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// Delete removes a user's subscription to a plant
+func (r *PlantAvailabilityRepository) Delete(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM plant_availability_subscriptions
+		WHERE user_id = $1 AND plant_id = $2
+	`, userID, plantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete plant availability subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("plant availability subscription not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// ClaimWaiting marks the waiting subscriptions to a plant in a city (compared case-insensitively) as
+// notified and returns their subscribers, so each subscriber is notified once
+func (r *PlantAvailabilityRepository) ClaimWaiting(ctx context.Context, plantID uuid.UUID, city string) ([]*models.PlantAvailabilityRecipient, error) {
+	recipients := []*models.PlantAvailabilityRecipient{}
+	err := r.db.SelectContext(ctx, &recipients, `
+		UPDATE plant_availability_subscriptions s
+		SET notified_at = NOW(), updated_at = NOW()
+		FROM users u
+		WHERE u.id = s.user_id AND s.plant_id = $1 AND LOWER(s.city) = LOWER($2) AND s.notified_at IS NULL
+		RETURNING s.user_id, u.language
+	`, plantID, city)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim plant availability subscriptions: %w", err)
+	}
+	return recipients, nil
+}
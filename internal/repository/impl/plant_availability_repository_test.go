@@ -0,0 +1,54 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlantAvailabilityRepository_ClaimWaiting(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantAvailabilityRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	plantID, userID := uuid.New(), uuid.New()
+	mock.ExpectQuery("UPDATE plant_availability_subscriptions (.+) RETURNING s.user_id, u.language").
+		WithArgs(plantID, "Казань").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "language"}).AddRow(userID, "ENGLISH"))
+
+	recipients, err := repo.ClaimWaiting(context.Background(), plantID, "Казань")
+	assert.NoError(t, err)
+	if assert.Len(t, recipients, 1) {
+		assert.Equal(t, userID, recipients[0].UserID)
+		assert.Equal(t, models.LanguageEnglish, recipients[0].Language)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlantAvailabilityRepository_Delete_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantAvailabilityRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID, plantID := uuid.New(), uuid.New()
+	mock.ExpectExec("DELETE FROM plant_availability_subscriptions").
+		WithArgs(userID, plantID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.Delete(context.Background(), userID, plantID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,170 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// PlantEnrichmentRepository is the implementation of the plant enrichment repository
+type PlantEnrichmentRepository struct {
+	db *db.DB
+}
+
+// NewPlantEnrichmentRepository creates a new plant enrichment repository
+func NewPlantEnrichmentRepository(db *db.DB) *PlantEnrichmentRepository {
+	return &PlantEnrichmentRepository{
+		db: db.Repository("plant_enrichment"),
+	}
+}
+
+// GetCandidates gets catalog plants with a scientific name that miss synonyms, an image or
+// toxicity, the ones checked least recently first
+func (r *PlantEnrichmentRepository) GetCandidates(ctx context.Context, limit int) ([]*models.Plant, error) {
+	plants := []*models.Plant{}
+	err := r.db.SelectContext(ctx, &plants, `
+		SELECT id, name, scientific_name, image_url, pet_friendly, synonyms, image_license, image_attribution
+		FROM plants
+		WHERE deleted_at IS NULL AND scientific_name <> ''
+			AND (cardinality(synonyms) = 0 OR image_url = '' OR pet_friendly IS NULL)
+		ORDER BY enrichment_checked_at NULLS FIRST, name
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant enrichment candidates: %w", err)
+	}
+	return plants, nil
+}
+
+// MarkChecked records that the sources were asked for the missing fields of a plant
+func (r *PlantEnrichmentRepository) MarkChecked(ctx context.Context, plantID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE plants SET enrichment_checked_at = NOW() WHERE id = $1
+	`, plantID)
+	if err != nil {
+		return fmt.Errorf("failed to mark plant enrichment checked: %w", err)
+	}
+	return nil
+}
+
+// Propose queues a proposed plant change for review; it reports false when the source already has a
+// pending proposal for the field, or when the same value was rejected before
+func (r *PlantEnrichmentRepository) Propose(ctx context.Context, proposal *models.PlantEnrichmentProposal) (bool, error) {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO plant_enrichment_proposals (plant_id, field, source, value)
+		SELECT $1::uuid, $2::varchar, $3::varchar, $4::jsonb
+		WHERE NOT EXISTS (
+			SELECT 1 FROM plant_enrichment_proposals
+			WHERE plant_id = $1 AND field = $2 AND value = $4::jsonb AND status = 'REJECTED'
+		)
+		ON CONFLICT (plant_id, field, source) WHERE status = 'PENDING' DO NOTHING
+		RETURNING id, status, created_at
+	`, proposal.PlantID, proposal.Field, proposal.Source, proposal.Value).
+		Scan(&proposal.ID, &proposal.Status, &proposal.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create plant enrichment proposal: %w", err)
+	}
+	return true, nil
+}
+
+// GetPending gets all proposals awaiting review, oldest first
+func (r *PlantEnrichmentRepository) GetPending(ctx context.Context) ([]*models.PlantEnrichmentProposal, error) {
+	proposals := []*models.PlantEnrichmentProposal{}
+	err := r.db.SelectContext(ctx, &proposals, `
+		SELECT e.id, e.plant_id, p.name AS plant_name, e.field, e.source, e.value, e.status,
+			   e.reviewed_by, e.reviewed_at, e.created_at
+		FROM plant_enrichment_proposals e
+		JOIN plants p ON p.id = e.plant_id
+		WHERE e.status = 'PENDING'
+		ORDER BY e.created_at ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending plant enrichment proposals: %w", err)
+	}
+	return proposals, nil
+}
+
+// Review sets the status of a pending proposal. An approved proposal is applied to its plant and the
+// other pending proposals for the same field are rejected, in one transaction.
+func (r *PlantEnrichmentRepository) Review(ctx context.Context, id uuid.UUID, status models.PlantEnrichmentStatus, reviewerID uuid.UUID) (*models.PlantEnrichmentProposal, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var proposal models.PlantEnrichmentProposal
+	err = tx.GetContext(ctx, &proposal, `
+		UPDATE plant_enrichment_proposals e
+		SET status = $2, reviewed_by = $3, reviewed_at = NOW()
+		FROM plants p
+		WHERE e.id = $1 AND e.status = 'PENDING' AND p.id = e.plant_id
+		RETURNING e.id, e.plant_id, p.name AS plant_name, e.field, e.source, e.value, e.status,
+			e.reviewed_by, e.reviewed_at, e.created_at
+	`, id, status, reviewerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("pending plant enrichment proposal not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to review plant enrichment proposal: %w", err)
+	}
+
+	if status == models.PlantEnrichmentStatusApproved {
+		if err := applyPlantEnrichment(ctx, tx, &proposal); err != nil {
+			return nil, err
+		}
+
+		// The other sources' proposals for the field are superseded by the approved one
+		_, err = tx.ExecContext(ctx, `
+			UPDATE plant_enrichment_proposals
+			SET status = 'REJECTED', reviewed_by = $3, reviewed_at = NOW()
+			WHERE plant_id = $1 AND field = $2 AND status = 'PENDING'
+		`, proposal.PlantID, proposal.Field, reviewerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reject superseded plant enrichment proposals: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &proposal, nil
+}
+
+// applyPlantEnrichment writes the value of an approved proposal to its plant
+func applyPlantEnrichment(ctx context.Context, tx *sqlx.Tx, proposal *models.PlantEnrichmentProposal) error {
+	var err error
+	value := proposal.Value
+	switch {
+	case proposal.Field == models.PlantEnrichmentFieldSynonyms && len(value.Synonyms) > 0:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE plants SET synonyms = $2, updated_at = NOW() WHERE id = $1
+		`, proposal.PlantID, pq.StringArray(value.Synonyms))
+	case proposal.Field == models.PlantEnrichmentFieldImage && value.Image != nil:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE plants
+			SET image_url = $2, image_license = $3, image_attribution = NULLIF($4, ''), updated_at = NOW()
+			WHERE id = $1
+		`, proposal.PlantID, value.Image.URL, value.Image.License, value.Image.Attribution)
+	case proposal.Field == models.PlantEnrichmentFieldToxicity && value.PetFriendly != nil:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE plants SET pet_friendly = $2, updated_at = NOW() WHERE id = $1
+		`, proposal.PlantID, *value.PetFriendly)
+	default:
+		return fmt.Errorf("plant enrichment proposal %s has no %s value", proposal.ID, proposal.Field)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply plant enrichment proposal: %w", err)
+	}
+	return nil
+}
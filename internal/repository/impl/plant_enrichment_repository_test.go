@@ -0,0 +1,61 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlantEnrichmentRepository_Review(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantEnrichmentRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	id, plantID, reviewerID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "plant_id", "plant_name", "field", "source", "value", "status", "reviewed_by", "reviewed_at", "created_at"}
+	value := `{"image":{"url":"https://example.org/monstera.jpg","license":"CC BY 4.0","attribution":"Anna"}}`
+
+	// An approved image is applied and supersedes the other sources' images
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE plant_enrichment_proposals e SET status = \\$2").
+		WithArgs(id, models.PlantEnrichmentStatusApproved, reviewerID).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(id, plantID, "Monstera", "IMAGE", "gbif", value, "APPROVED", reviewerID, now, now))
+	mock.ExpectExec("UPDATE plants SET image_url = \\$2, image_license = \\$3").
+		WithArgs(plantID, "https://example.org/monstera.jpg", "CC BY 4.0", "Anna").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE plant_enrichment_proposals SET status = 'REJECTED'").
+		WithArgs(plantID, models.PlantEnrichmentFieldImage, reviewerID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	proposal, err := repo.Review(context.Background(), id, models.PlantEnrichmentStatusApproved, reviewerID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, proposal) && assert.NotNil(t, proposal.Value.Image) {
+		assert.Equal(t, "Monstera", proposal.PlantName)
+		assert.Equal(t, "CC BY 4.0", proposal.Value.Image.License)
+	}
+
+	// A proposal that was already reviewed is not found
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE plant_enrichment_proposals e").
+		WithArgs(id, models.PlantEnrichmentStatusRejected, reviewerID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err = repo.Review(context.Background(), id, models.PlantEnrichmentStatusRejected, reviewerID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
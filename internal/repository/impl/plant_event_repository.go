@@ -0,0 +1,66 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PlantEventRepository is the implementation of the plant event repository
+type PlantEventRepository struct {
+	db *db.DB
+}
+
+// NewPlantEventRepository creates a new plant event repository
+func NewPlantEventRepository(db *db.DB) *PlantEventRepository {
+	return &PlantEventRepository{
+		db: db.Repository("plant_event"),
+	}
+}
+
+// Create appends an event to the event stream of a plant in a user's collection
+func (r *PlantEventRepository) Create(ctx context.Context, event *models.PlantEvent) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO plant_events (user_id, plant_id, type, payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, seq, occurred_at
+	`, event.UserID, event.PlantID, event.Type, event.Payload).Scan(&event.ID, &event.Seq, &event.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to create plant event: %w", err)
+	}
+	return nil
+}
+
+// ListAfter gets up to limit events of a plant in a user's collection recorded after the given
+// position in the stream, oldest first; only events of the given types when any are given
+func (r *PlantEventRepository) ListAfter(
+	ctx context.Context,
+	userID uuid.UUID,
+	plantID uuid.UUID,
+	afterSeq int64,
+	types []models.PlantEventType,
+	limit int,
+) ([]*models.PlantEvent, error) {
+	typeNames := make([]string, len(types))
+	for i, eventType := range types {
+		typeNames[i] = string(eventType)
+	}
+
+	events := []*models.PlantEvent{}
+	err := r.db.SelectContext(ctx, &events, `
+		SELECT id, seq, user_id, plant_id, type, payload, occurred_at
+		FROM plant_events
+		WHERE user_id = $1 AND plant_id = $2 AND seq > $3
+			AND (cardinality($4::text[]) = 0 OR type = ANY($4))
+		ORDER BY seq
+		LIMIT $5
+	`, userID, plantID, afterSeq, pq.StringArray(typeNames), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,41 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlantEventRepository_ListAfter(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantEventRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID, plantID, eventID := uuid.New(), uuid.New(), uuid.New()
+	occurredAt := time.Date(2024, time.May, 1, 9, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "seq", "user_id", "plant_id", "type", "payload", "occurred_at"}).
+		AddRow(eventID, int64(12), userID, plantID, "MOVED", []byte(`{"from":"Kitchen","to":"Bedroom"}`), occurredAt)
+	mock.ExpectQuery("SELECT (.+) FROM plant_events").
+		WithArgs(userID, plantID, int64(11), pq.StringArray{"MOVED"}, 51).
+		WillReturnRows(rows)
+
+	events, err := repo.ListAfter(context.Background(), userID, plantID, 11, []models.PlantEventType{models.PlantEventTypeMoved}, 51)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, int64(12), events[0].Seq)
+		assert.Equal(t, models.PlantEventTypeMoved, events[0].Type)
+		assert.Equal(t, models.PlantEventPayload{"from": "Kitchen", "to": "Bedroom"}, events[0].Payload)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,68 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// PlantExportRepository is the implementation of the plant export repository
+type PlantExportRepository struct {
+	db *db.DB
+}
+
+// NewPlantExportRepository creates a new plant export repository
+func NewPlantExportRepository(db *db.DB) *PlantExportRepository {
+	return &PlantExportRepository{
+		db: db.Repository("plant_export"),
+	}
+}
+
+// Each calls fn with every plant of the catalog and its care instructions, ordered by name and read
+// one at a time; plants removed from the catalog are left out. An error of fn stops the export and
+// is returned.
+func (r *PlantExportRepository) Each(ctx context.Context, fn func(plant *models.Plant) error) error {
+	rows, err := r.db.QueryxContext(ctx, `
+		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id, p.family, p.pet_friendly,
+			   p.species_id, p.created_at, p.updated_at,
+			   c.id, `+r.db.Read("c", "care_instructions", "watering_frequency")+`,
+			   c.watering_frequency_min, c.watering_frequency_max, c.sunlight, c.min_temperature, c.max_temperature,
+			   c.humidity, c.soil_type, `+r.db.Read("c", "care_instructions", "fertilizer_frequency")+`,
+			   c.additional_notes, c.source_url, c.source_author, c.last_reviewed_at
+		FROM plants p
+		JOIN care_instructions c ON p.care_instructions_id = c.id
+		WHERE p.deleted_at IS NULL
+		ORDER BY p.name, p.id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to export plants: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var plant models.Plant
+		care := &plant.CareInstructions
+		err := rows.Scan(
+			&plant.ID, &plant.Name, &plant.ScientificName, &plant.Description, &plant.ImageURL,
+			&plant.Price, &plant.ShopID, &plant.Family, &plant.PetFriendly,
+			&plant.SpeciesID, &plant.CreatedAt, &plant.UpdatedAt,
+			&care.ID, &care.WateringFrequency,
+			&care.WateringFrequencyMin, &care.WateringFrequencyMax, &care.Sunlight, &care.Temperature.Min, &care.Temperature.Max,
+			&care.Humidity, &care.SoilType, &care.FertilizerFrequency,
+			&care.AdditionalNotes, &care.SourceURL, &care.SourceAuthor, &care.LastReviewedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan plant: %w", err)
+		}
+		if err := fn(&plant); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating plants: %w", err)
+	}
+	return nil
+}
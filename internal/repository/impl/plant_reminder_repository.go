@@ -0,0 +1,163 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantReminderRepository is the implementation of the plant reminder repository
+type PlantReminderRepository struct {
+	db *db.DB
+}
+
+// NewPlantReminderRepository creates a new plant reminder repository
+func NewPlantReminderRepository(db *db.DB) *PlantReminderRepository {
+	return &PlantReminderRepository{
+		db: db.Repository("plant_reminder"),
+	}
+}
+
+// ListByUserPlant gets the reminders of a plant in a user's collection, the next due first
+func (r *PlantReminderRepository) ListByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.PlantReminder, error) {
+	reminders := []*models.PlantReminder{}
+	err := r.db.SelectContext(ctx, &reminders, `
+		SELECT id, user_id, plant_id, title, frequency_days, next_due, created_at, updated_at
+		FROM plant_reminders
+		WHERE user_id = $1 AND plant_id = $2
+		ORDER BY next_due ASC, created_at ASC
+	`, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// GetByID gets a reminder
+func (r *PlantReminderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PlantReminder, error) {
+	var reminder models.PlantReminder
+	err := r.db.GetContext(ctx, &reminder, `
+		SELECT id, user_id, plant_id, title, frequency_days, next_due, created_at, updated_at
+		FROM plant_reminders
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("plant reminder not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get plant reminder: %w", err)
+	}
+	return &reminder, nil
+}
+
+// Create stores a new reminder
+func (r *PlantReminderRepository) Create(ctx context.Context, reminder *models.PlantReminder) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO plant_reminders (user_id, plant_id, title, frequency_days, next_due)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, reminder.UserID, reminder.PlantID, reminder.Title, reminder.FrequencyDays, reminder.NextDue,
+	).Scan(&reminder.ID, &reminder.CreatedAt, &reminder.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create plant reminder: %w", err)
+	}
+	return nil
+}
+
+// Update updates the title, frequency and due date of a reminder
+func (r *PlantReminderRepository) Update(ctx context.Context, reminder *models.PlantReminder) error {
+	err := r.db.QueryRowxContext(ctx, `
+		UPDATE plant_reminders
+		SET title = $2, frequency_days = $3, next_due = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, reminder.ID, reminder.Title, reminder.FrequencyDays, reminder.NextDue,
+	).Scan(&reminder.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("plant reminder not found: %w", err)
+		}
+		return fmt.Errorf("failed to update plant reminder: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a reminder
+func (r *PlantReminderRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM plant_reminders WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete plant reminder: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("plant reminder not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// GetDue gets the reminders due on or before the given day with the plants they are about
+func (r *PlantReminderRepository) GetDue(ctx context.Context, until time.Time) ([]*models.PlantReminder, error) {
+	rows, err := r.db.QueryxContext(ctx, `
+		SELECT pr.id, pr.user_id, pr.plant_id, pr.title, pr.frequency_days, pr.next_due,
+			up.id, up.location, up.created_at, p.name, u.language
+		FROM plant_reminders pr
+		JOIN user_plants up ON up.user_id = pr.user_id AND up.plant_id = pr.plant_id
+		JOIN plants p ON pr.plant_id = p.id
+		JOIN users u ON pr.user_id = u.id
+		WHERE pr.next_due <= $1 AND u.anonymized_at IS NULL AND u.deleted_at IS NULL
+		ORDER BY pr.next_due ASC
+	`, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due plant reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []*models.PlantReminder
+	for rows.Next() {
+		var reminder models.PlantReminder
+		var userPlant models.UserPlant
+		var plantName string
+		err := rows.Scan(
+			&reminder.ID, &reminder.UserID, &reminder.PlantID, &reminder.Title, &reminder.FrequencyDays, &reminder.NextDue,
+			&userPlant.ID, &userPlant.Location, &userPlant.CreatedAt, &plantName, &userPlant.UserLanguage,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan plant reminder: %w", err)
+		}
+
+		userPlant.UserID = reminder.UserID
+		userPlant.PlantID = reminder.PlantID
+		userPlant.Plant = &models.Plant{
+			ID:   reminder.PlantID,
+			Name: plantName,
+		}
+		reminder.UserPlant = &userPlant
+		reminders = append(reminders, &reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating plant reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// SetNextDue moves a reminder to its next due date
+func (r *PlantReminderRepository) SetNextDue(ctx context.Context, id uuid.UUID, nextDue time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE plant_reminders SET next_due = $1, updated_at = NOW() WHERE id = $2
+	`, nextDue, id)
+	if err != nil {
+		return fmt.Errorf("failed to update plant reminder: %w", err)
+	}
+	return nil
+}
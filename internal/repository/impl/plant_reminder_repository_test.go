@@ -0,0 +1,61 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlantReminderRepository_GetDue(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantReminderRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	today := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	reminderID, userID, plantID, userPlantID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	mock.ExpectQuery("SELECT .* FROM plant_reminders pr JOIN user_plants up .* WHERE pr.next_due <= \\$1 AND u.anonymized_at IS NULL AND u.deleted_at IS NULL").
+		WithArgs(today).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "plant_id", "title", "frequency_days", "next_due",
+			"id", "location", "created_at", "name", "language",
+		}).AddRow(reminderID, userID, plantID, "Check for spider mites", 30, today, userPlantID, "Kitchen", today, "Monstera", models.LanguageEnglish))
+
+	reminders, err := repo.GetDue(context.Background(), today)
+	assert.NoError(t, err)
+	if assert.Len(t, reminders, 1) {
+		assert.Equal(t, "Check for spider mites", reminders[0].Title)
+		assert.Equal(t, 30, reminders[0].FrequencyDays)
+		assert.Equal(t, plantID, reminders[0].UserPlant.PlantID)
+		assert.Equal(t, "Monstera", reminders[0].UserPlant.Plant.Name)
+		assert.Equal(t, models.LanguageEnglish, reminders[0].UserPlant.UserLanguage)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlantReminderRepository_Update_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantReminderRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	reminder := &models.PlantReminder{ID: uuid.New(), Title: "Wipe the leaves", FrequencyDays: 14, NextDue: time.Date(2024, 5, 24, 0, 0, 0, 0, time.UTC)}
+	mock.ExpectQuery("UPDATE plant_reminders SET title = \\$2, frequency_days = \\$3, next_due = \\$4").
+		WithArgs(reminder.ID, reminder.Title, reminder.FrequencyDays, reminder.NextDue).
+		WillReturnError(sql.ErrNoRows)
+
+	assert.ErrorIs(t, repo.Update(context.Background(), reminder), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/anpanovv/planter/internal/db"
 	"github.com/anpanovv/planter/internal/models"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // PlantRepository is the implementation of the plant repository
@@ -20,22 +22,44 @@ type PlantRepository struct {
 // NewPlantRepository creates a new plant repository
 func NewPlantRepository(db *db.DB) *PlantRepository {
 	return &PlantRepository{
-		db: db,
+		db: db.Repository("plant"),
 	}
 }
 
+// plantCategoriesColumn and plantTagsColumn read the category slugs and the tags of the plant p
+const (
+	plantCategoriesColumn = `ARRAY(
+		SELECT cat.slug FROM plant_categories pc JOIN categories cat ON cat.id = pc.category_id
+		WHERE pc.plant_id = p.id ORDER BY cat.slug
+	)`
+	plantTagsColumn = `ARRAY(
+		SELECT t.name FROM plant_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.plant_id = p.id ORDER BY t.name
+	)`
+)
+
+// knownToxicity returns the toxicity of a plant read from the database, nil when nothing is known
+func knownToxicity(toxicity models.Toxicity) *models.Toxicity {
+	if toxicity.Pets == nil && toxicity.Children == nil {
+		return nil
+	}
+	return &toxicity
+}
+
 // GetAll gets all plants
 func (r *PlantRepository) GetAll(ctx context.Context) ([]*models.Plant, error) {
 	rows, err := r.db.QueryxContext(ctx, `
-		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id,
-			   p.created_at, p.updated_at,
-			   c.id as "care_instructions.id", c.watering_frequency as "care_instructions.watering_frequency",
+		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id, p.family, p.pet_friendly,
+			   p.toxicity_pets, p.toxicity_children, p.created_at, p.updated_at, `+plantCategoriesColumn+`, `+plantTagsColumn+`,
+			   c.id as "care_instructions.id", `+r.db.Read("c", "care_instructions", "watering_frequency")+` as "care_instructions.watering_frequency",
 			   c.sunlight as "care_instructions.sunlight", c.min_temperature, c.max_temperature,
 			   c.humidity as "care_instructions.humidity", c.soil_type as "care_instructions.soil_type",
-			   c.fertilizer_frequency as "care_instructions.fertilizer_frequency",
-			   c.additional_notes as "care_instructions.additional_notes"
+			   `+r.db.Read("c", "care_instructions", "fertilizer_frequency")+` as "care_instructions.fertilizer_frequency",
+			   c.additional_notes as "care_instructions.additional_notes",
+			   c.source_url, c.source_author, c.last_reviewed_at
 		FROM plants p
 		JOIN care_instructions c ON p.care_instructions_id = c.id
+		WHERE p.deleted_at IS NULL AND p.status = 'PUBLISHED'
 		ORDER BY p.name
 	`)
 	if err != nil {
@@ -48,13 +72,16 @@ func (r *PlantRepository) GetAll(ctx context.Context) ([]*models.Plant, error) {
 		var plant models.Plant
 		var careInstructions models.CareInstructions
 		var minTemp, maxTemp int
+		var toxicity models.Toxicity
 
 		err := rows.Scan(
 			&plant.ID, &plant.Name, &plant.ScientificName, &plant.Description, &plant.ImageURL,
-			&plant.Price, &plant.ShopID, &plant.CreatedAt, &plant.UpdatedAt,
+			&plant.Price, &plant.ShopID, &plant.Family, &plant.PetFriendly, &toxicity.Pets, &toxicity.Children, &plant.CreatedAt, &plant.UpdatedAt,
+			&plant.Categories, &plant.Tags,
 			&careInstructions.ID, &careInstructions.WateringFrequency, &careInstructions.Sunlight,
 			&minTemp, &maxTemp, &careInstructions.Humidity, &careInstructions.SoilType,
 			&careInstructions.FertilizerFrequency, &careInstructions.AdditionalNotes,
+			&careInstructions.SourceURL, &careInstructions.SourceAuthor, &careInstructions.LastReviewedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan plant: %w", err)
@@ -65,6 +92,7 @@ func (r *PlantRepository) GetAll(ctx context.Context) ([]*models.Plant, error) {
 			Max: maxTemp,
 		}
 		plant.CareInstructions = careInstructions
+		plant.Toxicity = knownToxicity(toxicity)
 		plants = append(plants, &plant)
 	}
 
@@ -75,26 +103,133 @@ func (r *PlantRepository) GetAll(ctx context.Context) ([]*models.Plant, error) {
 	return plants, nil
 }
 
+// plantFilterCondition matches plants against the optional filters bound to $1-$6; plants removed
+// from the catalog are left out unless $7 is true, and only plants in the review state $8, in the
+// category with the slug $9 and with all the tags $10 are matched when they are set
+const plantFilterCondition = `
+	($1::text IS NULL OR c.sunlight::text = $1)
+	AND ($2::text IS NULL OR c.humidity::text = $2)
+	AND ($3::boolean IS NULL OR p.pet_friendly = $3)
+	AND ($4::numeric IS NULL OR p.price >= $4)
+	AND ($5::numeric IS NULL OR p.price <= $5)
+	AND ($6::uuid IS NULL OR p.shop_id = $6 OR EXISTS (
+		SELECT 1 FROM shop_plants sp WHERE sp.plant_id = p.id AND sp.shop_id = $6
+	))
+	AND ($7::boolean OR p.deleted_at IS NULL)
+	AND ($8::text IS NULL OR p.status = $8)
+	AND ($9::text IS NULL OR EXISTS (
+		SELECT 1 FROM plant_categories pc JOIN categories cat ON cat.id = pc.category_id
+		WHERE pc.plant_id = p.id AND cat.slug = $9
+	))
+	AND (COALESCE(cardinality($10::text[]), 0) = 0 OR (
+		SELECT COUNT(*) FROM plant_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.plant_id = p.id AND t.name = ANY($10)
+	) = cardinality($10::text[]))
+`
+
+// List gets a page of plants matching the filter, ordered by name, with the total number of matches
+func (r *PlantRepository) List(ctx context.Context, filter *models.PlantFilter) ([]*models.Plant, int, error) {
+	args := []interface{}{
+		filter.Sunlight, filter.Humidity, filter.PetFriendly,
+		filter.MinPrice, filter.MaxPrice, filter.ShopID, filter.IncludeDeleted, filter.Status,
+		filter.Category, pq.StringArray(filter.Tags),
+	}
+
+	// Count the matches
+	var total int
+	err := r.db.GetContext(ctx, &total, `
+		SELECT COUNT(*)
+		FROM plants p
+		JOIN care_instructions c ON p.care_instructions_id = c.id
+		WHERE `+plantFilterCondition, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count plants: %w", err)
+	}
+
+	rows, err := r.db.QueryxContext(ctx, `
+		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id, p.family, p.pet_friendly,
+			   p.toxicity_pets, p.toxicity_children, p.created_at, p.updated_at, p.deleted_at, p.species_id, p.care_overrides, p.status,
+			   `+plantCategoriesColumn+`, `+plantTagsColumn+`,
+			   c.id, `+r.db.Read("c", "care_instructions", "watering_frequency")+`, c.sunlight, c.min_temperature, c.max_temperature,
+			   c.humidity, c.soil_type, `+r.db.Read("c", "care_instructions", "fertilizer_frequency")+`, c.additional_notes,
+			   c.source_url, c.source_author, c.last_reviewed_at
+		FROM plants p
+		JOIN care_instructions c ON p.care_instructions_id = c.id
+		WHERE `+plantFilterCondition+`
+		ORDER BY p.name, p.id
+		LIMIT $11 OFFSET $12
+	`, append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list plants: %w", err)
+	}
+	defer rows.Close()
+
+	plants := []*models.Plant{}
+	for rows.Next() {
+		var plant models.Plant
+		var careInstructions models.CareInstructions
+		var minTemp, maxTemp int
+		var toxicity models.Toxicity
+
+		err := rows.Scan(
+			&plant.ID, &plant.Name, &plant.ScientificName, &plant.Description, &plant.ImageURL,
+			&plant.Price, &plant.ShopID, &plant.Family, &plant.PetFriendly, &toxicity.Pets, &toxicity.Children, &plant.CreatedAt, &plant.UpdatedAt, &plant.DeletedAt,
+			&plant.SpeciesID, &plant.CareOverrides, &plant.Status, &plant.Categories, &plant.Tags,
+			&careInstructions.ID, &careInstructions.WateringFrequency, &careInstructions.Sunlight,
+			&minTemp, &maxTemp, &careInstructions.Humidity, &careInstructions.SoilType,
+			&careInstructions.FertilizerFrequency, &careInstructions.AdditionalNotes,
+			&careInstructions.SourceURL, &careInstructions.SourceAuthor, &careInstructions.LastReviewedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan plant: %w", err)
+		}
+
+		careInstructions.Temperature = models.TemperatureRange{
+			Min: minTemp,
+			Max: maxTemp,
+		}
+		plant.CareInstructions = careInstructions
+		plant.Toxicity = knownToxicity(toxicity)
+		plants = append(plants, &plant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating plants: %w", err)
+	}
+
+	return plants, total, nil
+}
+
 // GetByID gets a plant by ID
 func (r *PlantRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Plant, error) {
 	var plant models.Plant
 	var careInstructions models.CareInstructions
 	var minTemp, maxTemp int
+	var toxicity models.Toxicity
 
 	err := r.db.QueryRowxContext(ctx, `
-		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id,
-			   p.created_at, p.updated_at,
-			   c.id, c.watering_frequency, c.sunlight, c.min_temperature, c.max_temperature,
-			   c.humidity, c.soil_type, c.fertilizer_frequency, c.additional_notes
+		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id, p.family, p.pet_friendly,
+			   p.toxicity_pets, p.toxicity_children, p.created_at, p.updated_at, p.deleted_at, p.species_id, p.care_overrides,
+			   p.synonyms, p.image_license, p.image_attribution, p.status,
+			   `+plantCategoriesColumn+`, `+plantTagsColumn+`,
+			   c.id, `+r.db.Read("c", "care_instructions", "watering_frequency")+`, c.watering_frequency_min, c.watering_frequency_max,
+			   c.sunlight, c.min_temperature, c.max_temperature,
+			   c.humidity, c.soil_type, `+r.db.Read("c", "care_instructions", "fertilizer_frequency")+`, c.additional_notes,
+			   c.source_url, c.source_author, c.last_reviewed_at
 		FROM plants p
 		JOIN care_instructions c ON p.care_instructions_id = c.id
 		WHERE p.id = $1
 	`, id).Scan(
 		&plant.ID, &plant.Name, &plant.ScientificName, &plant.Description, &plant.ImageURL,
-		&plant.Price, &plant.ShopID, &plant.CreatedAt, &plant.UpdatedAt,
-		&careInstructions.ID, &careInstructions.WateringFrequency, &careInstructions.Sunlight,
+		&plant.Price, &plant.ShopID, &plant.Family, &plant.PetFriendly, &toxicity.Pets, &toxicity.Children, &plant.CreatedAt, &plant.UpdatedAt, &plant.DeletedAt,
+		&plant.SpeciesID, &plant.CareOverrides,
+		&plant.Synonyms, &plant.ImageLicense, &plant.ImageAttribution, &plant.Status,
+		&plant.Categories, &plant.Tags,
+		&careInstructions.ID, &careInstructions.WateringFrequency,
+		&careInstructions.WateringFrequencyMin, &careInstructions.WateringFrequencyMax, &careInstructions.Sunlight,
 		&minTemp, &maxTemp, &careInstructions.Humidity, &careInstructions.SoilType,
 		&careInstructions.FertilizerFrequency, &careInstructions.AdditionalNotes,
+		&careInstructions.SourceURL, &careInstructions.SourceAuthor, &careInstructions.LastReviewedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -108,25 +243,37 @@ func (r *PlantRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Pl
 		Max: maxTemp,
 	}
 	plant.CareInstructions = careInstructions
+	plant.Toxicity = knownToxicity(toxicity)
 
 	return &plant, nil
 }
 
-// Search searches for plants by query
-func (r *PlantRepository) Search(ctx context.Context, query string) ([]*models.Plant, error) {
+// Search gets the catalog plants whose name, scientific name or description contain the text of
+// the query and that match its filters, ordered by name
+func (r *PlantRepository) Search(ctx context.Context, query *models.PlantSearchQuery) ([]*models.Plant, error) {
+	wateringFrequency := r.db.Read("c", "care_instructions", "watering_frequency")
 	rows, err := r.db.QueryxContext(ctx, `
-		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id,
-			   p.created_at, p.updated_at,
-			   c.id as "care_instructions.id", c.watering_frequency as "care_instructions.watering_frequency",
+		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id, p.family, p.pet_friendly,
+			   p.toxicity_pets, p.toxicity_children, p.created_at, p.updated_at, `+plantCategoriesColumn+`, `+plantTagsColumn+`,
+			   c.id as "care_instructions.id", `+wateringFrequency+` as "care_instructions.watering_frequency",
 			   c.sunlight as "care_instructions.sunlight", c.min_temperature, c.max_temperature,
 			   c.humidity as "care_instructions.humidity", c.soil_type as "care_instructions.soil_type",
-			   c.fertilizer_frequency as "care_instructions.fertilizer_frequency",
-			   c.additional_notes as "care_instructions.additional_notes"
+			   `+r.db.Read("c", "care_instructions", "fertilizer_frequency")+` as "care_instructions.fertilizer_frequency",
+			   c.additional_notes as "care_instructions.additional_notes",
+			   c.source_url, c.source_author, c.last_reviewed_at
 		FROM plants p
 		JOIN care_instructions c ON p.care_instructions_id = c.id
-		WHERE p.name ILIKE $1 OR p.scientific_name ILIKE $1 OR p.description ILIKE $1
+		WHERE p.deleted_at IS NULL AND p.status = 'PUBLISHED'
+			AND ($1::text = '' OR p.name ILIKE '%' || $1 || '%' OR p.scientific_name ILIKE '%' || $1 || '%'
+				OR p.description ILIKE '%' || $1 || '%')
+			AND ($2::text IS NULL OR c.sunlight::text = $2)
+			AND ($3::text IS NULL OR c.humidity::text = $3)
+			AND ($4::boolean IS NULL OR p.pet_friendly = $4)
+			AND ($5::text IS NULL OR p.family ILIKE $5)
+			AND ($6::int IS NULL OR `+wateringFrequency+` >= $6)
+			AND ($7::int IS NULL OR `+wateringFrequency+` <= $7)
 		ORDER BY p.name
-	`, "%"+query+"%")
+	`, query.Text, query.Sunlight, query.Humidity, query.PetFriendly, query.Family, query.MinWatering, query.MaxWatering)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search plants: %w", err)
 	}
@@ -137,13 +284,16 @@ func (r *PlantRepository) Search(ctx context.Context, query string) ([]*models.P
 		var plant models.Plant
 		var careInstructions models.CareInstructions
 		var minTemp, maxTemp int
+		var toxicity models.Toxicity
 
 		err := rows.Scan(
 			&plant.ID, &plant.Name, &plant.ScientificName, &plant.Description, &plant.ImageURL,
-			&plant.Price, &plant.ShopID, &plant.CreatedAt, &plant.UpdatedAt,
+			&plant.Price, &plant.ShopID, &plant.Family, &plant.PetFriendly, &toxicity.Pets, &toxicity.Children, &plant.CreatedAt, &plant.UpdatedAt,
+			&plant.Categories, &plant.Tags,
 			&careInstructions.ID, &careInstructions.WateringFrequency, &careInstructions.Sunlight,
 			&minTemp, &maxTemp, &careInstructions.Humidity, &careInstructions.SoilType,
 			&careInstructions.FertilizerFrequency, &careInstructions.AdditionalNotes,
+			&careInstructions.SourceURL, &careInstructions.SourceAuthor, &careInstructions.LastReviewedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan plant: %w", err)
@@ -154,6 +304,7 @@ func (r *PlantRepository) Search(ctx context.Context, query string) ([]*models.P
 			Max: maxTemp,
 		}
 		plant.CareInstructions = careInstructions
+		plant.Toxicity = knownToxicity(toxicity)
 		plants = append(plants, &plant)
 	}
 
@@ -167,17 +318,18 @@ func (r *PlantRepository) Search(ctx context.Context, query string) ([]*models.P
 // GetFavorites gets a user's favorite plants
 func (r *PlantRepository) GetFavorites(ctx context.Context, userID uuid.UUID) ([]*models.Plant, error) {
 	rows, err := r.db.QueryxContext(ctx, `
-		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id,
-			   p.created_at, p.updated_at,
-			   c.id as "care_instructions.id", c.watering_frequency as "care_instructions.watering_frequency",
+		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id, p.family, p.pet_friendly,
+			   p.created_at, p.updated_at, p.deleted_at,
+			   c.id as "care_instructions.id", `+r.db.Read("c", "care_instructions", "watering_frequency")+` as "care_instructions.watering_frequency",
 			   c.sunlight as "care_instructions.sunlight", c.min_temperature, c.max_temperature,
 			   c.humidity as "care_instructions.humidity", c.soil_type as "care_instructions.soil_type",
-			   c.fertilizer_frequency as "care_instructions.fertilizer_frequency",
-			   c.additional_notes as "care_instructions.additional_notes"
+			   `+r.db.Read("c", "care_instructions", "fertilizer_frequency")+` as "care_instructions.fertilizer_frequency",
+			   c.additional_notes as "care_instructions.additional_notes",
+			   c.source_url, c.source_author, c.last_reviewed_at
 		FROM plants p
 		JOIN care_instructions c ON p.care_instructions_id = c.id
 		JOIN user_favorite_plants ufp ON p.id = ufp.plant_id
-		WHERE ufp.user_id = $1
+		WHERE ufp.user_id = $1 AND ufp.deleted_at IS NULL
 		ORDER BY ufp.created_at DESC
 	`, userID)
 	if err != nil {
@@ -193,10 +345,11 @@ func (r *PlantRepository) GetFavorites(ctx context.Context, userID uuid.UUID) ([
 
 		err := rows.Scan(
 			&plant.ID, &plant.Name, &plant.ScientificName, &plant.Description, &plant.ImageURL,
-			&plant.Price, &plant.ShopID, &plant.CreatedAt, &plant.UpdatedAt,
+			&plant.Price, &plant.ShopID, &plant.Family, &plant.PetFriendly, &plant.CreatedAt, &plant.UpdatedAt, &plant.DeletedAt,
 			&careInstructions.ID, &careInstructions.WateringFrequency, &careInstructions.Sunlight,
 			&minTemp, &maxTemp, &careInstructions.Humidity, &careInstructions.SoilType,
 			&careInstructions.FertilizerFrequency, &careInstructions.AdditionalNotes,
+			&careInstructions.SourceURL, &careInstructions.SourceAuthor, &careInstructions.LastReviewedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan plant: %w", err)
@@ -218,12 +371,14 @@ func (r *PlantRepository) GetFavorites(ctx context.Context, userID uuid.UUID) ([
 	return plants, nil
 }
 
-// AddToFavorites adds a plant to a user's favorites
+// AddToFavorites adds a plant to a user's favorites; a removed favorite is added again
 func (r *PlantRepository) AddToFavorites(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, `
 		INSERT INTO user_favorite_plants (user_id, plant_id)
 		VALUES ($1, $2)
-		ON CONFLICT (user_id, plant_id) DO NOTHING
+		ON CONFLICT (user_id, plant_id) DO UPDATE
+		SET created_at = NOW(), deleted_at = NULL
+		WHERE user_favorite_plants.deleted_at IS NOT NULL
 	`, userID, plantID)
 	if err != nil {
 		return fmt.Errorf("failed to add plant to favorites: %w", err)
@@ -231,11 +386,13 @@ func (r *PlantRepository) AddToFavorites(ctx context.Context, userID uuid.UUID,
 	return nil
 }
 
-// RemoveFromFavorites removes a plant from a user's favorites
+// RemoveFromFavorites removes a plant from a user's favorites. The favorite is kept as a tombstone,
+// so clients syncing their favorites learn about the removal.
 func (r *PlantRepository) RemoveFromFavorites(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, `
-		DELETE FROM user_favorite_plants
-		WHERE user_id = $1 AND plant_id = $2
+		UPDATE user_favorite_plants
+		SET deleted_at = NOW()
+		WHERE user_id = $1 AND plant_id = $2 AND deleted_at IS NULL
 	`, userID, plantID)
 	if err != nil {
 		return fmt.Errorf("failed to remove plant from favorites: %w", err)
@@ -243,6 +400,48 @@ func (r *PlantRepository) RemoveFromFavorites(ctx context.Context, userID uuid.U
 	return nil
 }
 
+// GetFavoriteChanges gets the plants a user added to or removed from their favorites after since,
+// all their favorites when since is zero
+func (r *PlantRepository) GetFavoriteChanges(ctx context.Context, userID uuid.UUID, since time.Time) (*models.FavoriteChanges, error) {
+	changes := &models.FavoriteChanges{Added: []uuid.UUID{}, Removed: []uuid.UUID{}}
+	if err := r.db.GetContext(ctx, &changes.Until, `SELECT NOW()`); err != nil {
+		return nil, fmt.Errorf("failed to get favorite changes: %w", err)
+	}
+
+	var rows []struct {
+		PlantID uuid.UUID `db:"plant_id"`
+		Removed bool      `db:"removed"`
+	}
+	var err error
+	if since.IsZero() {
+		err = r.db.SelectContext(ctx, &rows, `
+			SELECT plant_id, FALSE AS removed
+			FROM user_favorite_plants
+			WHERE user_id = $1 AND deleted_at IS NULL
+			ORDER BY created_at
+		`, userID)
+	} else {
+		err = r.db.SelectContext(ctx, &rows, `
+			SELECT plant_id, deleted_at IS NOT NULL AS removed
+			FROM user_favorite_plants
+			WHERE user_id = $1 AND GREATEST(created_at, deleted_at) > $2
+			ORDER BY GREATEST(created_at, deleted_at)
+		`, userID, since)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get favorite changes: %w", err)
+	}
+
+	for _, row := range rows {
+		if row.Removed {
+			changes.Removed = append(changes.Removed, row.PlantID)
+		} else {
+			changes.Added = append(changes.Added, row.PlantID)
+		}
+	}
+	return changes, nil
+}
+
 // MarkAsWatered marks a plant as watered
 func (r *PlantRepository) MarkAsWatered(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error {
 	// First verify the plant exists
@@ -257,17 +456,25 @@ func (r *PlantRepository) MarkAsWatered(ctx context.Context, userID uuid.UUID, p
 		return fmt.Errorf("plant with ID %s not found", plantID)
 	}
 
-	// Get the plant's watering frequency
-	var wateringFrequency int
+	// Get the plant's watering frequency and whether it is watered in low effort mode
+	var careInstructions models.CareInstructions
+	var lowEffort bool
 	err = r.db.QueryRowContext(ctx, `
-		SELECT c.watering_frequency
+		SELECT `+r.db.Read("c", "care_instructions", "watering_frequency")+`, c.watering_frequency_max,
+			   COALESCE(up.low_effort_mode, u.low_effort_mode, FALSE)
 		FROM plants p
 		JOIN care_instructions c ON p.care_instructions_id = c.id
+		LEFT JOIN users u ON u.id = $2
+		LEFT JOIN user_plants up ON up.plant_id = p.id AND up.user_id = $2
 		WHERE p.id = $1
-	`, plantID).Scan(&wateringFrequency)
+	`, plantID, userID).Scan(&careInstructions.WateringFrequency, &careInstructions.WateringFrequencyMax, &lowEffort)
 	if err != nil {
 		return fmt.Errorf("failed to get watering frequency for plant %s: %w", plantID, err)
 	}
+	wateringFrequency := careInstructions.WateringFrequency
+	if lowEffort {
+		wateringFrequency = careInstructions.LowEffortWateringFrequency()
+	}
 
 	// Calculate the next watering date
 	now := time.Now()
@@ -288,10 +495,13 @@ func (r *PlantRepository) MarkAsWatered(ctx context.Context, userID uuid.UUID, p
 	// Create or update the record
 	if !userPlantExists {
 		// Create new record
+		columns, values := r.db.Insert("user_plants",
+			[]string{"user_id", "plant_id", "last_watered", "next_watering", "created_at", "updated_at"},
+			[]string{"$1", "$2", "$3", "$4", "$5", "$5"})
 		_, err = r.db.ExecContext(ctx, `
 			INSERT INTO user_plants
-			(user_id, plant_id, last_watered, next_watering, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $5)
+			(`+columns+`)
+			VALUES (`+values+`)
 		`, userID, plantID, now, nextWatering, now)
 		if err != nil {
 			return fmt.Errorf("failed to create user plant record for user %s plant %s: %w", userID, plantID, err)
@@ -300,7 +510,7 @@ func (r *PlantRepository) MarkAsWatered(ctx context.Context, userID uuid.UUID, p
 		// Update existing record
 		_, err = r.db.ExecContext(ctx, `
 			UPDATE user_plants
-			SET last_watered = $1, next_watering = $2, updated_at = $1
+			SET `+r.db.Assign("user_plants", "last_watered", "$1")+`, `+r.db.Assign("user_plants", "next_watering", "$2")+`, updated_at = $1
 			WHERE user_id = $3 AND plant_id = $4
 		`, now, nextWatering, userID, plantID)
 		if err != nil {
@@ -315,7 +525,11 @@ func (r *PlantRepository) MarkAsWatered(ctx context.Context, userID uuid.UUID, p
 func (r *PlantRepository) GetUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) (*models.UserPlant, error) {
 	var userPlant models.UserPlant
 	err := r.db.GetContext(ctx, &userPlant, `
-		SELECT id, user_id, plant_id, location, last_watered, next_watering, created_at, updated_at
+		SELECT id, user_id, plant_id, location,
+			   `+r.db.Read("", "user_plants", "last_watered")+` AS last_watered,
+			   `+r.db.Read("", "user_plants", "next_watering")+` AS next_watering,
+			   nickname, notes, low_effort_mode, home_id, created_at, updated_at,
+			   (SELECT u.low_effort_mode FROM users u WHERE u.id = user_plants.user_id) AS user_low_effort_mode
 		FROM user_plants
 		WHERE user_id = $1 AND plant_id = $2
 	`, userID, plantID)
@@ -331,17 +545,21 @@ func (r *PlantRepository) GetUserPlant(ctx context.Context, userID uuid.UUID, pl
 // GetUserPlants gets all plants owned by a user
 func (r *PlantRepository) GetUserPlants(ctx context.Context, userID uuid.UUID) ([]*models.Plant, error) {
 	rows, err := r.db.QueryxContext(ctx, `
-		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id,
-			   p.created_at, p.updated_at,
-			   c.id as "care_instructions.id", c.watering_frequency as "care_instructions.watering_frequency",
+		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id, p.family, p.pet_friendly,
+			   p.created_at, p.updated_at, p.deleted_at,
+			   c.id as "care_instructions.id", `+r.db.Read("c", "care_instructions", "watering_frequency")+` as "care_instructions.watering_frequency",
 			   c.sunlight as "care_instructions.sunlight", c.min_temperature, c.max_temperature,
 			   c.humidity as "care_instructions.humidity", c.soil_type as "care_instructions.soil_type",
-			   c.fertilizer_frequency as "care_instructions.fertilizer_frequency",
+			   `+r.db.Read("c", "care_instructions", "fertilizer_frequency")+` as "care_instructions.fertilizer_frequency",
 			   c.additional_notes as "care_instructions.additional_notes",
-			   up.location, up.last_watered, up.next_watering
+			   c.source_url, c.source_author, c.last_reviewed_at,
+			   c.watering_frequency_min, c.watering_frequency_max,
+			   up.location, `+r.db.Read("up", "user_plants", "last_watered")+`, `+r.db.Read("up", "user_plants", "next_watering")+`,
+			   up.nickname, up.notes, up.low_effort_mode, u.low_effort_mode, up.home_id
 		FROM plants p
 		JOIN care_instructions c ON p.care_instructions_id = c.id
 		JOIN user_plants up ON p.id = up.plant_id
+		JOIN users u ON up.user_id = u.id
 		WHERE up.user_id = $1
 		ORDER BY up.created_at DESC
 	`, userID)
@@ -355,14 +573,18 @@ func (r *PlantRepository) GetUserPlants(ctx context.Context, userID uuid.UUID) (
 		var plant models.Plant
 		var careInstructions models.CareInstructions
 		var minTemp, maxTemp int
+		var userPlant models.UserPlant
 
 		err := rows.Scan(
 			&plant.ID, &plant.Name, &plant.ScientificName, &plant.Description, &plant.ImageURL,
-			&plant.Price, &plant.ShopID, &plant.CreatedAt, &plant.UpdatedAt,
+			&plant.Price, &plant.ShopID, &plant.Family, &plant.PetFriendly, &plant.CreatedAt, &plant.UpdatedAt, &plant.DeletedAt,
 			&careInstructions.ID, &careInstructions.WateringFrequency, &careInstructions.Sunlight,
 			&minTemp, &maxTemp, &careInstructions.Humidity, &careInstructions.SoilType,
 			&careInstructions.FertilizerFrequency, &careInstructions.AdditionalNotes,
+			&careInstructions.SourceURL, &careInstructions.SourceAuthor, &careInstructions.LastReviewedAt,
+			&careInstructions.WateringFrequencyMin, &careInstructions.WateringFrequencyMax,
 			&plant.Location, &plant.LastWatered, &plant.NextWatering,
+			&plant.Nickname, &plant.Notes, &userPlant.LowEffortMode, &userPlant.UserLowEffortMode, &plant.HomeID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan plant: %w", err)
@@ -373,6 +595,9 @@ func (r *PlantRepository) GetUserPlants(ctx context.Context, userID uuid.UUID) (
 			Max: maxTemp,
 		}
 		plant.CareInstructions = careInstructions
+		if lowEffort, source := userPlant.LowEffort(); lowEffort {
+			plant.LowEffort = models.NewLowEffortWatering(careInstructions, source)
+		}
 
 		// Check if the plant is a favorite
 		isFavorite, err := r.IsFavorite(ctx, userID, plant.ID)
@@ -393,12 +618,44 @@ func (r *PlantRepository) GetUserPlants(ctx context.Context, userID uuid.UUID) (
 
 // AddUserPlant adds a plant to a user's collection
 func (r *PlantRepository) AddUserPlant(ctx context.Context, userPlant *models.UserPlant) error {
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO user_plants (user_id, plant_id, location, last_watered, next_watering)
-		VALUES ($1, $2, $3, $4, $5)
+	return upsertUserPlant(ctx, r.db, r.db, userPlant)
+}
+
+// AddUserPlants adds plants to a user's collection like AddUserPlant, all in one transaction
+func (r *PlantRepository) AddUserPlants(ctx context.Context, userPlants []*models.UserPlant) error {
+	// Begin a transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, userPlant := range userPlants {
+		if err := upsertUserPlant(ctx, r.db, tx, userPlant); err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// upsertUserPlant adds a plant to a user's collection through exec, updating it when it is already there
+func upsertUserPlant(ctx context.Context, d *db.DB, exec sqlx.ExecerContext, userPlant *models.UserPlant) error {
+	columns, values := d.Insert("user_plants",
+		[]string{"user_id", "plant_id", "location", "last_watered", "next_watering", "nickname", "notes"},
+		[]string{"$1", "$2", "$3", "$4", "$5", "$6", "$7"})
+	_, err := exec.ExecContext(ctx, `
+		INSERT INTO user_plants (`+columns+`)
+		VALUES (`+values+`)
 		ON CONFLICT (user_id, plant_id) DO UPDATE
-		SET location = $3, last_watered = $4, next_watering = $5, updated_at = NOW()
-	`, userPlant.UserID, userPlant.PlantID, userPlant.Location, userPlant.LastWatered, userPlant.NextWatering)
+		SET location = $3, `+d.Assign("user_plants", "last_watered", "$4")+`, `+d.Assign("user_plants", "next_watering", "$5")+`,
+			nickname = COALESCE($6, user_plants.nickname), notes = COALESCE($7, user_plants.notes), updated_at = NOW()
+	`, userPlant.UserID, userPlant.PlantID, userPlant.Location, userPlant.LastWatered, userPlant.NextWatering,
+		userPlant.Nickname, userPlant.Notes)
 	if err != nil {
 		return fmt.Errorf("failed to add user plant: %w", err)
 	}
@@ -409,15 +666,51 @@ func (r *PlantRepository) AddUserPlant(ctx context.Context, userPlant *models.Us
 func (r *PlantRepository) UpdateUserPlant(ctx context.Context, userPlant *models.UserPlant) error {
 	_, err := r.db.ExecContext(ctx, `
 		UPDATE user_plants
-		SET location = $1, last_watered = $2, next_watering = $3, updated_at = NOW()
-		WHERE user_id = $4 AND plant_id = $5
-	`, userPlant.Location, userPlant.LastWatered, userPlant.NextWatering, userPlant.UserID, userPlant.PlantID)
+		SET location = $1, `+r.db.Assign("user_plants", "last_watered", "$2")+`, `+r.db.Assign("user_plants", "next_watering", "$3")+`,
+			nickname = $4, notes = $5, updated_at = NOW()
+		WHERE user_id = $6 AND plant_id = $7
+	`, userPlant.Location, userPlant.LastWatered, userPlant.NextWatering, userPlant.Nickname, userPlant.Notes,
+		userPlant.UserID, userPlant.PlantID)
 	if err != nil {
 		return fmt.Errorf("failed to update user plant: %w", err)
 	}
 	return nil
 }
 
+// SetNextWatering moves the next watering of a plant in a user's collection
+func (r *PlantRepository) SetNextWatering(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, nextWatering time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE user_plants
+		SET `+r.db.Assign("user_plants", "next_watering", "$1")+`, updated_at = NOW()
+		WHERE user_id = $2 AND plant_id = $3
+	`, nextWatering, userID, plantID)
+	if err != nil {
+		return fmt.Errorf("failed to set next watering of user plant: %w", err)
+	}
+	return nil
+}
+
+// SetUserPlantLowEffortMode sets the low effort mode of a plant in a user's collection; nil makes
+// the plant follow the user's mode
+func (r *PlantRepository) SetUserPlantLowEffortMode(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, enabled *bool) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_plants
+		SET low_effort_mode = $1, updated_at = NOW()
+		WHERE user_id = $2 AND plant_id = $3
+	`, enabled, userID, plantID)
+	if err != nil {
+		return fmt.Errorf("failed to set low effort mode of user plant: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user plant not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
 // RemoveUserPlant removes a plant from a user's collection
 func (r *PlantRepository) RemoveUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, `
@@ -436,7 +729,7 @@ func (r *PlantRepository) IsFavorite(ctx context.Context, userID uuid.UUID, plan
 	err := r.db.GetContext(ctx, &count, `
 		SELECT COUNT(*)
 		FROM user_favorite_plants
-		WHERE user_id = $1 AND plant_id = $2
+		WHERE user_id = $1 AND plant_id = $2 AND deleted_at IS NULL
 	`, userID, plantID)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if plant is favorite: %w", err)
@@ -444,7 +737,7 @@ func (r *PlantRepository) IsFavorite(ctx context.Context, userID uuid.UUID, plan
 	return count > 0, nil
 }
 
-// CreatePlant creates a new plant
+// CreatePlant creates a new plant, published unless it is given another review state
 func (r *PlantRepository) CreatePlant(ctx context.Context, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, error) {
 	// Begin a transaction
 	tx, err := r.db.BeginTxx(ctx, nil)
@@ -454,39 +747,20 @@ func (r *PlantRepository) CreatePlant(ctx context.Context, plant *models.Plant,
 	defer tx.Rollback()
 
 	// Create care instructions
-	err = tx.QueryRowxContext(ctx, `
-		INSERT INTO care_instructions (
-			watering_frequency, sunlight, min_temperature, max_temperature,
-			humidity, soil_type, fertilizer_frequency, additional_notes
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at
-	`,
-		careInstructions.WateringFrequency,
-		careInstructions.Sunlight,
-		careInstructions.Temperature.Min,
-		careInstructions.Temperature.Max,
-		careInstructions.Humidity,
-		careInstructions.SoilType,
-		careInstructions.FertilizerFrequency,
-		careInstructions.AdditionalNotes,
-	).Scan(
-		&careInstructions.ID,
-		&careInstructions.CreatedAt,
-		&careInstructions.UpdatedAt,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create care instructions: %w", err)
+	if err := insertCareInstructions(ctx, r.db, tx, careInstructions); err != nil {
+		return nil, err
 	}
 
 	// Create plant
+	pets, children := plant.Toxicity.Severities()
 	err = tx.QueryRowxContext(ctx, `
 		INSERT INTO plants (
 			name, scientific_name, description, image_url,
-			care_instructions_id, price, shop_id
+			care_instructions_id, price, shop_id, pet_friendly,
+			species_id, care_overrides, status, toxicity_pets, toxicity_children
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at, updated_at
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE(NULLIF($11, ''), 'PUBLISHED'), $12, $13)
+		RETURNING id, status, created_at, updated_at
 	`,
 		plant.Name,
 		plant.ScientificName,
@@ -495,8 +769,15 @@ func (r *PlantRepository) CreatePlant(ctx context.Context, plant *models.Plant,
 		careInstructions.ID,
 		plant.Price,
 		plant.ShopID,
+		plant.PetFriendly,
+		plant.SpeciesID,
+		plant.CareOverrides,
+		plant.Status,
+		pets,
+		children,
 	).Scan(
 		&plant.ID,
+		&plant.Status,
 		&plant.CreatedAt,
 		&plant.UpdatedAt,
 	)
@@ -515,15 +796,129 @@ func (r *PlantRepository) CreatePlant(ctx context.Context, plant *models.Plant,
 	return plant, nil
 }
 
+// UpdatePlant updates a plant in the catalog and its care instructions together. The license of
+// the image is dropped when the image changes.
+func (r *PlantRepository) UpdatePlant(ctx context.Context, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, error) {
+	// Begin a transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Update the plant
+	pets, children := plant.Toxicity.Severities()
+	err = tx.QueryRowxContext(ctx, `
+		UPDATE plants
+		SET name = $2, scientific_name = $3, description = $4, image_url = $5,
+			price = $6, shop_id = $7, pet_friendly = $8, species_id = $9, care_overrides = $10,
+			toxicity_pets = $11, toxicity_children = $12,
+			image_license = CASE WHEN image_url = $5 THEN image_license END,
+			image_attribution = CASE WHEN image_url = $5 THEN image_attribution END,
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING care_instructions_id, created_at, updated_at
+	`,
+		plant.ID,
+		plant.Name,
+		plant.ScientificName,
+		plant.Description,
+		plant.ImageURL,
+		plant.Price,
+		plant.ShopID,
+		plant.PetFriendly,
+		plant.SpeciesID,
+		plant.CareOverrides,
+		pets,
+		children,
+	).Scan(
+		&careInstructions.ID,
+		&plant.CreatedAt,
+		&plant.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("plant not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to update plant: %w", err)
+	}
+
+	// Update its care instructions
+	if err := updateCareInstructions(ctx, r.db, tx, careInstructions); err != nil {
+		return nil, err
+	}
+
+	// Set care instructions
+	plant.CareInstructions = *careInstructions
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return plant, nil
+}
+
+// DeletePlant removes a plant from the catalog. The plant is soft-deleted so it stays in
+// collections and favorites; its care instructions are kept with it.
+func (r *PlantRepository) DeletePlant(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE plants
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete plant: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("plant not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// UpdatePlantImage replaces the image of a plant in the catalog and drops the license of the old one
+func (r *PlantRepository) UpdatePlantImage(ctx context.Context, plantID uuid.UUID, imageURL models.AssetKey) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE plants
+		SET image_url = $2,
+			image_license = CASE WHEN image_url = $2 THEN image_license END,
+			image_attribution = CASE WHEN image_url = $2 THEN image_attribution END,
+			updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, plantID, imageURL)
+	if err != nil {
+		return fmt.Errorf("failed to update plant image: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("plant not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
 // GetAllUserPlantsForWateringCheck gets all user plants that need to be checked for watering
 func (r *PlantRepository) GetAllUserPlantsForWateringCheck(ctx context.Context) ([]*models.UserPlant, error) {
 	rows, err := r.db.QueryxContext(ctx, `
-		SELECT up.id, up.user_id, up.plant_id, up.location, up.last_watered, up.next_watering,
-			   p.name, p.scientific_name, p.description, p.image_url
+		SELECT up.id, up.user_id, up.plant_id, up.location, `+r.db.Read("up", "user_plants", "last_watered")+`, `+r.db.Read("up", "user_plants", "next_watering")+`,
+			   p.name, p.scientific_name, p.description, p.image_url, u.language, u.watering_reminder_channel,
+			   ol.latitude, ol.longitude, u.current_home_id, u.timezone, u.notification_hour, u.watering_reminded_on,
+			   h.id, h.name, h.timezone, h.latitude, h.longitude, h.outdoor_rooms
 		FROM user_plants up
 		JOIN plants p ON up.plant_id = p.id
-		WHERE up.next_watering IS NOT NULL
-		ORDER BY up.next_watering ASC
+		JOIN users u ON up.user_id = u.id
+		LEFT JOIN user_outdoor_locations ol ON ol.user_id = up.user_id AND ol.location = up.location
+		LEFT JOIN homes h ON h.id = up.home_id
+		WHERE `+r.db.Read("up", "user_plants", "next_watering")+` IS NOT NULL AND u.anonymized_at IS NULL AND u.deleted_at IS NULL
+		ORDER BY `+r.db.Read("up", "user_plants", "next_watering")+` ASC
 	`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get plants for watering check: %w", err)
@@ -534,14 +929,42 @@ func (r *PlantRepository) GetAllUserPlantsForWateringCheck(ctx context.Context)
 	for rows.Next() {
 		var userPlant models.UserPlant
 		var plantName, scientificName, description, imageURL string
+		var latitude, longitude sql.NullFloat64
+		var homeID uuid.NullUUID
+		var homeName, homeTimezone sql.NullString
+		var home models.Home
 		err := rows.Scan(
 			&userPlant.ID, &userPlant.UserID, &userPlant.PlantID, &userPlant.Location,
 			&userPlant.LastWatered, &userPlant.NextWatering,
-			&plantName, &scientificName, &description, &imageURL,
+			&plantName, &scientificName, &description, &imageURL, &userPlant.UserLanguage,
+			&userPlant.UserReminderChannel, &latitude, &longitude, &userPlant.UserCurrentHomeID,
+			&userPlant.UserTimezone, &userPlant.UserNotificationHour, &userPlant.UserWateringRemindedOn,
+			&homeID, &homeName, &homeTimezone, &home.Latitude, &home.Longitude, &home.OutdoorRooms,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user plant: %w", err)
 		}
+		if homeID.Valid {
+			home.ID, home.UserID, home.Name, home.Timezone = homeID.UUID, userPlant.UserID, homeName.String, homeTimezone.String
+			userPlant.HomeID = &home.ID
+			userPlant.Home = &home
+		}
+		if latitude.Valid && longitude.Valid && userPlant.Location != nil {
+			userPlant.OutdoorLocation = &models.OutdoorLocation{
+				UserID:    userPlant.UserID,
+				Location:  *userPlant.Location,
+				Latitude:  latitude.Float64,
+				Longitude: longitude.Float64,
+			}
+		} else if userPlant.Home != nil && home.Latitude != nil && userPlant.Location != nil && home.HasOutdoorRoom(*userPlant.Location) {
+			// Outdoor rooms of a home follow the weather at the home
+			userPlant.OutdoorLocation = &models.OutdoorLocation{
+				UserID:    userPlant.UserID,
+				Location:  *userPlant.Location,
+				Latitude:  *home.Latitude,
+				Longitude: *home.Longitude,
+			}
+		}
 		
 		// Create a Plant model with minimal fields for the notification
 		userPlant.Plant = &models.Plant{
@@ -549,7 +972,7 @@ func (r *PlantRepository) GetAllUserPlantsForWateringCheck(ctx context.Context)
 			Name:          plantName,
 			ScientificName: scientificName,
 			Description:   description,
-			ImageURL:      imageURL,
+			ImageURL:      models.AssetKey(imageURL),
 		}
 		userPlants = append(userPlants, &userPlant)
 	}
@@ -559,4 +982,134 @@ func (r *PlantRepository) GetAllUserPlantsForWateringCheck(ctx context.Context)
 	}
 
 	return userPlants, nil
-}
\ No newline at end of file
+}
+
+// GetPlantsWithCareReviewedBefore gets plants whose care instructions were never reviewed or last reviewed before the given time
+func (r *PlantRepository) GetPlantsWithCareReviewedBefore(ctx context.Context, reviewedBefore time.Time) ([]*models.Plant, error) {
+	rows, err := r.db.QueryxContext(ctx, `
+		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id, p.family, p.pet_friendly,
+			   p.created_at, p.updated_at,
+			   c.id as "care_instructions.id", `+r.db.Read("c", "care_instructions", "watering_frequency")+` as "care_instructions.watering_frequency",
+			   c.sunlight as "care_instructions.sunlight", c.min_temperature, c.max_temperature,
+			   c.humidity as "care_instructions.humidity", c.soil_type as "care_instructions.soil_type",
+			   `+r.db.Read("c", "care_instructions", "fertilizer_frequency")+` as "care_instructions.fertilizer_frequency",
+			   c.additional_notes as "care_instructions.additional_notes",
+			   c.source_url, c.source_author, c.last_reviewed_at
+		FROM plants p
+		JOIN care_instructions c ON p.care_instructions_id = c.id
+		WHERE p.deleted_at IS NULL AND (c.last_reviewed_at IS NULL OR c.last_reviewed_at < $1)
+		ORDER BY c.last_reviewed_at ASC NULLS FIRST, p.name
+	`, reviewedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plants with stale care instructions: %w", err)
+	}
+	defer rows.Close()
+
+	var plants []*models.Plant
+	for rows.Next() {
+		var plant models.Plant
+		var careInstructions models.CareInstructions
+		var minTemp, maxTemp int
+
+		err := rows.Scan(
+			&plant.ID, &plant.Name, &plant.ScientificName, &plant.Description, &plant.ImageURL,
+			&plant.Price, &plant.ShopID, &plant.Family, &plant.PetFriendly, &plant.CreatedAt, &plant.UpdatedAt,
+			&careInstructions.ID, &careInstructions.WateringFrequency, &careInstructions.Sunlight,
+			&minTemp, &maxTemp, &careInstructions.Humidity, &careInstructions.SoilType,
+			&careInstructions.FertilizerFrequency, &careInstructions.AdditionalNotes,
+			&careInstructions.SourceURL, &careInstructions.SourceAuthor, &careInstructions.LastReviewedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan plant: %w", err)
+		}
+
+		careInstructions.Temperature = models.TemperatureRange{
+			Min: minTemp,
+			Max: maxTemp,
+		}
+		plant.CareInstructions = careInstructions
+		plants = append(plants, &plant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating plants: %w", err)
+	}
+
+	return plants, nil
+}
+
+// insertCareInstructions creates care instructions in a transaction and sets their ID and timestamps
+func insertCareInstructions(ctx context.Context, d *db.DB, tx *sqlx.Tx, careInstructions *models.CareInstructions) error {
+	columns, values := d.Insert("care_instructions",
+		[]string{
+			"watering_frequency", "sunlight", "min_temperature", "max_temperature",
+			"humidity", "soil_type", "fertilizer_frequency", "additional_notes",
+			"source_url", "source_author", "last_reviewed_at",
+			"watering_frequency_min", "watering_frequency_max",
+		},
+		[]string{"$1", "$2", "$3", "$4", "$5", "$6", "$7", "$8", "$9", "$10", "$11", "$12", "$13"})
+	err := tx.QueryRowxContext(ctx, `
+		INSERT INTO care_instructions (`+columns+`)
+		VALUES (`+values+`)
+		RETURNING id, created_at, updated_at
+	`,
+		careInstructions.WateringFrequency,
+		careInstructions.Sunlight,
+		careInstructions.Temperature.Min,
+		careInstructions.Temperature.Max,
+		careInstructions.Humidity,
+		careInstructions.SoilType,
+		careInstructions.FertilizerFrequency,
+		careInstructions.AdditionalNotes,
+		careInstructions.SourceURL,
+		careInstructions.SourceAuthor,
+		careInstructions.LastReviewedAt,
+		careInstructions.WateringFrequencyMin,
+		careInstructions.WateringFrequencyMax,
+	).Scan(
+		&careInstructions.ID,
+		&careInstructions.CreatedAt,
+		&careInstructions.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create care instructions: %w", err)
+	}
+	return nil
+}
+
+// updateCareInstructions replaces the care instructions with the ID of careInstructions in a
+// transaction and sets their timestamps
+func updateCareInstructions(ctx context.Context, d *db.DB, tx *sqlx.Tx, careInstructions *models.CareInstructions) error {
+	err := tx.QueryRowxContext(ctx, `
+		UPDATE care_instructions
+		SET `+d.Assign("care_instructions", "watering_frequency", "$2")+`, sunlight = $3,
+			min_temperature = $4, max_temperature = $5, humidity = $6, soil_type = $7,
+			`+d.Assign("care_instructions", "fertilizer_frequency", "$8")+`, additional_notes = $9,
+			source_url = $10, source_author = $11, last_reviewed_at = $12,
+			watering_frequency_min = $13, watering_frequency_max = $14, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`,
+		careInstructions.ID,
+		careInstructions.WateringFrequency,
+		careInstructions.Sunlight,
+		careInstructions.Temperature.Min,
+		careInstructions.Temperature.Max,
+		careInstructions.Humidity,
+		careInstructions.SoilType,
+		careInstructions.FertilizerFrequency,
+		careInstructions.AdditionalNotes,
+		careInstructions.SourceURL,
+		careInstructions.SourceAuthor,
+		careInstructions.LastReviewedAt,
+		careInstructions.WateringFrequencyMin,
+		careInstructions.WateringFrequencyMax,
+	).Scan(
+		&careInstructions.CreatedAt,
+		&careInstructions.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update care instructions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantReviewRepository is the implementation of the plant review repository
+type PlantReviewRepository struct {
+	db *db.DB
+}
+
+// NewPlantReviewRepository creates a new plant review repository
+func NewPlantReviewRepository(db *db.DB) *PlantReviewRepository {
+	return &PlantReviewRepository{
+		db: db.Repository("plant_review"),
+	}
+}
+
+// ListByStatus gets the catalog plants in a review state, the ones that entered it first first
+func (r *PlantReviewRepository) ListByStatus(ctx context.Context, status models.PlantStatus) ([]*models.Plant, error) {
+	plants := []*models.Plant{}
+	err := r.db.SelectContext(ctx, &plants, `
+		SELECT id, name, scientific_name, description, image_url, species_id, status, status_changed_at,
+			   created_at, updated_at
+		FROM plants
+		WHERE deleted_at IS NULL AND status = $1
+		ORDER BY status_changed_at, id
+	`, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plants by status: %w", err)
+	}
+	return plants, nil
+}
+
+// SetStatus moves a catalog plant from one review state to another
+func (r *PlantReviewRepository) SetStatus(ctx context.Context, plantID uuid.UUID, from models.PlantStatus, to models.PlantStatus) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE plants
+		SET status = $3, status_changed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $2 AND deleted_at IS NULL
+	`, plantID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to set plant status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("plant in status %s not found: %w", from, sql.ErrNoRows)
+	}
+	return nil
+}
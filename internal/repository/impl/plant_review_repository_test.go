@@ -0,0 +1,62 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlantReviewRepository_ListByStatus(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantReviewRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	plantID := uuid.New()
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("FROM plants WHERE deleted_at IS NULL AND status = \\$1 ORDER BY status_changed_at").
+		WithArgs(models.PlantStatusInReview).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "scientific_name", "description", "image_url", "species_id", "status", "status_changed_at", "created_at", "updated_at"}).
+			AddRow(plantID, "Monstera", "Monstera deliciosa", "Swiss cheese plant", "", nil, "IN_REVIEW", now, now, now))
+
+	plants, err := repo.ListByStatus(context.Background(), models.PlantStatusInReview)
+	assert.NoError(t, err)
+	if assert.Len(t, plants, 1) {
+		assert.Equal(t, models.PlantStatusInReview, plants[0].Status)
+		assert.Equal(t, now, *plants[0].StatusChangedAt)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlantReviewRepository_SetStatus(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantReviewRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	plantID := uuid.New()
+	mock.ExpectExec("UPDATE plants SET status = \\$3, status_changed_at = NOW\\(\\)").
+		WithArgs(plantID, models.PlantStatusInReview, models.PlantStatusPublished).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.SetStatus(context.Background(), plantID, models.PlantStatusInReview, models.PlantStatusPublished))
+
+	// A plant moved by someone else in the meantime is not found in its old state
+	mock.ExpectExec("UPDATE plants SET status = \\$3").
+		WithArgs(plantID, models.PlantStatusInReview, models.PlantStatusPublished).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err = repo.SetStatus(context.Background(), plantID, models.PlantStatusInReview, models.PlantStatusPublished)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
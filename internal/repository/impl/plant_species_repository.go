@@ -0,0 +1,203 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantSpeciesRepository is the implementation of the plant species repository
+type PlantSpeciesRepository struct {
+	db *db.DB
+}
+
+// NewPlantSpeciesRepository creates a new plant species repository
+func NewPlantSpeciesRepository(db *db.DB) *PlantSpeciesRepository {
+	return &PlantSpeciesRepository{
+		db: db.Repository("plant_species"),
+	}
+}
+
+// Create creates a species with its default care instructions
+func (r *PlantSpeciesRepository) Create(ctx context.Context, species *models.PlantSpecies) error {
+	// Begin a transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Create the default care instructions
+	if err := insertCareInstructions(ctx, r.db, tx, &species.CareInstructions); err != nil {
+		return err
+	}
+
+	// Create the species
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO plant_species (scientific_name, family, care_instructions_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`, species.ScientificName, species.Family, species.CareInstructions.ID).
+		Scan(&species.ID, &species.CreatedAt, &species.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create species: %w", err)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// speciesQuery selects species with their default care instructions and the number of their cultivars
+func (r *PlantSpeciesRepository) speciesQuery(where string) string {
+	return `
+		SELECT s.id, s.scientific_name, s.family, s.created_at, s.updated_at,
+			   (SELECT COUNT(*) FROM plants p WHERE p.species_id = s.id AND p.deleted_at IS NULL),
+			   c.id, ` + r.db.Read("c", "care_instructions", "watering_frequency") + `, c.sunlight, c.min_temperature, c.max_temperature,
+			   c.humidity, c.soil_type, ` + r.db.Read("c", "care_instructions", "fertilizer_frequency") + `, c.additional_notes,
+			   c.source_url, c.source_author, c.last_reviewed_at, c.created_at, c.updated_at,
+			   c.watering_frequency_min, c.watering_frequency_max
+		FROM plant_species s
+		JOIN care_instructions c ON s.care_instructions_id = c.id
+		` + where
+}
+
+// scanSpecies scans a row of speciesQuery
+func scanSpecies(scan func(dest ...interface{}) error) (*models.PlantSpecies, error) {
+	var species models.PlantSpecies
+	care := &species.CareInstructions
+	err := scan(
+		&species.ID, &species.ScientificName, &species.Family, &species.CreatedAt, &species.UpdatedAt,
+		&species.CultivarCount,
+		&care.ID, &care.WateringFrequency, &care.Sunlight, &care.Temperature.Min, &care.Temperature.Max,
+		&care.Humidity, &care.SoilType, &care.FertilizerFrequency, &care.AdditionalNotes,
+		&care.SourceURL, &care.SourceAuthor, &care.LastReviewedAt, &care.CreatedAt, &care.UpdatedAt,
+		&care.WateringFrequencyMin, &care.WateringFrequencyMax,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &species, nil
+}
+
+// GetByID gets a species with its default care instructions
+func (r *PlantSpeciesRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PlantSpecies, error) {
+	species, err := scanSpecies(r.db.QueryRowxContext(ctx, r.speciesQuery(`WHERE s.id = $1`), id).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("species not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get species: %w", err)
+	}
+	return species, nil
+}
+
+// GetByScientificName gets a species by its scientific name, compared case-insensitively
+func (r *PlantSpeciesRepository) GetByScientificName(ctx context.Context, scientificName string) (*models.PlantSpecies, error) {
+	species, err := scanSpecies(r.db.QueryRowxContext(ctx, r.speciesQuery(`WHERE LOWER(s.scientific_name) = LOWER($1)`), scientificName).Scan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("species not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get species: %w", err)
+	}
+	return species, nil
+}
+
+// List gets all species ordered by scientific name
+func (r *PlantSpeciesRepository) List(ctx context.Context) ([]*models.PlantSpecies, error) {
+	rows, err := r.db.QueryxContext(ctx, r.speciesQuery(`ORDER BY s.scientific_name`))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list species: %w", err)
+	}
+	defer rows.Close()
+
+	speciesList := []*models.PlantSpecies{}
+	for rows.Next() {
+		species, err := scanSpecies(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan species: %w", err)
+		}
+		speciesList = append(speciesList, species)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating species: %w", err)
+	}
+	return speciesList, nil
+}
+
+// Update replaces a species and its default care instructions, and rewrites the care instructions
+// of its cultivars with their overrides applied to the new defaults
+func (r *PlantSpeciesRepository) Update(ctx context.Context, species *models.PlantSpecies) error {
+	// Begin a transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Update the species
+	err = tx.QueryRowxContext(ctx, `
+		UPDATE plant_species
+		SET scientific_name = $2, family = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING care_instructions_id, created_at, updated_at
+	`, species.ID, species.ScientificName, species.Family).
+		Scan(&species.CareInstructions.ID, &species.CreatedAt, &species.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("species not found: %w", err)
+		}
+		return fmt.Errorf("failed to update species: %w", err)
+	}
+
+	// Update its default care instructions
+	if err := updateCareInstructions(ctx, r.db, tx, &species.CareInstructions); err != nil {
+		return err
+	}
+
+	// Resolve the care instructions of its cultivars again, removed ones included so they stay
+	// consistent in the collections that keep them
+	var cultivars []struct {
+		CareInstructionsID uuid.UUID             `db:"care_instructions_id"`
+		CareOverrides      *models.CareOverrides `db:"care_overrides"`
+		Deleted            bool                  `db:"deleted"`
+	}
+	err = tx.SelectContext(ctx, &cultivars, `
+		SELECT care_instructions_id, care_overrides, deleted_at IS NOT NULL AS deleted
+		FROM plants
+		WHERE species_id = $1
+	`, species.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get cultivars: %w", err)
+	}
+
+	species.CultivarCount = 0
+	for _, cultivar := range cultivars {
+		var overrides models.CareOverrides
+		if cultivar.CareOverrides != nil {
+			overrides = *cultivar.CareOverrides
+		}
+		resolved := overrides.Apply(species.CareInstructions)
+		resolved.ID = cultivar.CareInstructionsID
+		if err := updateCareInstructions(ctx, r.db, tx, &resolved); err != nil {
+			return fmt.Errorf("failed to resolve the care instructions of a cultivar: %w", err)
+		}
+		if !cultivar.Deleted {
+			species.CultivarCount++
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlantSpeciesRepository_Update_ResolvesCultivars(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantSpeciesRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	speciesID, speciesCareID, cultivarCareID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	minWatering, maxWatering := 5, 14
+	species := &models.PlantSpecies{
+		ID:             speciesID,
+		ScientificName: "Monstera deliciosa",
+		CareInstructions: models.CareInstructions{
+			WateringFrequency:    7,
+			WateringFrequencyMin: &minWatering,
+			WateringFrequencyMax: &maxWatering,
+			Sunlight:             models.SunlightLevelMedium,
+			Temperature:          models.TemperatureRange{Min: 18, Max: 27},
+			Humidity:             models.HumidityLevelHigh,
+			SoilType:             "Aroid mix",
+			FertilizerFrequency:  30,
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE plant_species").
+		WithArgs(speciesID, "Monstera deliciosa", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"care_instructions_id", "created_at", "updated_at"}).AddRow(speciesCareID, now, now))
+	mock.ExpectQuery("UPDATE care_instructions").
+		WithArgs(speciesCareID, 7, models.SunlightLevelMedium, 18, 27, models.HumidityLevelHigh, "Aroid mix", 30, "", nil, nil, nil, 5, 14).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+	mock.ExpectQuery("SELECT care_instructions_id, care_overrides").
+		WithArgs(speciesID).
+		WillReturnRows(sqlmock.NewRows([]string{"care_instructions_id", "care_overrides", "deleted"}).
+			AddRow(cultivarCareID, []byte(`{"sunlight":"HIGH"}`), false))
+
+	// The cultivar keeps its brighter light and inherits the rest
+	mock.ExpectQuery("UPDATE care_instructions").
+		WithArgs(cultivarCareID, 7, models.SunlightLevelHigh, 18, 27, models.HumidityLevelHigh, "Aroid mix", 30, "", nil, nil, nil, 5, 14).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+	mock.ExpectCommit()
+
+	err = repo.Update(context.Background(), species)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, species.CultivarCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,87 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantStatsRepository is the implementation of the plant stats repository
+type PlantStatsRepository struct {
+	db *db.DB
+}
+
+// NewPlantStatsRepository creates a new plant stats repository
+func NewPlantStatsRepository(db *db.DB) *PlantStatsRepository {
+	return &PlantStatsRepository{
+		db: db.Repository("plant_stats"),
+	}
+}
+
+// Refresh aggregates the statistics of every catalog plant: the average interval of waterings
+// logged since wateringSince, and the share of plants owned since before activeSince that were
+// watered since then. It returns the number of plants aggregated.
+func (r *PlantStatsRepository) Refresh(ctx context.Context, wateringSince time.Time, activeSince time.Time, computedAt time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		WITH intervals AS (
+			SELECT plant_id,
+				   EXTRACT(EPOCH FROM occurred_at - LAG(occurred_at) OVER (PARTITION BY user_id, plant_id ORDER BY occurred_at)) / 86400 AS days
+			FROM plant_events
+			WHERE type = $1 AND occurred_at >= $2
+		), watering AS (
+			SELECT plant_id, AVG(days) AS interval_days
+			FROM intervals
+			WHERE days IS NOT NULL
+			GROUP BY plant_id
+		), owners AS (
+			SELECT up.plant_id, COUNT(*) AS owners,
+				   COUNT(*) FILTER (WHERE up.created_at <= $3) AS established,
+				   COUNT(*) FILTER (WHERE up.created_at <= $3 AND `+r.db.Read("up", "user_plants", "last_watered")+` >= $3) AS watered
+			FROM user_plants up
+			GROUP BY up.plant_id
+		)
+		INSERT INTO plant_stats (plant_id, owners, watering_interval_days, survival_rate, computed_at)
+		SELECT p.id, COALESCE(o.owners, 0), ROUND(w.interval_days::numeric, 1),
+			   CASE WHEN o.established > 0 THEN ROUND(o.watered::numeric / o.established, 3) END, $4
+		FROM plants p
+		LEFT JOIN owners o ON o.plant_id = p.id
+		LEFT JOIN watering w ON w.plant_id = p.id
+		ON CONFLICT (plant_id) DO UPDATE
+		SET owners = EXCLUDED.owners, watering_interval_days = EXCLUDED.watering_interval_days,
+			survival_rate = EXCLUDED.survival_rate, computed_at = EXCLUDED.computed_at
+	`, models.PlantEventTypeWatered, wateringSince, activeSince, computedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh plant stats: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rows), nil
+}
+
+// GetByPlant gets the statistics of a plant; a plant not aggregated yet has none
+func (r *PlantStatsRepository) GetByPlant(ctx context.Context, plantID uuid.UUID) (*models.PlantStats, error) {
+	var stats models.PlantStats
+	err := r.db.GetContext(ctx, &stats, `
+		SELECT p.id AS plant_id, COALESCE(s.owners, 0) AS owners, s.watering_interval_days,
+			   s.survival_rate, s.computed_at
+		FROM plants p
+		LEFT JOIN plant_stats s ON s.plant_id = p.id
+		WHERE p.id = $1
+	`, plantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("plant not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get plant stats: %w", err)
+	}
+	return &stats, nil
+}
@@ -0,0 +1,78 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlantStatsRepository_Refresh(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantStatsRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	now := time.Date(2024, time.May, 13, 2, 0, 0, 0, time.UTC)
+	wateringSince, activeSince := now.AddDate(0, 0, -180), now.AddDate(0, 0, -30)
+	mock.ExpectExec("INSERT INTO plant_stats (.+) FROM plants p (.+) ON CONFLICT \\(plant_id\\) DO UPDATE").
+		WithArgs(models.PlantEventTypeWatered, wateringSince, activeSince, now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	aggregated, err := repo.Refresh(context.Background(), wateringSince, activeSince, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, aggregated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlantStatsRepository_GetByPlant(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantStatsRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	plantID, newPlantID, missingID := uuid.New(), uuid.New(), uuid.New()
+	computedAt := time.Date(2024, time.May, 13, 2, 0, 0, 0, time.UTC)
+	columns := []string{"plant_id", "owners", "watering_interval_days", "survival_rate", "computed_at"}
+	mock.ExpectQuery("SELECT (.+) FROM plants p LEFT JOIN plant_stats s").
+		WithArgs(plantID).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(plantID, 12, []byte("6.5"), []byte("0.833"), computedAt))
+	mock.ExpectQuery("SELECT (.+) FROM plants p LEFT JOIN plant_stats s").
+		WithArgs(newPlantID).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(newPlantID, 0, nil, nil, nil))
+	mock.ExpectQuery("SELECT (.+) FROM plants p LEFT JOIN plant_stats s").
+		WithArgs(missingID).
+		WillReturnError(sql.ErrNoRows)
+
+	stats, err := repo.GetByPlant(context.Background(), plantID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, 12, stats.Owners)
+		assert.Equal(t, 6.5, *stats.WateringIntervalDays)
+		assert.Equal(t, 0.833, *stats.SurvivalRate)
+		assert.Equal(t, computedAt, *stats.ComputedAt)
+	}
+
+	stats, err = repo.GetByPlant(context.Background(), newPlantID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, stats) {
+		assert.Zero(t, stats.Owners)
+		assert.Nil(t, stats.WateringIntervalDays)
+		assert.Nil(t, stats.ComputedAt)
+	}
+
+	_, err = repo.GetByPlant(context.Background(), missingID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,201 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PlantTaxonomyRepository is the implementation of the plant taxonomy repository
+type PlantTaxonomyRepository struct {
+	db *db.DB
+}
+
+// NewPlantTaxonomyRepository creates a new plant taxonomy repository
+func NewPlantTaxonomyRepository(db *db.DB) *PlantTaxonomyRepository {
+	return &PlantTaxonomyRepository{
+		db: db.Repository("plant_taxonomy"),
+	}
+}
+
+// ListCategories gets all categories by name with the number of published plants in each
+func (r *PlantTaxonomyRepository) ListCategories(ctx context.Context) ([]*models.Category, error) {
+	categories := []*models.Category{}
+	err := r.db.SelectContext(ctx, &categories, `
+		SELECT cat.id, cat.slug, cat.name, cat.created_at, cat.updated_at,
+			   COUNT(p.id) AS plant_count
+		FROM categories cat
+		LEFT JOIN plant_categories pc ON pc.category_id = cat.id
+		LEFT JOIN plants p ON p.id = pc.plant_id AND p.deleted_at IS NULL AND p.status = 'PUBLISHED'
+		GROUP BY cat.id
+		ORDER BY cat.name, cat.slug
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	return categories, nil
+}
+
+// GetCategoryBySlug gets a category by its slug
+func (r *PlantTaxonomyRepository) GetCategoryBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	var category models.Category
+	err := r.db.GetContext(ctx, &category, `
+		SELECT id, slug, name, created_at, updated_at
+		FROM categories
+		WHERE slug = $1
+	`, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("category not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	return &category, nil
+}
+
+// CreateCategory creates a category
+func (r *PlantTaxonomyRepository) CreateCategory(ctx context.Context, category *models.Category) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO categories (slug, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`, category.Slug, category.Name).Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create category: %w", err)
+	}
+	return nil
+}
+
+// UpdateCategory updates the slug and name of a category
+func (r *PlantTaxonomyRepository) UpdateCategory(ctx context.Context, category *models.Category) error {
+	err := r.db.QueryRowxContext(ctx, `
+		UPDATE categories
+		SET slug = $2, name = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, category.ID, category.Slug, category.Name).Scan(&category.CreatedAt, &category.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("category not found: %w", err)
+		}
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+	return nil
+}
+
+// DeleteCategory deletes a category, taking it off its plants
+func (r *PlantTaxonomyRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("category not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// ListTags gets the tags of published plants, the most used first
+func (r *PlantTaxonomyRepository) ListTags(ctx context.Context) ([]*models.TagCount, error) {
+	tags := []*models.TagCount{}
+	err := r.db.SelectContext(ctx, &tags, `
+		SELECT t.name AS tag, COUNT(*) AS plant_count
+		FROM tags t
+		JOIN plant_tags pt ON pt.tag_id = t.id
+		JOIN plants p ON p.id = pt.plant_id
+		WHERE p.deleted_at IS NULL AND p.status = 'PUBLISHED'
+		GROUP BY t.name
+		ORDER BY plant_count DESC, t.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return tags, nil
+}
+
+// GetPlantTaxonomy gets the category slugs and tags of a plant
+func (r *PlantTaxonomyRepository) GetPlantTaxonomy(ctx context.Context, plantID uuid.UUID) (*models.PlantTaxonomy, error) {
+	var categories, tags pq.StringArray
+	err := r.db.QueryRowxContext(ctx, `
+		SELECT `+plantCategoriesColumn+`, `+plantTagsColumn+`
+		FROM plants p
+		WHERE p.id = $1
+	`, plantID).Scan(&categories, &tags)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("plant not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get plant taxonomy: %w", err)
+	}
+	return &models.PlantTaxonomy{Categories: categories, Tags: tags}, nil
+}
+
+// SetPlantTaxonomy replaces the categories and tags of a catalog plant, creating tags it does not know yet
+func (r *PlantTaxonomyRepository) SetPlantTaxonomy(ctx context.Context, plantID uuid.UUID, taxonomy *models.PlantTaxonomy) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the plant so it is not removed from the catalog meanwhile
+	var id uuid.UUID
+	err = tx.QueryRowxContext(ctx, `
+		SELECT id FROM plants WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, plantID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("plant not found: %w", err)
+		}
+		return fmt.Errorf("failed to get plant: %w", err)
+	}
+
+	// Replace the categories
+	if _, err := tx.ExecContext(ctx, `DELETE FROM plant_categories WHERE plant_id = $1`, plantID); err != nil {
+		return fmt.Errorf("failed to clear plant categories: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO plant_categories (plant_id, category_id)
+		SELECT $1, id FROM categories WHERE slug = ANY($2)
+	`, plantID, pq.StringArray(taxonomy.Categories))
+	if err != nil {
+		return fmt.Errorf("failed to set plant categories: %w", err)
+	}
+
+	// Create the new tags and replace the tags of the plant
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tags (name)
+		SELECT unnest($1::text[])
+		ON CONFLICT (name) DO NOTHING
+	`, pq.StringArray(taxonomy.Tags))
+	if err != nil {
+		return fmt.Errorf("failed to create tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM plant_tags WHERE plant_id = $1`, plantID); err != nil {
+		return fmt.Errorf("failed to clear plant tags: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO plant_tags (plant_id, tag_id)
+		SELECT $1, id FROM tags WHERE name = ANY($2)
+	`, plantID, pq.StringArray(taxonomy.Tags))
+	if err != nil {
+		return fmt.Errorf("failed to set plant tags: %w", err)
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlantTaxonomyRepository_ListCategories(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantTaxonomyRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("FROM categories cat LEFT JOIN plant_categories pc").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "name", "created_at", "updated_at", "plant_count"}).
+			AddRow(uuid.New(), "succulent", "Succulents", now, now, 4))
+
+	categories, err := repo.ListCategories(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, categories, 1) {
+		assert.Equal(t, "succulent", categories[0].Slug)
+		assert.Equal(t, 4, categories[0].PlantCount)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlantTaxonomyRepository_DeleteCategory_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantTaxonomyRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	categoryID := uuid.New()
+	mock.ExpectExec("DELETE FROM categories WHERE id = \\$1").
+		WithArgs(categoryID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, repo.DeleteCategory(context.Background(), categoryID), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlantTaxonomyRepository_SetPlantTaxonomy(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantTaxonomyRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	plantID := uuid.New()
+	taxonomy := &models.PlantTaxonomy{Categories: []string{"fern"}, Tags: []string{"pet-safe", "air-purifying"}}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM plants WHERE id = \\$1 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs(plantID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(plantID))
+	mock.ExpectExec("DELETE FROM plant_categories WHERE plant_id = \\$1").
+		WithArgs(plantID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO plant_categories \\(plant_id, category_id\\) SELECT \\$1, id FROM categories WHERE slug = ANY\\(\\$2\\)").
+		WithArgs(plantID, pq.StringArray{"fern"}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO tags \\(name\\) SELECT unnest\\(\\$1::text\\[\\]\\) ON CONFLICT \\(name\\) DO NOTHING").
+		WithArgs(pq.StringArray{"pet-safe", "air-purifying"}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM plant_tags WHERE plant_id = \\$1").
+		WithArgs(plantID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO plant_tags \\(plant_id, tag_id\\) SELECT \\$1, id FROM tags WHERE name = ANY\\(\\$2\\)").
+		WithArgs(plantID, pq.StringArray{"pet-safe", "air-purifying"}).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.SetPlantTaxonomy(context.Background(), plantID, taxonomy))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlantTaxonomyRepository_SetPlantTaxonomy_PlantNotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantTaxonomyRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	plantID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM plants").
+		WithArgs(plantID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	err = repo.SetPlantTaxonomy(context.Background(), plantID, &models.PlantTaxonomy{})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
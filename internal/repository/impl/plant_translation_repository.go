@@ -0,0 +1,107 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PlantTranslationRepository is the implementation of the plant translation repository
+type PlantTranslationRepository struct {
+	db *db.DB
+}
+
+// NewPlantTranslationRepository creates a new plant translation repository
+func NewPlantTranslationRepository(db *db.DB) *PlantTranslationRepository {
+	return &PlantTranslationRepository{
+		db: db.Repository("plant_translation"),
+	}
+}
+
+// List gets the translations of a plant by language
+func (r *PlantTranslationRepository) List(ctx context.Context, plantID uuid.UUID) ([]*models.PlantTranslation, error) {
+	translations := []*models.PlantTranslation{}
+	err := r.db.SelectContext(ctx, &translations, `
+		SELECT plant_id, language, name, description, additional_notes, created_at, updated_at
+		FROM plant_translations
+		WHERE plant_id = $1
+		ORDER BY language
+	`, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plant translations: %w", err)
+	}
+	return translations, nil
+}
+
+// GetForPlants gets the translations of plants into a language by plant ID; plants without a
+// translation are left out
+func (r *PlantTranslationRepository) GetForPlants(ctx context.Context, plantIDs []uuid.UUID, language models.Language) (map[uuid.UUID]*models.PlantTranslation, error) {
+	byPlant := make(map[uuid.UUID]*models.PlantTranslation)
+	if len(plantIDs) == 0 {
+		return byPlant, nil
+	}
+
+	ids := make([]string, 0, len(plantIDs))
+	for _, id := range plantIDs {
+		ids = append(ids, id.String())
+	}
+
+	var translations []*models.PlantTranslation
+	err := r.db.SelectContext(ctx, &translations, `
+		SELECT plant_id, language, name, description, additional_notes, created_at, updated_at
+		FROM plant_translations
+		WHERE plant_id = ANY($1::uuid[]) AND language = $2
+	`, pq.StringArray(ids), language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant translations: %w", err)
+	}
+	for _, translation := range translations {
+		byPlant[translation.PlantID] = translation
+	}
+	return byPlant, nil
+}
+
+// Upsert creates the translation of a plant into a language or replaces its fields
+func (r *PlantTranslationRepository) Upsert(ctx context.Context, translation *models.PlantTranslation) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO plant_translations (plant_id, language, name, description, additional_notes)
+		SELECT id, $2, $3, $4, $5
+		FROM plants
+		WHERE id = $1 AND deleted_at IS NULL
+		ON CONFLICT (plant_id, language) DO UPDATE
+		SET name = EXCLUDED.name, description = EXCLUDED.description,
+			additional_notes = EXCLUDED.additional_notes, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`, translation.PlantID, translation.Language, translation.Name, translation.Description, translation.AdditionalNotes).
+		Scan(&translation.CreatedAt, &translation.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("plant not found: %w", err)
+		}
+		return fmt.Errorf("failed to save plant translation: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the translation of a plant into a language
+func (r *PlantTranslationRepository) Delete(ctx context.Context, plantID uuid.UUID, language models.Language) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM plant_translations WHERE plant_id = $1 AND language = $2`, plantID, language)
+	if err != nil {
+		return fmt.Errorf("failed to delete plant translation: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("plant translation not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlantTranslationRepository_GetForPlants(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantTranslationRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	// No plants need no query
+	translations, err := repo.GetForPlants(context.Background(), nil, models.LanguageEnglish)
+	require.NoError(t, err)
+	assert.Empty(t, translations)
+
+	translated, untranslated := uuid.New(), uuid.New()
+	mock.ExpectQuery("SELECT plant_id, language, name, description, additional_notes, created_at, updated_at FROM plant_translations").
+		WithArgs(pq.StringArray{translated.String(), untranslated.String()}, models.LanguageEnglish).
+		WillReturnRows(sqlmock.NewRows([]string{"plant_id", "language", "name", "description", "additional_notes", "created_at", "updated_at"}).
+			AddRow(translated, "ENGLISH", "Money plant", nil, nil, time.Now(), time.Now()))
+
+	translations, err = repo.GetForPlants(context.Background(), []uuid.UUID{translated, untranslated}, models.LanguageEnglish)
+	require.NoError(t, err)
+	require.Len(t, translations, 1)
+	assert.Equal(t, "Money plant", *translations[translated].Name)
+	assert.Nil(t, translations[translated].Description)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPlantTranslationRepository_Upsert_DeletedPlant(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewPlantTranslationRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	// A plant removed from the catalog inserts no translation
+	name := "Money plant"
+	translation := &models.PlantTranslation{PlantID: uuid.New(), Language: models.LanguageEnglish, Name: &name}
+	mock.ExpectQuery("INSERT INTO plant_translations").
+		WithArgs(translation.PlantID, models.LanguageEnglish, &name, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}))
+
+	err = repo.Upsert(context.Background(), translation)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,119 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// QuizRepository is the implementation of the quiz repository
+type QuizRepository struct {
+	db *db.DB
+}
+
+// NewQuizRepository creates a new quiz repository
+func NewQuizRepository(db *db.DB) *QuizRepository {
+	return &QuizRepository{
+		db: db.Repository("quiz"),
+	}
+}
+
+// GetDay gets the questions of the quiz of a day
+func (r *QuizRepository) GetDay(ctx context.Context, date time.Time) (models.QuizQuestionSet, error) {
+	var questions models.QuizQuestionSet
+	err := r.db.GetContext(ctx, &questions, `
+		SELECT questions FROM quiz_days WHERE quiz_date = $1::date
+	`, date.Format(time.DateOnly))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("quiz not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get quiz: %w", err)
+	}
+	return questions, nil
+}
+
+// CreateDay stores the questions of the quiz of a day unless it has some already, and returns the
+// stored questions, so instances generating the quiz at the same time agree on it
+func (r *QuizRepository) CreateDay(ctx context.Context, date time.Time, questions models.QuizQuestionSet) (models.QuizQuestionSet, error) {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO quiz_days (quiz_date, questions)
+		VALUES ($1::date, $2)
+		ON CONFLICT (quiz_date) DO NOTHING
+	`, date.Format(time.DateOnly), questions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quiz: %w", err)
+	}
+	return r.GetDay(ctx, date)
+}
+
+// GetResult gets the result of a user for the quiz of a day
+func (r *QuizRepository) GetResult(ctx context.Context, userID uuid.UUID, date time.Time) (*models.QuizResult, error) {
+	var result models.QuizResult
+	err := r.db.GetContext(ctx, &result, `
+		SELECT quiz_date::text AS quiz_date, correct, total, points, streak, answers, created_at
+		FROM quiz_results
+		WHERE user_id = $1 AND quiz_date = $2::date
+	`, userID, date.Format(time.DateOnly))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("quiz result not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get quiz result: %w", err)
+	}
+	return &result, nil
+}
+
+// SaveResult saves the result of a user for the quiz of a day. The streak continues the one of the
+// day before, so it is counted in the same statement; a second answer to the quiz is not saved.
+func (r *QuizRepository) SaveResult(ctx context.Context, userID uuid.UUID, date time.Time, result *models.QuizResult) (bool, error) {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO quiz_results (user_id, quiz_date, correct, total, points, answers, streak)
+		VALUES ($1, $2::date, $3, $4, $5, $6, 1 + COALESCE((
+			SELECT streak FROM quiz_results WHERE user_id = $1 AND quiz_date = $2::date - 1
+		), 0))
+		ON CONFLICT (user_id, quiz_date) DO NOTHING
+		RETURNING streak, created_at
+	`, userID, date.Format(time.DateOnly), result.Correct, result.Total, result.Points, result.Answers).
+		Scan(&result.Streak, &result.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to save quiz result: %w", err)
+	}
+	result.Date = date.Format(time.DateOnly)
+	return true, nil
+}
+
+// GetLeaderboard gets the quiz scores since a day of a user and the users they follow, most points
+// first. A streak is current while its last quiz was answered today or yesterday.
+func (r *QuizRepository) GetLeaderboard(ctx context.Context, userID uuid.UUID, since time.Time, today time.Time) ([]*models.QuizLeaderboardEntry, error) {
+	entries := []*models.QuizLeaderboardEntry{}
+	err := r.db.SelectContext(ctx, &entries, `
+		SELECT u.id AS user_id, u.name,
+			COALESCE(SUM(q.points), 0) AS points,
+			COUNT(q.quiz_date) AS quizzes,
+			COALESCE((
+				SELECT s.streak FROM quiz_results s
+				WHERE s.user_id = u.id AND s.quiz_date >= $3::date - 1
+				ORDER BY s.quiz_date DESC
+				LIMIT 1
+			), 0) AS streak
+		FROM users u
+		LEFT JOIN quiz_results q ON q.user_id = u.id AND q.quiz_date >= $2::date
+		WHERE u.deleted_at IS NULL AND (u.id = $1 OR u.id IN (SELECT followee_id FROM user_follows WHERE follower_id = $1))
+		GROUP BY u.id
+		ORDER BY points DESC, u.name ASC, u.id ASC
+	`, userID, since.Format(time.DateOnly), today.Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiz leaderboard: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,72 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuizRepository_SaveResult(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewQuizRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID := uuid.New()
+	date := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	result := &models.QuizResult{Correct: 3, Total: 5, Points: 30, Answers: models.QuizAnswers{}}
+	mock.ExpectQuery(`INSERT INTO quiz_results (.+) 1 \+ COALESCE\(\(\s+SELECT streak FROM quiz_results WHERE user_id = \$1 AND quiz_date = \$2::date - 1\s+\), 0\)\)\s+ON CONFLICT \(user_id, quiz_date\) DO NOTHING`).
+		WithArgs(userID, "2026-05-10", 3, 5, 30, result.Answers).
+		WillReturnRows(sqlmock.NewRows([]string{"streak", "created_at"}).AddRow(4, time.Now()))
+	mock.ExpectQuery("INSERT INTO quiz_results").
+		WithArgs(userID, "2026-05-10", 3, 5, 30, result.Answers).
+		WillReturnRows(sqlmock.NewRows([]string{"streak", "created_at"}))
+
+	saved, err := repo.SaveResult(context.Background(), userID, date, result)
+	assert.NoError(t, err)
+	assert.True(t, saved)
+	assert.Equal(t, 4, result.Streak)
+	assert.Equal(t, "2026-05-10", result.Date)
+
+	// The user answered the quiz of the day already
+	saved, err = repo.SaveResult(context.Background(), userID, date, result)
+	assert.NoError(t, err)
+	assert.False(t, saved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuizRepository_GetLeaderboard(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewQuizRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID, friendID := uuid.New(), uuid.New()
+	since := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`WHERE u.deleted_at IS NULL AND \(u.id = \$1 OR u.id IN \(SELECT followee_id FROM user_follows WHERE follower_id = \$1\)\)`).
+		WithArgs(userID, "2026-05-04", "2026-05-10").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "points", "quizzes", "streak"}).
+			AddRow(friendID, "Анна", 120, 4, 3).
+			AddRow(userID, "Иван", 0, 0, 0))
+
+	entries, err := repo.GetLeaderboard(context.Background(), userID, since, today)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, friendID, entries[0].UserID)
+		assert.Equal(t, 120, entries[0].Points)
+		assert.Equal(t, 3, entries[0].Streak)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/anpanovv/planter/internal/db"
 	"github.com/anpanovv/planter/internal/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // RecommendationRepository is the implementation of the recommendation repository
@@ -19,18 +20,20 @@ type RecommendationRepository struct {
 // NewRecommendationRepository creates a new recommendation repository
 func NewRecommendationRepository(db *db.DB) *RecommendationRepository {
 	return &RecommendationRepository{
-		db: db,
+		db: db.Repository("recommendation"),
 	}
 }
 
 // SaveQuestionnaire saves a plant questionnaire
 func (r *RecommendationRepository) SaveQuestionnaire(ctx context.Context, questionnaire *models.PlantQuestionnaire) error {
 	err := r.db.QueryRowxContext(ctx, `
-		INSERT INTO plant_questionnaires (user_id, sunlight_preference, pet_friendly, care_level, preferred_location, additional_preferences)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO plant_questionnaires (user_id, sunlight_preference, pet_friendly, care_level, preferred_location, additional_preferences,
+			result_count, max_per_family, preferred_tags, has_children)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9::text[], '{}'), $10)
 		RETURNING id, created_at
 	`, questionnaire.UserID, questionnaire.SunlightPreference, questionnaire.PetFriendly, questionnaire.CareLevel,
-		questionnaire.PreferredLocation, questionnaire.AdditionalPreferences).
+		questionnaire.PreferredLocation, questionnaire.AdditionalPreferences, questionnaire.ResultCount, questionnaire.MaxPerFamily,
+		questionnaire.PreferredTags, questionnaire.HasChildren).
 		Scan(&questionnaire.ID, &questionnaire.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to save questionnaire: %w", err)
@@ -42,7 +45,8 @@ func (r *RecommendationRepository) SaveQuestionnaire(ctx context.Context, questi
 func (r *RecommendationRepository) GetQuestionnaire(ctx context.Context, id uuid.UUID) (*models.PlantQuestionnaire, error) {
 	var questionnaire models.PlantQuestionnaire
 	err := r.db.GetContext(ctx, &questionnaire, `
-		SELECT id, user_id, sunlight_preference, pet_friendly, care_level, preferred_location, additional_preferences, created_at
+		SELECT id, user_id, sunlight_preference, pet_friendly, care_level, preferred_location, additional_preferences,
+			   result_count, max_per_family, preferred_tags, has_children, created_at
 		FROM plant_questionnaires
 		WHERE id = $1
 	`, id)
@@ -58,10 +62,10 @@ func (r *RecommendationRepository) GetQuestionnaire(ctx context.Context, id uuid
 // SaveRecommendation saves a plant recommendation
 func (r *RecommendationRepository) SaveRecommendation(ctx context.Context, recommendation *models.PlantRecommendation) error {
 	err := r.db.QueryRowxContext(ctx, `
-		INSERT INTO plant_recommendations (questionnaire_id, plant_id, score, reasoning)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO plant_recommendations (questionnaire_id, plant_id, score, reasoning, explanation)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, created_at
-	`, recommendation.QuestionnaireID, recommendation.PlantID, recommendation.Score, recommendation.Reasoning).
+	`, recommendation.QuestionnaireID, recommendation.PlantID, recommendation.Score, recommendation.Reasoning, recommendation.Explanation).
 		Scan(&recommendation.ID, &recommendation.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to save recommendation: %w", err)
@@ -73,7 +77,7 @@ func (r *RecommendationRepository) SaveRecommendation(ctx context.Context, recom
 func (r *RecommendationRepository) GetRecommendations(ctx context.Context, questionnaireID uuid.UUID) ([]*models.PlantRecommendation, error) {
 	var recommendations []*models.PlantRecommendation
 	err := r.db.SelectContext(ctx, &recommendations, `
-		SELECT id, questionnaire_id, plant_id, score, reasoning, created_at
+		SELECT id, questionnaire_id, plant_id, score, reasoning, explanation, created_at
 		FROM plant_recommendations
 		WHERE questionnaire_id = $1
 		ORDER BY score DESC
@@ -87,14 +91,15 @@ func (r *RecommendationRepository) GetRecommendations(ctx context.Context, quest
 // GetRecommendedPlants gets all recommended plants for a questionnaire
 func (r *RecommendationRepository) GetRecommendedPlants(ctx context.Context, questionnaireID uuid.UUID) ([]*models.Plant, error) {
 	rows, err := r.db.QueryxContext(ctx, `
-		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id,
+		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, p.price, p.shop_id, p.family, p.pet_friendly,
 			   p.created_at, p.updated_at,
-			   c.id as "care_instructions.id", c.watering_frequency as "care_instructions.watering_frequency",
+			   c.id as "care_instructions.id", `+r.db.Read("c", "care_instructions", "watering_frequency")+` as "care_instructions.watering_frequency",
 			   c.sunlight as "care_instructions.sunlight", c.min_temperature, c.max_temperature,
 			   c.humidity as "care_instructions.humidity", c.soil_type as "care_instructions.soil_type",
-			   c.fertilizer_frequency as "care_instructions.fertilizer_frequency",
+			   `+r.db.Read("c", "care_instructions", "fertilizer_frequency")+` as "care_instructions.fertilizer_frequency",
 			   c.additional_notes as "care_instructions.additional_notes",
-			   pr.score, pr.reasoning
+			   c.source_url, c.source_author, c.last_reviewed_at,
+			   pr.explanation
 		FROM plants p
 		JOIN care_instructions c ON p.care_instructions_id = c.id
 		JOIN plant_recommendations pr ON p.id = pr.plant_id
@@ -111,16 +116,16 @@ func (r *RecommendationRepository) GetRecommendedPlants(ctx context.Context, que
 		var plant models.Plant
 		var careInstructions models.CareInstructions
 		var minTemp, maxTemp int
-		var score float64
-		var reasoning string
+		var explanation *models.RecommendationExplanation
 
 		err := rows.Scan(
 			&plant.ID, &plant.Name, &plant.ScientificName, &plant.Description, &plant.ImageURL,
-			&plant.Price, &plant.ShopID, &plant.CreatedAt, &plant.UpdatedAt,
+			&plant.Price, &plant.ShopID, &plant.Family, &plant.PetFriendly, &plant.CreatedAt, &plant.UpdatedAt,
 			&careInstructions.ID, &careInstructions.WateringFrequency, &careInstructions.Sunlight,
 			&minTemp, &maxTemp, &careInstructions.Humidity, &careInstructions.SoilType,
 			&careInstructions.FertilizerFrequency, &careInstructions.AdditionalNotes,
-			&score, &reasoning,
+			&careInstructions.SourceURL, &careInstructions.SourceAuthor, &careInstructions.LastReviewedAt,
+			&explanation,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan plant: %w", err)
@@ -131,6 +136,7 @@ func (r *RecommendationRepository) GetRecommendedPlants(ctx context.Context, que
 			Max: maxTemp,
 		}
 		plant.CareInstructions = careInstructions
+		plant.Recommendation = explanation
 		plants = append(plants, &plant)
 	}
 
@@ -164,9 +170,14 @@ func (r *RecommendationRepository) CreateChatSession(ctx context.Context, userID
 func (r *RecommendationRepository) GetChatSession(ctx context.Context, id uuid.UUID) (*models.ChatSession, error) {
 	var session models.ChatSession
 	err := r.db.GetContext(ctx, &session, `
-		SELECT id, user_id, title, created_at, updated_at, last_used
-		FROM chat_sessions
-		WHERE id = $1
+		SELECT s.id, s.user_id, s.title, s.created_at, s.updated_at, s.last_used,
+			s.escalation_status, s.escalation_reason, s.escalated_at, s.expert_id,
+			COALESCE(SUM(m.prompt_tokens), 0) AS prompt_tokens,
+			COALESCE(SUM(m.completion_tokens), 0) AS completion_tokens
+		FROM chat_sessions s
+		LEFT JOIN chat_messages m ON m.session_id = s.id
+		WHERE s.id = $1
+		GROUP BY s.id
 	`, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -181,10 +192,15 @@ func (r *RecommendationRepository) GetChatSession(ctx context.Context, id uuid.U
 func (r *RecommendationRepository) GetChatSessionsByUser(ctx context.Context, userID uuid.UUID) ([]*models.ChatSession, error) {
 	var sessions []*models.ChatSession
 	err := r.db.SelectContext(ctx, &sessions, `
-		SELECT id, user_id, title, created_at, updated_at, last_used
-		FROM chat_sessions
-		WHERE user_id = $1
-		ORDER BY last_used DESC
+		SELECT s.id, s.user_id, s.title, s.created_at, s.updated_at, s.last_used,
+			s.escalation_status, s.escalation_reason, s.escalated_at, s.expert_id,
+			COALESCE(SUM(m.prompt_tokens), 0) AS prompt_tokens,
+			COALESCE(SUM(m.completion_tokens), 0) AS completion_tokens
+		FROM chat_sessions s
+		LEFT JOIN chat_messages m ON m.session_id = s.id
+		WHERE s.user_id = $1
+		GROUP BY s.id
+		ORDER BY s.last_used DESC
 	`, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chat sessions: %w", err)
@@ -195,10 +211,12 @@ func (r *RecommendationRepository) GetChatSessionsByUser(ctx context.Context, us
 // SaveChatMessage saves a chat message
 func (r *RecommendationRepository) SaveChatMessage(ctx context.Context, message *models.ChatMessage) error {
 	err := r.db.QueryRowxContext(ctx, `
-		INSERT INTO chat_messages (session_id, user_id, role, content)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO chat_messages (session_id, user_id, role, content, language, prompt_tokens, completion_tokens, expert_id,
+			image_url, image_description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at
-	`, message.SessionID, message.UserID, message.Role, message.Content).
+	`, message.SessionID, message.UserID, message.Role, message.Content, message.Language,
+		message.PromptTokens, message.CompletionTokens, message.ExpertID, message.ImageURL, message.ImageDescription).
 		Scan(&message.ID, &message.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to save chat message: %w", err)
@@ -210,7 +228,8 @@ func (r *RecommendationRepository) SaveChatMessage(ctx context.Context, message
 func (r *RecommendationRepository) GetChatMessages(ctx context.Context, sessionID uuid.UUID) ([]*models.ChatMessage, error) {
 	var messages []*models.ChatMessage
 	err := r.db.SelectContext(ctx, &messages, `
-		SELECT id, session_id, user_id, role, content, created_at
+		SELECT id, session_id, user_id, role, content, language, prompt_tokens, completion_tokens, expert_id,
+			image_url, image_description, created_at
 		FROM chat_messages
 		WHERE session_id = $1
 		ORDER BY created_at ASC
@@ -234,9 +253,197 @@ func (r *RecommendationRepository) UpdateChatSessionLastUsed(ctx context.Context
 	return nil
 }
 
+// RenameChatSession sets the title of a chat session
+func (r *RecommendationRepository) RenameChatSession(ctx context.Context, sessionID uuid.UUID, title string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE chat_sessions
+		SET title = $2, updated_at = NOW()
+		WHERE id = $1
+	`, sessionID, title)
+	if err != nil {
+		return fmt.Errorf("failed to rename chat session: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("chat session not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// ReplaceChatSessionTitle sets the title of a chat session unless it was changed from oldTitle, so
+// a title generated for the session does not overwrite one the user gave it meanwhile
+func (r *RecommendationRepository) ReplaceChatSessionTitle(ctx context.Context, sessionID uuid.UUID, oldTitle string, newTitle string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE chat_sessions
+		SET title = $3, updated_at = NOW()
+		WHERE id = $1 AND title = $2
+	`, sessionID, oldTitle, newTitle)
+	if err != nil {
+		return false, fmt.Errorf("failed to update chat session title: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// DeleteChatSession deletes a chat session; its messages are deleted along with it
+func (r *RecommendationRepository) DeleteChatSession(ctx context.Context, sessionID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM chat_sessions WHERE id = $1`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete chat session: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("chat session not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// GetChatContext gets the conversation context of a chat session
+func (r *RecommendationRepository) GetChatContext(ctx context.Context, sessionID uuid.UUID) (*models.ChatContext, error) {
+	var chatContext models.ChatContext
+	err := r.db.GetContext(ctx, &chatContext, `
+		SELECT id, system_prompt, summary, summarized_through
+		FROM chat_sessions
+		WHERE id = $1
+	`, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("chat session not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get chat context: %w", err)
+	}
+	return &chatContext, nil
+}
+
+// SaveChatContext saves the conversation context of a chat session
+func (r *RecommendationRepository) SaveChatContext(ctx context.Context, chatContext *models.ChatContext) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE chat_sessions
+		SET system_prompt = $2, summary = $3, summarized_through = $4, updated_at = NOW()
+		WHERE id = $1
+	`, chatContext.SessionID, chatContext.SystemPrompt, chatContext.Summary, chatContext.SummarizedThrough)
+	if err != nil {
+		return fmt.Errorf("failed to save chat context: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to save chat context: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("chat session not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
 // SaveDetailedQuestionnaire saves a detailed plant questionnaire
 func (r *RecommendationRepository) SaveDetailedQuestionnaire(ctx context.Context, questionnaire *models.DetailedQuestionnaireRequest) (*models.PlantQuestionnaire, error) {
 	// This method is not needed as we're using the standard SaveQuestionnaire method
 	// with additional preferences text that includes all the detailed information
 	return nil, fmt.Errorf("not implemented: use SaveQuestionnaire instead")
 }
+
+// GetChatUsage gets the number of chat sessions and messages of a user and the tokens spent on them
+func (r *RecommendationRepository) GetChatUsage(ctx context.Context, userID uuid.UUID) (*models.ChatUsage, error) {
+	var usage models.ChatUsage
+	err := r.db.GetContext(ctx, &usage, `
+		SELECT
+			(SELECT COUNT(*) FROM chat_sessions WHERE user_id = $1) AS sessions,
+			COUNT(*) AS messages,
+			COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+			COALESCE(SUM(completion_tokens), 0) AS completion_tokens
+		FROM chat_messages
+		WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat usage: %w", err)
+	}
+	return &usage, nil
+}
+
+// EscalateChatSession puts a chat session in the expert queue; it reports false when the session
+// is already waiting for or being answered by an expert
+func (r *RecommendationRepository) EscalateChatSession(ctx context.Context, sessionID uuid.UUID, reason *string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE chat_sessions
+		SET escalation_status = $2, escalation_reason = $3, escalated_at = NOW(), expert_id = NULL, updated_at = NOW()
+		WHERE id = $1 AND (escalation_status IS NULL OR escalation_status = $4)
+	`, sessionID, models.ChatEscalationStatusPending, reason, models.ChatEscalationStatusResolved)
+	if err != nil {
+		return false, fmt.Errorf("failed to escalate chat session: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// GetEscalatedChatSessions gets the chat sessions with one of the escalation statuses, longest waiting first
+func (r *RecommendationRepository) GetEscalatedChatSessions(ctx context.Context, statuses []models.ChatEscalationStatus) ([]*models.ChatSession, error) {
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+
+	sessions := []*models.ChatSession{}
+	err := r.db.SelectContext(ctx, &sessions, `
+		SELECT s.id, s.user_id, s.title, s.created_at, s.updated_at, s.last_used,
+			s.escalation_status, s.escalation_reason, s.escalated_at, s.expert_id,
+			COALESCE(SUM(m.prompt_tokens), 0) AS prompt_tokens,
+			COALESCE(SUM(m.completion_tokens), 0) AS completion_tokens
+		FROM chat_sessions s
+		LEFT JOIN chat_messages m ON m.session_id = s.id
+		WHERE s.escalation_status = ANY($1)
+		GROUP BY s.id
+		ORDER BY s.escalated_at ASC
+	`, pq.Array(values))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get escalated chat sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// ClaimChatEscalation assigns a waiting escalation to an expert; it reports false when the session is
+// not waiting or is claimed by another expert. Claiming an escalation again is allowed.
+func (r *RecommendationRepository) ClaimChatEscalation(ctx context.Context, sessionID uuid.UUID, expertID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE chat_sessions
+		SET escalation_status = $3, expert_id = $2, updated_at = NOW()
+		WHERE id = $1 AND (escalation_status = $4 OR (escalation_status = $3 AND expert_id = $2))
+	`, sessionID, expertID, models.ChatEscalationStatusClaimed, models.ChatEscalationStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim chat escalation: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ResolveChatEscalation closes an escalation claimed by the expert; it reports false when the
+// expert has not claimed it
+func (r *RecommendationRepository) ResolveChatEscalation(ctx context.Context, sessionID uuid.UUID, expertID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE chat_sessions
+		SET escalation_status = $3, updated_at = NOW()
+		WHERE id = $1 AND escalation_status = $4 AND expert_id = $2
+	`, sessionID, expertID, models.ChatEscalationStatusResolved, models.ChatEscalationStatusClaimed)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve chat escalation: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
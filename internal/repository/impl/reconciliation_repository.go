@@ -0,0 +1,230 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// reconciliationQuery holds the query counting the inconsistencies of a check and the statement repairing them
+type reconciliationQuery struct {
+	count  string
+	repair string
+}
+
+// reconciliationQueries returns the query counting the inconsistencies of each check and the statement repairing them
+func reconciliationQueries(d *db.DB) map[models.ReconciliationCheck]reconciliationQuery {
+	lastWatered := d.Read("up", "user_plants", "last_watered")
+	nextWatering := d.Read("up", "user_plants", "next_watering")
+	expectedNextWatering := lastWatered + " + " + d.Read("c", "care_instructions", "watering_frequency") + " * INTERVAL '1 day'"
+
+	// Bells that differ from the notifications of their user, with what they should show; users with
+	// notifications but no bell are included
+	driftedBells := `
+		SELECT COALESCE(b.user_id, e.user_id) AS user_id, COALESCE(e.unread_count, 0) AS unread_count,
+			e.latest_notification_at
+		FROM notification_bells b
+		FULL JOIN (
+			SELECT user_id, COUNT(*) FILTER (WHERE NOT is_read) AS unread_count, MAX(created_at) AS latest_notification_at
+			FROM notifications
+			GROUP BY user_id
+		) e ON e.user_id = b.user_id
+		WHERE (COALESCE(b.unread_count, -1), b.latest_notification_at)
+			IS DISTINCT FROM (COALESCE(e.unread_count, 0), e.latest_notification_at)
+	`
+
+	return map[models.ReconciliationCheck]reconciliationQuery{
+		models.ReconciliationCheckNextWatering: {
+			count: `
+				SELECT COUNT(*)
+				FROM user_plants up
+				JOIN plants p ON p.id = up.plant_id
+				JOIN care_instructions c ON c.id = p.care_instructions_id
+				WHERE ` + lastWatered + ` IS NOT NULL
+					AND ` + nextWatering + ` IS DISTINCT FROM ` + expectedNextWatering + `
+			`,
+			repair: `
+				UPDATE user_plants up
+				SET ` + d.Assign("user_plants", "next_watering", expectedNextWatering) + `, updated_at = NOW()
+				FROM plants p
+				JOIN care_instructions c ON c.id = p.care_instructions_id
+				WHERE p.id = up.plant_id
+					AND ` + lastWatered + ` IS NOT NULL
+					AND ` + nextWatering + ` IS DISTINCT FROM ` + expectedNextWatering + `
+			`,
+		},
+		models.ReconciliationCheckOrphanedCareTasks: {
+			count: `
+				SELECT COUNT(*)
+				FROM care_task_completions ctc
+				WHERE NOT EXISTS (
+					SELECT 1 FROM user_plants up
+					WHERE up.user_id = ctc.user_id AND up.plant_id = ctc.plant_id
+				)
+			`,
+			repair: `
+				DELETE FROM care_task_completions ctc
+				WHERE NOT EXISTS (
+					SELECT 1 FROM user_plants up
+					WHERE up.user_id = ctc.user_id AND up.plant_id = ctc.plant_id
+				)
+			`,
+		},
+		models.ReconciliationCheckOrphanedNotifications: {
+			count: `
+				SELECT COUNT(*)
+				FROM notifications n
+				WHERE n.type = 'WATERING' AND n.is_read = FALSE
+					AND NOT EXISTS (
+						SELECT 1 FROM user_plants up
+						WHERE up.user_id = n.user_id AND up.plant_id = n.plant_id
+					)
+			`,
+			repair: `
+				UPDATE notifications n
+				SET is_read = TRUE, updated_at = NOW()
+				WHERE n.type = 'WATERING' AND n.is_read = FALSE
+					AND NOT EXISTS (
+						SELECT 1 FROM user_plants up
+						WHERE up.user_id = n.user_id AND up.plant_id = n.plant_id
+					)
+			`,
+		},
+		models.ReconciliationCheckNotificationBells: {
+			count: `SELECT COUNT(*) FROM (` + driftedBells + `) drifted`,
+			repair: `
+				INSERT INTO notification_bells (user_id, unread_count, latest_notification_at)
+				SELECT user_id, unread_count, latest_notification_at FROM (` + driftedBells + `) drifted
+				ON CONFLICT (user_id) DO UPDATE
+				SET unread_count = EXCLUDED.unread_count, latest_notification_at = EXCLUDED.latest_notification_at,
+					updated_at = NOW()
+			`,
+		},
+	}
+}
+
+// ReconciliationRepository is the implementation of the reconciliation repository
+type ReconciliationRepository struct {
+	db *db.DB
+}
+
+// NewReconciliationRepository creates a new reconciliation repository
+func NewReconciliationRepository(db *db.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{
+		db: db.Repository("reconciliation"),
+	}
+}
+
+// RunCheck counts the inconsistencies found by a check and repairs them unless dryRun is set
+func (r *ReconciliationRepository) RunCheck(ctx context.Context, check models.ReconciliationCheck, dryRun bool) (int, error) {
+	queries, ok := reconciliationQueries(r.db)[check]
+	if !ok {
+		return 0, fmt.Errorf("unknown reconciliation check %s", check)
+	}
+
+	if dryRun {
+		var count int
+		if err := r.db.GetContext(ctx, &count, queries.count); err != nil {
+			return 0, fmt.Errorf("failed to count inconsistencies for %s: %w", check, err)
+		}
+		return count, nil
+	}
+
+	result, err := r.db.ExecContext(ctx, queries.repair)
+	if err != nil {
+		return 0, fmt.Errorf("failed to repair inconsistencies for %s: %w", check, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rows), nil
+}
+
+// SaveRun saves a reconciliation run with its corrections
+func (r *ReconciliationRepository) SaveRun(ctx context.Context, run *models.ReconciliationRun) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO reconciliation_runs (dry_run, started_at, finished_at, total_corrections, error)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, run.DryRun, run.StartedAt, run.FinishedAt, run.TotalCorrections, run.Error).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save reconciliation run: %w", err)
+	}
+
+	for _, correction := range run.Corrections {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO reconciliation_corrections (run_id, check_name, corrections)
+			VALUES ($1, $2, $3)
+		`, run.ID, correction.Check, correction.Corrections)
+		if err != nil {
+			return fmt.Errorf("failed to save reconciliation correction: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetRuns gets the most recent reconciliation runs
+func (r *ReconciliationRepository) GetRuns(ctx context.Context, limit int) ([]*models.ReconciliationRun, error) {
+	runs := []*models.ReconciliationRun{}
+	err := r.db.SelectContext(ctx, &runs, `
+		SELECT id, dry_run, started_at, finished_at, total_corrections, error
+		FROM reconciliation_runs
+		ORDER BY started_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconciliation runs: %w", err)
+	}
+	if len(runs) == 0 {
+		return runs, nil
+	}
+
+	// Get the corrections of the runs
+	runIDs := make([]uuid.UUID, 0, len(runs))
+	runsByID := make(map[uuid.UUID]*models.ReconciliationRun, len(runs))
+	for _, run := range runs {
+		run.Corrections = []*models.ReconciliationCorrection{}
+		runIDs = append(runIDs, run.ID)
+		runsByID[run.ID] = run
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT run_id, check_name, corrections
+		FROM reconciliation_corrections
+		WHERE run_id IN (?)
+		ORDER BY check_name
+	`, runIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build reconciliation corrections query: %w", err)
+	}
+
+	var rows []struct {
+		RunID uuid.UUID `db:"run_id"`
+		models.ReconciliationCorrection
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get reconciliation corrections: %w", err)
+	}
+	for _, row := range rows {
+		correction := row.ReconciliationCorrection
+		runsByID[row.RunID].Corrections = append(runsByID[row.RunID].Corrections, &correction)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,73 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// SagaRepository is the implementation of the saga repository
+type SagaRepository struct {
+	db *db.DB
+}
+
+// NewSagaRepository creates a new saga repository
+func NewSagaRepository(db *db.DB) *SagaRepository {
+	return &SagaRepository{
+		db: db.Repository("saga"),
+	}
+}
+
+// Create stores a new saga
+func (r *SagaRepository) Create(ctx context.Context, saga *models.Saga) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO sagas (name, status, current_step, completed_steps, data, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, saga.Name, saga.Status, saga.CurrentStep, saga.Completed, saga.Data, saga.Error).
+		Scan(&saga.ID, &saga.CreatedAt, &saga.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create saga: %w", err)
+	}
+	return nil
+}
+
+// Update stores the status, current step, completed steps, data and error of a saga
+func (r *SagaRepository) Update(ctx context.Context, saga *models.Saga) error {
+	err := r.db.QueryRowxContext(ctx, `
+		UPDATE sagas
+		SET status = $2, current_step = $3, completed_steps = $4, data = $5, error = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, saga.ID, saga.Status, saga.CurrentStep, saga.Completed, saga.Data, saga.Error).
+		Scan(&saga.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("saga not found: %w", err)
+		}
+		return fmt.Errorf("failed to update saga: %w", err)
+	}
+	return nil
+}
+
+// ListStuck gets the sagas still running or compensating that were last updated before a time, and
+// the sagas whose steps could not be undone, the longest stuck first
+func (r *SagaRepository) ListStuck(ctx context.Context, updatedBefore time.Time, limit int) ([]*models.Saga, error) {
+	sagas := []*models.Saga{}
+	err := r.db.SelectContext(ctx, &sagas, `
+		SELECT id, name, status, current_step, completed_steps, data, error, created_at, updated_at
+		FROM sagas
+		WHERE (status IN ('RUNNING', 'COMPENSATING') AND updated_at < $1) OR status = 'FAILED'
+		ORDER BY updated_at ASC
+		LIMIT $2
+	`, updatedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stuck sagas: %w", err)
+	}
+	return sagas, nil
+}
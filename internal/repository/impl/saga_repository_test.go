@@ -0,0 +1,40 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSagaRepository_ListStuck(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewSagaRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-15 * time.Minute)
+	message := "failed to release stock: connection refused"
+	mock.ExpectQuery("SELECT .* FROM sagas WHERE \\(status IN \\('RUNNING', 'COMPENSATING'\\) AND updated_at < \\$1\\) OR status = 'FAILED' ORDER BY updated_at ASC LIMIT \\$2").
+		WithArgs(cutoff, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "status", "current_step", "completed_steps", "data", "error", "created_at", "updated_at"}).
+			AddRow(uuid.New(), "reservation", "FAILED", "reserve_stock", 1, []byte(`{"reservationId":"r-1"}`), message, now, now))
+
+	sagas, err := repo.ListStuck(context.Background(), cutoff, 100)
+	assert.NoError(t, err)
+	if assert.Len(t, sagas, 1) {
+		assert.Equal(t, models.SagaStatusFailed, sagas[0].Status)
+		assert.Equal(t, "r-1", sagas[0].Data["reservationId"])
+		assert.Equal(t, message, *sagas[0].Error)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,95 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// SeasonalGuideRepository is the implementation of the seasonal guide repository
+type SeasonalGuideRepository struct {
+	db *db.DB
+}
+
+// NewSeasonalGuideRepository creates a new seasonal guide repository
+func NewSeasonalGuideRepository(db *db.DB) *SeasonalGuideRepository {
+	return &SeasonalGuideRepository{
+		db: db.Repository("seasonal_guide"),
+	}
+}
+
+// ListOverrides gets the overrides of a month by list and position, or of every month when month is 0
+func (r *SeasonalGuideRepository) ListOverrides(ctx context.Context, month int) ([]*models.SeasonalGuideOverride, error) {
+	overrides := []*models.SeasonalGuideOverride{}
+	err := r.db.SelectContext(ctx, &overrides, `
+		SELECT id, month, list, plant_id, hidden, position, note, created_at, updated_at
+		FROM seasonal_guide_overrides
+		WHERE $1 = 0 OR month = $1
+		ORDER BY month, list, position, created_at
+	`, month)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seasonal guide overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// UpsertOverride stores an override, replacing the override of the same plant in the same list and month
+func (r *SeasonalGuideRepository) UpsertOverride(ctx context.Context, override *models.SeasonalGuideOverride) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO seasonal_guide_overrides (month, list, plant_id, hidden, position, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (month, list, plant_id) DO UPDATE
+		SET hidden = EXCLUDED.hidden, position = EXCLUDED.position, note = EXCLUDED.note, updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`, override.Month, override.List, override.PlantID, override.Hidden, override.Position, override.Note).
+		Scan(&override.ID, &override.CreatedAt, &override.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save seasonal guide override: %w", err)
+	}
+	return nil
+}
+
+// DeleteOverride removes an override
+func (r *SeasonalGuideRepository) DeleteOverride(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM seasonal_guide_overrides WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete seasonal guide override: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("seasonal guide override not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// GetOwnerCounts gets the number of users owning each catalog plant, as last aggregated by the plant
+// stats; plants not aggregated yet are left out
+func (r *SeasonalGuideRepository) GetOwnerCounts(ctx context.Context) (map[uuid.UUID]int, error) {
+	rows, err := r.db.QueryxContext(ctx, `SELECT plant_id, owners FROM plant_stats`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant owner counts: %w", err)
+	}
+	defer rows.Close()
+
+	owners := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var plantID uuid.UUID
+		var count int
+		if err := rows.Scan(&plantID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan plant owner count: %w", err)
+		}
+		owners[plantID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get plant owner counts: %w", err)
+	}
+	return owners, nil
+}
@@ -0,0 +1,70 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeasonalGuideRepository_UpsertOverride(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewSeasonalGuideRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	override := &models.SeasonalGuideOverride{Month: 3, List: models.SeasonalGuideListRepot, PlantID: uuid.New(), Position: 1}
+	overrideID := uuid.New()
+	mock.ExpectQuery("INSERT INTO seasonal_guide_overrides .* ON CONFLICT \\(month, list, plant_id\\) DO UPDATE").
+		WithArgs(3, models.SeasonalGuideListRepot, override.PlantID, false, 1, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(overrideID, now, now))
+
+	assert.NoError(t, repo.UpsertOverride(context.Background(), override))
+	assert.Equal(t, overrideID, override.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeasonalGuideRepository_DeleteOverride_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewSeasonalGuideRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	overrideID := uuid.New()
+	mock.ExpectExec("DELETE FROM seasonal_guide_overrides WHERE id = \\$1").
+		WithArgs(overrideID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, repo.DeleteOverride(context.Background(), overrideID), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeasonalGuideRepository_GetOwnerCounts(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewSeasonalGuideRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	plantID := uuid.New()
+	mock.ExpectQuery("SELECT plant_id, owners FROM plant_stats").
+		WillReturnRows(sqlmock.NewRows([]string{"plant_id", "owners"}).AddRow(plantID, 12))
+
+	owners, err := repo.GetOwnerCounts(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{plantID: 12}, owners)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -19,16 +19,17 @@ type ShopRepository struct {
 // NewShopRepository creates a new shop repository
 func NewShopRepository(db *db.DB) *ShopRepository {
 	return &ShopRepository{
-		db: db,
+		db: db.Repository("shop"),
 	}
 }
 
-// GetAll gets all shops
+// GetAll gets all shops that are not deleted
 func (r *ShopRepository) GetAll(ctx context.Context) ([]*models.Shop, error) {
 	var shops []*models.Shop
 	err := r.db.SelectContext(ctx, &shops, `
-		SELECT id, name, address, rating, image_url, created_at, updated_at
+		SELECT id, name, address, city, rating, image_url, latitude, longitude, created_at, updated_at
 		FROM shops
+		WHERE deleted_at IS NULL
 		ORDER BY name
 	`)
 	if err != nil {
@@ -37,13 +38,27 @@ func (r *ShopRepository) GetAll(ctx context.Context) ([]*models.Shop, error) {
 	return shops, nil
 }
 
-// GetByID gets a shop by ID
+// Create creates a new shop
+func (r *ShopRepository) Create(ctx context.Context, shop *models.Shop) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO shops (name, address, city, rating, image_url, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`, shop.Name, shop.Address, shop.City, shop.Rating, shop.ImageURL, shop.Latitude, shop.Longitude,
+	).Scan(&shop.ID, &shop.CreatedAt, &shop.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create shop: %w", err)
+	}
+	return nil
+}
+
+// GetByID gets a shop by ID; deleted shops are not found
 func (r *ShopRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Shop, error) {
 	var shop models.Shop
 	err := r.db.GetContext(ctx, &shop, `
-		SELECT id, name, address, rating, image_url, created_at, updated_at
+		SELECT id, name, address, city, rating, image_url, latitude, longitude, created_at, updated_at
 		FROM shops
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -59,15 +74,17 @@ func (r *ShopRepository) GetPlants(ctx context.Context, shopID uuid.UUID) ([]*mo
 	rows, err := r.db.QueryxContext(ctx, `
 		SELECT p.id, p.name, p.scientific_name, p.description, p.image_url, sp.price, sp.shop_id,
 			   p.created_at, p.updated_at,
-			   c.id as "care_instructions.id", c.watering_frequency as "care_instructions.watering_frequency",
+			   c.id as "care_instructions.id", `+r.db.Read("c", "care_instructions", "watering_frequency")+` as "care_instructions.watering_frequency",
 			   c.sunlight as "care_instructions.sunlight", c.min_temperature, c.max_temperature,
 			   c.humidity as "care_instructions.humidity", c.soil_type as "care_instructions.soil_type",
-			   c.fertilizer_frequency as "care_instructions.fertilizer_frequency",
-			   c.additional_notes as "care_instructions.additional_notes"
+			   `+r.db.Read("c", "care_instructions", "fertilizer_frequency")+` as "care_instructions.fertilizer_frequency",
+			   c.additional_notes as "care_instructions.additional_notes",
+			   c.source_url, c.source_author, c.last_reviewed_at
 		FROM plants p
 		JOIN care_instructions c ON p.care_instructions_id = c.id
 		JOIN shop_plants sp ON p.id = sp.plant_id
-		WHERE sp.shop_id = $1
+		JOIN shops s ON s.id = sp.shop_id AND s.deleted_at IS NULL
+		WHERE sp.shop_id = $1 AND p.deleted_at IS NULL AND p.status = 'PUBLISHED'
 		ORDER BY p.name
 	`, shopID)
 	if err != nil {
@@ -88,6 +105,7 @@ func (r *ShopRepository) GetPlants(ctx context.Context, shopID uuid.UUID) ([]*mo
 			&careInstructions.ID, &careInstructions.WateringFrequency, &careInstructions.Sunlight,
 			&minTemp, &maxTemp, &careInstructions.Humidity, &careInstructions.SoilType,
 			&careInstructions.FertilizerFrequency, &careInstructions.AdditionalNotes,
+			&careInstructions.SourceURL, &careInstructions.SourceAuthor, &careInstructions.LastReviewedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan plant: %w", err)
@@ -122,4 +140,196 @@ func (r *ShopRepository) GetSpecialOffers(ctx context.Context) ([]*models.Specia
 		return nil, fmt.Errorf("failed to get special offers: %w", err)
 	}
 	return offers, nil
-}
\ No newline at end of file
+}
+
+// GetPlantOffers gets the offers of all shops selling a plant, deleted shops left out
+func (r *ShopRepository) GetPlantOffers(ctx context.Context, plantID uuid.UUID) ([]*models.PlantOffer, error) {
+	var offers []*models.PlantOffer
+	err := r.db.SelectContext(ctx, &offers, `
+		SELECT sp.id, sp.shop_id, sp.plant_id, sp.price, sp.condition, sp.size_cm, sp.pot_diameter_cm,
+			   sp.batch_photo_urls, sp.created_at, sp.updated_at,
+			   s.id AS "shop.id", s.name AS "shop.name", s.address AS "shop.address", s.city AS "shop.city",
+			   s.rating AS "shop.rating", s.image_url AS "shop.image_url",
+			   s.created_at AS "shop.created_at", s.updated_at AS "shop.updated_at"
+		FROM shop_plants sp
+		JOIN shops s ON s.id = sp.shop_id
+		WHERE sp.plant_id = $1 AND s.deleted_at IS NULL
+		ORDER BY sp.price, s.rating DESC
+	`, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant offers: %w", err)
+	}
+	return offers, nil
+}
+
+// UpdateShopPlant updates the price and stock attributes of a shop's plant
+func (r *ShopRepository) UpdateShopPlant(ctx context.Context, shopPlant *models.ShopPlant) error {
+	err := r.db.GetContext(ctx, shopPlant, `
+		UPDATE shop_plants
+		SET price = $3, condition = $4, size_cm = $5, pot_diameter_cm = $6, batch_photo_urls = $7, updated_at = NOW()
+		WHERE shop_id = $1 AND plant_id = $2
+		RETURNING id, shop_id, plant_id, price, condition, size_cm, pot_diameter_cm, batch_photo_urls, created_at, updated_at
+	`, shopPlant.ShopID, shopPlant.PlantID, shopPlant.Price, shopPlant.Condition, shopPlant.SizeCm,
+		shopPlant.PotDiameterCm, shopPlant.BatchPhotos)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("shop plant not found: %w", err)
+		}
+		return fmt.Errorf("failed to update shop plant: %w", err)
+	}
+	return nil
+}
+
+// Update updates the name, address, rating, image and coordinates of a shop
+func (r *ShopRepository) Update(ctx context.Context, shop *models.Shop) error {
+	err := r.db.QueryRowxContext(ctx, `
+		UPDATE shops
+		SET name = $2, address = $3, city = $4, rating = $5, image_url = $6, latitude = $7, longitude = $8, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING created_at, updated_at
+	`, shop.ID, shop.Name, shop.Address, shop.City, shop.Rating, shop.ImageURL, shop.Latitude, shop.Longitude,
+	).Scan(&shop.CreatedAt, &shop.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("shop not found: %w", err)
+		}
+		return fmt.Errorf("failed to update shop: %w", err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a shop: it and its offers are hidden but kept for the audit log
+func (r *ShopRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE shops SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete shop: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("shop not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// AddShopPlant has a shop sell a catalog plant, reporting false when the shop already sells it
+func (r *ShopRepository) AddShopPlant(ctx context.Context, shopPlant *models.ShopPlant) (bool, error) {
+	var exists struct {
+		Plant bool `db:"plant"`
+		Shop  bool `db:"shop"`
+	}
+	err := r.db.GetContext(ctx, &exists, `
+		SELECT EXISTS (SELECT 1 FROM plants WHERE id = $1 AND deleted_at IS NULL) AS plant,
+			EXISTS (SELECT 1 FROM shops WHERE id = $2 AND deleted_at IS NULL) AS shop
+	`, shopPlant.PlantID, shopPlant.ShopID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check plant: %w", err)
+	}
+	if !exists.Shop {
+		return false, fmt.Errorf("shop not found: %w", sql.ErrNoRows)
+	}
+	if !exists.Plant {
+		return false, fmt.Errorf("plant not found: %w", sql.ErrNoRows)
+	}
+
+	err = r.db.QueryRowxContext(ctx, `
+		INSERT INTO shop_plants (shop_id, plant_id, price, condition, size_cm, pot_diameter_cm, batch_photo_urls)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (shop_id, plant_id) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`, shopPlant.ShopID, shopPlant.PlantID, shopPlant.Price, shopPlant.Condition, shopPlant.SizeCm,
+		shopPlant.PotDiameterCm, shopPlant.BatchPhotos).
+		Scan(&shopPlant.ID, &shopPlant.CreatedAt, &shopPlant.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to add shop plant: %w", err)
+	}
+	return true, nil
+}
+
+// RemoveShopPlant stops a shop selling a plant
+func (r *ShopRepository) RemoveShopPlant(ctx context.Context, shopID uuid.UUID, plantID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM shop_plants WHERE shop_id = $1 AND plant_id = $2`, shopID, plantID)
+	if err != nil {
+		return fmt.Errorf("failed to remove shop plant: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("shop plant not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// ListSpecialOffers gets all special offers, expired ones included, the latest ending first
+func (r *ShopRepository) ListSpecialOffers(ctx context.Context) ([]*models.SpecialOffer, error) {
+	offers := []*models.SpecialOffer{}
+	err := r.db.SelectContext(ctx, &offers, `
+		SELECT id, title, description, image_url, discount_percentage, valid_until, created_at, updated_at
+		FROM special_offers
+		ORDER BY valid_until DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list special offers: %w", err)
+	}
+	return offers, nil
+}
+
+// CreateSpecialOffer creates a special offer
+func (r *ShopRepository) CreateSpecialOffer(ctx context.Context, offer *models.SpecialOffer) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO special_offers (title, description, image_url, discount_percentage, valid_until)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, offer.Title, offer.Description, offer.ImageURL, offer.DiscountPercentage, offer.ValidUntil,
+	).Scan(&offer.ID, &offer.CreatedAt, &offer.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create special offer: %w", err)
+	}
+	return nil
+}
+
+// UpdateSpecialOffer updates a special offer
+func (r *ShopRepository) UpdateSpecialOffer(ctx context.Context, offer *models.SpecialOffer) error {
+	err := r.db.QueryRowxContext(ctx, `
+		UPDATE special_offers
+		SET title = $2, description = $3, image_url = $4, discount_percentage = $5, valid_until = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, offer.ID, offer.Title, offer.Description, offer.ImageURL, offer.DiscountPercentage, offer.ValidUntil,
+	).Scan(&offer.CreatedAt, &offer.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("special offer not found: %w", err)
+		}
+		return fmt.Errorf("failed to update special offer: %w", err)
+	}
+	return nil
+}
+
+// DeleteSpecialOffer deletes a special offer
+func (r *ShopRepository) DeleteSpecialOffer(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM special_offers WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete special offer: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("special offer not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShopRepository_Update_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewShopRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	shop := &models.Shop{ID: uuid.New(), Name: "Флора", Address: "ул. Мира, 1", Rating: 4}
+	mock.ExpectQuery("UPDATE shops SET name = \\$2").
+		WithArgs(shop.ID, shop.Name, shop.Address, nil, 4.0, nil, nil, nil).
+		WillReturnError(sql.ErrNoRows)
+
+	assert.ErrorIs(t, repo.Update(context.Background(), shop), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShopRepository_AddShopPlant(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewShopRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	shopPlant := &models.ShopPlant{ShopID: uuid.New(), PlantID: uuid.New(), Price: 990, BatchPhotos: models.AssetKeys{}}
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM plants").
+		WithArgs(shopPlant.PlantID, shopPlant.ShopID).
+		WillReturnRows(sqlmock.NewRows([]string{"plant", "shop"}).AddRow(true, true))
+	mock.ExpectQuery("INSERT INTO shop_plants .* ON CONFLICT \\(shop_id, plant_id\\) DO NOTHING").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(uuid.New(), now, now))
+
+	added, err := repo.AddShopPlant(context.Background(), shopPlant)
+	assert.NoError(t, err)
+	assert.True(t, added)
+	assert.NotEqual(t, uuid.Nil, shopPlant.ID)
+
+	// The shop already sells the plant
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM plants").
+		WillReturnRows(sqlmock.NewRows([]string{"plant", "shop"}).AddRow(true, true))
+	mock.ExpectQuery("INSERT INTO shop_plants").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}))
+	added, err = repo.AddShopPlant(context.Background(), shopPlant)
+	assert.NoError(t, err)
+	assert.False(t, added)
+
+	// The plant is not in the catalog
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM plants").
+		WillReturnRows(sqlmock.NewRows([]string{"plant", "shop"}).AddRow(false, true))
+	_, err = repo.AddShopPlant(context.Background(), shopPlant)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// The shop is deleted
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM plants").
+		WillReturnRows(sqlmock.NewRows([]string{"plant", "shop"}).AddRow(true, false))
+	_, err = repo.AddShopPlant(context.Background(), shopPlant)
+	assert.ErrorContains(t, err, "shop not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShopRepository_CreateSpecialOffer(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewShopRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	offer := &models.SpecialOffer{
+		Title: "Весенняя распродажа", Description: "Скидки на суккуленты", ImageURL: "https://example.com/spring.jpg",
+		DiscountPercentage: 20, ValidUntil: now.AddDate(0, 0, 7),
+	}
+	offerID := uuid.New()
+	mock.ExpectQuery("INSERT INTO special_offers").
+		WithArgs(offer.Title, offer.Description, offer.ImageURL, 20, offer.ValidUntil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(offerID, now, now))
+
+	assert.NoError(t, repo.CreateSpecialOffer(context.Background(), offer))
+	assert.Equal(t, offerID, offer.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShopRepository_DeleteSpecialOffer_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewShopRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	offerID := uuid.New()
+	mock.ExpectExec("DELETE FROM special_offers WHERE id = \\$1").
+		WithArgs(offerID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, repo.DeleteSpecialOffer(context.Background(), offerID), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShopRepository_Delete_Soft(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewShopRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	shopID := uuid.New()
+	mock.ExpectExec("UPDATE shops SET deleted_at = NOW\\(\\), updated_at = NOW\\(\\) WHERE id = \\$1 AND deleted_at IS NULL").
+		WithArgs(shopID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Delete(context.Background(), shopID))
+
+	// Deleting it again does not find it
+	mock.ExpectExec("UPDATE shops SET deleted_at").
+		WithArgs(shopID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Delete(context.Background(), shopID), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,105 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// supportTicketColumns are the columns selected for a support ticket
+const supportTicketColumns = `id, user_id, plant_id, message, context, status, admin_note, created_at, updated_at, resolved_at`
+
+// SupportTicketRepository is the implementation of the support ticket repository
+type SupportTicketRepository struct {
+	db *db.DB
+}
+
+// NewSupportTicketRepository creates a new support ticket repository
+func NewSupportTicketRepository(db *db.DB) *SupportTicketRepository {
+	return &SupportTicketRepository{
+		db: db.Repository("support_ticket"),
+	}
+}
+
+// Create stores a new support ticket
+func (r *SupportTicketRepository) Create(ctx context.Context, ticket *models.SupportTicket) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO support_tickets (user_id, plant_id, message, context, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`, ticket.UserID, ticket.PlantID, ticket.Message, ticket.Context, ticket.Status).Scan(&ticket.ID, &ticket.CreatedAt, &ticket.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create support ticket: %w", err)
+	}
+	return nil
+}
+
+// GetByID gets a support ticket by ID
+func (r *SupportTicketRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SupportTicket, error) {
+	var ticket models.SupportTicket
+	err := r.db.GetContext(ctx, &ticket, `
+		SELECT `+supportTicketColumns+`
+		FROM support_tickets
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("support ticket not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get support ticket: %w", err)
+	}
+	return &ticket, nil
+}
+
+// List gets a page of support tickets, oldest first, with the total number matching the filter
+func (r *SupportTicketRepository) List(ctx context.Context, status models.SupportTicketStatus, limit int, offset int) ([]*models.SupportTicket, int, error) {
+	var total int
+	err := r.db.GetContext(ctx, &total, `
+		SELECT COUNT(*)
+		FROM support_tickets
+		WHERE $1 = '' OR status = $1
+	`, string(status))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count support tickets: %w", err)
+	}
+
+	tickets := []*models.SupportTicket{}
+	err = r.db.SelectContext(ctx, &tickets, `
+		SELECT `+supportTicketColumns+`
+		FROM support_tickets
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at
+		LIMIT $2 OFFSET $3
+	`, string(status), limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get support tickets: %w", err)
+	}
+	return tickets, total, nil
+}
+
+// UpdateStatus sets the status and admin note of a support ticket. The note is kept when none is
+// given; resolving or closing a ticket records when it happened.
+func (r *SupportTicketRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.SupportTicketStatus, adminNote *string) (*models.SupportTicket, error) {
+	var ticket models.SupportTicket
+	err := r.db.GetContext(ctx, &ticket, `
+		UPDATE support_tickets
+		SET status = $2,
+			admin_note = COALESCE($3, admin_note),
+			resolved_at = CASE WHEN $2 IN ('RESOLVED', 'CLOSED') THEN COALESCE(resolved_at, NOW()) ELSE NULL END,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+supportTicketColumns+`
+	`, id, string(status), adminNote)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("support ticket not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to update support ticket: %w", err)
+	}
+	return &ticket, nil
+}
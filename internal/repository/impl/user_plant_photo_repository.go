@@ -0,0 +1,144 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// photoShareable tells whether public share pages may show a photo: it is approved and shows a plant
+const photoShareable = `moderation_status = 'APPROVED' AND NOT ('not_plant' = ANY(moderation_labels)) AS shareable`
+
+// photoColumns are the columns a photo is read from
+const photoColumns = `id, user_id, plant_id, image_key, content_type, moderation_status, moderation_labels, ` +
+	photoShareable + `, reviewed_at, created_at`
+
+// UserPlantPhotoRepository is the implementation of the user plant photo repository
+type UserPlantPhotoRepository struct {
+	db *db.DB
+}
+
+// NewUserPlantPhotoRepository creates a new user plant photo repository
+func NewUserPlantPhotoRepository(db *db.DB) *UserPlantPhotoRepository {
+	return &UserPlantPhotoRepository{
+		db: db.Repository("user_plant_photo"),
+	}
+}
+
+// Create saves a photo of a plant in a user's collection
+func (r *UserPlantPhotoRepository) Create(ctx context.Context, photo *models.UserPlantPhoto) error {
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO user_plant_photos (id, user_id, plant_id, image_key, content_type, moderation_status, moderation_labels)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+photoShareable+`, created_at
+	`, photo.ID, photo.UserID, photo.PlantID, photo.ImageURL, photo.ContentType, photo.ModerationStatus, photo.ModerationLabels).
+		Scan(&photo.Shareable, &photo.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save user plant photo: %w", err)
+	}
+	return nil
+}
+
+// GetByID gets a photo
+func (r *UserPlantPhotoRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.UserPlantPhoto, error) {
+	var photo models.UserPlantPhoto
+	err := r.db.GetContext(ctx, &photo, `
+		SELECT `+photoColumns+`
+		FROM user_plant_photos
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user plant photo not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get user plant photo: %w", err)
+	}
+	return &photo, nil
+}
+
+// ListByUserPlant gets the photos of a plant in a user's collection, newest first
+func (r *UserPlantPhotoRepository) ListByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.UserPlantPhoto, error) {
+	photos := []*models.UserPlantPhoto{}
+	err := r.db.SelectContext(ctx, &photos, `
+		SELECT `+photoColumns+`
+		FROM user_plant_photos
+		WHERE user_id = $1 AND plant_id = $2
+		ORDER BY created_at DESC
+	`, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user plant photos: %w", err)
+	}
+	return photos, nil
+}
+
+// ListByUser gets the photos of all plants in a user's collection, newest first
+func (r *UserPlantPhotoRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.UserPlantPhoto, error) {
+	photos := []*models.UserPlantPhoto{}
+	err := r.db.SelectContext(ctx, &photos, `
+		SELECT `+photoColumns+`
+		FROM user_plant_photos
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user plant photos: %w", err)
+	}
+	return photos, nil
+}
+
+// ListQuarantined gets the photos awaiting an admin's review, oldest first
+func (r *UserPlantPhotoRepository) ListQuarantined(ctx context.Context) ([]*models.UserPlantPhoto, error) {
+	photos := []*models.UserPlantPhoto{}
+	err := r.db.SelectContext(ctx, &photos, `
+		SELECT `+photoColumns+`
+		FROM user_plant_photos
+		WHERE moderation_status = 'QUARANTINED'
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quarantined photos: %w", err)
+	}
+	return photos, nil
+}
+
+// Approve approves a quarantined photo, so it goes live
+func (r *UserPlantPhotoRepository) Approve(ctx context.Context, id uuid.UUID) (*models.UserPlantPhoto, error) {
+	var photo models.UserPlantPhoto
+	err := r.db.GetContext(ctx, &photo, `
+		UPDATE user_plant_photos
+		SET moderation_status = 'APPROVED', reviewed_at = NOW()
+		WHERE id = $1 AND moderation_status = 'QUARANTINED'
+		RETURNING `+photoColumns+`
+	`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("quarantined photo not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to approve photo: %w", err)
+	}
+	return &photo, nil
+}
+
+// Delete deletes a photo
+func (r *UserPlantPhotoRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM user_plant_photos
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user plant photo: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete user plant photo: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user plant photo not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
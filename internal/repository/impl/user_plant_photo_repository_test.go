@@ -0,0 +1,78 @@
+package impl
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserPlantPhotoRepository_ListByUserPlant(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewUserPlantPhotoRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	userID, plantID, photoID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, user_id, plant_id, image_key, content_type, moderation_status, .* FROM user_plant_photos").
+		WithArgs(userID, plantID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "plant_id", "image_key", "content_type", "moderation_status", "moderation_labels", "shareable", "reviewed_at", "created_at"}).
+			AddRow(photoID, userID, plantID, "user-plants/monstera.jpg", "image/jpeg", "APPROVED", "{not_plant}", false, nil, now))
+
+	photos, err := repo.ListByUserPlant(context.Background(), userID, plantID)
+	assert.NoError(t, err)
+	if assert.Len(t, photos, 1) {
+		assert.Equal(t, models.AssetKey("user-plants/monstera.jpg"), photos[0].ImageURL)
+		assert.Equal(t, "image/jpeg", photos[0].ContentType)
+		assert.Equal(t, models.PhotoModerationApproved, photos[0].ModerationStatus)
+		assert.Equal(t, []string{models.PhotoModerationLabelNotPlant}, []string(photos[0].ModerationLabels))
+		assert.False(t, photos[0].Shareable)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserPlantPhotoRepository_Delete_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewUserPlantPhotoRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	photoID := uuid.New()
+	mock.ExpectExec("DELETE FROM user_plant_photos").
+		WithArgs(photoID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.Delete(context.Background(), photoID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserPlantPhotoRepository_Approve_NotQuarantined(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer mockDB.Close()
+	repo := NewUserPlantPhotoRepository(&db.DB{DB: sqlx.NewDb(mockDB, "sqlmock")})
+
+	photoID := uuid.New()
+	mock.ExpectQuery("UPDATE user_plant_photos SET moderation_status = 'APPROVED'").
+		WithArgs(photoID).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.Approve(context.Background(), photoID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,142 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/db"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// UserPlantTaskRepository is the implementation of the recurring care task repository
+type UserPlantTaskRepository struct {
+	db *db.DB
+}
+
+// NewUserPlantTaskRepository creates a new recurring care task repository
+func NewUserPlantTaskRepository(db *db.DB) *UserPlantTaskRepository {
+	return &UserPlantTaskRepository{
+		db: db.Repository("user_plant_task"),
+	}
+}
+
+// GetByUserPlant gets the recurring tasks of a plant in a user's collection
+func (r *UserPlantTaskRepository) GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.UserPlantTask, error) {
+	tasks := []*models.UserPlantTask{}
+	err := r.db.SelectContext(ctx, &tasks, `
+		SELECT id, user_id, plant_id, task_type, frequency_days, next_due, created_at, updated_at
+		FROM user_plant_tasks
+		WHERE user_id = $1 AND plant_id = $2
+		ORDER BY next_due ASC, task_type ASC
+	`, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plant tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// AddMissing stores the tasks whose type the plant has no task of yet and leaves the others untouched
+func (r *UserPlantTaskRepository) AddMissing(ctx context.Context, tasks []*models.UserPlantTask) error {
+	for _, task := range tasks {
+		_, err := r.db.ExecContext(ctx, `
+			INSERT INTO user_plant_tasks (user_id, plant_id, task_type, frequency_days, next_due)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (user_id, plant_id, task_type) DO NOTHING
+		`, task.UserID, task.PlantID, task.Type, task.FrequencyDays, task.NextDue)
+		if err != nil {
+			return fmt.Errorf("failed to add user plant task: %w", err)
+		}
+	}
+	return nil
+}
+
+// Replace replaces the recurring tasks of a plant in a user's collection
+func (r *UserPlantTaskRepository) Replace(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, tasks []*models.UserPlantTask) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM user_plant_tasks WHERE user_id = $1 AND plant_id = $2
+	`, userID, plantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user plant tasks: %w", err)
+	}
+
+	for _, task := range tasks {
+		err = tx.QueryRowxContext(ctx, `
+			INSERT INTO user_plant_tasks (user_id, plant_id, task_type, frequency_days, next_due)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at, updated_at
+		`, userID, plantID, task.Type, task.FrequencyDays, task.NextDue).Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save user plant task: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetDue gets the tasks due on or before the given day with the plants they are about
+func (r *UserPlantTaskRepository) GetDue(ctx context.Context, until time.Time) ([]*models.UserPlantTask, error) {
+	rows, err := r.db.QueryxContext(ctx, `
+		SELECT t.id, t.user_id, t.plant_id, t.task_type, t.frequency_days, t.next_due,
+			up.id, up.location, up.created_at, p.name, u.language
+		FROM user_plant_tasks t
+		JOIN user_plants up ON up.user_id = t.user_id AND up.plant_id = t.plant_id
+		JOIN plants p ON t.plant_id = p.id
+		JOIN users u ON t.user_id = u.id
+		WHERE t.next_due <= $1 AND u.anonymized_at IS NULL AND u.deleted_at IS NULL
+		ORDER BY t.next_due ASC
+	`, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due user plant tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*models.UserPlantTask
+	for rows.Next() {
+		var task models.UserPlantTask
+		var userPlant models.UserPlant
+		var plantName string
+		err := rows.Scan(
+			&task.ID, &task.UserID, &task.PlantID, &task.Type, &task.FrequencyDays, &task.NextDue,
+			&userPlant.ID, &userPlant.Location, &userPlant.CreatedAt, &plantName, &userPlant.UserLanguage,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user plant task: %w", err)
+		}
+
+		userPlant.UserID = task.UserID
+		userPlant.PlantID = task.PlantID
+		userPlant.Plant = &models.Plant{
+			ID:   task.PlantID,
+			Name: plantName,
+		}
+		task.UserPlant = &userPlant
+		tasks = append(tasks, &task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user plant tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// SetNextDue moves a task to its next due date
+func (r *UserPlantTaskRepository) SetNextDue(ctx context.Context, taskID uuid.UUID, nextDue time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE user_plant_tasks SET next_due = $1, updated_at = NOW() WHERE id = $2
+	`, nextDue, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to update user plant task: %w", err)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/anpanovv/planter/internal/db"
 	"github.com/anpanovv/planter/internal/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // UserRepository is the implementation of the user repository
@@ -19,17 +20,17 @@ type UserRepository struct {
 // NewUserRepository creates a new user repository
 func NewUserRepository(db *db.DB) *UserRepository {
 	return &UserRepository{
-		db: db,
+		db: db.Repository("user"),
 	}
 }
 
-// GetByID gets a user by ID
+// GetByID gets a user by ID; deleted users are not found
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
 	err := r.db.GetContext(ctx, &user, `
-		SELECT id, name, email, profile_image_url, language, notifications_enabled, created_at, updated_at
+		SELECT id, name, email, profile_image_url, language, notifications_enabled, watering_reminder_channel, low_effort_mode, city, timezone, notification_hour, roles, created_at, updated_at
 		FROM users
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -62,13 +63,13 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	return &user, nil
 }
 
-// GetByEmail gets a user by email
+// GetByEmail gets a user by email; deleted users are not found
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	err := r.db.GetContext(ctx, &user, `
-		SELECT id, name, email, password_hash, profile_image_url, language, notifications_enabled, created_at, updated_at
+		SELECT id, name, email, password_hash, profile_image_url, language, notifications_enabled, watering_reminder_channel, low_effort_mode, city, timezone, notification_hour, roles, created_at, updated_at
 		FROM users
-		WHERE email = $1
+		WHERE email = $1 AND deleted_at IS NULL
 	`, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -113,9 +114,9 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	err = tx.QueryRowxContext(ctx, `
 		INSERT INTO users (name, email, password_hash, profile_image_url, language, notifications_enabled)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
+		RETURNING id, watering_reminder_channel, notification_hour, created_at, updated_at
 	`, user.Name, user.Email, user.PasswordHash, user.ProfileImageURL, user.Language, user.NotificationsEnabled).
-		Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+		Scan(&user.ID, &user.WateringReminderChannel, &user.NotificationHour, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -145,9 +146,12 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	// Update user
 	_, err = tx.ExecContext(ctx, `
 		UPDATE users
-		SET name = $1, profile_image_url = $2, language = $3, notifications_enabled = $4, updated_at = NOW()
-		WHERE id = $5
-	`, user.Name, user.ProfileImageURL, user.Language, user.NotificationsEnabled, user.ID)
+		SET name = $1, profile_image_url = $2, language = $3, notifications_enabled = $4,
+			watering_reminder_channel = $5, city = $6, timezone = $7, notification_hour = COALESCE($8, notification_hour),
+			updated_at = NOW()
+		WHERE id = $9 AND deleted_at IS NULL
+	`, user.Name, user.ProfileImageURL, user.Language, user.NotificationsEnabled, user.WateringReminderChannel, user.City,
+		user.Timezone, user.NotificationHour, user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -175,6 +179,24 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	return tx.Commit()
 }
 
+// SetLowEffortMode turns the low effort mode of a user on or off
+func (r *UserRepository) SetLowEffortMode(ctx context.Context, userID uuid.UUID, enabled bool) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET low_effort_mode = $1, updated_at = NOW() WHERE id = $2
+	`, enabled, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set low effort mode: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
 // GetLocations gets a user's locations
 func (r *UserRepository) GetLocations(ctx context.Context, userID uuid.UUID) ([]string, error) {
 	var locations []string
@@ -221,7 +243,7 @@ func (r *UserRepository) GetFavoritePlantIDs(ctx context.Context, userID uuid.UU
 	err := r.db.SelectContext(ctx, &plantIDs, `
 		SELECT plant_id::text
 		FROM user_favorite_plants
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at
 	`, userID)
 	if err != nil {
@@ -243,4 +265,68 @@ func (r *UserRepository) GetOwnedPlantIDs(ctx context.Context, userID uuid.UUID)
 		return nil, fmt.Errorf("failed to get owned plant IDs: %w", err)
 	}
 	return plantIDs, nil
-}
\ No newline at end of file
+}
+
+// GetRoles gets a user's roles
+func (r *UserRepository) GetRoles(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var roles pq.StringArray
+	err := r.db.GetContext(ctx, &roles, `
+		SELECT roles
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL
+	`, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", err)
+		}
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	return roles, nil
+}
+
+// AddRole grants a role to a user; granting a role the user already has does nothing
+func (r *UserRepository) AddRole(ctx context.Context, userID uuid.UUID, role models.Role) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET roles = array_append(roles, $2), updated_at = NOW()
+		WHERE id = $1 AND NOT ($2 = ANY(roles))
+	`, userID, string(role))
+	if err != nil {
+		return fmt.Errorf("failed to add user role: %w", err)
+	}
+	return nil
+}
+
+// GetByRole gets the users granted a role
+func (r *UserRepository) GetByRole(ctx context.Context, role models.Role) ([]*models.User, error) {
+	users := []*models.User{}
+	err := r.db.SelectContext(ctx, &users, `
+		SELECT id, name, email, profile_image_url, language, notifications_enabled, watering_reminder_channel, low_effort_mode, city, timezone, notification_hour, roles, created_at, updated_at
+		FROM users
+		WHERE $1 = ANY(roles) AND deleted_at IS NULL
+		ORDER BY created_at
+	`, string(role))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by role: %w", err)
+	}
+	return users, nil
+}
+
+// SoftDelete marks a user as deleted: the account can no longer be used or found, but its data is
+// kept for the audit log and its email stays taken
+func (r *UserRepository) SoftDelete(ctx context.Context, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
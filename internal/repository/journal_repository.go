@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// JournalRepository defines the interface for plant journal operations
+type JournalRepository interface {
+	// Create adds an entry to a plant's journal
+	Create(ctx context.Context, entry *models.PlantJournalEntry) error
+
+	// GetByUserPlant gets the most recent journal entries of a plant in a user's collection
+	GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, limit int) ([]*models.PlantJournalEntry, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// LLMUsageRepository defines the interface for Yandex GPT usage storage
+type LLMUsageRepository interface {
+	// Record stores the tokens consumed by a Yandex GPT request
+	Record(ctx context.Context, usage *models.LLMUsage) error
+
+	// GetTotals gets the number of requests and tokens since the given time
+	GetTotals(ctx context.Context, since time.Time) (*models.LLMUsageTotals, error)
+
+	// GetTopUsers gets the users who consumed the most tokens since the given time
+	GetTopUsers(ctx context.Context, since time.Time, limit int) ([]*models.LLMUserUsage, error)
+
+	// CountUsersOverQuota counts the users who consumed at least the given number of tokens since the given time
+	CountUsersOverQuota(ctx context.Context, since time.Time, tokens int64) (int, error)
+
+	// GetUserTokens gets the number of tokens a user consumed since the given time
+	GetUserTokens(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error)
+}
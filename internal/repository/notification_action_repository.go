@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// NotificationActionRepository defines the interface for the used notification action tokens
+type NotificationActionRepository interface {
+	// Claim records that a notification action token is used; it reports false when it was used before
+	Claim(ctx context.Context, token *models.NotificationActionToken) (bool, error)
+
+	// Release undoes the claim of a token whose action could not be performed
+	Release(ctx context.Context, id uuid.UUID) error
+
+	// DeleteExpired deletes the used tokens that expired before the given time and returns how many were deleted
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
     "context"
+    "time"
+
     "github.com/anpanovv/planter/internal/models"
     "github.com/google/uuid"
 )
@@ -17,6 +19,34 @@ type NotificationRepository interface {
     // MarkAsRead marks a notification as read
     MarkAsRead(ctx context.Context, notificationID uuid.UUID, userID uuid.UUID) error
 
+    // CountUnread counts the unread notifications of a user, as their bell shows them
+    CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+
+    // GetBell gets the unread state of the notifications of a user, kept as they are created and read
+    GetBell(ctx context.Context, userID uuid.UUID) (*models.NotificationBell, error)
+
+    // MarkAllAsRead marks all notifications of a user as read and returns the number marked
+    MarkAllAsRead(ctx context.Context, userID uuid.UUID) (int, error)
+
+    // Delete deletes a notification of a user
+    Delete(ctx context.Context, notificationID uuid.UUID, userID uuid.UUID) error
+
     // GetUnreadWateringNotifications gets all unread watering notifications that need to be sent
     GetUnreadWateringNotifications(ctx context.Context) ([]*models.Notification, error)
+
+    // GetWateringDigests gets the users who get watering reminders by email and were not emailed on the
+    // given day, with their plants due for watering before dueBefore. Plants in a home other than the
+    // one the user is staying at are left out.
+    GetWateringDigests(ctx context.Context, day time.Time, dueBefore time.Time) ([]*models.WateringDigest, error)
+
+    // ClaimWateringDigest records that the watering reminder email of a user is sent on the given day;
+    // it reports false when the email was already sent that day
+    ClaimWateringDigest(ctx context.Context, userID uuid.UUID, day time.Time) (bool, error)
+
+    // ReleaseWateringDigest undoes the claim of a watering reminder email that could not be sent
+    ReleaseWateringDigest(ctx context.Context, userID uuid.UUID, day time.Time) error
+
+    // ClaimWateringReminders records that the in-app watering reminders of a user are sent for the given
+    // local day of the user; it reports false when they were already sent for that day or a later one
+    ClaimWateringReminders(ctx context.Context, userID uuid.UUID, day time.Time) (bool, error)
 } 
\ No newline at end of file
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// NotificationTemplateRepository defines the interface for notification template overrides
+type NotificationTemplateRepository interface {
+	// Get gets the stored template of a notification type in a language, or nil if none is stored
+	Get(ctx context.Context, notificationType models.NotificationType, language models.Language) (*models.NotificationTemplate, error)
+
+	// GetAll gets all stored templates
+	GetAll(ctx context.Context) ([]*models.NotificationTemplate, error)
+
+	// Upsert creates or replaces the stored template of a notification type in a language
+	Upsert(ctx context.Context, template *models.NotificationTemplate) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// OutdoorLocationRepository defines the interface for the outdoor locations of users
+type OutdoorLocationRepository interface {
+	// List gets the outdoor locations of a user
+	List(ctx context.Context, userID uuid.UUID) ([]*models.OutdoorLocation, error)
+
+	// Upsert marks a location of a user as outdoors or moves it to other coordinates
+	Upsert(ctx context.Context, location *models.OutdoorLocation) error
+
+	// Delete stops treating a location of a user as outdoors
+	Delete(ctx context.Context, userID uuid.UUID, location string) error
+}
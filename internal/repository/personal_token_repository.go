@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PersonalTokenRepository defines the interface for personal access token operations
+type PersonalTokenRepository interface {
+	// Create creates a new personal access token
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+
+	// GetByHash gets an active, unexpired token by the hash of its raw value
+	GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+
+	// GetByUser gets all personal access tokens of a user
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.PersonalAccessToken, error)
+
+	// Revoke revokes a personal access token owned by a user
+	Revoke(ctx context.Context, tokenID uuid.UUID, userID uuid.UUID) error
+
+	// UpdateLastUsed sets the last used time of a token to now
+	UpdateLastUsed(ctx context.Context, tokenID uuid.UUID) error
+}
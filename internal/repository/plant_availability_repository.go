@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantAvailabilityRepository defines the interface for plant availability subscription operations
+type PlantAvailabilityRepository interface {
+	// Upsert subscribes a user to a plant in a city, replacing the city of an existing subscription and
+	// making it wait again if it was notified
+	Upsert(ctx context.Context, subscription *models.PlantAvailabilitySubscription) error
+
+	// GetByUser gets the subscriptions of a user, newest first
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.PlantAvailabilitySubscription, error)
+
+	// Delete removes a user's subscription to a plant
+	Delete(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error
+
+	// ClaimWaiting marks the waiting subscriptions to a plant in a city (compared case-insensitively) as
+	// notified and returns their subscribers, so each subscriber is notified once
+	ClaimWaiting(ctx context.Context, plantID uuid.UUID, city string) ([]*models.PlantAvailabilityRecipient, error)
+}
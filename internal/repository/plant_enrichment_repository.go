@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantEnrichmentRepository defines the interface for plant enrichment operations
+type PlantEnrichmentRepository interface {
+	// GetCandidates gets catalog plants with a scientific name that miss synonyms, an image or
+	// toxicity, the ones checked least recently first
+	GetCandidates(ctx context.Context, limit int) ([]*models.Plant, error)
+
+	// MarkChecked records that the sources were asked for the missing fields of a plant
+	MarkChecked(ctx context.Context, plantID uuid.UUID) error
+
+	// Propose queues a proposed plant change for review and fills its ID, status and creation time.
+	// It reports false when the source already has a pending proposal for the field, or when the
+	// same value was rejected before.
+	Propose(ctx context.Context, proposal *models.PlantEnrichmentProposal) (bool, error)
+
+	// GetPending gets all proposals awaiting review, oldest first
+	GetPending(ctx context.Context) ([]*models.PlantEnrichmentProposal, error)
+
+	// Review sets the status of a pending proposal. An approved proposal is applied to its plant
+	// and the other pending proposals for the same field are rejected, in one transaction.
+	Review(ctx context.Context, id uuid.UUID, status models.PlantEnrichmentStatus, reviewerID uuid.UUID) (*models.PlantEnrichmentProposal, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantEventRepository defines the interface for plant lifecycle event operations
+type PlantEventRepository interface {
+	// Create appends an event to the event stream of a plant in a user's collection
+	Create(ctx context.Context, event *models.PlantEvent) error
+
+	// ListAfter gets up to limit events of a plant in a user's collection recorded after the given
+	// position in the stream, oldest first; only events of the given types when any are given
+	ListAfter(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, afterSeq int64, types []models.PlantEventType, limit int) ([]*models.PlantEvent, error)
+}
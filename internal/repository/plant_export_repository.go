@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// PlantExportRepository defines the interface for reading the whole plant catalog for exports
+type PlantExportRepository interface {
+	// Each calls fn with every plant of the catalog and its care instructions, ordered by name and
+	// read one at a time; plants removed from the catalog are left out. An error of fn stops the
+	// export and is returned.
+	Each(ctx context.Context, fn func(plant *models.Plant) error) error
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantReminderRepository defines the interface for the reminders users define for their plants
+type PlantReminderRepository interface {
+	// ListByUserPlant gets the reminders of a plant in a user's collection, the next due first
+	ListByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.PlantReminder, error)
+
+	// GetByID gets a reminder
+	GetByID(ctx context.Context, id uuid.UUID) (*models.PlantReminder, error)
+
+	// Create stores a new reminder
+	Create(ctx context.Context, reminder *models.PlantReminder) error
+
+	// Update updates the title, frequency and due date of a reminder
+	Update(ctx context.Context, reminder *models.PlantReminder) error
+
+	// Delete removes a reminder
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// GetDue gets the reminders due on or before the given day with the plants they are about
+	GetDue(ctx context.Context, until time.Time) ([]*models.PlantReminder, error)
+
+	// SetNextDue moves a reminder to its next due date
+	SetNextDue(ctx context.Context, id uuid.UUID, nextDue time.Time) error
+}
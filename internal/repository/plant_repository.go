@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/anpanovv/planter/internal/models"
 	"github.com/google/uuid"
@@ -12,11 +13,15 @@ type PlantRepository interface {
 	// GetAll gets all plants
 	GetAll(ctx context.Context) ([]*models.Plant, error)
 	
+	// List gets a page of plants matching the filter, ordered by name, with the total number of matches
+	List(ctx context.Context, filter *models.PlantFilter) ([]*models.Plant, int, error)
+
 	// GetByID gets a plant by ID
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Plant, error)
 	
-	// Search searches for plants by query
-	Search(ctx context.Context, query string) ([]*models.Plant, error)
+	// Search gets the catalog plants whose name, scientific name or description contain the text of
+	// the query and that match its filters, ordered by name
+	Search(ctx context.Context, query *models.PlantSearchQuery) ([]*models.Plant, error)
 	
 	// GetFavorites gets a user's favorite plants
 	GetFavorites(ctx context.Context, userID uuid.UUID) ([]*models.Plant, error)
@@ -36,21 +41,50 @@ type PlantRepository interface {
 	// GetUserPlants gets all plants owned by a user
 	GetUserPlants(ctx context.Context, userID uuid.UUID) ([]*models.Plant, error)
 	
-	// AddUserPlant adds a plant to a user's collection
+	// AddUserPlant adds a plant to a user's collection. A plant already in the collection gets the new
+	// location and watering dates, and keeps its nickname and notes unless new ones are given.
 	AddUserPlant(ctx context.Context, userPlant *models.UserPlant) error
 	
+	// AddUserPlants adds plants to a user's collection like AddUserPlant, all in one transaction
+	AddUserPlants(ctx context.Context, userPlants []*models.UserPlant) error
+	
 	// UpdateUserPlant updates a user's plant
 	UpdateUserPlant(ctx context.Context, userPlant *models.UserPlant) error
 	
+	// SetUserPlantLowEffortMode sets the low effort mode of a plant in a user's collection; nil makes
+	// the plant follow the user's mode
+	SetUserPlantLowEffortMode(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, enabled *bool) error
+	
+	// SetNextWatering moves the next watering of a plant in a user's collection
+	SetNextWatering(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, nextWatering time.Time) error
+
 	// RemoveUserPlant removes a plant from a user's collection
 	RemoveUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error
 	
+	// GetFavoriteChanges gets the plants a user added to or removed from their favorites after since,
+	// all their favorites when since is zero
+	GetFavoriteChanges(ctx context.Context, userID uuid.UUID, since time.Time) (*models.FavoriteChanges, error)
+
 	// IsFavorite checks if a plant is a favorite of a user
 	IsFavorite(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) (bool, error)
 	
-	// CreatePlant creates a new plant
+	// CreatePlant creates a new plant, published unless it is given another review state
 	CreatePlant(ctx context.Context, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, error)
+
+	// UpdatePlant updates a plant in the catalog and its care instructions together. The license of
+	// the image is dropped when the image changes.
+	UpdatePlant(ctx context.Context, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, error)
+
+	// UpdatePlantImage replaces the image of a plant in the catalog and drops the license of the old one
+	UpdatePlantImage(ctx context.Context, plantID uuid.UUID, imageURL models.AssetKey) error
+
+	// DeletePlant removes a plant from the catalog. The plant is soft-deleted so it stays in
+	// collections and favorites.
+	DeletePlant(ctx context.Context, id uuid.UUID) error
 	
 	// GetAllUserPlantsForWateringCheck gets all user plants that need to be checked for watering
 	GetAllUserPlantsForWateringCheck(ctx context.Context) ([]*models.UserPlant, error)
+	
+	// GetPlantsWithCareReviewedBefore gets plants whose care instructions were never reviewed or last reviewed before the given time
+	GetPlantsWithCareReviewedBefore(ctx context.Context, reviewedBefore time.Time) ([]*models.Plant, error)
 }
\ No newline at end of file
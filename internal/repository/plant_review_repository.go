@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantReviewRepository defines the interface for the review states of catalog plants
+type PlantReviewRepository interface {
+	// ListByStatus gets the catalog plants in a review state, the ones that entered it first first
+	ListByStatus(ctx context.Context, status models.PlantStatus) ([]*models.Plant, error)
+
+	// SetStatus moves a catalog plant from one review state to another. It returns sql.ErrNoRows when
+	// the plant is not in the catalog or no longer in the state it is moved from.
+	SetStatus(ctx context.Context, plantID uuid.UUID, from models.PlantStatus, to models.PlantStatus) error
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantSpeciesRepository defines the interface for plant species operations
+type PlantSpeciesRepository interface {
+	// Create creates a species with its default care instructions
+	Create(ctx context.Context, species *models.PlantSpecies) error
+
+	// GetByID gets a species with its default care instructions
+	GetByID(ctx context.Context, id uuid.UUID) (*models.PlantSpecies, error)
+
+	// GetByScientificName gets a species by its scientific name, compared case-insensitively
+	GetByScientificName(ctx context.Context, scientificName string) (*models.PlantSpecies, error)
+
+	// List gets all species ordered by scientific name
+	List(ctx context.Context) ([]*models.PlantSpecies, error)
+
+	// Update replaces a species and its default care instructions, and rewrites the care instructions
+	// of its cultivars with their overrides applied to the new defaults
+	Update(ctx context.Context, species *models.PlantSpecies) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantStatsRepository defines the interface for plant ownership statistics operations
+type PlantStatsRepository interface {
+	// Refresh aggregates the statistics of every catalog plant: the average interval of waterings
+	// logged since wateringSince, and the share of plants owned since before activeSince that were
+	// watered since then. It returns the number of plants aggregated.
+	Refresh(ctx context.Context, wateringSince time.Time, activeSince time.Time, computedAt time.Time) (int, error)
+
+	// GetByPlant gets the statistics of a plant; a plant not aggregated yet has none
+	GetByPlant(ctx context.Context, plantID uuid.UUID) (*models.PlantStats, error)
+}
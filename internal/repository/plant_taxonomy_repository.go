@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantTaxonomyRepository defines the interface for the categories and tags of catalog plants
+type PlantTaxonomyRepository interface {
+	// ListCategories gets all categories by name with the number of published plants in each
+	ListCategories(ctx context.Context) ([]*models.Category, error)
+
+	// GetCategoryBySlug gets a category by its slug
+	GetCategoryBySlug(ctx context.Context, slug string) (*models.Category, error)
+
+	// CreateCategory creates a category
+	CreateCategory(ctx context.Context, category *models.Category) error
+
+	// UpdateCategory updates the slug and name of a category
+	UpdateCategory(ctx context.Context, category *models.Category) error
+
+	// DeleteCategory deletes a category, taking it off its plants
+	DeleteCategory(ctx context.Context, id uuid.UUID) error
+
+	// ListTags gets the tags of published plants, the most used first
+	ListTags(ctx context.Context) ([]*models.TagCount, error)
+
+	// GetPlantTaxonomy gets the category slugs and tags of a plant
+	GetPlantTaxonomy(ctx context.Context, plantID uuid.UUID) (*models.PlantTaxonomy, error)
+
+	// SetPlantTaxonomy replaces the categories and tags of a catalog plant, creating tags it does not
+	// know yet. Category slugs must exist. It returns sql.ErrNoRows when the plant is not in the catalog.
+	SetPlantTaxonomy(ctx context.Context, plantID uuid.UUID, taxonomy *models.PlantTaxonomy) error
+}
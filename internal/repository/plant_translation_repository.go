@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// PlantTranslationRepository defines the interface for the translations of catalog plants
+type PlantTranslationRepository interface {
+	// List gets the translations of a plant by language
+	List(ctx context.Context, plantID uuid.UUID) ([]*models.PlantTranslation, error)
+
+	// GetForPlants gets the translations of plants into a language by plant ID; plants without a
+	// translation are left out
+	GetForPlants(ctx context.Context, plantIDs []uuid.UUID, language models.Language) (map[uuid.UUID]*models.PlantTranslation, error)
+
+	// Upsert creates the translation of a plant into a language or replaces its fields
+	Upsert(ctx context.Context, translation *models.PlantTranslation) error
+
+	// Delete deletes the translation of a plant into a language
+	Delete(ctx context.Context, plantID uuid.UUID, language models.Language) error
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// QuizRepository defines the interface for daily quizzes and their results. Dates are UTC days.
+type QuizRepository interface {
+	// GetDay gets the questions of the quiz of a day
+	GetDay(ctx context.Context, date time.Time) (models.QuizQuestionSet, error)
+
+	// CreateDay stores the questions of the quiz of a day unless it has some already, and returns
+	// the stored questions
+	CreateDay(ctx context.Context, date time.Time, questions models.QuizQuestionSet) (models.QuizQuestionSet, error)
+
+	// GetResult gets the result of a user for the quiz of a day
+	GetResult(ctx context.Context, userID uuid.UUID, date time.Time) (*models.QuizResult, error)
+
+	// SaveResult saves the result of a user for the quiz of a day, setting its streak; it reports
+	// false when the user answered the quiz already
+	SaveResult(ctx context.Context, userID uuid.UUID, date time.Time, result *models.QuizResult) (bool, error)
+
+	// GetLeaderboard gets the quiz scores since a day of a user and the users they follow, most
+	// points first; today is the day current streaks are counted to
+	GetLeaderboard(ctx context.Context, userID uuid.UUID, since time.Time, today time.Time) ([]*models.QuizLeaderboardEntry, error)
+}
@@ -21,7 +21,7 @@ type RecommendationRepository interface {
 	// GetRecommendations gets all recommendations for a questionnaire
 	GetRecommendations(ctx context.Context, questionnaireID uuid.UUID) ([]*models.PlantRecommendation, error)
 	
-	// GetRecommendedPlants gets all recommended plants for a questionnaire
+	// GetRecommendedPlants gets all recommended plants for a questionnaire with why each of them was recommended
 	GetRecommendedPlants(ctx context.Context, questionnaireID uuid.UUID) ([]*models.Plant, error)
 	
 	// SaveDetailedQuestionnaire saves a detailed plant questionnaire
@@ -42,6 +42,40 @@ type RecommendationRepository interface {
 	// GetChatMessages gets all messages for a chat session
 	GetChatMessages(ctx context.Context, sessionID uuid.UUID) ([]*models.ChatMessage, error)
 	
+	// RenameChatSession sets the title of a chat session
+	RenameChatSession(ctx context.Context, sessionID uuid.UUID, title string) error
+
+	// ReplaceChatSessionTitle sets the title of a chat session unless it was changed from oldTitle;
+	// it reports false when the session has another title
+	ReplaceChatSessionTitle(ctx context.Context, sessionID uuid.UUID, oldTitle string, newTitle string) (bool, error)
+
+	// DeleteChatSession deletes a chat session with its messages
+	DeleteChatSession(ctx context.Context, sessionID uuid.UUID) error
+
 	// UpdateChatSessionLastUsed updates the last used timestamp for a chat session
 	UpdateChatSessionLastUsed(ctx context.Context, sessionID uuid.UUID) error
+
+	// GetChatContext gets the conversation context of a chat session
+	GetChatContext(ctx context.Context, sessionID uuid.UUID) (*models.ChatContext, error)
+
+	// SaveChatContext saves the conversation context of a chat session
+	SaveChatContext(ctx context.Context, chatContext *models.ChatContext) error
+
+	// GetChatUsage gets the number of chat sessions and messages of a user and the tokens spent on them
+	GetChatUsage(ctx context.Context, userID uuid.UUID) (*models.ChatUsage, error)
+
+	// EscalateChatSession puts a chat session in the expert queue; it reports false when the session
+	// is already waiting for or being answered by an expert
+	EscalateChatSession(ctx context.Context, sessionID uuid.UUID, reason *string) (bool, error)
+
+	// GetEscalatedChatSessions gets the chat sessions with one of the escalation statuses, longest waiting first
+	GetEscalatedChatSessions(ctx context.Context, statuses []models.ChatEscalationStatus) ([]*models.ChatSession, error)
+
+	// ClaimChatEscalation assigns a waiting escalation to an expert; it reports false when the session is
+	// not waiting or is claimed by another expert
+	ClaimChatEscalation(ctx context.Context, sessionID uuid.UUID, expertID uuid.UUID) (bool, error)
+
+	// ResolveChatEscalation closes an escalation claimed by the expert; it reports false when the
+	// expert has not claimed it
+	ResolveChatEscalation(ctx context.Context, sessionID uuid.UUID, expertID uuid.UUID) (bool, error)
 }
\ No newline at end of file
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// ReconciliationRepository defines the interface for detecting and repairing inconsistent derived data
+type ReconciliationRepository interface {
+	// RunCheck counts the inconsistencies found by a check and repairs them unless dryRun is set
+	RunCheck(ctx context.Context, check models.ReconciliationCheck, dryRun bool) (int, error)
+
+	// SaveRun saves a reconciliation run with its corrections
+	SaveRun(ctx context.Context, run *models.ReconciliationRun) error
+
+	// GetRuns gets the most recent reconciliation runs
+	GetRuns(ctx context.Context, limit int) ([]*models.ReconciliationRun, error)
+}
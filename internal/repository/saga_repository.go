@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// SagaRepository defines the interface for the persisted state of sagas
+type SagaRepository interface {
+	// Create stores a new saga
+	Create(ctx context.Context, saga *models.Saga) error
+
+	// Update stores the status, current step, completed steps, data and error of a saga
+	Update(ctx context.Context, saga *models.Saga) error
+
+	// ListStuck gets the sagas still running or compensating that were last updated before a time, and
+	// the sagas whose steps could not be undone, the longest stuck first
+	ListStuck(ctx context.Context, updatedBefore time.Time, limit int) ([]*models.Saga, error)
+}
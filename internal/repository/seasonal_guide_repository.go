@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// SeasonalGuideRepository defines the interface for seasonal guide operations
+type SeasonalGuideRepository interface {
+	// ListOverrides gets the overrides of a month by list and position, or of every month when month is 0
+	ListOverrides(ctx context.Context, month int) ([]*models.SeasonalGuideOverride, error)
+
+	// UpsertOverride stores an override, replacing the override of the same plant in the same list and month
+	UpsertOverride(ctx context.Context, override *models.SeasonalGuideOverride) error
+
+	// DeleteOverride removes an override
+	DeleteOverride(ctx context.Context, id uuid.UUID) error
+
+	// GetOwnerCounts gets the number of users owning each catalog plant, as last aggregated by the
+	// plant stats; plants not aggregated yet are left out
+	GetOwnerCounts(ctx context.Context) (map[uuid.UUID]int, error)
+}
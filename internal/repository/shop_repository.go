@@ -9,15 +9,48 @@ import (
 
 // ShopRepository defines the interface for shop operations
 type ShopRepository interface {
-	// GetAll gets all shops
+	// GetAll gets all shops that are not deleted
 	GetAll(ctx context.Context) ([]*models.Shop, error)
 	
-	// GetByID gets a shop by ID
+	// Create creates a new shop
+	Create(ctx context.Context, shop *models.Shop) error
+	
+	// GetByID gets a shop by ID; deleted shops are not found
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Shop, error)
+
+	// Update updates the name, address, rating, image and coordinates of a shop
+	Update(ctx context.Context, shop *models.Shop) error
+
+	// Delete soft-deletes a shop: it and its offers are hidden but kept for the audit log
+	Delete(ctx context.Context, id uuid.UUID) error
 	
 	// GetPlants gets all plants from a shop
 	GetPlants(ctx context.Context, shopID uuid.UUID) ([]*models.Plant, error)
 	
 	// GetSpecialOffers gets all special offers
 	GetSpecialOffers(ctx context.Context) ([]*models.SpecialOffer, error)
+
+	// GetPlantOffers gets the offers of all shops selling a plant, deleted shops left out
+	GetPlantOffers(ctx context.Context, plantID uuid.UUID) ([]*models.PlantOffer, error)
+
+	// UpdateShopPlant updates the price and stock attributes of a shop's plant
+	UpdateShopPlant(ctx context.Context, shopPlant *models.ShopPlant) error
+
+	// AddShopPlant has a shop sell a catalog plant, reporting false when the shop already sells it
+	AddShopPlant(ctx context.Context, shopPlant *models.ShopPlant) (bool, error)
+
+	// RemoveShopPlant stops a shop selling a plant
+	RemoveShopPlant(ctx context.Context, shopID uuid.UUID, plantID uuid.UUID) error
+
+	// ListSpecialOffers gets all special offers, expired ones included, the latest ending first
+	ListSpecialOffers(ctx context.Context) ([]*models.SpecialOffer, error)
+
+	// CreateSpecialOffer creates a special offer
+	CreateSpecialOffer(ctx context.Context, offer *models.SpecialOffer) error
+
+	// UpdateSpecialOffer updates a special offer
+	UpdateSpecialOffer(ctx context.Context, offer *models.SpecialOffer) error
+
+	// DeleteSpecialOffer deletes a special offer
+	DeleteSpecialOffer(ctx context.Context, id uuid.UUID) error
 }
\ No newline at end of file
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// SupportTicketRepository defines the interface for support ticket operations
+type SupportTicketRepository interface {
+	// Create stores a new support ticket
+	Create(ctx context.Context, ticket *models.SupportTicket) error
+
+	// GetByID gets a support ticket by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.SupportTicket, error)
+
+	// List gets a page of support tickets, oldest first, with the total number matching the filter
+	List(ctx context.Context, status models.SupportTicketStatus, limit int, offset int) ([]*models.SupportTicket, int, error)
+
+	// UpdateStatus sets the status and admin note of a support ticket
+	UpdateStatus(ctx context.Context, id uuid.UUID, status models.SupportTicketStatus, adminNote *string) (*models.SupportTicket, error)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// UserPlantPhotoRepository defines the interface for operations on photos of plants in users' collections
+type UserPlantPhotoRepository interface {
+	// Create saves a photo of a plant in a user's collection
+	Create(ctx context.Context, photo *models.UserPlantPhoto) error
+
+	// GetByID gets a photo
+	GetByID(ctx context.Context, id uuid.UUID) (*models.UserPlantPhoto, error)
+
+	// ListByUserPlant gets the photos of a plant in a user's collection, newest first
+	ListByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.UserPlantPhoto, error)
+
+	// ListByUser gets the photos of all plants in a user's collection, newest first
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.UserPlantPhoto, error)
+
+	// ListQuarantined gets the photos awaiting an admin's review, oldest first
+	ListQuarantined(ctx context.Context) ([]*models.UserPlantPhoto, error)
+
+	// Approve approves a quarantined photo, so it goes live
+	Approve(ctx context.Context, id uuid.UUID) (*models.UserPlantPhoto, error)
+
+	// Delete deletes a photo
+	Delete(ctx context.Context, id uuid.UUID) error
+}
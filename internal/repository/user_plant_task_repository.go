@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// UserPlantTaskRepository defines the interface for recurring care task operations
+type UserPlantTaskRepository interface {
+	// GetByUserPlant gets the recurring tasks of a plant in a user's collection
+	GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.UserPlantTask, error)
+
+	// AddMissing stores the tasks whose type the plant has no task of yet and leaves the others untouched
+	AddMissing(ctx context.Context, tasks []*models.UserPlantTask) error
+
+	// Replace replaces the recurring tasks of a plant in a user's collection
+	Replace(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, tasks []*models.UserPlantTask) error
+
+	// GetDue gets the tasks due on or before the given day with the plants they are about
+	GetDue(ctx context.Context, until time.Time) ([]*models.UserPlantTask, error)
+
+	// SetNextDue moves a task to its next due date
+	SetNextDue(ctx context.Context, taskID uuid.UUID, nextDue time.Time) error
+}
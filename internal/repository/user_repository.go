@@ -9,10 +9,10 @@ import (
 
 // UserRepository defines the interface for user operations
 type UserRepository interface {
-	// GetByID gets a user by ID
+	// GetByID gets a user by ID; deleted users are not found
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	
-	// GetByEmail gets a user by email
+	// GetByEmail gets a user by email; deleted users are not found
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	
 	// Create creates a new user
@@ -21,6 +21,9 @@ type UserRepository interface {
 	// Update updates a user
 	Update(ctx context.Context, user *models.User) error
 	
+	// SetLowEffortMode turns the low effort mode of a user on or off
+	SetLowEffortMode(ctx context.Context, userID uuid.UUID, enabled bool) error
+	
 	// GetLocations gets a user's locations
 	GetLocations(ctx context.Context, userID uuid.UUID) ([]string, error)
 	
@@ -35,4 +38,17 @@ type UserRepository interface {
 	
 	// GetOwnedPlantIDs gets a user's owned plant IDs
 	GetOwnedPlantIDs(ctx context.Context, userID uuid.UUID) ([]string, error)
+
+	// GetRoles gets a user's roles
+	GetRoles(ctx context.Context, userID uuid.UUID) ([]string, error)
+
+	// AddRole grants a role to a user; granting a role the user already has does nothing
+	AddRole(ctx context.Context, userID uuid.UUID, role models.Role) error
+
+	// GetByRole gets the users granted a role
+	GetByRole(ctx context.Context, role models.Role) ([]*models.User, error)
+
+	// SoftDelete marks a user as deleted: the account can no longer be used or found, but its data is
+	// kept for the audit log and its email stays taken
+	SoftDelete(ctx context.Context, userID uuid.UUID) error
 }
\ No newline at end of file
@@ -0,0 +1,135 @@
+// Package saga runs operations that span several subsystems as a sequence of steps, each with a
+// compensation that undoes it: when a step fails, the steps completed before it are compensated in
+// reverse order, e.g. the stock a reservation took is released when its notification cannot be
+// enqueued. The state of every saga is persisted as it advances, so sagas stuck between steps, because
+// the instance running them stopped, or whose compensation failed can be found and fixed by an admin.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+)
+
+// ErrCompensationFailed is returned when a step failed and a completed step could not be undone, so the
+// saga is left for an admin
+var ErrCompensationFailed = errors.New("saga compensation failed")
+
+const (
+	// DefaultStuckAfter is how long a saga may go without advancing before it is considered stuck
+	DefaultStuckAfter = 15 * time.Minute
+
+	// maxStuckSagas is the number of stuck sagas listed at once
+	maxStuckSagas = 100
+)
+
+// Step is a step of a saga. Action does the step and Compensate undoes it; both may read and add to the
+// data the steps share, which is persisted after every step. Steps without a compensation, such as a
+// last step that cannot fail afterwards, leave nothing to undo.
+type Step struct {
+	Name       string
+	Action     func(ctx context.Context, data models.SagaData) error
+	Compensate func(ctx context.Context, data models.SagaData) error
+}
+
+// Orchestrator runs sagas and persists their state
+type Orchestrator struct {
+	repo repository.SagaRepository
+	now  func() time.Time
+}
+
+// NewOrchestrator creates a new saga orchestrator
+func NewOrchestrator(repo repository.SagaRepository) *Orchestrator {
+	return &Orchestrator{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// Run runs the steps of a saga in order. When a step fails the completed steps are compensated in
+// reverse order and the error of the step is returned; when a compensation fails as well, the saga is
+// marked as failed and ErrCompensationFailed is returned with both errors. The saga is not started when
+// its state cannot be stored; later failures to store it are logged, and the saga shows up as stuck.
+func (o *Orchestrator) Run(ctx context.Context, name string, data models.SagaData, steps ...Step) (*models.Saga, error) {
+	if data == nil {
+		data = models.SagaData{}
+	}
+	saga := &models.Saga{Name: name, Status: models.SagaStatusRunning, Data: data}
+	if err := o.repo.Create(ctx, saga); err != nil {
+		return nil, fmt.Errorf("failed to start saga %s: %w", name, err)
+	}
+
+	for i, step := range steps {
+		saga.CurrentStep = step.Name
+		o.save(ctx, saga)
+
+		if err := step.Action(ctx, data); err != nil {
+			return saga, o.compensate(ctx, saga, steps[:i], fmt.Errorf("step %s of saga %s failed: %w", step.Name, name, err))
+		}
+		saga.Completed = i + 1
+	}
+
+	saga.Status = models.SagaStatusCompleted
+	o.save(ctx, saga)
+	return saga, nil
+}
+
+// compensate undoes the completed steps of a saga after one failed, the last completed first
+func (o *Orchestrator) compensate(ctx context.Context, saga *models.Saga, completed []Step, cause error) error {
+	// Undo the steps even when the request that ran the saga is cancelled
+	ctx = context.WithoutCancel(ctx)
+
+	message := cause.Error()
+	saga.Status = models.SagaStatusCompensating
+	saga.Error = &message
+	o.save(ctx, saga)
+
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := completed[i]
+		if step.Compensate == nil {
+			saga.Completed = i
+			continue
+		}
+		saga.CurrentStep = step.Name
+		if err := step.Compensate(ctx, saga.Data); err != nil {
+			failure := fmt.Errorf("%w: undoing step %s: %w, after %w", ErrCompensationFailed, step.Name, err, cause)
+			message := failure.Error()
+			saga.Status = models.SagaStatusFailed
+			saga.Error = &message
+			o.save(ctx, saga)
+			return failure
+		}
+		saga.Completed = i
+		o.save(ctx, saga)
+	}
+
+	saga.Status = models.SagaStatusCompensated
+	o.save(ctx, saga)
+	return cause
+}
+
+// save stores the state of a saga, logging a failure; a saga that cannot be stored goes on and shows
+// up as stuck
+func (o *Orchestrator) save(ctx context.Context, saga *models.Saga) {
+	if err := o.repo.Update(ctx, saga); err != nil {
+		log.Printf("Failed to save state of saga %s (%s): %v", saga.ID, saga.Name, err)
+	}
+}
+
+// ListStuck gets the sagas that did not advance for longer than stuckAfter and the sagas whose steps
+// could not be undone, the longest stuck first
+func (o *Orchestrator) ListStuck(ctx context.Context, stuckAfter time.Duration) ([]*models.Saga, error) {
+	if stuckAfter <= 0 {
+		stuckAfter = DefaultStuckAfter
+	}
+	sagas, err := o.repo.ListStuck(ctx, o.now().Add(-stuckAfter), maxStuckSagas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stuck sagas: %w", err)
+	}
+	return sagas, nil
+}
@@ -0,0 +1,137 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSagaRepository is a mock implementation of the SagaRepository interface that keeps the statuses
+// a saga was saved with
+type MockSagaRepository struct {
+	mock.Mock
+	statuses []models.SagaStatus
+}
+
+func (m *MockSagaRepository) Create(ctx context.Context, saga *models.Saga) error {
+	args := m.Called(ctx, saga)
+	saga.ID = uuid.New()
+	return args.Error(0)
+}
+
+func (m *MockSagaRepository) Update(ctx context.Context, saga *models.Saga) error {
+	m.statuses = append(m.statuses, saga.Status)
+	return nil
+}
+
+func (m *MockSagaRepository) ListStuck(ctx context.Context, updatedBefore time.Time, limit int) ([]*models.Saga, error) {
+	args := m.Called(ctx, updatedBefore, limit)
+	return args.Get(0).([]*models.Saga), args.Error(1)
+}
+
+// reservationSteps are the steps of a reservation whose stock is tracked in stock; the notification
+// step fails with notifyErr and the stock is released with releaseErr
+func reservationSteps(stock *int, notifyErr error, releaseErr error) []Step {
+	return []Step{
+		{
+			Name: "reserve_stock",
+			Action: func(ctx context.Context, data models.SagaData) error {
+				*stock--
+				data["reservationId"] = "r-1"
+				return nil
+			},
+			Compensate: func(ctx context.Context, data models.SagaData) error {
+				if releaseErr != nil {
+					return releaseErr
+				}
+				*stock++
+				return nil
+			},
+		},
+		{
+			Name: "enqueue_notification",
+			Action: func(ctx context.Context, data models.SagaData) error {
+				return notifyErr
+			},
+		},
+	}
+}
+
+// TestOrchestrator_Run tests that completed sagas keep the data of their steps
+func TestOrchestrator_Run(t *testing.T) {
+	repo := new(MockSagaRepository)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	stock := 3
+
+	saga, err := NewOrchestrator(repo).Run(context.Background(), "reservation", nil, reservationSteps(&stock, nil, nil)...)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stock)
+	assert.Equal(t, models.SagaStatusCompleted, saga.Status)
+	assert.Equal(t, 2, saga.Completed)
+	assert.Equal(t, "r-1", saga.Data["reservationId"])
+}
+
+// TestOrchestrator_Run_Compensates tests that the stock is released when the notification fails
+func TestOrchestrator_Run_Compensates(t *testing.T) {
+	repo := new(MockSagaRepository)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	stock := 3
+	notifyErr := errors.New("queue unavailable")
+
+	saga, err := NewOrchestrator(repo).Run(context.Background(), "reservation", nil, reservationSteps(&stock, notifyErr, nil)...)
+	assert.ErrorIs(t, err, notifyErr)
+	assert.Equal(t, 3, stock)
+	assert.Equal(t, models.SagaStatusCompensated, saga.Status)
+	assert.Equal(t, 0, saga.Completed)
+	assert.Contains(t, repo.statuses, models.SagaStatusCompensating)
+}
+
+// TestOrchestrator_Run_CompensationFails tests that sagas whose steps cannot be undone are left for an admin
+func TestOrchestrator_Run_CompensationFails(t *testing.T) {
+	repo := new(MockSagaRepository)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	stock := 3
+	notifyErr, releaseErr := errors.New("queue unavailable"), errors.New("connection refused")
+
+	saga, err := NewOrchestrator(repo).Run(context.Background(), "reservation", nil, reservationSteps(&stock, notifyErr, releaseErr)...)
+	assert.ErrorIs(t, err, ErrCompensationFailed)
+	assert.ErrorIs(t, err, notifyErr)
+	assert.ErrorIs(t, err, releaseErr)
+	assert.Equal(t, 2, stock)
+	assert.Equal(t, models.SagaStatusFailed, saga.Status)
+	assert.Equal(t, "reserve_stock", saga.CurrentStep)
+	assert.Equal(t, 1, saga.Completed)
+}
+
+// TestOrchestrator_Run_NotStarted tests that no step runs when the saga cannot be stored
+func TestOrchestrator_Run_NotStarted(t *testing.T) {
+	repo := new(MockSagaRepository)
+	repo.On("Create", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+	stock := 3
+
+	_, err := NewOrchestrator(repo).Run(context.Background(), "reservation", nil, reservationSteps(&stock, nil, nil)...)
+	assert.Error(t, err)
+	assert.Equal(t, 3, stock)
+}
+
+// TestOrchestrator_ListStuck tests that sagas are stuck after the default time unless told otherwise
+func TestOrchestrator_ListStuck(t *testing.T) {
+	repo := new(MockSagaRepository)
+	orchestrator := NewOrchestrator(repo)
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	orchestrator.now = func() time.Time { return now }
+	repo.On("ListStuck", mock.Anything, now.Add(-DefaultStuckAfter), maxStuckSagas).Return([]*models.Saga{}, nil)
+	repo.On("ListStuck", mock.Anything, now.Add(-time.Hour), maxStuckSagas).Return([]*models.Saga{}, nil)
+
+	_, err := orchestrator.ListStuck(context.Background(), 0)
+	assert.NoError(t, err)
+	_, err = orchestrator.ListStuck(context.Background(), time.Hour)
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
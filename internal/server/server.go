@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Timeouts holds the limits of the HTTP server
+type Timeouts struct {
+	Read     time.Duration // reading a whole request, body included
+	Write    time.Duration // writing the response, measured from the end of the request headers
+	Idle     time.Duration // keeping an idle keep-alive connection open
+	Shutdown time.Duration // draining in-flight requests on shutdown
+}
+
+// Server is an HTTP server that drains in-flight requests when its context is cancelled
+type Server struct {
+	httpServer      *http.Server
+	shutdownTimeout time.Duration
+}
+
+// New creates a new server listening on the address
+func New(addr string, handler http.Handler, timeouts Timeouts) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadTimeout:       timeouts.Read,
+			ReadHeaderTimeout: timeouts.Read,
+			WriteTimeout:      timeouts.Write,
+			IdleTimeout:       timeouts.Idle,
+		},
+		shutdownTimeout: timeouts.Shutdown,
+	}
+}
+
+// RegisterOnShutdown registers a function to call when the server shuts down, e.g. to close hijacked
+// connections such as WebSockets, which the server neither closes nor waits for
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.httpServer.RegisterOnShutdown(f)
+}
+
+// Run listens on the server address and serves until the context is cancelled
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on the listener until the context is cancelled, then stops accepting connections
+// and waits up to the shutdown timeout for in-flight requests to finish
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errChan:
+		return fmt.Errorf("server stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down the server, waiting up to %s for in-flight requests", s.shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		// Cut off the requests that did not finish in time
+		s.httpServer.Close()
+		return fmt.Errorf("failed to shut down the server: %w", err)
+	}
+
+	if err := <-errChan; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+	return nil
+}
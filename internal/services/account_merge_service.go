@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidAccountMerge is returned when the source and target are not two different accounts
+	ErrInvalidAccountMerge = errors.New("source and target must be two different accounts")
+
+	// ErrAccountNotMergeable is returned when either account does not exist or was merged or anonymized
+	ErrAccountNotMergeable = errors.New("both accounts must exist and be neither merged nor anonymized")
+)
+
+// MergeAccounts merges the source account into the target account on behalf of an admin. The target
+// keeps its profile and gets the source's collection, favorites, chats, notifications and history;
+// where both accounts have an equivalent row, the target's wins. The source account is closed.
+// A dry run only reports what would be moved.
+func (s *UserService) MergeAccounts(ctx context.Context, adminID uuid.UUID, req *models.AccountMergeRequest, dryRun bool) (*models.AccountMerge, error) {
+	if req.SourceUserID == uuid.Nil || req.TargetUserID == uuid.Nil || req.SourceUserID == req.TargetUserID {
+		return nil, ErrInvalidAccountMerge
+	}
+
+	merge := &models.AccountMerge{
+		SourceUserID: req.SourceUserID,
+		TargetUserID: req.TargetUserID,
+		MergedBy:     &adminID,
+		Method:       models.AccountMergeMethodAdmin,
+		DryRun:       dryRun,
+	}
+	merged, err := s.mergeRepo.Merge(ctx, merge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge accounts: %w", err)
+	}
+	if !merged {
+		return nil, ErrAccountNotMergeable
+	}
+	return merge, nil
+}
+
+// GetAccountMerges gets the most recent account merges
+func (s *UserService) GetAccountMerges(ctx context.Context, limit int) ([]*models.AccountMerge, error) {
+	if limit < 1 || limit > 100 {
+		limit = 30
+	}
+
+	merges, err := s.mergeRepo.List(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account merges: %w", err)
+	}
+	return merges, nil
+}
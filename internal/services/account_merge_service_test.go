@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAccountMergeRepository is a mock implementation of the AccountMergeRepository interface
+type MockAccountMergeRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccountMergeRepository) Merge(ctx context.Context, merge *models.AccountMerge) (bool, error) {
+	args := m.Called(ctx, merge)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAccountMergeRepository) List(ctx context.Context, limit int) ([]*models.AccountMerge, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]*models.AccountMerge), args.Error(1)
+}
+
+// TestUserService_MergeAccounts tests that admins merge two different open accounts and that the merge is attributed to them
+func TestUserService_MergeAccounts(t *testing.T) {
+	mockMergeRepo := new(MockAccountMergeRepository)
+	userService := NewUserService(new(MockUserRepository))
+	userService.SetAccountMergeRepository(mockMergeRepo)
+	ctx := context.Background()
+	adminID, sourceID, targetID := uuid.New(), uuid.New(), uuid.New()
+
+	_, err := userService.MergeAccounts(ctx, adminID, &models.AccountMergeRequest{SourceUserID: sourceID, TargetUserID: sourceID}, false)
+	assert.ErrorIs(t, err, ErrInvalidAccountMerge)
+
+	mockMergeRepo.On("Merge", ctx, mock.MatchedBy(func(m *models.AccountMerge) bool {
+		return m.SourceUserID == sourceID && m.TargetUserID == targetID && *m.MergedBy == adminID &&
+			m.Method == models.AccountMergeMethodAdmin && m.DryRun
+	})).Return(true, nil).Once()
+	merge, err := userService.MergeAccounts(ctx, adminID, &models.AccountMergeRequest{SourceUserID: sourceID, TargetUserID: targetID}, true)
+	assert.NoError(t, err)
+	assert.True(t, merge.DryRun)
+
+	// Accounts already merged or anonymized are refused
+	mockMergeRepo.On("Merge", ctx, mock.Anything).Return(false, nil).Once()
+	_, err = userService.MergeAccounts(ctx, adminID, &models.AccountMergeRequest{SourceUserID: sourceID, TargetUserID: targetID}, false)
+	assert.ErrorIs(t, err, ErrAccountNotMergeable)
+	mockMergeRepo.AssertExpectations(t)
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidEvent is returned for analytics events that do not match the schema of their type
+var ErrInvalidEvent = errors.New("invalid analytics event")
+
+// ErrEventBufferFull is returned when events arrive faster than they can be written
+var ErrEventBufferFull = errors.New("analytics event buffer is full")
+
+const (
+	// eventFlushBatchSize is the number of buffered events that triggers an early flush
+	eventFlushBatchSize = 200
+
+	// eventBufferLimit is the maximum number of events kept in memory while the store is unavailable
+	eventBufferLimit = 10000
+
+	// eventMaxAge is how old an event may be when it is reported, e.g. after the app was offline
+	eventMaxAge = 7 * 24 * time.Hour
+
+	// eventMaxClockSkew is how far in the future a client clock may report an event
+	eventMaxClockSkew = 5 * time.Minute
+)
+
+// AnalyticsService validates client analytics events and writes them to the event store in batches
+type AnalyticsService struct {
+	eventRepo     repository.EventRepository
+	flushInterval time.Duration
+
+	mu        sync.Mutex
+	buffer    []*models.AnalyticsEvent
+	flushChan chan struct{}
+	stopChan  chan struct{}
+	doneChan  chan struct{}
+}
+
+// NewAnalyticsService creates a new analytics service that flushes buffered events every flushInterval
+func NewAnalyticsService(eventRepo repository.EventRepository, flushInterval time.Duration) *AnalyticsService {
+	return &AnalyticsService{
+		eventRepo:     eventRepo,
+		flushInterval: flushInterval,
+		flushChan:     make(chan struct{}, 1),
+		stopChan:      make(chan struct{}),
+		doneChan:      make(chan struct{}),
+	}
+}
+
+// TrackEvents validates a batch of events and buffers them for writing; the batch is accepted or rejected as a whole
+func (s *AnalyticsService) TrackEvents(ctx context.Context, userID *uuid.UUID, events []*models.AnalyticsEvent) (int, error) {
+	now := time.Now()
+	for i, event := range events {
+		if err := validateEvent(event, now); err != nil {
+			return 0, fmt.Errorf("%w: event %d: %v", ErrInvalidEvent, i, err)
+		}
+	}
+
+	// The user is taken from the authentication, never from the payload
+	for _, event := range events {
+		event.UserID = userID
+		event.ReceivedAt = now
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.buffer)+len(events) > eventBufferLimit {
+		return 0, ErrEventBufferFull
+	}
+	s.buffer = append(s.buffer, events...)
+
+	// Wake up the writer early once a full batch is waiting
+	if len(s.buffer) >= eventFlushBatchSize {
+		select {
+		case s.flushChan <- struct{}{}:
+		default:
+		}
+	}
+
+	return len(events), nil
+}
+
+// Flush writes all buffered events to the event store; events that could not be written are kept for the next flush
+func (s *AnalyticsService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.buffer
+	s.buffer = nil
+	s.mu.Unlock()
+
+	for start := 0; start < len(pending); start += eventFlushBatchSize {
+		end := start + eventFlushBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+
+		if err := s.eventRepo.CreateBatch(ctx, pending[start:end]); err != nil {
+			s.requeue(pending[start:])
+			return fmt.Errorf("failed to write analytics events: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// requeue puts unwritten events back in front of the buffer, dropping the oldest ones over the limit
+func (s *AnalyticsService) requeue(events []*models.AnalyticsEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buffer = append(events, s.buffer...)
+	if dropped := len(s.buffer) - eventBufferLimit; dropped > 0 {
+		log.Printf("Dropping %d analytics events over the buffer limit", dropped)
+		s.buffer = s.buffer[dropped:]
+	}
+}
+
+// Start starts writing buffered events in the background
+func (s *AnalyticsService) Start() {
+	ticker := time.NewTicker(s.flushInterval)
+	go func() {
+		defer close(s.doneChan)
+		for {
+			select {
+			case <-ticker.C:
+			case <-s.flushChan:
+			case <-s.stopChan:
+				ticker.Stop()
+				if err := s.Flush(context.Background()); err != nil {
+					log.Printf("Error flushing analytics events on shutdown: %v", err)
+				}
+				return
+			}
+
+			if err := s.Flush(context.Background()); err != nil {
+				log.Printf("Error flushing analytics events: %v", err)
+			}
+		}
+	}()
+}
+
+// Stop stops the background writer after a final flush
+func (s *AnalyticsService) Stop() {
+	close(s.stopChan)
+	<-s.doneChan
+}
+
+// GetDailyCounts gets the number of events per day, type and A/B variant for the last given number of days
+func (s *AnalyticsService) GetDailyCounts(ctx context.Context, days int) ([]*models.AnalyticsEventCount, error) {
+	if days < 1 {
+		days = 7
+	}
+
+	since := truncateToDay(time.Now()).AddDate(0, 0, -(days - 1))
+	counts, err := s.eventRepo.GetDailyCounts(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analytics event counts: %w", err)
+	}
+	return counts, nil
+}
+
+// validateEvent checks the fields required by the event type and the event time
+func validateEvent(event *models.AnalyticsEvent, now time.Time) error {
+	switch event.Type {
+	case models.AnalyticsEventTypeScreenView:
+		if event.Screen == nil || *event.Screen == "" {
+			return errors.New("screen is required for SCREEN_VIEW")
+		}
+	case models.AnalyticsEventTypeRecommendationClick:
+		if event.PlantID == nil {
+			return errors.New("plantId is required for RECOMMENDATION_CLICK")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", event.Type)
+	}
+
+	if event.OccurredAt.After(now.Add(eventMaxClockSkew)) {
+		return errors.New("occurredAt is in the future")
+	}
+	if event.OccurredAt.Before(now.Add(-eventMaxAge)) {
+		return errors.New("occurredAt is too old")
+	}
+
+	return nil
+}
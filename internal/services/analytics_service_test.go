@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEventRepository is a mock implementation of the EventRepository interface
+type MockEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockEventRepository) CreateBatch(ctx context.Context, events []*models.AnalyticsEvent) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *MockEventRepository) GetDailyCounts(ctx context.Context, since time.Time) ([]*models.AnalyticsEventCount, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]*models.AnalyticsEventCount), args.Error(1)
+}
+
+func screenViewEvent(screen string) *models.AnalyticsEvent {
+	return &models.AnalyticsEvent{
+		SessionID:  "session-1",
+		Type:       models.AnalyticsEventTypeScreenView,
+		Screen:     &screen,
+		OccurredAt: time.Now().Add(-time.Minute),
+	}
+}
+
+// TestAnalyticsService_TrackEvents_Validation tests the per-type schema and event time checks
+func TestAnalyticsService_TrackEvents_Validation(t *testing.T) {
+	service := NewAnalyticsService(new(MockEventRepository), time.Minute)
+	ctx := context.Background()
+
+	// A recommendation click needs the plant
+	click := &models.AnalyticsEvent{
+		SessionID:  "session-1",
+		Type:       models.AnalyticsEventTypeRecommendationClick,
+		OccurredAt: time.Now(),
+	}
+	_, err := service.TrackEvents(ctx, nil, []*models.AnalyticsEvent{screenViewEvent("home"), click})
+	assert.True(t, errors.Is(err, ErrInvalidEvent))
+
+	// Events from the future or too long ago are rejected
+	future := screenViewEvent("home")
+	future.OccurredAt = time.Now().Add(time.Hour)
+	_, err = service.TrackEvents(ctx, nil, []*models.AnalyticsEvent{future})
+	assert.True(t, errors.Is(err, ErrInvalidEvent))
+
+	old := screenViewEvent("home")
+	old.OccurredAt = time.Now().AddDate(0, 0, -30)
+	_, err = service.TrackEvents(ctx, nil, []*models.AnalyticsEvent{old})
+	assert.True(t, errors.Is(err, ErrInvalidEvent))
+
+	// Rejected batches are not buffered
+	assert.Empty(t, service.buffer)
+}
+
+// TestAnalyticsService_Flush tests that buffered events are written with the authenticated user
+func TestAnalyticsService_Flush(t *testing.T) {
+	mockRepo := new(MockEventRepository)
+	service := NewAnalyticsService(mockRepo, time.Minute)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	plantID := uuid.New()
+	click := &models.AnalyticsEvent{
+		SessionID:  "session-1",
+		Type:       models.AnalyticsEventTypeRecommendationClick,
+		PlantID:    &plantID,
+		OccurredAt: time.Now(),
+	}
+	accepted, err := service.TrackEvents(ctx, &userID, []*models.AnalyticsEvent{screenViewEvent("home"), click})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, accepted)
+
+	mockRepo.On("CreateBatch", ctx, mock.MatchedBy(func(events []*models.AnalyticsEvent) bool {
+		return len(events) == 2 && *events[0].UserID == userID && !events[1].ReceivedAt.IsZero()
+	})).Return(nil).Once()
+
+	assert.NoError(t, service.Flush(ctx))
+	assert.Empty(t, service.buffer)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAnalyticsService_Flush_Failure tests that events are kept for the next flush when the write fails
+func TestAnalyticsService_Flush_Failure(t *testing.T) {
+	mockRepo := new(MockEventRepository)
+	service := NewAnalyticsService(mockRepo, time.Minute)
+	ctx := context.Background()
+
+	_, err := service.TrackEvents(ctx, nil, []*models.AnalyticsEvent{screenViewEvent("home")})
+	assert.NoError(t, err)
+
+	mockRepo.On("CreateBatch", ctx, mock.Anything).Return(fmt.Errorf("database unavailable")).Once()
+	assert.Error(t, service.Flush(ctx))
+	assert.Len(t, service.buffer, 1)
+
+	mockRepo.On("CreateBatch", ctx, mock.Anything).Return(nil).Once()
+	assert.NoError(t, service.Flush(ctx))
+	assert.Empty(t, service.buffer)
+}
+
+// TestAnalyticsService_TrackEvents_BufferFull tests that batches over the buffer limit are rejected
+func TestAnalyticsService_TrackEvents_BufferFull(t *testing.T) {
+	service := NewAnalyticsService(new(MockEventRepository), time.Minute)
+	ctx := context.Background()
+
+	service.buffer = make([]*models.AnalyticsEvent, eventBufferLimit)
+	_, err := service.TrackEvents(ctx, nil, []*models.AnalyticsEvent{screenViewEvent("home")})
+	assert.True(t, errors.Is(err, ErrEventBufferFull))
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidAnnouncement is returned for announcements without a message in the default language or
+// a blank city
+var ErrInvalidAnnouncement = errors.New("invalid announcement")
+
+// ErrAnnouncementFinished is returned when canceling an announcement that was completed or canceled
+var ErrAnnouncementFinished = errors.New("announcement is no longer in progress")
+
+// defaultAnnouncementBatchSize is the number of recipients an announcement is sent to at a time
+const defaultAnnouncementBatchSize = 500
+
+// AnnouncementService broadcasts announcements of admins to segments of the users as notifications.
+// The job sends them in batches of recipients ordered by ID; a batch is claimed before it is sent, so
+// instances running the job at the same time do not send it twice and a canceled announcement stops
+// after the batch being sent. A batch interrupted by a restart is not sent again.
+type AnnouncementService struct {
+	announcementRepo    repository.AnnouncementRepository
+	notificationService *NotificationService
+	batchSize           int
+}
+
+// NewAnnouncementService creates a new announcement service
+func NewAnnouncementService(announcementRepo repository.AnnouncementRepository, notificationService *NotificationService) *AnnouncementService {
+	return &AnnouncementService{
+		announcementRepo:    announcementRepo,
+		notificationService: notificationService,
+		batchSize:           defaultAnnouncementBatchSize,
+	}
+}
+
+// CreateAnnouncement creates an announcement of an admin, which the job starts sending on its next run
+func (s *AnnouncementService) CreateAnnouncement(ctx context.Context, adminID uuid.UUID, req *models.CreateAnnouncementRequest) (*models.Announcement, error) {
+	messages := make(models.AnnouncementMessages, len(req.Messages))
+	for language, message := range req.Messages {
+		if message = strings.TrimSpace(message); message != "" {
+			messages[language] = message
+		}
+	}
+	if messages[models.LanguageRussian] == "" {
+		return nil, fmt.Errorf("%w: a message in RUSSIAN is required, users of other languages without a message get it", ErrInvalidAnnouncement)
+	}
+
+	announcement := &models.Announcement{
+		Segment:   req.Segment,
+		Messages:  messages,
+		CreatedBy: &adminID,
+	}
+	if req.Segment == models.AnnouncementSegmentCity && req.City != nil {
+		city := strings.TrimSpace(*req.City)
+		if city == "" {
+			return nil, fmt.Errorf("%w: city is required for the CITY segment", ErrInvalidAnnouncement)
+		}
+		announcement.City = &city
+	}
+
+	if err := s.announcementRepo.Create(ctx, announcement); err != nil {
+		return nil, err
+	}
+	return announcement, nil
+}
+
+// GetAnnouncement gets an announcement with its delivery stats
+func (s *AnnouncementService) GetAnnouncement(ctx context.Context, id uuid.UUID) (*models.Announcement, error) {
+	return s.announcementRepo.GetByID(ctx, id)
+}
+
+// ListAnnouncements lists the announcements with their delivery stats, newest first
+func (s *AnnouncementService) ListAnnouncements(ctx context.Context) ([]*models.Announcement, error) {
+	return s.announcementRepo.List(ctx)
+}
+
+// CancelAnnouncement stops sending an announcement. Notifications already sent are kept.
+func (s *AnnouncementService) CancelAnnouncement(ctx context.Context, id uuid.UUID) (*models.Announcement, error) {
+	if _, err := s.announcementRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	canceled, err := s.announcementRepo.Cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canceled {
+		return nil, ErrAnnouncementFinished
+	}
+	return s.announcementRepo.GetByID(ctx, id)
+}
+
+// SendPending sends the announcements in progress to their remaining recipients and returns the
+// number of notifications sent. An announcement that fails is logged and picked up on the next run.
+func (s *AnnouncementService) SendPending(ctx context.Context) (int, error) {
+	announcements, err := s.announcementRepo.GetInProgress(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, announcement := range announcements {
+		sent, err := s.send(ctx, announcement)
+		total += sent
+		if err != nil {
+			log.Printf("Error sending announcement %s: %v", announcement.ID, err)
+		}
+	}
+	return total, nil
+}
+
+// send sends an announcement batch by batch until every recipient got it or the batch cannot be
+// claimed, because the announcement was canceled or another instance is sending it
+func (s *AnnouncementService) send(ctx context.Context, announcement *models.Announcement) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		recipients, err := s.announcementRepo.GetRecipients(ctx, announcement, announcement.LastUserID, s.batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(recipients) == 0 {
+			return total, s.announcementRepo.Complete(ctx, announcement.ID)
+		}
+
+		last := recipients[len(recipients)-1].UserID
+		claimed, err := s.announcementRepo.ClaimBatch(ctx, announcement.ID, announcement.LastUserID, last)
+		if err != nil {
+			return total, err
+		}
+		if !claimed {
+			return total, nil
+		}
+		announcement.LastUserID = &last
+
+		sent, failed := 0, 0
+		for _, recipient := range recipients {
+			payload := models.NotificationPayload{
+				"announcementId": announcement.ID.String(),
+				"message":        announcementMessage(announcement.Messages, recipient.Language),
+			}
+			_, err := s.notificationService.SendNotification(ctx, recipient.UserID, recipient.Language, models.NotificationTypeAnnouncement, payload)
+			if err != nil {
+				log.Printf("Failed to send announcement %s to user %s: %v", announcement.ID, recipient.UserID, err)
+				failed++
+				continue
+			}
+			sent++
+		}
+		total += sent
+
+		if err := s.announcementRepo.RecordBatch(ctx, announcement.ID, sent, failed); err != nil {
+			return total, err
+		}
+	}
+	return total, ctx.Err()
+}
+
+// announcementMessage returns the message of an announcement in a language, or in Russian when it
+// has none in that language
+func announcementMessage(messages models.AnnouncementMessages, language models.Language) string {
+	if message, ok := messages[language]; ok {
+		return message
+	}
+	return messages[models.LanguageRussian]
+}
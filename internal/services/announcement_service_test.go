@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAnnouncementRepository is a mock implementation of the AnnouncementRepository interface
+type MockAnnouncementRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnnouncementRepository) Create(ctx context.Context, announcement *models.Announcement) error {
+	args := m.Called(ctx, announcement)
+	return args.Error(0)
+}
+
+func (m *MockAnnouncementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Announcement, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Announcement), args.Error(1)
+}
+
+func (m *MockAnnouncementRepository) List(ctx context.Context) ([]*models.Announcement, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Announcement), args.Error(1)
+}
+
+func (m *MockAnnouncementRepository) GetInProgress(ctx context.Context) ([]*models.Announcement, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Announcement), args.Error(1)
+}
+
+func (m *MockAnnouncementRepository) GetRecipients(ctx context.Context, announcement *models.Announcement, after *uuid.UUID, limit int) ([]*models.AnnouncementRecipient, error) {
+	args := m.Called(ctx, announcement, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AnnouncementRecipient), args.Error(1)
+}
+
+func (m *MockAnnouncementRepository) ClaimBatch(ctx context.Context, id uuid.UUID, from *uuid.UUID, to uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAnnouncementRepository) RecordBatch(ctx context.Context, id uuid.UUID, sent, failed int) error {
+	args := m.Called(ctx, id, sent, failed)
+	return args.Error(0)
+}
+
+func (m *MockAnnouncementRepository) Complete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAnnouncementRepository) Cancel(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+// TestAnnouncementService_CreateAnnouncement tests that messages are trimmed, that the Russian
+// message is required and that the city is kept for the CITY segment only
+func TestAnnouncementService_CreateAnnouncement(t *testing.T) {
+	mockAnnouncementRepo := new(MockAnnouncementRepository)
+	service := NewAnnouncementService(mockAnnouncementRepo, nil)
+	adminID := uuid.New()
+	city := " Казань "
+
+	mockAnnouncementRepo.On("Create", mock.Anything, mock.MatchedBy(func(a *models.Announcement) bool {
+		return a.Segment == models.AnnouncementSegmentCity && *a.City == "Казань" && *a.CreatedBy == adminID &&
+			a.Messages[models.LanguageRussian] == "Новый раздел в приложении" && len(a.Messages) == 1
+	})).Return(nil).Once()
+	announcement, err := service.CreateAnnouncement(context.Background(), adminID, &models.CreateAnnouncementRequest{
+		Segment:  models.AnnouncementSegmentCity,
+		City:     &city,
+		Messages: models.AnnouncementMessages{models.LanguageRussian: " Новый раздел в приложении ", models.LanguageEnglish: "  "},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Казань", *announcement.City)
+
+	// The city of other segments is ignored
+	mockAnnouncementRepo.On("Create", mock.Anything, mock.MatchedBy(func(a *models.Announcement) bool {
+		return a.Segment == models.AnnouncementSegmentAll && a.City == nil
+	})).Return(nil).Once()
+	_, err = service.CreateAnnouncement(context.Background(), adminID, &models.CreateAnnouncementRequest{
+		Segment:  models.AnnouncementSegmentAll,
+		City:     &city,
+		Messages: models.AnnouncementMessages{models.LanguageRussian: "Привет"},
+	})
+	require.NoError(t, err)
+
+	// Users of other languages fall back to the Russian message, so it is required
+	_, err = service.CreateAnnouncement(context.Background(), adminID, &models.CreateAnnouncementRequest{
+		Segment:  models.AnnouncementSegmentAll,
+		Messages: models.AnnouncementMessages{models.LanguageEnglish: "Hello"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidAnnouncement)
+	mockAnnouncementRepo.AssertExpectations(t)
+}
+
+// TestAnnouncementService_SendPending tests that announcements are sent batch by batch in the
+// language of each recipient, that failed notifications are counted and that the announcement is
+// completed once no recipient is left
+func TestAnnouncementService_SendPending(t *testing.T) {
+	mockAnnouncementRepo := new(MockAnnouncementRepository)
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	service := NewAnnouncementService(mockAnnouncementRepo, NewNotificationService(mockNotificationRepo, new(MockPlantRepository), nil, NewNotificationTemplateService(mockTemplateRepo)))
+	service.batchSize = 2
+
+	announcement := &models.Announcement{
+		ID:       uuid.New(),
+		Segment:  models.AnnouncementSegmentAll,
+		Messages: models.AnnouncementMessages{models.LanguageRussian: "Скидки на горшки", models.LanguageEnglish: "Pots on sale"},
+		Status:   models.AnnouncementStatusPending,
+	}
+	first := &models.AnnouncementRecipient{UserID: uuid.New(), Language: models.LanguageRussian}
+	second := &models.AnnouncementRecipient{UserID: uuid.New(), Language: models.LanguageEnglish}
+	third := &models.AnnouncementRecipient{UserID: uuid.New(), Language: models.LanguageRussian}
+
+	mockAnnouncementRepo.On("GetInProgress", mock.Anything).Return([]*models.Announcement{announcement}, nil)
+	mockAnnouncementRepo.On("GetRecipients", mock.Anything, announcement, (*uuid.UUID)(nil), 2).
+		Return([]*models.AnnouncementRecipient{first, second}, nil).Once()
+	mockAnnouncementRepo.On("ClaimBatch", mock.Anything, announcement.ID, (*uuid.UUID)(nil), second.UserID).Return(true, nil).Once()
+	mockAnnouncementRepo.On("RecordBatch", mock.Anything, announcement.ID, 2, 0).Return(nil).Once()
+	mockAnnouncementRepo.On("GetRecipients", mock.Anything, announcement, &second.UserID, 2).
+		Return([]*models.AnnouncementRecipient{third}, nil).Once()
+	mockAnnouncementRepo.On("ClaimBatch", mock.Anything, announcement.ID, &second.UserID, third.UserID).Return(true, nil).Once()
+	mockAnnouncementRepo.On("RecordBatch", mock.Anything, announcement.ID, 0, 1).Return(nil).Once()
+	mockAnnouncementRepo.On("GetRecipients", mock.Anything, announcement, &third.UserID, 2).
+		Return([]*models.AnnouncementRecipient{}, nil).Once()
+	mockAnnouncementRepo.On("Complete", mock.Anything, announcement.ID).Return(nil).Once()
+
+	mockTemplateRepo.On("Get", mock.Anything, models.NotificationTypeAnnouncement, mock.Anything).Return(nil, nil)
+	messages := make(map[uuid.UUID]string)
+	mockNotificationRepo.On("Create", mock.Anything, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.UserID != third.UserID
+	})).Run(func(args mock.Arguments) {
+		notification := args.Get(1).(*models.Notification)
+		messages[notification.UserID] = notification.Message
+		assert.Equal(t, announcement.ID.String(), notification.Payload["announcementId"])
+	}).Return(nil).Twice()
+	mockNotificationRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("connection reset")).Once()
+
+	sent, err := service.SendPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, "Скидки на горшки", messages[first.UserID])
+	assert.Equal(t, "Pots on sale", messages[second.UserID])
+	mockAnnouncementRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertExpectations(t)
+}
+
+// TestAnnouncementService_SendPending_Canceled tests that nothing is sent once the batch cannot be
+// claimed, as happens after the announcement was canceled
+func TestAnnouncementService_SendPending_Canceled(t *testing.T) {
+	mockAnnouncementRepo := new(MockAnnouncementRepository)
+	mockNotificationRepo := new(MockNotificationRepository)
+	service := NewAnnouncementService(mockAnnouncementRepo, NewNotificationService(mockNotificationRepo, new(MockPlantRepository), nil, nil))
+
+	lastUserID := uuid.New()
+	announcement := &models.Announcement{ID: uuid.New(), Status: models.AnnouncementStatusSending, LastUserID: &lastUserID,
+		Messages: models.AnnouncementMessages{models.LanguageRussian: "Привет"}}
+	recipient := &models.AnnouncementRecipient{UserID: uuid.New(), Language: models.LanguageRussian}
+	mockAnnouncementRepo.On("GetInProgress", mock.Anything).Return([]*models.Announcement{announcement}, nil)
+	mockAnnouncementRepo.On("GetRecipients", mock.Anything, announcement, &lastUserID, defaultAnnouncementBatchSize).
+		Return([]*models.AnnouncementRecipient{recipient}, nil)
+	mockAnnouncementRepo.On("ClaimBatch", mock.Anything, announcement.ID, &lastUserID, recipient.UserID).Return(false, nil)
+
+	sent, err := service.SendPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	mockAnnouncementRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestAnnouncementService_CancelAnnouncement tests that only announcements in progress can be canceled
+func TestAnnouncementService_CancelAnnouncement(t *testing.T) {
+	mockAnnouncementRepo := new(MockAnnouncementRepository)
+	service := NewAnnouncementService(mockAnnouncementRepo, nil)
+
+	id := uuid.New()
+	mockAnnouncementRepo.On("GetByID", mock.Anything, id).Return(&models.Announcement{ID: id, Status: models.AnnouncementStatusCompleted}, nil)
+	mockAnnouncementRepo.On("Cancel", mock.Anything, id).Return(false, nil).Once()
+
+	_, err := service.CancelAnnouncement(context.Background(), id)
+	assert.ErrorIs(t, err, ErrAnnouncementFinished)
+	mockAnnouncementRepo.AssertExpectations(t)
+}
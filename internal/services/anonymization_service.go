@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrAnonymizationDisabled is returned when anonymization runs while no inactivity period is configured
+var ErrAnonymizationDisabled = errors.New("anonymization of inactive accounts is disabled")
+
+const (
+	// anonymizationBatchSize is the largest number of accounts warned and anonymized by a single run
+	anonymizationBatchSize = 500
+
+	// activityWriteInterval is how often the activity of a user is written at most
+	activityWriteInterval = time.Hour
+
+	// maxTrackedActivity is the number of users whose last activity write is remembered before old entries are dropped
+	maxTrackedActivity = 10000
+
+	// maxAnonymizationFailures is the number of failures spelled out in the report of a run
+	maxAnonymizationFailures = 10
+
+	// anonymizedEmailDomain is the reserved domain of the emails anonymized accounts get
+	anonymizedEmailDomain = "anonymized.invalid"
+)
+
+// inactivityWarnings holds the subject and body of the warning email for each supported language.
+// The body is formatted with the user's name, the inactivity period in days and the anonymization date.
+var inactivityWarnings = map[models.Language]struct {
+	subject string
+	body    string
+}{
+	models.LanguageRussian: {
+		subject: "Ваш аккаунт Planter будет обезличен",
+		body: "Здравствуйте, %s!\n\n" +
+			"Вы не заходили в Planter больше %d дней. Если вы не войдёте в приложение до %s, " +
+			"мы удалим ваши личные данные: имя, email, заметки и историю чата. " +
+			"Войти снова в этот аккаунт будет нельзя.\n\n" +
+			"Чтобы сохранить аккаунт, просто откройте приложение.\n\nКоманда Planter",
+	},
+	models.LanguageEnglish: {
+		subject: "Your Planter account will be anonymized",
+		body: "Hello %s,\n\n" +
+			"You have not used Planter for more than %d days. Unless you sign in before %s, " +
+			"we will remove your personal data: your name, email, notes and chat history. " +
+			"You will not be able to sign in to this account again.\n\n" +
+			"To keep your account, just open the app.\n\nThe Planter team",
+	},
+}
+
+// AnonymizationService warns the owners of dormant accounts and anonymizes the accounts that stay
+// inactive, keeping their plants and care history for the aggregate statistics
+type AnonymizationService struct {
+	anonymizationRepo repository.AnonymizationRepository
+	mailer            Mailer
+	inactivePeriod    time.Duration
+	warningPeriod     time.Duration
+	now               func() time.Time
+
+	activityMu   sync.Mutex
+	activitySeen map[uuid.UUID]time.Time
+}
+
+// NewAnonymizationService creates a new anonymization service. Accounts are warned after inactiveDays
+// without activity and anonymized warningDays after the warning; owners cannot be warned, and so no
+// account is anonymized, when mailer is nil.
+func NewAnonymizationService(
+	anonymizationRepo repository.AnonymizationRepository,
+	mailer Mailer,
+	inactiveDays int,
+	warningDays int,
+) *AnonymizationService {
+	return &AnonymizationService{
+		anonymizationRepo: anonymizationRepo,
+		mailer:            mailer,
+		inactivePeriod:    time.Duration(inactiveDays) * 24 * time.Hour,
+		warningPeriod:     time.Duration(warningDays) * 24 * time.Hour,
+		now:               time.Now,
+		activitySeen:      make(map[uuid.UUID]time.Time),
+	}
+}
+
+// RecordActivity records that a user is active, writing it at most once an hour per user.
+// Failures are only logged so they never fail the request.
+func (s *AnonymizationService) RecordActivity(ctx context.Context, userID uuid.UUID) {
+	now := s.now()
+
+	s.activityMu.Lock()
+	if last, ok := s.activitySeen[userID]; ok && now.Sub(last) < activityWriteInterval {
+		s.activityMu.Unlock()
+		return
+	}
+	if len(s.activitySeen) >= maxTrackedActivity {
+		for id, last := range s.activitySeen {
+			if now.Sub(last) >= activityWriteInterval {
+				delete(s.activitySeen, id)
+			}
+		}
+	}
+	s.activitySeen[userID] = now
+	s.activityMu.Unlock()
+
+	if err := s.anonymizationRepo.TouchActivity(ctx, userID); err != nil {
+		log.Printf("Failed to record activity of user %s: %v", userID, err)
+	}
+}
+
+// Run anonymizes the accounts that stayed inactive through the warning period and warns the owners of
+// newly inactive accounts, then saves the report. With dryRun set the accounts are only listed.
+// A failing account does not stop the others; the failures are recorded in the report.
+func (s *AnonymizationService) Run(ctx context.Context, dryRun bool) (*models.AnonymizationRun, error) {
+	if s.inactivePeriod <= 0 {
+		return nil, ErrAnonymizationDisabled
+	}
+
+	now := s.now()
+	run := &models.AnonymizationRun{
+		DryRun:    dryRun,
+		StartedAt: now,
+		Accounts:  []*models.AnonymizationAccount{},
+	}
+	var failures []string
+
+	// Anonymize first so the accounts warned below get their full warning period
+	warned, err := s.anonymizationRepo.GetWarnedInactive(ctx, now.Add(-s.warningPeriod), anonymizationBatchSize)
+	if err != nil {
+		failures = append(failures, err.Error())
+	}
+	for _, account := range warned {
+		if !dryRun {
+			anonymized, err := s.anonymizationRepo.Anonymize(ctx, account.UserID, anonymizedEmail(account.Email))
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("account %s: %v", account.UserID, err))
+				continue
+			}
+			if !anonymized {
+				continue
+			}
+		}
+		run.Accounts = append(run.Accounts, &models.AnonymizationAccount{UserID: account.UserID, Action: models.AnonymizationActionAnonymized})
+		run.Anonymized++
+	}
+
+	inactive, err := s.anonymizationRepo.GetUnwarnedInactive(ctx, now.Add(-s.inactivePeriod), anonymizationBatchSize)
+	if err != nil {
+		failures = append(failures, err.Error())
+	}
+	if len(inactive) > 0 && s.mailer == nil && !dryRun {
+		failures = append(failures, fmt.Sprintf("%d inactive accounts were not warned: no mailer is configured", len(inactive)))
+		inactive = nil
+	}
+	for _, account := range inactive {
+		if !dryRun {
+			if err := s.warn(ctx, account, now.Add(s.warningPeriod)); err != nil {
+				failures = append(failures, fmt.Sprintf("account %s: %v", account.UserID, err))
+				continue
+			}
+		}
+		run.Accounts = append(run.Accounts, &models.AnonymizationAccount{UserID: account.UserID, Action: models.AnonymizationActionWarned})
+		run.Warned++
+	}
+
+	// Counters are logged in a fixed format so log-based metrics can pick them up
+	log.Printf("anonymization dry_run=%t warned=%d anonymized=%d failures=%d", dryRun, run.Warned, run.Anonymized, len(failures))
+
+	run.FinishedAt = s.now()
+	if len(failures) > 0 {
+		message := summarizeFailures(failures)
+		run.Error = &message
+	}
+
+	if err := s.anonymizationRepo.SaveRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to save anonymization run: %w", err)
+	}
+
+	if run.Error != nil {
+		return run, fmt.Errorf("anonymization finished with errors: %s", *run.Error)
+	}
+	return run, nil
+}
+
+// GetRuns gets the most recent anonymization runs with the accounts they processed
+func (s *AnonymizationService) GetRuns(ctx context.Context, limit int) ([]*models.AnonymizationRun, error) {
+	if limit < 1 || limit > 100 {
+		limit = 30
+	}
+
+	runs, err := s.anonymizationRepo.GetRuns(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anonymization runs: %w", err)
+	}
+	return runs, nil
+}
+
+// warn emails the owner of an inactive account the date it will be anonymized on and records the warning
+func (s *AnonymizationService) warn(ctx context.Context, account *models.InactiveAccount, deadline time.Time) error {
+	warning, ok := inactivityWarnings[account.Language]
+	if !ok {
+		warning = inactivityWarnings[models.LanguageRussian]
+	}
+	layout, ok := notificationDateLayouts[account.Language]
+	if !ok {
+		layout = notificationDateLayouts[models.LanguageRussian]
+	}
+
+	inactiveDays := int(s.inactivePeriod / (24 * time.Hour))
+	body := fmt.Sprintf(warning.body, account.Name, inactiveDays, deadline.Format(layout))
+	if err := s.mailer.Send(ctx, account.Email, warning.subject, body); err != nil {
+		return fmt.Errorf("failed to send inactivity warning: %w", err)
+	}
+	return s.anonymizationRepo.MarkWarned(ctx, account.UserID)
+}
+
+// anonymizedEmail returns the email an anonymized account gets: a hash of the original, which stays
+// unique like the email itself but cannot be mailed or traced back without the original
+func anonymizedEmail(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:]) + "@" + anonymizedEmailDomain
+}
+
+// summarizeFailures joins the first failures of a run and counts the rest
+func summarizeFailures(failures []string) string {
+	if len(failures) <= maxAnonymizationFailures {
+		return strings.Join(failures, "; ")
+	}
+	return fmt.Sprintf("%s; and %d more", strings.Join(failures[:maxAnonymizationFailures], "; "), len(failures)-maxAnonymizationFailures)
+}
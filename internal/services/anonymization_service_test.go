@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAnonymizationRepository is a mock implementation of the AnonymizationRepository interface
+type MockAnonymizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnonymizationRepository) TouchActivity(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAnonymizationRepository) GetUnwarnedInactive(ctx context.Context, inactiveSince time.Time, limit int) ([]*models.InactiveAccount, error) {
+	args := m.Called(ctx, inactiveSince, limit)
+	return args.Get(0).([]*models.InactiveAccount), args.Error(1)
+}
+
+func (m *MockAnonymizationRepository) MarkWarned(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAnonymizationRepository) GetWarnedInactive(ctx context.Context, warnedBefore time.Time, limit int) ([]*models.InactiveAccount, error) {
+	args := m.Called(ctx, warnedBefore, limit)
+	return args.Get(0).([]*models.InactiveAccount), args.Error(1)
+}
+
+func (m *MockAnonymizationRepository) Anonymize(ctx context.Context, userID uuid.UUID, anonymizedEmail string) (bool, error) {
+	args := m.Called(ctx, userID, anonymizedEmail)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAnonymizationRepository) SaveRun(ctx context.Context, run *models.AnonymizationRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockAnonymizationRepository) GetRuns(ctx context.Context, limit int) ([]*models.AnonymizationRun, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]*models.AnonymizationRun), args.Error(1)
+}
+
+// sentEmail is an email sent through a capturingMailer
+type sentEmail struct {
+	to      string
+	subject string
+	body    string
+}
+
+// capturingMailer keeps the emails sent through it and fails for the recipients in failFor
+type capturingMailer struct {
+	sent    []sentEmail
+	failFor map[string]bool
+}
+
+func (m *capturingMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	if m.failFor[to] {
+		return errors.New("mailbox unavailable")
+	}
+	m.sent = append(m.sent, sentEmail{to: to, subject: subject, body: body})
+	return nil
+}
+
+// TestAnonymizationService_Run tests that warned accounts are anonymized with a hashed email, newly
+// inactive owners are warned in their language and a failed warning is reported without stopping the run
+func TestAnonymizationService_Run(t *testing.T) {
+	mockRepo := new(MockAnonymizationRepository)
+	mailer := &capturingMailer{failFor: map[string]bool{"bounce@example.com": true}}
+	service := NewAnonymizationService(mockRepo, mailer, 730, 30)
+	now := time.Date(2024, time.May, 1, 4, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	dormant, changedMind := uuid.New(), uuid.New()
+	anna, bounced := uuid.New(), uuid.New()
+	mockRepo.On("GetWarnedInactive", ctx, now.AddDate(0, 0, -30), anonymizationBatchSize).Return([]*models.InactiveAccount{
+		{UserID: dormant, Email: "Old@Example.com"},
+		{UserID: changedMind, Email: "back@example.com"},
+	}, nil)
+	mockRepo.On("Anonymize", ctx, dormant, anonymizedEmail("old@example.com")).Return(true, nil)
+	mockRepo.On("Anonymize", ctx, changedMind, mock.Anything).Return(false, nil)
+	mockRepo.On("GetUnwarnedInactive", ctx, now.AddDate(0, 0, -730), anonymizationBatchSize).Return([]*models.InactiveAccount{
+		{UserID: anna, Name: "Anna", Email: "anna@example.com", Language: models.LanguageEnglish},
+		{UserID: bounced, Name: "Boris", Email: "bounce@example.com", Language: models.LanguageRussian},
+	}, nil)
+	mockRepo.On("MarkWarned", ctx, anna).Return(nil)
+	mockRepo.On("SaveRun", ctx, mock.Anything).Return(nil)
+
+	run, err := service.Run(ctx, false)
+	assert.Error(t, err)
+	if assert.NotNil(t, run) {
+		assert.Equal(t, 1, run.Anonymized)
+		assert.Equal(t, 1, run.Warned)
+		assert.Equal(t, []*models.AnonymizationAccount{
+			{UserID: dormant, Action: models.AnonymizationActionAnonymized},
+			{UserID: anna, Action: models.AnonymizationActionWarned},
+		}, run.Accounts)
+		if assert.NotNil(t, run.Error) {
+			assert.Contains(t, *run.Error, bounced.String())
+		}
+	}
+	if assert.Len(t, mailer.sent, 1) {
+		assert.Equal(t, "Your Planter account will be anonymized", mailer.sent[0].subject)
+		assert.Contains(t, mailer.sent[0].body, "Hello Anna")
+		assert.Contains(t, mailer.sent[0].body, "May 31, 2024")
+	}
+	assert.True(t, strings.HasSuffix(anonymizedEmail("old@example.com"), "@anonymized.invalid"))
+	assert.NotContains(t, anonymizedEmail("old@example.com"), "old")
+	mockRepo.AssertNotCalled(t, "MarkWarned", ctx, bounced)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAnonymizationService_Run_DryRun tests that a dry run only lists the accounts
+func TestAnonymizationService_Run_DryRun(t *testing.T) {
+	mockRepo := new(MockAnonymizationRepository)
+	service := NewAnonymizationService(mockRepo, nil, 730, 30)
+	ctx := context.Background()
+	warned, inactive := uuid.New(), uuid.New()
+
+	mockRepo.On("GetWarnedInactive", ctx, mock.Anything, anonymizationBatchSize).Return([]*models.InactiveAccount{{UserID: warned}}, nil)
+	mockRepo.On("GetUnwarnedInactive", ctx, mock.Anything, anonymizationBatchSize).Return([]*models.InactiveAccount{{UserID: inactive}}, nil)
+	mockRepo.On("SaveRun", ctx, mock.MatchedBy(func(run *models.AnonymizationRun) bool {
+		return run.DryRun && run.Anonymized == 1 && run.Warned == 1
+	})).Return(nil)
+
+	run, err := service.Run(ctx, true)
+	assert.NoError(t, err)
+	assert.Len(t, run.Accounts, 2)
+	mockRepo.AssertNotCalled(t, "Anonymize", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "MarkWarned", mock.Anything, mock.Anything)
+}
+
+// TestAnonymizationService_Run_NoMailer tests that owners who cannot be warned are left alone
+func TestAnonymizationService_Run_NoMailer(t *testing.T) {
+	mockRepo := new(MockAnonymizationRepository)
+	service := NewAnonymizationService(mockRepo, nil, 730, 30)
+	ctx := context.Background()
+
+	mockRepo.On("GetWarnedInactive", ctx, mock.Anything, anonymizationBatchSize).Return([]*models.InactiveAccount{}, nil)
+	mockRepo.On("GetUnwarnedInactive", ctx, mock.Anything, anonymizationBatchSize).Return([]*models.InactiveAccount{{UserID: uuid.New()}}, nil)
+	mockRepo.On("SaveRun", ctx, mock.Anything).Return(nil)
+
+	run, err := service.Run(ctx, false)
+	assert.Error(t, err)
+	assert.Equal(t, 0, run.Warned)
+	mockRepo.AssertNotCalled(t, "MarkWarned", mock.Anything, mock.Anything)
+
+	_, err = NewAnonymizationService(mockRepo, nil, 0, 30).Run(ctx, false)
+	assert.ErrorIs(t, err, ErrAnonymizationDisabled)
+}
+
+// TestAnonymizationService_RecordActivity tests that the activity of a user is written at most once an hour
+func TestAnonymizationService_RecordActivity(t *testing.T) {
+	mockRepo := new(MockAnonymizationRepository)
+	service := NewAnonymizationService(mockRepo, nil, 730, 30)
+	now := time.Date(2024, time.May, 1, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mockRepo.On("TouchActivity", ctx, userID).Return(nil).Twice()
+
+	service.RecordActivity(ctx, userID)
+	now = now.Add(30 * time.Minute)
+	service.RecordActivity(ctx, userID)
+	now = now.Add(time.Hour)
+	service.RecordActivity(ctx, userID)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// apiKeyPrefix is prepended to every generated public API key
+const apiKeyPrefix = "plk_"
+
+// APIKeyService handles public API key operations
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// CreateAPIKey creates a new API key for a user and returns the raw key once
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, userID uuid.UUID, name string) (*models.CreateAPIKeyResponse, error) {
+	// Generate the raw key
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	rawKey := apiKeyPrefix + hex.EncodeToString(buf)
+
+	// Only the hash of the key is stored
+	apiKey := &models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		KeyHash:   hashAPIKey(rawKey),
+		KeyPrefix: rawKey[:len(apiKeyPrefix)+8],
+	}
+
+	err := s.apiKeyRepo.Create(ctx, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &models.CreateAPIKeyResponse{
+		APIKey: *apiKey,
+		Key:    rawKey,
+	}, nil
+}
+
+// GetAPIKeys gets all API keys of a user
+func (s *APIKeyService) GetAPIKeys(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	apiKeys, err := s.apiKeyRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	return apiKeys, nil
+}
+
+// RevokeAPIKey revokes an API key owned by a user
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error {
+	err := s.apiKeyRepo.Revoke(ctx, keyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKeyUsage gets the daily usage of a user's API key for the last given number of days
+func (s *APIKeyService) GetAPIKeyUsage(ctx context.Context, userID uuid.UUID, keyID uuid.UUID, days int) ([]*models.APIKeyUsage, error) {
+	if days < 1 {
+		days = 30
+	}
+
+	// Check if the user owns the key
+	apiKeys, err := s.apiKeyRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	owned := false
+	for _, apiKey := range apiKeys {
+		if apiKey.ID == keyID {
+			owned = true
+			break
+		}
+	}
+	if !owned {
+		return nil, fmt.Errorf("user does not own this API key")
+	}
+
+	since := time.Now().AddDate(0, 0, -(days - 1))
+	usage, err := s.apiKeyRepo.GetUsage(ctx, keyID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key usage: %w", err)
+	}
+	return usage, nil
+}
+
+// ValidateAPIKey checks a raw API key and returns its ID
+func (s *APIKeyService) ValidateAPIKey(ctx context.Context, rawKey string) (uuid.UUID, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return uuid.Nil, fmt.Errorf("invalid API key format")
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByHash(ctx, hashAPIKey(rawKey))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid API key: %w", err)
+	}
+	return apiKey.ID, nil
+}
+
+// RecordAPIKeyUsage records a public API request made with a key
+func (s *APIKeyService) RecordAPIKeyUsage(ctx context.Context, keyID uuid.UUID) error {
+	err := s.apiKeyRepo.RecordUsage(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to record API key usage: %w", err)
+	}
+	return nil
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 hash of a raw API key
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyRepository is a mock implementation of the APIKeyRepository interface
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, apiKey *models.APIKey) error {
+	args := m.Called(ctx, apiKey)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, keyID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, keyID, userID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) RecordUsage(ctx context.Context, keyID uuid.UUID) error {
+	args := m.Called(ctx, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetUsage(ctx context.Context, keyID uuid.UUID, since time.Time) ([]*models.APIKeyUsage, error) {
+	args := m.Called(ctx, keyID, since)
+	return args.Get(0).([]*models.APIKeyUsage), args.Error(1)
+}
+
+// TestAPIKeyService_CreateAndValidate tests that a created key validates against its stored hash
+func TestAPIKeyService_CreateAndValidate(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	keyID := uuid.New()
+
+	var stored *models.APIKey
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*models.APIKey")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.APIKey)
+		stored.ID = keyID
+	}).Return(nil)
+
+	// Create the key
+	resp, err := service.CreateAPIKey(ctx, userID, "Home Assistant")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resp.Key, apiKeyPrefix))
+	assert.True(t, strings.HasPrefix(resp.Key, resp.APIKey.KeyPrefix))
+	assert.NotEqual(t, resp.Key, stored.KeyHash)
+	assert.Equal(t, hashAPIKey(resp.Key), stored.KeyHash)
+
+	// Validate the raw key
+	mockRepo.On("GetByHash", ctx, stored.KeyHash).Return(stored, nil)
+	validatedID, err := service.ValidateAPIKey(ctx, resp.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, keyID, validatedID)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAPIKeyService_ValidateAPIKey_Invalid tests that malformed and unknown keys are rejected
+func TestAPIKeyService_ValidateAPIKey_Invalid(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo)
+	ctx := context.Background()
+
+	// Malformed keys never reach the repository
+	_, err := service.ValidateAPIKey(ctx, "not-a-key")
+	assert.Error(t, err)
+
+	// Unknown or revoked keys are rejected
+	mockRepo.On("GetByHash", ctx, hashAPIKey(apiKeyPrefix+"unknown")).Return(nil, fmt.Errorf("API key not found"))
+	_, err = service.ValidateAPIKey(ctx, apiKeyPrefix+"unknown")
+	assert.Error(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAPIKeyService_GetAPIKeyUsage_NotOwned tests that usage of another user's key is not returned
+func TestAPIKeyService_GetAPIKeyUsage_NotOwned(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mockRepo.On("GetByUser", ctx, userID).Return([]*models.APIKey{{ID: uuid.New(), UserID: userID}}, nil)
+
+	usage, err := service.GetAPIKeyUsage(ctx, userID, uuid.New(), 7)
+	assert.Error(t, err)
+	assert.Nil(t, usage)
+	mockRepo.AssertNotCalled(t, "GetUsage", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -4,28 +4,49 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/anpanovv/planter/internal/db/pgerror"
 	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/events"
 	"github.com/anpanovv/planter/internal/models"
 	"github.com/anpanovv/planter/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// UserWelcomer sets up the accounts of newly registered users
+type UserWelcomer interface {
+	Welcome(ctx context.Context, user *models.User) error
+}
+
 // AuthService handles authentication operations
 type AuthService struct {
-	userRepo repository.UserRepository
-	auth     *middleware.Auth
+	userRepo  repository.UserRepository
+	auth      *middleware.Auth
+	publisher events.Publisher
+	welcomer  UserWelcomer // nil when new accounts are not set up
 }
 
 // NewAuthService creates a new auth service
 func NewAuthService(userRepo repository.UserRepository, auth *middleware.Auth) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
-		auth:     auth,
+		userRepo:  userRepo,
+		auth:      auth,
+		publisher: events.NopPublisher{},
 	}
 }
 
+// SetEventPublisher sets the publisher domain events are sent to
+func (s *AuthService) SetEventPublisher(publisher events.Publisher) {
+	s.publisher = publisher
+}
+
+// SetWelcomer sets the welcomer that sets up the accounts of new users after they register
+func (s *AuthService) SetWelcomer(welcomer UserWelcomer) {
+	s.welcomer = welcomer
+}
+
 // Login authenticates a user and returns a token
 func (s *AuthService) Login(ctx context.Context, email, password string) (*models.AuthResponse, error) {
 	// Get the user by email
@@ -46,6 +67,13 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (*model
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
+	publishEvent(ctx, s.publisher, events.UserRegistered{
+		UserID:     user.ID,
+		Email:      user.Email,
+		Language:   string(user.Language),
+		OccurredAt: time.Now(),
+	})
+
 	// Hide the password hash
 	user.PasswordHash = ""
 
@@ -79,10 +107,21 @@ func (s *AuthService) Register(ctx context.Context, name, email, password string
 	}
 
 	err = s.userRepo.Create(ctx, user)
+	if errors.Is(err, pgerror.ErrConflict) {
+		// The email is taken by a deleted account or one registered meanwhile
+		return nil, errors.New("email already in use")
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// Set up the new account; the user is registered even when this fails
+	if s.welcomer != nil {
+		if err := s.welcomer.Welcome(ctx, user); err != nil {
+			log.Printf("Error welcoming user %s: %v", user.ID, err)
+		}
+	}
+
 	// Generate a token
 	token, err := s.auth.GenerateToken(user.ID, 24*time.Hour)
 	if err != nil {
@@ -58,6 +58,34 @@ func (m *MockUserRepository) RemoveLocation(ctx context.Context, userID uuid.UUI
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) GetRoles(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserRepository) AddRole(ctx context.Context, userID uuid.UUID, role models.Role) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetByRole(ctx context.Context, role models.Role) ([]*models.User, error) {
+	args := m.Called(ctx, role)
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) SoftDelete(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetLowEffortMode(ctx context.Context, userID uuid.UUID, enabled bool) error {
+	args := m.Called(ctx, userID, enabled)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) GetFavoritePlantIDs(ctx context.Context, userID uuid.UUID) ([]string, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).([]string), args.Error(1)
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// BadgeService awards badges to users and tells them with a BADGE_EARNED notification
+type BadgeService struct {
+	badgeRepo           repository.BadgeRepository
+	notificationService *NotificationService // nil when users are not notified of their badges
+}
+
+// NewBadgeService creates a new badge service
+func NewBadgeService(badgeRepo repository.BadgeRepository, notificationService *NotificationService) *BadgeService {
+	return &BadgeService{
+		badgeRepo:           badgeRepo,
+		notificationService: notificationService,
+	}
+}
+
+// Award awards a badge to a user and reports whether it is new. The notification is best effort:
+// when it fails, the badge is kept and the failure logged.
+func (s *BadgeService) Award(ctx context.Context, userID uuid.UUID, language models.Language, badge models.BadgeType) (bool, error) {
+	awarded, err := s.badgeRepo.Award(ctx, userID, badge)
+	if err != nil || !awarded {
+		return false, err
+	}
+
+	if s.notificationService != nil {
+		payload := models.NotificationPayload{"badge": string(badge)}
+		if _, err := s.notificationService.SendNotification(ctx, userID, language, models.NotificationTypeBadgeEarned, payload); err != nil {
+			log.Printf("Failed to notify user %s of badge %s: %v", userID, badge, err)
+		}
+	}
+	return true, nil
+}
+
+// GetBadges gets the badges of a user in the order they were earned
+func (s *BadgeService) GetBadges(ctx context.Context, userID uuid.UUID) ([]*models.Badge, error) {
+	return s.badgeRepo.ListByUser(ctx, userID)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// WelcomeCampaign is the campaign introducing new users to the app over their first week
+const WelcomeCampaign = "WELCOME"
+
+// campaignStep is a notification of a campaign sent a number of days after the user was enrolled
+type campaignStep struct {
+	Key   string // sent as the step field of the payload, which the template picks the message by
+	Day   int    // days after enrollment the step is due
+	Plant bool   // whether the step is about the enrollment's plant
+}
+
+// campaignDefinition describes the steps of a campaign, all sent as notifications of one type
+type campaignDefinition struct {
+	Type  models.NotificationType
+	Steps []campaignStep
+}
+
+// campaigns is the registry of campaigns. A new campaign needs an entry here and a notification type
+// whose template has a message for each step.
+var campaigns = map[string]*campaignDefinition{
+	WelcomeCampaign: {
+		Type: models.NotificationTypeWelcome,
+		Steps: []campaignStep{
+			{Key: "WELCOME", Day: 0, Plant: true},
+			{Key: "FIRST_WATERING", Day: 1, Plant: true},
+			{Key: "CARE_TASKS", Day: 3},
+			{Key: "ADD_PLANTS", Day: 5},
+			{Key: "ASK_EXPERT", Day: 7},
+		},
+	},
+}
+
+// CampaignService sends the notifications of campaigns step by step. A step is sent once it is due
+// and the previous one was sent, so steps missed while the job was not running go out one per run.
+type CampaignService struct {
+	campaignRepo        repository.CampaignRepository
+	notificationService *NotificationService
+	now                 func() time.Time
+}
+
+// NewCampaignService creates a new campaign service
+func NewCampaignService(campaignRepo repository.CampaignRepository, notificationService *NotificationService) *CampaignService {
+	return &CampaignService{
+		campaignRepo:        campaignRepo,
+		notificationService: notificationService,
+		now:                 time.Now,
+	}
+}
+
+// Enroll enrolls a user in a campaign and sends its first step when it is due right away. Steps
+// about a plant link to plantID, or are sent without a plant when it is nil. Users already enrolled
+// are left as they are.
+func (s *CampaignService) Enroll(ctx context.Context, userID uuid.UUID, language models.Language, campaign string, plantID *uuid.UUID) error {
+	if _, ok := campaigns[campaign]; !ok {
+		return fmt.Errorf("unknown campaign %q", campaign)
+	}
+
+	enrollment := &models.CampaignEnrollment{
+		UserID:       userID,
+		Campaign:     campaign,
+		PlantID:      plantID,
+		UserLanguage: language,
+	}
+	enrolled, err := s.campaignRepo.Enroll(ctx, enrollment)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return nil
+	}
+
+	if _, err := s.sendDueStep(ctx, enrollment); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SendDueSteps sends the next step of every enrollment whose step is due and returns the number of
+// steps sent. An enrollment whose step cannot be sent is logged and tried again on the next run.
+func (s *CampaignService) SendDueSteps(ctx context.Context) (int, error) {
+	enrollments, err := s.campaignRepo.GetActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, enrollment := range enrollments {
+		ok, err := s.sendDueStep(ctx, enrollment)
+		if err != nil {
+			log.Printf("Error sending %s campaign step %d to user %s: %v",
+				enrollment.Campaign, enrollment.NextStep, enrollment.UserID, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendDueStep sends the next step of an enrollment when it is due and moves the enrollment on to
+// the step after it, reporting whether a step was sent
+func (s *CampaignService) sendDueStep(ctx context.Context, enrollment *models.CampaignEnrollment) (bool, error) {
+	definition, ok := campaigns[enrollment.Campaign]
+	if !ok {
+		return false, fmt.Errorf("unknown campaign %q", enrollment.Campaign)
+	}
+
+	// Enrollments of campaigns that lost steps since are completed
+	if enrollment.NextStep >= len(definition.Steps) {
+		return false, s.campaignRepo.SetNextStep(ctx, enrollment.UserID, enrollment.Campaign, enrollment.NextStep, true)
+	}
+	step := definition.Steps[enrollment.NextStep]
+	if s.now().Before(enrollment.EnrolledAt.AddDate(0, 0, step.Day)) {
+		return false, nil
+	}
+
+	payload := models.NotificationPayload{"step": step.Key}
+	if step.Plant && enrollment.PlantID != nil {
+		payload["plantId"] = enrollment.PlantID.String()
+	}
+	if _, err := s.notificationService.SendNotification(ctx, enrollment.UserID, enrollment.UserLanguage, definition.Type, payload); err != nil {
+		return false, fmt.Errorf("failed to send campaign step: %w", err)
+	}
+
+	nextStep := enrollment.NextStep + 1
+	if err := s.campaignRepo.SetNextStep(ctx, enrollment.UserID, enrollment.Campaign, nextStep, nextStep == len(definition.Steps)); err != nil {
+		return true, fmt.Errorf("failed to record campaign step: %w", err)
+	}
+	enrollment.NextStep = nextStep
+	return true, nil
+}
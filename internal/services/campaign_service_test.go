@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCampaignRepository is a mock implementation of the CampaignRepository interface
+type MockCampaignRepository struct {
+	mock.Mock
+}
+
+func (m *MockCampaignRepository) Enroll(ctx context.Context, enrollment *models.CampaignEnrollment) (bool, error) {
+	args := m.Called(ctx, enrollment)
+	if args.Bool(0) {
+		enrollment.EnrolledAt = time.Now()
+	}
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCampaignRepository) GetActive(ctx context.Context) ([]*models.CampaignEnrollment, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CampaignEnrollment), args.Error(1)
+}
+
+func (m *MockCampaignRepository) SetNextStep(ctx context.Context, userID uuid.UUID, campaign string, nextStep int, completed bool) error {
+	args := m.Called(ctx, userID, campaign, nextStep, completed)
+	return args.Error(0)
+}
+
+// TestCampaignService_Enroll tests that a new enrollment gets its first step right away, linked to
+// the sample plant, and that users already enrolled get nothing
+func TestCampaignService_Enroll(t *testing.T) {
+	mockCampaignRepo := new(MockCampaignRepository)
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	service := NewCampaignService(mockCampaignRepo, NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(mockTemplateRepo)))
+
+	userID, plantID := uuid.New(), uuid.New()
+	mockCampaignRepo.On("Enroll", mock.Anything, mock.MatchedBy(func(e *models.CampaignEnrollment) bool {
+		return e.UserID == userID && e.Campaign == WelcomeCampaign && *e.PlantID == plantID
+	})).Return(true, nil).Once()
+	mockPlantRepo.On("GetByID", mock.Anything, plantID).Return(&models.Plant{ID: plantID, Name: "Monstera"}, nil)
+	mockTemplateRepo.On("Get", mock.Anything, models.NotificationTypeWelcome, models.LanguageEnglish).Return(nil, nil)
+	var notification *models.Notification
+	mockNotificationRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		notification = args.Get(1).(*models.Notification)
+	}).Return(nil).Once()
+	mockCampaignRepo.On("SetNextStep", mock.Anything, userID, WelcomeCampaign, 1, false).Return(nil).Once()
+
+	err := service.Enroll(context.Background(), userID, models.LanguageEnglish, WelcomeCampaign, &plantID)
+	require.NoError(t, err)
+	require.NotNil(t, notification)
+	assert.Equal(t, models.NotificationTypeWelcome, notification.Type)
+	assert.Equal(t, plantID, *notification.PlantID)
+	assert.Equal(t, "Welcome to Planter! We added Monstera to your collection — have a look at its care schedule.", notification.Message)
+
+	// Users already enrolled are not welcomed again
+	mockCampaignRepo.On("Enroll", mock.Anything, mock.Anything).Return(false, nil).Once()
+	assert.NoError(t, service.Enroll(context.Background(), userID, models.LanguageEnglish, WelcomeCampaign, &plantID))
+	mockCampaignRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertExpectations(t)
+}
+
+// TestCampaignService_SendDueSteps tests that only due steps are sent, one per enrollment, that
+// steps not about a plant do not link to it, and that the last step completes the enrollment
+func TestCampaignService_SendDueSteps(t *testing.T) {
+	mockCampaignRepo := new(MockCampaignRepository)
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	service := NewCampaignService(mockCampaignRepo, NewNotificationService(mockNotificationRepo, new(MockPlantRepository), nil, NewNotificationTemplateService(mockTemplateRepo)))
+	now := time.Date(2024, time.May, 10, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	plantID := uuid.New()
+	notDue := &models.CampaignEnrollment{UserID: uuid.New(), Campaign: WelcomeCampaign, PlantID: &plantID, NextStep: 2, EnrolledAt: now.AddDate(0, 0, -2), UserLanguage: models.LanguageRussian}
+	behind := &models.CampaignEnrollment{UserID: uuid.New(), Campaign: WelcomeCampaign, PlantID: &plantID, NextStep: 2, EnrolledAt: now.AddDate(0, 0, -6), UserLanguage: models.LanguageRussian}
+	last := &models.CampaignEnrollment{UserID: uuid.New(), Campaign: WelcomeCampaign, NextStep: 4, EnrolledAt: now.AddDate(0, 0, -7), UserLanguage: models.LanguageEnglish}
+	mockCampaignRepo.On("GetActive", mock.Anything).Return([]*models.CampaignEnrollment{notDue, behind, last}, nil)
+	mockTemplateRepo.On("Get", mock.Anything, models.NotificationTypeWelcome, mock.Anything).Return(nil, nil)
+
+	messages := make(map[uuid.UUID]string)
+	mockNotificationRepo.On("Create", mock.Anything, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.PlantID == nil
+	})).Run(func(args mock.Arguments) {
+		notification := args.Get(1).(*models.Notification)
+		messages[notification.UserID] = notification.Message
+	}).Return(nil).Twice()
+	mockCampaignRepo.On("SetNextStep", mock.Anything, behind.UserID, WelcomeCampaign, 3, false).Return(nil).Once()
+	mockCampaignRepo.On("SetNextStep", mock.Anything, last.UserID, WelcomeCampaign, 5, true).Return(nil).Once()
+
+	sent, err := service.SendDueSteps(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, "Кроме полива растениям нужны подкормка, опрыскивание и обрезка: эти задачи уже в вашем расписании ухода.", messages[behind.UserID])
+	assert.Equal(t, "Something wrong with a plant? Ask the assistant in the chat what to do.", messages[last.UserID])
+	mockCampaignRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertExpectations(t)
+}
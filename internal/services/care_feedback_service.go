@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrCareFeedbackNotEligible is returned when a user has not owned a plant long enough to rate its difficulty
+var ErrCareFeedbackNotEligible = errors.New("plant has not been owned long enough to rate its difficulty")
+
+const (
+	// careFeedbackAfterMonths is how long a user owns a plant before being asked about its difficulty
+	careFeedbackAfterMonths = 3
+
+	// defaultEditorialDifficulty is the difficulty assumed for plants without an editorial rating
+	defaultEditorialDifficulty = 3
+
+	// communityPriorWeight is the number of virtual "as expected" answers the editorial rating counts as,
+	// so a handful of answers cannot swing the score
+	communityPriorWeight = 5
+
+	// communityMaxShift is how far the community score can move from the editorial one when every owner agrees
+	communityMaxShift = 2.0
+
+	// minCommunityResponses is the number of answers needed before the community difficulty is shown
+	minCommunityResponses = 5
+)
+
+// CareFeedbackService collects owners' feedback on plant difficulty and recalibrates a community difficulty from it
+type CareFeedbackService struct {
+	feedbackRepo        repository.CareFeedbackRepository
+	plantRepo           repository.PlantRepository
+	notificationService *NotificationService
+}
+
+// NewCareFeedbackService creates a new care feedback service
+func NewCareFeedbackService(
+	feedbackRepo repository.CareFeedbackRepository,
+	plantRepo repository.PlantRepository,
+	notificationService *NotificationService,
+) *CareFeedbackService {
+	return &CareFeedbackService{
+		feedbackRepo:        feedbackRepo,
+		plantRepo:           plantRepo,
+		notificationService: notificationService,
+	}
+}
+
+// SubmitFeedback saves a user's answer about how hard a plant in their collection was to care for
+func (s *CareFeedbackService) SubmitFeedback(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, rating models.CareFeedbackRating) (*models.CareFeedback, error) {
+	// Check if the user owns the plant
+	userPlant, err := s.plantRepo.GetUserPlant(ctx, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("plant not in user's collection: %w", err)
+	}
+
+	// Check if the user has owned it long enough
+	if userPlant.CreatedAt.After(time.Now().AddDate(0, -careFeedbackAfterMonths, 0)) {
+		return nil, ErrCareFeedbackNotEligible
+	}
+
+	feedback := &models.CareFeedback{
+		UserID:  userID,
+		PlantID: plantID,
+		Rating:  rating,
+	}
+	if err := s.feedbackRepo.Upsert(ctx, feedback); err != nil {
+		return nil, fmt.Errorf("failed to save care feedback: %w", err)
+	}
+	return feedback, nil
+}
+
+// RequestFeedback notifies owners of plants they have had for three months and were not asked about yet
+func (s *CareFeedbackService) RequestFeedback(ctx context.Context) (int, error) {
+	candidates, err := s.feedbackRepo.GetFeedbackCandidates(ctx, time.Now().AddDate(0, -careFeedbackAfterMonths, 0))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get care feedback candidates: %w", err)
+	}
+
+	requested := 0
+	for _, userPlant := range candidates {
+		err := s.notificationService.CreatePlantNotification(ctx, userPlant, models.NotificationTypeCareFeedback, nil)
+		if err != nil {
+			return requested, fmt.Errorf("failed to request care feedback: %w", err)
+		}
+		requested++
+	}
+	return requested, nil
+}
+
+// Recalibrate recomputes the community difficulty of every plant with feedback
+func (s *CareFeedbackService) Recalibrate(ctx context.Context) (int, error) {
+	totals, err := s.feedbackRepo.GetTotals(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get care feedback totals: %w", err)
+	}
+
+	now := time.Now()
+	difficulties := make([]*models.CommunityDifficulty, 0, len(totals))
+	for _, total := range totals {
+		difficulties = append(difficulties, &models.CommunityDifficulty{
+			PlantID:   total.PlantID,
+			Score:     communityDifficulty(total),
+			Responses: total.Easier + total.AsExpected + total.Harder,
+			UpdatedAt: now,
+		})
+	}
+
+	if err := s.feedbackRepo.SaveCommunityDifficulties(ctx, difficulties); err != nil {
+		return 0, fmt.Errorf("failed to save community difficulties: %w", err)
+	}
+	return len(difficulties), nil
+}
+
+// GetDifficulty gets the editorial and community difficulty of a plant
+func (s *CareFeedbackService) GetDifficulty(ctx context.Context, plantID uuid.UUID) (*models.PlantDifficulty, error) {
+	difficulty, err := s.feedbackRepo.GetDifficulty(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant difficulty: %w", err)
+	}
+
+	// A few answers are not representative yet
+	if difficulty.Community != nil && difficulty.Community.Responses < minCommunityResponses {
+		difficulty.Community = nil
+	}
+	return difficulty, nil
+}
+
+// SetEditorialDifficulty sets the editorial difficulty of a plant
+func (s *CareFeedbackService) SetEditorialDifficulty(ctx context.Context, plantID uuid.UUID, difficulty int) error {
+	if err := s.feedbackRepo.SetEditorialDifficulty(ctx, plantID, difficulty); err != nil {
+		return fmt.Errorf("failed to set editorial difficulty: %w", err)
+	}
+	return nil
+}
+
+// communityDifficulty shifts the editorial difficulty by the share of owners who found the plant
+// harder or easier than expected, shrunk towards the editorial rating while there are few answers
+func communityDifficulty(total *models.CareFeedbackTotals) float64 {
+	base := float64(defaultEditorialDifficulty)
+	if total.Editorial != nil {
+		base = float64(*total.Editorial)
+	}
+
+	responses := total.Easier + total.AsExpected + total.Harder
+	shift := communityMaxShift * float64(total.Harder-total.Easier) / float64(responses+communityPriorWeight)
+
+	score := math.Max(1, math.Min(5, base+shift))
+	return math.Round(score*100) / 100
+}
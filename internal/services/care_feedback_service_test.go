@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCareFeedbackRepository is a mock implementation of the CareFeedbackRepository interface
+type MockCareFeedbackRepository struct {
+	mock.Mock
+}
+
+func (m *MockCareFeedbackRepository) Upsert(ctx context.Context, feedback *models.CareFeedback) error {
+	args := m.Called(ctx, feedback)
+	return args.Error(0)
+}
+
+func (m *MockCareFeedbackRepository) GetFeedbackCandidates(ctx context.Context, ownedBefore time.Time) ([]*models.UserPlant, error) {
+	args := m.Called(ctx, ownedBefore)
+	return args.Get(0).([]*models.UserPlant), args.Error(1)
+}
+
+func (m *MockCareFeedbackRepository) GetTotals(ctx context.Context) ([]*models.CareFeedbackTotals, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.CareFeedbackTotals), args.Error(1)
+}
+
+func (m *MockCareFeedbackRepository) SaveCommunityDifficulties(ctx context.Context, difficulties []*models.CommunityDifficulty) error {
+	args := m.Called(ctx, difficulties)
+	return args.Error(0)
+}
+
+func (m *MockCareFeedbackRepository) GetDifficulty(ctx context.Context, plantID uuid.UUID) (*models.PlantDifficulty, error) {
+	args := m.Called(ctx, plantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PlantDifficulty), args.Error(1)
+}
+
+func (m *MockCareFeedbackRepository) SetEditorialDifficulty(ctx context.Context, plantID uuid.UUID, difficulty int) error {
+	args := m.Called(ctx, plantID, difficulty)
+	return args.Error(0)
+}
+
+// TestCareFeedbackService_SubmitFeedback tests that owners of three months can rate a plant
+func TestCareFeedbackService_SubmitFeedback(t *testing.T) {
+	mockFeedbackRepo := new(MockCareFeedbackRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewCareFeedbackService(mockFeedbackRepo, mockPlantRepo, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	plantID := uuid.New()
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{
+		UserID:    userID,
+		PlantID:   plantID,
+		CreatedAt: time.Now().AddDate(0, -4, 0),
+	}, nil)
+	mockFeedbackRepo.On("Upsert", ctx, mock.MatchedBy(func(f *models.CareFeedback) bool {
+		return f.UserID == userID && f.PlantID == plantID && f.Rating == models.CareFeedbackRatingHarder
+	})).Return(nil)
+
+	feedback, err := service.SubmitFeedback(ctx, userID, plantID, models.CareFeedbackRatingHarder)
+	assert.NoError(t, err)
+	assert.Equal(t, models.CareFeedbackRatingHarder, feedback.Rating)
+	mockFeedbackRepo.AssertExpectations(t)
+}
+
+// TestCareFeedbackService_SubmitFeedback_TooEarly tests that recently added plants cannot be rated
+func TestCareFeedbackService_SubmitFeedback_TooEarly(t *testing.T) {
+	mockFeedbackRepo := new(MockCareFeedbackRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewCareFeedbackService(mockFeedbackRepo, mockPlantRepo, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+	plantID := uuid.New()
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{
+		UserID:    userID,
+		PlantID:   plantID,
+		CreatedAt: time.Now().AddDate(0, -1, 0),
+	}, nil)
+
+	_, err := service.SubmitFeedback(ctx, userID, plantID, models.CareFeedbackRatingEasier)
+	assert.ErrorIs(t, err, ErrCareFeedbackNotEligible)
+	mockFeedbackRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything)
+}
+
+// TestCareFeedbackService_RequestFeedback tests that candidates receive a care feedback notification
+func TestCareFeedbackService_RequestFeedback(t *testing.T) {
+	mockFeedbackRepo := new(MockCareFeedbackRepository)
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	notificationService := NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(mockTemplateRepo))
+	service := NewCareFeedbackService(mockFeedbackRepo, mockPlantRepo, notificationService)
+	ctx := context.Background()
+
+	userPlant := &models.UserPlant{
+		UserID:       uuid.New(),
+		PlantID:      uuid.New(),
+		Plant:        &models.Plant{Name: "Monstera"},
+		UserLanguage: models.LanguageEnglish,
+	}
+
+	mockFeedbackRepo.On("GetFeedbackCandidates", ctx, mock.AnythingOfType("time.Time")).Return([]*models.UserPlant{userPlant}, nil)
+	mockTemplateRepo.On("Get", ctx, models.NotificationTypeCareFeedback, models.LanguageEnglish).Return(nil, nil)
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.UserID == userPlant.UserID && n.Type == models.NotificationTypeCareFeedback
+	})).Return(nil)
+
+	requested, err := service.RequestFeedback(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requested)
+	mockNotificationRepo.AssertExpectations(t)
+}
+
+// TestCareFeedbackService_Recalibrate tests that the community score moves towards the answers
+func TestCareFeedbackService_Recalibrate(t *testing.T) {
+	mockFeedbackRepo := new(MockCareFeedbackRepository)
+	service := NewCareFeedbackService(mockFeedbackRepo, new(MockPlantRepository), nil)
+	ctx := context.Background()
+	editorial := 2
+	harderPlant := uuid.New()
+	easierPlant := uuid.New()
+
+	mockFeedbackRepo.On("GetTotals", ctx).Return([]*models.CareFeedbackTotals{
+		{PlantID: harderPlant, Editorial: &editorial, Harder: 15},
+		{PlantID: easierPlant, Easier: 3, AsExpected: 2},
+	}, nil)
+	mockFeedbackRepo.On("SaveCommunityDifficulties", ctx, mock.MatchedBy(func(d []*models.CommunityDifficulty) bool {
+		return len(d) == 2 &&
+			d[0].PlantID == harderPlant && d[0].Score == 3.5 && d[0].Responses == 15 &&
+			d[1].PlantID == easierPlant && d[1].Score == 2.4 && d[1].Responses == 5
+	})).Return(nil)
+
+	recalibrated, err := service.Recalibrate(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, recalibrated)
+	mockFeedbackRepo.AssertExpectations(t)
+}
+
+// TestCareFeedbackService_GetDifficulty_FewResponses tests that the community score is hidden until enough owners answered
+func TestCareFeedbackService_GetDifficulty_FewResponses(t *testing.T) {
+	mockFeedbackRepo := new(MockCareFeedbackRepository)
+	service := NewCareFeedbackService(mockFeedbackRepo, new(MockPlantRepository), nil)
+	ctx := context.Background()
+	plantID := uuid.New()
+
+	mockFeedbackRepo.On("GetDifficulty", ctx, plantID).Return(&models.PlantDifficulty{
+		PlantID:   plantID,
+		Community: &models.CommunityDifficulty{Score: 4, Responses: 2},
+	}, nil)
+
+	difficulty, err := service.GetDifficulty(ctx, plantID)
+	assert.NoError(t, err)
+	assert.Nil(t, difficulty.Community)
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+//go:embed templates/care_hints.json
+var careHintRulesJSON []byte
+
+// careHintRules holds the rules presenting watering urgency by language
+var careHintRules = mustLoadCareHintRules(careHintRulesJSON)
+
+// careHintRule is how watering urgency is presented in a language
+type careHintRule struct {
+	dueSoonDays int // plants due within this many days after today are DUE_SOON
+	badges      map[models.CareStatus]*template.Template
+}
+
+// careHintBadgeData is the data of a badge template
+type careHintBadgeData struct {
+	Days int // days until the next watering, or days overdue for OVERDUE
+}
+
+// AddCareHints sets the care hint of the plants of a collection that have a watering schedule
+func AddCareHints(plants []*models.Plant, language models.Language, now time.Time) {
+	for _, plant := range plants {
+		if plant.NextWatering != nil {
+			plant.CareHint = CareHintFor(*plant.NextWatering, language, now)
+		}
+	}
+}
+
+// CareHintFor gets the care hint of a plant next watered at the given time. Days are counted in UTC
+// calendar days, and unsupported languages fall back to Russian.
+func CareHintFor(nextWatering time.Time, language models.Language, now time.Time) *models.CareHint {
+	rule, ok := careHintRules[language]
+	if !ok {
+		rule = careHintRules[models.LanguageRussian]
+	}
+
+	days := int(truncateToDay(nextWatering).Sub(truncateToDay(now)).Hours() / 24)
+	hint := &models.CareHint{SortPriority: days}
+	badgeDays := days
+	switch {
+	case days < 0:
+		hint.Status = models.CareStatusOverdue
+		badgeDays = -days
+	case days <= rule.dueSoonDays:
+		hint.Status = models.CareStatusDueSoon
+	default:
+		hint.Status = models.CareStatusOK
+	}
+
+	var badge bytes.Buffer
+	if err := rule.badges[hint.Status].Execute(&badge, careHintBadgeData{Days: badgeDays}); err == nil {
+		hint.Badge = badge.String()
+	}
+	return hint
+}
+
+// mustLoadCareHintRules parses the care hint rules and panics if they are invalid, miss a badge or
+// miss Russian, the language others fall back to
+func mustLoadCareHintRules(data []byte) map[models.Language]*careHintRule {
+	var sources map[models.Language]struct {
+		DueSoonDays int                          `json:"dueSoonDays"`
+		Badges      map[models.CareStatus]string `json:"badges"`
+	}
+	if err := json.Unmarshal(data, &sources); err != nil {
+		panic(fmt.Sprintf("invalid care hint rules: %v", err))
+	}
+	if _, ok := sources[models.LanguageRussian]; !ok {
+		panic("care hint rules have no Russian version")
+	}
+
+	rules := make(map[models.Language]*careHintRule, len(sources))
+	for language, source := range sources {
+		rule := &careHintRule{dueSoonDays: source.DueSoonDays, badges: make(map[models.CareStatus]*template.Template)}
+		for _, status := range []models.CareStatus{models.CareStatusOverdue, models.CareStatusDueSoon, models.CareStatusOK} {
+			text, ok := source.Badges[status]
+			if !ok {
+				panic(fmt.Sprintf("care hint rules for %s have no %s badge", language, status))
+			}
+			badge, err := template.New(string(language) + "/" + string(status)).Parse(text)
+			if err != nil {
+				panic(fmt.Sprintf("invalid %s %s badge: %v", language, status, err))
+			}
+			rule.badges[status] = badge
+		}
+		rules[language] = rule
+	}
+	return rules
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCareHintFor tests the status, badge and sort priority of plants watered at different days in
+// both languages, counting calendar days
+func TestCareHintFor(t *testing.T) {
+	now := time.Date(2024, 5, 10, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		nextWatering time.Time
+		language     models.Language
+		expected     models.CareHint
+	}{
+		{time.Date(2024, 5, 7, 9, 0, 0, 0, time.UTC), models.LanguageEnglish, models.CareHint{Status: models.CareStatusOverdue, Badge: "3 days overdue", SortPriority: -3}},
+		{time.Date(2024, 5, 9, 23, 0, 0, 0, time.UTC), models.LanguageEnglish, models.CareHint{Status: models.CareStatusOverdue, Badge: "1 day overdue", SortPriority: -1}},
+		{time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC), models.LanguageEnglish, models.CareHint{Status: models.CareStatusDueSoon, Badge: "Water today", SortPriority: 0}},
+		{time.Date(2024, 5, 11, 1, 0, 0, 0, time.UTC), models.LanguageEnglish, models.CareHint{Status: models.CareStatusDueSoon, Badge: "Water tomorrow", SortPriority: 1}},
+		{time.Date(2024, 5, 15, 8, 0, 0, 0, time.UTC), models.LanguageEnglish, models.CareHint{Status: models.CareStatusOK, Badge: "Water in 5 days", SortPriority: 5}},
+		{time.Date(2024, 5, 8, 8, 0, 0, 0, time.UTC), models.LanguageRussian, models.CareHint{Status: models.CareStatusOverdue, Badge: "Полив просрочен на 2 дн.", SortPriority: -2}},
+		{time.Date(2024, 5, 11, 8, 0, 0, 0, time.UTC), models.LanguageRussian, models.CareHint{Status: models.CareStatusDueSoon, Badge: "Полить завтра", SortPriority: 1}},
+		// Unsupported languages fall back to Russian
+		{time.Date(2024, 5, 13, 8, 0, 0, 0, time.UTC), models.Language("GERMAN"), models.CareHint{Status: models.CareStatusOK, Badge: "Полив через 3 дн.", SortPriority: 3}},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, &tc.expected, CareHintFor(tc.nextWatering, tc.language, now), tc.nextWatering)
+	}
+}
+
+// TestAddCareHints tests that only plants with a watering schedule get a hint
+func TestAddCareHints(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	due := now.Add(-time.Hour)
+	scheduled := &models.Plant{Name: "Monstera", NextWatering: &due}
+	unscheduled := &models.Plant{Name: "Cactus"}
+
+	AddCareHints([]*models.Plant{scheduled, unscheduled}, models.LanguageEnglish, now)
+
+	if assert.NotNil(t, scheduled.CareHint) {
+		assert.Equal(t, models.CareStatusDueSoon, scheduled.CareHint.Status)
+	}
+	assert.Nil(t, unscheduled.CareHint)
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrCarePlanNotFound is returned when a plant in the user's collection has no care plan yet
+var ErrCarePlanNotFound = errors.New("care plan not found")
+
+const (
+	// carePlanMonths is the number of months a care plan covers
+	carePlanMonths = 12
+
+	// dormantWateringFactor stretches the watering interval while the plant is dormant
+	dormantWateringFactor = 1.5
+
+	// carePlanReminderGraceDays is how long a missed reminder is still worth sending
+	carePlanReminderGraceDays = 7
+)
+
+// Seasons of the northern hemisphere; they are shifted by six months in the southern one
+var (
+	growingMonths = map[time.Month]bool{
+		time.March: true, time.April: true, time.May: true, time.June: true,
+		time.July: true, time.August: true, time.September: true,
+	}
+	dormantMonths = map[time.Month]bool{
+		time.November: true, time.December: true, time.January: true, time.February: true,
+	}
+	repotMonths = map[time.Month]bool{
+		time.March: true, time.April: true,
+	}
+)
+
+// carePlanNotes holds the month notes of a care plan in each supported language
+var carePlanNotes = map[models.Language]struct {
+	Repot      string
+	Fertilize  string
+	Dormant    string
+	Transition string
+}{
+	models.LanguageRussian: {
+		Repot:      "Окно для пересадки: если корни заполнили горшок, пересадите растение в горшок на 2–3 см шире.",
+		Fertilize:  "Сезон подкормок: подкармливайте каждые %d дн.",
+		Dormant:    "Период покоя: поливайте реже, примерно раз в %d дн., и не подкармливайте.",
+		Transition: "Переходный период: постепенно сокращайте полив и подкормки, следите за освещением.",
+	},
+	models.LanguageEnglish: {
+		Repot:      "Repotting window: if the roots fill the pot, move the plant to a pot 2-3 cm wider.",
+		Fertilize:  "Fertilizing season: feed every %d days.",
+		Dormant:    "Dormancy: water less, about every %d days, and do not fertilize.",
+		Transition: "Transition period: gradually reduce watering and feeding and keep an eye on the light.",
+	},
+}
+
+// CarePlanAdvisor writes a summary of a rule-based care plan
+type CarePlanAdvisor interface {
+	GenerateCarePlanAdvice(ctx context.Context, plant *models.Plant, plan *models.CarePlan, language models.Language) (string, error)
+}
+
+// CarePlanService generates long-term care plans of plants in users' collections and reminds owners
+// when a repotting, fertilizing or dormancy window of a plan begins
+type CarePlanService struct {
+	carePlanRepo        repository.CarePlanRepository
+	plantRepo           repository.PlantRepository
+	notificationService *NotificationService
+	advisor             CarePlanAdvisor
+}
+
+// NewCarePlanService creates a new care plan service
+func NewCarePlanService(
+	carePlanRepo repository.CarePlanRepository,
+	plantRepo repository.PlantRepository,
+	notificationService *NotificationService,
+	advisor CarePlanAdvisor,
+) *CarePlanService {
+	return &CarePlanService{
+		carePlanRepo:        carePlanRepo,
+		plantRepo:           plantRepo,
+		notificationService: notificationService,
+		advisor:             advisor,
+	}
+}
+
+// GeneratePlan builds a 12-month care plan of a plant in the user's collection starting this month and
+// replaces the previous one. The summary is omitted when Yandex GPT is unavailable.
+func (s *CarePlanService) GeneratePlan(
+	ctx context.Context,
+	userID uuid.UUID,
+	plantID uuid.UUID,
+	req *models.CarePlanRequest,
+	language models.Language,
+) (*models.CarePlan, error) {
+	if _, ok := carePlanNotes[language]; !ok {
+		language = models.LanguageRussian
+	}
+
+	// Check if the user owns the plant
+	if _, err := s.plantRepo.GetUserPlant(ctx, userID, plantID); err != nil {
+		return nil, fmt.Errorf("plant not in user's collection: %w", err)
+	}
+
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant: %w", err)
+	}
+
+	hemisphere := models.HemisphereNorthern
+	if req.Hemisphere != nil {
+		hemisphere = *req.Hemisphere
+	}
+
+	plan := buildCarePlan(plant, hemisphere, time.Now(), language)
+	plan.UserID = userID
+	plan.PlantID = plantID
+
+	// The rule-based plan is useful on its own, so a failed summary is only logged
+	if s.advisor != nil {
+		summary, err := s.advisor.GenerateCarePlanAdvice(ctx, plant, plan, language)
+		if err != nil {
+			if !errors.Is(err, ErrYandexGPTNotConfigured) {
+				log.Printf("Error generating care plan summary: %v", err)
+			}
+		} else if summary = strings.TrimSpace(summary); summary != "" {
+			plan.Summary = &summary
+		}
+	}
+
+	if err := s.carePlanRepo.Save(ctx, plan, carePlanReminders(plan)); err != nil {
+		return nil, fmt.Errorf("failed to save care plan: %w", err)
+	}
+	return plan, nil
+}
+
+// GetPlan gets the care plan of a plant in the user's collection
+func (s *CarePlanService) GetPlan(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) (*models.CarePlan, error) {
+	plan, err := s.carePlanRepo.GetByUserPlant(ctx, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care plan: %w", err)
+	}
+	if plan == nil {
+		return nil, ErrCarePlanNotFound
+	}
+	return plan, nil
+}
+
+// SendReminders notifies owners about care plan windows beginning today or in the last few days
+func (s *CarePlanService) SendReminders(ctx context.Context) (int, error) {
+	today := truncateToDay(time.Now())
+	reminders, err := s.carePlanRepo.GetDueReminders(ctx, today.AddDate(0, 0, -carePlanReminderGraceDays), today.AddDate(0, 0, 1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get due care plan reminders: %w", err)
+	}
+
+	sent := 0
+	for _, reminder := range reminders {
+		err := s.notificationService.CreatePlantNotification(ctx, reminder.UserPlant, reminder.Type, &reminder.DueOn)
+		if err != nil {
+			return sent, fmt.Errorf("failed to send care plan reminder: %w", err)
+		}
+		if err := s.carePlanRepo.MarkReminderSent(ctx, reminder.ID); err != nil {
+			return sent, fmt.Errorf("failed to mark care plan reminder as sent: %w", err)
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// buildCarePlan lays out the months of a care plan starting with the month of now. Plants are
+// repotted in early spring, fertilized through the growing season and watered less while dormant.
+func buildCarePlan(plant *models.Plant, hemisphere models.Hemisphere, now time.Time, language models.Language) *models.CarePlan {
+	care := plant.CareInstructions
+	notes := carePlanNotes[language]
+	start := truncateToDay(now).AddDate(0, 0, 1-now.UTC().Day())
+
+	plan := &models.CarePlan{
+		Hemisphere: hemisphere,
+		StartsOn:   start,
+		Months:     make([]*models.CarePlanMonth, 0, carePlanMonths),
+	}
+	for i := 0; i < carePlanMonths; i++ {
+		month := start.AddDate(0, i, 0)
+		season := seasonMonth(month.Month(), hemisphere)
+
+		planMonth := &models.CarePlanMonth{
+			Month:             month,
+			Fertilize:         growingMonths[season] && care.FertilizerFrequency > 0,
+			Repot:             repotMonths[season],
+			Dormant:           dormantMonths[season],
+			WateringFrequency: care.WateringFrequency,
+		}
+		if planMonth.Dormant {
+			planMonth.WateringFrequency = int(math.Ceil(float64(care.WateringFrequency) * dormantWateringFactor))
+		}
+
+		var monthNotes []string
+		if planMonth.Repot {
+			monthNotes = append(monthNotes, notes.Repot)
+		}
+		if planMonth.Fertilize {
+			monthNotes = append(monthNotes, fmt.Sprintf(notes.Fertilize, care.FertilizerFrequency))
+		}
+		if planMonth.Dormant {
+			monthNotes = append(monthNotes, fmt.Sprintf(notes.Dormant, planMonth.WateringFrequency))
+		}
+		if len(monthNotes) == 0 {
+			monthNotes = append(monthNotes, notes.Transition)
+		}
+		planMonth.Notes = strings.Join(monthNotes, " ")
+
+		plan.Months = append(plan.Months, planMonth)
+	}
+
+	return plan
+}
+
+// seasonMonth returns the month of the northern hemisphere with the same season as month in hemisphere
+func seasonMonth(month time.Month, hemisphere models.Hemisphere) time.Month {
+	if hemisphere == models.HemisphereSouthern {
+		return (month+5)%12 + 1
+	}
+	return month
+}
+
+// carePlanReminders schedules a reminder on the first day of every repotting, fertilizing and dormancy
+// window that begins after the first month of the plan; the current month is already shown in the plan
+func carePlanReminders(plan *models.CarePlan) []*models.CarePlanReminder {
+	windows := []struct {
+		notificationType models.NotificationType
+		inWindow         func(*models.CarePlanMonth) bool
+	}{
+		{models.NotificationTypeRepotting, func(m *models.CarePlanMonth) bool { return m.Repot }},
+		{models.NotificationTypeFertilizingSeason, func(m *models.CarePlanMonth) bool { return m.Fertilize }},
+		{models.NotificationTypeDormancy, func(m *models.CarePlanMonth) bool { return m.Dormant }},
+	}
+
+	var reminders []*models.CarePlanReminder
+	for i := 1; i < len(plan.Months); i++ {
+		for _, window := range windows {
+			if window.inWindow(plan.Months[i]) && !window.inWindow(plan.Months[i-1]) {
+				reminders = append(reminders, &models.CarePlanReminder{
+					Type:  window.notificationType,
+					DueOn: plan.Months[i].Month,
+				})
+			}
+		}
+	}
+	return reminders
+}
+
+// carePlanMonth returns the month of a care plan containing the date, or nil if the plan does not cover it
+func carePlanMonth(plan *models.CarePlan, date time.Time) *models.CarePlanMonth {
+	if plan == nil {
+		return nil
+	}
+	date = date.UTC()
+	for _, month := range plan.Months {
+		if month.Month.Year() == date.Year() && month.Month.Month() == date.Month() {
+			return month
+		}
+	}
+	return nil
+}
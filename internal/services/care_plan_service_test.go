@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCarePlanRepository is a mock implementation of the CarePlanRepository interface
+type MockCarePlanRepository struct {
+	mock.Mock
+}
+
+func (m *MockCarePlanRepository) Save(ctx context.Context, plan *models.CarePlan, reminders []*models.CarePlanReminder) error {
+	args := m.Called(ctx, plan, reminders)
+	return args.Error(0)
+}
+
+func (m *MockCarePlanRepository) GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) (*models.CarePlan, error) {
+	args := m.Called(ctx, userID, plantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CarePlan), args.Error(1)
+}
+
+func (m *MockCarePlanRepository) GetDueReminders(ctx context.Context, from time.Time, to time.Time) ([]*models.CarePlanReminder, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).([]*models.CarePlanReminder), args.Error(1)
+}
+
+func (m *MockCarePlanRepository) MarkReminderSent(ctx context.Context, reminderID uuid.UUID) error {
+	args := m.Called(ctx, reminderID)
+	return args.Error(0)
+}
+
+// carePlanFixture returns a plant watered every 4 days and fertilized every 14 days
+func carePlanFixture() *models.Plant {
+	return &models.Plant{
+		ID:   uuid.New(),
+		Name: "Monstera",
+		CareInstructions: models.CareInstructions{
+			WateringFrequency:   4,
+			FertilizerFrequency: 14,
+		},
+	}
+}
+
+// TestBuildCarePlan tests the seasons of a plan in both hemispheres
+func TestBuildCarePlan(t *testing.T) {
+	plant := carePlanFixture()
+	now := time.Date(2024, time.January, 17, 15, 0, 0, 0, time.UTC)
+
+	plan := buildCarePlan(plant, models.HemisphereNorthern, now, models.LanguageEnglish)
+	assert.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), plan.StartsOn)
+	assert.Len(t, plan.Months, carePlanMonths)
+
+	january, march := plan.Months[0], plan.Months[2]
+	assert.True(t, january.Dormant)
+	assert.False(t, january.Fertilize)
+	assert.Equal(t, 6, january.WateringFrequency)
+	assert.Contains(t, january.Notes, "every 6 days")
+	assert.True(t, march.Repot)
+	assert.True(t, march.Fertilize)
+	assert.Equal(t, 4, march.WateringFrequency)
+
+	// Seasons are shifted by six months in the southern hemisphere
+	plan = buildCarePlan(plant, models.HemisphereSouthern, now, models.LanguageEnglish)
+	assert.False(t, plan.Months[0].Dormant)
+	assert.True(t, plan.Months[0].Fertilize)
+	assert.True(t, plan.Months[8].Repot) // September
+	assert.True(t, plan.Months[6].Dormant)
+}
+
+// TestCarePlanReminders tests that reminders are scheduled when windows begin after the first month
+func TestCarePlanReminders(t *testing.T) {
+	plan := buildCarePlan(carePlanFixture(), models.HemisphereNorthern, time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC), models.LanguageEnglish)
+
+	reminders := carePlanReminders(plan)
+
+	due := map[models.NotificationType]time.Time{}
+	for _, reminder := range reminders {
+		due[reminder.Type] = reminder.DueOn
+	}
+	assert.Len(t, reminders, 3)
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), due[models.NotificationTypeRepotting])
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), due[models.NotificationTypeFertilizingSeason])
+	assert.Equal(t, time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC), due[models.NotificationTypeDormancy])
+}
+
+// TestCarePlanService_GeneratePlan tests that a plan is saved with its reminders without Yandex GPT
+func TestCarePlanService_GeneratePlan(t *testing.T) {
+	mockCarePlanRepo := new(MockCarePlanRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewCarePlanService(mockCarePlanRepo, mockPlantRepo, nil, nil)
+
+	userID := uuid.New()
+	plant := carePlanFixture()
+	southern := models.HemisphereSouthern
+
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userID, plant.ID).Return(&models.UserPlant{UserID: userID, PlantID: plant.ID}, nil)
+	mockPlantRepo.On("GetByID", mock.Anything, plant.ID).Return(plant, nil)
+	mockCarePlanRepo.On("Save", mock.Anything, mock.MatchedBy(func(plan *models.CarePlan) bool {
+		return plan.UserID == userID && plan.PlantID == plant.ID && plan.Hemisphere == models.HemisphereSouthern
+	}), mock.Anything).Return(nil)
+
+	plan, err := service.GeneratePlan(context.Background(), userID, plant.ID, &models.CarePlanRequest{Hemisphere: &southern}, models.LanguageRussian)
+
+	assert.NoError(t, err)
+	assert.Len(t, plan.Months, carePlanMonths)
+	assert.Nil(t, plan.Summary)
+	mockCarePlanRepo.AssertExpectations(t)
+}
+
+// TestCarePlanService_GetPlan_NotFound tests that a missing plan is reported
+func TestCarePlanService_GetPlan_NotFound(t *testing.T) {
+	mockCarePlanRepo := new(MockCarePlanRepository)
+	service := NewCarePlanService(mockCarePlanRepo, new(MockPlantRepository), nil, nil)
+
+	userID, plantID := uuid.New(), uuid.New()
+	mockCarePlanRepo.On("GetByUserPlant", mock.Anything, userID, plantID).Return(nil, nil)
+
+	plan, err := service.GetPlan(context.Background(), userID, plantID)
+
+	assert.ErrorIs(t, err, ErrCarePlanNotFound)
+	assert.Nil(t, plan)
+}
@@ -0,0 +1,567 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCareTask is returned for malformed weeks and task IDs or tasks that are not scheduled
+var ErrInvalidCareTask = errors.New("invalid care task")
+
+// careTaskDateLayout is the date layout used in weeks and task IDs
+const careTaskDateLayout = "2006-01-02"
+
+// mistDays holds the weekdays (offsets from Monday) misting falls on for each humidity level
+var mistDays = map[models.HumidityLevel][]int{
+	models.HumidityLevelHigh:   {0, 2, 4},
+	models.HumidityLevelMedium: {2},
+}
+
+// careTaskPlantEvents holds the plant lifecycle events recorded when tasks are completed
+var careTaskPlantEvents = map[models.CareTaskType]models.PlantEventType{
+	models.CareTaskTypeWater:     models.PlantEventTypeWatered,
+	models.CareTaskTypeFertilize: models.PlantEventTypeFertilized,
+	models.CareTaskTypeRepot:     models.PlantEventTypeRepotted,
+}
+
+// rotateDay is the weekday (offset from Monday) the plant is rotated on
+const rotateDay = 6
+
+// mistFrequencies holds the default misting interval in days for each humidity level
+var mistFrequencies = map[models.HumidityLevel]int{
+	models.HumidityLevelHigh:   2,
+	models.HumidityLevelMedium: 7,
+}
+
+const (
+	// pruneFrequency is the default pruning interval in days
+	pruneFrequency = 90
+
+	// maxScheduleDays is the longest period a care schedule can be requested for
+	maxScheduleDays = 90
+)
+
+// userPlantCare holds a plant from the user's collection with everything its tasks are scheduled from
+type userPlantCare struct {
+	userPlant *models.UserPlant
+	plant     *models.Plant
+	plan      *models.CarePlan // nil if there is none
+	schedules []*models.UserPlantTask
+}
+
+// CareTaskService builds weekly care checklists from plant schedules and care plans and records completions
+type CareTaskService struct {
+	plantRepo         repository.PlantRepository
+	careTaskRepo      repository.CareTaskRepository
+	carePlanRepo      repository.CarePlanRepository
+	userPlantTaskRepo repository.UserPlantTaskRepository
+	recorder          PlantEventRecorder
+}
+
+// NewCareTaskService creates a new care task service
+func NewCareTaskService(
+	plantRepo repository.PlantRepository,
+	careTaskRepo repository.CareTaskRepository,
+	carePlanRepo repository.CarePlanRepository,
+	userPlantTaskRepo repository.UserPlantTaskRepository,
+) *CareTaskService {
+	return &CareTaskService{
+		plantRepo:         plantRepo,
+		careTaskRepo:      careTaskRepo,
+		carePlanRepo:      carePlanRepo,
+		userPlantTaskRepo: userPlantTaskRepo,
+	}
+}
+
+// SetPlantEventRecorder sets the recorder completed waterings, fertilizings and repottings are added to
+// the plants' event streams with
+func (s *CareTaskService) SetPlantEventRecorder(recorder PlantEventRecorder) {
+	s.recorder = recorder
+}
+
+// GetWeeklyTasks gets the care checklist of a user plant for the week containing the given
+// date (YYYY-MM-DD) or for an ISO week (YYYY-Www); an empty week means the current week
+func (s *CareTaskService) GetWeeklyTasks(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, week string) (*models.CareTaskChecklist, error) {
+	weekStart, err := parseWeekStart(week, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	care, err := s.getUserPlantCare(ctx, userID, plantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildChecklist(ctx, care, weekStart)
+}
+
+// GetSchedule gets the care tasks of a user plant due in the given number of days starting today
+// along with its recurring tasks
+func (s *CareTaskService) GetSchedule(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, days int) (*models.CareSchedule, error) {
+	if days <= 0 || days > maxScheduleDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidCareTask, maxScheduleDays)
+	}
+
+	care, err := s.getUserPlantCare(ctx, userID, plantID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := truncateToDay(time.Now())
+	to := from.AddDate(0, 0, days)
+	schedule := &models.CareSchedule{
+		PlantID:   plantID,
+		From:      from,
+		To:        to,
+		Schedules: care.schedules,
+		Tasks:     []*models.CareTask{},
+	}
+	for weekStart := startOfWeek(from); weekStart.Before(to); weekStart = weekStart.AddDate(0, 0, 7) {
+		checklist, err := s.buildChecklist(ctx, care, weekStart)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range checklist.Tasks {
+			if !task.DueDate.Before(from) && task.DueDate.Before(to) {
+				schedule.Tasks = append(schedule.Tasks, task)
+			}
+		}
+	}
+
+	return schedule, nil
+}
+
+// UpdateSchedule replaces the recurring tasks of a user plant. Tasks without a due date keep
+// their current one, or are first due a full interval from today when they are new.
+func (s *CareTaskService) UpdateSchedule(
+	ctx context.Context,
+	userID uuid.UUID,
+	plantID uuid.UUID,
+	req *models.UpdateCareScheduleRequest,
+) ([]*models.UserPlantTask, error) {
+	// Check if the user owns the plant
+	if _, err := s.plantRepo.GetUserPlant(ctx, userID, plantID); err != nil {
+		return nil, fmt.Errorf("failed to get user plant: %w", err)
+	}
+
+	current, err := s.userPlantTaskRepo.GetByUserPlant(ctx, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	currentDue := make(map[models.CareTaskType]time.Time, len(current))
+	for _, task := range current {
+		currentDue[task.Type] = task.NextDue
+	}
+
+	today := truncateToDay(time.Now())
+	tasks := make([]*models.UserPlantTask, 0, len(req.Tasks))
+	seen := make(map[models.CareTaskType]bool, len(req.Tasks))
+	for _, entry := range req.Tasks {
+		if seen[entry.Type] {
+			return nil, fmt.Errorf("%w: %s is scheduled more than once", ErrInvalidCareTask, entry.Type)
+		}
+		seen[entry.Type] = true
+
+		nextDue, ok := currentDue[entry.Type]
+		switch {
+		case entry.NextDue != nil:
+			nextDue = truncateToDay(*entry.NextDue)
+		case !ok:
+			nextDue = today.AddDate(0, 0, entry.FrequencyDays)
+		}
+		tasks = append(tasks, &models.UserPlantTask{
+			UserID:        userID,
+			PlantID:       plantID,
+			Type:          entry.Type,
+			FrequencyDays: entry.FrequencyDays,
+			NextDue:       nextDue,
+		})
+	}
+
+	if err := s.userPlantTaskRepo.Replace(ctx, userID, plantID, tasks); err != nil {
+		return nil, fmt.Errorf("failed to save schedule: %w", err)
+	}
+	return tasks, nil
+}
+
+// ScheduleDefaultTasks gives a plant just added to a collection the recurring tasks it has none of yet
+func (s *CareTaskService) ScheduleDefaultTasks(ctx context.Context, userPlant *models.UserPlant, plant *models.Plant) error {
+	tasks := defaultCareSchedules(userPlant, plant, truncateToDay(time.Now()))
+	if err := s.userPlantTaskRepo.AddMissing(ctx, tasks); err != nil {
+		return fmt.Errorf("failed to schedule default tasks: %w", err)
+	}
+	return nil
+}
+
+// CompleteTask records the completion of a task from a weekly checklist
+func (s *CareTaskService) CompleteTask(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, taskID string) (*models.CareTask, error) {
+	taskType, dueDate, err := parseCareTaskID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	care, err := s.getUserPlantCare(ctx, userID, plantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The task must be part of the plant's schedule
+	scheduled := false
+	for _, task := range scheduleCareTasks(care, startOfWeek(dueDate)) {
+		if task.ID == taskID {
+			scheduled = true
+			break
+		}
+	}
+	if !scheduled {
+		return nil, fmt.Errorf("%w: %s is not scheduled for this plant", ErrInvalidCareTask, taskID)
+	}
+
+	completion := &models.CareTaskCompletion{
+		UserID:   userID,
+		PlantID:  plantID,
+		TaskType: taskType,
+		DueDate:  dueDate,
+	}
+	err = s.careTaskRepo.CompleteTask(ctx, completion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete task: %w", err)
+	}
+	if eventType, ok := careTaskPlantEvents[taskType]; ok {
+		recordPlantEvent(ctx, s.recorder, userID, plantID, eventType, models.PlantEventPayload{
+			"taskId":  taskID,
+			"dueDate": dueDate.Format(careTaskDateLayout),
+		})
+	}
+
+	return &models.CareTask{
+		ID:          taskID,
+		Type:        taskType,
+		DueDate:     dueDate,
+		Completed:   true,
+		CompletedAt: &completion.CompletedAt,
+	}, nil
+}
+
+// GetAdherence gets the share of completed tasks due so far over the last weeks, including the current one
+func (s *CareTaskService) GetAdherence(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, weeks int) (*models.CareTaskStats, error) {
+	if weeks <= 0 {
+		return nil, fmt.Errorf("%w: weeks must be positive", ErrInvalidCareTask)
+	}
+
+	care, err := s.getUserPlantCare(ctx, userID, plantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	stats := &models.CareTaskStats{}
+	weekStart := startOfWeek(now).AddDate(0, 0, -7*(weeks-1))
+	for i := 0; i < weeks; i++ {
+		checklist, err := s.buildChecklist(ctx, care, weekStart.AddDate(0, 0, 7*i))
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range checklist.Tasks {
+			// Tasks that are not due yet do not count against adherence
+			if task.DueDate.After(now) && !task.Completed {
+				continue
+			}
+			stats.Total++
+			if task.Completed {
+				stats.Completed++
+			}
+		}
+	}
+	stats.Adherence = adherence(stats.Completed, stats.Total)
+
+	return stats, nil
+}
+
+// getUserPlantCare gets a plant from the user's collection with its care plan and recurring tasks
+func (s *CareTaskService) getUserPlantCare(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) (*userPlantCare, error) {
+	userPlant, err := s.plantRepo.GetUserPlant(ctx, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plant: %w", err)
+	}
+
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant: %w", err)
+	}
+
+	plan, err := s.carePlanRepo.GetByUserPlant(ctx, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get care plan: %w", err)
+	}
+
+	schedules, err := s.userPlantTaskRepo.GetByUserPlant(ctx, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+
+	return &userPlantCare{
+		userPlant: userPlant,
+		plant:     plant,
+		plan:      plan,
+		schedules: schedules,
+	}, nil
+}
+
+// buildChecklist schedules the tasks of a week and marks the completed ones
+func (s *CareTaskService) buildChecklist(ctx context.Context, care *userPlantCare, weekStart time.Time) (*models.CareTaskChecklist, error) {
+	userPlant := care.userPlant
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	tasks := scheduleCareTasks(care, weekStart)
+
+	completions, err := s.careTaskRepo.GetCompletions(ctx, userPlant.UserID, userPlant.PlantID, weekStart, weekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get completions: %w", err)
+	}
+	completed := make(map[string]*models.CareTaskCompletion, len(completions))
+	for _, completion := range completions {
+		completed[careTaskID(completion.TaskType, completion.DueDate)] = completion
+	}
+
+	checklist := &models.CareTaskChecklist{
+		PlantID:   userPlant.PlantID,
+		WeekStart: weekStart,
+		WeekEnd:   weekEnd,
+		Tasks:     tasks,
+	}
+	for _, task := range tasks {
+		if completion, ok := completed[task.ID]; ok {
+			task.Completed = true
+			task.CompletedAt = &completion.CompletedAt
+			checklist.Stats.Completed++
+		}
+	}
+	checklist.Stats.Total = len(tasks)
+	checklist.Stats.Adherence = adherence(checklist.Stats.Completed, checklist.Stats.Total)
+
+	return checklist, nil
+}
+
+// scheduleCareTasks generates the care tasks of a plant for the week starting at weekStart.
+// Watering follows the watering frequency anchored at the next watering date, fertilizing
+// follows the fertilizer frequency anchored at the day the plant was added, misting depends
+// on the humidity the plant needs, and the plant is rotated once a week. A recurring task of
+// the plant replaces the rule of its type and is the only source of pruning tasks. A care
+// plan, when given, stretches watering during dormancy, limits fertilizing to its season and
+// adds a repotting task at the start of each repotting window unless repotting is recurring.
+// Low effort mode waters as rarely as the plant tolerates unless dormancy stretches it further.
+func scheduleCareTasks(plantCare *userPlantCare, weekStart time.Time) []*models.CareTask {
+	userPlant, plan := plantCare.userPlant, plantCare.plan
+	care := plantCare.plant.CareInstructions
+	schedules := make(map[models.CareTaskType]*models.UserPlantTask, len(plantCare.schedules))
+	for _, schedule := range plantCare.schedules {
+		schedules[schedule.Type] = schedule
+	}
+	var tasks []*models.CareTask
+
+	// Watering
+	wateringAnchor := truncateToDay(userPlant.CreatedAt)
+	if userPlant.NextWatering != nil {
+		wateringAnchor = truncateToDay(*userPlant.NextWatering)
+	}
+	wateringFrequency := care.WateringFrequency
+	if month := carePlanMonth(plan, weekStart); month != nil {
+		wateringFrequency = month.WateringFrequency
+	}
+	if lowEffort, _ := userPlant.LowEffort(); lowEffort && care.LowEffortWateringFrequency() > wateringFrequency {
+		wateringFrequency = care.LowEffortWateringFrequency()
+	}
+	tasks = append(tasks, periodicCareTasks(models.CareTaskTypeWater, wateringFrequency, wateringAnchor, weekStart)...)
+
+	// Misting
+	if schedule, ok := schedules[models.CareTaskTypeMist]; ok {
+		tasks = append(tasks, periodicCareTasks(models.CareTaskTypeMist, schedule.FrequencyDays, truncateToDay(schedule.NextDue), weekStart)...)
+	} else {
+		for _, offset := range mistDays[care.Humidity] {
+			tasks = append(tasks, newCareTask(models.CareTaskTypeMist, weekStart.AddDate(0, 0, offset)))
+		}
+	}
+
+	// Fertilizing
+	fertilizerFrequency, fertilizerAnchor := care.FertilizerFrequency, truncateToDay(userPlant.CreatedAt)
+	if schedule, ok := schedules[models.CareTaskTypeFertilize]; ok {
+		fertilizerFrequency, fertilizerAnchor = schedule.FrequencyDays, truncateToDay(schedule.NextDue)
+	}
+	for _, task := range periodicCareTasks(models.CareTaskTypeFertilize, fertilizerFrequency, fertilizerAnchor, weekStart) {
+		if month := carePlanMonth(plan, task.DueDate); month != nil && !month.Fertilize {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	// Rotating
+	tasks = append(tasks, newCareTask(models.CareTaskTypeRotate, weekStart.AddDate(0, 0, rotateDay)))
+
+	// Pruning
+	if schedule, ok := schedules[models.CareTaskTypePrune]; ok {
+		tasks = append(tasks, periodicCareTasks(models.CareTaskTypePrune, schedule.FrequencyDays, truncateToDay(schedule.NextDue), weekStart)...)
+	}
+
+	// Repotting
+	if schedule, ok := schedules[models.CareTaskTypeRepot]; ok {
+		tasks = append(tasks, periodicCareTasks(models.CareTaskTypeRepot, schedule.FrequencyDays, truncateToDay(schedule.NextDue), weekStart)...)
+	} else {
+		for day := 0; day < 7; day++ {
+			date := weekStart.AddDate(0, 0, day)
+			if date.Day() != 1 {
+				continue
+			}
+			month := carePlanMonth(plan, date)
+			previous := carePlanMonth(plan, date.AddDate(0, -1, 0))
+			if month != nil && month.Repot && (previous == nil || !previous.Repot) {
+				tasks = append(tasks, newCareTask(models.CareTaskTypeRepot, date))
+			}
+		}
+	}
+
+	return tasks
+}
+
+// defaultCareSchedules returns the recurring tasks a plant gets when it is added to a collection:
+// fertilizing at the fertilizer frequency, misting when the plant needs humid air and pruning.
+// Repotting follows the care plan until the owner schedules it.
+func defaultCareSchedules(userPlant *models.UserPlant, plant *models.Plant, today time.Time) []*models.UserPlantTask {
+	care := plant.CareInstructions
+	frequencies := []struct {
+		taskType  models.CareTaskType
+		frequency int
+	}{
+		{models.CareTaskTypeFertilize, care.FertilizerFrequency},
+		{models.CareTaskTypeMist, mistFrequencies[care.Humidity]},
+		{models.CareTaskTypePrune, pruneFrequency},
+	}
+
+	var tasks []*models.UserPlantTask
+	for _, f := range frequencies {
+		if f.frequency <= 0 {
+			continue
+		}
+		tasks = append(tasks, &models.UserPlantTask{
+			UserID:        userPlant.UserID,
+			PlantID:       userPlant.PlantID,
+			Type:          f.taskType,
+			FrequencyDays: f.frequency,
+			NextDue:       today.AddDate(0, 0, f.frequency),
+		})
+	}
+	return tasks
+}
+
+// nextCareTaskDue returns the first date after today in the series of a recurring task due on dueDate
+func nextCareTaskDue(dueDate time.Time, frequency int, today time.Time) time.Time {
+	dueDate = truncateToDay(dueDate)
+	if dueDate.After(today) {
+		return dueDate
+	}
+	missed := int(today.Sub(dueDate).Hours()/24)/frequency + 1
+	return dueDate.AddDate(0, 0, missed*frequency)
+}
+
+// periodicCareTasks generates tasks every frequency days counted from anchor that fall in the week
+func periodicCareTasks(taskType models.CareTaskType, frequency int, anchor time.Time, weekStart time.Time) []*models.CareTask {
+	if frequency <= 0 {
+		return nil
+	}
+
+	var tasks []*models.CareTask
+	for day := 0; day < 7; day++ {
+		date := weekStart.AddDate(0, 0, day)
+		diff := int(date.Sub(anchor).Hours() / 24)
+		if ((diff%frequency)+frequency)%frequency == 0 {
+			tasks = append(tasks, newCareTask(taskType, date))
+		}
+	}
+	return tasks
+}
+
+// newCareTask creates a care task due on a date
+func newCareTask(taskType models.CareTaskType, dueDate time.Time) *models.CareTask {
+	return &models.CareTask{
+		ID:      careTaskID(taskType, dueDate),
+		Type:    taskType,
+		DueDate: dueDate,
+	}
+}
+
+// careTaskID builds the ID of a care task, e.g. water-2024-05-14
+func careTaskID(taskType models.CareTaskType, dueDate time.Time) string {
+	return strings.ToLower(string(taskType)) + "-" + dueDate.UTC().Format(careTaskDateLayout)
+}
+
+// parseCareTaskID parses a care task ID built by careTaskID
+func parseCareTaskID(taskID string) (models.CareTaskType, time.Time, error) {
+	kind, date, found := strings.Cut(taskID, "-")
+	if !found {
+		return "", time.Time{}, fmt.Errorf("%w: malformed task ID %s", ErrInvalidCareTask, taskID)
+	}
+
+	taskType := models.CareTaskType(strings.ToUpper(kind))
+	switch taskType {
+	case models.CareTaskTypeWater, models.CareTaskTypeMist, models.CareTaskTypeFertilize, models.CareTaskTypeRotate,
+		models.CareTaskTypeRepot, models.CareTaskTypePrune:
+	default:
+		return "", time.Time{}, fmt.Errorf("%w: unknown task type %s", ErrInvalidCareTask, kind)
+	}
+
+	dueDate, err := time.Parse(careTaskDateLayout, date)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: malformed task date %s", ErrInvalidCareTask, date)
+	}
+
+	return taskType, dueDate, nil
+}
+
+// parseWeekStart returns the Monday of the week given as a date (YYYY-MM-DD) or an ISO week (YYYY-Www)
+func parseWeekStart(week string, now time.Time) (time.Time, error) {
+	if week == "" {
+		return startOfWeek(now), nil
+	}
+
+	var year, number int
+	if _, err := fmt.Sscanf(week, "%4d-W%2d", &year, &number); err == nil {
+		if number < 1 || number > 53 {
+			return time.Time{}, fmt.Errorf("%w: week number %d out of range", ErrInvalidCareTask, number)
+		}
+		// January 4th is always in ISO week 1
+		return startOfWeek(time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)).AddDate(0, 0, 7*(number-1)), nil
+	}
+
+	date, err := time.Parse(careTaskDateLayout, week)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: week %q must be YYYY-MM-DD or YYYY-Www", ErrInvalidCareTask, week)
+	}
+	return startOfWeek(date), nil
+}
+
+// startOfWeek returns the Monday of the week containing t, at midnight UTC
+func startOfWeek(t time.Time) time.Time {
+	day := truncateToDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// truncateToDay returns midnight UTC of the day of t
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// adherence returns completed / total, or 1 when nothing was due
+func adherence(completed int, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+	return float64(completed) / float64(total)
+}
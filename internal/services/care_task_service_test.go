@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCareTaskRepository is a mock implementation of the CareTaskRepository interface
+type MockCareTaskRepository struct {
+	mock.Mock
+}
+
+func (m *MockCareTaskRepository) CompleteTask(ctx context.Context, completion *models.CareTaskCompletion) error {
+	args := m.Called(ctx, completion)
+	return args.Error(0)
+}
+
+func (m *MockCareTaskRepository) GetCompletions(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, from time.Time, to time.Time) ([]*models.CareTaskCompletion, error) {
+	args := m.Called(ctx, userID, plantID, from, to)
+	return args.Get(0).([]*models.CareTaskCompletion), args.Error(1)
+}
+
+// MockUserPlantTaskRepository is a mock implementation of the UserPlantTaskRepository interface
+type MockUserPlantTaskRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserPlantTaskRepository) GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.UserPlantTask, error) {
+	args := m.Called(ctx, userID, plantID)
+	return args.Get(0).([]*models.UserPlantTask), args.Error(1)
+}
+
+func (m *MockUserPlantTaskRepository) AddMissing(ctx context.Context, tasks []*models.UserPlantTask) error {
+	args := m.Called(ctx, tasks)
+	return args.Error(0)
+}
+
+func (m *MockUserPlantTaskRepository) Replace(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, tasks []*models.UserPlantTask) error {
+	args := m.Called(ctx, userID, plantID, tasks)
+	return args.Error(0)
+}
+
+func (m *MockUserPlantTaskRepository) GetDue(ctx context.Context, until time.Time) ([]*models.UserPlantTask, error) {
+	args := m.Called(ctx, until)
+	return args.Get(0).([]*models.UserPlantTask), args.Error(1)
+}
+
+func (m *MockUserPlantTaskRepository) SetNextDue(ctx context.Context, taskID uuid.UUID, nextDue time.Time) error {
+	args := m.Called(ctx, taskID, nextDue)
+	return args.Error(0)
+}
+
+// careTaskFixture returns a user plant watered every 4 days with high humidity needs
+func careTaskFixture() (*models.UserPlant, *models.Plant) {
+	nextWatering := time.Date(2024, time.May, 14, 9, 0, 0, 0, time.UTC)
+	plant := &models.Plant{
+		ID:   uuid.New(),
+		Name: "Calathea",
+		CareInstructions: models.CareInstructions{
+			WateringFrequency:   4,
+			Humidity:            models.HumidityLevelHigh,
+			FertilizerFrequency: 30,
+		},
+	}
+	userPlant := &models.UserPlant{
+		ID:           uuid.New(),
+		UserID:       uuid.New(),
+		PlantID:      plant.ID,
+		NextWatering: &nextWatering,
+		CreatedAt:    time.Date(2024, time.April, 1, 12, 0, 0, 0, time.UTC),
+	}
+	return userPlant, plant
+}
+
+// TestCareTaskService_GetWeeklyTasks tests the weekly checklist and its completion stats
+func TestCareTaskService_GetWeeklyTasks(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockCareTaskRepo := new(MockCareTaskRepository)
+	mockCarePlanRepo := new(MockCarePlanRepository)
+	mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+	service := NewCareTaskService(mockPlantRepo, mockCareTaskRepo, mockCarePlanRepo, mockUserPlantTaskRepo)
+
+	userPlant, plant := careTaskFixture()
+	weekStart := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)
+	completions := []*models.CareTaskCompletion{
+		{TaskType: models.CareTaskTypeWater, DueDate: time.Date(2024, time.May, 14, 0, 0, 0, 0, time.UTC), CompletedAt: time.Now()},
+	}
+
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(userPlant, nil)
+	mockPlantRepo.On("GetByID", mock.Anything, plant.ID).Return(plant, nil)
+	mockCarePlanRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(nil, nil)
+	mockUserPlantTaskRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return([]*models.UserPlantTask{}, nil)
+	mockCareTaskRepo.On("GetCompletions", mock.Anything, userPlant.UserID, plant.ID, weekStart, weekStart.AddDate(0, 0, 7)).Return(completions, nil)
+
+	checklist, err := service.GetWeeklyTasks(context.Background(), userPlant.UserID, plant.ID, "2024-W20")
+	assert.NoError(t, err)
+
+	counts := map[models.CareTaskType]int{}
+	for _, task := range checklist.Tasks {
+		counts[task.Type]++
+	}
+	assert.Equal(t, 2, counts[models.CareTaskTypeWater])
+	assert.Equal(t, 3, counts[models.CareTaskTypeMist])
+	assert.Equal(t, 0, counts[models.CareTaskTypeFertilize])
+	assert.Equal(t, 1, counts[models.CareTaskTypeRotate])
+
+	assert.Equal(t, "water-2024-05-14", checklist.Tasks[0].ID)
+	assert.True(t, checklist.Tasks[0].Completed)
+	assert.Equal(t, 6, checklist.Stats.Total)
+	assert.Equal(t, 1, checklist.Stats.Completed)
+	assert.InDelta(t, 1.0/6.0, checklist.Stats.Adherence, 0.0001)
+}
+
+// TestCareTaskService_CompleteTask_NotScheduled tests that unscheduled tasks cannot be completed
+func TestCareTaskService_CompleteTask_NotScheduled(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockCareTaskRepo := new(MockCareTaskRepository)
+	mockCarePlanRepo := new(MockCarePlanRepository)
+	mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+	service := NewCareTaskService(mockPlantRepo, mockCareTaskRepo, mockCarePlanRepo, mockUserPlantTaskRepo)
+
+	userPlant, plant := careTaskFixture()
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(userPlant, nil)
+	mockPlantRepo.On("GetByID", mock.Anything, plant.ID).Return(plant, nil)
+	mockCarePlanRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(nil, nil)
+	mockUserPlantTaskRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return([]*models.UserPlantTask{}, nil)
+
+	task, err := service.CompleteTask(context.Background(), userPlant.UserID, plant.ID, "water-2024-05-15")
+
+	assert.ErrorIs(t, err, ErrInvalidCareTask)
+	assert.Nil(t, task)
+	mockCareTaskRepo.AssertNotCalled(t, "CompleteTask", mock.Anything, mock.Anything)
+}
+
+// TestCareTaskService_GetWeeklyTasks_FollowsCarePlan tests that a care plan stretches watering,
+// pauses fertilizing and schedules repotting
+func TestCareTaskService_GetWeeklyTasks_FollowsCarePlan(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockCareTaskRepo := new(MockCareTaskRepository)
+	mockCarePlanRepo := new(MockCarePlanRepository)
+	mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+	service := NewCareTaskService(mockPlantRepo, mockCareTaskRepo, mockCarePlanRepo, mockUserPlantTaskRepo)
+
+	userPlant, plant := careTaskFixture()
+	plant.CareInstructions.FertilizerFrequency = 1
+	plan := &models.CarePlan{
+		Months: []*models.CarePlanMonth{
+			{Month: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), Dormant: true, WateringFrequency: 7},
+			{Month: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), Repot: true, WateringFrequency: 7},
+		},
+	}
+	weekStart := time.Date(2024, time.May, 27, 0, 0, 0, 0, time.UTC)
+
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(userPlant, nil)
+	mockPlantRepo.On("GetByID", mock.Anything, plant.ID).Return(plant, nil)
+	mockCarePlanRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(plan, nil)
+	mockUserPlantTaskRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return([]*models.UserPlantTask{}, nil)
+	mockCareTaskRepo.On("GetCompletions", mock.Anything, userPlant.UserID, plant.ID, weekStart, weekStart.AddDate(0, 0, 7)).Return([]*models.CareTaskCompletion{}, nil)
+
+	checklist, err := service.GetWeeklyTasks(context.Background(), userPlant.UserID, plant.ID, "2024-05-27")
+	assert.NoError(t, err)
+
+	counts := map[models.CareTaskType]int{}
+	for _, task := range checklist.Tasks {
+		counts[task.Type]++
+	}
+	assert.Equal(t, 1, counts[models.CareTaskTypeWater])
+	assert.Equal(t, 0, counts[models.CareTaskTypeFertilize])
+	assert.Equal(t, 1, counts[models.CareTaskTypeRepot])
+	assert.Equal(t, "repot-2024-06-01", checklist.Tasks[len(checklist.Tasks)-1].ID)
+}
+
+// TestCareTaskService_GetWeeklyTasks_LowEffort tests that low effort mode waters as rarely as the plant tolerates
+func TestCareTaskService_GetWeeklyTasks_LowEffort(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockCareTaskRepo := new(MockCareTaskRepository)
+	mockCarePlanRepo := new(MockCarePlanRepository)
+	mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+	service := NewCareTaskService(mockPlantRepo, mockCareTaskRepo, mockCarePlanRepo, mockUserPlantTaskRepo)
+
+	userPlant, plant := careTaskFixture()
+	userPlant.UserLowEffortMode = true
+	maxWatering := 7
+	plant.CareInstructions.WateringFrequencyMax = &maxWatering
+	weekStart := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)
+
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(userPlant, nil)
+	mockPlantRepo.On("GetByID", mock.Anything, plant.ID).Return(plant, nil)
+	mockCarePlanRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(nil, nil)
+	mockUserPlantTaskRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return([]*models.UserPlantTask{}, nil)
+	mockCareTaskRepo.On("GetCompletions", mock.Anything, userPlant.UserID, plant.ID, weekStart, weekStart.AddDate(0, 0, 7)).Return([]*models.CareTaskCompletion{}, nil)
+
+	checklist, err := service.GetWeeklyTasks(context.Background(), userPlant.UserID, plant.ID, "2024-W20")
+	assert.NoError(t, err)
+
+	var watering []string
+	for _, task := range checklist.Tasks {
+		if task.Type == models.CareTaskTypeWater {
+			watering = append(watering, task.ID)
+		}
+	}
+	// Every 7 days instead of every 4: the 18th is skipped
+	assert.Equal(t, []string{"water-2024-05-14"}, watering)
+}
+
+// TestCareTaskService_GetWeeklyTasks_FollowsSchedules tests that recurring tasks replace the built-in rules
+func TestCareTaskService_GetWeeklyTasks_FollowsSchedules(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockCareTaskRepo := new(MockCareTaskRepository)
+	mockCarePlanRepo := new(MockCarePlanRepository)
+	mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+	service := NewCareTaskService(mockPlantRepo, mockCareTaskRepo, mockCarePlanRepo, mockUserPlantTaskRepo)
+
+	userPlant, plant := careTaskFixture()
+	weekStart := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)
+	schedules := []*models.UserPlantTask{
+		{Type: models.CareTaskTypeMist, FrequencyDays: 7, NextDue: time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC)},
+		{Type: models.CareTaskTypePrune, FrequencyDays: 90, NextDue: time.Date(2024, time.August, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(userPlant, nil)
+	mockPlantRepo.On("GetByID", mock.Anything, plant.ID).Return(plant, nil)
+	mockCarePlanRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(nil, nil)
+	mockUserPlantTaskRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(schedules, nil)
+	mockCareTaskRepo.On("GetCompletions", mock.Anything, userPlant.UserID, plant.ID, weekStart, weekStart.AddDate(0, 0, 7)).Return([]*models.CareTaskCompletion{}, nil)
+
+	checklist, err := service.GetWeeklyTasks(context.Background(), userPlant.UserID, plant.ID, "2024-W20")
+	assert.NoError(t, err)
+
+	ids := map[string]bool{}
+	for _, task := range checklist.Tasks {
+		ids[task.ID] = true
+	}
+	assert.True(t, ids["mist-2024-05-15"])
+	assert.False(t, ids["mist-2024-05-13"])
+	// Pruning falls in the week 90 days before its next due date
+	assert.True(t, ids["prune-2024-05-17"])
+}
+
+// TestCareTaskService_UpdateSchedule tests that tasks keep their due dates unless new ones are given
+func TestCareTaskService_UpdateSchedule(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+	service := NewCareTaskService(mockPlantRepo, new(MockCareTaskRepository), new(MockCarePlanRepository), mockUserPlantTaskRepo)
+
+	userPlant, plant := careTaskFixture()
+	mistDue := time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC)
+	repotDue := time.Date(2025, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return(userPlant, nil)
+	mockUserPlantTaskRepo.On("GetByUserPlant", mock.Anything, userPlant.UserID, plant.ID).Return([]*models.UserPlantTask{
+		{Type: models.CareTaskTypeMist, FrequencyDays: 2, NextDue: mistDue},
+	}, nil)
+	mockUserPlantTaskRepo.On("Replace", mock.Anything, userPlant.UserID, plant.ID, mock.Anything).Return(nil)
+
+	tasks, err := service.UpdateSchedule(context.Background(), userPlant.UserID, plant.ID, &models.UpdateCareScheduleRequest{
+		Tasks: []models.CareScheduleEntry{
+			{Type: models.CareTaskTypeMist, FrequencyDays: 3},
+			{Type: models.CareTaskTypeRepot, FrequencyDays: 365, NextDue: &repotDue},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+	assert.Equal(t, mistDue, tasks[0].NextDue)
+	assert.Equal(t, 3, tasks[0].FrequencyDays)
+	assert.Equal(t, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), tasks[1].NextDue)
+
+	// A task type can only be scheduled once
+	_, err = service.UpdateSchedule(context.Background(), userPlant.UserID, plant.ID, &models.UpdateCareScheduleRequest{
+		Tasks: []models.CareScheduleEntry{
+			{Type: models.CareTaskTypePrune, FrequencyDays: 30},
+			{Type: models.CareTaskTypePrune, FrequencyDays: 60},
+		},
+	})
+	assert.ErrorIs(t, err, ErrInvalidCareTask)
+	mockUserPlantTaskRepo.AssertNumberOfCalls(t, "Replace", 1)
+}
+
+// TestDefaultCareSchedules tests the recurring tasks of a plant added to a collection
+func TestDefaultCareSchedules(t *testing.T) {
+	userPlant, plant := careTaskFixture()
+	today := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)
+
+	tasks := defaultCareSchedules(userPlant, plant, today)
+
+	frequencies := map[models.CareTaskType]int{}
+	for _, task := range tasks {
+		frequencies[task.Type] = task.FrequencyDays
+		assert.Equal(t, today.AddDate(0, 0, task.FrequencyDays), task.NextDue)
+	}
+	assert.Equal(t, map[models.CareTaskType]int{
+		models.CareTaskTypeFertilize: 30,
+		models.CareTaskTypeMist:      2,
+		models.CareTaskTypePrune:     pruneFrequency,
+	}, frequencies)
+}
+
+// TestNextCareTaskDue tests that missed occurrences are skipped
+func TestNextCareTaskDue(t *testing.T) {
+	today := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC), nextCareTaskDue(today, 7, today))
+	assert.Equal(t, time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC), nextCareTaskDue(time.Date(2024, time.May, 9, 0, 0, 0, 0, time.UTC), 3, today))
+	assert.Equal(t, time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC), nextCareTaskDue(time.Date(2024, time.May, 20, 0, 0, 0, 0, time.UTC), 7, today))
+}
+
+// TestParseWeekStart tests parsing of dates and ISO weeks
+func TestParseWeekStart(t *testing.T) {
+	monday := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)
+
+	start, err := parseWeekStart("2024-05-16", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, monday, start)
+
+	start, err = parseWeekStart("2024-W20", time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, monday, start)
+
+	start, err = parseWeekStart("", time.Date(2024, time.May, 19, 23, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, monday, start)
+
+	_, err = parseWeekStart("next week", time.Now())
+	assert.ErrorIs(t, err, ErrInvalidCareTask)
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidChangelogEntry is returned for changelog entries without a title and body in the default
+// language or with a blank version
+var ErrInvalidChangelogEntry = errors.New("invalid changelog entry")
+
+// ChangelogService manages the release notes shown in the what's-new sheet of the app. Each user
+// marks the entries the app showed as seen, so the sheet shows them once.
+type ChangelogService struct {
+	changelogRepo repository.ChangelogRepository
+	now           func() time.Time
+}
+
+// NewChangelogService creates a new changelog service
+func NewChangelogService(changelogRepo repository.ChangelogRepository) *ChangelogService {
+	return &ChangelogService{
+		changelogRepo: changelogRepo,
+		now:           time.Now,
+	}
+}
+
+// CreateEntry creates a changelog entry of an admin, published now unless a time is given
+func (s *ChangelogService) CreateEntry(ctx context.Context, adminID uuid.UUID, req *models.SaveChangelogEntryRequest) (*models.ChangelogEntry, error) {
+	entry, err := s.changelogEntry(req)
+	if err != nil {
+		return nil, err
+	}
+	entry.CreatedBy = &adminID
+
+	if err := s.changelogRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// UpdateEntry updates a changelog entry, published now unless a time is given. Users who saw the
+// entry are not shown it again.
+func (s *ChangelogService) UpdateEntry(ctx context.Context, id uuid.UUID, req *models.SaveChangelogEntryRequest) (*models.ChangelogEntry, error) {
+	entry, err := s.changelogEntry(req)
+	if err != nil {
+		return nil, err
+	}
+	entry.ID = id
+
+	if err := s.changelogRepo.Update(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// DeleteEntry deletes a changelog entry
+func (s *ChangelogService) DeleteEntry(ctx context.Context, id uuid.UUID) error {
+	return s.changelogRepo.Delete(ctx, id)
+}
+
+// GetEntry gets a changelog entry
+func (s *ChangelogService) GetEntry(ctx context.Context, id uuid.UUID) (*models.ChangelogEntry, error) {
+	return s.changelogRepo.GetByID(ctx, id)
+}
+
+// ListEntries lists every changelog entry, scheduled ones included, the latest published first
+func (s *ChangelogService) ListEntries(ctx context.Context) ([]*models.ChangelogEntry, error) {
+	return s.changelogRepo.List(ctx)
+}
+
+// GetChangelog gets the changelog entries published for a user in their language, newest first,
+// only the ones the user has not seen when unseenOnly is set
+func (s *ChangelogService) GetChangelog(ctx context.Context, userID uuid.UUID, language models.Language, unseenOnly bool) (*models.Changelog, error) {
+	entries, err := s.changelogRepo.ListPublished(ctx, userID, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	changelog := &models.Changelog{Entries: []*models.ChangelogItem{}}
+	for _, entry := range entries {
+		if !entry.Seen {
+			changelog.Unseen++
+		} else if unseenOnly {
+			continue
+		}
+		changelog.Entries = append(changelog.Entries, &models.ChangelogItem{
+			ID:          entry.ID,
+			Version:     entry.Version,
+			Title:       changelogText(entry.Titles, language),
+			Body:        changelogText(entry.Bodies, language),
+			PublishedAt: entry.PublishedAt,
+			Seen:        entry.Seen,
+		})
+	}
+	return changelog, nil
+}
+
+// MarkSeen marks changelog entries shown to a user as seen and returns the number of entries the
+// user had not seen yet. Unknown and scheduled entries are skipped.
+func (s *ChangelogService) MarkSeen(ctx context.Context, userID uuid.UUID, entryIDs []uuid.UUID) (int, error) {
+	return s.changelogRepo.MarkSeen(ctx, userID, entryIDs, s.now())
+}
+
+// changelogEntry builds the changelog entry a request saves, with its texts trimmed
+func (s *ChangelogService) changelogEntry(req *models.SaveChangelogEntryRequest) (*models.ChangelogEntry, error) {
+	entry := &models.ChangelogEntry{
+		Version:     strings.TrimSpace(req.Version),
+		Titles:      trimChangelogTexts(req.Titles),
+		Bodies:      trimChangelogTexts(req.Bodies),
+		PublishedAt: s.now(),
+	}
+	if entry.Version == "" {
+		return nil, fmt.Errorf("%w: version must not be blank", ErrInvalidChangelogEntry)
+	}
+	if entry.Titles[models.LanguageRussian] == "" || entry.Bodies[models.LanguageRussian] == "" {
+		return nil, fmt.Errorf("%w: a title and body in RUSSIAN are required, users of other languages without them get them", ErrInvalidChangelogEntry)
+	}
+	if req.PublishedAt != nil {
+		entry.PublishedAt = *req.PublishedAt
+	}
+	return entry, nil
+}
+
+// trimChangelogTexts trims the texts of a changelog entry, dropping blank ones
+func trimChangelogTexts(texts models.ChangelogTexts) models.ChangelogTexts {
+	trimmed := make(models.ChangelogTexts, len(texts))
+	for language, text := range texts {
+		if text = strings.TrimSpace(text); text != "" {
+			trimmed[language] = text
+		}
+	}
+	return trimmed
+}
+
+// changelogText returns a text of a changelog entry in a language, or in Russian when it has none in
+// that language
+func changelogText(texts models.ChangelogTexts, language models.Language) string {
+	if text, ok := texts[language]; ok {
+		return text
+	}
+	return texts[models.LanguageRussian]
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockChangelogRepository is a mock implementation of the ChangelogRepository interface
+type MockChangelogRepository struct {
+	mock.Mock
+}
+
+func (m *MockChangelogRepository) Create(ctx context.Context, entry *models.ChangelogEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockChangelogRepository) Update(ctx context.Context, entry *models.ChangelogEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockChangelogRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockChangelogRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ChangelogEntry, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChangelogEntry), args.Error(1)
+}
+
+func (m *MockChangelogRepository) List(ctx context.Context) ([]*models.ChangelogEntry, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.ChangelogEntry), args.Error(1)
+}
+
+func (m *MockChangelogRepository) ListPublished(ctx context.Context, userID uuid.UUID, until time.Time) ([]*models.ChangelogEntry, error) {
+	args := m.Called(ctx, userID, until)
+	return args.Get(0).([]*models.ChangelogEntry), args.Error(1)
+}
+
+func (m *MockChangelogRepository) MarkSeen(ctx context.Context, userID uuid.UUID, entryIDs []uuid.UUID, until time.Time) (int, error) {
+	args := m.Called(ctx, userID, entryIDs, until)
+	return args.Int(0), args.Error(1)
+}
+
+// TestChangelogService_CreateEntry tests that texts are trimmed, that Russian texts are required and
+// that entries are published now by default
+func TestChangelogService_CreateEntry(t *testing.T) {
+	mockChangelogRepo := new(MockChangelogRepository)
+	service := NewChangelogService(mockChangelogRepo)
+	now := time.Date(2024, time.May, 13, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	adminID := uuid.New()
+	mockChangelogRepo.On("Create", mock.Anything, mock.MatchedBy(func(entry *models.ChangelogEntry) bool {
+		return entry.Version == "2.4.0" && entry.PublishedAt.Equal(now) && *entry.CreatedBy == adminID &&
+			entry.Titles[models.LanguageRussian] == "Квиз" && len(entry.Bodies) == 1
+	})).Return(nil).Once()
+
+	_, err := service.CreateEntry(context.Background(), adminID, &models.SaveChangelogEntryRequest{
+		Version: " 2.4.0 ",
+		Titles:  models.ChangelogTexts{models.LanguageRussian: " Квиз ", models.LanguageEnglish: "Quiz"},
+		Bodies:  models.ChangelogTexts{models.LanguageRussian: "Ежедневный квиз", models.LanguageEnglish: "  "},
+	})
+	require.NoError(t, err)
+
+	_, err = service.CreateEntry(context.Background(), adminID, &models.SaveChangelogEntryRequest{
+		Version: "2.4.0",
+		Titles:  models.ChangelogTexts{models.LanguageEnglish: "Quiz"},
+		Bodies:  models.ChangelogTexts{models.LanguageEnglish: "A daily quiz"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidChangelogEntry)
+	mockChangelogRepo.AssertExpectations(t)
+}
+
+// TestChangelogService_GetChangelog tests that entries are localized with a fallback to Russian and
+// that seen entries are left out when only unseen ones are asked for
+func TestChangelogService_GetChangelog(t *testing.T) {
+	mockChangelogRepo := new(MockChangelogRepository)
+	service := NewChangelogService(mockChangelogRepo)
+	now := time.Date(2024, time.May, 13, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	userID := uuid.New()
+	newEntry := &models.ChangelogEntry{
+		ID:      uuid.New(),
+		Version: "2.4.0",
+		Titles:  models.ChangelogTexts{models.LanguageRussian: "Квиз", models.LanguageEnglish: "Quiz"},
+		Bodies:  models.ChangelogTexts{models.LanguageRussian: "Ежедневный квиз"},
+	}
+	oldEntry := &models.ChangelogEntry{
+		ID:      uuid.New(),
+		Version: "2.3.0",
+		Titles:  models.ChangelogTexts{models.LanguageRussian: "Фото"},
+		Bodies:  models.ChangelogTexts{models.LanguageRussian: "Фото в чате"},
+		Seen:    true,
+	}
+	mockChangelogRepo.On("ListPublished", mock.Anything, userID, now).Return([]*models.ChangelogEntry{newEntry, oldEntry}, nil)
+
+	changelog, err := service.GetChangelog(context.Background(), userID, models.LanguageEnglish, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changelog.Unseen)
+	require.Len(t, changelog.Entries, 2)
+	assert.Equal(t, "Quiz", changelog.Entries[0].Title)
+	assert.Equal(t, "Ежедневный квиз", changelog.Entries[0].Body)
+	assert.True(t, changelog.Entries[1].Seen)
+
+	changelog, err = service.GetChangelog(context.Background(), userID, models.LanguageEnglish, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changelog.Unseen)
+	require.Len(t, changelog.Entries, 1)
+	assert.Equal(t, newEntry.ID, changelog.Entries[0].ID)
+}
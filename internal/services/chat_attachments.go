@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/storage"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrChatAttachmentsUnavailable is returned when no object store is configured for chat photos
+	ErrChatAttachmentsUnavailable = errors.New("chat photo attachments are not available")
+
+	// ErrUnsupportedChatImage is returned when a photo attached to a chat message is not a JPEG or PNG image
+	ErrUnsupportedChatImage = errors.New("photo must be a JPEG or PNG image")
+)
+
+// chatImageExtensions maps the content types of photos the vision provider accepts to the
+// extension of their keys
+var chatImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// chatImageNotes are the notes passed to Yandex GPT in place of an attached photo, which it cannot
+// see: the conditions the vision provider found, that it found none, or that the photo was not analyzed
+var chatImageNotes = map[models.Language]struct {
+	findings   string
+	noFindings string
+	unanalyzed string
+}{
+	models.LanguageRussian: {
+		findings:   "[К сообщению приложено фото растения. Состояния, распознанные на фото, с уверенностью: %s. Учитывай их в ответе.]",
+		noFindings: "[К сообщению приложено фото растения. Признаков болезней или вредителей на фото не найдено.]",
+		unanalyzed: "[К сообщению приложено фото растения, но его не удалось проанализировать. Попроси описать, что видно на фото.]",
+	},
+	models.LanguageEnglish: {
+		findings:   "[A photo of the plant is attached to the message. Conditions recognized on the photo, with confidence: %s. Take them into account in the answer.]",
+		noFindings: "[A photo of the plant is attached to the message. No signs of diseases or pests were found on the photo.]",
+		unanalyzed: "[A photo of the plant is attached to the message, but it could not be analyzed. Ask the user to describe what the photo shows.]",
+	},
+}
+
+// SetChatAttachments sets the store photos attached to chat messages are kept in and the vision
+// provider describing them to Yandex GPT. Without a store photos are rejected; without a provider
+// they are kept but the assistant is told it cannot see them.
+func (s *RecommendationService) SetChatAttachments(objects storage.ObjectStore, provider DiagnosisProvider) {
+	s.chatObjects = objects
+	s.chatImageProvider = provider
+}
+
+// chatImageContentType checks that a photo attached to a chat message can be stored and returns
+// its content type
+func (s *RecommendationService) chatImageContentType(image []byte) (string, error) {
+	if s.chatObjects == nil {
+		return "", ErrChatAttachmentsUnavailable
+	}
+	contentType := http.DetectContentType(image)
+	if _, ok := chatImageExtensions[contentType]; !ok {
+		return "", ErrUnsupportedChatImage
+	}
+	return contentType, nil
+}
+
+// describeChatImage runs the vision provider over a photo attached to a chat message and returns
+// the note passed to Yandex GPT in its place. A failing provider is logged and described as such,
+// so the message is still answered.
+func (s *RecommendationService) describeChatImage(ctx context.Context, image []byte, contentType string, language models.Language) string {
+	notes, ok := chatImageNotes[language]
+	if !ok {
+		notes = chatImageNotes[models.LanguageRussian]
+	}
+	if s.chatImageProvider == nil {
+		return notes.unanalyzed
+	}
+
+	findings, err := s.chatImageProvider.Diagnose(ctx, image, contentType)
+	if err != nil {
+		log.Printf("Failed to describe chat photo with %s: %v", s.chatImageProvider.Name(), err)
+		return notes.unanalyzed
+	}
+	findings = topDiagnosisFindings(findings)
+	if len(findings) == 0 {
+		return notes.noFindings
+	}
+
+	conditions := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		conditions = append(conditions, fmt.Sprintf("%s %d%%", finding.Condition, int(math.Round(finding.Confidence*100))))
+	}
+	return fmt.Sprintf(notes.findings, strings.Join(conditions, ", "))
+}
+
+// storeChatImage stores a photo attached to a user message and sets its key on the message
+func (s *RecommendationService) storeChatImage(ctx context.Context, message *models.ChatMessage, image []byte, contentType string) error {
+	key := models.AssetKey(fmt.Sprintf("chat/%s/%s/%s%s", message.UserID, message.SessionID, uuid.New(), chatImageExtensions[contentType]))
+	if err := s.chatObjects.Put(ctx, string(key), image, contentType); err != nil {
+		return fmt.Errorf("failed to store chat photo: %w", err)
+	}
+	message.ImageURL = &key
+	return nil
+}
+
+// deleteStoredChatImage removes the stored photo of a chat message that could not be saved
+func (s *RecommendationService) deleteStoredChatImage(ctx context.Context, message *models.ChatMessage) {
+	if message.ImageURL == nil {
+		return
+	}
+	if err := s.chatObjects.Delete(ctx, string(*message.ImageURL)); err != nil {
+		log.Printf("Error deleting stored chat photo %s: %v", *message.ImageURL, err)
+	}
+}
+
+// chatMessageText returns the text of a chat message as passed to Yandex GPT, followed by the note
+// describing its photo
+func chatMessageText(message *models.ChatMessage) string {
+	switch {
+	case message.ImageDescription == "":
+		return message.Content
+	case message.Content == "":
+		return message.ImageDescription
+	default:
+		return message.Content + "\n\n" + message.ImageDescription
+	}
+}
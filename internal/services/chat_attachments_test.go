@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecommendationService_SendChatMessageWithImage tests that an attached photo is described to
+// Yandex GPT along with the message, stored under a key of the session and saved with the message
+func TestRecommendationService_SendChatMessageWithImage(t *testing.T) {
+	var requests []YandexGPTRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request YandexGPTRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+		w.Write([]byte(`{"result":{"alternatives":[{"message":{"role":"assistant","text":"Looks like spider mites."}}],"usage":{"inputTextTokens":"10","completionTokens":"5","totalTokens":"15"}}}`))
+	}))
+	defer server.Close()
+
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	mockProvider := new(MockDiagnosisProvider)
+	objects := memoryObjectStore{}
+	service := NewRecommendationService(mockRecommendationRepo, nil, "test-key", "gpt://b1g/yandexgpt-lite")
+	service.yandexGPTEndpoint = server.URL
+	service.SetChatAttachments(objects, mockProvider)
+
+	userID, sessionID := uuid.New(), uuid.New()
+	mockProvider.On("Diagnose", mock.Anything, pngHeader, "image/png").Return([]*models.DiagnosisFinding{
+		{Condition: "spider mites", Confidence: 0.834},
+		{Condition: "overwatering", Confidence: 0.01},
+	}, nil)
+	mockRecommendationRepo.On("GetChatSession", mock.Anything, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID}, nil)
+	mockRecommendationRepo.On("GetChatContext", mock.Anything, sessionID).Return(&models.ChatContext{SessionID: sessionID, SystemPrompt: chatSystemPrompt(models.LanguageEnglish)}, nil)
+	mockRecommendationRepo.On("GetChatMessages", mock.Anything, sessionID).Return([]*models.ChatMessage{}, nil)
+	var saved *models.ChatMessage
+	mockRecommendationRepo.On("SaveChatMessage", mock.Anything, mock.MatchedBy(func(m *models.ChatMessage) bool {
+		return m.Role == "user"
+	})).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*models.ChatMessage)
+	}).Return(nil).Once()
+	mockRecommendationRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRecommendationRepo.On("SaveChatContext", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockRecommendationRepo.On("UpdateChatSessionLastUsed", mock.Anything, sessionID).Return(nil)
+
+	answer, err := service.SendChatMessageWithImage(context.Background(), sessionID, userID, "What is wrong with my ficus?", pngHeader, models.LanguageEnglish)
+	require.NoError(t, err)
+	assert.Equal(t, "Looks like spider mites.", answer.Content)
+
+	// Yandex GPT got the message with the conditions found on the photo
+	if assert.Len(t, requests, 1) {
+		chat := requests[0].Messages
+		prompt := chat[len(chat)-1].Text
+		assert.True(t, strings.HasPrefix(prompt, "What is wrong with my ficus?\n\n[A photo of the plant is attached"))
+		assert.Contains(t, prompt, "spider mites 83%")
+		assert.NotContains(t, prompt, "overwatering")
+	}
+
+	// The photo is stored and saved with the message and its description
+	require.NotNil(t, saved)
+	require.NotNil(t, saved.ImageURL)
+	assert.True(t, strings.HasPrefix(string(*saved.ImageURL), "chat/"+userID.String()+"/"+sessionID.String()+"/"))
+	assert.True(t, strings.HasSuffix(string(*saved.ImageURL), ".png"))
+	assert.Equal(t, pngHeader, objects[string(*saved.ImageURL)])
+	assert.Contains(t, saved.ImageDescription, "spider mites 83%")
+	assert.Equal(t, "What is wrong with my ficus?", saved.Content)
+	mockRecommendationRepo.AssertExpectations(t)
+}
+
+// TestRecommendationService_SendChatMessageWithImage_Rejected tests that photos are rejected
+// without an object store or in an unsupported format, before anything is sent
+func TestRecommendationService_SendChatMessageWithImage_Rejected(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	service := NewRecommendationService(mockRecommendationRepo, nil, "test-key", "gpt://b1g/yandexgpt-lite")
+
+	_, err := service.SendChatMessageWithImage(context.Background(), uuid.New(), uuid.New(), "Look", pngHeader, models.LanguageEnglish)
+	assert.ErrorIs(t, err, ErrChatAttachmentsUnavailable)
+
+	service.SetChatAttachments(memoryObjectStore{}, nil)
+	_, err = service.SendChatMessageWithImage(context.Background(), uuid.New(), uuid.New(), "Look", []byte("GIF89a"), models.LanguageEnglish)
+	assert.ErrorIs(t, err, ErrUnsupportedChatImage)
+	mockRecommendationRepo.AssertNotCalled(t, "GetChatSession", mock.Anything, mock.Anything)
+}
+
+// TestRecommendationService_DescribeChatImage tests the notes passed in place of photos the vision
+// provider found nothing on or could not analyze
+func TestRecommendationService_DescribeChatImage(t *testing.T) {
+	service := NewRecommendationService(new(MockRecommendationRepository), nil, "", "")
+	ctx := context.Background()
+
+	// Without a provider the assistant is told the photo was not analyzed
+	assert.Equal(t, chatImageNotes[models.LanguageRussian].unanalyzed, service.describeChatImage(ctx, pngHeader, "image/png", models.LanguageRussian))
+
+	mockProvider := new(MockDiagnosisProvider)
+	service.SetChatAttachments(memoryObjectStore{}, mockProvider)
+	mockProvider.On("Diagnose", mock.Anything, pngHeader, "image/png").Return([]*models.DiagnosisFinding{}, nil).Once()
+	assert.Equal(t, chatImageNotes[models.LanguageEnglish].noFindings, service.describeChatImage(ctx, pngHeader, "image/png", models.LanguageEnglish))
+
+	mockProvider.On("Diagnose", mock.Anything, pngHeader, "image/png").Return([]*models.DiagnosisFinding(nil), errors.New("vision unavailable")).Once()
+	assert.Equal(t, chatImageNotes[models.LanguageEnglish].unanalyzed, service.describeChatImage(ctx, pngHeader, "image/png", models.LanguageEnglish))
+	mockProvider.AssertExpectations(t)
+}
+
+// TestChatMessageText tests that the description of a photo follows the text of its message
+func TestChatMessageText(t *testing.T) {
+	assert.Equal(t, "Hi", chatMessageText(&models.ChatMessage{Content: "Hi"}))
+	assert.Equal(t, "[photo]", chatMessageText(&models.ChatMessage{ImageDescription: "[photo]"}))
+	assert.Equal(t, "Hi\n\n[photo]", chatMessageText(&models.ChatMessage{Content: "Hi", ImageDescription: "[photo]"}))
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	// chatContextMessages is the number of the latest messages sent to Yandex GPT verbatim,
+	// including the message being answered
+	chatContextMessages = 10
+
+	// chatSummaryThreshold is the number of messages after the summary at which the older ones
+	// are folded into it
+	chatSummaryThreshold = 20
+
+	// defaultChatContextCacheSize is the number of chat contexts kept in memory when not configured
+	defaultChatContextCacheSize = 1000
+
+	// defaultChatContextIdleTimeout is how long an unused chat context stays in memory when not configured
+	defaultChatContextIdleTimeout = 24 * time.Hour
+
+	// redisChatContextPrefix is the prefix of the Redis keys chat contexts are cached under
+	redisChatContextPrefix = "planter:chat:context:"
+
+	// redisChatContextTimeout bounds the Redis round trips of a chat context cache operation
+	redisChatContextTimeout = time.Second
+)
+
+// chatSummaryOptions are the completion options of chat summaries
+var chatSummaryOptions = CompletionOptions{
+	Temperature: 0.2,
+	MaxTokens:   500,
+}
+
+// chatSummaryPrompts holds the instruction to summarize a conversation for each supported language
+var chatSummaryPrompts = map[models.Language]string{
+	models.LanguageRussian: "Кратко перескажи разговор пользователя с экспертом по растениям в нескольких предложениях. Сохрани растения, условия их содержания и советы, которые уже были даны. Отвечай на русском языке.",
+	models.LanguageEnglish: "Briefly summarize the conversation between the user and the plant expert in a few sentences. Keep the plants, their growing conditions and the advice already given. Answer in English.",
+}
+
+// chatSummaryIntros holds the text introducing the summary in the system prompt for each supported language
+var chatSummaryIntros = map[models.Language]string{
+	models.LanguageRussian: "Краткое содержание предыдущей части разговора:",
+	models.LanguageEnglish: "Summary of the earlier part of the conversation:",
+}
+
+// chatContextStore holds the contexts of recently used chat sessions. Misses are reloaded from
+// the database, so a store may drop contexts at any time.
+type chatContextStore interface {
+	// Get returns a copy of the context of a session
+	Get(sessionID uuid.UUID) (*models.ChatContext, bool)
+
+	// Put stores a copy of the context of a session
+	Put(chatContext *models.ChatContext)
+
+	// Remove drops the context of a session
+	Remove(sessionID uuid.UUID)
+}
+
+// chatContextEntry is a chat context held by the cache
+type chatContextEntry struct {
+	context  models.ChatContext
+	lastUsed time.Time
+}
+
+// chatContextCache keeps the contexts of recently used chat sessions in memory. It holds at
+// most capacity contexts, evicting the least recently used one, and drops contexts unused for
+// longer than the idle timeout. The contexts are persisted, so an evicted one is reloaded from
+// the database.
+type chatContextCache struct {
+	mu          sync.Mutex
+	capacity    int
+	idleTimeout time.Duration
+	order       *list.List // Front is the most recently used
+	entries     map[uuid.UUID]*list.Element
+	now         func() time.Time
+}
+
+// newChatContextCache creates a new chat context cache
+func newChatContextCache(capacity int, idleTimeout time.Duration) *chatContextCache {
+	if capacity <= 0 {
+		capacity = defaultChatContextCacheSize
+	}
+	if idleTimeout <= 0 {
+		idleTimeout = defaultChatContextIdleTimeout
+	}
+	return &chatContextCache{
+		capacity:    capacity,
+		idleTimeout: idleTimeout,
+		order:       list.New(),
+		entries:     make(map[uuid.UUID]*list.Element),
+		now:         time.Now,
+	}
+}
+
+// Get returns a copy of the context of a session and marks it as used
+func (c *chatContextCache) Get(sessionID uuid.UUID) (*models.ChatContext, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.evictIdle(now)
+
+	element, ok := c.entries[sessionID]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*chatContextEntry)
+	entry.lastUsed = now
+	c.order.MoveToFront(element)
+
+	chatContext := entry.context
+	return &chatContext, true
+}
+
+// Put stores a copy of the context of a session, evicting the least recently used one when full
+func (c *chatContextCache) Put(chatContext *models.ChatContext) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.evictIdle(now)
+
+	if element, ok := c.entries[chatContext.SessionID]; ok {
+		element.Value = &chatContextEntry{context: *chatContext, lastUsed: now}
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[chatContext.SessionID] = c.order.PushFront(&chatContextEntry{context: *chatContext, lastUsed: now})
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// Remove drops the context of a session
+func (c *chatContextCache) Remove(sessionID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[sessionID]; ok {
+		c.remove(element)
+	}
+}
+
+// Len returns the number of cached contexts
+func (c *chatContextCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// evictIdle drops the contexts unused for longer than the idle timeout. The list is ordered by
+// use, so it stops at the first context that is still fresh.
+func (c *chatContextCache) evictIdle(now time.Time) {
+	for element := c.order.Back(); element != nil; element = c.order.Back() {
+		if now.Sub(element.Value.(*chatContextEntry).lastUsed) <= c.idleTimeout {
+			return
+		}
+		c.remove(element)
+	}
+}
+
+// remove drops a cached context
+func (c *chatContextCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*chatContextEntry).context.SessionID)
+}
+
+// redisChatContextCache keeps the contexts of chat sessions in Redis, shared by all instances of
+// the API. A context expires once it has been unused for longer than the idle timeout; Redis
+// evicts by its own memory policy, so there is no capacity.
+type redisChatContextCache struct {
+	client      *redis.Client
+	idleTimeout time.Duration
+}
+
+// newRedisChatContextCache creates a new Redis chat context cache
+func newRedisChatContextCache(client *redis.Client, idleTimeout time.Duration) *redisChatContextCache {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultChatContextIdleTimeout
+	}
+	return &redisChatContextCache{
+		client:      client,
+		idleTimeout: idleTimeout,
+	}
+}
+
+// Get returns the context of a session and extends its expiry. Redis errors count as misses.
+func (c *redisChatContextCache) Get(sessionID uuid.UUID) (*models.ChatContext, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisChatContextTimeout)
+	defer cancel()
+
+	key := redisChatContextPrefix + sessionID.String()
+	value, err := c.client.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, redis.ErrNil) {
+			log.Printf("Error getting cached context of chat session %s: %v", sessionID, err)
+		}
+		return nil, false
+	}
+
+	var chatContext models.ChatContext
+	if err := json.Unmarshal([]byte(value), &chatContext); err != nil {
+		log.Printf("Error decoding cached context of chat session %s: %v", sessionID, err)
+		return nil, false
+	}
+	if _, err := c.client.Expire(ctx, key, c.idleTimeout); err != nil {
+		log.Printf("Error extending cached context of chat session %s: %v", sessionID, err)
+	}
+	return &chatContext, true
+}
+
+// Put stores the context of a session
+func (c *redisChatContextCache) Put(chatContext *models.ChatContext) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisChatContextTimeout)
+	defer cancel()
+
+	value, err := json.Marshal(chatContext)
+	if err != nil {
+		log.Printf("Error encoding context of chat session %s: %v", chatContext.SessionID, err)
+		return
+	}
+	if err := c.client.Set(ctx, redisChatContextPrefix+chatContext.SessionID.String(), string(value), c.idleTimeout); err != nil {
+		log.Printf("Error caching context of chat session %s: %v", chatContext.SessionID, err)
+	}
+}
+
+// Remove drops the context of a session
+func (c *redisChatContextCache) Remove(sessionID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisChatContextTimeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, redisChatContextPrefix+sessionID.String()); err != nil {
+		log.Printf("Error dropping cached context of chat session %s: %v", sessionID, err)
+	}
+}
+
+// SetChatContextCache replaces the cache of chat contexts with one holding at most capacity
+// contexts and dropping the ones unused for longer than idleTimeout
+func (s *RecommendationService) SetChatContextCache(capacity int, idleTimeout time.Duration) {
+	s.chatContexts = newChatContextCache(capacity, idleTimeout)
+}
+
+// loadChatContext returns the context of a session from the cache, loading it from the database on a miss
+func (s *RecommendationService) loadChatContext(ctx context.Context, sessionID uuid.UUID) (*models.ChatContext, error) {
+	if chatContext, ok := s.chatContexts.Get(sessionID); ok {
+		return chatContext, nil
+	}
+
+	chatContext, err := s.recommendationRepo.GetChatContext(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	s.chatContexts.Put(chatContext)
+	return chatContext, nil
+}
+
+// buildChatMessages returns the messages sent to Yandex GPT for a session: the system prompt
+// with the summary, the latest messages not covered by the summary and the message being answered
+func buildChatMessages(chatContext *models.ChatContext, history []*models.ChatMessage, message string, language models.Language) []Message {
+	systemPrompt := chatContext.SystemPrompt
+	if chatContext.Summary != "" {
+		intro, ok := chatSummaryIntros[language]
+		if !ok {
+			intro = chatSummaryIntros[models.LanguageRussian]
+		}
+		systemPrompt += "\n\n" + intro + "\n" + chatContext.Summary
+	}
+	messages := []Message{{Role: "system", Text: systemPrompt}}
+
+	if len(history) > chatContextMessages-1 {
+		history = history[len(history)-(chatContextMessages-1):]
+	}
+	for _, msg := range history {
+		// Yandex GPT knows no expert role, so the advice of a human expert is passed as its own
+		role := msg.Role
+		if role == models.ChatMessageRoleExpert {
+			role = "assistant"
+		}
+		messages = append(messages, Message{Role: role, Text: chatMessageText(msg)})
+	}
+
+	return append(messages, Message{Role: "user", Text: message})
+}
+
+// unsummarizedMessages returns the messages of a session created after the ones covered by the summary
+func unsummarizedMessages(chatContext *models.ChatContext, messages []*models.ChatMessage) []*models.ChatMessage {
+	if chatContext.SummarizedThrough == nil {
+		return messages
+	}
+	for i, msg := range messages {
+		if msg.CreatedAt.After(*chatContext.SummarizedThrough) {
+			return messages[i:]
+		}
+	}
+	return nil
+}
+
+// updateChatContext folds the messages older than the latest ones into the summary once enough
+// of them have piled up and saves the context. Summarizing is best effort: when it fails, the
+// context keeps the messages and the next message tries again.
+func (s *RecommendationService) updateChatContext(
+	ctx context.Context,
+	chatContext *models.ChatContext,
+	unsummarized []*models.ChatMessage,
+	language models.Language,
+	changed bool,
+) {
+	if len(unsummarized) > chatSummaryThreshold {
+		older := unsummarized[:len(unsummarized)-chatContextMessages]
+		summary, err := s.summarizeChat(ctx, chatContext.Summary, older, language)
+		if err != nil {
+			log.Printf("Error summarizing chat session %s: %v", chatContext.SessionID, err)
+		} else {
+			summarizedThrough := older[len(older)-1].CreatedAt
+			chatContext.Summary = summary
+			chatContext.SummarizedThrough = &summarizedThrough
+			changed = true
+		}
+	}
+
+	if !changed {
+		return
+	}
+	if err := s.recommendationRepo.SaveChatContext(ctx, chatContext); err != nil {
+		log.Printf("Error saving context of chat session %s: %v", chatContext.SessionID, err)
+		s.chatContexts.Remove(chatContext.SessionID)
+		return
+	}
+	s.chatContexts.Put(chatContext)
+}
+
+// summarizeChat asks Yandex GPT to fold messages into the previous summary of a conversation
+func (s *RecommendationService) summarizeChat(ctx context.Context, previous string, messages []*models.ChatMessage, language models.Language) (string, error) {
+	prompt, ok := chatSummaryPrompts[language]
+	if !ok {
+		prompt = chatSummaryPrompts[models.LanguageRussian]
+	}
+
+	var conversation strings.Builder
+	if previous != "" {
+		conversation.WriteString(previous)
+		conversation.WriteString("\n\n")
+	}
+	for _, msg := range messages {
+		fmt.Fprintf(&conversation, "%s: %s\n", msg.Role, chatMessageText(msg))
+	}
+
+	summary, err := s.callYandexGPTCompletion(ctx, []Message{
+		{Role: "system", Text: prompt},
+		{Role: "user", Text: conversation.String()},
+	}, chatSummaryOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize chat: %w", err)
+	}
+
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", fmt.Errorf("failed to summarize chat: empty summary")
+	}
+	return summary, nil
+}
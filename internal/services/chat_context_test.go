@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/redis"
+	"github.com/anpanovv/planter/internal/redis/redistest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestChatContextCache_EvictsLeastRecentlyUsed tests that a full cache drops the context used longest ago
+func TestChatContextCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newChatContextCache(2, time.Hour)
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+	cache.Put(&models.ChatContext{SessionID: first, SystemPrompt: "first"})
+	cache.Put(&models.ChatContext{SessionID: second})
+	_, ok := cache.Get(first)
+	assert.True(t, ok)
+	cache.Put(&models.ChatContext{SessionID: third})
+
+	assert.Equal(t, 2, cache.Len())
+	_, ok = cache.Get(second)
+	assert.False(t, ok)
+
+	// The cache hands out copies
+	chatContext, ok := cache.Get(first)
+	assert.True(t, ok)
+	chatContext.SystemPrompt = "changed"
+	chatContext, _ = cache.Get(first)
+	assert.Equal(t, "first", chatContext.SystemPrompt)
+}
+
+// TestChatContextCache_EvictsIdle tests that contexts unused for longer than the idle timeout are dropped
+func TestChatContextCache_EvictsIdle(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	cache := newChatContextCache(10, 2*time.Hour)
+	cache.now = func() time.Time { return now }
+	idle, active := uuid.New(), uuid.New()
+
+	cache.Put(&models.ChatContext{SessionID: idle})
+	cache.Put(&models.ChatContext{SessionID: active})
+	now = now.Add(90 * time.Minute)
+	_, ok := cache.Get(active)
+	assert.True(t, ok)
+
+	now = now.Add(time.Hour)
+	_, ok = cache.Get(idle)
+	assert.False(t, ok)
+	_, ok = cache.Get(active)
+	assert.True(t, ok)
+	assert.Equal(t, 1, cache.Len())
+}
+
+// TestRedisChatContextCache tests that instances sharing Redis see each other's contexts until they idle out
+func TestRedisChatContextCache(t *testing.T) {
+	server := redistest.NewServer(t)
+	first := newRedisChatContextCache(redis.New(redis.Options{Addr: server.Addr()}), time.Hour)
+	second := newRedisChatContextCache(redis.New(redis.Options{Addr: server.Addr()}), time.Hour)
+	sessionID := uuid.New()
+	summarizedThrough := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	first.Put(&models.ChatContext{SessionID: sessionID, SystemPrompt: "prompt", Summary: "summary", SummarizedThrough: &summarizedThrough})
+	chatContext, ok := second.Get(sessionID)
+	assert.True(t, ok)
+	assert.Equal(t, "summary", chatContext.Summary)
+	assert.True(t, summarizedThrough.Equal(*chatContext.SummarizedThrough))
+
+	// Reading the context extends its expiry
+	server.Advance(40 * time.Minute)
+	_, ok = first.Get(sessionID)
+	assert.True(t, ok)
+	server.Advance(40 * time.Minute)
+	_, ok = second.Get(sessionID)
+	assert.True(t, ok)
+
+	server.Advance(2 * time.Hour)
+	_, ok = first.Get(sessionID)
+	assert.False(t, ok)
+
+	first.Put(&models.ChatContext{SessionID: sessionID})
+	second.Remove(sessionID)
+	_, ok = first.Get(sessionID)
+	assert.False(t, ok)
+}
+
+// TestRecommendationService_SendChatMessage_Summarizes tests that only the latest messages are sent
+// verbatim and that older ones are folded into the persisted summary
+func TestRecommendationService_SendChatMessage_Summarizes(t *testing.T) {
+	var requests []YandexGPTRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request YandexGPTRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+		w.Write([]byte(`{"result":{"alternatives":[{"message":{"role":"assistant","text":"The monstera needs more light."}}],"usage":{"inputTextTokens":"10","completionTokens":"5","totalTokens":"15"}}}`))
+	}))
+	defer server.Close()
+
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	service := NewRecommendationService(mockRecommendationRepo, nil, "test-key", "gpt://b1g/yandexgpt-lite")
+	service.yandexGPTEndpoint = server.URL
+
+	userID, sessionID := uuid.New(), uuid.New()
+	start := time.Now().Add(-time.Hour)
+	var history []*models.ChatMessage
+	for i := 0; i < 24; i++ {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		history = append(history, &models.ChatMessage{
+			ID:        uuid.New(),
+			SessionID: sessionID,
+			Role:      role,
+			Content:   fmt.Sprintf("message %d", i),
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	mockRecommendationRepo.On("GetChatSession", mock.Anything, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID}, nil)
+	mockRecommendationRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+	mockRecommendationRepo.On("GetChatContext", mock.Anything, sessionID).Return(&models.ChatContext{SessionID: sessionID, SystemPrompt: chatSystemPrompt(models.LanguageEnglish)}, nil).Once()
+	mockRecommendationRepo.On("GetChatMessages", mock.Anything, sessionID).Return(history, nil)
+	mockRecommendationRepo.On("SaveChatContext", mock.Anything, mock.MatchedBy(func(c *models.ChatContext) bool {
+		return c.Summary == "The monstera needs more light." && c.SummarizedThrough != nil && c.SummarizedThrough.Equal(history[15].CreatedAt)
+	})).Return(nil).Once()
+	mockRecommendationRepo.On("UpdateChatSessionLastUsed", mock.Anything, sessionID).Return(nil)
+
+	_, err := service.SendChatMessage(context.Background(), sessionID, userID, "Why are the leaves of my monstera pale?", models.LanguageEnglish)
+	assert.NoError(t, err)
+
+	// The answer got the system prompt, the latest messages and the question
+	if assert.Len(t, requests, 2) {
+		chat := requests[0].Messages
+		assert.Len(t, chat, chatContextMessages+1)
+		assert.Equal(t, "system", chat[0].Role)
+		assert.Equal(t, "message 15", chat[1].Text)
+		assert.Equal(t, "Why are the leaves of my monstera pale?", chat[len(chat)-1].Text)
+		assert.Contains(t, requests[1].Messages[1].Text, "message 0")
+	}
+
+	// The summarized context is cached for the next message
+	chatContext, ok := service.chatContexts.Get(sessionID)
+	assert.True(t, ok)
+	assert.Equal(t, "The monstera needs more light.", chatContext.Summary)
+	mockRecommendationRepo.AssertExpectations(t)
+}
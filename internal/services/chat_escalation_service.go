@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrChatSessionForbidden is returned when a user escalates a chat session of another user
+	ErrChatSessionForbidden = errors.New("user does not own this chat session")
+
+	// ErrChatEscalationOpen is returned when a session already waiting for or answered by an expert is escalated
+	ErrChatEscalationOpen = errors.New("chat session is already escalated to an expert")
+
+	// ErrChatEscalationTaken is returned when an expert claims an escalation that is not waiting or is
+	// claimed by another expert
+	ErrChatEscalationTaken = errors.New("chat escalation is not waiting for an expert")
+
+	// ErrChatEscalationNotClaimed is returned when an expert answers or resolves an escalation they
+	// have not claimed
+	ErrChatEscalationNotClaimed = errors.New("chat escalation is not claimed by this expert")
+
+	// ErrInvalidChatEscalationStatus is returned when escalations are filtered by an unknown status
+	ErrInvalidChatEscalationStatus = errors.New("invalid chat escalation status")
+)
+
+// openChatEscalationStatuses are the statuses listed in the expert queue unless asked otherwise
+var openChatEscalationStatuses = []models.ChatEscalationStatus{
+	models.ChatEscalationStatusPending,
+	models.ChatEscalationStatusClaimed,
+}
+
+// ChatEscalationService hands chat sessions to human experts: users escalate a session, experts
+// claim it from the queue, answer in the session and resolve it
+type ChatEscalationService struct {
+	recommendationRepo  repository.RecommendationRepository
+	userRepo            repository.UserRepository
+	notificationService *NotificationService
+}
+
+// NewChatEscalationService creates a new chat escalation service. Users are not notified of expert
+// replies when notificationService is nil.
+func NewChatEscalationService(
+	recommendationRepo repository.RecommendationRepository,
+	userRepo repository.UserRepository,
+	notificationService *NotificationService,
+) *ChatEscalationService {
+	return &ChatEscalationService{
+		recommendationRepo:  recommendationRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+	}
+}
+
+// Escalate puts a user's chat session in the expert queue with an optional reason
+func (s *ChatEscalationService) Escalate(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID, reason string) (*models.ChatSession, error) {
+	session, err := s.recommendationRepo.GetChatSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat session: %w", err)
+	}
+	if session.UserID != userID {
+		return nil, ErrChatSessionForbidden
+	}
+
+	var reasonText *string
+	if reason != "" {
+		reasonText = &reason
+	}
+	escalated, err := s.recommendationRepo.EscalateChatSession(ctx, sessionID, reasonText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to escalate chat session: %w", err)
+	}
+	if !escalated {
+		return nil, ErrChatEscalationOpen
+	}
+
+	return s.getSession(ctx, sessionID)
+}
+
+// ListEscalations gets the escalated sessions with a status, longest waiting first. Without a status
+// the sessions waiting for or being answered by an expert are listed.
+func (s *ChatEscalationService) ListEscalations(ctx context.Context, status models.ChatEscalationStatus) ([]*models.ChatSession, error) {
+	statuses := openChatEscalationStatuses
+	switch status {
+	case "":
+	case models.ChatEscalationStatusPending, models.ChatEscalationStatusClaimed, models.ChatEscalationStatusResolved:
+		statuses = []models.ChatEscalationStatus{status}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidChatEscalationStatus, status)
+	}
+
+	sessions, err := s.recommendationRepo.GetEscalatedChatSessions(ctx, statuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat escalations: %w", err)
+	}
+	return sessions, nil
+}
+
+// GetEscalation gets an escalated session with its conversation
+func (s *ChatEscalationService) GetEscalation(ctx context.Context, sessionID uuid.UUID) (*models.ChatEscalation, error) {
+	session, err := s.getEscalatedSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.recommendationRepo.GetChatMessages(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat messages: %w", err)
+	}
+	return &models.ChatEscalation{Session: session, Messages: messages}, nil
+}
+
+// Claim assigns a waiting escalation to an expert, so other experts leave it alone
+func (s *ChatEscalationService) Claim(ctx context.Context, sessionID uuid.UUID, expertID uuid.UUID) (*models.ChatSession, error) {
+	if _, err := s.getEscalatedSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.recommendationRepo.ClaimChatEscalation(ctx, sessionID, expertID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim chat escalation: %w", err)
+	}
+	if !claimed {
+		return nil, ErrChatEscalationTaken
+	}
+
+	return s.getSession(ctx, sessionID)
+}
+
+// Reply adds an expert's answer to a claimed escalation and notifies the owner of the session
+func (s *ChatEscalationService) Reply(ctx context.Context, sessionID uuid.UUID, expertID uuid.UUID, message string) (*models.ChatMessage, error) {
+	session, err := s.getEscalatedSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if *session.EscalationStatus != models.ChatEscalationStatusClaimed || session.ExpertID == nil || *session.ExpertID != expertID {
+		return nil, ErrChatEscalationNotClaimed
+	}
+
+	owner, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat session owner: %w", err)
+	}
+
+	// The message belongs to the session's owner like the assistant's, and records the expert who wrote it
+	reply := &models.ChatMessage{
+		ID:        uuid.New(),
+		SessionID: sessionID,
+		UserID:    session.UserID,
+		Role:      models.ChatMessageRoleExpert,
+		Content:   message,
+		Language:  resolveChatLanguage(message, owner.Language),
+		ExpertID:  &expertID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.recommendationRepo.SaveChatMessage(ctx, reply); err != nil {
+		return nil, fmt.Errorf("failed to save expert message: %w", err)
+	}
+	if err := s.recommendationRepo.UpdateChatSessionLastUsed(ctx, sessionID); err != nil {
+		return nil, fmt.Errorf("failed to update chat session last used: %w", err)
+	}
+
+	// The reply is stored either way, so a failed notification is only logged
+	if s.notificationService != nil {
+		payload := models.NotificationPayload{"sessionId": sessionID.String()}
+		if _, err := s.notificationService.SendNotification(ctx, owner.ID, owner.Language, models.NotificationTypeChatExpertReply, payload); err != nil {
+			log.Printf("Failed to notify user %s about the expert reply in chat session %s: %v", owner.ID, sessionID, err)
+		}
+	}
+
+	return reply, nil
+}
+
+// Resolve closes an escalation claimed by the expert; the user can escalate the session again
+func (s *ChatEscalationService) Resolve(ctx context.Context, sessionID uuid.UUID, expertID uuid.UUID) (*models.ChatSession, error) {
+	if _, err := s.getEscalatedSession(ctx, sessionID); err != nil {
+		return nil, err
+	}
+
+	resolved, err := s.recommendationRepo.ResolveChatEscalation(ctx, sessionID, expertID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve chat escalation: %w", err)
+	}
+	if !resolved {
+		return nil, ErrChatEscalationNotClaimed
+	}
+
+	return s.getSession(ctx, sessionID)
+}
+
+// getSession gets a chat session
+func (s *ChatEscalationService) getSession(ctx context.Context, sessionID uuid.UUID) (*models.ChatSession, error) {
+	session, err := s.recommendationRepo.GetChatSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat session: %w", err)
+	}
+	return session, nil
+}
+
+// getEscalatedSession gets a chat session that has been escalated; sessions that never were are not
+// found, so experts only see the conversations users handed to them
+func (s *ChatEscalationService) getEscalatedSession(ctx context.Context, sessionID uuid.UUID) (*models.ChatSession, error) {
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.EscalationStatus == nil {
+		return nil, fmt.Errorf("chat escalation not found: %w", sql.ErrNoRows)
+	}
+	return session, nil
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestChatEscalationService_Escalate tests that users escalate their own sessions once at a time
+func TestChatEscalationService_Escalate(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	service := NewChatEscalationService(mockRecommendationRepo, new(MockUserRepository), nil)
+	ctx := context.Background()
+
+	userID, sessionID := uuid.New(), uuid.New()
+	pending := models.ChatEscalationStatusPending
+	mockRecommendationRepo.On("GetChatSession", ctx, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID}, nil).Once()
+	mockRecommendationRepo.On("EscalateChatSession", ctx, sessionID, mock.MatchedBy(func(reason *string) bool {
+		return reason != nil && *reason == "Leaves keep turning yellow"
+	})).Return(true, nil).Once()
+	mockRecommendationRepo.On("GetChatSession", ctx, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID, EscalationStatus: &pending}, nil).Once()
+
+	session, err := service.Escalate(ctx, sessionID, userID, "Leaves keep turning yellow")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ChatEscalationStatusPending, *session.EscalationStatus)
+
+	// A session already in the queue is not escalated again
+	mockRecommendationRepo.On("GetChatSession", ctx, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID, EscalationStatus: &pending}, nil)
+	mockRecommendationRepo.On("EscalateChatSession", ctx, sessionID, (*string)(nil)).Return(false, nil)
+
+	_, err = service.Escalate(ctx, sessionID, userID, "")
+	assert.ErrorIs(t, err, ErrChatEscalationOpen)
+
+	// Other users' sessions cannot be escalated
+	_, err = service.Escalate(ctx, sessionID, uuid.New(), "")
+	assert.ErrorIs(t, err, ErrChatSessionForbidden)
+	mockRecommendationRepo.AssertExpectations(t)
+}
+
+// TestChatEscalationService_ListEscalations tests the default statuses and the status filter
+func TestChatEscalationService_ListEscalations(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	service := NewChatEscalationService(mockRecommendationRepo, new(MockUserRepository), nil)
+	ctx := context.Background()
+
+	mockRecommendationRepo.On("GetEscalatedChatSessions", ctx, openChatEscalationStatuses).Return([]*models.ChatSession{}, nil).Once()
+	mockRecommendationRepo.On("GetEscalatedChatSessions", ctx, []models.ChatEscalationStatus{models.ChatEscalationStatusResolved}).Return([]*models.ChatSession{}, nil).Once()
+
+	_, err := service.ListEscalations(ctx, "")
+	assert.NoError(t, err)
+	_, err = service.ListEscalations(ctx, models.ChatEscalationStatusResolved)
+	assert.NoError(t, err)
+	_, err = service.ListEscalations(ctx, "OPEN")
+	assert.ErrorIs(t, err, ErrInvalidChatEscalationStatus)
+	mockRecommendationRepo.AssertExpectations(t)
+}
+
+// TestChatEscalationService_Claim tests that an escalation claimed by another expert cannot be claimed
+func TestChatEscalationService_Claim(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	service := NewChatEscalationService(mockRecommendationRepo, new(MockUserRepository), nil)
+	ctx := context.Background()
+
+	sessionID, expertID := uuid.New(), uuid.New()
+	claimed := models.ChatEscalationStatusClaimed
+	mockRecommendationRepo.On("GetChatSession", ctx, sessionID).Return(&models.ChatSession{ID: sessionID, EscalationStatus: &claimed}, nil)
+	mockRecommendationRepo.On("ClaimChatEscalation", ctx, sessionID, expertID).Return(false, nil)
+
+	_, err := service.Claim(ctx, sessionID, expertID)
+	assert.ErrorIs(t, err, ErrChatEscalationTaken)
+
+	// Sessions that were never escalated are not found
+	otherID := uuid.New()
+	mockRecommendationRepo.On("GetChatSession", ctx, otherID).Return(&models.ChatSession{ID: otherID}, nil)
+
+	_, err = service.Claim(ctx, otherID, expertID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	mockRecommendationRepo.AssertNotCalled(t, "ClaimChatEscalation", ctx, otherID, expertID)
+}
+
+// TestChatEscalationService_Reply tests that the expert's answer is added to the session and the owner is notified
+func TestChatEscalationService_Reply(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	notificationService := NewNotificationService(mockNotificationRepo, new(MockPlantRepository), nil, NewNotificationTemplateService(mockTemplateRepo))
+	service := NewChatEscalationService(mockRecommendationRepo, mockUserRepo, notificationService)
+	ctx := context.Background()
+
+	userID, sessionID, expertID := uuid.New(), uuid.New(), uuid.New()
+	claimed := models.ChatEscalationStatusClaimed
+	mockRecommendationRepo.On("GetChatSession", ctx, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID, EscalationStatus: &claimed, ExpertID: &expertID}, nil)
+	mockUserRepo.On("GetByID", ctx, userID).Return(&models.User{ID: userID, Language: models.LanguageEnglish}, nil)
+	mockRecommendationRepo.On("SaveChatMessage", ctx, mock.MatchedBy(func(m *models.ChatMessage) bool {
+		return m.SessionID == sessionID && m.UserID == userID && m.Role == models.ChatMessageRoleExpert &&
+			m.ExpertID != nil && *m.ExpertID == expertID && m.Language == models.LanguageEnglish
+	})).Return(nil)
+	mockRecommendationRepo.On("UpdateChatSessionLastUsed", ctx, sessionID).Return(nil)
+	mockTemplateRepo.On("Get", ctx, models.NotificationTypeChatExpertReply, mock.Anything).Return(nil, nil)
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.UserID == userID && n.Type == models.NotificationTypeChatExpertReply && n.Payload["sessionId"] == sessionID.String()
+	})).Return(nil).Once()
+
+	message, err := service.Reply(ctx, sessionID, expertID, "Water it less often and move it away from the radiator.")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ChatMessageRoleExpert, message.Role)
+	mockRecommendationRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertExpectations(t)
+
+	// Only the expert who claimed the escalation answers it
+	_, err = service.Reply(ctx, sessionID, uuid.New(), "Repot it.")
+	assert.ErrorIs(t, err, ErrChatEscalationNotClaimed)
+}
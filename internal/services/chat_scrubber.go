@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// Kinds of data the chat scrubber removes
+const (
+	ChatScrubEmail       = "email"
+	ChatScrubPhone       = "phone"
+	ChatScrubAddress     = "address"
+	ChatScrubPattern     = "pattern"
+	ChatScrubBlockedWord = "blocked_word"
+)
+
+// ChatScrubKinds lists the kinds of scrubbed data in the order they are scrubbed
+var ChatScrubKinds = []string{ChatScrubEmail, ChatScrubPhone, ChatScrubAddress, ChatScrubPattern, ChatScrubBlockedWord}
+
+// chatScrubRule replaces the matches of a pattern with a placeholder. The replacement is a
+// regexp template, so rules matching the character before their match can put it back with ${1}.
+type chatScrubRule struct {
+	kind        string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// defaultChatScrubRules find emails, phone numbers and street addresses. Go regular expressions know
+// word boundaries of ASCII letters only, so the Russian addresses match the character before them.
+var defaultChatScrubRules = []chatScrubRule{
+	{
+		kind:        ChatScrubEmail,
+		pattern:     regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		replacement: "[email]",
+	},
+	{
+		// +7 (999) 123-45-67, 8 999 123 45 67, 89991234567, +1 555 123 4567
+		kind:        ChatScrubPhone,
+		pattern:     regexp.MustCompile(`(?:\+\d{1,3}[\s\-]?(?:\(\d{3}\)|\d{3})|\b8[\s\-]?(?:\(\d{3}\)|\d{3})|\(\d{3}\)|\b\d{3})[\s\-]?\d{3}[\s\-]?\d{2}[\s\-]?\d{2}\b`),
+		replacement: "[phone]",
+	},
+	{
+		// ул. Ленина, д. 5, кв. 12
+		kind:        ChatScrubAddress,
+		pattern:     regexp.MustCompile(`(^|[^\p{L}])(?i:ул|улица|пр-т|проспект|пер|переулок|б-р|бульвар|шоссе|наб|набережная|пл|площадь)\.?\s+\p{L}[\p{L}\d.\- ]{0,40}?,?\s*(?:(?i:д|дом)\.?\s*)?\d+\p{L}?(?:/\d+)?(?:,?\s*(?i:кв|квартира|корп|корпус|стр)\.?\s*\d+)*`),
+		replacement: "${1}[address]",
+	},
+	{
+		// Невский проспект, 28
+		kind:        ChatScrubAddress,
+		pattern:     regexp.MustCompile(`(^|[^\p{L}])\p{Lu}[\p{L}\-]+\s+(?i:улица|проспект|переулок|бульвар|шоссе|набережная|площадь),?\s*(?:(?i:д|дом)\.?\s*)?\d+\p{L}?(?:/\d+)?(?:,?\s*(?i:кв|квартира|корп|корпус|стр)\.?\s*\d+)*`),
+		replacement: "${1}[address]",
+	},
+	{
+		// 221B Baker Street
+		kind:        ChatScrubAddress,
+		pattern:     regexp.MustCompile(`\b\d+[A-Za-z]?\s+(?:[A-Z][a-z]+\s+){1,3}(?:Street|St|Avenue|Ave|Road|Rd|Boulevard|Blvd|Lane|Ln|Drive|Dr|Way|Court|Ct|Place|Pl)\b\.?`),
+		replacement: "[address]",
+	},
+}
+
+// chatWordPattern matches the words checked against the blocked words
+var chatWordPattern = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// ChatScrubStats holds the counters of a chat scrubber
+type ChatScrubStats struct {
+	Messages uint64            // messages that had something scrubbed
+	Scrubs   map[string]uint64 // scrubbed matches by kind
+}
+
+// ChatScrubber removes personal data and blocked words from chat messages before they are sent to
+// Yandex GPT. Emails, phone numbers and addresses are replaced with placeholders naming what was
+// there; matches of the configured patterns and blocked words with [redacted].
+type ChatScrubber struct {
+	rules        []chatScrubRule
+	blockedWords map[string]bool
+	messages     atomic.Uint64
+	scrubs       map[string]*atomic.Uint64 // fixed set of kinds, so it is only read concurrently
+}
+
+// NewChatScrubber creates a chat scrubber applying the default rules, the regular expressions
+// in patterns and the blocked words, which are matched as whole words regardless of case
+func NewChatScrubber(patterns []string, blockedWords []string) (*ChatScrubber, error) {
+	rules := append([]chatScrubRule(nil), defaultChatScrubRules...)
+	for _, pattern := range patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat scrub pattern %q: %w", pattern, err)
+		}
+		rules = append(rules, chatScrubRule{kind: ChatScrubPattern, pattern: compiled, replacement: "[redacted]"})
+	}
+
+	words := make(map[string]bool, len(blockedWords))
+	for _, word := range blockedWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			words[word] = true
+		}
+	}
+
+	scrubs := make(map[string]*atomic.Uint64, len(ChatScrubKinds))
+	for _, kind := range ChatScrubKinds {
+		scrubs[kind] = new(atomic.Uint64)
+	}
+
+	return &ChatScrubber{rules: rules, blockedWords: words, scrubs: scrubs}, nil
+}
+
+// LoadChatScrubPatterns reads the regular expressions of a patterns file, one per line. Blank lines
+// and lines starting with # are skipped.
+func LoadChatScrubPatterns(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open chat scrub patterns: %w", err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read chat scrub patterns: %w", err)
+	}
+	return patterns, nil
+}
+
+// Scrub returns the text with personal data and blocked words replaced, along with the number of
+// replacements by kind. Nothing is counted; Record adds the replacements to the counters.
+func (s *ChatScrubber) Scrub(text string) (string, map[string]int) {
+	counts := make(map[string]int)
+	for _, rule := range s.rules {
+		matches := rule.pattern.FindAllStringIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		counts[rule.kind] += len(matches)
+		text = rule.pattern.ReplaceAllString(text, rule.replacement)
+	}
+
+	if len(s.blockedWords) > 0 {
+		text = chatWordPattern.ReplaceAllStringFunc(text, func(word string) string {
+			if !s.blockedWords[strings.ToLower(word)] {
+				return word
+			}
+			counts[ChatScrubBlockedWord]++
+			return "[redacted]"
+		})
+	}
+
+	return text, counts
+}
+
+// Record adds the replacements made in a message to the counters
+func (s *ChatScrubber) Record(counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	s.messages.Add(1)
+	for kind, count := range counts {
+		if counter, ok := s.scrubs[kind]; ok {
+			counter.Add(uint64(count))
+		}
+	}
+}
+
+// Stats returns the counters since the scrubber was created
+func (s *ChatScrubber) Stats() ChatScrubStats {
+	stats := ChatScrubStats{Messages: s.messages.Load(), Scrubs: make(map[string]uint64, len(s.scrubs))}
+	for kind, counter := range s.scrubs {
+		stats.Scrubs[kind] = counter.Load()
+	}
+	return stats
+}
+
+// formatChatScrubCounts formats the replacements made in a message as kind=count pairs in a fixed order
+func formatChatScrubCounts(counts map[string]int) string {
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	pairs := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", kind, counts[kind]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// SetChatScrubber sets the scrubber chat messages go through before they are sent to Yandex GPT
+func (s *RecommendationService) SetChatScrubber(scrubber *ChatScrubber) {
+	s.scrubber = scrubber
+}
+
+// scrubChatMessage scrubs a new message of a session and counts what was scrubbed. The counters
+// are logged in a fixed format so log-based metrics can pick them up; the message is not.
+func (s *RecommendationService) scrubChatMessage(sessionID uuid.UUID, message string) string {
+	if s.scrubber == nil {
+		return message
+	}
+	scrubbed, counts := s.scrubber.Scrub(message)
+	if len(counts) > 0 {
+		s.scrubber.Record(counts)
+		log.Printf("chat scrub session=%s %s", sessionID, formatChatScrubCounts(counts))
+	}
+	return scrubbed
+}
+
+// scrubChatHistory returns copies of the user messages of a session scrubbed again, since the
+// messages are saved as written. They were counted when they were sent.
+func (s *RecommendationService) scrubChatHistory(history []*models.ChatMessage) []*models.ChatMessage {
+	if s.scrubber == nil {
+		return history
+	}
+	scrubbed := make([]*models.ChatMessage, len(history))
+	for i, msg := range history {
+		scrubbed[i] = msg
+		if msg.Role != "user" {
+			continue
+		}
+		copied := *msg
+		copied.Content, _ = s.scrubber.Scrub(msg.Content)
+		scrubbed[i] = &copied
+	}
+	return scrubbed
+}
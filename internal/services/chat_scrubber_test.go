@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChatScrubber_Scrub tests that personal data, configured patterns and blocked words are replaced
+func TestChatScrubber_Scrub(t *testing.T) {
+	scrubber, err := NewChatScrubber([]string{`ORD-\d+`}, []string{"Darn"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		counts   map[string]int
+	}{
+		{
+			name:     "email",
+			text:     "Write me at anna.petrova+plants@example.com please",
+			expected: "Write me at [email] please",
+			counts:   map[string]int{ChatScrubEmail: 1},
+		},
+		{
+			name:     "phones",
+			text:     "Call +7 (999) 123-45-67 or 89991234567",
+			expected: "Call [phone] or [phone]",
+			counts:   map[string]int{ChatScrubPhone: 2},
+		},
+		{
+			name:     "russian address",
+			text:     "Я живу на ул. Ленина, д. 5, кв. 12, там много света",
+			expected: "Я живу на [address], там много света",
+			counts:   map[string]int{ChatScrubAddress: 1},
+		},
+		{
+			name:     "russian address with the street type after the name",
+			text:     "Офис на Невский проспект, 28",
+			expected: "Офис на [address]",
+			counts:   map[string]int{ChatScrubAddress: 1},
+		},
+		{
+			name:     "english address",
+			text:     "My balcony at 221B Baker Street faces north",
+			expected: "My balcony at [address] faces north",
+			counts:   map[string]int{ChatScrubAddress: 1},
+		},
+		{
+			name:     "pattern and blocked words",
+			text:     "Order ORD-1234 arrived, darn it, DARN",
+			expected: "Order [redacted] arrived, [redacted] it, [redacted]",
+			counts:   map[string]int{ChatScrubPattern: 1, ChatScrubBlockedWord: 2},
+		},
+		{
+			name:     "plant care is kept",
+			text:     "Water every 7-10 days at 18-25 °C, the pot is 12345 mm wide; стул у окна 5",
+			expected: "Water every 7-10 days at 18-25 °C, the pot is 12345 mm wide; стул у окна 5",
+			counts:   map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scrubbed, counts := scrubber.Scrub(tt.text)
+			assert.Equal(t, tt.expected, scrubbed)
+			assert.Equal(t, tt.counts, counts)
+		})
+	}
+}
+
+// TestNewChatScrubber_InvalidPattern tests that a pattern that does not compile is refused
+func TestNewChatScrubber_InvalidPattern(t *testing.T) {
+	_, err := NewChatScrubber([]string{`(`}, nil)
+	assert.Error(t, err)
+}
+
+// TestRecommendationService_SendChatMessage_Scrubs tests that Yandex GPT gets the scrubbed messages
+// while the message is saved as written and the scrubs are counted once
+func TestRecommendationService_SendChatMessage_Scrubs(t *testing.T) {
+	var request YandexGPTRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"result":{"alternatives":[{"message":{"role":"assistant","text":"Move it closer to the window."}}],"usage":{"inputTextTokens":"10","completionTokens":"5","totalTokens":"15"}}}`))
+	}))
+	defer server.Close()
+
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	service := NewRecommendationService(mockRecommendationRepo, nil, "test-key", "gpt://b1g/yandexgpt-lite")
+	service.yandexGPTEndpoint = server.URL
+	scrubber, err := NewChatScrubber(nil, nil)
+	require.NoError(t, err)
+	service.SetChatScrubber(scrubber)
+
+	userID, sessionID := uuid.New(), uuid.New()
+	history := []*models.ChatMessage{
+		{ID: uuid.New(), SessionID: sessionID, Role: "user", Content: "My email is anna@example.com", CreatedAt: time.Now().Add(-time.Minute)},
+		{ID: uuid.New(), SessionID: sessionID, Role: "assistant", Content: "How can I help?", CreatedAt: time.Now().Add(-time.Minute)},
+	}
+	message := "Call me at +7 999 123 45 67 about my monstera"
+
+	mockRecommendationRepo.On("GetChatSession", mock.Anything, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID}, nil)
+	mockRecommendationRepo.On("GetChatContext", mock.Anything, sessionID).Return(&models.ChatContext{SessionID: sessionID, SystemPrompt: chatSystemPrompt(models.LanguageEnglish)}, nil)
+	mockRecommendationRepo.On("GetChatMessages", mock.Anything, sessionID).Return(history, nil)
+	mockRecommendationRepo.On("SaveChatMessage", mock.Anything, mock.MatchedBy(func(m *models.ChatMessage) bool {
+		return m.Role == "user" && m.Content == message
+	})).Return(nil).Once()
+	mockRecommendationRepo.On("SaveChatMessage", mock.Anything, mock.MatchedBy(func(m *models.ChatMessage) bool {
+		return m.Role == "assistant"
+	})).Return(nil).Once()
+	mockRecommendationRepo.On("UpdateChatSessionLastUsed", mock.Anything, sessionID).Return(nil)
+
+	_, err = service.SendChatMessage(context.Background(), sessionID, userID, message, models.LanguageEnglish)
+	require.NoError(t, err)
+
+	if assert.Len(t, request.Messages, 4) {
+		assert.Equal(t, "My email is [email]", request.Messages[1].Text)
+		assert.Equal(t, "How can I help?", request.Messages[2].Text)
+		assert.Equal(t, "Call me at [phone] about my monstera", request.Messages[3].Text)
+	}
+	assert.Equal(t, "My email is anna@example.com", history[0].Content)
+
+	stats := scrubber.Stats()
+	assert.Equal(t, uint64(1), stats.Messages)
+	assert.Equal(t, uint64(1), stats.Scrubs[ChatScrubPhone])
+	assert.Equal(t, uint64(0), stats.Scrubs[ChatScrubEmail])
+	mockRecommendationRepo.AssertExpectations(t)
+}
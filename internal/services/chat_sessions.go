@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidChatSessionTitle is returned when a chat session is renamed to a blank title
+var ErrInvalidChatSessionTitle = errors.New("chat session title must not be blank")
+
+// defaultChatSessionTitle is the title of new chat sessions until their first exchange titles them
+const defaultChatSessionTitle = "Разговор о растениях"
+
+// maxGeneratedChatTitleLength is the most characters of a title generated from a message, the
+// ellipsis included
+const maxGeneratedChatTitleLength = 50
+
+// chatPhotoTitles are the titles of sessions whose first message is a photo without text
+var chatPhotoTitles = map[models.Language]string{
+	models.LanguageRussian: "Фото растения",
+	models.LanguageEnglish: "Plant photo",
+}
+
+// RenameChatSession renames a chat session of the user
+func (s *RecommendationService) RenameChatSession(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID, title string) (*models.ChatSession, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrInvalidChatSessionTitle
+	}
+
+	session, err := s.recommendationRepo.GetChatSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat session: %w", err)
+	}
+	if session.UserID != userID {
+		return nil, ErrChatSessionForbidden
+	}
+
+	if err := s.recommendationRepo.RenameChatSession(ctx, sessionID, title); err != nil {
+		return nil, err
+	}
+	return s.recommendationRepo.GetChatSession(ctx, sessionID)
+}
+
+// DeleteChatSession deletes a chat session of the user with its messages and the photos attached
+// to them. Photos are deleted once the session is gone; a photo that cannot be deleted is logged.
+func (s *RecommendationService) DeleteChatSession(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) error {
+	session, err := s.recommendationRepo.GetChatSession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat session: %w", err)
+	}
+	if session.UserID != userID {
+		return ErrChatSessionForbidden
+	}
+
+	messages, err := s.recommendationRepo.GetChatMessages(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get chat messages: %w", err)
+	}
+	if err := s.recommendationRepo.DeleteChatSession(ctx, sessionID); err != nil {
+		return err
+	}
+	s.chatContexts.Remove(sessionID)
+
+	if s.chatObjects != nil {
+		for _, message := range messages {
+			s.deleteStoredChatImage(ctx, message)
+		}
+	}
+	return nil
+}
+
+// titleChatSession titles a session after its first exchange, unless the user renamed it already.
+// Titling is best effort: a failure is logged and the session keeps the default title.
+func (s *RecommendationService) titleChatSession(ctx context.Context, sessionID uuid.UUID, message string, hasImage bool, language models.Language) {
+	title := generateChatSessionTitle(message, hasImage, language)
+	if title == "" {
+		return
+	}
+	if _, err := s.recommendationRepo.ReplaceChatSessionTitle(ctx, sessionID, defaultChatSessionTitle, title); err != nil {
+		log.Printf("Error titling chat session %s: %v", sessionID, err)
+	}
+}
+
+// generateChatSessionTitle generates the title of a session from its first message: the first
+// sentence, cut at a word to maxGeneratedChatTitleLength characters. A photo sent without text
+// titles the session as a plant photo; an empty title leaves the default.
+func generateChatSessionTitle(message string, hasImage bool, language models.Language) string {
+	text := strings.TrimSpace(message)
+	if line, _, found := strings.Cut(text, "\n"); found {
+		text = line
+	}
+	text = firstSentence(strings.Join(strings.Fields(text), " "))
+	text = strings.TrimRight(text, " ,;:-.…")
+
+	if text == "" {
+		if !hasImage {
+			return ""
+		}
+		if title, ok := chatPhotoTitles[language]; ok {
+			return title
+		}
+		return chatPhotoTitles[models.LanguageRussian]
+	}
+
+	if utf8.RuneCountInString(text) > maxGeneratedChatTitleLength {
+		runes := []rune(text)[:maxGeneratedChatTitleLength-1]
+		if i := strings.LastIndex(string(runes), " "); i > 0 {
+			runes = []rune(string(runes)[:i])
+		}
+		text = strings.TrimRight(string(runes), " ,;:-") + "…"
+	}
+
+	first, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(first)) + text[size:]
+}
+
+// firstSentence returns the text up to the end of its first sentence, keeping a question or
+// exclamation mark; a period is dropped. Periods inside words and numbers, as in "0.5", do not end
+// a sentence.
+func firstSentence(text string) string {
+	for i, r := range text {
+		if r != '.' && r != '!' && r != '?' && r != '…' {
+			continue
+		}
+		end := i + utf8.RuneLen(r)
+		if end < len(text) && text[end] != ' ' {
+			continue
+		}
+		if r == '!' || r == '?' {
+			return text[:end]
+		}
+		return text[:i]
+	}
+	return text
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateChatSessionTitle tests that titles are taken from the first sentence of the first
+// message and cut at a word
+func TestGenerateChatSessionTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		hasImage bool
+		language models.Language
+		want     string
+	}{
+		{"question", "почему желтеют листья у фикуса? Стоит у окна", false, models.LanguageRussian, "Почему желтеют листья у фикуса?"},
+		{"period dropped", "My monstera droops.  It gets water weekly", false, models.LanguageEnglish, "My monstera droops"},
+		{"numbers kept", "Поливаю 0.5 л раз в неделю", false, models.LanguageRussian, "Поливаю 0.5 л раз в неделю"},
+		{"first line", "Hi\nmy cactus is soft", false, models.LanguageEnglish, "Hi"},
+		{"cut at a word", "How often should I water a fiddle leaf fig that stands next to a radiator", false, models.LanguageEnglish, "How often should I water a fiddle leaf fig that…"},
+		{"photo without text", "  ", true, models.LanguageEnglish, "Plant photo"},
+		{"nothing to title", "...", false, models.LanguageRussian, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title := generateChatSessionTitle(tt.message, tt.hasImage, tt.language)
+			assert.Equal(t, tt.want, title)
+			assert.LessOrEqual(t, len([]rune(title)), maxGeneratedChatTitleLength)
+		})
+	}
+}
+
+// TestRecommendationService_SendChatMessage_TitlesSession tests that a new session is titled from
+// its first message, without overwriting a title the user gave it
+func TestRecommendationService_SendChatMessage_TitlesSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":{"alternatives":[{"message":{"role":"assistant","text":"Water it less."}}],"usage":{"inputTextTokens":"10","completionTokens":"5","totalTokens":"15"}}}`))
+	}))
+	defer server.Close()
+
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	service := NewRecommendationService(mockRecommendationRepo, nil, "test-key", "gpt://b1g/yandexgpt-lite")
+	service.yandexGPTEndpoint = server.URL
+
+	userID, sessionID := uuid.New(), uuid.New()
+	mockRecommendationRepo.On("GetChatSession", mock.Anything, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID, Title: defaultChatSessionTitle}, nil)
+	mockRecommendationRepo.On("GetChatContext", mock.Anything, sessionID).Return(&models.ChatContext{SessionID: sessionID, SystemPrompt: chatSystemPrompt(models.LanguageEnglish)}, nil)
+	mockRecommendationRepo.On("GetChatMessages", mock.Anything, sessionID).Return([]*models.ChatMessage{}, nil)
+	mockRecommendationRepo.On("SaveChatMessage", mock.Anything, mock.Anything).Return(nil)
+	mockRecommendationRepo.On("SaveChatContext", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mockRecommendationRepo.On("UpdateChatSessionLastUsed", mock.Anything, sessionID).Return(nil)
+	mockRecommendationRepo.On("ReplaceChatSessionTitle", mock.Anything, sessionID, defaultChatSessionTitle, "Why is my cactus soft?").Return(true, nil).Once()
+
+	_, err := service.SendChatMessage(context.Background(), sessionID, userID, "why is my cactus soft? It is in a north window", models.LanguageEnglish)
+	require.NoError(t, err)
+	mockRecommendationRepo.AssertExpectations(t)
+}
+
+// TestRecommendationService_RenameChatSession tests that titles are trimmed and that only the
+// owner renames a session
+func TestRecommendationService_RenameChatSession(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	service := NewRecommendationService(mockRecommendationRepo, nil, "", "")
+
+	userID, sessionID := uuid.New(), uuid.New()
+	mockRecommendationRepo.On("GetChatSession", mock.Anything, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID, Title: "Cactus"}, nil)
+	mockRecommendationRepo.On("RenameChatSession", mock.Anything, sessionID, "Cactus").Return(nil).Once()
+
+	session, err := service.RenameChatSession(context.Background(), sessionID, userID, "  Cactus ")
+	require.NoError(t, err)
+	assert.Equal(t, "Cactus", session.Title)
+
+	_, err = service.RenameChatSession(context.Background(), sessionID, userID, "   ")
+	assert.ErrorIs(t, err, ErrInvalidChatSessionTitle)
+
+	_, err = service.RenameChatSession(context.Background(), sessionID, uuid.New(), "Mine now")
+	assert.ErrorIs(t, err, ErrChatSessionForbidden)
+	mockRecommendationRepo.AssertExpectations(t)
+}
+
+// TestRecommendationService_DeleteChatSession tests that a session is deleted with the photos
+// attached to its messages, and that other users cannot delete it
+func TestRecommendationService_DeleteChatSession(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	service := NewRecommendationService(mockRecommendationRepo, nil, "", "")
+	objects := memoryObjectStore{"chat/photo.png": pngHeader, "plants/other.png": pngHeader}
+	service.SetChatAttachments(objects, nil)
+
+	userID, sessionID := uuid.New(), uuid.New()
+	photo := models.AssetKey("chat/photo.png")
+	mockRecommendationRepo.On("GetChatSession", mock.Anything, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID}, nil)
+	mockRecommendationRepo.On("GetChatMessages", mock.Anything, sessionID).Return([]*models.ChatMessage{
+		{Role: "user", Content: "Look", ImageURL: &photo},
+		{Role: "assistant", Content: "Looks healthy"},
+	}, nil)
+	mockRecommendationRepo.On("DeleteChatSession", mock.Anything, sessionID).Return(nil).Once()
+
+	assert.ErrorIs(t, service.DeleteChatSession(context.Background(), sessionID, uuid.New()), ErrChatSessionForbidden)
+	assert.Len(t, objects, 2)
+
+	require.NoError(t, service.DeleteChatSession(context.Background(), sessionID, userID))
+	assert.NotContains(t, objects, "chat/photo.png")
+	assert.Contains(t, objects, "plants/other.png")
+
+	// A session that is gone is not found
+	missingID := uuid.New()
+	mockRecommendationRepo.On("GetChatSession", mock.Anything, missingID).Return(nil, fmt.Errorf("chat session not found: %w", sql.ErrNoRows))
+	assert.ErrorIs(t, service.DeleteChatSession(context.Background(), missingID, userID), sql.ErrNoRows)
+	mockRecommendationRepo.AssertExpectations(t)
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// circuitBreaker stops calls to a failing dependency. It opens after a number of
+// consecutive failures, rejects calls until the cooldown passes and then lets a
+// single probe call through: the breaker closes if the probe succeeds and opens
+// again if it fails.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     models.CircuitBreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// newCircuitBreaker creates a closed circuit breaker; it never opens when threshold is not positive
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     models.CircuitBreakerClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a call may be made now
+func (b *circuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case models.CircuitBreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = models.CircuitBreakerHalfOpen
+		b.probing = true
+		return true
+	case models.CircuitBreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record records the outcome of an allowed call
+func (b *circuitBreaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.state = models.CircuitBreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == models.CircuitBreakerHalfOpen || (b.threshold > 0 && b.failures >= b.threshold) {
+		b.state = models.CircuitBreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Skip releases an allowed call whose outcome says nothing about the dependency, such as a cancelled one
+func (b *circuitBreaker) Skip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Status returns the current state of the breaker
+func (b *circuitBreaker) Status() models.CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := models.CircuitBreakerStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != models.CircuitBreakerClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// apiDeprecations lists the endpoints scheduled for removal.
+// Add an entry here when an endpoint gets a sunset date so clients can warn about it.
+var apiDeprecations = []models.DeprecationNotice{}
+
+// onboardingCopy holds the onboarding screens for each supported language
+var onboardingCopy = map[models.Language][]models.OnboardingStep{
+	models.LanguageRussian: {
+		{Title: "Добро пожаловать в Planter", Body: "Собирайте свою коллекцию растений и следите за их состоянием."},
+		{Title: "Подбор растений", Body: "Ответьте на несколько вопросов, и мы подберем растения под ваши условия."},
+		{Title: "Напоминания о поливе", Body: "Мы напомним, когда пора полить растения, чтобы вы ничего не забыли."},
+		{Title: "Советы эксперта", Body: "Задавайте вопросы об уходе в чате с ассистентом."},
+	},
+	models.LanguageEnglish: {
+		{Title: "Welcome to Planter", Body: "Build your plant collection and keep track of how your plants are doing."},
+		{Title: "Plant matching", Body: "Answer a few questions and we will suggest plants that suit your home."},
+		{Title: "Watering reminders", Body: "We will remind you when it is time to water so nothing gets forgotten."},
+		{Title: "Expert advice", Body: "Ask the assistant anything about plant care in the chat."},
+	},
+}
+
+// ClientConfigService builds the server-driven configuration for mobile clients
+type ClientConfigService struct {
+	minAppVersion    string
+	latestAppVersion string
+	features         map[string]bool
+}
+
+// NewClientConfigService creates a new client config service
+func NewClientConfigService(minAppVersion, latestAppVersion string, features map[string]bool) *ClientConfigService {
+	return &ClientConfigService{
+		minAppVersion:    minAppVersion,
+		latestAppVersion: latestAppVersion,
+		features:         features,
+	}
+}
+
+// GetClientConfig returns the client configuration for the given app version and language.
+// An empty app version is treated as up to date.
+func (s *ClientConfigService) GetClientConfig(appVersion string, language models.Language) *models.ClientConfig {
+	if _, ok := onboardingCopy[language]; !ok {
+		language = models.LanguageRussian
+	}
+
+	features := make(map[string]bool, len(s.features))
+	for name, enabled := range s.features {
+		features[name] = enabled
+	}
+
+	versionInfo := models.AppVersionInfo{
+		Minimum: s.minAppVersion,
+		Latest:  s.latestAppVersion,
+	}
+	if appVersion != "" {
+		versionInfo.ForceUpgrade = compareVersions(appVersion, s.minAppVersion) < 0
+		versionInfo.UpdateAvailable = compareVersions(appVersion, s.latestAppVersion) < 0
+	}
+
+	return &models.ClientConfig{
+		Features:     features,
+		AppVersion:   versionInfo,
+		Deprecations: apiDeprecations,
+		Language:     language,
+		Onboarding:   onboardingCopy[language],
+	}
+}
+
+// compareVersions compares two dotted version strings numerically.
+// It returns -1 if a < b, 0 if a == b and 1 if a > b; missing or non-numeric parts count as zero.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bParts := strings.Split(strings.TrimPrefix(b, "v"), ".")
+
+	n := len(aParts)
+	if len(bParts) > n {
+		n = len(bParts)
+	}
+
+	for i := 0; i < n; i++ {
+		var aNum, bNum int
+		if i < len(aParts) {
+			aNum, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bNum, _ = strconv.Atoi(bParts[i])
+		}
+		if aNum < bNum {
+			return -1
+		}
+		if aNum > bNum {
+			return 1
+		}
+	}
+	return 0
+}
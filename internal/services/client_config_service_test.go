@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompareVersions tests numeric comparison of dotted versions
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, compareVersions("1.2.0", "1.2"))
+	assert.Equal(t, -1, compareVersions("1.2.9", "1.10.0"))
+	assert.Equal(t, 1, compareVersions("v2.0.0", "1.99.99"))
+	assert.Equal(t, -1, compareVersions("1.0", "1.0.1"))
+}
+
+// TestClientConfigService_GetClientConfig tests version flags, language fallback and flag copying
+func TestClientConfigService_GetClientConfig(t *testing.T) {
+	features := map[string]bool{"chat": true, "shops": false}
+	service := NewClientConfigService("1.2.0", "1.4.0", features)
+
+	// Outdated client must be forced to upgrade
+	config := service.GetClientConfig("1.1.5", models.LanguageEnglish)
+	assert.True(t, config.AppVersion.ForceUpgrade)
+	assert.True(t, config.AppVersion.UpdateAvailable)
+	assert.Equal(t, models.LanguageEnglish, config.Language)
+	assert.NotEmpty(t, config.Onboarding)
+	assert.Equal(t, features, config.Features)
+
+	// Supported but not latest client only gets an update hint
+	config = service.GetClientConfig("1.3.0", models.LanguageRussian)
+	assert.False(t, config.AppVersion.ForceUpgrade)
+	assert.True(t, config.AppVersion.UpdateAvailable)
+
+	// Unknown version and language fall back to defaults
+	config = service.GetClientConfig("", models.Language("GERMAN"))
+	assert.False(t, config.AppVersion.ForceUpgrade)
+	assert.False(t, config.AppVersion.UpdateAvailable)
+	assert.Equal(t, models.LanguageRussian, config.Language)
+
+	// Mutating the response must not leak into the service
+	config.Features["chat"] = false
+	assert.True(t, service.GetClientConfig("", models.LanguageRussian).Features["chat"])
+}
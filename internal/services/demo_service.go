@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// demoLookupRetry is how long a failed lookup of the demo account is cached
+const demoLookupRetry = time.Minute
+
+// DemoService identifies the shared demo account and simulates mutations made with it,
+// so app store reviewers and sales demos see realistic responses without changing real data
+type DemoService struct {
+	userRepo  repository.UserRepository
+	plantRepo repository.PlantRepository
+	email     string
+
+	mu         sync.Mutex
+	userID     uuid.UUID
+	lookedUpAt time.Time
+}
+
+// NewDemoService creates a new demo service; demo mode is disabled when email is empty
+func NewDemoService(userRepo repository.UserRepository, plantRepo repository.PlantRepository, email string) *DemoService {
+	return &DemoService{
+		userRepo:  userRepo,
+		plantRepo: plantRepo,
+		email:     strings.TrimSpace(email),
+	}
+}
+
+// Enabled reports whether a demo account is configured
+func (s *DemoService) Enabled() bool {
+	return s.email != ""
+}
+
+// IsDemoUser checks if a user is the demo account
+func (s *DemoService) IsDemoUser(ctx context.Context, userID uuid.UUID) bool {
+	if !s.Enabled() {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The account may be registered after startup, so a failed lookup is retried later
+	if s.userID == uuid.Nil && time.Since(s.lookedUpAt) >= demoLookupRetry {
+		s.lookedUpAt = time.Now()
+		if user, err := s.userRepo.GetByEmail(ctx, s.email); err == nil && user != nil {
+			s.userID = user.ID
+		}
+	}
+
+	return s.userID != uuid.Nil && s.userID == userID
+}
+
+// SimulateMarkAsWatered returns the plant as it would look after watering without saving anything
+func (s *DemoService) SimulateMarkAsWatered(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) (*models.Plant, error) {
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("plant not found: %w", err)
+	}
+
+	// Keep the location of plants already in the collection
+	if userPlant, err := s.plantRepo.GetUserPlant(ctx, userID, plantID); err == nil {
+		plant.Location = userPlant.Location
+	}
+
+	isFavorite, err := s.plantRepo.IsFavorite(ctx, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if plant is favorite: %w", err)
+	}
+	plant.IsFavorite = isFavorite
+
+	now := time.Now()
+	plant.LastWatered = &now
+	if plant.CareInstructions.WateringFrequency > 0 {
+		next := now.AddDate(0, 0, plant.CareInstructions.WateringFrequency)
+		plant.NextWatering = &next
+	}
+
+	return plant, nil
+}
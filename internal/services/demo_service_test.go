@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestDemoService_IsDemoUser tests that only the configured account is the demo account
+func TestDemoService_IsDemoUser(t *testing.T) {
+	ctx := context.Background()
+	demoID := uuid.New()
+
+	// Disabled without an email
+	disabled := NewDemoService(new(MockUserRepository), new(MockPlantRepository), "")
+	assert.False(t, disabled.Enabled())
+	assert.False(t, disabled.IsDemoUser(ctx, demoID))
+
+	mockUserRepo := new(MockUserRepository)
+	service := NewDemoService(mockUserRepo, new(MockPlantRepository), "demo@planter.app")
+	mockUserRepo.On("GetByEmail", ctx, "demo@planter.app").Return(&models.User{ID: demoID}, nil).Once()
+
+	assert.True(t, service.Enabled())
+	assert.True(t, service.IsDemoUser(ctx, demoID))
+	assert.False(t, service.IsDemoUser(ctx, uuid.New()))
+
+	// The account is looked up once
+	mockUserRepo.AssertNumberOfCalls(t, "GetByEmail", 1)
+}
+
+// TestDemoService_IsDemoUser_NotRegistered tests that a missing demo account is not looked up on every request
+func TestDemoService_IsDemoUser_NotRegistered(t *testing.T) {
+	ctx := context.Background()
+	mockUserRepo := new(MockUserRepository)
+	service := NewDemoService(mockUserRepo, new(MockPlantRepository), "demo@planter.app")
+	mockUserRepo.On("GetByEmail", ctx, "demo@planter.app").Return(nil, fmt.Errorf("user not found"))
+
+	assert.False(t, service.IsDemoUser(ctx, uuid.New()))
+	assert.False(t, service.IsDemoUser(ctx, uuid.New()))
+	mockUserRepo.AssertNumberOfCalls(t, "GetByEmail", 1)
+}
+
+// TestDemoService_SimulateMarkAsWatered tests that watering is simulated without writing
+func TestDemoService_SimulateMarkAsWatered(t *testing.T) {
+	ctx := context.Background()
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewDemoService(new(MockUserRepository), mockPlantRepo, "demo@planter.app")
+
+	userID := uuid.New()
+	plantID := uuid.New()
+	location := "Kitchen"
+	mockPlantRepo.On("GetByID", ctx, plantID).Return(&models.Plant{
+		ID:               plantID,
+		CareInstructions: models.CareInstructions{WateringFrequency: 7},
+	}, nil)
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{Location: &location}, nil)
+	mockPlantRepo.On("IsFavorite", ctx, userID, plantID).Return(true, nil)
+
+	plant, err := service.SimulateMarkAsWatered(ctx, userID, plantID)
+	assert.NoError(t, err)
+	assert.Equal(t, &location, plant.Location)
+	assert.True(t, plant.IsFavorite)
+	assert.WithinDuration(t, time.Now(), *plant.LastWatered, time.Minute)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 7), *plant.NextWatering, time.Minute)
+	mockPlantRepo.AssertNotCalled(t, "MarkAsWatered", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrDiagnosisUnavailable is returned when no vision provider is configured
+	ErrDiagnosisUnavailable = errors.New("photo diagnosis is not available")
+
+	// ErrUnsupportedDiagnosisImage is returned when the uploaded photo is not a JPEG or PNG image
+	ErrUnsupportedDiagnosisImage = errors.New("photo must be a JPEG or PNG image")
+)
+
+const (
+	// maxDiagnosisFindings is the number of most likely conditions kept per diagnosis
+	maxDiagnosisFindings = 5
+
+	// minDiagnosisConfidence is the confidence below which conditions are dropped
+	minDiagnosisConfidence = 0.05
+
+	// diagnosisHistoryLimit is the number of past diagnoses returned per plant
+	diagnosisHistoryLimit = 20
+)
+
+// DiagnosisProvider detects plant conditions on a photo
+type DiagnosisProvider interface {
+	// Name returns the provider name stored with diagnoses
+	Name() string
+
+	// Diagnose returns the conditions detected on a photo with their confidence
+	Diagnose(ctx context.Context, image []byte, contentType string) ([]*models.DiagnosisFinding, error)
+}
+
+// DiagnosisService handles plant diagnosis from photos
+type DiagnosisService struct {
+	diagnosisRepo repository.DiagnosisRepository
+	plantRepo     repository.PlantRepository
+	provider      DiagnosisProvider
+	recorder      PlantEventRecorder
+}
+
+// NewDiagnosisService creates a new diagnosis service. Diagnosis is unavailable when provider is nil.
+func NewDiagnosisService(
+	diagnosisRepo repository.DiagnosisRepository,
+	plantRepo repository.PlantRepository,
+	provider DiagnosisProvider,
+) *DiagnosisService {
+	return &DiagnosisService{
+		diagnosisRepo: diagnosisRepo,
+		plantRepo:     plantRepo,
+		provider:      provider,
+	}
+}
+
+// SetPlantEventRecorder sets the recorder diagnosed photos are added to the plants' event streams with
+func (s *DiagnosisService) SetPlantEventRecorder(recorder PlantEventRecorder) {
+	s.recorder = recorder
+}
+
+// Diagnose detects the conditions of a plant in the user's collection on a photo and saves the diagnosis
+func (s *DiagnosisService) Diagnose(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, image []byte) (*models.PlantDiagnosis, error) {
+	if s.provider == nil {
+		return nil, ErrDiagnosisUnavailable
+	}
+
+	// Check the photo format
+	contentType := http.DetectContentType(image)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return nil, ErrUnsupportedDiagnosisImage
+	}
+
+	// Check if the user owns the plant
+	if _, err := s.plantRepo.GetUserPlant(ctx, userID, plantID); err != nil {
+		return nil, fmt.Errorf("plant not in user's collection: %w", err)
+	}
+
+	findings, err := s.provider.Diagnose(ctx, image, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diagnose plant: %w", err)
+	}
+
+	diagnosis := &models.PlantDiagnosis{
+		UserID:   userID,
+		PlantID:  plantID,
+		Provider: s.provider.Name(),
+		Findings: topDiagnosisFindings(findings),
+	}
+	if err := s.diagnosisRepo.Create(ctx, diagnosis); err != nil {
+		return nil, fmt.Errorf("failed to save diagnosis: %w", err)
+	}
+	recordPlantEvent(ctx, s.recorder, userID, plantID, models.PlantEventTypePhotoAdded, models.PlantEventPayload{
+		"diagnosisId": diagnosis.ID.String(),
+	})
+	return diagnosis, nil
+}
+
+// GetDiagnoses gets the diagnosis history of a plant in the user's collection
+func (s *DiagnosisService) GetDiagnoses(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.PlantDiagnosis, error) {
+	diagnoses, err := s.diagnosisRepo.GetByUserPlant(ctx, userID, plantID, diagnosisHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diagnoses: %w", err)
+	}
+	return diagnoses, nil
+}
+
+// topDiagnosisFindings keeps the most likely conditions, merging duplicates and dropping unlikely ones
+func topDiagnosisFindings(findings []*models.DiagnosisFinding) []*models.DiagnosisFinding {
+	byCondition := make(map[string]*models.DiagnosisFinding, len(findings))
+	for _, finding := range findings {
+		if finding.Condition == "" || finding.Confidence < minDiagnosisConfidence {
+			continue
+		}
+		if existing, ok := byCondition[finding.Condition]; ok && existing.Confidence >= finding.Confidence {
+			continue
+		}
+		byCondition[finding.Condition] = &models.DiagnosisFinding{
+			Condition:  finding.Condition,
+			Confidence: math.Round(math.Min(finding.Confidence, 1)*1000) / 1000,
+		}
+	}
+
+	result := make([]*models.DiagnosisFinding, 0, len(byCondition))
+	for _, finding := range byCondition {
+		result = append(result, finding)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Confidence != result[j].Confidence {
+			return result[i].Confidence > result[j].Confidence
+		}
+		return result[i].Condition < result[j].Condition
+	})
+
+	if len(result) > maxDiagnosisFindings {
+		result = result[:maxDiagnosisFindings]
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// pngHeader is enough of a PNG file for content type detection
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// MockDiagnosisRepository is a mock implementation of the DiagnosisRepository interface
+type MockDiagnosisRepository struct {
+	mock.Mock
+}
+
+func (m *MockDiagnosisRepository) Create(ctx context.Context, diagnosis *models.PlantDiagnosis) error {
+	args := m.Called(ctx, diagnosis)
+	return args.Error(0)
+}
+
+func (m *MockDiagnosisRepository) GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, limit int) ([]*models.PlantDiagnosis, error) {
+	args := m.Called(ctx, userID, plantID, limit)
+	return args.Get(0).([]*models.PlantDiagnosis), args.Error(1)
+}
+
+// MockDiagnosisProvider is a mock implementation of the DiagnosisProvider interface
+type MockDiagnosisProvider struct {
+	mock.Mock
+}
+
+func (m *MockDiagnosisProvider) Name() string {
+	return "mock"
+}
+
+func (m *MockDiagnosisProvider) Diagnose(ctx context.Context, image []byte, contentType string) ([]*models.DiagnosisFinding, error) {
+	args := m.Called(ctx, image, contentType)
+	return args.Get(0).([]*models.DiagnosisFinding), args.Error(1)
+}
+
+// TestDiagnosisService_Diagnose tests that the most likely findings are saved
+func TestDiagnosisService_Diagnose(t *testing.T) {
+	mockDiagnosisRepo := new(MockDiagnosisRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockProvider := new(MockDiagnosisProvider)
+	service := NewDiagnosisService(mockDiagnosisRepo, mockPlantRepo, mockProvider)
+	ctx := context.Background()
+	userID := uuid.New()
+	plantID := uuid.New()
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID}, nil)
+	mockProvider.On("Diagnose", ctx, pngHeader, "image/png").Return([]*models.DiagnosisFinding{
+		{Condition: "healthy", Confidence: 0.02},
+		{Condition: "overwatering", Confidence: 0.61},
+		{Condition: "spider_mites", Confidence: 0.12},
+		{Condition: "overwatering", Confidence: 0.4},
+	}, nil)
+	mockDiagnosisRepo.On("Create", ctx, mock.AnythingOfType("*models.PlantDiagnosis")).Return(nil)
+
+	diagnosis, err := service.Diagnose(ctx, userID, plantID, pngHeader)
+	assert.NoError(t, err)
+	assert.Equal(t, "mock", diagnosis.Provider)
+	assert.Equal(t, []*models.DiagnosisFinding{
+		{Condition: "overwatering", Confidence: 0.61},
+		{Condition: "spider_mites", Confidence: 0.12},
+	}, diagnosis.Findings)
+	mockDiagnosisRepo.AssertExpectations(t)
+}
+
+// TestDiagnosisService_Diagnose_UnsupportedImage tests that non-image uploads are rejected before calling the provider
+func TestDiagnosisService_Diagnose_UnsupportedImage(t *testing.T) {
+	mockProvider := new(MockDiagnosisProvider)
+	service := NewDiagnosisService(new(MockDiagnosisRepository), new(MockPlantRepository), mockProvider)
+
+	_, err := service.Diagnose(context.Background(), uuid.New(), uuid.New(), []byte("not an image"))
+	assert.ErrorIs(t, err, ErrUnsupportedDiagnosisImage)
+	mockProvider.AssertNotCalled(t, "Diagnose", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestDiagnosisService_Diagnose_Unavailable tests that diagnosis fails without a provider
+func TestDiagnosisService_Diagnose_Unavailable(t *testing.T) {
+	service := NewDiagnosisService(new(MockDiagnosisRepository), new(MockPlantRepository), nil)
+
+	_, err := service.Diagnose(context.Background(), uuid.New(), uuid.New(), pngHeader)
+	assert.ErrorIs(t, err, ErrDiagnosisUnavailable)
+}
+
+// TestDiagnosisService_Diagnose_NotOwned tests that only plants in the user's collection can be diagnosed
+func TestDiagnosisService_Diagnose_NotOwned(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockProvider := new(MockDiagnosisProvider)
+	service := NewDiagnosisService(new(MockDiagnosisRepository), mockPlantRepo, mockProvider)
+	ctx := context.Background()
+	userID := uuid.New()
+	plantID := uuid.New()
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(nil, fmt.Errorf("user plant not found"))
+
+	_, err := service.Diagnose(ctx, userID, plantID, pngHeader)
+	assert.Error(t, err)
+	mockProvider.AssertNotCalled(t, "Diagnose", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestYandexVisionProvider_Diagnose tests that classification properties are returned as findings
+func TestYandexVisionProvider_Diagnose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Api-Key test-key", r.Header.Get("Authorization"))
+
+		var req yandexVisionRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "folder", req.FolderID)
+		assert.Equal(t, "plant-conditions", req.AnalyzeSpecs[0].Features[0].ClassificationConfig.Model)
+
+		w.Write([]byte(`{"results":[{"results":[{"classification":{"properties":[
+			{"name":"overwatering","probability":0.7},{"name":"healthy","probability":0.3}]}}]}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewYandexVisionProvider("test-key", "folder", "plant-conditions")
+	provider.endpoint = server.URL
+
+	findings, err := provider.Diagnose(context.Background(), pngHeader, "image/png")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.DiagnosisFinding{
+		{Condition: "overwatering", Confidence: 0.7},
+		{Condition: "healthy", Confidence: 0.3},
+	}, findings)
+}
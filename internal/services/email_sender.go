@@ -0,0 +1,123 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// EmailType identifies the template of an email
+type EmailType string
+
+const (
+	// EmailTypeWateringDigest lists the plants a user needs to water today; its data is a WateringDigestEmail
+	EmailTypeWateringDigest EmailType = "WATERING_DIGEST"
+)
+
+//go:embed templates/emails.json
+var emailTemplatesJSON []byte
+
+// emailTemplates holds the templates of every email type by language
+var emailTemplates = mustLoadEmailTemplates(emailTemplatesJSON)
+
+// emailTemplate is the subject and body template of an email in a language
+type emailTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// WateringDigestEmail is the data of a watering reminder email
+type WateringDigestEmail struct {
+	Name   string
+	Plants []WateringDigestEmailPlant
+}
+
+// WateringDigestEmailPlant is a plant listed in a watering reminder email
+type WateringDigestEmailPlant struct {
+	Name      string
+	Location  string
+	Overdue   bool   // the plant needed water before today
+	DueDate   string // formatted in the recipient's language
+	ActionURL string // link marking the plant watered; empty when notification actions are disabled
+}
+
+// EmailSender renders emails from their templates in the recipient's language and sends them
+type EmailSender struct {
+	mailer Mailer
+}
+
+// NewEmailSender creates a new email sender
+func NewEmailSender(mailer Mailer) *EmailSender {
+	return &EmailSender{
+		mailer: mailer,
+	}
+}
+
+// Send renders an email of a type in a language and sends it. Unsupported languages fall back to Russian.
+func (s *EmailSender) Send(ctx context.Context, to string, emailType EmailType, language models.Language, data interface{}) error {
+	subject, body, err := renderEmail(emailType, language, data)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, to, subject, body)
+}
+
+// renderEmail renders the subject and body of an email of a type in a language
+func renderEmail(emailType EmailType, language models.Language, data interface{}) (string, string, error) {
+	byLanguage, ok := emailTemplates[emailType]
+	if !ok {
+		return "", "", fmt.Errorf("unknown email type: %s", emailType)
+	}
+	tmpl, ok := byLanguage[language]
+	if !ok {
+		tmpl = byLanguage[models.LanguageRussian]
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s email subject: %w", emailType, err)
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s email body: %w", emailType, err)
+	}
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
+
+// mustLoadEmailTemplates parses the email templates and panics if they are invalid or miss Russian,
+// the language others fall back to
+func mustLoadEmailTemplates(data []byte) map[EmailType]map[models.Language]*emailTemplate {
+	var sources map[EmailType]map[models.Language]struct {
+		Subject string `json:"subject"`
+		Body    string `json:"body"`
+	}
+	if err := json.Unmarshal(data, &sources); err != nil {
+		panic(fmt.Sprintf("invalid email templates: %v", err))
+	}
+
+	templates := make(map[EmailType]map[models.Language]*emailTemplate, len(sources))
+	for emailType, byLanguage := range sources {
+		if _, ok := byLanguage[models.LanguageRussian]; !ok {
+			panic(fmt.Sprintf("email template %s has no Russian version", emailType))
+		}
+		templates[emailType] = make(map[models.Language]*emailTemplate, len(byLanguage))
+		for language, source := range byLanguage {
+			name := string(emailType) + "/" + string(language)
+			subject, err := template.New(name + "/subject").Parse(source.Subject)
+			if err != nil {
+				panic(fmt.Sprintf("invalid %s email subject: %v", name, err))
+			}
+			body, err := template.New(name + "/body").Parse(source.Body)
+			if err != nil {
+				panic(fmt.Sprintf("invalid %s email body: %v", name, err))
+			}
+			templates[emailType][language] = &emailTemplate{subject: subject, body: body}
+		}
+	}
+	return templates
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRenderEmail_WateringDigest tests that the watering reminder email names a single plant in its
+// subject, lists every plant in its body and falls back to Russian
+func TestRenderEmail_WateringDigest(t *testing.T) {
+	single := WateringDigestEmail{
+		Name:   "Anna",
+		Plants: []WateringDigestEmailPlant{{Name: "Monstera", Location: "Kitchen"}},
+	}
+	several := WateringDigestEmail{
+		Name: "Anna",
+		Plants: []WateringDigestEmailPlant{
+			{Name: "Monstera"},
+			{Name: "Ficus", Overdue: true, DueDate: "08.05.2024"},
+		},
+	}
+
+	subject, body, err := renderEmail(EmailTypeWateringDigest, models.LanguageEnglish, single)
+	assert.NoError(t, err)
+	assert.Equal(t, "Time to water your Monstera", subject)
+	assert.Contains(t, body, "Hello Anna,")
+	assert.Contains(t, body, "- Monstera (Kitchen)\n")
+
+	subject, body, err = renderEmail(EmailTypeWateringDigest, models.LanguageRussian, several)
+	assert.NoError(t, err)
+	assert.Equal(t, "Пора полить ваши растения", subject)
+	assert.Contains(t, body, "— Monstera\n")
+	assert.Contains(t, body, "— Ficus, полив нужен с 08.05.2024")
+
+	subject, _, err = renderEmail(EmailTypeWateringDigest, models.Language("GERMAN"), single)
+	assert.NoError(t, err)
+	assert.Equal(t, "Пора полить растение Monstera", subject)
+
+	_, _, err = renderEmail(EmailType("UNKNOWN"), models.LanguageEnglish, single)
+	assert.Error(t, err)
+}
+
+// TestEmailSender_Send tests that a rendered email is sent through the mailer
+func TestEmailSender_Send(t *testing.T) {
+	mailer := &capturingMailer{}
+	sender := NewEmailSender(mailer)
+
+	err := sender.Send(context.Background(), "anna@example.com", EmailTypeWateringDigest, models.LanguageEnglish, WateringDigestEmail{
+		Name:   "Anna",
+		Plants: []WateringDigestEmailPlant{{Name: "Ficus"}},
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, mailer.sent, 1) {
+		assert.Equal(t, "anna@example.com", mailer.sent[0].to)
+		assert.Equal(t, "Time to water your Ficus", mailer.sent[0].subject)
+	}
+}
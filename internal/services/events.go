@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/anpanovv/planter/internal/events"
+)
+
+// publishEvent publishes a domain event; failures are only logged so they never fail the operation that produced the event
+func publishEvent(ctx context.Context, publisher events.Publisher, event events.Event) {
+	if err := publisher.Publish(ctx, event); err != nil {
+		log.Printf("Failed to publish event %s: %v", event.EventName(), err)
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	// flightLockTTL is how long a call holds the lock of its key across instances; a call
+	// outliving it no longer keeps other instances waiting
+	flightLockTTL = 2 * time.Minute
+
+	// flightLockPoll is how often a call waiting for the lock of another instance checks it
+	flightLockPoll = 200 * time.Millisecond
+)
+
+// plantsCall is an in-flight or completed call producing a list of plants
+type plantsCall struct {
+	done     chan struct{}
+	plants   []*models.Plant
+	warnings []models.Warning
+	err      error
+}
+
+// plantsFlightGroup deduplicates concurrent calls for the same key so that
+// only one of them does the work and the others wait for its result
+type plantsFlightGroup struct {
+	mu    sync.Mutex
+	calls map[uuid.UUID]*plantsCall
+
+	// Calls for the same key on other instances are serialized through Redis locks when set
+	locks      *redis.Client
+	lockPrefix string
+}
+
+// newPlantsFlightGroup creates a new flight group
+func newPlantsFlightGroup() *plantsFlightGroup {
+	return &plantsFlightGroup{
+		calls: make(map[uuid.UUID]*plantsCall),
+	}
+}
+
+// SetLocker serializes calls for the same key across instances with Redis locks stored under
+// keys starting with prefix. A call waits for the call of another instance to finish and then
+// runs fn itself, so fn should pick up the result the other call saved.
+func (g *plantsFlightGroup) SetLocker(client *redis.Client, prefix string) {
+	g.locks = client
+	g.lockPrefix = prefix
+}
+
+// Do runs fn for key unless a call for the same key is already running, in which
+// case it waits for that call and returns its result. fn receives a context that
+// is not cancelled when the first caller goes away, so a client retry can still
+// pick up the result of the original request.
+func (g *plantsFlightGroup) Do(
+	ctx context.Context,
+	key uuid.UUID,
+	fn func(ctx context.Context) ([]*models.Plant, []models.Warning, error),
+) ([]*models.Plant, []models.Warning, error) {
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		call = &plantsCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(context.WithoutCancel(ctx), key, call, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.plants, call.warnings, call.err
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+// run executes fn and publishes its result to all waiters
+func (g *plantsFlightGroup) run(
+	ctx context.Context,
+	key uuid.UUID,
+	call *plantsCall,
+	fn func(ctx context.Context) ([]*models.Plant, []models.Warning, error),
+) {
+	lock, err := g.lock(ctx, key)
+	if err != nil {
+		call.err = err
+	} else {
+		call.plants, call.warnings, call.err = fn(ctx)
+		if lock != nil {
+			if err := lock.Release(ctx); err != nil {
+				log.Printf("Failed to release flight lock of %s: %v", key, err)
+			}
+		}
+	}
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	close(call.done)
+}
+
+// lock waits for the Redis lock of a key when a locker is set. When Redis fails, the call runs
+// without the lock rather than not at all.
+func (g *plantsFlightGroup) lock(ctx context.Context, key uuid.UUID) (*redis.Lock, error) {
+	if g.locks == nil {
+		return nil, nil
+	}
+
+	for {
+		lock, err := g.locks.Lock(ctx, g.lockPrefix+key.String(), flightLockTTL)
+		if err == nil {
+			return lock, nil
+		}
+		if !errors.Is(err, redis.ErrLockHeld) {
+			log.Printf("Failed to take flight lock of %s: %v", key, err)
+			return nil, nil
+		}
+
+		select {
+		case <-time.After(flightLockPoll):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
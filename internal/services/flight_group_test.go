@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/redis"
+	"github.com/anpanovv/planter/internal/redis/redistest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPlantsFlightGroup_Do tests that concurrent calls for the same key share one run
+func TestPlantsFlightGroup_Do(t *testing.T) {
+	group := newPlantsFlightGroup()
+	key := uuid.New()
+	plants := []*models.Plant{{ID: uuid.New(), Name: "Monstera"}}
+
+	var runs int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fn := func(ctx context.Context) ([]*models.Plant, []models.Warning, error) {
+		if atomic.AddInt32(&runs, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return plants, nil, nil
+	}
+
+	// Start the first caller and wait until it is generating
+	var wg sync.WaitGroup
+	results := make([][]*models.Plant, 3)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _, _ = group.Do(context.Background(), key, fn)
+	}()
+	<-started
+
+	// Retries arrive while the first call is still running
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, _ = group.Do(context.Background(), key, fn)
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+	for _, result := range results {
+		assert.Equal(t, plants, result)
+	}
+}
+
+// TestPlantsFlightGroup_Do_CallerCancelled tests that a cancelled caller does not abort the shared run
+func TestPlantsFlightGroup_Do_CallerCancelled(t *testing.T) {
+	group := newPlantsFlightGroup()
+	key := uuid.New()
+	plants := []*models.Plant{{ID: uuid.New(), Name: "Ficus"}}
+
+	release := make(chan struct{})
+	fn := func(ctx context.Context) ([]*models.Plant, []models.Warning, error) {
+		<-release
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return plants, nil, nil
+	}
+
+	// The first caller gives up before generation finishes
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := group.Do(ctx, key, fn)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// A retry joins the still-running call and gets its result
+	done := make(chan []*models.Plant)
+	go func() {
+		result, _, _ := group.Do(context.Background(), key, fn)
+		done <- result
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	assert.Equal(t, plants, <-done)
+}
+
+// TestPlantsFlightGroup_Do_AcrossInstances tests that groups sharing Redis run the calls for the
+// same key one after another
+func TestPlantsFlightGroup_Do_AcrossInstances(t *testing.T) {
+	server := redistest.NewServer(t)
+	first, second := newPlantsFlightGroup(), newPlantsFlightGroup()
+	first.SetLocker(redis.New(redis.Options{Addr: server.Addr()}), "flight:")
+	second.SetLocker(redis.New(redis.Options{Addr: server.Addr()}), "flight:")
+	key := uuid.New()
+
+	var running, overlaps int32
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	fn := func(ctx context.Context) ([]*models.Plant, []models.Warning, error) {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		started <- struct{}{}
+		<-release
+		atomic.AddInt32(&running, -1)
+		return nil, nil, nil
+	}
+
+	var wg sync.WaitGroup
+	for _, group := range []*plantsFlightGroup{first, second} {
+		wg.Add(1)
+		go func(group *plantsFlightGroup) {
+			defer wg.Done()
+			_, _, err := group.Do(context.Background(), key, fn)
+			assert.NoError(t, err)
+		}(group)
+	}
+
+	// The second call waits for the lock of the first one
+	<-started
+	select {
+	case <-started:
+		t.Fatal("both instances ran the call at the same time")
+	case <-time.After(3 * flightLockPoll):
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Len(t, started, 1)
+	assert.Zero(t, atomic.LoadInt32(&overlaps))
+	assert.Zero(t, server.Keys())
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrCannotFollowSelf is returned when a user tries to follow themselves
+var ErrCannotFollowSelf = errors.New("users cannot follow themselves")
+
+// FollowService handles the users a user follows, whose quiz scores they compare theirs with
+type FollowService struct {
+	followRepo repository.FollowRepository
+	userRepo   repository.UserRepository
+}
+
+// NewFollowService creates a new follow service
+func NewFollowService(followRepo repository.FollowRepository, userRepo repository.UserRepository) *FollowService {
+	return &FollowService{
+		followRepo: followRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// Follow makes a user follow another user
+func (s *FollowService) Follow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	if followerID == followeeID {
+		return ErrCannotFollowSelf
+	}
+	if _, err := s.userRepo.GetByID(ctx, followeeID); err != nil {
+		return err
+	}
+	return s.followRepo.Follow(ctx, followerID, followeeID)
+}
+
+// Unfollow makes a user stop following another user
+func (s *FollowService) Unfollow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	unfollowed, err := s.followRepo.Unfollow(ctx, followerID, followeeID)
+	if err != nil {
+		return err
+	}
+	if !unfollowed {
+		return fmt.Errorf("user not followed: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// GetFollowing gets the users a user follows, by name
+func (s *FollowService) GetFollowing(ctx context.Context, userID uuid.UUID) ([]*models.FollowedUser, error) {
+	return s.followRepo.ListFollowing(ctx, userID)
+}
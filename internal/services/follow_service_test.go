@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFollowRepository is a mock implementation of the FollowRepository interface
+type MockFollowRepository struct {
+	mock.Mock
+}
+
+func (m *MockFollowRepository) Follow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) error {
+	args := m.Called(ctx, followerID, followeeID)
+	return args.Error(0)
+}
+
+func (m *MockFollowRepository) Unfollow(ctx context.Context, followerID uuid.UUID, followeeID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, followerID, followeeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowRepository) ListFollowing(ctx context.Context, userID uuid.UUID) ([]*models.FollowedUser, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.FollowedUser), args.Error(1)
+}
+
+// TestFollowService_Follow tests that users cannot follow themselves or users that do not exist
+func TestFollowService_Follow(t *testing.T) {
+	mockFollowRepo := new(MockFollowRepository)
+	mockUserRepo := new(MockUserRepository)
+	service := NewFollowService(mockFollowRepo, mockUserRepo)
+	followerID, followeeID, missingID := uuid.New(), uuid.New(), uuid.New()
+
+	mockUserRepo.On("GetByID", mock.Anything, followeeID).Return(&models.User{ID: followeeID}, nil)
+	mockUserRepo.On("GetByID", mock.Anything, missingID).Return(nil, fmt.Errorf("user not found: %w", sql.ErrNoRows))
+	mockFollowRepo.On("Follow", mock.Anything, followerID, followeeID).Return(nil).Once()
+
+	assert.NoError(t, service.Follow(context.Background(), followerID, followeeID))
+	assert.ErrorIs(t, service.Follow(context.Background(), followerID, followerID), ErrCannotFollowSelf)
+	assert.ErrorIs(t, service.Follow(context.Background(), followerID, missingID), sql.ErrNoRows)
+	mockFollowRepo.AssertExpectations(t)
+}
+
+// TestFollowService_Unfollow tests that unfollowing a user that is not followed is not found
+func TestFollowService_Unfollow(t *testing.T) {
+	mockFollowRepo := new(MockFollowRepository)
+	service := NewFollowService(mockFollowRepo, new(MockUserRepository))
+	followerID, followeeID := uuid.New(), uuid.New()
+
+	mockFollowRepo.On("Unfollow", mock.Anything, followerID, followeeID).Return(true, nil).Once()
+	mockFollowRepo.On("Unfollow", mock.Anything, followerID, followeeID).Return(false, nil).Once()
+
+	assert.NoError(t, service.Unfollow(context.Background(), followerID, followeeID))
+	assert.ErrorIs(t, service.Unfollow(context.Background(), followerID, followeeID), sql.ErrNoRows)
+	mockFollowRepo.AssertExpectations(t)
+}
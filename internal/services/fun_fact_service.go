@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// FunFactGenerator generates fun facts about a plant
+type FunFactGenerator interface {
+	GenerateFunFacts(ctx context.Context, plant *models.Plant, language models.Language) ([]string, error)
+}
+
+// FunFactService handles plant fun fact operations. Facts are generated once per
+// plant and language, stored as pending and only shown after admin approval.
+type FunFactService struct {
+	funFactRepo repository.FunFactRepository
+	plantRepo   repository.PlantRepository
+	generator   FunFactGenerator
+}
+
+// NewFunFactService creates a new fun fact service
+func NewFunFactService(
+	funFactRepo repository.FunFactRepository,
+	plantRepo repository.PlantRepository,
+	generator FunFactGenerator,
+) *FunFactService {
+	return &FunFactService{
+		funFactRepo: funFactRepo,
+		plantRepo:   plantRepo,
+		generator:   generator,
+	}
+}
+
+// GetFunFacts gets the approved fun facts of a plant
+func (s *FunFactService) GetFunFacts(ctx context.Context, plantID uuid.UUID, language models.Language) ([]*models.PlantFunFact, error) {
+	facts, err := s.funFactRepo.GetByPlant(ctx, plantID, language, models.FunFactStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fun facts: %w", err)
+	}
+	return facts, nil
+}
+
+// GenerateFunFacts generates fun facts for a plant and stores them for review.
+// Generation runs only once per plant and language.
+func (s *FunFactService) GenerateFunFacts(ctx context.Context, plantID uuid.UUID, language models.Language) ([]*models.PlantFunFact, error) {
+	// Facts are generated only once
+	count, err := s.funFactRepo.CountByPlant(ctx, plantID, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count fun facts: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("fun facts already generated for this plant")
+	}
+
+	// Get the plant
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant: %w", err)
+	}
+
+	// Generate the facts
+	texts, err := s.generator.GenerateFunFacts(ctx, plant, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate fun facts: %w", err)
+	}
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no fun facts generated")
+	}
+
+	// Store them for review
+	facts := make([]*models.PlantFunFact, 0, len(texts))
+	for _, text := range texts {
+		facts = append(facts, &models.PlantFunFact{
+			PlantID:  plantID,
+			Language: language,
+			Fact:     text,
+			Status:   models.FunFactStatusPending,
+		})
+	}
+
+	err = s.funFactRepo.CreateBatch(ctx, facts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save fun facts: %w", err)
+	}
+
+	return facts, nil
+}
+
+// GetPendingFunFacts gets all fun facts awaiting review
+func (s *FunFactService) GetPendingFunFacts(ctx context.Context) ([]*models.PlantFunFact, error) {
+	facts, err := s.funFactRepo.GetPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending fun facts: %w", err)
+	}
+	return facts, nil
+}
+
+// ReviewFunFact approves or rejects a fun fact
+func (s *FunFactService) ReviewFunFact(ctx context.Context, factID uuid.UUID, status models.FunFactStatus) (*models.PlantFunFact, error) {
+	if status != models.FunFactStatusApproved && status != models.FunFactStatusRejected {
+		return nil, fmt.Errorf("invalid review status: %s", status)
+	}
+
+	fact, err := s.funFactRepo.UpdateStatus(ctx, factID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review fun fact: %w", err)
+	}
+	return fact, nil
+}
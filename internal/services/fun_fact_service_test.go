@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFunFactRepository is a mock implementation of the FunFactRepository interface
+type MockFunFactRepository struct {
+	mock.Mock
+}
+
+func (m *MockFunFactRepository) CreateBatch(ctx context.Context, facts []*models.PlantFunFact) error {
+	args := m.Called(ctx, facts)
+	return args.Error(0)
+}
+
+func (m *MockFunFactRepository) GetByPlant(ctx context.Context, plantID uuid.UUID, language models.Language, status models.FunFactStatus) ([]*models.PlantFunFact, error) {
+	args := m.Called(ctx, plantID, language, status)
+	return args.Get(0).([]*models.PlantFunFact), args.Error(1)
+}
+
+func (m *MockFunFactRepository) CountByPlant(ctx context.Context, plantID uuid.UUID, language models.Language) (int, error) {
+	args := m.Called(ctx, plantID, language)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockFunFactRepository) GetPending(ctx context.Context) ([]*models.PlantFunFact, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.PlantFunFact), args.Error(1)
+}
+
+func (m *MockFunFactRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.FunFactStatus) (*models.PlantFunFact, error) {
+	args := m.Called(ctx, id, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PlantFunFact), args.Error(1)
+}
+
+// MockFunFactGenerator is a mock implementation of the FunFactGenerator interface
+type MockFunFactGenerator struct {
+	mock.Mock
+}
+
+func (m *MockFunFactGenerator) GenerateFunFacts(ctx context.Context, plant *models.Plant, language models.Language) ([]string, error) {
+	args := m.Called(ctx, plant, language)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// TestFunFactService_GenerateFunFacts tests that generated facts are stored as pending
+func TestFunFactService_GenerateFunFacts(t *testing.T) {
+	mockFunFactRepo := new(MockFunFactRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockGenerator := new(MockFunFactGenerator)
+	service := NewFunFactService(mockFunFactRepo, mockPlantRepo, mockGenerator)
+
+	plant := &models.Plant{ID: uuid.New(), Name: "Монстера"}
+	texts := []string{"Факт 1", "Факт 2"}
+
+	mockFunFactRepo.On("CountByPlant", mock.Anything, plant.ID, models.LanguageRussian).Return(0, nil)
+	mockPlantRepo.On("GetByID", mock.Anything, plant.ID).Return(plant, nil)
+	mockGenerator.On("GenerateFunFacts", mock.Anything, plant, models.LanguageRussian).Return(texts, nil)
+	mockFunFactRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(facts []*models.PlantFunFact) bool {
+		return len(facts) == 2 && facts[0].Status == models.FunFactStatusPending && facts[1].Fact == "Факт 2"
+	})).Return(nil)
+
+	facts, err := service.GenerateFunFacts(context.Background(), plant.ID, models.LanguageRussian)
+
+	assert.NoError(t, err)
+	assert.Len(t, facts, 2)
+	mockFunFactRepo.AssertExpectations(t)
+	mockPlantRepo.AssertExpectations(t)
+	mockGenerator.AssertExpectations(t)
+}
+
+// TestFunFactService_GenerateFunFacts_AlreadyGenerated tests that facts are generated only once
+func TestFunFactService_GenerateFunFacts_AlreadyGenerated(t *testing.T) {
+	mockFunFactRepo := new(MockFunFactRepository)
+	mockGenerator := new(MockFunFactGenerator)
+	service := NewFunFactService(mockFunFactRepo, new(MockPlantRepository), mockGenerator)
+
+	plantID := uuid.New()
+	mockFunFactRepo.On("CountByPlant", mock.Anything, plantID, models.LanguageEnglish).Return(3, nil)
+
+	facts, err := service.GenerateFunFacts(context.Background(), plantID, models.LanguageEnglish)
+
+	assert.Error(t, err)
+	assert.Nil(t, facts)
+	mockGenerator.AssertNotCalled(t, "GenerateFunFacts", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestFunFactService_ReviewFunFact_InvalidStatus tests that only approve and reject are accepted
+func TestFunFactService_ReviewFunFact_InvalidStatus(t *testing.T) {
+	mockFunFactRepo := new(MockFunFactRepository)
+	service := NewFunFactService(mockFunFactRepo, new(MockPlantRepository), new(MockFunFactGenerator))
+
+	fact, err := service.ReviewFunFact(context.Background(), uuid.New(), models.FunFactStatusPending)
+
+	assert.Error(t, err)
+	assert.Nil(t, fact)
+	mockFunFactRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// gbifAPIURL is the base URL of the GBIF species and occurrence API
+const gbifAPIURL = "https://api.gbif.org/v1"
+
+// GBIFProvider looks up scientific synonyms and licensed photos of plants in the Global
+// Biodiversity Information Facility
+type GBIFProvider struct {
+	userAgent string
+	endpoint  string
+	client    *http.Client
+}
+
+// NewGBIFProvider creates a new GBIF enrichment provider
+func NewGBIFProvider(userAgent string) *GBIFProvider {
+	return &GBIFProvider{
+		userAgent: userAgent,
+		endpoint:  gbifAPIURL,
+		client: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// gbifMatchResponse represents the GBIF backbone taxon matching a name
+type gbifMatchResponse struct {
+	UsageKey         int    `json:"usageKey"`
+	AcceptedUsageKey int    `json:"acceptedUsageKey"` // set when the name is a synonym
+	MatchType        string `json:"matchType"`        // EXACT, FUZZY, HIGHERRANK or NONE
+}
+
+// gbifSynonymsResponse represents a page of the synonyms of a GBIF taxon
+type gbifSynonymsResponse struct {
+	Results []struct {
+		CanonicalName string `json:"canonicalName"`
+	} `json:"results"`
+}
+
+// gbifOccurrenceResponse represents a page of GBIF occurrences with their media
+type gbifOccurrenceResponse struct {
+	Results []struct {
+		Media []struct {
+			Type         string `json:"type"`
+			Identifier   string `json:"identifier"`
+			License      string `json:"license"`
+			Creator      string `json:"creator"`
+			RightsHolder string `json:"rightsHolder"`
+		} `json:"media"`
+	} `json:"results"`
+}
+
+// Name returns the source name stored with proposals
+func (p *GBIFProvider) Name() string {
+	return "gbif"
+}
+
+// Enrich returns the synonyms of the accepted taxon of the plant's scientific name and the first
+// occurrence photo with a reusable license. Names GBIF only matches approximately are skipped.
+func (p *GBIFProvider) Enrich(ctx context.Context, plant *models.Plant) (*models.PlantEnrichment, error) {
+	query := url.Values{}
+	query.Set("name", plant.ScientificName)
+	query.Set("kingdom", "Plantae")
+	query.Set("strict", "true")
+
+	var match gbifMatchResponse
+	if err := getEnrichmentJSON(ctx, p.client, p.endpoint+"/species/match?"+query.Encode(), p.userAgent, &match); err != nil {
+		return nil, fmt.Errorf("failed to match name: %w", err)
+	}
+	enrichment := &models.PlantEnrichment{}
+	if match.MatchType != "EXACT" || match.UsageKey == 0 {
+		return enrichment, nil
+	}
+	taxonKey := strconv.Itoa(match.UsageKey)
+	if match.AcceptedUsageKey != 0 {
+		taxonKey = strconv.Itoa(match.AcceptedUsageKey)
+	}
+
+	if len(plant.Synonyms) == 0 {
+		var synonyms gbifSynonymsResponse
+		if err := getEnrichmentJSON(ctx, p.client, p.endpoint+"/species/"+taxonKey+"/synonyms?limit=50", p.userAgent, &synonyms); err != nil {
+			return nil, fmt.Errorf("failed to get synonyms: %w", err)
+		}
+		for _, synonym := range synonyms.Results {
+			enrichment.Synonyms = append(enrichment.Synonyms, synonym.CanonicalName)
+		}
+	}
+
+	if plant.ImageURL == "" {
+		query := url.Values{}
+		query.Set("taxonKey", taxonKey)
+		query.Set("mediaType", "StillImage")
+		query.Set("limit", "20")
+
+		var occurrences gbifOccurrenceResponse
+		if err := getEnrichmentJSON(ctx, p.client, p.endpoint+"/occurrence/search?"+query.Encode(), p.userAgent, &occurrences); err != nil {
+			return nil, fmt.Errorf("failed to get occurrence photos: %w", err)
+		}
+	photos:
+		for _, occurrence := range occurrences.Results {
+			for _, media := range occurrence.Media {
+				if media.Type != "StillImage" || media.Identifier == "" || !reusableImageLicense(media.License) {
+					continue
+				}
+				attribution := media.Creator
+				if attribution == "" {
+					attribution = media.RightsHolder
+				}
+				enrichment.Image = &models.PlantImage{URL: media.Identifier, License: media.License, Attribution: attribution}
+				break photos
+			}
+		}
+	}
+	return enrichment, nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGBIFProvider_Enrich tests looking up the synonyms and a licensed photo of the accepted taxon,
+// and names that only match approximately
+func TestGBIFProvider_Enrich(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "planter-test", r.Header.Get("User-Agent"))
+		switch r.URL.Path {
+		case "/species/match":
+			if r.URL.Query().Get("name") == "Monstera deliciosa" {
+				w.Write([]byte(`{"usageKey":2868241,"matchType":"EXACT"}`))
+				return
+			}
+			w.Write([]byte(`{"usageKey":2868200,"matchType":"FUZZY"}`))
+		case "/species/2868241/synonyms":
+			w.Write([]byte(`{"results":[{"canonicalName":"Philodendron pertusum"},{"canonicalName":"Monstera tacanaensis"}]}`))
+		case "/occurrence/search":
+			assert.Equal(t, "2868241", r.URL.Query().Get("taxonKey"))
+			w.Write([]byte(`{"results":[
+				{"media":[{"type":"StillImage","identifier":"https://example.org/nc.jpg","license":"http://creativecommons.org/licenses/by-nc/4.0/"}]},
+				{"media":[{"type":"StillImage","identifier":"https://example.org/by.jpg","license":"http://creativecommons.org/licenses/by/4.0/","rightsHolder":"Anna"}]}
+			]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewGBIFProvider("planter-test")
+	provider.endpoint = server.URL
+
+	enrichment, err := provider.Enrich(context.Background(), &models.Plant{ScientificName: "Monstera deliciosa"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Philodendron pertusum", "Monstera tacanaensis"}, enrichment.Synonyms)
+	assert.Equal(t, &models.PlantImage{URL: "https://example.org/by.jpg", License: "http://creativecommons.org/licenses/by/4.0/", Attribution: "Anna"}, enrichment.Image)
+
+	enrichment, err = provider.Enrich(context.Background(), &models.Plant{ScientificName: "Monstera delicioza"})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.PlantEnrichment{}, enrichment)
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// updateGolden rewrites the golden files with the current output instead of comparing against them:
+// go test ./internal/services -run Golden -update
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// goldenLanguages are the languages the golden files cover, in file order
+var goldenLanguages = []models.Language{models.LanguageRussian, models.LanguageEnglish}
+
+// assertGolden compares text with the golden file testdata/golden/<name>.golden. A textual change
+// fails the test with the changed lines, so it is reviewed as a diff of the golden file.
+func assertGolden(t *testing.T, name string, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+	if string(want) != got {
+		t.Fatalf("%s differs from the golden file, run with -update if the change is intended:\n%s", path, goldenDiff(string(want), got))
+	}
+}
+
+// goldenDiff shows the lines between the common beginning and end of two texts, with a few lines
+// of context: removed lines start with "-", added lines with "+"
+func goldenDiff(want, got string) string {
+	const contextLines = 3
+
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	prefix := 0
+	for prefix < len(wantLines) && prefix < len(gotLines) && wantLines[prefix] == gotLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(wantLines)-prefix && suffix < len(gotLines)-prefix &&
+		wantLines[len(wantLines)-1-suffix] == gotLines[len(gotLines)-1-suffix] {
+		suffix++
+	}
+
+	var b strings.Builder
+	start := max(prefix-contextLines, 0)
+	fmt.Fprintf(&b, "@@ line %d @@\n", start+1)
+	for _, line := range wantLines[start:prefix] {
+		b.WriteString("  " + line + "\n")
+	}
+	for _, line := range wantLines[prefix : len(wantLines)-suffix] {
+		b.WriteString("- " + line + "\n")
+	}
+	for _, line := range gotLines[prefix : len(gotLines)-suffix] {
+		b.WriteString("+ " + line + "\n")
+	}
+	end := min(len(gotLines)-suffix+contextLines, len(gotLines))
+	for _, line := range gotLines[len(gotLines)-suffix : end] {
+		b.WriteString("  " + line + "\n")
+	}
+	return b.String()
+}
+
+// TestGolden_RecommendationPrompts renders the recommendation prompt for every combination of
+// questionnaire answers
+func TestGolden_RecommendationPrompts(t *testing.T) {
+	service := &RecommendationService{}
+	plants := parseTestCatalog()
+	location := "подоконник на кухне"
+	preferences := "без цветов, с крупными листьями"
+
+	var b strings.Builder
+	for _, sunlight := range []models.SunlightLevel{models.SunlightLevelLow, models.SunlightLevelMedium, models.SunlightLevelHigh} {
+		for _, petFriendly := range []bool{false, true} {
+			for careLevel := 1; careLevel <= 5; careLevel++ {
+				for _, withLocation := range []bool{false, true} {
+					for _, withPreferences := range []bool{false, true} {
+						questionnaire := &models.PlantQuestionnaire{
+							SunlightPreference: sunlight,
+							PetFriendly:        petFriendly,
+							CareLevel:          careLevel,
+						}
+						if withLocation {
+							questionnaire.PreferredLocation = &location
+						}
+						if withPreferences {
+							questionnaire.AdditionalPreferences = &preferences
+						}
+
+						fmt.Fprintf(&b, "### sunlight=%s petFriendly=%t careLevel=%d location=%t preferences=%t\n",
+							sunlight, petFriendly, careLevel, withLocation, withPreferences)
+						b.WriteString(service.preparePrompt(questionnaire, plants))
+						b.WriteString("\n\n")
+					}
+				}
+			}
+		}
+	}
+
+	// Custom result limits change the number of plants asked for
+	questionnaire := &models.PlantQuestionnaire{
+		SunlightPreference: models.SunlightLevelMedium,
+		CareLevel:          3,
+		ResultCount:        1,
+		MaxPerFamily:       1,
+	}
+	b.WriteString("### sunlight=MEDIUM petFriendly=false careLevel=3 resultCount=1 maxPerFamily=1\n")
+	b.WriteString(service.preparePrompt(questionnaire, plants))
+	b.WriteString("\n")
+
+	assertGolden(t, "recommendation_prompts", b.String())
+}
+
+// TestGolden_ChatSystemPrompts renders the chat system prompt in every language
+func TestGolden_ChatSystemPrompts(t *testing.T) {
+	var b strings.Builder
+	for _, language := range goldenLanguages {
+		fmt.Fprintf(&b, "### %s\n%s\n\n", language, chatSystemPrompt(language))
+	}
+	assertGolden(t, "chat_system_prompts", b.String())
+}
+
+// TestGolden_NotificationTemplates renders the built-in template of every notification type in
+// every language
+func TestGolden_NotificationTemplates(t *testing.T) {
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	mockTemplateRepo.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	service := NewNotificationTemplateService(mockTemplateRepo)
+
+	ctx := context.Background()
+	plant := &models.Plant{Name: "Монстера"}
+	location := "Гостиная"
+	dueDate := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	payload := models.NotificationPayload{"city": "Москва", "discount": 15, "rainfallMm": 6.5, "message": "Скидки на горшки до конца недели", "badge": "QUIZ_STREAK_7"}
+
+	var b strings.Builder
+	for _, definition := range NotificationTypes() {
+		for _, language := range goldenLanguages {
+			message, err := service.Render(ctx, definition.Type, language, plant, &location, &dueDate, payload)
+			if err != nil {
+				t.Fatalf("failed to render %s in %s: %v", definition.Type, language, err)
+			}
+			fmt.Fprintf(&b, "### %s %s\n%s\n\n", definition.Type, language, message)
+		}
+	}
+	assertGolden(t, "notification_templates", b.String())
+}
+
+// TestGolden_Emails renders every email in every language, with one and with several plants
+func TestGolden_Emails(t *testing.T) {
+	digests := map[string]WateringDigestEmail{
+		"one plant": {
+			Name:   "Анна",
+			Plants: []WateringDigestEmailPlant{{Name: "Монстера", Location: "Гостиная"}},
+		},
+		"several plants": {
+			Name: "Анна",
+			Plants: []WateringDigestEmailPlant{
+				{Name: "Монстера", Location: "Гостиная", ActionURL: "https://planter.example/actions/water?token=abc"},
+				{Name: "Фикус", Overdue: true, DueDate: "28.02.2024"},
+			},
+		},
+	}
+
+	var b strings.Builder
+	for _, variant := range []string{"one plant", "several plants"} {
+		for _, language := range goldenLanguages {
+			subject, body, err := renderEmail(EmailTypeWateringDigest, language, digests[variant])
+			if err != nil {
+				t.Fatalf("failed to render %s email in %s: %v", variant, language, err)
+			}
+			fmt.Fprintf(&b, "### %s %s, %s\nSubject: %s\n\n%s\n\n", EmailTypeWateringDigest, language, variant, subject, body)
+		}
+	}
+	assertGolden(t, "emails", b.String())
+}
+
+// TestGolden_QuizQuestions renders a question of every kind with all its options in every language
+func TestGolden_QuizQuestions(t *testing.T) {
+	questions := []*models.QuizQuestionFacts{
+		{PlantName: "Монстера", Kind: models.QuizQuestionKindWatering, Options: []string{"1", "3", "7", "14"}},
+		{PlantName: "Монстера", Kind: models.QuizQuestionKindSunlight, Options: quizLevels},
+		{PlantName: "Монстера", Kind: models.QuizQuestionKindHumidity, Options: quizLevels},
+		{PlantName: "Монстера", Kind: models.QuizQuestionKindPetFriendly, Options: []string{"true", "false"}},
+	}
+
+	var b strings.Builder
+	for _, question := range questions {
+		for _, language := range goldenLanguages {
+			rendered := renderQuizQuestion(question, language)
+			fmt.Fprintf(&b, "### %s %s\n%s\n", question.Kind, language, rendered.Text)
+			for _, option := range rendered.Options {
+				fmt.Fprintf(&b, "- %s\n", option)
+			}
+			b.WriteString("\n")
+		}
+	}
+	assertGolden(t, "quiz_questions", b.String())
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	// Homes and users name IANA timezones, which the slim images the API runs in do not ship
+	_ "time/tzdata"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ErrInvalidHome is returned for homes with an unknown timezone, half their coordinates, or outdoor
+// rooms but no coordinates to look their weather up at
+var ErrInvalidHome = errors.New("invalid home")
+
+// HomeService manages the homes of users who keep plants in several places, e.g. a flat and a dacha.
+// A user staying at one of their homes is reminded of the plants there and of the plants in no home;
+// the reminders of the plants in their other homes wait until they come back.
+type HomeService struct {
+	homeRepo repository.HomeRepository
+}
+
+// NewHomeService creates a new home service
+func NewHomeService(homeRepo repository.HomeRepository) *HomeService {
+	return &HomeService{
+		homeRepo: homeRepo,
+	}
+}
+
+// ListHomes gets the homes of a user by name
+func (s *HomeService) ListHomes(ctx context.Context, userID uuid.UUID) ([]*models.Home, error) {
+	return s.homeRepo.List(ctx, userID)
+}
+
+// GetHome gets a home of a user
+func (s *HomeService) GetHome(ctx context.Context, userID uuid.UUID, homeID uuid.UUID) (*models.Home, error) {
+	return s.homeRepo.GetByID(ctx, userID, homeID)
+}
+
+// CreateHome creates a home of a user
+func (s *HomeService) CreateHome(ctx context.Context, userID uuid.UUID, home *models.Home) (*models.Home, error) {
+	if err := normalizeHome(home); err != nil {
+		return nil, err
+	}
+	home.UserID = userID
+
+	if err := s.homeRepo.Create(ctx, home); err != nil {
+		return nil, err
+	}
+	return home, nil
+}
+
+// UpdateHome updates the name, timezone, coordinates and outdoor rooms of a home of a user
+func (s *HomeService) UpdateHome(ctx context.Context, userID uuid.UUID, homeID uuid.UUID, home *models.Home) (*models.Home, error) {
+	if err := normalizeHome(home); err != nil {
+		return nil, err
+	}
+	home.UserID = userID
+	home.ID = homeID
+
+	if err := s.homeRepo.Update(ctx, home); err != nil {
+		return nil, err
+	}
+	return s.homeRepo.GetByID(ctx, userID, homeID)
+}
+
+// DeleteHome deletes a home of a user; its plants stay in the collection without a home
+func (s *HomeService) DeleteHome(ctx context.Context, userID uuid.UUID, homeID uuid.UUID) error {
+	return s.homeRepo.Delete(ctx, userID, homeID)
+}
+
+// AssignPlants moves plants of a user's collection to a home of the user and returns the home with
+// its new plant count. Plants not in the collection are ignored.
+func (s *HomeService) AssignPlants(ctx context.Context, userID uuid.UUID, homeID uuid.UUID, plantIDs []uuid.UUID) (*models.Home, error) {
+	if _, err := s.homeRepo.GetByID(ctx, userID, homeID); err != nil {
+		return nil, err
+	}
+	if _, err := s.homeRepo.AssignPlants(ctx, userID, homeID, plantIDs); err != nil {
+		return nil, err
+	}
+	return s.homeRepo.GetByID(ctx, userID, homeID)
+}
+
+// UnassignPlant takes a plant of a user's collection out of a home
+func (s *HomeService) UnassignPlant(ctx context.Context, userID uuid.UUID, homeID uuid.UUID, plantID uuid.UUID) error {
+	return s.homeRepo.UnassignPlant(ctx, userID, homeID, plantID)
+}
+
+// SetCurrentHome marks the home a user is staying at and returns it; the next watering check reminds
+// the user of the plants there. A nil home returns nil and reminds the user of the plants in every home.
+func (s *HomeService) SetCurrentHome(ctx context.Context, userID uuid.UUID, homeID *uuid.UUID) (*models.Home, error) {
+	if err := s.homeRepo.SetCurrent(ctx, userID, homeID); err != nil {
+		return nil, err
+	}
+	if homeID == nil {
+		return nil, nil
+	}
+	return s.homeRepo.GetByID(ctx, userID, *homeID)
+}
+
+// normalizeHome trims the name and rooms of a home, drops repeated outdoor rooms and checks its
+// timezone and coordinates
+func normalizeHome(home *models.Home) error {
+	home.Name = strings.TrimSpace(home.Name)
+	home.Timezone = strings.TrimSpace(home.Timezone)
+	// LoadLocation takes an empty name for UTC and Local for the zone of the server
+	if _, err := time.LoadLocation(home.Timezone); err != nil || home.Timezone == "" || home.Timezone == "Local" {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidHome, home.Timezone)
+	}
+	if (home.Latitude == nil) != (home.Longitude == nil) {
+		return fmt.Errorf("%w: latitude and longitude must be given together", ErrInvalidHome)
+	}
+
+	rooms := pq.StringArray{}
+	seen := make(map[string]bool, len(home.OutdoorRooms))
+	for _, room := range home.OutdoorRooms {
+		room = strings.TrimSpace(room)
+		if seen[roomKey(room)] {
+			continue
+		}
+		seen[roomKey(room)] = true
+		rooms = append(rooms, room)
+	}
+	if len(rooms) > 0 && home.Latitude == nil {
+		return fmt.Errorf("%w: outdoor rooms need the coordinates of the home", ErrInvalidHome)
+	}
+	home.OutdoorRooms = rooms
+	return nil
+}
+
+// awayFromHome reports whether the owner of a plant is staying at another of their homes, so the
+// watering reminders of the plant wait until they come back
+func awayFromHome(userPlant *models.UserPlant) bool {
+	return userPlant.Home != nil && userPlant.UserCurrentHomeID != nil && *userPlant.UserCurrentHomeID != userPlant.Home.ID
+}
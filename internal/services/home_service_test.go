@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockHomeRepository is a mock implementation of the HomeRepository interface
+type MockHomeRepository struct {
+	mock.Mock
+}
+
+func (m *MockHomeRepository) List(ctx context.Context, userID uuid.UUID) ([]*models.Home, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Home), args.Error(1)
+}
+
+func (m *MockHomeRepository) GetByID(ctx context.Context, userID uuid.UUID, homeID uuid.UUID) (*models.Home, error) {
+	args := m.Called(ctx, userID, homeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Home), args.Error(1)
+}
+
+func (m *MockHomeRepository) Create(ctx context.Context, home *models.Home) error {
+	args := m.Called(ctx, home)
+	return args.Error(0)
+}
+
+func (m *MockHomeRepository) Update(ctx context.Context, home *models.Home) error {
+	args := m.Called(ctx, home)
+	return args.Error(0)
+}
+
+func (m *MockHomeRepository) Delete(ctx context.Context, userID uuid.UUID, homeID uuid.UUID) error {
+	args := m.Called(ctx, userID, homeID)
+	return args.Error(0)
+}
+
+func (m *MockHomeRepository) AssignPlants(ctx context.Context, userID uuid.UUID, homeID uuid.UUID, plantIDs []uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID, homeID, plantIDs)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHomeRepository) UnassignPlant(ctx context.Context, userID uuid.UUID, homeID uuid.UUID, plantID uuid.UUID) error {
+	args := m.Called(ctx, userID, homeID, plantID)
+	return args.Error(0)
+}
+
+func (m *MockHomeRepository) SetCurrent(ctx context.Context, userID uuid.UUID, homeID *uuid.UUID) error {
+	args := m.Called(ctx, userID, homeID)
+	return args.Error(0)
+}
+
+func TestHomeService_CreateHome(t *testing.T) {
+	latitude, longitude := 55.92, 37.82
+	tests := []struct {
+		name    string
+		home    models.Home
+		wantErr bool
+	}{
+		{name: "timezone only", home: models.Home{Name: "Flat", Timezone: "Europe/Moscow"}},
+		{name: "outdoor rooms", home: models.Home{Name: "Dacha", Timezone: "Europe/Moscow", Latitude: &latitude, Longitude: &longitude, OutdoorRooms: pq.StringArray{"Greenhouse"}}},
+		{name: "unknown timezone", home: models.Home{Name: "Flat", Timezone: "Moscow"}, wantErr: true},
+		{name: "server timezone", home: models.Home{Name: "Flat", Timezone: "Local"}, wantErr: true},
+		{name: "half the coordinates", home: models.Home{Name: "Dacha", Timezone: "UTC", Latitude: &latitude}, wantErr: true},
+		{name: "outdoor rooms without coordinates", home: models.Home{Name: "Dacha", Timezone: "UTC", OutdoorRooms: pq.StringArray{"Garden"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockHomeRepository)
+			service := NewHomeService(repo)
+			userID := uuid.New()
+			if !tt.wantErr {
+				repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+			}
+
+			home := tt.home
+			created, err := service.CreateHome(context.Background(), userID, &home)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidHome)
+				repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, userID, created.UserID)
+			assert.NotNil(t, created.OutdoorRooms)
+		})
+	}
+}
+
+func TestHomeService_CreateHome_NormalizesOutdoorRooms(t *testing.T) {
+	repo := new(MockHomeRepository)
+	service := NewHomeService(repo)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	latitude, longitude := 55.92, 37.82
+	home, err := service.CreateHome(context.Background(), uuid.New(), &models.Home{
+		Name:         "  Dacha ",
+		Timezone:     "Europe/Moscow",
+		Latitude:     &latitude,
+		Longitude:    &longitude,
+		OutdoorRooms: pq.StringArray{" Greenhouse", "greenhouse", "Garden"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Dacha", home.Name)
+	assert.Equal(t, pq.StringArray{"Greenhouse", "Garden"}, home.OutdoorRooms)
+	assert.True(t, home.HasOutdoorRoom("GARDEN "))
+	assert.False(t, home.HasOutdoorRoom("Kitchen"))
+}
+
+func TestHomeService_SetCurrentHome_Clear(t *testing.T) {
+	repo := new(MockHomeRepository)
+	service := NewHomeService(repo)
+	userID := uuid.New()
+	repo.On("SetCurrent", mock.Anything, userID, (*uuid.UUID)(nil)).Return(nil)
+
+	home, err := service.SetCurrentHome(context.Background(), userID, nil)
+	require.NoError(t, err)
+	assert.Nil(t, home)
+	repo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotificationService_CheckAndCreateWateringNotifications_AwayFromHome(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	service := NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(mockTemplateRepo))
+	now := time.Date(2024, time.June, 1, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	// The user stays at the flat: the plant at the dacha waits, the plant in no home is reminded of
+	ctx := context.Background()
+	userID := uuid.New()
+	flatID := uuid.New()
+	nextWatering := now.Add(-24 * time.Hour)
+	atDacha := &models.UserPlant{
+		UserID:            userID,
+		PlantID:           uuid.New(),
+		NextWatering:      &nextWatering,
+		Plant:             &models.Plant{Name: "Tomato"},
+		UserLanguage:      models.LanguageEnglish,
+		Home:              &models.Home{ID: uuid.New(), Name: "Dacha", Timezone: "Europe/Moscow"},
+		UserCurrentHomeID: &flatID,
+	}
+	inNoHome := &models.UserPlant{
+		UserID:            userID,
+		PlantID:           uuid.New(),
+		NextWatering:      &nextWatering,
+		Plant:             &models.Plant{Name: "Cactus"},
+		UserLanguage:      models.LanguageEnglish,
+		UserCurrentHomeID: &flatID,
+	}
+
+	mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{atDacha, inNoHome}, nil)
+	mockTemplateRepo.On("Get", ctx, models.NotificationTypeWatering, models.LanguageEnglish).Return(nil, nil)
+	mockNotificationRepo.On("ClaimWateringReminders", ctx, userID, time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)).Return(true, nil).Once()
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return *n.PlantID == inNoHome.PlantID
+	})).Return(nil).Once()
+
+	stats, err := service.CheckAndCreateWateringNotifications(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.PlantsNeedingWater)
+	assert.Equal(t, 1, stats.RemindersHeld)
+	mockNotificationRepo.AssertExpectations(t)
+}
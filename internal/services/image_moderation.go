@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnknownImageModerator is returned when the configured image moderation provider does not exist
+	ErrUnknownImageModerator = errors.New("unknown image moderation provider")
+
+	// ErrImageModeratorUnconfigured is returned when the configured image moderation provider lacks its credentials
+	ErrImageModeratorUnconfigured = errors.New("image moderation provider is not configured")
+)
+
+const (
+	// yandexVisionModerationModel is the built-in Yandex Vision model classifying unsafe content
+	yandexVisionModerationModel = "moderation"
+
+	// unsafeImageProbability is the probability of unsafe content from which an image is quarantined
+	unsafeImageProbability = 0.8
+
+	// notPlantProbability is the probability of showing a plant below which an image obviously shows none
+	notPlantProbability = 0.2
+)
+
+// yandexVisionUnsafeClasses are the classes of the Yandex Vision moderation model that quarantine an image
+var yandexVisionUnsafeClasses = map[string]bool{
+	"adult":    true,
+	"gruesome": true,
+}
+
+// ImageModeration is what an image moderator found on an image
+type ImageModeration struct {
+	Unsafe   []string // classes of unsafe content, e.g. adult; an image with any is quarantined
+	NotPlant bool     // the image obviously shows no plant
+}
+
+// ImageModerator checks images users upload before they go live
+type ImageModerator interface {
+	// Name returns the provider name
+	Name() string
+
+	// Moderate checks an image for unsafe content and whether it shows a plant
+	Moderate(ctx context.Context, image []byte, contentType string) (*ImageModeration, error)
+}
+
+// NewImageModerator creates the image moderator configured by name: stub or yandex-vision. An empty
+// name or none returns nil, which lets uploads go live unchecked. Yandex Vision tells plants apart
+// only when plantModel names a classification model with a plant class.
+func NewImageModerator(name string, apiKey string, folderID string, plantModel string) (ImageModerator, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "none":
+		return nil, nil
+	case "stub":
+		return StubImageModerator{}, nil
+	case "yandex-vision":
+		if apiKey == "" {
+			return nil, fmt.Errorf("%w: %s needs a Yandex Vision API key", ErrImageModeratorUnconfigured, name)
+		}
+		return NewYandexVisionModerator(apiKey, folderID, plantModel), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownImageModerator, name)
+	}
+}
+
+// StubImageModerator approves every image; it stands in for a moderation provider in development
+type StubImageModerator struct{}
+
+// Name returns the provider name
+func (StubImageModerator) Name() string {
+	return "stub"
+}
+
+// Moderate finds nothing on any image
+func (StubImageModerator) Moderate(ctx context.Context, image []byte, contentType string) (*ImageModeration, error) {
+	return &ImageModeration{}, nil
+}
+
+// YandexVisionModerator checks images with the Yandex Vision moderation model and, when configured,
+// a classification model telling plants apart
+type YandexVisionModerator struct {
+	vision     *YandexVisionProvider
+	plantModel string // no plant check when empty
+}
+
+// NewYandexVisionModerator creates a new Yandex Vision image moderator
+func NewYandexVisionModerator(apiKey string, folderID string, plantModel string) *YandexVisionModerator {
+	return &YandexVisionModerator{
+		vision:     NewYandexVisionProvider(apiKey, folderID, yandexVisionModerationModel),
+		plantModel: plantModel,
+	}
+}
+
+// Name returns the provider name
+func (m *YandexVisionModerator) Name() string {
+	return "yandex-vision"
+}
+
+// Moderate classifies an image with the moderation model and the plant model in one request
+func (m *YandexVisionModerator) Moderate(ctx context.Context, image []byte, contentType string) (*ImageModeration, error) {
+	models := []string{yandexVisionModerationModel}
+	if m.plantModel != "" {
+		models = append(models, m.plantModel)
+	}
+	classifications, err := m.vision.classify(ctx, image, contentType, models...)
+	if err != nil {
+		return nil, err
+	}
+	if len(classifications) != len(models) {
+		return nil, fmt.Errorf("expected %d classifications, got %d", len(models), len(classifications))
+	}
+
+	moderation := &ImageModeration{}
+	for _, property := range classifications[0] {
+		if yandexVisionUnsafeClasses[property.Name] && property.Probability >= unsafeImageProbability {
+			moderation.Unsafe = append(moderation.Unsafe, property.Name)
+		}
+	}
+	if m.plantModel != "" {
+		// A model without a plant class tells nothing, rather than that no image shows a plant
+		for _, property := range classifications[1] {
+			if property.Name == "plant" {
+				moderation.NotPlant = property.Probability < notPlantProbability
+			}
+		}
+	}
+	return moderation, nil
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewImageModerator tests that moderators are created by name and that Yandex Vision needs an API key
+func TestNewImageModerator(t *testing.T) {
+	moderator, err := NewImageModerator("", "", "", "")
+	assert.NoError(t, err)
+	assert.Nil(t, moderator)
+
+	moderator, err = NewImageModerator("stub", "", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "stub", moderator.Name())
+
+	_, err = NewImageModerator("yandex-vision", "", "folder", "")
+	assert.ErrorIs(t, err, ErrImageModeratorUnconfigured)
+
+	_, err = NewImageModerator("rekognition", "", "", "")
+	assert.ErrorIs(t, err, ErrUnknownImageModerator)
+}
+
+// TestYandexVisionModerator_Moderate tests that likely unsafe classes are found and that images the
+// plant model hardly sees a plant on are marked
+func TestYandexVisionModerator_Moderate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req yandexVisionRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		features := req.AnalyzeSpecs[0].Features
+		if assert.Len(t, features, 2) {
+			assert.Equal(t, "moderation", features[0].ClassificationConfig.Model)
+			assert.Equal(t, "plants", features[1].ClassificationConfig.Model)
+		}
+
+		w.Write([]byte(`{"results":[{"results":[
+			{"classification":{"properties":[{"name":"adult","probability":0.1},{"name":"gruesome","probability":0.9},{"name":"text","probability":0.95}]}},
+			{"classification":{"properties":[{"name":"plant","probability":0.05}]}}]}]}`))
+	}))
+	defer server.Close()
+
+	moderator := NewYandexVisionModerator("test-key", "folder", "plants")
+	moderator.vision.endpoint = server.URL
+
+	moderation, err := moderator.Moderate(context.Background(), pngHeader, "image/png")
+	assert.NoError(t, err)
+	assert.Equal(t, &ImageModeration{Unsafe: []string{"gruesome"}, NotPlant: true}, moderation)
+}
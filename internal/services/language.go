@@ -0,0 +1,60 @@
+package services
+
+import (
+	"unicode"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// chatSystemPrompts holds the chat system prompt for each supported language
+var chatSystemPrompts = map[models.Language]string{
+	models.LanguageRussian: "Ты - эксперт по растениям. Помогай пользователям с вопросами о выращивании, уходе и выборе растений. Отвечай на русском языке.",
+	models.LanguageEnglish: "You are a plant expert. Help users with questions about growing, caring for and choosing plants. Answer in English.",
+}
+
+// chatSystemPrompt returns the chat system prompt for a language, defaulting to Russian
+func chatSystemPrompt(language models.Language) string {
+	if prompt, ok := chatSystemPrompts[language]; ok {
+		return prompt
+	}
+	return chatSystemPrompts[models.LanguageRussian]
+}
+
+// detectLanguage guesses the language of a text from the share of Cyrillic and Latin letters.
+// The second return value is false when the text has too few letters to decide.
+func detectLanguage(text string) (models.Language, bool) {
+	var cyrillic, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	// Plant names are often written in Latin inside Russian text, so Russian wins unless
+	// Latin letters clearly dominate
+	switch {
+	case cyrillic+latin < 3:
+		return "", false
+	case cyrillic > 0 && cyrillic*3 >= latin:
+		return models.LanguageRussian, true
+	case latin > 0:
+		return models.LanguageEnglish, true
+	default:
+		return "", false
+	}
+}
+
+// resolveChatLanguage picks the answer language: the detected message language,
+// then the user's preference, then Russian
+func resolveChatLanguage(message string, preferred models.Language) models.Language {
+	if language, ok := detectLanguage(message); ok {
+		return language
+	}
+	if _, ok := chatSystemPrompts[preferred]; ok {
+		return preferred
+	}
+	return models.LanguageRussian
+}
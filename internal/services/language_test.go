@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestResolveChatLanguage tests message language detection with the preference fallback
+func TestResolveChatLanguage(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		preferred models.Language
+		expected  models.Language
+	}{
+		{"russian", "Как часто поливать фикус?", models.LanguageEnglish, models.LanguageRussian},
+		{"english", "How often should I water my ficus?", models.LanguageRussian, models.LanguageEnglish},
+		{"russian with latin plant name", "Подходит ли Monstera deliciosa для тени?", models.LanguageEnglish, models.LanguageRussian},
+		{"undetectable uses preference", "?? 42", models.LanguageEnglish, models.LanguageEnglish},
+		{"undetectable without preference", "👍", "", models.LanguageRussian},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolveChatLanguage(tt.message, tt.preferred))
+		})
+	}
+}
+
+// TestChatSystemPrompt tests that the system prompt follows the language
+func TestChatSystemPrompt(t *testing.T) {
+	assert.Contains(t, chatSystemPrompt(models.LanguageEnglish), "Answer in English")
+	assert.Contains(t, chatSystemPrompt(models.LanguageRussian), "Отвечай на русском языке")
+	assert.Equal(t, chatSystemPrompt(models.LanguageRussian), chatSystemPrompt("GERMAN"))
+}
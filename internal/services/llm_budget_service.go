@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrLLMQuotaExceeded is returned when a user has used up their monthly Yandex GPT token quota
+	ErrLLMQuotaExceeded = errors.New("monthly Yandex GPT quota exceeded")
+
+	// ErrYandexGPTCircuitOpen is returned while Yandex GPT calls are suspended after repeated failures
+	ErrYandexGPTCircuitOpen = errors.New("Yandex GPT is temporarily unavailable")
+)
+
+// llmBudgetTopUsers is the number of heaviest users listed in the budget report
+const llmBudgetTopUsers = 10
+
+// LLMBudgetService tracks the Yandex GPT spend against the monthly budget, enforces
+// per-user token quotas and suspends calls while the API keeps failing
+type LLMBudgetService struct {
+	usageRepo        repository.LLMUsageRepository
+	monthlyBudget    float64 // in rubles; no budget when 0
+	pricePer1KTokens float64 // in rubles
+	userQuota        int64   // tokens per user per month; unlimited when 0
+	breaker          *circuitBreaker
+	now              func() time.Time
+}
+
+// NewLLMBudgetService creates a new Yandex GPT budget service. The circuit breaker opens after
+// breakerThreshold consecutive failures and lets a probe call through after breakerCooldown.
+func NewLLMBudgetService(
+	usageRepo repository.LLMUsageRepository,
+	monthlyBudget float64,
+	pricePer1KTokens float64,
+	userQuota int64,
+	breakerThreshold int,
+	breakerCooldown time.Duration,
+) *LLMBudgetService {
+	return &LLMBudgetService{
+		usageRepo:        usageRepo,
+		monthlyBudget:    monthlyBudget,
+		pricePer1KTokens: pricePer1KTokens,
+		userQuota:        userQuota,
+		breaker:          newCircuitBreaker(breakerThreshold, breakerCooldown),
+		now:              time.Now,
+	}
+}
+
+// Acquire checks whether a Yandex GPT call may be made for the user in the context. Every
+// successful Acquire must be followed by Release with the outcome of the call.
+func (s *LLMBudgetService) Acquire(ctx context.Context) error {
+	if userID, err := middleware.GetUserID(ctx); err == nil && s.userQuota > 0 {
+		tokens, err := s.usageRepo.GetUserTokens(ctx, userID, monthStart(s.now()))
+		if err != nil {
+			// Users should not lose the assistant because the usage could not be read
+			log.Printf("Error checking Yandex GPT quota of user %s: %v", userID, err)
+		} else if tokens >= s.userQuota {
+			return ErrLLMQuotaExceeded
+		}
+	}
+
+	if !s.breaker.Allow() {
+		return ErrYandexGPTCircuitOpen
+	}
+	return nil
+}
+
+// Release records the outcome of a Yandex GPT call allowed by Acquire along with the tokens it consumed
+func (s *LLMBudgetService) Release(ctx context.Context, inputTokens int64, completionTokens int64, callErr error) {
+	switch {
+	case callErr == nil:
+		s.breaker.Record(true)
+	case ctx.Err() != nil:
+		// The caller went away, so the failure says nothing about the API
+		s.breaker.Skip()
+	default:
+		s.breaker.Record(!isYandexGPTOutage(callErr))
+	}
+
+	if callErr != nil {
+		return
+	}
+
+	usage := &models.LLMUsage{
+		InputTokens:      inputTokens,
+		CompletionTokens: completionTokens,
+		CreatedAt:        s.now(),
+	}
+	if userID, err := middleware.GetUserID(ctx); err == nil {
+		usage.UserID = &userID
+	}
+	if err := s.usageRepo.Record(context.WithoutCancel(ctx), usage); err != nil {
+		log.Printf("Error recording Yandex GPT usage: %v", err)
+	}
+}
+
+// GetUserQuota gets the tokens a user spent this month and the monthly quota, which is 0 when users are not limited
+func (s *LLMBudgetService) GetUserQuota(ctx context.Context, userID uuid.UUID) (int64, int64, error) {
+	if s.userQuota <= 0 {
+		return 0, 0, nil
+	}
+
+	tokens, err := s.usageRepo.GetUserTokens(ctx, userID, monthStart(s.now()))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get LLM usage of user: %w", err)
+	}
+	return tokens, s.userQuota, nil
+}
+
+// GetReport gets the spend, quota consumption and circuit breaker state of the current month
+func (s *LLMBudgetService) GetReport(ctx context.Context) (*models.LLMBudgetReport, error) {
+	now := s.now()
+	start := monthStart(now)
+
+	totals, err := s.usageRepo.GetTotals(ctx, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM usage totals: %w", err)
+	}
+
+	topUsers, err := s.usageRepo.GetTopUsers(ctx, start, llmBudgetTopUsers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top LLM users: %w", err)
+	}
+
+	report := &models.LLMBudgetReport{
+		PeriodStart:      start,
+		GeneratedAt:      now,
+		Requests:         totals.Requests,
+		InputTokens:      totals.InputTokens,
+		CompletionTokens: totals.CompletionTokens,
+		Spend:            s.cost(totals.InputTokens + totals.CompletionTokens),
+		TopUsers:         topUsers,
+		CircuitBreaker:   s.breaker.Status(),
+	}
+
+	// Project the month-end spend assuming the rate so far continues
+	if elapsed := now.Sub(start); elapsed > 0 {
+		report.ProjectedSpend = report.Spend * float64(start.AddDate(0, 1, 0).Sub(start)) / float64(elapsed)
+	}
+
+	if s.monthlyBudget > 0 {
+		budget := s.monthlyBudget
+		usedRatio := report.Spend / budget
+		report.MonthlyBudget = &budget
+		report.BudgetUsedRatio = &usedRatio
+	}
+
+	if s.userQuota > 0 {
+		quota := s.userQuota
+		report.UserQuotaTokens = &quota
+		for _, user := range topUsers {
+			usedRatio := float64(user.Tokens) / float64(quota)
+			user.QuotaUsedRatio = &usedRatio
+		}
+
+		report.UsersOverQuota, err = s.usageRepo.CountUsersOverQuota(ctx, start, quota)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count users over LLM quota: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+// cost converts tokens to rubles
+func (s *LLMBudgetService) cost(tokens int64) float64 {
+	return float64(tokens) / 1000 * s.pricePer1KTokens
+}
+
+// isYandexGPTOutage reports whether a failed call points at an unavailable API rather than a bad request
+func isYandexGPTOutage(err error) bool {
+	var apiErr *YandexGPTAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == 429
+	}
+	return true
+}
+
+// monthStart returns the first moment of the UTC calendar month containing t
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
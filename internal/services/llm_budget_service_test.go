@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLLMUsageRepository is a mock implementation of the LLMUsageRepository interface
+type MockLLMUsageRepository struct {
+	mock.Mock
+}
+
+func (m *MockLLMUsageRepository) Record(ctx context.Context, usage *models.LLMUsage) error {
+	args := m.Called(ctx, usage)
+	return args.Error(0)
+}
+
+func (m *MockLLMUsageRepository) GetTotals(ctx context.Context, since time.Time) (*models.LLMUsageTotals, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LLMUsageTotals), args.Error(1)
+}
+
+func (m *MockLLMUsageRepository) GetTopUsers(ctx context.Context, since time.Time, limit int) ([]*models.LLMUserUsage, error) {
+	args := m.Called(ctx, since, limit)
+	return args.Get(0).([]*models.LLMUserUsage), args.Error(1)
+}
+
+func (m *MockLLMUsageRepository) CountUsersOverQuota(ctx context.Context, since time.Time, tokens int64) (int, error) {
+	args := m.Called(ctx, since, tokens)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLLMUsageRepository) GetUserTokens(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error) {
+	args := m.Called(ctx, userID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// TestCircuitBreaker tests that the breaker opens after consecutive failures and closes after a successful probe
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, time.May, 10, 12, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	assert.True(t, breaker.Allow())
+	breaker.Record(false)
+	assert.True(t, breaker.Allow())
+	breaker.Record(false)
+
+	assert.Equal(t, models.CircuitBreakerOpen, breaker.Status().State)
+	assert.False(t, breaker.Allow())
+
+	// A single probe is let through after the cooldown
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+	assert.Equal(t, models.CircuitBreakerHalfOpen, breaker.Status().State)
+
+	// A failed probe opens the breaker again
+	breaker.Record(false)
+	assert.Equal(t, models.CircuitBreakerOpen, breaker.Status().State)
+
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+	breaker.Record(true)
+
+	status := breaker.Status()
+	assert.Equal(t, models.CircuitBreakerClosed, status.State)
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.Nil(t, status.OpenedAt)
+}
+
+// TestLLMBudgetService_Acquire_QuotaExceeded tests that users over their quota are refused
+func TestLLMBudgetService_Acquire_QuotaExceeded(t *testing.T) {
+	mockUsageRepo := new(MockLLMUsageRepository)
+	service := NewLLMBudgetService(mockUsageRepo, 0, 0.2, 1000, 5, time.Minute)
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, userID.String())
+	mockUsageRepo.On("GetUserTokens", mock.Anything, userID, mock.Anything).Return(int64(1000), nil)
+
+	assert.ErrorIs(t, service.Acquire(ctx), ErrLLMQuotaExceeded)
+
+	// Requests not made on behalf of a user are not limited
+	assert.NoError(t, service.Acquire(context.Background()))
+}
+
+// TestRecommendationService_RecordsLLMUsage tests that completions record their tokens and outages open the breaker
+func TestRecommendationService_RecordsLLMUsage(t *testing.T) {
+	statusCode := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+		w.Write([]byte(`{"result":{"alternatives":[{"message":{"role":"assistant","text":"pong"}}],"usage":{"inputTextTokens":"12","completionTokens":"30","totalTokens":"42"}}}`))
+	}))
+	defer server.Close()
+
+	mockUsageRepo := new(MockLLMUsageRepository)
+	budget := NewLLMBudgetService(mockUsageRepo, 0, 0.2, 0, 1, time.Minute)
+	service := NewRecommendationService(nil, nil, "test-key", "gpt://b1g/yandexgpt-lite")
+	service.yandexGPTEndpoint = server.URL
+	service.SetLLMBudget(budget)
+
+	userID := uuid.New()
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, userID.String())
+	mockUsageRepo.On("Record", mock.Anything, mock.MatchedBy(func(usage *models.LLMUsage) bool {
+		return usage.UserID != nil && *usage.UserID == userID && usage.InputTokens == 12 && usage.CompletionTokens == 30
+	})).Return(nil).Once()
+
+	response, err := service.callYandexGPTAPI(ctx, "ping", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", response)
+	mockUsageRepo.AssertExpectations(t)
+
+	// An unavailable API opens the breaker and later calls fail fast
+	statusCode = http.StatusServiceUnavailable
+	_, err = service.callYandexGPTAPI(ctx, "ping", nil)
+	assert.Error(t, err)
+
+	_, err = service.callYandexGPTAPI(ctx, "ping", nil)
+	assert.ErrorIs(t, err, ErrYandexGPTCircuitOpen)
+	assert.Equal(t, models.CircuitBreakerOpen, budget.breaker.Status().State)
+}
+
+// TestRecommendationService_SendChatMessage_RecordsTokens tests that assistant messages keep the tokens spent on them
+func TestRecommendationService_SendChatMessage_RecordsTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":{"alternatives":[{"message":{"role":"assistant","text":"Try a snake plant."}}],"usage":{"inputTextTokens":"120","completionTokens":"35","totalTokens":"155"}}}`))
+	}))
+	defer server.Close()
+
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	service := NewRecommendationService(mockRecommendationRepo, nil, "test-key", "gpt://b1g/yandexgpt-lite")
+	service.yandexGPTEndpoint = server.URL
+
+	userID, sessionID := uuid.New(), uuid.New()
+	mockRecommendationRepo.On("GetChatSession", mock.Anything, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID}, nil)
+	mockRecommendationRepo.On("GetChatContext", mock.Anything, sessionID).Return(&models.ChatContext{SessionID: sessionID, SystemPrompt: chatSystemPrompt(models.LanguageEnglish)}, nil)
+	mockRecommendationRepo.On("GetChatMessages", mock.Anything, sessionID).Return([]*models.ChatMessage{}, nil)
+	mockRecommendationRepo.On("SaveChatMessage", mock.Anything, mock.MatchedBy(func(m *models.ChatMessage) bool {
+		return m.Role == "user" && m.PromptTokens == 0 && m.CompletionTokens == 0
+	})).Return(nil).Once()
+	mockRecommendationRepo.On("SaveChatMessage", mock.Anything, mock.MatchedBy(func(m *models.ChatMessage) bool {
+		return m.Role == "assistant" && m.PromptTokens == 120 && m.CompletionTokens == 35
+	})).Return(nil).Once()
+	mockRecommendationRepo.On("UpdateChatSessionLastUsed", mock.Anything, sessionID).Return(nil)
+
+	message, err := service.SendChatMessage(context.Background(), sessionID, userID, "What grows in a dark room?", models.LanguageEnglish)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Try a snake plant.", message.Content)
+	assert.Equal(t, int64(120), message.PromptTokens)
+	mockRecommendationRepo.AssertExpectations(t)
+}
+
+// TestRecommendationService_SendChatMessage_ClientGone tests that a client disconnecting cancels the
+// Yandex GPT request, saves nothing and does not count against the API
+func TestRecommendationService_SendChatMessage_ClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The client disconnects while the answer is being generated, which takes longer than the test
+		cancel()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	mockUsageRepo := new(MockLLMUsageRepository)
+	budget := NewLLMBudgetService(mockUsageRepo, 0, 0.2, 0, 1, time.Minute)
+	service := NewRecommendationService(mockRecommendationRepo, nil, "test-key", "gpt://b1g/yandexgpt-lite")
+	service.yandexGPTEndpoint = server.URL
+	service.SetLLMBudget(budget)
+
+	userID, sessionID := uuid.New(), uuid.New()
+	mockRecommendationRepo.On("GetChatSession", mock.Anything, sessionID).Return(&models.ChatSession{ID: sessionID, UserID: userID}, nil)
+	mockRecommendationRepo.On("GetChatContext", mock.Anything, sessionID).Return(&models.ChatContext{SessionID: sessionID, SystemPrompt: chatSystemPrompt(models.LanguageEnglish)}, nil)
+	mockRecommendationRepo.On("GetChatMessages", mock.Anything, sessionID).Return([]*models.ChatMessage{}, nil)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := service.SendChatMessage(ctx, sessionID, userID, "What grows in a dark room?", models.LanguageEnglish)
+		done <- err
+	}()
+
+	// The call returns without waiting for the answer
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the Yandex GPT request was not canceled")
+	}
+	assert.ErrorIs(t, err, ErrChatRequestCanceled)
+	mockRecommendationRepo.AssertNotCalled(t, "SaveChatMessage", mock.Anything, mock.Anything)
+	mockRecommendationRepo.AssertNotCalled(t, "UpdateChatSessionLastUsed", mock.Anything, mock.Anything)
+	assert.Equal(t, models.CircuitBreakerClosed, budget.breaker.Status().State)
+
+	// A client gone before the call does not reach the API
+	_, err = service.SendChatMessage(ctx, sessionID, userID, "And in a bright one?", models.LanguageEnglish)
+	assert.ErrorIs(t, err, ErrChatRequestCanceled)
+}
+
+// TestRecommendationService_GetChatUsage tests that the monthly quota is reported only when users are limited
+func TestRecommendationService_GetChatUsage(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	mockUsageRepo := new(MockLLMUsageRepository)
+	service := NewRecommendationService(mockRecommendationRepo, nil, "", "")
+
+	userID := uuid.New()
+	mockRecommendationRepo.On("GetChatUsage", mock.Anything, userID).Return(&models.ChatUsage{Sessions: 2, Messages: 6, PromptTokens: 900, CompletionTokens: 300}, nil)
+
+	usage, err := service.GetChatUsage(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.Nil(t, usage.MonthlyQuotaTokens)
+
+	service.SetLLMBudget(NewLLMBudgetService(mockUsageRepo, 0, 0.2, 5000, 5, time.Minute))
+	mockUsageRepo.On("GetUserTokens", mock.Anything, userID, mock.Anything).Return(int64(1200), nil)
+
+	usage, err = service.GetChatUsage(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(900), usage.PromptTokens)
+	assert.Equal(t, int64(1200), *usage.MonthTokens)
+	assert.Equal(t, int64(5000), *usage.MonthlyQuotaTokens)
+}
+
+// TestLLMBudgetService_GetReport tests the spend, projection and quota consumption of the report
+func TestLLMBudgetService_GetReport(t *testing.T) {
+	mockUsageRepo := new(MockLLMUsageRepository)
+	service := NewLLMBudgetService(mockUsageRepo, 1000, 0.5, 10000, 5, time.Minute)
+	// A quarter into a 30-day month
+	service.now = func() time.Time { return time.Date(2024, time.June, 8, 12, 0, 0, 0, time.UTC) }
+
+	start := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	heavyUser := &models.LLMUserUsage{UserID: uuid.New(), Requests: 40, Tokens: 15000}
+	mockUsageRepo.On("GetTotals", mock.Anything, start).Return(&models.LLMUsageTotals{Requests: 100, InputTokens: 300000, CompletionTokens: 100000}, nil)
+	mockUsageRepo.On("GetTopUsers", mock.Anything, start, llmBudgetTopUsers).Return([]*models.LLMUserUsage{heavyUser}, nil)
+	mockUsageRepo.On("CountUsersOverQuota", mock.Anything, start, int64(10000)).Return(1, nil)
+
+	report, err := service.GetReport(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, start, report.PeriodStart)
+	assert.InDelta(t, 200, report.Spend, 0.001)
+	assert.InDelta(t, 800, report.ProjectedSpend, 0.001)
+	assert.InDelta(t, 0.2, *report.BudgetUsedRatio, 0.001)
+	assert.Equal(t, 1, report.UsersOverQuota)
+	assert.InDelta(t, 1.5, *heavyUser.QuotaUsedRatio, 0.001)
+	assert.Equal(t, models.CircuitBreakerClosed, report.CircuitBreaker.State)
+}
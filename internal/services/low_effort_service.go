@@ -0,0 +1,191 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"text/template"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+//go:embed templates/low_effort.json
+var lowEffortTradeOffsJSON []byte
+
+// lowEffortTradeOffs holds the texts of the trade-offs of low effort mode by language
+var lowEffortTradeOffs = mustLoadLowEffortTradeOffs(lowEffortTradeOffsJSON)
+
+// lowEffortTradeOff identifies a trade-off of watering a plant in low effort mode
+type lowEffortTradeOff string
+
+const (
+	lowEffortTradeOffStretched    lowEffortTradeOff = "STRETCHED"     // waterings are further apart
+	lowEffortTradeOffSlowerGrowth lowEffortTradeOff = "SLOWER_GROWTH" // the plant grows slower on less water
+	lowEffortTradeOffHighHumidity lowEffortTradeOff = "HIGH_HUMIDITY" // humid plants suffer the most from dry soil
+	lowEffortTradeOffNoTolerance  lowEffortTradeOff = "NO_TOLERANCE"  // nothing to stretch toward, watering is unchanged
+)
+
+// lowEffortTradeOffData is the data of a trade-off template
+type lowEffortTradeOffData struct {
+	Days       int // days between waterings in low effort mode
+	NormalDays int // days between waterings the plant does best with
+}
+
+// LowEffortService turns low effort mode on and off for users and the plants in their collections.
+// Plants in low effort mode are watered as rarely as their care instructions say they tolerate, and
+// their next watering moves accordingly when the mode changes.
+type LowEffortService struct {
+	plantRepo repository.PlantRepository
+	userRepo  repository.UserRepository
+}
+
+// NewLowEffortService creates a new low effort service
+func NewLowEffortService(plantRepo repository.PlantRepository, userRepo repository.UserRepository) *LowEffortService {
+	return &LowEffortService{
+		plantRepo: plantRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// SetUserMode turns the low effort mode of a user on or off and returns their collection with the
+// watering rescheduled. Plants with a mode of their own keep it.
+func (s *LowEffortService) SetUserMode(ctx context.Context, userID uuid.UUID, enabled bool) ([]*models.Plant, error) {
+	if err := s.userRepo.SetLowEffortMode(ctx, userID, enabled); err != nil {
+		return nil, fmt.Errorf("failed to set low effort mode: %w", err)
+	}
+
+	plants, err := s.plantRepo.GetUserPlants(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plants: %w", err)
+	}
+	for _, plant := range plants {
+		if err := s.reschedule(ctx, userID, plant); err != nil {
+			return nil, err
+		}
+	}
+	return plants, nil
+}
+
+// SetPlantMode sets the low effort mode of a plant in a user's collection and returns the plant with
+// its watering rescheduled; nil makes the plant follow the user's mode again
+func (s *LowEffortService) SetPlantMode(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, enabled *bool) (*models.Plant, error) {
+	if err := s.plantRepo.SetUserPlantLowEffortMode(ctx, userID, plantID, enabled); err != nil {
+		return nil, fmt.Errorf("failed to set low effort mode of plant: %w", err)
+	}
+
+	plants, err := s.plantRepo.GetUserPlants(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plants: %w", err)
+	}
+	for _, plant := range plants {
+		if plant.ID == plantID {
+			if err := s.reschedule(ctx, userID, plant); err != nil {
+				return nil, err
+			}
+			return plant, nil
+		}
+	}
+	return nil, fmt.Errorf("user plant not found: %w", sql.ErrNoRows)
+}
+
+// reschedule moves the next watering of a plant in a collection to its last watering plus the days
+// between waterings it gets now. Plants that were never watered keep their schedule.
+func (s *LowEffortService) reschedule(ctx context.Context, userID uuid.UUID, plant *models.Plant) error {
+	if plant.LastWatered == nil {
+		return nil
+	}
+	frequency := plant.CareInstructions.WateringFrequency
+	if plant.LowEffort != nil {
+		frequency = plant.LowEffort.WateringFrequency
+	}
+	nextWatering := plant.LastWatered.AddDate(0, 0, frequency)
+	if plant.NextWatering != nil && plant.NextWatering.Equal(nextWatering) {
+		return nil
+	}
+
+	err := s.plantRepo.UpdateUserPlant(ctx, &models.UserPlant{
+		UserID:       userID,
+		PlantID:      plant.ID,
+		Location:     plant.Location,
+		LastWatered:  plant.LastWatered,
+		NextWatering: &nextWatering,
+		Nickname:     plant.Nickname,
+		Notes:        plant.Notes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reschedule watering of plant %s: %w", plant.ID, err)
+	}
+	plant.NextWatering = &nextWatering
+	return nil
+}
+
+// AddLowEffortTradeOffs spells out, in the given language, what low effort mode costs the plants of a
+// collection watered in it. Unsupported languages fall back to Russian.
+func AddLowEffortTradeOffs(plants []*models.Plant, language models.Language) {
+	texts, ok := lowEffortTradeOffs[language]
+	if !ok {
+		texts = lowEffortTradeOffs[models.LanguageRussian]
+	}
+
+	for _, plant := range plants {
+		lowEffort := plant.LowEffort
+		if lowEffort == nil {
+			continue
+		}
+
+		var tradeOffs []lowEffortTradeOff
+		if lowEffort.Stretched {
+			tradeOffs = append(tradeOffs, lowEffortTradeOffStretched, lowEffortTradeOffSlowerGrowth)
+			if plant.CareInstructions.Humidity == models.HumidityLevelHigh {
+				tradeOffs = append(tradeOffs, lowEffortTradeOffHighHumidity)
+			}
+		} else {
+			tradeOffs = append(tradeOffs, lowEffortTradeOffNoTolerance)
+		}
+
+		data := lowEffortTradeOffData{Days: lowEffort.WateringFrequency, NormalDays: lowEffort.NormalWateringFrequency}
+		lowEffort.TradeOffs = make([]string, 0, len(tradeOffs))
+		for _, tradeOff := range tradeOffs {
+			var text bytes.Buffer
+			if err := texts[tradeOff].Execute(&text, data); err == nil {
+				lowEffort.TradeOffs = append(lowEffort.TradeOffs, text.String())
+			}
+		}
+	}
+}
+
+// mustLoadLowEffortTradeOffs parses the trade-off texts and panics if they are invalid, miss a
+// trade-off or miss Russian, the language others fall back to
+func mustLoadLowEffortTradeOffs(data []byte) map[models.Language]map[lowEffortTradeOff]*template.Template {
+	var sources map[models.Language]map[lowEffortTradeOff]string
+	if err := json.Unmarshal(data, &sources); err != nil {
+		panic(fmt.Sprintf("invalid low effort trade-offs: %v", err))
+	}
+	if _, ok := sources[models.LanguageRussian]; !ok {
+		panic("low effort trade-offs have no Russian version")
+	}
+
+	texts := make(map[models.Language]map[lowEffortTradeOff]*template.Template, len(sources))
+	for language, source := range sources {
+		texts[language] = make(map[lowEffortTradeOff]*template.Template)
+		for _, tradeOff := range []lowEffortTradeOff{
+			lowEffortTradeOffStretched, lowEffortTradeOffSlowerGrowth, lowEffortTradeOffHighHumidity, lowEffortTradeOffNoTolerance,
+		} {
+			text, ok := source[tradeOff]
+			if !ok {
+				panic(fmt.Sprintf("low effort trade-offs for %s have no %s text", language, tradeOff))
+			}
+			parsed, err := template.New(string(language) + "/" + string(tradeOff)).Parse(text)
+			if err != nil {
+				panic(fmt.Sprintf("invalid %s %s trade-off: %v", language, tradeOff, err))
+			}
+			texts[language][tradeOff] = parsed
+		}
+	}
+	return texts
+}
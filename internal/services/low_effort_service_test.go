@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// lowEffortFixture returns a plant of a collection watered every 7 days that tolerates 14, last
+// watered on May 10th
+func lowEffortFixture() *models.Plant {
+	lastWatered := time.Date(2024, time.May, 10, 9, 0, 0, 0, time.UTC)
+	nextWatering := lastWatered.AddDate(0, 0, 7)
+	maxWatering := 14
+	return &models.Plant{
+		ID:   uuid.New(),
+		Name: "Zamioculcas",
+		CareInstructions: models.CareInstructions{
+			WateringFrequency:    7,
+			WateringFrequencyMax: &maxWatering,
+			Humidity:             models.HumidityLevelHigh,
+		},
+		LastWatered:  &lastWatered,
+		NextWatering: &nextWatering,
+	}
+}
+
+// TestLowEffortService_SetUserMode tests that turning the mode on moves the next watering of the
+// collection to the most days each plant tolerates
+func TestLowEffortService_SetUserMode(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockUserRepo := new(MockUserRepository)
+	service := NewLowEffortService(mockPlantRepo, mockUserRepo)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	stretched := lowEffortFixture()
+	stretched.LowEffort = models.NewLowEffortWatering(stretched.CareInstructions, models.LowEffortSourceUser)
+	neverWatered := &models.Plant{ID: uuid.New(), CareInstructions: models.CareInstructions{WateringFrequency: 3}}
+
+	mockUserRepo.On("SetLowEffortMode", ctx, userID, true).Return(nil)
+	mockPlantRepo.On("GetUserPlants", ctx, userID).Return([]*models.Plant{stretched, neverWatered}, nil)
+	mockPlantRepo.On("UpdateUserPlant", ctx, mock.Anything).Return(nil)
+
+	plants, err := service.SetUserMode(ctx, userID, true)
+
+	assert.NoError(t, err)
+	assert.Len(t, plants, 2)
+	assert.Equal(t, time.Date(2024, time.May, 24, 9, 0, 0, 0, time.UTC), *stretched.NextWatering)
+	mockPlantRepo.AssertNumberOfCalls(t, "UpdateUserPlant", 1)
+	mockPlantRepo.AssertCalled(t, "UpdateUserPlant", ctx, mock.MatchedBy(func(up *models.UserPlant) bool {
+		return up.PlantID == stretched.ID && up.NextWatering.Equal(*stretched.NextWatering)
+	}))
+}
+
+// TestLowEffortService_SetPlantMode tests that a plant following the user's mode again goes back to
+// its usual watering, and that plants outside the collection are not found
+func TestLowEffortService_SetPlantMode(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewLowEffortService(mockPlantRepo, new(MockUserRepository))
+
+	ctx := context.Background()
+	userID := uuid.New()
+	plant := lowEffortFixture()
+	stretchedWatering := plant.LastWatered.AddDate(0, 0, 14)
+	plant.NextWatering = &stretchedWatering
+
+	mockPlantRepo.On("SetUserPlantLowEffortMode", ctx, userID, plant.ID, (*bool)(nil)).Return(nil)
+	mockPlantRepo.On("GetUserPlants", ctx, userID).Return([]*models.Plant{plant}, nil)
+	mockPlantRepo.On("UpdateUserPlant", ctx, mock.Anything).Return(nil)
+
+	result, err := service.SetPlantMode(ctx, userID, plant.ID, nil)
+
+	assert.NoError(t, err)
+	assert.Nil(t, result.LowEffort)
+	assert.Equal(t, time.Date(2024, time.May, 17, 9, 0, 0, 0, time.UTC), *result.NextWatering)
+
+	missingID := uuid.New()
+	mockPlantRepo.On("SetUserPlantLowEffortMode", ctx, userID, missingID, (*bool)(nil)).Return(sql.ErrNoRows)
+	_, err = service.SetPlantMode(ctx, userID, missingID, nil)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// TestAddLowEffortTradeOffs tests that the trade-offs are spelled out in the language of the response
+func TestAddLowEffortTradeOffs(t *testing.T) {
+	stretched := lowEffortFixture()
+	stretched.LowEffort = models.NewLowEffortWatering(stretched.CareInstructions, models.LowEffortSourcePlant)
+	unchanged := &models.Plant{CareInstructions: models.CareInstructions{WateringFrequency: 3}}
+	unchanged.LowEffort = models.NewLowEffortWatering(unchanged.CareInstructions, models.LowEffortSourceUser)
+	normal := lowEffortFixture()
+
+	AddLowEffortTradeOffs([]*models.Plant{stretched, unchanged, normal}, models.LanguageEnglish)
+
+	assert.True(t, stretched.LowEffort.Stretched)
+	assert.Equal(t, 14, stretched.LowEffort.WateringFrequency)
+	assert.Equal(t, []string{
+		"Watered every 14 days instead of 7: the soil stays dry longer between waterings",
+		"Growth may slow down and leaves may droop a little before each watering",
+		"The plant needs high humidity and takes sparse watering harder: mist it",
+	}, stretched.LowEffort.TradeOffs)
+	assert.False(t, unchanged.LowEffort.Stretched)
+	assert.Equal(t, []string{"The plant has no documented drought tolerance, so it is still watered every 3 days"}, unchanged.LowEffort.TradeOffs)
+	assert.Nil(t, normal.LowEffort)
+
+	// Unsupported languages fall back to Russian
+	AddLowEffortTradeOffs([]*models.Plant{unchanged}, models.Language("GERMAN"))
+	assert.Equal(t, []string{"Засухоустойчивость растения не указана, поэтому его по-прежнему поливают раз в 3 дн."}, unchanged.LowEffort.TradeOffs)
+}
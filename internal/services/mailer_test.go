@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSMTPMailer_Send tests that the envelope uses the bare sender address and the message is UTF-8 plain text
+func TestSMTPMailer_Send(t *testing.T) {
+	mailer, err := NewSMTPMailer("smtp.example.com", 587, "", "", "Planter <no-reply@planter.app>")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var envelope string
+	var recipients []string
+	var message string
+	mailer.send = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, "smtp.example.com:587", addr)
+		assert.Nil(t, auth)
+		envelope, recipients, message = from, to, string(msg)
+		return nil
+	}
+
+	err = mailer.Send(context.Background(), "anna@example.com", "Ваш аккаунт", "Здравствуйте!\nДо встречи")
+	assert.NoError(t, err)
+	assert.Equal(t, "no-reply@planter.app", envelope)
+	assert.Equal(t, []string{"anna@example.com"}, recipients)
+	assert.Contains(t, message, "From: \"Planter\" <no-reply@planter.app>\r\n")
+	assert.Contains(t, message, "Subject: =?utf-8?b?")
+	assert.True(t, strings.HasSuffix(message, "\r\n\r\nЗдравствуйте!\r\nДо встречи"))
+
+	// Header injection through the recipient is refused
+	err = mailer.Send(context.Background(), "anna@example.com\r\nBcc: eve@example.com", "Hi", "Hi")
+	assert.Error(t, err)
+}
+
+// TestBuildEmail_Date tests that the date header is formatted for mail clients
+func TestBuildEmail_Date(t *testing.T) {
+	msg := string(buildEmail("a@example.com", "b@example.com", "Hi", "", time.Date(2024, time.May, 1, 9, 0, 0, 0, time.UTC)))
+	assert.Contains(t, msg, "Date: Wed, 01 May 2024 09:00:00 +0000\r\n")
+}
+
+// TestNewSMTPMailer_InvalidSender tests that a malformed sender is rejected at startup
+func TestNewSMTPMailer_InvalidSender(t *testing.T) {
+	_, err := NewSMTPMailer("smtp.example.com", 587, "", "", "not an address")
+	assert.Error(t, err)
+}
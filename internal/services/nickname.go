@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// nicknameSuggestionCount is the number of nicknames suggested for a plant
+const nicknameSuggestionCount = 5
+
+// nicknameTemplates holds name-based nickname templates for each supported language
+var nicknameTemplates = map[models.Language][]string{
+	models.LanguageRussian: {"Малыш %s", "Сэр %s", "%s-младший", "Принцесса %s", "Капитан %s", "Доктор %s"},
+	models.LanguageEnglish: {"Little %s", "Sir %s", "%s Jr.", "Princess %s", "Captain %s", "Dr. %s"},
+}
+
+// nicknameTraits holds trait-based nicknames for each supported language
+var nicknameTraits = map[models.Language]map[string][]string{
+	models.LanguageRussian: {
+		"sun":     {"Солнышко", "Загорелик"},
+		"shade":   {"Тихоня", "Полумрак"},
+		"thirsty": {"Водохлёб", "Пузырёк"},
+		"dry":     {"Верблюжонок", "Кактусёнок"},
+		"any":     {"Листик", "Зелёнка", "Кучерявчик", "Фикусёнок"},
+	},
+	models.LanguageEnglish: {
+		"sun":     {"Sunny", "Goldie"},
+		"shade":   {"Shadow", "Moony"},
+		"thirsty": {"Splash", "Bubbles"},
+		"dry":     {"Camel", "Dusty"},
+		"any":     {"Leafy", "Sprout", "Curly", "Greenie"},
+	},
+}
+
+// GetNicknameSuggestions suggests nicknames for a plant in the user's collection.
+// Suggestions are built from templates and the plant's care needs, so they cost no
+// LLM call, and are stable for the same user plant.
+func (s *PlantService) GetNicknameSuggestions(
+	ctx context.Context,
+	userID uuid.UUID,
+	plantID uuid.UUID,
+	language models.Language,
+) (*models.NicknameSuggestions, error) {
+	// The plant must be in the user's collection
+	userPlant, err := s.plantRepo.GetUserPlant(ctx, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plant: %w", err)
+	}
+
+	// Get the plant
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant: %w", err)
+	}
+
+	return &models.NicknameSuggestions{
+		PlantID:   plantID,
+		Nicknames: suggestNicknames(plant, language, userPlant.ID),
+	}, nil
+}
+
+// suggestNicknames builds nickname suggestions for a plant, seeded by the given ID
+func suggestNicknames(plant *models.Plant, language models.Language, seed uuid.UUID) []string {
+	templates, ok := nicknameTemplates[language]
+	if !ok {
+		language = models.LanguageRussian
+		templates = nicknameTemplates[language]
+	}
+	traits := nicknameTraits[language]
+
+	// The seed makes the selection stable for the same user plant
+	hash := fnv.New32a()
+	hash.Write(seed[:])
+	sum := int(hash.Sum32() % 1024)
+
+	// Trait-based nicknames reflect the plant's care needs
+	var nicknames []string
+	care := plant.CareInstructions
+	switch care.Sunlight {
+	case models.SunlightLevelHigh:
+		nicknames = append(nicknames, traits["sun"][sum%len(traits["sun"])])
+	case models.SunlightLevelLow:
+		nicknames = append(nicknames, traits["shade"][sum%len(traits["shade"])])
+	}
+	switch {
+	case care.WateringFrequency > 0 && care.WateringFrequency <= 3:
+		nicknames = append(nicknames, traits["thirsty"][sum%len(traits["thirsty"])])
+	case care.WateringFrequency >= 14:
+		nicknames = append(nicknames, traits["dry"][sum%len(traits["dry"])])
+	}
+
+	// Generic and name-based nicknames fill the rest; the name uses the first word of the plant name
+	pool := append([]string{}, traits["any"]...)
+	if fields := strings.Fields(plant.Name); len(fields) > 0 {
+		for _, template := range templates {
+			pool = append(pool, fmt.Sprintf(template, fields[0]))
+		}
+	}
+
+	// Rotate the pool by the seed
+	offset := sum % len(pool)
+	for i := 0; i < len(pool) && len(nicknames) < nicknameSuggestionCount; i++ {
+		nicknames = append(nicknames, pool[(offset+i)%len(pool)])
+	}
+	return nicknames
+}
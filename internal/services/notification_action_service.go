@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotificationActionsUnavailable is returned when notification actions are not configured
+	ErrNotificationActionsUnavailable = errors.New("notification actions are not configured")
+	// ErrInvalidActionToken is returned for tokens that are malformed, forged or whose plant left the collection
+	ErrInvalidActionToken = errors.New("invalid action token")
+	// ErrActionTokenExpired is returned for tokens used after they expired
+	ErrActionTokenExpired = errors.New("action token expired")
+	// ErrActionTokenUsed is returned for tokens that were already used
+	ErrActionTokenUsed = errors.New("action token already used")
+)
+
+// defaultActionTokenTTL is how long action tokens can be used when no duration is configured
+const defaultActionTokenTTL = 72 * time.Hour
+
+// actionTokenKeyContext separates the key action tokens are signed with from the secret it is
+// derived from, so a token of one kind is never accepted as the other
+const actionTokenKeyContext = "planter notification actions"
+
+// SetActionTokens enables the actions of watering notifications: their payload carries a single-use
+// token signed with a key derived from secret that marks the plant watered without signing in, for
+// ttl. When urlTemplate is set, watering reminder emails link to it with {token} replaced.
+func (s *NotificationService) SetActionTokens(
+	actionRepo repository.NotificationActionRepository,
+	secret string,
+	ttl time.Duration,
+	urlTemplate string,
+) {
+	if ttl <= 0 {
+		ttl = defaultActionTokenTTL
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(actionTokenKeyContext))
+
+	s.actionRepo = actionRepo
+	s.actionKey = mac.Sum(nil)
+	s.actionTTL = ttl
+	s.actionURL = urlTemplate
+}
+
+// UseActionToken verifies a notification action token, claims it and performs its action with
+// perform. The claim is released when the action fails, so the token can be tried again.
+func (s *NotificationService) UseActionToken(
+	ctx context.Context,
+	token string,
+	perform func(ctx context.Context, action *models.NotificationActionToken) error,
+) (*models.NotificationActionToken, error) {
+	if s.actionRepo == nil {
+		return nil, ErrNotificationActionsUnavailable
+	}
+
+	action, err := s.parseActionToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if s.now().Unix() >= action.ExpiresAt {
+		return nil, ErrActionTokenExpired
+	}
+
+	// Plants removed from the collection since the notification are not added back by the action
+	if _, err := s.plantRepo.GetUserPlant(ctx, action.UserID, action.PlantID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: plant is not in the collection", ErrInvalidActionToken)
+		}
+		return nil, fmt.Errorf("failed to get user plant: %w", err)
+	}
+
+	claimed, err := s.actionRepo.Claim(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrActionTokenUsed
+	}
+
+	if err := perform(ctx, action); err != nil {
+		if releaseErr := s.actionRepo.Release(ctx, action.ID); releaseErr != nil {
+			log.Printf("Failed to release notification action token %s: %v", action.ID, releaseErr)
+		}
+		return nil, err
+	}
+	return action, nil
+}
+
+// issueActionToken signs a single-use token of an action on a user's plant
+func (s *NotificationService) issueActionToken(userID uuid.UUID, plantID uuid.UUID, action models.NotificationAction) (string, error) {
+	payload, err := json.Marshal(&models.NotificationActionToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		PlantID:   plantID,
+		Action:    action,
+		ExpiresAt: s.now().Add(s.actionTTL).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode action token: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.signActionToken(encoded)), nil
+}
+
+// parseActionToken verifies the signature of an action token and decodes its claims
+func (s *NotificationService) parseActionToken(token string) (*models.NotificationActionToken, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidActionToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.signActionToken(encoded)) {
+		return nil, ErrInvalidActionToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidActionToken
+	}
+	var action models.NotificationActionToken
+	if err := json.Unmarshal(payload, &action); err != nil {
+		return nil, ErrInvalidActionToken
+	}
+	if action.ID == uuid.Nil || action.Action != models.NotificationActionWater {
+		return nil, ErrInvalidActionToken
+	}
+	return &action, nil
+}
+
+// signActionToken computes the signature of the encoded claims of an action token
+func (s *NotificationService) signActionToken(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.actionKey)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// addWateringActionURLs adds links marking each plant of a watering reminder email watered
+func (s *NotificationService) addWateringActionURLs(digest *models.WateringDigest, email *WateringDigestEmail) {
+	if s.actionRepo == nil || s.actionURL == "" {
+		return
+	}
+	for i, plant := range digest.Plants {
+		token, err := s.issueActionToken(digest.UserID, plant.PlantID, models.NotificationActionWater)
+		if err != nil {
+			log.Printf("Failed to issue watering action token of user %s: %v", digest.UserID, err)
+			continue
+		}
+		email.Plants[i].ActionURL = strings.ReplaceAll(s.actionURL, "{token}", url.PathEscape(token))
+	}
+}
+
+// deleteExpiredActionTokens deletes the used action tokens that can no longer be replayed
+func (s *NotificationService) deleteExpiredActionTokens(ctx context.Context) {
+	if s.actionRepo == nil {
+		return
+	}
+	if _, err := s.actionRepo.DeleteExpired(ctx, s.now()); err != nil {
+		log.Printf("Failed to delete expired notification action tokens: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotificationActionRepository is a mock implementation of the NotificationActionRepository interface
+type MockNotificationActionRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationActionRepository) Claim(ctx context.Context, token *models.NotificationActionToken) (bool, error) {
+	args := m.Called(ctx, token)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationActionRepository) Release(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationActionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// TestNotificationService_UseActionToken tests that a watering action token is performed once, and
+// that forged, expired and failed tokens are handled
+func TestNotificationService_UseActionToken(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockActionRepo := new(MockNotificationActionRepository)
+	service := NewNotificationService(new(MockNotificationRepository), mockPlantRepo, nil, NewNotificationTemplateService(new(MockNotificationTemplateRepository)))
+	service.SetActionTokens(mockActionRepo, "secret", 48*time.Hour, "")
+	now := time.Date(2024, 5, 10, 9, 30, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	userID, plantID := uuid.New(), uuid.New()
+	token, err := service.issueActionToken(userID, plantID, models.NotificationActionWater)
+	assert.NoError(t, err)
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID}, nil)
+	mockActionRepo.On("Claim", ctx, mock.MatchedBy(func(a *models.NotificationActionToken) bool {
+		return a.UserID == userID && a.PlantID == plantID && a.ExpiresAt == now.Add(48*time.Hour).Unix()
+	})).Return(true, nil).Once()
+
+	performed := 0
+	water := func(ctx context.Context, action *models.NotificationActionToken) error {
+		performed++
+		return nil
+	}
+	action, err := service.UseActionToken(ctx, token, water)
+	assert.NoError(t, err)
+	assert.Equal(t, models.NotificationActionWater, action.Action)
+	assert.Equal(t, 1, performed)
+
+	// A replayed token is rejected
+	mockActionRepo.On("Claim", ctx, mock.Anything).Return(false, nil).Once()
+	_, err = service.UseActionToken(ctx, token, water)
+	assert.ErrorIs(t, err, ErrActionTokenUsed)
+	assert.Equal(t, 1, performed)
+
+	// A token signed with another secret, or with changed claims, is rejected
+	other := NewNotificationService(new(MockNotificationRepository), mockPlantRepo, nil, nil)
+	other.SetActionTokens(mockActionRepo, "other secret", 0, "")
+	forged, err := other.issueActionToken(userID, plantID, models.NotificationActionWater)
+	assert.NoError(t, err)
+	_, err = service.UseActionToken(ctx, forged, water)
+	assert.ErrorIs(t, err, ErrInvalidActionToken)
+	_, signature, _ := strings.Cut(token, ".")
+	claims, _, _ := strings.Cut(forged, ".")
+	_, err = service.UseActionToken(ctx, claims+"."+signature, water)
+	assert.ErrorIs(t, err, ErrInvalidActionToken)
+
+	// An expired token is rejected
+	service.now = func() time.Time { return now.Add(49 * time.Hour) }
+	_, err = service.UseActionToken(ctx, token, water)
+	assert.ErrorIs(t, err, ErrActionTokenExpired)
+	service.now = func() time.Time { return now }
+
+	// The claim of a failed action is released so the token can be tried again
+	failing, err := service.issueActionToken(userID, plantID, models.NotificationActionWater)
+	assert.NoError(t, err)
+	mockActionRepo.On("Claim", ctx, mock.Anything).Return(true, nil).Once()
+	mockActionRepo.On("Release", ctx, mock.Anything).Return(nil).Once()
+	_, err = service.UseActionToken(ctx, failing, func(ctx context.Context, action *models.NotificationActionToken) error {
+		return errors.New("database unavailable")
+	})
+	assert.Error(t, err)
+
+	// A plant removed from the collection is not watered
+	removedID := uuid.New()
+	removed, err := service.issueActionToken(userID, removedID, models.NotificationActionWater)
+	assert.NoError(t, err)
+	mockPlantRepo.On("GetUserPlant", ctx, userID, removedID).Return(nil, fmt.Errorf("user plant not found: %w", sql.ErrNoRows))
+	_, err = service.UseActionToken(ctx, removed, water)
+	assert.ErrorIs(t, err, ErrInvalidActionToken)
+	mockActionRepo.AssertExpectations(t)
+}
+
+// TestNotificationService_WateringNotificationActionToken tests that watering notifications and
+// emails carry action tokens, and dry run samples do not
+func TestNotificationService_WateringNotificationActionToken(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+	mockActionRepo := new(MockNotificationActionRepository)
+	mailer := &capturingMailer{}
+	service := NewNotificationService(mockNotificationRepo, mockPlantRepo, mockUserPlantTaskRepo, NewNotificationTemplateService(mockTemplateRepo))
+	service.SetEmailSender(NewEmailSender(mailer), 8)
+	service.SetActionTokens(mockActionRepo, "secret", 0, "https://planter.app/actions/{token}")
+	now := time.Date(2024, 5, 10, 9, 30, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	overdue := now.Add(-24 * time.Hour)
+	userPlant := &models.UserPlant{
+		UserID:       uuid.New(),
+		PlantID:      uuid.New(),
+		Plant:        &models.Plant{Name: "Monstera"},
+		NextWatering: &overdue,
+		UserLanguage: models.LanguageEnglish,
+	}
+	digest := &models.WateringDigest{
+		UserID:   uuid.New(),
+		Name:     "Anna",
+		Email:    "anna@example.com",
+		Language: models.LanguageEnglish,
+		Plants:   []*models.WateringDigestPlant{{PlantID: uuid.New(), Name: "Ficus", NextWatering: now}},
+	}
+	today := truncateToDay(now)
+	mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{userPlant}, nil)
+	mockUserPlantTaskRepo.On("GetDue", ctx, mock.Anything).Return([]*models.UserPlantTask{}, nil)
+	mockTemplateRepo.On("Get", ctx, models.NotificationTypeWatering, models.LanguageEnglish).Return(nil, nil)
+	mockNotificationRepo.On("GetWateringDigests", ctx, today, today.AddDate(0, 0, 1)).Return([]*models.WateringDigest{digest}, nil)
+
+	// The dry run sample has no token
+	stats, err := service.DryRunCareNotifications(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, stats.Sample, 1) {
+		assert.NotContains(t, stats.Sample[0].Payload, "actionToken")
+	}
+
+	var created *models.Notification
+	mockNotificationRepo.On("Create", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*models.Notification)
+	}).Return(nil)
+	mockNotificationRepo.On("ClaimWateringDigest", ctx, digest.UserID, today).Return(true, nil)
+	mockNotificationRepo.On("ClaimWateringReminders", ctx, userPlant.UserID, today).Return(true, nil)
+	mockActionRepo.On("DeleteExpired", ctx, now).Return(int64(2), nil).Once()
+
+	_, err = service.CheckAndCreateCareNotifications(ctx)
+	assert.NoError(t, err)
+	if assert.NotNil(t, created) {
+		action, err := service.parseActionToken(created.Payload["actionToken"].(string))
+		assert.NoError(t, err)
+		assert.Equal(t, userPlant.UserID, action.UserID)
+		assert.Equal(t, userPlant.PlantID, action.PlantID)
+		assert.Equal(t, now.Add(defaultActionTokenTTL).Unix(), action.ExpiresAt)
+	}
+	if assert.Len(t, mailer.sent, 1) {
+		assert.Contains(t, mailer.sent[0].body, "- Ficus\n  Watered it? https://planter.app/actions/")
+	}
+	mockActionRepo.AssertExpectations(t)
+}
@@ -3,34 +3,111 @@ package services
 import (
     "context"
     "fmt"
+    "log"
     "time"
 
+    "github.com/anpanovv/planter/internal/events"
     "github.com/anpanovv/planter/internal/models"
     "github.com/anpanovv/planter/internal/repository"
     "github.com/google/uuid"
 )
 
-// NotificationStats contains statistics about notification processing
+// NotificationStats contains statistics about notification processing. In a dry run nothing is
+// written or sent: the counts are what the check would have created and sent, and Sample holds some
+// of the notifications it would have created.
 type NotificationStats struct {
-    UsersProcessed      int
-    PlantsNeedingWater int
-    NotificationsCreated int
+    DryRun               bool                   `json:"dryRun"`
+    UsersProcessed       int                    `json:"usersProcessed"`
+    PlantsNeedingWater   int                    `json:"plantsNeedingWater"`
+    RemindersHeld        int                    `json:"remindersHeld"` // watering reminders waiting for the owner to come home or for daytime there
+    CareTasksDue         int                    `json:"careTasksDue"`
+    NotificationsCreated int                    `json:"notificationsCreated"`
+    EmailsSent           int                    `json:"emailsSent"`
+    Sample               []*models.Notification `json:"sample,omitempty"`
+}
+
+// notificationDryRunSampleSize is the number of would-be notifications a dry run keeps
+const notificationDryRunSampleSize = 20
+
+// careTaskNotificationTypes maps recurring care tasks to the notifications sent when they are due
+var careTaskNotificationTypes = map[models.CareTaskType]models.NotificationType{
+    models.CareTaskTypeFertilize: models.NotificationTypeFertilizing,
+    models.CareTaskTypeMist:      models.NotificationTypeMisting,
+    models.CareTaskTypeRepot:     models.NotificationTypeRepotting,
+    models.CareTaskTypePrune:     models.NotificationTypePruning,
+}
+
+// NotificationStream pushes newly created notifications to the clients of their recipient that are
+// connected, so they do not have to poll
+type NotificationStream interface {
+    Publish(notification *models.Notification)
 }
 
 // NotificationService handles notification operations
 type NotificationService struct {
-    notificationRepo repository.NotificationRepository
-    plantRepo       repository.PlantRepository
+    notificationRepo  repository.NotificationRepository
+    plantRepo         repository.PlantRepository
+    userPlantTaskRepo repository.UserPlantTaskRepository
+    templates         *NotificationTemplateService
+    publisher         events.Publisher
+    emailSender       *EmailSender // nil when emails are not configured
+    emailHour         int          // UTC hour watering reminder emails are sent from
+    actionRepo        repository.NotificationActionRepository // nil when notification actions are disabled
+    actionKey         []byte                                  // key action tokens are signed with
+    actionTTL         time.Duration                           // how long action tokens can be used
+    actionURL         string                                  // page performing an action, {token} is replaced; empty leaves links out of emails
+    weather           *WeatherService                         // nil when reminders ignore the weather
+    stream            NotificationStream                      // nil when notifications are not streamed
+    reminderRepo      repository.PlantReminderRepository      // nil when users cannot define reminders
+    now               func() time.Time
 }
 
 // NewNotificationService creates a new notification service
-func NewNotificationService(notificationRepo repository.NotificationRepository, plantRepo repository.PlantRepository) *NotificationService {
+func NewNotificationService(
+    notificationRepo repository.NotificationRepository,
+    plantRepo repository.PlantRepository,
+    userPlantTaskRepo repository.UserPlantTaskRepository,
+    templates *NotificationTemplateService,
+) *NotificationService {
     return &NotificationService{
-        notificationRepo: notificationRepo,
-        plantRepo:       plantRepo,
+        notificationRepo:  notificationRepo,
+        plantRepo:         plantRepo,
+        userPlantTaskRepo: userPlantTaskRepo,
+        templates:         templates,
+        publisher:         events.NopPublisher{},
+        now:               time.Now,
     }
 }
 
+// SetEventPublisher sets the publisher domain events are sent to
+func (s *NotificationService) SetEventPublisher(publisher events.Publisher) {
+    s.publisher = publisher
+}
+
+// SetEmailSender sets the sender of the watering reminder emails of users who chose email reminders;
+// they are sent once a day from the given UTC hour. Without it those users get in-app notifications.
+func (s *NotificationService) SetEmailSender(sender *EmailSender, hour int) {
+    s.emailSender = sender
+    s.emailHour = hour
+}
+
+// SetNotificationStream sets the stream new notifications are pushed to
+func (s *NotificationService) SetNotificationStream(stream NotificationStream) {
+    s.stream = stream
+}
+
+// SetWeatherService sets the service watering reminders of plants in outdoor locations follow the
+// weather with
+func (s *NotificationService) SetWeatherService(weather *WeatherService) {
+    s.weather = weather
+}
+
+// SetReminderRepository sets the repository of the reminders users define for their plants, which
+// the care notifications check sends when they are due
+func (s *NotificationService) SetReminderRepository(reminderRepo repository.PlantReminderRepository) {
+    s.reminderRepo = reminderRepo
+}
+
 // GetUserNotifications gets all notifications for a user with pagination
 func (s *NotificationService) GetUserNotifications(ctx context.Context, userID uuid.UUID, page, pageSize int) (*models.NotificationResponse, error) {
     if page < 1 {
@@ -49,6 +126,10 @@ func (s *NotificationService) GetUserNotifications(ctx context.Context, userID u
         }, nil
     }
 
+    for _, notification := range notifications {
+        notification.Display = notificationDisplay(notification)
+    }
+
     return &models.NotificationResponse{
         Notifications: notifications,
         Total:        total,
@@ -64,40 +145,525 @@ func (s *NotificationService) MarkAsRead(ctx context.Context, notificationID uui
     return nil
 }
 
+// GetUnreadCount counts the unread notifications of a user
+func (s *NotificationService) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+    count, err := s.notificationRepo.CountUnread(ctx, userID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+    }
+    return count, nil
+}
+
+// GetBell gets the unread state of the notifications of a user without counting them
+func (s *NotificationService) GetBell(ctx context.Context, userID uuid.UUID) (*models.NotificationBell, error) {
+    bell, err := s.notificationRepo.GetBell(ctx, userID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get notification bell: %w", err)
+    }
+    return bell, nil
+}
+
+// MarkAllAsRead marks all notifications of a user as read and returns the number marked
+func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID uuid.UUID) (int, error) {
+    count, err := s.notificationRepo.MarkAllAsRead(ctx, userID)
+    if err != nil {
+        return 0, fmt.Errorf("failed to mark all notifications as read: %w", err)
+    }
+    return count, nil
+}
+
+// DeleteNotification deletes a notification of a user
+func (s *NotificationService) DeleteNotification(ctx context.Context, notificationID uuid.UUID, userID uuid.UUID) error {
+    if err := s.notificationRepo.Delete(ctx, notificationID, userID); err != nil {
+        return fmt.Errorf("failed to delete notification: %w", err)
+    }
+    return nil
+}
+
+// CheckAndCreateCareNotifications creates notifications for plants that need watering and for
+// recurring care tasks that are due
+func (s *NotificationService) CheckAndCreateCareNotifications(ctx context.Context) (*NotificationStats, error) {
+    return s.checkCareNotifications(ctx, &NotificationStats{})
+}
+
+// DryRunCareNotifications runs the care notifications check without creating notifications, sending
+// emails or rescheduling care tasks, to validate schedule changes against production data
+func (s *NotificationService) DryRunCareNotifications(ctx context.Context) (*NotificationStats, error) {
+    return s.checkCareNotifications(ctx, &NotificationStats{DryRun: true, Sample: []*models.Notification{}})
+}
+
+// checkCareNotifications runs the care notifications check, writing nothing when stats is of a dry run
+func (s *NotificationService) checkCareNotifications(ctx context.Context, stats *NotificationStats) (*NotificationStats, error) {
+    userSet := make(map[uuid.UUID]struct{})
+
+    if err := s.createWateringNotifications(ctx, stats, userSet); err != nil {
+        return nil, err
+    }
+    if err := s.createCareTaskNotifications(ctx, stats, userSet); err != nil {
+        return nil, err
+    }
+    if err := s.createReminderNotifications(ctx, stats, userSet); err != nil {
+        return nil, err
+    }
+    if err := s.sendWateringDigests(ctx, stats, userSet); err != nil {
+        return nil, err
+    }
+    if !stats.DryRun {
+        s.deleteExpiredActionTokens(ctx)
+    }
+
+    stats.UsersProcessed = len(userSet)
+    return stats, nil
+}
+
 // CheckAndCreateWateringNotifications checks for plants that need watering and creates notifications
 func (s *NotificationService) CheckAndCreateWateringNotifications(ctx context.Context) (*NotificationStats, error) {
     stats := &NotificationStats{}
     userSet := make(map[uuid.UUID]struct{})
 
+    if err := s.createWateringNotifications(ctx, stats, userSet); err != nil {
+        return nil, err
+    }
+
+    stats.UsersProcessed = len(userSet)
+    return stats, nil
+}
+
+// createWateringNotifications notifies owners of plants to water on their local day or overdue, once a
+// day in the hours they get reminders in: from their notification hour until the evening, in the time
+// zone they chose or else the one of the plant's home. Plants in outdoor locations follow the weather:
+// rain skips the reminder and moves the watering to the next day, heat sends it up to a day early.
+// Reminders of plants in a home wait while the owner stays at another of their homes.
+func (s *NotificationService) createWateringNotifications(ctx context.Context, stats *NotificationStats, userSet map[uuid.UUID]struct{}) error {
     // Get all user plants
     userPlants, err := s.plantRepo.GetAllUserPlantsForWateringCheck(ctx)
     if err != nil {
-    	return nil, fmt.Errorf("failed to get plants for watering check: %w", err)
+    	return fmt.Errorf("failed to get plants for watering check: %w", err)
     }
    
-    now := time.Now()
+    now := s.now()
+    claims := make(map[uuid.UUID]bool) // whether this check sends the reminders of the day of each owner
     for _, userPlant := range userPlants {
-    	if userPlant.NextWatering != nil && userPlant.NextWatering.Before(now) {
+        if userPlant.NextWatering == nil {
+            continue
+        }
+        day, open := wateringWindow(userPlant, now)
+        due := wateringDue(userPlant, day)
+
+        if awayFromHome(userPlant) || !open {
+            if due {
+                stats.RemindersHeld++
+            }
+            continue
+        }
+        if _, claimed := claims[userPlant.UserID]; !claimed && wateringRemindedOn(userPlant, day) {
+            continue
+        }
+
+        if s.followsWeather(userPlant) && userPlant.NextWatering.Before(now.AddDate(0, 0, 1)) {
+            decision, err := s.weather.WateringDecision(ctx, userPlant.OutdoorLocation)
+            if err != nil {
+                // The reminder is sent as if the plant were indoors
+                log.Printf("Failed to get weather for plant %s of user %s: %v", userPlant.PlantID, userPlant.UserID, err)
+            } else {
+                userPlant.WeatherDecision = decision
+                switch {
+                case decision.Action == models.WateringWeatherSkipped && due:
+                    userSet[userPlant.UserID] = struct{}{}
+                    if err := s.skipWatering(ctx, stats, userPlant, now.AddDate(0, 0, 1)); err != nil {
+                        return err
+                    }
+                    continue
+                case decision.Action == models.WateringWeatherAdvanced:
+                    due = true
+                }
+            }
+        }
+
+    	if due {
             stats.PlantsNeedingWater++
             userSet[userPlant.UserID] = struct{}{}
 
+            // Owners who chose email reminders get the plant in their daily email instead
+            if userPlant.UserReminderChannel == models.ReminderChannelEmail && s.emailSender != nil {
+                continue
+            }
+
+            claimed, err := s.claimWateringReminders(ctx, stats, claims, userPlant, day)
+            if err != nil {
+                return err
+            }
+            if !claimed {
+                continue
+            }
+
     		// Create notification
-    		notification := &models.Notification{
-    			UserID:  userPlant.UserID,
-    			PlantID: userPlant.PlantID,
-    			Type:    models.NotificationTypeWatering,
-    			Message: fmt.Sprintf("Пора полить ваше растение %s!", userPlant.Plant.Name),
-    			IsRead:  false,
-    		}
-   
-    		err := s.notificationRepo.Create(ctx, notification)
+    		err = s.createCheckNotification(ctx, stats, userPlant, models.NotificationTypeWatering, userPlant.NextWatering, nil)
     		if err != nil {
-    			return nil, fmt.Errorf("failed to create watering notification: %w", err)
+    			return fmt.Errorf("failed to create watering notification: %w", err)
     		}
-            stats.NotificationsCreated++
     	}
     }
 
-    stats.UsersProcessed = len(userSet)
-    return stats, nil
-}
\ No newline at end of file
+    return nil
+}
+
+// claimWateringReminders reports whether the check sends the watering reminders of the owner of a plant
+// for the owner's local day. The first of the owner's plants claims the day, so the reminders are sent
+// once a day even with several instances; a dry run claims nothing.
+func (s *NotificationService) claimWateringReminders(
+    ctx context.Context,
+    stats *NotificationStats,
+    claims map[uuid.UUID]bool,
+    userPlant *models.UserPlant,
+    day time.Time,
+) (bool, error) {
+    if claimed, ok := claims[userPlant.UserID]; ok {
+        return claimed, nil
+    }
+
+    claimed := true
+    if !stats.DryRun {
+        var err error
+        claimed, err = s.notificationRepo.ClaimWateringReminders(ctx, userPlant.UserID, day)
+        if err != nil {
+            return false, fmt.Errorf("failed to claim watering reminders: %w", err)
+        }
+    }
+    claims[userPlant.UserID] = claimed
+    return claimed, nil
+}
+
+// followsWeather reports whether the watering reminders of a plant follow the weather
+func (s *NotificationService) followsWeather(userPlant *models.UserPlant) bool {
+    return s.weather != nil && s.weather.Enabled() && userPlant.OutdoorLocation != nil
+}
+
+// skipWatering moves the watering of an outdoor plant rain has watered to the given time and tells the
+// owner why the reminder did not come. Owners who chose email reminders find the plant in a later email.
+func (s *NotificationService) skipWatering(ctx context.Context, stats *NotificationStats, userPlant *models.UserPlant, nextWatering time.Time) error {
+    if !stats.DryRun {
+        if err := s.plantRepo.SetNextWatering(ctx, userPlant.UserID, userPlant.PlantID, nextWatering); err != nil {
+            return fmt.Errorf("failed to postpone watering: %w", err)
+        }
+    }
+    if userPlant.UserReminderChannel == models.ReminderChannelEmail && s.emailSender != nil {
+        return nil
+    }
+
+    err := s.createCheckNotification(ctx, stats, userPlant, models.NotificationTypeWateringSkipped, &nextWatering, nil)
+    if err != nil {
+        return fmt.Errorf("failed to create watering skipped notification: %w", err)
+    }
+    return nil
+}
+
+// createCareTaskNotifications notifies owners of recurring care tasks due today or missed since the
+// last check and moves each task to its next due date
+func (s *NotificationService) createCareTaskNotifications(ctx context.Context, stats *NotificationStats, userSet map[uuid.UUID]struct{}) error {
+    today := truncateToDay(time.Now())
+    tasks, err := s.userPlantTaskRepo.GetDue(ctx, today)
+    if err != nil {
+        return fmt.Errorf("failed to get due care tasks: %w", err)
+    }
+
+    for _, task := range tasks {
+        notificationType, ok := careTaskNotificationTypes[task.Type]
+        if !ok {
+            continue
+        }
+        stats.CareTasksDue++
+        userSet[task.UserID] = struct{}{}
+
+        err := s.createCheckNotification(ctx, stats, task.UserPlant, notificationType, &task.NextDue, nil)
+        if err != nil {
+            return fmt.Errorf("failed to create care task notification: %w", err)
+        }
+        if stats.DryRun {
+            continue
+        }
+
+        if err := s.userPlantTaskRepo.SetNextDue(ctx, task.ID, nextCareTaskDue(task.NextDue, task.FrequencyDays, today)); err != nil {
+            return fmt.Errorf("failed to reschedule care task: %w", err)
+        }
+    }
+
+    return nil
+}
+
+// createReminderNotifications notifies owners of the reminders they defined that are due today or
+// were missed since the last check and moves each reminder to its next due date. Reminders count
+// as care tasks in the stats.
+func (s *NotificationService) createReminderNotifications(ctx context.Context, stats *NotificationStats, userSet map[uuid.UUID]struct{}) error {
+    if s.reminderRepo == nil {
+        return nil
+    }
+
+    today := truncateToDay(time.Now())
+    reminders, err := s.reminderRepo.GetDue(ctx, today)
+    if err != nil {
+        return fmt.Errorf("failed to get due reminders: %w", err)
+    }
+
+    for _, reminder := range reminders {
+        stats.CareTasksDue++
+        userSet[reminder.UserID] = struct{}{}
+
+        fields := models.NotificationPayload{
+            reminderIDField.Name: reminder.ID.String(),
+            reminderTitleField.Name: reminder.Title,
+        }
+        err := s.createCheckNotification(ctx, stats, reminder.UserPlant, models.NotificationTypeReminder, &reminder.NextDue, fields)
+        if err != nil {
+            return fmt.Errorf("failed to create reminder notification: %w", err)
+        }
+        if stats.DryRun {
+            continue
+        }
+
+        if err := s.reminderRepo.SetNextDue(ctx, reminder.ID, nextCareTaskDue(reminder.NextDue, reminder.FrequencyDays, today)); err != nil {
+            return fmt.Errorf("failed to reschedule reminder: %w", err)
+        }
+    }
+
+    return nil
+}
+
+// sendWateringDigests sends users who chose email reminders one email listing every plant to water
+// today, once a day from the configured hour. A failed email is retried at the next check.
+func (s *NotificationService) sendWateringDigests(ctx context.Context, stats *NotificationStats, userSet map[uuid.UUID]struct{}) error {
+    now := s.now().UTC()
+    if s.emailSender == nil || now.Hour() < s.emailHour {
+        return nil
+    }
+
+    today := truncateToDay(now)
+    digests, err := s.notificationRepo.GetWateringDigests(ctx, today, today.AddDate(0, 0, 1))
+    if err != nil {
+        return fmt.Errorf("failed to get watering digests: %w", err)
+    }
+
+    for _, digest := range digests {
+        userSet[digest.UserID] = struct{}{}
+
+        // Users who got today's email are not listed, so a dry run counts the emails a check would send
+        if stats.DryRun {
+            stats.EmailsSent++
+            continue
+        }
+
+        // Claim the day first so instances running the check at the same time send one email
+        claimed, err := s.notificationRepo.ClaimWateringDigest(ctx, digest.UserID, today)
+        if err != nil {
+            return fmt.Errorf("failed to claim watering digest: %w", err)
+        }
+        if !claimed {
+            continue
+        }
+
+        email := wateringDigestEmail(digest, today)
+        s.addWateringActionURLs(digest, &email)
+        if err := s.emailSender.Send(ctx, digest.Email, EmailTypeWateringDigest, digest.Language, email); err != nil {
+            log.Printf("Failed to send watering reminder email to user %s: %v", digest.UserID, err)
+            if err := s.notificationRepo.ReleaseWateringDigest(ctx, digest.UserID, today); err != nil {
+                log.Printf("Failed to release watering digest of user %s: %v", digest.UserID, err)
+            }
+            continue
+        }
+        stats.EmailsSent++
+    }
+
+    return nil
+}
+
+// wateringDigestEmail builds the data of the watering reminder email of a digest
+func wateringDigestEmail(digest *models.WateringDigest, today time.Time) WateringDigestEmail {
+    layout, ok := notificationDateLayouts[digest.Language]
+    if !ok {
+        layout = notificationDateLayouts[models.LanguageRussian]
+    }
+
+    email := WateringDigestEmail{Name: digest.Name}
+    for _, plant := range digest.Plants {
+        item := WateringDigestEmailPlant{
+            Name:    plant.Name,
+            Overdue: plant.NextWatering.Before(today),
+            DueDate: plant.NextWatering.Format(layout),
+        }
+        if plant.Location != nil {
+            item.Location = *plant.Location
+        }
+        email.Plants = append(email.Plants, item)
+    }
+    return email
+}
+
+// createCheckNotification creates a notification of the care notifications check, or adds it to the
+// sample of a dry run
+func (s *NotificationService) createCheckNotification(
+    ctx context.Context,
+    stats *NotificationStats,
+    userPlant *models.UserPlant,
+    notificationType models.NotificationType,
+    dueDate *time.Time,
+    fields models.NotificationPayload,
+) error {
+    notification, err := s.buildPlantNotification(ctx, userPlant, notificationType, dueDate, fields)
+    if err != nil {
+        return err
+    }
+    if !stats.DryRun {
+        if err := s.create(ctx, notification); err != nil {
+            return err
+        }
+        stats.NotificationsCreated++
+        return nil
+    }
+
+    // The sample is shown to admins, who should not be able to act for the owner
+    delete(notification.Payload, actionTokenField.Name)
+    stats.NotificationsCreated++
+    if len(stats.Sample) < notificationDryRunSampleSize {
+        notification.Display = notificationDisplay(notification)
+        stats.Sample = append(stats.Sample, notification)
+    }
+    return nil
+}
+
+// CreatePlantNotification renders a notification about a user's plant in the owner's language and stores it
+func (s *NotificationService) CreatePlantNotification(
+    ctx context.Context,
+    userPlant *models.UserPlant,
+    notificationType models.NotificationType,
+    dueDate *time.Time,
+) error {
+    notification, err := s.buildPlantNotification(ctx, userPlant, notificationType, dueDate, nil)
+    if err != nil {
+        return err
+    }
+    return s.create(ctx, notification)
+}
+
+// buildPlantNotification renders a notification about a user's plant in the owner's language. fields
+// are added to the payload besides the plant and the due date.
+func (s *NotificationService) buildPlantNotification(
+    ctx context.Context,
+    userPlant *models.UserPlant,
+    notificationType models.NotificationType,
+    dueDate *time.Time,
+    fields models.NotificationPayload,
+) (*models.Notification, error) {
+    payload := models.NotificationPayload{"plantId": userPlant.PlantID.String()}
+    for name, value := range fields {
+        payload[name] = value
+    }
+    if dueDate != nil {
+        payload["dueDate"] = dueDate.Format(notificationDateLayout)
+    }
+    if notificationType == models.NotificationTypeWatering && s.actionRepo != nil {
+        token, err := s.issueActionToken(userPlant.UserID, userPlant.PlantID, models.NotificationActionWater)
+        if err != nil {
+            return nil, err
+        }
+        payload[actionTokenField.Name] = token
+    }
+    if decision := userPlant.WeatherDecision; decision != nil {
+        payload[weatherDecisionField.Name] = string(decision.Action)
+        payload[rainfallField.Name] = decision.RainfallMM
+        payload[maxTemperatureField.Name] = decision.MaxTemperature
+    }
+    if err := validateNotificationPayload(notificationType, payload); err != nil {
+        return nil, err
+    }
+
+    // Render the message in the user's language
+    message, err := s.templates.Render(ctx, notificationType, userPlant.UserLanguage,
+        userPlant.Plant, userPlant.Location, dueDate, payload)
+    if err != nil {
+        return nil, fmt.Errorf("failed to render notification: %w", err)
+    }
+
+    plantID := userPlant.PlantID
+    return &models.Notification{
+        UserID:  userPlant.UserID,
+        PlantID: &plantID,
+        Type:    notificationType,
+        Message: message,
+        Payload: payload,
+        IsRead:  false,
+    }, nil
+}
+
+// SendNotification sends a notification of any registered type to a user. The payload must match
+// the fields of the type; a plantId field links the notification to the plant.
+func (s *NotificationService) SendNotification(
+    ctx context.Context,
+    userID uuid.UUID,
+    language models.Language,
+    notificationType models.NotificationType,
+    payload models.NotificationPayload,
+) (*models.Notification, error) {
+    if err := validateNotificationPayload(notificationType, payload); err != nil {
+        return nil, err
+    }
+
+    notification := &models.Notification{
+        UserID:  userID,
+        Type:    notificationType,
+        Payload: payload,
+        IsRead:  false,
+    }
+
+    var plant *models.Plant
+    if value, ok := payload["plantId"].(string); ok {
+        plantID := uuid.MustParse(value)
+        var err error
+        plant, err = s.plantRepo.GetByID(ctx, plantID)
+        if err != nil {
+            return nil, fmt.Errorf("failed to get plant: %w", err)
+        }
+        notification.PlantID = &plantID
+    }
+
+    var dueDate *time.Time
+    if value, ok := payload["dueDate"].(string); ok {
+        date, _ := time.Parse(notificationDateLayout, value)
+        dueDate = &date
+    }
+
+    // Render the message in the user's language
+    message, err := s.templates.Render(ctx, notificationType, language, plant, nil, dueDate, payload)
+    if err != nil {
+        return nil, fmt.Errorf("failed to render notification: %w", err)
+    }
+    notification.Message = message
+
+    if err := s.create(ctx, notification); err != nil {
+        return nil, err
+    }
+    notification.Display = notificationDisplay(notification)
+    return notification, nil
+}
+
+// create stores a notification and publishes its creation
+func (s *NotificationService) create(ctx context.Context, notification *models.Notification) error {
+    err := s.notificationRepo.Create(ctx, notification)
+    if err != nil {
+        return fmt.Errorf("failed to create notification: %w", err)
+    }
+
+    publishEvent(ctx, s.publisher, events.NotificationCreated{
+        UserID:     notification.UserID,
+        PlantID:    notification.PlantID,
+        Type:       string(notification.Type),
+        Message:    notification.Message,
+        Payload:    notification.Payload,
+        OccurredAt: time.Now(),
+    })
+
+    if s.stream != nil {
+        notification.Display = notificationDisplay(notification)
+        s.stream.Publish(notification)
+    }
+    return nil
+}
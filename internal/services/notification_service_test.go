@@ -2,6 +2,7 @@ package services
 
 import (
     "context"
+    "database/sql"
     "fmt"
     "testing"
     "time"
@@ -37,6 +38,49 @@ func (m *MockNotificationRepository) GetUnreadWateringNotifications(ctx context.
     return args.Get(0).([]*models.Notification), args.Error(1)
 }
 
+func (m *MockNotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+    args := m.Called(ctx, userID)
+    return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetBell(ctx context.Context, userID uuid.UUID) (*models.NotificationBell, error) {
+    args := m.Called(ctx, userID)
+    if args.Get(0) == nil {
+        return nil, args.Error(1)
+    }
+    return args.Get(0).(*models.NotificationBell), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) (int, error) {
+    args := m.Called(ctx, userID)
+    return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) Delete(ctx context.Context, notificationID uuid.UUID, userID uuid.UUID) error {
+    args := m.Called(ctx, notificationID, userID)
+    return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetWateringDigests(ctx context.Context, day time.Time, dueBefore time.Time) ([]*models.WateringDigest, error) {
+    args := m.Called(ctx, day, dueBefore)
+    return args.Get(0).([]*models.WateringDigest), args.Error(1)
+}
+
+func (m *MockNotificationRepository) ClaimWateringDigest(ctx context.Context, userID uuid.UUID, day time.Time) (bool, error) {
+    args := m.Called(ctx, userID, day)
+    return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) ReleaseWateringDigest(ctx context.Context, userID uuid.UUID, day time.Time) error {
+    args := m.Called(ctx, userID, day)
+    return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ClaimWateringReminders(ctx context.Context, userID uuid.UUID, day time.Time) (bool, error) {
+    args := m.Called(ctx, userID, day)
+    return args.Bool(0), args.Error(1)
+}
+
 func (m *MockPlantRepository) GetAllUserPlantsForWateringCheck(ctx context.Context) ([]*models.UserPlant, error) {
     args := m.Called(ctx)
     if args.Get(0) == nil {
@@ -51,7 +95,7 @@ func TestNotificationService_GetUserNotifications(t *testing.T) {
     mockPlantRepo := new(MockPlantRepository)
 
     // Create service
-    service := NewNotificationService(mockNotificationRepo, mockPlantRepo)
+    service := NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(new(MockNotificationTemplateRepository)))
 
     // Test data
     ctx := context.Background()
@@ -86,7 +130,7 @@ func TestNotificationService_MarkAsRead(t *testing.T) {
     mockPlantRepo := new(MockPlantRepository)
 
     // Create service
-    service := NewNotificationService(mockNotificationRepo, mockPlantRepo)
+    service := NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(new(MockNotificationTemplateRepository)))
 
     // Test data
     ctx := context.Background()
@@ -104,18 +148,59 @@ func TestNotificationService_MarkAsRead(t *testing.T) {
     mockNotificationRepo.AssertExpectations(t)
 }
 
+func TestNotificationService_MarkAllAsRead(t *testing.T) {
+    mockNotificationRepo := new(MockNotificationRepository)
+    service := NewNotificationService(mockNotificationRepo, new(MockPlantRepository), nil, NewNotificationTemplateService(new(MockNotificationTemplateRepository)))
+
+    ctx := context.Background()
+    userID := uuid.New()
+    mockNotificationRepo.On("CountUnread", ctx, userID).Return(3, nil).Once()
+    mockNotificationRepo.On("MarkAllAsRead", ctx, userID).Return(3, nil)
+    mockNotificationRepo.On("CountUnread", ctx, userID).Return(0, nil).Once()
+
+    count, err := service.GetUnreadCount(ctx, userID)
+    assert.NoError(t, err)
+    assert.Equal(t, 3, count)
+
+    marked, err := service.MarkAllAsRead(ctx, userID)
+    assert.NoError(t, err)
+    assert.Equal(t, 3, marked)
+
+    count, err = service.GetUnreadCount(ctx, userID)
+    assert.NoError(t, err)
+    assert.Equal(t, 0, count)
+    mockNotificationRepo.AssertExpectations(t)
+}
+
+func TestNotificationService_DeleteNotification(t *testing.T) {
+    mockNotificationRepo := new(MockNotificationRepository)
+    service := NewNotificationService(mockNotificationRepo, new(MockPlantRepository), nil, NewNotificationTemplateService(new(MockNotificationTemplateRepository)))
+
+    ctx := context.Background()
+    userID, notificationID := uuid.New(), uuid.New()
+    mockNotificationRepo.On("Delete", ctx, notificationID, userID).Return(fmt.Errorf("notification not found: %w", sql.ErrNoRows))
+
+    // Notifications of other users are reported as not found
+    err := service.DeleteNotification(ctx, notificationID, userID)
+    assert.ErrorIs(t, err, sql.ErrNoRows)
+    mockNotificationRepo.AssertExpectations(t)
+}
+
 func TestNotificationService_CheckAndCreateWateringNotifications(t *testing.T) {
     // Create mocks
     mockNotificationRepo := new(MockNotificationRepository)
     mockPlantRepo := new(MockPlantRepository)
+    mockTemplateRepo := new(MockNotificationTemplateRepository)
 
     // Create service
-    service := NewNotificationService(mockNotificationRepo, mockPlantRepo)
+    service := NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(mockTemplateRepo))
+    now := time.Date(2024, 5, 10, 9, 30, 0, 0, time.UTC)
+    service.now = func() time.Time { return now }
 
     // Test data
     ctx := context.Background()
     userID := uuid.New()
-    nextWatering := time.Now().Add(-24 * time.Hour) // Plant needs watering
+    nextWatering := now.Add(-24 * time.Hour) // Plant needs watering
     
     userPlant := &models.UserPlant{
         ID:           uuid.New(),
@@ -126,18 +211,22 @@ func TestNotificationService_CheckAndCreateWateringNotifications(t *testing.T) {
             ID:   uuid.New(),
             Name: "Test Plant",
         },
+        UserLanguage: models.LanguageEnglish,
     }
 
     userPlants := []*models.UserPlant{userPlant}
 
     // Set up expectations
     mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return(userPlants, nil)
+    mockTemplateRepo.On("Get", ctx, models.NotificationTypeWatering, models.LanguageEnglish).Return(nil, nil)
+    mockNotificationRepo.On("ClaimWateringReminders", ctx, userID, truncateToDay(now)).Return(true, nil)
     mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
-        return n.UserID == userID && n.PlantID == userPlant.PlantID && n.Type == models.NotificationTypeWatering
+        return n.UserID == userID && *n.PlantID == userPlant.PlantID && n.Type == models.NotificationTypeWatering &&
+            n.Message == "Time to water your Test Plant!" && n.Payload["plantId"] == userPlant.PlantID.String()
     })).Return(nil)
 
     // Call the service
-    err := service.CheckAndCreateWateringNotifications(ctx)
+    _, err := service.CheckAndCreateWateringNotifications(ctx)
 
     // Assert
     assert.NoError(t, err)
@@ -145,13 +234,64 @@ func TestNotificationService_CheckAndCreateWateringNotifications(t *testing.T) {
     mockNotificationRepo.AssertExpectations(t)
 }
 
+func TestNotificationService_CheckAndCreateCareNotifications(t *testing.T) {
+    // Create mocks
+    mockNotificationRepo := new(MockNotificationRepository)
+    mockPlantRepo := new(MockPlantRepository)
+    mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+    mockTemplateRepo := new(MockNotificationTemplateRepository)
+
+    // Create service
+    service := NewNotificationService(mockNotificationRepo, mockPlantRepo, mockUserPlantTaskRepo, NewNotificationTemplateService(mockTemplateRepo))
+
+    // Test data: fertilizing was due two days ago and is repeated every 14 days
+    ctx := context.Background()
+    today := truncateToDay(time.Now())
+    userPlant := &models.UserPlant{
+        UserID:       uuid.New(),
+        PlantID:      uuid.New(),
+        Plant:        &models.Plant{Name: "Test Plant"},
+        UserLanguage: models.LanguageEnglish,
+    }
+    task := &models.UserPlantTask{
+        ID:            uuid.New(),
+        UserID:        userPlant.UserID,
+        PlantID:       userPlant.PlantID,
+        Type:          models.CareTaskTypeFertilize,
+        FrequencyDays: 14,
+        NextDue:       today.AddDate(0, 0, -2),
+        UserPlant:     userPlant,
+    }
+
+    // Set up expectations
+    mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{}, nil)
+    mockUserPlantTaskRepo.On("GetDue", ctx, today).Return([]*models.UserPlantTask{task}, nil)
+    mockTemplateRepo.On("Get", ctx, models.NotificationTypeFertilizing, models.LanguageEnglish).Return(nil, nil)
+    mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+        return n.UserID == userPlant.UserID && n.Type == models.NotificationTypeFertilizing &&
+            n.Message == "Time to fertilize your Test Plant!"
+    })).Return(nil)
+    mockUserPlantTaskRepo.On("SetNextDue", ctx, task.ID, today.AddDate(0, 0, 12)).Return(nil)
+
+    // Call the service
+    stats, err := service.CheckAndCreateCareNotifications(ctx)
+
+    // Assert
+    assert.NoError(t, err)
+    assert.Equal(t, 1, stats.CareTasksDue)
+    assert.Equal(t, 1, stats.NotificationsCreated)
+    assert.Equal(t, 1, stats.UsersProcessed)
+    mockNotificationRepo.AssertExpectations(t)
+    mockUserPlantTaskRepo.AssertExpectations(t)
+}
+
 func TestNotificationService_GetUserNotifications_NoNotifications(t *testing.T) {
     // Create mocks
     mockNotificationRepo := new(MockNotificationRepository)
     mockPlantRepo := new(MockPlantRepository)
 
     // Create service
-    service := NewNotificationService(mockNotificationRepo, mockPlantRepo)
+    service := NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(new(MockNotificationTemplateRepository)))
 
     // Test data
     ctx := context.Background()
@@ -169,4 +309,168 @@ func TestNotificationService_GetUserNotifications_NoNotifications(t *testing.T)
     assert.Empty(t, response.Notifications)
     assert.Equal(t, 0, response.Total)
     mockNotificationRepo.AssertExpectations(t)
-} 
\ No newline at end of file
+} 
+
+// TestNotificationService_CheckAndCreateCareNotifications_EmailDigest tests that plants of owners who
+// chose email reminders are left out of the in-app notifications and sent in one email a day, which is
+// released for the next check when it cannot be sent
+func TestNotificationService_CheckAndCreateCareNotifications_EmailDigest(t *testing.T) {
+    // Create mocks
+    mockNotificationRepo := new(MockNotificationRepository)
+    mockPlantRepo := new(MockPlantRepository)
+    mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+    mailer := &capturingMailer{failFor: map[string]bool{"broken@example.com": true}}
+
+    // Create service sending emails from 8:00 UTC, checked at 9:30
+    service := NewNotificationService(mockNotificationRepo, mockPlantRepo, mockUserPlantTaskRepo, NewNotificationTemplateService(new(MockNotificationTemplateRepository)))
+    service.SetEmailSender(NewEmailSender(mailer), 8)
+    now := time.Date(2024, 5, 10, 9, 30, 0, 0, time.UTC)
+    service.now = func() time.Time { return now }
+
+    // Test data: an overdue plant of an email user and two digests, one to a failing mailbox
+    ctx := context.Background()
+    today := truncateToDay(now)
+    overdue := time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC)
+    kitchen := "Kitchen"
+    emailUser := &models.UserPlant{
+        UserID:              uuid.New(),
+        PlantID:             uuid.New(),
+        Plant:               &models.Plant{Name: "Monstera"},
+        NextWatering:        &overdue,
+        UserReminderChannel: models.ReminderChannelEmail,
+    }
+    digest := &models.WateringDigest{
+        UserID:   emailUser.UserID,
+        Name:     "Anna",
+        Email:    "anna@example.com",
+        Language: models.LanguageEnglish,
+        Plants: []*models.WateringDigestPlant{
+            {Name: "Monstera", Location: &kitchen, NextWatering: overdue},
+            {Name: "Ficus", NextWatering: today.Add(15 * time.Hour)},
+        },
+    }
+    failing := &models.WateringDigest{
+        UserID:   uuid.New(),
+        Name:     "Boris",
+        Email:    "broken@example.com",
+        Language: models.LanguageRussian,
+        Plants:   []*models.WateringDigestPlant{{Name: "Aloe", NextWatering: today}},
+    }
+
+    // Set up expectations: no in-app notification is created for the email user
+    mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{emailUser}, nil)
+    mockUserPlantTaskRepo.On("GetDue", ctx, mock.Anything).Return([]*models.UserPlantTask{}, nil)
+    mockNotificationRepo.On("GetWateringDigests", ctx, today, today.AddDate(0, 0, 1)).Return([]*models.WateringDigest{digest, failing}, nil)
+    mockNotificationRepo.On("ClaimWateringDigest", ctx, digest.UserID, today).Return(true, nil)
+    mockNotificationRepo.On("ClaimWateringDigest", ctx, failing.UserID, today).Return(true, nil)
+    mockNotificationRepo.On("ReleaseWateringDigest", ctx, failing.UserID, today).Return(nil)
+
+    // Call the service
+    stats, err := service.CheckAndCreateCareNotifications(ctx)
+
+    // Assert
+    assert.NoError(t, err)
+    assert.Equal(t, 1, stats.PlantsNeedingWater)
+    assert.Equal(t, 0, stats.NotificationsCreated)
+    assert.Equal(t, 1, stats.EmailsSent)
+    assert.Equal(t, 2, stats.UsersProcessed)
+    if assert.Len(t, mailer.sent, 1) {
+        assert.Equal(t, "anna@example.com", mailer.sent[0].to)
+        assert.Equal(t, "Time to water your plants", mailer.sent[0].subject)
+        assert.Contains(t, mailer.sent[0].body, "- Monstera (Kitchen), due since May 8, 2024")
+        assert.Contains(t, mailer.sent[0].body, "- Ficus\n")
+    }
+    mockNotificationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+    mockNotificationRepo.AssertExpectations(t)
+}
+
+// TestNotificationService_CheckAndCreateCareNotifications_BeforeEmailHour tests that no email is sent
+// before the configured hour
+func TestNotificationService_CheckAndCreateCareNotifications_BeforeEmailHour(t *testing.T) {
+    // Create mocks
+    mockNotificationRepo := new(MockNotificationRepository)
+    mockPlantRepo := new(MockPlantRepository)
+    mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+    mailer := &capturingMailer{}
+
+    // Create service sending emails from 8:00 UTC, checked at 7:59
+    service := NewNotificationService(mockNotificationRepo, mockPlantRepo, mockUserPlantTaskRepo, NewNotificationTemplateService(new(MockNotificationTemplateRepository)))
+    service.SetEmailSender(NewEmailSender(mailer), 8)
+    service.now = func() time.Time { return time.Date(2024, 5, 10, 7, 59, 0, 0, time.UTC) }
+
+    // Set up expectations
+    ctx := context.Background()
+    mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{}, nil)
+    mockUserPlantTaskRepo.On("GetDue", ctx, mock.Anything).Return([]*models.UserPlantTask{}, nil)
+
+    // Call the service
+    stats, err := service.CheckAndCreateCareNotifications(ctx)
+
+    // Assert
+    assert.NoError(t, err)
+    assert.Equal(t, 0, stats.EmailsSent)
+    assert.Empty(t, mailer.sent)
+    mockNotificationRepo.AssertNotCalled(t, "GetWateringDigests", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestNotificationService_DryRunCareNotifications tests that a dry run counts and samples the
+// notifications and emails of a check without creating, sending or rescheduling anything
+func TestNotificationService_DryRunCareNotifications(t *testing.T) {
+    // Create mocks
+    mockNotificationRepo := new(MockNotificationRepository)
+    mockPlantRepo := new(MockPlantRepository)
+    mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+    mockTemplateRepo := new(MockNotificationTemplateRepository)
+    mailer := &capturingMailer{}
+
+    // Create service sending emails from 8:00 UTC, checked at 9:30
+    service := NewNotificationService(mockNotificationRepo, mockPlantRepo, mockUserPlantTaskRepo, NewNotificationTemplateService(mockTemplateRepo))
+    service.SetEmailSender(NewEmailSender(mailer), 8)
+    now := time.Date(2024, 5, 10, 9, 30, 0, 0, time.UTC)
+    service.now = func() time.Time { return now }
+
+    // Test data: an overdue plant, a due fertilizing task and a watering email
+    ctx := context.Background()
+    overdue := now.Add(-24 * time.Hour)
+    userPlant := &models.UserPlant{
+        UserID:       uuid.New(),
+        PlantID:      uuid.New(),
+        Plant:        &models.Plant{Name: "Test Plant"},
+        NextWatering: &overdue,
+        UserLanguage: models.LanguageEnglish,
+    }
+    task := &models.UserPlantTask{
+        ID:            uuid.New(),
+        UserID:        userPlant.UserID,
+        PlantID:       userPlant.PlantID,
+        Type:          models.CareTaskTypeFertilize,
+        FrequencyDays: 14,
+        NextDue:       truncateToDay(time.Now()),
+        UserPlant:     userPlant,
+    }
+    digest := &models.WateringDigest{UserID: uuid.New(), Email: "anna@example.com", Language: models.LanguageEnglish}
+
+    // Set up expectations
+    mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{userPlant}, nil)
+    mockUserPlantTaskRepo.On("GetDue", ctx, mock.Anything).Return([]*models.UserPlantTask{task}, nil)
+    mockTemplateRepo.On("Get", ctx, mock.Anything, models.LanguageEnglish).Return(nil, nil)
+    mockNotificationRepo.On("GetWateringDigests", ctx, mock.Anything, mock.Anything).Return([]*models.WateringDigest{digest}, nil)
+
+    // Call the service
+    stats, err := service.DryRunCareNotifications(ctx)
+
+    // Assert
+    assert.NoError(t, err)
+    assert.True(t, stats.DryRun)
+    assert.Equal(t, 2, stats.NotificationsCreated)
+    assert.Equal(t, 1, stats.EmailsSent)
+    assert.Equal(t, 2, stats.UsersProcessed)
+    if assert.Len(t, stats.Sample, 2) {
+        assert.Equal(t, "Time to water your Test Plant!", stats.Sample[0].Message)
+        assert.Equal(t, models.NotificationTypeFertilizing, stats.Sample[1].Type)
+    }
+    assert.Empty(t, mailer.sent)
+    mockNotificationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+    mockNotificationRepo.AssertNotCalled(t, "ClaimWateringDigest", mock.Anything, mock.Anything, mock.Anything)
+    mockUserPlantTaskRepo.AssertNotCalled(t, "SetNextDue", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+)
+
+//go:embed templates/notifications.json
+var defaultNotificationTemplatesJSON []byte
+
+// defaultNotificationTemplates holds the built-in templates by notification type and language
+var defaultNotificationTemplates = mustLoadNotificationTemplates(defaultNotificationTemplatesJSON)
+
+// notificationDateLayouts holds the due date layout for each supported language
+var notificationDateLayouts = map[models.Language]string{
+	models.LanguageRussian: "02.01.2006",
+	models.LanguageEnglish: "Jan 2, 2006",
+}
+
+// NotificationTemplateService renders notification messages from per-type, per-language
+// templates. Templates stored in the database override the built-in ones.
+type NotificationTemplateService struct {
+	templateRepo repository.NotificationTemplateRepository
+}
+
+// NewNotificationTemplateService creates a new notification template service
+func NewNotificationTemplateService(templateRepo repository.NotificationTemplateRepository) *NotificationTemplateService {
+	return &NotificationTemplateService{
+		templateRepo: templateRepo,
+	}
+}
+
+// Render renders the message of a notification type in a language. Unsupported languages
+// fall back to Russian. The payload fields are available to templates as .Payload.
+func (s *NotificationTemplateService) Render(
+	ctx context.Context,
+	notificationType models.NotificationType,
+	language models.Language,
+	plant *models.Plant,
+	location *string,
+	dueDate *time.Time,
+	payload models.NotificationPayload,
+) (string, error) {
+	if _, ok := notificationDateLayouts[language]; !ok {
+		language = models.LanguageRussian
+	}
+
+	body, err := s.templateBody(ctx, notificationType, language)
+	if err != nil {
+		return "", err
+	}
+
+	data := models.NotificationTemplateData{Payload: payload}
+	if plant != nil {
+		data.PlantName = plant.Name
+	}
+	if location != nil {
+		data.Location = *location
+	}
+	if dueDate != nil {
+		data.DueDate = dueDate.Format(notificationDateLayouts[language])
+	}
+
+	return executeNotificationTemplate(body, data)
+}
+
+// GetTemplates gets the effective template of every known notification type and language
+func (s *NotificationTemplateService) GetTemplates(ctx context.Context) ([]*models.NotificationTemplate, error) {
+	stored, err := s.templateRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification templates: %w", err)
+	}
+
+	effective := make(map[string]*models.NotificationTemplate)
+	for notificationType, byLanguage := range defaultNotificationTemplates {
+		for language, body := range byLanguage {
+			effective[string(notificationType)+"/"+string(language)] = &models.NotificationTemplate{
+				Type:      notificationType,
+				Language:  language,
+				Body:      body,
+				IsDefault: true,
+			}
+		}
+	}
+	for _, override := range stored {
+		effective[string(override.Type)+"/"+string(override.Language)] = override
+	}
+
+	templates := make([]*models.NotificationTemplate, 0, len(effective))
+	for _, notificationTemplate := range effective {
+		templates = append(templates, notificationTemplate)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Type != templates[j].Type {
+			return templates[i].Type < templates[j].Type
+		}
+		return templates[i].Language < templates[j].Language
+	})
+
+	return templates, nil
+}
+
+// UpdateTemplate stores a template override after checking that it renders
+func (s *NotificationTemplateService) UpdateTemplate(
+	ctx context.Context,
+	notificationType models.NotificationType,
+	language models.Language,
+	body string,
+) (*models.NotificationTemplate, error) {
+	if _, ok := defaultNotificationTemplates[notificationType]; !ok {
+		return nil, fmt.Errorf("unknown notification type: %s", notificationType)
+	}
+	if _, ok := notificationDateLayouts[language]; !ok {
+		return nil, fmt.Errorf("unsupported language: %s", language)
+	}
+
+	// Reject templates that do not parse or use unknown variables
+	if _, err := executeNotificationTemplate(body, models.NotificationTemplateData{}); err != nil {
+		return nil, err
+	}
+
+	notificationTemplate := &models.NotificationTemplate{
+		Type:     notificationType,
+		Language: language,
+		Body:     body,
+	}
+	err := s.templateRepo.Upsert(ctx, notificationTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save notification template: %w", err)
+	}
+	return notificationTemplate, nil
+}
+
+// templateBody returns the stored template body or the built-in one
+func (s *NotificationTemplateService) templateBody(ctx context.Context, notificationType models.NotificationType, language models.Language) (string, error) {
+	stored, err := s.templateRepo.Get(ctx, notificationType, language)
+	if err != nil {
+		return "", fmt.Errorf("failed to get notification template: %w", err)
+	}
+	if stored != nil {
+		return stored.Body, nil
+	}
+
+	byLanguage, ok := defaultNotificationTemplates[notificationType]
+	if !ok {
+		return "", fmt.Errorf("no template for notification type %s", notificationType)
+	}
+	if body, ok := byLanguage[language]; ok {
+		return body, nil
+	}
+	if body, ok := byLanguage[models.LanguageRussian]; ok {
+		return body, nil
+	}
+	return "", fmt.Errorf("no template for notification type %s in %s", notificationType, language)
+}
+
+// executeNotificationTemplate parses and executes a template body
+func executeNotificationTemplate(body string, data models.NotificationTemplateData) (string, error) {
+	tmpl, err := template.New("notification").Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("invalid notification template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render notification template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// mustLoadNotificationTemplates parses the built-in templates and panics if they are invalid
+func mustLoadNotificationTemplates(data []byte) map[models.NotificationType]map[models.Language]string {
+	var templates map[models.NotificationType]map[models.Language]string
+	if err := json.Unmarshal(data, &templates); err != nil {
+		panic(fmt.Sprintf("invalid built-in notification templates: %v", err))
+	}
+	for notificationType, byLanguage := range templates {
+		if _, ok := notificationTypes[notificationType]; !ok {
+			panic(fmt.Sprintf("built-in template for unregistered notification type %s", notificationType))
+		}
+		for language, body := range byLanguage {
+			if _, err := executeNotificationTemplate(body, models.NotificationTemplateData{}); err != nil {
+				panic(fmt.Sprintf("invalid built-in %s template in %s: %v", notificationType, language, err))
+			}
+		}
+	}
+	return templates
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotificationTemplateRepository is a mock implementation of the NotificationTemplateRepository interface
+type MockNotificationTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationTemplateRepository) Get(ctx context.Context, notificationType models.NotificationType, language models.Language) (*models.NotificationTemplate, error) {
+	args := m.Called(ctx, notificationType, language)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationTemplate), args.Error(1)
+}
+
+func (m *MockNotificationTemplateRepository) GetAll(ctx context.Context) ([]*models.NotificationTemplate, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.NotificationTemplate), args.Error(1)
+}
+
+func (m *MockNotificationTemplateRepository) Upsert(ctx context.Context, template *models.NotificationTemplate) error {
+	args := m.Called(ctx, template)
+	return args.Error(0)
+}
+
+// TestNotificationTemplateService_Render tests built-in templates, overrides and the language fallback
+func TestNotificationTemplateService_Render(t *testing.T) {
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	service := NewNotificationTemplateService(mockTemplateRepo)
+
+	ctx := context.Background()
+	plant := &models.Plant{Name: "Фикус"}
+	location := "Кухня"
+	dueDate := time.Date(2024, time.May, 14, 9, 0, 0, 0, time.UTC)
+
+	mockTemplateRepo.On("Get", ctx, models.NotificationTypeWatering, models.LanguageRussian).Return(nil, nil)
+	mockTemplateRepo.On("Get", ctx, models.NotificationTypeWatering, models.LanguageEnglish).Return(&models.NotificationTemplate{
+		Body: "Water {{.PlantName}} in the {{.Location}} by {{.DueDate}}",
+	}, nil)
+
+	// Built-in template; unsupported languages fall back to Russian
+	message, err := service.Render(ctx, models.NotificationTypeWatering, models.Language("GERMAN"), plant, &location, &dueDate, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Пора полить ваше растение Фикус!", message)
+
+	// Stored override with all variables
+	message, err = service.Render(ctx, models.NotificationTypeWatering, models.LanguageEnglish, plant, &location, &dueDate, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Water Фикус in the Кухня by May 14, 2024", message)
+}
+
+// TestNotificationTemplateService_UpdateTemplate_Invalid tests that broken templates are rejected
+func TestNotificationTemplateService_UpdateTemplate_Invalid(t *testing.T) {
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	service := NewNotificationTemplateService(mockTemplateRepo)
+
+	_, err := service.UpdateTemplate(context.Background(), models.NotificationTypeWatering, models.LanguageEnglish, "Water {{.Unknown}}")
+	assert.Error(t, err)
+
+	_, err = service.UpdateTemplate(context.Background(), models.NotificationTypeWatering, models.LanguageEnglish, "Water {{.PlantName")
+	assert.Error(t, err)
+
+	_, err = service.UpdateTemplate(context.Background(), models.NotificationType("UNKNOWN"), models.LanguageEnglish, "Hi")
+	assert.Error(t, err)
+
+	mockTemplateRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything)
+}
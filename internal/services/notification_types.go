@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidNotification is returned for notifications of unknown types or with payloads that do not
+// match the fields of their type
+var ErrInvalidNotification = errors.New("invalid notification")
+
+// notificationDateLayout is the layout of DATE payload fields
+const notificationDateLayout = "2006-01-02"
+
+// Payload fields of notifications about a plant in the user's collection
+var (
+	plantIDField = models.NotificationField{Name: "plantId", Type: models.NotificationFieldTypeUUID, Required: true}
+	dueDateField = models.NotificationField{Name: "dueDate", Type: models.NotificationFieldTypeDate}
+	// actionTokenField holds a token the recipient can mark the plant watered with, see UseActionToken
+	actionTokenField = models.NotificationField{Name: "actionToken", Type: models.NotificationFieldTypeString}
+)
+
+// Payload fields of reminders users define for their plants
+var (
+	reminderIDField    = models.NotificationField{Name: "reminderId", Type: models.NotificationFieldTypeUUID, Required: true}
+	reminderTitleField = models.NotificationField{Name: "title", Type: models.NotificationFieldTypeString, Required: true}
+)
+
+// Payload fields recording how the weather changed the watering reminder of an outdoor plant
+var (
+	weatherDecisionField = models.NotificationField{Name: "weatherDecision", Type: models.NotificationFieldTypeString}
+	rainfallField        = models.NotificationField{Name: "rainfallMm", Type: models.NotificationFieldTypeNumber}
+	maxTemperatureField  = models.NotificationField{Name: "maxTemperature", Type: models.NotificationFieldTypeNumber}
+)
+
+// notificationTypes is the registry of notification types. A new kind of notification needs an entry
+// here and its templates; its data is kept in the payload, so the notifications table stays the same.
+var notificationTypes = map[models.NotificationType]*models.NotificationTypeDefinition{
+	models.NotificationTypeWatering: {
+		Category: models.NotificationCategoryCare,
+		Icon:     "water_drop",
+		Action:   "planter://plants/{plantId}",
+		Fields: []models.NotificationField{
+			plantIDField, dueDateField, actionTokenField,
+			weatherDecisionField, rainfallField, maxTemperatureField,
+		},
+	},
+	models.NotificationTypeWateringSkipped: {
+		Category: models.NotificationCategoryCare,
+		Icon:     "rainy",
+		Action:   "planter://plants/{plantId}",
+		Fields: []models.NotificationField{
+			plantIDField, dueDateField,
+			weatherDecisionField, rainfallField, maxTemperatureField,
+		},
+	},
+	models.NotificationTypeFertilizing: {
+		Category: models.NotificationCategoryCare,
+		Icon:     "compost",
+		Action:   "planter://plants/{plantId}",
+		Fields:   []models.NotificationField{plantIDField, dueDateField},
+	},
+	models.NotificationTypeMisting: {
+		Category: models.NotificationCategoryCare,
+		Icon:     "shower",
+		Action:   "planter://plants/{plantId}",
+		Fields:   []models.NotificationField{plantIDField, dueDateField},
+	},
+	models.NotificationTypePruning: {
+		Category: models.NotificationCategoryCare,
+		Icon:     "content_cut",
+		Action:   "planter://plants/{plantId}",
+		Fields:   []models.NotificationField{plantIDField, dueDateField},
+	},
+	models.NotificationTypeReminder: {
+		Category: models.NotificationCategoryCare,
+		Icon:     "notifications_active",
+		Action:   "planter://plants/{plantId}/reminders",
+		Fields:   []models.NotificationField{plantIDField, dueDateField, reminderIDField, reminderTitleField},
+	},
+	models.NotificationTypeRepotting: {
+		Category: models.NotificationCategorySeason,
+		Icon:     "potted_plant",
+		Action:   "planter://plants/{plantId}/care-plan",
+		Fields:   []models.NotificationField{plantIDField, dueDateField},
+	},
+	models.NotificationTypeFertilizingSeason: {
+		Category: models.NotificationCategorySeason,
+		Icon:     "eco",
+		Action:   "planter://plants/{plantId}/care-plan",
+		Fields:   []models.NotificationField{plantIDField, dueDateField},
+	},
+	models.NotificationTypeDormancy: {
+		Category: models.NotificationCategorySeason,
+		Icon:     "bedtime",
+		Action:   "planter://plants/{plantId}/care-plan",
+		Fields:   []models.NotificationField{plantIDField, dueDateField},
+	},
+	models.NotificationTypeCareFeedback: {
+		Category: models.NotificationCategoryFeedback,
+		Icon:     "rate_review",
+		Action:   "planter://plants/{plantId}/feedback",
+		Fields:   []models.NotificationField{plantIDField},
+	},
+	models.NotificationTypeOffer: {
+		Category: models.NotificationCategoryOffer,
+		Icon:     "local_offer",
+		Action:   "planter://shops/{shopId}",
+		Fields: []models.NotificationField{
+			{Name: "shopId", Type: models.NotificationFieldTypeUUID, Required: true},
+			{Name: "discount", Type: models.NotificationFieldTypeNumber, Required: true},
+			{Name: "plantId", Type: models.NotificationFieldTypeUUID},
+			{Name: "validUntil", Type: models.NotificationFieldTypeDate},
+		},
+	},
+	models.NotificationTypeSupportTicket: {
+		Category: models.NotificationCategorySupport,
+		Icon:     "support_agent",
+		Action:   "planter://admin/support/tickets/{ticketId}",
+		Fields: []models.NotificationField{
+			{Name: "ticketId", Type: models.NotificationFieldTypeUUID, Required: true},
+		},
+	},
+	models.NotificationTypeChatExpertReply: {
+		Category: models.NotificationCategorySupport,
+		Icon:     "psychology",
+		Action:   "planter://chat/sessions/{sessionId}",
+		Fields: []models.NotificationField{
+			{Name: "sessionId", Type: models.NotificationFieldTypeUUID, Required: true},
+		},
+	},
+	models.NotificationTypePlantAvailable: {
+		Category: models.NotificationCategoryOffer,
+		Icon:     "storefront",
+		Action:   "planter://shops/{shopId}",
+		Fields: []models.NotificationField{
+			plantIDField,
+			{Name: "shopId", Type: models.NotificationFieldTypeUUID, Required: true},
+			{Name: "city", Type: models.NotificationFieldTypeString, Required: true},
+		},
+	},
+	models.NotificationTypeWelcome: {
+		Category: models.NotificationCategoryOnboarding,
+		Icon:     "waving_hand",
+		Action:   "planter://plants/{plantId}",
+		Fields: []models.NotificationField{
+			{Name: "step", Type: models.NotificationFieldTypeString, Required: true},
+			{Name: "plantId", Type: models.NotificationFieldTypeUUID},
+		},
+	},
+	models.NotificationTypeAnnouncement: {
+		Category: models.NotificationCategoryNews,
+		Icon:     "campaign",
+		Fields: []models.NotificationField{
+			{Name: "announcementId", Type: models.NotificationFieldTypeUUID, Required: true},
+			{Name: "message", Type: models.NotificationFieldTypeString, Required: true},
+		},
+	},
+	models.NotificationTypeBadgeEarned: {
+		Category: models.NotificationCategoryAchievement,
+		Icon:     "military_tech",
+		Fields: []models.NotificationField{
+			{Name: "badge", Type: models.NotificationFieldTypeString, Required: true},
+		},
+	},
+}
+
+func init() {
+	for notificationType, definition := range notificationTypes {
+		definition.Type = notificationType
+	}
+}
+
+// NotificationTypes lists the registered notification types ordered by type
+func NotificationTypes() []*models.NotificationTypeDefinition {
+	definitions := make([]*models.NotificationTypeDefinition, 0, len(notificationTypes))
+	for _, definition := range notificationTypes {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Type < definitions[j].Type
+	})
+	return definitions
+}
+
+// validateNotificationPayload checks a payload against the fields of its notification type.
+// Fields the type does not describe are rejected so clients can rely on the registry.
+func validateNotificationPayload(notificationType models.NotificationType, payload models.NotificationPayload) error {
+	definition, ok := notificationTypes[notificationType]
+	if !ok {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidNotification, notificationType)
+	}
+
+	known := make(map[string]bool, len(definition.Fields))
+	for _, field := range definition.Fields {
+		known[field.Name] = true
+
+		value, ok := payload[field.Name]
+		if !ok || value == nil {
+			if field.Required {
+				return fmt.Errorf("%w: %s is required for %s", ErrInvalidNotification, field.Name, notificationType)
+			}
+			continue
+		}
+		if !validNotificationField(field.Type, value) {
+			return fmt.Errorf("%w: %s must be a %s", ErrInvalidNotification, field.Name, field.Type)
+		}
+	}
+
+	for name := range payload {
+		if !known[name] {
+			return fmt.Errorf("%w: unknown field %s for %s", ErrInvalidNotification, name, notificationType)
+		}
+	}
+	return nil
+}
+
+// validNotificationField reports whether a decoded JSON value has the type of a payload field
+func validNotificationField(fieldType models.NotificationFieldType, value interface{}) bool {
+	switch fieldType {
+	case models.NotificationFieldTypeNumber:
+		switch v := value.(type) {
+		case float64:
+			return !math.IsNaN(v) && !math.IsInf(v, 0)
+		case int:
+			return true
+		}
+		return false
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	switch fieldType {
+	case models.NotificationFieldTypeUUID:
+		_, err := uuid.Parse(s)
+		return err == nil
+	case models.NotificationFieldTypeDate:
+		_, err := time.Parse(notificationDateLayout, s)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+// notificationDisplay returns the rendering data of a notification with the action link filled from
+// its payload. The action is left out when a field it needs is missing.
+func notificationDisplay(notification *models.Notification) *models.NotificationDisplay {
+	definition, ok := notificationTypes[notification.Type]
+	if !ok {
+		return nil
+	}
+
+	display := &models.NotificationDisplay{
+		Category: definition.Category,
+		Icon:     definition.Icon,
+	}
+
+	action := definition.Action
+	for _, field := range definition.Fields {
+		placeholder := "{" + field.Name + "}"
+		if !strings.Contains(action, placeholder) {
+			continue
+		}
+		value, ok := notification.Payload[field.Name].(string)
+		if !ok || value == "" {
+			return display
+		}
+		action = strings.ReplaceAll(action, placeholder, value)
+	}
+	display.Action = action
+	return display
+}
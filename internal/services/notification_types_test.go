@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestValidateNotificationPayload tests payloads against the fields of their registered type
+func TestValidateNotificationPayload(t *testing.T) {
+	shopID := uuid.New().String()
+
+	assert.NoError(t, validateNotificationPayload(models.NotificationTypeWatering, models.NotificationPayload{
+		"plantId": uuid.New().String(), "dueDate": "2024-05-10",
+	}))
+	assert.NoError(t, validateNotificationPayload(models.NotificationTypeOffer, models.NotificationPayload{
+		"shopId": shopID, "discount": 15.0,
+	}))
+
+	invalid := []struct {
+		notificationType models.NotificationType
+		payload          models.NotificationPayload
+	}{
+		{"BIRTHDAY", models.NotificationPayload{}},
+		{models.NotificationTypeWatering, models.NotificationPayload{"dueDate": "2024-05-10"}},
+		{models.NotificationTypeWatering, models.NotificationPayload{"plantId": "monstera"}},
+		{models.NotificationTypeWatering, models.NotificationPayload{"plantId": uuid.New().String(), "dueDate": "10.05.2024"}},
+		{models.NotificationTypeOffer, models.NotificationPayload{"shopId": shopID, "discount": "15%"}},
+		{models.NotificationTypeOffer, models.NotificationPayload{"shopId": shopID, "discount": 15.0, "coupon": "SPRING"}},
+	}
+	for _, tc := range invalid {
+		assert.ErrorIs(t, validateNotificationPayload(tc.notificationType, tc.payload), ErrInvalidNotification, tc.payload)
+	}
+}
+
+// TestNotificationDisplay tests that action links are filled from the payload
+func TestNotificationDisplay(t *testing.T) {
+	plantID := uuid.New().String()
+
+	display := notificationDisplay(&models.Notification{
+		Type:    models.NotificationTypeDormancy,
+		Payload: models.NotificationPayload{"plantId": plantID},
+	})
+	assert.Equal(t, models.NotificationCategorySeason, display.Category)
+	assert.Equal(t, "planter://plants/"+plantID+"/care-plan", display.Action)
+
+	// Notifications created before payloads existed keep their icon but get no link
+	display = notificationDisplay(&models.Notification{Type: models.NotificationTypeWatering})
+	assert.Equal(t, "water_drop", display.Icon)
+	assert.Empty(t, display.Action)
+}
+
+// TestNotificationService_SendNotification tests sending a notification that is not about a plant
+func TestNotificationService_SendNotification(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	service := NewNotificationService(mockNotificationRepo, new(MockPlantRepository), nil, NewNotificationTemplateService(mockTemplateRepo))
+
+	userID, shopID := uuid.New(), uuid.New()
+	payload := models.NotificationPayload{"shopId": shopID.String(), "discount": 20.0}
+	mockTemplateRepo.On("Get", mock.Anything, models.NotificationTypeOffer, models.LanguageEnglish).Return(nil, nil)
+	mockNotificationRepo.On("Create", mock.Anything, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.UserID == userID && n.PlantID == nil && n.Type == models.NotificationTypeOffer
+	})).Return(nil)
+
+	notification, err := service.SendNotification(context.Background(), userID, models.LanguageEnglish, models.NotificationTypeOffer, payload)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "20% off at a partner shop! Take a look while the offer lasts.", notification.Message)
+	assert.Equal(t, "planter://shops/"+shopID.String(), notification.Display.Action)
+	mockNotificationRepo.AssertExpectations(t)
+
+	// Payloads that do not match the type are rejected before anything is stored
+	_, err = service.SendNotification(context.Background(), userID, models.LanguageEnglish, models.NotificationTypeOffer, models.NotificationPayload{})
+	assert.ErrorIs(t, err, ErrInvalidNotification)
+}
+
+// recordingStream records the notifications pushed to it
+type recordingStream struct {
+	published []*models.Notification
+}
+
+func (s *recordingStream) Publish(notification *models.Notification) {
+	s.published = append(s.published, notification)
+}
+
+// TestNotificationService_SendNotification_Stream tests that stored notifications are pushed to the
+// stream with their display data and rejected ones are not
+func TestNotificationService_SendNotification_Stream(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	service := NewNotificationService(mockNotificationRepo, new(MockPlantRepository), nil, NewNotificationTemplateService(mockTemplateRepo))
+	stream := &recordingStream{}
+	service.SetNotificationStream(stream)
+
+	userID, shopID := uuid.New(), uuid.New()
+	payload := models.NotificationPayload{"shopId": shopID.String(), "discount": 20.0}
+	mockTemplateRepo.On("Get", mock.Anything, models.NotificationTypeOffer, models.LanguageEnglish).Return(nil, nil)
+	mockNotificationRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := service.SendNotification(context.Background(), userID, models.LanguageEnglish, models.NotificationTypeOffer, payload)
+	assert.NoError(t, err)
+	_, err = service.SendNotification(context.Background(), userID, models.LanguageEnglish, models.NotificationTypeOffer, models.NotificationPayload{})
+	assert.ErrorIs(t, err, ErrInvalidNotification)
+
+	if assert.Len(t, stream.published, 1) {
+		assert.Equal(t, userID, stream.published[0].UserID)
+		assert.Equal(t, "planter://shops/"+shopID.String(), stream.published[0].Display.Action)
+	}
+}
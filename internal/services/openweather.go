@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// openWeatherDaySummaryURL is the OpenWeather One Call API endpoint of daily aggregations
+const openWeatherDaySummaryURL = "https://api.openweathermap.org/data/3.0/onecall/day_summary"
+
+// OpenWeatherProvider looks up recent weather with the OpenWeather One Call API
+type OpenWeatherProvider struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewOpenWeatherProvider creates a new OpenWeather provider
+func NewOpenWeatherProvider(apiKey string) *OpenWeatherProvider {
+	return &OpenWeatherProvider{
+		apiKey:   apiKey,
+		endpoint: openWeatherDaySummaryURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		now: time.Now,
+	}
+}
+
+// openWeatherDaySummary represents a daily aggregation from the OpenWeather One Call API
+type openWeatherDaySummary struct {
+	Precipitation struct {
+		Total float64 `json:"total"`
+	} `json:"precipitation"`
+	Temperature struct {
+		Max float64 `json:"max"`
+	} `json:"temperature"`
+}
+
+// GetRecentWeather sums the rainfall and finds the highest temperature at a place over the last days,
+// today included
+func (p *OpenWeatherProvider) GetRecentWeather(ctx context.Context, latitude, longitude float64, days int) (*models.WeatherReport, error) {
+	report := &models.WeatherReport{MaxTemperature: math.Inf(-1)}
+	today := p.now().UTC()
+	for i := 0; i < days; i++ {
+		summary, err := p.getDaySummary(ctx, latitude, longitude, today.AddDate(0, 0, -i))
+		if err != nil {
+			return nil, err
+		}
+		report.RainfallMM += summary.Precipitation.Total
+		report.MaxTemperature = math.Max(report.MaxTemperature, summary.Temperature.Max)
+	}
+	return report, nil
+}
+
+// getDaySummary gets the weather aggregated over a day
+func (p *OpenWeatherProvider) getDaySummary(ctx context.Context, latitude, longitude float64, day time.Time) (*openWeatherDaySummary, error) {
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(latitude, 'f', -1, 64))
+	query.Set("lon", strconv.FormatFloat(longitude, 'f', -1, 64))
+	query.Set("date", day.Format("2006-01-02"))
+	query.Set("units", "metric")
+	query.Set("appid", p.apiKey)
+
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Send the request
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check the response status
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("API returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// Parse the response
+	var summary openWeatherDaySummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &summary, nil
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOpenWeatherProvider_GetRecentWeather tests summing the rainfall and finding the highest
+// temperature over the days looked at
+func TestOpenWeatherProvider_GetRecentWeather(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.URL.Query().Get("appid"))
+		assert.Equal(t, "metric", r.URL.Query().Get("units"))
+		assert.Equal(t, "59.9357", r.URL.Query().Get("lat"))
+		assert.Equal(t, "30.326", r.URL.Query().Get("lon"))
+		switch r.URL.Query().Get("date") {
+		case "2024-06-10":
+			w.Write([]byte(`{"precipitation":{"total":1.5},"temperature":{"min":14.2,"max":24.1}}`))
+		case "2024-06-09":
+			w.Write([]byte(`{"precipitation":{"total":4.25},"temperature":{"min":15.8,"max":27.3}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOpenWeatherProvider("test-key")
+	provider.endpoint = server.URL
+	provider.now = func() time.Time { return time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC) }
+
+	report, err := provider.GetRecentWeather(context.Background(), 59.9357, 30.326, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 5.75, report.RainfallMM)
+	assert.Equal(t, 27.3, report.MaxTemperature)
+
+	_, err = provider.GetRecentWeather(context.Background(), 59.9357, 30.326, 3)
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PersonalTokenPrefix is prepended to every generated personal access token so it can be
+// told apart from a JWT in the Authorization header
+const PersonalTokenPrefix = "pat_"
+
+// PersonalTokenService handles personal access token operations
+type PersonalTokenService struct {
+	tokenRepo repository.PersonalTokenRepository
+}
+
+// NewPersonalTokenService creates a new personal access token service
+func NewPersonalTokenService(tokenRepo repository.PersonalTokenRepository) *PersonalTokenService {
+	return &PersonalTokenService{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// CreateToken creates a new personal access token for a user and returns the raw token once
+func (s *PersonalTokenService) CreateToken(ctx context.Context, userID uuid.UUID, req *models.CreatePersonalAccessTokenRequest) (*models.CreatePersonalAccessTokenResponse, error) {
+	// Generate the raw token
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate personal access token: %w", err)
+	}
+	rawToken := PersonalTokenPrefix + hex.EncodeToString(buf)
+
+	// Deduplicate the scopes, keeping the requested order
+	scopes := pq.StringArray{}
+	seen := make(map[models.TokenScope]bool)
+	for _, scope := range req.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, string(scope))
+		}
+	}
+
+	// Only the hash of the token is stored
+	token := &models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        req.Name,
+		TokenHash:   hashAPIKey(rawToken),
+		TokenPrefix: rawToken[:len(PersonalTokenPrefix)+8],
+		Scopes:      scopes,
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	err := s.tokenRepo.Create(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create personal access token: %w", err)
+	}
+
+	return &models.CreatePersonalAccessTokenResponse{
+		Token: *token,
+		Value: rawToken,
+	}, nil
+}
+
+// GetTokens gets all personal access tokens of a user
+func (s *PersonalTokenService) GetTokens(ctx context.Context, userID uuid.UUID) ([]*models.PersonalAccessToken, error) {
+	tokens, err := s.tokenRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get personal access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken revokes a personal access token owned by a user
+func (s *PersonalTokenService) RevokeToken(ctx context.Context, userID uuid.UUID, tokenID uuid.UUID) error {
+	err := s.tokenRepo.Revoke(ctx, tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke personal access token: %w", err)
+	}
+	return nil
+}
+
+// ValidatePersonalToken checks a raw personal access token and returns its owner and scopes
+func (s *PersonalTokenService) ValidatePersonalToken(ctx context.Context, rawToken string) (uuid.UUID, []string, error) {
+	if !strings.HasPrefix(rawToken, PersonalTokenPrefix) {
+		return uuid.Nil, nil, fmt.Errorf("invalid personal access token format")
+	}
+
+	token, err := s.tokenRepo.GetByHash(ctx, hashAPIKey(rawToken))
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("invalid personal access token: %w", err)
+	}
+
+	// Recording the usage must not block the request
+	if err := s.tokenRepo.UpdateLastUsed(ctx, token.ID); err != nil {
+		log.Printf("Failed to record usage for personal access token %s: %v", token.ID, err)
+	}
+
+	return token.UserID, token.Scopes, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPersonalTokenRepository is a mock implementation of the PersonalTokenRepository interface
+type MockPersonalTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockPersonalTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockPersonalTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PersonalAccessToken), args.Error(1)
+}
+
+func (m *MockPersonalTokenRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.PersonalAccessToken, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.PersonalAccessToken), args.Error(1)
+}
+
+func (m *MockPersonalTokenRepository) Revoke(ctx context.Context, tokenID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, tokenID, userID)
+	return args.Error(0)
+}
+
+func (m *MockPersonalTokenRepository) UpdateLastUsed(ctx context.Context, tokenID uuid.UUID) error {
+	args := m.Called(ctx, tokenID)
+	return args.Error(0)
+}
+
+// TestPersonalTokenService_CreateAndValidate tests that a created token validates with its scopes
+func TestPersonalTokenService_CreateAndValidate(t *testing.T) {
+	mockRepo := new(MockPersonalTokenRepository)
+	service := NewPersonalTokenService(mockRepo)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	tokenID := uuid.New()
+	days := 30
+
+	var stored *models.PersonalAccessToken
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*models.PersonalAccessToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.PersonalAccessToken)
+		stored.ID = tokenID
+	}).Return(nil)
+
+	// Create the token with a duplicated scope
+	resp, err := service.CreateToken(ctx, userID, &models.CreatePersonalAccessTokenRequest{
+		Name:          "Home Assistant",
+		Scopes:        []models.TokenScope{models.TokenScopePlantsWater, models.TokenScopePlantsRead, models.TokenScopePlantsWater},
+		ExpiresInDays: &days,
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resp.Value, PersonalTokenPrefix))
+	assert.True(t, strings.HasPrefix(resp.Value, resp.Token.TokenPrefix))
+	assert.Equal(t, hashAPIKey(resp.Value), stored.TokenHash)
+	assert.Equal(t, []string{"plants:water", "plants:read"}, []string(stored.Scopes))
+	assert.NotNil(t, stored.ExpiresAt)
+
+	// Validate the raw token
+	mockRepo.On("GetByHash", ctx, stored.TokenHash).Return(stored, nil)
+	mockRepo.On("UpdateLastUsed", ctx, tokenID).Return(nil)
+	validatedUserID, scopes, err := service.ValidatePersonalToken(ctx, resp.Value)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, validatedUserID)
+	assert.Equal(t, []string{"plants:water", "plants:read"}, scopes)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestPersonalTokenService_ValidatePersonalToken_Invalid tests that unknown and malformed tokens are rejected
+func TestPersonalTokenService_ValidatePersonalToken_Invalid(t *testing.T) {
+	mockRepo := new(MockPersonalTokenRepository)
+	service := NewPersonalTokenService(mockRepo)
+
+	ctx := context.Background()
+
+	// A JWT or API key is not a personal access token
+	_, _, err := service.ValidatePersonalToken(ctx, "plk_0123456789")
+	assert.Error(t, err)
+
+	// Revoked or expired tokens are not found by hash
+	mockRepo.On("GetByHash", ctx, hashAPIKey("pat_revoked")).Return(nil, fmt.Errorf("not found"))
+	_, _, err = service.ValidatePersonalToken(ctx, "pat_revoked")
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "UpdateLastUsed", mock.Anything, mock.Anything)
+}
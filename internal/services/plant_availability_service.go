@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/events"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// PlantAvailabilityService lets users wait for a plant to be sold in their city and notifies them
+// when the inventory change pipeline reports it in stock at a shop there
+type PlantAvailabilityService struct {
+	subscriptionRepo    repository.PlantAvailabilityRepository
+	plantRepo           repository.PlantRepository
+	shopRepo            repository.ShopRepository
+	notificationService *NotificationService
+}
+
+// NewPlantAvailabilityService creates a new plant availability service
+func NewPlantAvailabilityService(
+	subscriptionRepo repository.PlantAvailabilityRepository,
+	plantRepo repository.PlantRepository,
+	shopRepo repository.ShopRepository,
+	notificationService *NotificationService,
+) *PlantAvailabilityService {
+	return &PlantAvailabilityService{
+		subscriptionRepo:    subscriptionRepo,
+		plantRepo:           plantRepo,
+		shopRepo:            shopRepo,
+		notificationService: notificationService,
+	}
+}
+
+// Subscribe asks to notify a user once a plant is in stock at a shop in a city. Subscribing again
+// changes the city and waits for the next time the plant is stocked.
+func (s *PlantAvailabilityService) Subscribe(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, city string) (*models.PlantAvailabilitySubscription, error) {
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("plant not found: %w", err)
+	}
+	if !plant.Published() {
+		return nil, errPlantNotPublished
+	}
+	if plant.DeletedAt != nil {
+		return nil, ErrPlantDeleted
+	}
+
+	subscription := &models.PlantAvailabilitySubscription{
+		UserID:    userID,
+		PlantID:   plantID,
+		PlantName: plant.Name,
+		City:      strings.TrimSpace(city),
+	}
+	if err := s.subscriptionRepo.Upsert(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to plant availability: %w", err)
+	}
+	return subscription, nil
+}
+
+// GetSubscriptions gets the plant availability subscriptions of a user, newest first
+func (s *PlantAvailabilityService) GetSubscriptions(ctx context.Context, userID uuid.UUID) ([]*models.PlantAvailabilitySubscription, error) {
+	subscriptions, err := s.subscriptionRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant availability subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// Unsubscribe removes a user's subscription to a plant
+func (s *PlantAvailabilityService) Unsubscribe(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error {
+	if err := s.subscriptionRepo.Delete(ctx, userID, plantID); err != nil {
+		return fmt.Errorf("failed to unsubscribe from plant availability: %w", err)
+	}
+	return nil
+}
+
+// HandleInventoryChanged notifies the users waiting for a plant in the city of the shop that stocked it.
+// It consumes InventoryChanged events; shops without a known city match no subscription.
+func (s *PlantAvailabilityService) HandleInventoryChanged(ctx context.Context, event events.Event) error {
+	change, ok := event.(events.InventoryChanged)
+	if !ok || !change.InStock {
+		return nil
+	}
+
+	shop, err := s.shopRepo.GetByID(ctx, change.ShopID)
+	if err != nil {
+		return fmt.Errorf("failed to get shop: %w", err)
+	}
+	if shop.City == nil || *shop.City == "" {
+		return nil
+	}
+
+	recipients, err := s.subscriptionRepo.ClaimWaiting(ctx, change.PlantID, *shop.City)
+	if err != nil {
+		return fmt.Errorf("failed to claim plant availability subscriptions: %w", err)
+	}
+
+	// Subscriptions are claimed before sending, so a failed notification is only logged
+	payload := models.NotificationPayload{
+		"plantId": change.PlantID.String(),
+		"shopId":  change.ShopID.String(),
+		"city":    *shop.City,
+	}
+	for _, recipient := range recipients {
+		if _, err := s.notificationService.SendNotification(ctx, recipient.UserID, recipient.Language, models.NotificationTypePlantAvailable, payload); err != nil {
+			log.Printf("Failed to notify user %s that plant %s is available in %s: %v", recipient.UserID, change.PlantID, *shop.City, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/events"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPlantAvailabilityRepository is a mock implementation of the PlantAvailabilityRepository interface
+type MockPlantAvailabilityRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlantAvailabilityRepository) Upsert(ctx context.Context, subscription *models.PlantAvailabilitySubscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockPlantAvailabilityRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.PlantAvailabilitySubscription, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.PlantAvailabilitySubscription), args.Error(1)
+}
+
+func (m *MockPlantAvailabilityRepository) Delete(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error {
+	args := m.Called(ctx, userID, plantID)
+	return args.Error(0)
+}
+
+func (m *MockPlantAvailabilityRepository) ClaimWaiting(ctx context.Context, plantID uuid.UUID, city string) ([]*models.PlantAvailabilityRecipient, error) {
+	args := m.Called(ctx, plantID, city)
+	return args.Get(0).([]*models.PlantAvailabilityRecipient), args.Error(1)
+}
+
+// TestPlantAvailabilityService_Subscribe tests that the city is trimmed and removed plants cannot be subscribed to
+func TestPlantAvailabilityService_Subscribe(t *testing.T) {
+	mockSubscriptionRepo := new(MockPlantAvailabilityRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewPlantAvailabilityService(mockSubscriptionRepo, mockPlantRepo, new(MockShopRepository), nil)
+	ctx := context.Background()
+
+	userID, plantID, deletedID := uuid.New(), uuid.New(), uuid.New()
+	deletedAt := time.Now()
+	mockPlantRepo.On("GetByID", ctx, plantID).Return(&models.Plant{ID: plantID, Name: "Monstera"}, nil)
+	mockPlantRepo.On("GetByID", ctx, deletedID).Return(&models.Plant{ID: deletedID, DeletedAt: &deletedAt}, nil)
+	mockSubscriptionRepo.On("Upsert", ctx, mock.MatchedBy(func(s *models.PlantAvailabilitySubscription) bool {
+		return s.UserID == userID && s.PlantID == plantID && s.City == "Казань"
+	})).Return(nil).Once()
+
+	subscription, err := service.Subscribe(ctx, userID, plantID, "  Казань ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Monstera", subscription.PlantName)
+
+	_, err = service.Subscribe(ctx, userID, deletedID, "Казань")
+	assert.ErrorIs(t, err, ErrPlantDeleted)
+	mockSubscriptionRepo.AssertExpectations(t)
+}
+
+// TestPlantAvailabilityService_HandleInventoryChanged tests that the subscribers in the shop's city are notified
+func TestPlantAvailabilityService_HandleInventoryChanged(t *testing.T) {
+	mockSubscriptionRepo := new(MockPlantAvailabilityRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockShopRepo := new(MockShopRepository)
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	notificationService := NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(mockTemplateRepo))
+	service := NewPlantAvailabilityService(mockSubscriptionRepo, mockPlantRepo, mockShopRepo, notificationService)
+	ctx := context.Background()
+
+	shopID, plantID := uuid.New(), uuid.New()
+	city := "Санкт-Петербург"
+	recipients := []*models.PlantAvailabilityRecipient{
+		{UserID: uuid.New(), Language: models.LanguageEnglish},
+		{UserID: uuid.New(), Language: models.LanguageRussian},
+	}
+	mockShopRepo.On("GetByID", ctx, shopID).Return(&models.Shop{ID: shopID, City: &city}, nil)
+	mockSubscriptionRepo.On("ClaimWaiting", ctx, plantID, city).Return(recipients, nil).Once()
+	mockPlantRepo.On("GetByID", ctx, plantID).Return(&models.Plant{ID: plantID, Name: "Monstera"}, nil)
+	mockTemplateRepo.On("Get", ctx, models.NotificationTypePlantAvailable, mock.Anything).Return(nil, nil)
+	for _, recipient := range recipients {
+		userID := recipient.UserID
+		mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+			return n.UserID == userID && n.Type == models.NotificationTypePlantAvailable &&
+				n.Payload["shopId"] == shopID.String() && n.Payload["city"] == city
+		})).Return(nil).Once()
+	}
+
+	err := service.HandleInventoryChanged(ctx, events.InventoryChanged{ShopID: shopID, PlantID: plantID, InStock: true})
+	assert.NoError(t, err)
+	mockSubscriptionRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertExpectations(t)
+
+	// Plants going out of stock notify no one
+	err = service.HandleInventoryChanged(ctx, events.InventoryChanged{ShopID: shopID, PlantID: plantID, InStock: false})
+	assert.NoError(t, err)
+	mockSubscriptionRepo.AssertNumberOfCalls(t, "ClaimWaiting", 1)
+}
+
+// TestPlantAvailabilityService_HandleInventoryChanged_NoCity tests that shops without a known city match no subscription
+func TestPlantAvailabilityService_HandleInventoryChanged_NoCity(t *testing.T) {
+	mockSubscriptionRepo := new(MockPlantAvailabilityRepository)
+	mockShopRepo := new(MockShopRepository)
+	service := NewPlantAvailabilityService(mockSubscriptionRepo, new(MockPlantRepository), mockShopRepo, nil)
+	shopID := uuid.New()
+
+	mockShopRepo.On("GetByID", mock.Anything, shopID).Return(&models.Shop{ID: shopID}, nil)
+
+	err := service.HandleInventoryChanged(context.Background(), events.InventoryChanged{ShopID: shopID, PlantID: uuid.New(), InStock: true})
+	assert.NoError(t, err)
+	mockSubscriptionRepo.AssertNotCalled(t, "ClaimWaiting", mock.Anything, mock.Anything, mock.Anything)
+
+	mockShopRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+	err = service.HandleInventoryChanged(context.Background(), events.InventoryChanged{ShopID: uuid.New(), InStock: true})
+	assert.Error(t, err)
+}
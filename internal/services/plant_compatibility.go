@@ -0,0 +1,250 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"text/template"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+//go:embed templates/compatibility.json
+var compatibilityTextsJSON []byte
+
+// compatibilityTexts holds the explanations of compatibility checks by language, criterion and status
+var compatibilityTexts = mustLoadCompatibilityTexts(compatibilityTextsJSON)
+
+// compatibilityStatuses lists the statuses each criterion can have. Temperatures are documented as
+// ranges, so there is no compromise between ranges that do not overlap.
+var compatibilityStatuses = map[models.CompatibilityCriterion][]models.CompatibilityStatus{
+	models.CompatibilityCriterionLight:       {models.CompatibilityStatusCompatible, models.CompatibilityStatusCaution, models.CompatibilityStatusIncompatible},
+	models.CompatibilityCriterionWater:       {models.CompatibilityStatusCompatible, models.CompatibilityStatusCaution, models.CompatibilityStatusIncompatible},
+	models.CompatibilityCriterionHumidity:    {models.CompatibilityStatusCompatible, models.CompatibilityStatusCaution, models.CompatibilityStatusIncompatible},
+	models.CompatibilityCriterionTemperature: {models.CompatibilityStatusCompatible, models.CompatibilityStatusIncompatible},
+}
+
+// compatibilityStatusRank orders the statuses from best to worst
+var compatibilityStatusRank = map[models.CompatibilityStatus]int{
+	models.CompatibilityStatusCompatible:   0,
+	models.CompatibilityStatusCaution:      1,
+	models.CompatibilityStatusIncompatible: 2,
+}
+
+// levelRank orders light and humidity levels
+var levelRank = map[string]int{"LOW": 0, "MEDIUM": 1, "HIGH": 2}
+
+// wateringCompromiseRatio is how far apart the watering ranges of plants may be for a shared
+// watering to be a compromise rather than a conflict
+const wateringCompromiseRatio = 1.5
+
+// compatibilityData is the data of an explanation template
+type compatibilityData struct {
+	Low       string // plant at the low end of a conflict: less light, more frequent watering, drier air, cooler
+	High      string // plant at the high end of a conflict
+	LowValue  int    // what the low end plant needs at most, e.g. days between waterings
+	HighValue int    // what the high end plant needs at least
+	Min       int    // shared range, e.g. of days between waterings
+	Max       int
+}
+
+// compatibilityResult is the outcome of one criterion before it is put into words
+type compatibilityResult struct {
+	criterion models.CompatibilityCriterion
+	status    models.CompatibilityStatus
+	data      compatibilityData
+}
+
+// CheckPlantCompatibility gets the plants and tells whether they can share a pot or a terrarium
+func (s *PlantService) CheckPlantCompatibility(ctx context.Context, plantIDs []uuid.UUID, language models.Language) (*models.PlantCompatibility, error) {
+	plants := make([]*models.Plant, 0, len(plantIDs))
+	for _, plantID := range plantIDs {
+		plant, err := s.plantRepo.GetByID(ctx, plantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get plant %s: %w", plantID, err)
+		}
+		plants = append(plants, plant)
+	}
+	return PlantCompatibilityOf(plants, language), nil
+}
+
+// PlantCompatibilityOf compares the light, water, humidity and temperature needs of plants planted
+// together and explains the outcome in the given language. Plants are compatible when no need
+// conflicts; unsupported languages fall back to Russian.
+func PlantCompatibilityOf(plants []*models.Plant, language models.Language) *models.PlantCompatibility {
+	texts, ok := compatibilityTexts[language]
+	if !ok {
+		texts = compatibilityTexts[models.LanguageRussian]
+	}
+
+	shared := &models.SharedCare{}
+	results := []compatibilityResult{
+		compareLevels(models.CompatibilityCriterionLight, plants, func(c models.CareInstructions) string { return string(c.Sunlight) },
+			func(level string) { shared.Sunlight = models.SunlightLevel(level) }),
+		compareWatering(plants, shared),
+		compareLevels(models.CompatibilityCriterionHumidity, plants, func(c models.CareInstructions) string { return string(c.Humidity) },
+			func(level string) { shared.Humidity = models.HumidityLevel(level) }),
+		compareTemperatures(plants, shared),
+	}
+
+	compatibility := &models.PlantCompatibility{
+		Status: models.CompatibilityStatusCompatible,
+		Checks: make([]models.CompatibilityCheck, 0, len(results)),
+	}
+	for _, result := range results {
+		var explanation bytes.Buffer
+		if err := texts[result.criterion][result.status].Execute(&explanation, result.data); err != nil {
+			explanation.Reset()
+		}
+		compatibility.Checks = append(compatibility.Checks, models.CompatibilityCheck{
+			Criterion:   result.criterion,
+			Status:      result.status,
+			Explanation: explanation.String(),
+		})
+		if compatibilityStatusRank[result.status] > compatibilityStatusRank[compatibility.Status] {
+			compatibility.Status = result.status
+		}
+	}
+
+	compatibility.Compatible = compatibility.Status != models.CompatibilityStatusIncompatible
+	if compatibility.Compatible {
+		compatibility.SharedCare = shared
+	}
+	return compatibility
+}
+
+// compareLevels compares light or humidity levels: the same level is compatible, adjacent levels meet
+// at MEDIUM and LOW next to HIGH conflicts
+func compareLevels(criterion models.CompatibilityCriterion, plants []*models.Plant, level func(models.CareInstructions) string, setShared func(string)) compatibilityResult {
+	low, high := plants[0], plants[0]
+	for _, plant := range plants[1:] {
+		if levelRank[level(plant.CareInstructions)] < levelRank[level(low.CareInstructions)] {
+			low = plant
+		}
+		if levelRank[level(plant.CareInstructions)] > levelRank[level(high.CareInstructions)] {
+			high = plant
+		}
+	}
+
+	result := compatibilityResult{criterion: criterion, data: compatibilityData{Low: low.Name, High: high.Name}}
+	switch levelRank[level(high.CareInstructions)] - levelRank[level(low.CareInstructions)] {
+	case 0:
+		result.status = models.CompatibilityStatusCompatible
+		setShared(level(low.CareInstructions))
+	case 1:
+		result.status = models.CompatibilityStatusCaution
+		setShared("MEDIUM")
+	default:
+		result.status = models.CompatibilityStatusIncompatible
+	}
+	return result
+}
+
+// compareWatering compares the ranges of days between waterings the plants tolerate; a plant without a
+// documented range tolerates its usual frequency only
+func compareWatering(plants []*models.Plant, shared *models.SharedCare) compatibilityResult {
+	// Low is the plant tolerating the fewest days at most, High the one needing the most days at least
+	var low, high *models.Plant
+	sharedMin, sharedMax := 0, math.MaxInt
+	total := 0
+	for _, plant := range plants {
+		care := plant.CareInstructions
+		minDays, maxDays := care.WateringFrequency, care.WateringFrequency
+		if care.WateringFrequencyMin != nil {
+			minDays = *care.WateringFrequencyMin
+		}
+		if care.WateringFrequencyMax != nil {
+			maxDays = *care.WateringFrequencyMax
+		}
+		if minDays > sharedMin || high == nil {
+			sharedMin, high = minDays, plant
+		}
+		if maxDays < sharedMax || low == nil {
+			sharedMax, low = maxDays, plant
+		}
+		total += care.WateringFrequency
+	}
+
+	result := compatibilityResult{
+		criterion: models.CompatibilityCriterionWater,
+		data:      compatibilityData{Low: low.Name, High: high.Name, LowValue: sharedMax, HighValue: sharedMin},
+	}
+	switch {
+	case sharedMin <= sharedMax:
+		// Water as close to the usual frequencies as the shared range allows
+		average := int(math.Round(float64(total) / float64(len(plants))))
+		shared.WateringFrequency = min(max(average, sharedMin), sharedMax)
+		result.status = models.CompatibilityStatusCompatible
+		result.data.Min, result.data.Max = sharedMin, sharedMax
+	case float64(sharedMin) <= float64(sharedMax)*wateringCompromiseRatio:
+		shared.WateringFrequency = int(math.Round(float64(sharedMin+sharedMax) / 2))
+		result.status = models.CompatibilityStatusCaution
+		result.data.Min, result.data.Max = shared.WateringFrequency, shared.WateringFrequency
+	default:
+		result.status = models.CompatibilityStatusIncompatible
+	}
+	return result
+}
+
+// compareTemperatures compares the temperature ranges the plants do well in
+func compareTemperatures(plants []*models.Plant, shared *models.SharedCare) compatibilityResult {
+	// Low is the plant with the coolest maximum, High the one with the warmest minimum
+	low, high := plants[0], plants[0]
+	for _, plant := range plants[1:] {
+		if plant.CareInstructions.Temperature.Max < low.CareInstructions.Temperature.Max {
+			low = plant
+		}
+		if plant.CareInstructions.Temperature.Min > high.CareInstructions.Temperature.Min {
+			high = plant
+		}
+	}
+
+	sharedMin, sharedMax := high.CareInstructions.Temperature.Min, low.CareInstructions.Temperature.Max
+	result := compatibilityResult{
+		criterion: models.CompatibilityCriterionTemperature,
+		data:      compatibilityData{Low: low.Name, High: high.Name, LowValue: sharedMax, HighValue: sharedMin, Min: sharedMin, Max: sharedMax},
+	}
+	if sharedMin <= sharedMax {
+		result.status = models.CompatibilityStatusCompatible
+		shared.Temperature = models.TemperatureRange{Min: sharedMin, Max: sharedMax}
+	} else {
+		result.status = models.CompatibilityStatusIncompatible
+	}
+	return result
+}
+
+// mustLoadCompatibilityTexts parses the explanations of compatibility checks and panics if they are
+// invalid, miss a status of a criterion or miss Russian, the language others fall back to
+func mustLoadCompatibilityTexts(data []byte) map[models.Language]map[models.CompatibilityCriterion]map[models.CompatibilityStatus]*template.Template {
+	var sources map[models.Language]map[models.CompatibilityCriterion]map[models.CompatibilityStatus]string
+	if err := json.Unmarshal(data, &sources); err != nil {
+		panic(fmt.Sprintf("invalid compatibility texts: %v", err))
+	}
+	if _, ok := sources[models.LanguageRussian]; !ok {
+		panic("compatibility texts have no Russian version")
+	}
+
+	texts := make(map[models.Language]map[models.CompatibilityCriterion]map[models.CompatibilityStatus]*template.Template, len(sources))
+	for language, source := range sources {
+		texts[language] = make(map[models.CompatibilityCriterion]map[models.CompatibilityStatus]*template.Template)
+		for criterion, statuses := range compatibilityStatuses {
+			texts[language][criterion] = make(map[models.CompatibilityStatus]*template.Template)
+			for _, status := range statuses {
+				text, ok := source[criterion][status]
+				if !ok {
+					panic(fmt.Sprintf("compatibility texts for %s have no %s %s text", language, criterion, status))
+				}
+				parsed, err := template.New(string(language) + "/" + string(criterion) + "/" + string(status)).Parse(text)
+				if err != nil {
+					panic(fmt.Sprintf("invalid %s %s %s compatibility text: %v", language, criterion, status, err))
+				}
+				texts[language][criterion][status] = parsed
+			}
+		}
+	}
+	return texts
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// compatibilityPlant builds a plant with the care needs compared by the compatibility checker
+func compatibilityPlant(name string, sunlight models.SunlightLevel, humidity models.HumidityLevel, watering int, wateringMin, wateringMax *int, minTemp, maxTemp int) *models.Plant {
+	return &models.Plant{
+		ID:   uuid.New(),
+		Name: name,
+		CareInstructions: models.CareInstructions{
+			WateringFrequency:    watering,
+			WateringFrequencyMin: wateringMin,
+			WateringFrequencyMax: wateringMax,
+			Sunlight:             sunlight,
+			Humidity:             humidity,
+			Temperature:          models.TemperatureRange{Min: minTemp, Max: maxTemp},
+		},
+	}
+}
+
+// checkStatuses maps the checks of a compatibility by criterion
+func checkStatuses(compatibility *models.PlantCompatibility) map[models.CompatibilityCriterion]models.CompatibilityStatus {
+	statuses := make(map[models.CompatibilityCriterion]models.CompatibilityStatus)
+	for _, check := range compatibility.Checks {
+		statuses[check.Criterion] = check.Status
+	}
+	return statuses
+}
+
+// TestPlantCompatibilityOf_Compatible tests plants whose ranges overlap and the care they share
+func TestPlantCompatibilityOf_Compatible(t *testing.T) {
+	ten, fourteen, twentyOne := 10, 14, 21
+	echeveria := compatibilityPlant("Echeveria", models.SunlightLevelHigh, models.HumidityLevelLow, 14, &ten, &twentyOne, 10, 30)
+	haworthia := compatibilityPlant("Haworthia", models.SunlightLevelHigh, models.HumidityLevelLow, 10, nil, &fourteen, 12, 27)
+
+	compatibility := PlantCompatibilityOf([]*models.Plant{echeveria, haworthia}, models.LanguageEnglish)
+
+	assert.True(t, compatibility.Compatible)
+	assert.Equal(t, models.CompatibilityStatusCompatible, compatibility.Status)
+	assert.Len(t, compatibility.Checks, 4)
+	assert.Equal(t, &models.SharedCare{
+		WateringFrequency: 12,
+		Sunlight:          models.SunlightLevelHigh,
+		Humidity:          models.HumidityLevelLow,
+		Temperature:       models.TemperatureRange{Min: 12, Max: 27},
+	}, compatibility.SharedCare)
+	assert.Equal(t, "They can be watered together: every 10 to 14 days.", compatibility.Checks[1].Explanation)
+	assert.Equal(t, "All plants do well between 12 and 27 °C.", compatibility.Checks[3].Explanation)
+}
+
+// TestPlantCompatibilityOf_Caution tests compromises between adjacent levels and close watering
+// frequencies
+func TestPlantCompatibilityOf_Caution(t *testing.T) {
+	pothos := compatibilityPlant("Pothos", models.SunlightLevelLow, models.HumidityLevelMedium, 7, nil, nil, 15, 29)
+	monstera := compatibilityPlant("Monstera", models.SunlightLevelMedium, models.HumidityLevelHigh, 9, nil, nil, 18, 30)
+
+	compatibility := PlantCompatibilityOf([]*models.Plant{pothos, monstera}, models.LanguageEnglish)
+
+	assert.True(t, compatibility.Compatible)
+	assert.Equal(t, models.CompatibilityStatusCaution, compatibility.Status)
+	assert.Equal(t, map[models.CompatibilityCriterion]models.CompatibilityStatus{
+		models.CompatibilityCriterionLight:       models.CompatibilityStatusCaution,
+		models.CompatibilityCriterionWater:       models.CompatibilityStatusCaution,
+		models.CompatibilityCriterionHumidity:    models.CompatibilityStatusCaution,
+		models.CompatibilityCriterionTemperature: models.CompatibilityStatusCompatible,
+	}, checkStatuses(compatibility))
+	assert.Equal(t, 8, compatibility.SharedCare.WateringFrequency)
+	assert.Equal(t, models.SunlightLevelMedium, compatibility.SharedCare.Sunlight)
+	assert.Equal(t, "Pothos is watered every 7 days and Monstera every 9 days: watering every 8 days is a compromise, keep an eye on both.",
+		compatibility.Checks[1].Explanation)
+}
+
+// TestPlantCompatibilityOf_Incompatible tests conflicting needs, explained in the requested language
+// and in Russian for unsupported ones
+func TestPlantCompatibilityOf_Incompatible(t *testing.T) {
+	ten := 10
+	fern := compatibilityPlant("Fern", models.SunlightLevelLow, models.HumidityLevelHigh, 3, nil, nil, 16, 24)
+	cactus := compatibilityPlant("Cactus", models.SunlightLevelHigh, models.HumidityLevelLow, 14, &ten, nil, 25, 35)
+	moss := compatibilityPlant("Moss", models.SunlightLevelLow, models.HumidityLevelHigh, 3, nil, nil, 10, 22)
+
+	compatibility := PlantCompatibilityOf([]*models.Plant{fern, cactus, moss}, models.LanguageEnglish)
+
+	assert.False(t, compatibility.Compatible)
+	assert.Equal(t, models.CompatibilityStatusIncompatible, compatibility.Status)
+	assert.Nil(t, compatibility.SharedCare)
+	for _, check := range compatibility.Checks {
+		assert.Equal(t, models.CompatibilityStatusIncompatible, check.Status, check.Criterion)
+	}
+	assert.Equal(t, "Fern needs water at least every 3 days while Cactus needs at least 10 days between waterings: in shared soil one of them dries out or rots.",
+		compatibility.Checks[1].Explanation)
+	assert.Equal(t, "Moss needs at most 22 °C while Cactus needs at least 25 °C: there is no temperature both do well in.",
+		compatibility.Checks[3].Explanation)
+
+	russian := PlantCompatibilityOf([]*models.Plant{fern, cactus}, models.Language("GERMAN"))
+	assert.Equal(t, "Растению Fern нужна тень, а растению Cactus — яркий свет: при любом освещении одно из них будет страдать.",
+		russian.Checks[0].Explanation)
+}
+
+// TestPlantService_CheckPlantCompatibility_NotFound tests that a missing plant fails the check
+func TestPlantService_CheckPlantCompatibility_NotFound(t *testing.T) {
+	mockRepo := new(MockPlantRepository)
+	service := NewPlantService(mockRepo)
+	existing := compatibilityPlant("Pothos", models.SunlightLevelLow, models.HumidityLevelMedium, 7, nil, nil, 15, 29)
+	missingID := uuid.New()
+	mockRepo.On("GetByID", context.Background(), existing.ID).Return(existing, nil)
+	mockRepo.On("GetByID", context.Background(), missingID).Return(nil, sql.ErrNoRows)
+
+	_, err := service.CheckPlantCompatibility(context.Background(), []uuid.UUID{existing.ID, missingID}, models.LanguageEnglish)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPlantEnrichmentUnavailable is returned when no enrichment source is configured
+	ErrPlantEnrichmentUnavailable = errors.New("plant enrichment sources are not configured")
+	// ErrUnknownPlantEnrichmentSource is returned for enrichment source names that are not supported
+	ErrUnknownPlantEnrichmentSource = errors.New("unknown plant enrichment source")
+	// ErrPlantEnrichmentProposalNotFound is returned when a proposal does not exist or was already reviewed
+	ErrPlantEnrichmentProposalNotFound = errors.New("pending plant enrichment proposal not found")
+)
+
+// defaultPlantEnrichmentLimit is the number of plants an enrichment run checks when not given
+const defaultPlantEnrichmentLimit = 50
+
+// PlantEnrichmentProvider looks up plant fields in an external source
+type PlantEnrichmentProvider interface {
+	// Name returns the source name stored with proposals
+	Name() string
+
+	// Enrich returns the fields the source knows for a plant, found by its scientific name. Providers
+	// may skip the fields the plant already has.
+	Enrich(ctx context.Context, plant *models.Plant) (*models.PlantEnrichment, error)
+}
+
+// NewPlantEnrichmentProviders creates the providers of the configured sources: gbif and wikidata.
+// Requests identify themselves with userAgent, as the sources ask of automated clients.
+func NewPlantEnrichmentProviders(names []string, userAgent string) ([]PlantEnrichmentProvider, error) {
+	var providers []PlantEnrichmentProvider
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "gbif":
+			providers = append(providers, NewGBIFProvider(userAgent))
+		case "wikidata":
+			providers = append(providers, NewWikidataProvider(userAgent))
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPlantEnrichmentSource, name)
+		}
+	}
+	return providers, nil
+}
+
+// PlantEnrichmentService fills missing plant fields from external sources. Nothing is written to the
+// catalog directly: the fields found are queued as proposals that an admin approves or rejects.
+type PlantEnrichmentService struct {
+	enrichmentRepo repository.PlantEnrichmentRepository
+	providers      []PlantEnrichmentProvider
+}
+
+// NewPlantEnrichmentService creates a new plant enrichment service
+func NewPlantEnrichmentService(enrichmentRepo repository.PlantEnrichmentRepository, providers ...PlantEnrichmentProvider) *PlantEnrichmentService {
+	return &PlantEnrichmentService{
+		enrichmentRepo: enrichmentRepo,
+		providers:      providers,
+	}
+}
+
+// Run asks every source for the missing fields of up to limit plants, the ones checked least recently
+// first, and queues what they found for review. A failing lookup does not stop the run; its error is
+// reported and the plant is checked again by the next run.
+func (s *PlantEnrichmentService) Run(ctx context.Context, limit int) (*models.PlantEnrichmentRun, error) {
+	if len(s.providers) == 0 {
+		return nil, ErrPlantEnrichmentUnavailable
+	}
+	if limit < 1 || limit > 500 {
+		limit = defaultPlantEnrichmentLimit
+	}
+
+	plants, err := s.enrichmentRepo.GetCandidates(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.PlantEnrichmentRun{Proposals: []*models.PlantEnrichmentProposal{}}
+	for _, plant := range plants {
+		failed := false
+		for _, provider := range s.providers {
+			enrichment, err := provider.Enrich(ctx, plant)
+			if err != nil {
+				failed = true
+				run.Errors = append(run.Errors, fmt.Sprintf("%s: %s: %v", provider.Name(), plant.ScientificName, err))
+				continue
+			}
+
+			for _, proposal := range plantEnrichmentProposals(plant, provider.Name(), enrichment) {
+				created, err := s.enrichmentRepo.Propose(ctx, proposal)
+				if err != nil {
+					return nil, err
+				}
+				if created {
+					run.Proposals = append(run.Proposals, proposal)
+				}
+			}
+		}
+
+		run.PlantsChecked++
+		if failed {
+			continue
+		}
+		if err := s.enrichmentRepo.MarkChecked(ctx, plant.ID); err != nil {
+			log.Printf("Failed to mark plant %s enrichment checked: %v", plant.ID, err)
+		}
+	}
+
+	// Counters are logged in a fixed format so log-based metrics can pick them up
+	log.Printf("plant enrichment plants=%d proposals=%d errors=%d", run.PlantsChecked, len(run.Proposals), len(run.Errors))
+	return run, nil
+}
+
+// GetPendingProposals gets the proposals awaiting review
+func (s *PlantEnrichmentService) GetPendingProposals(ctx context.Context) ([]*models.PlantEnrichmentProposal, error) {
+	return s.enrichmentRepo.GetPending(ctx)
+}
+
+// ReviewProposal approves or rejects a pending proposal; an approved proposal is applied to its plant
+func (s *PlantEnrichmentService) ReviewProposal(ctx context.Context, id uuid.UUID, status models.PlantEnrichmentStatus, reviewerID uuid.UUID) (*models.PlantEnrichmentProposal, error) {
+	if status != models.PlantEnrichmentStatusApproved && status != models.PlantEnrichmentStatusRejected {
+		return nil, fmt.Errorf("invalid review status: %s", status)
+	}
+
+	proposal, err := s.enrichmentRepo.Review(ctx, id, status, reviewerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPlantEnrichmentProposalNotFound
+		}
+		return nil, fmt.Errorf("failed to review plant enrichment proposal: %w", err)
+	}
+	return proposal, nil
+}
+
+// plantEnrichmentProposals turns the fields a source found into one proposal per field the plant
+// misses. Synonyms repeating the scientific name and images whose license does not allow showing them
+// are left out.
+func plantEnrichmentProposals(plant *models.Plant, source string, enrichment *models.PlantEnrichment) []*models.PlantEnrichmentProposal {
+	if enrichment == nil {
+		return nil
+	}
+
+	var proposals []*models.PlantEnrichmentProposal
+	propose := func(field models.PlantEnrichmentField, value models.PlantEnrichment) {
+		proposals = append(proposals, &models.PlantEnrichmentProposal{
+			PlantID:   plant.ID,
+			PlantName: plant.Name,
+			Field:     field,
+			Source:    source,
+			Value:     value,
+		})
+	}
+
+	if len(plant.Synonyms) == 0 {
+		if synonyms := uniqueSynonyms(plant.ScientificName, enrichment.Synonyms); len(synonyms) > 0 {
+			propose(models.PlantEnrichmentFieldSynonyms, models.PlantEnrichment{Synonyms: synonyms})
+		}
+	}
+	if plant.ImageURL == "" && enrichment.Image != nil && enrichment.Image.URL != "" && reusableImageLicense(enrichment.Image.License) {
+		propose(models.PlantEnrichmentFieldImage, models.PlantEnrichment{Image: enrichment.Image})
+	}
+	if plant.PetFriendly == nil && enrichment.PetFriendly != nil {
+		propose(models.PlantEnrichmentFieldToxicity, models.PlantEnrichment{PetFriendly: enrichment.PetFriendly})
+	}
+	return proposals
+}
+
+// uniqueSynonyms returns the synonyms without blanks, repeats and the scientific name itself, in order
+func uniqueSynonyms(scientificName string, synonyms []string) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(scientificName)): true}
+	var unique []string
+	for _, synonym := range synonyms {
+		synonym = strings.TrimSpace(synonym)
+		key := strings.ToLower(synonym)
+		if synonym == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, synonym)
+	}
+	return unique
+}
+
+// reusableImageLicense reports whether an image license lets the catalog show the image, with the
+// attribution it asks for: public domain and Creative Commons licenses without the non-commercial
+// and no-derivatives terms. Licenses are names such as "CC BY-SA 4.0" or deed URLs.
+func reusableImageLicense(license string) bool {
+	terms := map[string]bool{}
+	for _, term := range strings.FieldsFunc(strings.ToLower(license), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		terms[term] = true
+	}
+	if terms["nc"] || terms["nd"] {
+		return false
+	}
+	return terms["by"] || terms["cc0"] || terms["publicdomain"] || (terms["public"] && terms["domain"])
+}
+
+// getEnrichmentJSON sends a GET request to an enrichment source and decodes its JSON response
+func getEnrichmentJSON(ctx context.Context, client *http.Client, url string, userAgent string, target interface{}) error {
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	// Send the request
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check the response status
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("API returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// Parse the response
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPlantEnrichmentRepository is a mock implementation of the PlantEnrichmentRepository interface
+type MockPlantEnrichmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlantEnrichmentRepository) GetCandidates(ctx context.Context, limit int) ([]*models.Plant, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]*models.Plant), args.Error(1)
+}
+
+func (m *MockPlantEnrichmentRepository) MarkChecked(ctx context.Context, plantID uuid.UUID) error {
+	args := m.Called(ctx, plantID)
+	return args.Error(0)
+}
+
+func (m *MockPlantEnrichmentRepository) Propose(ctx context.Context, proposal *models.PlantEnrichmentProposal) (bool, error) {
+	args := m.Called(ctx, proposal)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPlantEnrichmentRepository) GetPending(ctx context.Context) ([]*models.PlantEnrichmentProposal, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.PlantEnrichmentProposal), args.Error(1)
+}
+
+func (m *MockPlantEnrichmentRepository) Review(ctx context.Context, id uuid.UUID, status models.PlantEnrichmentStatus, reviewerID uuid.UUID) (*models.PlantEnrichmentProposal, error) {
+	args := m.Called(ctx, id, status, reviewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PlantEnrichmentProposal), args.Error(1)
+}
+
+// stubEnrichmentProvider returns fixed fields, or fails for the plants in failing
+type stubEnrichmentProvider struct {
+	name       string
+	enrichment *models.PlantEnrichment
+	failing    map[uuid.UUID]bool
+}
+
+func (p *stubEnrichmentProvider) Name() string {
+	return p.name
+}
+
+func (p *stubEnrichmentProvider) Enrich(ctx context.Context, plant *models.Plant) (*models.PlantEnrichment, error) {
+	if p.failing[plant.ID] {
+		return nil, errors.New("service unavailable")
+	}
+	return p.enrichment, nil
+}
+
+// TestPlantEnrichmentService_Run tests that only the fields a plant misses are proposed, that images
+// need a reusable license, and that plants whose lookup failed are not marked checked
+func TestPlantEnrichmentService_Run(t *testing.T) {
+	mockRepo := new(MockPlantEnrichmentRepository)
+	ctx := context.Background()
+	petFriendly := false
+	monstera := &models.Plant{ID: uuid.New(), Name: "Monstera", ScientificName: "Monstera deliciosa", ImageURL: "plants/monstera.jpg"}
+	ficus := &models.Plant{ID: uuid.New(), Name: "Ficus", ScientificName: "Ficus elastica", PetFriendly: &petFriendly}
+
+	gbif := &stubEnrichmentProvider{name: "gbif", enrichment: &models.PlantEnrichment{
+		Synonyms: []string{"Philodendron pertusum", "monstera deliciosa", " ", "Philodendron pertusum"},
+		Image:    &models.PlantImage{URL: "https://example.org/plant.jpg", License: "http://creativecommons.org/licenses/by-nc/4.0/"},
+	}}
+	wikidata := &stubEnrichmentProvider{name: "wikidata", failing: map[uuid.UUID]bool{ficus.ID: true}, enrichment: &models.PlantEnrichment{
+		Image:       &models.PlantImage{URL: "https://example.org/plant.jpg", License: "CC BY-SA 4.0", Attribution: "Anna"},
+		PetFriendly: &petFriendly,
+	}}
+	service := NewPlantEnrichmentService(mockRepo, gbif, wikidata)
+
+	var proposed []*models.PlantEnrichmentProposal
+	mockRepo.On("GetCandidates", ctx, defaultPlantEnrichmentLimit).Return([]*models.Plant{monstera, ficus}, nil)
+	mockRepo.On("Propose", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		proposed = append(proposed, args.Get(1).(*models.PlantEnrichmentProposal))
+	}).Return(true, nil)
+	mockRepo.On("MarkChecked", ctx, monstera.ID).Return(nil).Once()
+
+	run, err := service.Run(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, run.PlantsChecked)
+	assert.Len(t, run.Errors, 1)
+
+	var summary []string
+	for _, proposal := range proposed {
+		summary = append(summary, fmt.Sprintf("%s %s %s", proposal.PlantName, proposal.Source, proposal.Field))
+	}
+	assert.Equal(t, []string{
+		"Monstera gbif SYNONYMS",
+		"Monstera wikidata TOXICITY",
+		"Ficus gbif SYNONYMS",
+	}, summary)
+	assert.Equal(t, []string{"Philodendron pertusum"}, proposed[0].Value.Synonyms)
+	assert.Equal(t, run.Proposals, proposed)
+	mockRepo.AssertExpectations(t)
+
+	// Without sources nothing runs
+	_, err = NewPlantEnrichmentService(mockRepo).Run(ctx, 10)
+	assert.ErrorIs(t, err, ErrPlantEnrichmentUnavailable)
+}
+
+// TestPlantEnrichmentService_ReviewProposal tests reviewing proposals that were already reviewed
+func TestPlantEnrichmentService_ReviewProposal(t *testing.T) {
+	mockRepo := new(MockPlantEnrichmentRepository)
+	service := NewPlantEnrichmentService(mockRepo)
+	ctx := context.Background()
+	id, reviewerID := uuid.New(), uuid.New()
+
+	mockRepo.On("Review", ctx, id, models.PlantEnrichmentStatusApproved, reviewerID).
+		Return(nil, fmt.Errorf("pending plant enrichment proposal not found: %w", sql.ErrNoRows))
+
+	_, err := service.ReviewProposal(ctx, id, models.PlantEnrichmentStatusApproved, reviewerID)
+	assert.ErrorIs(t, err, ErrPlantEnrichmentProposalNotFound)
+
+	_, err = service.ReviewProposal(ctx, id, models.PlantEnrichmentStatusPending, reviewerID)
+	assert.Error(t, err)
+	mockRepo.AssertNumberOfCalls(t, "Review", 1)
+}
+
+// TestReusableImageLicense tests which image licenses allow showing an image in the catalog
+func TestReusableImageLicense(t *testing.T) {
+	for license, reusable := range map[string]bool{
+		"CC BY-SA 4.0":  true,
+		"CC0":           true,
+		"Public domain": true,
+		"http://creativecommons.org/licenses/by/4.0/":       true,
+		"http://creativecommons.org/publicdomain/zero/1.0/": true,
+		"http://creativecommons.org/licenses/by-nc/4.0/":    false,
+		"CC BY-ND 2.0":        false,
+		"All rights reserved": false,
+		"":                    false,
+	} {
+		assert.Equal(t, reusable, reusableImageLicense(license), license)
+	}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/anpanovv/planter/internal/events"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidPlantEventCursor is returned for cursors that were not issued by the events endpoint
+	ErrInvalidPlantEventCursor = errors.New("invalid plant event cursor")
+
+	// ErrInvalidPlantEventType is returned when the events are filtered by an unknown type
+	ErrInvalidPlantEventType = errors.New("invalid plant event type")
+)
+
+const (
+	// defaultPlantEventPageSize is the number of events returned per page unless asked otherwise
+	defaultPlantEventPageSize = 50
+
+	// maxPlantEventPageSize is the largest page of events that can be requested
+	maxPlantEventPageSize = 200
+)
+
+// plantEventTypes holds the known plant event types
+var plantEventTypes = map[models.PlantEventType]bool{
+	models.PlantEventTypeWatered:    true,
+	models.PlantEventTypeFertilized: true,
+	models.PlantEventTypeRepotted:   true,
+	models.PlantEventTypeMoved:      true,
+	models.PlantEventTypePhotoAdded: true,
+}
+
+// PlantEventRecorder records lifecycle events of plants in users' collections
+type PlantEventRecorder interface {
+	Record(ctx context.Context, event *models.PlantEvent) error
+}
+
+// recordPlantEvent records a plant event when a recorder is set; failures are only logged so they
+// never fail the operation the event describes
+func recordPlantEvent(
+	ctx context.Context,
+	recorder PlantEventRecorder,
+	userID uuid.UUID,
+	plantID uuid.UUID,
+	eventType models.PlantEventType,
+	payload models.PlantEventPayload,
+) {
+	if recorder == nil {
+		return
+	}
+	event := &models.PlantEvent{
+		UserID:  userID,
+		PlantID: plantID,
+		Type:    eventType,
+		Payload: payload,
+	}
+	if err := recorder.Record(ctx, event); err != nil {
+		log.Printf("Failed to record %s event of plant %s for user %s: %v", eventType, plantID, userID, err)
+	}
+}
+
+// PlantEventService keeps the lifecycle event stream of plants in users' collections
+type PlantEventService struct {
+	plantEventRepo repository.PlantEventRepository
+	plantRepo      repository.PlantRepository
+	publisher      events.Publisher
+}
+
+// NewPlantEventService creates a new plant event service
+func NewPlantEventService(plantEventRepo repository.PlantEventRepository, plantRepo repository.PlantRepository) *PlantEventService {
+	return &PlantEventService{
+		plantEventRepo: plantEventRepo,
+		plantRepo:      plantRepo,
+		publisher:      events.NopPublisher{},
+	}
+}
+
+// SetEventPublisher sets the publisher domain events are sent to
+func (s *PlantEventService) SetEventPublisher(publisher events.Publisher) {
+	s.publisher = publisher
+}
+
+// Record appends an event to the plant's event stream and publishes it for external automation
+func (s *PlantEventService) Record(ctx context.Context, event *models.PlantEvent) error {
+	if event.Payload == nil {
+		event.Payload = models.PlantEventPayload{}
+	}
+	if err := s.plantEventRepo.Create(ctx, event); err != nil {
+		return fmt.Errorf("failed to record plant event: %w", err)
+	}
+	publishEvent(ctx, s.publisher, plantLifecycleEvent(event))
+	return nil
+}
+
+// List gets a page of the event stream of a plant in the user's collection, oldest event first
+func (s *PlantEventService) List(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, filter *models.PlantEventFilter) (*models.PlantEventPage, error) {
+	afterSeq, err := decodePlantEventCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	for _, eventType := range filter.Types {
+		if !plantEventTypes[eventType] {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPlantEventType, eventType)
+		}
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultPlantEventPageSize
+	}
+	if limit > maxPlantEventPageSize {
+		limit = maxPlantEventPageSize
+	}
+
+	// Check if the user owns the plant
+	if _, err := s.plantRepo.GetUserPlant(ctx, userID, plantID); err != nil {
+		return nil, fmt.Errorf("plant not in user's collection: %w", err)
+	}
+
+	// Get one event more than asked for to know whether there are more
+	plantEvents, err := s.plantEventRepo.ListAfter(ctx, userID, plantID, afterSeq, filter.Types, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant events: %w", err)
+	}
+
+	page := &models.PlantEventPage{Events: plantEvents}
+	if len(plantEvents) > limit {
+		page.Events = plantEvents[:limit]
+		page.HasMore = true
+	}
+
+	// Without new events the cursor stays where it was, so clients can keep polling with it
+	lastSeq := afterSeq
+	if len(page.Events) > 0 {
+		lastSeq = page.Events[len(page.Events)-1].Seq
+	}
+	page.NextCursor = encodePlantEventCursor(lastSeq)
+	return page, nil
+}
+
+// plantLifecycleEvent builds the domain event published for a stored plant event
+func plantLifecycleEvent(event *models.PlantEvent) events.PlantLifecycle {
+	return events.PlantLifecycle{
+		ID:         event.ID,
+		UserID:     event.UserID,
+		PlantID:    event.PlantID,
+		Type:       string(event.Type),
+		Payload:    event.Payload,
+		OccurredAt: event.OccurredAt,
+	}
+}
+
+// encodePlantEventCursor encodes a position in the event stream as an opaque cursor
+func encodePlantEventCursor(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(seq, 10)))
+}
+
+// decodePlantEventCursor decodes a cursor into a position in the event stream; an empty cursor is the start
+func decodePlantEventCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidPlantEventCursor
+	}
+	seq, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || seq < 0 {
+		return 0, ErrInvalidPlantEventCursor
+	}
+	return seq, nil
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/events"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPlantEventRepository is a mock implementation of the PlantEventRepository interface
+type MockPlantEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlantEventRepository) Create(ctx context.Context, event *models.PlantEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockPlantEventRepository) ListAfter(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, afterSeq int64, types []models.PlantEventType, limit int) ([]*models.PlantEvent, error) {
+	args := m.Called(ctx, userID, plantID, afterSeq, types, limit)
+	return args.Get(0).([]*models.PlantEvent), args.Error(1)
+}
+
+// capturingPublisher keeps the events published to it
+type capturingPublisher struct {
+	published []events.Event
+}
+
+func (p *capturingPublisher) Publish(ctx context.Context, event events.Event) error {
+	p.published = append(p.published, event)
+	return nil
+}
+
+// plantEventsFixture returns events with consecutive positions in the stream starting after afterSeq
+func plantEventsFixture(userID uuid.UUID, plantID uuid.UUID, afterSeq int64, count int) []*models.PlantEvent {
+	plantEvents := make([]*models.PlantEvent, count)
+	for i := range plantEvents {
+		plantEvents[i] = &models.PlantEvent{
+			ID:         uuid.New(),
+			Seq:        afterSeq + int64(i) + 1,
+			UserID:     userID,
+			PlantID:    plantID,
+			Type:       models.PlantEventTypeWatered,
+			Payload:    models.PlantEventPayload{},
+			OccurredAt: time.Date(2024, time.May, 1+i, 9, 0, 0, 0, time.UTC),
+		}
+	}
+	return plantEvents
+}
+
+// TestPlantEventService_List_Pages tests that the cursor of a page continues right after its last event
+func TestPlantEventService_List_Pages(t *testing.T) {
+	mockPlantEventRepo := new(MockPlantEventRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewPlantEventService(mockPlantEventRepo, mockPlantRepo)
+	ctx := context.Background()
+	userID, plantID := uuid.New(), uuid.New()
+	types := []models.PlantEventType{models.PlantEventTypeWatered}
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID}, nil)
+	mockPlantEventRepo.On("ListAfter", ctx, userID, plantID, int64(0), types, 3).Return(plantEventsFixture(userID, plantID, 0, 3), nil)
+	mockPlantEventRepo.On("ListAfter", ctx, userID, plantID, int64(2), types, 3).Return(plantEventsFixture(userID, plantID, 2, 1), nil)
+	mockPlantEventRepo.On("ListAfter", ctx, userID, plantID, int64(3), types, 3).Return([]*models.PlantEvent{}, nil)
+
+	page, err := service.List(ctx, userID, plantID, &models.PlantEventFilter{Limit: 2, Types: types})
+	assert.NoError(t, err)
+	assert.Len(t, page.Events, 2)
+	assert.True(t, page.HasMore)
+
+	page, err = service.List(ctx, userID, plantID, &models.PlantEventFilter{Cursor: page.NextCursor, Limit: 2, Types: types})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Events, 1) {
+		assert.Equal(t, int64(3), page.Events[0].Seq)
+	}
+	assert.False(t, page.HasMore)
+
+	// Polling with the last cursor keeps it until new events arrive
+	cursor := page.NextCursor
+	page, err = service.List(ctx, userID, plantID, &models.PlantEventFilter{Cursor: cursor, Limit: 2, Types: types})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Events)
+	assert.Equal(t, cursor, page.NextCursor)
+	mockPlantEventRepo.AssertExpectations(t)
+}
+
+// TestPlantEventService_List_Invalid tests that malformed cursors and unknown types are rejected
+func TestPlantEventService_List_Invalid(t *testing.T) {
+	service := NewPlantEventService(new(MockPlantEventRepository), new(MockPlantRepository))
+	ctx := context.Background()
+
+	for _, cursor := range []string{"not a cursor!", encodePlantEventCursor(-1), "YWJj"} {
+		_, err := service.List(ctx, uuid.New(), uuid.New(), &models.PlantEventFilter{Cursor: cursor})
+		assert.ErrorIs(t, err, ErrInvalidPlantEventCursor, cursor)
+	}
+	_, err := service.List(ctx, uuid.New(), uuid.New(), &models.PlantEventFilter{Types: []models.PlantEventType{"PRUNED"}})
+	assert.ErrorIs(t, err, ErrInvalidPlantEventType)
+}
+
+// TestPlantEventService_List_NotOwned tests that only the events of plants in the user's collection are listed
+func TestPlantEventService_List_NotOwned(t *testing.T) {
+	mockPlantEventRepo := new(MockPlantEventRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewPlantEventService(mockPlantEventRepo, mockPlantRepo)
+	userID, plantID := uuid.New(), uuid.New()
+
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userID, plantID).Return(nil, fmt.Errorf("user plant not found: %w", sql.ErrNoRows))
+
+	_, err := service.List(context.Background(), userID, plantID, &models.PlantEventFilter{})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	mockPlantEventRepo.AssertNotCalled(t, "ListAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestPlantService_UpdateUserPlant_RecordsMove tests that moving a plant to another room is
+// recorded and published with the stored event
+func TestPlantService_UpdateUserPlant_RecordsMove(t *testing.T) {
+	mockPlantEventRepo := new(MockPlantEventRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	publisher := &capturingPublisher{}
+	plantEventService := NewPlantEventService(mockPlantEventRepo, mockPlantRepo)
+	plantEventService.SetEventPublisher(publisher)
+	plantService := NewPlantService(mockPlantRepo)
+	plantService.SetPlantEventRecorder(plantEventService)
+	userID, plantID := uuid.New(), uuid.New()
+	kitchen, bedroom := "Kitchen", "Bedroom"
+
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID, Location: &kitchen}, nil)
+	mockPlantRepo.On("UpdateUserPlant", mock.Anything, mock.Anything).Return(nil)
+	mockPlantEventRepo.On("Create", mock.Anything, mock.MatchedBy(func(event *models.PlantEvent) bool {
+		return event.Type == models.PlantEventTypeMoved && event.Payload["from"] == "Kitchen" && event.Payload["to"] == "Bedroom"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.PlantEvent).Seq = 7
+	}).Return(nil).Once()
+
+	assert.NoError(t, plantService.UpdateUserPlant(context.Background(), userID, plantID, &bedroom, models.UserPlantDetails{}))
+	if assert.Len(t, publisher.published, 1) {
+		event := publisher.published[0].(events.PlantLifecycle)
+		assert.Equal(t, "MOVED", event.Type)
+		assert.Equal(t, "Bedroom", event.Payload["to"])
+	}
+
+	// Saving the same room again is not a move
+	kitchenAgain := "Kitchen"
+	mockPlantRepo.ExpectedCalls = nil
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID, Location: &kitchenAgain}, nil)
+	mockPlantRepo.On("UpdateUserPlant", mock.Anything, mock.Anything).Return(nil)
+	assert.NoError(t, plantService.UpdateUserPlant(context.Background(), userID, plantID, &kitchenAgain, models.UserPlantDetails{}))
+	mockPlantEventRepo.AssertExpectations(t)
+}
+
+// TestDiagnosisService_Diagnose_RecordsPhoto tests that a diagnosed photo is added to the event stream
+// and that a failure to record it does not fail the diagnosis
+func TestDiagnosisService_Diagnose_RecordsPhoto(t *testing.T) {
+	mockDiagnosisRepo := new(MockDiagnosisRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockProvider := new(MockDiagnosisProvider)
+	mockPlantEventRepo := new(MockPlantEventRepository)
+	service := NewDiagnosisService(mockDiagnosisRepo, mockPlantRepo, mockProvider)
+	service.SetPlantEventRecorder(NewPlantEventService(mockPlantEventRepo, mockPlantRepo))
+	userID, plantID := uuid.New(), uuid.New()
+
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID}, nil)
+	mockProvider.On("Diagnose", mock.Anything, pngHeader, "image/png").Return([]*models.DiagnosisFinding{}, nil)
+	mockDiagnosisRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockPlantEventRepo.On("Create", mock.Anything, mock.MatchedBy(func(event *models.PlantEvent) bool {
+		return event.Type == models.PlantEventTypePhotoAdded && event.Payload["diagnosisId"] != nil
+	})).Return(fmt.Errorf("connection refused"))
+
+	diagnosis, err := service.Diagnose(context.Background(), userID, plantID, pngHeader)
+	assert.NoError(t, err)
+	assert.NotNil(t, diagnosis)
+	mockPlantEventRepo.AssertExpectations(t)
+}
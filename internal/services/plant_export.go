@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidPlantExport is returned for plant exports selecting unknown or repeated fields
+	ErrInvalidPlantExport = errors.New("invalid plant export")
+
+	// ErrUnsupportedPlantExportFormat is returned for plant exports that are neither CSV nor JSON
+	ErrUnsupportedPlantExportFormat = errors.New("plant export must be CSV or JSON")
+)
+
+// Formats of plant catalog exports, the same as of imports
+const (
+	PlantExportFormatCSV  = PlantImportFormatCSV
+	PlantExportFormatJSON = PlantImportFormatJSON
+)
+
+// plantExportFlushRows is the number of CSV rows written before they are sent to the client
+const plantExportFlushRows = 100
+
+// plantExportField is a field of a catalog export: a CSV column, named like the columns of imports,
+// and the path of the field in a JSON element, in the shape of the admin plant requests
+type plantExportField struct {
+	name  string
+	path  []string
+	value func(plant *models.Plant) interface{} // nil leaves the field empty
+}
+
+// plantExportFields are the fields of a catalog export in their default order. Exporting only the
+// columns imports take gives a file an import reads back.
+var plantExportFields = []plantExportField{
+	{"id", []string{"id"}, func(p *models.Plant) interface{} { return p.ID }},
+	{"name", []string{"name"}, func(p *models.Plant) interface{} { return p.Name }},
+	{"scientific_name", []string{"scientificName"}, func(p *models.Plant) interface{} { return p.ScientificName }},
+	{"family", []string{"family"}, func(p *models.Plant) interface{} { return p.Family }},
+	{"description", []string{"description"}, func(p *models.Plant) interface{} { return p.Description }},
+	{"image_url", []string{"imageUrl"}, func(p *models.Plant) interface{} { return p.ImageURL.URL() }},
+	{"price", []string{"price"}, func(p *models.Plant) interface{} { return p.Price }},
+	{"shop_id", []string{"shopId"}, func(p *models.Plant) interface{} { return p.ShopID }},
+	{"pet_friendly", []string{"petFriendly"}, func(p *models.Plant) interface{} { return p.PetFriendly }},
+	{"species_id", []string{"speciesId"}, func(p *models.Plant) interface{} { return p.SpeciesID }},
+	{"watering_frequency", []string{"careInstructions", "wateringFrequency"}, func(p *models.Plant) interface{} { return p.CareInstructions.WateringFrequency }},
+	{"watering_frequency_min", []string{"careInstructions", "wateringFrequencyMin"}, func(p *models.Plant) interface{} { return p.CareInstructions.WateringFrequencyMin }},
+	{"watering_frequency_max", []string{"careInstructions", "wateringFrequencyMax"}, func(p *models.Plant) interface{} { return p.CareInstructions.WateringFrequencyMax }},
+	{"sunlight", []string{"careInstructions", "sunlight"}, func(p *models.Plant) interface{} { return string(p.CareInstructions.Sunlight) }},
+	{"humidity", []string{"careInstructions", "humidity"}, func(p *models.Plant) interface{} { return string(p.CareInstructions.Humidity) }},
+	{"min_temperature", []string{"careInstructions", "temperature", "min"}, func(p *models.Plant) interface{} { return p.CareInstructions.Temperature.Min }},
+	{"max_temperature", []string{"careInstructions", "temperature", "max"}, func(p *models.Plant) interface{} { return p.CareInstructions.Temperature.Max }},
+	{"soil_type", []string{"careInstructions", "soilType"}, func(p *models.Plant) interface{} { return p.CareInstructions.SoilType }},
+	{"fertilizer_frequency", []string{"careInstructions", "fertilizerFrequency"}, func(p *models.Plant) interface{} { return p.CareInstructions.FertilizerFrequency }},
+	{"additional_notes", []string{"careInstructions", "additionalNotes"}, func(p *models.Plant) interface{} { return p.CareInstructions.AdditionalNotes }},
+	{"source_url", []string{"careInstructions", "sourceUrl"}, func(p *models.Plant) interface{} { return p.CareInstructions.SourceURL }},
+	{"source_author", []string{"careInstructions", "sourceAuthor"}, func(p *models.Plant) interface{} { return p.CareInstructions.SourceAuthor }},
+	{"last_reviewed_at", []string{"careInstructions", "lastReviewedAt"}, func(p *models.Plant) interface{} { return p.CareInstructions.LastReviewedAt }},
+	{"created_at", []string{"createdAt"}, func(p *models.Plant) interface{} { return p.CreatedAt }},
+	{"updated_at", []string{"updatedAt"}, func(p *models.Plant) interface{} { return p.UpdatedAt }},
+}
+
+// PlantExport is an export of the catalog in a format with a selection of fields
+type PlantExport struct {
+	format string
+	fields []plantExportField
+}
+
+// NewPlantExport checks the format and the fields of a catalog export; no fields export them all
+func NewPlantExport(format string, fields []string) (*PlantExport, error) {
+	if format != PlantExportFormatCSV && format != PlantExportFormatJSON {
+		return nil, ErrUnsupportedPlantExportFormat
+	}
+	if len(fields) == 0 {
+		return &PlantExport{format: format, fields: plantExportFields}, nil
+	}
+
+	byName := make(map[string]plantExportField, len(plantExportFields))
+	for _, field := range plantExportFields {
+		byName[field.name] = field
+	}
+	export := &PlantExport{format: format}
+	seen := make(map[string]bool, len(fields))
+	for _, name := range fields {
+		name = strings.ToLower(strings.TrimSpace(name))
+		field, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidPlantExport, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: field %q appears twice", ErrInvalidPlantExport, name)
+		}
+		seen[name] = true
+		export.fields = append(export.fields, field)
+	}
+	return export, nil
+}
+
+// ContentType returns the content type of the exported file
+func (e *PlantExport) ContentType() string {
+	if e.format == PlantExportFormatJSON {
+		return "application/json"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Filename returns the name of the exported file on the given day
+func (e *PlantExport) Filename(day time.Time) string {
+	return fmt.Sprintf("plants-%s.%s", day.Format(time.DateOnly), e.format)
+}
+
+// PlantExportService exports the plant catalog with its care instructions, for backups and for
+// syncing partner shops
+type PlantExportService struct {
+	exportRepo repository.PlantExportRepository
+}
+
+// NewPlantExportService creates a new plant export service
+func NewPlantExportService(exportRepo repository.PlantExportRepository) *PlantExportService {
+	return &PlantExportService{
+		exportRepo: exportRepo,
+	}
+}
+
+// Export writes the catalog to w, one plant at a time as it is read, so the catalog is never held in
+// memory. A file cut short by an error ends where the error happened; CSV is flushed every
+// plantExportFlushRows rows.
+func (s *PlantExportService) Export(ctx context.Context, w io.Writer, export *PlantExport) error {
+	if export.format == PlantExportFormatJSON {
+		return s.exportJSON(ctx, w, export.fields)
+	}
+	return s.exportCSV(ctx, w, export.fields)
+}
+
+// exportCSV writes the catalog as CSV with a header naming the fields
+func (s *PlantExportService) exportCSV(ctx context.Context, w io.Writer, fields []plantExportField) error {
+	writer := csv.NewWriter(w)
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = field.name
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write plant export: %w", err)
+	}
+
+	rows := 0
+	record := make([]string, len(fields))
+	err := s.exportRepo.Each(ctx, func(plant *models.Plant) error {
+		for i, field := range fields {
+			record[i] = plantExportCSVValue(field.value(plant))
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write plant export: %w", err)
+		}
+		if rows++; rows%plantExportFlushRows == 0 {
+			writer.Flush()
+		}
+		return writer.Error()
+	})
+	writer.Flush()
+	if err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// exportJSON writes the catalog as a JSON array of plants
+func (s *PlantExportService) exportJSON(ctx context.Context, w io.Writer, fields []plantExportField) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return fmt.Errorf("failed to write plant export: %w", err)
+	}
+
+	separator := "\n"
+	err := s.exportRepo.Each(ctx, func(plant *models.Plant) error {
+		element := map[string]interface{}{}
+		for _, field := range fields {
+			setPlantExportPath(element, field.path, field.value(plant))
+		}
+		data, err := json.Marshal(element)
+		if err != nil {
+			return fmt.Errorf("failed to encode plant %s: %w", plant.ID, err)
+		}
+		if _, err := io.WriteString(w, separator); err != nil {
+			return fmt.Errorf("failed to write plant export: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write plant export: %w", err)
+		}
+		separator = ",\n"
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, "\n]\n"); err != nil {
+		return fmt.Errorf("failed to write plant export: %w", err)
+	}
+	return nil
+}
+
+// setPlantExportPath sets the value of a field in a JSON element, creating the objects on its path
+func setPlantExportPath(element map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := element[key].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			element[key] = child
+		}
+		element = child
+	}
+	element[path[len(path)-1]] = value
+}
+
+// plantExportCSVValue formats the value of a field for a CSV column; missing values are empty
+func plantExportCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case uuid.UUID:
+		return v.String()
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case *string:
+		if v != nil {
+			return *v
+		}
+	case *int:
+		if v != nil {
+			return strconv.Itoa(*v)
+		}
+	case *float64:
+		if v != nil {
+			return strconv.FormatFloat(*v, 'f', -1, 64)
+		}
+	case *bool:
+		if v != nil {
+			return strconv.FormatBool(*v)
+		}
+	case *uuid.UUID:
+		if v != nil {
+			return v.String()
+		}
+	case *time.Time:
+		if v != nil {
+			return v.UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlantExportRepository hands out its plants in order, failing after them when err is set
+type fakePlantExportRepository struct {
+	plants []*models.Plant
+	err    error
+}
+
+func (r *fakePlantExportRepository) Each(ctx context.Context, fn func(plant *models.Plant) error) error {
+	for _, plant := range r.plants {
+		if err := fn(plant); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+// exportedPlant returns a catalog plant with its care instructions as an export reads it
+func exportedPlant() *models.Plant {
+	price := 1290.5
+	petFriendly := false
+	maxDays := 10
+	return &models.Plant{
+		ID:             uuid.MustParse("3f0c7f2e-5d7a-4c8e-9a40-0b3c1f6f7a11"),
+		Name:           "Ficus",
+		ScientificName: "Ficus lyrata",
+		Description:    "Fiddle-leaf fig, \"easy\"",
+		ImageURL:       "https://cdn.example.com/plants/ficus.jpg",
+		Price:          &price,
+		PetFriendly:    &petFriendly,
+		CareInstructions: models.CareInstructions{
+			WateringFrequency:    7,
+			WateringFrequencyMax: &maxDays,
+			Sunlight:             models.SunlightLevelMedium,
+			Humidity:             models.HumidityLevelMedium,
+			Temperature:          models.TemperatureRange{Min: 16, Max: 26},
+			SoilType:             "Loam",
+			FertilizerFrequency:  30,
+		},
+		CreatedAt: time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestNewPlantExport(t *testing.T) {
+	_, err := NewPlantExport("xml", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedPlantExportFormat)
+
+	_, err = NewPlantExport(PlantExportFormatCSV, []string{"name", "owner"})
+	assert.ErrorIs(t, err, ErrInvalidPlantExport)
+
+	_, err = NewPlantExport(PlantExportFormatCSV, []string{"name", " NAME"})
+	assert.ErrorIs(t, err, ErrInvalidPlantExport)
+
+	export, err := NewPlantExport(PlantExportFormatJSON, nil)
+	require.NoError(t, err)
+	assert.Len(t, export.fields, len(plantExportFields))
+	assert.Equal(t, "plants-2024-06-01.json", export.Filename(time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+// TestPlantExportService_Export_CSV tests that the selected fields are written in the requested
+// order and missing values are left empty
+func TestPlantExportService_Export_CSV(t *testing.T) {
+	service := NewPlantExportService(&fakePlantExportRepository{plants: []*models.Plant{exportedPlant()}})
+	export, err := NewPlantExport(PlantExportFormatCSV, []string{"scientific_name", "description", "price", "shop_id", "watering_frequency_max", "sunlight", "created_at"})
+	require.NoError(t, err)
+
+	var file bytes.Buffer
+	require.NoError(t, service.Export(context.Background(), &file, export))
+	assert.Equal(t,
+		"scientific_name,description,price,shop_id,watering_frequency_max,sunlight,created_at\n"+
+			"Ficus lyrata,\"Fiddle-leaf fig, \"\"easy\"\"\",1290.5,,10,MEDIUM,2024-03-01T09:00:00Z\n",
+		file.String())
+}
+
+// TestPlantExportService_Export_JSON tests that care instructions are nested like in the admin plant
+// requests
+func TestPlantExportService_Export_JSON(t *testing.T) {
+	service := NewPlantExportService(&fakePlantExportRepository{plants: []*models.Plant{exportedPlant(), exportedPlant()}})
+	export, err := NewPlantExport(PlantExportFormatJSON, []string{"pet_friendly", "min_temperature"})
+	require.NoError(t, err)
+
+	var file bytes.Buffer
+	require.NoError(t, service.Export(context.Background(), &file, export))
+	var plants []map[string]interface{}
+	require.NoError(t, json.Unmarshal(file.Bytes(), &plants))
+	require.Len(t, plants, 2)
+	assert.Equal(t, map[string]interface{}{
+		"petFriendly":      false,
+		"careInstructions": map[string]interface{}{"temperature": map[string]interface{}{"min": float64(16)}},
+	}, plants[0])
+}
+
+// TestPlantExportService_Export_Empty tests that an empty catalog is an empty array
+func TestPlantExportService_Export_Empty(t *testing.T) {
+	service := NewPlantExportService(&fakePlantExportRepository{})
+	export, err := NewPlantExport(PlantExportFormatJSON, nil)
+	require.NoError(t, err)
+
+	var file bytes.Buffer
+	require.NoError(t, service.Export(context.Background(), &file, export))
+	var plants []map[string]interface{}
+	require.NoError(t, json.Unmarshal(file.Bytes(), &plants))
+	assert.Empty(t, plants)
+}
+
+// TestPlantExportService_Export_Error tests that an error reading the catalog is returned after the
+// plants read before it were written
+func TestPlantExportService_Export_Error(t *testing.T) {
+	readErr := errors.New("connection reset")
+	service := NewPlantExportService(&fakePlantExportRepository{plants: []*models.Plant{exportedPlant()}, err: readErr})
+	export, err := NewPlantExport(PlantExportFormatCSV, []string{"name"})
+	require.NoError(t, err)
+
+	var file bytes.Buffer
+	assert.ErrorIs(t, service.Export(context.Background(), &file, export), readErr)
+	assert.Equal(t, "name\nFicus\n", file.String())
+}
+
+// TestPlantExportService_Export_ImportRoundTrip tests that a CSV export of the columns imports take
+// is read back by an import
+func TestPlantExportService_Export_ImportRoundTrip(t *testing.T) {
+	exportService := NewPlantExportService(&fakePlantExportRepository{plants: []*models.Plant{exportedPlant()}})
+	export, err := NewPlantExport(PlantExportFormatCSV, []string{
+		"name", "scientific_name", "description", "image_url", "price", "pet_friendly", "species_id",
+		"watering_frequency", "watering_frequency_min", "watering_frequency_max", "sunlight", "humidity",
+		"min_temperature", "max_temperature", "soil_type", "fertilizer_frequency", "additional_notes",
+	})
+	require.NoError(t, err)
+	var file bytes.Buffer
+	require.NoError(t, exportService.Export(context.Background(), &file, export))
+
+	mockPlantRepo := new(MockPlantRepository)
+	mockPlantRepo.On("GetAll", mock.Anything).Return([]*models.Plant{}, nil)
+	result, err := NewPlantService(mockPlantRepo).ImportPlants(context.Background(), &file, PlantImportFormatCSV, true)
+	require.NoError(t, err)
+	assert.Equal(t, []models.PlantImportStatus{models.PlantImportStatusValid}, plantImportStatuses(result))
+}
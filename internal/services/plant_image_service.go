@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/storage"
+	"github.com/google/uuid"
+)
+
+// plantImageDir returns the directory the uploaded images of a catalog plant are stored in
+func plantImageDir(plantID uuid.UUID) string {
+	return "plants/" + plantID.String()
+}
+
+// SetPlantImage stores an uploaded image of a catalog plant and makes it the plant's image. The
+// image is stored under the hash of its content, so its URL can be cached forever: a new image
+// gets a new URL and the previously uploaded one is deleted.
+func (s *PlantService) SetPlantImage(ctx context.Context, plantID uuid.UUID, image []byte) (*models.Plant, error) {
+	if s.objects == nil {
+		return nil, ErrPhotoUploadUnavailable
+	}
+
+	// Check the image format
+	contentType := http.DetectContentType(image)
+	extension, ok := photoExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedPhoto
+	}
+
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant: %w", err)
+	}
+	key := models.AssetKey(storage.ContentKey(plantImageDir(plantID), image, extension))
+	if plant.ImageURL == key {
+		return plant, nil
+	}
+
+	// Store the image, then point the plant at it
+	if err := s.objects.Put(ctx, string(key), image, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store plant image: %w", err)
+	}
+	if err := s.plantRepo.UpdatePlantImage(ctx, plantID, key); err != nil {
+		if err := s.objects.Delete(ctx, string(key)); err != nil {
+			log.Printf("Error deleting stored plant image %s: %v", key, err)
+		}
+		return nil, fmt.Errorf("failed to update plant image: %w", err)
+	}
+
+	// Images set by URL or hosted elsewhere are left alone
+	previous := string(plant.ImageURL)
+	if strings.HasPrefix(previous, plantImageDir(plantID)+"/") && storage.IsContentKey(previous) {
+		if err := s.objects.Delete(ctx, previous); err != nil {
+			log.Printf("Error deleting stored plant image %s: %v", previous, err)
+		}
+	}
+
+	plant.ImageURL = key
+	plant.ImageLicense = nil
+	plant.ImageAttribution = nil
+	return plant, nil
+}
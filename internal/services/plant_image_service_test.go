@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPlantService_SetPlantImage tests that plant images are stored under the hash of their content and
+// that a new image replaces the previously uploaded one
+func TestPlantService_SetPlantImage(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockPlantRepo)
+	ctx := context.Background()
+	plantID := uuid.New()
+
+	// Uploads need an object store
+	_, err := plantService.SetPlantImage(ctx, plantID, pngPhoto)
+	assert.ErrorIs(t, err, ErrPhotoUploadUnavailable)
+
+	objects := memoryObjectStore{"plants/monstera.jpg": pngPhoto}
+	plantService.SetObjectStore(objects)
+	_, err = plantService.SetPlantImage(ctx, plantID, []byte("not an image"))
+	assert.ErrorIs(t, err, ErrUnsupportedPhoto)
+
+	// The first upload replaces an image set by URL, which is kept
+	license := "CC BY-SA 4.0"
+	plant := &models.Plant{ID: plantID, Name: "Monstera", ImageURL: "plants/monstera.jpg", ImageLicense: &license}
+	mockPlantRepo.On("GetByID", ctx, plantID).Return(plant, nil).Once()
+	first := models.AssetKey(storage.ContentKey("plants/"+plantID.String(), pngPhoto, ".png"))
+	mockPlantRepo.On("UpdatePlantImage", ctx, plantID, first).Return(nil).Once()
+
+	updated, err := plantService.SetPlantImage(ctx, plantID, pngPhoto)
+	assert.NoError(t, err)
+	assert.Equal(t, first, updated.ImageURL)
+	assert.Nil(t, updated.ImageLicense)
+	assert.Equal(t, pngPhoto, objects[string(first)])
+	assert.Contains(t, objects, "plants/monstera.jpg")
+
+	// Uploading the same image again changes nothing
+	mockPlantRepo.On("GetByID", ctx, plantID).Return(&models.Plant{ID: plantID, ImageURL: first}, nil).Once()
+	updated, err = plantService.SetPlantImage(ctx, plantID, pngPhoto)
+	assert.NoError(t, err)
+	assert.Equal(t, first, updated.ImageURL)
+
+	// A new image gets a new key and the previous upload is deleted
+	newPhoto := append(append([]byte{}, pngPhoto...), 0x01)
+	second := models.AssetKey(storage.ContentKey("plants/"+plantID.String(), newPhoto, ".png"))
+	mockPlantRepo.On("GetByID", ctx, plantID).Return(&models.Plant{ID: plantID, ImageURL: first}, nil).Once()
+	mockPlantRepo.On("UpdatePlantImage", ctx, plantID, second).Return(nil).Once()
+
+	updated, err = plantService.SetPlantImage(ctx, plantID, newPhoto)
+	assert.NoError(t, err)
+	assert.Equal(t, second, updated.ImageURL)
+	assert.NotEqual(t, first, second)
+	assert.NotContains(t, objects, string(first))
+	assert.Contains(t, objects, string(second))
+	mockPlantRepo.AssertExpectations(t)
+}
@@ -0,0 +1,414 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidPlantImport is returned when a plant import cannot be read as a file of its format
+	ErrInvalidPlantImport = errors.New("invalid plant import")
+
+	// ErrUnsupportedPlantImportFormat is returned for plant imports that are neither CSV nor JSON
+	ErrUnsupportedPlantImportFormat = errors.New("plant import must be CSV or JSON")
+)
+
+// Formats of plant catalog imports
+const (
+	PlantImportFormatCSV  = "csv"
+	PlantImportFormatJSON = "json"
+)
+
+// plantImportColumns are the columns of a CSV plant import; name and scientific_name are required
+var plantImportColumns = map[string]bool{
+	"name":                   true,
+	"scientific_name":        true,
+	"description":            true,
+	"image_url":              true,
+	"price":                  true,
+	"pet_friendly":           true,
+	"species_id":             true,
+	"watering_frequency":     true,
+	"watering_frequency_min": true,
+	"watering_frequency_max": true,
+	"sunlight":               true,
+	"humidity":               true,
+	"min_temperature":        true,
+	"max_temperature":        true,
+	"soil_type":              true,
+	"fertilizer_frequency":   true,
+	"additional_notes":       true,
+}
+
+// plantImportEntry is a row read from a plant import; a row that could not be read as a plant has
+// its problem in row.Error
+type plantImportEntry struct {
+	row              *models.PlantImportRow
+	plant            *models.Plant
+	careInstructions *models.CareInstructions
+}
+
+// plantImportSource reads the rows of a plant import one at a time, so large files are never held in
+// memory; next returns io.EOF after the last row
+type plantImportSource interface {
+	next() (*plantImportEntry, error)
+}
+
+// ImportPlants creates catalog plants from a CSV or JSON file, reading it row by row. Every row is
+// validated like a plant created by an admin; plants whose scientific name is already in the catalog
+// or earlier in the file are reported as duplicates. A dry run checks the rows without creating any
+// plant. Rows that cannot be imported are reported without failing the others; a file that becomes
+// unreadable part way keeps the rows imported before, and the result tells where it stopped.
+func (s *PlantService) ImportPlants(ctx context.Context, file io.Reader, format string, dryRun bool) (*models.PlantImportResult, error) {
+	var source plantImportSource
+	var err error
+	switch format {
+	case PlantImportFormatCSV:
+		source, err = newCSVPlantImportSource(file)
+	case PlantImportFormatJSON:
+		source, err = newJSONPlantImportSource(file)
+	default:
+		return nil, ErrUnsupportedPlantImportFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the catalog by scientific name
+	plants, err := s.plantRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plants: %w", err)
+	}
+	plantIDs := make(map[string]uuid.UUID, len(plants))
+	for _, plant := range plants {
+		plantIDs[normalizePlantName(plant.ScientificName)] = plant.ID
+	}
+
+	result := &models.PlantImportResult{DryRun: dryRun, Rows: []*models.PlantImportRow{}}
+	for {
+		entry, err := source.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if len(result.Rows) == 0 {
+				return nil, err
+			}
+			result.Error = err.Error()
+			break
+		}
+
+		row := entry.row
+		if row.Error == "" {
+			s.importPlant(ctx, entry, plantIDs, dryRun)
+		} else {
+			row.Status = models.PlantImportStatusInvalid
+		}
+		switch row.Status {
+		case models.PlantImportStatusCreated, models.PlantImportStatusValid:
+			result.Created++
+		case models.PlantImportStatusDuplicate:
+			result.Duplicates++
+		case models.PlantImportStatusInvalid:
+			result.Invalid++
+		default:
+			result.Failed++
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if len(result.Rows) == 0 && result.Error == "" {
+		return nil, fmt.Errorf("%w: no plants", ErrInvalidPlantImport)
+	}
+	return result, nil
+}
+
+// importPlant validates and creates the plant of an import row unless its scientific name is already
+// known, recording the outcome on the row
+func (s *PlantService) importPlant(ctx context.Context, entry *plantImportEntry, plantIDs map[string]uuid.UUID, dryRun bool) {
+	row, plant := entry.row, entry.plant
+
+	careInstructions, err := s.resolveCareInstructions(ctx, plant, entry.careInstructions)
+	if err == nil {
+		err = validatePlant(plant, careInstructions)
+	}
+	if err == nil {
+		err = validateCareLevels(careInstructions)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidPlant) {
+			row.Status = models.PlantImportStatusInvalid
+			row.Error = err.Error()
+			return
+		}
+		log.Printf("Error checking imported plant %q: %v", plant.Name, err)
+		row.Status = models.PlantImportStatusFailed
+		row.Error = "failed to check plant"
+		return
+	}
+
+	scientificName := normalizePlantName(plant.ScientificName)
+	if plantID, ok := plantIDs[scientificName]; ok {
+		row.Status = models.PlantImportStatusDuplicate
+		if plantID != uuid.Nil {
+			row.PlantID = &plantID
+		}
+		return
+	}
+	if dryRun {
+		row.Status = models.PlantImportStatusValid
+		plantIDs[scientificName] = uuid.Nil
+		return
+	}
+
+	created, err := s.plantRepo.CreatePlant(ctx, plant, careInstructions)
+	if err != nil {
+		log.Printf("Error creating imported plant %q: %v", plant.Name, err)
+		row.Status = models.PlantImportStatusFailed
+		row.Error = "failed to create plant"
+		return
+	}
+	row.Status = models.PlantImportStatusCreated
+	row.PlantID = &created.ID
+	plantIDs[scientificName] = created.ID
+}
+
+// validateCareLevels checks the sunlight and humidity of care instructions, which admins' forms
+// restrict but files can misspell
+func validateCareLevels(careInstructions *models.CareInstructions) error {
+	if _, ok := levelRank[string(careInstructions.Sunlight)]; !ok {
+		return fmt.Errorf("%w: sunlight must be LOW, MEDIUM or HIGH", ErrInvalidPlant)
+	}
+	if _, ok := levelRank[string(careInstructions.Humidity)]; !ok {
+		return fmt.Errorf("%w: humidity must be LOW, MEDIUM or HIGH", ErrInvalidPlant)
+	}
+	return nil
+}
+
+// csvPlantImportSource reads the plants of a CSV file with a header naming its columns
+type csvPlantImportSource struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+// newCSVPlantImportSource reads the header of a CSV plant import
+func newCSVPlantImportSource(file io.Reader) (*csvPlantImportSource, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: no plants", ErrInvalidPlantImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPlantImport, err)
+	}
+	// Spreadsheets may start the file with a byte order mark
+	columns := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !plantImportColumns[column] {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidPlantImport, column)
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("%w: column %q appears twice", ErrInvalidPlantImport, column)
+		}
+		seen[column] = true
+		columns[i] = column
+	}
+	if !seen["name"] || !seen["scientific_name"] {
+		return nil, fmt.Errorf("%w: the name and scientific_name columns are required", ErrInvalidPlantImport)
+	}
+	return &csvPlantImportSource{reader: reader, columns: columns}, nil
+}
+
+// next reads the plant of the next CSV record
+func (c *csvPlantImportSource) next() (*plantImportEntry, error) {
+	record, err := c.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPlantImport, err)
+	}
+	line, _ := c.reader.FieldPos(0)
+
+	entry := &plantImportEntry{
+		row:              &models.PlantImportRow{Row: line},
+		plant:            &models.Plant{},
+		careInstructions: &models.CareInstructions{},
+	}
+	if len(record) != len(c.columns) {
+		entry.row.Error = fmt.Sprintf("expected %d columns, got %d", len(c.columns), len(record))
+		return entry, nil
+	}
+	for i, value := range record {
+		if err := setPlantImportColumn(entry, c.columns[i], strings.TrimSpace(value)); err != nil {
+			entry.row.Error = fmt.Sprintf("%s: %v", c.columns[i], err)
+			break
+		}
+	}
+	entry.row.Name = entry.plant.Name
+	entry.row.ScientificName = entry.plant.ScientificName
+	return entry, nil
+}
+
+// setPlantImportColumn sets the field of a CSV column on the plant of an entry; empty values leave
+// the field unset
+func setPlantImportColumn(entry *plantImportEntry, column string, value string) error {
+	if value == "" {
+		return nil
+	}
+	plant, care := entry.plant, entry.careInstructions
+
+	var err error
+	switch column {
+	case "name":
+		plant.Name = value
+	case "scientific_name":
+		plant.ScientificName = value
+	case "description":
+		plant.Description = value
+	case "image_url":
+		plant.ImageURL = models.AssetKeyFromURL(value)
+	case "price":
+		var price float64
+		if price, err = strconv.ParseFloat(value, 64); err == nil {
+			plant.Price = &price
+		}
+	case "pet_friendly":
+		var petFriendly bool
+		if petFriendly, err = strconv.ParseBool(value); err == nil {
+			plant.PetFriendly = &petFriendly
+		}
+	case "species_id":
+		var speciesID uuid.UUID
+		if speciesID, err = uuid.Parse(value); err == nil {
+			plant.SpeciesID = &speciesID
+		}
+	case "watering_frequency":
+		care.WateringFrequency, err = strconv.Atoi(value)
+	case "watering_frequency_min":
+		var days int
+		if days, err = strconv.Atoi(value); err == nil {
+			care.WateringFrequencyMin = &days
+		}
+	case "watering_frequency_max":
+		var days int
+		if days, err = strconv.Atoi(value); err == nil {
+			care.WateringFrequencyMax = &days
+		}
+	case "sunlight":
+		care.Sunlight = models.SunlightLevel(strings.ToUpper(value))
+	case "humidity":
+		care.Humidity = models.HumidityLevel(strings.ToUpper(value))
+	case "min_temperature":
+		care.Temperature.Min, err = strconv.Atoi(value)
+	case "max_temperature":
+		care.Temperature.Max, err = strconv.Atoi(value)
+	case "soil_type":
+		care.SoilType = value
+	case "fertilizer_frequency":
+		care.FertilizerFrequency, err = strconv.Atoi(value)
+	case "additional_notes":
+		care.AdditionalNotes = value
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q", value)
+	}
+	return nil
+}
+
+// plantImportRecord is a plant of a JSON import, in the shape of the admin plant requests
+type plantImportRecord struct {
+	Name             string                  `json:"name"`
+	ScientificName   string                  `json:"scientificName"`
+	Description      string                  `json:"description"`
+	ImageURL         string                  `json:"imageUrl"`
+	Price            *float64                `json:"price,omitempty"`
+	ShopID           *string                 `json:"shopId,omitempty"`
+	PetFriendly      *bool                   `json:"petFriendly,omitempty"`
+	CareInstructions models.CareInstructions `json:"careInstructions"`
+	SpeciesID        *uuid.UUID              `json:"speciesId,omitempty"`
+	CareOverrides    *models.CareOverrides   `json:"careOverrides,omitempty"`
+}
+
+// jsonPlantImportSource reads the plants of a JSON array one element at a time
+type jsonPlantImportSource struct {
+	decoder *json.Decoder
+	count   int
+}
+
+// newJSONPlantImportSource reads the opening of the JSON array of a plant import
+func newJSONPlantImportSource(file io.Reader) (*jsonPlantImportSource, error) {
+	decoder := json.NewDecoder(file)
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: no plants", ErrInvalidPlantImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPlantImport, err)
+	}
+	if token != json.Delim('[') {
+		return nil, fmt.Errorf("%w: expected an array of plants", ErrInvalidPlantImport)
+	}
+	return &jsonPlantImportSource{decoder: decoder}, nil
+}
+
+// next reads the plant of the next array element. An element is read whole before it is decoded,
+// so one that does not fit a plant leaves the rest of the array readable.
+func (j *jsonPlantImportSource) next() (*plantImportEntry, error) {
+	if !j.decoder.More() {
+		if _, err := j.decoder.Token(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPlantImport, err)
+		}
+		return nil, io.EOF
+	}
+	j.count++
+
+	var element json.RawMessage
+	if err := j.decoder.Decode(&element); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPlantImport, err)
+	}
+	var record plantImportRecord
+	decoder := json.NewDecoder(bytes.NewReader(element))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&record)
+
+	careInstructions := record.CareInstructions
+	entry := &plantImportEntry{
+		row: &models.PlantImportRow{
+			Row:            j.count,
+			Name:           record.Name,
+			ScientificName: record.ScientificName,
+		},
+		plant: &models.Plant{
+			Name:           record.Name,
+			ScientificName: record.ScientificName,
+			Description:    record.Description,
+			ImageURL:       models.AssetKeyFromURL(record.ImageURL),
+			Price:          record.Price,
+			ShopID:         record.ShopID,
+			PetFriendly:    record.PetFriendly,
+			SpeciesID:      record.SpeciesID,
+			CareOverrides:  record.CareOverrides,
+		},
+		careInstructions: &careInstructions,
+	}
+	if err != nil {
+		entry.row.Error = err.Error()
+	}
+	return entry, nil
+}
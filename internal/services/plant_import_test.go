@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// plantImportStatuses returns the status of each row of a plant import
+func plantImportStatuses(result *models.PlantImportResult) []models.PlantImportStatus {
+	statuses := make([]models.PlantImportStatus, len(result.Rows))
+	for i, row := range result.Rows {
+		statuses[i] = row.Status
+	}
+	return statuses
+}
+
+// TestPlantService_ImportPlants_CSV tests that valid rows are created, known scientific names are
+// duplicates and rows with missing or malformed fields are reported
+func TestPlantService_ImportPlants_CSV(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockPlantRepo)
+
+	existing := &models.Plant{ID: uuid.New(), Name: "Monstera", ScientificName: "Monstera deliciosa"}
+	createdID := uuid.New()
+	mockPlantRepo.On("GetAll", mock.Anything).Return([]*models.Plant{existing}, nil)
+	mockPlantRepo.On("CreatePlant", mock.Anything, mock.MatchedBy(func(plant *models.Plant) bool {
+		return plant.Name == "Ficus" && *plant.PetFriendly == false
+	}), mock.MatchedBy(func(care *models.CareInstructions) bool {
+		return care.WateringFrequency == 7 && care.Sunlight == models.SunlightLevelMedium && care.Temperature.Max == 26
+	})).Return(&models.Plant{ID: createdID}, nil).Once()
+
+	file := "name,scientific_name,description,image_url,pet_friendly,watering_frequency,sunlight,humidity,min_temperature,max_temperature,soil_type,fertilizer_frequency\n" +
+		"Ficus,Ficus lyrata,Fiddle-leaf fig,plants/ficus.jpg,false,7,medium,MEDIUM,16,26,Loam,30\n" +
+		"Monstera,monstera  Deliciosa,Swiss cheese plant,plants/monstera.jpg,false,7,MEDIUM,HIGH,18,27,Peat,30\n" +
+		"Fiddle-leaf fig,Ficus Lyrata,Again,plants/ficus.jpg,false,7,MEDIUM,MEDIUM,16,26,Loam,30\n" +
+		"Cactus,Cactaceae,Spiky,plants/cactus.jpg,false,21,BRIGHT,LOW,10,35,Sand,60\n" +
+		"Pilea,Pilea peperomioides,Money plant,plants/pilea.jpg,sometimes,7,MEDIUM,MEDIUM,15,25,Loam,30\n" +
+		"Fern\n"
+	result, err := plantService.ImportPlants(context.Background(), strings.NewReader(file), PlantImportFormatCSV, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 2, result.Duplicates)
+	assert.Equal(t, 3, result.Invalid)
+	assert.Equal(t, []models.PlantImportStatus{
+		models.PlantImportStatusCreated,
+		models.PlantImportStatusDuplicate,
+		models.PlantImportStatusDuplicate,
+		models.PlantImportStatusInvalid,
+		models.PlantImportStatusInvalid,
+		models.PlantImportStatusInvalid,
+	}, plantImportStatuses(result))
+	assert.Equal(t, createdID, *result.Rows[0].PlantID)
+	assert.Equal(t, existing.ID, *result.Rows[1].PlantID)
+	assert.Equal(t, createdID, *result.Rows[2].PlantID)
+	assert.Equal(t, 5, result.Rows[3].Row)
+	assert.Contains(t, result.Rows[3].Error, "sunlight")
+	assert.Contains(t, result.Rows[4].Error, "pet_friendly")
+	assert.Contains(t, result.Rows[5].Error, "expected 12 columns")
+	mockPlantRepo.AssertExpectations(t)
+}
+
+// TestPlantService_ImportPlants_JSONDryRun tests that a dry run creates nothing, still finds
+// duplicates within the file, and reports elements that do not fit a plant
+func TestPlantService_ImportPlants_JSONDryRun(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockPlantRepo)
+	mockPlantRepo.On("GetAll", mock.Anything).Return([]*models.Plant{}, nil)
+
+	plant := `{"name":"Pilea","scientificName":"Pilea peperomioides","description":"Money plant","imageUrl":"plants/pilea.jpg",
+		"careInstructions":{"wateringFrequency":7,"sunlight":"MEDIUM","humidity":"MEDIUM","temperature":{"min":15,"max":25},"soilType":"Loam","fertilizerFrequency":30}}`
+	file := "[" + plant + "," + plant + `,{"name":"Fern","careInstructions":{"wateringFrequency":"often"}},{"name":"Aloe","color":"green"}]`
+	result, err := plantService.ImportPlants(context.Background(), strings.NewReader(file), PlantImportFormatJSON, true)
+
+	assert.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, []models.PlantImportStatus{
+		models.PlantImportStatusValid,
+		models.PlantImportStatusDuplicate,
+		models.PlantImportStatusInvalid,
+		models.PlantImportStatusInvalid,
+	}, plantImportStatuses(result))
+	assert.Nil(t, result.Rows[1].PlantID)
+	assert.Equal(t, 4, result.Rows[3].Row)
+	assert.Empty(t, result.Error)
+	mockPlantRepo.AssertNotCalled(t, "CreatePlant", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestPlantService_ImportPlants_Invalid tests that unreadable files are refused and that a file
+// breaking off after some rows keeps them and tells where it stopped
+func TestPlantService_ImportPlants_Invalid(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockPlantRepo)
+	mockPlantRepo.On("GetAll", mock.Anything).Return([]*models.Plant{}, nil)
+	ctx := context.Background()
+
+	_, err := plantService.ImportPlants(ctx, strings.NewReader("name,colour\n"), PlantImportFormatCSV, false)
+	assert.ErrorIs(t, err, ErrInvalidPlantImport)
+	_, err = plantService.ImportPlants(ctx, strings.NewReader("name,scientific_name\n"), PlantImportFormatCSV, false)
+	assert.ErrorIs(t, err, ErrInvalidPlantImport)
+	_, err = plantService.ImportPlants(ctx, strings.NewReader(`{"name":"Pilea"}`), PlantImportFormatJSON, false)
+	assert.ErrorIs(t, err, ErrInvalidPlantImport)
+	_, err = plantService.ImportPlants(ctx, strings.NewReader("name"), "xlsx", false)
+	assert.ErrorIs(t, err, ErrUnsupportedPlantImportFormat)
+
+	result, err := plantService.ImportPlants(ctx, strings.NewReader(`[{"name":"Pilea"}, {"name":`), PlantImportFormatJSON, true)
+	assert.NoError(t, err)
+	assert.Len(t, result.Rows, 1)
+	assert.NotEmpty(t, result.Error)
+}
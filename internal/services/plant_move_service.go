@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidPlantMove is returned when the rooms of a move are blank or a room is moved twice
+var ErrInvalidPlantMove = errors.New("invalid plant move")
+
+const (
+	// defaultAcclimationDays is the number of days moved plants get to settle in by default
+	defaultAcclimationDays = 14
+
+	// acclimationSpreadDays is the number of days after acclimation the postponed care tasks are
+	// spread over
+	acclimationSpreadDays = 7
+)
+
+// acclimationPostponedTasks are the care tasks that stress a plant still settling in after a move
+var acclimationPostponedTasks = map[models.CareTaskType]bool{
+	models.CareTaskTypeFertilize: true,
+	models.CareTaskTypeRepot:     true,
+	models.CareTaskTypePrune:     true,
+}
+
+// PlantMoveService moves the plants of a collection to other rooms, e.g. when the user moves house.
+// Plants whose new room lacks the light they need are flagged, and their fertilizing, repotting and
+// pruning wait until they have settled in.
+type PlantMoveService struct {
+	plantRepo repository.PlantRepository
+	taskRepo  repository.UserPlantTaskRepository
+	recorder  PlantEventRecorder
+	now       func() time.Time
+}
+
+// NewPlantMoveService creates a new plant move service
+func NewPlantMoveService(plantRepo repository.PlantRepository, taskRepo repository.UserPlantTaskRepository) *PlantMoveService {
+	return &PlantMoveService{
+		plantRepo: plantRepo,
+		taskRepo:  taskRepo,
+		now:       time.Now,
+	}
+}
+
+// SetPlantEventRecorder sets the recorder the moves of plants are added to
+func (s *PlantMoveService) SetPlantEventRecorder(recorder PlantEventRecorder) {
+	s.recorder = recorder
+}
+
+// postponedTask is a care task of a moved plant due before the plant has settled in
+type postponedTask struct {
+	plant *models.MovedPlant
+	task  *models.UserPlantTask
+}
+
+// MovePlants moves the plants of the user's collection in each room of the request to its new room.
+// Rooms are matched regardless of case and surrounding spaces. Fertilizing, repotting and pruning due
+// before the acclimation period ends are postponed and spread over the week after it, earliest due
+// first, so plants moved together are not all cared for on the same day.
+func (s *PlantMoveService) MovePlants(ctx context.Context, userID uuid.UUID, req *models.MovePlantsRequest) (*models.PlantMove, error) {
+	// Index the rooms by the room moved from
+	rooms := make(map[string]*models.RoomMove, len(req.Rooms))
+	for _, room := range req.Rooms {
+		key := roomKey(room.From)
+		if key == "" || strings.TrimSpace(room.To) == "" {
+			return nil, fmt.Errorf("%w: rooms must not be blank", ErrInvalidPlantMove)
+		}
+		if _, ok := rooms[key]; ok {
+			return nil, fmt.Errorf("%w: room %q is moved twice", ErrInvalidPlantMove, room.From)
+		}
+		rooms[key] = room
+	}
+
+	acclimationDays := defaultAcclimationDays
+	if req.AcclimationDays != nil {
+		acclimationDays = *req.AcclimationDays
+	}
+	acclimationEnds := truncateToDay(s.now()).AddDate(0, 0, acclimationDays)
+
+	plants, err := s.plantRepo.GetUserPlants(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plants: %w", err)
+	}
+
+	// Move the plants of each room; all targets are taken from the rooms before the move, so rooms
+	// can swap
+	move := &models.PlantMove{
+		AcclimationDays: acclimationDays,
+		AcclimationEnds: acclimationEnds.Format(time.DateOnly),
+		Plants:          []*models.MovedPlant{},
+	}
+	matched := make(map[string]bool, len(rooms))
+	var postponed []postponedTask
+	for _, plant := range plants {
+		if plant.Location == nil {
+			continue
+		}
+		room, ok := rooms[roomKey(*plant.Location)]
+		if !ok {
+			continue
+		}
+		matched[roomKey(room.From)] = true
+
+		moved, err := s.movePlant(ctx, userID, plant, room)
+		if err != nil {
+			return nil, err
+		}
+		if moved.LightFit != models.PlantLightFitGood {
+			move.Unsuitable++
+		}
+		move.Plants = append(move.Plants, moved)
+
+		if acclimationDays == 0 {
+			continue
+		}
+		tasks, err := s.taskRepo.GetByUserPlant(ctx, userID, plant.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get care tasks of plant %s: %w", plant.ID, err)
+		}
+		for _, task := range tasks {
+			if acclimationPostponedTasks[task.Type] && task.NextDue.Before(acclimationEnds) {
+				postponed = append(postponed, postponedTask{plant: moved, task: task})
+			}
+		}
+	}
+
+	// Spread the postponed tasks over the week after acclimation
+	sort.SliceStable(postponed, func(i, j int) bool { return postponed[i].task.NextDue.Before(postponed[j].task.NextDue) })
+	for i, p := range postponed {
+		due := acclimationEnds.AddDate(0, 0, i%acclimationSpreadDays)
+		if err := s.taskRepo.SetNextDue(ctx, p.task.ID, due); err != nil {
+			return nil, fmt.Errorf("failed to postpone care task %s: %w", p.task.ID, err)
+		}
+		p.plant.PostponedTasks = append(p.plant.PostponedTasks, &models.PostponedCareTask{Type: p.task.Type, From: p.task.NextDue, To: due})
+	}
+
+	for _, room := range req.Rooms {
+		if !matched[roomKey(room.From)] {
+			move.UnmatchedRooms = append(move.UnmatchedRooms, room.From)
+		}
+	}
+	return move, nil
+}
+
+// movePlant moves a plant to its new room and tells how the light there fits the plant. A room that
+// keeps its name but gets another light leaves the plant where it is.
+func (s *PlantMoveService) movePlant(ctx context.Context, userID uuid.UUID, plant *models.Plant, room *models.RoomMove) (*models.MovedPlant, error) {
+	from, to := *plant.Location, strings.TrimSpace(room.To)
+	if from != to {
+		err := s.plantRepo.UpdateUserPlant(ctx, &models.UserPlant{
+			UserID:       userID,
+			PlantID:      plant.ID,
+			Location:     &to,
+			LastWatered:  plant.LastWatered,
+			NextWatering: plant.NextWatering,
+			Nickname:     plant.Nickname,
+			Notes:        plant.Notes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to move plant %s: %w", plant.ID, err)
+		}
+		recordPlantEvent(ctx, s.recorder, userID, plant.ID, models.PlantEventTypeMoved, models.PlantEventPayload{"from": from, "to": to})
+	}
+
+	return &models.MovedPlant{
+		PlantID:    plant.ID,
+		Name:       plant.Name,
+		Nickname:   plant.Nickname,
+		From:       from,
+		To:         to,
+		Light:      room.Light,
+		NeedsLight: plant.CareInstructions.Sunlight,
+		LightFit:   plantLightFit(room.Light, plant.CareInstructions.Sunlight),
+	}, nil
+}
+
+// plantLightFit tells how the light of a room fits the light a plant needs; plants whose need is
+// unknown fit any room
+func plantLightFit(light models.SunlightLevel, needs models.SunlightLevel) models.PlantLightFit {
+	if needs == "" {
+		return models.PlantLightFitGood
+	}
+	switch have, want := levelRank[string(light)], levelRank[string(needs)]; {
+	case have < want:
+		return models.PlantLightFitTooDark
+	case have > want:
+		return models.PlantLightFitTooBright
+	default:
+		return models.PlantLightFitGood
+	}
+}
+
+// roomKey returns the key rooms are matched by, regardless of case and surrounding spaces
+func roomKey(room string) string {
+	return strings.ToLower(strings.TrimSpace(room))
+}
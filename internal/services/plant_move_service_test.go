@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPlantMoveService_MovePlants tests that rooms can swap, that light mismatches are flagged and
+// that demanding care due while plants settle in is spread over the days after acclimation
+func TestPlantMoveService_MovePlants(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+	service := NewPlantMoveService(mockPlantRepo, mockUserPlantTaskRepo)
+	today := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return today.Add(15 * time.Hour) }
+
+	userID := uuid.New()
+	kitchen, bedroom := "kitchen ", "Bedroom"
+	ficus := &models.Plant{ID: uuid.New(), Name: "Ficus", Location: &kitchen, CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelHigh}}
+	fern := &models.Plant{ID: uuid.New(), Name: "Fern", Location: &bedroom, CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelMedium}}
+	cactus := &models.Plant{ID: uuid.New(), Name: "Cactus"}
+	mockPlantRepo.On("GetUserPlants", mock.Anything, userID).Return([]*models.Plant{ficus, fern, cactus}, nil)
+	mockPlantRepo.On("UpdateUserPlant", mock.Anything, mock.MatchedBy(func(up *models.UserPlant) bool {
+		return up.PlantID == ficus.ID && *up.Location == "Bedroom"
+	})).Return(nil).Once()
+	mockPlantRepo.On("UpdateUserPlant", mock.Anything, mock.MatchedBy(func(up *models.UserPlant) bool {
+		return up.PlantID == fern.ID && *up.Location == "Kitchen"
+	})).Return(nil).Once()
+
+	fertilize := &models.UserPlantTask{ID: uuid.New(), Type: models.CareTaskTypeFertilize, NextDue: today.AddDate(0, 0, 2)}
+	water := &models.UserPlantTask{ID: uuid.New(), Type: models.CareTaskTypeWater, NextDue: today.AddDate(0, 0, 1)}
+	prune := &models.UserPlantTask{ID: uuid.New(), Type: models.CareTaskTypePrune, NextDue: today.AddDate(0, 0, 1)}
+	repot := &models.UserPlantTask{ID: uuid.New(), Type: models.CareTaskTypeRepot, NextDue: today.AddDate(0, 0, 30)}
+	mockUserPlantTaskRepo.On("GetByUserPlant", mock.Anything, userID, ficus.ID).Return([]*models.UserPlantTask{fertilize, water}, nil)
+	mockUserPlantTaskRepo.On("GetByUserPlant", mock.Anything, userID, fern.ID).Return([]*models.UserPlantTask{prune, repot}, nil)
+	acclimationEnds := today.AddDate(0, 0, defaultAcclimationDays)
+	mockUserPlantTaskRepo.On("SetNextDue", mock.Anything, prune.ID, acclimationEnds).Return(nil).Once()
+	mockUserPlantTaskRepo.On("SetNextDue", mock.Anything, fertilize.ID, acclimationEnds.AddDate(0, 0, 1)).Return(nil).Once()
+
+	move, err := service.MovePlants(context.Background(), userID, &models.MovePlantsRequest{Rooms: []*models.RoomMove{
+		{From: "Kitchen", To: "Bedroom", Light: models.SunlightLevelLow},
+		{From: "bedroom", To: "Kitchen", Light: models.SunlightLevelHigh},
+		{From: "Attic", To: "Balcony", Light: models.SunlightLevelHigh},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-27", move.AcclimationEnds)
+	assert.Equal(t, 2, move.Unsuitable)
+	assert.Equal(t, []string{"Attic"}, move.UnmatchedRooms)
+	require.Len(t, move.Plants, 2)
+	assert.Equal(t, models.PlantLightFitTooDark, move.Plants[0].LightFit)
+	assert.Equal(t, models.PlantLightFitTooBright, move.Plants[1].LightFit)
+	require.Len(t, move.Plants[0].PostponedTasks, 1)
+	assert.Equal(t, models.CareTaskTypeFertilize, move.Plants[0].PostponedTasks[0].Type)
+	require.Len(t, move.Plants[1].PostponedTasks, 1)
+	assert.Equal(t, acclimationEnds, move.Plants[1].PostponedTasks[0].To)
+	mockPlantRepo.AssertExpectations(t)
+	mockUserPlantTaskRepo.AssertExpectations(t)
+}
+
+// TestPlantMoveService_MovePlants_Invalid tests that a room cannot be moved twice
+func TestPlantMoveService_MovePlants_Invalid(t *testing.T) {
+	service := NewPlantMoveService(new(MockPlantRepository), new(MockUserPlantTaskRepository))
+
+	_, err := service.MovePlants(context.Background(), uuid.New(), &models.MovePlantsRequest{Rooms: []*models.RoomMove{
+		{From: "Kitchen", To: "Bedroom", Light: models.SunlightLevelLow},
+		{From: " KITCHEN", To: "Hall", Light: models.SunlightLevelLow},
+	}})
+	assert.ErrorIs(t, err, ErrInvalidPlantMove)
+}
+
+// TestPlantLightFit tests how room light fits plant needs
+func TestPlantLightFit(t *testing.T) {
+	assert.Equal(t, models.PlantLightFitGood, plantLightFit(models.SunlightLevelMedium, models.SunlightLevelMedium))
+	assert.Equal(t, models.PlantLightFitTooDark, plantLightFit(models.SunlightLevelLow, models.SunlightLevelHigh))
+	assert.Equal(t, models.PlantLightFitTooBright, plantLightFit(models.SunlightLevelHigh, models.SunlightLevelLow))
+	assert.Equal(t, models.PlantLightFitGood, plantLightFit(models.SunlightLevelLow, ""))
+}
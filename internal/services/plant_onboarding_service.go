@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPlantIdentificationUnavailable is returned when no identification provider is configured
+	ErrPlantIdentificationUnavailable = errors.New("plant identification is not available")
+
+	// ErrPlantNotIdentified is returned when the photo matches no catalog plant with enough confidence
+	ErrPlantNotIdentified = errors.New("the plant on the photo could not be identified")
+
+	// ErrPlantAlreadyInCollection is returned when onboarding a plant the user already has
+	ErrPlantAlreadyInCollection = errors.New("the plant is already in the collection")
+)
+
+const (
+	// maxPlantIdentificationCandidates is the number of most likely species kept per identification
+	maxPlantIdentificationCandidates = 5
+
+	// minPlantIdentificationScore is the score a candidate needs for its plant to be added without asking
+	minPlantIdentificationScore = 0.2
+
+	// onboardingScheduleDays is the number of days of care returned with an onboarded plant
+	onboardingScheduleDays = 14
+)
+
+// PlantIdentificationProvider identifies plant species on photos
+type PlantIdentificationProvider interface {
+	// Name returns the provider name
+	Name() string
+
+	// Identify returns the species a photo shows with their scores
+	Identify(ctx context.Context, image []byte, contentType string) ([]*models.PlantIdentificationCandidate, error)
+}
+
+// OnboardingScheduler schedules the care of onboarded plants and reports their schedule
+type OnboardingScheduler interface {
+	CareScheduler
+	GetSchedule(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, days int) (*models.CareSchedule, error)
+}
+
+// PlantOnboardingService adds a new plant to a collection from a photo in one call: it identifies
+// the plant, proposes a room, adds the plant, schedules its care and keeps the photo. A failing step
+// undoes the ones before it, so the plant is either fully set up or not added at all.
+type PlantOnboardingService struct {
+	plantRepo    repository.PlantRepository
+	plantService *PlantService
+	scheduler    OnboardingScheduler
+	identifier   PlantIdentificationProvider
+}
+
+// NewPlantOnboardingService creates a new plant onboarding service. Plants can be onboarded from a
+// photo only when identifier is set; otherwise the plant has to be given.
+func NewPlantOnboardingService(
+	plantRepo repository.PlantRepository,
+	plantService *PlantService,
+	scheduler OnboardingScheduler,
+	identifier PlantIdentificationProvider,
+) *PlantOnboardingService {
+	return &PlantOnboardingService{
+		plantRepo:    plantRepo,
+		plantService: plantService,
+		scheduler:    scheduler,
+		identifier:   identifier,
+	}
+}
+
+// Onboard identifies the plant on a photo and sets it up in the user's collection. When the photo
+// matches no catalog plant, ErrPlantNotIdentified is returned with a result listing the candidates,
+// so the user can pick one and onboard it again with its plant ID.
+func (s *PlantOnboardingService) Onboard(ctx context.Context, userID uuid.UUID, req *models.PlantOnboardingRequest) (*models.PlantOnboardingResult, []models.Warning, error) {
+	// Check the photo format
+	if _, ok := photoExtensions[http.DetectContentType(req.Photo)]; !ok {
+		return nil, nil, ErrUnsupportedPhoto
+	}
+
+	// Identify the plant unless the user picked it
+	result := &models.PlantOnboardingResult{Candidates: []*models.PlantIdentificationCandidate{}}
+	var plant *models.Plant
+	if req.PlantID != nil {
+		var err error
+		plant, err = s.plantRepo.GetByID(ctx, *req.PlantID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("plant not found: %w", err)
+		}
+		if !plant.Published() {
+			return nil, nil, errPlantNotPublished
+		}
+		if plant.DeletedAt != nil {
+			return nil, nil, ErrPlantDeleted
+		}
+	} else {
+		candidates, err := s.identify(ctx, req.Photo)
+		if err != nil {
+			return nil, nil, err
+		}
+		result.Candidates = candidates
+		for _, candidate := range candidates {
+			if candidate.Plant != nil && candidate.Score >= minPlantIdentificationScore {
+				plant = candidate.Plant
+				break
+			}
+		}
+		if plant == nil {
+			return result, nil, ErrPlantNotIdentified
+		}
+	}
+
+	// Onboarding a plant the user already has would roll back onto their existing one
+	if _, err := s.plantRepo.GetUserPlant(ctx, userID, plant.ID); err == nil {
+		return nil, nil, ErrPlantAlreadyInCollection
+	}
+
+	// Pick the room
+	collection, err := s.plantRepo.GetUserPlants(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user plants: %w", err)
+	}
+	roomLights := collectionRoomLights(collection)
+	var warnings []models.Warning
+	if req.Location != nil {
+		result.Location = strings.TrimSpace(*req.Location)
+		result.LocationSource = models.PlantOnboardingLocationRequested
+		if light, ok := roomLights[result.Location]; ok && light != plant.CareInstructions.Sunlight {
+			warnings = append(warnings, models.Warning{
+				Code: models.WarningCodeLightMismatch,
+				Message: fmt.Sprintf("%s needs %s light but the plants in %s need %s light",
+					plant.Name, plant.CareInstructions.Sunlight, result.Location, light),
+			})
+		}
+	} else {
+		result.Location, result.LocationSource = proposeOnboardingRoom(collection, roomLights, plant.CareInstructions.Sunlight)
+	}
+
+	// Add the plant to the collection
+	userPlant := &models.UserPlant{
+		UserID:   userID,
+		PlantID:  plant.ID,
+		Location: &result.Location,
+		Nickname: normalizeUserPlantDetail(req.Nickname),
+	}
+	if err := s.plantRepo.AddUserPlant(ctx, userPlant); err != nil {
+		return nil, nil, fmt.Errorf("failed to add user plant: %w", err)
+	}
+
+	// Schedule its care and keep the photo, removing the plant again when either fails
+	if err := s.scheduler.ScheduleDefaultTasks(ctx, userPlant, plant); err != nil {
+		return nil, nil, s.rollback(ctx, userID, plant.ID, fmt.Errorf("failed to schedule care: %w", err))
+	}
+	photo, err := s.plantService.AddUserPlantPhoto(ctx, userID, plant.ID, req.Photo)
+	switch {
+	case errors.Is(err, ErrPhotoUploadUnavailable):
+		// The plant is set up without its photo
+	case err != nil:
+		return nil, nil, s.rollback(ctx, userID, plant.ID, fmt.Errorf("failed to save photo: %w", err))
+	default:
+		result.Photo = photo
+	}
+
+	// The plant is set up, so a failure to read back its schedule is only logged
+	result.Schedule, err = s.scheduler.GetSchedule(ctx, userID, plant.ID, onboardingScheduleDays)
+	if err != nil {
+		log.Printf("Error getting care schedule of onboarded plant %s for user %s: %v", plant.ID, userID, err)
+	}
+
+	plant.Location = &result.Location
+	result.Plant = plant
+	return result, warnings, nil
+}
+
+// identify returns the most likely species on a photo with their catalog plants
+func (s *PlantOnboardingService) identify(ctx context.Context, photo []byte) ([]*models.PlantIdentificationCandidate, error) {
+	if s.identifier == nil {
+		return nil, ErrPlantIdentificationUnavailable
+	}
+
+	candidates, err := s.identifier.Identify(ctx, photo, http.DetectContentType(photo))
+	if err != nil {
+		return nil, fmt.Errorf("failed to identify plant: %w", err)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > maxPlantIdentificationCandidates {
+		candidates = candidates[:maxPlantIdentificationCandidates]
+	}
+
+	for _, candidate := range candidates {
+		candidate.Score = math.Min(math.Max(candidate.Score, 0), 1)
+		plant, err := s.findCatalogPlant(ctx, candidate.ScientificName)
+		if err != nil {
+			return nil, err
+		}
+		candidate.Plant = plant
+	}
+	return candidates, nil
+}
+
+// findCatalogPlant returns the catalog plant of a species, or nil when the catalog does not have it.
+// Cultivars, whose scientific name extends the species name, match when the species itself is missing.
+func (s *PlantOnboardingService) findCatalogPlant(ctx context.Context, scientificName string) (*models.Plant, error) {
+	normalize := func(name string) string {
+		return strings.ToLower(strings.Join(strings.Fields(name), " "))
+	}
+	name := normalize(scientificName)
+	if name == "" {
+		return nil, nil
+	}
+
+	plants, err := s.plantRepo.Search(ctx, &models.PlantSearchQuery{Text: scientificName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search catalog: %w", err)
+	}
+	var cultivar *models.Plant
+	for _, plant := range plants {
+		if plant.DeletedAt != nil {
+			continue
+		}
+		plantName := normalize(plant.ScientificName)
+		if plantName == name {
+			return plant, nil
+		}
+		if cultivar == nil && strings.HasPrefix(plantName, name+" ") {
+			cultivar = plant
+		}
+	}
+	return cultivar, nil
+}
+
+// rollback removes a plant added during onboarding with its tasks and photos, then returns cause.
+// The removal runs even when the request was cancelled, so nothing half set up is left behind.
+func (s *PlantOnboardingService) rollback(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, cause error) error {
+	if err := s.plantService.RemoveUserPlant(context.WithoutCancel(ctx), userID, plantID); err != nil {
+		log.Printf("Error rolling back onboarding of plant %s for user %s: %v", plantID, userID, err)
+	}
+	return cause
+}
+
+// collectionRoomLights returns the light most plants in each room of a collection need. Rooms whose
+// plants are split evenly get the lowest of the tied levels.
+func collectionRoomLights(collection []*models.Plant) map[string]models.SunlightLevel {
+	counts := make(map[string]map[models.SunlightLevel]int)
+	for _, plant := range collection {
+		if plant.Location == nil || strings.TrimSpace(*plant.Location) == "" || plant.CareInstructions.Sunlight == "" {
+			continue
+		}
+		room := strings.TrimSpace(*plant.Location)
+		if counts[room] == nil {
+			counts[room] = make(map[models.SunlightLevel]int)
+		}
+		counts[room][plant.CareInstructions.Sunlight]++
+	}
+
+	lights := make(map[string]models.SunlightLevel, len(counts))
+	for room, byLight := range counts {
+		best := 0
+		for _, light := range []models.SunlightLevel{models.SunlightLevelLow, models.SunlightLevelMedium, models.SunlightLevelHigh} {
+			if byLight[light] > best {
+				best = byLight[light]
+				lights[room] = light
+			}
+		}
+	}
+	return lights
+}
+
+// proposeOnboardingRoom picks the room whose plants need the light a new plant needs, preferring the
+// room with the most such plants and then the first by name
+func proposeOnboardingRoom(
+	collection []*models.Plant,
+	roomLights map[string]models.SunlightLevel,
+	light models.SunlightLevel,
+) (string, models.PlantOnboardingLocationSource) {
+	matching := make(map[string]int)
+	for _, plant := range collection {
+		if plant.Location == nil {
+			continue
+		}
+		room := strings.TrimSpace(*plant.Location)
+		if roomLight, ok := roomLights[room]; ok && roomLight == light && plant.CareInstructions.Sunlight == light {
+			matching[room]++
+		}
+	}
+
+	best := ""
+	for room, count := range matching {
+		if best == "" || count > matching[best] || (count == matching[best] && room < best) {
+			best = room
+		}
+	}
+	if best == "" {
+		return "", models.PlantOnboardingLocationNone
+	}
+	return best, models.PlantOnboardingLocationLightFit
+}
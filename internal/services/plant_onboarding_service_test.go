@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubIdentificationProvider identifies every photo as the same candidates
+type stubIdentificationProvider struct {
+	candidates []*models.PlantIdentificationCandidate
+}
+
+func (p *stubIdentificationProvider) Name() string {
+	return "stub"
+}
+
+func (p *stubIdentificationProvider) Identify(ctx context.Context, image []byte, contentType string) ([]*models.PlantIdentificationCandidate, error) {
+	return p.candidates, nil
+}
+
+// stubOnboardingScheduler records the plants it schedules and fails when err is set
+type stubOnboardingScheduler struct {
+	scheduled []uuid.UUID
+	err       error
+}
+
+func (s *stubOnboardingScheduler) ScheduleDefaultTasks(ctx context.Context, userPlant *models.UserPlant, plant *models.Plant) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.scheduled = append(s.scheduled, plant.ID)
+	return nil
+}
+
+func (s *stubOnboardingScheduler) GetSchedule(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, days int) (*models.CareSchedule, error) {
+	return &models.CareSchedule{PlantID: plantID, Tasks: []*models.CareTask{}}, nil
+}
+
+// TestPlantOnboardingService_Onboard tests that an identified plant is added to the room that fits
+// its light with its care scheduled and the photo kept
+func TestPlantOnboardingService_Onboard(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockPhotoRepo := new(MockUserPlantPhotoRepository)
+	plantService := NewPlantService(mockPlantRepo)
+	plantService.SetPhotoRepository(mockPhotoRepo)
+	plantService.SetObjectStore(memoryObjectStore{})
+	scheduler := &stubOnboardingScheduler{}
+	identifier := &stubIdentificationProvider{candidates: []*models.PlantIdentificationCandidate{
+		{ScientificName: "Ficus elastica", Score: 0.1},
+		{ScientificName: "Monstera deliciosa", Score: 0.8},
+	}}
+	service := NewPlantOnboardingService(mockPlantRepo, plantService, scheduler, identifier)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	monstera := &models.Plant{ID: uuid.New(), Name: "Монстера", ScientificName: "Monstera deliciosa"}
+	monstera.CareInstructions.Sunlight = models.SunlightLevelMedium
+	room := func(name string, light models.SunlightLevel) *models.Plant {
+		plant := &models.Plant{ID: uuid.New(), Location: &name}
+		plant.CareInstructions.Sunlight = light
+		return plant
+	}
+
+	mockPlantRepo.On("Search", ctx, &models.PlantSearchQuery{Text: "Monstera deliciosa"}).Return([]*models.Plant{monstera}, nil)
+	mockPlantRepo.On("Search", ctx, &models.PlantSearchQuery{Text: "Ficus elastica"}).Return([]*models.Plant{}, nil)
+	mockPlantRepo.On("GetUserPlant", ctx, userID, monstera.ID).Return(nil, sql.ErrNoRows).Once()
+	mockPlantRepo.On("GetUserPlants", ctx, userID).Return([]*models.Plant{
+		room("Кухня", models.SunlightLevelHigh),
+		room("Гостиная", models.SunlightLevelMedium),
+		room("Гостиная", models.SunlightLevelMedium),
+		room("Спальня", models.SunlightLevelMedium),
+	}, nil)
+	mockPlantRepo.On("AddUserPlant", ctx, mock.MatchedBy(func(up *models.UserPlant) bool {
+		return up.PlantID == monstera.ID && *up.Location == "Гостиная"
+	})).Return(nil)
+	mockPlantRepo.On("GetUserPlant", ctx, userID, monstera.ID).Return(&models.UserPlant{UserID: userID, PlantID: monstera.ID}, nil)
+	mockPhotoRepo.On("ListByUserPlant", ctx, userID, monstera.ID).Return([]*models.UserPlantPhoto{}, nil)
+	mockPhotoRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	result, warnings, err := service.Onboard(ctx, userID, &models.PlantOnboardingRequest{Photo: pngPhoto})
+
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, monstera.ID, result.Plant.ID)
+	assert.Equal(t, "Гостиная", result.Location)
+	assert.Equal(t, models.PlantOnboardingLocationLightFit, result.LocationSource)
+	if assert.Len(t, result.Candidates, 2) {
+		assert.Equal(t, "Monstera deliciosa", result.Candidates[0].ScientificName)
+		assert.Nil(t, result.Candidates[1].Plant)
+	}
+	assert.NotNil(t, result.Photo)
+	assert.NotNil(t, result.Schedule)
+	assert.Equal(t, []uuid.UUID{monstera.ID}, scheduler.scheduled)
+	mockPlantRepo.AssertExpectations(t)
+}
+
+// TestPlantOnboardingService_Onboard_NotIdentified tests that unlikely candidates are returned for the
+// user to pick from instead of adding a plant
+func TestPlantOnboardingService_Onboard_NotIdentified(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	identifier := &stubIdentificationProvider{candidates: []*models.PlantIdentificationCandidate{
+		{ScientificName: "Monstera deliciosa", Score: 0.05},
+	}}
+	service := NewPlantOnboardingService(mockPlantRepo, NewPlantService(mockPlantRepo), &stubOnboardingScheduler{}, identifier)
+
+	ctx := context.Background()
+	monstera := &models.Plant{ID: uuid.New(), ScientificName: "Monstera deliciosa 'Thai Constellation'"}
+	mockPlantRepo.On("Search", ctx, mock.Anything).Return([]*models.Plant{monstera}, nil)
+
+	result, _, err := service.Onboard(ctx, uuid.New(), &models.PlantOnboardingRequest{Photo: pngPhoto})
+
+	assert.ErrorIs(t, err, ErrPlantNotIdentified)
+	assert.Nil(t, result.Plant)
+	if assert.Len(t, result.Candidates, 1) {
+		// The cultivar stands in for the species the catalog does not have
+		assert.Equal(t, monstera, result.Candidates[0].Plant)
+	}
+	mockPlantRepo.AssertNotCalled(t, "AddUserPlant", mock.Anything, mock.Anything)
+
+	// Without a provider only a given plant can be onboarded
+	service = NewPlantOnboardingService(mockPlantRepo, NewPlantService(mockPlantRepo), &stubOnboardingScheduler{}, nil)
+	_, _, err = service.Onboard(ctx, uuid.New(), &models.PlantOnboardingRequest{Photo: pngPhoto})
+	assert.ErrorIs(t, err, ErrPlantIdentificationUnavailable)
+}
+
+// TestPlantOnboardingService_Onboard_Rollback tests that a plant whose care cannot be scheduled is
+// removed from the collection again, and that a plant already there is left alone
+func TestPlantOnboardingService_Onboard_Rollback(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	scheduler := &stubOnboardingScheduler{err: errors.New("database is down")}
+	service := NewPlantOnboardingService(mockPlantRepo, NewPlantService(mockPlantRepo), scheduler, nil)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	plant := &models.Plant{ID: uuid.New(), Name: "Фикус"}
+	plant.CareInstructions.Sunlight = models.SunlightLevelHigh
+	kitchen := "Кухня"
+	shaded := &models.Plant{ID: uuid.New(), Location: &kitchen}
+	shaded.CareInstructions.Sunlight = models.SunlightLevelLow
+
+	mockPlantRepo.On("GetByID", ctx, plant.ID).Return(plant, nil)
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plant.ID).Return(nil, sql.ErrNoRows).Once()
+	mockPlantRepo.On("GetUserPlants", ctx, userID).Return([]*models.Plant{shaded}, nil)
+	mockPlantRepo.On("AddUserPlant", ctx, mock.Anything).Return(nil)
+	mockPlantRepo.On("RemoveUserPlant", mock.Anything, userID, plant.ID).Return(nil)
+
+	_, _, err := service.Onboard(ctx, userID, &models.PlantOnboardingRequest{Photo: pngPhoto, PlantID: &plant.ID, Location: &kitchen})
+
+	assert.Error(t, err)
+	mockPlantRepo.AssertCalled(t, "RemoveUserPlant", mock.Anything, userID, plant.ID)
+
+	// A plant already in the collection is not onboarded twice
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plant.ID).Return(&models.UserPlant{UserID: userID, PlantID: plant.ID}, nil)
+	_, _, err = service.Onboard(ctx, userID, &models.PlantOnboardingRequest{Photo: pngPhoto, PlantID: &plant.ID})
+	assert.ErrorIs(t, err, ErrPlantAlreadyInCollection)
+	mockPlantRepo.AssertNumberOfCalls(t, "AddUserPlant", 1)
+}
+
+// TestCollectionRoomLights tests the light of a room is the one most of its plants need
+func TestCollectionRoomLights(t *testing.T) {
+	plant := func(room string, light models.SunlightLevel) *models.Plant {
+		p := &models.Plant{Location: &room}
+		p.CareInstructions.Sunlight = light
+		return p
+	}
+
+	lights := collectionRoomLights([]*models.Plant{
+		plant("Кухня", models.SunlightLevelHigh),
+		plant("Кухня", models.SunlightLevelHigh),
+		plant("Кухня", models.SunlightLevelLow),
+		plant("Спальня", models.SunlightLevelHigh),
+		plant("Спальня", models.SunlightLevelMedium),
+		plant(" ", models.SunlightLevelLow),
+	})
+
+	assert.Equal(t, map[string]models.SunlightLevel{
+		"Кухня":   models.SunlightLevelHigh,
+		"Спальня": models.SunlightLevelMedium,
+	}, lights)
+}
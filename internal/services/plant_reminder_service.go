@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidPlantReminder is returned for reminders without a title
+	ErrInvalidPlantReminder = errors.New("invalid plant reminder")
+
+	// ErrTooManyPlantReminders is returned when a plant in a collection already has the most reminders allowed
+	ErrTooManyPlantReminders = errors.New("the plant already has the most reminders allowed")
+)
+
+// maxPlantReminders is the number of reminders a plant in a collection can have
+const maxPlantReminders = 20
+
+// PlantReminderService manages the recurring reminders users define for the plants in their
+// collections; the care notifications check sends them when they are due
+type PlantReminderService struct {
+	reminderRepo repository.PlantReminderRepository
+	plantRepo    repository.PlantRepository
+	now          func() time.Time
+}
+
+// NewPlantReminderService creates a new plant reminder service
+func NewPlantReminderService(reminderRepo repository.PlantReminderRepository, plantRepo repository.PlantRepository) *PlantReminderService {
+	return &PlantReminderService{
+		reminderRepo: reminderRepo,
+		plantRepo:    plantRepo,
+		now:          time.Now,
+	}
+}
+
+// GetReminders gets the reminders of a plant in the user's collection, the next due first
+func (s *PlantReminderService) GetReminders(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.PlantReminder, error) {
+	if _, err := s.plantRepo.GetUserPlant(ctx, userID, plantID); err != nil {
+		return nil, fmt.Errorf("plant not in user's collection: %w", err)
+	}
+	return s.reminderRepo.ListByUserPlant(ctx, userID, plantID)
+}
+
+// CreateReminder adds a reminder to a plant in the user's collection. Without a due date it is first
+// due a full interval from today.
+func (s *PlantReminderService) CreateReminder(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, req *models.PlantReminderRequest) (*models.PlantReminder, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidPlantReminder)
+	}
+	reminders, err := s.GetReminders(ctx, userID, plantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(reminders) >= maxPlantReminders {
+		return nil, ErrTooManyPlantReminders
+	}
+
+	reminder := &models.PlantReminder{
+		UserID:        userID,
+		PlantID:       plantID,
+		Title:         title,
+		FrequencyDays: req.FrequencyDays,
+		NextDue:       truncateToDay(s.now()).AddDate(0, 0, req.FrequencyDays),
+	}
+	if req.NextDue != nil {
+		reminder.NextDue = truncateToDay(*req.NextDue)
+	}
+	if err := s.reminderRepo.Create(ctx, reminder); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+// UpdateReminder changes the title and frequency of a reminder of a plant in the user's collection.
+// Without a due date it keeps its current one. Reminders of other plants or users are reported as
+// not found.
+func (s *PlantReminderService) UpdateReminder(
+	ctx context.Context,
+	userID uuid.UUID,
+	plantID uuid.UUID,
+	reminderID uuid.UUID,
+	req *models.PlantReminderRequest,
+) (*models.PlantReminder, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidPlantReminder)
+	}
+	reminder, err := s.getReminder(ctx, userID, plantID, reminderID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminder.Title = title
+	reminder.FrequencyDays = req.FrequencyDays
+	if req.NextDue != nil {
+		reminder.NextDue = truncateToDay(*req.NextDue)
+	}
+	if err := s.reminderRepo.Update(ctx, reminder); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+// DeleteReminder removes a reminder of a plant in the user's collection
+func (s *PlantReminderService) DeleteReminder(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, reminderID uuid.UUID) error {
+	if _, err := s.getReminder(ctx, userID, plantID, reminderID); err != nil {
+		return err
+	}
+	return s.reminderRepo.Delete(ctx, reminderID)
+}
+
+// getReminder gets a reminder of a plant in the user's collection
+func (s *PlantReminderService) getReminder(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, reminderID uuid.UUID) (*models.PlantReminder, error) {
+	reminder, err := s.reminderRepo.GetByID(ctx, reminderID)
+	if err != nil {
+		return nil, err
+	}
+	if reminder.UserID != userID || reminder.PlantID != plantID {
+		return nil, fmt.Errorf("plant reminder not found: %w", sql.ErrNoRows)
+	}
+	return reminder, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPlantReminderRepository is a mock implementation of the PlantReminderRepository interface
+type MockPlantReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlantReminderRepository) ListByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.PlantReminder, error) {
+	args := m.Called(ctx, userID, plantID)
+	return args.Get(0).([]*models.PlantReminder), args.Error(1)
+}
+
+func (m *MockPlantReminderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PlantReminder, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PlantReminder), args.Error(1)
+}
+
+func (m *MockPlantReminderRepository) Create(ctx context.Context, reminder *models.PlantReminder) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
+
+func (m *MockPlantReminderRepository) Update(ctx context.Context, reminder *models.PlantReminder) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
+
+func (m *MockPlantReminderRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPlantReminderRepository) GetDue(ctx context.Context, until time.Time) ([]*models.PlantReminder, error) {
+	args := m.Called(ctx, until)
+	return args.Get(0).([]*models.PlantReminder), args.Error(1)
+}
+
+func (m *MockPlantReminderRepository) SetNextDue(ctx context.Context, id uuid.UUID, nextDue time.Time) error {
+	args := m.Called(ctx, id, nextDue)
+	return args.Error(0)
+}
+
+// TestPlantReminderService_CreateReminder tests that new reminders are first due a full interval from
+// today unless a due date is given, and that a plant's reminders are limited
+func TestPlantReminderService_CreateReminder(t *testing.T) {
+	mockReminderRepo := new(MockPlantReminderRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewPlantReminderService(mockReminderRepo, mockPlantRepo)
+	now := time.Date(2024, time.May, 10, 18, 30, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+	userID, plantID := uuid.New(), uuid.New()
+
+	_, err := service.CreateReminder(ctx, userID, plantID, &models.PlantReminderRequest{Title: "  ", FrequencyDays: 7})
+	assert.ErrorIs(t, err, ErrInvalidPlantReminder)
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID}, nil)
+	mockReminderRepo.On("ListByUserPlant", ctx, userID, plantID).Return([]*models.PlantReminder{}, nil).Twice()
+	mockReminderRepo.On("Create", ctx, mock.AnythingOfType("*models.PlantReminder")).Return(nil).Twice()
+
+	reminder, err := service.CreateReminder(ctx, userID, plantID, &models.PlantReminderRequest{Title: " Clean the leaves ", FrequencyDays: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, "Clean the leaves", reminder.Title)
+	assert.Equal(t, time.Date(2024, time.May, 17, 0, 0, 0, 0, time.UTC), reminder.NextDue)
+
+	nextDue := time.Date(2024, time.May, 12, 9, 0, 0, 0, time.UTC)
+	reminder, err = service.CreateReminder(ctx, userID, plantID, &models.PlantReminderRequest{Title: "Rotate the pot", FrequencyDays: 14, NextDue: &nextDue})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.May, 12, 0, 0, 0, 0, time.UTC), reminder.NextDue)
+
+	full := make([]*models.PlantReminder, maxPlantReminders)
+	mockReminderRepo.On("ListByUserPlant", ctx, userID, plantID).Return(full, nil).Once()
+	_, err = service.CreateReminder(ctx, userID, plantID, &models.PlantReminderRequest{Title: "Check for pests", FrequencyDays: 7})
+	assert.ErrorIs(t, err, ErrTooManyPlantReminders)
+	mockReminderRepo.AssertExpectations(t)
+}
+
+// TestPlantReminderService_OtherUsersReminder tests that reminders of other users are not found
+func TestPlantReminderService_OtherUsersReminder(t *testing.T) {
+	mockReminderRepo := new(MockPlantReminderRepository)
+	service := NewPlantReminderService(mockReminderRepo, new(MockPlantRepository))
+	ctx := context.Background()
+	reminder := &models.PlantReminder{ID: uuid.New(), UserID: uuid.New(), PlantID: uuid.New(), Title: "Clean the leaves", FrequencyDays: 7}
+	mockReminderRepo.On("GetByID", ctx, reminder.ID).Return(reminder, nil)
+
+	_, err := service.UpdateReminder(ctx, uuid.New(), reminder.PlantID, reminder.ID, &models.PlantReminderRequest{Title: "Dust", FrequencyDays: 7})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	err = service.DeleteReminder(ctx, uuid.New(), reminder.PlantID, reminder.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	mockReminderRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockReminderRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+// TestNotificationService_ReminderNotifications tests that due reminders are sent with their title
+// and moved to their next due date
+func TestNotificationService_ReminderNotifications(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockUserPlantTaskRepo := new(MockUserPlantTaskRepository)
+	mockReminderRepo := new(MockPlantReminderRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	service := NewNotificationService(mockNotificationRepo, mockPlantRepo, mockUserPlantTaskRepo, NewNotificationTemplateService(mockTemplateRepo))
+	service.SetReminderRepository(mockReminderRepo)
+
+	ctx := context.Background()
+	today := truncateToDay(time.Now())
+	userPlant := &models.UserPlant{
+		UserID:       uuid.New(),
+		PlantID:      uuid.New(),
+		Plant:        &models.Plant{Name: "Monstera"},
+		UserLanguage: models.LanguageEnglish,
+	}
+	reminder := &models.PlantReminder{
+		ID:            uuid.New(),
+		UserID:        userPlant.UserID,
+		PlantID:       userPlant.PlantID,
+		Title:         "Clean the leaves",
+		FrequencyDays: 10,
+		NextDue:       today.AddDate(0, 0, -3),
+		UserPlant:     userPlant,
+	}
+
+	mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{}, nil)
+	mockUserPlantTaskRepo.On("GetDue", ctx, today).Return([]*models.UserPlantTask{}, nil)
+	mockReminderRepo.On("GetDue", ctx, today).Return([]*models.PlantReminder{reminder}, nil)
+	mockTemplateRepo.On("Get", ctx, models.NotificationTypeReminder, models.LanguageEnglish).Return(nil, nil)
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.Type == models.NotificationTypeReminder &&
+			n.Message == "Reminder for your Monstera: Clean the leaves" &&
+			n.Payload["reminderId"] == reminder.ID.String()
+	})).Return(nil)
+	mockReminderRepo.On("SetNextDue", ctx, reminder.ID, today.AddDate(0, 0, 7)).Return(nil)
+
+	stats, err := service.CheckAndCreateCareNotifications(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.CareTasksDue)
+	assert.Equal(t, 1, stats.NotificationsCreated)
+	mockNotificationRepo.AssertExpectations(t)
+	mockReminderRepo.AssertExpectations(t)
+}
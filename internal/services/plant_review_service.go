@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPlantReviewUnavailable is returned when catalog plants cannot change their review state
+	ErrPlantReviewUnavailable = errors.New("plant review is not available")
+
+	// ErrInvalidPlantStatusChange is returned for moves between review states the workflow does not have
+	ErrInvalidPlantStatusChange = errors.New("invalid plant status change")
+
+	// ErrPlantStatusChangeForbidden is returned when none of the roles of a user may make a move
+	ErrPlantStatusChangeForbidden = errors.New("plant status change is not allowed for the user's roles")
+
+	// ErrPlantNotDraft is returned when a plant that is not a draft is edited as one
+	ErrPlantNotDraft = errors.New("plant is not a draft")
+)
+
+// plantStatusChange is a move of a catalog plant from one review state to another
+type plantStatusChange struct {
+	from models.PlantStatus
+	to   models.PlantStatus
+}
+
+// plantStatusChanges are the moves of the review workflow with the roles allowed to make them
+var plantStatusChanges = map[plantStatusChange][]models.Role{
+	// Submitted for review
+	{models.PlantStatusDraft, models.PlantStatusInReview}: {models.RoleEditor, models.RoleAdmin},
+	// Withdrawn by an editor or sent back by a reviewer
+	{models.PlantStatusInReview, models.PlantStatusDraft}: {models.RoleEditor, models.RoleReviewer, models.RoleAdmin},
+	// Published
+	{models.PlantStatusInReview, models.PlantStatusPublished}: {models.RoleReviewer, models.RoleAdmin},
+	// Unpublished to be fixed
+	{models.PlantStatusPublished, models.PlantStatusDraft}: {models.RoleAdmin},
+}
+
+// SetReviewRepository lets catalog plants move between review states
+func (s *PlantService) SetReviewRepository(reviewRepo repository.PlantReviewRepository) {
+	s.reviewRepo = reviewRepo
+}
+
+// ListPlantsByStatus gets the catalog plants in a review state, the ones waiting longest first
+func (s *PlantService) ListPlantsByStatus(ctx context.Context, status models.PlantStatus) ([]*models.Plant, error) {
+	if s.reviewRepo == nil {
+		return nil, ErrPlantReviewUnavailable
+	}
+	plants, err := s.reviewRepo.ListByStatus(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plants by status: %w", err)
+	}
+	return plants, nil
+}
+
+// GetCatalogPlant gets a plant by ID whatever its review state, for the editors of the catalog
+func (s *PlantService) GetCatalogPlant(ctx context.Context, plantID uuid.UUID) (*models.Plant, error) {
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant: %w", err)
+	}
+	return plant, nil
+}
+
+// CreateDraftPlant creates a catalog plant users do not see until it is reviewed and published
+func (s *PlantService) CreateDraftPlant(ctx context.Context, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, []models.Warning, error) {
+	plant.Status = models.PlantStatusDraft
+	return s.CreatePlant(ctx, plant, careInstructions)
+}
+
+// UpdateDraftPlant replaces a draft and its care instructions. Plants in review or published are
+// edited by sending them back to draft first.
+func (s *PlantService) UpdateDraftPlant(ctx context.Context, plantID uuid.UUID, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, error) {
+	current, err := s.plantRepo.GetByID(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant: %w", err)
+	}
+	if current.Status != models.PlantStatusDraft {
+		return nil, ErrPlantNotDraft
+	}
+
+	updated, err := s.UpdatePlant(ctx, plantID, plant, careInstructions)
+	if err != nil {
+		return nil, err
+	}
+	updated.Status = models.PlantStatusDraft
+	return updated, nil
+}
+
+// ChangePlantStatus moves a catalog plant to another review state if one of the roles of the user
+// may make the move
+func (s *PlantService) ChangePlantStatus(ctx context.Context, plantID uuid.UUID, status models.PlantStatus, roles []string) (*models.Plant, error) {
+	if s.reviewRepo == nil {
+		return nil, ErrPlantReviewUnavailable
+	}
+
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant: %w", err)
+	}
+	allowed, ok := plantStatusChanges[plantStatusChange{from: plant.Status, to: status}]
+	if !ok || plant.DeletedAt != nil {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidPlantStatusChange, plant.Status, status)
+	}
+	if !hasAnyRole(roles, allowed) {
+		return nil, ErrPlantStatusChangeForbidden
+	}
+
+	if err := s.reviewRepo.SetStatus(ctx, plantID, plant.Status, status); err != nil {
+		return nil, fmt.Errorf("failed to change plant status: %w", err)
+	}
+	now := time.Now()
+	plant.Status = status
+	plant.StatusChangedAt = &now
+	return plant, nil
+}
+
+// hasAnyRole reports whether one of the granted roles is allowed
+func hasAnyRole(granted []string, allowed []models.Role) bool {
+	for _, role := range granted {
+		for _, a := range allowed {
+			if models.Role(role) == a {
+				return true
+			}
+		}
+	}
+	return false
+}
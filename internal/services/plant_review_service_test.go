@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPlantReviewRepository is a mock implementation of the PlantReviewRepository interface
+type MockPlantReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlantReviewRepository) ListByStatus(ctx context.Context, status models.PlantStatus) ([]*models.Plant, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]*models.Plant), args.Error(1)
+}
+
+func (m *MockPlantReviewRepository) SetStatus(ctx context.Context, plantID uuid.UUID, from models.PlantStatus, to models.PlantStatus) error {
+	args := m.Called(ctx, plantID, from, to)
+	return args.Error(0)
+}
+
+func TestPlantService_ChangePlantStatus(t *testing.T) {
+	deletedAt := time.Now()
+	tests := []struct {
+		name    string
+		plant   models.Plant
+		status  models.PlantStatus
+		roles   []string
+		wantErr error
+	}{
+		{name: "editor submits a draft", plant: models.Plant{Status: models.PlantStatusDraft}, status: models.PlantStatusInReview, roles: []string{"editor"}},
+		{name: "reviewer publishes", plant: models.Plant{Status: models.PlantStatusInReview}, status: models.PlantStatusPublished, roles: []string{"reviewer"}},
+		{name: "reviewer sends back", plant: models.Plant{Status: models.PlantStatusInReview}, status: models.PlantStatusDraft, roles: []string{"reviewer"}},
+		{name: "admin unpublishes", plant: models.Plant{Status: models.PlantStatusPublished}, status: models.PlantStatusDraft, roles: []string{"expert", "admin"}},
+		{name: "editor publishes", plant: models.Plant{Status: models.PlantStatusInReview}, status: models.PlantStatusPublished, roles: []string{"editor"}, wantErr: ErrPlantStatusChangeForbidden},
+		{name: "reviewer unpublishes", plant: models.Plant{Status: models.PlantStatusPublished}, status: models.PlantStatusDraft, roles: []string{"reviewer"}, wantErr: ErrPlantStatusChangeForbidden},
+		{name: "draft skips review", plant: models.Plant{Status: models.PlantStatusDraft}, status: models.PlantStatusPublished, roles: []string{"admin"}, wantErr: ErrInvalidPlantStatusChange},
+		{name: "same state", plant: models.Plant{Status: models.PlantStatusInReview}, status: models.PlantStatusInReview, roles: []string{"admin"}, wantErr: ErrInvalidPlantStatusChange},
+		{name: "removed from the catalog", plant: models.Plant{Status: models.PlantStatusDraft, DeletedAt: &deletedAt}, status: models.PlantStatusInReview, roles: []string{"admin"}, wantErr: ErrInvalidPlantStatusChange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPlantRepo := new(MockPlantRepository)
+			mockReviewRepo := new(MockPlantReviewRepository)
+			service := NewPlantService(mockPlantRepo)
+			service.SetReviewRepository(mockReviewRepo)
+
+			plant := tt.plant
+			plant.ID = uuid.New()
+			mockPlantRepo.On("GetByID", mock.Anything, plant.ID).Return(&plant, nil)
+			from := plant.Status
+			if tt.wantErr == nil {
+				mockReviewRepo.On("SetStatus", mock.Anything, plant.ID, from, tt.status).Return(nil)
+			}
+
+			changed, err := service.ChangePlantStatus(context.Background(), plant.ID, tt.status, tt.roles)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockReviewRepo.AssertNotCalled(t, "SetStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, changed.Status)
+			assert.NotNil(t, changed.StatusChangedAt)
+			mockReviewRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPlantService_ChangePlantStatus_Unavailable(t *testing.T) {
+	service := NewPlantService(new(MockPlantRepository))
+
+	_, err := service.ChangePlantStatus(context.Background(), uuid.New(), models.PlantStatusInReview, []string{"editor"})
+	assert.ErrorIs(t, err, ErrPlantReviewUnavailable)
+}
+
+func TestPlantService_UpdateDraftPlant_NotDraft(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewPlantService(mockPlantRepo)
+	plantID := uuid.New()
+	mockPlantRepo.On("GetByID", mock.Anything, plantID).Return(&models.Plant{ID: plantID, Status: models.PlantStatusInReview}, nil)
+
+	_, err := service.UpdateDraftPlant(context.Background(), plantID, &models.Plant{Name: "Monstera"}, &models.CareInstructions{})
+	assert.ErrorIs(t, err, ErrPlantNotDraft)
+	mockPlantRepo.AssertNotCalled(t, "UpdatePlant", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestPlantService_UnpublishedPlantsAreHidden tests that users cannot find or collect plants that are
+// not published
+func TestPlantService_UnpublishedPlantsAreHidden(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewPlantService(mockPlantRepo)
+	ctx := context.Background()
+	plantID := uuid.New()
+	mockPlantRepo.On("GetByID", ctx, plantID).Return(&models.Plant{ID: plantID, Status: models.PlantStatusDraft}, nil)
+
+	_, err := service.GetPlant(ctx, plantID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.ErrorIs(t, service.AddToFavorites(ctx, uuid.New(), plantID), sql.ErrNoRows)
+	mockPlantRepo.AssertNotCalled(t, "AddToFavorites", mock.Anything, mock.Anything, mock.Anything)
+
+	plant, err := service.GetCatalogPlant(ctx, plantID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PlantStatusDraft, plant.Status)
+}
+
+// TestPlantService_ListPlants_PublishedByDefault tests that plant lists only show published plants
+// unless a review state is asked for or removed plants are included
+func TestPlantService_ListPlants_PublishedByDefault(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewPlantService(mockPlantRepo)
+	mockPlantRepo.On("List", mock.Anything, mock.Anything).Return([]*models.Plant{}, 0, nil)
+
+	filter := &models.PlantFilter{}
+	_, _, err := service.ListPlants(context.Background(), filter)
+	require.NoError(t, err)
+	if assert.NotNil(t, filter.Status) {
+		assert.Equal(t, models.PlantStatusPublished, *filter.Status)
+	}
+
+	filter = &models.PlantFilter{IncludeDeleted: true}
+	_, _, err = service.ListPlants(context.Background(), filter)
+	require.NoError(t, err)
+	assert.Nil(t, filter.Status)
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// plantSearchLevels are the values of the light and humidity filters of the search query language
+var plantSearchLevels = map[string]string{
+	"low":    "LOW",
+	"medium": "MEDIUM",
+	"high":   "HIGH",
+}
+
+// plantSearchBooleans are the values of the pet filter of the search query language
+var plantSearchBooleans = map[string]bool{
+	"true":  true,
+	"yes":   true,
+	"false": false,
+	"no":    false,
+}
+
+// ParsePlantSearchQuery parses the search query language of power users: space separated key:value
+// filters such as light:low pet:true water:<7, with the other words searched as text. The keys are
+// light (or sunlight) and humidity with low, medium or high, pet with true or false, family, and water
+// with a number of days between waterings, optionally compared with <, <=, > or >=. Unknown keys and
+// values that are not valid for their key are searched as text too, so a query always parses.
+func ParsePlantSearchQuery(query string) *models.PlantSearchQuery {
+	search := &models.PlantSearchQuery{}
+	var words []string
+	for _, token := range strings.Fields(query) {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || value == "" || !applyPlantSearchFilter(search, strings.ToLower(key), value) {
+			words = append(words, token)
+		}
+	}
+	search.Text = strings.Join(words, " ")
+	return search
+}
+
+// applyPlantSearchFilter sets the filter of a key:value token of the search query language; it
+// reports false when the key is unknown or the value is not valid for it
+func applyPlantSearchFilter(search *models.PlantSearchQuery, key string, value string) bool {
+	switch key {
+	case "light", "sunlight":
+		level, ok := plantSearchLevels[strings.ToLower(value)]
+		if !ok {
+			return false
+		}
+		sunlight := models.SunlightLevel(level)
+		search.Sunlight = &sunlight
+	case "humidity":
+		level, ok := plantSearchLevels[strings.ToLower(value)]
+		if !ok {
+			return false
+		}
+		humidity := models.HumidityLevel(level)
+		search.Humidity = &humidity
+	case "pet":
+		petFriendly, ok := plantSearchBooleans[strings.ToLower(value)]
+		if !ok {
+			return false
+		}
+		search.PetFriendly = &petFriendly
+	case "family":
+		search.Family = &value
+	case "water":
+		return applyPlantSearchRange(value, &search.MinWatering, &search.MaxWatering)
+	default:
+		return false
+	}
+	return true
+}
+
+// applyPlantSearchRange narrows the inclusive bounds of a number of days by a comparison such as
+// <7, >=3 or 5; it reports false when the value is not a positive number with a known comparison
+func applyPlantSearchRange(value string, min **int, max **int) bool {
+	operator := strings.TrimRight(value, "0123456789")
+	days, err := strconv.Atoi(value[len(operator):])
+	if err != nil || days <= 0 {
+		return false
+	}
+
+	switch operator {
+	case "<":
+		days--
+		*max = &days
+	case "<=":
+		*max = &days
+	case ">":
+		days++
+		*min = &days
+	case ">=":
+		*min = &days
+	case "", "=":
+		*min = &days
+		exact := days
+		*max = &exact
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParsePlantSearchQuery tests that filters are parsed from the query and the rest is searched as text
+func TestParsePlantSearchQuery(t *testing.T) {
+	low := models.SunlightLevelLow
+	high := models.HumidityLevelHigh
+	petFriendly := true
+	araceae := "Araceae"
+	three, six, seven := 3, 6, 7
+
+	tests := []struct {
+		name     string
+		query    string
+		expected *models.PlantSearchQuery
+	}{
+		{
+			name:     "filters and text",
+			query:    "light:low pet:true water:<7 tag:hanging",
+			expected: &models.PlantSearchQuery{Text: "tag:hanging", Sunlight: &low, PetFriendly: &petFriendly, MaxWatering: &six},
+		},
+		{
+			name:     "plain text",
+			query:    "  Monstera   deliciosa ",
+			expected: &models.PlantSearchQuery{Text: "Monstera deliciosa"},
+		},
+		{
+			name:     "keys and values ignore case",
+			query:    "Humidity:HIGH family:Araceae Sunlight:Low",
+			expected: &models.PlantSearchQuery{Sunlight: &low, Humidity: &high, Family: &araceae},
+		},
+		{
+			name:     "watering range",
+			query:    "water:>=3 water:<=7",
+			expected: &models.PlantSearchQuery{MinWatering: &three, MaxWatering: &seven},
+		},
+		{
+			name:     "exact watering",
+			query:    "water:7",
+			expected: &models.PlantSearchQuery{MinWatering: &seven, MaxWatering: &seven},
+		},
+		{
+			name:     "invalid values are searched as text",
+			query:    "light:bright pet:maybe water:<0 water:~7 family: ficus",
+			expected: &models.PlantSearchQuery{Text: "light:bright pet:maybe water:<0 water:~7 family: ficus"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParsePlantSearchQuery(tt.query))
+		})
+	}
+}
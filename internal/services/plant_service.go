@@ -2,26 +2,109 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/anpanovv/planter/internal/events"
 	"github.com/anpanovv/planter/internal/models"
 	"github.com/anpanovv/planter/internal/repository"
+	"github.com/anpanovv/planter/internal/storage"
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrInvalidPlantFilter is returned for contradictory or malformed plant list filters
+	ErrInvalidPlantFilter = errors.New("invalid plant filter")
+
+	// ErrInvalidPlant is returned when a catalog plant or its care instructions are incomplete
+	ErrInvalidPlant = errors.New("invalid plant")
+
+	// ErrPlantDeleted is returned when a plant removed from the catalog is added to a collection or favorites
+	ErrPlantDeleted = errors.New("plant has been removed from the catalog")
+
+	// errPlantNotPublished is returned for plants users may not see yet; it reads as not found
+	errPlantNotPublished = fmt.Errorf("plant is not published: %w", sql.ErrNoRows)
+)
+
+const (
+	// defaultPlantPageSize is the number of plants returned per page unless asked otherwise
+	defaultPlantPageSize = 50
+
+	// maxPlantPageSize is the largest page of plants that can be requested
+	maxPlantPageSize = 100
+
+	// favoriteSyncOverlap is how far before the last sync favorite changes are listed again, so a
+	// change committed while the last sync ran is not missed; clients apply changes idempotently
+	favoriteSyncOverlap = 5 * time.Second
+)
+
+// CareScheduler schedules the recurring care tasks of plants added to a collection
+type CareScheduler interface {
+	ScheduleDefaultTasks(ctx context.Context, userPlant *models.UserPlant, plant *models.Plant) error
+}
+
 // PlantService handles plant operations
 type PlantService struct {
 	plantRepo repository.PlantRepository
+	publisher events.Publisher
+	scheduler CareScheduler
+	recorder  PlantEventRecorder
+	speciesRepo repository.PlantSpeciesRepository // nil when plants cannot be cultivars of a species
+	photoRepo   repository.UserPlantPhotoRepository // nil when plants in collections have no photos
+	objects     storage.ObjectStore                 // nil when photos cannot be uploaded
+	moderator   ImageModerator                      // nil when uploaded photos go live unchecked
+	translationRepo repository.PlantTranslationRepository // nil when plants are shown as in the catalog
+	reviewRepo      repository.PlantReviewRepository      // nil when plants cannot change their review state
+	taxonomyRepo    repository.PlantTaxonomyRepository    // nil when plants have no categories or tags
 }
 
 // NewPlantService creates a new plant service
 func NewPlantService(plantRepo repository.PlantRepository) *PlantService {
 	return &PlantService{
 		plantRepo: plantRepo,
+		publisher: events.NopPublisher{},
 	}
 }
 
+// SetEventPublisher sets the publisher domain events are sent to
+func (s *PlantService) SetEventPublisher(publisher events.Publisher) {
+	s.publisher = publisher
+}
+
+// SetSpeciesRepository sets the repository of the species catalog plants can be cultivars of
+func (s *PlantService) SetSpeciesRepository(speciesRepo repository.PlantSpeciesRepository) {
+	s.speciesRepo = speciesRepo
+}
+
+// SetPhotoRepository sets the repository of the photos users take of the plants in their collection
+func (s *PlantService) SetPhotoRepository(photoRepo repository.UserPlantPhotoRepository) {
+	s.photoRepo = photoRepo
+}
+
+// SetObjectStore sets the store uploaded photos are written to
+func (s *PlantService) SetObjectStore(objects storage.ObjectStore) {
+	s.objects = objects
+}
+
+// SetImageModerator sets the moderator uploaded photos are checked by before they go live
+func (s *PlantService) SetImageModerator(moderator ImageModerator) {
+	s.moderator = moderator
+}
+
+// SetCareScheduler sets the scheduler plants added to a collection get their recurring care tasks from
+func (s *PlantService) SetCareScheduler(scheduler CareScheduler) {
+	s.scheduler = scheduler
+}
+
+// SetPlantEventRecorder sets the recorder waterings and moves of plants are added to their event streams with
+func (s *PlantService) SetPlantEventRecorder(recorder PlantEventRecorder) {
+	s.recorder = recorder
+}
+
 // GetAllPlants gets all plants
 func (s *PlantService) GetAllPlants(ctx context.Context) ([]*models.Plant, error) {
 	plants, err := s.plantRepo.GetAll(ctx)
@@ -31,18 +114,66 @@ func (s *PlantService) GetAllPlants(ctx context.Context) ([]*models.Plant, error
 	return plants, nil
 }
 
-// GetPlant gets a plant by ID
+// ListPlants gets a page of plants matching the filter with the total number of matches
+func (s *PlantService) ListPlants(ctx context.Context, filter *models.PlantFilter) ([]*models.Plant, int, error) {
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, 0, fmt.Errorf("%w: minPrice is greater than maxPrice", ErrInvalidPlantFilter)
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = defaultPlantPageSize
+	}
+	if filter.PageSize > maxPlantPageSize {
+		filter.PageSize = maxPlantPageSize
+	}
+
+	// Match categories and tags the way they are saved
+	if filter.Category != nil {
+		slug, ok := normalizeTaxonomyName(*filter.Category)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: invalid category %q", ErrInvalidPlantFilter, *filter.Category)
+		}
+		filter.Category = &slug
+	}
+	if len(filter.Tags) > 0 {
+		tags, err := normalizeTaxonomyNames(filter.Tags)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: %v", ErrInvalidPlantFilter, err)
+		}
+		filter.Tags = tags
+	}
+
+	// Plants in review are only listed when asked for
+	if filter.Status == nil && !filter.IncludeDeleted {
+		published := models.PlantStatusPublished
+		filter.Status = &published
+	}
+
+	plants, total, err := s.plantRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list plants: %w", err)
+	}
+	return plants, total, nil
+}
+
+// GetPlant gets a published plant by ID
 func (s *PlantService) GetPlant(ctx context.Context, plantID uuid.UUID) (*models.Plant, error) {
 	plant, err := s.plantRepo.GetByID(ctx, plantID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get plant: %w", err)
 	}
+	if !plant.Published() {
+		return nil, errPlantNotPublished
+	}
 	return plant, nil
 }
 
-// SearchPlants searches for plants by query
+// SearchPlants searches for plants by a query of the search query language, see ParsePlantSearchQuery
 func (s *PlantService) SearchPlants(ctx context.Context, query string) ([]*models.Plant, error) {
-	plants, err := s.plantRepo.Search(ctx, query)
+	plants, err := s.plantRepo.Search(ctx, ParsePlantSearchQuery(query))
 	if err != nil {
 		return nil, fmt.Errorf("failed to search plants: %w", err)
 	}
@@ -58,13 +189,32 @@ func (s *PlantService) GetFavoritePlants(ctx context.Context, userID uuid.UUID)
 	return plants, nil
 }
 
+// GetFavoriteChanges gets the plants a user added to or removed from their favorites since their
+// last sync, or all their favorites on the first sync, when since is zero
+func (s *PlantService) GetFavoriteChanges(ctx context.Context, userID uuid.UUID, since time.Time) (*models.FavoriteChanges, error) {
+	if !since.IsZero() {
+		since = since.Add(-favoriteSyncOverlap)
+	}
+	changes, err := s.plantRepo.GetFavoriteChanges(ctx, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get favorite changes: %w", err)
+	}
+	return changes, nil
+}
+
 // AddToFavorites adds a plant to a user's favorites
 func (s *PlantService) AddToFavorites(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error {
 	// Check if the plant exists
-	_, err := s.plantRepo.GetByID(ctx, plantID)
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
 	if err != nil {
 		return fmt.Errorf("plant not found: %w", err)
 	}
+	if !plant.Published() {
+		return errPlantNotPublished
+	}
+	if plant.DeletedAt != nil {
+		return ErrPlantDeleted
+	}
 
 	// Add to favorites
 	err = s.plantRepo.AddToFavorites(ctx, userID, plantID)
@@ -124,6 +274,21 @@ func (s *PlantService) MarkAsWatered(ctx context.Context, userID uuid.UUID, plan
 	plant.LastWatered = userPlant.LastWatered
 	plant.NextWatering = userPlant.NextWatering
 	plant.Location = userPlant.Location
+	if lowEffort, source := userPlant.LowEffort(); lowEffort {
+		plant.LowEffort = models.NewLowEffortWatering(plant.CareInstructions, source)
+	}
+
+	wateredAt := time.Now()
+	if userPlant.LastWatered != nil {
+		wateredAt = *userPlant.LastWatered
+	}
+	publishEvent(ctx, s.publisher, events.PlantWatered{
+		UserID:       userID,
+		PlantID:      plantID,
+		WateredAt:    wateredAt,
+		NextWatering: userPlant.NextWatering,
+	})
+	recordPlantEvent(ctx, s.recorder, userID, plantID, models.PlantEventTypeWatered, nil)
 
 	// Check if the plant is a favorite
 	isFavorite, err := s.plantRepo.IsFavorite(ctx, userID, plantID)
@@ -141,15 +306,67 @@ func (s *PlantService) GetUserPlants(ctx context.Context, userID uuid.UUID) ([]*
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user plants: %w", err)
 	}
+	if err := s.attachPhotos(ctx, userID, plants); err != nil {
+		return nil, err
+	}
 	return plants, nil
 }
 
-// AddUserPlant adds a plant to a user's collection
-func (s *PlantService) AddUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, location string) error {
+// GetUserPlantsInHome gets the plants of a user kept in a home; uuid.Nil gets the plants in no home
+func (s *PlantService) GetUserPlantsInHome(ctx context.Context, userID uuid.UUID, homeID uuid.UUID) ([]*models.Plant, error) {
+	plants, err := s.plantRepo.GetUserPlants(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plants: %w", err)
+	}
+
+	inHome := []*models.Plant{}
+	for _, plant := range plants {
+		if (plant.HomeID == nil && homeID == uuid.Nil) || (plant.HomeID != nil && *plant.HomeID == homeID) {
+			inHome = append(inHome, plant)
+		}
+	}
+	if err := s.attachPhotos(ctx, userID, inHome); err != nil {
+		return nil, err
+	}
+	return inHome, nil
+}
+
+// AddUserPlant adds a plant to a user's collection. The returned warnings point out that the plant
+// was already in the collection or that the spot gets a different amount of light than the plant
+// needs; light is optional.
+func (s *PlantService) AddUserPlant(
+	ctx context.Context,
+	userID uuid.UUID,
+	plantID uuid.UUID,
+	location string,
+	light *models.SunlightLevel,
+	details models.UserPlantDetails,
+) ([]models.Warning, error) {
 	// Check if the plant exists
-	_, err := s.plantRepo.GetByID(ctx, plantID)
+	plant, err := s.plantRepo.GetByID(ctx, plantID)
 	if err != nil {
-		return fmt.Errorf("plant not found: %w", err)
+		return nil, fmt.Errorf("plant not found: %w", err)
+	}
+	if !plant.Published() {
+		return nil, errPlantNotPublished
+	}
+	if plant.DeletedAt != nil {
+		return nil, ErrPlantDeleted
+	}
+
+	var warnings []models.Warning
+	if _, err := s.plantRepo.GetUserPlant(ctx, userID, plantID); err == nil {
+		warnings = append(warnings, models.Warning{
+			Code:    models.WarningCodeDuplicatePlant,
+			Message: fmt.Sprintf("%s is already in your collection; its location was updated", plant.Name),
+		})
+	}
+	if light != nil && *light != plant.CareInstructions.Sunlight {
+		warnings = append(warnings, models.Warning{
+			Code: models.WarningCodeLightMismatch,
+			Message: fmt.Sprintf("%s needs %s light but the spot gets %s light",
+				plant.Name, plant.CareInstructions.Sunlight, *light),
+		})
 	}
 
 	// Add the plant to the user's collection
@@ -157,78 +374,349 @@ func (s *PlantService) AddUserPlant(ctx context.Context, userID uuid.UUID, plant
 		UserID:   userID,
 		PlantID:  plantID,
 		Location: &location,
+		Nickname: normalizeUserPlantDetail(details.Nickname),
+		Notes:    normalizeUserPlantDetail(details.Notes),
 	}
 
 	err = s.plantRepo.AddUserPlant(ctx, userPlant)
 	if err != nil {
-		return fmt.Errorf("failed to add user plant: %w", err)
+		return nil, fmt.Errorf("failed to add user plant: %w", err)
 	}
-	return nil
+
+	// The plant is in the collection either way, so a failed schedule is only logged
+	if s.scheduler != nil {
+		if err := s.scheduler.ScheduleDefaultTasks(ctx, userPlant, plant); err != nil {
+			log.Printf("Error scheduling care tasks of plant %s for user %s: %v", plantID, userID, err)
+		}
+	}
+	return warnings, nil
+}
+
+// AddUserPlants adds several plants to a user's collection in one transaction, scheduling the next
+// watering of each from when it was last watered. Plants that cannot be added, such as plants missing
+// from the catalog, are reported in their results without stopping the others; when the transaction
+// fails, none is added.
+func (s *PlantService) AddUserPlants(ctx context.Context, userID uuid.UUID, items []*models.AddUserPlantsItem) (*models.AddUserPlantsResponse, error) {
+	owned, err := s.plantRepo.GetUserPlants(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plants: %w", err)
+	}
+	inCollection := make(map[uuid.UUID]bool, len(owned))
+	for _, plant := range owned {
+		inCollection[plant.ID] = true
+	}
+
+	now := time.Now()
+	response := &models.AddUserPlantsResponse{Results: make([]*models.AddUserPlantsResult, len(items))}
+	var userPlants []*models.UserPlant
+	var plants []*models.Plant
+	inBatch := make(map[uuid.UUID]bool, len(items))
+	for i, item := range items {
+		result := &models.AddUserPlantsResult{PlantID: item.PlantID}
+		response.Results[i] = result
+
+		plant, err := s.plantRepo.GetByID(ctx, item.PlantID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			result.Error = "plant not found"
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to get plant: %w", err)
+		case !plant.Published():
+			result.Error = "plant not found"
+			continue
+		case plant.DeletedAt != nil:
+			result.Error = ErrPlantDeleted.Error()
+			continue
+		case inBatch[item.PlantID]:
+			result.Error = "plant is already in the batch"
+			continue
+		case item.LastWatered != nil && item.LastWatered.After(now):
+			result.Error = "last watered is in the future"
+			continue
+		}
+		inBatch[item.PlantID] = true
+
+		if inCollection[item.PlantID] {
+			result.Warnings = append(result.Warnings, models.Warning{
+				Code:    models.WarningCodeDuplicatePlant,
+				Message: fmt.Sprintf("%s is already in your collection; its location was updated", plant.Name),
+			})
+		}
+
+		location := item.Location
+		userPlant := &models.UserPlant{
+			UserID:      userID,
+			PlantID:     item.PlantID,
+			Location:    &location,
+			LastWatered: item.LastWatered,
+		}
+		if item.LastWatered != nil {
+			nextWatering := item.LastWatered.AddDate(0, 0, plant.CareInstructions.WateringFrequency)
+			userPlant.NextWatering = &nextWatering
+		}
+		userPlants = append(userPlants, userPlant)
+		plants = append(plants, plant)
+		result.Added = true
+	}
+
+	if len(userPlants) > 0 {
+		if err := s.plantRepo.AddUserPlants(ctx, userPlants); err != nil {
+			return nil, fmt.Errorf("failed to add user plants: %w", err)
+		}
+	}
+
+	for _, result := range response.Results {
+		if result.Added {
+			response.Added++
+		} else {
+			response.Failed++
+		}
+	}
+
+	// The plants are in the collection either way, so a failed schedule is only logged
+	if s.scheduler != nil {
+		for i, userPlant := range userPlants {
+			if err := s.scheduler.ScheduleDefaultTasks(ctx, userPlant, plants[i]); err != nil {
+				log.Printf("Error scheduling care tasks of plant %s for user %s: %v", userPlant.PlantID, userID, err)
+			}
+		}
+	}
+	return response, nil
 }
 
-// UpdateUserPlant updates a user's plant
-func (s *PlantService) UpdateUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, location string) error {
+// UpdateUserPlant updates the location, nickname and notes of a user's plant; nil values are left unchanged
+func (s *PlantService) UpdateUserPlant(
+	ctx context.Context,
+	userID uuid.UUID,
+	plantID uuid.UUID,
+	location *string,
+	details models.UserPlantDetails,
+) error {
 	// Check if the user owns the plant
 	userPlant, err := s.plantRepo.GetUserPlant(ctx, userID, plantID)
 	if err != nil {
 		return fmt.Errorf("user does not own this plant: %w", err)
 	}
 
-	// Update the location
-	userPlant.Location = &location
+	// Update the location and details
+	previous := userPlant.Location
+	if location != nil {
+		userPlant.Location = location
+	}
+	if details.Nickname != nil {
+		userPlant.Nickname = normalizeUserPlantDetail(details.Nickname)
+	}
+	if details.Notes != nil {
+		userPlant.Notes = normalizeUserPlantDetail(details.Notes)
+	}
 
 	// Update the user plant
 	err = s.plantRepo.UpdateUserPlant(ctx, userPlant)
 	if err != nil {
 		return fmt.Errorf("failed to update user plant: %w", err)
 	}
+
+	// Moving the plant to another room is a lifecycle event
+	if location != nil && (previous == nil || *previous != *location) {
+		payload := models.PlantEventPayload{"from": nil, "to": *location}
+		if previous != nil {
+			payload["from"] = *previous
+		}
+		recordPlantEvent(ctx, s.recorder, userID, plantID, models.PlantEventTypeMoved, payload)
+	}
 	return nil
 }
 
-// RemoveUserPlant removes a plant from a user's collection
+// RemoveUserPlant removes a plant from a user's collection along with its photos
 func (s *PlantService) RemoveUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error {
+	// The photo rows go with the plant, so the stored images are looked up first
+	var photos []*models.UserPlantPhoto
+	if s.photoRepo != nil && s.objects != nil {
+		var err error
+		photos, err = s.photoRepo.ListByUserPlant(ctx, userID, plantID)
+		if err != nil {
+			return fmt.Errorf("failed to get user plant photos: %w", err)
+		}
+	}
+
 	err := s.plantRepo.RemoveUserPlant(ctx, userID, plantID)
 	if err != nil {
 		return fmt.Errorf("failed to remove user plant: %w", err)
 	}
+	for _, photo := range photos {
+		s.deleteStoredPhoto(ctx, photo)
+	}
+	return nil
+}
+
+// CreatePlant creates a new plant. The returned warnings list catalog plants the new one probably duplicates.
+// A cultivar gets the care instructions of its species with its overrides applied.
+func (s *PlantService) CreatePlant(ctx context.Context, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, []models.Warning, error) {
+	careInstructions, err := s.resolveCareInstructions(ctx, plant, careInstructions)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validatePlant(plant, careInstructions); err != nil {
+		return nil, nil, err
+	}
+
+	// Look for plants that are probably the same one before adding another
+	warnings := s.duplicatePlantWarnings(ctx, plant)
+
+	// Create the plant
+	createdPlant, err := s.plantRepo.CreatePlant(ctx, plant, careInstructions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create plant: %w", err)
+	}
+
+	return createdPlant, warnings, nil
+}
+
+// UpdatePlant replaces a catalog plant and its care instructions. A cultivar gets the care
+// instructions of its species with its overrides applied.
+func (s *PlantService) UpdatePlant(ctx context.Context, plantID uuid.UUID, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, error) {
+	careInstructions, err := s.resolveCareInstructions(ctx, plant, careInstructions)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePlant(plant, careInstructions); err != nil {
+		return nil, err
+	}
+
+	plant.ID = plantID
+	updatedPlant, err := s.plantRepo.UpdatePlant(ctx, plant, careInstructions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update plant: %w", err)
+	}
+	return updatedPlant, nil
+}
+
+// DeletePlant removes a plant from the catalog; users who own it or marked it as a favorite keep it
+func (s *PlantService) DeletePlant(ctx context.Context, plantID uuid.UUID) error {
+	if err := s.plantRepo.DeletePlant(ctx, plantID); err != nil {
+		return fmt.Errorf("failed to delete plant: %w", err)
+	}
 	return nil
 }
 
-// CreatePlant creates a new plant
-func (s *PlantService) CreatePlant(ctx context.Context, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, error) {
+// validatePlant checks that a catalog plant and its care instructions are complete
+func validatePlant(plant *models.Plant, careInstructions *models.CareInstructions) error {
 	// Validate plant data
 	if plant.Name == "" {
-		return nil, fmt.Errorf("plant name is required")
+		return fmt.Errorf("%w: plant name is required", ErrInvalidPlant)
 	}
 	if plant.ScientificName == "" {
-		return nil, fmt.Errorf("scientific name is required")
+		return fmt.Errorf("%w: scientific name is required", ErrInvalidPlant)
 	}
 	if plant.Description == "" {
-		return nil, fmt.Errorf("description is required")
+		return fmt.Errorf("%w: description is required", ErrInvalidPlant)
 	}
 	if plant.ImageURL == "" {
-		return nil, fmt.Errorf("image URL is required")
+		return fmt.Errorf("%w: image URL is required", ErrInvalidPlant)
+	}
+	if err := validateToxicity(plant); err != nil {
+		return err
 	}
 
 	// Validate care instructions
+	return validateCareInstructions(careInstructions, ErrInvalidPlant)
+}
+
+// validateToxicity checks the toxicity severities of a catalog plant and that they agree with it being
+// pet friendly. A plant whose toxicity to pets is known is pet friendly when it is not toxic to them.
+func validateToxicity(plant *models.Plant) error {
+	pets, children := plant.Toxicity.Severities()
+	for _, severity := range []*models.ToxicitySeverity{pets, children} {
+		if severity == nil {
+			continue
+		}
+		switch *severity {
+		case models.ToxicitySeverityNone, models.ToxicitySeverityMild, models.ToxicitySeverityModerate, models.ToxicitySeveritySevere:
+		default:
+			return fmt.Errorf("%w: toxicity must be NONE, MILD, MODERATE or SEVERE", ErrInvalidPlant)
+		}
+	}
+	if pets == nil {
+		return nil
+	}
+
+	safe := *pets == models.ToxicitySeverityNone
+	if plant.PetFriendly != nil && *plant.PetFriendly != safe {
+		return fmt.Errorf("%w: petFriendly contradicts the toxicity to pets", ErrInvalidPlant)
+	}
+	plant.PetFriendly = &safe
+	return nil
+}
+
+// validateCareInstructions checks that care instructions are complete, wrapping problems in invalid
+func validateCareInstructions(careInstructions *models.CareInstructions, invalid error) error {
 	if careInstructions.WateringFrequency <= 0 {
-		return nil, fmt.Errorf("watering frequency must be positive")
+		return fmt.Errorf("%w: watering frequency must be positive", invalid)
+	}
+	if min := careInstructions.WateringFrequencyMin; min != nil && (*min <= 0 || *min > careInstructions.WateringFrequency) {
+		return fmt.Errorf("%w: minimum watering frequency must be positive and at most the watering frequency", invalid)
+	}
+	if max := careInstructions.WateringFrequencyMax; max != nil && *max < careInstructions.WateringFrequency {
+		return fmt.Errorf("%w: maximum watering frequency must be at least the watering frequency", invalid)
 	}
 	if careInstructions.Temperature.Min >= careInstructions.Temperature.Max {
-		return nil, fmt.Errorf("minimum temperature must be less than maximum temperature")
+		return fmt.Errorf("%w: minimum temperature must be less than maximum temperature", invalid)
 	}
 	if careInstructions.SoilType == "" {
-		return nil, fmt.Errorf("soil type is required")
+		return fmt.Errorf("%w: soil type is required", invalid)
 	}
 	if careInstructions.FertilizerFrequency <= 0 {
-		return nil, fmt.Errorf("fertilizer frequency must be positive")
+		return fmt.Errorf("%w: fertilizer frequency must be positive", invalid)
 	}
+	return nil
+}
 
-	// Create the plant
-	createdPlant, err := s.plantRepo.CreatePlant(ctx, plant, careInstructions)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create plant: %w", err)
+// duplicatePlantWarnings warns about catalog plants with the same name or scientific name as the
+// new plant, ignoring case and spacing. The check is best effort, so search errors are only logged.
+func (s *PlantService) duplicatePlantWarnings(ctx context.Context, plant *models.Plant) []models.Warning {
+	seen := make(map[uuid.UUID]bool)
+	var warnings []models.Warning
+	for _, query := range []string{plant.ScientificName, plant.Name} {
+		candidates, err := s.plantRepo.Search(ctx, &models.PlantSearchQuery{Text: strings.TrimSpace(query)})
+		if err != nil {
+			log.Printf("Error searching for duplicates of plant %q: %v", plant.Name, err)
+			return warnings
+		}
+
+		for _, candidate := range candidates {
+			if seen[candidate.ID] {
+				continue
+			}
+			if normalizePlantName(candidate.ScientificName) == normalizePlantName(plant.ScientificName) ||
+				normalizePlantName(candidate.Name) == normalizePlantName(plant.Name) {
+				seen[candidate.ID] = true
+				warnings = append(warnings, models.Warning{
+					Code:    models.WarningCodeDuplicatePlant,
+					Message: fmt.Sprintf("%s (%s) is already in the catalog with ID %s", candidate.Name, candidate.ScientificName, candidate.ID),
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+// normalizePlantName returns the form of a plant name used to find duplicates: lower case, with
+// single spaces
+func normalizePlantName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// GetStaleCareInstructions gets plants whose care instructions have not been reviewed in the given number of days
+func (s *PlantService) GetStaleCareInstructions(ctx context.Context, maxAgeDays int) ([]*models.Plant, error) {
+	if maxAgeDays <= 0 {
+		return nil, fmt.Errorf("max age must be positive")
 	}
 
-	return createdPlant, nil
+	reviewedBefore := time.Now().AddDate(0, 0, -maxAgeDays)
+	plants, err := s.plantRepo.GetPlantsWithCareReviewedBefore(ctx, reviewedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plants with stale care instructions: %w", err)
+	}
+	return plants, nil
 }
\ No newline at end of file
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -22,6 +24,11 @@ func (m *MockPlantRepository) GetAll(ctx context.Context) ([]*models.Plant, erro
 	return args.Get(0).([]*models.Plant), args.Error(1)
 }
 
+func (m *MockPlantRepository) List(ctx context.Context, filter *models.PlantFilter) ([]*models.Plant, int, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*models.Plant), args.Int(1), args.Error(2)
+}
+
 func (m *MockPlantRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Plant, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -30,7 +37,7 @@ func (m *MockPlantRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 	return args.Get(0).(*models.Plant), args.Error(1)
 }
 
-func (m *MockPlantRepository) Search(ctx context.Context, query string) ([]*models.Plant, error) {
+func (m *MockPlantRepository) Search(ctx context.Context, query *models.PlantSearchQuery) ([]*models.Plant, error) {
 	args := m.Called(ctx, query)
 	return args.Get(0).([]*models.Plant), args.Error(1)
 }
@@ -73,16 +80,39 @@ func (m *MockPlantRepository) AddUserPlant(ctx context.Context, userPlant *model
 	return args.Error(0)
 }
 
+func (m *MockPlantRepository) AddUserPlants(ctx context.Context, userPlants []*models.UserPlant) error {
+	args := m.Called(ctx, userPlants)
+	return args.Error(0)
+}
+
 func (m *MockPlantRepository) UpdateUserPlant(ctx context.Context, userPlant *models.UserPlant) error {
 	args := m.Called(ctx, userPlant)
 	return args.Error(0)
 }
 
+func (m *MockPlantRepository) SetUserPlantLowEffortMode(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, enabled *bool) error {
+	args := m.Called(ctx, userID, plantID, enabled)
+	return args.Error(0)
+}
+
+func (m *MockPlantRepository) SetNextWatering(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, nextWatering time.Time) error {
+	args := m.Called(ctx, userID, plantID, nextWatering)
+	return args.Error(0)
+}
+
 func (m *MockPlantRepository) RemoveUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) error {
 	args := m.Called(ctx, userID, plantID)
 	return args.Error(0)
 }
 
+func (m *MockPlantRepository) GetFavoriteChanges(ctx context.Context, userID uuid.UUID, since time.Time) (*models.FavoriteChanges, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FavoriteChanges), args.Error(1)
+}
+
 func (m *MockPlantRepository) IsFavorite(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) (bool, error) {
 	args := m.Called(ctx, userID, plantID)
 	return args.Bool(0), args.Error(1)
@@ -96,6 +126,29 @@ func (m *MockPlantRepository) CreatePlant(ctx context.Context, plant *models.Pla
 	return args.Get(0).(*models.Plant), args.Error(1)
 }
 
+func (m *MockPlantRepository) UpdatePlant(ctx context.Context, plant *models.Plant, careInstructions *models.CareInstructions) (*models.Plant, error) {
+	args := m.Called(ctx, plant, careInstructions)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Plant), args.Error(1)
+}
+
+func (m *MockPlantRepository) UpdatePlantImage(ctx context.Context, plantID uuid.UUID, imageURL models.AssetKey) error {
+	args := m.Called(ctx, plantID, imageURL)
+	return args.Error(0)
+}
+
+func (m *MockPlantRepository) DeletePlant(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPlantRepository) GetPlantsWithCareReviewedBefore(ctx context.Context, reviewedBefore time.Time) ([]*models.Plant, error) {
+	args := m.Called(ctx, reviewedBefore)
+	return args.Get(0).([]*models.Plant), args.Error(1)
+}
+
 // TestPlantService_CreatePlant tests the CreatePlant method
 func TestPlantService_CreatePlant(t *testing.T) {
 	// Create a mock repository
@@ -133,13 +186,15 @@ func TestPlantService_CreatePlant(t *testing.T) {
 	}
 
 	// Set up the mock expectations
+	mockRepo.On("Search", mock.Anything, mock.Anything).Return([]*models.Plant{}, nil)
 	mockRepo.On("CreatePlant", mock.Anything, plant, careInstructions).Return(expectedPlant, nil)
 
 	// Call the method
-	result, err := plantService.CreatePlant(context.Background(), plant, careInstructions)
+	result, warnings, err := plantService.CreatePlant(context.Background(), plant, careInstructions)
 
 	// Assert that there was no error
 	assert.NoError(t, err)
+	assert.Empty(t, warnings)
 
 	// Assert that the result is the expected plant
 	assert.Equal(t, expectedPlant, result)
@@ -185,6 +240,43 @@ func TestPlantService_GetAllPlants(t *testing.T) {
 	mockPlantRepo.AssertExpectations(t)
 }
 
+// TestPlantService_ListPlants tests the default and capped page sizes of ListPlants
+func TestPlantService_ListPlants(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockPlantRepo)
+
+	plants := []*models.Plant{{ID: uuid.New(), Name: "Plant 1"}}
+	mockPlantRepo.On("List", mock.Anything, mock.MatchedBy(func(filter *models.PlantFilter) bool {
+		return filter.Page == 1 && filter.PageSize == defaultPlantPageSize
+	})).Return(plants, 7, nil).Once()
+	mockPlantRepo.On("List", mock.Anything, mock.MatchedBy(func(filter *models.PlantFilter) bool {
+		return filter.Page == 3 && filter.PageSize == maxPlantPageSize
+	})).Return([]*models.Plant{}, 7, nil).Once()
+
+	result, total, err := plantService.ListPlants(context.Background(), &models.PlantFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, plants, result)
+	assert.Equal(t, 7, total)
+
+	result, _, err = plantService.ListPlants(context.Background(), &models.PlantFilter{Page: 3, PageSize: 1000})
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+
+	mockPlantRepo.AssertExpectations(t)
+}
+
+// TestPlantService_ListPlants_InvalidPriceRange tests that a minimum price above the maximum is rejected
+func TestPlantService_ListPlants_InvalidPriceRange(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockPlantRepo)
+
+	minPrice, maxPrice := 500.0, 100.0
+	_, _, err := plantService.ListPlants(context.Background(), &models.PlantFilter{MinPrice: &minPrice, MaxPrice: &maxPrice})
+
+	assert.ErrorIs(t, err, ErrInvalidPlantFilter)
+	mockPlantRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
 // TestPlantService_GetPlant tests the GetPlant method of the PlantService
 func TestPlantService_GetPlant(t *testing.T) {
 	// Create a mock plant repository
@@ -262,4 +354,337 @@ func TestPlantService_MarkAsWatered_NotInCollection(t *testing.T) {
 	assert.Equal(t, userPlant.LastWatered, result.LastWatered)
 	assert.Equal(t, userPlant.NextWatering, result.NextWatering)
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+// TestPlantService_CreatePlant_DuplicateWarning tests that a likely duplicate of a catalog plant is reported
+func TestPlantService_CreatePlant_DuplicateWarning(t *testing.T) {
+	mockRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockRepo)
+
+	plant := &models.Plant{
+		Name:           "Monstera",
+		ScientificName: "Monstera  deliciosa",
+		Description:    "Swiss cheese plant",
+		ImageURL:       "https://example.com/monstera.jpg",
+	}
+	careInstructions := &models.CareInstructions{
+		WateringFrequency:   7,
+		Temperature:         models.TemperatureRange{Min: 18, Max: 27},
+		SoilType:            "Peat-based",
+		FertilizerFrequency: 30,
+	}
+	existing := &models.Plant{ID: uuid.New(), Name: "Monstera Deliciosa", ScientificName: "Monstera deliciosa"}
+
+	mockRepo.On("Search", mock.Anything, &models.PlantSearchQuery{Text: "Monstera  deliciosa"}).Return([]*models.Plant{existing}, nil)
+	mockRepo.On("Search", mock.Anything, &models.PlantSearchQuery{Text: "Monstera"}).Return([]*models.Plant{existing}, nil)
+	mockRepo.On("CreatePlant", mock.Anything, plant, careInstructions).Return(&models.Plant{ID: uuid.New()}, nil)
+
+	_, warnings, err := plantService.CreatePlant(context.Background(), plant, careInstructions)
+
+	assert.NoError(t, err)
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, models.WarningCodeDuplicatePlant, warnings[0].Code)
+		assert.Contains(t, warnings[0].Message, existing.ID.String())
+	}
+}
+
+// TestPlantService_AddUserPlant_Warnings tests the warnings about a plant already in the collection and a dim spot
+func TestPlantService_AddUserPlant_Warnings(t *testing.T) {
+	mockRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockRepo)
+
+	userID := uuid.New()
+	plant := &models.Plant{ID: uuid.New(), Name: "Aloe", CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelHigh}}
+	low := models.SunlightLevelLow
+
+	mockRepo.On("GetByID", mock.Anything, plant.ID).Return(plant, nil)
+	mockRepo.On("GetUserPlant", mock.Anything, userID, plant.ID).Return(&models.UserPlant{UserID: userID, PlantID: plant.ID}, nil)
+	mockRepo.On("AddUserPlant", mock.Anything, mock.Anything).Return(nil)
+
+	warnings, err := plantService.AddUserPlant(context.Background(), userID, plant.ID, "Hallway", &low, models.UserPlantDetails{})
+
+	assert.NoError(t, err)
+	if assert.Len(t, warnings, 2) {
+		assert.Equal(t, models.WarningCodeDuplicatePlant, warnings[0].Code)
+		assert.Equal(t, models.WarningCodeLightMismatch, warnings[1].Code)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+// TestPlantService_AddUserPlants tests that the plants of a batch are added together, scheduled from
+// when they were last watered, and that the plants that cannot be added are reported
+func TestPlantService_AddUserPlants(t *testing.T) {
+	mockRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockRepo)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	deletedAt := time.Now()
+	aloe := &models.Plant{ID: uuid.New(), Name: "Aloe", CareInstructions: models.CareInstructions{WateringFrequency: 14}}
+	ficus := &models.Plant{ID: uuid.New(), Name: "Ficus", CareInstructions: models.CareInstructions{WateringFrequency: 7}}
+	removed := &models.Plant{ID: uuid.New(), Name: "Fern", DeletedAt: &deletedAt}
+	cactus := &models.Plant{ID: uuid.New(), Name: "Cactus"}
+	missingID := uuid.New()
+	lastWatered := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	future := time.Now().Add(48 * time.Hour)
+
+	mockRepo.On("GetUserPlants", ctx, userID).Return([]*models.Plant{ficus}, nil)
+	mockRepo.On("GetByID", ctx, aloe.ID).Return(aloe, nil)
+	mockRepo.On("GetByID", ctx, ficus.ID).Return(ficus, nil)
+	mockRepo.On("GetByID", ctx, removed.ID).Return(removed, nil)
+	mockRepo.On("GetByID", ctx, cactus.ID).Return(cactus, nil)
+	mockRepo.On("GetByID", ctx, missingID).Return(nil, fmt.Errorf("plant not found: %w", sql.ErrNoRows))
+
+	var added []*models.UserPlant
+	mockRepo.On("AddUserPlants", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		added = args.Get(1).([]*models.UserPlant)
+	}).Return(nil).Once()
+
+	response, err := plantService.AddUserPlants(ctx, userID, []*models.AddUserPlantsItem{
+		{PlantID: aloe.ID, Location: "Kitchen", LastWatered: &lastWatered},
+		{PlantID: ficus.ID, Location: "Hallway"},
+		{PlantID: removed.ID},
+		{PlantID: missingID},
+		{PlantID: aloe.ID, Location: "Bedroom"},
+		{PlantID: cactus.ID, LastWatered: &future},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, response.Added)
+	assert.Equal(t, 4, response.Failed)
+	var outcomes []string
+	for _, result := range response.Results {
+		outcomes = append(outcomes, fmt.Sprintf("%t %s", result.Added, result.Error))
+	}
+	assert.Equal(t, []string{
+		"true ",
+		"true ",
+		"false " + ErrPlantDeleted.Error(),
+		"false plant not found",
+		"false plant is already in the batch",
+		"false last watered is in the future",
+	}, outcomes)
+	if assert.Len(t, response.Results[1].Warnings, 1) {
+		assert.Equal(t, models.WarningCodeDuplicatePlant, response.Results[1].Warnings[0].Code)
+	}
+	if assert.Len(t, added, 2) {
+		assert.Equal(t, "Kitchen", *added[0].Location)
+		assert.Equal(t, lastWatered.AddDate(0, 0, 14), *added[0].NextWatering)
+		assert.Nil(t, added[1].NextWatering)
+	}
+
+	// A failed transaction adds none of the plants
+	mockRepo.On("AddUserPlants", ctx, mock.Anything).Return(errors.New("connection reset")).Once()
+	_, err = plantService.AddUserPlants(ctx, userID, []*models.AddUserPlantsItem{{PlantID: aloe.ID}})
+	assert.Error(t, err)
+}
+
+// TestPlantService_AddUserPlant_Deleted tests that plants removed from the catalog cannot be added again
+func TestPlantService_AddUserPlant_Deleted(t *testing.T) {
+	mockRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockRepo)
+
+	deletedAt := time.Now()
+	plant := &models.Plant{ID: uuid.New(), Name: "Aloe", DeletedAt: &deletedAt}
+	mockRepo.On("GetByID", mock.Anything, plant.ID).Return(plant, nil)
+
+	_, err := plantService.AddUserPlant(context.Background(), uuid.New(), plant.ID, "Kitchen", nil, models.UserPlantDetails{})
+	assert.ErrorIs(t, err, ErrPlantDeleted)
+
+	err = plantService.AddToFavorites(context.Background(), uuid.New(), plant.ID)
+	assert.ErrorIs(t, err, ErrPlantDeleted)
+	mockRepo.AssertNotCalled(t, "AddUserPlant", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "AddToFavorites", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestPlantService_UpdatePlant tests that updates are validated and applied to the plant in the URL
+func TestPlantService_UpdatePlant(t *testing.T) {
+	mockRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockRepo)
+
+	plantID := uuid.New()
+	plant := &models.Plant{
+		Name:           "Monstera",
+		ScientificName: "Monstera deliciosa",
+		Description:    "Swiss cheese plant",
+		ImageURL:       "https://example.com/monstera.jpg",
+	}
+	careInstructions := &models.CareInstructions{
+		WateringFrequency:   10,
+		Temperature:         models.TemperatureRange{Min: 18, Max: 27},
+		SoilType:            "Peat-based",
+		FertilizerFrequency: 30,
+	}
+	mockRepo.On("UpdatePlant", mock.Anything, mock.MatchedBy(func(p *models.Plant) bool {
+		return p.ID == plantID
+	}), careInstructions).Return(plant, nil)
+
+	_, err := plantService.UpdatePlant(context.Background(), plantID, plant, careInstructions)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	// Incomplete care instructions are rejected before anything is written
+	_, err = plantService.UpdatePlant(context.Background(), plantID, plant, &models.CareInstructions{})
+	assert.ErrorIs(t, err, ErrInvalidPlant)
+
+	// Unknown severities and a petFriendly flag contradicting the toxicity are rejected
+	unknown, mild := models.ToxicitySeverity("DEADLY"), models.ToxicitySeverityMild
+	plant.Toxicity = &models.Toxicity{Children: &unknown}
+	_, err = plantService.UpdatePlant(context.Background(), plantID, plant, careInstructions)
+	assert.ErrorIs(t, err, ErrInvalidPlant)
+	safe := true
+	plant.PetFriendly, plant.Toxicity = &safe, &models.Toxicity{Pets: &mild}
+	_, err = plantService.UpdatePlant(context.Background(), plantID, plant, careInstructions)
+	assert.ErrorIs(t, err, ErrInvalidPlant)
+	mockRepo.AssertNumberOfCalls(t, "UpdatePlant", 1)
+
+	// The toxicity to pets sets petFriendly when it is left out
+	plant.PetFriendly = nil
+	_, err = plantService.UpdatePlant(context.Background(), plantID, plant, careInstructions)
+	assert.NoError(t, err)
+	if assert.NotNil(t, plant.PetFriendly) {
+		assert.False(t, *plant.PetFriendly)
+	}
+}
+
+// TestPlantService_GetStaleCareInstructions tests the GetStaleCareInstructions method
+func TestPlantService_GetStaleCareInstructions(t *testing.T) {
+	// Create mock repository
+	mockRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockRepo)
+
+	// Test data
+	reviewed := time.Now().AddDate(-2, 0, 0)
+	plants := []*models.Plant{
+		{
+			ID:   uuid.New(),
+			Name: "Monstera",
+			CareInstructions: models.CareInstructions{
+				LastReviewedAt: &reviewed,
+			},
+		},
+	}
+
+	// Set up expectations: the cutoff must be roughly one year ago
+	mockRepo.On("GetPlantsWithCareReviewedBefore", mock.Anything, mock.MatchedBy(func(cutoff time.Time) bool {
+		expected := time.Now().AddDate(0, 0, -365)
+		return cutoff.Sub(expected) < time.Minute && expected.Sub(cutoff) < time.Minute
+	})).Return(plants, nil)
+
+	// Call the service
+	result, err := plantService.GetStaleCareInstructions(context.Background(), 365)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, plants[0].ID, result[0].ID)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestPlantService_GetStaleCareInstructions_InvalidAge tests that a non-positive age is rejected
+func TestPlantService_GetStaleCareInstructions_InvalidAge(t *testing.T) {
+	mockRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockRepo)
+
+	result, err := plantService.GetStaleCareInstructions(context.Background(), 0)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "GetPlantsWithCareReviewedBefore", mock.Anything, mock.Anything)
+}
+
+// TestPlantService_GetNicknameSuggestions tests that suggestions are stable and reflect care needs
+func TestPlantService_GetNicknameSuggestions(t *testing.T) {
+	mockRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockRepo)
+
+	userID := uuid.New()
+	plantID := uuid.New()
+	userPlant := &models.UserPlant{ID: uuid.New(), UserID: userID, PlantID: plantID}
+	plant := &models.Plant{
+		ID:   plantID,
+		Name: "Aloe Vera",
+		CareInstructions: models.CareInstructions{
+			Sunlight:          models.SunlightLevelHigh,
+			WateringFrequency: 21,
+		},
+	}
+
+	mockRepo.On("GetUserPlant", mock.Anything, userID, plantID).Return(userPlant, nil)
+	mockRepo.On("GetByID", mock.Anything, plantID).Return(plant, nil)
+
+	first, err := plantService.GetNicknameSuggestions(context.Background(), userID, plantID, models.LanguageEnglish)
+	assert.NoError(t, err)
+	second, err := plantService.GetNicknameSuggestions(context.Background(), userID, plantID, models.LanguageEnglish)
+	assert.NoError(t, err)
+
+	assert.Len(t, first.Nicknames, nicknameSuggestionCount)
+	assert.Equal(t, first.Nicknames, second.Nicknames)
+	assert.Contains(t, []string{"Sunny", "Goldie"}, first.Nicknames[0])
+	assert.Contains(t, []string{"Camel", "Dusty"}, first.Nicknames[1])
+}
+
+// TestPlantService_GetWateringRoute tests grouping of due plants by room in the user's room order
+func TestPlantService_GetWateringRoute(t *testing.T) {
+	mockRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockRepo)
+
+	userID := uuid.New()
+	now := time.Date(2024, time.May, 14, 10, 0, 0, 0, time.UTC)
+	overdue := now.AddDate(0, 0, -2)
+	dueToday := now.Add(6 * time.Hour)
+	tomorrow := now.AddDate(0, 0, 1)
+	kitchen, bedroom, hall := "Kitchen", "Bedroom", "Hall"
+
+	plants := []*models.Plant{
+		{ID: uuid.New(), Name: "Basil", Location: &kitchen, NextWatering: &dueToday},
+		{ID: uuid.New(), Name: "Mint", Location: &kitchen, NextWatering: &overdue},
+		{ID: uuid.New(), Name: "Fern", Location: &bedroom, NextWatering: &tomorrow},
+		{ID: uuid.New(), Name: "Ivy", Location: &hall, NextWatering: &overdue},
+		{ID: uuid.New(), Name: "Pothos", Location: &bedroom},
+		{ID: uuid.New(), Name: "Cactus", NextWatering: &overdue},
+	}
+	mockRepo.On("GetUserPlants", mock.Anything, userID).Return(plants, nil)
+
+	route, err := plantService.GetWateringRoute(context.Background(), userID, []string{"Bedroom", "Kitchen"}, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, route.TotalPlants)
+	assert.Len(t, route.Stops, 4)
+
+	// Listed rooms in the user's order, then unlisted rooms, then plants without a room
+	assert.Equal(t, "Bedroom", *route.Stops[0].Room)
+	assert.Equal(t, "Kitchen", *route.Stops[1].Room)
+	assert.Equal(t, "Hall", *route.Stops[2].Room)
+	assert.Nil(t, route.Stops[3].Room)
+	assert.Equal(t, 4, route.Stops[3].Step)
+
+	// The fern is not due today; the most overdue plant comes first
+	assert.Equal(t, "Pothos", route.Stops[0].Plants[0].Name)
+	assert.Len(t, route.Stops[0].Plants, 1)
+	assert.Equal(t, "Mint", route.Stops[1].Plants[0].Name)
+	assert.Equal(t, "Basil", route.Stops[1].Plants[1].Name)
+}
+
+// TestPlantService_GetFavoriteChanges tests that a sync lists every favorite the first time and the
+// changes since shortly before the last sync afterwards
+func TestPlantService_GetFavoriteChanges(t *testing.T) {
+	mockRepo := new(MockPlantRepository)
+	service := NewPlantService(mockRepo)
+	ctx := context.Background()
+	userID := uuid.New()
+	lastSync := time.Date(2024, time.May, 10, 9, 0, 0, 0, time.UTC)
+
+	all := &models.FavoriteChanges{Added: []uuid.UUID{uuid.New()}, Removed: []uuid.UUID{}, Until: lastSync}
+	mockRepo.On("GetFavoriteChanges", ctx, userID, time.Time{}).Return(all, nil).Once()
+	changes, err := service.GetFavoriteChanges(ctx, userID, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, all, changes)
+
+	since := &models.FavoriteChanges{Added: []uuid.UUID{}, Removed: all.Added, Until: lastSync.Add(time.Hour)}
+	mockRepo.On("GetFavoriteChanges", ctx, userID, lastSync.Add(-favoriteSyncOverlap)).Return(since, nil).Once()
+	changes, err = service.GetFavoriteChanges(ctx, userID, lastSync)
+	assert.NoError(t, err)
+	assert.Equal(t, since, changes)
+	mockRepo.AssertExpectations(t)
+}
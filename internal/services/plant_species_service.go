@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/db/pgerror"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidSpecies is returned when a species or its default care instructions are incomplete
+	ErrInvalidSpecies = errors.New("invalid species")
+
+	// ErrSpeciesExists is returned when another species has the same scientific name
+	ErrSpeciesExists = errors.New("a species with this scientific name already exists")
+)
+
+// ListSpecies gets all species of the catalog with their default care instructions
+func (s *PlantService) ListSpecies(ctx context.Context) ([]*models.PlantSpecies, error) {
+	species, err := s.speciesRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list species: %w", err)
+	}
+	return species, nil
+}
+
+// GetSpecies gets a species with its default care instructions
+func (s *PlantService) GetSpecies(ctx context.Context, speciesID uuid.UUID) (*models.PlantSpecies, error) {
+	species, err := s.speciesRepo.GetByID(ctx, speciesID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get species: %w", err)
+	}
+	return species, nil
+}
+
+// CreateSpecies creates a species whose care instructions become the defaults of its cultivars. A
+// species created meanwhile with the same scientific name is reported as when it was found by the check.
+func (s *PlantService) CreateSpecies(ctx context.Context, req *models.PlantSpeciesRequest) (*models.PlantSpecies, error) {
+	species := &models.PlantSpecies{
+		ScientificName:   strings.TrimSpace(req.ScientificName),
+		Family:           req.Family,
+		CareInstructions: req.CareInstructions,
+	}
+	if err := s.checkSpecies(ctx, species); err != nil {
+		return nil, err
+	}
+
+	if err := s.speciesRepo.Create(ctx, species); err != nil {
+		if errors.Is(pgerror.Map(err), pgerror.ErrConflict) {
+			return nil, ErrSpeciesExists
+		}
+		return nil, fmt.Errorf("failed to create species: %w", err)
+	}
+	return species, nil
+}
+
+// UpdateSpecies replaces a species and its default care instructions. The care instructions of its
+// cultivars are resolved again, so fields they do not override follow the new defaults.
+func (s *PlantService) UpdateSpecies(ctx context.Context, speciesID uuid.UUID, req *models.PlantSpeciesRequest) (*models.PlantSpecies, error) {
+	species := &models.PlantSpecies{
+		ID:               speciesID,
+		ScientificName:   strings.TrimSpace(req.ScientificName),
+		Family:           req.Family,
+		CareInstructions: req.CareInstructions,
+	}
+	if err := s.checkSpecies(ctx, species); err != nil {
+		return nil, err
+	}
+
+	if err := s.speciesRepo.Update(ctx, species); err != nil {
+		if errors.Is(pgerror.Map(err), pgerror.ErrConflict) {
+			return nil, ErrSpeciesExists
+		}
+		return nil, fmt.Errorf("failed to update species: %w", err)
+	}
+	return species, nil
+}
+
+// checkSpecies checks that a species is complete and its scientific name is not taken by another one
+func (s *PlantService) checkSpecies(ctx context.Context, species *models.PlantSpecies) error {
+	if species.ScientificName == "" {
+		return fmt.Errorf("%w: scientific name is required", ErrInvalidSpecies)
+	}
+	if err := validateCareInstructions(&species.CareInstructions, ErrInvalidSpecies); err != nil {
+		return err
+	}
+
+	existing, err := s.speciesRepo.GetByScientificName(ctx, species.ScientificName)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get species: %w", err)
+	case existing.ID != species.ID:
+		return ErrSpeciesExists
+	}
+	return nil
+}
+
+// resolveCareInstructions returns the care instructions a catalog plant is saved with: the given
+// ones for a standalone plant, the defaults of its species with its overrides applied for a cultivar
+func (s *PlantService) resolveCareInstructions(
+	ctx context.Context,
+	plant *models.Plant,
+	careInstructions *models.CareInstructions,
+) (*models.CareInstructions, error) {
+	if plant.SpeciesID == nil {
+		plant.CareOverrides = nil
+		return careInstructions, nil
+	}
+	if s.speciesRepo == nil {
+		return nil, fmt.Errorf("%w: species are not available", ErrInvalidPlant)
+	}
+
+	species, err := s.speciesRepo.GetByID(ctx, *plant.SpeciesID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: species not found", ErrInvalidPlant)
+		}
+		return nil, fmt.Errorf("failed to get species: %w", err)
+	}
+
+	var overrides models.CareOverrides
+	if plant.CareOverrides != nil {
+		overrides = *plant.CareOverrides
+	}
+	resolved := overrides.Apply(species.CareInstructions)
+	return &resolved, nil
+}
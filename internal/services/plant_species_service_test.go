@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPlantSpeciesRepository is a mock implementation of the PlantSpeciesRepository interface
+type MockPlantSpeciesRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlantSpeciesRepository) Create(ctx context.Context, species *models.PlantSpecies) error {
+	args := m.Called(ctx, species)
+	return args.Error(0)
+}
+
+func (m *MockPlantSpeciesRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PlantSpecies, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PlantSpecies), args.Error(1)
+}
+
+func (m *MockPlantSpeciesRepository) GetByScientificName(ctx context.Context, scientificName string) (*models.PlantSpecies, error) {
+	args := m.Called(ctx, scientificName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PlantSpecies), args.Error(1)
+}
+
+func (m *MockPlantSpeciesRepository) List(ctx context.Context) ([]*models.PlantSpecies, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.PlantSpecies), args.Error(1)
+}
+
+func (m *MockPlantSpeciesRepository) Update(ctx context.Context, species *models.PlantSpecies) error {
+	args := m.Called(ctx, species)
+	return args.Error(0)
+}
+
+// monsteraCare returns the default care instructions of Monstera deliciosa used by the species tests
+func monsteraCare() models.CareInstructions {
+	return models.CareInstructions{
+		ID:                  uuid.New(),
+		WateringFrequency:   7,
+		Sunlight:            models.SunlightLevelMedium,
+		Temperature:         models.TemperatureRange{Min: 18, Max: 27},
+		Humidity:            models.HumidityLevelHigh,
+		SoilType:            "Aroid mix",
+		FertilizerFrequency: 30,
+		AdditionalNotes:     "Wipe the leaves",
+	}
+}
+
+// TestPlantService_CreatePlant_Cultivar tests that a cultivar gets the care instructions of its species with its overrides applied
+func TestPlantService_CreatePlant_Cultivar(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockSpeciesRepo := new(MockPlantSpeciesRepository)
+	plantService := NewPlantService(mockPlantRepo)
+	plantService.SetSpeciesRepository(mockSpeciesRepo)
+	ctx := context.Background()
+
+	speciesID := uuid.New()
+	mockSpeciesRepo.On("GetByID", ctx, speciesID).Return(&models.PlantSpecies{ID: speciesID, CareInstructions: monsteraCare()}, nil)
+	mockPlantRepo.On("Search", ctx, mock.Anything).Return([]*models.Plant{}, nil)
+
+	// Variegated leaves need more light
+	high := models.SunlightLevelHigh
+	plant := &models.Plant{
+		Name:           "Monstera Variegata",
+		ScientificName: "Monstera deliciosa 'Variegata'",
+		Description:    "Variegated monstera",
+		ImageURL:       "plants/monstera-variegata.jpg",
+		SpeciesID:      &speciesID,
+		CareOverrides:  &models.CareOverrides{Sunlight: &high},
+	}
+	mockPlantRepo.On("CreatePlant", ctx, plant, mock.MatchedBy(func(c *models.CareInstructions) bool {
+		return c.ID == uuid.Nil && c.Sunlight == models.SunlightLevelHigh &&
+			c.WateringFrequency == 7 && c.SoilType == "Aroid mix"
+	})).Return(plant, nil).Once()
+
+	// The care instructions of the request are ignored for cultivars
+	_, _, err := plantService.CreatePlant(ctx, plant, &models.CareInstructions{})
+	assert.NoError(t, err)
+	mockPlantRepo.AssertExpectations(t)
+
+	// Cultivars of unknown species are rejected
+	unknownID := uuid.New()
+	mockSpeciesRepo.On("GetByID", ctx, unknownID).Return(nil, fmt.Errorf("species not found: %w", sql.ErrNoRows))
+	plant.SpeciesID = &unknownID
+	_, _, err = plantService.CreatePlant(ctx, plant, &models.CareInstructions{})
+	assert.ErrorIs(t, err, ErrInvalidPlant)
+}
+
+// TestPlantService_CreateSpecies tests that species need complete care instructions and a scientific name of their own
+func TestPlantService_CreateSpecies(t *testing.T) {
+	mockSpeciesRepo := new(MockPlantSpeciesRepository)
+	plantService := NewPlantService(new(MockPlantRepository))
+	plantService.SetSpeciesRepository(mockSpeciesRepo)
+	ctx := context.Background()
+
+	mockSpeciesRepo.On("GetByScientificName", ctx, "Monstera deliciosa").Return(nil, fmt.Errorf("species not found: %w", sql.ErrNoRows)).Once()
+	mockSpeciesRepo.On("Create", ctx, mock.MatchedBy(func(s *models.PlantSpecies) bool {
+		return s.ScientificName == "Monstera deliciosa" && s.CareInstructions.SoilType == "Aroid mix"
+	})).Return(nil).Once()
+
+	req := &models.PlantSpeciesRequest{ScientificName: " Monstera deliciosa ", CareInstructions: monsteraCare()}
+	_, err := plantService.CreateSpecies(ctx, req)
+	assert.NoError(t, err)
+
+	mockSpeciesRepo.On("GetByScientificName", ctx, "Monstera deliciosa").Return(&models.PlantSpecies{ID: uuid.New()}, nil)
+	_, err = plantService.CreateSpecies(ctx, req)
+	assert.ErrorIs(t, err, ErrSpeciesExists)
+
+	_, err = plantService.CreateSpecies(ctx, &models.PlantSpeciesRequest{ScientificName: "Ficus elastica"})
+	assert.ErrorIs(t, err, ErrInvalidSpecies)
+	mockSpeciesRepo.AssertExpectations(t)
+}
+
+// TestCareOverrides_Apply tests that overridden fields replace the species defaults and the others are inherited
+func TestCareOverrides_Apply(t *testing.T) {
+	defaults := monsteraCare()
+	watering := 10
+	notes := ""
+
+	resolved := models.CareOverrides{WateringFrequency: &watering, AdditionalNotes: &notes}.Apply(defaults)
+	assert.Equal(t, 10, resolved.WateringFrequency)
+	assert.Equal(t, "", resolved.AdditionalNotes)
+	assert.Equal(t, defaults.Sunlight, resolved.Sunlight)
+	assert.Equal(t, defaults.Temperature, resolved.Temperature)
+	assert.Equal(t, uuid.Nil, resolved.ID)
+
+	// The drought tolerance of the species is dropped for a cultivar watered outside it
+	minWatering, maxWatering := 5, 9
+	defaults.WateringFrequencyMin, defaults.WateringFrequencyMax = &minWatering, &maxWatering
+	resolved = models.CareOverrides{WateringFrequency: &watering}.Apply(defaults)
+	assert.Nil(t, resolved.WateringFrequencyMin)
+	assert.Nil(t, resolved.WateringFrequencyMax)
+	watering = 8
+	resolved = models.CareOverrides{WateringFrequency: &watering}.Apply(defaults)
+	assert.Equal(t, &maxWatering, resolved.WateringFrequencyMax)
+}
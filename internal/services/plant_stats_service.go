@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// minPlantStatsOwners is the fewest owners the averages of a plant are shown for, so they never
+	// give away the habits of a few users
+	minPlantStatsOwners = 5
+
+	// plantStatsWateringWindow is how far back waterings are averaged
+	plantStatsWateringWindow = 180 * 24 * time.Hour
+
+	// plantStatsActiveWindow is how long a plant is owned, and how recently it must have been watered,
+	// to count as surviving
+	plantStatsActiveWindow = 30 * 24 * time.Hour
+)
+
+// PlantStatsService aggregates anonymized ownership statistics of catalog plants, shown on plant
+// pages as signals for buyers
+type PlantStatsService struct {
+	statsRepo repository.PlantStatsRepository
+	now       func() time.Time
+}
+
+// NewPlantStatsService creates a new plant stats service
+func NewPlantStatsService(statsRepo repository.PlantStatsRepository) *PlantStatsService {
+	return &PlantStatsService{
+		statsRepo: statsRepo,
+		now:       time.Now,
+	}
+}
+
+// Refresh aggregates the statistics of every catalog plant and returns the number of plants aggregated
+func (s *PlantStatsService) Refresh(ctx context.Context) (int, error) {
+	now := s.now()
+	aggregated, err := s.statsRepo.Refresh(ctx, now.Add(-plantStatsWateringWindow), now.Add(-plantStatsActiveWindow), now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh plant stats: %w", err)
+	}
+	return aggregated, nil
+}
+
+// GetPlantStats gets the statistics of a plant. The averages of a plant with fewer than
+// minPlantStatsOwners owners are left out.
+func (s *PlantStatsService) GetPlantStats(ctx context.Context, plantID uuid.UUID) (*models.PlantStats, error) {
+	stats, err := s.statsRepo.GetByPlant(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant stats: %w", err)
+	}
+
+	if stats.Owners < minPlantStatsOwners {
+		stats.WateringIntervalDays = nil
+		stats.SurvivalRate = nil
+	}
+	return stats, nil
+}
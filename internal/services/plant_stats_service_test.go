@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPlantStatsRepository is a mock implementation of the PlantStatsRepository interface
+type MockPlantStatsRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlantStatsRepository) Refresh(ctx context.Context, wateringSince time.Time, activeSince time.Time, computedAt time.Time) (int, error) {
+	args := m.Called(ctx, wateringSince, activeSince, computedAt)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPlantStatsRepository) GetByPlant(ctx context.Context, plantID uuid.UUID) (*models.PlantStats, error) {
+	args := m.Called(ctx, plantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PlantStats), args.Error(1)
+}
+
+// TestPlantStatsService_Refresh tests the windows waterings and surviving plants are aggregated over
+func TestPlantStatsService_Refresh(t *testing.T) {
+	mockStatsRepo := new(MockPlantStatsRepository)
+	service := NewPlantStatsService(mockStatsRepo)
+	now := time.Date(2024, time.May, 13, 2, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	mockStatsRepo.On("Refresh", mock.Anything, now.AddDate(0, 0, -180), now.AddDate(0, 0, -30), now).Return(42, nil).Once()
+
+	aggregated, err := service.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 42, aggregated)
+	mockStatsRepo.AssertExpectations(t)
+}
+
+// TestPlantStatsService_GetPlantStats tests that averages are left out while a plant has few owners
+func TestPlantStatsService_GetPlantStats(t *testing.T) {
+	mockStatsRepo := new(MockPlantStatsRepository)
+	service := NewPlantStatsService(mockStatsRepo)
+
+	interval, survival := 6.5, 0.8
+	popularID, rareID := uuid.New(), uuid.New()
+	mockStatsRepo.On("GetByPlant", mock.Anything, popularID).Return(&models.PlantStats{PlantID: popularID, Owners: minPlantStatsOwners, WateringIntervalDays: &interval, SurvivalRate: &survival}, nil)
+	mockStatsRepo.On("GetByPlant", mock.Anything, rareID).Return(&models.PlantStats{PlantID: rareID, Owners: 2, WateringIntervalDays: &interval, SurvivalRate: &survival}, nil)
+
+	stats, err := service.GetPlantStats(context.Background(), popularID)
+	require.NoError(t, err)
+	assert.Equal(t, &interval, stats.WateringIntervalDays)
+	assert.Equal(t, &survival, stats.SurvivalRate)
+
+	stats, err = service.GetPlantStats(context.Background(), rareID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Owners)
+	assert.Nil(t, stats.WateringIntervalDays)
+	assert.Nil(t, stats.SurvivalRate)
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/anpanovv/planter/internal/db/pgerror"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTaxonomyUnavailable is returned when plants cannot be put in categories or tagged
+	ErrTaxonomyUnavailable = errors.New("plant categories and tags are not available")
+
+	// ErrInvalidCategory is returned when a category slug or name is not usable
+	ErrInvalidCategory = errors.New("invalid category")
+
+	// ErrCategoryExists is returned when another category has the same slug
+	ErrCategoryExists = errors.New("a category with this slug already exists")
+
+	// ErrUnknownCategory is returned when a plant is put in a category that does not exist
+	ErrUnknownCategory = errors.New("unknown category")
+
+	// ErrInvalidTag is returned when a tag is empty, too long or has characters tags do not have
+	ErrInvalidTag = errors.New("invalid tag")
+)
+
+// maxTaxonomyNameLength is the longest category slug or tag
+const maxTaxonomyNameLength = 50
+
+// SetTaxonomyRepository lets catalog plants be put in categories and tagged
+func (s *PlantService) SetTaxonomyRepository(taxonomyRepo repository.PlantTaxonomyRepository) {
+	s.taxonomyRepo = taxonomyRepo
+}
+
+// ListCategories gets all categories by name with the number of published plants in each
+func (s *PlantService) ListCategories(ctx context.Context) ([]*models.Category, error) {
+	if s.taxonomyRepo == nil {
+		return nil, ErrTaxonomyUnavailable
+	}
+	categories, err := s.taxonomyRepo.ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	return categories, nil
+}
+
+// CreateCategory creates a category with a slug no other category has. A category created
+// meanwhile with the same slug is reported as when it was found by the check.
+func (s *PlantService) CreateCategory(ctx context.Context, req *models.CategoryRequest) (*models.Category, error) {
+	if s.taxonomyRepo == nil {
+		return nil, ErrTaxonomyUnavailable
+	}
+	category, err := s.checkCategory(ctx, uuid.Nil, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taxonomyRepo.CreateCategory(ctx, category); err != nil {
+		if errors.Is(pgerror.Map(err), pgerror.ErrConflict) {
+			return nil, ErrCategoryExists
+		}
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	return category, nil
+}
+
+// UpdateCategory renames a category; plants in it stay in it
+func (s *PlantService) UpdateCategory(ctx context.Context, categoryID uuid.UUID, req *models.CategoryRequest) (*models.Category, error) {
+	if s.taxonomyRepo == nil {
+		return nil, ErrTaxonomyUnavailable
+	}
+	category, err := s.checkCategory(ctx, categoryID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taxonomyRepo.UpdateCategory(ctx, category); err != nil {
+		if errors.Is(pgerror.Map(err), pgerror.ErrConflict) {
+			return nil, ErrCategoryExists
+		}
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category, taking it off its plants
+func (s *PlantService) DeleteCategory(ctx context.Context, categoryID uuid.UUID) error {
+	if s.taxonomyRepo == nil {
+		return ErrTaxonomyUnavailable
+	}
+	if err := s.taxonomyRepo.DeleteCategory(ctx, categoryID); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	return nil
+}
+
+// checkCategory normalizes the slug of a category request and checks that no other category has it
+func (s *PlantService) checkCategory(ctx context.Context, categoryID uuid.UUID, req *models.CategoryRequest) (*models.Category, error) {
+	slug, ok := normalizeTaxonomyName(req.Slug)
+	if !ok {
+		return nil, fmt.Errorf("%w: slug %q has characters other than letters, digits and hyphens", ErrInvalidCategory, req.Slug)
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidCategory)
+	}
+
+	existing, err := s.taxonomyRepo.GetCategoryBySlug(ctx, slug)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	case existing.ID != categoryID:
+		return nil, ErrCategoryExists
+	}
+	return &models.Category{ID: categoryID, Slug: slug, Name: name}, nil
+}
+
+// ListTags gets the tags of published plants, the most used first
+func (s *PlantService) ListTags(ctx context.Context) ([]*models.TagCount, error) {
+	if s.taxonomyRepo == nil {
+		return nil, ErrTaxonomyUnavailable
+	}
+	tags, err := s.taxonomyRepo.ListTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return tags, nil
+}
+
+// GetPlantTaxonomy gets the category slugs and tags of a catalog plant
+func (s *PlantService) GetPlantTaxonomy(ctx context.Context, plantID uuid.UUID) (*models.PlantTaxonomy, error) {
+	if s.taxonomyRepo == nil {
+		return nil, ErrTaxonomyUnavailable
+	}
+	taxonomy, err := s.taxonomyRepo.GetPlantTaxonomy(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant taxonomy: %w", err)
+	}
+	return taxonomy, nil
+}
+
+// SetPlantTaxonomy replaces the categories and tags of a catalog plant. Categories must exist; tags
+// are normalized and created when no plant had them before.
+func (s *PlantService) SetPlantTaxonomy(ctx context.Context, plantID uuid.UUID, req *models.PlantTaxonomy) (*models.PlantTaxonomy, error) {
+	if s.taxonomyRepo == nil {
+		return nil, ErrTaxonomyUnavailable
+	}
+
+	// Check the categories
+	categories, err := normalizeTaxonomyNames(req.Categories)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownCategory, err)
+	}
+	for _, slug := range categories {
+		if _, err := s.taxonomyRepo.GetCategoryBySlug(ctx, slug); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownCategory, slug)
+			}
+			return nil, fmt.Errorf("failed to get category: %w", err)
+		}
+	}
+
+	// Normalize the tags
+	tags, err := normalizeTaxonomyNames(req.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTag, err)
+	}
+
+	taxonomy := &models.PlantTaxonomy{Categories: categories, Tags: tags}
+	if err := s.taxonomyRepo.SetPlantTaxonomy(ctx, plantID, taxonomy); err != nil {
+		return nil, fmt.Errorf("failed to set plant taxonomy: %w", err)
+	}
+	return taxonomy, nil
+}
+
+// normalizeTaxonomyNames normalizes category slugs or tags, dropping duplicates. It never returns nil,
+// so an empty list clears what a plant had.
+func normalizeTaxonomyNames(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		n, ok := normalizeTaxonomyName(name)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a valid name", name)
+		}
+		if !seen[n] {
+			seen[n] = true
+			normalized = append(normalized, n)
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeTaxonomyName lowercases a category slug or tag and joins its words with hyphens, so
+// "Pet safe" and "pet_safe" are both "pet-safe". It reports false for names that are empty, too long
+// or have characters other than letters, digits and word separators.
+func normalizeTaxonomyName(name string) (string, bool) {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-' || r == '\t'
+	})
+	normalized := strings.Join(words, "-")
+	if normalized == "" || len([]rune(normalized)) > maxTaxonomyNameLength {
+		return "", false
+	}
+	for _, r := range normalized {
+		if r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "", false
+		}
+	}
+	return normalized, true
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPlantTaxonomyRepository is a mock implementation of the PlantTaxonomyRepository interface
+type MockPlantTaxonomyRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlantTaxonomyRepository) ListCategories(ctx context.Context) ([]*models.Category, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.Category), args.Error(1)
+}
+
+func (m *MockPlantTaxonomyRepository) GetCategoryBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Category), args.Error(1)
+}
+
+func (m *MockPlantTaxonomyRepository) CreateCategory(ctx context.Context, category *models.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockPlantTaxonomyRepository) UpdateCategory(ctx context.Context, category *models.Category) error {
+	args := m.Called(ctx, category)
+	return args.Error(0)
+}
+
+func (m *MockPlantTaxonomyRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPlantTaxonomyRepository) ListTags(ctx context.Context) ([]*models.TagCount, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.TagCount), args.Error(1)
+}
+
+func (m *MockPlantTaxonomyRepository) GetPlantTaxonomy(ctx context.Context, plantID uuid.UUID) (*models.PlantTaxonomy, error) {
+	args := m.Called(ctx, plantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PlantTaxonomy), args.Error(1)
+}
+
+func (m *MockPlantTaxonomyRepository) SetPlantTaxonomy(ctx context.Context, plantID uuid.UUID, taxonomy *models.PlantTaxonomy) error {
+	args := m.Called(ctx, plantID, taxonomy)
+	return args.Error(0)
+}
+
+func TestNormalizeTaxonomyName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{name: "pet-safe", want: "pet-safe", ok: true},
+		{name: " Pet safe ", want: "pet-safe", ok: true},
+		{name: "air_purifying", want: "air-purifying", ok: true},
+		{name: "Low--light", want: "low-light", ok: true},
+		{name: "суккулент", want: "суккулент", ok: true},
+		{name: " - ", ok: false},
+		{name: "pet/safe", ok: false},
+		{name: strings.Repeat("a", 51), ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := normalizeTaxonomyName(tt.name)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+}
+
+func TestPlantService_CreateCategory(t *testing.T) {
+	mockTaxonomyRepo := new(MockPlantTaxonomyRepository)
+	service := NewPlantService(new(MockPlantRepository))
+	service.SetTaxonomyRepository(mockTaxonomyRepo)
+	ctx := context.Background()
+
+	mockTaxonomyRepo.On("GetCategoryBySlug", ctx, "flowering-plants").Return(nil, sql.ErrNoRows).Once()
+	mockTaxonomyRepo.On("CreateCategory", ctx, mock.AnythingOfType("*models.Category")).Return(nil)
+	category, err := service.CreateCategory(ctx, &models.CategoryRequest{Slug: "Flowering plants", Name: " Flowering plants "})
+	require.NoError(t, err)
+	assert.Equal(t, "flowering-plants", category.Slug)
+	assert.Equal(t, "Flowering plants", category.Name)
+
+	mockTaxonomyRepo.On("GetCategoryBySlug", ctx, "flowering-plants").Return(&models.Category{ID: uuid.New()}, nil)
+	_, err = service.CreateCategory(ctx, &models.CategoryRequest{Slug: "flowering-plants", Name: "Flowering"})
+	assert.ErrorIs(t, err, ErrCategoryExists)
+
+	_, err = service.CreateCategory(ctx, &models.CategoryRequest{Slug: "ferns!", Name: "Ferns"})
+	assert.ErrorIs(t, err, ErrInvalidCategory)
+	mockTaxonomyRepo.AssertNumberOfCalls(t, "CreateCategory", 1)
+}
+
+// TestPlantService_CreateCategory_Concurrent tests that a category created with the same slug after
+// the check is reported as existing
+func TestPlantService_CreateCategory_Concurrent(t *testing.T) {
+	mockTaxonomyRepo := new(MockPlantTaxonomyRepository)
+	service := NewPlantService(new(MockPlantRepository))
+	service.SetTaxonomyRepository(mockTaxonomyRepo)
+	ctx := context.Background()
+
+	uniqueViolation := &pq.Error{Code: "23505", Constraint: "categories_slug_key"}
+	mockTaxonomyRepo.On("GetCategoryBySlug", ctx, "ferns").Return(nil, sql.ErrNoRows)
+	mockTaxonomyRepo.On("CreateCategory", ctx, mock.AnythingOfType("*models.Category")).
+		Return(fmt.Errorf("failed to create category: %w", uniqueViolation))
+	_, err := service.CreateCategory(ctx, &models.CategoryRequest{Slug: "ferns", Name: "Ferns"})
+	assert.ErrorIs(t, err, ErrCategoryExists)
+}
+
+func TestPlantService_SetPlantTaxonomy(t *testing.T) {
+	mockTaxonomyRepo := new(MockPlantTaxonomyRepository)
+	service := NewPlantService(new(MockPlantRepository))
+	service.SetTaxonomyRepository(mockTaxonomyRepo)
+	ctx := context.Background()
+	plantID := uuid.New()
+
+	mockTaxonomyRepo.On("GetCategoryBySlug", ctx, "fern").Return(&models.Category{ID: uuid.New(), Slug: "fern"}, nil)
+	mockTaxonomyRepo.On("SetPlantTaxonomy", ctx, plantID, &models.PlantTaxonomy{
+		Categories: []string{"fern"},
+		Tags:       []string{"pet-safe", "air-purifying"},
+	}).Return(nil)
+	taxonomy, err := service.SetPlantTaxonomy(ctx, plantID, &models.PlantTaxonomy{
+		Categories: []string{"Fern"},
+		Tags:       []string{"Pet safe", "air_purifying", "pet-safe"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pet-safe", "air-purifying"}, taxonomy.Tags)
+
+	mockTaxonomyRepo.On("GetCategoryBySlug", ctx, "cactus").Return(nil, sql.ErrNoRows)
+	_, err = service.SetPlantTaxonomy(ctx, plantID, &models.PlantTaxonomy{Categories: []string{"cactus"}})
+	assert.ErrorIs(t, err, ErrUnknownCategory)
+
+	_, err = service.SetPlantTaxonomy(ctx, plantID, &models.PlantTaxonomy{Tags: []string{"pet/safe"}})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	mockTaxonomyRepo.AssertNumberOfCalls(t, "SetPlantTaxonomy", 1)
+}
+
+func TestPlantService_SetPlantTaxonomy_Unavailable(t *testing.T) {
+	service := NewPlantService(new(MockPlantRepository))
+
+	_, err := service.SetPlantTaxonomy(context.Background(), uuid.New(), &models.PlantTaxonomy{})
+	assert.ErrorIs(t, err, ErrTaxonomyUnavailable)
+}
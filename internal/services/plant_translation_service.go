@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPlantTranslationsUnavailable is returned when no repository is configured for plant translations
+	ErrPlantTranslationsUnavailable = errors.New("plant translations are not available")
+
+	// ErrInvalidPlantTranslation is returned for translations into the catalog's own or an unknown
+	// language, or translating no field
+	ErrInvalidPlantTranslation = errors.New("invalid plant translation")
+)
+
+// CatalogLanguage is the language the catalog is written in; plants are translated from it
+const CatalogLanguage = models.LanguageRussian
+
+// acceptLanguages maps the primary language tags of Accept-Language to the languages of the app
+var acceptLanguages = map[string]models.Language{
+	"ru": models.LanguageRussian,
+	"en": models.LanguageEnglish,
+}
+
+// ParseAcceptLanguage picks the language of the app an Accept-Language header prefers most, by the
+// quality of its tags and then their order. It reports false when no tag names a language of the app.
+func ParseAcceptLanguage(header string) (models.Language, bool) {
+	type candidate struct {
+		language models.Language
+		quality  float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		language, ok := acceptLanguages[primary]
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{language: language, quality: quality})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].language, true
+}
+
+// SetTranslationRepository sets the repository of the translations plants are shown in
+func (s *PlantService) SetTranslationRepository(translationRepo repository.PlantTranslationRepository) {
+	s.translationRepo = translationRepo
+}
+
+// LocalizePlants shows plants with the fields of their translation into a language. Plants without a
+// translation, and every plant when the translations cannot be read, are left as in the catalog.
+func (s *PlantService) LocalizePlants(ctx context.Context, language models.Language, plants ...*models.Plant) {
+	if s.translationRepo == nil || language == CatalogLanguage || len(plants) == 0 {
+		return
+	}
+
+	plantIDs := make([]uuid.UUID, 0, len(plants))
+	for _, plant := range plants {
+		plantIDs = append(plantIDs, plant.ID)
+	}
+	translations, err := s.translationRepo.GetForPlants(ctx, plantIDs, language)
+	if err != nil {
+		log.Printf("Failed to get plant translations into %s: %v", language, err)
+		return
+	}
+	for _, plant := range plants {
+		if translation, ok := translations[plant.ID]; ok {
+			translation.Apply(plant)
+		}
+	}
+}
+
+// GetPlantTranslations gets the translations of a plant by language
+func (s *PlantService) GetPlantTranslations(ctx context.Context, plantID uuid.UUID) ([]*models.PlantTranslation, error) {
+	if s.translationRepo == nil {
+		return nil, ErrPlantTranslationsUnavailable
+	}
+	if _, err := s.plantRepo.GetByID(ctx, plantID); err != nil {
+		return nil, fmt.Errorf("failed to get plant: %w", err)
+	}
+	translations, err := s.translationRepo.List(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant translations: %w", err)
+	}
+	return translations, nil
+}
+
+// SetPlantTranslation sets the translation of a plant into a language; blank fields are shown as in
+// the catalog
+func (s *PlantService) SetPlantTranslation(ctx context.Context, plantID uuid.UUID, language models.Language, req *models.PlantTranslationRequest) (*models.PlantTranslation, error) {
+	if s.translationRepo == nil {
+		return nil, ErrPlantTranslationsUnavailable
+	}
+	if err := checkTranslationLanguage(language); err != nil {
+		return nil, err
+	}
+
+	translation := &models.PlantTranslation{
+		PlantID:         plantID,
+		Language:        language,
+		Name:            translatedField(req.Name),
+		Description:     translatedField(req.Description),
+		AdditionalNotes: translatedField(req.AdditionalNotes),
+	}
+	if translation.Name == nil && translation.Description == nil && translation.AdditionalNotes == nil {
+		return nil, fmt.Errorf("%w: no field is translated", ErrInvalidPlantTranslation)
+	}
+
+	if err := s.translationRepo.Upsert(ctx, translation); err != nil {
+		return nil, err
+	}
+	return translation, nil
+}
+
+// DeletePlantTranslation deletes the translation of a plant into a language, so the plant is shown
+// in it as in the catalog
+func (s *PlantService) DeletePlantTranslation(ctx context.Context, plantID uuid.UUID, language models.Language) error {
+	if s.translationRepo == nil {
+		return ErrPlantTranslationsUnavailable
+	}
+	if err := checkTranslationLanguage(language); err != nil {
+		return err
+	}
+	return s.translationRepo.Delete(ctx, plantID, language)
+}
+
+// checkTranslationLanguage checks that plants can be translated into a language
+func checkTranslationLanguage(language models.Language) error {
+	if language != models.LanguageRussian && language != models.LanguageEnglish {
+		return fmt.Errorf("%w: unknown language %q", ErrInvalidPlantTranslation, language)
+	}
+	if language == CatalogLanguage {
+		return fmt.Errorf("%w: the catalog is written in %s", ErrInvalidPlantTranslation, language)
+	}
+	return nil
+}
+
+// translatedField trims a translated field; a blank field is not translated
+func translatedField(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
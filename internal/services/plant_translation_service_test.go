@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPlantTranslationRepository is a mock implementation of the PlantTranslationRepository interface
+type MockPlantTranslationRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlantTranslationRepository) List(ctx context.Context, plantID uuid.UUID) ([]*models.PlantTranslation, error) {
+	args := m.Called(ctx, plantID)
+	return args.Get(0).([]*models.PlantTranslation), args.Error(1)
+}
+
+func (m *MockPlantTranslationRepository) GetForPlants(ctx context.Context, plantIDs []uuid.UUID, language models.Language) (map[uuid.UUID]*models.PlantTranslation, error) {
+	args := m.Called(ctx, plantIDs, language)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID]*models.PlantTranslation), args.Error(1)
+}
+
+func (m *MockPlantTranslationRepository) Upsert(ctx context.Context, translation *models.PlantTranslation) error {
+	args := m.Called(ctx, translation)
+	return args.Error(0)
+}
+
+func (m *MockPlantTranslationRepository) Delete(ctx context.Context, plantID uuid.UUID, language models.Language) error {
+	args := m.Called(ctx, plantID, language)
+	return args.Error(0)
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   models.Language
+		ok     bool
+	}{
+		{header: "en-US,en;q=0.9", want: models.LanguageEnglish, ok: true},
+		{header: "ru-RU,ru;q=0.9,en;q=0.8", want: models.LanguageRussian, ok: true},
+		{header: "de-DE,de;q=0.9,en;q=0.7,ru;q=0.5", want: models.LanguageEnglish, ok: true},
+		{header: "ru;q=0.4, EN;q=0.6", want: models.LanguageEnglish, ok: true},
+		{header: "en;q=0,ru", want: models.LanguageRussian, ok: true},
+		{header: "de, fr;q=0.5, *", ok: false},
+		{header: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			language, ok := ParseAcceptLanguage(tt.header)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, language)
+		})
+	}
+}
+
+func TestPlantService_LocalizePlants(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockTranslationRepo := new(MockPlantTranslationRepository)
+	service := NewPlantService(mockPlantRepo)
+	service.SetTranslationRepository(mockTranslationRepo)
+
+	name, notes := "Money plant", "Turn the pot weekly"
+	translated := &models.Plant{ID: uuid.New(), Name: "Пилея", Description: "Китайское денежное дерево"}
+	untranslated := &models.Plant{ID: uuid.New(), Name: "Фикус"}
+	mockTranslationRepo.On("GetForPlants", mock.Anything, []uuid.UUID{translated.ID, untranslated.ID}, models.LanguageEnglish).
+		Return(map[uuid.UUID]*models.PlantTranslation{
+			translated.ID: {PlantID: translated.ID, Language: models.LanguageEnglish, Name: &name, AdditionalNotes: &notes},
+		}, nil)
+
+	service.LocalizePlants(context.Background(), models.LanguageEnglish, translated, untranslated)
+	assert.Equal(t, "Money plant", translated.Name)
+	assert.Equal(t, "Китайское денежное дерево", translated.Description)
+	assert.Equal(t, "Turn the pot weekly", translated.CareInstructions.AdditionalNotes)
+	assert.Equal(t, "Фикус", untranslated.Name)
+
+	// The catalog language needs no translation
+	service.LocalizePlants(context.Background(), CatalogLanguage, translated)
+	mockTranslationRepo.AssertNumberOfCalls(t, "GetForPlants", 1)
+}
+
+func TestPlantService_LocalizePlants_Error(t *testing.T) {
+	mockTranslationRepo := new(MockPlantTranslationRepository)
+	service := NewPlantService(new(MockPlantRepository))
+	service.SetTranslationRepository(mockTranslationRepo)
+
+	plant := &models.Plant{ID: uuid.New(), Name: "Пилея"}
+	mockTranslationRepo.On("GetForPlants", mock.Anything, mock.Anything, models.LanguageEnglish).
+		Return(nil, errors.New("connection refused"))
+
+	// The plant is shown as in the catalog
+	service.LocalizePlants(context.Background(), models.LanguageEnglish, plant)
+	assert.Equal(t, "Пилея", plant.Name)
+}
+
+func TestPlantService_SetPlantTranslation(t *testing.T) {
+	mockTranslationRepo := new(MockPlantTranslationRepository)
+	service := NewPlantService(new(MockPlantRepository))
+	service.SetTranslationRepository(mockTranslationRepo)
+	plantID := uuid.New()
+
+	name, blank := " Money plant ", "  "
+	_, err := service.SetPlantTranslation(context.Background(), plantID, CatalogLanguage, &models.PlantTranslationRequest{Name: &name})
+	assert.ErrorIs(t, err, ErrInvalidPlantTranslation)
+	_, err = service.SetPlantTranslation(context.Background(), plantID, models.Language("GERMAN"), &models.PlantTranslationRequest{Name: &name})
+	assert.ErrorIs(t, err, ErrInvalidPlantTranslation)
+	_, err = service.SetPlantTranslation(context.Background(), plantID, models.LanguageEnglish, &models.PlantTranslationRequest{Description: &blank})
+	assert.ErrorIs(t, err, ErrInvalidPlantTranslation)
+	mockTranslationRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything)
+
+	mockTranslationRepo.On("Upsert", mock.Anything, mock.Anything).Return(nil)
+	translation, err := service.SetPlantTranslation(context.Background(), plantID, models.LanguageEnglish, &models.PlantTranslationRequest{Name: &name, Description: &blank})
+	require.NoError(t, err)
+	assert.Equal(t, "Money plant", *translation.Name)
+	assert.Nil(t, translation.Description)
+}
+
+func TestPlantService_SetPlantTranslation_Unavailable(t *testing.T) {
+	service := NewPlantService(new(MockPlantRepository))
+
+	name := "Money plant"
+	_, err := service.SetPlantTranslation(context.Background(), uuid.New(), models.LanguageEnglish, &models.PlantTranslationRequest{Name: &name})
+	assert.ErrorIs(t, err, ErrPlantTranslationsUnavailable)
+}
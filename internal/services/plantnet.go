@@ -0,0 +1,134 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// plantNetIdentifyURL is the endpoint of the Pl@ntNet identification API; the project is appended
+const plantNetIdentifyURL = "https://my-api.plantnet.org/v2/identify/"
+
+// PlantNetProvider identifies plant species on photos with the Pl@ntNet API
+type PlantNetProvider struct {
+	apiKey   string
+	project  string // flora the species are looked up in, "all" for every one
+	endpoint string
+	client   *http.Client
+}
+
+// NewPlantNetProvider creates a new Pl@ntNet identification provider
+func NewPlantNetProvider(apiKey string, project string) *PlantNetProvider {
+	if project == "" {
+		project = "all"
+	}
+	return &PlantNetProvider{
+		apiKey:   apiKey,
+		project:  project,
+		endpoint: plantNetIdentifyURL,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// plantNetResponse represents the species a photo was identified as
+type plantNetResponse struct {
+	Results []struct {
+		Score   float64 `json:"score"`
+		Species struct {
+			ScientificNameWithoutAuthor string   `json:"scientificNameWithoutAuthor"`
+			CommonNames                 []string `json:"commonNames"`
+			Family                      struct {
+				ScientificNameWithoutAuthor string `json:"scientificNameWithoutAuthor"`
+			} `json:"family"`
+		} `json:"species"`
+	} `json:"results"`
+}
+
+// Name returns the provider name
+func (p *PlantNetProvider) Name() string {
+	return "plantnet"
+}
+
+// Identify returns the species a plant photo shows with their scores. A photo Pl@ntNet finds no
+// species on yields no candidates.
+func (p *PlantNetProvider) Identify(ctx context.Context, image []byte, contentType string) ([]*models.PlantIdentificationCandidate, error) {
+	// Build the multipart form with the photo; the organ is left for Pl@ntNet to detect
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="images"; filename="photo"`)
+	header.Set("Content-Type", contentType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if _, err := part.Write(image); err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := form.WriteField("organs", "auto"); err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Create the HTTP request
+	query := url.Values{}
+	query.Set("api-key", p.apiKey)
+	query.Set("nb-results", strconv.Itoa(maxPlantIdentificationCandidates))
+	requestURL := p.endpoint + url.PathEscape(p.project) + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	// Send the request
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check the response status; Pl@ntNet answers 404 when it recognizes no species
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("API returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// Parse the response
+	var response plantNetResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	candidates := make([]*models.PlantIdentificationCandidate, 0, len(response.Results))
+	for _, result := range response.Results {
+		if result.Species.ScientificNameWithoutAuthor == "" {
+			continue
+		}
+		candidates = append(candidates, &models.PlantIdentificationCandidate{
+			ScientificName: result.Species.ScientificNameWithoutAuthor,
+			CommonNames:    result.Species.CommonNames,
+			Family:         result.Species.Family.ScientificNameWithoutAuthor,
+			Score:          result.Score,
+		})
+	}
+	return candidates, nil
+}
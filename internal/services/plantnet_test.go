@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPlantNetProvider_Identify tests that the photo is sent as a form and the species are read back
+func TestPlantNetProvider_Identify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/all", r.URL.Path)
+		assert.Equal(t, "test-key", r.URL.Query().Get("api-key"))
+
+		file, header, err := r.FormFile("images")
+		if assert.NoError(t, err) {
+			image, _ := io.ReadAll(file)
+			assert.Equal(t, pngPhoto, image)
+			assert.Equal(t, "image/png", header.Header.Get("Content-Type"))
+		}
+		assert.Equal(t, "auto", r.FormValue("organs"))
+
+		w.Write([]byte(`{"results":[
+			{"score":0.91,"species":{"scientificNameWithoutAuthor":"Monstera deliciosa","commonNames":["Swiss cheese plant"],"family":{"scientificNameWithoutAuthor":"Araceae"}}},
+			{"score":0.03,"species":{"scientificNameWithoutAuthor":""}}
+		]}`))
+	}))
+	defer server.Close()
+
+	provider := NewPlantNetProvider("test-key", "")
+	provider.endpoint = server.URL + "/"
+
+	candidates, err := provider.Identify(context.Background(), pngPhoto, "image/png")
+
+	assert.NoError(t, err)
+	if assert.Len(t, candidates, 1) {
+		assert.Equal(t, "Monstera deliciosa", candidates[0].ScientificName)
+		assert.Equal(t, "Araceae", candidates[0].Family)
+		assert.Equal(t, []string{"Swiss cheese plant"}, candidates[0].CommonNames)
+		assert.Equal(t, 0.91, candidates[0].Score)
+	}
+}
+
+// TestPlantNetProvider_Identify_NotFound tests that a photo without a recognizable species yields no candidates
+func TestPlantNetProvider_Identify_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"statusCode":404,"error":"Not Found","message":"Species not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	provider := NewPlantNetProvider("test-key", "weurope")
+	provider.endpoint = server.URL + "/"
+
+	candidates, err := provider.Identify(context.Background(), pngPhoto, "image/png")
+
+	assert.NoError(t, err)
+	assert.Empty(t, candidates)
+}
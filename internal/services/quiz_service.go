@@ -0,0 +1,426 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrQuizUnavailable is returned when the catalog has no plant with facts to ask about
+	ErrQuizUnavailable = errors.New("the daily quiz is not available")
+
+	// ErrQuizClosed is returned when answering the quiz of another day than today
+	ErrQuizClosed = errors.New("only the quiz of today can be answered")
+
+	// ErrQuizAlreadyAnswered is returned when a user answers the quiz of a day again
+	ErrQuizAlreadyAnswered = errors.New("the quiz of today was answered already")
+
+	// ErrInvalidQuizAnswers is returned when the answers do not match the questions of the quiz
+	ErrInvalidQuizAnswers = errors.New("invalid quiz answers")
+)
+
+const (
+	// quizQuestions is the number of questions of a daily quiz
+	quizQuestions = 5
+
+	// quizPointsPerAnswer is the number of points a right answer scores
+	quizPointsPerAnswer = 10
+
+	// quizPerfectBonus is the number of points added when every answer of a quiz is right
+	quizPerfectBonus = 10
+
+	// quizWateringOptions is the number of options of a question on watering
+	quizWateringOptions = 4
+
+	// defaultQuizLeaderboardDays is the number of days a leaderboard counts by default
+	defaultQuizLeaderboardDays = 7
+
+	// maxQuizLeaderboardDays is the most days a leaderboard counts
+	maxQuizLeaderboardDays = 365
+)
+
+// quizQuestionKinds lists the kinds of questions a quiz rotates through
+var quizQuestionKinds = []models.QuizQuestionKind{
+	models.QuizQuestionKindWatering,
+	models.QuizQuestionKindSunlight,
+	models.QuizQuestionKindHumidity,
+	models.QuizQuestionKindPetFriendly,
+}
+
+// quizLevels are the options of questions on sunlight and humidity, from low to high
+var quizLevels = []string{"LOW", "MEDIUM", "HIGH"}
+
+//go:embed templates/quiz.json
+var quizTextsJSON []byte
+
+// quizTexts holds the texts of quiz questions by language and kind
+var quizTexts = mustLoadQuizTexts(quizTextsJSON)
+
+// quizText is how the questions of a kind are put into words in a language
+type quizText struct {
+	question *template.Template
+	option   *template.Template
+}
+
+// quizTextData is the data of a quiz question or option template
+type quizTextData struct {
+	Plant string // name of the plant asked about
+	Value string // value of the option, e.g. days between waterings or LOW
+}
+
+// QuizService serves the daily care knowledge quiz: a few questions generated from the facts of
+// catalog plants, the same for every user on a day. Right answers score points, answering on
+// consecutive days builds a streak, and users compare their points with the users they follow.
+type QuizService struct {
+	quizRepo  repository.QuizRepository
+	plantRepo repository.PlantRepository
+	badges    *BadgeService // nil when quizzes earn no badges
+	now       func() time.Time
+}
+
+// NewQuizService creates a new quiz service
+func NewQuizService(quizRepo repository.QuizRepository, plantRepo repository.PlantRepository, badges *BadgeService) *QuizService {
+	return &QuizService{
+		quizRepo:  quizRepo,
+		plantRepo: plantRepo,
+		badges:    badges,
+		now:       time.Now,
+	}
+}
+
+// GetDailyQuiz gets the quiz of today in a language, with the user's result once they answered it.
+// The first request of a day generates the quiz from the catalog.
+func (s *QuizService) GetDailyQuiz(ctx context.Context, userID uuid.UUID, language models.Language) (*models.DailyQuiz, error) {
+	today := truncateToDay(s.now())
+	questions, err := s.dailyQuestions(ctx, today)
+	if err != nil {
+		return nil, err
+	}
+
+	quiz := &models.DailyQuiz{
+		Date:      today.Format(time.DateOnly),
+		Questions: make([]*models.QuizQuestion, 0, len(questions)),
+	}
+	for _, question := range questions {
+		quiz.Questions = append(quiz.Questions, renderQuizQuestion(question, language))
+	}
+
+	result, err := s.quizRepo.GetResult(ctx, userID, today)
+	switch {
+	case err == nil:
+		quiz.Result = result
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+	return quiz, nil
+}
+
+// SubmitAnswers scores the answers of a user to the quiz of today, saves the result and awards the
+// badges it earns
+func (s *QuizService) SubmitAnswers(ctx context.Context, userID uuid.UUID, language models.Language, req *models.SubmitQuizAnswersRequest) (*models.QuizResult, error) {
+	today := truncateToDay(s.now())
+	if req.Date != today.Format(time.DateOnly) {
+		return nil, ErrQuizClosed
+	}
+	questions, err := s.dailyQuestions(ctx, today)
+	if err != nil {
+		return nil, err
+	}
+
+	// Score the answers
+	if len(req.Answers) != len(questions) {
+		return nil, fmt.Errorf("%w: the quiz has %d questions", ErrInvalidQuizAnswers, len(questions))
+	}
+	result := &models.QuizResult{Total: len(questions), Answers: make(models.QuizAnswers, 0, len(questions))}
+	for i, question := range questions {
+		option := req.Answers[i]
+		if option >= len(question.Options) {
+			return nil, fmt.Errorf("%w: question %d has %d options", ErrInvalidQuizAnswers, i+1, len(question.Options))
+		}
+		answer := &models.QuizAnswer{Option: option, CorrectOption: question.Answer, Correct: option == question.Answer}
+		if answer.Correct {
+			result.Correct++
+		}
+		result.Answers = append(result.Answers, answer)
+	}
+	result.Points = result.Correct * quizPointsPerAnswer
+	if result.Correct == result.Total {
+		result.Points += quizPerfectBonus
+	}
+
+	saved, err := s.quizRepo.SaveResult(ctx, userID, today, result)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrQuizAlreadyAnswered
+	}
+
+	result.Badges = s.awardBadges(ctx, userID, language, result)
+	return result, nil
+}
+
+// GetLeaderboard gets the quiz points of a user and the users they follow over the last days,
+// today included. Days default to a week and are capped at a year.
+func (s *QuizService) GetLeaderboard(ctx context.Context, userID uuid.UUID, days int) (*models.QuizLeaderboard, error) {
+	if days <= 0 {
+		days = defaultQuizLeaderboardDays
+	}
+	if days > maxQuizLeaderboardDays {
+		days = maxQuizLeaderboardDays
+	}
+
+	today := truncateToDay(s.now())
+	entries, err := s.quizRepo.GetLeaderboard(ctx, userID, today.AddDate(0, 0, 1-days), today)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		entry.Rank = i + 1
+		if i > 0 && entry.Points == entries[i-1].Points {
+			entry.Rank = entries[i-1].Rank
+		}
+		entry.IsMe = entry.UserID == userID
+	}
+	return &models.QuizLeaderboard{Days: days, Entries: entries}, nil
+}
+
+// dailyQuestions gets the questions of the quiz of a day, generating and storing them on first use
+func (s *QuizService) dailyQuestions(ctx context.Context, day time.Time) (models.QuizQuestionSet, error) {
+	questions, err := s.quizRepo.GetDay(ctx, day)
+	if err == nil {
+		return questions, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	plants, err := s.plantRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plants: %w", err)
+	}
+	questions = generateQuizQuestions(plants, day)
+	if len(questions) == 0 {
+		return nil, ErrQuizUnavailable
+	}
+	return s.quizRepo.CreateDay(ctx, day, questions)
+}
+
+// awardBadges awards the badges a quiz result earns and returns the new ones. Badges are best
+// effort: a failure is logged and the result kept.
+func (s *QuizService) awardBadges(ctx context.Context, userID uuid.UUID, language models.Language, result *models.QuizResult) []models.BadgeType {
+	if s.badges == nil {
+		return nil
+	}
+
+	earned := []models.BadgeType{models.BadgeTypeQuizFirst}
+	if result.Correct == result.Total {
+		earned = append(earned, models.BadgeTypeQuizPerfect)
+	}
+	if result.Streak >= 7 {
+		earned = append(earned, models.BadgeTypeQuizStreak7)
+	}
+	if result.Streak >= 30 {
+		earned = append(earned, models.BadgeTypeQuizStreak30)
+	}
+
+	var awarded []models.BadgeType
+	for _, badge := range earned {
+		isNew, err := s.badges.Award(ctx, userID, language, badge)
+		if err != nil {
+			log.Printf("Failed to award badge %s to user %s: %v", badge, userID, err)
+			continue
+		}
+		if isNew {
+			awarded = append(awarded, badge)
+		}
+	}
+	return awarded
+}
+
+// generateQuizQuestions generates the questions of the quiz of a day from catalog plants. The day
+// seeds the choice of plants, so the same catalog gives the same quiz; kinds of questions rotate,
+// and a plant without the fact a kind asks about gets the next kind.
+func generateQuizQuestions(plants []*models.Plant, day time.Time) models.QuizQuestionSet {
+	rng := rand.New(rand.NewSource(day.Unix()))
+
+	candidates := make([]*models.Plant, len(plants))
+	copy(candidates, plants)
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID.String() < candidates[j].ID.String() })
+	rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+
+	frequencies := quizWateringFrequencies(plants)
+	offset := rng.Intn(len(quizQuestionKinds))
+	questions := models.QuizQuestionSet{}
+	for _, plant := range candidates {
+		if len(questions) == quizQuestions {
+			break
+		}
+		for i := range quizQuestionKinds {
+			kind := quizQuestionKinds[(offset+len(questions)+i)%len(quizQuestionKinds)]
+			if question := quizQuestionFor(plant, kind, frequencies, rng); question != nil {
+				questions = append(questions, question)
+				break
+			}
+		}
+	}
+	return questions
+}
+
+// quizQuestionFor returns a question of a kind on a plant, or nil when the catalog lacks the fact
+func quizQuestionFor(plant *models.Plant, kind models.QuizQuestionKind, frequencies []int, rng *rand.Rand) *models.QuizQuestionFacts {
+	question := &models.QuizQuestionFacts{PlantID: plant.ID, PlantName: plant.Name, Kind: kind}
+	var answer string
+	switch kind {
+	case models.QuizQuestionKindWatering:
+		frequency := plant.CareInstructions.WateringFrequency
+		if frequency <= 0 {
+			return nil
+		}
+		for _, option := range quizWateringOptionsFor(frequency, frequencies, rng) {
+			question.Options = append(question.Options, strconv.Itoa(option))
+		}
+		answer = strconv.Itoa(frequency)
+	case models.QuizQuestionKindSunlight:
+		answer = string(plant.CareInstructions.Sunlight)
+		question.Options = quizLevels
+	case models.QuizQuestionKindHumidity:
+		answer = string(plant.CareInstructions.Humidity)
+		question.Options = quizLevels
+	case models.QuizQuestionKindPetFriendly:
+		if plant.PetFriendly == nil {
+			return nil
+		}
+		answer = strconv.FormatBool(*plant.PetFriendly)
+		question.Options = []string{"true", "false"}
+	}
+
+	for i, option := range question.Options {
+		if option == answer {
+			question.Answer = i
+			return question
+		}
+	}
+	return nil
+}
+
+// quizWateringOptionsFor returns the days between waterings offered for a plant watered every
+// frequency days, in ascending order: the right one and others of the catalog, or multiples of it
+// when the catalog has too few
+func quizWateringOptionsFor(frequency int, frequencies []int, rng *rand.Rand) []int {
+	options := []int{frequency}
+	seen := map[int]bool{frequency: true}
+	for _, i := range rng.Perm(len(frequencies)) {
+		if len(options) == quizWateringOptions {
+			break
+		}
+		if !seen[frequencies[i]] {
+			seen[frequencies[i]] = true
+			options = append(options, frequencies[i])
+		}
+	}
+	for _, option := range []int{frequency * 2, frequency * 3, frequency / 2, frequency * 4} {
+		if len(options) == quizWateringOptions {
+			break
+		}
+		if option > 0 && !seen[option] {
+			seen[option] = true
+			options = append(options, option)
+		}
+	}
+	sort.Ints(options)
+	return options
+}
+
+// quizWateringFrequencies returns the distinct days between waterings of catalog plants, ascending
+func quizWateringFrequencies(plants []*models.Plant) []int {
+	seen := make(map[int]bool)
+	var frequencies []int
+	for _, plant := range plants {
+		if frequency := plant.CareInstructions.WateringFrequency; frequency > 0 && !seen[frequency] {
+			seen[frequency] = true
+			frequencies = append(frequencies, frequency)
+		}
+	}
+	sort.Ints(frequencies)
+	return frequencies
+}
+
+// renderQuizQuestion puts a quiz question into words in a language, defaulting to Russian
+func renderQuizQuestion(question *models.QuizQuestionFacts, language models.Language) *models.QuizQuestion {
+	texts, ok := quizTexts[language]
+	if !ok {
+		texts = quizTexts[models.LanguageRussian]
+	}
+	text := texts[question.Kind]
+
+	rendered := &models.QuizQuestion{
+		PlantID: question.PlantID,
+		Kind:    question.Kind,
+		Text:    executeQuizText(text.question, quizTextData{Plant: question.PlantName}),
+		Options: make([]string, 0, len(question.Options)),
+	}
+	for _, option := range question.Options {
+		rendered.Options = append(rendered.Options, executeQuizText(text.option, quizTextData{Plant: question.PlantName, Value: option}))
+	}
+	return rendered
+}
+
+// executeQuizText renders a quiz template; the templates are checked at startup, so a failure
+// leaves the text empty
+func executeQuizText(tmpl *template.Template, data quizTextData) string {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		log.Printf("Failed to render quiz text %s: %v", tmpl.Name(), err)
+		return ""
+	}
+	return b.String()
+}
+
+// mustLoadQuizTexts parses the quiz texts, panicking when a language lacks a kind of question or
+// a template is invalid
+func mustLoadQuizTexts(data []byte) map[models.Language]map[models.QuizQuestionKind]*quizText {
+	var sources map[models.Language]map[models.QuizQuestionKind]map[string]string
+	if err := json.Unmarshal(data, &sources); err != nil {
+		panic(fmt.Sprintf("invalid quiz texts: %v", err))
+	}
+	if _, ok := sources[models.LanguageRussian]; !ok {
+		panic("quiz texts have no Russian version")
+	}
+
+	texts := make(map[models.Language]map[models.QuizQuestionKind]*quizText, len(sources))
+	for language, source := range sources {
+		texts[language] = make(map[models.QuizQuestionKind]*quizText, len(quizQuestionKinds))
+		for _, kind := range quizQuestionKinds {
+			text := &quizText{}
+			for part, target := range map[string]**template.Template{"question": &text.question, "option": &text.option} {
+				raw, ok := source[kind][part]
+				if !ok {
+					panic(fmt.Sprintf("quiz texts for %s have no %s %s", language, kind, part))
+				}
+				parsed, err := template.New(string(language) + "/" + string(kind) + "/" + part).Parse(raw)
+				if err != nil {
+					panic(fmt.Sprintf("invalid %s %s %s quiz text: %v", language, kind, part, err))
+				}
+				*target = parsed
+			}
+			texts[language][kind] = text
+		}
+	}
+	return texts
+}
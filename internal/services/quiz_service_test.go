@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockQuizRepository is a mock implementation of the QuizRepository interface
+type MockQuizRepository struct {
+	mock.Mock
+}
+
+func (m *MockQuizRepository) GetDay(ctx context.Context, date time.Time) (models.QuizQuestionSet, error) {
+	args := m.Called(ctx, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.QuizQuestionSet), args.Error(1)
+}
+
+func (m *MockQuizRepository) CreateDay(ctx context.Context, date time.Time, questions models.QuizQuestionSet) (models.QuizQuestionSet, error) {
+	args := m.Called(ctx, date, questions)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.QuizQuestionSet), args.Error(1)
+}
+
+func (m *MockQuizRepository) GetResult(ctx context.Context, userID uuid.UUID, date time.Time) (*models.QuizResult, error) {
+	args := m.Called(ctx, userID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QuizResult), args.Error(1)
+}
+
+func (m *MockQuizRepository) SaveResult(ctx context.Context, userID uuid.UUID, date time.Time, result *models.QuizResult) (bool, error) {
+	args := m.Called(ctx, userID, date, result)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockQuizRepository) GetLeaderboard(ctx context.Context, userID uuid.UUID, since time.Time, today time.Time) ([]*models.QuizLeaderboardEntry, error) {
+	args := m.Called(ctx, userID, since, today)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.QuizLeaderboardEntry), args.Error(1)
+}
+
+// MockBadgeRepository is a mock implementation of the BadgeRepository interface
+type MockBadgeRepository struct {
+	mock.Mock
+}
+
+func (m *MockBadgeRepository) Award(ctx context.Context, userID uuid.UUID, badge models.BadgeType) (bool, error) {
+	args := m.Called(ctx, userID, badge)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBadgeRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Badge, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Badge), args.Error(1)
+}
+
+// quizTestPlants returns catalog plants with every fact a quiz asks about
+func quizTestPlants(n int) []*models.Plant {
+	petFriendly := true
+	plants := make([]*models.Plant, 0, n)
+	for i := 0; i < n; i++ {
+		plants = append(plants, &models.Plant{
+			ID:          uuid.New(),
+			Name:        fmt.Sprintf("Plant %d", i),
+			PetFriendly: &petFriendly,
+			CareInstructions: models.CareInstructions{
+				WateringFrequency: 3 + i,
+				Sunlight:          models.SunlightLevelMedium,
+				Humidity:          models.HumidityLevelHigh,
+			},
+		})
+	}
+	return plants
+}
+
+// TestGenerateQuizQuestions tests that the same catalog and day give the same questions, that
+// kinds rotate and that every question has its right answer among the options
+func TestGenerateQuizQuestions(t *testing.T) {
+	plants := quizTestPlants(8)
+	day := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	questions := generateQuizQuestions(plants, day)
+	require.Len(t, questions, quizQuestions)
+	assert.Equal(t, questions, generateQuizQuestions([]*models.Plant{plants[7], plants[3], plants[0], plants[1], plants[2], plants[4], plants[5], plants[6]}, day))
+
+	kinds := make(map[models.QuizQuestionKind]bool)
+	plantIDs := make(map[uuid.UUID]bool)
+	for _, question := range questions {
+		kinds[question.Kind] = true
+		plantIDs[question.PlantID] = true
+		require.Less(t, question.Answer, len(question.Options))
+		if question.Kind == models.QuizQuestionKindWatering {
+			assert.Len(t, question.Options, quizWateringOptions)
+		}
+	}
+	assert.Len(t, kinds, len(quizQuestionKinds))
+	assert.Len(t, plantIDs, quizQuestions)
+
+	// Plants without the facts of any kind are left out
+	assert.Empty(t, generateQuizQuestions([]*models.Plant{{ID: uuid.New(), Name: "Unknown"}}, day))
+}
+
+// TestQuizWateringOptionsFor tests that multiples of the frequency fill in when the catalog has
+// too few other frequencies
+func TestQuizWateringOptionsFor(t *testing.T) {
+	options := quizWateringOptionsFor(7, []int{7}, rand.New(rand.NewSource(1)))
+	assert.Equal(t, []int{3, 7, 14, 21}, options)
+}
+
+// TestQuizService_GetDailyQuiz tests that the quiz of today is generated on first use and returned
+// in the language of the user, with their result once they answered it
+func TestQuizService_GetDailyQuiz(t *testing.T) {
+	mockQuizRepo := new(MockQuizRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewQuizService(mockQuizRepo, mockPlantRepo, nil)
+	now := time.Date(2026, 5, 10, 15, 30, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	today := truncateToDay(now)
+	userID := uuid.New()
+
+	plants := quizTestPlants(6)
+	mockQuizRepo.On("GetDay", mock.Anything, today).Return(nil, fmt.Errorf("quiz not found: %w", sql.ErrNoRows))
+	mockPlantRepo.On("GetAll", mock.Anything).Return(plants, nil)
+	mockQuizRepo.On("CreateDay", mock.Anything, today, generateQuizQuestions(plants, today)).
+		Return(generateQuizQuestions(plants, today), nil)
+	result := &models.QuizResult{Date: "2026-05-10", Correct: 4, Total: 5, Points: 40, Streak: 2}
+	mockQuizRepo.On("GetResult", mock.Anything, userID, today).Return(result, nil)
+
+	quiz, err := service.GetDailyQuiz(context.Background(), userID, models.LanguageEnglish)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-05-10", quiz.Date)
+	require.Len(t, quiz.Questions, quizQuestions)
+	for _, question := range quiz.Questions {
+		assert.NotEmpty(t, question.Text)
+		assert.NotEmpty(t, question.Options)
+	}
+	assert.Equal(t, result, quiz.Result)
+	mockQuizRepo.AssertExpectations(t)
+}
+
+// TestQuizService_GetDailyQuiz_Unavailable tests that an empty catalog gives no quiz
+func TestQuizService_GetDailyQuiz_Unavailable(t *testing.T) {
+	mockQuizRepo := new(MockQuizRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewQuizService(mockQuizRepo, mockPlantRepo, nil)
+
+	mockQuizRepo.On("GetDay", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("quiz not found: %w", sql.ErrNoRows))
+	mockPlantRepo.On("GetAll", mock.Anything).Return([]*models.Plant{}, nil)
+
+	_, err := service.GetDailyQuiz(context.Background(), uuid.New(), models.LanguageRussian)
+	assert.ErrorIs(t, err, ErrQuizUnavailable)
+	mockQuizRepo.AssertNotCalled(t, "CreateDay", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestQuizService_SubmitAnswers tests that answers are scored, that a perfect quiz earns the bonus
+// and that new badges are returned while the ones the user has are not
+func TestQuizService_SubmitAnswers(t *testing.T) {
+	mockQuizRepo := new(MockQuizRepository)
+	mockBadgeRepo := new(MockBadgeRepository)
+	service := NewQuizService(mockQuizRepo, new(MockPlantRepository), NewBadgeService(mockBadgeRepo, nil))
+	now := time.Date(2026, 5, 10, 8, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	today := truncateToDay(now)
+	userID := uuid.New()
+
+	questions := models.QuizQuestionSet{
+		{Kind: models.QuizQuestionKindSunlight, Options: quizLevels, Answer: 1},
+		{Kind: models.QuizQuestionKindPetFriendly, Options: []string{"true", "false"}, Answer: 0},
+	}
+	mockQuizRepo.On("GetDay", mock.Anything, today).Return(questions, nil)
+	mockQuizRepo.On("SaveResult", mock.Anything, userID, today, mock.MatchedBy(func(r *models.QuizResult) bool {
+		return r.Correct == 2 && r.Total == 2 && r.Points == 2*quizPointsPerAnswer+quizPerfectBonus && len(r.Answers) == 2
+	})).Run(func(args mock.Arguments) {
+		args.Get(3).(*models.QuizResult).Streak = 7
+	}).Return(true, nil).Once()
+	mockBadgeRepo.On("Award", mock.Anything, userID, models.BadgeTypeQuizFirst).Return(false, nil)
+	mockBadgeRepo.On("Award", mock.Anything, userID, models.BadgeTypeQuizPerfect).Return(true, nil)
+	mockBadgeRepo.On("Award", mock.Anything, userID, models.BadgeTypeQuizStreak7).Return(false, errors.New("connection reset"))
+
+	result, err := service.SubmitAnswers(context.Background(), userID, models.LanguageRussian, &models.SubmitQuizAnswersRequest{
+		Date:    "2026-05-10",
+		Answers: []int{1, 0},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 30, result.Points)
+	assert.True(t, result.Answers[0].Correct)
+	assert.Equal(t, []models.BadgeType{models.BadgeTypeQuizPerfect}, result.Badges)
+	mockQuizRepo.AssertExpectations(t)
+	mockBadgeRepo.AssertExpectations(t)
+	mockBadgeRepo.AssertNotCalled(t, "Award", mock.Anything, userID, models.BadgeTypeQuizStreak30)
+
+	// The quiz of today is answered once
+	mockQuizRepo.On("SaveResult", mock.Anything, userID, today, mock.Anything).Return(false, nil).Once()
+	_, err = service.SubmitAnswers(context.Background(), userID, models.LanguageRussian, &models.SubmitQuizAnswersRequest{
+		Date:    "2026-05-10",
+		Answers: []int{0, 0},
+	})
+	assert.ErrorIs(t, err, ErrQuizAlreadyAnswered)
+}
+
+// TestQuizService_SubmitAnswers_Rejected tests that answers to another day, of another number or
+// out of the options are rejected before anything is saved
+func TestQuizService_SubmitAnswers_Rejected(t *testing.T) {
+	mockQuizRepo := new(MockQuizRepository)
+	service := NewQuizService(mockQuizRepo, new(MockPlantRepository), nil)
+	now := time.Date(2026, 5, 10, 8, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	questions := models.QuizQuestionSet{{Kind: models.QuizQuestionKindHumidity, Options: quizLevels, Answer: 2}}
+	mockQuizRepo.On("GetDay", mock.Anything, truncateToDay(now)).Return(questions, nil)
+
+	_, err := service.SubmitAnswers(context.Background(), uuid.New(), models.LanguageRussian, &models.SubmitQuizAnswersRequest{Date: "2026-05-09", Answers: []int{2}})
+	assert.ErrorIs(t, err, ErrQuizClosed)
+
+	_, err = service.SubmitAnswers(context.Background(), uuid.New(), models.LanguageRussian, &models.SubmitQuizAnswersRequest{Date: "2026-05-10", Answers: []int{2, 1}})
+	assert.ErrorIs(t, err, ErrInvalidQuizAnswers)
+
+	_, err = service.SubmitAnswers(context.Background(), uuid.New(), models.LanguageRussian, &models.SubmitQuizAnswersRequest{Date: "2026-05-10", Answers: []int{3}})
+	assert.ErrorIs(t, err, ErrInvalidQuizAnswers)
+	mockQuizRepo.AssertNotCalled(t, "SaveResult", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestQuizService_GetLeaderboard tests that ties share a rank, that the user is marked and that
+// the days are defaulted and capped
+func TestQuizService_GetLeaderboard(t *testing.T) {
+	mockQuizRepo := new(MockQuizRepository)
+	service := NewQuizService(mockQuizRepo, new(MockPlantRepository), nil)
+	now := time.Date(2026, 5, 10, 8, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	today := truncateToDay(now)
+
+	userID := uuid.New()
+	entries := []*models.QuizLeaderboardEntry{
+		{UserID: uuid.New(), Points: 120},
+		{UserID: userID, Points: 90},
+		{UserID: uuid.New(), Points: 90},
+		{UserID: uuid.New(), Points: 0},
+	}
+	mockQuizRepo.On("GetLeaderboard", mock.Anything, userID, today.AddDate(0, 0, -6), today).Return(entries, nil).Once()
+
+	leaderboard, err := service.GetLeaderboard(context.Background(), userID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, defaultQuizLeaderboardDays, leaderboard.Days)
+	ranks := make([]int, 0, len(leaderboard.Entries))
+	for _, entry := range leaderboard.Entries {
+		ranks = append(ranks, entry.Rank)
+	}
+	assert.Equal(t, []int{1, 2, 2, 4}, ranks)
+	assert.True(t, leaderboard.Entries[1].IsMe)
+	assert.False(t, leaderboard.Entries[2].IsMe)
+
+	mockQuizRepo.On("GetLeaderboard", mock.Anything, userID, today.AddDate(0, 0, 1-maxQuizLeaderboardDays), today).Return([]*models.QuizLeaderboardEntry{}, nil).Once()
+	leaderboard, err = service.GetLeaderboard(context.Background(), userID, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, maxQuizLeaderboardDays, leaderboard.Days)
+	mockQuizRepo.AssertExpectations(t)
+}
@@ -0,0 +1,441 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// minRecommendationScore is the score a plant must exceed to be recommended by the weighted and hybrid engines
+const minRecommendationScore = 0.3
+
+// defaultHybridLLMShare is the share of the hybrid score Yandex GPT is worth when none is configured
+const defaultHybridLLMShare = 0.5
+
+var (
+	// ErrUnknownRecommendationEngine is returned when the configured recommendation engine does not exist
+	ErrUnknownRecommendationEngine = errors.New("unknown recommendation engine")
+
+	// ErrLLMNotConfigured is returned when Yandex GPT is asked for recommendations without an API key
+	ErrLLMNotConfigured = errors.New("Yandex GPT is not configured")
+)
+
+// RecommendationEngine scores the catalog plants against a questionnaire. The returned
+// recommendations carry an explanation of their score; the result count and the diversity
+// constraint are applied by the caller.
+type RecommendationEngine interface {
+	// Name returns the name the engine is reported under in explanations
+	Name() models.RecommendationEngineName
+
+	// Recommend scores the plants and returns those worth recommending
+	Recommend(ctx context.Context, questionnaire *models.PlantQuestionnaire, allPlants []*models.Plant) ([]*models.PlantRecommendation, error)
+}
+
+// RecommendationWeights are the shares of the weighted score the questionnaire criteria are worth.
+// They are normalized to sum to 1, so only their ratios matter. Tags is apart: it is the share of the
+// score the preferred tags of a questionnaire are worth when it has some, and the other criteria are
+// worth the rest.
+type RecommendationWeights struct {
+	Sunlight    float64
+	CareLevel   float64
+	PetFriendly float64
+	Location    float64
+	Tags        float64
+}
+
+// DefaultRecommendationWeights are the weights used when none are configured
+var DefaultRecommendationWeights = RecommendationWeights{
+	Sunlight:    0.4,
+	CareLevel:   0.3,
+	PetFriendly: 0.1,
+	Location:    0.2,
+	Tags:        0.3,
+}
+
+// normalized returns the weights scaled to sum to 1 and the tags share clamped to [0, 1]. Negative
+// weights are ignored and the default weights are used when no criterion weight is positive.
+func (w RecommendationWeights) normalized() RecommendationWeights {
+	tags := math.Min(math.Max(w.Tags, 0), 1)
+	w.Sunlight = math.Max(w.Sunlight, 0)
+	w.CareLevel = math.Max(w.CareLevel, 0)
+	w.PetFriendly = math.Max(w.PetFriendly, 0)
+	w.Location = math.Max(w.Location, 0)
+
+	total := w.Sunlight + w.CareLevel + w.PetFriendly + w.Location
+	if total == 0 {
+		return DefaultRecommendationWeights.normalized()
+	}
+	return RecommendationWeights{
+		Sunlight:    w.Sunlight / total,
+		CareLevel:   w.CareLevel / total,
+		PetFriendly: w.PetFriendly / total,
+		Location:    w.Location / total,
+		Tags:        tags,
+	}
+}
+
+// WeightedEngine scores plants on the questionnaire criteria, each worth its configured weight
+type WeightedEngine struct {
+	weights RecommendationWeights
+}
+
+// NewWeightedEngine creates a new weighted criteria engine
+func NewWeightedEngine(weights RecommendationWeights) *WeightedEngine {
+	return &WeightedEngine{weights: weights.normalized()}
+}
+
+// Name returns the name the engine is reported under in explanations
+func (e *WeightedEngine) Name() models.RecommendationEngineName {
+	return models.RecommendationEngineWeighted
+}
+
+// Recommend scores every plant and returns those above the minimum score, best first
+func (e *WeightedEngine) Recommend(
+	ctx context.Context,
+	questionnaire *models.PlantQuestionnaire,
+	allPlants []*models.Plant,
+) ([]*models.PlantRecommendation, error) {
+	var recommendations []*models.PlantRecommendation
+	for _, plant := range allPlants {
+		recommendation := e.score(questionnaire, plant)
+		if recommendation.Score > minRecommendationScore {
+			recommendations = append(recommendations, recommendation)
+		}
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+	return recommendations, nil
+}
+
+// score scores a plant on every criterion, whether or not it ends up recommended. The preferred tags
+// are only scored when the questionnaire has some.
+func (e *WeightedEngine) score(questionnaire *models.PlantQuestionnaire, plant *models.Plant) *models.PlantRecommendation {
+	tagsShare := 0.0
+	if len(questionnaire.PreferredTags) > 0 {
+		tagsShare = e.weights.Tags
+	}
+	rest := 1 - tagsShare
+
+	criteria := []models.RecommendationCriterionScore{
+		weightedCriterion(models.RecommendationCriterionSunlight, e.weights.Sunlight*rest, sunlightMatch(questionnaire, plant)),
+		weightedCriterion(models.RecommendationCriterionCareLevel, e.weights.CareLevel*rest, careLevelMatch(questionnaire, plant)),
+		weightedCriterion(models.RecommendationCriterionPetFriendly, e.weights.PetFriendly*rest, petFriendlyMatch(questionnaire, plant)),
+		weightedCriterion(models.RecommendationCriterionLocation, e.weights.Location*rest, locationMatch(questionnaire, plant)),
+	}
+	if tagsShare > 0 {
+		criteria = append(criteria, weightedCriterion(models.RecommendationCriterionTags, tagsShare, tagsMatch(questionnaire, plant)))
+	}
+
+	score := 0.0
+	var reasoning []string
+	for _, criterion := range criteria {
+		score += criterion.Contribution
+		if criterion.Match > 0 && criterion.Reason != "" {
+			reasoning = append(reasoning, criterion.Reason)
+		}
+	}
+	score = roundScore(score)
+
+	return &models.PlantRecommendation{
+		QuestionnaireID: questionnaire.ID,
+		PlantID:         plant.ID,
+		Score:           score,
+		Reasoning:       strings.Join(reasoning, " "),
+		Explanation: &models.RecommendationExplanation{
+			Engine:   models.RecommendationEngineWeighted,
+			Score:    score,
+			Criteria: criteria,
+		},
+	}
+}
+
+// criterionMatch is how well a plant meets a criterion and why
+type criterionMatch struct {
+	match  float64
+	reason string
+}
+
+// weightedCriterion returns the score of a criterion worth the given weight
+func weightedCriterion(criterion models.RecommendationCriterion, weight float64, m criterionMatch) models.RecommendationCriterionScore {
+	return models.RecommendationCriterionScore{
+		Criterion:    criterion,
+		Weight:       roundScore(weight),
+		Match:        m.match,
+		Contribution: roundScore(weight * m.match),
+		Reason:       m.reason,
+	}
+}
+
+// sunlightMatch fully matches the preferred light and half matches the neighbouring level
+func sunlightMatch(questionnaire *models.PlantQuestionnaire, plant *models.Plant) criterionMatch {
+	sunlight := plant.CareInstructions.Sunlight
+	preference := questionnaire.SunlightPreference
+	switch {
+	case sunlight == preference:
+		return criterionMatch{1, fmt.Sprintf("Уровень освещенности (%s) полностью соответствует вашим требованиям.", sunlight)}
+	case (sunlight == models.SunlightLevelMedium && (preference == models.SunlightLevelLow || preference == models.SunlightLevelHigh)) ||
+		((sunlight == models.SunlightLevelLow || sunlight == models.SunlightLevelHigh) && preference == models.SunlightLevelMedium):
+		return criterionMatch{0.5, fmt.Sprintf("Уровень освещенности (%s) частично соответствует вашим требованиям.", sunlight)}
+	default:
+		return criterionMatch{0, fmt.Sprintf("Растению нужен другой уровень освещенности (%s).", sunlight)}
+	}
+}
+
+// careLevelMatch fully matches the preferred care level (1-5 scale) and half matches one level off
+func careLevelMatch(questionnaire *models.PlantQuestionnaire, plant *models.Plant) criterionMatch {
+	switch abs(plant.CareInstructions.FertilizerFrequency - questionnaire.CareLevel) {
+	case 0:
+		return criterionMatch{1, "Уровень ухода полностью соответствует вашим возможностям."}
+	case 1:
+		return criterionMatch{0.5, "Уровень ухода близок к желаемому."}
+	default:
+		return criterionMatch{0, "Уровень ухода отличается от желаемого."}
+	}
+}
+
+// petFriendlyMatch fully matches plants known to be safe for pets when the user asked for them and
+// half matches plants whose safety is unknown. Plants toxic to pets are left out before scoring, see
+// safePlants, and do not match when scored anyway.
+func petFriendlyMatch(questionnaire *models.PlantQuestionnaire, plant *models.Plant) criterionMatch {
+	if !questionnaire.PetFriendly {
+		return criterionMatch{}
+	}
+	known := plant.PetFriendly != nil || (plant.Toxicity != nil && plant.Toxicity.Pets != nil)
+	switch {
+	case plant.ToxicToPets():
+		return criterionMatch{0, "Растение опасно для домашних животных."}
+	case !known:
+		return criterionMatch{0.5, "Безопасность растения для домашних животных не проверена."}
+	default:
+		return criterionMatch{1, "Растение безопасно для домашних животных."}
+	}
+}
+
+// locationMatch matches plants whose care notes mention the preferred location
+func locationMatch(questionnaire *models.PlantQuestionnaire, plant *models.Plant) criterionMatch {
+	if questionnaire.PreferredLocation == nil || plant.CareInstructions.AdditionalNotes == "" {
+		return criterionMatch{}
+	}
+	if strings.Contains(strings.ToLower(plant.CareInstructions.AdditionalNotes), strings.ToLower(*questionnaire.PreferredLocation)) {
+		return criterionMatch{1, fmt.Sprintf("Подходит для размещения в %s.", *questionnaire.PreferredLocation)}
+	}
+	return criterionMatch{}
+}
+
+// safePlants leaves out the plants known to harm pets when the questionnaire asks for pet friendly
+// plants, and those known to harm children when the user has children, whatever engine scores them.
+// Plants whose toxicity is unknown stay.
+func safePlants(questionnaire *models.PlantQuestionnaire, plants []*models.Plant) []*models.Plant {
+	if !questionnaire.PetFriendly && !questionnaire.HasChildren {
+		return plants
+	}
+	safe := make([]*models.Plant, 0, len(plants))
+	for _, plant := range plants {
+		if questionnaire.PetFriendly && plant.ToxicToPets() {
+			continue
+		}
+		if questionnaire.HasChildren && plant.ToxicToChildren() {
+			continue
+		}
+		safe = append(safe, plant)
+	}
+	return safe
+}
+
+// preferredTags normalizes the tags a questionnaire asks for the way plant tags are saved, dropping
+// duplicates and names no tag can have
+func preferredTags(tags []string) pq.StringArray {
+	var preferred pq.StringArray
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		normalized, ok := normalizeTaxonomyName(tag)
+		if ok && !seen[normalized] {
+			seen[normalized] = true
+			preferred = append(preferred, normalized)
+		}
+	}
+	return preferred
+}
+
+// tagsMatch is the share of the preferred tags of the questionnaire the plant has as tags or categories
+func tagsMatch(questionnaire *models.PlantQuestionnaire, plant *models.Plant) criterionMatch {
+	has := make(map[string]bool, len(plant.Tags)+len(plant.Categories))
+	for _, tag := range plant.Tags {
+		has[tag] = true
+	}
+	for _, category := range plant.Categories {
+		has[category] = true
+	}
+
+	var matched []string
+	for _, tag := range questionnaire.PreferredTags {
+		if has[tag] {
+			matched = append(matched, tag)
+		}
+	}
+	if len(matched) == 0 {
+		return criterionMatch{}
+	}
+	return criterionMatch{
+		roundScore(float64(len(matched)) / float64(len(questionnaire.PreferredTags))),
+		fmt.Sprintf("Растение подходит по тегам: %s.", strings.Join(matched, ", ")),
+	}
+}
+
+// roundScore rounds a score to the two decimals it is stored with
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
+
+// LLMEngine asks Yandex GPT to pick and score the plants
+type LLMEngine struct {
+	service *RecommendationService
+}
+
+// NewLLMEngine creates a new engine calling Yandex GPT through the recommendation service, so its
+// budget, quotas and circuit breaker apply
+func NewLLMEngine(service *RecommendationService) *LLMEngine {
+	return &LLMEngine{service: service}
+}
+
+// Name returns the name the engine is reported under in explanations
+func (e *LLMEngine) Name() models.RecommendationEngineName {
+	return models.RecommendationEngineLLM
+}
+
+// Recommend returns the plants Yandex GPT picked with the score and reasoning it gave them
+func (e *LLMEngine) Recommend(
+	ctx context.Context,
+	questionnaire *models.PlantQuestionnaire,
+	allPlants []*models.Plant,
+) ([]*models.PlantRecommendation, error) {
+	if e.service.yandexGPTAPIKey == "" {
+		return nil, ErrLLMNotConfigured
+	}
+
+	recommendations, err := e.service.generateRecommendationsWithYandexGPT(ctx, questionnaire, allPlants)
+	if err != nil {
+		return nil, err
+	}
+	for _, recommendation := range recommendations {
+		recommendation.Explanation = &models.RecommendationExplanation{
+			Engine: models.RecommendationEngineLLM,
+			Score:  recommendation.Score,
+			Criteria: []models.RecommendationCriterionScore{{
+				Criterion:    models.RecommendationCriterionLLM,
+				Weight:       1,
+				Match:        recommendation.Score,
+				Contribution: recommendation.Score,
+				Reason:       recommendation.Reasoning,
+			}},
+		}
+	}
+	return recommendations, nil
+}
+
+// HybridEngine blends the Yandex GPT score of a plant with its weighted criteria score
+type HybridEngine struct {
+	llm      RecommendationEngine
+	weighted *WeightedEngine
+	llmShare float64
+}
+
+// NewHybridEngine creates a new hybrid engine. llmShare is the share of the score Yandex GPT is
+// worth, the weighted criteria are worth the rest; the default share is used when it is not in (0, 1].
+func NewHybridEngine(llm RecommendationEngine, weighted *WeightedEngine, llmShare float64) *HybridEngine {
+	if llmShare <= 0 || llmShare > 1 {
+		llmShare = defaultHybridLLMShare
+	}
+	return &HybridEngine{llm: llm, weighted: weighted, llmShare: llmShare}
+}
+
+// Name returns the name the engine is reported under in explanations
+func (e *HybridEngine) Name() models.RecommendationEngineName {
+	return models.RecommendationEngineHybrid
+}
+
+// Recommend scores every plant on the weighted criteria and the Yandex GPT score, which is 0 for
+// plants Yandex GPT did not pick, and returns those above the minimum score, best first. It fails
+// when Yandex GPT does.
+func (e *HybridEngine) Recommend(
+	ctx context.Context,
+	questionnaire *models.PlantQuestionnaire,
+	allPlants []*models.Plant,
+) ([]*models.PlantRecommendation, error) {
+	llmRecommendations, err := e.llm.Recommend(ctx, questionnaire, allPlants)
+	if err != nil {
+		return nil, err
+	}
+	llmByPlant := make(map[uuid.UUID]*models.PlantRecommendation, len(llmRecommendations))
+	for _, recommendation := range llmRecommendations {
+		llmByPlant[recommendation.PlantID] = recommendation
+	}
+
+	var recommendations []*models.PlantRecommendation
+	for _, plant := range allPlants {
+		recommendation := e.weighted.score(questionnaire, plant)
+
+		// Scale the weighted criteria down to their share and add the Yandex GPT one
+		criteria := make([]models.RecommendationCriterionScore, 0, len(recommendation.Explanation.Criteria)+1)
+		for _, criterion := range recommendation.Explanation.Criteria {
+			criterion.Weight = roundScore(criterion.Weight * (1 - e.llmShare))
+			criterion.Contribution = roundScore(criterion.Contribution * (1 - e.llmShare))
+			criteria = append(criteria, criterion)
+		}
+		llmCriterion := models.RecommendationCriterionScore{
+			Criterion: models.RecommendationCriterionLLM,
+			Weight:    roundScore(e.llmShare),
+		}
+		if picked, ok := llmByPlant[plant.ID]; ok {
+			llmCriterion.Match = picked.Score
+			llmCriterion.Contribution = roundScore(picked.Score * e.llmShare)
+			llmCriterion.Reason = picked.Reasoning
+			recommendation.Reasoning = picked.Reasoning
+		}
+		criteria = append(criteria, llmCriterion)
+
+		score := roundScore(recommendation.Score*(1-e.llmShare) + llmCriterion.Match*e.llmShare)
+		if score <= minRecommendationScore {
+			continue
+		}
+		recommendation.Score = score
+		recommendation.Explanation = &models.RecommendationExplanation{
+			Engine:   models.RecommendationEngineHybrid,
+			Score:    score,
+			Criteria: criteria,
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+	return recommendations, nil
+}
+
+// NewRecommendationEngine creates the engine configured by name: weighted, llm or hybrid. An empty
+// name or auto returns nil, which leaves the service to use Yandex GPT when it has an API key and
+// the weighted criteria otherwise.
+func NewRecommendationEngine(name string, service *RecommendationService, llmShare float64) (RecommendationEngine, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		return nil, nil
+	case "weighted":
+		return service.localEngine, nil
+	case "llm":
+		return NewLLMEngine(service), nil
+	case "hybrid":
+		return NewHybridEngine(NewLLMEngine(service), service.localEngine, llmShare), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRecommendationEngine, name)
+	}
+}
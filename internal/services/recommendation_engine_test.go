@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubRecommendationEngine is a recommendation engine returning fixed recommendations
+type stubRecommendationEngine struct {
+	recommendations []*models.PlantRecommendation
+	err             error
+}
+
+func (e *stubRecommendationEngine) Name() models.RecommendationEngineName {
+	return models.RecommendationEngineLLM
+}
+
+func (e *stubRecommendationEngine) Recommend(ctx context.Context, questionnaire *models.PlantQuestionnaire, allPlants []*models.Plant) ([]*models.PlantRecommendation, error) {
+	return e.recommendations, e.err
+}
+
+// TestWeightedEngine_Recommend tests that plants are scored on the weighted criteria and each score is explained
+func TestWeightedEngine_Recommend(t *testing.T) {
+	toxic := false
+	questionnaire := &models.PlantQuestionnaire{ID: uuid.New(), SunlightPreference: models.SunlightLevelLow, CareLevel: 2, PetFriendly: true}
+	none := models.ToxicitySeverityNone
+	zamioculcas := &models.Plant{ID: uuid.New(), Toxicity: &models.Toxicity{Pets: &none}, CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelLow, FertilizerFrequency: 2}}
+	dieffenbachia := &models.Plant{ID: uuid.New(), PetFriendly: &toxic, CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelLow, FertilizerFrequency: 2}}
+	aloe := &models.Plant{ID: uuid.New(), PetFriendly: &toxic, CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelHigh, FertilizerFrequency: 5}}
+
+	// Only sunlight and pet safety count, pet safety twice as much
+	engine := NewWeightedEngine(RecommendationWeights{Sunlight: 1, PetFriendly: 2})
+	recommendations, err := engine.Recommend(context.Background(), questionnaire, []*models.Plant{aloe, dieffenbachia, zamioculcas})
+	assert.NoError(t, err)
+	if assert.Len(t, recommendations, 2) {
+		assert.Equal(t, zamioculcas.ID, recommendations[0].PlantID)
+		assert.Equal(t, 1.0, recommendations[0].Score)
+		assert.Equal(t, dieffenbachia.ID, recommendations[1].PlantID)
+		assert.Equal(t, 0.33, recommendations[1].Score)
+
+		explanation := recommendations[1].Explanation
+		assert.Equal(t, models.RecommendationEngineWeighted, explanation.Engine)
+		assert.Equal(t, models.RecommendationCriterionScore{
+			Criterion: models.RecommendationCriterionPetFriendly,
+			Weight:    0.67,
+			Reason:    "Растение опасно для домашних животных.",
+		}, explanation.Criteria[2])
+		assert.Equal(t, 0.0, explanation.Criteria[1].Weight)
+	}
+
+	// No positive weight falls back to the default weights
+	assert.Equal(t, DefaultRecommendationWeights, RecommendationWeights{Sunlight: -1}.normalized())
+}
+
+// TestSafePlants tests that plants toxic to pets or children are left out only when they matter
+func TestSafePlants(t *testing.T) {
+	none, mild, severe := models.ToxicitySeverityNone, models.ToxicitySeverityMild, models.ToxicitySeveritySevere
+	safe := true
+	care := models.CareInstructions{Sunlight: models.SunlightLevelLow, FertilizerFrequency: 2}
+	zamioculcas := &models.Plant{ID: uuid.New(), Toxicity: &models.Toxicity{Pets: &mild, Children: &mild}, CareInstructions: care}
+	chlorophytum := &models.Plant{ID: uuid.New(), Toxicity: &models.Toxicity{Pets: &none, Children: &none}, CareInstructions: care}
+	dieffenbachia := &models.Plant{ID: uuid.New(), Toxicity: &models.Toxicity{Children: &severe}, PetFriendly: &safe, CareInstructions: care}
+	calathea := &models.Plant{ID: uuid.New(), CareInstructions: care}
+	plants := []*models.Plant{zamioculcas, chlorophytum, dieffenbachia, calathea}
+
+	assert.Equal(t, plants, safePlants(&models.PlantQuestionnaire{}, plants))
+	assert.Equal(t, []*models.Plant{chlorophytum, dieffenbachia, calathea}, safePlants(&models.PlantQuestionnaire{PetFriendly: true}, plants))
+	assert.Equal(t, []*models.Plant{chlorophytum, calathea}, safePlants(&models.PlantQuestionnaire{HasChildren: true}, plants))
+
+	// Plants known to be safe for pets score higher than plants nobody checked
+	questionnaire := &models.PlantQuestionnaire{ID: uuid.New(), SunlightPreference: models.SunlightLevelLow, CareLevel: 2, PetFriendly: true}
+	engine := NewWeightedEngine(RecommendationWeights{PetFriendly: 1})
+	recommendations, err := engine.Recommend(context.Background(), questionnaire, []*models.Plant{calathea, chlorophytum})
+	assert.NoError(t, err)
+	if assert.Len(t, recommendations, 2) {
+		assert.Equal(t, chlorophytum.ID, recommendations[0].PlantID)
+		assert.Equal(t, 1.0, recommendations[0].Score)
+		assert.Equal(t, calathea.ID, recommendations[1].PlantID)
+		assert.Equal(t, 0.5, recommendations[1].Score)
+	}
+}
+
+// TestWeightedEngine_Recommend_PreferredTags tests that preferred tags are worth their share of the
+// score, matched against the tags and categories of plants, and the other criteria share the rest
+func TestWeightedEngine_Recommend_PreferredTags(t *testing.T) {
+	questionnaire := &models.PlantQuestionnaire{
+		ID:                 uuid.New(),
+		SunlightPreference: models.SunlightLevelLow,
+		CareLevel:          2,
+		PreferredTags:      preferredTags([]string{"Pet safe", "air_purifying", "pet-safe"}),
+	}
+	care := models.CareInstructions{Sunlight: models.SunlightLevelLow, FertilizerFrequency: 2}
+	chlorophytum := &models.Plant{ID: uuid.New(), Tags: []string{"air-purifying", "pet-safe"}, CareInstructions: care}
+	calathea := &models.Plant{ID: uuid.New(), Categories: []string{"pet-safe"}, CareInstructions: care}
+	zamioculcas := &models.Plant{ID: uuid.New(), CareInstructions: care}
+
+	engine := NewWeightedEngine(RecommendationWeights{Sunlight: 1, Tags: 0.4})
+	recommendations, err := engine.Recommend(context.Background(), questionnaire, []*models.Plant{zamioculcas, calathea, chlorophytum})
+	assert.NoError(t, err)
+	if assert.Len(t, recommendations, 3) {
+		assert.Equal(t, chlorophytum.ID, recommendations[0].PlantID)
+		assert.Equal(t, 1.0, recommendations[0].Score)
+		assert.Equal(t, calathea.ID, recommendations[1].PlantID)
+		assert.Equal(t, 0.8, recommendations[1].Score)
+		assert.Equal(t, zamioculcas.ID, recommendations[2].PlantID)
+		assert.Equal(t, 0.6, recommendations[2].Score)
+
+		criteria := recommendations[1].Explanation.Criteria
+		if assert.Len(t, criteria, 5) {
+			assert.Equal(t, 0.6, criteria[0].Weight)
+			assert.Equal(t, models.RecommendationCriterionScore{
+				Criterion:    models.RecommendationCriterionTags,
+				Weight:       0.4,
+				Match:        0.5,
+				Contribution: 0.2,
+				Reason:       "Растение подходит по тегам: pet-safe.",
+			}, criteria[4])
+		}
+	}
+
+	// Questionnaires without preferred tags are scored on the other criteria alone
+	questionnaire.PreferredTags = nil
+	recommendations, err = engine.Recommend(context.Background(), questionnaire, []*models.Plant{zamioculcas})
+	assert.NoError(t, err)
+	if assert.Len(t, recommendations, 1) {
+		assert.Equal(t, 1.0, recommendations[0].Score)
+		assert.Len(t, recommendations[0].Explanation.Criteria, 4)
+	}
+}
+
+// TestHybridEngine_Recommend tests that the Yandex GPT score is blended with the weighted criteria score
+func TestHybridEngine_Recommend(t *testing.T) {
+	questionnaire := &models.PlantQuestionnaire{ID: uuid.New(), SunlightPreference: models.SunlightLevelLow, CareLevel: 2}
+	zamioculcas := &models.Plant{ID: uuid.New(), CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelLow, FertilizerFrequency: 2}}
+	aloe := &models.Plant{ID: uuid.New(), CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelHigh, FertilizerFrequency: 5}}
+	llm := &stubRecommendationEngine{recommendations: []*models.PlantRecommendation{
+		{PlantID: aloe.ID, Score: 0.8, Reasoning: "Неприхотливое растение"},
+	}}
+
+	engine := NewHybridEngine(llm, NewWeightedEngine(DefaultRecommendationWeights), 0.5)
+	recommendations, err := engine.Recommend(context.Background(), questionnaire, []*models.Plant{aloe, zamioculcas})
+	assert.NoError(t, err)
+	if assert.Len(t, recommendations, 2) {
+		// Yandex GPT picked it for 0.8, halved
+		assert.Equal(t, aloe.ID, recommendations[0].PlantID)
+		assert.Equal(t, 0.4, recommendations[0].Score)
+		assert.Equal(t, "Неприхотливое растение", recommendations[0].Reasoning)
+
+		// Sunlight and care level match for 0.7 of the weighted score, halved
+		assert.Equal(t, zamioculcas.ID, recommendations[1].PlantID)
+		assert.Equal(t, 0.35, recommendations[1].Score)
+
+		explanation := recommendations[1].Explanation
+		assert.Equal(t, models.RecommendationEngineHybrid, explanation.Engine)
+		if assert.Len(t, explanation.Criteria, 5) {
+			assert.Equal(t, 0.2, explanation.Criteria[0].Weight)
+			assert.Equal(t, models.RecommendationCriterionLLM, explanation.Criteria[4].Criterion)
+			assert.Equal(t, 0.5, explanation.Criteria[4].Weight)
+		}
+	}
+
+	// The hybrid engine fails when Yandex GPT does
+	llm.err = errors.New("connection refused")
+	_, err = engine.Recommend(context.Background(), questionnaire, []*models.Plant{aloe, zamioculcas})
+	assert.Error(t, err)
+}
+
+// TestNewRecommendationEngine tests that engines are created by their configured name
+func TestNewRecommendationEngine(t *testing.T) {
+	service := NewRecommendationService(new(MockRecommendationRepository), new(MockPlantRepository), "", "")
+
+	engine, err := NewRecommendationEngine("auto", service, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, engine)
+
+	engine, err = NewRecommendationEngine("Hybrid", service, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RecommendationEngineHybrid, engine.Name())
+	assert.Equal(t, defaultHybridLLMShare, engine.(*HybridEngine).llmShare)
+
+	_, err = NewRecommendationEngine("neural", service, 0)
+	assert.ErrorIs(t, err, ErrUnknownRecommendationEngine)
+}
+
+// TestRecommendationService_GenerateRecommendations_EngineFallback tests that the local engine stands in for a failing engine
+func TestRecommendationService_GenerateRecommendations_EngineFallback(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewRecommendationService(mockRecommendationRepo, mockPlantRepo, "", "")
+	service.SetRecommendationEngine(&stubRecommendationEngine{err: ErrLLMNotConfigured})
+	ctx := context.Background()
+
+	questionnaireID := uuid.New()
+	plant := &models.Plant{ID: uuid.New(), CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelLow, FertilizerFrequency: 2}}
+	mockRecommendationRepo.On("GetQuestionnaire", mock.Anything, questionnaireID).Return(&models.PlantQuestionnaire{
+		ID: questionnaireID, SunlightPreference: models.SunlightLevelLow, CareLevel: 2,
+	}, nil)
+	mockPlantRepo.On("GetAll", mock.Anything).Return([]*models.Plant{plant}, nil)
+	mockRecommendationRepo.On("SaveRecommendation", mock.Anything, mock.MatchedBy(func(r *models.PlantRecommendation) bool {
+		return r.PlantID == plant.ID && r.Explanation != nil && r.Explanation.Engine == models.RecommendationEngineWeighted
+	})).Return(nil).Once()
+	mockRecommendationRepo.On("GetRecommendedPlants", mock.Anything, questionnaireID).Return([]*models.Plant{plant}, nil)
+
+	_, warnings, err := service.GenerateRecommendations(ctx, questionnaireID)
+	assert.NoError(t, err)
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, models.WarningCodeLLMFallback, warnings[0].Code)
+	}
+	mockRecommendationRepo.AssertExpectations(t)
+}
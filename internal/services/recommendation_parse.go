@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// Reasons a recommendation answer of Yandex GPT fails validation
+const (
+	RecommendationParseInvalidJSON   = "invalid_json"
+	RecommendationParseInvalidSchema = "invalid_schema"
+	RecommendationParseNoPlants      = "no_plants"
+)
+
+// RecommendationParseReasons lists the reasons a recommendation answer fails validation
+var RecommendationParseReasons = []string{RecommendationParseInvalidJSON, RecommendationParseInvalidSchema, RecommendationParseNoPlants}
+
+// maxRecommendationReasks is the number of times Yandex GPT is asked again after an answer that
+// does not validate, before the recommendations are left to the local engine
+const maxRecommendationReasks = 1
+
+// recommendationReaskPrompt asks Yandex GPT to correct an answer that did not validate
+const recommendationReaskPrompt = `Ответ не прошел проверку: %s. Ответь еще раз только JSON-объектом в указанном формате, без пояснений и разметки.`
+
+// yandexGPTRecommendationAnswer is the JSON answer the recommendation prompt asks for. The fields
+// are pointers so missing ones can be told from zero values.
+type yandexGPTRecommendationAnswer struct {
+	Recommendations []struct {
+		Number    *int     `json:"number"`
+		Name      *string  `json:"name"`
+		Score     *float64 `json:"score"`
+		Reasoning *string  `json:"reasoning"`
+	} `json:"recommendations"`
+}
+
+// recommendationParseError is returned for a recommendation answer that fails validation. Its
+// message is sent back to Yandex GPT when the answer is asked for again.
+type recommendationParseError struct {
+	reason  string
+	message string
+}
+
+func (e *recommendationParseError) Error() string {
+	return e.message
+}
+
+// RecommendationParseStats holds the counters of the recommendation answers of Yandex GPT
+type RecommendationParseStats struct {
+	Answers  uint64            // answers received, re-asked ones included
+	Failures map[string]uint64 // answers that failed validation by reason
+	Reasks   uint64            // answers asked for again after a failed one
+	Rejected uint64            // generations left to the local engine because no answer validated
+}
+
+// recommendationParseCounters counts the recommendation answers of Yandex GPT. The zero value is
+// ready to use.
+type recommendationParseCounters struct {
+	answers       atomic.Uint64
+	invalidJSON   atomic.Uint64
+	invalidSchema atomic.Uint64
+	noPlants      atomic.Uint64
+	reasks        atomic.Uint64
+	rejected      atomic.Uint64
+}
+
+// failure returns the counter of answers failing validation for a reason
+func (c *recommendationParseCounters) failure(reason string) *atomic.Uint64 {
+	switch reason {
+	case RecommendationParseInvalidJSON:
+		return &c.invalidJSON
+	case RecommendationParseNoPlants:
+		return &c.noPlants
+	default:
+		return &c.invalidSchema
+	}
+}
+
+// RecommendationParseStats returns the counters of the recommendation answers of Yandex GPT since
+// the service was created
+func (s *RecommendationService) RecommendationParseStats() RecommendationParseStats {
+	stats := RecommendationParseStats{
+		Answers:  s.parseCounters.answers.Load(),
+		Failures: make(map[string]uint64, len(RecommendationParseReasons)),
+		Reasks:   s.parseCounters.reasks.Load(),
+		Rejected: s.parseCounters.rejected.Load(),
+	}
+	for _, reason := range RecommendationParseReasons {
+		stats.Failures[reason] = s.parseCounters.failure(reason).Load()
+	}
+	return stats
+}
+
+// askYandexGPTForRecommendations sends the recommendation prompt to Yandex GPT and parses the
+// answer. An answer that fails validation is asked for again, with the problem, before giving up.
+// Failures are logged in a fixed format so log-based metrics can pick them up.
+func (s *RecommendationService) askYandexGPTForRecommendations(
+	ctx context.Context,
+	prompt string,
+	questionnaireID uuid.UUID,
+	allPlants []*models.Plant,
+) ([]*models.PlantRecommendation, error) {
+	messages := []Message{{Role: "user", Text: prompt}}
+	for attempt := 0; ; attempt++ {
+		response, err := s.callYandexGPTAPI(ctx, "", messages)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call Yandex GPT API: %w", err)
+		}
+		s.parseCounters.answers.Add(1)
+
+		recommendations, err := s.parseYandexGPTResponse(response, questionnaireID, allPlants)
+		if err == nil {
+			return recommendations, nil
+		}
+
+		reason := RecommendationParseInvalidSchema
+		var parseErr *recommendationParseError
+		if errors.As(err, &parseErr) {
+			reason = parseErr.reason
+		}
+		s.parseCounters.failure(reason).Add(1)
+		log.Printf("llm recommendation parse failure questionnaire=%s attempt=%d reason=%s: %v",
+			questionnaireID, attempt+1, reason, err)
+
+		if attempt == maxRecommendationReasks {
+			s.parseCounters.rejected.Add(1)
+			return nil, fmt.Errorf("failed to parse Yandex GPT response: %w", err)
+		}
+		s.parseCounters.reasks.Add(1)
+		messages = append(messages,
+			Message{Role: "assistant", Text: response},
+			Message{Role: "user", Text: fmt.Sprintf(recommendationReaskPrompt, err)},
+		)
+	}
+}
+
+// parseYandexGPTResponse parses a recommendation answer of Yandex GPT. The answer must be the JSON
+// object the prompt asks for, optionally in a code block, with a listed plant number, a name, a
+// score in [0, 1] and reasoning for every recommendation. Recommendations whose name matches no
+// listed plant are dropped; an answer left without any fails.
+func (s *RecommendationService) parseYandexGPTResponse(
+	response string,
+	questionnaireID uuid.UUID,
+	allPlants []*models.Plant,
+) ([]*models.PlantRecommendation, error) {
+	var answer yandexGPTRecommendationAnswer
+	decoder := json.NewDecoder(strings.NewReader(stripCodeBlock(response)))
+	if err := decoder.Decode(&answer); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, &recommendationParseError{RecommendationParseInvalidSchema,
+				fmt.Sprintf("field %s must be a %s", typeErr.Field, typeErr.Type)}
+		}
+		return nil, &recommendationParseError{RecommendationParseInvalidJSON, fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return nil, &recommendationParseError{RecommendationParseInvalidJSON, "invalid JSON: text after the object"}
+	}
+
+	if len(answer.Recommendations) == 0 {
+		return nil, &recommendationParseError{RecommendationParseInvalidSchema, "recommendations are missing"}
+	}
+	invalid := func(i int, format string, args ...interface{}) error {
+		return &recommendationParseError{RecommendationParseInvalidSchema,
+			fmt.Sprintf("recommendation %d: ", i+1) + fmt.Sprintf(format, args...)}
+	}
+
+	var recommendations []*models.PlantRecommendation
+	for i, item := range answer.Recommendations {
+		switch {
+		case item.Number == nil:
+			return nil, invalid(i, "number is missing")
+		case *item.Number < 1 || *item.Number > len(allPlants):
+			return nil, invalid(i, "number must be between 1 and %d", len(allPlants))
+		case item.Name == nil || strings.TrimSpace(*item.Name) == "":
+			return nil, invalid(i, "name is missing")
+		case item.Score == nil:
+			return nil, invalid(i, "score is missing")
+		case *item.Score < 0 || *item.Score > 1:
+			return nil, invalid(i, "score must be between 0 and 1")
+		case item.Reasoning == nil || strings.TrimSpace(*item.Reasoning) == "":
+			return nil, invalid(i, "reasoning is missing")
+		}
+
+		plant := recommendedPlant(*item.Number, strings.TrimSpace(*item.Name), allPlants)
+		if plant == nil {
+			continue
+		}
+		recommendations = append(recommendations, &models.PlantRecommendation{
+			QuestionnaireID: questionnaireID,
+			PlantID:         plant.ID,
+			Score:           *item.Score,
+			Reasoning:       strings.TrimSpace(*item.Reasoning),
+		})
+	}
+
+	if len(recommendations) == 0 {
+		return nil, &recommendationParseError{RecommendationParseNoPlants, "no recommendation names a listed plant"}
+	}
+	return recommendations, nil
+}
+
+// stripCodeBlock returns the contents of a text wrapped in a Markdown code block, which models
+// tend to put JSON in, or the text itself
+func stripCodeBlock(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") || len(text) < 6 {
+		return text
+	}
+	text = strings.TrimSuffix(text, "```")
+	if newline := strings.IndexByte(text, '\n'); newline >= 0 {
+		return strings.TrimSpace(text[newline+1:])
+	}
+	return strings.TrimSpace(strings.TrimPrefix(text, "```"))
+}
+
+// recommendedPlant returns the plant a recommendation refers to. The model sometimes numbers its
+// answer on its own, so the number is trusted only when the name agrees with the plant at that
+// position; otherwise the plant is looked up by name.
+func recommendedPlant(number int, name string, allPlants []*models.Plant) *models.Plant {
+	name = strings.ToLower(name)
+	matches := func(plant *models.Plant) bool {
+		plantName := strings.ToLower(strings.TrimSpace(plant.Name))
+		scientificName := strings.ToLower(strings.TrimSpace(plant.ScientificName))
+		return (plantName != "" && strings.Contains(name, plantName)) ||
+			(scientificName != "" && strings.Contains(name, scientificName)) ||
+			(len([]rune(name)) >= 3 && strings.Contains(plantName, name))
+	}
+
+	if number >= 1 && number <= len(allPlants) && matches(allPlants[number-1]) {
+		return allPlants[number-1]
+	}
+	for _, plant := range allPlants {
+		if matches(plant) {
+			return plant
+		}
+	}
+	return nil
+}
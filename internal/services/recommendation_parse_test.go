@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseTestCatalog returns the plants the parser tests recommend from
+func parseTestCatalog() []*models.Plant {
+	araceae := "Araceae"
+	return []*models.Plant{
+		{ID: uuid.New(), Name: "Монстера", ScientificName: "Monstera deliciosa", Family: &araceae},
+		{ID: uuid.New(), Name: "Сансевиерия", ScientificName: "Sansevieria trifasciata"},
+		{ID: uuid.New(), Name: "Фикус Бенджамина", ScientificName: "Ficus benjamina"},
+	}
+}
+
+// assertRecommendationsValid checks the properties every parsed recommendation must have
+func assertRecommendationsValid(t *testing.T, recommendations []*models.PlantRecommendation, questionnaireID uuid.UUID, plants []*models.Plant) {
+	known := make(map[uuid.UUID]bool, len(plants))
+	for _, plant := range plants {
+		known[plant.ID] = true
+	}
+	for _, recommendation := range recommendations {
+		if !known[recommendation.PlantID] {
+			t.Fatalf("recommendation of unknown plant %s", recommendation.PlantID)
+		}
+		if math.IsNaN(recommendation.Score) || recommendation.Score < 0 || recommendation.Score > 1 {
+			t.Fatalf("score %v is outside [0, 1]", recommendation.Score)
+		}
+		if recommendation.QuestionnaireID != questionnaireID {
+			t.Fatalf("recommendation of questionnaire %s", recommendation.QuestionnaireID)
+		}
+	}
+}
+
+// TestParseYandexGPTResponse tests the answers the model produces
+func TestParseYandexGPTResponse(t *testing.T) {
+	service := &RecommendationService{}
+	plants := parseTestCatalog()
+	questionnaireID := uuid.New()
+
+	response := "```json\n" + `{"recommendations": [
+		{"number": 3, "name": "Фикус Бенджамина", "score": 0.85, "reasoning": "Любит рассеянный свет.\nНе переносит сквозняков."},
+		{"number": 2, "name": "Хлорофитум", "score": 0.6, "reasoning": "Растения нет в списке."},
+		{"number": 2, "name": "Monstera deliciosa", "score": 0.4, "reasoning": " Неприхотлива. "}
+	]}` + "\n```"
+
+	recommendations, err := service.parseYandexGPTResponse(response, questionnaireID, plants)
+	assert.NoError(t, err)
+	assertRecommendationsValid(t, recommendations, questionnaireID, plants)
+	assert.Len(t, recommendations, 2)
+
+	assert.Equal(t, plants[2].ID, recommendations[0].PlantID)
+	assert.Equal(t, 0.85, recommendations[0].Score)
+	assert.Equal(t, "Любит рассеянный свет.\nНе переносит сквозняков.", recommendations[0].Reasoning)
+
+	// A plant not in the list is dropped; a wrong number is corrected by the name
+	assert.Equal(t, plants[0].ID, recommendations[1].PlantID)
+	assert.Equal(t, "Неприхотлива.", recommendations[1].Reasoning)
+}
+
+// TestParseYandexGPTResponse_Invalid tests that answers breaking the format fail with the reason
+// they are counted under
+func TestParseYandexGPTResponse_Invalid(t *testing.T) {
+	service := &RecommendationService{}
+	plants := parseTestCatalog()
+
+	tests := []struct {
+		name     string
+		response string
+		reason   string
+	}{
+		{"free text", "1. 1. Монстера - 0.9\nНеприхотлива", RecommendationParseInvalidJSON},
+		{"truncated", `{"recommendations": [{"number": 1, "name": "Монстера"`, RecommendationParseInvalidJSON},
+		{"text after the object", `{"recommendations": []} И еще одно растение`, RecommendationParseInvalidJSON},
+		{"no recommendations", `{"plants": []}`, RecommendationParseInvalidSchema},
+		{"number as text", `{"recommendations": [{"number": "1", "name": "Монстера", "score": 0.9, "reasoning": "Да"}]}`, RecommendationParseInvalidSchema},
+		{"number not listed", `{"recommendations": [{"number": 4, "name": "Монстера", "score": 0.9, "reasoning": "Да"}]}`, RecommendationParseInvalidSchema},
+		{"score out of range", `{"recommendations": [{"number": 1, "name": "Монстера", "score": 1.7, "reasoning": "Да"}]}`, RecommendationParseInvalidSchema},
+		{"missing reasoning", `{"recommendations": [{"number": 1, "name": "Монстера", "score": 0.9}]}`, RecommendationParseInvalidSchema},
+		{"no listed plant", `{"recommendations": [{"number": 1, "name": "Хлорофитум", "score": 0.9, "reasoning": "Да"}]}`, RecommendationParseNoPlants},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.parseYandexGPTResponse(tt.response, uuid.New(), plants)
+			var parseErr *recommendationParseError
+			if assert.ErrorAs(t, err, &parseErr) {
+				assert.Equal(t, tt.reason, parseErr.reason)
+			}
+		})
+	}
+}
+
+// TestParseYandexGPTResponse_RoundTrip tests that answers in the requested format are parsed back
+// to the plants and scores they were written from
+func TestParseYandexGPTResponse_RoundTrip(t *testing.T) {
+	service := &RecommendationService{}
+	plants := parseTestCatalog()
+	questionnaireID := uuid.New()
+	random := rand.New(rand.NewSource(1))
+
+	for run := 0; run < 200; run++ {
+		var answer struct {
+			Recommendations []map[string]interface{} `json:"recommendations"`
+		}
+		var wantIDs []uuid.UUID
+		var wantScores []float64
+		for i := 0; i < 1+random.Intn(5); i++ {
+			number := 1 + random.Intn(len(plants))
+			score := math.Round(random.Float64()*100) / 100
+			answer.Recommendations = append(answer.Recommendations, map[string]interface{}{
+				"number":    number,
+				"name":      plants[number-1].Name,
+				"score":     score,
+				"reasoning": fmt.Sprintf("Причина %d\n\"в кавычках\"", i),
+			})
+			wantIDs = append(wantIDs, plants[number-1].ID)
+			wantScores = append(wantScores, score)
+		}
+		response, err := json.Marshal(answer)
+		assert.NoError(t, err)
+
+		recommendations, err := service.parseYandexGPTResponse(string(response), questionnaireID, plants)
+		assert.NoError(t, err)
+		assertRecommendationsValid(t, recommendations, questionnaireID, plants)
+		if assert.Len(t, recommendations, len(wantIDs), string(response)) {
+			for i, recommendation := range recommendations {
+				assert.Equal(t, wantIDs[i], recommendation.PlantID)
+				assert.InDelta(t, wantScores[i], recommendation.Score, 1e-9)
+			}
+		}
+	}
+}
+
+// TestRecommendationService_AskYandexGPTForRecommendations tests that an answer failing
+// validation is asked for once more with the problem, and that the generation fails when the
+// second answer does not validate either
+func TestRecommendationService_AskYandexGPTForRecommendations(t *testing.T) {
+	plants := parseTestCatalog()
+	valid := `{"recommendations": [{"number": 1, "name": "Монстера", "score": 0.9, "reasoning": "Неприхотлива."}]}`
+
+	tests := []struct {
+		name    string
+		answers []string
+		wantErr bool
+		stats   RecommendationParseStats
+	}{
+		{
+			name:    "valid answer",
+			answers: []string{valid},
+			stats:   RecommendationParseStats{Answers: 1},
+		},
+		{
+			name:    "corrected answer",
+			answers: []string{"1. 1. Монстера - 0.9", valid},
+			stats:   RecommendationParseStats{Answers: 2, Reasks: 1},
+		},
+		{
+			name:    "invalid twice",
+			answers: []string{"1. 1. Монстера - 0.9", `{"recommendations": []}`},
+			wantErr: true,
+			stats:   RecommendationParseStats{Answers: 2, Reasks: 1, Rejected: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []YandexGPTRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var request YandexGPTRequest
+				json.NewDecoder(r.Body).Decode(&request)
+				requests = append(requests, request)
+				body, _ := json.Marshal(map[string]interface{}{"result": map[string]interface{}{
+					"alternatives": []interface{}{map[string]interface{}{
+						"message": map[string]string{"role": "assistant", "text": tt.answers[len(requests)-1]},
+					}},
+				}})
+				w.Write(body)
+			}))
+			defer server.Close()
+
+			service := NewRecommendationService(nil, nil, "test-key", "gpt://b1g/yandexgpt-lite")
+			service.yandexGPTEndpoint = server.URL
+
+			recommendations, err := service.askYandexGPTForRecommendations(context.Background(), "prompt", uuid.New(), plants)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, recommendations, 1)
+				assert.Equal(t, plants[0].ID, recommendations[0].PlantID)
+			}
+			require.Len(t, requests, len(tt.answers))
+
+			// The re-ask carries the first answer and what was wrong with it
+			if len(requests) > 1 {
+				messages := requests[1].Messages
+				require.Len(t, messages, 3)
+				assert.Equal(t, tt.answers[0], messages[1].Text)
+				assert.Contains(t, messages[2].Text, "invalid JSON")
+			}
+
+			stats := service.RecommendationParseStats()
+			assert.Equal(t, tt.stats.Answers, stats.Answers)
+			assert.Equal(t, tt.stats.Reasks, stats.Reasks)
+			assert.Equal(t, tt.stats.Rejected, stats.Rejected)
+			assert.Equal(t, tt.stats.Reasks, stats.Failures[RecommendationParseInvalidJSON])
+			assert.Equal(t, tt.stats.Rejected, stats.Failures[RecommendationParseInvalidSchema])
+		})
+	}
+}
+
+// FuzzParseYandexGPTResponse checks that arbitrary model output never panics and only yields
+// recommendations of listed plants with scores in [0, 1]
+func FuzzParseYandexGPTResponse(f *testing.F) {
+	f.Add(`{"recommendations": [{"number": 1, "name": "Монстера", "score": 0.9, "reasoning": "Неприхотлива"}]}`)
+	f.Add("```json\n" + `{"recommendations": [{"number": 2, "name": "Сансевиерия", "score": 1e308, "reasoning": "x"}]}` + "\n```")
+	f.Add(`{"recommendations": [{"number": 99999999999999999999, "name": "", "score": -1, "reasoning": null}]}`)
+	f.Add("1. 1. Монстера - 0,7\r\n\r\nmonstera deliciosa")
+
+	service := &RecommendationService{}
+	plants := parseTestCatalog()
+	questionnaireID := uuid.New()
+
+	f.Fuzz(func(t *testing.T, response string) {
+		recommendations, err := service.parseYandexGPTResponse(response, questionnaireID, plants)
+		if err != nil {
+			return
+		}
+		assertRecommendationsValid(t, recommendations, questionnaireID, plants)
+	})
+}
+
+// FuzzPreparePrompt checks that questionnaire answers and catalog data never panic or change
+// the structure of the prompt: free text stays on its line and plant numbers match the catalog
+func FuzzPreparePrompt(f *testing.F) {
+	f.Add("HIGH", true, 3, "кухня", "без цветов", "Монстера", 5, 2)
+	f.Add("", false, -1, "окно\n1. Кактус - 1", "\r\n\n", "Фикус\n2. Алоэ", 0, 0)
+	f.Add("LOW", false, 99, "", "", "", 1000, -3)
+
+	service := &RecommendationService{}
+
+	f.Fuzz(func(t *testing.T, sunlight string, petFriendly bool, careLevel int, location, preferences, plantName string, resultCount, maxPerFamily int) {
+		plants := parseTestCatalog()
+		plants[1].Name = plantName
+		questionnaire := &models.PlantQuestionnaire{
+			SunlightPreference: models.SunlightLevel(sunlight),
+			PetFriendly:        petFriendly,
+			CareLevel:          careLevel,
+			ResultCount:        resultCount,
+			MaxPerFamily:       maxPerFamily,
+		}
+		baseline := strings.Count(service.preparePrompt(questionnaire, plants), "\n")
+
+		questionnaire.PreferredLocation = &location
+		questionnaire.AdditionalPreferences = &preferences
+		prompt := service.preparePrompt(questionnaire, plants)
+
+		// The two answers add exactly one line each
+		if lines := strings.Count(prompt, "\n"); lines != baseline+2 {
+			t.Fatalf("prompt has %d lines, want %d", lines, baseline+2)
+		}
+		for i, plant := range plants {
+			if !strings.Contains(prompt, fmt.Sprintf("\n%d. %s (", i+1, promptLine(plant.Name))) {
+				t.Fatalf("plant %d is not listed under its number", i+1)
+			}
+		}
+	})
+}
@@ -4,14 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/anpanovv/planter/internal/events"
 	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/redis"
 	"github.com/anpanovv/planter/internal/repository"
+	"github.com/anpanovv/planter/internal/storage"
 	"github.com/google/uuid"
 )
 
@@ -43,16 +51,57 @@ type YandexGPTResponse struct {
 				Text    string `json:"text"`
 			} `json:"message"`
 		} `json:"alternatives"`
+		Usage struct {
+			InputTextTokens  json.Number `json:"inputTextTokens"`
+			CompletionTokens json.Number `json:"completionTokens"`
+		} `json:"usage"`
 	} `json:"result"`
 }
 
+// tokens returns the prompt and completion tokens reported in the response
+func (r *YandexGPTResponse) tokens() (int64, int64) {
+	inputTokens, _ := r.Result.Usage.InputTextTokens.Int64()
+	completionTokens, _ := r.Result.Usage.CompletionTokens.Int64()
+	return inputTokens, completionTokens
+}
+
+// defaultCompletionOptions are the completion options of chat messages and free-form prompts
+var defaultCompletionOptions = CompletionOptions{
+	Temperature: 0.7,
+	MaxTokens:   2000,
+}
+
+// ErrChatRequestCanceled is returned when the client goes away before Yandex GPT answers a chat
+// message. The upstream request is cancelled and nothing of the exchange is saved.
+var ErrChatRequestCanceled = errors.New("chat request canceled")
+
+const (
+	// defaultRecommendationCount is the number of plants recommended when the questionnaire does not set it
+	defaultRecommendationCount = 5
+
+	// defaultMaxPerFamily is the maximum number of recommended plants from one family when the questionnaire does not set it
+	defaultMaxPerFamily = 2
+)
+
 // RecommendationService handles plant recommendation operations
 type RecommendationService struct {
 	recommendationRepo repository.RecommendationRepository
 	plantRepo          repository.PlantRepository
 	yandexGPTAPIKey    string
 	yandexGPTModel     string
-	chatSessions       map[uuid.UUID][]Message // In-memory cache for chat sessions
+	chatContexts       chatContextStore        // Hot cache of the persisted chat contexts
+	generationFlight   *plantsFlightGroup      // Deduplicates concurrent generation per questionnaire
+	yandexGPTEndpoint  string
+	llmStatusMu        sync.RWMutex
+	llmStatus          *models.LLMStatus // Result of the last Yandex GPT self-test
+	publisher          events.Publisher
+	budget             *LLMBudgetService // nil when spend and quotas are not tracked
+	engine             RecommendationEngine // nil to use Yandex GPT when it has an API key and localEngine otherwise
+	localEngine        *WeightedEngine      // Scores quick recommendations and stands in when the engine fails
+	scrubber           *ChatScrubber        // nil to send chat messages as written
+	chatObjects        storage.ObjectStore  // nil when photos cannot be attached to chat messages
+	chatImageProvider  DiagnosisProvider    // nil when attached photos are not described to Yandex GPT
+	parseCounters      recommendationParseCounters
 }
 
 // NewRecommendationService creates a new recommendation service
@@ -67,8 +116,44 @@ func NewRecommendationService(
 		plantRepo:          plantRepo,
 		yandexGPTAPIKey:    yandexGPTAPIKey,
 		yandexGPTModel:     yandexGPTModel,
-		chatSessions:       make(map[uuid.UUID][]Message),
+		chatContexts:       newChatContextCache(defaultChatContextCacheSize, defaultChatContextIdleTimeout),
+		generationFlight:   newPlantsFlightGroup(),
+		yandexGPTEndpoint:  yandexGPTCompletionURL,
+		publisher:          events.NopPublisher{},
+		localEngine:        NewWeightedEngine(DefaultRecommendationWeights),
+	}
+}
+
+// SetRecommendationEngine sets the engine recommendations are generated with
+func (s *RecommendationService) SetRecommendationEngine(engine RecommendationEngine) {
+	s.engine = engine
+}
+
+// SetRecommendationWeights sets the weights of the questionnaire criteria the local engine scores
+// plants with. Engines created before are not affected.
+func (s *RecommendationService) SetRecommendationWeights(weights RecommendationWeights) {
+	s.localEngine = NewWeightedEngine(weights)
+}
+
+// SetEventPublisher sets the publisher domain events are sent to
+func (s *RecommendationService) SetEventPublisher(publisher events.Publisher) {
+	s.publisher = publisher
+}
+
+// SetLLMBudget sets the budget service Yandex GPT calls are checked against and recorded in
+func (s *RecommendationService) SetLLMBudget(budget *LLMBudgetService) {
+	s.budget = budget
+}
+
+// SetRedis shares the chat context cache and the recommendation generation runs with the other
+// instances of the API through Redis. The idle timeout of the current chat context cache is kept.
+func (s *RecommendationService) SetRedis(client *redis.Client) {
+	idleTimeout := defaultChatContextIdleTimeout
+	if cache, ok := s.chatContexts.(*chatContextCache); ok {
+		idleTimeout = cache.idleTimeout
 	}
+	s.chatContexts = newRedisChatContextCache(client, idleTimeout)
+	s.generationFlight.SetLocker(client, "planter:flight:recommendations:")
 }
 
 // SaveQuestionnaire saves a plant questionnaire
@@ -81,6 +166,10 @@ func (s *RecommendationService) SaveQuestionnaire(ctx context.Context, userID *u
 		CareLevel:            questionnaire.CareLevel,
 		PreferredLocation:    questionnaire.PreferredLocation,
 		AdditionalPreferences: questionnaire.AdditionalPreferences,
+		PreferredTags:        preferredTags(questionnaire.Tags),
+		HasChildren:          questionnaire.HasChildren,
+		ResultCount:          intOrDefault(questionnaire.Count, defaultRecommendationCount),
+		MaxPerFamily:         intOrDefault(questionnaire.MaxPerFamily, defaultMaxPerFamily),
 	}
 
 	// Save the questionnaire
@@ -105,6 +194,9 @@ func (s *RecommendationService) SaveDetailedQuestionnaire(
 		PetFriendly:          questionnaire.PetFriendly,
 		CareLevel:            questionnaire.CareLevel,
 		PreferredLocation:    questionnaire.PreferredLocation,
+		HasChildren:          questionnaire.HasChildren,
+		ResultCount:          intOrDefault(questionnaire.Count, defaultRecommendationCount),
+		MaxPerFamily:         intOrDefault(questionnaire.MaxPerFamily, defaultMaxPerFamily),
 	}
 
 	// Create additional preferences text that includes all the detailed information
@@ -124,6 +216,14 @@ func (s *RecommendationService) SaveDetailedQuestionnaire(
 
 	plantQuestionnaire.AdditionalPreferences = &additionalPrefs
 
+	// Ask for the tags of the flowering and air-purifying plants the catalog has
+	if questionnaire.FloweringPreference {
+		plantQuestionnaire.PreferredTags = append(plantQuestionnaire.PreferredTags, "flowering")
+	}
+	if questionnaire.AirPurifying {
+		plantQuestionnaire.PreferredTags = append(plantQuestionnaire.PreferredTags, "air-purifying")
+	}
+
 	// Save the questionnaire
 	err := s.recommendationRepo.SaveQuestionnaire(ctx, plantQuestionnaire)
 	if err != nil {
@@ -133,160 +233,286 @@ func (s *RecommendationService) SaveDetailedQuestionnaire(
 	return plantQuestionnaire, nil
 }
 
-// generateLocalRecommendations generates plant recommendations using local matching logic
-func (s *RecommendationService) generateLocalRecommendations(
-	ctx context.Context,
-	questionnaire *models.PlantQuestionnaire,
-	allPlants []*models.Plant,
-) ([]*models.PlantRecommendation, error) {
-	var recommendations []*models.PlantRecommendation
+// abs returns the absolute value of an integer
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// intOrDefault returns the value of an optional integer or the default if it is not set
+func intOrDefault(value *int, def int) int {
+	if value == nil {
+		return def
+	}
+	return *value
+}
+
+// recommendationLimits returns the number of plants to recommend for a questionnaire
+// and the maximum number of them from one family
+func recommendationLimits(questionnaire *models.PlantQuestionnaire) (int, int) {
+	count := questionnaire.ResultCount
+	if count <= 0 {
+		count = defaultRecommendationCount
+	}
+	maxPerFamily := questionnaire.MaxPerFamily
+	if maxPerFamily <= 0 {
+		maxPerFamily = defaultMaxPerFamily
+	}
+	return count, maxPerFamily
+}
+
+// plantFamily returns the key plants are grouped by for the diversity constraint: the botanical
+// family when it is known, otherwise the genus from the scientific name
+func plantFamily(plant *models.Plant) string {
+	if plant.Family != nil && strings.TrimSpace(*plant.Family) != "" {
+		return strings.ToLower(strings.TrimSpace(*plant.Family))
+	}
+	if fields := strings.Fields(plant.ScientificName); len(fields) > 0 {
+		return strings.ToLower(fields[0])
+	}
+	return ""
+}
 
+// diversifyRecommendations keeps the best scored recommendations, at most count of them and at most
+// maxPerFamily from one family. Plants without a known family or genus are not limited.
+func diversifyRecommendations(
+	recommendations []*models.PlantRecommendation,
+	allPlants []*models.Plant,
+	count int,
+	maxPerFamily int,
+) []*models.PlantRecommendation {
+	families := make(map[uuid.UUID]string, len(allPlants))
 	for _, plant := range allPlants {
-		score := 0.0
-		reasoning := ""
-
-		// Match sunlight preference
-		if plant.CareInstructions.Sunlight == questionnaire.SunlightPreference {
-			score += 0.4
-			reasoning += fmt.Sprintf("Уровень освещенности (%s) полностью соответствует вашим требованиям. ", plant.CareInstructions.Sunlight)
-		} else if (plant.CareInstructions.Sunlight == models.SunlightLevelMedium && 
-			(questionnaire.SunlightPreference == models.SunlightLevelLow || questionnaire.SunlightPreference == models.SunlightLevelHigh)) ||
-			((plant.CareInstructions.Sunlight == models.SunlightLevelLow || plant.CareInstructions.Sunlight == models.SunlightLevelHigh) && 
-			questionnaire.SunlightPreference == models.SunlightLevelMedium) {
-			score += 0.2
-			reasoning += fmt.Sprintf("Уровень освещенности (%s) частично соответствует вашим требованиям. ", plant.CareInstructions.Sunlight)
-		}
+		families[plant.ID] = plantFamily(plant)
+	}
 
-		// Match care level (1-5 scale)
-		careLevelDiff := float64(abs(plant.CareInstructions.FertilizerFrequency - questionnaire.CareLevel))
-		if careLevelDiff == 0 {
-			score += 0.3
-			reasoning += "Уровень ухода полностью соответствует вашим возможностям. "
-		} else if careLevelDiff == 1 {
-			score += 0.15
-			reasoning += "Уровень ухода близок к желаемому. "
-		}
+	sorted := make([]*models.PlantRecommendation, len(recommendations))
+	copy(sorted, recommendations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Score > sorted[j].Score
+	})
 
-		// Match pet friendly requirement
-		if questionnaire.PetFriendly {
-			// For now, assume all plants are not pet friendly unless explicitly marked
-			// This would need to be added to the plant model and database
-			score += 0.1
-			reasoning += "Растение безопасно для домашних животных. "
+	result := make([]*models.PlantRecommendation, 0, count)
+	perFamily := make(map[string]int)
+	seen := make(map[uuid.UUID]bool)
+	for _, recommendation := range sorted {
+		if len(result) == count {
+			break
 		}
-
-		// Add location matching if specified
-		if questionnaire.PreferredLocation != nil && plant.CareInstructions.AdditionalNotes != "" {
-			if strings.Contains(strings.ToLower(plant.CareInstructions.AdditionalNotes), 
-				strings.ToLower(*questionnaire.PreferredLocation)) {
-				score += 0.2
-				reasoning += fmt.Sprintf("Подходит для размещения в %s. ", *questionnaire.PreferredLocation)
-			}
+		if seen[recommendation.PlantID] {
+			continue
 		}
 
-		// Create recommendation if score is above threshold
-		if score > 0.3 { // Minimum 30% match
-			recommendations = append(recommendations, &models.PlantRecommendation{
-				QuestionnaireID: questionnaire.ID,
-				PlantID:        plant.ID,
-				Score:          score,
-				Reasoning:      strings.TrimSpace(reasoning),
-			})
+		family := families[recommendation.PlantID]
+		if family != "" && perFamily[family] >= maxPerFamily {
+			continue
 		}
-	}
-
-	// Sort recommendations by score in descending order
-	sort.Slice(recommendations, func(i, j int) bool {
-		return recommendations[i].Score > recommendations[j].Score
-	})
 
-	// Return top 5 recommendations or all if less than 5
-	if len(recommendations) > 5 {
-		recommendations = recommendations[:5]
+		perFamily[family]++
+		seen[recommendation.PlantID] = true
+		result = append(result, recommendation)
 	}
-
-	return recommendations, nil
+	return result
 }
 
-// abs returns the absolute value of an integer
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
+// GenerateRecommendations generates plant recommendations based on a questionnaire.
+// Concurrent calls for the same questionnaire share a single generation run.
+func (s *RecommendationService) GenerateRecommendations(ctx context.Context, questionnaireID uuid.UUID) ([]*models.Plant, []models.Warning, error) {
+	return s.generationFlight.Do(ctx, questionnaireID, func(ctx context.Context) ([]*models.Plant, []models.Warning, error) {
+		return s.generateRecommendations(ctx, questionnaireID)
+	})
 }
 
-// GenerateRecommendations generates plant recommendations based on a questionnaire
-func (s *RecommendationService) GenerateRecommendations(ctx context.Context, questionnaireID uuid.UUID) ([]*models.Plant, error) {
+// generateRecommendations generates and saves plant recommendations for a questionnaire. A warning
+// is returned when the engine failed and the local engine was used instead.
+func (s *RecommendationService) generateRecommendations(ctx context.Context, questionnaireID uuid.UUID) ([]*models.Plant, []models.Warning, error) {
 	// Get the questionnaire
 	questionnaire, err := s.recommendationRepo.GetQuestionnaire(ctx, questionnaireID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get questionnaire: %w", err)
+		return nil, nil, fmt.Errorf("failed to get questionnaire: %w", err)
 	}
 
-	// Get all plants
+	// Get the plants that cannot harm the user's pets or children
 	allPlants, err := s.plantRepo.GetAll(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get plants: %w", err)
+		return nil, nil, fmt.Errorf("failed to get plants: %w", err)
 	}
+	allPlants = safePlants(questionnaire, allPlants)
 
-	var recommendations []*models.PlantRecommendation
-	
-	// Try to use Yandex GPT if API key is available
-	if s.yandexGPTAPIKey != "" {
-		recommendations, err = s.generateRecommendationsWithYandexGPT(ctx, questionnaire, allPlants)
-		if err != nil {
-			// Fallback to local recommendations if Yandex GPT fails
-			recommendations, err = s.generateLocalRecommendations(ctx, questionnaire, allPlants)
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate recommendations: %w", err)
-			}
+	var warnings []models.Warning
+	engine := s.recommendationEngine()
+	recommendations, err := engine.Recommend(ctx, questionnaire, allPlants)
+	if err != nil {
+		if engine == RecommendationEngine(s.localEngine) {
+			return nil, nil, fmt.Errorf("failed to generate recommendations: %w", err)
 		}
-	} else {
-		// Use local recommendations if no API key
-		recommendations, err = s.generateLocalRecommendations(ctx, questionnaire, allPlants)
+
+		// Fallback to the local engine if Yandex GPT fails
+		log.Printf("Recommendation engine %s failed for questionnaire %s: %v", engine.Name(), questionnaireID, err)
+		warnings = append(warnings, models.Warning{
+			Code:    models.WarningCodeLLMFallback,
+			Message: "Yandex GPT is unavailable, so the recommendations were made by the built-in matcher",
+		})
+		recommendations, err = s.localEngine.Recommend(ctx, questionnaire, allPlants)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate recommendations: %w", err)
+			return nil, nil, fmt.Errorf("failed to generate recommendations: %w", err)
 		}
 	}
 
+	// Keep the best scored plants without recommending too many from one family
+	count, maxPerFamily := recommendationLimits(questionnaire)
+	recommendations = diversifyRecommendations(recommendations, allPlants, count, maxPerFamily)
+
 	// Save the recommendations
 	for _, recommendation := range recommendations {
 		err = s.recommendationRepo.SaveRecommendation(ctx, recommendation)
 		if err != nil {
-			return nil, fmt.Errorf("failed to save recommendation: %w", err)
+			return nil, nil, fmt.Errorf("failed to save recommendation: %w", err)
 		}
 	}
 
 	// Get the recommended plants
 	recommendedPlants, err := s.recommendationRepo.GetRecommendedPlants(ctx, questionnaireID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get recommended plants: %w", err)
+		return nil, nil, fmt.Errorf("failed to get recommended plants: %w", err)
 	}
 
-	return recommendedPlants, nil
+	plantIDs := make([]uuid.UUID, 0, len(recommendedPlants))
+	for _, plant := range recommendedPlants {
+		plantIDs = append(plantIDs, plant.ID)
+	}
+	publishEvent(ctx, s.publisher, events.RecommendationGenerated{
+		QuestionnaireID: questionnaireID,
+		UserID:          questionnaire.UserID,
+		PlantIDs:        plantIDs,
+		OccurredAt:      time.Now(),
+	})
+
+	return recommendedPlants, warnings, nil
+}
+
+// QuickRecommendations scores plants for the given preferences with the local engine, without
+// Yandex GPT. The preferences are saved as a questionnaire of the user only when userID is set.
+// A warning is returned when not enough plants suit the light and some of them need a different amount.
+func (s *RecommendationService) QuickRecommendations(
+	ctx context.Context,
+	userID *uuid.UUID,
+	req *models.QuickRecommendationsRequest,
+) (*models.QuickRecommendationsResponse, []models.Warning, error) {
+	questionnaire := &models.PlantQuestionnaire{
+		UserID:             userID,
+		SunlightPreference: req.Light,
+		PetFriendly:        req.PetFriendly,
+		HasChildren:        req.HasChildren,
+		CareLevel:          req.Effort,
+		PreferredTags:      preferredTags(req.Tags),
+		ResultCount:        intOrDefault(req.Count, defaultRecommendationCount),
+		MaxPerFamily:       intOrDefault(req.MaxPerFamily, defaultMaxPerFamily),
+	}
+
+	// Get the plants that cannot harm the user's pets or children
+	allPlants, err := s.plantRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get plants: %w", err)
+	}
+	allPlants = safePlants(questionnaire, allPlants)
+
+	// Save the questionnaire first so the recommendations can reference it
+	if userID != nil {
+		if err := s.recommendationRepo.SaveQuestionnaire(ctx, questionnaire); err != nil {
+			return nil, nil, fmt.Errorf("failed to save questionnaire: %w", err)
+		}
+	}
+
+	recommendations, err := s.localEngine.Recommend(ctx, questionnaire, allPlants)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate recommendations: %w", err)
+	}
+	count, maxPerFamily := recommendationLimits(questionnaire)
+	recommendations = diversifyRecommendations(recommendations, allPlants, count, maxPerFamily)
+
+	plantsByID := make(map[uuid.UUID]*models.Plant, len(allPlants))
+	for _, plant := range allPlants {
+		plantsByID[plant.ID] = plant
+	}
+
+	response := &models.QuickRecommendationsResponse{
+		Plants: make([]*models.Plant, 0, len(recommendations)),
+	}
+	for _, recommendation := range recommendations {
+		if userID != nil {
+			if err := s.recommendationRepo.SaveRecommendation(ctx, recommendation); err != nil {
+				return nil, nil, fmt.Errorf("failed to save recommendation: %w", err)
+			}
+		}
+		plant := *plantsByID[recommendation.PlantID]
+		plant.Recommendation = recommendation.Explanation
+		response.Plants = append(response.Plants, &plant)
+	}
+
+	if userID != nil {
+		response.QuestionnaireID = &questionnaire.ID
+	}
+
+	var warnings []models.Warning
+	mismatched := 0
+	for _, plant := range response.Plants {
+		if plant.CareInstructions.Sunlight != req.Light {
+			mismatched++
+		}
+	}
+	if mismatched > 0 {
+		warnings = append(warnings, models.Warning{
+			Code:    models.WarningCodeLightMismatch,
+			Message: fmt.Sprintf("%d of %d recommended plants need a different amount of light than %s", mismatched, len(response.Plants), req.Light),
+		})
+	}
+	return response, warnings, nil
+}
+
+// recommendationEngine returns the engine recommendations are generated with: the configured one,
+// otherwise Yandex GPT when it has an API key and the local engine when it does not
+func (s *RecommendationService) recommendationEngine() RecommendationEngine {
+	if s.engine != nil {
+		return s.engine
+	}
+	if s.yandexGPTAPIKey != "" {
+		return NewLLMEngine(s)
+	}
+	return s.localEngine
 }
 
-// GetRecommendations gets all recommendations for a questionnaire
-func (s *RecommendationService) GetRecommendations(ctx context.Context, questionnaireID uuid.UUID) ([]*models.Plant, error) {
+// GetRecommendations gets all recommendations for a questionnaire, generating them if needed.
+// The existence check runs inside the same flight as generation so that a retry arriving
+// while the first request is still generating waits for it instead of generating again.
+func (s *RecommendationService) GetRecommendations(ctx context.Context, questionnaireID uuid.UUID) ([]*models.Plant, []models.Warning, error) {
+	return s.generationFlight.Do(ctx, questionnaireID, func(ctx context.Context) ([]*models.Plant, []models.Warning, error) {
+		return s.getOrGenerateRecommendations(ctx, questionnaireID)
+	})
+}
+
+// getOrGenerateRecommendations returns saved recommendations or generates them if there are none
+func (s *RecommendationService) getOrGenerateRecommendations(ctx context.Context, questionnaireID uuid.UUID) ([]*models.Plant, []models.Warning, error) {
 	// Check if recommendations exist
 	recommendations, err := s.recommendationRepo.GetRecommendations(ctx, questionnaireID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get recommendations: %w", err)
+		return nil, nil, fmt.Errorf("failed to get recommendations: %w", err)
 	}
 
 	// If no recommendations exist, generate them
 	if len(recommendations) == 0 {
-		return s.GenerateRecommendations(ctx, questionnaireID)
+		return s.generateRecommendations(ctx, questionnaireID)
 	}
 
 	// Get the recommended plants
 	recommendedPlants, err := s.recommendationRepo.GetRecommendedPlants(ctx, questionnaireID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get recommended plants: %w", err)
+		return nil, nil, fmt.Errorf("failed to get recommended plants: %w", err)
 	}
 
-	return recommendedPlants, nil
+	return recommendedPlants, nil, nil
 }
 
 // generateRecommendationsWithYandexGPT generates plant recommendations using Yandex GPT
@@ -298,16 +524,10 @@ func (s *RecommendationService) generateRecommendationsWithYandexGPT(
 	// Prepare the prompt
 	prompt := s.preparePrompt(questionnaire, allPlants)
 
-	// Call Yandex GPT API
-	response, err := s.callYandexGPTAPI(ctx, prompt, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call Yandex GPT API: %w", err)
-	}
-
-	// Parse the response
-	recommendations, err := s.parseYandexGPTResponse(response, questionnaire.ID, allPlants)
+	// Ask Yandex GPT, re-asking once when the answer does not validate
+	recommendations, err := s.askYandexGPTForRecommendations(ctx, prompt, questionnaire.ID, allPlants)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse Yandex GPT response: %w", err)
+		return nil, err
 	}
 
 	return recommendations, nil
@@ -353,7 +573,21 @@ func (s *RecommendationService) preparePrompt(questionnaire *models.PlantQuestio
 		if i > 0 {
 			plantList += "\n"
 		}
-		plantList += fmt.Sprintf("%d. %s (научное название: %s)", i+1, plant.Name, plant.ScientificName)
+		plantList += fmt.Sprintf("%d. %s (научное название: %s", i+1, promptLine(plant.Name), promptLine(plant.ScientificName))
+		if plant.Family != nil && promptLine(*plant.Family) != "" {
+			plantList += fmt.Sprintf(", семейство: %s", promptLine(*plant.Family))
+		}
+		if tags := append(append([]string{}, plant.Categories...), plant.Tags...); len(tags) > 0 {
+			plantList += fmt.Sprintf(", теги: %s", strings.Join(tags, ", "))
+		}
+		plantList += ")"
+	}
+
+	// Ask for more candidates than needed so the diversity constraint can skip some of them
+	count, maxPerFamily := recommendationLimits(questionnaire)
+	candidates := count * 2
+	if candidates > len(allPlants) {
+		candidates = len(allPlants)
 	}
 
 	// Prepare the prompt
@@ -366,64 +600,105 @@ func (s *RecommendationService) preparePrompt(questionnaire *models.PlantQuestio
 `, sunlightRussian, petFriendlyRussian, careLevelRussian)
 
 	if questionnaire.PreferredLocation != nil {
-		prompt += fmt.Sprintf("- Предпочтительное расположение: %s\n", *questionnaire.PreferredLocation)
+		prompt += fmt.Sprintf("- Предпочтительное расположение: %s\n", promptLine(*questionnaire.PreferredLocation))
 	}
 
 	if questionnaire.AdditionalPreferences != nil {
-		prompt += fmt.Sprintf("- Дополнительные предпочтения: %s\n", *questionnaire.AdditionalPreferences)
+		prompt += fmt.Sprintf("- Дополнительные предпочтения: %s\n", promptLine(*questionnaire.AdditionalPreferences))
+	}
+
+	if len(questionnaire.PreferredTags) > 0 {
+		prompt += fmt.Sprintf("- Желательные теги: %s\n", strings.Join(questionnaire.PreferredTags, ", "))
+	}
+
+	if questionnaire.HasChildren {
+		prompt += "- В доме есть дети\n"
 	}
 
 	prompt += fmt.Sprintf(`
 Список доступных растений:
 %s
 
-Выбери 5 наиболее подходящих растений из списка и объясни, почему они подходят пользователю. Для каждого растения укажи его номер из списка, название и оценку соответствия от 0 до 1, где 1 - идеальное соответствие.
-
-Формат ответа:
-1. [Номер растения]. [Название растения] - [Оценка]
-[Объяснение, почему это растение подходит]
+Выбери %d наиболее подходящих растений из списка, не более %d из одного семейства, и объясни, почему они подходят пользователю. Для каждого растения укажи его номер из списка, название и оценку соответствия от 0 до 1, где 1 - идеальное соответствие.
 
-2. [Номер растения]. [Название растения] - [Оценка]
-[Объяснение, почему это растение подходит]
-
-и так далее.`, plantList)
+Ответь только JSON-объектом без пояснений и разметки, в формате:
+{"recommendations": [{"number": [номер растения из списка], "name": "[название растения]", "score": [оценка от 0 до 1], "reasoning": "[объяснение, почему это растение подходит]"}]}`, plantList, candidates, maxPerFamily)
 
 	return prompt
 }
 
+// promptLine collapses a value into a single line so user input and catalog data cannot break
+// the structure of a prompt, such as the numbered plant list
+func promptLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
 // callYandexGPTAPI calls the Yandex GPT API with a prompt or messages
 func (s *RecommendationService) callYandexGPTAPI(ctx context.Context, prompt string, messages []Message) (string, error) {
-	// Prepare the request
-	requestBody := YandexGPTRequest{
-		ModelURI: s.yandexGPTModel,
-		CompletionOptions: CompletionOptions{
-			Temperature: 0.7,
-			MaxTokens:   2000,
-		},
-	}
-
 	// Use either prompt or messages
 	if prompt != "" {
-		requestBody.Messages = []Message{
+		messages = []Message{
 			{
 				Role: "user",
 				Text: prompt,
 			},
 		}
-	} else if messages != nil {
-		requestBody.Messages = messages
+	}
+
+	return s.callYandexGPTCompletion(ctx, messages, defaultCompletionOptions)
+}
+
+// callYandexGPTCompletion sends a completion request to the Yandex GPT API and returns the text of the answer
+func (s *RecommendationService) callYandexGPTCompletion(ctx context.Context, messages []Message, options CompletionOptions) (string, error) {
+	response, err := s.completeYandexGPT(ctx, messages, options)
+	if err != nil {
+		return "", err
+	}
+	return response.Result.Alternatives[0].Message.Text, nil
+}
+
+// completeYandexGPT sends a completion request to the Yandex GPT API, checking the user's
+// quota and the circuit breaker first when a budget is set
+func (s *RecommendationService) completeYandexGPT(ctx context.Context, messages []Message, options CompletionOptions) (*YandexGPTResponse, error) {
+	if s.budget != nil {
+		if err := s.budget.Acquire(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := s.sendYandexGPTCompletion(ctx, messages, options)
+
+	// Record the outcome and the tokens spent
+	if s.budget != nil {
+		var inputTokens, completionTokens int64
+		if err == nil {
+			inputTokens, completionTokens = response.tokens()
+		}
+		s.budget.Release(ctx, inputTokens, completionTokens, err)
+	}
+
+	return response, err
+}
+
+// sendYandexGPTCompletion sends a completion request to the Yandex GPT API and returns a response with at least one alternative
+func (s *RecommendationService) sendYandexGPTCompletion(ctx context.Context, messages []Message, options CompletionOptions) (*YandexGPTResponse, error) {
+	// Prepare the request
+	requestBody := YandexGPTRequest{
+		ModelURI:          s.yandexGPTModel,
+		CompletionOptions: options,
+		Messages:          messages,
 	}
 
 	// Convert the request to JSON
 	requestJSON, err := json.Marshal(requestBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Create the HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://llm.api.cloud.yandex.net/foundationModels/v1/completion", bytes.NewBuffer(requestJSON))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.yandexGPTEndpoint, bytes.NewBuffer(requestJSON))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set the headers
@@ -438,125 +713,48 @@ func (s *RecommendationService) callYandexGPTAPI(ctx context.Context, prompt str
 	// Send the request
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check the response status
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned status code %d", resp.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &YandexGPTAPIError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
 	}
 
 	// Parse the response
 	var response YandexGPTResponse
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	// Check if there are any alternatives
 	if len(response.Result.Alternatives) == 0 {
-		return "", fmt.Errorf("no alternatives in response")
+		return nil, fmt.Errorf("no alternatives in response")
 	}
 
-	// Return the text of the first alternative
-	return response.Result.Alternatives[0].Message.Text, nil
-}
-
-// parseYandexGPTResponse parses the response from Yandex GPT
-func (s *RecommendationService) parseYandexGPTResponse(
-	response string,
-	questionnaireID uuid.UUID,
-	allPlants []*models.Plant,
-) ([]*models.PlantRecommendation, error) {
-	// Split the response into lines
-	lines := bytes.Split([]byte(response), []byte("\n"))
-
-	var recommendations []*models.PlantRecommendation
-	var currentPlantNumber int
-	var currentScore float64
-	var currentReasoning string
-	var parsingReasoning bool
-
-	// Parse each line
-	for _, line := range lines {
-		lineStr := string(line)
-
-		// Skip empty lines
-		if len(lineStr) == 0 {
-			continue
-		}
-
-		// Check if this is a new plant
-		var plantNumber int
-		var plantName string
-		var score float64
-		_, err := fmt.Sscanf(lineStr, "%d. %s - %f", &plantNumber, &plantName, &score)
-		if err == nil && plantNumber > 0 && plantNumber <= len(allPlants) {
-			// If we were parsing a reasoning, save the previous plant
-			if parsingReasoning && currentPlantNumber > 0 {
-				// Find the plant by number
-				if currentPlantNumber <= len(allPlants) {
-					plant := allPlants[currentPlantNumber-1]
-					recommendations = append(recommendations, &models.PlantRecommendation{
-						QuestionnaireID: questionnaireID,
-						PlantID:         plant.ID,
-						Score:           currentScore,
-						Reasoning:       currentReasoning,
-					})
-				}
-			}
-
-			// Start parsing a new plant
-			currentPlantNumber = plantNumber
-			currentScore = score
-			currentReasoning = ""
-			parsingReasoning = true
-		} else if parsingReasoning {
-			// Add to the current reasoning
-			if len(currentReasoning) > 0 {
-				currentReasoning += "\n"
-			}
-			currentReasoning += lineStr
-		}
-	}
-
-	// Save the last plant
-	if parsingReasoning && currentPlantNumber > 0 {
-		// Find the plant by number
-		if currentPlantNumber <= len(allPlants) {
-			plant := allPlants[currentPlantNumber-1]
-			recommendations = append(recommendations, &models.PlantRecommendation{
-				QuestionnaireID: questionnaireID,
-				PlantID:         plant.ID,
-				Score:           currentScore,
-				Reasoning:       currentReasoning,
-			})
-		}
-	}
-
-	// If no recommendations were parsed, return an error
-	if len(recommendations) == 0 {
-		return nil, fmt.Errorf("failed to parse any recommendations from response")
-	}
-
-	return recommendations, nil
+	return &response, nil
 }
 
 // CreateChatSession creates a new chat session
 func (s *RecommendationService) CreateChatSession(ctx context.Context, userID uuid.UUID) (*models.ChatSession, error) {
 	// Create a new chat session
-	session, err := s.recommendationRepo.CreateChatSession(ctx, userID, "Разговор о растениях")
+	session, err := s.recommendationRepo.CreateChatSession(ctx, userID, defaultChatSessionTitle)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create chat session: %w", err)
 	}
 
-	// Initialize the in-memory session with a system message
-	systemMessage := Message{
-		Role: "system",
-		Text: "Ты - эксперт по растениям. Помогай пользователям с вопросами о выращивании, уходе и выборе растений. Отвечай на русском языке.",
+	// Persist the context the session's messages are answered with
+	chatContext := &models.ChatContext{
+		SessionID:    session.ID,
+		SystemPrompt: chatSystemPrompt(models.LanguageRussian),
+	}
+	if err := s.recommendationRepo.SaveChatContext(ctx, chatContext); err != nil {
+		return nil, fmt.Errorf("failed to save chat context: %w", err)
 	}
-	s.chatSessions[session.ID] = []Message{systemMessage}
+	s.chatContexts.Put(chatContext)
 
 	return session, nil
 }
@@ -571,13 +769,42 @@ func (s *RecommendationService) GetChatSessionsByUser(ctx context.Context, userI
 	return s.recommendationRepo.GetChatSessionsByUser(ctx, userID)
 }
 
-// SendChatMessage sends a message to the chat and gets a response
+// SendChatMessage sends a message to the chat and gets a response. The assistant answers
+// in the language of the message, falling back to the user's preferred language. The Yandex GPT
+// request is bound to ctx, so a client that disconnects cancels it; the message and the answer are
+// saved together once the answer is in, and nothing is saved for a cancelled exchange.
 func (s *RecommendationService) SendChatMessage(
 	ctx context.Context,
 	sessionID uuid.UUID,
 	userID uuid.UUID,
 	message string,
+	preferredLanguage models.Language,
+) (*models.ChatMessage, error) {
+	return s.SendChatMessageWithImage(ctx, sessionID, userID, message, nil, preferredLanguage)
+}
+
+// SendChatMessageWithImage sends a message with an attached photo of a plant, which may be nil, to
+// the chat and gets a response. Yandex GPT reads text only, so the photo is described by the vision
+// provider first and the description is sent along with the message; the photo is stored once the
+// answer is in.
+func (s *RecommendationService) SendChatMessageWithImage(
+	ctx context.Context,
+	sessionID uuid.UUID,
+	userID uuid.UUID,
+	message string,
+	image []byte,
+	preferredLanguage models.Language,
 ) (*models.ChatMessage, error) {
+	// Check the photo format
+	var imageContentType string
+	if image != nil {
+		contentType, err := s.chatImageContentType(image)
+		if err != nil {
+			return nil, err
+		}
+		imageContentType = contentType
+	}
+
 	// Get the chat session
 	session, err := s.recommendationRepo.GetChatSession(ctx, sessionID)
 	if err != nil {
@@ -589,95 +816,108 @@ func (s *RecommendationService) SendChatMessage(
 		return nil, fmt.Errorf("user does not own this chat session")
 	}
 
-	// Create and save the user message
+	// Determine the language to answer in
+	language := resolveChatLanguage(message, preferredLanguage)
+
+	// Scrub personal data from the message before it leaves for Yandex GPT; it is saved as written
+	scrubbedMessage := s.scrubChatMessage(sessionID, message)
+
+	// Create the user message; it is saved with the answer
 	userMessage := &models.ChatMessage{
 		ID:        uuid.New(),
 		SessionID: sessionID,
 		UserID:    userID,
 		Role:      "user",
 		Content:   message,
+		Language:  language,
 		CreatedAt: time.Now(),
 	}
-	
-	err = s.recommendationRepo.SaveChatMessage(ctx, userMessage)
+	if image != nil {
+		userMessage.ImageDescription = s.describeChatImage(ctx, image, imageContentType, language)
+	}
+
+	// Load the persisted context, switching its system prompt to the language of the message
+	chatContext, err := s.loadChatContext(ctx, sessionID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to save user message: %w", err)
+		return nil, fmt.Errorf("failed to get chat context: %w", err)
+	}
+	contextChanged := false
+	if systemPrompt := chatSystemPrompt(language); chatContext.SystemPrompt != systemPrompt {
+		chatContext.SystemPrompt = systemPrompt
+		contextChanged = true
 	}
 
-	// Get all previous messages for context
+	// Get the previous messages not covered by the summary
 	dbMessages, err := s.recommendationRepo.GetChatMessages(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chat messages: %w", err)
 	}
+	history := s.scrubChatHistory(unsummarizedMessages(chatContext, dbMessages))
 
 	// Prepare messages for the API call
-	var messages []Message
-	
-	// Check if we have in-memory session context
-	if sessionMessages, ok := s.chatSessions[sessionID]; ok {
-		// Use the in-memory session which includes the system message
-		messages = sessionMessages
-	} else {
-		// Initialize with a system message
-		messages = []Message{
-			{
-				Role: "system",
-				Text: "Ты - эксперт по растениям. Помогай пользователям с вопросами о выращивании, уходе и выборе растений. Отвечай на русском языке.",
-			},
-		}
-	}
+	prompt := chatMessageText(&models.ChatMessage{Content: scrubbedMessage, ImageDescription: userMessage.ImageDescription})
+	messages := buildChatMessages(chatContext, history, prompt, language)
 
-	// Add previous messages from the database (up to the last 10 messages)
-	maxMessages := 10
-	startIdx := 0
-	if len(dbMessages) > maxMessages {
-		startIdx = len(dbMessages) - maxMessages
+	// Call Yandex GPT API unless the client is already gone
+	if ctx.Err() != nil {
+		return nil, ErrChatRequestCanceled
 	}
-	
-	for i := startIdx; i < len(dbMessages); i++ {
-		msg := dbMessages[i]
-		messages = append(messages, Message{
-			Role: msg.Role,
-			Text: msg.Content,
-		})
-	}
-
-	// Add the current user message
-	messages = append(messages, Message{
-		Role: "user",
-		Text: message,
-	})
-
-	// Call Yandex GPT API
-	response, err := s.callYandexGPTAPI(ctx, "", messages)
+	completion, err := s.completeYandexGPT(ctx, messages, defaultCompletionOptions)
 	if err != nil {
+		if ctx.Err() != nil {
+			log.Printf("Chat request of session %s canceled by the client: %v", sessionID, err)
+			return nil, ErrChatRequestCanceled
+		}
 		return nil, fmt.Errorf("failed to call Yandex GPT API: %w", err)
 	}
+	response := completion.Result.Alternatives[0].Message.Text
+	promptTokens, completionTokens := completion.tokens()
 
-	// Create and save the assistant message
+	// Create and save the assistant message with the tokens spent on it
 	assistantMessage := &models.ChatMessage{
-		ID:        uuid.New(),
-		SessionID: sessionID,
-		UserID:    userID,
-		Role:      "assistant",
-		Content:   response,
-		CreatedAt: time.Now(),
+		ID:               uuid.New(),
+		SessionID:        sessionID,
+		UserID:           userID,
+		Role:             "assistant",
+		Content:          response,
+		Language:         language,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		CreatedAt:        time.Now(),
 	}
-	
-	err = s.recommendationRepo.SaveChatMessage(ctx, assistantMessage)
-	if err != nil {
+
+	// The answer is paid for, so the exchange is saved even if the client disconnects meanwhile
+	saveCtx := context.WithoutCancel(ctx)
+	if image != nil {
+		if err := s.storeChatImage(saveCtx, userMessage, image, imageContentType); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.recommendationRepo.SaveChatMessage(saveCtx, userMessage); err != nil {
+		s.deleteStoredChatImage(saveCtx, userMessage)
+		return nil, fmt.Errorf("failed to save user message: %w", err)
+	}
+	if err := s.recommendationRepo.SaveChatMessage(saveCtx, assistantMessage); err != nil {
 		return nil, fmt.Errorf("failed to save assistant message: %w", err)
 	}
 
-	// Update the in-memory session
-	messages = append(messages, Message{
-		Role: "assistant",
-		Text: response,
-	})
-	s.chatSessions[sessionID] = messages
+	// Fold older messages into the summary and save the context. Summarizing calls Yandex GPT again
+	// and is skipped when the client is gone; the next message catches up.
+	if ctx.Err() != nil {
+		s.updateChatContext(saveCtx, chatContext, nil, language, contextChanged)
+	} else {
+		scrubbedUserMessage := *userMessage
+		scrubbedUserMessage.Content = scrubbedMessage
+		s.updateChatContext(ctx, chatContext, append(history, &scrubbedUserMessage, assistantMessage), language, contextChanged)
+	}
+
+	// Title a new session after its first exchange
+	if len(dbMessages) == 0 && session.Title == defaultChatSessionTitle {
+		s.titleChatSession(saveCtx, sessionID, scrubbedMessage, image != nil, language)
+	}
 
 	// Update the last used timestamp
-	err = s.recommendationRepo.UpdateChatSessionLastUsed(ctx, sessionID)
+	err = s.recommendationRepo.UpdateChatSessionLastUsed(saveCtx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update chat session last used: %w", err)
 	}
@@ -685,6 +925,28 @@ func (s *RecommendationService) SendChatMessage(
 	return assistantMessage, nil
 }
 
+// GetChatUsage gets the cumulative chat usage of a user along with the Yandex GPT tokens the user
+// spent this month when a monthly quota is set
+func (s *RecommendationService) GetChatUsage(ctx context.Context, userID uuid.UUID) (*models.ChatUsage, error) {
+	usage, err := s.recommendationRepo.GetChatUsage(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat usage: %w", err)
+	}
+
+	if s.budget != nil {
+		monthTokens, quota, err := s.budget.GetUserQuota(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if quota > 0 {
+			usage.MonthTokens = &monthTokens
+			usage.MonthlyQuotaTokens = &quota
+		}
+	}
+
+	return usage, nil
+}
+
 // GetChatMessages gets all messages for a chat session
 func (s *RecommendationService) GetChatMessages(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID) ([]*models.ChatMessage, error) {
 	// Get the chat session
@@ -700,4 +962,157 @@ func (s *RecommendationService) GetChatMessages(ctx context.Context, sessionID u
 
 	// Get all messages for the session
 	return s.recommendationRepo.GetChatMessages(ctx, sessionID)
-}
\ No newline at end of file
+}
+// listMarkerPattern matches a leading bullet or "1." / "1)" list marker
+var listMarkerPattern = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s*`)
+
+// funFactPrompts holds the fun fact prompt template for each supported language
+var funFactPrompts = map[models.Language]string{
+	models.LanguageRussian: "Напиши 5 коротких интересных фактов о растении %s (%s). Каждый факт с новой строки, без нумерации и вступления.",
+	models.LanguageEnglish: "Write 5 short fun facts about the plant %s (%s). Put each fact on its own line, without numbering or an introduction.",
+}
+
+// GenerateFunFacts generates fun facts about a plant using Yandex GPT
+func (s *RecommendationService) GenerateFunFacts(ctx context.Context, plant *models.Plant, language models.Language) ([]string, error) {
+	if s.yandexGPTAPIKey == "" {
+		return nil, fmt.Errorf("Yandex GPT is not configured")
+	}
+
+	template, ok := funFactPrompts[language]
+	if !ok {
+		template = funFactPrompts[models.LanguageRussian]
+	}
+
+	response, err := s.callYandexGPTAPI(ctx, fmt.Sprintf(template, plant.Name, plant.ScientificName), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Yandex GPT API: %w", err)
+	}
+
+	// One fact per line; strip list markers the model may add anyway
+	var facts []string
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(listMarkerPattern.ReplaceAllString(line, ""))
+		if line != "" {
+			facts = append(facts, line)
+		}
+	}
+
+	return facts, nil
+}
+
+// triageAdvicePrompts holds the triage follow-up prompt template for each supported language
+var triageAdvicePrompts = map[models.Language]string{
+	models.LanguageRussian: "Растение %s проявляет симптомы: %s. Наиболее вероятные причины по результатам проверки: %s. Коротко (не более 5 предложений) объясни, как отличить эти причины друг от друга и что сделать в ближайшие дни.",
+	models.LanguageEnglish: "The plant %s shows these symptoms: %s. The most likely causes found by a checklist are: %s. Briefly (at most 5 sentences) explain how to tell these causes apart and what to do over the next few days.",
+}
+
+// GenerateTriageAdvice writes a follow-up to a rule-based triage using Yandex GPT
+func (s *RecommendationService) GenerateTriageAdvice(
+	ctx context.Context,
+	plant *models.Plant,
+	symptoms []models.TriageSymptom,
+	causes []models.TriageCause,
+	language models.Language,
+) (string, error) {
+	if s.yandexGPTAPIKey == "" {
+		return "", ErrYandexGPTNotConfigured
+	}
+
+	template, ok := triageAdvicePrompts[language]
+	if !ok {
+		template = triageAdvicePrompts[models.LanguageRussian]
+	}
+
+	plantName := "houseplant"
+	if language == models.LanguageRussian {
+		plantName = "комнатное растение"
+	}
+	if plant != nil {
+		plantName = fmt.Sprintf("%s (%s)", plant.Name, plant.ScientificName)
+	}
+
+	symptomNames := make([]string, 0, len(symptoms))
+	for _, symptom := range symptoms {
+		symptomNames = append(symptomNames, strings.ToLower(strings.ReplaceAll(string(symptom), "_", " ")))
+	}
+	causeNames := make([]string, 0, len(causes))
+	for _, cause := range causes {
+		causeNames = append(causeNames, strings.ToLower(strings.ReplaceAll(string(cause), "_", " ")))
+	}
+
+	prompt := fmt.Sprintf(template, plantName, strings.Join(symptomNames, ", "), strings.Join(causeNames, ", "))
+	response, err := s.callYandexGPTAPI(ctx, prompt, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to call Yandex GPT API: %w", err)
+	}
+	return response, nil
+}
+
+// carePlanAdvicePrompts holds the care plan summary prompt template for each supported language
+var carePlanAdvicePrompts = map[models.Language]string{
+	models.LanguageRussian: "Для растения %s составлен план ухода на год: пересадка — %s, подкормки — %s, период покоя — %s. Условия: полив раз в %d дн., освещение %s, влажность %s, грунт: %s. Коротко (не более 5 предложений) дополни план советами по сезонам, которые важны именно для этого растения.",
+	models.LanguageEnglish: "A one-year care plan was made for the plant %s: repotting in %s, fertilizing in %s, dormancy in %s. Conditions: watering every %d days, %s light, %s humidity, soil: %s. Briefly (at most 5 sentences) add seasonal advice that matters for this particular plant.",
+}
+
+// GenerateCarePlanAdvice writes a summary of a rule-based care plan using Yandex GPT
+func (s *RecommendationService) GenerateCarePlanAdvice(
+	ctx context.Context,
+	plant *models.Plant,
+	plan *models.CarePlan,
+	language models.Language,
+) (string, error) {
+	if s.yandexGPTAPIKey == "" {
+		return "", ErrYandexGPTNotConfigured
+	}
+
+	template, ok := carePlanAdvicePrompts[language]
+	if !ok {
+		template = carePlanAdvicePrompts[models.LanguageRussian]
+	}
+
+	// Name the months of each window in the prompt's language
+	monthNames := func(inWindow func(*models.CarePlanMonth) bool) string {
+		var names []string
+		for _, month := range plan.Months {
+			if inWindow(month) {
+				names = append(names, monthName(month.Month.Month(), language))
+			}
+		}
+		if len(names) == 0 {
+			return "-"
+		}
+		return strings.Join(names, ", ")
+	}
+
+	care := plant.CareInstructions
+	prompt := fmt.Sprintf(template,
+		fmt.Sprintf("%s (%s)", plant.Name, plant.ScientificName),
+		monthNames(func(m *models.CarePlanMonth) bool { return m.Repot }),
+		monthNames(func(m *models.CarePlanMonth) bool { return m.Fertilize }),
+		monthNames(func(m *models.CarePlanMonth) bool { return m.Dormant }),
+		care.WateringFrequency,
+		strings.ToLower(string(care.Sunlight)),
+		strings.ToLower(string(care.Humidity)),
+		care.SoilType,
+	)
+	response, err := s.callYandexGPTAPI(ctx, prompt, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to call Yandex GPT API: %w", err)
+	}
+	return response, nil
+}
+
+// russianMonthNames holds the Russian names of the months
+var russianMonthNames = [...]string{
+	"январь", "февраль", "март", "апрель", "май", "июнь",
+	"июль", "август", "сентябрь", "октябрь", "ноябрь", "декабрь",
+}
+
+// monthName returns the name of a month in the given language
+func monthName(month time.Month, language models.Language) string {
+	if language == models.LanguageEnglish {
+		return month.String()
+	}
+	return russianMonthNames[month-1]
+}
+
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -88,6 +89,62 @@ func (m *MockRecommendationRepository) UpdateChatSessionLastUsed(ctx context.Con
 	return args.Error(0)
 }
 
+func (m *MockRecommendationRepository) GetChatUsage(ctx context.Context, userID uuid.UUID) (*models.ChatUsage, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChatUsage), args.Error(1)
+}
+
+func (m *MockRecommendationRepository) GetChatContext(ctx context.Context, sessionID uuid.UUID) (*models.ChatContext, error) {
+	args := m.Called(ctx, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChatContext), args.Error(1)
+}
+
+func (m *MockRecommendationRepository) SaveChatContext(ctx context.Context, chatContext *models.ChatContext) error {
+	args := m.Called(ctx, chatContext)
+	return args.Error(0)
+}
+
+func (m *MockRecommendationRepository) RenameChatSession(ctx context.Context, sessionID uuid.UUID, title string) error {
+	args := m.Called(ctx, sessionID, title)
+	return args.Error(0)
+}
+
+func (m *MockRecommendationRepository) ReplaceChatSessionTitle(ctx context.Context, sessionID uuid.UUID, oldTitle string, newTitle string) (bool, error) {
+	args := m.Called(ctx, sessionID, oldTitle, newTitle)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecommendationRepository) DeleteChatSession(ctx context.Context, sessionID uuid.UUID) error {
+	args := m.Called(ctx, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockRecommendationRepository) EscalateChatSession(ctx context.Context, sessionID uuid.UUID, reason *string) (bool, error) {
+	args := m.Called(ctx, sessionID, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecommendationRepository) GetEscalatedChatSessions(ctx context.Context, statuses []models.ChatEscalationStatus) ([]*models.ChatSession, error) {
+	args := m.Called(ctx, statuses)
+	return args.Get(0).([]*models.ChatSession), args.Error(1)
+}
+
+func (m *MockRecommendationRepository) ClaimChatEscalation(ctx context.Context, sessionID uuid.UUID, expertID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, sessionID, expertID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecommendationRepository) ResolveChatEscalation(ctx context.Context, sessionID uuid.UUID, expertID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, sessionID, expertID)
+	return args.Bool(0), args.Error(1)
+}
+
 // TestRecommendationService_SaveQuestionnaire tests the SaveQuestionnaire method of the RecommendationService
 func TestRecommendationService_SaveQuestionnaire(t *testing.T) {
 	// Create mock repositories
@@ -190,7 +247,7 @@ func TestRecommendationService_GetRecommendations(t *testing.T) {
 	)
 
 	// Test the GetRecommendations method
-	result, err := recommendationService.GetRecommendations(context.Background(), questionnaireID)
+	result, _, err := recommendationService.GetRecommendations(context.Background(), questionnaireID)
 
 	// Assert that there was no error
 	assert.NoError(t, err)
@@ -264,7 +321,7 @@ func TestRecommendationService_GenerateRecommendations(t *testing.T) {
 	})).Return(nil)
 
 	// Test the GenerateRecommendations method
-	result, err := recommendationService.GenerateRecommendations(context.Background(), questionnaireID)
+	result, _, err := recommendationService.GenerateRecommendations(context.Background(), questionnaireID)
 
 	// Assert that there was no error
 	assert.NoError(t, err)
@@ -381,6 +438,9 @@ func TestRecommendationService_CreateChatSession(t *testing.T) {
 	// Set up the mock expectations
 	mockRecommendationRepo.On("CreateChatSession", mock.Anything, userID, "Разговор о растениях").
 		Return(expectedSession, nil)
+	mockRecommendationRepo.On("SaveChatContext", mock.Anything, mock.MatchedBy(func(c *models.ChatContext) bool {
+		return c.SessionID == expectedSession.ID && strings.Contains(c.SystemPrompt, "эксперт по растениям")
+	})).Return(nil)
 
 	// Create the recommendation service
 	recommendationService := NewRecommendationService(
@@ -401,12 +461,11 @@ func TestRecommendationService_CreateChatSession(t *testing.T) {
 	assert.Equal(t, userID, result.UserID)
 	assert.Equal(t, "Разговор о растениях", result.Title)
 
-	// Verify that the in-memory session was initialized with a system message
-	sessionMessages, ok := recommendationService.chatSessions[result.ID]
+	// Verify that the context was cached with the system prompt
+	chatContext, ok := recommendationService.chatContexts.Get(result.ID)
 	assert.True(t, ok)
-	assert.Equal(t, 1, len(sessionMessages))
-	assert.Equal(t, "system", sessionMessages[0].Role)
-	assert.Contains(t, sessionMessages[0].Text, "эксперт по растениям")
+	assert.Contains(t, chatContext.SystemPrompt, "эксперт по растениям")
+	assert.Empty(t, chatContext.Summary)
 
 	// Verify that all expectations were met
 	mockRecommendationRepo.AssertExpectations(t)
@@ -459,6 +518,7 @@ func TestRecommendationService_SendChatMessage(t *testing.T) {
 		return m.SessionID == sessionID && m.UserID == userID && m.Role == "assistant"
 	})).Return(nil)
 	mockRecommendationRepo.On("UpdateChatSessionLastUsed", mock.Anything, sessionID).Return(nil)
+	mockRecommendationRepo.On("ReplaceChatSessionTitle", mock.Anything, sessionID, "Разговор о растениях", userMessage).Return(true, nil)
 
 	// Create a base recommendation service
 	baseService := NewRecommendationService(
@@ -474,16 +534,14 @@ func TestRecommendationService_SendChatMessage(t *testing.T) {
 		fixedResponse:         assistantResponse,
 	}
 
-	// Initialize the in-memory session
-	mockService.chatSessions[sessionID] = []Message{
-		{
-			Role: "system",
-			Text: "Ты - эксперт по растениям. Помогай пользователям с вопросами о выращивании, уходе и выборе растений. Отвечай на русском языке.",
-		},
-	}
+	// Cache the session context
+	mockService.chatContexts.Put(&models.ChatContext{
+		SessionID:    sessionID,
+		SystemPrompt: chatSystemPrompt(models.LanguageRussian),
+	})
 
 	// Test the SendChatMessage method
-	result, err := mockService.SendChatMessage(context.Background(), sessionID, userID, userMessage)
+	result, err := mockService.SendChatMessage(context.Background(), sessionID, userID, userMessage, models.LanguageRussian)
 
 	// Assert that there was no error
 	assert.NoError(t, err)
@@ -494,15 +552,9 @@ func TestRecommendationService_SendChatMessage(t *testing.T) {
 	assert.Equal(t, "assistant", result.Role)
 	assert.Equal(t, assistantResponse, result.Content)
 
-	// Verify that the in-memory session was updated
-	sessionMessages, ok := mockService.chatSessions[sessionID]
+	// Verify that the context is still cached
+	_, ok := mockService.chatContexts.Get(sessionID)
 	assert.True(t, ok)
-	assert.Equal(t, 3, len(sessionMessages)) // system + user + assistant
-	assert.Equal(t, "system", sessionMessages[0].Role)
-	assert.Equal(t, "user", sessionMessages[1].Role)
-	assert.Equal(t, userMessage, sessionMessages[1].Text)
-	assert.Equal(t, "assistant", sessionMessages[2].Role)
-	assert.Equal(t, assistantResponse, sessionMessages[2].Text)
 
 	// Verify that all expectations were met
 	mockRecommendationRepo.AssertExpectations(t)
@@ -573,3 +625,136 @@ func TestRecommendationService_GetChatMessages(t *testing.T) {
 	mockRecommendationRepo.AssertExpectations(t)
 	mockPlantRepo.AssertExpectations(t)
 }
+
+// TestDiversifyRecommendations tests that recommendations are limited per family and by count
+func TestDiversifyRecommendations(t *testing.T) {
+	araceae := "Araceae"
+	monstera := &models.Plant{ID: uuid.New(), ScientificName: "Monstera deliciosa", Family: &araceae}
+	philodendron := &models.Plant{ID: uuid.New(), ScientificName: "Philodendron hederaceum", Family: &araceae}
+	anthurium := &models.Plant{ID: uuid.New(), ScientificName: "Anthurium andraeanum", Family: &araceae}
+	ficus := &models.Plant{ID: uuid.New(), ScientificName: "Ficus elastica"}
+	ficusLyrata := &models.Plant{ID: uuid.New(), ScientificName: "Ficus lyrata"}
+	unknown := &models.Plant{ID: uuid.New()}
+	allPlants := []*models.Plant{monstera, philodendron, anthurium, ficus, ficusLyrata, unknown}
+
+	recommendations := []*models.PlantRecommendation{
+		{PlantID: ficusLyrata.ID, Score: 0.5},
+		{PlantID: monstera.ID, Score: 0.9},
+		{PlantID: philodendron.ID, Score: 0.85},
+		{PlantID: anthurium.ID, Score: 0.8},
+		{PlantID: ficus.ID, Score: 0.7},
+		{PlantID: unknown.ID, Score: 0.4},
+	}
+
+	result := diversifyRecommendations(recommendations, allPlants, 4, 2)
+
+	var plantIDs []uuid.UUID
+	for _, recommendation := range result {
+		plantIDs = append(plantIDs, recommendation.PlantID)
+	}
+	assert.Equal(t, []uuid.UUID{monstera.ID, philodendron.ID, ficus.ID, ficusLyrata.ID}, plantIDs)
+
+	result = diversifyRecommendations(recommendations, allPlants, 10, 1)
+	assert.Len(t, result, 3)
+	assert.Equal(t, unknown.ID, result[2].PlantID)
+}
+
+// TestRecommendationService_SaveQuestionnaire_Limits tests that the result count and family limit default when not set
+func TestRecommendationService_SaveQuestionnaire_Limits(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	recommendationService := NewRecommendationService(mockRecommendationRepo, new(MockPlantRepository), "", "")
+	count := 8
+
+	mockRecommendationRepo.On("SaveQuestionnaire", mock.Anything, mock.AnythingOfType("*models.PlantQuestionnaire")).Return(nil)
+
+	result, err := recommendationService.SaveQuestionnaire(context.Background(), nil, &models.QuestionnaireRequest{
+		SunlightPreference: models.SunlightLevelLow,
+		CareLevel:          2,
+		Count:              &count,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 8, result.ResultCount)
+	assert.Equal(t, defaultMaxPerFamily, result.MaxPerFamily)
+}
+
+// TestRecommendationService_QuickRecommendations tests that quick recommendations are not saved for anonymous users
+func TestRecommendationService_QuickRecommendations(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	recommendationService := NewRecommendationService(mockRecommendationRepo, mockPlantRepo, "test-api-key", "test-model")
+	ctx := context.Background()
+
+	shade := &models.Plant{ID: uuid.New(), Name: "Zamioculcas", CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelLow, FertilizerFrequency: 1}}
+	sun := &models.Plant{ID: uuid.New(), Name: "Aloe", CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelHigh, FertilizerFrequency: 4}}
+	mockPlantRepo.On("GetAll", ctx).Return([]*models.Plant{shade, sun}, nil)
+
+	response, warnings, err := recommendationService.QuickRecommendations(ctx, nil, &models.QuickRecommendationsRequest{
+		Light:  models.SunlightLevelLow,
+		Effort: 1,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Nil(t, response.QuestionnaireID)
+	if assert.Len(t, response.Plants, 1) {
+		assert.Equal(t, shade.ID, response.Plants[0].ID)
+		assert.Equal(t, models.RecommendationEngineWeighted, response.Plants[0].Recommendation.Engine)
+	}
+	assert.Nil(t, shade.Recommendation)
+	mockRecommendationRepo.AssertNotCalled(t, "SaveQuestionnaire", mock.Anything, mock.Anything)
+	mockRecommendationRepo.AssertNotCalled(t, "SaveRecommendation", mock.Anything, mock.Anything)
+}
+
+// TestRecommendationService_QuickRecommendations_Save tests that quick recommendations are saved for the user who opted in
+func TestRecommendationService_QuickRecommendations_Save(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	recommendationService := NewRecommendationService(mockRecommendationRepo, mockPlantRepo, "", "")
+	ctx := context.Background()
+	userID := uuid.New()
+	questionnaireID := uuid.New()
+
+	plant := &models.Plant{ID: uuid.New(), CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelMedium, FertilizerFrequency: 3}}
+	mockPlantRepo.On("GetAll", ctx).Return([]*models.Plant{plant}, nil)
+	mockRecommendationRepo.On("SaveQuestionnaire", ctx, mock.MatchedBy(func(q *models.PlantQuestionnaire) bool {
+		return *q.UserID == userID && q.CareLevel == 3 && q.ResultCount == defaultRecommendationCount
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.PlantQuestionnaire).ID = questionnaireID
+	})
+	mockRecommendationRepo.On("SaveRecommendation", ctx, mock.MatchedBy(func(r *models.PlantRecommendation) bool {
+		return r.QuestionnaireID == questionnaireID && r.PlantID == plant.ID
+	})).Return(nil)
+
+	response, _, err := recommendationService.QuickRecommendations(ctx, &userID, &models.QuickRecommendationsRequest{
+		Light:  models.SunlightLevelMedium,
+		Effort: 3,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, questionnaireID, *response.QuestionnaireID)
+	assert.Len(t, response.Plants, 1)
+	mockRecommendationRepo.AssertExpectations(t)
+}
+
+// TestRecommendationService_QuickRecommendations_LightMismatch tests the warning about plants that need a different amount of light
+func TestRecommendationService_QuickRecommendations_LightMismatch(t *testing.T) {
+	mockRecommendationRepo := new(MockRecommendationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	recommendationService := NewRecommendationService(mockRecommendationRepo, mockPlantRepo, "", "")
+	ctx := context.Background()
+
+	// Only a partial match exists for low light
+	plant := &models.Plant{ID: uuid.New(), Name: "Pothos", CareInstructions: models.CareInstructions{Sunlight: models.SunlightLevelMedium, FertilizerFrequency: 2}}
+	mockPlantRepo.On("GetAll", ctx).Return([]*models.Plant{plant}, nil)
+
+	response, warnings, err := recommendationService.QuickRecommendations(ctx, nil, &models.QuickRecommendationsRequest{
+		Light:  models.SunlightLevelLow,
+		Effort: 2,
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, response.Plants, 1) {
+		assert.Equal(t, plant.ID, response.Plants[0].ID)
+	}
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, models.WarningCodeLightMismatch, warnings[0].Code)
+	}
+}
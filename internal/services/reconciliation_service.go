@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+)
+
+// reconciliationChecks lists the checks in the order they run; next watering dates are repaired
+// before the orphan checks because they only touch rows that stay, and notification bells are
+// recounted last because orphaned notifications are marked as read
+var reconciliationChecks = []models.ReconciliationCheck{
+	models.ReconciliationCheckNextWatering,
+	models.ReconciliationCheckOrphanedCareTasks,
+	models.ReconciliationCheckOrphanedNotifications,
+	models.ReconciliationCheckNotificationBells,
+}
+
+// ReconciliationService detects and repairs drift between derived data and its source tables
+type ReconciliationService struct {
+	reconciliationRepo repository.ReconciliationRepository
+}
+
+// NewReconciliationService creates a new reconciliation service
+func NewReconciliationService(reconciliationRepo repository.ReconciliationRepository) *ReconciliationService {
+	return &ReconciliationService{
+		reconciliationRepo: reconciliationRepo,
+	}
+}
+
+// Reconcile runs all checks, repairing the inconsistencies unless dryRun is set, and saves the report.
+// A failing check does not stop the others; its error is recorded in the report.
+func (s *ReconciliationService) Reconcile(ctx context.Context, dryRun bool) (*models.ReconciliationRun, error) {
+	run := &models.ReconciliationRun{
+		DryRun:      dryRun,
+		StartedAt:   time.Now(),
+		Corrections: []*models.ReconciliationCorrection{},
+	}
+
+	var failures []string
+	for _, check := range reconciliationChecks {
+		count, err := s.reconciliationRepo.RunCheck(ctx, check, dryRun)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+
+		// Counters are logged in a fixed format so log-based metrics can pick them up
+		log.Printf("reconciliation check=%s dry_run=%t corrections=%d", check, dryRun, count)
+
+		run.Corrections = append(run.Corrections, &models.ReconciliationCorrection{
+			Check:       check,
+			Corrections: count,
+		})
+		run.TotalCorrections += count
+	}
+
+	run.FinishedAt = time.Now()
+	if len(failures) > 0 {
+		message := strings.Join(failures, "; ")
+		run.Error = &message
+	}
+
+	if err := s.reconciliationRepo.SaveRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to save reconciliation run: %w", err)
+	}
+
+	if run.Error != nil {
+		return run, fmt.Errorf("reconciliation finished with errors: %s", *run.Error)
+	}
+	return run, nil
+}
+
+// GetRuns gets the most recent reconciliation runs
+func (s *ReconciliationService) GetRuns(ctx context.Context, limit int) ([]*models.ReconciliationRun, error) {
+	if limit < 1 || limit > 100 {
+		limit = 30
+	}
+
+	runs, err := s.reconciliationRepo.GetRuns(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconciliation runs: %w", err)
+	}
+	return runs, nil
+}
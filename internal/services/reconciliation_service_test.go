@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReconciliationRepository is a mock implementation of the ReconciliationRepository interface
+type MockReconciliationRepository struct {
+	mock.Mock
+}
+
+func (m *MockReconciliationRepository) RunCheck(ctx context.Context, check models.ReconciliationCheck, dryRun bool) (int, error) {
+	args := m.Called(ctx, check, dryRun)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockReconciliationRepository) SaveRun(ctx context.Context, run *models.ReconciliationRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockReconciliationRepository) GetRuns(ctx context.Context, limit int) ([]*models.ReconciliationRun, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]*models.ReconciliationRun), args.Error(1)
+}
+
+// TestReconciliationService_Reconcile tests that all checks run and the report is saved
+func TestReconciliationService_Reconcile(t *testing.T) {
+	mockRepo := new(MockReconciliationRepository)
+	service := NewReconciliationService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("RunCheck", ctx, models.ReconciliationCheckNextWatering, false).Return(3, nil)
+	mockRepo.On("RunCheck", ctx, models.ReconciliationCheckOrphanedCareTasks, false).Return(0, nil)
+	mockRepo.On("RunCheck", ctx, models.ReconciliationCheckOrphanedNotifications, false).Return(2, nil)
+	mockRepo.On("RunCheck", ctx, models.ReconciliationCheckNotificationBells, false).Return(1, nil)
+	mockRepo.On("SaveRun", ctx, mock.AnythingOfType("*models.ReconciliationRun")).Return(nil)
+
+	run, err := service.Reconcile(ctx, false)
+	assert.NoError(t, err)
+	assert.False(t, run.DryRun)
+	assert.Equal(t, 6, run.TotalCorrections)
+	assert.Len(t, run.Corrections, 4)
+	assert.Nil(t, run.Error)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestReconciliationService_Reconcile_CheckFails tests that a failing check is reported without stopping the others
+func TestReconciliationService_Reconcile_CheckFails(t *testing.T) {
+	mockRepo := new(MockReconciliationRepository)
+	service := NewReconciliationService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("RunCheck", ctx, models.ReconciliationCheckNextWatering, true).Return(0, fmt.Errorf("timeout"))
+	mockRepo.On("RunCheck", ctx, models.ReconciliationCheckOrphanedCareTasks, true).Return(4, nil)
+	mockRepo.On("RunCheck", ctx, models.ReconciliationCheckOrphanedNotifications, true).Return(1, nil)
+	mockRepo.On("RunCheck", ctx, models.ReconciliationCheckNotificationBells, true).Return(0, nil)
+	mockRepo.On("SaveRun", ctx, mock.AnythingOfType("*models.ReconciliationRun")).Return(nil)
+
+	run, err := service.Reconcile(ctx, true)
+	assert.Error(t, err)
+	assert.True(t, run.DryRun)
+	assert.Equal(t, 5, run.TotalCorrections)
+	assert.Len(t, run.Corrections, 3)
+	assert.Contains(t, *run.Error, "timeout")
+	mockRepo.AssertCalled(t, "SaveRun", ctx, run)
+}
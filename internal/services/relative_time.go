@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+//go:embed templates/relative_times.json
+var relativeTimesJSON []byte
+
+// relativeTimes holds the phrases of humanized times by language
+var relativeTimes = mustLoadRelativeTimes(relativeTimesJSON)
+
+// relativeTime names a phrase of humanized times
+type relativeTime string
+
+const (
+	relativeTimeNow          relativeTime = "NOW"
+	relativeTimeMinutesAhead relativeTime = "MINUTES_AHEAD"
+	relativeTimeMinutesAgo   relativeTime = "MINUTES_AGO"
+	relativeTimeHoursAhead   relativeTime = "HOURS_AHEAD"
+	relativeTimeHoursAgo     relativeTime = "HOURS_AGO"
+	relativeTimeToday        relativeTime = "TODAY"
+	relativeTimeTomorrow     relativeTime = "TOMORROW"
+	relativeTimeYesterday    relativeTime = "YESTERDAY"
+	relativeTimeDaysAhead    relativeTime = "DAYS_AHEAD"
+	relativeTimeDaysAgo      relativeTime = "DAYS_AGO"
+)
+
+// relativeTimeData is the data of a phrase template
+type relativeTimeData struct {
+	N int // minutes, hours or days before or after now
+}
+
+// pluralRules pick the plural form of a count by language: Russian has one for 1, 21, 31...,
+// one for 2-4, 22-24... and one for the rest, English one for 1 and one for the rest
+var pluralRules = map[models.Language]func(n int) int{
+	models.LanguageRussian: func(n int) int {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		default:
+			return 2
+		}
+	},
+	models.LanguageEnglish: func(n int) int {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	},
+}
+
+// AddPlantTimeDisplays sets the humanized next watering of the plants of a collection, e.g. "in 3 days",
+// counted in calendar days of the given location
+func AddPlantTimeDisplays(plants []*models.Plant, language models.Language, location *time.Location, now time.Time) {
+	for _, plant := range plants {
+		if plant.NextWatering != nil {
+			plant.NextWateringDisplay = FormatRelativeDay(*plant.NextWatering, now, language, location)
+		}
+	}
+}
+
+// AddNotificationTimeDisplays sets the humanized creation time of notifications, e.g. "5 minutes ago",
+// and the humanized due date of the ones that have one, e.g. "tomorrow"
+func AddNotificationTimeDisplays(notifications []*models.Notification, language models.Language, location *time.Location, now time.Time) {
+	for _, notification := range notifications {
+		notification.CreatedAtDisplay = FormatRelativeTime(notification.CreatedAt, now, language, location)
+		if value, ok := notification.Payload["dueDate"].(string); ok {
+			// Due dates are calendar dates, which fall on the same day wherever the user is
+			if dueDate, err := time.ParseInLocation(notificationDateLayout, value, location); err == nil {
+				notification.DueDateDisplay = FormatRelativeDay(dueDate, now, language, location)
+			}
+		}
+	}
+}
+
+// FormatRelativeTime humanizes a time for a reader in a language at now: in minutes within the hour, in
+// hours within the day and in calendar days of the location beyond, e.g. "2 hours ago" or "через 3 дня".
+// Unsupported languages fall back to Russian.
+func FormatRelativeTime(t time.Time, now time.Time, language models.Language, location *time.Location) string {
+	d := t.Sub(now)
+	ahead := d > 0
+	if d < 0 {
+		d = -d
+	}
+
+	switch {
+	case d < time.Minute:
+		return formatRelativeTime(language, relativeTimeNow, 0)
+	case d < time.Hour && ahead:
+		return formatRelativeTime(language, relativeTimeMinutesAhead, int(d/time.Minute))
+	case d < time.Hour:
+		return formatRelativeTime(language, relativeTimeMinutesAgo, int(d/time.Minute))
+	case d < 24*time.Hour && ahead:
+		return formatRelativeTime(language, relativeTimeHoursAhead, int(d/time.Hour))
+	case d < 24*time.Hour:
+		return formatRelativeTime(language, relativeTimeHoursAgo, int(d/time.Hour))
+	default:
+		return FormatRelativeDay(t, now, language, location)
+	}
+}
+
+// FormatRelativeDay humanizes the day of a time for a reader in a language at now, counting calendar
+// days of the location, e.g. "today", "tomorrow" or "через 3 дня". Unsupported languages fall back to
+// Russian.
+func FormatRelativeDay(t time.Time, now time.Time, language models.Language, location *time.Location) string {
+	days := calendarDays(now.In(location), t.In(location))
+	switch {
+	case days == 0:
+		return formatRelativeTime(language, relativeTimeToday, 0)
+	case days == 1:
+		return formatRelativeTime(language, relativeTimeTomorrow, 1)
+	case days == -1:
+		return formatRelativeTime(language, relativeTimeYesterday, 1)
+	case days > 0:
+		return formatRelativeTime(language, relativeTimeDaysAhead, days)
+	default:
+		return formatRelativeTime(language, relativeTimeDaysAgo, -days)
+	}
+}
+
+// calendarDays counts the calendar days from the date of one time to the date of another. The dates
+// are compared in UTC, where days are 24 hours long, so days clocks change on count as one.
+func calendarDays(from time.Time, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
+// formatRelativeTime renders a phrase of humanized times in a language
+func formatRelativeTime(language models.Language, phrase relativeTime, n int) string {
+	phrases, ok := relativeTimes[language]
+	if !ok {
+		phrases = relativeTimes[models.LanguageRussian]
+	}
+
+	var text bytes.Buffer
+	if err := phrases[phrase].Execute(&text, relativeTimeData{N: n}); err != nil {
+		return ""
+	}
+	return text.String()
+}
+
+// mustLoadRelativeTimes parses the phrases of humanized times and panics if they are invalid, miss a
+// phrase, are in a language without plural rules or miss Russian, the language others fall back to
+func mustLoadRelativeTimes(data []byte) map[models.Language]map[relativeTime]*template.Template {
+	var sources map[models.Language]map[relativeTime]string
+	if err := json.Unmarshal(data, &sources); err != nil {
+		panic(fmt.Sprintf("invalid relative times: %v", err))
+	}
+	if _, ok := sources[models.LanguageRussian]; !ok {
+		panic("relative times have no Russian version")
+	}
+
+	phrases := make(map[models.Language]map[relativeTime]*template.Template, len(sources))
+	for language, source := range sources {
+		rule, ok := pluralRules[language]
+		if !ok {
+			panic(fmt.Sprintf("relative times in %s have no plural rules", language))
+		}
+		funcs := template.FuncMap{
+			"plural": func(n int, forms ...string) string {
+				return forms[min(rule(n), len(forms)-1)]
+			},
+		}
+
+		phrases[language] = make(map[relativeTime]*template.Template)
+		for _, phrase := range []relativeTime{
+			relativeTimeNow, relativeTimeMinutesAhead, relativeTimeMinutesAgo, relativeTimeHoursAhead, relativeTimeHoursAgo,
+			relativeTimeToday, relativeTimeTomorrow, relativeTimeYesterday, relativeTimeDaysAhead, relativeTimeDaysAgo,
+		} {
+			text, ok := source[phrase]
+			if !ok {
+				panic(fmt.Sprintf("relative times in %s have no %s phrase", language, phrase))
+			}
+			parsed, err := template.New(string(language) + "/" + string(phrase)).Funcs(funcs).Parse(text)
+			if err != nil {
+				panic(fmt.Sprintf("invalid %s %s phrase: %v", language, phrase, err))
+			}
+			phrases[language][phrase] = parsed
+		}
+	}
+	return phrases
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatRelativeTime(t *testing.T) {
+	now := time.Date(2024, time.May, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		t       time.Time
+		english string
+		russian string
+	}{
+		{now.Add(-20 * time.Second), "just now", "только что"},
+		{now.Add(-time.Minute), "1 minute ago", "1 минуту назад"},
+		{now.Add(22 * time.Minute), "in 22 minutes", "через 22 минуты"},
+		{now.Add(-3 * time.Hour), "3 hours ago", "3 часа назад"},
+		{now.Add(21 * time.Hour), "in 21 hours", "через 21 час"},
+		{now.Add(-30 * time.Hour), "yesterday", "вчера"},
+		{now.Add(3 * 24 * time.Hour), "in 3 days", "через 3 дня"},
+		{now.Add(-11 * 24 * time.Hour), "11 days ago", "11 дней назад"},
+	} {
+		assert.Equal(t, tc.english, FormatRelativeTime(tc.t, now, models.LanguageEnglish, time.UTC))
+		assert.Equal(t, tc.russian, FormatRelativeTime(tc.t, now, models.LanguageRussian, time.UTC))
+	}
+
+	// Unsupported languages fall back to Russian
+	assert.Equal(t, "через 5 минут", FormatRelativeTime(now.Add(5*time.Minute), now, models.Language("GERMAN"), time.UTC))
+}
+
+// TestFormatRelativeDay tests that days are counted in calendar days of the reader, also across the
+// days clocks change on
+func TestFormatRelativeDay(t *testing.T) {
+	now := time.Date(2024, time.May, 10, 22, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "today", FormatRelativeDay(now.Add(time.Hour), now, models.LanguageEnglish, time.UTC))
+	assert.Equal(t, "tomorrow", FormatRelativeDay(now.Add(2*time.Hour), now, models.LanguageEnglish, time.UTC))
+
+	// 22:30 UTC is already 08:30 the next day in Vladivostok
+	vladivostok := mustLoadLocation(t, "Asia/Vladivostok")
+	assert.Equal(t, "сегодня", FormatRelativeDay(now.Add(2*time.Hour), now, models.LanguageRussian, vladivostok))
+	assert.Equal(t, "вчера", FormatRelativeDay(now.Add(-9*time.Hour), now, models.LanguageRussian, vladivostok))
+
+	// A week across the night clocks went forward in Berlin is still seven days
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	before := time.Date(2024, time.March, 28, 0, 30, 0, 0, berlin)
+	assert.Equal(t, "in 7 days", FormatRelativeDay(before.AddDate(0, 0, 7), before, models.LanguageEnglish, berlin))
+	assert.Equal(t, "через 21 день", FormatRelativeDay(before.AddDate(0, 0, 21), before, models.LanguageRussian, berlin))
+}
+
+func TestAddNotificationTimeDisplays(t *testing.T) {
+	now := time.Date(2024, time.May, 10, 12, 0, 0, 0, time.UTC)
+	notifications := []*models.Notification{
+		{CreatedAt: now.Add(-2 * time.Hour), Payload: models.NotificationPayload{"dueDate": "2024-05-11"}},
+		{CreatedAt: now.Add(-5 * time.Minute), Payload: models.NotificationPayload{"dueDate": "not a date"}},
+	}
+
+	AddNotificationTimeDisplays(notifications, models.LanguageEnglish, time.UTC, now)
+
+	assert.Equal(t, "2 hours ago", notifications[0].CreatedAtDisplay)
+	assert.Equal(t, "tomorrow", notifications[0].DueDateDisplay)
+	assert.Equal(t, "5 minutes ago", notifications[1].CreatedAtDisplay)
+	assert.Empty(t, notifications[1].DueDateDisplay)
+}
+
+func TestPluralRules(t *testing.T) {
+	russian := pluralRules[models.LanguageRussian]
+	for n, form := range map[int]int{1: 0, 2: 1, 4: 1, 5: 2, 11: 2, 12: 2, 14: 2, 21: 0, 22: 1, 25: 2, 101: 0, 111: 2} {
+		assert.Equal(t, form, russian(n), "n=%d", n)
+	}
+}
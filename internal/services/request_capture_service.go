@@ -0,0 +1,398 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrReplayUnavailable is returned when a request is replayed while no replay target is configured
+	ErrReplayUnavailable = errors.New("replay of captured requests is not configured")
+
+	// ErrCapturedBodyIncomplete is returned when a request whose body was not kept in full is replayed
+	ErrCapturedBodyIncomplete = errors.New("the body of the captured request was not kept in full")
+)
+
+const (
+	// redactedValue replaces the credentials and secrets of a captured request
+	redactedValue = "[REDACTED]"
+
+	// ReplayedRequestHeader is the header a replayed request carries the ID of its captured request in
+	ReplayedRequestHeader = "X-Replayed-Request-Id"
+
+	// replayTokenDuration is how long the token a request is replayed with is valid
+	replayTokenDuration = 15 * time.Minute
+
+	// replayTimeout is how long a replayed request may take
+	replayTimeout = 30 * time.Second
+
+	// maxReplayResponseBytes is the number of bytes of a replayed response body that are returned
+	maxReplayResponseBytes = 1 << 20
+
+	// captureSaveTimeout is how long saving a captured request may take
+	captureSaveTimeout = 5 * time.Second
+
+	// capturePruneInterval is how often requests captured before the retention period are deleted at most
+	capturePruneInterval = time.Hour
+)
+
+// redactedHeaders are the headers holding credentials or the client's address; they are kept redacted
+// so the captures show they were sent
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+	"X-Forwarded-For":     true,
+	"X-Real-Ip":           true,
+}
+
+// replaySkippedHeaders are the headers of a captured request that are not sent again when it is replayed;
+// they describe the original connection and are set for the replayed one by the HTTP client
+var replaySkippedHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Accept-Encoding":   true,
+	"Keep-Alive":        true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+}
+
+// sensitiveFields are the parts of body field names whose values are redacted, compared in lower case
+// without separators
+var sensitiveFields = []string{"password", "token", "secret", "apikey", "authorization"}
+
+// ReplayTokenSigner signs the tokens requests are replayed with, using the replay target's JWT secret
+type ReplayTokenSigner interface {
+	GenerateToken(userID uuid.UUID, duration time.Duration) (string, error)
+}
+
+// RequestCaptureService records the requests answered with a server error, with their credentials and
+// secrets redacted, and replays them against a staging instance as a chosen user to reproduce failures
+type RequestCaptureService struct {
+	capturedRequestRepo repository.CapturedRequestRepository
+	enabled             bool
+	maxBodyBytes        int
+	retention           time.Duration
+	replayTarget        *url.URL
+	replaySigner        ReplayTokenSigner
+	client              *http.Client
+	now                 func() time.Time
+
+	pruneMu   sync.Mutex
+	lastPrune time.Time
+}
+
+// NewRequestCaptureService creates a new request capture service keeping up to maxBodyBytes of a request
+// body and deleting captures after retentionDays; they are kept when it is 0. Requests are captured only
+// when enabled, while the captures already stored can always be listed and replayed.
+func NewRequestCaptureService(
+	capturedRequestRepo repository.CapturedRequestRepository,
+	enabled bool,
+	maxBodyBytes int,
+	retentionDays int,
+) *RequestCaptureService {
+	return &RequestCaptureService{
+		capturedRequestRepo: capturedRequestRepo,
+		enabled:             enabled,
+		maxBodyBytes:        maxBodyBytes,
+		retention:           time.Duration(retentionDays) * 24 * time.Hour,
+		client: &http.Client{
+			Timeout: replayTimeout,
+			// Answer redirects with the redirect so a replay never leaves the target
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// SetReplayTarget sets the base URL of the staging instance captured requests are replayed against and
+// the signer of the tokens that act as the chosen user there
+func (s *RequestCaptureService) SetReplayTarget(targetURL string, signer ReplayTokenSigner) error {
+	target, err := url.Parse(targetURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("invalid replay target %q", targetURL)
+	}
+	target.Path = strings.TrimSuffix(target.Path, "/")
+	target.RawQuery = ""
+	target.Fragment = ""
+
+	s.replayTarget = target
+	s.replaySigner = signer
+	return nil
+}
+
+// Enabled checks if requests answered with a server error are captured
+func (s *RequestCaptureService) Enabled() bool {
+	return s.enabled
+}
+
+// MaxCapturedBodyBytes is the number of bytes of a request body kept for the recording
+func (s *RequestCaptureService) MaxCapturedBodyBytes() int {
+	return s.maxBodyBytes
+}
+
+// RecordFailedRequest stores a failed request with its credentials and secrets redacted.
+// Failures are only logged since the request was already answered.
+func (s *RequestCaptureService) RecordFailedRequest(request middleware.FailedRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), captureSaveTimeout)
+	defer cancel()
+
+	body, bodyStatus := sanitizeBody(request.Header.Get("Content-Type"), request.Body, request.BodyTruncated)
+	captured := &models.CapturedRequest{
+		Method:     request.Method,
+		Path:       request.Path,
+		Query:      sanitizeQuery(request.Query),
+		Headers:    sanitizeHeaders(request.Header),
+		Body:       body,
+		BodyStatus: bodyStatus,
+		UserID:     request.UserID,
+		Status:     request.Status,
+		DurationMs: request.Duration.Milliseconds(),
+	}
+	if err := s.capturedRequestRepo.Create(ctx, captured); err != nil {
+		log.Printf("Failed to capture failed request %s %s: %v", request.Method, request.Path, err)
+		return
+	}
+	log.Printf("Captured failed request %s: %s %s - %d", captured.ID, captured.Method, captured.Path, captured.Status)
+
+	s.prune(ctx)
+}
+
+// ListCaptured gets the most recently captured requests
+func (s *RequestCaptureService) ListCaptured(ctx context.Context, limit int) ([]*models.CapturedRequest, error) {
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	requests, err := s.capturedRequestRepo.List(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list captured requests: %w", err)
+	}
+	return requests, nil
+}
+
+// GetCaptured gets a captured request by ID
+func (s *RequestCaptureService) GetCaptured(ctx context.Context, id uuid.UUID) (*models.CapturedRequest, error) {
+	return s.capturedRequestRepo.GetByID(ctx, id)
+}
+
+// Replay sends a captured request again to the replay target, authenticated as the given user of the
+// target, and returns the response. Redacted headers are not sent; secrets in the body stay redacted.
+func (s *RequestCaptureService) Replay(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.ReplayResult, error) {
+	if s.replayTarget == nil {
+		return nil, ErrReplayUnavailable
+	}
+
+	// Get the captured request
+	captured, err := s.capturedRequestRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if captured.BodyStatus != models.CapturedBodyComplete {
+		return nil, ErrCapturedBodyIncomplete
+	}
+
+	// Build the request against the target
+	target := *s.replayTarget
+	target.Path += captured.Path
+	target.RawQuery = captured.Query
+	req, err := http.NewRequestWithContext(ctx, captured.Method, target.String(), strings.NewReader(captured.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build replayed request: %w", err)
+	}
+	for name, values := range captured.Headers {
+		name = http.CanonicalHeaderKey(name)
+		if redactedHeaders[name] || replaySkippedHeaders[name] || name == "Host" {
+			continue
+		}
+		req.Header[name] = values
+	}
+
+	// Act as the chosen user
+	token, err := s.replaySigner.GenerateToken(userID, replayTokenDuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate replay token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(ReplayedRequestHeader, captured.ID.String())
+
+	// Send the request
+	start := s.now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReplayResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replayed response: %w", err)
+	}
+	result := &models.ReplayResult{
+		RequestID:  captured.ID,
+		Target:     target.String(),
+		UserID:     userID,
+		Status:     resp.StatusCode,
+		Headers:    resp.Header,
+		DurationMs: s.now().Sub(start).Milliseconds(),
+	}
+	if len(body) > maxReplayResponseBytes {
+		body = body[:maxReplayResponseBytes]
+		result.BodyTruncated = true
+	}
+	result.Body = string(body)
+
+	log.Printf("Replayed captured request %s against %s as user %s: %d", captured.ID, result.Target, userID, result.Status)
+	return result, nil
+}
+
+// prune deletes the requests captured before the retention period, at most once an hour
+func (s *RequestCaptureService) prune(ctx context.Context) {
+	if s.retention <= 0 {
+		return
+	}
+
+	now := s.now()
+	s.pruneMu.Lock()
+	if now.Sub(s.lastPrune) < capturePruneInterval {
+		s.pruneMu.Unlock()
+		return
+	}
+	s.lastPrune = now
+	s.pruneMu.Unlock()
+
+	if _, err := s.capturedRequestRepo.DeleteBefore(ctx, now.Add(-s.retention)); err != nil {
+		log.Printf("Failed to delete old captured requests: %v", err)
+	}
+}
+
+// sanitizeHeaders copies the headers of a request with the credentials and the client's address redacted
+func sanitizeHeaders(header http.Header) models.CapturedHeaders {
+	headers := make(models.CapturedHeaders, len(header))
+	for name, values := range header {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			headers[name] = []string{redactedValue}
+			continue
+		}
+		headers[name] = append([]string(nil), values...)
+	}
+	return headers
+}
+
+// sanitizeQuery redacts the values of the sensitive query parameters
+func sanitizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ""
+	}
+	if !redactValues(query) {
+		return rawQuery
+	}
+	return query.Encode()
+}
+
+// sanitizeBody returns the body of a request to keep, with the values of sensitive fields redacted.
+// Bodies that cannot be redacted, such as truncated JSON, and bodies that are not text are not kept.
+func sanitizeBody(contentType string, body []byte, truncated bool) (string, models.CapturedBodyStatus) {
+	if len(body) == 0 {
+		return "", models.CapturedBodyComplete
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if truncated {
+			return "", models.CapturedBodyTruncated
+		}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return "", models.CapturedBodyOmitted
+		}
+		redacted, err := json.Marshal(redactJSON(value))
+		if err != nil {
+			return "", models.CapturedBodyOmitted
+		}
+		return string(redacted), models.CapturedBodyComplete
+	case mediaType == "application/x-www-form-urlencoded":
+		if truncated {
+			return "", models.CapturedBodyTruncated
+		}
+		return sanitizeQuery(string(body)), models.CapturedBodyComplete
+	case strings.HasPrefix(mediaType, "text/"):
+		if truncated {
+			return string(body), models.CapturedBodyTruncated
+		}
+		return string(body), models.CapturedBodyComplete
+	default:
+		return "", models.CapturedBodyOmitted
+	}
+}
+
+// redactJSON redacts the values of the sensitive fields of a decoded JSON value, at any depth
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitiveField(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactJSON(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+// redactValues redacts the values of the sensitive keys and reports whether any was redacted
+func redactValues(values url.Values) bool {
+	redacted := false
+	for key := range values {
+		if isSensitiveField(key) {
+			values[key] = []string{redactedValue}
+			redacted = true
+		}
+	}
+	return redacted
+}
+
+// isSensitiveField checks if a field name holds a credential or a secret
+func isSensitiveField(name string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	for _, sensitive := range sensitiveFields {
+		if strings.Contains(normalized, sensitive) {
+			return true
+		}
+	}
+	return false
+}
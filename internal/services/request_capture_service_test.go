@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/middleware"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCapturedRequestRepository is a mock implementation of the CapturedRequestRepository interface
+type MockCapturedRequestRepository struct {
+	mock.Mock
+}
+
+func (m *MockCapturedRequestRepository) Create(ctx context.Context, request *models.CapturedRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockCapturedRequestRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.CapturedRequest, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CapturedRequest), args.Error(1)
+}
+
+func (m *MockCapturedRequestRepository) List(ctx context.Context, limit int) ([]*models.CapturedRequest, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]*models.CapturedRequest), args.Error(1)
+}
+
+func (m *MockCapturedRequestRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// TestRequestCaptureService_RecordFailedRequest tests that credentials, the client's address and secrets
+// in the body and query are redacted before a failed request is stored, and old captures are pruned
+func TestRequestCaptureService_RecordFailedRequest(t *testing.T) {
+	mockRepo := new(MockCapturedRequestRepository)
+	service := NewRequestCaptureService(mockRepo, true, 1024, 14)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	userID := uuid.New()
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret-jwt")
+	header.Set("X-API-Key", "pk_live_123")
+	header.Set("X-Forwarded-For", "203.0.113.7")
+	header.Set("Content-Type", "application/json")
+	header.Set("Accept-Language", "en")
+
+	var stored *models.CapturedRequest
+	mockRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.CapturedRequest)
+	}).Return(nil)
+	mockRepo.On("DeleteBefore", mock.Anything, now.Add(-14*24*time.Hour)).Return(int64(3), nil).Once()
+
+	service.RecordFailedRequest(middleware.FailedRequest{
+		Method:   http.MethodPost,
+		Path:     "/auth/change-password",
+		Query:    "lang=en&token=abc",
+		Header:   header,
+		Body:     []byte(`{"oldPassword":"hunter2","new_password":"hunter3","profile":{"apiKey":"k"},"amount":12345678901234567890}`),
+		UserID:   &userID,
+		Status:   http.StatusInternalServerError,
+		Duration: 250 * time.Millisecond,
+	})
+
+	// Assert
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, []string{redactedValue}, stored.Headers["Authorization"])
+		assert.Equal(t, []string{redactedValue}, stored.Headers["X-Api-Key"])
+		assert.Equal(t, []string{redactedValue}, stored.Headers["X-Forwarded-For"])
+		assert.Equal(t, []string{"en"}, stored.Headers["Accept-Language"])
+		assert.Equal(t, "lang=en&token=%5BREDACTED%5D", stored.Query)
+		assert.JSONEq(t, `{"oldPassword":"[REDACTED]","new_password":"[REDACTED]","profile":{"apiKey":"[REDACTED]"},"amount":12345678901234567890}`, stored.Body)
+		assert.Equal(t, models.CapturedBodyComplete, stored.BodyStatus)
+		assert.Equal(t, &userID, stored.UserID)
+		assert.Equal(t, int64(250), stored.DurationMs)
+	}
+
+	// Captures are pruned at most once an hour
+	service.RecordFailedRequest(middleware.FailedRequest{Method: http.MethodGet, Path: "/plants", Header: http.Header{}, Status: http.StatusBadGateway})
+	mockRepo.AssertNumberOfCalls(t, "Create", 2)
+	mockRepo.AssertNumberOfCalls(t, "DeleteBefore", 1)
+}
+
+// TestSanitizeBody tests which bodies are kept and how
+func TestSanitizeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		truncated   bool
+		wantBody    string
+		wantStatus  models.CapturedBodyStatus
+	}{
+		{name: "empty", contentType: "", body: "", wantBody: "", wantStatus: models.CapturedBodyComplete},
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: "email=a%40b.c&password=p", wantBody: "email=a%40b.c&password=%5BREDACTED%5D", wantStatus: models.CapturedBodyComplete},
+		{name: "text", contentType: "text/plain; charset=utf-8", body: "hello", wantBody: "hello", wantStatus: models.CapturedBodyComplete},
+		{name: "truncated text", contentType: "text/plain", body: "hel", truncated: true, wantBody: "hel", wantStatus: models.CapturedBodyTruncated},
+		{name: "truncated JSON", contentType: "application/json", body: `{"password":"hun`, truncated: true, wantBody: "", wantStatus: models.CapturedBodyTruncated},
+		{name: "invalid JSON", contentType: "application/json", body: `{"password":`, wantBody: "", wantStatus: models.CapturedBodyOmitted},
+		{name: "photo", contentType: "multipart/form-data; boundary=x", body: "--x", wantBody: "", wantStatus: models.CapturedBodyOmitted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, status := sanitizeBody(tt.contentType, []byte(tt.body), tt.truncated)
+			assert.Equal(t, tt.wantBody, body)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+// TestRequestCaptureService_Replay tests that a captured request is replayed against the target as the
+// chosen user, without the redacted headers, and the target's response is returned
+func TestRequestCaptureService_Replay(t *testing.T) {
+	secret := "staging-secret"
+	userID := uuid.New()
+	requestID := uuid.New()
+
+	var received *http.Request
+	var receivedBody string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "boom"})
+	}))
+	defer target.Close()
+
+	mockRepo := new(MockCapturedRequestRepository)
+	service := NewRequestCaptureService(mockRepo, true, 1024, 0)
+	assert.NoError(t, service.SetReplayTarget(target.URL+"/api/", middleware.NewAuth(secret)))
+
+	mockRepo.On("GetByID", mock.Anything, requestID).Return(&models.CapturedRequest{
+		ID:     requestID,
+		Method: http.MethodPut,
+		Path:   "/plants/123",
+		Query:  "lang=en",
+		Headers: models.CapturedHeaders{
+			"Authorization":  {redactedValue},
+			"Content-Type":   {"application/json"},
+			"Content-Length": {"17"},
+			"X-App-Version":  {"2.4.0"},
+		},
+		Body:       `{"name":"Ficus"}`,
+		BodyStatus: models.CapturedBodyComplete,
+	}, nil)
+
+	// Act
+	result, err := service.Replay(context.Background(), requestID, userID)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.NotNil(t, received) {
+		assert.Equal(t, http.MethodPut, received.Method)
+		assert.Equal(t, "/api/plants/123", received.URL.Path)
+		assert.Equal(t, "lang=en", received.URL.RawQuery)
+		assert.Equal(t, "2.4.0", received.Header.Get("X-App-Version"))
+		assert.Equal(t, requestID.String(), received.Header.Get(ReplayedRequestHeader))
+		assert.Equal(t, `{"name":"Ficus"}`, receivedBody)
+
+		// The request is authenticated as the chosen user of the target
+		var seenUser uuid.UUID
+		handler := middleware.NewAuth(secret).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seenUser, _ = middleware.GetUserID(r.Context())
+		}))
+		check := httptest.NewRequest(http.MethodGet, "/", nil)
+		check.Header.Set("Authorization", received.Header.Get("Authorization"))
+		handler.ServeHTTP(httptest.NewRecorder(), check)
+		assert.Equal(t, userID, seenUser)
+	}
+	if assert.NotNil(t, result) {
+		assert.Equal(t, http.StatusInternalServerError, result.Status)
+		assert.Equal(t, target.URL+"/api/plants/123?lang=en", result.Target)
+		assert.JSONEq(t, `{"error":"boom"}`, result.Body)
+		assert.False(t, result.BodyTruncated)
+	}
+}
+
+// TestRequestCaptureService_Replay_Unavailable tests that nothing is replayed without a target or with
+// an incomplete body
+func TestRequestCaptureService_Replay_Unavailable(t *testing.T) {
+	mockRepo := new(MockCapturedRequestRepository)
+	service := NewRequestCaptureService(mockRepo, true, 1024, 0)
+	requestID := uuid.New()
+
+	_, err := service.Replay(context.Background(), requestID, uuid.New())
+	assert.ErrorIs(t, err, ErrReplayUnavailable)
+
+	assert.Error(t, service.SetReplayTarget("ftp://staging", middleware.NewAuth("secret")))
+	assert.NoError(t, service.SetReplayTarget("https://staging.planter.app", middleware.NewAuth("secret")))
+	mockRepo.On("GetByID", mock.Anything, requestID).Return(&models.CapturedRequest{
+		ID:         requestID,
+		Method:     http.MethodPost,
+		Path:       "/plants/diagnose",
+		BodyStatus: models.CapturedBodyOmitted,
+	}, nil)
+
+	_, err = service.Replay(context.Background(), requestID, uuid.New())
+	assert.ErrorIs(t, err, ErrCapturedBodyIncomplete)
+}
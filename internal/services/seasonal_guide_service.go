@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/anpanovv/planter/internal/cache"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSeasonalGuide is returned when a seasonal guide is asked for an unknown month or hemisphere
+var ErrInvalidSeasonalGuide = errors.New("invalid seasonal guide request")
+
+const (
+	// seasonalGuideListSize is the most plants a list of the seasonal guide shows
+	seasonalGuideListSize = 10
+
+	// SeasonalGuideCacheTTL is how long a built seasonal guide is served; guides are rebuilt daily
+	SeasonalGuideCacheTTL = 24 * time.Hour
+
+	// coldHardyTemperature is the lowest temperature, in °C, a plant bought in winter must tolerate to
+	// survive the way home
+	coldHardyTemperature = 12
+
+	// fastGrowerFertilizerFrequency is the longest interval, in days, between feedings of a plant growing
+	// fast enough to outgrow its pot during the growing season
+	fastGrowerFertilizerFrequency = 14
+)
+
+// propagationMonths are the months of the northern hemisphere cuttings root best in, when plants grow fastest
+var propagationMonths = map[time.Month]bool{
+	time.May: true, time.June: true, time.July: true,
+}
+
+// seasonalGuideRule tells whether a plant belongs to a list of the seasonal guide in a month of the
+// northern hemisphere, and why
+type seasonalGuideRule func(plant *models.Plant, season time.Month) (models.SeasonalGuideReason, bool)
+
+// seasonalGuideRules picks the plants of each list of the seasonal guide by their catalog attributes
+var seasonalGuideRules = map[models.SeasonalGuideList]seasonalGuideRule{
+	models.SeasonalGuideListBuy: func(plant *models.Plant, season time.Month) (models.SeasonalGuideReason, bool) {
+		if plant.ShopID == nil {
+			return "", false
+		}
+		if dormantMonths[season] {
+			return models.SeasonalGuideReasonColdHardy, plant.CareInstructions.Temperature.Min <= coldHardyTemperature
+		}
+		return models.SeasonalGuideReasonInShop, true
+	},
+	models.SeasonalGuideListPropagate: func(plant *models.Plant, season time.Month) (models.SeasonalGuideReason, bool) {
+		return models.SeasonalGuideReasonGrowthPeak, propagationMonths[season]
+	},
+	models.SeasonalGuideListRepot: func(plant *models.Plant, season time.Month) (models.SeasonalGuideReason, bool) {
+		fertilizerFrequency := plant.CareInstructions.FertilizerFrequency
+		switch {
+		case repotMonths[season]:
+			return models.SeasonalGuideReasonRepotWindow, true
+		case growingMonths[season] && fertilizerFrequency > 0 && fertilizerFrequency <= fastGrowerFertilizerFrequency:
+			return models.SeasonalGuideReasonFastGrower, true
+		}
+		return "", false
+	},
+}
+
+// SeasonalGuideService assembles the plants best bought, propagated and repotted each month from the
+// catalog and the choices of admins
+type SeasonalGuideService struct {
+	guideRepo repository.SeasonalGuideRepository
+	plantRepo repository.PlantRepository
+	cache     cache.Cache // nil when every request builds the guide
+	now       func() time.Time
+}
+
+// NewSeasonalGuideService creates a new seasonal guide service
+func NewSeasonalGuideService(guideRepo repository.SeasonalGuideRepository, plantRepo repository.PlantRepository) *SeasonalGuideService {
+	return &SeasonalGuideService{
+		guideRepo: guideRepo,
+		plantRepo: plantRepo,
+		now:       time.Now,
+	}
+}
+
+// SetCache sets the cache built guides are served from for the rest of the day
+func (s *SeasonalGuideService) SetCache(guideCache cache.Cache) {
+	s.cache = guideCache
+}
+
+// GetGuide gets the seasonal guide of a month, the current one when month is 0, in a hemisphere,
+// the northern one when hemisphere is empty
+func (s *SeasonalGuideService) GetGuide(ctx context.Context, month int, hemisphere models.Hemisphere) (*models.SeasonalGuide, error) {
+	now := s.now().UTC()
+	if month == 0 {
+		month = int(now.Month())
+	}
+	if month < 1 || month > 12 {
+		return nil, fmt.Errorf("%w: month must be between 1 and 12", ErrInvalidSeasonalGuide)
+	}
+	if hemisphere == "" {
+		hemisphere = models.HemisphereNorthern
+	}
+	if hemisphere != models.HemisphereNorthern && hemisphere != models.HemisphereSouthern {
+		return nil, fmt.Errorf("%w: hemisphere must be NORTHERN or SOUTHERN", ErrInvalidSeasonalGuide)
+	}
+
+	if s.cache == nil {
+		return s.buildGuide(ctx, month, hemisphere, now)
+	}
+
+	// The day is part of the key, so a guide is rebuilt at least daily
+	key := fmt.Sprintf("%s:%d:%s", now.Format("2006-01-02"), month, hemisphere)
+	data, err := s.cache.Load(ctx, key, func() ([]byte, error) {
+		guide, err := s.buildGuide(ctx, month, hemisphere, now)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(guide)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var guide models.SeasonalGuide
+	if err := json.Unmarshal(data, &guide); err != nil {
+		return nil, fmt.Errorf("failed to decode cached seasonal guide: %w", err)
+	}
+	return &guide, nil
+}
+
+// buildGuide assembles the lists of the seasonal guide of a month from the published plants
+func (s *SeasonalGuideService) buildGuide(ctx context.Context, month int, hemisphere models.Hemisphere, now time.Time) (*models.SeasonalGuide, error) {
+	plants, err := s.plantRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plants: %w", err)
+	}
+	owners, err := s.guideRepo.GetOwnerCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.guideRepo.ListOverrides(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+
+	season := seasonMonth(time.Month(month), hemisphere)
+	return &models.SeasonalGuide{
+		Month:       month,
+		Hemisphere:  hemisphere,
+		Buy:         seasonalGuideList(models.SeasonalGuideListBuy, season, plants, owners, overrides),
+		Propagate:   seasonalGuideList(models.SeasonalGuideListPropagate, season, plants, owners, overrides),
+		Repot:       seasonalGuideList(models.SeasonalGuideListRepot, season, plants, owners, overrides),
+		GeneratedAt: now,
+	}, nil
+}
+
+// seasonalGuideList lists the plants pinned to a list by position, then the plants its rule picks,
+// the most owned first, leaving out the plants hidden from it. Pinned plants that are not published
+// are left out too.
+func seasonalGuideList(
+	list models.SeasonalGuideList,
+	season time.Month,
+	plants []*models.Plant,
+	owners map[uuid.UUID]int,
+	overrides []*models.SeasonalGuideOverride,
+) []*models.SeasonalGuideItem {
+	byID := make(map[uuid.UUID]*models.Plant, len(plants))
+	for _, plant := range plants {
+		byID[plant.ID] = plant
+	}
+
+	items := []*models.SeasonalGuideItem{}
+	overridden := make(map[uuid.UUID]bool)
+	for _, override := range overrides {
+		if override.List != list {
+			continue
+		}
+		overridden[override.PlantID] = true
+		if plant, ok := byID[override.PlantID]; ok && !override.Hidden {
+			items = append(items, &models.SeasonalGuideItem{Plant: plant, Reason: models.SeasonalGuideReasonPinned, Note: override.Note})
+		}
+	}
+
+	var picked []*models.SeasonalGuideItem
+	for _, plant := range plants {
+		if overridden[plant.ID] {
+			continue
+		}
+		if reason, ok := seasonalGuideRules[list](plant, season); ok {
+			picked = append(picked, &models.SeasonalGuideItem{Plant: plant, Reason: reason})
+		}
+	}
+	// Plants come by name, which stays the order of equally owned plants
+	sort.SliceStable(picked, func(i, j int) bool {
+		return owners[picked[i].Plant.ID] > owners[picked[j].Plant.ID]
+	})
+
+	items = append(items, picked...)
+	if len(items) > seasonalGuideListSize {
+		items = items[:seasonalGuideListSize]
+	}
+	return items
+}
+
+// ListOverrides gets the overrides of a month, or of every month when month is 0
+func (s *SeasonalGuideService) ListOverrides(ctx context.Context, month int) ([]*models.SeasonalGuideOverride, error) {
+	if month < 0 || month > 12 {
+		return nil, fmt.Errorf("%w: month must be between 1 and 12", ErrInvalidSeasonalGuide)
+	}
+	return s.guideRepo.ListOverrides(ctx, month)
+}
+
+// SetOverride pins a plant to a list of the guide of a month or hides it from it, replacing the
+// previous choice for the plant, and drops the cached guides
+func (s *SeasonalGuideService) SetOverride(ctx context.Context, req *models.SeasonalGuideOverrideRequest) (*models.SeasonalGuideOverride, error) {
+	if _, err := s.plantRepo.GetByID(ctx, req.PlantID); err != nil {
+		return nil, err
+	}
+
+	override := &models.SeasonalGuideOverride{
+		Month:    req.Month,
+		List:     req.List,
+		PlantID:  req.PlantID,
+		Hidden:   req.Hidden,
+		Position: req.Position,
+		Note:     req.Note,
+	}
+	if err := s.guideRepo.UpsertOverride(ctx, override); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return override, nil
+}
+
+// DeleteOverride removes an override and drops the cached guides
+func (s *SeasonalGuideService) DeleteOverride(ctx context.Context, id uuid.UUID) error {
+	if err := s.guideRepo.DeleteOverride(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+// invalidate drops the cached guides, so changes of admins show at once
+func (s *SeasonalGuideService) invalidate(ctx context.Context) {
+	if s.cache != nil {
+		s.cache.Invalidate(ctx)
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/cache"
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSeasonalGuideRepository is a mock implementation of the SeasonalGuideRepository interface
+type MockSeasonalGuideRepository struct {
+	mock.Mock
+}
+
+func (m *MockSeasonalGuideRepository) ListOverrides(ctx context.Context, month int) ([]*models.SeasonalGuideOverride, error) {
+	args := m.Called(ctx, month)
+	return args.Get(0).([]*models.SeasonalGuideOverride), args.Error(1)
+}
+
+func (m *MockSeasonalGuideRepository) UpsertOverride(ctx context.Context, override *models.SeasonalGuideOverride) error {
+	args := m.Called(ctx, override)
+	return args.Error(0)
+}
+
+func (m *MockSeasonalGuideRepository) DeleteOverride(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockSeasonalGuideRepository) GetOwnerCounts(ctx context.Context) (map[uuid.UUID]int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[uuid.UUID]int), args.Error(1)
+}
+
+// seasonalGuidePlantIDs returns the IDs of the plants of a list of the seasonal guide
+func seasonalGuidePlantIDs(items []*models.SeasonalGuideItem) []uuid.UUID {
+	ids := []uuid.UUID{}
+	for _, item := range items {
+		ids = append(ids, item.Plant.ID)
+	}
+	return ids
+}
+
+// TestSeasonalGuideService_GetGuide tests that the lists follow the season of the hemisphere, the
+// choices of admins and the number of owners
+func TestSeasonalGuideService_GetGuide(t *testing.T) {
+	mockGuideRepo := new(MockSeasonalGuideRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewSeasonalGuideService(mockGuideRepo, mockPlantRepo)
+	service.now = func() time.Time { return time.Date(2024, time.May, 10, 9, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	shopID := uuid.New().String()
+	ficus := &models.Plant{ID: uuid.New(), Name: "Ficus", ShopID: &shopID, CareInstructions: models.CareInstructions{FertilizerFrequency: 30, Temperature: models.TemperatureRange{Min: 15}}}
+	monstera := &models.Plant{ID: uuid.New(), Name: "Monstera", ShopID: &shopID, CareInstructions: models.CareInstructions{FertilizerFrequency: 14, Temperature: models.TemperatureRange{Min: 10}}}
+	zamioculcas := &models.Plant{ID: uuid.New(), Name: "Zamioculcas", CareInstructions: models.CareInstructions{FertilizerFrequency: 30}}
+	note := "Цветёт к лету"
+	mockPlantRepo.On("GetAll", ctx).Return([]*models.Plant{ficus, monstera, zamioculcas}, nil)
+	mockGuideRepo.On("GetOwnerCounts", ctx).Return(map[uuid.UUID]int{monstera.ID: 7, ficus.ID: 2}, nil)
+	mockGuideRepo.On("ListOverrides", ctx, 5).Return([]*models.SeasonalGuideOverride{
+		{Month: 5, List: models.SeasonalGuideListBuy, PlantID: zamioculcas.ID, Note: &note},
+		{Month: 5, List: models.SeasonalGuideListPropagate, PlantID: monstera.ID, Hidden: true},
+	}, nil)
+
+	// May: everything sold can be bought, everything propagated and the heavy feeders repotted
+	guide, err := service.GetGuide(ctx, 0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, guide.Month)
+	assert.Equal(t, models.HemisphereNorthern, guide.Hemisphere)
+	assert.Equal(t, []uuid.UUID{zamioculcas.ID, monstera.ID, ficus.ID}, seasonalGuidePlantIDs(guide.Buy))
+	assert.Equal(t, models.SeasonalGuideReasonPinned, guide.Buy[0].Reason)
+	assert.Equal(t, &note, guide.Buy[0].Note)
+	assert.Equal(t, []uuid.UUID{ficus.ID, zamioculcas.ID}, seasonalGuidePlantIDs(guide.Propagate))
+	if assert.Len(t, guide.Repot, 1) {
+		assert.Equal(t, monstera.ID, guide.Repot[0].Plant.ID)
+		assert.Equal(t, models.SeasonalGuideReasonFastGrower, guide.Repot[0].Reason)
+	}
+
+	// November in the southern hemisphere is May in the northern one
+	mockGuideRepo.On("ListOverrides", ctx, 11).Return([]*models.SeasonalGuideOverride{}, nil)
+	guide, err = service.GetGuide(ctx, 11, models.HemisphereSouthern)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{monstera.ID, ficus.ID, zamioculcas.ID}, seasonalGuidePlantIDs(guide.Propagate))
+
+	// November in the northern hemisphere: only cold hardy plants are worth buying, nothing else
+	guide, err = service.GetGuide(ctx, 11, models.HemisphereNorthern)
+	assert.NoError(t, err)
+	if assert.Len(t, guide.Buy, 1) {
+		assert.Equal(t, monstera.ID, guide.Buy[0].Plant.ID)
+		assert.Equal(t, models.SeasonalGuideReasonColdHardy, guide.Buy[0].Reason)
+	}
+	assert.Empty(t, guide.Propagate)
+	assert.Empty(t, guide.Repot)
+
+	_, err = service.GetGuide(ctx, 13, "")
+	assert.ErrorIs(t, err, ErrInvalidSeasonalGuide)
+	_, err = service.GetGuide(ctx, 5, "EQUATORIAL")
+	assert.ErrorIs(t, err, ErrInvalidSeasonalGuide)
+}
+
+// TestSeasonalGuideService_GetGuide_Cache tests that a guide is built once a day until an admin changes it
+func TestSeasonalGuideService_GetGuide_Cache(t *testing.T) {
+	mockGuideRepo := new(MockSeasonalGuideRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewSeasonalGuideService(mockGuideRepo, mockPlantRepo)
+	service.SetCache(cache.NewMemory(SeasonalGuideCacheTTL, 0))
+	now := time.Date(2024, time.March, 10, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	plant := &models.Plant{ID: uuid.New(), Name: "Ficus"}
+	mockPlantRepo.On("GetAll", ctx).Return([]*models.Plant{plant}, nil)
+	mockPlantRepo.On("GetByID", ctx, plant.ID).Return(plant, nil)
+	mockGuideRepo.On("GetOwnerCounts", ctx).Return(map[uuid.UUID]int{}, nil)
+	mockGuideRepo.On("ListOverrides", ctx, 3).Return([]*models.SeasonalGuideOverride{}, nil)
+	mockGuideRepo.On("UpsertOverride", ctx, mock.AnythingOfType("*models.SeasonalGuideOverride")).Return(nil)
+
+	for i := 0; i < 2; i++ {
+		guide, err := service.GetGuide(ctx, 3, "")
+		assert.NoError(t, err)
+		assert.Len(t, guide.Repot, 1)
+	}
+	mockPlantRepo.AssertNumberOfCalls(t, "GetAll", 1)
+
+	// The next day the guide is built again
+	now = now.AddDate(0, 0, 1)
+	_, err := service.GetGuide(ctx, 3, "")
+	assert.NoError(t, err)
+	mockPlantRepo.AssertNumberOfCalls(t, "GetAll", 2)
+
+	// So it is when an admin changes it
+	_, err = service.SetOverride(ctx, &models.SeasonalGuideOverrideRequest{Month: 3, List: models.SeasonalGuideListRepot, PlantID: plant.ID, Hidden: true})
+	assert.NoError(t, err)
+	_, err = service.GetGuide(ctx, 3, "")
+	assert.NoError(t, err)
+	mockPlantRepo.AssertNumberOfCalls(t, "GetAll", 3)
+}
@@ -2,25 +2,75 @@ package services
 
 import (
 	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/anpanovv/planter/internal/events"
 	"github.com/anpanovv/planter/internal/models"
 	"github.com/anpanovv/planter/internal/repository"
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrGeocodingUnavailable is returned when shops are imported without a geocoder configured
+	ErrGeocodingUnavailable = errors.New("geocoding is not available")
+
+	// ErrAddressNotFound is returned by geocoders when an address matches no place
+	ErrAddressNotFound = errors.New("address not found")
+
+	// ErrInvalidShopImport is returned when a shop import is not a CSV file of names and addresses
+	ErrInvalidShopImport = errors.New("invalid shop import")
+
+	// ErrInvalidShop is returned when a shop is given only one of its coordinates
+	ErrInvalidShop = errors.New("invalid shop")
+
+	// ErrShopPlantExists is returned when a shop is asked to sell a plant it already sells
+	ErrShopPlantExists = errors.New("the shop already sells this plant")
+
+	// ErrInvalidSpecialOffer is returned when a special offer would end in the past
+	ErrInvalidSpecialOffer = errors.New("invalid special offer")
+)
+
+// maxShopImportRows is the number of shops a single import may contain
+const maxShopImportRows = 1000
+
+// Geocoder resolves addresses to coordinates
+type Geocoder interface {
+	// Geocode returns the coordinates of an address, or ErrAddressNotFound when it matches no place
+	Geocode(ctx context.Context, address string) (*models.GeoPoint, error)
+}
+
 // ShopService handles shop operations
 type ShopService struct {
-	shopRepo repository.ShopRepository
+	shopRepo  repository.ShopRepository
+	geocoder  Geocoder
+	publisher events.Publisher
 }
 
 // NewShopService creates a new shop service
 func NewShopService(shopRepo repository.ShopRepository) *ShopService {
 	return &ShopService{
-		shopRepo: shopRepo,
+		shopRepo:  shopRepo,
+		publisher: events.NopPublisher{},
 	}
 }
 
+// SetEventPublisher sets the publisher domain events are sent to
+func (s *ShopService) SetEventPublisher(publisher events.Publisher) {
+	s.publisher = publisher
+}
+
+// SetGeocoder sets the geocoder used to place imported shops on the map
+func (s *ShopService) SetGeocoder(geocoder Geocoder) {
+	s.geocoder = geocoder
+}
+
 // GetAllShops gets all shops
 func (s *ShopService) GetAllShops(ctx context.Context) ([]*models.Shop, error) {
 	shops, err := s.shopRepo.GetAll(ctx)
@@ -62,4 +112,342 @@ func (s *ShopService) GetSpecialOffers(ctx context.Context) ([]*models.SpecialOf
 		return nil, fmt.Errorf("failed to get special offers: %w", err)
 	}
 	return offers, nil
-}
\ No newline at end of file
+}
+
+// GetPlantOffers gets the offers of all shops selling a plant
+func (s *ShopService) GetPlantOffers(ctx context.Context, plantID uuid.UUID) ([]*models.PlantOffer, error) {
+	offers, err := s.shopRepo.GetPlantOffers(ctx, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plant offers: %w", err)
+	}
+	for _, offer := range offers {
+		if offer.BatchPhotos == nil {
+			offer.BatchPhotos = models.AssetKeys{}
+		}
+	}
+	return offers, nil
+}
+
+// UpdateShopPlant updates the price and stock attributes of a shop's plant
+func (s *ShopService) UpdateShopPlant(ctx context.Context, shopID uuid.UUID, plantID uuid.UUID, req *models.UpdateShopPlantRequest) (*models.ShopPlant, error) {
+	shopPlant := &models.ShopPlant{
+		ShopID:        shopID,
+		PlantID:       plantID,
+		Price:         req.Price,
+		Condition:     req.Condition,
+		SizeCm:        req.SizeCm,
+		PotDiameterCm: req.PotDiameterCm,
+		BatchPhotos:   make(models.AssetKeys, len(req.BatchPhotos)),
+	}
+	for i, photoURL := range req.BatchPhotos {
+		shopPlant.BatchPhotos[i] = models.AssetKeyFromURL(photoURL)
+	}
+
+	if err := s.shopRepo.UpdateShopPlant(ctx, shopPlant); err != nil {
+		return nil, fmt.Errorf("failed to update shop plant: %w", err)
+	}
+
+	// A batch with updated attributes is in stock
+	publishEvent(ctx, s.publisher, events.InventoryChanged{
+		ShopID:     shopID,
+		PlantID:    plantID,
+		InStock:    true,
+		OccurredAt: time.Now().UTC(),
+	})
+	return shopPlant, nil
+}
+
+// CreateShop creates a shop
+func (s *ShopService) CreateShop(ctx context.Context, req *models.ShopRequest) (*models.Shop, error) {
+	shop, err := shopFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.shopRepo.Create(ctx, shop); err != nil {
+		return nil, fmt.Errorf("failed to create shop: %w", err)
+	}
+	return shop, nil
+}
+
+// UpdateShop updates a shop
+func (s *ShopService) UpdateShop(ctx context.Context, shopID uuid.UUID, req *models.ShopRequest) (*models.Shop, error) {
+	shop, err := shopFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	shop.ID = shopID
+	if err := s.shopRepo.Update(ctx, shop); err != nil {
+		return nil, fmt.Errorf("failed to update shop: %w", err)
+	}
+	return shop, nil
+}
+
+// DeleteShop deletes a shop with the plants it sells
+func (s *ShopService) DeleteShop(ctx context.Context, shopID uuid.UUID) error {
+	if err := s.shopRepo.Delete(ctx, shopID); err != nil {
+		return fmt.Errorf("failed to delete shop: %w", err)
+	}
+	return nil
+}
+
+// shopFromRequest returns the shop a create or update request describes
+func shopFromRequest(req *models.ShopRequest) (*models.Shop, error) {
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, fmt.Errorf("%w: latitude and longitude are set together", ErrInvalidShop)
+	}
+	shop := &models.Shop{
+		Name:      strings.TrimSpace(req.Name),
+		Address:   strings.TrimSpace(req.Address),
+		City:      req.City,
+		Rating:    req.Rating,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}
+	if req.ImageURL != nil {
+		imageURL := models.AssetKeyFromURL(*req.ImageURL)
+		shop.ImageURL = &imageURL
+	}
+	return shop, nil
+}
+
+// AddShopPlant has a shop sell a catalog plant at a price
+func (s *ShopService) AddShopPlant(ctx context.Context, shopID uuid.UUID, req *models.AddShopPlantRequest) (*models.ShopPlant, error) {
+	// Check if the shop exists
+	if _, err := s.shopRepo.GetByID(ctx, shopID); err != nil {
+		return nil, fmt.Errorf("shop not found: %w", err)
+	}
+
+	shopPlant := &models.ShopPlant{
+		ShopID:        shopID,
+		PlantID:       req.PlantID,
+		Price:         req.Price,
+		Condition:     req.Condition,
+		SizeCm:        req.SizeCm,
+		PotDiameterCm: req.PotDiameterCm,
+		BatchPhotos:   make(models.AssetKeys, len(req.BatchPhotos)),
+	}
+	for i, photoURL := range req.BatchPhotos {
+		shopPlant.BatchPhotos[i] = models.AssetKeyFromURL(photoURL)
+	}
+
+	added, err := s.shopRepo.AddShopPlant(ctx, shopPlant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add shop plant: %w", err)
+	}
+	if !added {
+		return nil, ErrShopPlantExists
+	}
+
+	// The plant is in stock at the shop from now on
+	publishEvent(ctx, s.publisher, events.InventoryChanged{
+		ShopID:     shopID,
+		PlantID:    req.PlantID,
+		InStock:    true,
+		OccurredAt: time.Now().UTC(),
+	})
+	return shopPlant, nil
+}
+
+// RemoveShopPlant stops a shop selling a plant
+func (s *ShopService) RemoveShopPlant(ctx context.Context, shopID uuid.UUID, plantID uuid.UUID) error {
+	if err := s.shopRepo.RemoveShopPlant(ctx, shopID, plantID); err != nil {
+		return fmt.Errorf("failed to remove shop plant: %w", err)
+	}
+
+	publishEvent(ctx, s.publisher, events.InventoryChanged{
+		ShopID:     shopID,
+		PlantID:    plantID,
+		InStock:    false,
+		OccurredAt: time.Now().UTC(),
+	})
+	return nil
+}
+
+// ListSpecialOffers gets all special offers, expired ones included, for admins
+func (s *ShopService) ListSpecialOffers(ctx context.Context) ([]*models.SpecialOffer, error) {
+	offers, err := s.shopRepo.ListSpecialOffers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list special offers: %w", err)
+	}
+	return offers, nil
+}
+
+// CreateSpecialOffer creates a special offer
+func (s *ShopService) CreateSpecialOffer(ctx context.Context, req *models.SpecialOfferRequest) (*models.SpecialOffer, error) {
+	offer, err := specialOfferFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.shopRepo.CreateSpecialOffer(ctx, offer); err != nil {
+		return nil, fmt.Errorf("failed to create special offer: %w", err)
+	}
+	return offer, nil
+}
+
+// UpdateSpecialOffer updates a special offer
+func (s *ShopService) UpdateSpecialOffer(ctx context.Context, offerID uuid.UUID, req *models.SpecialOfferRequest) (*models.SpecialOffer, error) {
+	offer, err := specialOfferFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	offer.ID = offerID
+	if err := s.shopRepo.UpdateSpecialOffer(ctx, offer); err != nil {
+		return nil, fmt.Errorf("failed to update special offer: %w", err)
+	}
+	return offer, nil
+}
+
+// DeleteSpecialOffer deletes a special offer
+func (s *ShopService) DeleteSpecialOffer(ctx context.Context, offerID uuid.UUID) error {
+	if err := s.shopRepo.DeleteSpecialOffer(ctx, offerID); err != nil {
+		return fmt.Errorf("failed to delete special offer: %w", err)
+	}
+	return nil
+}
+
+// specialOfferFromRequest returns the special offer a create or update request describes
+func specialOfferFromRequest(req *models.SpecialOfferRequest) (*models.SpecialOffer, error) {
+	if !req.ValidUntil.After(time.Now()) {
+		return nil, fmt.Errorf("%w: validUntil must be in the future", ErrInvalidSpecialOffer)
+	}
+	return &models.SpecialOffer{
+		Title:              strings.TrimSpace(req.Title),
+		Description:        strings.TrimSpace(req.Description),
+		ImageURL:           models.AssetKeyFromURL(req.ImageURL),
+		DiscountPercentage: req.DiscountPercentage,
+		ValidUntil:         req.ValidUntil.UTC(),
+	}, nil
+}
+
+// ImportShops creates shops from a CSV file of names and addresses, placing them on the map with the
+// geocoder. An optional "name,address" header is skipped. Addresses are deduplicated against existing
+// shops and earlier rows; rows that cannot be imported are reported without failing the others.
+func (s *ShopService) ImportShops(ctx context.Context, file io.Reader) (*models.ShopImportResult, error) {
+	if s.geocoder == nil {
+		return nil, ErrGeocodingUnavailable
+	}
+
+	// Read the whole file first so a malformed file creates no shops
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var rows []*models.ShopImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidShopImport, err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(rows) == 0 && line == 1 && isShopImportHeader(record) {
+			continue
+		}
+
+		row := &models.ShopImportRow{Line: line}
+		if len(record) != 2 {
+			row.Status = models.ShopImportStatusInvalid
+			row.Error = fmt.Sprintf("expected 2 columns, got %d", len(record))
+		} else {
+			row.Name = strings.TrimSpace(record[0])
+			row.Address = strings.TrimSpace(record[1])
+			if row.Name == "" || row.Address == "" {
+				row.Status = models.ShopImportStatusInvalid
+				row.Error = "name and address are required"
+			}
+		}
+		rows = append(rows, row)
+		if len(rows) > maxShopImportRows {
+			return nil, fmt.Errorf("%w: more than %d shops", ErrInvalidShopImport, maxShopImportRows)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no shops", ErrInvalidShopImport)
+	}
+
+	// Index the existing shops by address
+	shops, err := s.shopRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shops: %w", err)
+	}
+	shopIDs := make(map[string]uuid.UUID, len(shops))
+	for _, shop := range shops {
+		shopIDs[normalizeAddress(shop.Address)] = shop.ID
+	}
+
+	result := &models.ShopImportResult{Rows: rows}
+	for _, row := range rows {
+		if row.Status == "" {
+			s.importShop(ctx, row, shopIDs)
+		}
+		switch row.Status {
+		case models.ShopImportStatusCreated:
+			result.Created++
+		case models.ShopImportStatusDuplicate:
+			result.Duplicates++
+		default:
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// importShop geocodes and creates the shop of an import row unless its address is already known,
+// recording the outcome on the row
+func (s *ShopService) importShop(ctx context.Context, row *models.ShopImportRow, shopIDs map[string]uuid.UUID) {
+	address := normalizeAddress(row.Address)
+	if shopID, ok := shopIDs[address]; ok {
+		row.Status = models.ShopImportStatusDuplicate
+		row.ShopID = &shopID
+		return
+	}
+
+	// Place the shop on the map
+	point, err := s.geocoder.Geocode(ctx, row.Address)
+	if errors.Is(err, ErrAddressNotFound) {
+		row.Status = models.ShopImportStatusNotFound
+		row.Error = err.Error()
+		return
+	}
+	if err != nil {
+		row.Status = models.ShopImportStatusFailed
+		row.Error = fmt.Sprintf("failed to geocode address: %v", err)
+		return
+	}
+
+	shop := &models.Shop{
+		Name:      row.Name,
+		Address:   row.Address,
+		Latitude:  &point.Latitude,
+		Longitude: &point.Longitude,
+	}
+	if point.City != "" {
+		shop.City = &point.City
+	}
+	if err := s.shopRepo.Create(ctx, shop); err != nil {
+		log.Printf("Error creating imported shop %q: %v", row.Name, err)
+		row.Status = models.ShopImportStatusFailed
+		row.Error = "failed to create shop"
+		return
+	}
+	row.Status = models.ShopImportStatusCreated
+	row.ShopID = &shop.ID
+	shopIDs[address] = shop.ID
+}
+
+// isShopImportHeader reports whether a CSV record is the "name,address" header
+func isShopImportHeader(record []string) bool {
+	return len(record) == 2 &&
+		strings.EqualFold(strings.TrimSpace(record[0]), "name") &&
+		strings.EqualFold(strings.TrimSpace(record[1]), "address")
+}
+
+// normalizeAddress returns the form of an address used to find duplicates: lower case, with
+// punctuation dropped and whitespace collapsed, so "ул. Ленина, 5" matches "ул Ленина 5"
+func normalizeAddress(address string) string {
+	address = strings.ReplaceAll(strings.ToLower(address), "ё", "е")
+	return strings.Join(strings.FieldsFunc(address, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
@@ -2,8 +2,11 @@ package services
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/anpanovv/planter/internal/events"
 	"github.com/anpanovv/planter/internal/models"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -20,6 +23,11 @@ func (m *MockShopRepository) GetAll(ctx context.Context) ([]*models.Shop, error)
 	return args.Get(0).([]*models.Shop), args.Error(1)
 }
 
+func (m *MockShopRepository) Create(ctx context.Context, shop *models.Shop) error {
+	args := m.Called(ctx, shop)
+	return args.Error(0)
+}
+
 func (m *MockShopRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Shop, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -38,6 +46,56 @@ func (m *MockShopRepository) GetSpecialOffers(ctx context.Context) ([]*models.Sp
 	return args.Get(0).([]*models.SpecialOffer), args.Error(1)
 }
 
+func (m *MockShopRepository) GetPlantOffers(ctx context.Context, plantID uuid.UUID) ([]*models.PlantOffer, error) {
+	args := m.Called(ctx, plantID)
+	return args.Get(0).([]*models.PlantOffer), args.Error(1)
+}
+
+func (m *MockShopRepository) UpdateShopPlant(ctx context.Context, shopPlant *models.ShopPlant) error {
+	args := m.Called(ctx, shopPlant)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) Update(ctx context.Context, shop *models.Shop) error {
+	args := m.Called(ctx, shop)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) AddShopPlant(ctx context.Context, shopPlant *models.ShopPlant) (bool, error) {
+	args := m.Called(ctx, shopPlant)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockShopRepository) RemoveShopPlant(ctx context.Context, shopID uuid.UUID, plantID uuid.UUID) error {
+	args := m.Called(ctx, shopID, plantID)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) ListSpecialOffers(ctx context.Context) ([]*models.SpecialOffer, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.SpecialOffer), args.Error(1)
+}
+
+func (m *MockShopRepository) CreateSpecialOffer(ctx context.Context, offer *models.SpecialOffer) error {
+	args := m.Called(ctx, offer)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) UpdateSpecialOffer(ctx context.Context, offer *models.SpecialOffer) error {
+	args := m.Called(ctx, offer)
+	return args.Error(0)
+}
+
+func (m *MockShopRepository) DeleteSpecialOffer(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 // TestShopService_GetAllShops tests the GetAllShops method of the ShopService
 func TestShopService_GetAllShops(t *testing.T) {
 	// Create a mock shop repository
@@ -192,4 +250,244 @@ func TestShopService_GetSpecialOffers(t *testing.T) {
 
 	// Verify that all expectations were met
 	mockShopRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+// TestShopService_GetPlantOffers tests that offers are returned with their batch attributes
+func TestShopService_GetPlantOffers(t *testing.T) {
+	mockShopRepo := new(MockShopRepository)
+	service := NewShopService(mockShopRepo)
+	ctx := context.Background()
+	plantID := uuid.New()
+	condition := models.ShopPlantConditionExcellent
+	sizeCm := 40
+
+	offers := []*models.PlantOffer{
+		{
+			ShopPlant: models.ShopPlant{PlantID: plantID, Price: 1500, Condition: &condition, SizeCm: &sizeCm,
+				BatchPhotos: models.AssetKeys{"https://example.com/batch.jpg"}},
+			Shop: models.Shop{Name: "Shop 1"},
+		},
+		{
+			ShopPlant: models.ShopPlant{PlantID: plantID, Price: 1800},
+			Shop:      models.Shop{Name: "Shop 2"},
+		},
+	}
+	mockShopRepo.On("GetPlantOffers", ctx, plantID).Return(offers, nil)
+
+	result, err := service.GetPlantOffers(ctx, plantID)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, models.ShopPlantConditionExcellent, *result[0].Condition)
+	assert.NotNil(t, result[1].BatchPhotos)
+	assert.Empty(t, result[1].BatchPhotos)
+	mockShopRepo.AssertExpectations(t)
+}
+
+// TestShopService_UpdateShopPlant tests that stock attributes are passed to the repository and the stock change is published
+func TestShopService_UpdateShopPlant(t *testing.T) {
+	mockShopRepo := new(MockShopRepository)
+	publisher := &capturingPublisher{}
+	service := NewShopService(mockShopRepo)
+	service.SetEventPublisher(publisher)
+	ctx := context.Background()
+	shopID := uuid.New()
+	plantID := uuid.New()
+	potDiameterCm := 12
+
+	mockShopRepo.On("UpdateShopPlant", ctx, mock.MatchedBy(func(sp *models.ShopPlant) bool {
+		return sp.ShopID == shopID && sp.PlantID == plantID && sp.Price == 990 &&
+			*sp.PotDiameterCm == 12 && len(sp.BatchPhotos) == 1
+	})).Return(nil)
+
+	shopPlant, err := service.UpdateShopPlant(ctx, shopID, plantID, &models.UpdateShopPlantRequest{
+		Price:         990,
+		PotDiameterCm: &potDiameterCm,
+		BatchPhotos:   []string{"https://example.com/batch.jpg"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 990.0, shopPlant.Price)
+	mockShopRepo.AssertExpectations(t)
+
+	// The stocked batch reaches availability subscribers through the inventory change event
+	if assert.Len(t, publisher.published, 1) {
+		event := publisher.published[0].(events.InventoryChanged)
+		assert.Equal(t, shopID, event.ShopID)
+		assert.Equal(t, plantID, event.PlantID)
+		assert.True(t, event.InStock)
+	}
+}
+
+// TestShopService_CreateShop tests that shops are created with both coordinates or none
+func TestShopService_CreateShop(t *testing.T) {
+	mockShopRepo := new(MockShopRepository)
+	service := NewShopService(mockShopRepo)
+	ctx := context.Background()
+	latitude, longitude := 55.75, 37.61
+
+	mockShopRepo.On("Create", ctx, mock.MatchedBy(func(shop *models.Shop) bool {
+		return shop.Name == "Зелёный дом" && *shop.Latitude == latitude && *shop.Longitude == longitude
+	})).Return(nil)
+
+	shop, err := service.CreateShop(ctx, &models.ShopRequest{
+		Name: " Зелёный дом ", Address: "ул. Ленина, 5", Rating: 4.5, Latitude: &latitude, Longitude: &longitude,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Зелёный дом", shop.Name)
+
+	_, err = service.CreateShop(ctx, &models.ShopRequest{Name: "Флора", Address: "ул. Мира, 1", Latitude: &latitude})
+	assert.ErrorIs(t, err, ErrInvalidShop)
+	mockShopRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+// TestShopService_AddShopPlant tests that a shop sells a plant once and its stock is announced
+func TestShopService_AddShopPlant(t *testing.T) {
+	mockShopRepo := new(MockShopRepository)
+	publisher := &capturingPublisher{}
+	service := NewShopService(mockShopRepo)
+	service.SetEventPublisher(publisher)
+	ctx := context.Background()
+	shopID := uuid.New()
+	plantID := uuid.New()
+	req := &models.AddShopPlantRequest{PlantID: plantID, UpdateShopPlantRequest: models.UpdateShopPlantRequest{Price: 1490}}
+
+	mockShopRepo.On("GetByID", ctx, shopID).Return(&models.Shop{ID: shopID}, nil)
+	mockShopRepo.On("AddShopPlant", ctx, mock.MatchedBy(func(sp *models.ShopPlant) bool {
+		return sp.ShopID == shopID && sp.PlantID == plantID && sp.Price == 1490
+	})).Return(true, nil).Once()
+
+	shopPlant, err := service.AddShopPlant(ctx, shopID, req)
+	assert.NoError(t, err)
+	assert.Equal(t, plantID, shopPlant.PlantID)
+	if assert.Len(t, publisher.published, 1) {
+		assert.True(t, publisher.published[0].(events.InventoryChanged).InStock)
+	}
+
+	// The shop already sells the plant
+	mockShopRepo.On("AddShopPlant", ctx, mock.Anything).Return(false, nil).Once()
+	_, err = service.AddShopPlant(ctx, shopID, req)
+	assert.ErrorIs(t, err, ErrShopPlantExists)
+
+	// Removing the plant takes it out of stock
+	mockShopRepo.On("RemoveShopPlant", ctx, shopID, plantID).Return(nil)
+	assert.NoError(t, service.RemoveShopPlant(ctx, shopID, plantID))
+	if assert.Len(t, publisher.published, 2) {
+		assert.False(t, publisher.published[1].(events.InventoryChanged).InStock)
+	}
+}
+
+// TestShopService_CreateSpecialOffer tests that special offers must end in the future
+func TestShopService_CreateSpecialOffer(t *testing.T) {
+	mockShopRepo := new(MockShopRepository)
+	service := NewShopService(mockShopRepo)
+	ctx := context.Background()
+	req := &models.SpecialOfferRequest{
+		Title:              "Весенняя распродажа",
+		Description:        "Скидки на суккуленты",
+		ImageURL:           "https://example.com/spring.jpg",
+		DiscountPercentage: 20,
+		ValidUntil:         time.Now().Add(7 * 24 * time.Hour),
+	}
+
+	mockShopRepo.On("CreateSpecialOffer", ctx, mock.MatchedBy(func(offer *models.SpecialOffer) bool {
+		return offer.Title == req.Title && offer.DiscountPercentage == 20
+	})).Return(nil)
+
+	offer, err := service.CreateSpecialOffer(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, offer.DiscountPercentage)
+
+	req.ValidUntil = time.Now().Add(-time.Hour)
+	_, err = service.CreateSpecialOffer(ctx, req)
+	assert.ErrorIs(t, err, ErrInvalidSpecialOffer)
+	_, err = service.UpdateSpecialOffer(ctx, uuid.New(), req)
+	assert.ErrorIs(t, err, ErrInvalidSpecialOffer)
+	mockShopRepo.AssertNumberOfCalls(t, "CreateSpecialOffer", 1)
+}
+
+// MockGeocoder is a mock implementation of the Geocoder interface
+type MockGeocoder struct {
+	mock.Mock
+}
+
+func (m *MockGeocoder) Geocode(ctx context.Context, address string) (*models.GeoPoint, error) {
+	args := m.Called(ctx, address)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.GeoPoint), args.Error(1)
+}
+
+// TestShopService_ImportShops tests the outcome reported for each row of an import
+func TestShopService_ImportShops(t *testing.T) {
+	mockShopRepo := new(MockShopRepository)
+	mockGeocoder := new(MockGeocoder)
+	shopService := NewShopService(mockShopRepo)
+	shopService.SetGeocoder(mockGeocoder)
+
+	existing := &models.Shop{ID: uuid.New(), Name: "Green House", Address: "ул. Ленина, 5"}
+	createdID := uuid.New()
+	mockShopRepo.On("GetAll", mock.Anything).Return([]*models.Shop{existing}, nil)
+	mockGeocoder.On("Geocode", mock.Anything, "Невский проспект, 28").Return(&models.GeoPoint{Latitude: 59.9357, Longitude: 30.3260, City: "Санкт-Петербург"}, nil)
+	mockGeocoder.On("Geocode", mock.Anything, "Nowhere 1").Return(nil, ErrAddressNotFound)
+	mockShopRepo.On("Create", mock.Anything, mock.MatchedBy(func(shop *models.Shop) bool {
+		return shop.Name == "Flora" && *shop.Latitude == 59.9357 && *shop.City == "Санкт-Петербург"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Shop).ID = createdID
+	}).Return(nil).Once()
+
+	file := "name,address\n" +
+		"Flora,\"Невский проспект, 28\"\n" +
+		"Green House 2,ул Ленина 5\n" +
+		"Flora Copy,\"невский проспект 28\"\n" +
+		"Ghost,Nowhere 1\n" +
+		",Somewhere 2\n" +
+		"Lonely\n"
+	result, err := shopService.ImportShops(context.Background(), strings.NewReader(file))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 2, result.Duplicates)
+	assert.Equal(t, 3, result.Failed)
+
+	statuses := make([]models.ShopImportStatus, len(result.Rows))
+	for i, row := range result.Rows {
+		statuses[i] = row.Status
+	}
+	assert.Equal(t, []models.ShopImportStatus{
+		models.ShopImportStatusCreated,
+		models.ShopImportStatusDuplicate,
+		models.ShopImportStatusDuplicate,
+		models.ShopImportStatusNotFound,
+		models.ShopImportStatusInvalid,
+		models.ShopImportStatusInvalid,
+	}, statuses)
+	assert.Equal(t, 2, result.Rows[0].Line)
+	assert.Equal(t, existing.ID, *result.Rows[1].ShopID)
+	assert.Equal(t, createdID, *result.Rows[2].ShopID)
+	mockShopRepo.AssertExpectations(t)
+	mockGeocoder.AssertExpectations(t)
+}
+
+// TestShopService_ImportShops_Invalid tests that malformed files and missing geocoders create no shops
+func TestShopService_ImportShops_Invalid(t *testing.T) {
+	mockShopRepo := new(MockShopRepository)
+	shopService := NewShopService(mockShopRepo)
+
+	_, err := shopService.ImportShops(context.Background(), strings.NewReader("Flora,Main St 1\n"))
+	assert.ErrorIs(t, err, ErrGeocodingUnavailable)
+
+	shopService.SetGeocoder(new(MockGeocoder))
+	_, err = shopService.ImportShops(context.Background(), strings.NewReader("Flora,\"Main St 1\n"))
+	assert.ErrorIs(t, err, ErrInvalidShopImport)
+
+	_, err = shopService.ImportShops(context.Background(), strings.NewReader("name,address\n"))
+	assert.ErrorIs(t, err, ErrInvalidShopImport)
+	mockShopRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestNormalizeAddress tests that addresses differing in case and punctuation match
+func TestNormalizeAddress(t *testing.T) {
+	assert.Equal(t, normalizeAddress("ул. Ленина, 5"), normalizeAddress("УЛ ЛЕНИНА 5"))
+	assert.Equal(t, "ул березовая 3", normalizeAddress("  ул.  Берёзовая,3 "))
+	assert.NotEqual(t, normalizeAddress("ул. Ленина, 5"), normalizeAddress("ул. Ленина, 15"))
+}
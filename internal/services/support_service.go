@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSupportTicketStatus is returned when tickets are filtered by an unknown status
+var ErrInvalidSupportTicketStatus = errors.New("invalid support ticket status")
+
+const (
+	// defaultSupportTicketPageSize is the number of tickets returned per page unless asked otherwise
+	defaultSupportTicketPageSize = 50
+
+	// maxSupportTicketPageSize is the largest page of tickets that can be requested
+	maxSupportTicketPageSize = 100
+
+	// maxSupportUserAgentLength is the length user agents are cut to in the context snapshot
+	maxSupportUserAgentLength = 300
+)
+
+// supportTicketStatuses holds the known support ticket statuses
+var supportTicketStatuses = map[models.SupportTicketStatus]bool{
+	models.SupportTicketStatusOpen:       true,
+	models.SupportTicketStatusInProgress: true,
+	models.SupportTicketStatusResolved:   true,
+	models.SupportTicketStatusClosed:     true,
+}
+
+// SupportService handles messages to support and their triage by admins
+type SupportService struct {
+	ticketRepo          repository.SupportTicketRepository
+	plantRepo           repository.PlantRepository
+	userRepo            repository.UserRepository
+	notificationService *NotificationService
+}
+
+// NewSupportService creates a new support service. Admins are not notified when notificationService is nil.
+func NewSupportService(
+	ticketRepo repository.SupportTicketRepository,
+	plantRepo repository.PlantRepository,
+	userRepo repository.UserRepository,
+	notificationService *NotificationService,
+) *SupportService {
+	return &SupportService{
+		ticketRepo:          ticketRepo,
+		plantRepo:           plantRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+	}
+}
+
+// SubmitTicket stores a user's message to support with the snapshot of their app taken by the
+// caller, adds the state of the plant in question and notifies the admins
+func (s *SupportService) SubmitTicket(
+	ctx context.Context,
+	userID uuid.UUID,
+	message string,
+	plantID *uuid.UUID,
+	snapshot models.SupportTicketContext,
+) (*models.SupportTicket, error) {
+	if len(snapshot.UserAgent) > maxSupportUserAgentLength {
+		snapshot.UserAgent = snapshot.UserAgent[:maxSupportUserAgentLength]
+	}
+
+	// Capture the plant the message is about; it must be in the user's collection
+	if plantID != nil {
+		userPlant, err := s.plantRepo.GetUserPlant(ctx, userID, *plantID)
+		if err != nil {
+			return nil, fmt.Errorf("plant not in user's collection: %w", err)
+		}
+		plant, err := s.plantRepo.GetByID(ctx, *plantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get plant: %w", err)
+		}
+		snapshot.Plant = &models.SupportTicketPlant{
+			Name:         plant.Name,
+			Location:     userPlant.Location,
+			LastWatered:  userPlant.LastWatered,
+			NextWatering: userPlant.NextWatering,
+			AddedAt:      userPlant.CreatedAt,
+		}
+	}
+
+	ticket := &models.SupportTicket{
+		UserID:  userID,
+		PlantID: plantID,
+		Message: message,
+		Context: snapshot,
+		Status:  models.SupportTicketStatusOpen,
+	}
+	if err := s.ticketRepo.Create(ctx, ticket); err != nil {
+		return nil, fmt.Errorf("failed to save support ticket: %w", err)
+	}
+
+	// The ticket is stored either way, so failed notifications are only logged
+	s.notifyAdmins(ctx, ticket)
+	return ticket, nil
+}
+
+// ListTickets gets a page of support tickets for triage, oldest first
+func (s *SupportService) ListTickets(ctx context.Context, filter *models.SupportTicketFilter) (*models.SupportTicketListResponse, error) {
+	if filter.Status != "" && !supportTicketStatuses[filter.Status] {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSupportTicketStatus, filter.Status)
+	}
+	page := filter.Page
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultSupportTicketPageSize
+	}
+	if pageSize > maxSupportTicketPageSize {
+		pageSize = maxSupportTicketPageSize
+	}
+
+	tickets, total, err := s.ticketRepo.List(ctx, filter.Status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get support tickets: %w", err)
+	}
+	return &models.SupportTicketListResponse{Tickets: tickets, Total: total}, nil
+}
+
+// GetTicket gets a support ticket
+func (s *SupportService) GetTicket(ctx context.Context, ticketID uuid.UUID) (*models.SupportTicket, error) {
+	ticket, err := s.ticketRepo.GetByID(ctx, ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get support ticket: %w", err)
+	}
+	return ticket, nil
+}
+
+// UpdateTicket sets the status of a support ticket and optionally the admin's note
+func (s *SupportService) UpdateTicket(ctx context.Context, ticketID uuid.UUID, req *models.UpdateSupportTicketRequest) (*models.SupportTicket, error) {
+	ticket, err := s.ticketRepo.UpdateStatus(ctx, ticketID, req.Status, req.AdminNote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update support ticket: %w", err)
+	}
+	return ticket, nil
+}
+
+// notifyAdmins sends every admin a notification about a new ticket in their language
+func (s *SupportService) notifyAdmins(ctx context.Context, ticket *models.SupportTicket) {
+	if s.notificationService == nil {
+		return
+	}
+
+	admins, err := s.userRepo.GetByRole(ctx, models.RoleAdmin)
+	if err != nil {
+		log.Printf("Failed to get admins to notify about support ticket %s: %v", ticket.ID, err)
+		return
+	}
+	for _, admin := range admins {
+		payload := models.NotificationPayload{"ticketId": ticket.ID.String()}
+		if _, err := s.notificationService.SendNotification(ctx, admin.ID, admin.Language, models.NotificationTypeSupportTicket, payload); err != nil {
+			log.Printf("Failed to notify admin %s about support ticket %s: %v", admin.ID, ticket.ID, err)
+		}
+	}
+}
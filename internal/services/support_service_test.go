@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSupportTicketRepository is a mock implementation of the SupportTicketRepository interface
+type MockSupportTicketRepository struct {
+	mock.Mock
+}
+
+func (m *MockSupportTicketRepository) Create(ctx context.Context, ticket *models.SupportTicket) error {
+	args := m.Called(ctx, ticket)
+	return args.Error(0)
+}
+
+func (m *MockSupportTicketRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SupportTicket, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SupportTicket), args.Error(1)
+}
+
+func (m *MockSupportTicketRepository) List(ctx context.Context, status models.SupportTicketStatus, limit int, offset int) ([]*models.SupportTicket, int, error) {
+	args := m.Called(ctx, status, limit, offset)
+	return args.Get(0).([]*models.SupportTicket), args.Int(1), args.Error(2)
+}
+
+func (m *MockSupportTicketRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.SupportTicketStatus, adminNote *string) (*models.SupportTicket, error) {
+	args := m.Called(ctx, id, status, adminNote)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SupportTicket), args.Error(1)
+}
+
+// TestSupportService_SubmitTicket tests that the plant in question is captured and every admin is notified
+func TestSupportService_SubmitTicket(t *testing.T) {
+	mockTicketRepo := new(MockSupportTicketRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockUserRepo := new(MockUserRepository)
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	notificationService := NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(mockTemplateRepo))
+	service := NewSupportService(mockTicketRepo, mockPlantRepo, mockUserRepo, notificationService)
+	ctx := context.Background()
+
+	userID, plantID, ticketID := uuid.New(), uuid.New(), uuid.New()
+	location := "Kitchen"
+	lastWatered := time.Date(2024, time.May, 10, 9, 0, 0, 0, time.UTC)
+	admins := []*models.User{
+		{ID: uuid.New(), Language: models.LanguageEnglish},
+		{ID: uuid.New(), Language: models.LanguageRussian},
+	}
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID, Location: &location, LastWatered: &lastWatered}, nil)
+	mockPlantRepo.On("GetByID", ctx, plantID).Return(&models.Plant{ID: plantID, Name: "Monstera"}, nil)
+	mockTicketRepo.On("Create", ctx, mock.MatchedBy(func(ticket *models.SupportTicket) bool {
+		return ticket.Status == models.SupportTicketStatusOpen &&
+			ticket.Context.AppVersion == "2.3.0" &&
+			ticket.Context.Plant != nil && ticket.Context.Plant.Name == "Monstera" && *ticket.Context.Plant.Location == "Kitchen"
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.SupportTicket).ID = ticketID
+	}).Return(nil)
+	mockUserRepo.On("GetByRole", ctx, models.RoleAdmin).Return(admins, nil)
+	mockTemplateRepo.On("Get", ctx, models.NotificationTypeSupportTicket, mock.Anything).Return(nil, nil)
+	for _, admin := range admins {
+		adminID := admin.ID
+		mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+			return n.UserID == adminID && n.Type == models.NotificationTypeSupportTicket && n.Payload["ticketId"] == ticketID.String()
+		})).Return(nil).Once()
+	}
+
+	ticket, err := service.SubmitTicket(ctx, userID, "Watering reminders stopped", &plantID, models.SupportTicketContext{
+		AppVersion:   "2.3.0",
+		RecentErrors: []string{"Failed to load notifications"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ticketID, ticket.ID)
+	mockTicketRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertExpectations(t)
+}
+
+// TestSupportService_SubmitTicket_NotifyFails tests that a ticket is kept when admins cannot be notified
+func TestSupportService_SubmitTicket_NotifyFails(t *testing.T) {
+	mockTicketRepo := new(MockSupportTicketRepository)
+	mockUserRepo := new(MockUserRepository)
+	notificationService := NewNotificationService(new(MockNotificationRepository), new(MockPlantRepository), nil, NewNotificationTemplateService(new(MockNotificationTemplateRepository)))
+	service := NewSupportService(mockTicketRepo, new(MockPlantRepository), mockUserRepo, notificationService)
+
+	mockTicketRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	mockUserRepo.On("GetByRole", mock.Anything, models.RoleAdmin).Return([]*models.User{}, errors.New("connection refused"))
+
+	ticket, err := service.SubmitTicket(context.Background(), uuid.New(), "The app crashes", nil, models.SupportTicketContext{})
+	assert.NoError(t, err)
+	assert.Nil(t, ticket.Context.Plant)
+}
+
+// TestSupportService_SubmitTicket_PlantNotOwned tests that tickets cannot point at other users' plants
+func TestSupportService_SubmitTicket_PlantNotOwned(t *testing.T) {
+	mockTicketRepo := new(MockSupportTicketRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	service := NewSupportService(mockTicketRepo, mockPlantRepo, new(MockUserRepository), nil)
+	userID, plantID := uuid.New(), uuid.New()
+
+	mockPlantRepo.On("GetUserPlant", mock.Anything, userID, plantID).Return(nil, fmt.Errorf("user plant not found: %w", sql.ErrNoRows))
+
+	_, err := service.SubmitTicket(context.Background(), userID, "Help", &plantID, models.SupportTicketContext{})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	mockTicketRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestSupportService_ListTickets tests the paging defaults and the status filter
+func TestSupportService_ListTickets(t *testing.T) {
+	mockTicketRepo := new(MockSupportTicketRepository)
+	service := NewSupportService(mockTicketRepo, new(MockPlantRepository), new(MockUserRepository), nil)
+	ctx := context.Background()
+
+	mockTicketRepo.On("List", ctx, models.SupportTicketStatusOpen, maxSupportTicketPageSize, maxSupportTicketPageSize).Return([]*models.SupportTicket{}, 120, nil)
+
+	response, err := service.ListTickets(ctx, &models.SupportTicketFilter{Status: models.SupportTicketStatusOpen, Page: 2, PageSize: 500})
+	assert.NoError(t, err)
+	assert.Equal(t, 120, response.Total)
+
+	_, err = service.ListTickets(ctx, &models.SupportTicketFilter{Status: "PENDING"})
+	assert.ErrorIs(t, err, ErrInvalidSupportTicketStatus)
+	mockTicketRepo.AssertExpectations(t)
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// maxTriageCauses is the number of most likely causes returned by a triage
+const maxTriageCauses = 3
+
+// journalHistoryLimit is the number of journal entries returned per plant
+const journalHistoryLimit = 50
+
+// triageWeights holds how strongly each symptom points to each cause
+var triageWeights = map[models.TriageSymptom]map[models.TriageCause]float64{
+	models.TriageSymptomYellowLeaves: {models.TriageCauseOverwatering: 3, models.TriageCauseLowLight: 2, models.TriageCauseUnderwatering: 1},
+	models.TriageSymptomDrooping:     {models.TriageCauseUnderwatering: 3, models.TriageCauseOverwatering: 2},
+	models.TriageSymptomBrownSpots:   {models.TriageCauseFungalDisease: 3, models.TriageCauseSunburn: 2, models.TriageCauseOverwatering: 1},
+	models.TriageSymptomBrownTips:    {models.TriageCauseLowHumidity: 3, models.TriageCauseUnderwatering: 2},
+	models.TriageSymptomLeafDrop:     {models.TriageCauseOverwatering: 2, models.TriageCauseUnderwatering: 2, models.TriageCauseLowLight: 1},
+	models.TriageSymptomMushyStem:    {models.TriageCauseOverwatering: 4, models.TriageCauseFungalDisease: 2},
+	models.TriageSymptomLeggyGrowth:  {models.TriageCauseLowLight: 4},
+	models.TriageSymptomPaleLeaves:   {models.TriageCauseSunburn: 2, models.TriageCauseLowLight: 2},
+}
+
+// triageActions holds the immediate actions for each cause in each supported language
+var triageActions = map[models.Language]map[models.TriageCause][]string{
+	models.LanguageRussian: {
+		models.TriageCauseOverwatering:  {"Не поливайте, пока верхние 3–4 см грунта не просохнут", "Проверьте дренажные отверстия и слейте воду из поддона", "Если грунт пахнет гнилью, пересадите растение в свежий грунт, удалив мягкие корни"},
+		models.TriageCauseUnderwatering: {"Полейте растение до появления воды в поддоне", "Если ком земли пересох, погрузите горшок в воду на 20–30 минут", "Проверяйте влажность грунта чаще"},
+		models.TriageCauseLowLight:      {"Переставьте растение ближе к окну", "Поворачивайте горшок раз в неделю для равномерного роста", "Зимой используйте фитолампу"},
+		models.TriageCauseSunburn:       {"Уберите растение от прямого солнца или притените его", "Удалите сильно обожжённые листья", "Приучайте к яркому свету постепенно"},
+		models.TriageCauseLowHumidity:   {"Уберите растение от батарей и кондиционера", "Поставьте рядом увлажнитель или поддон с мокрой галькой", "Обрезайте сухие кончики чистыми ножницами"},
+		models.TriageCauseFungalDisease: {"Изолируйте растение от других", "Удалите поражённые листья", "Не опрыскивайте листья и улучшите проветривание, при необходимости обработайте фунгицидом"},
+	},
+	models.LanguageEnglish: {
+		models.TriageCauseOverwatering:  {"Stop watering until the top 3-4 cm of soil are dry", "Check the drainage holes and empty the saucer", "If the soil smells rotten, repot into fresh soil and trim mushy roots"},
+		models.TriageCauseUnderwatering: {"Water thoroughly until water runs into the saucer", "If the root ball has dried out, soak the pot in water for 20-30 minutes", "Check the soil moisture more often"},
+		models.TriageCauseLowLight:      {"Move the plant closer to a window", "Rotate the pot weekly for even growth", "Use a grow light in winter"},
+		models.TriageCauseSunburn:       {"Move the plant out of direct sun or shade it", "Remove badly scorched leaves", "Get the plant used to bright light gradually"},
+		models.TriageCauseLowHumidity:   {"Move the plant away from radiators and air conditioning", "Add a humidifier or a pebble tray with water", "Trim the dry tips with clean scissors"},
+		models.TriageCauseFungalDisease: {"Isolate the plant from others", "Remove the affected leaves", "Stop misting, improve air flow and use a fungicide if needed"},
+	},
+}
+
+// TriageAdvisor writes a follow-up to a rule-based triage
+type TriageAdvisor interface {
+	GenerateTriageAdvice(
+		ctx context.Context,
+		plant *models.Plant,
+		symptoms []models.TriageSymptom,
+		causes []models.TriageCause,
+		language models.Language,
+	) (string, error)
+}
+
+// TriageService ranks the likely causes of a struggling plant's symptoms and records the triage in the plant's journal
+type TriageService struct {
+	journalRepo repository.JournalRepository
+	plantRepo   repository.PlantRepository
+	advisor     TriageAdvisor
+}
+
+// NewTriageService creates a new triage service
+func NewTriageService(
+	journalRepo repository.JournalRepository,
+	plantRepo repository.PlantRepository,
+	advisor TriageAdvisor,
+) *TriageService {
+	return &TriageService{
+		journalRepo: journalRepo,
+		plantRepo:   plantRepo,
+		advisor:     advisor,
+	}
+}
+
+// Triage ranks the likely causes of the symptoms with immediate actions. When a plant from the user's
+// collection is given, its watering history and light needs are taken into account and the triage is
+// added to its journal. The follow-up advice is omitted when Yandex GPT is unavailable.
+func (s *TriageService) Triage(
+	ctx context.Context,
+	userID uuid.UUID,
+	req *models.TriageRequest,
+	language models.Language,
+) (*models.TriageResult, error) {
+	if _, ok := triageActions[language]; !ok {
+		language = models.LanguageRussian
+	}
+
+	var userPlant *models.UserPlant
+	var plant *models.Plant
+	if req.PlantID != nil {
+		var err error
+		userPlant, err = s.plantRepo.GetUserPlant(ctx, userID, *req.PlantID)
+		if err != nil {
+			return nil, fmt.Errorf("plant not in user's collection: %w", err)
+		}
+		plant, err = s.plantRepo.GetByID(ctx, *req.PlantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get plant: %w", err)
+		}
+	}
+
+	result := &models.TriageResult{
+		PlantID: req.PlantID,
+		Causes:  rankTriageCauses(req.Symptoms, userPlant, plant, time.Now()),
+	}
+	for _, cause := range result.Causes {
+		cause.Actions = triageActions[language][cause.Cause]
+	}
+
+	// The rule-based result is useful on its own, so a failed follow-up is only logged
+	if s.advisor != nil && len(result.Causes) > 0 {
+		causes := make([]models.TriageCause, 0, len(result.Causes))
+		for _, cause := range result.Causes {
+			causes = append(causes, cause.Cause)
+		}
+		followUp, err := s.advisor.GenerateTriageAdvice(ctx, plant, req.Symptoms, causes, language)
+		if err != nil {
+			if !errors.Is(err, ErrYandexGPTNotConfigured) {
+				log.Printf("Error generating triage follow-up: %v", err)
+			}
+		} else if followUp = strings.TrimSpace(followUp); followUp != "" {
+			result.FollowUp = &followUp
+		}
+	}
+
+	if userPlant != nil {
+		entry := &models.PlantJournalEntry{
+			UserID:  userID,
+			PlantID: userPlant.PlantID,
+			Type:    models.JournalEntryTypeTriage,
+			Text:    triageJournalText(req.Symptoms, result),
+		}
+		if err := s.journalRepo.Create(ctx, entry); err != nil {
+			return nil, fmt.Errorf("failed to save triage to journal: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// GetJournal gets the journal of a plant in the user's collection
+func (s *TriageService) GetJournal(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.PlantJournalEntry, error) {
+	entries, err := s.journalRepo.GetByUserPlant(ctx, userID, plantID, journalHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal: %w", err)
+	}
+	return entries, nil
+}
+
+// rankTriageCauses scores the causes of the symptoms and returns the most likely ones. The plant's
+// watering history and light needs only strengthen causes the symptoms already point to.
+func rankTriageCauses(
+	symptoms []models.TriageSymptom,
+	userPlant *models.UserPlant,
+	plant *models.Plant,
+	now time.Time,
+) []*models.TriageCauseResult {
+	weights := make(map[models.TriageCause]float64)
+	for _, symptom := range symptoms {
+		for cause, weight := range triageWeights[symptom] {
+			weights[cause] += weight
+		}
+	}
+
+	boost := func(cause models.TriageCause, weight float64) {
+		if weights[cause] > 0 {
+			weights[cause] += weight
+		}
+	}
+	if userPlant != nil && plant != nil {
+		frequency := plant.CareInstructions.WateringFrequency
+		switch {
+		case userPlant.NextWatering != nil && now.After(userPlant.NextWatering.AddDate(0, 0, 2)):
+			boost(models.TriageCauseUnderwatering, 2)
+		case userPlant.LastWatered != nil && frequency >= 3 && now.Sub(*userPlant.LastWatered) < time.Duration(frequency)*24*time.Hour/3:
+			boost(models.TriageCauseOverwatering, 2)
+		}
+
+		switch plant.CareInstructions.Sunlight {
+		case models.SunlightLevelHigh:
+			boost(models.TriageCauseLowLight, 1)
+		case models.SunlightLevelLow:
+			boost(models.TriageCauseSunburn, 1)
+		}
+	}
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+
+	causes := make([]*models.TriageCauseResult, 0, len(weights))
+	for cause, weight := range weights {
+		causes = append(causes, &models.TriageCauseResult{
+			Cause: cause,
+			Score: math.Round(weight/total*100) / 100,
+		})
+	}
+	sort.Slice(causes, func(i, j int) bool {
+		if causes[i].Score != causes[j].Score {
+			return causes[i].Score > causes[j].Score
+		}
+		return causes[i].Cause < causes[j].Cause
+	})
+
+	if len(causes) > maxTriageCauses {
+		causes = causes[:maxTriageCauses]
+	}
+	return causes
+}
+
+// triageJournalText summarizes a triage for the plant's journal
+func triageJournalText(symptoms []models.TriageSymptom, result *models.TriageResult) string {
+	names := make([]string, 0, len(symptoms))
+	for _, symptom := range symptoms {
+		names = append(names, string(symptom))
+	}
+	causes := make([]string, 0, len(result.Causes))
+	for _, cause := range result.Causes {
+		causes = append(causes, fmt.Sprintf("%s (%.0f%%)", cause.Cause, cause.Score*100))
+	}
+
+	text := fmt.Sprintf("Symptoms: %s. Likely causes: %s.", strings.Join(names, ", "), strings.Join(causes, ", "))
+	if result.FollowUp != nil {
+		text += "\n\n" + *result.FollowUp
+	}
+	return text
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockJournalRepository is a mock implementation of the JournalRepository interface
+type MockJournalRepository struct {
+	mock.Mock
+}
+
+func (m *MockJournalRepository) Create(ctx context.Context, entry *models.PlantJournalEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockJournalRepository) GetByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, limit int) ([]*models.PlantJournalEntry, error) {
+	args := m.Called(ctx, userID, plantID, limit)
+	return args.Get(0).([]*models.PlantJournalEntry), args.Error(1)
+}
+
+// MockTriageAdvisor is a mock implementation of the TriageAdvisor interface
+type MockTriageAdvisor struct {
+	mock.Mock
+}
+
+func (m *MockTriageAdvisor) GenerateTriageAdvice(
+	ctx context.Context,
+	plant *models.Plant,
+	symptoms []models.TriageSymptom,
+	causes []models.TriageCause,
+	language models.Language,
+) (string, error) {
+	args := m.Called(ctx, plant, symptoms, causes, language)
+	return args.String(0), args.Error(1)
+}
+
+// TestRankTriageCauses tests that symptoms are ranked into causes
+func TestRankTriageCauses(t *testing.T) {
+	causes := rankTriageCauses([]models.TriageSymptom{models.TriageSymptomLeggyGrowth, models.TriageSymptomPaleLeaves}, nil, nil, time.Now())
+
+	assert.Len(t, causes, 2)
+	assert.Equal(t, models.TriageCauseLowLight, causes[0].Cause)
+	assert.Equal(t, 0.75, causes[0].Score)
+	assert.Equal(t, models.TriageCauseSunburn, causes[1].Cause)
+}
+
+// TestRankTriageCauses_WateringHistory tests that an overdue watering makes underwatering more likely
+func TestRankTriageCauses_WateringHistory(t *testing.T) {
+	symptoms := []models.TriageSymptom{models.TriageSymptomDrooping, models.TriageSymptomYellowLeaves}
+	now := time.Now()
+	plant := &models.Plant{CareInstructions: models.CareInstructions{WateringFrequency: 7, Sunlight: models.SunlightLevelMedium}}
+
+	// Without history, yellow leaves and drooping point to overwatering
+	causes := rankTriageCauses(symptoms, nil, nil, now)
+	assert.Equal(t, models.TriageCauseOverwatering, causes[0].Cause)
+
+	overdue := now.AddDate(0, 0, -5)
+	causes = rankTriageCauses(symptoms, &models.UserPlant{NextWatering: &overdue}, plant, now)
+	assert.Equal(t, models.TriageCauseUnderwatering, causes[0].Cause)
+}
+
+// TestTriageService_Triage tests that the triage of an owned plant is saved to its journal with the follow-up
+func TestTriageService_Triage(t *testing.T) {
+	mockJournalRepo := new(MockJournalRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockAdvisor := new(MockTriageAdvisor)
+	service := NewTriageService(mockJournalRepo, mockPlantRepo, mockAdvisor)
+	ctx := context.Background()
+	userID := uuid.New()
+	plantID := uuid.New()
+	plant := &models.Plant{ID: plantID, Name: "Monstera"}
+	symptoms := []models.TriageSymptom{models.TriageSymptomMushyStem}
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID}, nil)
+	mockPlantRepo.On("GetByID", ctx, plantID).Return(plant, nil)
+	mockAdvisor.On("GenerateTriageAdvice", ctx, plant, symptoms,
+		[]models.TriageCause{models.TriageCauseOverwatering, models.TriageCauseFungalDisease}, models.LanguageEnglish).
+		Return("Check the roots.", nil)
+	mockJournalRepo.On("Create", ctx, mock.MatchedBy(func(e *models.PlantJournalEntry) bool {
+		return e.UserID == userID && e.PlantID == plantID && e.Type == models.JournalEntryTypeTriage &&
+			e.Text == "Symptoms: MUSHY_STEM. Likely causes: OVERWATERING (67%), FUNGAL_DISEASE (33%).\n\nCheck the roots."
+	})).Return(nil)
+
+	result, err := service.Triage(ctx, userID, &models.TriageRequest{Symptoms: symptoms, PlantID: &plantID}, models.LanguageEnglish)
+	assert.NoError(t, err)
+	assert.Equal(t, "Check the roots.", *result.FollowUp)
+	assert.NotEmpty(t, result.Causes[0].Actions)
+	mockJournalRepo.AssertExpectations(t)
+}
+
+// TestTriageService_Triage_WithoutPlant tests that a triage without a plant is not saved and survives a failed follow-up
+func TestTriageService_Triage_WithoutPlant(t *testing.T) {
+	mockJournalRepo := new(MockJournalRepository)
+	mockAdvisor := new(MockTriageAdvisor)
+	service := NewTriageService(mockJournalRepo, new(MockPlantRepository), mockAdvisor)
+	ctx := context.Background()
+
+	mockAdvisor.On("GenerateTriageAdvice", ctx, (*models.Plant)(nil), mock.Anything, mock.Anything, models.LanguageRussian).
+		Return("", fmt.Errorf("timeout"))
+
+	result, err := service.Triage(ctx, uuid.New(), &models.TriageRequest{
+		Symptoms: []models.TriageSymptom{models.TriageSymptomBrownTips},
+	}, models.LanguageRussian)
+	assert.NoError(t, err)
+	assert.Nil(t, result.FollowUp)
+	assert.Equal(t, models.TriageCauseLowHumidity, result.Causes[0].Cause)
+	mockJournalRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrPhotoUploadUnavailable is returned when no object store is configured for uploaded photos
+	ErrPhotoUploadUnavailable = errors.New("photo uploads are not available")
+
+	// ErrUnsupportedPhoto is returned when an uploaded photo is not a JPEG, PNG or WebP image
+	ErrUnsupportedPhoto = errors.New("photo must be a JPEG, PNG or WebP image")
+
+	// ErrTooManyPhotos is returned when a plant in a collection already has the most photos allowed
+	ErrTooManyPhotos = errors.New("the plant already has the most photos allowed")
+)
+
+// maxUserPlantPhotos is the number of photos a plant in a collection can have
+const maxUserPlantPhotos = 30
+
+// photoExtensions maps the accepted photo content types to the extension of their keys
+var photoExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// AddUserPlantPhoto stores a photo of a plant in the user's collection. A photo the moderator finds
+// unsafe content on is quarantined until an admin approves it.
+func (s *PlantService) AddUserPlantPhoto(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, image []byte) (*models.UserPlantPhoto, error) {
+	if s.objects == nil || s.photoRepo == nil {
+		return nil, ErrPhotoUploadUnavailable
+	}
+
+	// Check the photo format
+	contentType := http.DetectContentType(image)
+	extension, ok := photoExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedPhoto
+	}
+
+	// Check if the user owns the plant
+	if _, err := s.plantRepo.GetUserPlant(ctx, userID, plantID); err != nil {
+		return nil, fmt.Errorf("plant not in user's collection: %w", err)
+	}
+	photos, err := s.photoRepo.ListByUserPlant(ctx, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plant photos: %w", err)
+	}
+	if len(photos) >= maxUserPlantPhotos {
+		return nil, ErrTooManyPhotos
+	}
+
+	// Store the image, then the photo referencing it
+	photo := &models.UserPlantPhoto{
+		ID:          uuid.New(),
+		UserID:      userID,
+		PlantID:     plantID,
+		ContentType: contentType,
+	}
+	s.moderatePhoto(ctx, photo, image)
+	photo.ImageURL = models.AssetKey(fmt.Sprintf("user-plants/%s/%s/%s%s", userID, plantID, photo.ID, extension))
+	if err := s.objects.Put(ctx, string(photo.ImageURL), image, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store photo: %w", err)
+	}
+	if err := s.photoRepo.Create(ctx, photo); err != nil {
+		s.deleteStoredPhoto(ctx, photo)
+		return nil, fmt.Errorf("failed to save photo: %w", err)
+	}
+
+	recordPlantEvent(ctx, s.recorder, userID, plantID, models.PlantEventTypePhotoAdded, models.PlantEventPayload{
+		"photoId": photo.ID.String(),
+	})
+	return photo, nil
+}
+
+// moderatePhoto sets the moderation status and labels of a new photo. A photo the moderator fails
+// to check is quarantined, so nothing goes live unchecked.
+func (s *PlantService) moderatePhoto(ctx context.Context, photo *models.UserPlantPhoto, image []byte) {
+	photo.ModerationStatus = models.PhotoModerationApproved
+	photo.ModerationLabels = pq.StringArray{}
+	if s.moderator == nil {
+		return
+	}
+
+	moderation, err := s.moderator.Moderate(ctx, image, photo.ContentType)
+	if err != nil {
+		log.Printf("Error moderating photo %s with %s, quarantining it: %v", photo.ID, s.moderator.Name(), err)
+		photo.ModerationStatus = models.PhotoModerationQuarantined
+		return
+	}
+	photo.ModerationLabels = append(photo.ModerationLabels, moderation.Unsafe...)
+	if len(moderation.Unsafe) > 0 {
+		photo.ModerationStatus = models.PhotoModerationQuarantined
+	}
+	if moderation.NotPlant {
+		photo.ModerationLabels = append(photo.ModerationLabels, models.PhotoModerationLabelNotPlant)
+	}
+}
+
+// GetQuarantinedPhotos gets the photos awaiting an admin's review, oldest first
+func (s *PlantService) GetQuarantinedPhotos(ctx context.Context) ([]*models.UserPlantPhoto, error) {
+	if s.photoRepo == nil {
+		return []*models.UserPlantPhoto{}, nil
+	}
+	photos, err := s.photoRepo.ListQuarantined(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quarantined photos: %w", err)
+	}
+	return photos, nil
+}
+
+// ApprovePhoto approves a quarantined photo, so it goes live
+func (s *PlantService) ApprovePhoto(ctx context.Context, photoID uuid.UUID) (*models.UserPlantPhoto, error) {
+	if s.photoRepo == nil {
+		return nil, fmt.Errorf("quarantined photo not found: %w", sql.ErrNoRows)
+	}
+	photo, err := s.photoRepo.Approve(ctx, photoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve photo: %w", err)
+	}
+	return photo, nil
+}
+
+// RejectPhoto deletes a quarantined photo along with its stored image
+func (s *PlantService) RejectPhoto(ctx context.Context, photoID uuid.UUID) error {
+	if s.photoRepo == nil {
+		return fmt.Errorf("quarantined photo not found: %w", sql.ErrNoRows)
+	}
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return fmt.Errorf("failed to get photo: %w", err)
+	}
+	if photo.ModerationStatus != models.PhotoModerationQuarantined {
+		return fmt.Errorf("quarantined photo not found: %w", sql.ErrNoRows)
+	}
+
+	if err := s.photoRepo.Delete(ctx, photoID); err != nil {
+		return fmt.Errorf("failed to delete photo: %w", err)
+	}
+	s.deleteStoredPhoto(ctx, photo)
+	return nil
+}
+
+// GetUserPlantPhotos gets the photos of a plant in the user's collection, newest first
+func (s *PlantService) GetUserPlantPhotos(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.UserPlantPhoto, error) {
+	if s.photoRepo == nil {
+		return []*models.UserPlantPhoto{}, nil
+	}
+	photos, err := s.photoRepo.ListByUserPlant(ctx, userID, plantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plant photos: %w", err)
+	}
+	return photos, nil
+}
+
+// DeleteUserPlantPhoto deletes a photo of a plant in the user's collection. Photos of other
+// plants or users are reported as not found.
+func (s *PlantService) DeleteUserPlantPhoto(ctx context.Context, userID uuid.UUID, plantID uuid.UUID, photoID uuid.UUID) error {
+	if s.photoRepo == nil {
+		return fmt.Errorf("user plant photo not found: %w", sql.ErrNoRows)
+	}
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return fmt.Errorf("failed to get user plant photo: %w", err)
+	}
+	if photo.UserID != userID || photo.PlantID != plantID {
+		return fmt.Errorf("user plant photo not found: %w", sql.ErrNoRows)
+	}
+
+	if err := s.photoRepo.Delete(ctx, photoID); err != nil {
+		return fmt.Errorf("failed to delete user plant photo: %w", err)
+	}
+	s.deleteStoredPhoto(ctx, photo)
+	return nil
+}
+
+// attachPhotos sets the photos of the plants in a user's collection
+func (s *PlantService) attachPhotos(ctx context.Context, userID uuid.UUID, plants []*models.Plant) error {
+	if s.photoRepo == nil || len(plants) == 0 {
+		return nil
+	}
+	photos, err := s.photoRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user plant photos: %w", err)
+	}
+
+	byPlant := make(map[uuid.UUID][]*models.UserPlantPhoto)
+	for _, photo := range photos {
+		byPlant[photo.PlantID] = append(byPlant[photo.PlantID], photo)
+	}
+	for _, plant := range plants {
+		plant.Photos = byPlant[plant.ID]
+	}
+	return nil
+}
+
+// deleteStoredPhoto deletes the image of a photo from the object store. The photo is gone either
+// way, so a failure only leaves an orphaned object behind and is logged.
+func (s *PlantService) deleteStoredPhoto(ctx context.Context, photo *models.UserPlantPhoto) {
+	if s.objects == nil {
+		return
+	}
+	if err := s.objects.Delete(ctx, string(photo.ImageURL)); err != nil {
+		log.Printf("Error deleting stored photo %s: %v", photo.ImageURL, err)
+	}
+}
+
+// normalizeUserPlantDetail trims a nickname or notes; blank values clear the detail
+func normalizeUserPlantDetail(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
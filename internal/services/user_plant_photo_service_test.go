@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUserPlantPhotoRepository is a mock implementation of the UserPlantPhotoRepository interface
+type MockUserPlantPhotoRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserPlantPhotoRepository) Create(ctx context.Context, photo *models.UserPlantPhoto) error {
+	args := m.Called(ctx, photo)
+	return args.Error(0)
+}
+
+func (m *MockUserPlantPhotoRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.UserPlantPhoto, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserPlantPhoto), args.Error(1)
+}
+
+func (m *MockUserPlantPhotoRepository) ListByUserPlant(ctx context.Context, userID uuid.UUID, plantID uuid.UUID) ([]*models.UserPlantPhoto, error) {
+	args := m.Called(ctx, userID, plantID)
+	return args.Get(0).([]*models.UserPlantPhoto), args.Error(1)
+}
+
+func (m *MockUserPlantPhotoRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.UserPlantPhoto, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.UserPlantPhoto), args.Error(1)
+}
+
+func (m *MockUserPlantPhotoRepository) ListQuarantined(ctx context.Context) ([]*models.UserPlantPhoto, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*models.UserPlantPhoto), args.Error(1)
+}
+
+func (m *MockUserPlantPhotoRepository) Approve(ctx context.Context, id uuid.UUID) (*models.UserPlantPhoto, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserPlantPhoto), args.Error(1)
+}
+
+func (m *MockUserPlantPhotoRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// memoryObjectStore is an object store keeping objects in memory
+type memoryObjectStore map[string][]byte
+
+func (s memoryObjectStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	s[key] = data
+	return nil
+}
+
+func (s memoryObjectStore) Delete(ctx context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+// pngPhoto is the start of a PNG image, enough for its format to be detected
+var pngPhoto = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// TestPlantService_AddUserPlantPhoto tests that uploaded photos are stored under a key of the plant in the collection
+func TestPlantService_AddUserPlantPhoto(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockPhotoRepo := new(MockUserPlantPhotoRepository)
+	plantService := NewPlantService(mockPlantRepo)
+	plantService.SetPhotoRepository(mockPhotoRepo)
+	ctx := context.Background()
+	userID, plantID := uuid.New(), uuid.New()
+
+	// Uploads need an object store
+	_, err := plantService.AddUserPlantPhoto(ctx, userID, plantID, pngPhoto)
+	assert.ErrorIs(t, err, ErrPhotoUploadUnavailable)
+
+	objects := memoryObjectStore{}
+	plantService.SetObjectStore(objects)
+	_, err = plantService.AddUserPlantPhoto(ctx, userID, plantID, []byte("not a photo"))
+	assert.ErrorIs(t, err, ErrUnsupportedPhoto)
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID}, nil)
+	mockPhotoRepo.On("ListByUserPlant", ctx, userID, plantID).Return([]*models.UserPlantPhoto{}, nil).Once()
+	mockPhotoRepo.On("Create", ctx, mock.AnythingOfType("*models.UserPlantPhoto")).Return(nil).Once()
+
+	photo, err := plantService.AddUserPlantPhoto(ctx, userID, plantID, pngPhoto)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", photo.ContentType)
+	assert.True(t, strings.HasPrefix(string(photo.ImageURL), "user-plants/"+userID.String()+"/"+plantID.String()+"/"))
+	assert.True(t, strings.HasSuffix(string(photo.ImageURL), ".png"))
+	assert.Equal(t, pngPhoto, objects[string(photo.ImageURL)])
+
+	// Plants have a limited number of photos
+	mockPhotoRepo.On("ListByUserPlant", ctx, userID, plantID).Return(make([]*models.UserPlantPhoto, maxUserPlantPhotos), nil).Once()
+	_, err = plantService.AddUserPlantPhoto(ctx, userID, plantID, pngPhoto)
+	assert.ErrorIs(t, err, ErrTooManyPhotos)
+	mockPhotoRepo.AssertExpectations(t)
+}
+
+// TestPlantService_DeleteUserPlantPhoto tests that deleting a photo removes its image and that photos of other plants are not found
+func TestPlantService_DeleteUserPlantPhoto(t *testing.T) {
+	mockPhotoRepo := new(MockUserPlantPhotoRepository)
+	plantService := NewPlantService(new(MockPlantRepository))
+	plantService.SetPhotoRepository(mockPhotoRepo)
+	objects := memoryObjectStore{"user-plants/monstera.png": pngPhoto}
+	plantService.SetObjectStore(objects)
+	ctx := context.Background()
+
+	photo := &models.UserPlantPhoto{ID: uuid.New(), UserID: uuid.New(), PlantID: uuid.New(), ImageURL: "user-plants/monstera.png"}
+	mockPhotoRepo.On("GetByID", ctx, photo.ID).Return(photo, nil)
+	mockPhotoRepo.On("Delete", ctx, photo.ID).Return(nil).Once()
+
+	err := plantService.DeleteUserPlantPhoto(ctx, uuid.New(), photo.PlantID, photo.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	assert.NoError(t, plantService.DeleteUserPlantPhoto(ctx, photo.UserID, photo.PlantID, photo.ID))
+	assert.Empty(t, objects)
+	mockPhotoRepo.AssertExpectations(t)
+}
+
+// imageModeratorFunc is an image moderator calling a function
+type imageModeratorFunc func() (*ImageModeration, error)
+
+func (f imageModeratorFunc) Name() string {
+	return "test"
+}
+
+func (f imageModeratorFunc) Moderate(ctx context.Context, image []byte, contentType string) (*ImageModeration, error) {
+	return f()
+}
+
+// TestPlantService_AddUserPlantPhoto_Moderation tests that photos with unsafe content or that the
+// moderator failed to check are quarantined, and that photos showing no plant are labeled
+func TestPlantService_AddUserPlantPhoto_Moderation(t *testing.T) {
+	tests := []struct {
+		name       string
+		moderation *ImageModeration
+		err        error
+		status     models.PhotoModerationStatus
+		labels     []string
+	}{
+		{name: "clean", moderation: &ImageModeration{}, status: models.PhotoModerationApproved, labels: []string{}},
+		{name: "not a plant", moderation: &ImageModeration{NotPlant: true}, status: models.PhotoModerationApproved, labels: []string{"not_plant"}},
+		{name: "unsafe", moderation: &ImageModeration{Unsafe: []string{"gruesome"}, NotPlant: true}, status: models.PhotoModerationQuarantined, labels: []string{"gruesome", "not_plant"}},
+		{name: "moderator failed", err: errors.New("timeout"), status: models.PhotoModerationQuarantined, labels: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPlantRepo := new(MockPlantRepository)
+			mockPhotoRepo := new(MockUserPlantPhotoRepository)
+			plantService := NewPlantService(mockPlantRepo)
+			plantService.SetPhotoRepository(mockPhotoRepo)
+			plantService.SetObjectStore(memoryObjectStore{})
+			plantService.SetImageModerator(imageModeratorFunc(func() (*ImageModeration, error) { return tt.moderation, tt.err }))
+			ctx := context.Background()
+			userID, plantID := uuid.New(), uuid.New()
+
+			mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{UserID: userID, PlantID: plantID}, nil)
+			mockPhotoRepo.On("ListByUserPlant", ctx, userID, plantID).Return([]*models.UserPlantPhoto{}, nil)
+			mockPhotoRepo.On("Create", ctx, mock.AnythingOfType("*models.UserPlantPhoto")).Return(nil)
+
+			photo, err := plantService.AddUserPlantPhoto(ctx, userID, plantID, pngPhoto)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, photo.ModerationStatus)
+			assert.Equal(t, tt.labels, []string(photo.ModerationLabels))
+		})
+	}
+}
+
+// TestPlantService_RejectPhoto tests that rejecting a quarantined photo deletes it with its image,
+// and that approved photos cannot be rejected
+func TestPlantService_RejectPhoto(t *testing.T) {
+	mockPhotoRepo := new(MockUserPlantPhotoRepository)
+	plantService := NewPlantService(new(MockPlantRepository))
+	plantService.SetPhotoRepository(mockPhotoRepo)
+	objects := memoryObjectStore{"user-plants/selfie.png": pngPhoto}
+	plantService.SetObjectStore(objects)
+	ctx := context.Background()
+
+	approved := &models.UserPlantPhoto{ID: uuid.New(), ModerationStatus: models.PhotoModerationApproved}
+	quarantined := &models.UserPlantPhoto{ID: uuid.New(), ImageURL: "user-plants/selfie.png", ModerationStatus: models.PhotoModerationQuarantined}
+	mockPhotoRepo.On("GetByID", ctx, approved.ID).Return(approved, nil)
+	mockPhotoRepo.On("GetByID", ctx, quarantined.ID).Return(quarantined, nil)
+	mockPhotoRepo.On("Delete", ctx, quarantined.ID).Return(nil).Once()
+
+	assert.ErrorIs(t, plantService.RejectPhoto(ctx, approved.ID), sql.ErrNoRows)
+	assert.NoError(t, plantService.RejectPhoto(ctx, quarantined.ID))
+	assert.Empty(t, objects)
+	mockPhotoRepo.AssertExpectations(t)
+}
+
+// TestPlantService_UpdateUserPlant_Details tests that nicknames and notes are trimmed, blank ones cleared and unset ones kept
+func TestPlantService_UpdateUserPlant_Details(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	plantService := NewPlantService(mockPlantRepo)
+	ctx := context.Background()
+	userID, plantID := uuid.New(), uuid.New()
+	kitchen, notes := "Kitchen", "Repotted in spring"
+
+	mockPlantRepo.On("GetUserPlant", ctx, userID, plantID).Return(&models.UserPlant{
+		UserID: userID, PlantID: plantID, Location: &kitchen, Notes: &notes,
+	}, nil)
+	mockPlantRepo.On("UpdateUserPlant", ctx, mock.MatchedBy(func(up *models.UserPlant) bool {
+		return *up.Location == "Kitchen" && up.Nickname != nil && *up.Nickname == "Monty" && up.Notes == nil
+	})).Return(nil).Once()
+
+	nickname, blank := "  Monty ", " "
+	err := plantService.UpdateUserPlant(ctx, userID, plantID, nil, models.UserPlantDetails{Nickname: &nickname, Notes: &blank})
+	assert.NoError(t, err)
+	mockPlantRepo.AssertExpectations(t)
+}
+
+// TestPlantService_GetUserPlants_Photos tests that the plants of a collection come with their photos
+func TestPlantService_GetUserPlants_Photos(t *testing.T) {
+	mockPlantRepo := new(MockPlantRepository)
+	mockPhotoRepo := new(MockUserPlantPhotoRepository)
+	plantService := NewPlantService(mockPlantRepo)
+	plantService.SetPhotoRepository(mockPhotoRepo)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	monstera, ficus := &models.Plant{ID: uuid.New()}, &models.Plant{ID: uuid.New()}
+	photo := &models.UserPlantPhoto{ID: uuid.New(), UserID: userID, PlantID: monstera.ID}
+	mockPlantRepo.On("GetUserPlants", ctx, userID).Return([]*models.Plant{monstera, ficus}, nil)
+	mockPhotoRepo.On("ListByUser", ctx, userID).Return([]*models.UserPlantPhoto{photo}, nil)
+
+	plants, err := plantService.GetUserPlants(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.UserPlantPhoto{photo}, plants[0].Photos)
+	assert.Empty(t, plants[1].Photos)
+}
@@ -2,16 +2,40 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/anpanovv/planter/internal/audit"
 	"github.com/anpanovv/planter/internal/models"
 	"github.com/anpanovv/planter/internal/repository"
 	"github.com/google/uuid"
 )
 
+// ErrInvalidReminderChannel is returned when a user picks an unknown watering reminder channel
+var ErrInvalidReminderChannel = errors.New("watering reminder channel must be PUSH or EMAIL")
+
+// ErrInvalidCity is returned when a user names a city longer than the profile keeps
+var ErrInvalidCity = errors.New("city must be at most 255 characters")
+
+// ErrInvalidTimezone is returned when a user picks a time zone that is not a known IANA zone
+var ErrInvalidTimezone = errors.New("timezone must be an IANA time zone, e.g. Europe/Moscow")
+
+// ErrInvalidNotificationHour is returned when a user picks an hour watering reminders cannot start at
+var ErrInvalidNotificationHour = fmt.Errorf("notification hour must be between 0 and %d", MaxNotificationHour)
+
+// maxCityLength is the longest city a profile keeps
+const maxCityLength = 255
+
 // UserService handles user operations
 type UserService struct {
-	userRepo repository.UserRepository
+	userRepo   repository.UserRepository
+	mergeRepo  repository.AccountMergeRepository
+	auditTrail *audit.Trail
 }
 
 // NewUserService creates a new user service
@@ -21,6 +45,16 @@ func NewUserService(userRepo repository.UserRepository) *UserService {
 	}
 }
 
+// SetAccountMergeRepository sets the repository accounts are merged with
+func (s *UserService) SetAccountMergeRepository(mergeRepo repository.AccountMergeRepository) {
+	s.mergeRepo = mergeRepo
+}
+
+// SetAuditTrail sets the audit trail profile edits are recorded in
+func (s *UserService) SetAuditTrail(auditTrail *audit.Trail) {
+	s.auditTrail = auditTrail
+}
+
 // GetUser gets a user by ID
 func (s *UserService) GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -37,6 +71,7 @@ func (s *UserService) UpdateUser(ctx context.Context, user *models.User) (*model
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+	before := *existingUser
 
 	// Update only the allowed fields
 	existingUser.Name = user.Name
@@ -45,15 +80,90 @@ func (s *UserService) UpdateUser(ctx context.Context, user *models.User) (*model
 	existingUser.NotificationsEnabled = user.NotificationsEnabled
 	existingUser.Locations = user.Locations
 
+	// Announcements are targeted at cities regardless of case and surrounding spaces; a blank city clears it
+	existingUser.City = nil
+	if user.City != nil {
+		if city := strings.TrimSpace(*user.City); city != "" {
+			if utf8.RuneCountInString(city) > maxCityLength {
+				return nil, ErrInvalidCity
+			}
+			existingUser.City = &city
+		}
+	}
+
+	// Clients that do not know the reminder channel leave it unchanged
+	switch user.WateringReminderChannel {
+	case "":
+	case models.ReminderChannelPush, models.ReminderChannelEmail:
+		existingUser.WateringReminderChannel = user.WateringReminderChannel
+	default:
+		return nil, ErrInvalidReminderChannel
+	}
+
+	// Clients that do not know the time zone leave it unchanged; a blank one clears it
+	if user.Timezone != nil {
+		existingUser.Timezone = nil
+		if timezone := strings.TrimSpace(*user.Timezone); timezone != "" {
+			if _, err := LoadTimezone(timezone); err != nil {
+				return nil, err
+			}
+			existingUser.Timezone = &timezone
+		}
+	}
+	if user.NotificationHour != nil {
+		if *user.NotificationHour < 0 || *user.NotificationHour > MaxNotificationHour {
+			return nil, ErrInvalidNotificationHour
+		}
+		existingUser.NotificationHour = user.NotificationHour
+	}
+
 	// Update the user
 	err = s.userRepo.Update(ctx, existingUser)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	// Record the fields that changed
+	changes := models.AuditChanges{}
+	audit.Diff(changes, "name", before.Name, existingUser.Name)
+	audit.Diff(changes, "profileImageUrl", before.ProfileImageURL, existingUser.ProfileImageURL)
+	audit.Diff(changes, "language", before.Language, existingUser.Language)
+	audit.Diff(changes, "notificationsEnabled", before.NotificationsEnabled, existingUser.NotificationsEnabled)
+	audit.Diff(changes, "locations", before.Locations, existingUser.Locations)
+	audit.Diff(changes, "city", before.City, existingUser.City)
+	audit.Diff(changes, "wateringReminderChannel", before.WateringReminderChannel, existingUser.WateringReminderChannel)
+	audit.Diff(changes, "timezone", before.Timezone, existingUser.Timezone)
+	audit.Diff(changes, "notificationHour", before.NotificationHour, existingUser.NotificationHour)
+	if len(changes) > 0 {
+		s.auditTrail.Record(ctx, &models.AuditEntry{
+			Action:     models.AuditActionUpdateProfile,
+			EntityType: "users",
+			EntityID:   existingUser.ID.String(),
+			Changes:    changes,
+		})
+	}
+
 	return existingUser, nil
 }
 
+// LoadTimezone loads the IANA time zone a user chose; it fails with ErrInvalidTimezone for unknown zones,
+// UTC named by an empty name and Local, which LoadLocation takes for the zone of the server
+func LoadTimezone(name string) (*time.Location, error) {
+	location, err := time.LoadLocation(name)
+	if err != nil || name == "" || name == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	return location, nil
+}
+
+// DeleteUser soft-deletes a user: the account can no longer sign in or be found, and its data is kept
+func (s *UserService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	if err := s.userRepo.SoftDelete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return nil
+}
+
 // AddLocation adds a location to a user
 func (s *UserService) AddLocation(ctx context.Context, userID uuid.UUID, location string) error {
 	err := s.userRepo.AddLocation(ctx, userID, location)
@@ -79,4 +189,57 @@ func (s *UserService) GetLocations(ctx context.Context, userID uuid.UUID) ([]str
 		return nil, fmt.Errorf("failed to get locations: %w", err)
 	}
 	return locations, nil
-}
\ No newline at end of file
+}
+// GetRoles gets the roles granted to a user; deleted users have none
+func (s *UserService) GetRoles(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	roles, err := s.userRepo.GetRoles(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+	return roles, nil
+}
+
+// HasRole checks if a user has been granted a role
+func (s *UserService) HasRole(ctx context.Context, userID uuid.UUID, role string) (bool, error) {
+	roles, err := s.userRepo.GetRoles(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Tokens of deleted users grant no roles
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get roles: %w", err)
+	}
+	for _, granted := range roles {
+		if granted == role {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GrantRole grants a role to the user registered with an email
+func (s *UserService) GrantRole(ctx context.Context, email string, role models.Role) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	err = s.userRepo.AddRole(ctx, user.ID, role)
+	if err != nil {
+		return fmt.Errorf("failed to grant role: %w", err)
+	}
+	return nil
+}
+
+// BootstrapAdmins grants the admin role to the users registered with the emails. Emails that are
+// not registered yet are logged and skipped, so they are picked up on a later start.
+func (s *UserService) BootstrapAdmins(ctx context.Context, emails []string) {
+	for _, email := range emails {
+		if err := s.GrantRole(ctx, email, models.RoleAdmin); err != nil {
+			log.Printf("Error granting the admin role to %s: %v", email, err)
+		}
+	}
+}
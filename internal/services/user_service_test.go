@@ -2,8 +2,12 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/anpanovv/planter/internal/audit"
 	"github.com/anpanovv/planter/internal/models"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -89,4 +93,146 @@ func TestUserService_UpdateUser(t *testing.T) {
 
 	// Verify that all expectations were met
 	mockUserRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+// TestUserService_UpdateUser_City tests that the city is trimmed, cleared when blank and limited in length
+func TestUserService_UpdateUser_City(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	userService := NewUserService(mockUserRepo)
+	userID := uuid.New()
+	city := "Казань"
+	mockUserRepo.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID, City: &city}, nil)
+	mockUserRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User")).Return(nil)
+
+	newCity := "  Нижний Новгород "
+	result, err := userService.UpdateUser(context.Background(), &models.User{ID: userID, City: &newCity})
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.City) {
+		assert.Equal(t, "Нижний Новгород", *result.City)
+	}
+
+	blank := " "
+	result, err = userService.UpdateUser(context.Background(), &models.User{ID: userID, City: &blank})
+	assert.NoError(t, err)
+	assert.Nil(t, result.City)
+
+	long := strings.Repeat("г", 256)
+	_, err = userService.UpdateUser(context.Background(), &models.User{ID: userID, City: &long})
+	assert.ErrorIs(t, err, ErrInvalidCity)
+}
+
+// TestUserService_UpdateUser_Timezone tests that the time zone and notification hour are validated and
+// left unchanged by clients that do not send them
+func TestUserService_UpdateUser_Timezone(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	userService := NewUserService(mockUserRepo)
+	userID := uuid.New()
+	timezone, hour := "Europe/Moscow", 9
+	mockUserRepo.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID, Timezone: &timezone, NotificationHour: &hour}, nil)
+	mockUserRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User")).Return(nil)
+
+	newTimezone, newHour := " America/New_York ", 7
+	result, err := userService.UpdateUser(context.Background(), &models.User{ID: userID, Timezone: &newTimezone, NotificationHour: &newHour})
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.Timezone) && assert.NotNil(t, result.NotificationHour) {
+		assert.Equal(t, "America/New_York", *result.Timezone)
+		assert.Equal(t, 7, *result.NotificationHour)
+	}
+
+	result, err = userService.UpdateUser(context.Background(), &models.User{ID: userID})
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.Timezone) && assert.NotNil(t, result.NotificationHour) {
+		assert.Equal(t, "America/New_York", *result.Timezone)
+		assert.Equal(t, 7, *result.NotificationHour)
+	}
+
+	blank := ""
+	result, err = userService.UpdateUser(context.Background(), &models.User{ID: userID, Timezone: &blank})
+	assert.NoError(t, err)
+	assert.Nil(t, result.Timezone)
+
+	for _, invalid := range []string{"Mars/Olympus", "Local"} {
+		_, err = userService.UpdateUser(context.Background(), &models.User{ID: userID, Timezone: &invalid})
+		assert.ErrorIs(t, err, ErrInvalidTimezone)
+	}
+	for _, invalid := range []int{-1, 22} {
+		_, err = userService.UpdateUser(context.Background(), &models.User{ID: userID, NotificationHour: &invalid})
+		assert.ErrorIs(t, err, ErrInvalidNotificationHour)
+	}
+}
+
+// MockAuditRepository is a mock implementation of the AuditRepository interface
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Record(ctx context.Context, entry *models.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter *models.AuditLogFilter, limit int, offset int) ([]*models.AuditEntry, int, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	return args.Get(0).([]*models.AuditEntry), args.Int(1), args.Error(2)
+}
+
+// TestUserService_UpdateUser_Audit tests that profile edits are recorded with the fields they changed
+func TestUserService_UpdateUser_Audit(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockAuditRepo := new(MockAuditRepository)
+	userService := NewUserService(mockUserRepo)
+	userService.SetAuditTrail(audit.NewTrail(mockAuditRepo))
+	userID := uuid.New()
+	city := "Казань"
+	existingUser := &models.User{ID: userID, Name: "Anna", City: &city, Language: models.LanguageRussian, Locations: []string{}}
+	mockUserRepo.On("GetByID", mock.Anything, userID).Return(existingUser, nil)
+	mockUserRepo.On("Update", mock.Anything, mock.AnythingOfType("*models.User")).Return(nil)
+
+	newCity := "Москва"
+	mockAuditRepo.On("Record", mock.Anything, mock.MatchedBy(func(entry *models.AuditEntry) bool {
+		return entry.Action == models.AuditActionUpdateProfile && entry.EntityID == userID.String() &&
+			len(entry.Changes) == 1 &&
+			assert.ObjectsAreEqual(map[string]interface{}{"from": &city, "to": &newCity}, entry.Changes["city"])
+	})).Return(nil).Once()
+
+	_, err := userService.UpdateUser(context.Background(), &models.User{ID: userID, Name: "Anna", City: &newCity, Language: models.LanguageRussian})
+	assert.NoError(t, err)
+	mockAuditRepo.AssertExpectations(t)
+}
+
+// TestUserService_HasRole tests role lookups, including users that no longer exist
+func TestUserService_HasRole(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	userService := NewUserService(mockUserRepo)
+
+	adminID, deletedID := uuid.New(), uuid.New()
+	mockUserRepo.On("GetRoles", mock.Anything, adminID).Return([]string{string(models.RoleAdmin)}, nil)
+	mockUserRepo.On("GetRoles", mock.Anything, deletedID).Return(nil, fmt.Errorf("user not found: %w", sql.ErrNoRows))
+
+	granted, err := userService.HasRole(context.Background(), adminID, string(models.RoleAdmin))
+	assert.NoError(t, err)
+	assert.True(t, granted)
+
+	granted, err = userService.HasRole(context.Background(), adminID, "support")
+	assert.NoError(t, err)
+	assert.False(t, granted)
+
+	granted, err = userService.HasRole(context.Background(), deletedID, string(models.RoleAdmin))
+	assert.NoError(t, err)
+	assert.False(t, granted)
+}
+
+// TestUserService_GrantRole tests granting a role by email
+func TestUserService_GrantRole(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	userService := NewUserService(mockUserRepo)
+
+	user := &models.User{ID: uuid.New(), Email: "admin@example.com"}
+	mockUserRepo.On("GetByEmail", mock.Anything, "admin@example.com").Return(user, nil)
+	mockUserRepo.On("GetByEmail", mock.Anything, "nobody@example.com").Return(nil, fmt.Errorf("user not found: %w", sql.ErrNoRows))
+	mockUserRepo.On("AddRole", mock.Anything, user.ID, models.RoleAdmin).Return(nil)
+
+	assert.NoError(t, userService.GrantRole(context.Background(), "admin@example.com", models.RoleAdmin))
+	assert.ErrorIs(t, userService.GrantRole(context.Background(), "nobody@example.com", models.RoleAdmin), sql.ErrNoRows)
+	mockUserRepo.AssertExpectations(t)
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// GetWateringRoute groups the user's plants due for watering today by room. Rooms follow the
+// order of roomOrder (the user's room list), then unlisted rooms alphabetically, and plants
+// without a room come last. Within a room the most overdue plants come first.
+func (s *PlantService) GetWateringRoute(ctx context.Context, userID uuid.UUID, roomOrder []string, now time.Time) (*models.WateringRoute, error) {
+	plants, err := s.plantRepo.GetUserPlants(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user plants: %w", err)
+	}
+
+	// Plants that were never watered or are due by the end of today
+	today := truncateToDay(now)
+	endOfToday := today.AddDate(0, 0, 1)
+	stops := make(map[string]*models.WateringRouteStop)
+	var unassigned *models.WateringRouteStop
+	total := 0
+	for _, plant := range plants {
+		if plant.NextWatering != nil && !plant.NextWatering.Before(endOfToday) {
+			continue
+		}
+		total++
+
+		if plant.Location == nil || *plant.Location == "" {
+			if unassigned == nil {
+				unassigned = &models.WateringRouteStop{}
+			}
+			unassigned.Plants = append(unassigned.Plants, plant)
+			continue
+		}
+
+		stop, ok := stops[*plant.Location]
+		if !ok {
+			room := *plant.Location
+			stop = &models.WateringRouteStop{Room: &room}
+			stops[room] = stop
+		}
+		stop.Plants = append(stop.Plants, plant)
+	}
+
+	// Order the rooms: listed rooms first, then the rest alphabetically
+	position := make(map[string]int, len(roomOrder))
+	for i, room := range roomOrder {
+		if _, ok := position[room]; !ok {
+			position[room] = i
+		}
+	}
+	ordered := make([]*models.WateringRouteStop, 0, len(stops)+1)
+	for _, stop := range stops {
+		ordered = append(ordered, stop)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		pi, iListed := position[*ordered[i].Room]
+		pj, jListed := position[*ordered[j].Room]
+		switch {
+		case iListed && jListed:
+			return pi < pj
+		case iListed != jListed:
+			return iListed
+		default:
+			return *ordered[i].Room < *ordered[j].Room
+		}
+	})
+	if unassigned != nil {
+		ordered = append(ordered, unassigned)
+	}
+
+	// Number the stops and put the most overdue plants first
+	for i, stop := range ordered {
+		stop.Step = i + 1
+		sort.SliceStable(stop.Plants, func(a, b int) bool {
+			return wateringDueBefore(stop.Plants[a], stop.Plants[b])
+		})
+	}
+
+	return &models.WateringRoute{
+		Date:        today,
+		TotalPlants: total,
+		Stops:       ordered,
+	}, nil
+}
+
+// wateringDueBefore reports whether a is due for watering before b; never watered plants come first
+func wateringDueBefore(a *models.Plant, b *models.Plant) bool {
+	switch {
+	case a.NextWatering == nil:
+		return b.NextWatering != nil
+	case b.NextWatering == nil:
+		return false
+	default:
+		return a.NextWatering.Before(*b.NextWatering)
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+const (
+	// MaxNotificationHour is the latest local hour users can get the watering reminders of the day from,
+	// leaving an hour before reminders wait for the next morning
+	MaxNotificationHour = reminderEndHour - 1
+
+	// reminderEndHour is the local hour from which watering reminders wait for the next morning
+	reminderEndHour = 22
+)
+
+// reminderLocation gets the time zone the watering reminders of a plant follow: the one its owner
+// chose, else the one of the home the plant is in, else UTC. Zones that are no longer known count as UTC.
+func reminderLocation(userPlant *models.UserPlant) *time.Location {
+	name := ""
+	if userPlant.UserTimezone != nil {
+		name = *userPlant.UserTimezone
+	} else if userPlant.Home != nil {
+		name = userPlant.Home.Timezone
+	}
+	location, err := LoadTimezone(name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// localDay gets the start of the day a time falls on in a location. Days are not always 24 hours long:
+// the days clocks change to or from summer time on have 23 or 25.
+func localDay(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// wateringWindow gets the local day of the owner of a plant at now and reports whether now is in the
+// hours the owner gets watering reminders in, from their notification hour until reminderEndHour
+func wateringWindow(userPlant *models.UserPlant, now time.Time) (time.Time, bool) {
+	location := reminderLocation(userPlant)
+	hour := now.In(location).Hour()
+	return localDay(now, location), hour >= userPlant.UserNotificationHour && hour < reminderEndHour
+}
+
+// wateringDue reports whether a plant is to be watered on the given local day of its owner or is overdue
+func wateringDue(userPlant *models.UserPlant, day time.Time) bool {
+	// AddDate keeps the wall clock, so the next day starts at midnight even after clocks changed
+	return userPlant.NextWatering.Before(day.AddDate(0, 0, 1))
+}
+
+// wateringRemindedOn reports whether the owner of a plant already got the watering reminders of the
+// given local day
+func wateringRemindedOn(userPlant *models.UserPlant, day time.Time) bool {
+	remindedOn := userPlant.UserWateringRemindedOn
+	return remindedOn != nil && remindedOn.Format(time.DateOnly) >= day.Format(time.DateOnly)
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return location
+}
+
+func TestWateringWindow(t *testing.T) {
+	// 05:30 UTC is 08:30 in Moscow and 01:30 in New York
+	now := time.Date(2024, time.June, 1, 5, 30, 0, 0, time.UTC)
+	moscow, newYork := "Europe/Moscow", "America/New_York"
+	unknown := "Unknown/Zone"
+
+	for _, tc := range []struct {
+		name      string
+		userPlant *models.UserPlant
+		day       time.Time
+		open      bool
+	}{
+		{"no zone is UTC", &models.UserPlant{UserNotificationHour: 9}, time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), false},
+		{"early riser in UTC", &models.UserPlant{UserNotificationHour: 5}, time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), true},
+		{"home zone", &models.UserPlant{UserNotificationHour: 8, Home: &models.Home{Timezone: moscow}}, time.Date(2024, time.June, 1, 0, 0, 0, 0, mustLoadLocation(t, moscow)), true},
+		{"before the hour", &models.UserPlant{UserNotificationHour: 9, Home: &models.Home{Timezone: moscow}}, time.Date(2024, time.June, 1, 0, 0, 0, 0, mustLoadLocation(t, moscow)), false},
+		{"user zone over home zone", &models.UserPlant{UserNotificationHour: 0, UserTimezone: &newYork, Home: &models.Home{Timezone: moscow}}, time.Date(2024, time.June, 1, 0, 0, 0, 0, mustLoadLocation(t, newYork)), true},
+		{"unknown zone is UTC", &models.UserPlant{UserNotificationHour: 9, UserTimezone: &unknown}, time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			day, open := wateringWindow(tc.userPlant, now)
+			assert.True(t, tc.day.Equal(day), "day %s, want %s", day, tc.day)
+			assert.Equal(t, tc.open, open)
+		})
+	}
+
+	// Reminders wait for the next morning in the evening
+	_, open := wateringWindow(&models.UserPlant{UserNotificationHour: 9}, time.Date(2024, time.June, 1, 22, 0, 0, 0, time.UTC))
+	assert.False(t, open)
+}
+
+// TestWateringWindow_DST tests that the notification hour follows the local clock when it changes to
+// and from summer time
+func TestWateringWindow_DST(t *testing.T) {
+	berlin := "Europe/Berlin"
+	userPlant := &models.UserPlant{UserTimezone: &berlin, UserNotificationHour: 9}
+
+	// 07:30 UTC is 08:30 the day before clocks go forward on 31 March and 09:30 that day
+	_, open := wateringWindow(userPlant, time.Date(2024, time.March, 30, 7, 30, 0, 0, time.UTC))
+	assert.False(t, open)
+	_, open = wateringWindow(userPlant, time.Date(2024, time.March, 31, 7, 30, 0, 0, time.UTC))
+	assert.True(t, open)
+
+	// 07:30 UTC is 09:30 the day before clocks go back on 27 October and 08:30 that day
+	_, open = wateringWindow(userPlant, time.Date(2024, time.October, 26, 7, 30, 0, 0, time.UTC))
+	assert.True(t, open)
+	_, open = wateringWindow(userPlant, time.Date(2024, time.October, 27, 7, 30, 0, 0, time.UTC))
+	assert.False(t, open)
+
+	// An hour skipped when clocks go forward opens the window at the next hour: 01:00 UTC is 03:00
+	userPlant.UserNotificationHour = 2
+	_, open = wateringWindow(userPlant, time.Date(2024, time.March, 31, 0, 59, 0, 0, time.UTC))
+	assert.False(t, open)
+	day, open := wateringWindow(userPlant, time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC))
+	assert.True(t, open)
+	assert.True(t, time.Date(2024, time.March, 30, 23, 0, 0, 0, time.UTC).Equal(day))
+}
+
+// TestWateringDue_DST tests that a plant is due until the end of the local day of its owner on the days
+// clocks change, which are not 24 hours long
+func TestWateringDue_DST(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		zone     string
+		now      time.Time // local
+		lastDue  time.Time // local, last time on the day
+		firstOut time.Time // local, first time on the next day
+	}{
+		{
+			name:     "25 hour day in New York",
+			zone:     "America/New_York",
+			now:      time.Date(2024, time.November, 3, 10, 0, 0, 0, time.UTC),
+			lastDue:  time.Date(2024, time.November, 3, 23, 30, 0, 0, time.UTC),
+			firstOut: time.Date(2024, time.November, 4, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "23 hour day in Berlin",
+			zone:     "Europe/Berlin",
+			now:      time.Date(2024, time.March, 31, 10, 0, 0, 0, time.UTC),
+			lastDue:  time.Date(2024, time.March, 31, 23, 30, 0, 0, time.UTC),
+			firstOut: time.Date(2024, time.April, 1, 0, 30, 0, 0, time.UTC),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			location := mustLoadLocation(t, tc.zone)
+			local := func(clock time.Time) *time.Time {
+				at := time.Date(clock.Year(), clock.Month(), clock.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+				return &at
+			}
+			userPlant := &models.UserPlant{UserTimezone: &tc.zone, UserNotificationHour: 9}
+			day, open := wateringWindow(userPlant, *local(tc.now))
+			require.True(t, open)
+
+			userPlant.NextWatering = local(tc.lastDue)
+			assert.True(t, wateringDue(userPlant, day))
+			userPlant.NextWatering = local(tc.firstOut)
+			assert.False(t, wateringDue(userPlant, day))
+		})
+	}
+}
+
+// TestNotificationService_CheckAndCreateWateringNotifications_UserTimezone tests that the owner of a
+// plant due later on their local day is reminded once, from their notification hour
+func TestNotificationService_CheckAndCreateWateringNotifications_UserTimezone(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	service := NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(mockTemplateRepo))
+	ctx := context.Background()
+
+	// 23:30 UTC on 10 May is 09:30 on 11 May in Vladivostok, where the plant is due in the evening
+	vladivostok := "Asia/Vladivostok"
+	location := mustLoadLocation(t, vladivostok)
+	localDay := time.Date(2024, time.May, 11, 0, 0, 0, 0, location)
+	nextWatering := time.Date(2024, time.May, 11, 18, 0, 0, 0, location)
+	newUserPlant := func(remindedOn *time.Time) *models.UserPlant {
+		return &models.UserPlant{
+			UserID:                 uuid.MustParse("7e0f6a4e-5c7b-4a7f-9d9a-0b9e7f1c2d3e"),
+			PlantID:                uuid.MustParse("2b1d0c9e-8f7a-4e6d-9c5b-4a3f2e1d0c9b"),
+			NextWatering:           &nextWatering,
+			Plant:                  &models.Plant{Name: "Monstera"},
+			UserLanguage:           models.LanguageEnglish,
+			UserTimezone:           &vladivostok,
+			UserNotificationHour:   9,
+			UserWateringRemindedOn: remindedOn,
+		}
+	}
+
+	// At 03:00 local the reminder is held
+	service.now = func() time.Time { return time.Date(2024, time.May, 10, 17, 0, 0, 0, time.UTC) }
+	mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{newUserPlant(nil)}, nil).Once()
+	stats, err := service.CheckAndCreateWateringNotifications(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.RemindersHeld)
+	assert.Zero(t, stats.NotificationsCreated)
+
+	// At 09:30 local the owner is reminded, claiming their local day
+	service.now = func() time.Time { return time.Date(2024, time.May, 10, 23, 30, 0, 0, time.UTC) }
+	mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{newUserPlant(nil)}, nil).Once()
+	mockTemplateRepo.On("Get", ctx, models.NotificationTypeWatering, models.LanguageEnglish).Return(nil, nil)
+	mockNotificationRepo.On("ClaimWateringReminders", ctx, newUserPlant(nil).UserID, mock.MatchedBy(func(day time.Time) bool {
+		return day.Equal(localDay) && day.Format(time.DateOnly) == "2024-05-11"
+	})).Return(true, nil).Once()
+	mockNotificationRepo.On("Create", ctx, mock.AnythingOfType("*models.Notification")).Return(nil).Once()
+	stats, err = service.CheckAndCreateWateringNotifications(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.NotificationsCreated)
+
+	// Later checks of the day find the owner reminded
+	remindedOn := time.Date(2024, time.May, 11, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return time.Date(2024, time.May, 11, 1, 0, 0, 0, time.UTC) }
+	mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{newUserPlant(&remindedOn)}, nil).Once()
+	stats, err = service.CheckAndCreateWateringNotifications(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.NotificationsCreated)
+	mockNotificationRepo.AssertExpectations(t)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/anpanovv/planter/internal/repository"
+	"github.com/google/uuid"
+)
+
+// weatherLookbackDays is the number of days, today included, the weather of an outdoor plant is looked at
+const weatherLookbackDays = 2
+
+// weatherCacheTTL is how long the weather looked up at a place is reused; the notifications check runs
+// far more often than the weather changes
+const weatherCacheTTL = time.Hour
+
+// WeatherProvider looks up the recent weather at a place
+type WeatherProvider interface {
+	// GetRecentWeather sums the rainfall and finds the highest temperature at a place over the last
+	// days, today included
+	GetRecentWeather(ctx context.Context, latitude, longitude float64, days int) (*models.WeatherReport, error)
+}
+
+// WeatherService keeps the outdoor locations of users and decides how the weather changes the
+// watering reminders of the plants there: enough rain skips a reminder, heat sends it a day early
+type WeatherService struct {
+	locationRepo repository.OutdoorLocationRepository
+	provider     WeatherProvider // nil when reminders ignore the weather
+	rainSkipMM   float64
+	heatAdvance  float64
+
+	mu      sync.Mutex
+	reports map[[2]float64]cachedWeatherReport // by latitude and longitude
+	now     func() time.Time
+}
+
+// cachedWeatherReport is the weather at a place with the time it was looked up
+type cachedWeatherReport struct {
+	report    *models.WeatherReport
+	fetchedAt time.Time
+}
+
+// NewWeatherService creates a new weather service. Reminders are skipped after rainSkipMM of rain and
+// advanced from heatAdvance °C over the last two days.
+func NewWeatherService(locationRepo repository.OutdoorLocationRepository, rainSkipMM, heatAdvance float64) *WeatherService {
+	return &WeatherService{
+		locationRepo: locationRepo,
+		rainSkipMM:   rainSkipMM,
+		heatAdvance:  heatAdvance,
+		reports:      make(map[[2]float64]cachedWeatherReport),
+		now:          time.Now,
+	}
+}
+
+// SetProvider sets the provider the weather is looked up with. Without it outdoor locations are kept
+// but reminders ignore the weather.
+func (s *WeatherService) SetProvider(provider WeatherProvider) {
+	s.provider = provider
+}
+
+// Enabled reports whether reminders follow the weather
+func (s *WeatherService) Enabled() bool {
+	return s.provider != nil
+}
+
+// ListOutdoorLocations gets the outdoor locations of a user
+func (s *WeatherService) ListOutdoorLocations(ctx context.Context, userID uuid.UUID) ([]*models.OutdoorLocation, error) {
+	locations, err := s.locationRepo.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outdoor locations: %w", err)
+	}
+	return locations, nil
+}
+
+// SaveOutdoorLocation marks a location of a user as outdoors at the given coordinates. Plants whose
+// location matches it exactly follow the weather there.
+func (s *WeatherService) SaveOutdoorLocation(ctx context.Context, userID uuid.UUID, location *models.OutdoorLocation) error {
+	location.UserID = userID
+	location.Location = strings.TrimSpace(location.Location)
+	if err := s.locationRepo.Upsert(ctx, location); err != nil {
+		return fmt.Errorf("failed to save outdoor location: %w", err)
+	}
+	return nil
+}
+
+// DeleteOutdoorLocation stops treating a location of a user as outdoors
+func (s *WeatherService) DeleteOutdoorLocation(ctx context.Context, userID uuid.UUID, location string) error {
+	if err := s.locationRepo.Delete(ctx, userID, strings.TrimSpace(location)); err != nil {
+		return fmt.Errorf("failed to delete outdoor location: %w", err)
+	}
+	return nil
+}
+
+// WateringDecision decides how the weather at an outdoor location changes a watering reminder
+func (s *WeatherService) WateringDecision(ctx context.Context, location *models.OutdoorLocation) (*models.WateringWeatherDecision, error) {
+	report, err := s.recentWeather(ctx, location.Latitude, location.Longitude)
+	if err != nil {
+		return nil, err
+	}
+	return s.decide(report), nil
+}
+
+// recentWeather gets the weather at a place over the last days, looking it up at most once an hour so
+// plants sharing a place cost one lookup
+func (s *WeatherService) recentWeather(ctx context.Context, latitude, longitude float64) (*models.WeatherReport, error) {
+	key := [2]float64{latitude, longitude}
+	s.mu.Lock()
+	cached, ok := s.reports[key]
+	s.mu.Unlock()
+	if ok && s.now().Sub(cached.fetchedAt) < weatherCacheTTL {
+		return cached.report, nil
+	}
+
+	report, err := s.provider.GetRecentWeather(ctx, latitude, longitude, weatherLookbackDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weather: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for k, c := range s.reports {
+		if now.Sub(c.fetchedAt) >= weatherCacheTTL {
+			delete(s.reports, k)
+		}
+	}
+	s.reports[key] = cachedWeatherReport{report: report, fetchedAt: now}
+	return report, nil
+}
+
+// decide picks the action for a watering reminder from the recent weather. Rain wins over heat: a hot
+// day after a downpour leaves the soil wet enough.
+func (s *WeatherService) decide(report *models.WeatherReport) *models.WateringWeatherDecision {
+	decision := &models.WateringWeatherDecision{
+		Action:         models.WateringWeatherKept,
+		RainfallMM:     math.Round(report.RainfallMM*10) / 10,
+		MaxTemperature: math.Round(report.MaxTemperature*10) / 10,
+	}
+	switch {
+	case report.RainfallMM >= s.rainSkipMM:
+		decision.Action = models.WateringWeatherSkipped
+	case report.MaxTemperature >= s.heatAdvance:
+		decision.Action = models.WateringWeatherAdvanced
+	}
+	return decision
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubWeatherProvider returns the weather of places by latitude and counts the lookups
+type stubWeatherProvider struct {
+	reports map[float64]*models.WeatherReport
+	lookups int
+}
+
+func (p *stubWeatherProvider) GetRecentWeather(ctx context.Context, latitude, longitude float64, days int) (*models.WeatherReport, error) {
+	p.lookups++
+	report, ok := p.reports[latitude]
+	if !ok {
+		return nil, errors.New("weather unavailable")
+	}
+	return report, nil
+}
+
+// TestWeatherService_WateringDecision tests skipping after rain even when hot, advancing in heat,
+// keeping reminders otherwise and looking up each place once an hour
+func TestWeatherService_WateringDecision(t *testing.T) {
+	provider := &stubWeatherProvider{reports: map[float64]*models.WeatherReport{
+		10: {RainfallMM: 7.04, MaxTemperature: 31},
+		20: {RainfallMM: 1, MaxTemperature: 32.46},
+		30: {RainfallMM: 4.9, MaxTemperature: 25},
+	}}
+	service := NewWeatherService(nil, 5, 30)
+	service.SetProvider(provider)
+
+	now := time.Date(2024, time.June, 10, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	tests := []struct {
+		latitude float64
+		want     models.WateringWeatherDecision
+	}{
+		{10, models.WateringWeatherDecision{Action: models.WateringWeatherSkipped, RainfallMM: 7, MaxTemperature: 31}},
+		{20, models.WateringWeatherDecision{Action: models.WateringWeatherAdvanced, RainfallMM: 1, MaxTemperature: 32.5}},
+		{30, models.WateringWeatherDecision{Action: models.WateringWeatherKept, RainfallMM: 4.9, MaxTemperature: 25}},
+		{10, models.WateringWeatherDecision{Action: models.WateringWeatherSkipped, RainfallMM: 7, MaxTemperature: 31}},
+	}
+	for _, tt := range tests {
+		decision, err := service.WateringDecision(ctx, &models.OutdoorLocation{Latitude: tt.latitude, Longitude: 30})
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, *decision)
+	}
+	assert.Equal(t, 3, provider.lookups)
+
+	now = now.Add(weatherCacheTTL)
+	_, err := service.WateringDecision(ctx, &models.OutdoorLocation{Latitude: 10, Longitude: 30})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, provider.lookups)
+
+	_, err = service.WateringDecision(ctx, &models.OutdoorLocation{Latitude: 40, Longitude: 30})
+	assert.Error(t, err)
+}
+
+// TestNotificationService_CheckAndCreateWateringNotifications_Weather tests that rain skips and
+// postpones the reminder of an outdoor plant, heat sends the reminder of one due tomorrow and plants
+// indoors ignore the weather
+func TestNotificationService_CheckAndCreateWateringNotifications_Weather(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	mockPlantRepo := new(MockPlantRepository)
+	mockTemplateRepo := new(MockNotificationTemplateRepository)
+	provider := &stubWeatherProvider{reports: map[float64]*models.WeatherReport{
+		59.9: {RainfallMM: 8, MaxTemperature: 18},
+		45.0: {RainfallMM: 0, MaxTemperature: 34},
+	}}
+	weather := NewWeatherService(nil, 5, 30)
+	weather.SetProvider(provider)
+	service := NewNotificationService(mockNotificationRepo, mockPlantRepo, nil, NewNotificationTemplateService(mockTemplateRepo))
+	service.SetWeatherService(weather)
+	now := time.Date(2024, time.July, 10, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	userID := uuid.New()
+	overdue := now.Add(-2 * time.Hour)
+	tomorrow := now.Add(20 * time.Hour)
+	rained := &models.UserPlant{
+		UserID:          userID,
+		PlantID:         uuid.New(),
+		NextWatering:    &overdue,
+		Plant:           &models.Plant{Name: "Lavender"},
+		UserLanguage:    models.LanguageEnglish,
+		OutdoorLocation: &models.OutdoorLocation{Location: "Balcony", Latitude: 59.9, Longitude: 30.3},
+	}
+	hot := &models.UserPlant{
+		UserID:          userID,
+		PlantID:         uuid.New(),
+		NextWatering:    &tomorrow,
+		Plant:           &models.Plant{Name: "Tomato"},
+		UserLanguage:    models.LanguageEnglish,
+		OutdoorLocation: &models.OutdoorLocation{Location: "Garden", Latitude: 45.0, Longitude: 39.0},
+	}
+	indoor := &models.UserPlant{
+		UserID:       userID,
+		PlantID:      uuid.New(),
+		NextWatering: &tomorrow,
+		Plant:        &models.Plant{Name: "Monstera"},
+		UserLanguage: models.LanguageEnglish,
+	}
+
+	mockPlantRepo.On("GetAllUserPlantsForWateringCheck", ctx).Return([]*models.UserPlant{rained, hot, indoor}, nil)
+	mockPlantRepo.On("SetNextWatering", ctx, userID, rained.PlantID, mock.MatchedBy(func(next time.Time) bool {
+		return next.After(now.Add(23 * time.Hour))
+	})).Return(nil)
+	mockTemplateRepo.On("Get", ctx, mock.Anything, models.LanguageEnglish).Return(nil, nil)
+	mockNotificationRepo.On("ClaimWateringReminders", ctx, userID, truncateToDay(now)).Return(true, nil).Once()
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return *n.PlantID == rained.PlantID && n.Type == models.NotificationTypeWateringSkipped &&
+			n.Payload["weatherDecision"] == "SKIPPED" && n.Payload["rainfallMm"] == 8.0
+	})).Return(nil).Once()
+	mockNotificationRepo.On("Create", ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return *n.PlantID == hot.PlantID && n.Type == models.NotificationTypeWatering &&
+			n.Payload["weatherDecision"] == "ADVANCED" && n.Payload["maxTemperature"] == 34.0
+	})).Return(nil).Once()
+
+	stats, err := service.CheckAndCreateWateringNotifications(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.PlantsNeedingWater)
+	assert.Equal(t, 2, stats.NotificationsCreated)
+	mockPlantRepo.AssertExpectations(t)
+	mockNotificationRepo.AssertExpectations(t)
+}
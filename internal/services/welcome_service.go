@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+)
+
+// WelcomeService sets up the accounts of new users so they meet the care loop right away: it adds a
+// sample plant with an active care schedule to the collection and enrolls the user in the welcome
+// campaign. Both are optional.
+type WelcomeService struct {
+	plantService  *PlantService
+	campaigns     *CampaignService // nil when new users get no welcome campaign
+	samplePlantID *uuid.UUID       // nil when new users get no sample plant
+}
+
+// NewWelcomeService creates a new welcome service
+func NewWelcomeService(plantService *PlantService, campaigns *CampaignService, samplePlantID *uuid.UUID) *WelcomeService {
+	return &WelcomeService{
+		plantService:  plantService,
+		campaigns:     campaigns,
+		samplePlantID: samplePlantID,
+	}
+}
+
+// Welcome sets up the account of a newly registered user. The sample plant counts as watered now,
+// so its next watering and care tasks are scheduled from the day the user joined. A sample plant
+// that cannot be added, e.g. because it was removed from the catalog, is left out of the campaign.
+func (s *WelcomeService) Welcome(ctx context.Context, user *models.User) error {
+	var plantID *uuid.UUID
+	if s.samplePlantID != nil {
+		now := time.Now()
+		response, err := s.plantService.AddUserPlants(ctx, user.ID, []*models.AddUserPlantsItem{
+			{PlantID: *s.samplePlantID, LastWatered: &now},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add sample plant: %w", err)
+		}
+		if response.Added > 0 {
+			plantID = s.samplePlantID
+		} else {
+			log.Printf("Sample plant %s not added for user %s: %s", *s.samplePlantID, user.ID, response.Results[0].Error)
+		}
+	}
+
+	if s.campaigns != nil {
+		if err := s.campaigns.Enroll(ctx, user.ID, user.Language, WelcomeCampaign, plantID); err != nil {
+			return fmt.Errorf("failed to enroll user in the welcome campaign: %w", err)
+		}
+	}
+	return nil
+}
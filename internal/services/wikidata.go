@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+const (
+	// wikidataSPARQLURL is the Wikidata query service endpoint
+	wikidataSPARQLURL = "https://query.wikidata.org/sparql"
+	// wikimediaCommonsAPIURL is the Wikimedia Commons API endpoint describing the licenses of images
+	wikimediaCommonsAPIURL = "https://commons.wikimedia.org/w/api.php"
+)
+
+// wikidataTaxonQuery finds the synonyms (P1420) and the image (P18) of the taxon with a name (P225)
+const wikidataTaxonQuery = `SELECT ?synonymName ?image WHERE {
+  ?taxon wdt:P225 "%s" .
+  OPTIONAL { ?taxon wdt:P1420 ?synonym . ?synonym wdt:P225 ?synonymName . }
+  OPTIONAL { ?taxon wdt:P18 ?image . }
+}
+LIMIT 100`
+
+// htmlTagPattern matches the tags of the HTML Commons describes authors with
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// WikidataProvider looks up scientific synonyms of plants in Wikidata, and their images with the
+// licenses Wikimedia Commons publishes them under
+type WikidataProvider struct {
+	userAgent       string
+	sparqlEndpoint  string
+	commonsEndpoint string
+	client          *http.Client
+}
+
+// NewWikidataProvider creates a new Wikidata enrichment provider
+func NewWikidataProvider(userAgent string) *WikidataProvider {
+	return &WikidataProvider{
+		userAgent:       userAgent,
+		sparqlEndpoint:  wikidataSPARQLURL,
+		commonsEndpoint: wikimediaCommonsAPIURL,
+		client: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+// wikidataSPARQLResponse represents the results of a Wikidata taxon query
+type wikidataSPARQLResponse struct {
+	Results struct {
+		Bindings []struct {
+			SynonymName struct {
+				Value string `json:"value"`
+			} `json:"synonymName"`
+			Image struct {
+				Value string `json:"value"` // Special:FilePath URL of the image on Wikimedia Commons
+			} `json:"image"`
+		} `json:"bindings"`
+	} `json:"results"`
+}
+
+// wikimediaImageInfoResponse represents the URL and metadata of a Wikimedia Commons file
+type wikimediaImageInfoResponse struct {
+	Query struct {
+		Pages map[string]struct {
+			ImageInfo []struct {
+				URL         string `json:"url"`
+				ExtMetadata struct {
+					LicenseShortName struct {
+						Value string `json:"value"`
+					} `json:"LicenseShortName"`
+					Artist struct {
+						Value string `json:"value"`
+					} `json:"Artist"`
+				} `json:"extmetadata"`
+			} `json:"imageinfo"`
+		} `json:"pages"`
+	} `json:"query"`
+}
+
+// Name returns the source name stored with proposals
+func (p *WikidataProvider) Name() string {
+	return "wikidata"
+}
+
+// Enrich returns the synonyms and the image of the taxon with the plant's scientific name
+func (p *WikidataProvider) Enrich(ctx context.Context, plant *models.Plant) (*models.PlantEnrichment, error) {
+	name := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(plant.ScientificName)
+	query := url.Values{}
+	query.Set("query", fmt.Sprintf(wikidataTaxonQuery, name))
+	query.Set("format", "json")
+
+	var response wikidataSPARQLResponse
+	if err := getEnrichmentJSON(ctx, p.client, p.sparqlEndpoint+"?"+query.Encode(), p.userAgent, &response); err != nil {
+		return nil, fmt.Errorf("failed to query taxon: %w", err)
+	}
+
+	enrichment := &models.PlantEnrichment{}
+	var imageURL string
+	for _, binding := range response.Results.Bindings {
+		if binding.SynonymName.Value != "" {
+			enrichment.Synonyms = append(enrichment.Synonyms, binding.SynonymName.Value)
+		}
+		if imageURL == "" {
+			imageURL = binding.Image.Value
+		}
+	}
+
+	if plant.ImageURL == "" && imageURL != "" {
+		image, err := p.commonsImage(ctx, imageURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get image license: %w", err)
+		}
+		enrichment.Image = image
+	}
+	return enrichment, nil
+}
+
+// commonsImage gets the file URL, license and author of a Wikimedia Commons image referenced by its
+// Special:FilePath URL
+func (p *WikidataProvider) commonsImage(ctx context.Context, filePathURL string) (*models.PlantImage, error) {
+	file, err := url.PathUnescape(path.Base(filePathURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image URL %q: %w", filePathURL, err)
+	}
+	query := url.Values{}
+	query.Set("action", "query")
+	query.Set("format", "json")
+	query.Set("prop", "imageinfo")
+	query.Set("iiprop", "url|extmetadata")
+	query.Set("titles", "File:"+file)
+
+	var response wikimediaImageInfoResponse
+	if err := getEnrichmentJSON(ctx, p.client, p.commonsEndpoint+"?"+query.Encode(), p.userAgent, &response); err != nil {
+		return nil, err
+	}
+	for _, page := range response.Query.Pages {
+		for _, info := range page.ImageInfo {
+			if info.URL == "" {
+				continue
+			}
+			return &models.PlantImage{
+				URL:         info.URL,
+				License:     info.ExtMetadata.LicenseShortName.Value,
+				Attribution: strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(info.ExtMetadata.Artist.Value, ""))),
+			}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWikidataProvider_Enrich tests looking up the synonyms of a taxon and the license and author of its image
+func TestWikidataProvider_Enrich(t *testing.T) {
+	var query string
+	sparql := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		w.Write([]byte(`{"results":{"bindings":[
+			{"synonymName":{"type":"literal","value":"Philodendron pertusum"},"image":{"type":"uri","value":"http://commons.wikimedia.org/wiki/Special:FilePath/Monstera%20deliciosa%201.jpg"}},
+			{"synonymName":{"type":"literal","value":"Monstera tacanaensis"},"image":{"type":"uri","value":"http://commons.wikimedia.org/wiki/Special:FilePath/Monstera%20deliciosa%201.jpg"}}
+		]}}`))
+	}))
+	defer sparql.Close()
+	commons := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "File:Monstera deliciosa 1.jpg", r.URL.Query().Get("titles"))
+		w.Write([]byte(`{"query":{"pages":{"123":{"imageinfo":[{"url":"https://upload.wikimedia.org/m.jpg","extmetadata":{"LicenseShortName":{"value":"CC BY-SA 4.0"},"Artist":{"value":"<a href=\"//commons.wikimedia.org/wiki/User:Anna\">Anna &amp; Co</a>"}}}]}}}}`))
+	}))
+	defer commons.Close()
+
+	provider := NewWikidataProvider("planter-test")
+	provider.sparqlEndpoint = sparql.URL
+	provider.commonsEndpoint = commons.URL
+
+	enrichment, err := provider.Enrich(context.Background(), &models.Plant{ScientificName: "Monstera deliciosa"})
+	assert.NoError(t, err)
+	assert.Contains(t, query, `wdt:P225 "Monstera deliciosa" .`)
+	assert.Equal(t, []string{"Philodendron pertusum", "Monstera tacanaensis"}, enrichment.Synonyms)
+	assert.Equal(t, &models.PlantImage{URL: "https://upload.wikimedia.org/m.jpg", License: "CC BY-SA 4.0", Attribution: "Anna & Co"}, enrichment.Image)
+
+	// Quotes in names cannot break out of the query
+	_, err = provider.Enrich(context.Background(), &models.Plant{ScientificName: `x" } #`, ImageURL: "plants/x.jpg"})
+	assert.NoError(t, err)
+	assert.Contains(t, query, `wdt:P225 "x\" } #" .`)
+}
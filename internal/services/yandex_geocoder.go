@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// yandexGeocoderURL is the Yandex Geocoder HTTP API endpoint
+const yandexGeocoderURL = "https://geocode-maps.yandex.ru/1.x/"
+
+// YandexGeocoder resolves addresses to coordinates with the Yandex Geocoder API
+type YandexGeocoder struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+// NewYandexGeocoder creates a new Yandex geocoder
+func NewYandexGeocoder(apiKey string) *YandexGeocoder {
+	return &YandexGeocoder{
+		apiKey:   apiKey,
+		endpoint: yandexGeocoderURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// yandexGeocoderResponse represents a response from the Yandex Geocoder API
+type yandexGeocoderResponse struct {
+	Response struct {
+		GeoObjectCollection struct {
+			FeatureMember []struct {
+				GeoObject struct {
+					MetaDataProperty struct {
+						GeocoderMetaData struct {
+							Address struct {
+								// Components lists the parts of the address from the country down
+								Components []struct {
+									Kind string `json:"kind"`
+									Name string `json:"name"`
+								} `json:"Components"`
+							} `json:"Address"`
+						} `json:"GeocoderMetaData"`
+					} `json:"metaDataProperty"`
+					Point struct {
+						// Pos holds the longitude and latitude separated by a space
+						Pos string `json:"pos"`
+					} `json:"Point"`
+				} `json:"GeoObject"`
+			} `json:"featureMember"`
+		} `json:"GeoObjectCollection"`
+	} `json:"response"`
+}
+
+// Geocode returns the coordinates and the locality of the best match for an address
+func (g *YandexGeocoder) Geocode(ctx context.Context, address string) (*models.GeoPoint, error) {
+	query := url.Values{}
+	query.Set("apikey", g.apiKey)
+	query.Set("geocode", address)
+	query.Set("format", "json")
+	query.Set("results", "1")
+
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Send the request
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check the response status
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("API returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// Parse the response
+	var response yandexGeocoderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	members := response.Response.GeoObjectCollection.FeatureMember
+	if len(members) == 0 {
+		return nil, ErrAddressNotFound
+	}
+
+	lon, lat, ok := strings.Cut(members[0].GeoObject.Point.Pos, " ")
+	if !ok {
+		return nil, fmt.Errorf("invalid position %q", members[0].GeoObject.Point.Pos)
+	}
+	longitude, err := strconv.ParseFloat(lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid longitude: %w", err)
+	}
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latitude: %w", err)
+	}
+
+	point := &models.GeoPoint{Latitude: latitude, Longitude: longitude}
+	for _, component := range members[0].GeoObject.MetaDataProperty.GeocoderMetaData.Address.Components {
+		if component.Kind == "locality" {
+			point.City = component.Name
+			break
+		}
+	}
+	return point, nil
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestYandexGeocoder_Geocode tests parsing the position and locality of the best match and addresses with no match
+func TestYandexGeocoder_Geocode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.URL.Query().Get("apikey"))
+		if r.URL.Query().Get("geocode") == "Nowhere 1" {
+			w.Write([]byte(`{"response":{"GeoObjectCollection":{"featureMember":[]}}}`))
+			return
+		}
+		w.Write([]byte(`{"response":{"GeoObjectCollection":{"featureMember":[{"GeoObject":{"metaDataProperty":{"GeocoderMetaData":{"Address":{"Components":[{"kind":"country","name":"Россия"},{"kind":"locality","name":"Санкт-Петербург"},{"kind":"street","name":"Невский проспект"}]}}},"Point":{"pos":"30.326 59.9357"}}}]}}}`))
+	}))
+	defer server.Close()
+
+	geocoder := NewYandexGeocoder("test-key")
+	geocoder.endpoint = server.URL
+
+	point, err := geocoder.Geocode(context.Background(), "Невский проспект, 28")
+	assert.NoError(t, err)
+	assert.Equal(t, 59.9357, point.Latitude)
+	assert.Equal(t, 30.326, point.Longitude)
+	assert.Equal(t, "Санкт-Петербург", point.City)
+
+	_, err = geocoder.Geocode(context.Background(), "Nowhere 1")
+	assert.ErrorIs(t, err, ErrAddressNotFound)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// yandexGPTCompletionURL is the Yandex GPT completion endpoint
+const yandexGPTCompletionURL = "https://llm.api.cloud.yandex.net/foundationModels/v1/completion"
+
+// ErrYandexGPTNotConfigured is returned by optional Yandex GPT features when no API key is set
+var ErrYandexGPTNotConfigured = errors.New("Yandex GPT is not configured")
+
+// YandexGPTAPIError is returned when the Yandex GPT API responds with a non-OK status
+type YandexGPTAPIError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error implements the error interface
+func (e *YandexGPTAPIError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("API returned status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("API returned status code %d: %s", e.StatusCode, e.Body)
+}
+
+// ValidateYandexGPTConfig checks the Yandex GPT settings without calling the API.
+// It returns nil when the configuration looks usable.
+func (s *RecommendationService) ValidateYandexGPTConfig() *models.LLMStatus {
+	status := &models.LLMStatus{Model: s.yandexGPTModel}
+
+	if s.yandexGPTAPIKey == "" {
+		status.Status = models.LLMStatusDisabled
+		status.Hint = "YANDEX_GPT_API_KEY is not set; recommendations use the local matcher and chat is unavailable"
+		return status
+	}
+
+	if strings.TrimSpace(s.yandexGPTAPIKey) != s.yandexGPTAPIKey || strings.ContainsAny(s.yandexGPTAPIKey, " \t\r\n") {
+		status.Status = models.LLMStatusMisconfigured
+		status.Error = "API key contains whitespace"
+		status.Hint = "check YANDEX_GPT_API_KEY for a trailing newline or stray spaces"
+		return status
+	}
+
+	// Model URIs look like gpt://<folder-id>/<model>[/<version>] or ds://<deployment-id>
+	scheme, rest, found := strings.Cut(s.yandexGPTModel, "://")
+	parts := strings.Split(rest, "/")
+	valid := found && ((scheme == "gpt" && len(parts) >= 2 && len(parts) <= 3 && parts[0] != "" && parts[1] != "") ||
+		(scheme == "ds" && rest != ""))
+	if !valid {
+		status.Status = models.LLMStatusMisconfigured
+		status.Error = fmt.Sprintf("malformed model URI %q", s.yandexGPTModel)
+		status.Hint = "YANDEX_GPT_MODEL must look like gpt://<folder-id>/yandexgpt-lite/latest"
+		return status
+	}
+
+	return nil
+}
+
+// SelfTestYandexGPT validates the configuration and performs a tiny completion.
+// The result is stored and returned by GetYandexGPTStatus.
+func (s *RecommendationService) SelfTestYandexGPT(ctx context.Context) *models.LLMStatus {
+	status := s.ValidateYandexGPTConfig()
+	if status == nil {
+		status = &models.LLMStatus{Model: s.yandexGPTModel}
+
+		start := time.Now()
+		_, err := s.callYandexGPTCompletion(ctx, []Message{{Role: "user", Text: "ping"}}, CompletionOptions{
+			Temperature: 0,
+			MaxTokens:   1,
+		})
+		status.LatencyMs = time.Since(start).Milliseconds()
+
+		if err != nil {
+			status.Status, status.Hint = diagnoseYandexGPTError(err)
+			status.Error = err.Error()
+		} else {
+			status.Status = models.LLMStatusOK
+		}
+	}
+
+	checkedAt := time.Now()
+	status.CheckedAt = &checkedAt
+
+	s.llmStatusMu.Lock()
+	s.llmStatus = status
+	s.llmStatusMu.Unlock()
+
+	copied := *status
+	return &copied
+}
+
+// GetYandexGPTStatus returns the result of the last self-test, or the static
+// configuration check if no self-test has completed yet
+func (s *RecommendationService) GetYandexGPTStatus() *models.LLMStatus {
+	s.llmStatusMu.RLock()
+	defer s.llmStatusMu.RUnlock()
+
+	if s.llmStatus != nil {
+		copied := *s.llmStatus
+		return &copied
+	}
+
+	if status := s.ValidateYandexGPTConfig(); status != nil {
+		return status
+	}
+	return &models.LLMStatus{Status: models.LLMStatusUnknown, Model: s.yandexGPTModel}
+}
+
+// diagnoseYandexGPTError maps a completion error to a status and an actionable hint
+func diagnoseYandexGPTError(err error) (models.LLMStatusCode, string) {
+	if errors.Is(err, ErrYandexGPTCircuitOpen) {
+		return models.LLMStatusUnreachable, "calls are suspended after repeated failures and resume after LLM_BREAKER_COOLDOWN; see GET /admin/llm/usage"
+	}
+
+	var apiErr *YandexGPTAPIError
+	if !errors.As(err, &apiErr) {
+		return models.LLMStatusUnreachable, "could not reach llm.api.cloud.yandex.net; check outbound network access and DNS"
+	}
+
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized:
+		return models.LLMStatusMisconfigured, "the API key was rejected; check YANDEX_GPT_API_KEY"
+	case apiErr.StatusCode == http.StatusForbidden:
+		return models.LLMStatusMisconfigured, "the service account has no access to the folder in YANDEX_GPT_MODEL; grant it the ai.languageModels.user role"
+	case apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusNotFound:
+		return models.LLMStatusMisconfigured, "the model URI was rejected; check the folder ID and model name in YANDEX_GPT_MODEL"
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		return models.LLMStatusUnreachable, "the Yandex GPT quota is exhausted; retry later or raise the quota"
+	default:
+		return models.LLMStatusUnreachable, "Yandex GPT returned an unexpected error; retry later"
+	}
+}
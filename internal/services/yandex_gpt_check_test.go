@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecommendationService_ValidateYandexGPTConfig tests the static configuration check
+func TestRecommendationService_ValidateYandexGPTConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiKey   string
+		model    string
+		expected models.LLMStatusCode
+	}{
+		{"disabled", "", "gpt://b1g/yandexgpt-lite", models.LLMStatusDisabled},
+		{"whitespace key", "key\n", "gpt://b1g/yandexgpt-lite", models.LLMStatusMisconfigured},
+		{"bare model name", "key", "yandexgpt", models.LLMStatusMisconfigured},
+		{"missing folder", "key", "gpt:///yandexgpt-lite", models.LLMStatusMisconfigured},
+		{"valid", "key", "gpt://b1g/yandexgpt-lite/latest", ""},
+		{"dedicated", "key", "ds://bt1abc", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRecommendationService(nil, nil, tt.apiKey, tt.model)
+			status := service.ValidateYandexGPTConfig()
+			if tt.expected == "" {
+				assert.Nil(t, status)
+				return
+			}
+			assert.Equal(t, tt.expected, status.Status)
+			assert.NotEmpty(t, status.Hint)
+		})
+	}
+}
+
+// TestRecommendationService_SelfTestYandexGPT tests the self-test against a fake API
+func TestRecommendationService_SelfTestYandexGPT(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		expected   models.LLMStatusCode
+	}{
+		{"ok", http.StatusOK, models.LLMStatusOK},
+		{"unauthorized", http.StatusUnauthorized, models.LLMStatusMisconfigured},
+		{"unavailable", http.StatusServiceUnavailable, models.LLMStatusUnreachable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Api-Key test-key", r.Header.Get("Authorization"))
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(`{"result":{"alternatives":[{"message":{"role":"assistant","text":"pong"}}]}}`))
+			}))
+			defer server.Close()
+
+			service := NewRecommendationService(nil, nil, "test-key", "gpt://b1g/yandexgpt-lite")
+			service.yandexGPTEndpoint = server.URL
+
+			// Before the self-test only the static check is known
+			assert.Equal(t, models.LLMStatusUnknown, service.GetYandexGPTStatus().Status)
+
+			status := service.SelfTestYandexGPT(context.Background())
+			assert.Equal(t, tt.expected, status.Status)
+			assert.NotNil(t, status.CheckedAt)
+			assert.Equal(t, tt.expected, service.GetYandexGPTStatus().Status)
+			if tt.expected != models.LLMStatusOK {
+				assert.NotEmpty(t, status.Error)
+				assert.NotEmpty(t, status.Hint)
+			}
+		})
+	}
+}
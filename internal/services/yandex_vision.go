@@ -0,0 +1,162 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+)
+
+// yandexVisionAnalyzeURL is the Yandex Vision batch analyze endpoint
+const yandexVisionAnalyzeURL = "https://vision.api.cloud.yandex.net/vision/v1/batchAnalyze"
+
+// YandexVisionProvider diagnoses plant photos with a Yandex Vision image classification model
+// trained on plant conditions
+type YandexVisionProvider struct {
+	apiKey   string
+	folderID string
+	model    string
+	endpoint string
+	client   *http.Client
+}
+
+// NewYandexVisionProvider creates a new Yandex Vision diagnosis provider
+func NewYandexVisionProvider(apiKey string, folderID string, model string) *YandexVisionProvider {
+	return &YandexVisionProvider{
+		apiKey:   apiKey,
+		folderID: folderID,
+		model:    model,
+		endpoint: yandexVisionAnalyzeURL,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// yandexVisionRequest represents a batch analyze request to the Yandex Vision API
+type yandexVisionRequest struct {
+	FolderID     string                    `json:"folderId"`
+	AnalyzeSpecs []yandexVisionAnalyzeSpec `json:"analyze_specs"`
+}
+
+// yandexVisionAnalyzeSpec represents an image and the features to analyze on it
+type yandexVisionAnalyzeSpec struct {
+	Content  string                `json:"content"`
+	MimeType string                `json:"mimeType"`
+	Features []yandexVisionFeature `json:"features"`
+}
+
+// yandexVisionFeature represents a feature to analyze on an image
+type yandexVisionFeature struct {
+	Type                 string `json:"type"`
+	ClassificationConfig struct {
+		Model string `json:"model"`
+	} `json:"classificationConfig"`
+}
+
+// yandexVisionResponse represents a batch analyze response from the Yandex Vision API
+type yandexVisionResponse struct {
+	Results []struct {
+		Results []struct {
+			Classification struct {
+				Properties []yandexVisionProperty `json:"properties"`
+			} `json:"classification"`
+		} `json:"results"`
+	} `json:"results"`
+}
+
+// Name returns the provider name stored with diagnoses
+func (p *YandexVisionProvider) Name() string {
+	return "yandex-vision"
+}
+
+// Diagnose classifies a plant photo and returns the detected conditions
+func (p *YandexVisionProvider) Diagnose(ctx context.Context, image []byte, contentType string) ([]*models.DiagnosisFinding, error) {
+	classifications, err := p.classify(ctx, image, contentType, p.model)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []*models.DiagnosisFinding
+	for _, properties := range classifications {
+		for _, property := range properties {
+			findings = append(findings, &models.DiagnosisFinding{
+				Condition:  property.Name,
+				Confidence: property.Probability,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// yandexVisionProperty is a class of an image classification with its probability
+type yandexVisionProperty struct {
+	Name        string  `json:"name"`
+	Probability float64 `json:"probability"`
+}
+
+// classify classifies an image with each of the classification models and returns the classes
+// found by each, in the order of the models
+func (p *YandexVisionProvider) classify(ctx context.Context, image []byte, contentType string, models ...string) ([][]yandexVisionProperty, error) {
+	features := make([]yandexVisionFeature, len(models))
+	for i, model := range models {
+		features[i].Type = "CLASSIFICATION"
+		features[i].ClassificationConfig.Model = model
+	}
+
+	requestJSON, err := json.Marshal(yandexVisionRequest{
+		FolderID: p.folderID,
+		AnalyzeSpecs: []yandexVisionAnalyzeSpec{
+			{
+				Content:  base64.StdEncoding.EncodeToString(image),
+				MimeType: contentType,
+				Features: features,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewBuffer(requestJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Api-Key "+p.apiKey)
+
+	// Send the request
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check the response status
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("API returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// Parse the response
+	var response yandexVisionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var classifications [][]yandexVisionProperty
+	for _, imageResult := range response.Results {
+		for _, featureResult := range imageResult.Results {
+			classifications = append(classifications, featureResult.Classification.Properties)
+		}
+	}
+	return classifications, nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const (
+	// ImmutableCacheControl is the Cache-Control of content addressed assets: their key changes with
+	// their content, so browsers and the CDN keep them for a year without revalidating
+	ImmutableCacheControl = "public, max-age=31536000, immutable"
+
+	// MutableCacheControl is the Cache-Control of other assets, which may be replaced under their key
+	MutableCacheControl = "public, max-age=300"
+
+	// contentHashLength is the number of hex digits of the content hash naming a content addressed asset
+	contentHashLength = sha256.Size * 2
+)
+
+// ContentKey returns the key of an asset named by the hash of its content under a directory, e.g.
+// plants/<plantId>/<sha256>.jpg. Changed content gets a new key, so a cached copy never goes stale
+// and nothing has to be purged from the CDN.
+func ContentKey(dir string, data []byte, extension string) string {
+	sum := sha256.Sum256(data)
+	return strings.TrimSuffix(dir, "/") + "/" + hex.EncodeToString(sum[:]) + extension
+}
+
+// IsContentKey reports whether a key names its asset by the hash of its content
+func IsContentKey(key string) bool {
+	name := path.Base(key)
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name = name[:i]
+	}
+	if len(name) != contentHashLength {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil && strings.ToLower(name) == name
+}
+
+// FileServer serves the objects of a DirectoryStore, so the CDN can pull uploaded assets from the
+// API. Content addressed assets are served as immutable, others with a short max age.
+type FileServer struct {
+	root http.Dir
+}
+
+// NewFileServer creates a new server of the objects stored under root
+func NewFileServer(root string) *FileServer {
+	return &FileServer{root: http.Dir(root)}
+}
+
+// ServeHTTP serves the object stored under the key the request path names. Directories are not
+// listed, and missing objects are not cached, so an object stored later is found.
+func (s *FileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if key == "" || strings.HasPrefix(path.Base(key), ".") {
+		http.NotFound(w, r)
+		return
+	}
+
+	file, err := s.root.Open("/" + key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	if IsContentKey(key) {
+		w.Header().Set("Cache-Control", ImmutableCacheControl)
+	} else {
+		w.Header().Set("Cache-Control", MutableCacheControl)
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentKey(t *testing.T) {
+	key := ContentKey("plants/monstera/", []byte("photo"), ".jpg")
+	assert.True(t, strings.HasPrefix(key, "plants/monstera/"))
+	assert.True(t, strings.HasSuffix(key, ".jpg"))
+	assert.True(t, IsContentKey(key))
+
+	// The same content gets the same key, changed content a new one
+	assert.Equal(t, key, ContentKey("plants/monstera", []byte("photo"), ".jpg"))
+	assert.NotEqual(t, key, ContentKey("plants/monstera", []byte("new photo"), ".jpg"))
+
+	assert.False(t, IsContentKey("plants/monstera.jpg"))
+	assert.False(t, IsContentKey("user-plants/1b4e28ba-2fa1-11d2-883f-0016d3cca427.png"))
+}
+
+func TestFileServer(t *testing.T) {
+	root := t.TempDir()
+	store := NewDirectoryStore(root)
+	ctx := context.Background()
+	contentKey := ContentKey("plants/monstera", []byte("photo"), ".jpg")
+	assert.NoError(t, store.Put(ctx, contentKey, []byte("photo"), "image/jpeg"))
+	assert.NoError(t, store.Put(ctx, "plants/monstera.jpg", []byte("photo"), "image/jpeg"))
+	server := NewFileServer(root)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	// Content addressed assets never change under their key
+	rec := serve(http.MethodGet, "/"+contentKey)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "photo", rec.Body.String())
+	assert.Equal(t, ImmutableCacheControl, rec.Header().Get("Cache-Control"))
+
+	rec = serve(http.MethodGet, "/plants/monstera.jpg")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, MutableCacheControl, rec.Header().Get("Cache-Control"))
+
+	// Missing objects and directories are not found, and not cached
+	rec = serve(http.MethodGet, "/"+ContentKey("plants/monstera", []byte("other"), ".jpg"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("Cache-Control"))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/plants/").Code)
+	assert.NoError(t, store.Put(ctx, "plants/.upload-1", []byte("photo"), "image/jpeg"))
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/plants/.upload-1").Code)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPut, "/plants/monstera.jpg").Code)
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidKey is returned when a key is empty, absolute or leaves the store
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// ObjectStore stores uploaded assets under their key
+type ObjectStore interface {
+	// Put stores data under a key, replacing an object already stored there
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+
+	// Delete removes the object stored under a key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// DirectoryStore stores objects as files under a directory, e.g. a bucket mounted on the host or
+// the document root the CDN pulls from
+type DirectoryStore struct {
+	root string
+}
+
+// NewDirectoryStore creates a new store writing objects under root
+func NewDirectoryStore(root string) *DirectoryStore {
+	return &DirectoryStore{root: root}
+}
+
+// Put stores data under a key. The file is written next to its final name and renamed, so
+// the CDN never serves a partially written object.
+func (s *DirectoryStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the file stored under a key
+func (s *DirectoryStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// path returns the name of the file a key is stored in
+func (s *DirectoryStore) path(key string) (string, error) {
+	if key == "" || isAbsolute(key) || strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
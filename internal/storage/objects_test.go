@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectoryStore(t *testing.T) {
+	root := t.TempDir()
+	s := NewDirectoryStore(root)
+	ctx := context.Background()
+
+	assert.NoError(t, s.Put(ctx, "user-plants/monstera.jpg", []byte("photo"), "image/jpeg"))
+	data, err := os.ReadFile(filepath.Join(root, "user-plants", "monstera.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, "photo", string(data))
+
+	assert.NoError(t, s.Delete(ctx, "user-plants/monstera.jpg"))
+	assert.NoFileExists(t, filepath.Join(root, "user-plants", "monstera.jpg"))
+	assert.NoError(t, s.Delete(ctx, "user-plants/monstera.jpg"))
+
+	// Keys cannot leave the directory
+	assert.ErrorIs(t, s.Put(ctx, "../monstera.jpg", []byte("photo"), "image/jpeg"), ErrInvalidKey)
+	assert.ErrorIs(t, s.Put(ctx, "/etc/monstera.jpg", []byte("photo"), "image/jpeg"), ErrInvalidKey)
+	assert.ErrorIs(t, s.Delete(ctx, "https://cdn.example.com/monstera.jpg"), ErrInvalidKey)
+}
@@ -0,0 +1,97 @@
+// Package storage resolves the keys of stored images and other assets to URLs.
+//
+// Assets are referenced by their key in the bucket, e.g. plants/monstera.jpg, and turned into
+// URLs under the configured CDN base URL only when a response is written, so moving the bucket
+// or switching the CDN is a configuration change. Absolute URLs of assets hosted elsewhere are
+// kept as they are.
+//
+// Uploaded assets are written to an ObjectStore under their key. Assets that are replaced, such as
+// the images of catalog plants, are stored under content addressed keys, so their URLs can be
+// cached forever and a change is a new URL rather than a purge.
+package storage
+
+import (
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// Storage resolves asset keys to URLs under a base URL
+type Storage struct {
+	baseURL        string   // ends with a slash; empty leaves keys unresolved
+	legacyBaseURLs []string // base URLs assets were previously served from, each ending with a slash
+}
+
+// New creates a new storage serving assets from baseURL. URLs under baseURL or one of the
+// legacyBaseURLs are recognized as stored assets and turned back into keys.
+func New(baseURL string, legacyBaseURLs []string) *Storage {
+	s := &Storage{baseURL: normalizeBaseURL(baseURL)}
+	for _, legacy := range legacyBaseURLs {
+		if legacy = normalizeBaseURL(legacy); legacy != "" && legacy != s.baseURL {
+			s.legacyBaseURLs = append(s.legacyBaseURLs, legacy)
+		}
+	}
+	return s
+}
+
+// BaseURLs returns the base URLs stored assets are recognized by, the current one first
+func (s *Storage) BaseURLs() []string {
+	var baseURLs []string
+	if s.baseURL != "" {
+		baseURLs = append(baseURLs, s.baseURL)
+	}
+	return append(baseURLs, s.legacyBaseURLs...)
+}
+
+// URL returns the URL of an asset. Empty values and absolute URLs of assets hosted elsewhere
+// are returned unchanged, as are keys when no base URL is configured.
+func (s *Storage) URL(key string) string {
+	if key == "" || s.baseURL == "" || isAbsolute(key) {
+		return key
+	}
+	return s.baseURL + strings.TrimPrefix(key, "/")
+}
+
+// Key returns the key of an asset referenced by its URL. URLs that are not under one of the
+// base URLs are hosted elsewhere and returned unchanged.
+func (s *Storage) Key(rawURL string) string {
+	for _, baseURL := range s.BaseURLs() {
+		if strings.HasPrefix(rawURL, baseURL) {
+			return strings.TrimPrefix(rawURL, baseURL)
+		}
+	}
+	return rawURL
+}
+
+// defaultStorage is the storage assets are resolved with when responses are written
+var defaultStorage atomic.Pointer[Storage]
+
+func init() {
+	defaultStorage.Store(New("", nil))
+}
+
+// SetDefault sets the storage assets are resolved with when responses are written
+func SetDefault(s *Storage) {
+	defaultStorage.Store(s)
+}
+
+// Default returns the storage assets are resolved with when responses are written; until
+// SetDefault is called keys are left unresolved
+func Default() *Storage {
+	return defaultStorage.Load()
+}
+
+// normalizeBaseURL trims a base URL and makes it end with a slash
+func normalizeBaseURL(baseURL string) string {
+	baseURL = strings.TrimSpace(baseURL)
+	if baseURL == "" || strings.HasSuffix(baseURL, "/") {
+		return baseURL
+	}
+	return baseURL + "/"
+}
+
+// isAbsolute reports whether a value is an absolute URL rather than a key
+func isAbsolute(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.IsAbs()
+}
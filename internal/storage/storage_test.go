@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorage_URL(t *testing.T) {
+	s := New("https://cdn.example.com/planter", nil)
+
+	assert.Equal(t, "https://cdn.example.com/planter/plants/monstera.jpg", s.URL("plants/monstera.jpg"))
+	assert.Equal(t, "https://cdn.example.com/planter/plants/monstera.jpg", s.URL("/plants/monstera.jpg"))
+	assert.Equal(t, "https://i.pinimg.com/monstera.jpg", s.URL("https://i.pinimg.com/monstera.jpg"))
+	assert.Equal(t, "", s.URL(""))
+
+	// Keys stay keys until a base URL is configured
+	assert.Equal(t, "plants/monstera.jpg", New("", nil).URL("plants/monstera.jpg"))
+}
+
+func TestStorage_Key(t *testing.T) {
+	s := New("https://cdn.example.com/planter/", []string{"https://old-bucket.example.com"})
+
+	assert.Equal(t, "plants/monstera.jpg", s.Key("https://cdn.example.com/planter/plants/monstera.jpg"))
+	assert.Equal(t, "plants/monstera.jpg", s.Key("https://old-bucket.example.com/plants/monstera.jpg"))
+	assert.Equal(t, "https://i.pinimg.com/monstera.jpg", s.Key("https://i.pinimg.com/monstera.jpg"))
+	assert.Equal(t, "plants/monstera.jpg", s.Key("plants/monstera.jpg"))
+	assert.Equal(t, []string{"https://cdn.example.com/planter/", "https://old-bucket.example.com/"}, s.BaseURLs())
+}
@@ -1,22 +1,50 @@
 package utils
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"strings"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/anpanovv/planter/internal/db/pgerror"
+	"github.com/anpanovv/planter/internal/models"
 )
 
-// Validate is a validator instance
-var Validate = validator.New()
+// retryAfterSeconds is the Retry-After of responses to transactions that failed because of concurrent ones
+const retryAfterSeconds = "1"
 
 // RespondWithError responds with an error
 func RespondWithError(w http.ResponseWriter, code int, message string) {
 	RespondWithJSON(w, code, map[string]string{"error": message})
 }
 
+// RespondWithDatabaseError responds to an error of a repository by the kind pgerror maps it to:
+// conflicts with 409, references to missing rows with 404, invalid values with 400 and transactions
+// failed because of concurrent ones with 503 and a Retry-After. It reports whether it responded;
+// other errors are left to the caller.
+func RespondWithDatabaseError(w http.ResponseWriter, err error) bool {
+	err = pgerror.Map(err)
+	switch {
+	case errors.Is(err, pgerror.ErrConflict):
+		RespondWithError(w, http.StatusConflict, "Conflicts with existing data")
+	case errors.Is(err, pgerror.ErrStillReferenced):
+		RespondWithError(w, http.StatusConflict, "Still in use")
+	case errors.Is(err, pgerror.ErrReferenceNotFound):
+		RespondWithError(w, http.StatusNotFound, "Referenced resource not found")
+	case errors.Is(err, pgerror.ErrInvalidValue):
+		RespondWithError(w, http.StatusBadRequest, "Invalid value")
+	case errors.Is(err, pgerror.ErrRetryable):
+		w.Header().Set("Retry-After", retryAfterSeconds)
+		RespondWithError(w, http.StatusServiceUnavailable, "Temporarily unavailable, retry")
+	default:
+		return false
+	}
+	return true
+}
+
 // RespondWithJSON responds with JSON
 func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, err := json.Marshal(payload)
@@ -31,28 +59,72 @@ func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(response)
 }
 
-// ValidationErrorMessage returns a formatted validation error message
-func ValidationErrorMessage(err error) string {
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		var messages []string
-		for _, e := range validationErrors {
-			field := e.Field()
-			switch e.Tag() {
-			case "required":
-				messages = append(messages, fmt.Sprintf("%s is required", field))
-			case "email":
-				messages = append(messages, fmt.Sprintf("%s must be a valid email", field))
-			case "min":
-				messages = append(messages, fmt.Sprintf("%s must be at least %s characters", field, e.Param()))
-			case "max":
-				messages = append(messages, fmt.Sprintf("%s must be at most %s characters", field, e.Param()))
-			case "oneof":
-				messages = append(messages, fmt.Sprintf("%s must be one of: %s", field, e.Param()))
-			default:
-				messages = append(messages, fmt.Sprintf("%s is invalid", field))
-			}
+// RespondWithJSONETag responds with JSON tagged with the hash of its content. Clients revalidate the
+// response on every use, and a request whose If-None-Match names the tag is answered with 304 Not
+// Modified and no body.
+func RespondWithJSONETag(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		RespondWithJSON(w, code, payload)
+		return
+	}
+
+	sum := sha256.Sum256(response)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+// etagMatches reports whether an If-None-Match header names an entity tag, comparing weakly
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
 		}
-		return strings.Join(messages, ", ")
 	}
-	return err.Error()
-}
\ No newline at end of file
+	return false
+}
+
+// RespondWithWarnings responds with a JSON object carrying non-fatal warnings in a "warnings" array
+// next to its own fields. The array is omitted when there are no warnings, and payloads that are
+// not JSON objects are sent without them.
+func RespondWithWarnings(w http.ResponseWriter, code int, payload interface{}, warnings []models.Warning) {
+	if len(warnings) == 0 {
+		RespondWithJSON(w, code, payload)
+		return
+	}
+
+	response, err := json.Marshal(payload)
+	if err != nil {
+		RespondWithJSON(w, code, payload)
+		return
+	}
+	warningsJSON, err := json.Marshal(warnings)
+	if err != nil || len(response) < 2 || response[0] != '{' {
+		RespondWithJSON(w, code, payload)
+		return
+	}
+
+	// Insert the warnings before the closing brace of the object
+	var buf bytes.Buffer
+	buf.Write(response[:len(response)-1])
+	if len(bytes.TrimSpace(response[1:len(response)-1])) > 0 {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"warnings":`)
+	buf.Write(warningsJSON)
+	buf.WriteByte('}')
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(buf.Bytes())
+}
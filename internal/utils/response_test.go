@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRespondWithWarnings(t *testing.T) {
+	warnings := []models.Warning{{Code: models.WarningCodeLightMismatch, Message: "needs more light"}}
+
+	tests := []struct {
+		name     string
+		payload  interface{}
+		warnings []models.Warning
+		expected string
+	}{
+		{"object", map[string]string{"message": "ok"}, warnings, `{"message":"ok","warnings":[{"code":"LIGHT_MISMATCH","message":"needs more light"}]}`},
+		{"empty object", struct{}{}, warnings, `{"warnings":[{"code":"LIGHT_MISMATCH","message":"needs more light"}]}`},
+		{"no warnings", map[string]string{"message": "ok"}, nil, `{"message":"ok"}`},
+		{"array", []string{"a"}, warnings, `["a"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			RespondWithWarnings(w, http.StatusCreated, tt.payload, tt.warnings)
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.expected, w.Body.String())
+		})
+	}
+}
+
+func TestRespondWithJSONETag(t *testing.T) {
+	payload := []string{"monstera", "ficus"}
+
+	w := httptest.NewRecorder()
+	RespondWithJSONETag(w, httptest.NewRequest(http.MethodGet, "/users/me/favorites", nil), http.StatusOK, payload)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `["monstera","ficus"]`, w.Body.String())
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+
+	// The client's copy is still current
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		r := httptest.NewRequest(http.MethodGet, "/users/me/favorites", nil)
+		r.Header.Set("If-None-Match", ifNoneMatch)
+		w = httptest.NewRecorder()
+		RespondWithJSONETag(w, r, http.StatusOK, payload)
+		assert.Equal(t, http.StatusNotModified, w.Code, ifNoneMatch)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	}
+
+	// The content changed
+	r := httptest.NewRequest(http.MethodGet, "/users/me/favorites", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	RespondWithJSONETag(w, r, http.StatusOK, []string{"monstera"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestRespondWithDatabaseError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"unique violation", &pq.Error{Code: "23505"}, http.StatusConflict},
+		{"missing reference", &pq.Error{Code: "23503", Detail: `Key (plant_id)=(1) is not present in table "plants".`}, http.StatusNotFound},
+		{"check violation", &pq.Error{Code: "23514"}, http.StatusBadRequest},
+		{"serialization failure", &pq.Error{Code: "40001"}, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			assert.True(t, RespondWithDatabaseError(w, fmt.Errorf("failed to add shop plant: %w", tt.err)))
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	RespondWithDatabaseError(w, &pq.Error{Code: "40P01"})
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	// Other errors are left to the caller
+	w = httptest.NewRecorder()
+	assert.False(t, RespondWithDatabaseError(w, errors.New("connection refused")))
+	assert.False(t, RespondWithDatabaseError(w, &pq.Error{Code: "42P01"}))
+	assert.Empty(t, w.Body.String())
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/go-playground/validator/v10"
+)
+
+// Validate is a validator instance. Fields are reported by their JSON names; fields without one,
+// such as query filters, by their Go name starting in lower case.
+var Validate = newValidator()
+
+// newValidator creates a validator reporting fields by their JSON names
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+		r, size := utf8.DecodeRuneInString(field.Name)
+		return string(unicode.ToLower(r)) + field.Name[size:]
+	})
+	return v
+}
+
+// validationMessages holds the message of each validation rule for each supported language. Messages
+// take the field name as %[1]s and the rule parameter as %[2]s; the _ITEMS variants are used for
+// lists and the _EXCLUSIVE variants for strict limits.
+var validationMessages = map[models.Language]map[string]string{
+	models.LanguageEnglish: {
+		"REQUIRED":            "%[1]s is required",
+		"INVALID_EMAIL":       "%[1]s must be a valid email",
+		"INVALID_URL":         "%[1]s must be a valid URL",
+		"TOO_SHORT":           "%[1]s must be at least %[2]s characters",
+		"TOO_SHORT_ITEMS":     "%[1]s must have at least %[2]s items",
+		"TOO_LONG":            "%[1]s must be at most %[2]s characters",
+		"TOO_LONG_ITEMS":      "%[1]s must have at most %[2]s items",
+		"TOO_SMALL":           "%[1]s must be at least %[2]s",
+		"TOO_SMALL_EXCLUSIVE": "%[1]s must be greater than %[2]s",
+		"TOO_LARGE":           "%[1]s must be at most %[2]s",
+		"TOO_LARGE_EXCLUSIVE": "%[1]s must be less than %[2]s",
+		"NOT_ALLOWED":         "%[1]s must be one of: %[2]s",
+		"INVALID":             "%[1]s is invalid",
+	},
+	models.LanguageRussian: {
+		"REQUIRED":            "Поле %[1]s обязательно",
+		"INVALID_EMAIL":       "Поле %[1]s должно содержать корректный email",
+		"INVALID_URL":         "Поле %[1]s должно содержать корректный URL",
+		"TOO_SHORT":           "Поле %[1]s должно содержать не менее %[2]s символов",
+		"TOO_SHORT_ITEMS":     "Поле %[1]s должно содержать не менее %[2]s элементов",
+		"TOO_LONG":            "Поле %[1]s должно содержать не более %[2]s символов",
+		"TOO_LONG_ITEMS":      "Поле %[1]s должно содержать не более %[2]s элементов",
+		"TOO_SMALL":           "Поле %[1]s должно быть не меньше %[2]s",
+		"TOO_SMALL_EXCLUSIVE": "Поле %[1]s должно быть больше %[2]s",
+		"TOO_LARGE":           "Поле %[1]s должно быть не больше %[2]s",
+		"TOO_LARGE_EXCLUSIVE": "Поле %[1]s должно быть меньше %[2]s",
+		"NOT_ALLOWED":         "Поле %[1]s должно принимать одно из значений: %[2]s",
+		"INVALID":             "Поле %[1]s заполнено неверно",
+	},
+}
+
+// ValidationErrors describes the fields that failed validation with messages in the language,
+// defaulting to Russian. It returns nil for errors that are not validation errors.
+func ValidationErrors(err error, language models.Language) []models.ValidationError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	messages, ok := validationMessages[language]
+	if !ok {
+		messages = validationMessages[models.LanguageRussian]
+	}
+
+	fields := make([]models.ValidationError, 0, len(validationErrors))
+	for _, e := range validationErrors {
+		// Drop the name of the validated struct from the path
+		_, field, ok := strings.Cut(e.Namespace(), ".")
+		if !ok {
+			field = e.Field()
+		}
+
+		code, key := validationRule(e)
+		fields = append(fields, models.ValidationError{
+			Field:   field,
+			Code:    code,
+			Param:   e.Param(),
+			Message: fmt.Sprintf(messages[key], field, strings.ReplaceAll(e.Param(), " ", ", ")),
+		})
+	}
+	return fields
+}
+
+// validationRule returns the code of the rule a field failed and the key of its message
+func validationRule(e validator.FieldError) (models.ValidationErrorCode, string) {
+	var isLength, isList bool
+	switch e.Kind() {
+	case reflect.String:
+		isLength = true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		isLength, isList = true, true
+	}
+
+	withSuffix := func(code models.ValidationErrorCode, suffix string) (models.ValidationErrorCode, string) {
+		return code, string(code) + suffix
+	}
+	switch e.Tag() {
+	case "required":
+		return withSuffix(models.ValidationErrorRequired, "")
+	case "email":
+		return withSuffix(models.ValidationErrorInvalidEmail, "")
+	case "url":
+		return withSuffix(models.ValidationErrorInvalidURL, "")
+	case "oneof":
+		return withSuffix(models.ValidationErrorNotAllowed, "")
+	case "min", "gte":
+		switch {
+		case isList:
+			return withSuffix(models.ValidationErrorTooShort, "_ITEMS")
+		case isLength:
+			return withSuffix(models.ValidationErrorTooShort, "")
+		}
+		return withSuffix(models.ValidationErrorTooSmall, "")
+	case "gt":
+		return withSuffix(models.ValidationErrorTooSmall, "_EXCLUSIVE")
+	case "max", "lte":
+		switch {
+		case isList:
+			return withSuffix(models.ValidationErrorTooLong, "_ITEMS")
+		case isLength:
+			return withSuffix(models.ValidationErrorTooLong, "")
+		}
+		return withSuffix(models.ValidationErrorTooLarge, "")
+	case "lt":
+		return withSuffix(models.ValidationErrorTooLarge, "_EXCLUSIVE")
+	default:
+		return withSuffix(models.ValidationErrorInvalid, "")
+	}
+}
+
+// RespondWithValidationError responds with 400 describing the fields that failed validation: the
+// "error" text joins their localized messages and "fields" lists them with machine-readable codes.
+// Other errors are sent as their text.
+func RespondWithValidationError(w http.ResponseWriter, err error, language models.Language) {
+	fields := ValidationErrors(err, language)
+	if fields == nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	RespondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error":  strings.Join(messages, ", "),
+		"fields": fields,
+	})
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type validationTestCare struct {
+	Sunlight string `json:"sunlight" validate:"oneof=LOW MEDIUM HIGH"`
+}
+
+type validationTestRequest struct {
+	Email    string             `json:"email" validate:"required,email"`
+	Password string             `json:"password" validate:"min=6"`
+	Price    float64            `json:"price" validate:"gt=0"`
+	Photos   []string           `json:"photos" validate:"max=1"`
+	Care     validationTestCare `json:"careInstructions"`
+	PageSize int                `validate:"max=100"`
+}
+
+func TestValidationErrors(t *testing.T) {
+	err := Validate.Struct(validationTestRequest{
+		Email:    "not-an-email",
+		Password: "123",
+		Photos:   []string{"a", "b"},
+		Care:     validationTestCare{Sunlight: "DARK"},
+		PageSize: 500,
+	})
+
+	fields := ValidationErrors(err, models.LanguageEnglish)
+	assert.Equal(t, []models.ValidationError{
+		{Field: "email", Code: models.ValidationErrorInvalidEmail, Message: "email must be a valid email"},
+		{Field: "password", Code: models.ValidationErrorTooShort, Param: "6", Message: "password must be at least 6 characters"},
+		{Field: "price", Code: models.ValidationErrorTooSmall, Param: "0", Message: "price must be greater than 0"},
+		{Field: "photos", Code: models.ValidationErrorTooLong, Param: "1", Message: "photos must have at most 1 items"},
+		{Field: "careInstructions.sunlight", Code: models.ValidationErrorNotAllowed, Param: "LOW MEDIUM HIGH", Message: "careInstructions.sunlight must be one of: LOW, MEDIUM, HIGH"},
+		{Field: "pageSize", Code: models.ValidationErrorTooLarge, Param: "100", Message: "pageSize must be at most 100"},
+	}, fields)
+
+	// Russian is used for other languages too
+	fields = ValidationErrors(err, models.Language("de"))
+	assert.Equal(t, "Поле email должно содержать корректный email", fields[0].Message)
+
+	assert.Nil(t, ValidationErrors(assert.AnError, models.LanguageEnglish))
+}
+
+func TestRespondWithValidationError(t *testing.T) {
+	w := httptest.NewRecorder()
+	RespondWithValidationError(w, Validate.Struct(validationTestRequest{
+		Password: "123456",
+		Price:    1,
+		Care:     validationTestCare{Sunlight: "LOW"},
+	}), models.LanguageRussian)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{
+		"error": "Поле email обязательно",
+		"fields": [{"field": "email", "code": "REQUIRED", "message": "Поле email обязательно"}]
+	}`, w.Body.String())
+}
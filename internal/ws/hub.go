@@ -0,0 +1,219 @@
+// Package ws streams events to clients connected over WebSocket
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// maxConnectionsPerUser is the number of notification streams a user may have open, e.g. one per device
+	maxConnectionsPerUser = 5
+
+	// sendBuffer is the number of messages queued for a client; a client that falls further behind is
+	// disconnected and catches up with GET /notifications when it reconnects
+	sendBuffer = 16
+
+	// writeTimeout is how long a message may take to reach a client
+	writeTimeout = 10 * time.Second
+
+	// pingInterval is how often idle streams get a ping message, so proxies keep them open and dead
+	// connections are noticed
+	pingInterval = 30 * time.Second
+)
+
+// Message types sent on a notification stream
+const (
+	MessageTypeNotification = "notification"
+	MessageTypePing         = "ping"
+)
+
+var (
+	// ErrTooManyConnections is returned when a user already has the maximum number of streams open
+	ErrTooManyConnections = errors.New("too many notification streams")
+	// ErrClosed is returned for streams opened after the hub was closed
+	ErrClosed = errors.New("hub is closed")
+)
+
+// Message is a message sent on a notification stream
+type Message struct {
+	Type         string               `json:"type"`
+	Notification *models.Notification `json:"notification,omitempty"`
+}
+
+// client is a connected notification stream
+type client struct {
+	userID uuid.UUID
+	send   chan []byte
+	done   chan struct{}
+	once   sync.Once
+}
+
+// close stops the client's writer, which closes the connection
+func (c *client) close() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// Hub keeps the notification streams of connected clients and pushes each new notification to the
+// streams of its recipient. Streams only get the notifications created by this instance.
+type Hub struct {
+	mu      sync.Mutex
+	clients map[uuid.UUID]map[*client]struct{}
+	closed  bool
+}
+
+// NewHub creates a new hub
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[uuid.UUID]map[*client]struct{}),
+	}
+}
+
+// Publish pushes a notification to the open streams of its recipient without waiting for them
+func (h *Hub) Publish(notification *models.Notification) {
+	data, err := json.Marshal(Message{Type: MessageTypeNotification, Notification: notification})
+	if err != nil {
+		log.Printf("Failed to encode notification %s for streaming: %v", notification.ID, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients[notification.UserID] {
+		select {
+		case c.send <- data:
+		default:
+			// The client cannot keep up; it reloads the notifications when it reconnects
+			h.unregisterLocked(c)
+			c.close()
+		}
+	}
+}
+
+// ServeNotifications upgrades the request to a WebSocket and streams the notifications of a user on it
+// until the client disconnects or the hub is closed
+func (h *Hub) ServeNotifications(w http.ResponseWriter, r *http.Request, userID uuid.UUID) error {
+	c := &client{
+		userID: userID,
+		send:   make(chan []byte, sendBuffer),
+		done:   make(chan struct{}),
+	}
+	if err := h.register(c); err != nil {
+		return err
+	}
+	// Serving returns when the stream ends, or right away when the handshake fails
+	defer h.unregister(c)
+
+	server := websocket.Server{
+		// Streams are authenticated with a bearer token, not cookies, so any origin may connect
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			h.stream(conn, c)
+		},
+	}
+	server.ServeHTTP(w, r)
+	return nil
+}
+
+// stream writes the messages of a client to its connection until either side stops
+func (h *Hub) stream(conn *websocket.Conn, c *client) {
+	defer conn.Close()
+
+	// The server's request deadlines still apply to the hijacked connection
+	conn.SetDeadline(time.Time{})
+
+	// Read until the client disconnects; clients send nothing but control frames
+	go func() {
+		defer c.close()
+		var discard []byte
+		for {
+			if err := websocket.Message.Receive(conn, &discard); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping, _ := json.Marshal(Message{Type: MessageTypePing})
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		var data []byte
+		select {
+		case <-c.done:
+			return
+		case data = <-c.send:
+		case <-ticker.C:
+			data = ping
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := websocket.Message.Send(conn, string(data)); err != nil {
+			return
+		}
+	}
+}
+
+// register adds a client, unless its user has too many streams open or the hub is closed
+func (h *Hub) register(c *client) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrClosed
+	}
+	if len(h.clients[c.userID]) >= maxConnectionsPerUser {
+		return ErrTooManyConnections
+	}
+	if h.clients[c.userID] == nil {
+		h.clients[c.userID] = make(map[*client]struct{})
+	}
+	h.clients[c.userID][c] = struct{}{}
+	return nil
+}
+
+// unregister removes a client
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unregisterLocked(c)
+}
+
+// unregisterLocked removes a client while the lock is held
+func (h *Hub) unregisterLocked(c *client) {
+	delete(h.clients[c.userID], c)
+	if len(h.clients[c.userID]) == 0 {
+		delete(h.clients, c.userID)
+	}
+}
+
+// Connections returns the number of open streams
+func (h *Hub) Connections() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	count := 0
+	for _, clients := range h.clients {
+		count += len(clients)
+	}
+	return count
+}
+
+// Close disconnects every stream and refuses new ones. The HTTP server does not track hijacked
+// connections, so it is called when the server shuts down.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, clients := range h.clients {
+		for c := range clients {
+			c.close()
+		}
+	}
+	h.clients = make(map[uuid.UUID]map[*client]struct{})
+}
@@ -0,0 +1,101 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anpanovv/planter/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// newTestServer serves the notification streams of the user named by the user query parameter
+func newTestServer(t *testing.T, hub *Hub) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := hub.ServeNotifications(w, r, uuid.MustParse(r.URL.Query().Get("user"))); err != nil {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// dial opens the notification stream of a user and waits until the hub has registered it
+func dial(t *testing.T, hub *Hub, server *httptest.Server, userID uuid.UUID) *websocket.Conn {
+	before := hub.Connections()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/?user=" + userID.String()
+	conn, err := websocket.Dial(url, "", server.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	require.Eventually(t, func() bool { return hub.Connections() > before }, time.Second, 5*time.Millisecond)
+	return conn
+}
+
+// receive reads the next message of a stream
+func receive(t *testing.T, conn *websocket.Conn) (Message, error) {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var data string
+	if err := websocket.Message.Receive(conn, &data); err != nil {
+		return Message{}, err
+	}
+	var message Message
+	require.NoError(t, json.Unmarshal([]byte(data), &message))
+	return message, nil
+}
+
+// TestHub_Publish tests that notifications reach every stream of their recipient and no other
+func TestHub_Publish(t *testing.T) {
+	hub := NewHub()
+	server := newTestServer(t, hub)
+	userID, otherUserID := uuid.New(), uuid.New()
+	phone := dial(t, hub, server, userID)
+	tablet := dial(t, hub, server, userID)
+	other := dial(t, hub, server, otherUserID)
+
+	notification := &models.Notification{ID: uuid.New(), UserID: userID, Type: models.NotificationTypeWatering, Message: "Time to water your Monstera!"}
+	hub.Publish(notification)
+
+	for _, conn := range []*websocket.Conn{phone, tablet} {
+		message, err := receive(t, conn)
+		require.NoError(t, err)
+		assert.Equal(t, MessageTypeNotification, message.Type)
+		if assert.NotNil(t, message.Notification) {
+			assert.Equal(t, notification.ID, message.Notification.ID)
+			assert.Equal(t, "Time to water your Monstera!", message.Notification.Message)
+		}
+	}
+	_, err := receive(t, other)
+	assert.Error(t, err)
+}
+
+// TestHub_Limits tests refusing streams over the limit of a user, dropping closed streams and
+// disconnecting everyone when the hub is closed
+func TestHub_Limits(t *testing.T) {
+	hub := NewHub()
+	server := newTestServer(t, hub)
+	userID := uuid.New()
+	var conns []*websocket.Conn
+	for i := 0; i < maxConnectionsPerUser; i++ {
+		conns = append(conns, dial(t, hub, server, userID))
+	}
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/?user=" + userID.String()
+	_, err := websocket.Dial(url, "", server.URL)
+	assert.Error(t, err)
+
+	conns[0].Close()
+	require.Eventually(t, func() bool { return hub.Connections() == maxConnectionsPerUser-1 }, time.Second, 5*time.Millisecond)
+
+	hub.Close()
+	assert.Equal(t, 0, hub.Connections())
+	_, err = receive(t, conns[1])
+	assert.Error(t, err)
+	_, err = websocket.Dial(url, "", server.URL)
+	assert.Error(t, err)
+}
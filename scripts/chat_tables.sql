@@ -15,9 +15,36 @@ CREATE TABLE chat_messages (
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     role VARCHAR(50) NOT NULL, -- 'user' or 'assistant'
     content TEXT NOT NULL,
+    language VARCHAR(20) NOT NULL DEFAULT 'RUSSIAN',
     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
 );
 
 -- Create indexes
 CREATE INDEX idx_chat_sessions_user_id ON chat_sessions(user_id);
-CREATE INDEX idx_chat_messages_session_id ON chat_messages(session_id);
\ No newline at end of file
+CREATE INDEX idx_chat_messages_session_id ON chat_messages(session_id);
+
+-- Add the message language to databases created before it existed
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS language VARCHAR(20) NOT NULL DEFAULT 'RUSSIAN';
+
+-- Add the Yandex GPT tokens spent on assistant messages to databases created before they were tracked
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS prompt_tokens BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS completion_tokens BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_chat_messages_user_id ON chat_messages(user_id);
+
+-- Add the persisted conversation context to databases created before it existed
+ALTER TABLE chat_sessions ADD COLUMN IF NOT EXISTS system_prompt TEXT NOT NULL DEFAULT '';
+ALTER TABLE chat_sessions ADD COLUMN IF NOT EXISTS summary TEXT NOT NULL DEFAULT '';
+ALTER TABLE chat_sessions ADD COLUMN IF NOT EXISTS summarized_through TIMESTAMP WITH TIME ZONE;
+
+-- Add requests for a human expert and expert replies to databases created before they existed
+ALTER TABLE chat_sessions ADD COLUMN IF NOT EXISTS escalation_status VARCHAR(20);
+ALTER TABLE chat_sessions ADD COLUMN IF NOT EXISTS escalation_reason TEXT;
+ALTER TABLE chat_sessions ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE chat_sessions ADD COLUMN IF NOT EXISTS expert_id UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS expert_id UUID REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_chat_sessions_escalation ON chat_sessions(escalation_status, escalated_at)
+    WHERE escalation_status IS NOT NULL;
+
+-- Add photos attached to chat messages to databases created before they existed
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS image_url TEXT;
+ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS image_description TEXT NOT NULL DEFAULT '';